@@ -0,0 +1,98 @@
+// PII anonymization of a database copy, `go run ./cmd/anonymize run -confirm <dbname>` rewrites user names, emails,
+// phones and contact addresses with deterministic fakes so staging can run on production-shaped data.
+// Point the config at the copy, -confirm must repeat its database name and Production mode is refused.
+// The audit trail is left alone since rewriting it would break its hash chain, truncate it in the copy instead.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/anonymize/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/anonymize/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func main() {
+	flags := flag.NewFlagSet("anonymize run", flag.ExitOnError)
+	confirm := flags.String("confirm", "", "name of the database to rewrite, must match the configured one")
+	batchSize := flags.Int("batch", 500, "rows per batch")
+	dryRun := flags.Bool("dry-run", false, "only count rows to anonymize")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: anonymize run -confirm <dbname> [flags]")
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) < 2 || os.Args[1] != "run" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := flags.Parse(os.Args[2:]); err != nil || *batchSize <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfgFile, err := config.LoadConfig(utils.GetConfigPath(os.Getenv("config")))
+	if err != nil {
+		log.Fatalf("LoadConfig: %v", err)
+	}
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("ParseConfig: %v", err)
+	}
+
+	if strings.EqualFold(cfg.Server.Mode, "Production") {
+		log.Fatal("refusing to anonymize in Production mode")
+	}
+	if *confirm != cfg.Postgres.PostgresqlDbname {
+		log.Fatalf("-confirm %q does not match the configured database %q", *confirm, cfg.Postgres.PostgresqlDbname)
+	}
+
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	ctx := context.Background()
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		log.Fatalf("secrets.NewProvider: %v", err)
+	}
+	salt, err := provider.Get(ctx, cfg.Anonymize.SaltSecret)
+	if err != nil {
+		log.Fatalf("anonymize salt: %v", err)
+	}
+	faker, err := anonymize.NewFaker([]byte(salt))
+	if err != nil {
+		log.Fatal(err)
+	}
+	cipher, err := pii.NewFromConfig(ctx, cfg)
+	if err != nil {
+		log.Fatalf("pii.NewFromConfig: %v", err)
+	}
+
+	db, err := postgres.NewPsqlDB(cfg)
+	if err != nil {
+		log.Fatalf("Postgresql init: %v", err)
+	}
+	defer db.Close()
+
+	uc := usecase.NewAnonymizeUseCase(repository.NewAnonymizeRepository(db, cipher), faker, appLogger)
+	report, err := uc.Run(ctx, *batchSize, *dryRun)
+	if err != nil {
+		log.Fatalf("anonymize after %d users, %d addresses, %d phones: %v", report.Users, report.Addresses, report.Phones, err)
+	}
+	fmt.Printf("anonymized %d users, %d addresses, %d phones, dry run %v\n", report.Users, report.Addresses, report.Phones, report.DryRun)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -11,7 +12,13 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/redis"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jmoiron/sqlx"
+	"github.com/minio/minio-go/v7"
 	"github.com/opentracing/opentracing-go"
 	"github.com/uber/jaeger-client-go"
 	jaegercfg "github.com/uber/jaeger-client-go/config"
@@ -20,6 +27,9 @@ import (
 )
 
 func main() {
+	devMode := flag.Bool("dev", false, "run without Postgres, Redis and MinIO using in-process stand-ins")
+	flag.Parse()
+
 	fmt.Println("Starting server...")
 
 	configPath := utils.GetConfigPath(os.Getenv("config"))
@@ -34,39 +44,119 @@ func main() {
 		log.Fatalf("ParseConfig: %v", err)
 	}
 
+	cfg.Dev.Enabled = cfg.Dev.Enabled || *devMode
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	cfgWatcher := config.NewWatcher(cfgFile, cfg)
+	cfgWatcher.Watch()
+
 	// Initial Logger
 	appLogger := logger.NewApiLogger(cfg)
 
 	appLogger.InitLogger()
 	appLogger.Infof(
-		"AppVersion: %s, LogLevel: %s, Mode: %s, SSL: %v",
+		"AppVersion: %s, LogLevel: %s, Mode: %s, SSL: %v, Exposure: %s (%s)",
 		cfg.Server.AppVersion,
 		cfg.Logger.Level,
 		cfg.Server.Mode,
 		cfg.Server.SSL,
+		cfg.Exposure.Profile,
+		cfg.Exposure.Active(),
 	)
 
-	// Initial PostgreSQL
-	psqlDB, err := postgres.NewPsqlDB(cfg)
-	if err != nil {
-		appLogger.Fatalf("Postgresql init: %s", err)
+	var (
+		psqlDB      *sqlx.DB
+		shadowDB    *sqlx.DB
+		replicaDBs  []*sqlx.DB
+		pgxPool     *pgxpool.Pool
+		redisClient *goredis.Client
+		awsClient   *minio.Client
+	)
+
+	if cfg.Dev.Enabled {
+		// Dev mode, repositories are kept in memory
+		var closeRedis func()
+		redisClient, closeRedis, err = redis.NewInProcessRedisClient()
+		if err != nil {
+			appLogger.Fatalf("In-process Redis init: %s", err)
+		}
+		defer closeRedis()
+
+		appLogger.Infof("Dev mode: in-memory repositories, in-process Redis, blobs stored in %s", cfg.Dev.DataDir)
 	} else {
-		appLogger.Infof("Postgres connected, Status: %#v", psqlDB.Stats())
+		// Initial PostgreSQL
+		psqlDB, err = postgres.NewPsqlDB(cfg)
+		if err != nil {
+			appLogger.Fatalf("Postgresql init: %s", err)
+		} else {
+			appLogger.Infof("Postgres connected, Status: %#v", psqlDB.Stats())
+		}
+		defer psqlDB.Close()
+
+		// Initial pgx native pool when selected as repository backend
+		if cfg.Postgres.Backend == postgres.BackendPgxPool {
+			pgxPool, err = postgres.NewPgxPool(cfg)
+			if err != nil {
+				appLogger.Fatalf("Postgresql pgxpool init: %s", err)
+			}
+			defer pgxPool.Close()
+			appLogger.Infof("Postgres pgxpool connected, MaxConns: %d", pgxPool.Config().MaxConns)
+		}
+
+		// Initial secondary backend receiving shadow traffic during a migration
+		if cfg.Shadow.Enabled {
+			shadowCfg := *cfg
+			shadowCfg.Postgres = cfg.Shadow.Postgres
+			shadowDB, err = postgres.NewPsqlDB(&shadowCfg)
+			if err != nil {
+				appLogger.Fatalf("Shadow Postgresql init: %s", err)
+			}
+			defer shadowDB.Close()
+			appLogger.Infof("Shadow Postgres connected, Reads: %v, Writes: %v, SampleRate: %v", cfg.Shadow.Reads, cfg.Shadow.Writes, cfg.Shadow.SampleRate)
+		}
+
+		// Initial read replicas of the auth repository
+		if cfg.Replicas.Enabled {
+			for i, replicaPostgres := range cfg.Replicas.Postgres {
+				replicaCfg := *cfg
+				replicaCfg.Postgres = replicaPostgres
+				replicaDB, err := postgres.NewPsqlDB(&replicaCfg)
+				if err != nil {
+					appLogger.Fatalf("Replica %d Postgresql init: %s", i, err)
+				}
+				defer replicaDB.Close()
+				replicaDBs = append(replicaDBs, replicaDB)
+			}
+			appLogger.Infof("Postgres replicas connected: %d, HedgeDelayMs: %d", len(replicaDBs), cfg.Replicas.HedgeDelayMs)
+		}
+
+		// Initial Redis
+		redisClient = redis.NewRedisClient(cfg)
+		defer redisClient.Close()
+		appLogger.Info("Redis connected")
+
+		// Initial Aws Minio
+		awsClient, err = aws.NewAWSClient(cfg.AWS.Endpoint, cfg.AWS.MinioAccessKey, cfg.AWS.MinioSecretKey, cfg.AWS.UseSSL)
+		if err != nil {
+			appLogger.Errorf("AWS Client init: %s", err)
+		}
+		appLogger.Info("AWS S3 connected")
+		appLogger.Info(awsClient)
 	}
-	defer psqlDB.Close()
 
-	// Initial Redis
-	redisClient := redis.NewRedisClient(cfg)
-	defer redisClient.Close()
-	appLogger.Info("Redis connected")
+	// Initial blob storage of the configured driver
+	blobs, err := storage.NewFromConfig(cfg)
+	if err != nil {
+		appLogger.Fatalf("Blob storage init: %s", err)
+	}
 
-	// Initial Aws Minio
-	awsClient, err := aws.NewAWSClient(cfg.AWS.Endpoint, cfg.AWS.MinioAccessKey, cfg.AWS.MinioSecretKey, cfg.AWS.UseSSL)
+	// Initial downstream service clients
+	serviceRegistry, err := services.NewRegistry(cfg.Services, appLogger)
 	if err != nil {
-		appLogger.Errorf("AWS Client init: %s", err)
+		appLogger.Fatalf("Services registry init: %s", err)
 	}
-	appLogger.Info("AWS S3 connected")
-	appLogger.Info(awsClient)
 
 	jaegerCfgInstance := jaegercfg.Configuration{
 		ServiceName: cfg.Jaeger.ServiceName,
@@ -95,7 +185,7 @@ func main() {
 	defer closer.Close()
 	appLogger.Info("Opentracing connected")
 
-	s := server.NewServer(cfg, psqlDB, redisClient, awsClient, appLogger)
+	s := server.NewServer(cfg, cfgWatcher, psqlDB, shadowDB, replicaDBs, pgxPool, redisClient, awsClient, blobs, serviceRegistry, appLogger)
 	if err := s.Run(); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,49 @@
+// Config validation for CI pipelines, `go run ./cmd/config validate` parses the config selected by the config
+// env variable like the api does and reports every problem found at once. Exits 1 when the config is invalid.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func main() {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	devMode := flags.Bool("dev", false, "validate as started with -dev, Postgres and Redis settings are not checked")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: config validate [flags]")
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) < 2 || os.Args[1] != "validate" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	configPath := utils.GetConfigPath(os.Getenv("config"))
+	cfgFile, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+	cfg.Dev.Enabled = cfg.Dev.Enabled || *devMode
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: ok\n", configPath)
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Interface methods carrying this comment hand back streams read after the call, they get no deadline
+const noDeadlineMarker = "observe:nodeadline"
+
+// Prefix of the caching annotations of interface methods, see pkg/cacheable
+const cacheMarker = "cache:"
+
+// Names the generated methods declare themselves, parameters named like them are renamed
+var reservedNames = map[string]bool{"_": true, "d": true, "call": true, "err": true}
+
+// Template data of one usecase decorator
+type decoratorData struct {
+	Source    string
+	Package   string
+	Module    string
+	Interface string
+	Imports   [][]string
+	Methods   []decoratedMethod
+	// Imports of the caching decorator, nil when no method is annotated for caching
+	CacheImports [][]string
+}
+
+// Method forwarded by the decorator
+type decoratedMethod struct {
+	Name     string
+	Params   string
+	Results  string
+	Args     string
+	Context  string
+	Err      string
+	Deadline bool
+	Returns  bool
+	// Result names, assigned by the caching decorator before emitting
+	ResultNames string
+	// Result cached by the caching decorator, nil for uncached methods
+	Cache *cachedResult
+	// Events raised once the method succeeded, empty for none
+	Emit string
+}
+
+// Cache annotation of a method rendered as Go expressions
+type cachedResult struct {
+	Type       string
+	Key        string
+	TTL        string
+	Invalidate string
+}
+
+// Decorator generator for one interface of a module usecase file
+type decoratorGenerator struct {
+	source     string
+	iface      string
+	out        string
+	outPkg     string
+	modulePath string
+	importPath string
+}
+
+// New decorator generator, source is the module usecase file and out the generated file
+func newDecoratorGenerator(source, iface, out string) (*decoratorGenerator, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "filepath.Abs")
+	}
+	root, err := findModuleRoot(filepath.Dir(absSource))
+	if err != nil {
+		return nil, err
+	}
+	modulePath, err := readModulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(absSource))
+	if err != nil {
+		return nil, errors.Wrap(err, "filepath.Rel")
+	}
+	if out == "" {
+		out = filepath.Join(filepath.Dir(source), "usecase", "observed_gen.go")
+	}
+
+	return &decoratorGenerator{
+		source:     source,
+		iface:      iface,
+		out:        out,
+		outPkg:     filepath.Base(filepath.Dir(out)),
+		modulePath: modulePath,
+		importPath: modulePath + "/" + filepath.ToSlash(rel),
+	}, nil
+}
+
+// Render the decorator, an existing output is replaced
+func (g *decoratorGenerator) Generate() error {
+	src, err := os.ReadFile(g.source)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+	data, err := g.parse(src)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.ParseFS(templatesFS, "templates/decorator.go.tmpl")
+	if err != nil {
+		return errors.Wrap(err, "template.ParseFS")
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return errors.Wrap(err, "render decorator.go.tmpl")
+	}
+	if err := writeFile(g.out, buf.Bytes()); err != nil {
+		return err
+	}
+
+	// The caching decorator only exists while methods are annotated
+	cachedOut := filepath.Join(filepath.Dir(g.out), "cached_gen.go")
+	if data.CacheImports == nil {
+		if err := os.Remove(cachedOut); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "os.Remove")
+		}
+		return nil
+	}
+	tmpl, err = template.ParseFS(templatesFS, "templates/cached.go.tmpl")
+	if err != nil {
+		return errors.Wrap(err, "template.ParseFS")
+	}
+	buf.Reset()
+	if err := tmpl.Execute(buf, data); err != nil {
+		return errors.Wrap(err, "render cached.go.tmpl")
+	}
+	return writeFile(cachedOut, buf.Bytes())
+}
+
+func (g *decoratorGenerator) parse(src []byte) (*decoratorData, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, g.source, src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "parser.ParseFile")
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok {
+			if it, ok := spec.Type.(*ast.InterfaceType); ok {
+				interfaces[spec.Name.Name] = it
+			}
+		}
+		return true
+	})
+	if interfaces[g.iface] == nil {
+		return nil, errors.Errorf("interface %s not found in %s", g.iface, g.source)
+	}
+
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		alias := filepath.Base(importPath)
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		imports[alias] = importPath
+	}
+
+	r := &typeRenderer{pkg: file.Name.Name, used: make(map[string]bool)}
+	methods, err := g.methods(interfaces, g.iface, r)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]string{
+		file.Name.Name: g.importPath,
+	}
+	for alias := range r.used {
+		importPath, ok := imports[alias]
+		if !ok {
+			return nil, errors.Errorf("unknown package %s in %s", alias, g.iface)
+		}
+		used[alias] = importPath
+	}
+	observed := map[string]string{"observe": g.modulePath + "/pkg/observe"}
+	for alias, importPath := range used {
+		observed[alias] = importPath
+	}
+
+	data := &decoratorData{
+		Source:    filepath.Base(g.source),
+		Package:   g.outPkg,
+		Module:    file.Name.Name,
+		Interface: g.iface,
+		Imports:   groupImports(observed, g.modulePath),
+		Methods:   methods,
+	}
+	for _, method := range methods {
+		if method.Cache == nil && method.Emit == "" {
+			continue
+		}
+		cached := map[string]string{"cacheable": g.modulePath + "/pkg/cacheable"}
+		for alias, importPath := range used {
+			cached[alias] = importPath
+		}
+		for _, method := range methods {
+			if method.Cache != nil && method.Cache.TTL != "0" {
+				cached["time"] = "time"
+			}
+		}
+		data.CacheImports = groupImports(cached, g.modulePath)
+		break
+	}
+	return data, nil
+}
+
+// Methods of the interface including embedded interfaces declared in the same file
+func (g *decoratorGenerator) methods(interfaces map[string]*ast.InterfaceType, name string, r *typeRenderer) ([]decoratedMethod, error) {
+	methods := make([]decoratedMethod, 0)
+	for _, field := range interfaces[name].Methods.List {
+		if len(field.Names) == 0 {
+			embedded, ok := field.Type.(*ast.Ident)
+			if !ok || interfaces[embedded.Name] == nil {
+				return nil, errors.Errorf("embedded interface %s of %s must be declared in %s", r.render(field.Type), name, g.source)
+			}
+			inner, err := g.methods(interfaces, embedded.Name, r)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, inner...)
+			continue
+		}
+
+		fn := field.Type.(*ast.FuncType)
+		method := decoratedMethod{
+			Name:     field.Names[0].Name,
+			Deadline: !strings.Contains(field.Doc.Text()+field.Comment.Text(), noDeadlineMarker),
+		}
+
+		params := make([]string, 0)
+		args := make([]string, 0)
+		paramNames := make(map[string]bool)
+		for i, param := range fieldList(fn.Params) {
+			paramName := fmt.Sprintf("p%d", i)
+			if param.name != "" && !reservedNames[param.name] {
+				paramName = param.name
+			}
+			if i == 0 && r.render(param.typ) == "context.Context" {
+				method.Context = paramName
+			}
+			typ := r.render(param.typ)
+			arg := paramName
+			if _, variadic := param.typ.(*ast.Ellipsis); variadic {
+				arg += "..."
+			}
+			params = append(params, paramName+" "+typ)
+			args = append(args, arg)
+			paramNames[paramName] = true
+		}
+
+		results := make([]string, 0)
+		resultNames := make([]string, 0)
+		resultTypes := make([]string, 0)
+		for i, result := range fieldList(fn.Results) {
+			typ := r.render(result.typ)
+			resultName := fmt.Sprintf("r%d", i)
+			if typ == "error" {
+				resultName = "err"
+				method.Err = resultName
+			}
+			results = append(results, resultName+" "+typ)
+			resultNames = append(resultNames, resultName)
+			resultTypes = append(resultTypes, typ)
+		}
+
+		method.Params = strings.Join(params, ", ")
+		method.Args = strings.Join(args, ", ")
+		method.ResultNames = strings.Join(resultNames, ", ")
+		method.Returns = len(results) > 0
+		if method.Returns {
+			method.Results = "(" + strings.Join(results, ", ") + ")"
+		}
+		if err := g.cacheAnnotations(&method, field.Doc.Text()+field.Comment.Text(), paramNames, resultTypes); err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// Read the cache: annotations of method, cached methods take a context and return a result and an error,
+// emitting methods return an error
+func (g *decoratorGenerator) cacheAnnotations(method *decoratedMethod, doc string, params map[string]bool, results []string) error {
+	annotations := make(map[string]string)
+	for _, word := range strings.Fields(doc) {
+		if !strings.HasPrefix(word, cacheMarker) {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(word, cacheMarker), "=")
+		if !ok || value == "" {
+			return errors.Errorf("%s.%s: annotation %s needs a value", g.iface, method.Name, word)
+		}
+		annotations[name] = value
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	if emit, ok := annotations["emit"]; ok {
+		delete(annotations, "emit")
+		if method.Context == "" || method.Err == "" {
+			return errors.Errorf("%s.%s: cache:emit needs a context parameter and an error result", g.iface, method.Name)
+		}
+		events, err := renderEvents(emit, params)
+		if err != nil {
+			return errors.Wrapf(err, "%s.%s: cache:emit", g.iface, method.Name)
+		}
+		method.Emit = events
+	}
+
+	key, ok := annotations["key"]
+	if !ok {
+		for name := range annotations {
+			return errors.Errorf("%s.%s: cache:%s needs cache:key", g.iface, method.Name, name)
+		}
+		return nil
+	}
+	if method.Context == "" || len(results) != 2 || results[1] != "error" {
+		return errors.Errorf("%s.%s: cache:key needs a context parameter and a result with an error", g.iface, method.Name)
+	}
+	if method.Emit != "" {
+		return errors.Errorf("%s.%s: cache:key and cache:emit exclude each other", g.iface, method.Name)
+	}
+
+	cache := &cachedResult{Type: results[0], TTL: "0", Invalidate: "nil"}
+	var err error
+	if cache.Key, err = renderTemplate(key, params); err != nil {
+		return errors.Wrapf(err, "%s.%s: cache:key", g.iface, method.Name)
+	}
+	for name, value := range annotations {
+		switch name {
+		case "key":
+		case "ttl":
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return errors.Errorf("%s.%s: cache:ttl %q is not a positive number of seconds", g.iface, method.Name, value)
+			}
+			cache.TTL = fmt.Sprintf("%d * time.Second", seconds)
+		case "invalidate":
+			events, err := renderEvents(value, params)
+			if err != nil {
+				return errors.Wrapf(err, "%s.%s: cache:invalidate", g.iface, method.Name)
+			}
+			cache.Invalidate = "[]string{" + events + "}"
+		default:
+			return errors.Errorf("%s.%s: unknown annotation cache:%s", g.iface, method.Name, name)
+		}
+	}
+	method.Cache = cache
+	return nil
+}
+
+// Comma separated event templates as a list of expressions
+func renderEvents(value string, params map[string]bool) (string, error) {
+	events := make([]string, 0)
+	for _, event := range strings.Split(value, ",") {
+		rendered, err := renderTemplate(event, params)
+		if err != nil {
+			return "", err
+		}
+		events = append(events, rendered)
+	}
+	return strings.Join(events, ", "), nil
+}
+
+// Key template as an expression, a string literal unless it has {param} or {param.Field} placeholders
+func renderTemplate(tmpl string, params map[string]bool) (string, error) {
+	if tmpl == "" {
+		return "", errors.New("empty template")
+	}
+	parts := make([]string, 0)
+	for rest := tmpl; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", errors.Errorf("unclosed placeholder in %q", tmpl)
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		placeholder := rest[start+1 : start+end]
+		if !params[strings.SplitN(placeholder, ".", 2)[0]] {
+			return "", errors.Errorf("placeholder {%s} of %q is not a parameter", placeholder, tmpl)
+		}
+		parts = append(parts, placeholder)
+		rest = rest[start+end+1:]
+	}
+	if len(parts) == 1 && strings.HasPrefix(parts[0], `"`) {
+		return parts[0], nil
+	}
+	return "cacheable.Key(" + strings.Join(parts, ", ") + ")", nil
+}
+
+type namedType struct {
+	name string
+	typ  ast.Expr
+}
+
+// Flatten `a, b int` into one entry per parameter
+func fieldList(list *ast.FieldList) []namedType {
+	fields := make([]namedType, 0)
+	if list == nil {
+		return fields
+	}
+	for _, field := range list.List {
+		if len(field.Names) == 0 {
+			fields = append(fields, namedType{typ: field.Type})
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, namedType{name: name.Name, typ: field.Type})
+		}
+	}
+	return fields
+}
+
+// Renders type expressions for use outside of their package, exported local names get qualified
+type typeRenderer struct {
+	pkg  string
+	used map[string]bool
+}
+
+func (r *typeRenderer) render(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return r.pkg + "." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			r.used[pkg.Name] = true
+			return pkg.Name + "." + t.Sel.Name
+		}
+		return r.render(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + r.render(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + r.render(t.Elt)
+		}
+		return "[" + r.render(t.Len) + "]" + r.render(t.Elt)
+	case *ast.BasicLit:
+		return t.Value
+	case *ast.MapType:
+		return "map[" + r.render(t.Key) + "]" + r.render(t.Value)
+	case *ast.Ellipsis:
+		return "..." + r.render(t.Elt)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + r.render(t.Value)
+		case ast.RECV:
+			return "<-chan " + r.render(t.Value)
+		}
+		return "chan " + r.render(t.Value)
+	case *ast.FuncType:
+		params := make([]string, 0)
+		for _, param := range fieldList(t.Params) {
+			params = append(params, r.render(param.typ))
+		}
+		results := make([]string, 0)
+		for _, result := range fieldList(t.Results) {
+			results = append(results, r.render(result.typ))
+		}
+		rendered := "func(" + strings.Join(params, ", ") + ")"
+		if len(results) == 1 {
+			rendered += " " + results[0]
+		} else if len(results) > 1 {
+			rendered += " (" + strings.Join(results, ", ") + ")"
+		}
+		return rendered
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+// Standard library, third party and module imports, each group sorted
+func groupImports(imports map[string]string, modulePath string) [][]string {
+	groups := make([][]string, 3)
+	for alias, importPath := range imports {
+		line := strconv.Quote(importPath)
+		if filepath.Base(importPath) != alias {
+			line = alias + " " + line
+		}
+		switch {
+		case strings.HasPrefix(importPath, modulePath+"/"):
+			groups[2] = append(groups[2], line)
+		case !strings.Contains(strings.Split(importPath, "/")[0], "."):
+			groups[0] = append(groups[0], line)
+		default:
+			groups[1] = append(groups[1], line)
+		}
+	}
+
+	nonEmpty := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		if len(group) > 0 {
+			sort.Strings(group)
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+	return nonEmpty
+}
+
+// Closest parent directory holding go.mod
+func findModuleRoot(dir string) (string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const widgetsUseCase = `package widgets
+
+import (
+	"context"
+	"io"
+
+	"example.com/svc/internal/models"
+)
+
+type Lister interface {
+	List(ctx context.Context, ids ...int64) ([]*models.Widget, error)
+}
+
+// Widgets use case
+type UseCase interface {
+	Lister
+	// observe:nodeadline
+	Open(ctx context.Context, id int64) (io.ReadCloser, error)
+	Touch(_ context.Context, err Reason)
+	Name() string
+}
+
+type Reason string
+`
+
+func TestDecoratorGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "internal", "widgets")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/svc\n\ngo 1.22\n"), 0o644))
+	require.NoError(t, os.MkdirAll(moduleDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "usecase.go"), []byte(widgetsUseCase), 0o644))
+
+	gen, err := newDecoratorGenerator(filepath.Join(moduleDir, "usecase.go"), "UseCase", "")
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	out, err := os.ReadFile(filepath.Join(moduleDir, "usecase", "observed_gen.go"))
+	require.NoError(t, err)
+	src := string(out)
+	require.Contains(t, src, "package usecase")
+	require.Contains(t, src, `"example.com/svc/internal/widgets"`)
+	require.Contains(t, src, `"example.com/svc/pkg/observe"`)
+	require.Contains(t, src, "func NewObservedUseCase(next widgets.UseCase, observer *observe.Observer) widgets.UseCase")
+	require.Contains(t, src, "return d.next.List(ctx, ids...)")
+	require.Contains(t, src, `d.observer.Start(ctx, "widgets.Open", false)`)
+	require.Contains(t, src, "func (d *observedUseCase) Touch(p0 context.Context, p1 widgets.Reason) {")
+	require.Contains(t, src, "defer func() { call.Done(nil) }()")
+	require.Contains(t, src, "func (d *observedUseCase) Name() (r0 string) {\n\treturn d.next.Name()\n}")
+
+	gen.iface = "Missing"
+	require.Error(t, gen.Generate())
+}
+
+const plansUseCase = `package plans
+
+import (
+	"context"
+
+	"example.com/svc/internal/models"
+)
+
+// Plans use case
+type UseCase interface {
+	// Plan of user, cached.
+	// cache:key=plan:{userID}:{filter.Kind} cache:ttl=30 cache:invalidate=plans,user:{userID}
+	Get(ctx context.Context, userID int, filter *models.Filter) (*models.Plan, error)
+	// cache:key=plans
+	List(ctx context.Context) ([]*models.Plan, error)
+	// cache:emit=user:{userID}
+	Assign(ctx context.Context, userID int, plan string) (*models.Plan, error)
+	// cache:emit=plans
+	Reset(ctx context.Context) error
+	Count(ctx context.Context) (int, error)
+}
+`
+
+func TestDecoratorGenerator_GenerateCached(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "internal", "plans")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/svc\n\ngo 1.22\n"), 0o644))
+	require.NoError(t, os.MkdirAll(moduleDir, 0o755))
+	source := filepath.Join(moduleDir, "usecase.go")
+	require.NoError(t, os.WriteFile(source, []byte(plansUseCase), 0o644))
+
+	gen, err := newDecoratorGenerator(source, "UseCase", "")
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	cachedOut := filepath.Join(moduleDir, "usecase", "cached_gen.go")
+	out, err := os.ReadFile(cachedOut)
+	require.NoError(t, err)
+	src := string(out)
+	require.Contains(t, src, `"example.com/svc/pkg/cacheable"`)
+	require.Contains(t, src, `"time"`)
+	require.Contains(t, src, "func NewCachedUseCase(next plans.UseCase, cache *cacheable.Cache) plans.UseCase")
+	require.Contains(t, src, `return cacheable.Load(ctx, d.cache, "plans.Get", cacheable.Key("plan:", userID, ":", filter.Kind), 30*time.Second, `+
+		`[]string{"plans", cacheable.Key("user:", userID)}, func() (*models.Plan, error) {`)
+	require.Contains(t, src, `return cacheable.Load(ctx, d.cache, "plans.List", "plans", 0, nil, func() ([]*models.Plan, error) {`)
+	require.Contains(t, src, "\tr0, err = d.next.Assign(ctx, userID, plan)\n\tif err == nil {\n\t\td.cache.Emit(ctx, cacheable.Key(\"user:\", userID))\n\t}\n\treturn\n")
+	require.Contains(t, src, "\terr = d.next.Reset(ctx)\n")
+	require.Contains(t, src, "\treturn d.next.Count(ctx)\n")
+
+	// Observed decorator is unaffected
+	out, err = os.ReadFile(filepath.Join(moduleDir, "usecase", "observed_gen.go"))
+	require.NoError(t, err)
+	require.NotContains(t, string(out), "cacheable")
+
+	// Unannotated interfaces drop a stale caching decorator
+	require.NoError(t, os.WriteFile(source, []byte(strings.ReplaceAll(plansUseCase, "cache:", "")), 0o644))
+	require.NoError(t, gen.Generate())
+	_, err = os.Stat(cachedOut)
+	require.True(t, os.IsNotExist(err))
+
+	for annotation, message := range map[string]string{
+		"cache:key=plan:{user}":             "placeholder {user}",
+		"cache:key=plan:{userID":            "unclosed placeholder",
+		"cache:ttl=30":                      "cache:ttl needs cache:key",
+		"cache:key=plan cache:ttl=soon":     "not a positive number",
+		"cache:key=plan cache:emit=plans":   "exclude each other",
+		"cache:key=plan cache:refresh=true": "unknown annotation cache:refresh",
+		"cache:key=":                        "needs a value",
+	} {
+		broken := strings.Replace(plansUseCase, "cache:key=plan:{userID}:{filter.Kind} cache:ttl=30 cache:invalidate=plans,user:{userID}", annotation, 1)
+		require.NoError(t, os.WriteFile(source, []byte(broken), 0o644))
+		require.ErrorContains(t, gen.Generate(), message, annotation)
+	}
+
+	// Only methods returning a result and an error are cached
+	broken := strings.Replace(plansUseCase, "// cache:emit=plans\n", "// cache:key=plans\n", 1)
+	require.NoError(t, os.WriteFile(source, []byte(broken), 0o644))
+	require.ErrorContains(t, gen.Generate(), "UseCase.Reset: cache:key needs")
+}
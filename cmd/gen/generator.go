@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+const modulesFile = "internal/server/modules_gen.go"
+
+var (
+	moduleNameRe    = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	migrationNumber = regexp.MustCompile(`^(\d+)_`)
+)
+
+// Template data for one module
+type moduleData struct {
+	ModulePath string
+	Package    string
+	Title      string
+	Entity     string
+	Var        string
+	Table      string
+	Migration  string
+}
+
+// Output file of a template, relative to the repository root
+type output struct {
+	template string
+	path     string
+}
+
+// Generator writes module scaffolding into a repository root
+type generator struct {
+	root string
+	data moduleData
+}
+
+// New generator for module name, entity defaults to the name without a trailing s
+func newGenerator(root, name, entity string) (*generator, error) {
+	if !moduleNameRe.MatchString(name) {
+		return nil, errors.Errorf("invalid module name %q, use lower case letters and digits", name)
+	}
+	if entity == "" {
+		entity = strings.TrimSuffix(name, "s")
+	}
+	entity = strings.ToUpper(entity[:1]) + entity[1:]
+
+	modulePath, err := readModulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	migration, err := nextMigration(filepath.Join(root, "migrations"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator{root: root, data: moduleData{
+		ModulePath: modulePath,
+		Package:    name,
+		Title:      strings.ToUpper(name[:1]) + name[1:],
+		Entity:     entity,
+		Var:        strings.ToLower(entity[:1]) + entity[1:],
+		Table:      name,
+		Migration:  migration,
+	}}, nil
+}
+
+func (g *generator) outputs() []output {
+	module := filepath.Join("internal", g.data.Package)
+	migration := filepath.Join("migrations", g.data.Migration+"_"+g.data.Table)
+	return []output{
+		{"delivery.go.tmpl", filepath.Join(module, "delivery.go")},
+		{"pg_repository.go.tmpl", filepath.Join(module, "pg_repository.go")},
+		{"usecase.go.tmpl", filepath.Join(module, "usecase.go")},
+		{"repository.go.tmpl", filepath.Join(module, "repository", "pg_repository.go")},
+		{"sql_queries.go.tmpl", filepath.Join(module, "repository", "sql_queries.go")},
+		{"usecase_impl.go.tmpl", filepath.Join(module, "usecase", "usecase.go")},
+		{"usecase_test.go.tmpl", filepath.Join(module, "usecase", "usecase_test.go")},
+		{"handlers.go.tmpl", filepath.Join(module, "delivery", "http", "handlers.go")},
+		{"routes.go.tmpl", filepath.Join(module, "delivery", "http", "routes.go")},
+		{"module.go.tmpl", filepath.Join(module, "delivery", "http", "module.go")},
+		{"model.go.tmpl", filepath.Join("internal", "models", strings.ToLower(g.data.Entity)+".go")},
+		{"migration.up.sql.tmpl", migration + ".up.sql"},
+		{"migration.down.sql.tmpl", migration + ".down.sql"},
+	}
+}
+
+// Render all files and register the module, nothing is overwritten
+func (g *generator) Generate() ([]string, error) {
+	outputs := g.outputs()
+	for _, out := range outputs {
+		if _, err := os.Stat(filepath.Join(g.root, out.path)); err == nil {
+			return nil, errors.Errorf("%s already exists", out.path)
+		}
+	}
+
+	tmpl, err := template.ParseFS(templatesFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, errors.Wrap(err, "template.ParseFS")
+	}
+
+	written := make([]string, 0, len(outputs)+1)
+	for _, out := range outputs {
+		buf := &bytes.Buffer{}
+		if err := tmpl.ExecuteTemplate(buf, out.template, g.data); err != nil {
+			return written, errors.Wrapf(err, "render %s", out.template)
+		}
+		if err := writeFile(filepath.Join(g.root, out.path), buf.Bytes()); err != nil {
+			return written, err
+		}
+		written = append(written, out.path)
+	}
+
+	if err := g.register(); err != nil {
+		return written, err
+	}
+	return append(written, modulesFile), nil
+}
+
+// Append the module to the server module list, keeping existing entries
+func (g *generator) register() error {
+	path := filepath.Join(g.root, modulesFile)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+
+	imports, entries, err := parseModules(src)
+	if err != nil {
+		return err
+	}
+
+	alias := g.data.Package + "Http"
+	imports[alias] = g.data.ModulePath + "/internal/" + g.data.Package + "/delivery/http"
+	entries = append(entries, alias+".NewModule(s.cfg, s.db, s.logger)")
+
+	return writeFile(path, renderModules(imports, entries))
+}
+
+// Read imports and list entries of the generated modules file
+func parseModules(src []byte) (map[string]string, []string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, modulesFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parser.ParseFile")
+	}
+
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		alias := filepath.Base(importPath)
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		imports[alias] = importPath
+	}
+
+	var entries []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			entries = append(entries, string(src[fset.Position(elt.Pos()).Offset:fset.Position(elt.End()).Offset]))
+		}
+		return false
+	})
+	return imports, entries, nil
+}
+
+func renderModules(imports map[string]string, entries []string) []byte {
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by cmd/gen. Entries are appended by `go run ./cmd/gen module <name>`, hand edits to the list are kept.\n\n")
+	buf.WriteString("package server\n\n")
+	if len(aliases) > 0 {
+		buf.WriteString("import (\n")
+		for _, alias := range aliases {
+			fmt.Fprintf(buf, "\t%s %q\n", alias, imports[alias])
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.WriteString("// Modules registered by the scaffolding generator\n")
+	buf.WriteString("func (s *Server) modules() []Module {\n")
+	if len(entries) == 0 {
+		buf.WriteString("\treturn []Module{}\n}\n")
+		return buf.Bytes()
+	}
+	buf.WriteString("\treturn []Module{\n")
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "\t\t%s,\n", entry)
+	}
+	buf.WriteString("\t}\n}\n")
+	return buf.Bytes()
+}
+
+// Write file creating parent dirs, go sources are gofmt'ed
+func writeFile(path string, content []byte) error {
+	if strings.HasSuffix(path, ".go") {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return errors.Wrapf(err, "format %s", path)
+		}
+		content = formatted
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "os.MkdirAll")
+	}
+	return errors.Wrap(os.WriteFile(path, content, 0o644), "os.WriteFile")
+}
+
+func readModulePath(root string) (string, error) {
+	file, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", errors.Wrap(err, "os.Open go.mod")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
+	}
+	return "", errors.New("module directive not found in go.mod")
+}
+
+// Next two digit migration prefix after the highest existing one
+func nextMigration(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "os.ReadDir")
+	}
+	highest := 0
+	for _, entry := range entries {
+		match := migrationNumber.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if n, _ := strconv.Atoi(match[1]); n > highest {
+			highest = n
+		}
+	}
+	return fmt.Sprintf("%02d", highest+1), nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/svc\n\ngo 1.22\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "migrations"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "migrations", "07_guest_files.up.sql"), nil, 0o644))
+	require.NoError(t, writeFile(filepath.Join(root, modulesFile), renderModules(map[string]string{}, nil)))
+
+	gen, err := newGenerator(root, "widgets", "")
+	require.NoError(t, err)
+	require.Equal(t, "Widget", gen.data.Entity)
+	require.Equal(t, "08", gen.data.Migration)
+
+	written, err := gen.Generate()
+	require.NoError(t, err)
+	require.Contains(t, written, "internal/widgets/delivery/http/module.go")
+	require.FileExists(t, filepath.Join(root, "migrations", "08_widgets.up.sql"))
+
+	src, err := os.ReadFile(filepath.Join(root, modulesFile))
+	require.NoError(t, err)
+	imports, entries, err := parseModules(src)
+	require.NoError(t, err)
+	require.Equal(t, "example.com/svc/internal/widgets/delivery/http", imports["widgetsHttp"])
+	require.Equal(t, []string{"widgetsHttp.NewModule(s.cfg, s.db, s.logger)"}, entries)
+
+	_, err = gen.Generate()
+	require.Error(t, err)
+
+	_, err = newGenerator(root, "Bad-Name", "")
+	require.Error(t, err)
+}
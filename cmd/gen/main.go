@@ -0,0 +1,144 @@
+// Code generator. `go run ./cmd/gen module <name>` creates a bounded context shaped like the auth and files modules,
+// `go run ./cmd/gen decorator -interface <name> usecase.go` writes the observed decorator of a module usecase
+// and the caching one when its methods carry cache: annotations,
+// `go run ./cmd/gen sdk` writes Go and TypeScript clients of the swagger document
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "module":
+		runModule(os.Args[2:])
+	case "decorator":
+		runDecorator(os.Args[2:])
+	case "sdk":
+		runSDK(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gen module [flags] <name>")
+	fmt.Fprintln(os.Stderr, "       gen decorator [flags] <usecase.go>")
+	fmt.Fprintln(os.Stderr, "       gen sdk [flags]")
+	os.Exit(2)
+}
+
+func runModule(args []string) {
+	flags := flag.NewFlagSet("gen module", flag.ExitOnError)
+	root := flags.String("root", ".", "repository root containing go.mod")
+	entity := flags.String("entity", "", "model name, defaults to the module name without a trailing s")
+	noMocks := flags.Bool("no-mocks", false, "skip mockgen for the generated interfaces")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gen module [flags] <name>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen, err := newGenerator(*root, flags.Arg(0), *entity)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	written, err := gen.Generate()
+	for _, path := range written {
+		fmt.Println("created", path)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	moduleDir := filepath.Join(*root, "internal", gen.data.Package)
+	decorator, err := newDecoratorGenerator(filepath.Join(moduleDir, "usecase.go"), "UseCase", "")
+	if err == nil {
+		err = decorator.Generate()
+	}
+	if err != nil {
+		log.Printf("decorator: %v, run `go generate ./internal/%s/...` once fixed", err, gen.data.Package)
+	} else {
+		fmt.Println("created", decorator.out)
+	}
+
+	if !*noMocks {
+		for _, source := range []string{"pg_repository.go", "usecase.go"} {
+			cmd := exec.Command("go", "run", "github.com/golang/mock/mockgen",
+				"-source", source, "-destination", filepath.Join("mock", source[:len(source)-3]+"_mock.go"), "-package", "mock")
+			cmd.Dir = moduleDir
+			cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+			if err := cmd.Run(); err != nil {
+				log.Printf("mockgen %s: %v, run `go generate ./internal/%s/...` once fixed", source, err, gen.data.Package)
+			}
+		}
+	}
+
+	fmt.Printf("module %s registered in internal/server/modules_gen.go, run `make sqlc` and `make migrate_up` for migration %s\n",
+		gen.data.Package, gen.data.Migration)
+}
+
+func runDecorator(args []string) {
+	flags := flag.NewFlagSet("gen decorator", flag.ExitOnError)
+	iface := flags.String("interface", "UseCase", "usecase interface to decorate")
+	out := flags.String("out", "", "generated file, defaults to usecase/observed_gen.go next to the source")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gen decorator [flags] <usecase.go>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen, err := newDecoratorGenerator(flags.Arg(0), *iface, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := gen.Generate(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runSDK(args []string) {
+	flags := flag.NewFlagSet("gen sdk", flag.ExitOnError)
+	spec := flags.String("spec", "docs/swagger.json", "swagger document written by `make swaggo`")
+	pkg := flags.String("package", "sdk", "package name of the Go client")
+	outGo := flags.String("go", "sdk/go/client_gen.go", "Go client file, empty to skip")
+	outTS := flags.String("ts", "sdk/typescript/client.ts", "TypeScript client file, empty to skip")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gen sdk [flags]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen, err := newSDKGenerator(*spec, *pkg, *outGo, *outTS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	written, err := gen.Generate()
+	for _, path := range written {
+		fmt.Println("created", path)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
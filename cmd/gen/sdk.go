@@ -0,0 +1,588 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Kept apart from the module templates, those are all parsed together
+//
+//go:embed templates/sdk/*.tmpl
+var sdkTemplatesFS embed.FS
+
+// Spec paths are relative to the API base path of the swag annotations
+const defaultSDKBasePath = "/api/v1"
+
+var (
+	nonAlnumRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	// Initialisms kept upper case in Go names, as golint wants them
+	goInitialisms = map[string]bool{"API": true, "CSRF": true, "HTTP": true, "ID": true, "IP": true, "JWT": true,
+		"OTP": true, "SLO": true, "SMS": true, "URL": true, "UUID": true}
+	httpMethods = []string{"get", "post", "put", "patch", "delete"}
+	// Names the generated methods declare themselves
+	sdkLocals = map[string]bool{"c": true, "ctx": true, "body": true, "params": true, "query": true, "payload": true,
+		"contentType": true, "err": true, "out": true, "v": true}
+)
+
+// Subset of a Swagger 2.0 document produced by swag
+type swaggerSpec struct {
+	BasePath    string                          `json:"basePath"`
+	Paths       map[string]map[string]swaggerOp `json:"paths"`
+	Definitions map[string]*swaggerSchema       `json:"definitions"`
+}
+
+type swaggerOp struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []swaggerParam             `json:"parameters"`
+	Responses   map[string]swaggerResponse `json:"responses"`
+}
+
+type swaggerParam struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Type     string         `json:"type"`
+	Format   string         `json:"format"`
+	Required bool           `json:"required"`
+	Items    *swaggerSchema `json:"items"`
+	Schema   *swaggerSchema `json:"schema"`
+}
+
+type swaggerResponse struct {
+	Schema *swaggerSchema `json:"schema"`
+}
+
+type swaggerSchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	Format               string                    `json:"format"`
+	Description          string                    `json:"description"`
+	Required             []string                  `json:"required"`
+	Properties           map[string]*swaggerSchema `json:"properties"`
+	Items                *swaggerSchema            `json:"items"`
+	AdditionalProperties *swaggerSchema            `json:"additionalProperties"`
+	AllOf                []*swaggerSchema          `json:"allOf"`
+}
+
+// Template data of one generated client, types are rendered per language by the template funcs
+type sdkData struct {
+	Source   string
+	Package  string
+	BasePath string
+	Models   []sdkModel
+	Ops      []sdkOperation
+	// Some Go field or parameter is a time.Time
+	UsesTime bool
+}
+
+type sdkModel struct {
+	Name        string
+	Description string
+	Fields      []sdkField
+}
+
+type sdkField struct {
+	JSON     string
+	GoName   string
+	Required bool
+	Schema   *swaggerSchema
+}
+
+type sdkOperation struct {
+	GoName   string
+	TSName   string
+	Method   string
+	Path     string
+	Summary  string
+	PathArgs []sdkParam
+	Query    []sdkParam
+	Form     []sdkParam
+	Body     *sdkParam
+	// Decoded success response, nil when the operation returns no document
+	Result *swaggerSchema
+}
+
+type sdkParam struct {
+	Name     string
+	GoName   string
+	TSName   string
+	Required bool
+	File     bool
+	Schema   *swaggerSchema
+}
+
+// SDK generator rendering clients of a swagger document
+type sdkGenerator struct {
+	spec    *swaggerSpec
+	source  string
+	pkg     string
+	names   map[string]string
+	outGo   string
+	outTS   string
+	written []string
+}
+
+// New SDK generator for the swagger document at source, outGo and outTS are skipped when empty
+func newSDKGenerator(source, pkg, outGo, outTS string) (*sdkGenerator, error) {
+	raw, err := os.ReadFile(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+	spec := &swaggerSpec{}
+	if err := json.Unmarshal(raw, spec); err != nil {
+		return nil, errors.Wrapf(err, "parse %s", source)
+	}
+	if spec.BasePath == "" {
+		spec.BasePath = defaultSDKBasePath
+	}
+
+	g := &sdkGenerator{spec: spec, source: filepath.ToSlash(source), pkg: pkg, outGo: outGo, outTS: outTS}
+	g.names = g.modelNames()
+	return g, nil
+}
+
+// Write the clients and return their paths
+func (g *sdkGenerator) Generate() ([]string, error) {
+	data, err := g.data()
+	if err != nil {
+		return nil, err
+	}
+
+	if g.outGo != "" {
+		if err := g.render("client.go.tmpl", g.outGo, data, true); err != nil {
+			return g.written, err
+		}
+	}
+	if g.outTS != "" {
+		if err := g.render("client.ts.tmpl", g.outTS, data, false); err != nil {
+			return g.written, err
+		}
+	}
+	return g.written, nil
+}
+
+func (g *sdkGenerator) render(name, out string, data *sdkData, gofmt bool) error {
+	tmpl, err := template.New(name).Funcs(g.funcs()).ParseFS(sdkTemplatesFS, "templates/sdk/"+name)
+	if err != nil {
+		return errors.Wrapf(err, "parse template %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "execute template %s", name)
+	}
+	content := buf.Bytes()
+	if gofmt {
+		if content, err = format.Source(content); err != nil {
+			return errors.Wrapf(err, "format %s", out)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return errors.Wrap(err, "os.MkdirAll")
+	}
+	if err := os.WriteFile(out, content, 0o644); err != nil {
+		return errors.Wrap(err, "os.WriteFile")
+	}
+	g.written = append(g.written, out)
+	return nil
+}
+
+// Client names of spec definitions, the package qualifier of swag is dropped unless two definitions collide
+func (g *sdkGenerator) modelNames() map[string]string {
+	keys := sortedKeys(g.spec.Definitions)
+	short := make(map[string]int, len(keys))
+	for _, key := range keys {
+		short[lastSegment(key)]++
+	}
+
+	names := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if short[lastSegment(key)] > 1 {
+			names[key] = goName(key)
+		} else {
+			names[key] = goName(lastSegment(key))
+		}
+	}
+	return names
+}
+
+func (g *sdkGenerator) data() (*sdkData, error) {
+	data := &sdkData{Source: g.source, Package: g.pkg, BasePath: strings.TrimRight(g.spec.BasePath, "/")}
+
+	for _, key := range sortedKeys(g.spec.Definitions) {
+		def := g.spec.Definitions[key]
+		model := sdkModel{Name: g.names[key], Description: def.Description}
+		required := make(map[string]bool, len(def.Required))
+		for _, name := range def.Required {
+			required[name] = true
+		}
+		for _, prop := range sortedKeys(def.Properties) {
+			model.Fields = append(model.Fields, sdkField{
+				JSON:     prop,
+				GoName:   goName(prop),
+				Required: required[prop],
+				Schema:   def.Properties[prop],
+			})
+		}
+		data.Models = append(data.Models, model)
+	}
+
+	seen := make(map[string]string)
+	for _, path := range sortedKeys(g.spec.Paths) {
+		for _, method := range httpMethods {
+			op, ok := g.spec.Paths[path][method]
+			if !ok {
+				continue
+			}
+			built, err := g.operation(method, path, op)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := seen[built.GoName]; ok {
+				return nil, errors.Errorf("operations %s and %s %s are both named %s, set an operationId", other, strings.ToUpper(method), path, built.GoName)
+			}
+			seen[built.GoName] = strings.ToUpper(method) + " " + path
+			data.Ops = append(data.Ops, built)
+		}
+	}
+	data.UsesTime = strings.Contains(g.spec.rawTypes(g.goType), "time.Time")
+	return data, nil
+}
+
+func (g *sdkGenerator) operation(method, path string, op swaggerOp) (sdkOperation, error) {
+	name := op.OperationID
+	if name == "" {
+		name = operationName(method, path)
+	}
+	built := sdkOperation{
+		GoName:  goName(name),
+		TSName:  lowerFirst(goName(name)),
+		Method:  strings.ToUpper(method),
+		Path:    path,
+		Summary: op.Summary,
+		Result:  successSchema(op.Responses),
+	}
+
+	for _, p := range op.Parameters {
+		param := sdkParam{
+			Name:     p.Name,
+			GoName:   goName(p.Name),
+			TSName:   lowerFirst(goName(p.Name)),
+			Required: p.Required || p.In == "path",
+			File:     p.Type == "file",
+			Schema:   p.Schema,
+		}
+		if param.Schema == nil {
+			param.Schema = &swaggerSchema{Type: p.Type, Format: p.Format, Items: p.Items}
+		}
+
+		switch p.In {
+		case "path":
+			if !strings.Contains(path, "{"+p.Name+"}") {
+				return built, errors.Errorf("%s %s: path parameter %s is not in the path", method, path, p.Name)
+			}
+			built.PathArgs = append(built.PathArgs, param)
+		case "query":
+			built.Query = append(built.Query, param)
+		case "formData":
+			built.Form = append(built.Form, param)
+		case "body":
+			body := param
+			built.Body = &body
+		}
+	}
+	if built.Body != nil && len(built.Form) > 0 {
+		return built, errors.Errorf("%s %s: body and form parameters together", method, path)
+	}
+	return built, nil
+}
+
+// Go types of every definition property and parameter joined, to find the imports they need
+func (s *swaggerSpec) rawTypes(goType func(*swaggerSchema) string) string {
+	var b strings.Builder
+	for _, def := range s.Definitions {
+		for _, prop := range def.Properties {
+			b.WriteString(goType(prop) + " ")
+		}
+	}
+	for _, ops := range s.Paths {
+		for _, op := range ops {
+			for _, p := range op.Parameters {
+				if p.Schema != nil {
+					b.WriteString(goType(p.Schema) + " ")
+				} else {
+					b.WriteString(goType(&swaggerSchema{Type: p.Type, Format: p.Format, Items: p.Items}) + " ")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// Schema of the first 2xx response with a json object or array, primitive bodies are not decoded
+func successSchema(responses map[string]swaggerResponse) *swaggerSchema {
+	for _, code := range sortedKeys(responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		schema := responses[code].Schema
+		if schema != nil && (schema.Ref != "" || schema.Type == "array" || schema.Type == "object" || len(schema.AllOf) > 0) {
+			return schema
+		}
+		return nil
+	}
+	return nil
+}
+
+func (g *sdkGenerator) funcs() template.FuncMap {
+	return template.FuncMap{
+		"goType":    g.goType,
+		"tsType":    g.tsType,
+		"goPath":    goPathExpr,
+		"tsPath":    tsPathExpr,
+		"tsOptions": tsOptions,
+		"lower":     strings.ToLower,
+		"goArg":     goArgName,
+		"zero":      g.goZero,
+		"quote":     func(s string) string { b, _ := json.Marshal(s); return string(b) },
+		"oneLine":   func(s string) string { return strings.Join(strings.Fields(s), " ") },
+		"isScalar":  isScalar,
+	}
+}
+
+// Go type of schema, optional references are pointers so absent objects stay nil
+func (g *sdkGenerator) goType(schema *swaggerSchema) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if schema.Ref != "" {
+		return "*" + g.names[refKey(schema.Ref)]
+	}
+	if len(schema.AllOf) > 0 {
+		return g.goType(schema.AllOf[0])
+	}
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "string":
+		if schema.Format == "date-time" {
+			return "time.Time"
+		}
+		return "string"
+	case "file":
+		return "io.Reader"
+	case "array":
+		return "[]" + g.goType(schema.Items)
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func (g *sdkGenerator) tsType(schema *swaggerSchema) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if schema.Ref != "" {
+		return g.names[refKey(schema.Ref)]
+	}
+	if len(schema.AllOf) > 0 {
+		return g.tsType(schema.AllOf[0])
+	}
+	switch schema.Type {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "string":
+		return "string"
+	case "file":
+		return "Blob"
+	case "array":
+		return g.tsType(schema.Items) + "[]"
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "Record<string, " + g.tsType(schema.AdditionalProperties) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+// Zero value of a scalar Go type, for leaving unset query parameters out
+func (g *sdkGenerator) goZero(schema *swaggerSchema) string {
+	switch g.goType(schema) {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "time.Time":
+		return "(time.Time{})"
+	case "interface{}", "io.Reader":
+		return "nil"
+	}
+	return "0"
+}
+
+// Parameter name of a generated method, renamed when it would shadow a keyword or a local of the method
+func goArgName(name string) string {
+	arg := lowerFirst(name)
+	if token.IsKeyword(arg) || sdkLocals[arg] {
+		return arg + "Arg"
+	}
+	return arg
+}
+
+func isScalar(schema *swaggerSchema) bool {
+	return schema != nil && schema.Ref == "" && schema.Type != "array" && schema.Type != "object"
+}
+
+// Go expression building the request path, path arguments are escaped
+func goPathExpr(op sdkOperation) string {
+	if len(op.PathArgs) == 0 {
+		return strconvQuote(op.Path)
+	}
+	format := op.Path
+	args := make([]string, 0, len(op.PathArgs))
+	for _, arg := range op.PathArgs {
+		format = strings.Replace(format, "{"+arg.Name+"}", "%s", 1)
+		args = append(args, "url.PathEscape(fmt.Sprint("+goArgName(arg.GoName)+"))")
+	}
+	return "fmt.Sprintf(" + strconvQuote(format) + ", " + strings.Join(args, ", ") + ")"
+}
+
+func tsPathExpr(op sdkOperation) string {
+	path := op.Path
+	for _, arg := range op.PathArgs {
+		path = strings.Replace(path, "{"+arg.Name+"}", "${encodeURIComponent(String("+arg.TSName+"))}", 1)
+	}
+	return "`" + path + "`"
+}
+
+// Request options argument of a TypeScript operation, empty without query, body or form
+func tsOptions(op sdkOperation) string {
+	options := make([]string, 0, 2)
+	if len(op.Query) > 0 {
+		options = append(options, "query: params")
+	}
+	if op.Body != nil {
+		options = append(options, "body")
+	}
+	if len(op.Form) > 0 {
+		options = append(options, "form: data")
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return ", { " + strings.Join(options, ", ") + " }"
+}
+
+func strconvQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// Operation name without an operationId, e.g. GET /auth/{id}/avatar is GetAuthByIDAvatar
+func operationName(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			b.WriteString("_by_" + strings.Trim(segment, "{}"))
+			continue
+		}
+		b.WriteString("_" + segment)
+	}
+	return b.String()
+}
+
+// Exported Go identifier of snake, kebab or dotted name
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if b.Len() == 0 || (b.String()[0] >= '0' && b.String()[0] <= '9') {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// Words of a name, camel case humps split too so userID and user_id agree
+func splitWords(name string) []string {
+	words := make([]string, 0)
+	for _, part := range nonAlnumRe.Split(name, -1) {
+		start := 0
+		for i := 1; i < len(part); i++ {
+			if part[i] >= 'A' && part[i] <= 'Z' && part[i-1] >= 'a' && part[i-1] <= 'z' {
+				words = append(words, part[start:i])
+				start = i
+			}
+		}
+		if start < len(part) {
+			words = append(words, part[start:])
+		}
+	}
+	return words
+}
+
+func lowerFirst(name string) string {
+	for i, r := range name {
+		if r < 'A' || r > 'Z' {
+			if i > 1 {
+				// Leading initialism, ID becomes id and IDToken idToken
+				return strings.ToLower(name[:i-1]) + name[i-1:]
+			}
+			return strings.ToLower(name[:i]) + name[i:]
+		}
+	}
+	return strings.ToLower(name)
+}
+
+func refKey(ref string) string {
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
+
+func lastSegment(key string) string {
+	return key[strings.LastIndex(key, ".")+1:]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
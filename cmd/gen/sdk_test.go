@@ -0,0 +1,157 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const widgetsSwagger = `{
+  "swagger": "2.0",
+  "basePath": "/api/v2",
+  "paths": {
+    "/widgets": {
+      "get": {
+        "summary": "List widgets",
+        "parameters": [
+          {"type": "integer", "name": "page", "in": "query"},
+          {"type": "array", "items": {"type": "string"}, "name": "tag", "in": "query"},
+          {"type": "string", "name": "type", "in": "query"}
+        ],
+        "responses": {"200": {"schema": {"type": "array", "items": {"$ref": "#/definitions/models.Widget"}}}}
+      },
+      "post": {
+        "operationId": "createWidget",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/dto.Widget"}}],
+        "responses": {"201": {"schema": {"$ref": "#/definitions/models.Widget"}}}
+      }
+    },
+    "/widgets/{widget_id}": {
+      "delete": {
+        "summary": "Delete widget",
+        "parameters": [{"type": "integer", "name": "widget_id", "in": "path", "required": true}],
+        "responses": {"200": {"schema": {"type": "string"}}}
+      }
+    },
+    "/widgets/{widget_id}/image": {
+      "post": {
+        "parameters": [
+          {"type": "integer", "name": "widget_id", "in": "path", "required": true},
+          {"type": "file", "name": "file", "in": "formData", "required": true},
+          {"type": "string", "name": "caption", "in": "formData"}
+        ],
+        "responses": {"200": {"schema": {"$ref": "#/definitions/models.Widget"}}}
+      }
+    }
+  },
+  "definitions": {
+    "dto.Widget": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string"}}
+    },
+    "models.Widget": {
+      "type": "object",
+      "description": "Widget with\n  its owner",
+      "properties": {
+        "id": {"type": "integer"},
+        "name": {"type": "string"},
+        "created_at": {"type": "string", "format": "date-time"},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+        "owner_id": {"type": "integer", "format": "int32"}
+      }
+    }
+  }
+}`
+
+func TestSDKGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "swagger.json")
+	require.NoError(t, os.WriteFile(spec, []byte(widgetsSwagger), 0o644))
+
+	outGo := filepath.Join(dir, "go", "client_gen.go")
+	outTS := filepath.Join(dir, "ts", "client.ts")
+	gen, err := newSDKGenerator(spec, "widgets", outGo, outTS)
+	require.NoError(t, err)
+	written, err := gen.Generate()
+	require.NoError(t, err)
+	require.Equal(t, []string{outGo, outTS}, written)
+
+	out, err := os.ReadFile(outGo)
+	require.NoError(t, err)
+	src := string(out)
+	require.Contains(t, src, "package widgets")
+	require.Contains(t, src, `const BasePath = "/api/v2"`)
+	// Colliding definitions keep their package qualifier
+	require.Contains(t, src, "type DtoWidget struct {\n\tName string `json:\"name\"`\n}")
+	require.Contains(t, src, "// ModelsWidget Widget with its owner")
+	require.Regexp(t, `CreatedAt\s+time\.Time\s+`+"`json:\"created_at,omitempty\"`", src)
+	require.Regexp(t, `Labels\s+map\[string\]string\s`, src)
+	require.Regexp(t, `OwnerID\s+int32\s`, src)
+	require.Contains(t, src, "func (c *Client) GetWidgets(ctx context.Context, params *GetWidgetsParams) ([]*ModelsWidget, error)")
+	require.Contains(t, src, "func (c *Client) CreateWidget(ctx context.Context, body *DtoWidget) (*ModelsWidget, error)")
+	require.Contains(t, src, "func (c *Client) DeleteWidgetsByWidgetID(ctx context.Context, widgetID int64) error")
+	require.Contains(t, src, "func (c *Client) PostWidgetsByWidgetIDImage(ctx context.Context, widgetID int64, file io.Reader, caption string) (*ModelsWidget, error)")
+	require.Contains(t, src, `query.Add("tag", fmt.Sprint(v))`)
+	require.Contains(t, src, `fmt.Sprintf("/widgets/%s", url.PathEscape(fmt.Sprint(widgetID)))`)
+
+	// Generated client only needs the standard library and has to type check
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, outGo, out, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("widgets", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	out, err = os.ReadFile(outTS)
+	require.NoError(t, err)
+	ts := string(out)
+	require.Contains(t, ts, "export interface DtoWidget {\n  \"name\": string;\n}")
+	require.Contains(t, ts, "\"labels\"?: Record<string, string>;")
+	require.Contains(t, ts, "async getWidgets(params: { \"page\"?: number; \"tag\"?: string[]; \"type\"?: string; } = {}): Promise<ModelsWidget[]>")
+	require.Contains(t, ts, "async createWidget(body: DtoWidget): Promise<ModelsWidget>")
+	require.Contains(t, ts, "return this.request('DELETE', `/widgets/${encodeURIComponent(String(widgetID))}`);")
+	require.Contains(t, ts, "form: { \"file\": Blob; \"caption\"?: string; }")
+}
+
+func TestSDKGenerator_NameCollision(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "swagger.json")
+	require.NoError(t, os.WriteFile(spec, []byte(`{"paths": {
+		"/a": {"get": {"operationId": "fetch", "responses": {}}},
+		"/b": {"get": {"operationId": "fetch", "responses": {}}}
+	}}`), 0o644))
+
+	gen, err := newSDKGenerator(spec, "sdk", filepath.Join(dir, "client.go"), "")
+	require.NoError(t, err)
+	_, err = gen.Generate()
+	require.ErrorContains(t, err, "both named Fetch")
+}
+
+func TestGoName(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"user_id":            "UserID",
+		"orderBy":            "OrderBy",
+		"models.UsersList":   "ModelsUsersList",
+		"get_auth_by_id_api": "GetAuthByIDAPI",
+		"2fa":                "X2fa",
+	} {
+		require.Equal(t, want, goName(in), in)
+	}
+	require.Equal(t, "typeArg", goArgName("Type"))
+	require.Equal(t, "widgetID", goArgName("WidgetID"))
+	require.Equal(t, "id", goArgName("ID"))
+}
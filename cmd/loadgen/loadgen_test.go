@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Fake of the auth routes the mix calls, sessions need the cookie and writes the CSRF token of the session
+func fakeAPI() *httptest.Server {
+	var mu sync.Mutex
+	registered := map[string]int{}
+	sessions := map[string]int{}
+
+	mux := http.NewServeMux()
+	user := func(w http.ResponseWriter, id int) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"user": map[string]interface{}{"id": id}})
+	}
+	session := func(r *http.Request) (string, int, bool) {
+		cookie, err := r.Cookie("session-id")
+		if err != nil {
+			return "", 0, false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		id, ok := sessions[cookie.Value]
+		return cookie.Value, id, ok
+	}
+
+	mux.HandleFunc("/api/v1/auth/register", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := registered[body["username"]]; ok {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"status":409,"error":"User with given email already exists","code":"email_already_registered"}`))
+			return
+		}
+		registered[body["username"]] = len(registered) + 1
+		w.WriteHeader(http.StatusCreated)
+		user(w, registered[body["username"]])
+	})
+	mux.HandleFunc("/api/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		id := registered[body["username"]]
+		sid := body["username"] + time.Now().String()
+		sessions[sid] = id
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "session-id", Value: sid, Path: "/"})
+		user(w, id)
+	})
+	mux.HandleFunc("/api/v1/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		sid, _, ok := session(r)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set(CSRFHeader, "csrf-"+sid)
+	})
+	mux.HandleFunc("/api/v1/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		if _, id, ok := session(r); ok {
+			user(w, id)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/api/v1/auth/find", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "lt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"total_count":0,"users":[]}`))
+	})
+	mux.HandleFunc("/api/v1/auth/", func(w http.ResponseWriter, r *http.Request) {
+		sid, id, ok := session(r)
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method != http.MethodPut || r.Header.Get(CSRFHeader) != "csrf-"+sid:
+			w.WriteHeader(http.StatusForbidden)
+		case strings.TrimPrefix(r.URL.Path, "/api/v1/auth/") != strconv.Itoa(id):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			user(w, id)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	server := fakeAPI()
+	defer server.Close()
+
+	mix, err := parseMix(defaultMix)
+	require.NoError(t, err)
+	opts := options{BaseURL: server.URL, Users: 3, Duration: 200 * time.Millisecond, Timeout: time.Second, Mix: mix, Prefix: "lt", Password: "pw"}
+
+	// The second run takes over the users of the first
+	for i := 0; i < 2; i++ {
+		report, err := run(context.Background(), opts)
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Users)
+		require.Zero(t, report.Failed, report.Ops)
+
+		ops := map[string]OpStats{}
+		for _, op := range report.Ops {
+			ops[op.Op] = op
+		}
+		for _, op := range []string{opLogin, opMe, opSearch, opUpdate, opCSRFToken} {
+			require.NotZero(t, ops[op].Requests, op)
+		}
+		require.NotContains(t, ops, opRegister)
+		require.Equal(t, report.Requests, ops[opLogin].Requests+ops[opMe].Requests+ops[opSearch].Requests+
+			ops[opUpdate].Requests+ops[opCSRFToken].Requests)
+		require.LessOrEqual(t, ops[opMe].P50, ops[opMe].Max)
+	}
+
+	// A paced run sends about rate requests per second
+	opts.Rate = 50
+	report, err := run(context.Background(), opts)
+	require.NoError(t, err)
+	require.LessOrEqual(t, report.Requests, 20)
+}
+
+func TestParseMix(t *testing.T) {
+	t.Parallel()
+
+	mix, err := parseMix("me=3, search=0,update=1")
+	require.NoError(t, err)
+	require.Equal(t, []weightedOp{{Name: opMe, Weight: 3}, {Name: opUpdate, Weight: 1}}, mix)
+
+	for _, broken := range []string{"", "me", "me=x", "me=-1", "me=1,me=2", "delete=1", "me=0"} {
+		_, err := parseMix(broken)
+		require.Error(t, err, broken)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	require.Equal(t, 100*time.Millisecond, percentile(latencies, 100))
+	require.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+	require.Zero(t, percentile(nil, 50))
+}
@@ -0,0 +1,65 @@
+// Load generator, `go run ./cmd/loadgen -url http://localhost:5000 -users 20 -duration 1m` signs up virtual users
+// and drives a weighted mix of login, me, search and update requests through the generated API client against a
+// running instance, then prints request counts, errors and latency percentiles per operation.
+// Login and register are rate limited per IP, raise their RateLimit on the target or the mix measures 429s.
+package main
+
+//go:generate go run ../gen sdk -spec ../../docs/swagger.json -package main -go client_gen.go -ts ""
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	flags := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	opts := options{}
+	flags.StringVar(&opts.BaseURL, "url", "http://localhost:5000", "scheme and host of the instance under load")
+	flags.IntVar(&opts.Users, "users", 10, "virtual users running the mix concurrently")
+	flags.DurationVar(&opts.Duration, "duration", 30*time.Second, "time the mix runs after sign up")
+	flags.Float64Var(&opts.Rate, "rate", 0, "requests per second across all users, 0 sends the next request once one returns")
+	flags.DurationVar(&opts.Timeout, "timeout", 10*time.Second, "timeout of one request")
+	mix := flags.String("mix", defaultMix, "weights of the operations, comma separated name=weight of "+opNames())
+	flags.StringVar(&opts.Prefix, "prefix", "loadgen", "username prefix of the virtual users, runs with the same prefix reuse them")
+	flags.StringVar(&opts.Password, "password", "loadgen-password", "password of the virtual users")
+	asJSON := flags.Bool("json", false, "print the report as json")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: loadgen [flags]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(os.Args[1:]); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	weights, err := parseMix(*mix)
+	if err != nil {
+		log.Fatalf("mix: %v", err)
+	}
+	opts.Mix = weights
+	if opts.Users < 1 || opts.Duration <= 0 || opts.Rate < 0 {
+		log.Fatal("users and duration must be positive, rate must not be negative")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = opts.Users
+	opts.Transport = transport
+
+	report, err := run(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *asJSON {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
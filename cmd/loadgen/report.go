@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Error label of requests without a response
+const errTransport = "transport"
+
+// Latencies and errors per operation, shared by the virtual users
+type recorder struct {
+	mu        sync.Mutex
+	started   time.Time
+	latencies map[string][]time.Duration
+	errors    map[string]map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: map[string][]time.Duration{}, errors: map[string]map[string]int{}}
+}
+
+// Drop what sign up recorded, the report covers the mix from now on
+func (r *recorder) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started = time.Now()
+	r.latencies = map[string][]time.Duration{}
+	r.errors = map[string]map[string]int{}
+}
+
+// Record a request of op, API errors are counted by status
+func (r *recorder) Record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies[op] = append(r.latencies[op], latency)
+	if err == nil {
+		return
+	}
+	label := errTransport
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		label = strconv.Itoa(apiErr.StatusCode)
+	}
+	if r.errors[op] == nil {
+		r.errors[op] = map[string]int{}
+	}
+	r.errors[op][label]++
+}
+
+// Report of the requests recorded since Start
+func (r *recorder) Report(users int) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := time.Since(r.started)
+	report := &Report{Users: users, Seconds: elapsed.Seconds()}
+	ops := make([]string, 0, len(r.latencies))
+	for op := range r.latencies {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	for _, op := range ops {
+		latencies := append([]time.Duration(nil), r.latencies[op]...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats := OpStats{
+			Op:       op,
+			Requests: len(latencies),
+			Errors:   r.errors[op],
+			RPS:      float64(len(latencies)) / elapsed.Seconds(),
+			P50:      millis(percentile(latencies, 50)),
+			P90:      millis(percentile(latencies, 90)),
+			P99:      millis(percentile(latencies, 99)),
+			Max:      millis(latencies[len(latencies)-1]),
+		}
+		for _, n := range stats.Errors {
+			stats.Failed += n
+		}
+		report.Requests += stats.Requests
+		report.Failed += stats.Failed
+		report.Ops = append(report.Ops, stats)
+	}
+	return report
+}
+
+// Nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Result of a run, latencies are in milliseconds
+type Report struct {
+	Users    int       `json:"users"`
+	Seconds  float64   `json:"seconds"`
+	Requests int       `json:"requests"`
+	Failed   int       `json:"failed"`
+	Ops      []OpStats `json:"ops"`
+}
+
+// Requests of one operation, errors by status or transport
+type OpStats struct {
+	Op       string         `json:"op"`
+	Requests int            `json:"requests"`
+	Failed   int            `json:"failed"`
+	Errors   map[string]int `json:"errors,omitempty"`
+	RPS      float64        `json:"rps"`
+	P50      float64        `json:"p50_ms"`
+	P90      float64        `json:"p90_ms"`
+	P99      float64        `json:"p99_ms"`
+	Max      float64        `json:"max_ms"`
+}
+
+// Write the report as json
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Write the report as an aligned table, one row per operation
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%d users, %d requests in %.1fs, %d failed\n\n", r.Users, r.Requests, r.Seconds, r.Failed)
+	fmt.Fprintln(tw, "op\trequests\tfailed\trps\tp50 ms\tp90 ms\tp99 ms\tmax ms\terrors\t")
+	for _, op := range r.Ops {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%s\t\n",
+			op.Op, op.Requests, op.Failed, op.RPS, op.P50, op.P90, op.P99, op.Max, formatErrors(op.Errors))
+	}
+	return tw.Flush()
+}
+
+func formatErrors(errs map[string]int) string {
+	labels := make([]string, 0, len(errs))
+	for label := range errs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	formatted := "-"
+	for i, label := range labels {
+		if i == 0 {
+			formatted = ""
+		} else {
+			formatted += " "
+		}
+		formatted += fmt.Sprintf("%s:%d", label, errs[label])
+	}
+	return formatted
+}
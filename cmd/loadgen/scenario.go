@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/cookiejar"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations of the mix, register and csrf_token are only sent when a user signs up or logs in
+const (
+	opLogin     = "login"
+	opMe        = "me"
+	opSearch    = "search"
+	opUpdate    = "update"
+	opRegister  = "register"
+	opCSRFToken = "csrf_token"
+)
+
+// Reads dominate like in production, logins renew sessions and updates write the profile
+const defaultMix = "login=1,me=6,search=2,update=1"
+
+// Timezone updates send, an unchanged value does not count against the change limits of the field
+const loadgenTimezone = "UTC"
+
+var mixOps = map[string]func(u *virtualUser, ctx context.Context) error{
+	opLogin:  (*virtualUser).login,
+	opMe:     (*virtualUser).me,
+	opSearch: (*virtualUser).search,
+	opUpdate: (*virtualUser).update,
+}
+
+type options struct {
+	BaseURL   string
+	Users     int
+	Duration  time.Duration
+	Rate      float64
+	Timeout   time.Duration
+	Mix       []weightedOp
+	Prefix    string
+	Password  string
+	Transport http.RoundTripper
+}
+
+type weightedOp struct {
+	Name   string
+	Weight int
+}
+
+func opNames() string {
+	names := make([]string, 0, len(mixOps))
+	for name := range mixOps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Mix of name=weight pairs, operations left out are not sent
+func parseMix(mix string) ([]weightedOp, error) {
+	var ops []weightedOp
+	seen := map[string]bool{}
+	for _, pair := range strings.Split(mix, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not name=weight", pair)
+		}
+		if _, known := mixOps[name]; !known {
+			return nil, fmt.Errorf("unknown operation %q, one of %s", name, opNames())
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("operation %q is listed twice", name)
+		}
+		seen[name] = true
+		n, err := strconv.Atoi(weight)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("weight of %q is not a non-negative integer", name)
+		}
+		if n > 0 {
+			ops = append(ops, weightedOp{Name: name, Weight: n})
+		}
+	}
+	if len(ops) == 0 {
+		return nil, errors.New("no operation has a weight")
+	}
+	return ops, nil
+}
+
+func pickOp(ops []weightedOp, rnd *rand.Rand) string {
+	total := 0
+	for _, op := range ops {
+		total += op.Weight
+	}
+	roll := rnd.Intn(total)
+	for _, op := range ops {
+		if roll -= op.Weight; roll < 0 {
+			return op.Name
+		}
+	}
+	return ops[len(ops)-1].Name
+}
+
+// Sign up and log in every virtual user, then run the mix until the duration is over
+func run(ctx context.Context, opts options) (*Report, error) {
+	recorder := newRecorder()
+	users := make([]*virtualUser, opts.Users)
+	for i := range users {
+		users[i] = newVirtualUser(opts, i, recorder)
+	}
+
+	var wg sync.WaitGroup
+	ready := make(chan *virtualUser, len(users))
+	for _, u := range users {
+		wg.Add(1)
+		go func(u *virtualUser) {
+			defer wg.Done()
+			if err := u.signUp(ctx); err != nil {
+				log.Printf("user %s: %v", u.username, err)
+				return
+			}
+			ready <- u
+		}(u)
+	}
+	wg.Wait()
+	close(ready)
+	if len(ready) == 0 {
+		return nil, errors.New("no virtual user could sign up and log in")
+	}
+
+	// Sign up is not part of the measured mix
+	recorder.Start()
+	runCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var ticks <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for u := range ready {
+		wg.Add(1)
+		go func(u *virtualUser) {
+			defer wg.Done()
+			u.runMix(runCtx, opts.Mix, ticks)
+		}(u)
+	}
+	wg.Wait()
+	return recorder.Report(opts.Users), nil
+}
+
+// Virtual user, one goroutine drives it with a session of its own
+type virtualUser struct {
+	client   *Client
+	recorder *recorder
+	rnd      *rand.Rand
+	timeout  time.Duration
+	username string
+	email    string
+	password string
+	prefix   string
+	// Path segment of the user, encoded when the target encodes ids
+	id        string
+	csrfToken string
+}
+
+func newVirtualUser(opts options, n int, rec *recorder) *virtualUser {
+	u := &virtualUser{
+		recorder: rec,
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano() + int64(n))),
+		timeout:  opts.Timeout,
+		username: fmt.Sprintf("%s_%d", opts.Prefix, n),
+		email:    fmt.Sprintf("%s_%d@loadgen.example.com", opts.Prefix, n),
+		password: opts.Password,
+		prefix:   opts.Prefix,
+	}
+	jar, _ := cookiejar.New(nil) // nolint: errcheck
+	u.client = NewClient(opts.BaseURL, u)
+	u.client.HTTPClient = &http.Client{Jar: jar, Transport: &csrfTransport{next: opts.Transport, user: u}}
+	u.client.UserAgent = "loadgen"
+	return u
+}
+
+// Apply sends the CSRF token of the session with writes, the cookie jar sends the session cookie
+func (u *virtualUser) Apply(req *http.Request) {
+	if u.csrfToken != "" && req.Method != http.MethodGet {
+		req.Header.Set(CSRFHeader, u.csrfToken)
+	}
+}
+
+// Keeps the CSRF token GET /auth/token returns in a header the generated client does not expose
+type csrfTransport struct {
+	next http.RoundTripper
+	user *virtualUser
+}
+
+func (t *csrfTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	res, err := next.RoundTrip(req)
+	if err == nil {
+		if token := res.Header.Get(CSRFHeader); token != "" {
+			t.user.csrfToken = token
+		}
+	}
+	return res, err
+}
+
+// Register the user, users of an earlier run with the same prefix are taken over, then log in
+func (u *virtualUser) signUp(ctx context.Context) error {
+	err := u.timed(ctx, opRegister, func(ctx context.Context) error {
+		body := map[string]string{"username": u.username, "email": u.email, "password": u.password}
+		return u.send(ctx, http.MethodPost, "/auth/register", body)
+	})
+	var apiErr *APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict) {
+		return fmt.Errorf("register: %w", err)
+	}
+	if err := u.login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	return nil
+}
+
+func (u *virtualUser) runMix(ctx context.Context, mix []weightedOp, ticks <-chan time.Time) {
+	for {
+		if ticks != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		// Errors are counted by the recorder, the user goes on like a client retrying later
+		_ = mixOps[pickOp(mix, u.rnd)](u, ctx)
+	}
+}
+
+// Login starts a new session, its CSRF token is fetched right after like browsers do
+func (u *virtualUser) login(ctx context.Context) error {
+	err := u.timed(ctx, opLogin, func(ctx context.Context) error {
+		return u.send(ctx, http.MethodPost, "/auth/login", map[string]string{"username": u.username, "password": u.password})
+	})
+	if err != nil {
+		return err
+	}
+	return u.timed(ctx, opCSRFToken, u.client.GetAuthToken)
+}
+
+func (u *virtualUser) me(ctx context.Context) error {
+	return u.timed(ctx, opMe, func(ctx context.Context) error {
+		_, err := u.client.GetAuthMe(ctx)
+		return err
+	})
+}
+
+// Search matches every virtual user of the prefix
+func (u *virtualUser) search(ctx context.Context) error {
+	return u.timed(ctx, opSearch, func(ctx context.Context) error {
+		_, err := u.client.GetAuthFind(ctx, &GetAuthFindParams{Name: u.prefix})
+		return err
+	})
+}
+
+func (u *virtualUser) update(ctx context.Context) error {
+	return u.timed(ctx, opUpdate, func(ctx context.Context) error {
+		return u.send(ctx, http.MethodPut, "/auth/"+u.id, map[string]string{"timezone": loadgenTimezone})
+	})
+}
+
+// Json request through the generated client. The swagger document has no bodies for register, login and update,
+// so their generated methods can't send one. The id of the user is kept from the response
+func (u *virtualUser) send(ctx context.Context, method, path string, body interface{}) error {
+	payload, contentType, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	var out struct {
+		User *struct {
+			ID json.RawMessage `json:"id"`
+		} `json:"user"`
+	}
+	if err := u.client.do(ctx, method, path, nil, payload, contentType, &out); err != nil {
+		return err
+	}
+	if out.User != nil && len(out.User.ID) > 0 {
+		u.id = strings.Trim(string(out.User.ID), `"`)
+	}
+	return nil
+}
+
+// Run call with the request timeout and record its latency, calls cut by the end of the run are not recorded
+func (u *virtualUser) timed(ctx context.Context, op string, call func(ctx context.Context) error) error {
+	callCtx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(callCtx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	u.recorder.Record(op, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,68 @@
+// PII key rotation, `go run ./cmd/pii rotate` encrypts plaintext user rows and re-encrypts rows on retired keys.
+// Add the new key to the keys secret, switch PII.ActiveKey to it, deploy, then run rotate before removing the old key.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func main() {
+	flags := flag.NewFlagSet("pii rotate", flag.ExitOnError)
+	batchSize := flags.Int("batch", 500, "users per batch")
+	dryRun := flags.Bool("dry-run", false, "only count rows needing rotation")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: pii rotate [flags]")
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) < 2 || os.Args[1] != "rotate" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := flags.Parse(os.Args[2:]); err != nil || *batchSize <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfgFile, err := config.LoadConfig(utils.GetConfigPath(os.Getenv("config")))
+	if err != nil {
+		log.Fatalf("LoadConfig: %v", err)
+	}
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("ParseConfig: %v", err)
+	}
+
+	ctx := context.Background()
+	cipher, err := pii.NewFromConfig(ctx, cfg)
+	if err != nil {
+		log.Fatalf("pii.NewFromConfig: %v", err)
+	}
+
+	db, err := postgres.NewPsqlDB(cfg)
+	if err != nil {
+		log.Fatalf("Postgresql init: %v", err)
+	}
+	defer db.Close()
+
+	rotator, err := repository.NewPIIRotator(db, cipher)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stats, err := rotator.Run(ctx, *batchSize, *dryRun)
+	if err != nil {
+		log.Fatalf("rotate after %d users: %v", stats.Scanned, err)
+	}
+	fmt.Printf("scanned %d users, rotated %d, dry run %v\n", stats.Scanned, stats.Rotated, stats.DryRun)
+}
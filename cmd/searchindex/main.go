@@ -0,0 +1,94 @@
+// User search index maintenance. `go run ./cmd/searchindex check` reports the pg_trgm extension, the trigger
+// keeping the index in step and the GIN index, and counts users missing from it. Exits 1 when a problem is found.
+// `go run ./cmd/searchindex rebuild` rewrites missing and stale index rows, with -reindex it also rebuilds the
+// GIN index concurrently.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func main() {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "usage: searchindex check [-json] | rebuild [-batch n] [-reindex]")
+	}
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("searchindex "+os.Args[1], flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the check result as json")
+	batchSize := flags.Int("batch", 5000, "user ids per rebuild batch")
+	reindex := flags.Bool("reindex", false, "rebuild the trigram index after the rows")
+	flags.Usage = func() {
+		usage()
+		flags.PrintDefaults()
+	}
+	if os.Args[1] != "check" && os.Args[1] != "rebuild" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := flags.Parse(os.Args[2:]); err != nil || *batchSize <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfgFile, err := config.LoadConfig(utils.GetConfigPath(os.Getenv("config")))
+	if err != nil {
+		log.Fatalf("LoadConfig: %v", err)
+	}
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("ParseConfig: %v", err)
+	}
+
+	db, err := postgres.NewPsqlDB(cfg)
+	if err != nil {
+		log.Fatalf("Postgresql init: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	index := repository.NewUserSearchIndex(db)
+
+	if os.Args[1] == "rebuild" {
+		stats, err := index.Rebuild(ctx, *batchSize, *reindex)
+		if err != nil {
+			log.Fatalf("rebuild after %d batches: %v", stats.Batches, err)
+		}
+		fmt.Printf("rebuilt %d batches, wrote %d rows, reindexed %v\n", stats.Batches, stats.Written, stats.Reindexed)
+		return
+	}
+
+	health, err := index.Health(ctx, true)
+	if err != nil {
+		log.Fatalf("check: %v", err)
+	}
+	if *asJSON {
+		out, err := json.MarshalIndent(health, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, problem := range health.Problems {
+			fmt.Println(problem)
+		}
+		fmt.Printf("extension %v, trigger %v, index valid %v, %d stale rows\n",
+			health.ExtensionInstalled, health.TriggerEnabled, health.IndexValid, health.Stale)
+	}
+	if !health.Healthy() {
+		os.Exit(1)
+	}
+}
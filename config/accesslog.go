@@ -0,0 +1,50 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Formats of the access log
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogW3C      = "w3c"
+)
+
+// Access log in Common, Combined or W3C extended log format for compliance tooling, written to Dir apart from
+// the structured application logs. The file is rotated every hour and once it grows past MaxMegabytes, the newest
+// MaxFiles rotated files are kept, 0 keeps them all. With Upload rotated files are put into Bucket of the blob
+// store under Prefix every UploadIntervalSeconds and removed once stored
+type AccessLog struct {
+	Enabled               bool
+	Format                string
+	Dir                   string
+	MaxMegabytes          int
+	MaxFiles              int
+	Upload                bool
+	Bucket                string
+	Prefix                string
+	UploadIntervalSeconds int
+}
+
+// Check the format is known and an upload has somewhere to go
+func (a AccessLog) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	switch a.Format {
+	case AccessLogCommon, AccessLogCombined, AccessLogW3C:
+	default:
+		return errors.Errorf("accessLog: unknown Format %q, one of %s, %s or %s", a.Format, AccessLogCommon, AccessLogCombined, AccessLogW3C)
+	}
+	if a.Dir == "" {
+		return errors.New("accessLog: Dir is required")
+	}
+	if a.MaxMegabytes < 0 || a.MaxFiles < 0 {
+		return errors.New("accessLog: negative MaxMegabytes or MaxFiles")
+	}
+	if a.Upload && (a.Bucket == "" || a.UploadIntervalSeconds < 1) {
+		return errors.New("accessLog: Upload needs a Bucket and a positive UploadIntervalSeconds")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, AccessLog{Format: "nginx"}.Validate())
+
+	accessLog := AccessLog{Enabled: true, Format: AccessLogW3C, Dir: "logs/access", MaxMegabytes: 100, MaxFiles: 24}
+	require.NoError(t, accessLog.Validate())
+
+	accessLog.Upload = true
+	require.Error(t, accessLog.Validate())
+	accessLog.Bucket, accessLog.UploadIntervalSeconds = "access-logs", 3600
+	require.NoError(t, accessLog.Validate())
+
+	accessLog.Format = "nginx"
+	require.Error(t, accessLog.Validate())
+	accessLog.Format, accessLog.Dir = AccessLogCommon, ""
+	require.Error(t, accessLog.Validate())
+	accessLog.Dir, accessLog.MaxFiles = "logs/access", -1
+	require.Error(t, accessLog.Validate())
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Variant name of the handler a route was registered with, every request not sent to a variant gets it
+const CanaryStable = "stable"
+
+// Canary releases of alternate handler implementations registered next to the stable handler of a route.
+// Routes maps route names to the percentage of requests each variant gets, the rest stay on the stable
+// handler. Requests naming a variant or stable in the Header header or the Cookie cookie get it regardless
+// of the percentages, so testers can pin themselves to one
+type Canary struct {
+	Enabled bool
+	Header  string
+	Cookie  string
+	Routes  map[string]map[string]float64
+}
+
+// Check percentages are within 0 and 100 and those of a route leave the rest to the stable handler
+func (c Canary) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	for _, route := range sortedKeys(c.Routes) {
+		var total float64
+		for _, variant := range sortedKeys(c.Routes[route]) {
+			percentage := c.Routes[route][variant]
+			if variant == CanaryStable {
+				return errors.Errorf("canary: Routes.%s can't name a variant %s", route, CanaryStable)
+			}
+			if percentage < 0 || percentage > 100 {
+				return errors.Errorf("canary: Routes.%s.%s percentage %v is not within 0 and 100", route, variant, percentage)
+			}
+			total += percentage
+		}
+		if total > 100 {
+			return errors.Errorf("canary: Routes.%s percentages add up to %v, over 100", route, total)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanary_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Canary{Routes: map[string]map[string]float64{"search": {"v2": 150}}}.Validate())
+
+	canary := Canary{Enabled: true, Routes: map[string]map[string]float64{"search": {"v2": 10, "v3": 5.5}}}
+	require.NoError(t, canary.Validate())
+
+	canary.Routes["search"]["v3"] = 95
+	require.Error(t, canary.Validate())
+	canary.Routes["search"] = map[string]float64{"v2": -1}
+	require.Error(t, canary.Validate())
+	canary.Routes["search"] = map[string]float64{CanaryStable: 10}
+	require.Error(t, canary.Validate())
+}
@@ -10,17 +10,76 @@ import (
 
 // App config struct
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	MongoDB  MongoDB
-	Cookie   Cookie
-	Store    Store
-	Session  Session
-	Metrics  Metrics
-	Logger   Logger
-	AWS      AWS
-	Jaeger   Jaeger
+	Server        ServerConfig
+	Postgres      PostgresConfig
+	Redis         RedisConfig
+	MongoDB       MongoDB
+	Cookie        Cookie
+	Store         Store
+	Session       Session
+	Metrics       Metrics
+	Logger        Logger
+	AWS           AWS
+	Storage       Storage
+	Jaeger        Jaeger
+	ChangeFeed    ChangeFeed
+	ACME          ACME
+	RateLimit     RateLimit
+	IPFilter      IPFilter
+	Files         Files
+	Scanner       Scanner
+	JobQueue      JobQueue
+	Services      map[string]Service
+	Clock         Clock
+	Dedup         Dedup
+	Secrets       Secrets
+	PII           PII
+	Anonymize     Anonymize
+	Replay        Replay
+	AuditChain    AuditChain
+	SMS           SMS
+	OTP           OTP
+	Risk          Risk
+	Organizations Organizations
+	Settings      RuntimeSettings
+	Presence      Presence
+	ReadModel     ReadModel
+	Registration  Registration
+	Rotation      PasswordRotation
+	Cache         Cache
+	JWTIssuers    map[string]JWTIssuer
+	Bearer        BearerAuth
+	Scheduler     Scheduler
+	Deletion      Deletion
+	FieldChanges  FieldChanges
+	Compaction    Compaction
+	UserBatch     UserBatch
+	Contacts      Contacts
+	Webhooks      Webhooks
+	SLO           SLO
+	ScopedTokens  ScopedTokens
+	AccessTokens  AccessTokens
+	Billing       Billing
+	RequestSchema RequestSchema
+	Access        Access
+	Security      Security
+	Pagination    Pagination
+	Deprecation   Deprecation
+	Problems      Problems
+	JSONAPI       JSONAPI
+	IDs           IDs
+	Remember      Remember
+	Observe       Observe
+	EmailPolicy   EmailPolicy
+	HRSync        HRSync
+	Shadow        Shadow
+	Replicas      Replicas
+	Dev           Dev
+	Fakes         Fakes
+	Exposure      Exposure
+	Tenancy       Tenancy
+	Canary        Canary
+	AccessLog     AccessLog
 }
 
 // Server config struct
@@ -32,11 +91,29 @@ type ServerConfig struct {
 	JwtSecretKey      string
 	CookieName        string
 	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
 	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
 	SSL               bool
 	CtxDefaultTimeout time.Duration
 	CSRF              bool
 	Debug             bool
+	// Serve HTTP/2 without TLS, ignored when SSL is on
+	H2C                bool
+	DisableKeepAlives  bool
+	TCPKeepAlivePeriod time.Duration
+	MaxConnections     int
+	// Json request body limit in bytes, 0 uses the binder default
+	MaxBodyBytes int64
+	// Serve gRPC on Port next to REST, HTTP/2 requests are routed by content-type
+	GRPC           bool
+	GRPCReflection bool
+	// Label request goroutines and auth queries for CPU profiles of the PprofPort
+	PprofLabels bool
+	// Serve the embedded admin panel at /api/v1/admin/ui/ with its sign in page at /api/v1/admin/login
+	AdminUI bool
+	// CIDRs of proxies whose X-Forwarded-For is trusted for the client ip, empty uses the peer address
+	TrustedProxies []string
 }
 
 // Logger config
@@ -46,6 +123,9 @@ type Logger struct {
 	DisableStacktrace bool
 	Encoding          string
 	Level             string
+	// Redis hash of runtime level overrides by module and the channel announcing changes to them
+	LevelsKey     string
+	LevelsChannel string
 }
 
 // Postgresql config
@@ -58,6 +138,26 @@ type PostgresConfig struct {
 	PostgresqlSSLMode  bool
 	PgDriver           string
 	DefaultSchema      string
+	Backend            string
+	MaxConns           int32
+	Explain            Explain
+	Statements         PreparedStatements
+}
+
+// Prepared statements config
+type PreparedStatements struct {
+	Enabled bool
+	Queries []string
+}
+
+// Query plans of a sample of slow auth queries are logged, EXPLAIN ANALYZE runs the query again so only
+// reads are sampled. SampleRate is the fraction of slow queries explained
+type Explain struct {
+	Enabled       bool
+	SlowQueryMs   int
+	SampleRate    float64
+	TimeoutMs     int
+	MaxConcurrent int
 }
 
 // Redis config
@@ -71,6 +171,19 @@ type RedisConfig struct {
 	PoolTimeout    int
 	Password       string
 	DB             int
+	// Session store operation classes. Reads validate a session on every request, so they fail fast and may
+	// be answered from process memory, writes may be retried
+	Read  RedisOpClass
+	Write RedisOpClass
+}
+
+// Redis operation class config
+type RedisOpClass struct {
+	TimeoutMs       int
+	Retries         int
+	RetryBackoffMs  int
+	FallbackSeconds int
+	FallbackSize    int
 }
 
 // MongoDB config
@@ -91,6 +204,32 @@ type Session struct {
 	Prefix string
 	Name   string
 	Expire int
+	// Seconds a login or re-authentication unlocks sensitive actions
+	StepUpMaxAge int
+	// Seconds a guest session lives, guests are upgraded on register or login
+	GuestExpire int
+	// Concurrent sessions per user, 0 is unlimited. LimitPolicy reject refuses the new login,
+	// evict_oldest ends the oldest session to make room
+	MaxConcurrent int
+	LimitPolicy   string
+	// Session data caps, keys per session and bytes of all values together
+	MaxDataKeys  int
+	MaxDataBytes int
+	// Lifecycle event streams, approximate entry caps of each user stream and of the stream of all users.
+	// A user stream is dropped EventRetention seconds after its last event
+	EventMaxLen       int64
+	EventGlobalMaxLen int64
+	EventRetention    int
+}
+
+// Remember-me config
+type Remember struct {
+	Enabled    bool
+	Prefix     string
+	CookieName string
+	ExpireDays int
+	// Seconds the previous token is still accepted, for requests racing a rotation
+	GraceSeconds int
 }
 
 // Metrics config
@@ -118,6 +257,619 @@ type Jaeger struct {
 	Host        string
 	ServiceName string
 	LogSpans    bool
+	// Tracing UI page of a trace with a {trace_id} placeholder, returned as the Trace-Link header when set
+	TraceLinkTemplate string
+}
+
+// Postgres LISTEN/NOTIFY cache invalidation feed config
+type ChangeFeed struct {
+	Enabled             bool
+	ReconnectMinBackoff int
+	ReconnectMaxBackoff int
+	BackfillBatchSize   int
+	RetentionHours      int
+}
+
+// Automatic TLS certificates config, static ssl files are used when disabled
+type ACME struct {
+	Enabled          bool
+	Domains          []string
+	Email            string
+	Cache            string
+	Bucket           string
+	HTTPPort         string
+	RenewBeforeHours int
+	DirectoryURL     string
+}
+
+// Per route rate limit policies, keyed by route name
+type RateLimit struct {
+	Enabled bool
+	Prefix  string
+	Routes  map[string]RateLimitRoute
+}
+
+// Rate limit policy of a route
+type RateLimitRoute struct {
+	Limit       int
+	Window      int
+	WarnOnly    bool
+	TenantLimit int
+	GlobalLimit int
+}
+
+// IP allow and deny lists per route group, admin managed entries live in redis sets under Prefix
+type IPFilter struct {
+	Enabled        bool
+	Prefix         string
+	RefreshSeconds int
+	BypassPaths    []string
+	Groups         map[string]IPFilterGroup
+}
+
+// CIDRs or single addresses of a route group
+type IPFilterGroup struct {
+	Allow []string
+	Deny  []string
+}
+
+// Uploaded files storage, objects are scanned in QuarantineBucket before moving to Bucket
+type Files struct {
+	Bucket           string
+	QuarantineBucket string
+	MaxSizeMB        int
+	// Interval of removing stored blobs no file references anymore
+	BlobSweepSeconds int
+	Multipart        Multipart
+	Stream           Stream
+}
+
+// Streamed uploads, the request body is piped into object storage without buffering
+type Stream struct {
+	MaxSizeMB int
+	// Accepted media types, empty accepts any
+	ContentTypes       []string
+	ProgressPrefix     string
+	ProgressTTLSeconds int
+	// Bytes received between progress updates
+	ProgressEveryMB int
+}
+
+// Multipart uploads of large files, parts are PUT directly to object storage through presigned URLs
+type Multipart struct {
+	MaxSizeMB            int
+	PartSizeMB           int
+	PresignSeconds       int
+	UploadTTLMinutes     int
+	AbortIntervalSeconds int
+}
+
+// Upload malware scanner, Driver is clamav or noop
+type Scanner struct {
+	Driver         string
+	Address        string
+	TimeoutSeconds int
+	ChunkSize      int
+}
+
+// Redis job queue and worker config
+type JobQueue struct {
+	Name               string
+	MaxAttempts        int
+	PollTimeoutSeconds int
+	Concurrency        int
+	// Delay before retrying a failed job, doubled per attempt up to MaxRetryBackoffSeconds
+	RetryBackoffSeconds    int
+	MaxRetryBackoffSeconds int
+}
+
+// Downstream service declaration
+type Service struct {
+	BaseURL        string
+	TimeoutSeconds int
+	Retries        int
+	Headers        map[string]string
+	Auth           ServiceAuth
+}
+
+// Downstream service auth, Type is none, bearer, basic or apikey
+type ServiceAuth struct {
+	Type     string
+	Token    string
+	Username string
+	Password string
+	Header   string
+}
+
+// Time zones, StorageTimezone is also the database session zone
+type Clock struct {
+	StorageTimezone     string
+	DefaultUserTimezone string
+}
+
+// Read deduplication, concurrent identical calls share one query
+type Dedup struct {
+	GetByID     bool
+	GetUsers    bool
+	Permissions bool
+}
+
+// Secrets provider, Driver is env or file
+type Secrets struct {
+	Driver string
+	Prefix string
+	Dir    string
+}
+
+// Audit event hash chain, events are HMAC signed when SigningKeySecret names a secret.
+// The chain head is anchored every AnchorIntervalSeconds into AnchorBucket, RetentionDays locks anchors as WORM.
+type AuditChain struct {
+	SigningKeySecret      string
+	AnchorEnabled         bool
+	AnchorBucket          string
+	AnchorPrefix          string
+	AnchorIntervalSeconds int
+	RetentionDays         int
+	VerifyBatchSize       int
+}
+
+// PII column encryption, key material is read from the secrets provider
+type PII struct {
+	Enabled bool
+	// Secret holding "version:base64key" pairs, comma separated
+	KeysSecret string
+	// Key version new values are encrypted with
+	ActiveKey        int
+	BlindIndexSecret string
+}
+
+// PII anonymization of database copies, SaltSecret names the secret keying the fake value mappings.
+// The same salt maps the same value to the same fake on every run.
+type Anonymize struct {
+	SaltSecret string
+}
+
+// Replay traffic recording config
+type Replay struct {
+	Record        bool
+	Dir           string
+	MaxExchanges  int
+	RedactHeaders []string
+	RedactFields  []string
+}
+
+// SMS provider, Driver is twilio, sns or log, credentials are read from the secrets provider
+type SMS struct {
+	Driver         string
+	TimeoutSeconds int
+	Twilio         SMSTwilio
+	SNS            SMSSNS
+}
+
+// Twilio account, AuthTokenSecret names the secret holding the auth token
+type SMSTwilio struct {
+	AccountSID      string
+	From            string
+	AuthTokenSecret string
+}
+
+// AWS SNS publisher, SecretKeySecret names the secret holding the secret access key
+type SMSSNS struct {
+	Region          string
+	AccessKey       string
+	SecretKeySecret string
+	SenderID        string
+}
+
+// One time codes sent by SMS, TTL and ResendSeconds in seconds
+type OTP struct {
+	CodeLength    int
+	TTL           int
+	MaxAttempts   int
+	ResendSeconds int
+}
+
+// Organizations, MaxMembers and MaxPendingInvitations are the quotas of new organizations, administrators
+// change them per organization. InvitationTTL in seconds.
+type Organizations struct {
+	MaxMembers            int
+	MaxPendingInvitations int
+	InvitationTTL         int
+}
+
+// Runtime settings stored in the database, instances cache them for RefreshSeconds and drop the cache
+// when a change is published on Channel
+type RuntimeSettings struct {
+	RefreshSeconds int
+	Channel        string
+}
+
+// Online presence in redis. Authenticated requests are heartbeats, written at most every TouchIntervalSeconds
+// per user and instance, users are offline TTLSeconds after the last one. Presence changes are published as
+// json on Channel, offline ones by a sweep every SweepIntervalSeconds
+type Presence struct {
+	Enabled              bool
+	Prefix               string
+	Channel              string
+	TTLSeconds           int
+	TouchIntervalSeconds int
+	SweepIntervalSeconds int
+}
+
+// Redis read models of users projected from the change feed log every IntervalSeconds, BatchSize log entries
+// at a time. Needs the change feed, its RetentionHours bound how long the projection may stall before a rebuild
+type ReadModel struct {
+	Enabled         bool
+	Prefix          string
+	IntervalSeconds int
+	BatchSize       int
+}
+
+// Registration, InviteOnly is the default of the invite_only setting. Invitations issued by administrators
+// expire after InvitationTTL seconds and are used InvitationMaxUses times unless they say otherwise.
+type Registration struct {
+	InviteOnly        bool
+	InvitationTTL     int
+	InvitationMaxUses int
+}
+
+// Password rotation campaigns, flagged users must change their password before anything else. Pending users
+// are mirrored into the PendingKey redis set so the session middleware needn't hit Postgres
+type PasswordRotation struct {
+	Enabled    bool
+	PendingKey string
+	// Rows of an uploaded cohort
+	UploadMaxRows int
+	// Pending user ids listed in a campaign report
+	ReportPendingLimit int
+}
+
+// Login risk scoring, scores of the scorers are weighted and summed, capped at 1. The highest threshold the
+// sum reaches decides: alert the user, force an SMS second factor or block. A zero threshold is never reached
+type Risk struct {
+	Enabled         bool
+	Prefix          string
+	AlertThreshold  float64
+	StepUpThreshold float64
+	BlockThreshold  float64
+	Reputation      RiskReputation
+	Velocity        RiskVelocity
+	Travel          RiskTravel
+}
+
+// Static IP reputation, Score of a range from 0 to 1
+type RiskReputation struct {
+	Weight float64
+	Ranges []RiskRange
+}
+
+// Address range with a reputation score
+type RiskRange struct {
+	CIDR  string
+	Score float64
+}
+
+// Failed logins per username and per address within WindowSeconds, MaxFailures scores 1
+type RiskVelocity struct {
+	Weight        float64
+	WindowSeconds int
+	MaxFailures   int
+}
+
+// Impossible travel between logins faster than MaxSpeedKmh, locations of addresses come from Locations.
+// The last login location of a user is kept RetentionSeconds
+type RiskTravel struct {
+	Weight           float64
+	MaxSpeedKmh      float64
+	RetentionSeconds int
+	Locations        []RiskLocation
+}
+
+// Address range at a known location
+type RiskLocation struct {
+	CIDR      string
+	Country   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Cache config
+type Cache struct {
+	ListTTL          int
+	ListStaleSeconds int
+	UseCases         bool
+	UseCasePrefix    string
+	UseCaseTTL       int
+}
+
+// Trusted external JWT issuer config
+type JWTIssuer struct {
+	Issuer            string
+	JWKSURL           string
+	Audience          string
+	Algorithms        []string
+	CacheSeconds      int
+	MinRefreshSeconds int
+	LeewaySeconds     int
+	Principal         string
+	UserClaim         string
+	ServiceRole       string
+}
+
+// Bearer auth config
+type BearerAuth struct {
+	Enabled bool
+	APIKeys map[string]APIKey
+}
+
+// API key of a server client, the key itself is the value of the secret KeySecret. The key acts as the local
+// user with UserEmail, without one it is a service principal holding ServiceRole
+type APIKey struct {
+	KeySecret   string
+	UserEmail   string
+	ServiceRole string
+}
+
+// Periodic tasks, Prefix namespaces the redis locks taken per run
+type Scheduler struct {
+	Prefix string
+}
+
+// Self-service account deletion, accounts are purged GracePeriodHours after the request
+// by a scheduler task running every PurgeIntervalSeconds
+type Deletion struct {
+	GracePeriodHours     int
+	PurgeIntervalSeconds int
+	PurgeBatchSize       int
+}
+
+// Throttles on the changes users make to their own profile fields, Limits by field allow at most Max changes
+// within PeriodSeconds. Fields are username, email, phone and timezone, administrators are not throttled
+type FieldChanges struct {
+	Enabled bool
+	Limits  map[string]ChangeLimit
+}
+
+// Changes of a field allowed within PeriodSeconds
+type ChangeLimit struct {
+	Max           int
+	PeriodSeconds int
+}
+
+// Scheduled cleanup config
+type Compaction struct {
+	Enabled            bool
+	DryRun             bool
+	IntervalSeconds    int
+	BatchSize          int
+	LoginHistoryDays   int
+	AuditRetentionDays int
+}
+
+// Admin batch writes on users, at most MaxOperations per request run Concurrency at a time
+type UserBatch struct {
+	MaxOperations int
+	Concurrency   int
+}
+
+// Per user limits of contact sub-resources, zero disables a limit
+type Contacts struct {
+	MaxAddresses   int
+	MaxPhones      int
+	MaxSocialLinks int
+}
+
+// Webhooks users register for events on their own account, deliveries run on the job queue and are
+// retried within its attempts budget. Targets on private networks are refused unless AllowPrivateTargets.
+type Webhooks struct {
+	Enabled             bool
+	MaxPerUser          int
+	TimeoutSeconds      int
+	AllowHTTP           bool
+	AllowPrivateTargets bool
+	DeliveryLogLimit    int
+	DevicesPrefix       string
+}
+
+// Email domain policy of registration, admin managed Allow and Deny entries live in redis under Prefix.
+// DisposableDomains extends the built-in list of disposable mailbox providers.
+type EmailPolicy struct {
+	Enabled           bool
+	Prefix            string
+	RefreshSeconds    int
+	Allow             []string
+	Deny              []string
+	BlockDisposable   bool
+	DisposableDomains []string
+	CheckMX           bool
+	MXTimeoutMs       int
+}
+
+// HR user sync config
+type HRSync struct {
+	Enabled         bool
+	Prefix          string
+	IntervalSeconds int
+	PageSize        int
+	MaxPages        int
+	Create          bool
+	Conflicts       map[string]string
+	KeepReports     int
+}
+
+// Shadow traffic config
+type Shadow struct {
+	Enabled    bool
+	Postgres   PostgresConfig
+	Reads      bool
+	Writes     bool
+	Methods    map[string]bool
+	SampleRate float64
+	TimeoutMs  int
+	QueueSize  int
+	Workers    int
+}
+
+// Generated usecase decorators, calls arriving without a deadline get UseCaseTimeoutMs, zero leaves them unbounded
+type Observe struct {
+	Enabled          bool
+	UseCaseTimeoutMs int
+}
+
+// Pagination config
+type Pagination struct {
+	Counts           map[string]string
+	CursorKeysSecret string
+	CursorActiveKey  int
+}
+
+// Public ids config
+type IDs struct {
+	Obfuscate bool
+	KeySecret string
+	Keep      []string
+}
+
+// Error responses are RFC 7807 problem details unless Legacy keeps the {status, error} body while clients migrate
+type Problems struct {
+	Legacy bool
+	// Prefix of problem type URIs, the pages served under /problems when empty
+	TypeBaseURL string
+	// Cache lifetime of the problem type pages
+	PageMaxAgeSeconds int
+}
+
+// Users and organizations are written as JSON:API documents to requests accepting application/vnd.api+json,
+// other requests keep the plain json bodies
+type JSONAPI struct {
+	Enabled bool
+}
+
+// Deprecated fields config
+type Deprecation struct {
+	Enabled      bool
+	ClientHeader string
+	Clients      []string
+}
+
+// Short lived tokens a session exchanges for a reduced scope set, TTLSeconds when the request asks for none
+type ScopedTokens struct {
+	TTLSeconds    int
+	MaxTTLSeconds int
+}
+
+// Personal access tokens users mint under /me/tokens, at most MaxPerUser unexpired ones each. With
+// MaxLifetimeDays set every token expires within it. Use of a token is recorded at most every TouchIntervalSeconds
+type AccessTokens struct {
+	Enabled              bool
+	MaxPerUser           int
+	MaxLifetimeDays      int
+	TouchIntervalSeconds int
+}
+
+// Billing config
+type Billing struct {
+	Enabled          bool
+	WebhookKeySecret string
+	SignatureHeader  string
+	ToleranceSeconds int
+	Plans            map[string]BillingPlan
+}
+
+// Organization quotas while subscribed to the plan, zero is unlimited
+type BillingPlan struct {
+	MaxMembers            int
+	MaxPendingInvitations int
+}
+
+// Request schema validation config
+type RequestSchema struct {
+	Enabled    bool
+	Spec       string
+	PathPrefix string
+}
+
+// Response security headers, an empty value leaves the header out. HSTS is sent on https requests only.
+// Routes override the policy headers for paths under PathPrefix, the longest matching prefix wins
+type Security struct {
+	Headers               bool
+	HSTSMaxAgeSeconds     int
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ContentTypeNosniff    bool
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	Routes                []SecurityRoute
+}
+
+// Header overrides of a route, empty fields keep the defaults and "-" leaves the header out
+type SecurityRoute struct {
+	PathPrefix            string
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+}
+
+// Access control config
+type Access struct {
+	CacheSeconds      int
+	LocalCacheSeconds int
+	RolePermissions   map[string][]string
+	Features          map[string]FeatureFlag
+	Grants            RoleGrants
+}
+
+// Temporary role grants last at most MaxHours, expired ones are revoked every ExpiryIntervalSeconds in batches of
+// ExpiryBatchSize
+type RoleGrants struct {
+	MaxHours              int
+	ExpiryIntervalSeconds int
+	ExpiryBatchSize       int
+}
+
+// Feature flag, on for users of Roles (any role when empty) entitled to one of Plans (any or none when empty) and
+// Percent of them picked by a stable hash of flag and user id
+type FeatureFlag struct {
+	Enabled bool
+	Roles   []string
+	Plans   []string
+	Percent int
+}
+
+// Service level objectives per route group, the metrics middleware tracks them over a sliding window of
+// WindowHours on each instance and publishes burn rates every RefreshSeconds
+type SLO struct {
+	Enabled        bool
+	WindowHours    int
+	RefreshSeconds int
+	Objectives     map[string]SLObjective
+}
+
+// Objective of the routes under PathPrefix, targets are percentages of good requests, zero LatencyMs disables the latency objective
+type SLObjective struct {
+	PathPrefix    string
+	Availability  float64
+	LatencyMs     int
+	LatencyTarget float64
+}
+
+// Dev mode, enabled by the --dev flag, runs without external services: repositories are kept in memory,
+// redis runs in process and objects are stored under DataDir with presigned uploads served on BlobPort
+type Dev struct {
+	Enabled  bool
+	DataDir  string
+	BlobPort string
+	BlobURL  string
+}
+
+// Outbound fakes, SMS and webhook deliveries are recorded to an outbox listed at /dev/outbox instead of sent.
+// The newest Capacity messages are listed, in dev mode each is also stored in Bucket of the blob store.
+// The exposure profile must allow Fakes
+type Fakes struct {
+	Enabled  bool
+	Capacity int
+	Bucket   string
 }
 
 // Load config file from given path
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Exposure profiles
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// Route groups and middleware registered per environment, Profile picks the active entry of Profiles
+type Exposure struct {
+	Profile  string
+	Profiles map[string]ExposureProfile
+}
+
+// Optional surfaces of a profile, each also needs its own switch turned on. None of them may ship to prod
+type ExposureProfile struct {
+	// Swagger UI and spec at /swagger
+	Swagger bool
+	// Request and response dumps of the debug middleware
+	Debug bool
+	// Pprof debug server on PprofPort
+	Pprof bool
+	// Traffic recording for replay
+	Replay bool
+	// gRPC server reflection
+	GRPCReflection bool
+	// In-memory repositories and in-process redis
+	DevMode bool
+	// Outbound fakes and their outbox at /dev/outbox
+	Fakes bool
+}
+
+// Active profile, an unset profile falls back to dev
+func (e Exposure) Active() ExposureProfile {
+	return e.Profiles[e.profileName()]
+}
+
+// Check the active profile is known and configured, Production mode runs the prod profile only and the
+// prod profile exposes nothing optional
+func (e Exposure) Validate(mode string) error {
+	name := e.profileName()
+	switch name {
+	case ProfileDev, ProfileStaging, ProfileProd:
+	default:
+		return errors.Errorf("exposure: unknown profile %q", name)
+	}
+	if _, ok := e.Profiles[name]; !ok {
+		return errors.Errorf("exposure: profile %q is not configured", name)
+	}
+	if strings.EqualFold(mode, "Production") && name != ProfileProd {
+		return errors.Errorf("exposure: Production mode requires the %q profile, got %q", ProfileProd, name)
+	}
+	if exposed := e.Profiles[ProfileProd].exposed(); len(exposed) > 0 {
+		return errors.Errorf("exposure: %q profile must not expose %s", ProfileProd, strings.Join(exposed, ", "))
+	}
+	return nil
+}
+
+func (e Exposure) profileName() string {
+	if e.Profile == "" {
+		return ProfileDev
+	}
+	return strings.ToLower(e.Profile)
+}
+
+func (p ExposureProfile) exposed() []string {
+	var exposed []string
+	for _, surface := range []struct {
+		name string
+		on   bool
+	}{
+		{"Swagger", p.Swagger},
+		{"Debug", p.Debug},
+		{"Pprof", p.Pprof},
+		{"Replay", p.Replay},
+		{"GRPCReflection", p.GRPCReflection},
+		{"DevMode", p.DevMode},
+		{"Fakes", p.Fakes},
+	} {
+		if surface.on {
+			exposed = append(exposed, surface.name)
+		}
+	}
+	return exposed
+}
+
+// Enabled surfaces, for the startup log
+func (p ExposureProfile) String() string {
+	exposed := p.exposed()
+	if len(exposed) == 0 {
+		return "none"
+	}
+	return strings.Join(exposed, ", ")
+}
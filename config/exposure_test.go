@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposure_Validate(t *testing.T) {
+	t.Parallel()
+
+	profiles := map[string]ExposureProfile{
+		ProfileDev:     {Swagger: true, Debug: true, Pprof: true, DevMode: true},
+		ProfileStaging: {Swagger: true},
+		ProfileProd:    {},
+	}
+
+	require.NoError(t, Exposure{Profiles: profiles}.Validate("Development"))
+	require.True(t, Exposure{Profiles: profiles}.Active().DevMode)
+	require.NoError(t, Exposure{Profile: "Staging", Profiles: profiles}.Validate("Development"))
+	require.NoError(t, Exposure{Profile: ProfileProd, Profiles: profiles}.Validate("Production"))
+
+	require.Error(t, Exposure{Profile: "qa", Profiles: profiles}.Validate("Development"))
+	require.Error(t, Exposure{Profile: ProfileStaging, Profiles: map[string]ExposureProfile{ProfileProd: {}}}.Validate("Development"))
+	require.Error(t, Exposure{Profile: ProfileStaging, Profiles: profiles}.Validate("Production"))
+
+	// Dangerous surfaces can't be turned on for prod, whichever profile is active
+	profiles[ProfileProd] = ExposureProfile{Swagger: true, Pprof: true}
+	err := Exposure{Profile: ProfileDev, Profiles: profiles}.Validate("Development")
+	require.EqualError(t, err, `exposure: "prod" profile must not expose Swagger, Pprof`)
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceDefault = "default"
+
+	redactedValue = "******"
+)
+
+// Key fragments marking a setting as secret
+var secretKeyFragments = []string{"password", "secret", "token", "accesskey", "accountkey", "privatekey", "dsn", "authorization", "apikey"}
+
+// Maps whose every value is secret, e.g. services.<name>.headers carries credentials of downstream services
+var secretMapKeys = []string{"headers"}
+
+// Single effective setting
+type Setting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// Setting changed between two snapshots
+type Change struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+// Flatten config into lower case dotted keys, matching viper key names
+func Flatten(c *Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := make(map[string]interface{})
+	if err = json.Unmarshal(data, &nested); err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]interface{})
+	flatten("", nested, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, nested map[string]interface{}, flat map[string]interface{}) {
+	for k, v := range nested {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if child, ok := v.(map[string]interface{}); ok {
+			flatten(key, child, flat)
+			continue
+		}
+		flat[key] = v
+	}
+}
+
+// Is setting key a secret
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	segments := strings.Split(key, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		for _, mapKey := range secretMapKeys {
+			if segment == mapKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Redact secret value, empty secrets stay empty so missing values are still visible
+func Redact(key string, value interface{}) interface{} {
+	if !IsSecretKey(key) {
+		return value
+	}
+	if value == nil || value == "" {
+		return value
+	}
+	return redactedValue
+}
+
+// Where the value of key comes from
+func Source(v *viper.Viper, key string) string {
+	if _, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+		return SourceEnv
+	}
+	if v != nil && v.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// Redacted effective settings with source annotation, sorted by key
+func Settings(v *viper.Viper, c *Config) ([]Setting, error) {
+	flat, err := Flatten(c)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]Setting, 0, len(flat))
+	for key, value := range flat {
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  Redact(key, value),
+			Source: Source(v, key),
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+
+	return settings, nil
+}
+
+// Redacted difference between two configs, sorted by key
+func Diff(oldCfg, newCfg *Config) ([]Change, error) {
+	oldFlat, err := Flatten(oldCfg)
+	if err != nil {
+		return nil, err
+	}
+	newFlat, err := Flatten(newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{}, len(newFlat))
+	for k := range oldFlat {
+		keys[k] = struct{}{}
+	}
+	for k := range newFlat {
+		keys[k] = struct{}{}
+	}
+
+	changes := make([]Change, 0)
+	for key := range keys {
+		oldValue, newValue := oldFlat[key], newFlat[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := Change{Key: key, OldValue: Redact(key, oldValue), NewValue: Redact(key, newValue)}
+		if IsSecretKey(key) {
+			// Both sides redact to the same mask, make the rotation itself visible
+			change.NewValue = fmt.Sprintf("%s (changed)", redactedValue)
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes, nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, redactedValue, Redact("postgres.postgresqlpassword", "postgres"))
+	require.Equal(t, redactedValue, Redact("server.jwtsecretkey", "secretkey"))
+	require.Equal(t, redactedValue, Redact("storage.azure.accountkey", "a2V5"))
+	require.Equal(t, "", Redact("redis.password", ""))
+	require.Equal(t, ":5000", Redact("server.port", ":5000"))
+
+	// Downstream service headers carry credentials under any name
+	require.Equal(t, redactedValue, Redact("services.billing.headers.x-tenant-key", "k3y"))
+	require.Equal(t, redactedValue, Redact("services.billing.headers.authorization", "Bearer abc"))
+	require.Equal(t, redactedValue, Redact("webhooks.apikey", "abc"))
+	require.Equal(t, "http://billing:8080", Redact("services.billing.baseurl", "http://billing:8080"))
+}
+
+func TestSettings_ServiceHeaders(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Services: map[string]Service{
+		"billing": {BaseURL: "http://billing:8080", Headers: map[string]string{"Authorization": "Bearer abc", "X-Client": "api"}},
+	}}
+	settings, err := Settings(nil, cfg)
+	require.NoError(t, err)
+	values := make(map[string]interface{})
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+	require.Equal(t, redactedValue, values["services.billing.headers.authorization"])
+	require.Equal(t, redactedValue, values["services.billing.headers.x-client"])
+	require.Equal(t, "http://billing:8080", values["services.billing.baseurl"])
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	oldCfg := &Config{Server: ServerConfig{Port: ":5000", JwtSecretKey: "old"}}
+	newCfg := &Config{Server: ServerConfig{Port: ":6000", JwtSecretKey: "new"}}
+
+	changes, err := Diff(oldCfg, newCfg)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, Change{Key: "server.jwtsecretkey", OldValue: redactedValue, NewValue: redactedValue + " (changed)"}, changes[0])
+	require.Equal(t, Change{Key: "server.port", OldValue: ":5000", NewValue: ":6000"}, changes[1])
+}
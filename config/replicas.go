@@ -0,0 +1,37 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Read replicas of the auth repository. Methods switches the reads served by them by lowercase name, every
+// other call stays on the primary, so only reads tolerating replication lag belong there. Replicas take turns,
+// with HedgeDelayMs set a read which has not answered within the delay is sent to the next replica as well and
+// the first answer wins. A single replica is hedged against the primary. ReadYourWrites answers the primary
+// position after a write as a consistency token, reads of requests sending it back only go to replicas which
+// have replayed that far and to the primary otherwise
+type Replicas struct {
+	Enabled        bool
+	Postgres       []PostgresConfig
+	Methods        map[string]bool
+	HedgeDelayMs   int
+	ReadYourWrites bool
+}
+
+// Check replicas are configured, database per tenant mode routes every query to the tenant database so
+// replicas would never be read
+func (r Replicas) Validate(tenancy Tenancy) error {
+	if !r.Enabled {
+		return nil
+	}
+	if len(r.Postgres) == 0 {
+		return errors.New("replicas: Postgres is required when enabled")
+	}
+	if tenancy.Isolated() {
+		return errors.New("replicas: database tenancy mode can't be combined with read replicas")
+	}
+	if r.HedgeDelayMs < 0 {
+		return errors.Errorf("replicas: negative HedgeDelayMs %d", r.HedgeDelayMs)
+	}
+	return nil
+}
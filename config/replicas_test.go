@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicas_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Replicas{}.Validate(Tenancy{Mode: TenancyDatabase}))
+
+	replicas := Replicas{Enabled: true, Postgres: []PostgresConfig{{PostgresqlHost: "replica"}}, HedgeDelayMs: 50}
+	require.NoError(t, replicas.Validate(Tenancy{}))
+	require.Error(t, replicas.Validate(Tenancy{Mode: TenancyDatabase}))
+
+	require.Error(t, Replicas{Enabled: true}.Validate(Tenancy{}))
+	replicas.HedgeDelayMs = -1
+	require.Error(t, replicas.Validate(Tenancy{}))
+}
@@ -0,0 +1,52 @@
+package config
+
+import "strings"
+
+// Blob storage drivers
+const (
+	StorageMinio = "minio"
+	StorageGCS   = "gcs"
+	StorageAzure = "azure"
+	StorageLocal = "local"
+)
+
+// Blob storage of files. The minio driver speaks S3 to the AWS endpoint, gcs the S3 compatible XML API of
+// Cloud Storage with HMAC keys, azure Blob Storage with a shared account key and local keeps blobs in a
+// directory served on Port for presigned part uploads. Buckets are containers on Azure. Dev mode always
+// stores blobs locally in Dev.DataDir
+type Storage struct {
+	Driver string
+	GCS    GCSStorage
+	Azure  AzureStorage
+	Local  LocalStorage
+}
+
+// Cloud Storage interoperability access, Endpoint defaults to storage.googleapis.com
+type GCSStorage struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+// Azure Blob Storage account, AccountKey is base64 as shown by the portal. Endpoint defaults to
+// https://<Account>.blob.core.windows.net
+type AzureStorage struct {
+	Account    string
+	AccountKey string
+	Endpoint   string
+}
+
+// Directory of the local driver, URL is where Port is reachable by clients
+type LocalStorage struct {
+	Root string
+	Port string
+	URL  string
+}
+
+// Selected driver, minio when unset
+func (s Storage) Selected() string {
+	if s.Driver == "" {
+		return StorageMinio
+	}
+	return strings.ToLower(s.Driver)
+}
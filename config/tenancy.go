@@ -0,0 +1,71 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Tenancy modes
+const (
+	TenancyShared   = "shared"
+	TenancyDatabase = "database"
+)
+
+// Tenant ids are part of a database name
+var tenantID = regexp.MustCompile(`^[a-z0-9_]{1,40}$`)
+
+// Database per tenant isolation. In database mode Header names the tenant of a request, its database is
+// DbnameTemplate with the tenant id and repositories use it through the request context. Pools are opened
+// on first use, past MaxOpen the least recently used one is closed. Migrate runs MigrationsPath on open
+type Tenancy struct {
+	Mode           string
+	Header         string
+	Tenants        []string
+	DbnameTemplate string
+	MaxOpen        int
+	// Open connections per tenant pool
+	MaxConns       int
+	Migrate        bool
+	MigrationsPath string
+}
+
+// Whether every tenant has its own database
+func (t Tenancy) Isolated() bool {
+	return strings.EqualFold(t.Mode, TenancyDatabase)
+}
+
+// Check database mode is fully configured. Only sqlx repositories are routed, so the pgx backend and shadow
+// traffic can't be combined with it
+func (t Tenancy) Validate(postgres PostgresConfig, shadow Shadow) error {
+	switch strings.ToLower(t.Mode) {
+	case "", TenancyShared:
+		return nil
+	case TenancyDatabase:
+	default:
+		return errors.Errorf("tenancy: unknown mode %q", t.Mode)
+	}
+
+	if t.Header == "" {
+		return errors.New("tenancy: Header is required in database mode")
+	}
+	if strings.Count(t.DbnameTemplate, "%s") != 1 {
+		return errors.Errorf("tenancy: DbnameTemplate %q must contain %%s once", t.DbnameTemplate)
+	}
+	if len(t.Tenants) == 0 {
+		return errors.New("tenancy: Tenants is required in database mode")
+	}
+	for _, tenant := range t.Tenants {
+		if !tenantID.MatchString(tenant) {
+			return errors.Errorf("tenancy: invalid tenant id %q", tenant)
+		}
+	}
+	if postgres.Backend != "" && postgres.Backend != "sqlx" {
+		return errors.Errorf("tenancy: database mode requires the sqlx backend, got %q", postgres.Backend)
+	}
+	if shadow.Enabled {
+		return errors.New("tenancy: database mode can't be combined with shadow traffic")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenancy_Validate(t *testing.T) {
+	t.Parallel()
+
+	isolated := Tenancy{Mode: "Database", Header: "X-Tenant-ID", Tenants: []string{"acme", "globex_2"}, DbnameTemplate: "user_service_%s"}
+	require.True(t, isolated.Isolated())
+	require.NoError(t, isolated.Validate(PostgresConfig{}, Shadow{}))
+	require.NoError(t, Tenancy{}.Validate(PostgresConfig{}, Shadow{Enabled: true}))
+	require.False(t, Tenancy{Mode: TenancyShared}.Isolated())
+
+	require.Error(t, Tenancy{Mode: "schema"}.Validate(PostgresConfig{}, Shadow{}))
+
+	invalid := isolated
+	invalid.Header = ""
+	require.Error(t, invalid.Validate(PostgresConfig{}, Shadow{}))
+
+	invalid = isolated
+	invalid.DbnameTemplate = "user_service_%s_%s"
+	require.Error(t, invalid.Validate(PostgresConfig{}, Shadow{}))
+
+	invalid = isolated
+	invalid.Tenants = nil
+	require.Error(t, invalid.Validate(PostgresConfig{}, Shadow{}))
+
+	// Tenant ids end up in a database name
+	invalid = isolated
+	invalid.Tenants = []string{"acme", "Acme; DROP"}
+	require.EqualError(t, invalid.Validate(PostgresConfig{}, Shadow{}), `tenancy: invalid tenant id "Acme; DROP"`)
+
+	require.Error(t, isolated.Validate(PostgresConfig{Backend: "pgx"}, Shadow{}))
+	require.Error(t, isolated.Validate(PostgresConfig{}, Shadow{Enabled: true}))
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Static certificate files served with SSL when ACME is disabled
+const (
+	CertFile = "ssl/server.crt"
+	KeyFile  = "ssl/server.pem"
+)
+
+// Profile fields changes can be limited for
+var changeLimitedFields = map[string]bool{"username": true, "email": true, "phone": true, "timezone": true}
+
+// Levels the logger knows, others fall back to debug
+var loggerLevels = map[string]bool{
+	"debug": true, "info": true, "warn": true, "error": true, "dpanic": true, "panic": true, "fatal": true,
+}
+
+// Every problem found by Validate, reported together so one run lists everything to fix
+type ValidationError struct {
+	Problems []string
+}
+
+// Error lists the problems one per line
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config: %d problem(s)\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+type validation struct {
+	problems []string
+}
+
+func (v *validation) check(err error) {
+	if err != nil {
+		v.problems = append(v.problems, err.Error())
+	}
+}
+
+func (v *validation) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validation) required(section string, values map[string]string) {
+	for _, name := range sortedKeys(values) {
+		if strings.TrimSpace(values[name]) == "" {
+			v.addf("%s: %s is required", section, name)
+		}
+	}
+}
+
+func (v *validation) nonNegative(section string, durations map[string]time.Duration) {
+	for _, name := range sortedKeys(durations) {
+		if durations[name] < 0 {
+			v.addf("%s: negative %s %d", section, name, durations[name])
+		}
+	}
+}
+
+// Check the config before anything is started, nil or a *ValidationError with every problem found. Dev mode
+// needs no Postgres or Redis, so their settings are only checked outside of it
+func (c *Config) Validate() error {
+	v := &validation{}
+
+	v.check(c.Exposure.Validate(c.Server.Mode))
+	if c.Dev.Enabled && !c.Exposure.Active().DevMode {
+		v.addf("exposure: dev mode is not allowed by the %q profile", c.Exposure.Profile)
+	}
+	if c.Fakes.Enabled && !c.Exposure.Active().Fakes {
+		v.addf("exposure: fakes are not allowed by the %q profile", c.Exposure.Profile)
+	}
+	v.check(c.Tenancy.Validate(c.Postgres, c.Shadow))
+	v.check(c.Replicas.Validate(c.Tenancy))
+	v.check(c.Canary.Validate())
+	v.check(c.AccessLog.Validate())
+
+	c.validateServer(v)
+	if !c.Dev.Enabled {
+		validatePostgres(v, "postgres", c.Postgres)
+		if c.Shadow.Enabled {
+			validatePostgres(v, "shadow.postgres", c.Shadow.Postgres)
+		}
+		for i, replica := range c.Replicas.Postgres {
+			validatePostgres(v, fmt.Sprintf("replicas.postgres[%d]", i), replica)
+		}
+		v.required("redis", map[string]string{"RedisAddr": c.Redis.RedisAddr})
+		c.validateStorage(v)
+	}
+	if c.Logger.Level != "" && !loggerLevels[strings.ToLower(c.Logger.Level)] {
+		v.addf("logger: unknown Level %q", c.Logger.Level)
+	}
+	if c.RequestSchema.Enabled && c.RequestSchema.Spec != "" {
+		if _, err := os.Stat(c.RequestSchema.Spec); err != nil {
+			v.addf("requestSchema: Spec: %v", err)
+		}
+	}
+	if c.Cache.UseCases && c.Cache.UseCaseTTL < 1 {
+		v.addf("cache: UseCases needs a positive UseCaseTTL")
+	}
+	if c.RateLimit.Enabled {
+		for _, route := range sortedKeys(c.RateLimit.Routes) {
+			policy := c.RateLimit.Routes[route]
+			if policy.Limit < 1 || policy.Window < 1 {
+				v.addf("rateLimit: Routes.%s needs a positive Limit and Window", route)
+			}
+			if policy.TenantLimit < 0 || policy.GlobalLimit < 0 {
+				v.addf("rateLimit: Routes.%s has a negative TenantLimit or GlobalLimit", route)
+			}
+			if policy.TenantLimit > 0 && policy.TenantLimit < policy.Limit {
+				v.addf("rateLimit: Routes.%s TenantLimit %d is below Limit %d", route, policy.TenantLimit, policy.Limit)
+			}
+			if policy.GlobalLimit > 0 && (policy.GlobalLimit < policy.Limit || policy.GlobalLimit < policy.TenantLimit) {
+				v.addf("rateLimit: Routes.%s GlobalLimit %d is below Limit or TenantLimit", route, policy.GlobalLimit)
+			}
+		}
+	}
+	if c.Billing.Enabled {
+		v.required("billing", map[string]string{"WebhookKeySecret": c.Billing.WebhookKeySecret})
+	}
+	if c.FieldChanges.Enabled {
+		for _, field := range sortedKeys(c.FieldChanges.Limits) {
+			limit := c.FieldChanges.Limits[field]
+			if !changeLimitedFields[field] {
+				v.addf("fieldChanges: unknown field %q in Limits", field)
+			}
+			if limit.Max < 1 || limit.PeriodSeconds < 1 {
+				v.addf("fieldChanges: Limits.%s needs a positive Max and PeriodSeconds", field)
+			}
+		}
+	}
+	if c.Bearer.Enabled {
+		for _, name := range sortedKeys(c.Bearer.APIKeys) {
+			if c.Bearer.APIKeys[name].KeySecret == "" {
+				v.addf("bearer: APIKeys.%s.KeySecret is required", name)
+			}
+		}
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// Listener settings, durations are seconds. SSL serves the static certificate files unless ACME issues them
+func (c *Config) validateServer(v *validation) {
+	v.required("server", map[string]string{"Port": c.Server.Port, "JwtSecretKey": c.Server.JwtSecretKey})
+	v.nonNegative("server", map[string]time.Duration{
+		"ReadTimeout":        c.Server.ReadTimeout,
+		"ReadHeaderTimeout":  c.Server.ReadHeaderTimeout,
+		"WriteTimeout":       c.Server.WriteTimeout,
+		"IdleTimeout":        c.Server.IdleTimeout,
+		"CtxDefaultTimeout":  c.Server.CtxDefaultTimeout,
+		"TCPKeepAlivePeriod": c.Server.TCPKeepAlivePeriod,
+	})
+	if c.Server.MaxBodyBytes < 0 {
+		v.addf("server: negative MaxBodyBytes %d", c.Server.MaxBodyBytes)
+	}
+	for _, cidr := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			v.addf("server: invalid TrustedProxies entry %q: %v", cidr, err)
+		}
+	}
+
+	switch {
+	case c.ACME.Enabled && !c.Server.SSL:
+		v.addf("acme: SSL is required when enabled")
+	case c.ACME.Enabled:
+		if len(c.ACME.Domains) == 0 {
+			v.addf("acme: Domains is required when enabled")
+		}
+	case c.Server.SSL:
+		for _, file := range []string{CertFile, KeyFile} {
+			if _, err := os.Stat(file); err != nil {
+				v.addf("server: SSL requires %s without ACME: %v", file, err)
+			}
+		}
+	}
+}
+
+// Check the selected blob storage driver is configured, dev mode stores blobs locally anyway
+func (c *Config) validateStorage(v *validation) {
+	storage := c.Storage
+	switch storage.Selected() {
+	case StorageMinio:
+	case StorageGCS:
+		v.required("storage", map[string]string{"GCS.AccessKey": storage.GCS.AccessKey, "GCS.SecretKey": storage.GCS.SecretKey})
+	case StorageAzure:
+		v.required("storage", map[string]string{"Azure.Account": storage.Azure.Account, "Azure.AccountKey": storage.Azure.AccountKey})
+	case StorageLocal:
+		v.required("storage", map[string]string{"Local.Root": storage.Local.Root, "Local.Port": storage.Local.Port, "Local.URL": storage.Local.URL})
+	default:
+		v.addf("storage: unknown Driver %q", storage.Driver)
+	}
+}
+
+func validatePostgres(v *validation, section string, postgres PostgresConfig) {
+	v.required(section, map[string]string{
+		"PostgresqlHost":   postgres.PostgresqlHost,
+		"PostgresqlPort":   postgres.PostgresqlPort,
+		"PostgresqlUser":   postgres.PostgresqlUser,
+		"PostgresqlDbname": postgres.PostgresqlDbname,
+	})
+	switch postgres.Backend {
+	case "", "sqlx", "pgxpool":
+	default:
+		v.addf("%s: unknown Backend %q", section, postgres.Backend)
+	}
+	if postgres.MaxConns < 0 {
+		v.addf("%s: negative MaxConns %d", section, postgres.MaxConns)
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
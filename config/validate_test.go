@@ -0,0 +1,117 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *Config {
+		return &Config{
+			Server: ServerConfig{Port: ":5000", JwtSecretKey: "secret", ReadTimeout: 5},
+			Postgres: PostgresConfig{
+				PostgresqlHost:   "localhost",
+				PostgresqlPort:   "5432",
+				PostgresqlUser:   "postgres",
+				PostgresqlDbname: "auth_db",
+			},
+			Redis:    RedisConfig{RedisAddr: "localhost:6379"},
+			Exposure: Exposure{Profiles: map[string]ExposureProfile{ProfileDev: {DevMode: true}, ProfileProd: {}}},
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	// Every problem is reported at once
+	cfg := valid()
+	cfg.Postgres.PostgresqlHost = ""
+	cfg.Postgres.Backend = "mysql"
+	cfg.Redis.RedisAddr = ""
+	cfg.Server.WriteTimeout = -1
+	cfg.ACME.Enabled = true
+	cfg.Logger.Level = "verbose"
+	cfg.Replicas.Enabled = true
+	err := cfg.Validate()
+	require.IsType(t, &ValidationError{}, err)
+	require.Equal(t, []string{
+		"replicas: Postgres is required when enabled",
+		"server: negative WriteTimeout -1",
+		"acme: SSL is required when enabled",
+		"postgres: PostgresqlHost is required",
+		`postgres: unknown Backend "mysql"`,
+		"redis: RedisAddr is required",
+		`logger: unknown Level "verbose"`,
+	}, err.(*ValidationError).Problems)
+	require.Contains(t, err.Error(), "config: 7 problem(s)")
+
+	// Dev mode needs no databases
+	cfg = valid()
+	cfg.Dev.Enabled = true
+	cfg.Postgres = PostgresConfig{}
+	cfg.Redis = RedisConfig{}
+	require.NoError(t, cfg.Validate())
+
+	// Fakes need a profile allowing them
+	cfg = valid()
+	cfg.Fakes.Enabled = true
+	require.EqualError(t, cfg.Validate(), "config: 1 problem(s)\n  - exposure: fakes are not allowed by the \"\" profile")
+	cfg.Exposure.Profiles[ProfileDev] = ExposureProfile{DevMode: true, Fakes: true}
+	require.NoError(t, cfg.Validate())
+
+	// Trusted proxies are CIDRs
+	cfg = valid()
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "10.0.0.1"}
+	require.EqualError(t, cfg.Validate(), "config: 1 problem(s)\n  - server: invalid TrustedProxies entry \"10.0.0.1\": invalid CIDR address: 10.0.0.1")
+
+	// Field change limits name known fields and allow changes
+	cfg = valid()
+	cfg.FieldChanges = FieldChanges{Enabled: true, Limits: map[string]ChangeLimit{
+		"username": {Max: 1, PeriodSeconds: 86400},
+		"password": {Max: 1, PeriodSeconds: 86400},
+		"email":    {Max: 0, PeriodSeconds: 86400},
+	}}
+	require.EqualError(t, cfg.Validate(), "config: 2 problem(s)\n"+
+		"  - fieldChanges: Limits.email needs a positive Max and PeriodSeconds\n"+
+		"  - fieldChanges: unknown field \"password\" in Limits")
+
+	// Storage drivers need their credentials, dev mode stores blobs locally
+	cfg = valid()
+	cfg.Storage = Storage{Driver: "Azure", Azure: AzureStorage{Account: "files"}}
+	require.EqualError(t, cfg.Validate(), "config: 1 problem(s)\n  - storage: Azure.AccountKey is required")
+	cfg.Storage.Driver = "dropbox"
+	require.EqualError(t, cfg.Validate(), "config: 1 problem(s)\n  - storage: unknown Driver \"dropbox\"")
+	cfg.Dev.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	// Usecase caches need a default lifetime
+	cfg = valid()
+	cfg.Cache.UseCases = true
+	require.EqualError(t, cfg.Validate(), "config: 1 problem(s)\n  - cache: UseCases needs a positive UseCaseTTL")
+	cfg.Cache.UseCaseTTL = 60
+	require.NoError(t, cfg.Validate())
+
+	// Tenant and global limits sit above the limit of one caller
+	cfg = valid()
+	cfg.RateLimit = RateLimit{Enabled: true, Routes: map[string]RateLimitRoute{
+		"login":  {Limit: 10, Window: 60, GlobalLimit: 5},
+		"search": {Limit: 60, Window: 60, TenantLimit: 30, GlobalLimit: 100},
+		"guest":  {Limit: 0, Window: 60, TenantLimit: -1},
+	}}
+	require.Equal(t, []string{
+		"rateLimit: Routes.guest needs a positive Limit and Window",
+		"rateLimit: Routes.guest has a negative TenantLimit or GlobalLimit",
+		"rateLimit: Routes.login GlobalLimit 5 is below Limit or TenantLimit",
+		"rateLimit: Routes.search TenantLimit 30 is below Limit 60",
+	}, cfg.Validate().(*ValidationError).Problems)
+	cfg.RateLimit.Routes = map[string]RateLimitRoute{"search": {Limit: 60, Window: 60, TenantLimit: 600, GlobalLimit: 3000}}
+	require.NoError(t, cfg.Validate())
+
+	// SSL without ACME needs the certificate files
+	cfg = valid()
+	cfg.Server.SSL = true
+	err = cfg.Validate()
+	require.Error(t, err)
+	require.Len(t, err.(*ValidationError).Problems, 2)
+}
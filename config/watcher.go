@@ -0,0 +1,93 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Effective config with reload history.
+// Components keep the values they were built with, a reload only records what changed on disk.
+type Watcher struct {
+	v         *viper.Viper
+	effective *Config
+
+	mu         sync.RWMutex
+	loadedAt   time.Time
+	reloaded   *Config
+	reloadedAt time.Time
+	lastDiff   []Change
+}
+
+// Snapshot of the effective config for inspection
+type Snapshot struct {
+	LoadedAt   time.Time `json:"loaded_at"`
+	ReloadedAt time.Time `json:"reloaded_at,omitempty"`
+	Settings   []Setting `json:"settings"`
+	// Changes applied by the last reload
+	LastReloadDiff []Change `json:"last_reload_diff"`
+	// Changes on disk not in effect until restart
+	PendingDiff []Change `json:"pending_diff"`
+}
+
+// Config watcher constructor
+func NewWatcher(v *viper.Viper, effective *Config) *Watcher {
+	return &Watcher{v: v, effective: effective, reloaded: effective, loadedAt: time.Now(), lastDiff: make([]Change, 0)}
+}
+
+// Watch config file and record diff on every change
+func (w *Watcher) Watch() {
+	w.v.OnConfigChange(func(e fsnotify.Event) {
+		if err := w.Reload(); err != nil {
+			log.Printf("config reload %s: %v", e.Name, err)
+		}
+	})
+	w.v.WatchConfig()
+}
+
+// Re-parse config and record diff from the previous reload
+func (w *Watcher) Reload() error {
+	next, err := ParseConfig(w.v)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	diff, err := Diff(w.reloaded, next)
+	if err != nil {
+		return err
+	}
+	w.reloaded = next
+	w.reloadedAt = time.Now()
+	w.lastDiff = diff
+
+	return nil
+}
+
+// Inspect effective config
+func (w *Watcher) Snapshot() (*Snapshot, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	settings, err := Settings(w.v, w.effective)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := Diff(w.effective, w.reloaded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		LoadedAt:       w.loadedAt,
+		ReloadedAt:     w.reloadedAt,
+		Settings:       settings,
+		LastReloadDiff: w.lastDiff,
+		PendingDiff:    pending,
+	}, nil
+}
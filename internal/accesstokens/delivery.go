@@ -0,0 +1,10 @@
+package accesstokens
+
+import "github.com/labstack/echo/v4"
+
+// Personal access tokens HTTP Handlers interface
+type Handlers interface {
+	ListTokens() echo.HandlerFunc
+	CreateToken() echo.HandlerFunc
+	RevokeToken() echo.HandlerFunc
+}
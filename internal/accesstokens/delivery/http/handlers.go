@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Personal access tokens handlers
+type tokensHandlers struct {
+	cfg      *config.Config
+	tokensUC accesstokens.UseCase
+	logger   logger.Logger
+}
+
+// NewAccessTokensHandlers Personal access tokens handlers constructor
+func NewAccessTokensHandlers(cfg *config.Config, tokensUC accesstokens.UseCase, log logger.Logger) accesstokens.Handlers {
+	return &tokensHandlers{cfg: cfg, tokensUC: tokensUC, logger: log}
+}
+
+// ListTokens godoc
+// @Summary List personal access tokens
+// @Description Unrevoked personal access tokens of the current user with their last use, plaintexts are not returned
+// @Tags AccessTokens
+// @Produce json
+// @Success 200 {array} models.PersonalAccessToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/me/tokens [get]
+func (h *tokensHandlers) ListTokens() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "tokensHandlers.ListTokens")
+		defer span.Finish()
+
+		tokens, err := h.tokensUC.ListTokens(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, tokens)
+	}
+}
+
+// CreateToken godoc
+// @Summary Create personal access token
+// @Description Mint a personal access token of the current user, needs a recent login or re-authentication. The token is only returned in this response
+// @Tags AccessTokens
+// @Accept json
+// @Produce json
+// @Param body body dto.PersonalAccessTokenRequest true "token"
+// @Success 201 {object} models.PersonalAccessToken
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/tokens [post]
+func (h *tokensHandlers) CreateToken() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "tokensHandlers.CreateToken")
+		defer span.Finish()
+
+		req := &dto.PersonalAccessTokenRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.tokensUC.CreateToken(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// RevokeToken godoc
+// @Summary Revoke personal access token
+// @Description Revoke a personal access token of the current user
+// @Tags AccessTokens
+// @Param id path int true "token_id"
+// @Success 204
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/tokens/{id} [delete]
+func (h *tokensHandlers) RevokeToken() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "tokensHandlers.RevokeToken")
+		defer span.Finish()
+
+		tokenID, err := strconv.ParseInt(c.Param("token_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.tokensUC.RevokeToken(ctx, tokenID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map personal access token routes under /me/tokens, authGroup must already carry the JWT and session middlewares
+func MapAccessTokensRoutes(authGroup *echo.Group, h accesstokens.Handlers, mw *middleware.MiddlewareManager) {
+	tokensGroup := authGroup.Group("/me/tokens")
+
+	tokensGroup.GET("", h.ListTokens())
+	tokensGroup.POST("", h.CreateToken(), mw.CSRF, mw.StepUp)
+	tokensGroup.DELETE("/:token_id", h.RevokeToken(), mw.CSRF)
+}
@@ -0,0 +1,20 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package accesstokens
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Personal access tokens repository interface, revoked tokens are never returned. Tokens are looked up by the
+// sha256 hex of their plaintext, which is not stored
+type Repository interface {
+	ListTokens(ctx context.Context, userID int) ([]*models.PersonalAccessToken, error)
+	CreateToken(ctx context.Context, token *models.PersonalAccessToken, hash string) (*models.PersonalAccessToken, error)
+	// Revoke token of a user, sql.ErrNoRows when it does not exist or is already revoked
+	RevokeToken(ctx context.Context, userID int, tokenID int64, now time.Time) error
+	GetTokenByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error)
+	TouchToken(ctx context.Context, tokenID int64, now time.Time) error
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Token kept in memory with the hash of its plaintext
+type memoryToken struct {
+	token   models.PersonalAccessToken
+	hash    string
+	revoked bool
+}
+
+// Personal access tokens Repository kept in process memory, dev mode stand-in for Postgres
+type tokensMemoryRepo struct {
+	mu     sync.RWMutex
+	lastID int64
+	tokens map[int64]*memoryToken
+}
+
+// Personal access tokens in-memory Repository constructor
+func NewAccessTokensMemoryRepository() accesstokens.Repository {
+	return &tokensMemoryRepo{tokens: make(map[int64]*memoryToken)}
+}
+
+// List tokens of a user
+func (r *tokensMemoryRepo) ListTokens(ctx context.Context, userID int) ([]*models.PersonalAccessToken, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "tokensMemoryRepo.ListTokens")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	for _, stored := range r.tokens {
+		if stored.token.UserID == userID && !stored.revoked {
+			tokens = append(tokens, stored.copy())
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// Create token stored under the hash of its plaintext
+func (r *tokensMemoryRepo) CreateToken(ctx context.Context, token *models.PersonalAccessToken, hash string) (*models.PersonalAccessToken, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "tokensMemoryRepo.CreateToken")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	stored := &memoryToken{token: *token, hash: hash}
+	stored.token.ID = r.lastID
+	stored.token.Token = ""
+	stored.token.Scopes = append([]string(nil), token.Scopes...)
+	stored.token.LastUsedAt = nil
+	stored.token.CreatedAt = time.Now()
+	r.tokens[stored.token.ID] = stored
+	return stored.copy(), nil
+}
+
+// Revoke token of a user
+func (r *tokensMemoryRepo) RevokeToken(ctx context.Context, userID int, tokenID int64, now time.Time) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "tokensMemoryRepo.RevokeToken")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[tokenID]
+	if !ok || stored.token.UserID != userID || stored.revoked {
+		return errors.Wrap(sql.ErrNoRows, "tokensMemoryRepo.RevokeToken")
+	}
+	stored.revoked = true
+	return nil
+}
+
+// Get unrevoked token by the hash of its plaintext
+func (r *tokensMemoryRepo) GetTokenByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "tokensMemoryRepo.GetTokenByHash")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.tokens {
+		if stored.hash == hash && !stored.revoked {
+			return stored.copy(), nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "tokensMemoryRepo.GetTokenByHash")
+}
+
+// Record use of token
+func (r *tokensMemoryRepo) TouchToken(ctx context.Context, tokenID int64, now time.Time) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "tokensMemoryRepo.TouchToken")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.tokens[tokenID]; ok {
+		stored.token.LastUsedAt = &now
+	}
+	return nil
+}
+
+func (t *memoryToken) copy() *models.PersonalAccessToken {
+	token := t.token
+	token.Scopes = append([]string(nil), t.token.Scopes...)
+	return &token
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Stored token, scopes are kept space separated
+type tokenRow struct {
+	ID         int64      `db:"id"`
+	UserID     int        `db:"user_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	Scopes     string     `db:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (row *tokenRow) toToken() *models.PersonalAccessToken {
+	return &models.PersonalAccessToken{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		Scopes:     strings.Fields(row.Scopes),
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+		CreatedAt:  row.CreatedAt,
+	}
+}
+
+// Personal access tokens Repository
+type tokensRepo struct {
+	db *sqlx.DB
+}
+
+// Personal access tokens Repository constructor
+func NewAccessTokensRepository(db *sqlx.DB) accesstokens.Repository {
+	return &tokensRepo{db: db}
+}
+
+// List tokens of a user
+func (r *tokensRepo) ListTokens(ctx context.Context, userID int) ([]*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensRepo.ListTokens")
+	defer span.Finish()
+
+	rows := make([]*tokenRow, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, listTokensQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "tokensRepo.ListTokens.SelectContext")
+	}
+
+	tokens := make([]*models.PersonalAccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.toToken())
+	}
+	return tokens, nil
+}
+
+// Create token stored under the hash of its plaintext
+func (r *tokensRepo) CreateToken(ctx context.Context, token *models.PersonalAccessToken, hash string) (*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensRepo.CreateToken")
+	defer span.Finish()
+
+	row := &tokenRow{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createTokenQuery,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		hash,
+		token.Scope(),
+		token.ExpiresAt,
+	).StructScan(row); err != nil {
+		return nil, errors.Wrap(err, "tokensRepo.CreateToken.StructScan")
+	}
+	return row.toToken(), nil
+}
+
+// Revoke token of a user
+func (r *tokensRepo) RevokeToken(ctx context.Context, userID int, tokenID int64, now time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensRepo.RevokeToken")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, revokeTokenQuery, tokenID, userID, now)
+	if err != nil {
+		return errors.Wrap(err, "tokensRepo.RevokeToken.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "tokensRepo.RevokeToken.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "tokensRepo.RevokeToken")
+	}
+	return nil
+}
+
+// Get unrevoked token by the hash of its plaintext
+func (r *tokensRepo) GetTokenByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensRepo.GetTokenByHash")
+	defer span.Finish()
+
+	row := &tokenRow{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, row, getTokenByHashQuery, hash); err != nil {
+		return nil, errors.Wrap(err, "tokensRepo.GetTokenByHash.GetContext")
+	}
+	return row.toToken(), nil
+}
+
+// Record use of token
+func (r *tokensRepo) TouchToken(ctx context.Context, tokenID int64, now time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensRepo.TouchToken")
+	defer span.Finish()
+
+	if _, err := tenant.DB(ctx, r.db).ExecContext(ctx, touchTokenQuery, tokenID, now); err != nil {
+		return errors.Wrap(err, "tokensRepo.TouchToken.ExecContext")
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package repository
+
+const (
+	listTokensQuery = `SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+						FROM personal_access_tokens
+						WHERE user_id = $1 AND revoked_at IS NULL
+						ORDER BY id`
+
+	createTokenQuery = `INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, now())
+						RETURNING id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at`
+
+	revokeTokenQuery = `UPDATE personal_access_tokens SET revoked_at = $3
+						WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	getTokenByHashQuery = `SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+						FROM personal_access_tokens
+						WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	touchTokenQuery = `UPDATE personal_access_tokens SET last_used_at = $2 WHERE id = $1`
+)
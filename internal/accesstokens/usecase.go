@@ -0,0 +1,20 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package accesstokens
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Personal access tokens UseCase interface, management methods act on the user of the context
+type UseCase interface {
+	ListTokens(ctx context.Context) ([]*models.PersonalAccessToken, error)
+	// Mint a token, the plaintext is only returned here
+	CreateToken(ctx context.Context, req *dto.PersonalAccessTokenRequest) (*models.PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, tokenID int64) error
+	// Token of the plaintext, sql.ErrNoRows when it is unknown, revoked or expired. Records its use
+	Authenticate(ctx context.Context, token string) (*models.PersonalAccessToken, error)
+}
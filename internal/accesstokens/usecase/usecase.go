@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	// Characters of the plaintext kept to tell tokens apart, the prefix and a few random ones
+	displayPrefixLen = len(models.PersonalAccessTokenPrefix) + 6
+
+	auditActionTokenCreated = "access_token.created"
+	auditActionTokenRevoked = "access_token.revoked"
+
+	errTokenLimit    = "Personal access token limit reached"
+	errTokenExpiry   = "Personal access token must expire in the future"
+	errTokenLifetime = "Personal access token expires after the maximum lifetime"
+	errTokenByToken  = "Personal access tokens cannot manage personal access tokens"
+)
+
+// Personal access tokens UseCase
+type tokensUC struct {
+	cfg     *config.Config
+	repo    accesstokens.Repository
+	auditUC audit.UseCase
+	clock   clock.Clock
+	logger  logger.Logger
+}
+
+// Personal access tokens UseCase constructor
+func NewAccessTokensUseCase(
+	cfg *config.Config,
+	repo accesstokens.Repository,
+	auditUC audit.UseCase,
+	clk clock.Clock,
+	logger logger.Logger,
+) accesstokens.UseCase {
+	return &tokensUC{cfg: cfg, repo: repo, auditUC: auditUC, clock: clk, logger: logger}
+}
+
+// List tokens of the current user including expired ones, plaintexts are never returned
+func (u *tokensUC) ListTokens(ctx context.Context) ([]*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensUC.ListTokens")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.ListTokens(ctx, userID)
+}
+
+// Mint token of the current user, up to AccessTokens.MaxPerUser unexpired ones. Without an expiry it gets the
+// maximum lifetime when one is configured. The plaintext is only returned here
+func (u *tokensUC) CreateToken(ctx context.Context, req *dto.PersonalAccessTokenRequest) (*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensUC.CreateToken")
+	defer span.Finish()
+
+	userID, err := u.managingUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := u.clock.Now()
+	expiresAt := req.ExpiresAt
+	if days := u.cfg.AccessTokens.MaxLifetimeDays; days > 0 {
+		maxExpiry := now.Add(time.Duration(days) * 24 * time.Hour)
+		if expiresAt == nil {
+			expiresAt = &maxExpiry
+		} else if expiresAt.After(maxExpiry) {
+			return nil, httpErrors.NewBadRequestError(errTokenLifetime)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, httpErrors.NewBadRequestError(errTokenExpiry)
+	}
+
+	existing, err := u.repo.ListTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, token := range existing {
+		if !token.Expired(now) {
+			active++
+		}
+	}
+	if limit := u.cfg.AccessTokens.MaxPerUser; limit > 0 && active >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errTokenLimit, limit)
+	}
+
+	plaintext, err := generateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "tokensUC.CreateToken.generateToken")
+	}
+	created, err := u.repo.CreateToken(ctx, &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    plaintext[:displayPrefixLen],
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, hashToken(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	created.Token = plaintext
+
+	u.record(ctx, auditActionTokenCreated, userID, map[string]interface{}{
+		"token_id":   created.ID,
+		"name":       created.Name,
+		"scopes":     created.Scopes,
+		"expires_at": created.ExpiresAt,
+	})
+	return created, nil
+}
+
+// Revoke token of the current user, it is refused from then on
+func (u *tokensUC) RevokeToken(ctx context.Context, tokenID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensUC.RevokeToken")
+	defer span.Finish()
+
+	userID, err := u.managingUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.RevokeToken(ctx, userID, tokenID, u.clock.Now()); err != nil {
+		return err
+	}
+
+	u.record(ctx, auditActionTokenRevoked, userID, map[string]interface{}{"token_id": tokenID})
+	return nil
+}
+
+// Token of the plaintext when it is neither revoked nor expired. Its use is recorded at most every
+// AccessTokens.TouchIntervalSeconds, a failing write is logged only
+func (u *tokensUC) Authenticate(ctx context.Context, token string) (*models.PersonalAccessToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tokensUC.Authenticate")
+	defer span.Finish()
+
+	if !strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return nil, errors.Wrap(sql.ErrNoRows, "tokensUC.Authenticate")
+	}
+	found, err := u.repo.GetTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	now := u.clock.Now()
+	if found.Expired(now) {
+		return nil, errors.Wrap(sql.ErrNoRows, "tokensUC.Authenticate.Expired")
+	}
+
+	interval := time.Duration(u.cfg.AccessTokens.TouchIntervalSeconds) * time.Second
+	if found.LastUsedAt == nil || now.Sub(*found.LastUsedAt) >= interval {
+		if err := u.repo.TouchToken(ctx, found.ID, now); err != nil {
+			u.logger.Errorf("tokensUC.Authenticate.TouchToken tokenID: %d, error: %v", found.ID, err)
+		} else {
+			found.LastUsedAt = &now
+		}
+	}
+	return found, nil
+}
+
+// User managing their tokens, a token could otherwise mint itself a longer lived or broader successor
+func (u *tokensUC) managingUserID(ctx context.Context) (int, error) {
+	if principal, ok := requestctx.Principal.From(ctx); ok && principal.Source == models.PrincipalSourceToken {
+		return 0, httpErrors.NewForbiddenError(errTokenByToken)
+	}
+	return currentUserID(ctx)
+}
+
+func (u *tokensUC) record(ctx context.Context, action string, userID int, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	event := &models.AuditEvent{
+		ActorID:   &userID,
+		RequestID: requestID,
+		Resource:  "user:" + strconv.Itoa(userID),
+	}
+	if err := u.auditUC.Record(ctx, action, event, metadata); err != nil {
+		u.logger.Errorf("tokensUC.record.Record action: %s, error: %v", action, err)
+	}
+}
+
+func currentUserID(ctx context.Context) (int, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return 0, httpErrors.NewUnauthorizedError(err)
+	}
+	return user.User.ID, nil
+}
+
+func generateToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return models.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestTokensUC_CreateToken(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{AccessTokens: config.AccessTokens{MaxPerUser: 1, MaxLifetimeDays: 30}}
+	now := time.Now()
+	uc := NewAccessTokensUseCase(cfg, repository.NewAccessTokensMemoryRepository(), nil, clock.NewFrozen(now), testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+
+	_, err := uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{"write:everything"}})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	past := now.Add(-time.Minute)
+	_, err = uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{models.ScopeAPI}, ExpiresAt: &past})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	tooLate := now.Add(31 * 24 * time.Hour)
+	_, err = uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{models.ScopeAPI}, ExpiresAt: &tooLate})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+
+	// Without an expiry the token gets the maximum lifetime, the plaintext is returned once
+	created, err := uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{
+		Name:   " ci ",
+		Scopes: []string{models.ScopeReadProfile, models.ScopeReadProfile},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ci", created.Name)
+	require.Equal(t, []string{models.ScopeReadProfile}, created.Scopes)
+	require.True(t, strings.HasPrefix(created.Token, models.PersonalAccessTokenPrefix))
+	require.True(t, strings.HasPrefix(created.Token, created.Prefix))
+	require.Equal(t, now.Add(30*24*time.Hour), *created.ExpiresAt)
+
+	tokens, err := uc.ListTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Empty(t, tokens[0].Token)
+
+	_, err = uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{Name: "laptop", Scopes: []string{models.ScopeAPI}})
+	require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+
+	// Tokens cannot mint or revoke tokens
+	tokenCtx := requestctx.Principal.With(ctx, &models.Principal{Source: models.PrincipalSourceToken})
+	_, err = uc.CreateToken(tokenCtx, &dto.PersonalAccessTokenRequest{Name: "laptop", Scopes: []string{models.ScopeAPI}})
+	require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(uc.RevokeToken(tokenCtx, created.ID)).Status())
+}
+
+func TestTokensUC_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{AccessTokens: config.AccessTokens{TouchIntervalSeconds: 60}}
+	clk := clock.NewFrozen(time.Now())
+	uc := NewAccessTokensUseCase(cfg, repository.NewAccessTokensMemoryRepository(), nil, clk, testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+	expiresAt := clk.Now().Add(time.Hour)
+	created, err := uc.CreateToken(ctx, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{models.ScopeAPI}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+
+	_, err = uc.Authenticate(ctx, created.Token+"x")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Use is recorded at most once per touch interval
+	found, err := uc.Authenticate(ctx, created.Token)
+	require.NoError(t, err)
+	require.Equal(t, 1, found.UserID)
+	firstUse := clk.Now()
+	require.Equal(t, firstUse, *found.LastUsedAt)
+	clk.Advance(30 * time.Second)
+	found, err = uc.Authenticate(ctx, created.Token)
+	require.NoError(t, err)
+	require.Equal(t, firstUse, *found.LastUsedAt)
+	clk.Advance(30 * time.Second)
+	found, err = uc.Authenticate(ctx, created.Token)
+	require.NoError(t, err)
+	require.Equal(t, clk.Now(), *found.LastUsedAt)
+
+	clk.Advance(time.Hour)
+	_, err = uc.Authenticate(ctx, created.Token)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Revoked tokens are refused and no longer listed
+	clk.Advance(-time.Hour)
+	require.NoError(t, uc.RevokeToken(ctx, created.ID))
+	require.ErrorIs(t, uc.RevokeToken(ctx, created.ID), sql.ErrNoRows)
+	_, err = uc.Authenticate(ctx, created.Token)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	tokens, err := uc.ListTokens(ctx)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
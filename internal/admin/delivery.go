@@ -0,0 +1,15 @@
+package admin
+
+import "github.com/labstack/echo/v4"
+
+// Admin HTTP Handlers interface
+type Handlers interface {
+	GetConfig() echo.HandlerFunc
+	GetSLO() echo.HandlerFunc
+	RevokeSessions() echo.HandlerFunc
+	GetSessionEvents() echo.HandlerFunc
+	GetUserSessionEvents() echo.HandlerFunc
+	BatchUsers() echo.HandlerFunc
+	UI() echo.HandlerFunc
+	UILogin() echo.HandlerFunc
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/admin"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/slo"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Admin handlers
+type adminHandlers struct {
+	cfg        *config.Config
+	cfgWatcher *config.Watcher
+	authUC     auth.UseCase
+	sessUC     session.UCSession
+	objectives *slo.Tracker
+	logger     logger.Logger
+}
+
+// NewAdminHandlers Admin handlers constructor
+func NewAdminHandlers(
+	cfg *config.Config,
+	cfgWatcher *config.Watcher,
+	authUC auth.UseCase,
+	sessUC session.UCSession,
+	objectives *slo.Tracker,
+	log logger.Logger,
+) admin.Handlers {
+	return &adminHandlers{cfg: cfg, cfgWatcher: cfgWatcher, authUC: authUC, sessUC: sessUC, objectives: objectives, logger: log}
+}
+
+// GetConfig godoc
+// @Summary Get effective config
+// @Description Effective runtime configuration with secrets redacted, value sources and reload diff, admin only
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} config.Snapshot
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/config [get]
+func (h *adminHandlers) GetConfig() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, _ := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.GetConfig")
+		defer span.Finish()
+
+		snapshot, err := h.cfgWatcher.Snapshot()
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, snapshot)
+	}
+}
+
+// GetSLO godoc
+// @Summary Get SLO status
+// @Description Error budget and burn rates of every service level objective as seen by this instance, admin only
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} slo.Status
+// @Router /admin/slo [get]
+func (h *adminHandlers) GetSLO() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, _ := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.GetSLO")
+		defer span.Finish()
+
+		return c.JSON(http.StatusOK, h.objectives.Summary())
+	}
+}
+
+// RevokeSessions godoc
+// @Summary Revoke sessions by criteria
+// @Description Revoke every session matching user ids, ip range, creation time and tenant, dry_run only counts matches, admin only
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param criteria body models.SessionRevokeCriteria true "revocation criteria"
+// @Success 200 {object} models.SessionRevokeResult
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/sessions/revoke [post]
+func (h *adminHandlers) RevokeSessions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.RevokeSessions")
+		defer span.Finish()
+
+		criteria := &models.SessionRevokeCriteria{}
+		if err := utils.ReadRequest(c, criteria); err != nil {
+			return err
+		}
+
+		result, err := h.sessUC.RevokeSessions(ctx, criteria)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// GetSessionEvents godoc
+// @Summary Session events of all users
+// @Description Session lifecycle events of every user newest first, created, refreshed, revoked, expired and hijack_suspected, admin only
+// @Tags Admin
+// @Produce json
+// @Param before query string false "cursor returned as next_cursor by the previous call"
+// @Param limit query int false "max events, default 50, max 500"
+// @Success 200 {object} models.SessionEvents
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/sessions/events [get]
+func (h *adminHandlers) GetSessionEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.GetSessionEvents")
+		defer span.Finish()
+
+		limit, err := eventLimit(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error())
+		}
+
+		events, err := h.sessUC.ListEvents(ctx, c.QueryParam("before"), limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
+
+// GetUserSessionEvents godoc
+// @Summary Session events of a user
+// @Description Session lifecycle events of one user newest first, admin only
+// @Tags Admin
+// @Produce json
+// @Param user_id path int true "user id"
+// @Param before query string false "cursor returned as next_cursor by the previous call"
+// @Param limit query int false "max events, default 50, max 500"
+// @Success 200 {object} models.SessionEvents
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/users/{user_id}/session-events [get]
+func (h *adminHandlers) GetUserSessionEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.GetUserSessionEvents")
+		defer span.Finish()
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error())
+		}
+		limit, err := eventLimit(c)
+		if err != nil {
+			return httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error())
+		}
+
+		events, err := h.sessUC.ListUserEvents(ctx, userID, c.QueryParam("before"), limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
+
+// Limit query param of event listings, 0 when unset so the usecase default applies
+func eventLimit(c echo.Context) (int, error) {
+	raw := c.QueryParam("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// BatchUsers godoc
+// @Summary Batch write users
+// @Description Create, update and delete users in one request, every operation succeeds or fails on its own and gets its status in results, admin only
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.UserBatchRequest true "operations"
+// @Success 207 {object} models.UserBatchResult
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 413 {object} httpErrors.RestError
+// @Router /admin/users/batch [post]
+func (h *adminHandlers) BatchUsers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "adminHandlers.BatchUsers")
+		defer span.Finish()
+
+		req := &models.UserBatchRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		result, err := h.authUC.BatchUsers(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusMultiStatus, result)
+	}
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/admin"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map admin routes, group is already restricted to administrators
+func MapAdminRoutes(adminGroup *echo.Group, h admin.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.GET("/config", h.GetConfig())
+	adminGroup.GET("/slo", h.GetSLO())
+	adminGroup.POST("/sessions/revoke", h.RevokeSessions(), mw.CSRF)
+	adminGroup.GET("/sessions/events", h.GetSessionEvents())
+	adminGroup.GET("/users/:user_id/session-events", h.GetUserSessionEvents())
+	adminGroup.POST("/users/batch", h.BatchUsers(), mw.CSRF)
+}
+
+// Map embedded admin panel, the sign in page goes on the public group, the panel on the admin group
+func MapAdminUIRoutes(publicGroup *echo.Group, adminGroup *echo.Group, h admin.Handlers) {
+	publicGroup.GET("/login", h.UILogin())
+	adminGroup.GET("/ui*", h.UI())
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	uiIndexFile = "index.html"
+	uiLoginFile = "login.html"
+)
+
+// Admin panel assets, the panel only calls the admin APIs and holds no state of its own
+//
+//go:embed ui
+var uiFiles embed.FS
+
+var uiFS, _ = fs.Sub(uiFiles, "ui")
+
+// UI godoc
+// @Summary Admin panel
+// @Description Embedded admin panel for users, sessions, jobs, feature flags and config, admin only
+// @Tags Admin
+// @Produce html
+// @Router /admin/ui/ [get]
+func (h *adminHandlers) UI() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		param := c.Param("*")
+		// Relative asset paths of the panel resolve against /ui/
+		if param == "" {
+			return c.Redirect(http.StatusFound, c.Request().URL.Path+"/")
+		}
+		name := strings.TrimPrefix(path.Clean(param), "/")
+		if name == "" || name == uiLoginFile {
+			name = uiIndexFile
+		}
+		if _, err := fs.Stat(uiFS, name); err != nil {
+			return c.NoContent(http.StatusNotFound)
+		}
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+		return echo.StaticFileHandler(name, uiFS)(c)
+	}
+}
+
+// UILogin godoc
+// @Summary Admin panel sign in
+// @Description Sign in page of the embedded admin panel, the only page served without a session
+// @Tags Admin
+// @Produce html
+// @Router /admin/login [get]
+func (h *adminHandlers) UILogin() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+		return echo.StaticFileHandler(uiLoginFile, uiFS)(c)
+	}
+}
@@ -0,0 +1,244 @@
+// Admin panel, every view calls the admin APIs with the session cookie of the signed in administrator.
+// Values are written with textContent only, nothing returned by the API is parsed as HTML.
+(() => {
+  'use strict';
+
+  const api = new URL('../../', window.location.href);
+  const status = document.getElementById('status');
+  let csrfToken = '';
+  let usersPage = 1;
+  let eventsBefore = '';
+
+  async function request(path, options = {}) {
+    const headers = Object.assign({'Content-Type': 'application/json'}, options.headers);
+    if (options.method && options.method !== 'GET') {
+      headers['X-CSRF-Token'] = await csrf();
+    }
+    const res = await fetch(new URL(path, api), Object.assign({}, options, {headers, credentials: 'same-origin'}));
+    if (res.status === 401) {
+      window.location.href = '../login';
+      throw new Error('signed out');
+    }
+    const body = res.status === 204 ? null : await res.json().catch(() => null);
+    if (!res.ok && res.status !== 207) {
+      throw new Error((body && (body.detail || body.error || body.message)) || res.statusText);
+    }
+    return body;
+  }
+
+  async function csrf() {
+    if (!csrfToken) {
+      const res = await fetch(new URL('auth/token', api), {credentials: 'same-origin'});
+      csrfToken = res.headers.get('X-CSRF-Token') || '';
+    }
+    return csrfToken;
+  }
+
+  function cell(row, value, className) {
+    const td = row.insertCell();
+    td.textContent = value === undefined || value === null ? '' : String(value);
+    if (className) {
+      td.className = className;
+    }
+    return td;
+  }
+
+  function button(row, label, onClick) {
+    const btn = document.createElement('button');
+    btn.textContent = label;
+    btn.addEventListener('click', onClick);
+    row.insertCell().appendChild(btn);
+  }
+
+  function show(text) {
+    status.textContent = text || '';
+  }
+
+  function formatValue(value) {
+    return typeof value === 'object' && value !== null ? JSON.stringify(value) : value;
+  }
+
+  const views = {
+    async users() {
+      const list = await request(`auth/all?page=${usersPage}&size=20`);
+      const rows = document.getElementById('users-rows');
+      rows.replaceChildren();
+      for (const user of list.users || []) {
+        const row = rows.insertRow();
+        cell(row, user.id);
+        cell(row, user.username);
+        cell(row, user.email);
+        cell(row, user.status);
+        cell(row, user.login_at);
+        button(row, 'Delete', async () => {
+          if (!window.confirm(`Delete user ${user.id}?`)) {
+            return;
+          }
+          const result = await request('admin/users/batch', {
+            method: 'POST',
+            body: JSON.stringify({operations: [{op: 'delete', user: {id: user.id}}]}),
+          });
+          const item = result.results[0];
+          show(item.error ? `Delete failed: ${item.error}` : `User ${user.id} deleted`);
+          await views.users();
+        });
+      }
+      document.getElementById('users-page').textContent = `Page ${list.page}`;
+    },
+
+    async sessions() {
+      const userID = parseInt(document.getElementById('events-user').value, 10);
+      const path = isNaN(userID) ? 'admin/sessions/events' : `admin/users/${userID}/session-events`;
+      const page = await request(`${path}?limit=50&before=${encodeURIComponent(eventsBefore)}`);
+      const rows = document.getElementById('events-rows');
+      rows.replaceChildren();
+      for (const event of page.events || []) {
+        const row = rows.insertRow();
+        cell(row, event.created_at);
+        cell(row, event.type, event.type === 'hijack_suspected' ? 'alert' : '');
+        cell(row, event.reason);
+        cell(row, event.user_id);
+        cell(row, event.session_id);
+        cell(row, event.ip_address);
+        cell(row, event.user_agent);
+      }
+      const older = document.getElementById('events-older');
+      older.disabled = !page.has_more;
+      older.dataset.cursor = page.next_cursor || '';
+    },
+
+    async jobs() {
+      const state = document.getElementById('jobs-state').value;
+      const [list, stats] = await Promise.all([request(`admin/jobs?state=${state}&size=50`), request('admin/jobs/stats')]);
+      document.getElementById('jobs-stats').textContent = JSON.stringify(stats, null, 2);
+      const rows = document.getElementById('jobs-rows');
+      rows.replaceChildren();
+      for (const job of list.jobs || []) {
+        const row = rows.insertRow();
+        cell(row, job.id);
+        cell(row, job.type);
+        cell(row, job.attempts);
+        cell(row, job.enqueued_at);
+        cell(row, job.last_error);
+        const action = state === 'dead' ? 'retry' : 'cancel';
+        button(row, action === 'retry' ? 'Retry' : 'Cancel', async () => {
+          await request(`admin/jobs/${encodeURIComponent(job.id)}/${action}`, {method: 'POST'});
+          await views.jobs();
+        });
+      }
+    },
+
+    async features() {
+      const snapshot = await request('admin/config');
+      const rows = document.getElementById('features-rows');
+      rows.replaceChildren();
+      for (const setting of snapshot.settings.filter((s) => s.key.startsWith('access.'))) {
+        const row = rows.insertRow();
+        cell(row, setting.key);
+        cell(row, formatValue(setting.value), 'value');
+        cell(row, setting.source);
+      }
+    },
+
+    async settings() {
+      const list = await request('admin/settings');
+      const rows = document.getElementById('settings-rows');
+      rows.replaceChildren();
+      for (const setting of list) {
+        const row = rows.insertRow();
+        cell(row, setting.key);
+        const input = document.createElement('input');
+        input.value = JSON.stringify(setting.value);
+        row.insertCell().appendChild(input);
+        cell(row, setting.updated_by ? `${setting.updated_at} by ${setting.updated_by}` : 'default');
+        button(row, 'Save', async () => {
+          try {
+            await request(`admin/settings/${encodeURIComponent(setting.key)}`, {
+              method: 'PUT',
+              body: JSON.stringify({value: JSON.parse(input.value)}),
+            });
+            show(`${setting.key} saved`);
+            await views.settings();
+          } catch (err) {
+            show(err.message);
+          }
+        });
+      }
+    },
+
+    async config() {
+      const snapshot = await request('admin/config');
+      const filter = document.getElementById('config-filter').value.toLowerCase();
+      const rows = document.getElementById('config-rows');
+      rows.replaceChildren();
+      for (const setting of snapshot.settings.filter((s) => s.key.includes(filter))) {
+        const row = rows.insertRow();
+        cell(row, setting.key);
+        cell(row, formatValue(setting.value), 'value');
+        cell(row, setting.source);
+      }
+      document.getElementById('config-pending').textContent = JSON.stringify(snapshot.pending_diff, null, 2);
+    },
+  };
+
+  async function route() {
+    const name = window.location.hash.slice(1) || 'users';
+    for (const section of document.querySelectorAll('main section')) {
+      section.hidden = section.id !== name;
+    }
+    show('');
+    if (views[name]) {
+      await views[name]().catch((err) => show(err.message));
+    }
+  }
+
+  document.getElementById('revoke-form').addEventListener('submit', async (event) => {
+    event.preventDefault();
+    const form = new FormData(event.target);
+    const criteria = {dry_run: form.get('dry_run') === 'on'};
+    const userIDs = String(form.get('user_ids')).split(',').map((id) => parseInt(id, 10)).filter((id) => !isNaN(id));
+    if (userIDs.length) {
+      criteria.user_ids = userIDs;
+    }
+    if (form.get('ip_range')) {
+      criteria.ip_range = form.get('ip_range');
+    }
+    if (form.get('tenant_id')) {
+      criteria.tenant_id = form.get('tenant_id');
+    }
+    if (form.get('created_before')) {
+      criteria.created_before = new Date(form.get('created_before')).toISOString();
+    }
+    try {
+      const result = await request('admin/sessions/revoke', {method: 'POST', body: JSON.stringify(criteria)});
+      document.getElementById('revoke-result').textContent = JSON.stringify(result, null, 2);
+    } catch (err) {
+      show(err.message);
+    }
+  });
+
+  for (const btn of document.querySelectorAll('[data-page]')) {
+    btn.addEventListener('click', () => {
+      usersPage = Math.max(1, usersPage + parseInt(btn.dataset.page, 10));
+      route();
+    });
+  }
+  document.getElementById('events-user').addEventListener('change', () => {
+    eventsBefore = '';
+    route();
+  });
+  document.getElementById('events-older').addEventListener('click', (event) => {
+    eventsBefore = event.target.dataset.cursor;
+    route();
+  });
+  document.getElementById('jobs-state').addEventListener('change', route);
+  document.getElementById('jobs-purge').addEventListener('click', async () => {
+    if (window.confirm('Purge all dead jobs?')) {
+      await request('admin/jobs/dead', {method: 'DELETE'}).catch((err) => show(err.message));
+      await route();
+    }
+  });
+  document.getElementById('config-filter').addEventListener('input', route);
+  window.addEventListener('hashchange', route);
+  route();
+})();
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/csrf"
+)
+
+func TestAdminHandlers_UI(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	h := &adminHandlers{}
+	signedIn := false
+	// Stands in for the session and admin middlewares of the admin group
+	adminOnly := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !signedIn {
+				return c.NoContent(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}
+	MapAdminUIRoutes(e.Group("/api/v1/admin"), e.Group("/api/v1/admin", adminOnly), h)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	// The sign in page is the only one served without a session
+	rec := get("/api/v1/admin/login")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<title>Admin sign in</title>")
+	require.Equal(t, "no-cache", rec.Header().Get(echo.HeaderCacheControl))
+	require.Equal(t, http.StatusUnauthorized, get("/api/v1/admin/ui/").Code)
+
+	signedIn = true
+	rec = get("/api/v1/admin/ui")
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "/api/v1/admin/ui/", rec.Header().Get(echo.HeaderLocation))
+
+	rec = get("/api/v1/admin/ui/")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/html")
+	index := rec.Body.String()
+	require.Contains(t, index, "app.js")
+	require.Equal(t, index, get("/api/v1/admin/ui/login.html").Body.String())
+
+	// Mutating calls of the panel carry the token the CSRF middleware checks
+	rec = get("/api/v1/admin/ui/app.js")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get(echo.HeaderContentType), "javascript")
+	require.Contains(t, rec.Body.String(), "'"+csrf.CSRFHeader+"'")
+
+	require.Equal(t, http.StatusNotFound, get("/api/v1/admin/ui/missing.js").Code)
+	require.Equal(t, http.StatusNotFound, get("/api/v1/admin/ui/../ui.go").Code)
+}
@@ -0,0 +1,18 @@
+package anonymize
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// PII columns of a database copy, values are read decrypted and written back encrypted with fresh blind indexes.
+// Rows are walked by id so ids and every reference to them stay untouched.
+type Repository interface {
+	ListUsers(ctx context.Context, afterID int, limit int) ([]*models.User, error)
+	UpdateUsers(ctx context.Context, users []*models.User) error
+	ListAddresses(ctx context.Context, afterID int64, limit int) ([]*models.Address, error)
+	UpdateAddresses(ctx context.Context, addresses []*models.Address) error
+	ListPhones(ctx context.Context, afterID int64, limit int) ([]*models.PhoneNumber, error)
+	UpdatePhones(ctx context.Context, phones []*models.PhoneNumber) error
+}
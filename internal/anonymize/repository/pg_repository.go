@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+)
+
+// Associated data of encrypted columns, must match the auth and contacts repositories
+const (
+	piiFieldEmail        = "users.email"
+	piiFieldPhone        = "users.phone"
+	piiFieldAddressLine1 = "user_addresses.line1"
+	piiFieldAddressLine2 = "user_addresses.line2"
+	piiFieldPhoneNumber  = "user_phones.number"
+)
+
+// Anonymize Repository
+type anonymizeRepo struct {
+	db     *sqlx.DB
+	cipher *pii.Cipher
+}
+
+// Anonymize Repository constructor, cipher is the one the copy was encrypted with and may be nil
+func NewAnonymizeRepository(db *sqlx.DB, cipher *pii.Cipher) anonymize.Repository {
+	return &anonymizeRepo{db: db, cipher: cipher}
+}
+
+// Users after afterID by id with email and phone decrypted
+func (r *anonymizeRepo) ListUsers(ctx context.Context, afterID int, limit int) ([]*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.ListUsers")
+	defer span.Finish()
+
+	users := make([]*models.User, 0, limit)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &users, listUsersQuery, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "anonymizeRepo.ListUsers.SelectContext")
+	}
+	for _, user := range users {
+		email, err := r.cipher.Decrypt(piiFieldEmail, user.Email)
+		if err != nil {
+			return nil, errors.Wrapf(err, "anonymizeRepo.ListUsers user %d", user.ID)
+		}
+		phone, err := r.cipher.Decrypt(piiFieldPhone, user.Phone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "anonymizeRepo.ListUsers user %d", user.ID)
+		}
+		user.Email, user.Phone = email, phone
+	}
+	return users, nil
+}
+
+// Write username, email and phone of a batch in one transaction
+func (r *anonymizeRepo) UpdateUsers(ctx context.Context, users []*models.User) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.UpdateUsers")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdateUsers.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	for _, user := range users {
+		email, err := r.cipher.Encrypt(piiFieldEmail, user.Email)
+		if err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateUsers user %d", user.ID)
+		}
+		phone, err := r.cipher.Encrypt(piiFieldPhone, user.Phone)
+		if err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateUsers user %d", user.ID)
+		}
+		if _, err := tx.ExecContext(ctx, updateUserQuery,
+			user.Username,
+			email,
+			r.cipher.BlindIndex(piiFieldEmail, user.Email),
+			phone,
+			r.cipher.BlindIndex(piiFieldPhone, user.Phone),
+			user.ID,
+		); err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateUsers.ExecContext user %d", user.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdateUsers.Commit")
+	}
+	return nil
+}
+
+// Addresses after afterID by id with their lines decrypted
+func (r *anonymizeRepo) ListAddresses(ctx context.Context, afterID int64, limit int) ([]*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.ListAddresses")
+	defer span.Finish()
+
+	addresses := make([]*models.Address, 0, limit)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &addresses, listAddressesQuery, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "anonymizeRepo.ListAddresses.SelectContext")
+	}
+	for _, address := range addresses {
+		line1, err := r.cipher.Decrypt(piiFieldAddressLine1, address.Line1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "anonymizeRepo.ListAddresses address %d", address.ID)
+		}
+		line2, err := r.cipher.Decrypt(piiFieldAddressLine2, address.Line2)
+		if err != nil {
+			return nil, errors.Wrapf(err, "anonymizeRepo.ListAddresses address %d", address.ID)
+		}
+		address.Line1, address.Line2 = line1, line2
+	}
+	return addresses, nil
+}
+
+// Write address lines and postal codes of a batch in one transaction
+func (r *anonymizeRepo) UpdateAddresses(ctx context.Context, addresses []*models.Address) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.UpdateAddresses")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdateAddresses.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	for _, address := range addresses {
+		line1, err := r.cipher.Encrypt(piiFieldAddressLine1, address.Line1)
+		if err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateAddresses address %d", address.ID)
+		}
+		line2, err := r.cipher.Encrypt(piiFieldAddressLine2, address.Line2)
+		if err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateAddresses address %d", address.ID)
+		}
+		if _, err := tx.ExecContext(ctx, updateAddressQuery, line1, line2, address.PostalCode, address.ID); err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdateAddresses.ExecContext address %d", address.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdateAddresses.Commit")
+	}
+	return nil
+}
+
+// Contact phones after afterID by id with their numbers decrypted
+func (r *anonymizeRepo) ListPhones(ctx context.Context, afterID int64, limit int) ([]*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.ListPhones")
+	defer span.Finish()
+
+	phones := make([]*models.PhoneNumber, 0, limit)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &phones, listPhonesQuery, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "anonymizeRepo.ListPhones.SelectContext")
+	}
+	for _, phone := range phones {
+		number, err := r.cipher.Decrypt(piiFieldPhoneNumber, phone.Number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "anonymizeRepo.ListPhones phone %d", phone.ID)
+		}
+		phone.Number = number
+	}
+	return phones, nil
+}
+
+// Write contact phone numbers of a batch in one transaction
+func (r *anonymizeRepo) UpdatePhones(ctx context.Context, phones []*models.PhoneNumber) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeRepo.UpdatePhones")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdatePhones.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	for _, phone := range phones {
+		number, err := r.cipher.Encrypt(piiFieldPhoneNumber, phone.Number)
+		if err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdatePhones phone %d", phone.ID)
+		}
+		if _, err := tx.ExecContext(ctx, updatePhoneQuery, number, phone.ID); err != nil {
+			return errors.Wrapf(err, "anonymizeRepo.UpdatePhones.ExecContext phone %d", phone.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "anonymizeRepo.UpdatePhones.Commit")
+	}
+	return nil
+}
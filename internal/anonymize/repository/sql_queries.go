@@ -0,0 +1,29 @@
+package repository
+
+const (
+	listUsersQuery = `SELECT id, username, email, COALESCE(phone, '') AS phone
+						FROM users
+						WHERE id > $1
+						ORDER BY id
+						LIMIT $2`
+
+	updateUserQuery = `UPDATE users
+						SET username = $1, email = $2, email_bidx = NULLIF($3, ''), phone = NULLIF($4, ''), phone_bidx = NULLIF($5, '')
+						WHERE id = $6`
+
+	listAddressesQuery = `SELECT id, user_id, line1, line2, postal_code
+						FROM user_addresses
+						WHERE id > $1
+						ORDER BY id
+						LIMIT $2`
+
+	updateAddressQuery = `UPDATE user_addresses SET line1 = $1, line2 = $2, postal_code = $3 WHERE id = $4`
+
+	listPhonesQuery = `SELECT id, user_id, number
+						FROM user_phones
+						WHERE id > $1
+						ORDER BY id
+						LIMIT $2`
+
+	updatePhoneQuery = `UPDATE user_phones SET number = $1 WHERE id = $2`
+)
@@ -0,0 +1,14 @@
+package anonymize
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Anonymization of a copied database for non-production environments
+type UseCase interface {
+	// Rewrite user names, emails, phones and contact addresses with deterministic fakes in batches,
+	// with dryRun only count the rows
+	Run(ctx context.Context, batchSize int, dryRun bool) (*models.AnonymizeReport, error)
+}
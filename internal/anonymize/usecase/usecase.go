@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	anonymizer "github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Anonymize UseCase
+type anonymizeUC struct {
+	repo   anonymize.Repository
+	faker  *anonymizer.Faker
+	logger logger.Logger
+}
+
+// Anonymize UseCase constructor
+func NewAnonymizeUseCase(repo anonymize.Repository, faker *anonymizer.Faker, log logger.Logger) anonymize.UseCase {
+	return &anonymizeUC{repo: repo, faker: faker, logger: log}
+}
+
+// Walk users, addresses and contact phones by id. Each batch commits on its own, an interrupted run is resumed
+// by running again: already anonymized rows just get other fakes. The same value maps to the same fake in every
+// table, so a login phone also stored as a contact phone stays equal.
+func (u *anonymizeUC) Run(ctx context.Context, batchSize int, dryRun bool) (*models.AnonymizeReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "anonymizeUC.Run")
+	defer span.Finish()
+
+	report := &models.AnonymizeReport{DryRun: dryRun}
+	if err := u.users(ctx, batchSize, report); err != nil {
+		return report, err
+	}
+	if err := u.addresses(ctx, batchSize, report); err != nil {
+		return report, err
+	}
+	if err := u.phones(ctx, batchSize, report); err != nil {
+		return report, err
+	}
+
+	u.logger.Infof("anonymizeUC.Run users: %d, addresses: %d, phones: %d, dry run: %v", report.Users, report.Addresses, report.Phones, dryRun)
+	return report, nil
+}
+
+func (u *anonymizeUC) users(ctx context.Context, batchSize int, report *models.AnonymizeReport) error {
+	afterID := 0
+	for {
+		users, err := u.repo.ListUsers(ctx, afterID, batchSize)
+		if err != nil || len(users) == 0 {
+			return err
+		}
+		for _, user := range users {
+			user.Username = u.faker.Username(user.Username)
+			user.Email = u.faker.Email(user.Email)
+			user.Phone = u.faker.Phone(user.Phone)
+			afterID = user.ID
+		}
+		if !report.DryRun {
+			if err := u.repo.UpdateUsers(ctx, users); err != nil {
+				return err
+			}
+		}
+		report.Users += len(users)
+	}
+}
+
+func (u *anonymizeUC) addresses(ctx context.Context, batchSize int, report *models.AnonymizeReport) error {
+	afterID := int64(0)
+	for {
+		addresses, err := u.repo.ListAddresses(ctx, afterID, batchSize)
+		if err != nil || len(addresses) == 0 {
+			return err
+		}
+		for _, address := range addresses {
+			address.Line1 = u.faker.StreetLine(address.Line1)
+			address.Line2 = u.faker.SecondaryLine(address.Line2)
+			address.PostalCode = u.faker.PostalCode(address.PostalCode)
+			afterID = address.ID
+		}
+		if !report.DryRun {
+			if err := u.repo.UpdateAddresses(ctx, addresses); err != nil {
+				return err
+			}
+		}
+		report.Addresses += len(addresses)
+	}
+}
+
+func (u *anonymizeUC) phones(ctx context.Context, batchSize int, report *models.AnonymizeReport) error {
+	afterID := int64(0)
+	for {
+		phones, err := u.repo.ListPhones(ctx, afterID, batchSize)
+		if err != nil || len(phones) == 0 {
+			return err
+		}
+		for _, phone := range phones {
+			phone.Number = u.faker.Phone(phone.Number)
+			afterID = phone.ID
+		}
+		if !report.DryRun {
+			if err := u.repo.UpdatePhones(ctx, phones); err != nil {
+				return err
+			}
+		}
+		report.Phones += len(phones)
+	}
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	anonymizer "github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+type memoryRepo struct {
+	anonymize.Repository
+	users     map[int]models.User
+	addresses map[int64]models.Address
+	phones    map[int64]models.PhoneNumber
+}
+
+func (r *memoryRepo) ListUsers(_ context.Context, afterID int, limit int) ([]*models.User, error) {
+	users := make([]*models.User, 0, limit)
+	for _, id := range sortedIDs(r.users) {
+		if id > afterID && len(users) < limit {
+			user := r.users[id]
+			users = append(users, &user)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryRepo) UpdateUsers(_ context.Context, users []*models.User) error {
+	for _, user := range users {
+		r.users[user.ID] = *user
+	}
+	return nil
+}
+
+func (r *memoryRepo) ListAddresses(_ context.Context, afterID int64, limit int) ([]*models.Address, error) {
+	addresses := make([]*models.Address, 0, limit)
+	for _, id := range sortedIDs(r.addresses) {
+		if id > afterID && len(addresses) < limit {
+			address := r.addresses[id]
+			addresses = append(addresses, &address)
+		}
+	}
+	return addresses, nil
+}
+
+func (r *memoryRepo) UpdateAddresses(_ context.Context, addresses []*models.Address) error {
+	for _, address := range addresses {
+		r.addresses[address.ID] = *address
+	}
+	return nil
+}
+
+func (r *memoryRepo) ListPhones(_ context.Context, afterID int64, limit int) ([]*models.PhoneNumber, error) {
+	phones := make([]*models.PhoneNumber, 0, limit)
+	for _, id := range sortedIDs(r.phones) {
+		if id > afterID && len(phones) < limit {
+			phone := r.phones[id]
+			phones = append(phones, &phone)
+		}
+	}
+	return phones, nil
+}
+
+func (r *memoryRepo) UpdatePhones(_ context.Context, phones []*models.PhoneNumber) error {
+	for _, phone := range phones {
+		r.phones[phone.ID] = *phone
+	}
+	return nil
+}
+
+func sortedIDs[K int | int64, V any](rows map[K]V) []K {
+	ids := make([]K, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		users: map[int]models.User{
+			1: {ID: 1, Username: "jane", Email: "jane@corp.io", Phone: "+442071838750"},
+			2: {ID: 2, Username: "john", Email: "john@corp.io"},
+			3: {ID: 3, Username: "carl", Email: "carl@corp.io"},
+		},
+		addresses: map[int64]models.Address{
+			10: {ID: 10, UserID: 1, Line1: "221B Baker Street", PostalCode: "NW1 6XE", City: "London", Country: "GB"},
+		},
+		phones: map[int64]models.PhoneNumber{
+			20: {ID: 20, UserID: 1, Number: "+442071838750"},
+			21: {ID: 21, UserID: 2, Number: "+442079460000"},
+		},
+	}
+}
+
+func TestAnonymizeUC_Run(t *testing.T) {
+	t.Parallel()
+
+	faker, err := anonymizer.NewFaker([]byte("staging-salt-0123456789"))
+	require.NoError(t, err)
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+
+	repo := newMemoryRepo()
+	uc := NewAnonymizeUseCase(repo, faker, appLogger)
+	ctx := context.Background()
+
+	report, err := uc.Run(ctx, 2, true)
+	require.NoError(t, err)
+	require.Equal(t, &models.AnonymizeReport{Users: 3, Addresses: 1, Phones: 2, DryRun: true}, report)
+	require.Equal(t, newMemoryRepo(), repo)
+
+	report, err = uc.Run(ctx, 2, false)
+	require.NoError(t, err)
+	require.Equal(t, &models.AnonymizeReport{Users: 3, Addresses: 1, Phones: 2}, report)
+
+	jane := repo.users[1]
+	require.Equal(t, faker.Username("jane"), jane.Username)
+	require.Equal(t, faker.Email("jane@corp.io"), jane.Email)
+	require.Empty(t, repo.users[2].Phone)
+
+	// The login phone and the contact phone holding the same number stay equal, ids and owners are kept
+	require.Equal(t, jane.Phone, repo.phones[20].Number)
+	require.NotEqual(t, repo.phones[20].Number, repo.phones[21].Number)
+	require.Equal(t, 2, repo.phones[21].UserID)
+
+	address := repo.addresses[10]
+	require.Equal(t, 1, address.UserID)
+	require.NotEqual(t, "221B Baker Street", address.Line1)
+	require.Empty(t, address.Line2)
+	require.Equal(t, "London", address.City)
+}
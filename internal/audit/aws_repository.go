@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Audit chain anchors in write-once object storage
+type AnchorRepository interface {
+	PutAnchor(ctx context.Context, anchor *models.AuditAnchor) error
+	// All anchors in chain order
+	ListAnchors(ctx context.Context) ([]*models.AuditAnchor, error)
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Audit repository interface, Create appends the event to the hash chain
+type Repository interface {
+	Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error)
+	// Newest chained event, nil while the chain is empty
+	Head(ctx context.Context) (*models.AuditEvent, error)
+	// Chained events after afterSeq in chain order
+	ListChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error)
+	// Remove up to limit of the oldest chained events created before the cutoff, never the head, and the
+	// unchained ones older than it. Returns the number of events and the new floor, nil when no chained event
+	// went. A dry run only counts
+	Prune(ctx context.Context, before time.Time, limit int, dryRun bool) (int, *models.AuditChainFloor, error)
+	// Last chained event removed, nil while none was
+	Floor(ctx context.Context) (*models.AuditChainFloor, error)
+}
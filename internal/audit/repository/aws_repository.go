@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Audit chain anchors kept in a minio bucket, one object per anchor keyed by zero padded chain seq
+type auditAnchorAWSRepo struct {
+	client    *minio.Client
+	bucket    string
+	prefix    string
+	retention time.Duration
+}
+
+// Audit anchor AWS repository constructor, a positive retention locks every anchor in compliance mode,
+// the bucket must have object locking enabled then
+func NewAuditAnchorAWSRepository(client *minio.Client, bucket string, prefix string, retention time.Duration) audit.AnchorRepository {
+	return &auditAnchorAWSRepo{client: client, bucket: bucket, prefix: prefix, retention: retention}
+}
+
+// Write anchor object
+func (r *auditAnchorAWSRepo) PutAnchor(ctx context.Context, anchor *models.AuditAnchor) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditAnchorAWSRepo.PutAnchor")
+	defer span.Finish()
+
+	body, err := json.Marshal(anchor)
+	if err != nil {
+		return errors.Wrap(err, "auditAnchorAWSRepo.PutAnchor.json.Marshal")
+	}
+
+	opts := minio.PutObjectOptions{ContentType: "application/json"}
+	if r.retention > 0 {
+		opts.Mode = minio.Compliance
+		opts.RetainUntilDate = anchor.AnchoredAt.Add(r.retention)
+	}
+	key := fmt.Sprintf("%s%020d.json", r.prefix, anchor.ChainSeq)
+	if _, err := r.client.PutObject(ctx, r.bucket, key, bytes.NewReader(body), int64(len(body)), opts); err != nil {
+		return errors.Wrap(err, "auditAnchorAWSRepo.PutAnchor.client.PutObject")
+	}
+	return nil
+}
+
+// List anchors in chain order
+func (r *auditAnchorAWSRepo) ListAnchors(ctx context.Context) ([]*models.AuditAnchor, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditAnchorAWSRepo.ListAnchors")
+	defer span.Finish()
+
+	anchors := make([]*models.AuditAnchor, 0)
+	for info := range r.client.ListObjects(ctx, r.bucket, minio.ListObjectsOptions{Prefix: r.prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, errors.Wrap(info.Err, "auditAnchorAWSRepo.ListAnchors.client.ListObjects")
+		}
+		anchor, err := r.getAnchor(ctx, info.Key)
+		if err != nil {
+			return nil, err
+		}
+		anchors = append(anchors, anchor)
+	}
+
+	sort.Slice(anchors, func(i, j int) bool { return anchors[i].ChainSeq < anchors[j].ChainSeq })
+	return anchors, nil
+}
+
+func (r *auditAnchorAWSRepo) getAnchor(ctx context.Context, key string) (*models.AuditAnchor, error) {
+	object, err := r.client.GetObject(ctx, r.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "auditAnchorAWSRepo.getAnchor.client.GetObject %s", key)
+	}
+	defer object.Close()
+
+	body, err := io.ReadAll(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "auditAnchorAWSRepo.getAnchor.io.ReadAll %s", key)
+	}
+	anchor := &models.AuditAnchor{}
+	if err := json.Unmarshal(body, anchor); err != nil {
+		return nil, errors.Wrapf(err, "auditAnchorAWSRepo.getAnchor.json.Unmarshal %s", key)
+	}
+	return anchor, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Events kept by the in-memory repository, older ones are dropped
+const memoryEventsLimit = 10000
+
+// Audit Repository kept in process memory, dev mode stand-in for Postgres
+type auditMemoryRepo struct {
+	mu       sync.Mutex
+	chainKey []byte
+	lastID   int64
+	events   []models.AuditEvent
+	floor    *models.AuditChainFloor
+}
+
+// Audit in-memory Repository constructor, chainKey signs the hash chain and may be empty
+func NewAuditMemoryRepository(chainKey []byte) audit.Repository {
+	return &auditMemoryRepo{chainKey: chainKey}
+}
+
+// Store audit event
+func (r *auditMemoryRepo) Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "auditMemoryRepo.Create")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *event
+	created.ID = r.lastID
+	created.CreatedAt = time.Now()
+	created.ChainSeq = r.lastID
+	created.PrevHash = ""
+	if len(r.events) > 0 {
+		created.PrevHash = r.events[len(r.events)-1].Hash
+	}
+	created.Hash = created.ChainHash(r.chainKey)
+
+	r.events = append(r.events, created)
+	if len(r.events) > memoryEventsLimit {
+		r.drop(len(r.events) - memoryEventsLimit)
+	}
+	return &created, nil
+}
+
+// Get newest chained event
+func (r *auditMemoryRepo) Head(ctx context.Context) (*models.AuditEvent, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "auditMemoryRepo.Head")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		return nil, nil
+	}
+	head := r.events[len(r.events)-1]
+	return &head, nil
+}
+
+// List chained events after seq, dropped events leave the chain starting after the floor
+func (r *auditMemoryRepo) ListChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "auditMemoryRepo.ListChain")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]*models.AuditEvent, 0, limit)
+	for i := range r.events {
+		if r.events[i].ChainSeq <= afterSeq {
+			continue
+		}
+		if len(events) == limit {
+			break
+		}
+		event := r.events[i]
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// Remove the oldest events created before the cutoff, the head stays
+func (r *auditMemoryRepo) Prune(ctx context.Context, before time.Time, limit int, dryRun bool) (int, *models.AuditChainFloor, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "auditMemoryRepo.Prune")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for count < limit && count < len(r.events)-1 && r.events[count].CreatedAt.Before(before) {
+		count++
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+	last := r.events[count-1]
+	floor := &models.AuditChainFloor{ChainSeq: last.ChainSeq, Hash: last.Hash, PrunedAt: time.Now()}
+	if !dryRun {
+		r.drop(count)
+	}
+	return count, floor, nil
+}
+
+// Get last chained event removed
+func (r *auditMemoryRepo) Floor(ctx context.Context) (*models.AuditChainFloor, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "auditMemoryRepo.Floor")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.floor == nil {
+		return nil, nil
+	}
+	floor := *r.floor
+	return &floor, nil
+}
+
+// Drop the count oldest events, the floor moves to the last of them
+func (r *auditMemoryRepo) drop(count int) {
+	last := r.events[count-1]
+	r.floor = &models.AuditChainFloor{ChainSeq: last.ChainSeq, Hash: last.Hash, PrunedAt: time.Now()}
+	r.events = append([]models.AuditEvent(nil), r.events[count:]...)
+}
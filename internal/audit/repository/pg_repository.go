@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Audit Repository
+type auditRepo struct {
+	db       *sqlx.DB
+	chainKey []byte
+}
+
+// Audit Repository constructor, chainKey signs the hash chain and may be empty
+func NewAuditRepository(db *sqlx.DB, chainKey []byte) audit.Repository {
+	return &auditRepo{db: db, chainKey: chainKey}
+}
+
+// Store audit event as the new chain head. The hash covers the stored representation,
+// so it is computed after the insert returned the id, metadata and timestamp.
+func (r *auditRepo) Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.Create")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Create.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if _, err := tx.ExecContext(ctx, lockAuditChainQuery); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Create.Lock")
+	}
+
+	head, err := scanAuditEvent(tx.QueryRowxContext(ctx, getAuditChainHeadQuery))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "auditRepo.Create.Head")
+	}
+
+	created := *event
+	created.ChainSeq = 1
+	created.PrevHash = ""
+	if head != nil {
+		created.ChainSeq = head.ChainSeq + 1
+		created.PrevHash = head.Hash
+	}
+
+	var metadata string
+	if err := tx.QueryRowxContext(
+		ctx,
+		createAuditEventQuery,
+		event.Action,
+		event.ActorID,
+		event.IPAddress,
+		event.RequestID,
+		event.Resource,
+		event.Metadata,
+		created.ChainSeq,
+		created.PrevHash,
+	).Scan(&created.ID, &metadata, &created.CreatedAt); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Create.Scan")
+	}
+	created.Metadata = json.RawMessage(metadata)
+	created.Hash = created.ChainHash(r.chainKey)
+
+	if _, err := tx.ExecContext(ctx, setAuditEventHashQuery, created.ID, created.Hash); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Create.SetHash")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Create.Commit")
+	}
+	return &created, nil
+}
+
+// Get newest chained event
+func (r *auditRepo) Head(ctx context.Context) (*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.Head")
+	defer span.Finish()
+
+	head, err := scanAuditEvent(tenant.DB(ctx, r.db).QueryRowxContext(ctx, getAuditChainHeadQuery))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "auditRepo.Head")
+	}
+	return head, nil
+}
+
+// List chained events after seq
+func (r *auditRepo) ListChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.ListChain")
+	defer span.Finish()
+
+	rows, err := tenant.DB(ctx, r.db).QueryxContext(ctx, listAuditChainQuery, afterSeq, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "auditRepo.ListChain.QueryxContext")
+	}
+	defer rows.Close()
+
+	events := make([]*models.AuditEvent, 0, limit)
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "auditRepo.ListChain.Scan")
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.ListChain.rows.Err")
+	}
+	return events, nil
+}
+
+// Remove the oldest events past the cutoff, the chain lock keeps appends from linking to a removed head
+func (r *auditRepo) Prune(ctx context.Context, before time.Time, limit int, dryRun bool) (int, *models.AuditChainFloor, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.Prune")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if _, err := tx.ExecContext(ctx, lockAuditChainQuery); err != nil {
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.Lock")
+	}
+
+	var floor *models.AuditChainFloor
+	bound := &models.AuditChainFloor{}
+	err = tx.QueryRowxContext(ctx, getAuditPruneBoundQuery, before, limit).Scan(&bound.ChainSeq, &bound.Hash)
+	switch {
+	case err == nil:
+		floor = bound
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.Bound")
+	}
+
+	if dryRun {
+		var count int
+		if err := tx.QueryRowxContext(ctx, countAuditPruneQuery, bound.ChainSeq, before, limit).Scan(&count); err != nil {
+			return 0, nil, errors.Wrap(err, "auditRepo.Prune.Count")
+		}
+		return count, floor, nil
+	}
+
+	result, err := tx.ExecContext(ctx, deleteAuditPruneQuery, bound.ChainSeq, before, limit)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.Delete")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.RowsAffected")
+	}
+	if floor != nil {
+		if err := tx.QueryRowxContext(ctx, setAuditChainFloorQuery, floor.ChainSeq, floor.Hash).
+			Scan(&floor.ChainSeq, &floor.Hash, &floor.PrunedAt); err != nil {
+			return 0, nil, errors.Wrap(err, "auditRepo.Prune.SetFloor")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, errors.Wrap(err, "auditRepo.Prune.Commit")
+	}
+	return int(deleted), floor, nil
+}
+
+// Get last chained event removed by retention
+func (r *auditRepo) Floor(ctx context.Context) (*models.AuditChainFloor, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.Floor")
+	defer span.Finish()
+
+	floor := &models.AuditChainFloor{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, floor, getAuditChainFloorQuery); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "auditRepo.Floor")
+	}
+	return floor, nil
+}
+
+// Scan event row, metadata is read as text so the hashed bytes match the stored jsonb rendering
+func scanAuditEvent(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.AuditEvent, error) {
+	var (
+		event                          models.AuditEvent
+		ipAddress, requestID, resource sql.NullString
+		metadata                       string
+	)
+	if err := row.Scan(
+		&event.ID,
+		&event.Action,
+		&event.ActorID,
+		&ipAddress,
+		&requestID,
+		&resource,
+		&metadata,
+		&event.CreatedAt,
+		&event.ChainSeq,
+		&event.PrevHash,
+		&event.Hash,
+	); err != nil {
+		return nil, err
+	}
+	event.IPAddress = ipAddress.String
+	event.RequestID = requestID.String
+	event.Resource = resource.String
+	event.Metadata = json.RawMessage(metadata)
+	return &event, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+const (
+	// Serializes appends so every event links to the one before it
+	lockAuditChainQuery = `SELECT pg_advisory_xact_lock(hashtext('audit_events_chain'))`
+
+	getAuditChainHeadQuery = `SELECT id, action, actor_id, ip_address, request_id, resource, metadata, created_at, chain_seq, prev_hash, hash
+						FROM audit_events WHERE chain_seq IS NOT NULL ORDER BY chain_seq DESC LIMIT 1`
+
+	createAuditEventQuery = `INSERT INTO audit_events (action, actor_id, ip_address, request_id, resource, metadata, chain_seq, prev_hash, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+						RETURNING id, metadata, created_at`
+
+	setAuditEventHashQuery = `UPDATE audit_events SET hash = $2 WHERE id = $1`
+
+	listAuditChainQuery = `SELECT id, action, actor_id, ip_address, request_id, resource, metadata, created_at, chain_seq, prev_hash, hash
+						FROM audit_events WHERE chain_seq > $1 ORDER BY chain_seq LIMIT $2`
+
+	// Newest of the oldest $2 chained events created before $1. The head stays so appends keep linking to it
+	getAuditPruneBoundQuery = `SELECT chain_seq, hash FROM (
+							SELECT chain_seq, hash FROM audit_events
+							WHERE chain_seq IS NOT NULL AND created_at < $1
+								AND chain_seq < (SELECT max(chain_seq) FROM audit_events)
+							ORDER BY chain_seq LIMIT $2
+						) due ORDER BY chain_seq DESC LIMIT 1`
+
+	// Chained events through $1 and up to $3 unchained ones created before $2
+	pruneAuditEventsWhere = `chain_seq <= $1
+						OR id IN (SELECT id FROM audit_events WHERE chain_seq IS NULL AND created_at < $2 ORDER BY id LIMIT $3)`
+
+	countAuditPruneQuery = `SELECT count(*) FROM audit_events WHERE ` + pruneAuditEventsWhere
+
+	deleteAuditPruneQuery = `DELETE FROM audit_events WHERE ` + pruneAuditEventsWhere
+
+	setAuditChainFloorQuery = `INSERT INTO audit_chain_floor (id, chain_seq, hash, pruned_at) VALUES (TRUE, $1, $2, now())
+						ON CONFLICT (id) DO UPDATE SET chain_seq = EXCLUDED.chain_seq, hash = EXCLUDED.hash, pruned_at = EXCLUDED.pruned_at
+						RETURNING chain_seq, hash, pruned_at`
+
+	getAuditChainFloorQuery = `SELECT chain_seq, hash, pruned_at FROM audit_chain_floor`
+)
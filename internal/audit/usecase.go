@@ -0,0 +1,34 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package audit
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Audit chain problem kinds
+const (
+	// Stored hash differs from the hash of the stored fields, the event was modified
+	ProblemHashMismatch = "hash_mismatch"
+	// Event does not reference the hash of the event before it
+	ProblemBrokenLink = "broken_link"
+	// Chain sequence skips numbers, events were removed
+	ProblemGap = "gap"
+	// Anchored head differs from the chain at its seq
+	ProblemAnchorMismatch = "anchor_mismatch"
+	// Anchored head is past the end of the chain, the tail was truncated
+	ProblemAnchorBeyondHead = "anchor_beyond_head"
+)
+
+// Audit use case
+type UseCase interface {
+	Record(ctx context.Context, action string, event *models.AuditEvent, metadata interface{}) error
+	// Write the chain head to anchor storage, nil when the head did not move since the last anchor
+	Anchor(ctx context.Context) (*models.AuditAnchor, error)
+	// observe:nodeadline, walks the whole chain
+	Verify(ctx context.Context) (*models.AuditVerification, error)
+	// Remove one batch of events past the audit retention, returns their number or, on a dry run, the number
+	// which would be removed
+	Prune(ctx context.Context, dryRun bool) (int, error)
+}
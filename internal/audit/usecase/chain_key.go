@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Audit chain signing key from the secrets provider, nil leaves the chain unsigned
+func LoadChainKey(ctx context.Context, cfg *config.Config) ([]byte, error) {
+	if cfg.AuditChain.SigningKeySecret == "" {
+		return nil, nil
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	key, err := provider.Get(ctx, cfg.AuditChain.SigningKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(key), nil
+}
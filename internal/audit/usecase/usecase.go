@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultVerifyBatchSize = 1000
+	defaultPruneBatchSize  = 1000
+	auditActionPruned      = "audit.pruned"
+)
+
+// Audit UseCase
+type auditUC struct {
+	cfg        *config.Config
+	repo       audit.Repository
+	anchorRepo audit.AnchorRepository
+	chainKey   []byte
+	clock      clock.Clock
+	logger     logger.Logger
+
+	mu           sync.Mutex
+	lastAnchored int64
+}
+
+// Audit UseCase constructor, anchorRepo may be nil when anchoring is disabled
+func NewAuditUseCase(
+	cfg *config.Config,
+	repo audit.Repository,
+	anchorRepo audit.AnchorRepository,
+	chainKey []byte,
+	clk clock.Clock,
+	logger logger.Logger,
+) audit.UseCase {
+	return &auditUC{cfg: cfg, repo: repo, anchorRepo: anchorRepo, chainKey: chainKey, clock: clk, logger: logger}
+}
+
+// Record audit event, metadata is stored as json
+func (u *auditUC) Record(ctx context.Context, action string, event *models.AuditEvent, metadata interface{}) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.Record")
+	defer span.Finish()
+
+	event.Action = action
+	if metadata != nil {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			return errors.Wrap(err, "auditUC.Record.json.Marshal")
+		}
+		event.Metadata = raw
+	} else {
+		event.Metadata = json.RawMessage("{}")
+	}
+
+	if _, err := u.repo.Create(ctx, event); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Anchor chain head
+func (u *auditUC) Anchor(ctx context.Context) (*models.AuditAnchor, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.Anchor")
+	defer span.Finish()
+
+	if u.anchorRepo == nil {
+		return nil, errors.New("auditUC.Anchor: anchoring is disabled")
+	}
+
+	head, err := u.repo.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if head == nil || head.ChainSeq == u.lastAnchored {
+		return nil, nil
+	}
+
+	anchor := &models.AuditAnchor{ChainSeq: head.ChainSeq, Hash: head.Hash, AnchoredAt: u.clock.Now()}
+	if err := u.anchorRepo.PutAnchor(ctx, anchor); err != nil {
+		return nil, err
+	}
+	u.lastAnchored = head.ChainSeq
+	return anchor, nil
+}
+
+// Verify the chain from its first event, or from the floor left by retention: every hash is recomputed, every
+// link and seq checked, and every anchor must match the event at its seq. Anchors of pruned events are skipped
+func (u *auditUC) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.Verify")
+	defer span.Finish()
+
+	anchors := make(map[int64]*models.AuditAnchor)
+	if u.anchorRepo != nil {
+		list, err := u.anchorRepo.ListAnchors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, anchor := range list {
+			anchors[anchor.ChainSeq] = anchor
+		}
+	}
+
+	batchSize := u.cfg.AuditChain.VerifyBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultVerifyBatchSize
+	}
+
+	floor, err := u.repo.Floor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AuditVerification{Anchors: len(anchors), Problems: make([]models.AuditChainProblem, 0)}
+	if floor != nil {
+		result.PrunedThrough = floor.ChainSeq
+		result.HeadSeq = floor.ChainSeq
+		result.HeadHash = floor.Hash
+	}
+	for {
+		events, err := u.repo.ListChain(ctx, result.HeadSeq, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			result.Problems = append(result.Problems, u.verifyEvent(event, result.HeadSeq, result.HeadHash, anchors[event.ChainSeq])...)
+			result.Events++
+			result.HeadSeq = event.ChainSeq
+			result.HeadHash = event.Hash
+		}
+		if len(events) < batchSize {
+			break
+		}
+	}
+
+	for seq, anchor := range anchors {
+		if seq > result.HeadSeq {
+			result.Problems = append(result.Problems, models.AuditChainProblem{
+				ChainSeq: seq,
+				Kind:     audit.ProblemAnchorBeyondHead,
+				Detail:   fmt.Sprintf("anchored at %s, chain ends at %d", anchor.AnchoredAt.Format(time.RFC3339), result.HeadSeq),
+			})
+		}
+	}
+
+	result.Valid = len(result.Problems) == 0
+	if !result.Valid {
+		u.logger.Warnf("auditUC.Verify: %d problems in %d events", len(result.Problems), result.Events)
+	}
+	return result, nil
+}
+
+// Remove the oldest events past AuditRetentionDays, at most BatchSize per run. The chain keeps verifying from the
+// hash of the last one removed. The removal is recorded as an audit event, nothing is removed without a retention
+func (u *auditUC) Prune(ctx context.Context, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.Prune")
+	defer span.Finish()
+
+	days := u.cfg.Compaction.AuditRetentionDays
+	if days <= 0 {
+		return 0, nil
+	}
+	limit := u.cfg.Compaction.BatchSize
+	if limit <= 0 {
+		limit = defaultPruneBatchSize
+	}
+
+	before := u.clock.Now().AddDate(0, 0, -days)
+	pruned, floor, err := u.repo.Prune(ctx, before, limit, dryRun)
+	if err != nil || dryRun || pruned == 0 {
+		return pruned, err
+	}
+
+	metadata := map[string]interface{}{"events": pruned, "before": before}
+	if floor != nil {
+		metadata["through_seq"] = floor.ChainSeq
+		metadata["through_hash"] = floor.Hash
+	}
+	if err := u.Record(ctx, auditActionPruned, &models.AuditEvent{}, metadata); err != nil {
+		u.logger.Errorf("auditUC.Prune.Record: %v", err)
+	}
+	return pruned, nil
+}
+
+// Problems of one event given the seq and hash of the event before it
+func (u *auditUC) verifyEvent(event *models.AuditEvent, prevSeq int64, prevHash string, anchor *models.AuditAnchor) []models.AuditChainProblem {
+	problems := make([]models.AuditChainProblem, 0)
+	problem := func(kind string, detail string) {
+		problems = append(problems, models.AuditChainProblem{ChainSeq: event.ChainSeq, EventID: event.ID, Kind: kind, Detail: detail})
+	}
+
+	if event.ChainSeq != prevSeq+1 {
+		problem(audit.ProblemGap, fmt.Sprintf("expected seq %d", prevSeq+1))
+	}
+	if event.PrevHash != prevHash {
+		problem(audit.ProblemBrokenLink, "previous hash does not match the event before")
+	}
+	if event.ChainHash(u.chainKey) != event.Hash {
+		problem(audit.ProblemHashMismatch, "stored fields do not match the hash")
+	}
+	if anchor != nil && anchor.Hash != event.Hash {
+		problem(audit.ProblemAnchorMismatch, fmt.Sprintf("anchored hash %s", anchor.Hash))
+	}
+	return problems
+}
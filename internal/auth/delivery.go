@@ -6,12 +6,23 @@ import "github.com/labstack/echo/v4"
 type Handlers interface {
 	Register() echo.HandlerFunc
 	Login() echo.HandlerFunc
+	LoginOTP() echo.HandlerFunc
 	Logout() echo.HandlerFunc
+	Guest() echo.HandlerFunc
+	Reauthenticate() echo.HandlerFunc
+	SendPhoneOTP() echo.HandlerFunc
+	VerifyPhone() echo.HandlerFunc
+	SetSMS2FA() echo.HandlerFunc
+	RequestDeletion() echo.HandlerFunc
+	CancelDeletion() echo.HandlerFunc
+	ChangePassword() echo.HandlerFunc
 	Update() echo.HandlerFunc
 	Delete() echo.HandlerFunc
 	GetUserByID() echo.HandlerFunc
 	FindByName() echo.HandlerFunc
+	FindByNameUncounted() echo.HandlerFunc
 	GetUsers() echo.HandlerFunc
 	GetMe() echo.HandlerFunc
 	GetCSRFToken() echo.HandlerFunc
+	ExchangeToken() echo.HandlerFunc
 }
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -11,34 +13,84 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/guest"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/otp"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/risk"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/csrf"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/riskscore"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
+// Cause logged for logins refused by risk scoring, clients get a plain 401
+var errLoginRiskBlocked = errors.New("login refused by risk assessment")
+
 // Auth handlers
 type authHandlers struct {
-	cfg    *config.Config
-	authUC auth.UseCase
-	sessUC session.UCSession
-	logger logger.Logger
+	cfg        *config.Config
+	authUC     auth.UseCase
+	sessUC     session.UCSession
+	guestUC    guest.UseCase
+	otpUC      otp.UseCase
+	webhooksUC webhooks.UseCase
+	riskUC     risk.UseCase
+	regUC      registration.UseCase
+	rememberUC remember.UseCase
+	rotationUC passwordrotation.UseCase
+	zones      *clock.Zones
+	logger     logger.Logger
 }
 
 // NewAuthHandlers Auth handlers constructor
-func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, sessUC session.UCSession, log logger.Logger) auth.Handlers {
-	return &authHandlers{cfg: cfg, authUC: authUC, sessUC: sessUC, logger: log}
+func NewAuthHandlers(
+	cfg *config.Config,
+	authUC auth.UseCase,
+	sessUC session.UCSession,
+	guestUC guest.UseCase,
+	otpUC otp.UseCase,
+	webhooksUC webhooks.UseCase,
+	riskUC risk.UseCase,
+	regUC registration.UseCase,
+	rememberUC remember.UseCase,
+	rotationUC passwordrotation.UseCase,
+	zones *clock.Zones,
+	log logger.Logger,
+) auth.Handlers {
+	return &authHandlers{
+		cfg:        cfg,
+		authUC:     authUC,
+		sessUC:     sessUC,
+		guestUC:    guestUC,
+		otpUC:      otpUC,
+		webhooksUC: webhooksUC,
+		riskUC:     riskUC,
+		regUC:      regUC,
+		rememberUC: rememberUC,
+		rotationUC: rotationUC,
+		zones:      zones,
+		logger:     log,
+	}
 }
 
 // Register godoc
 // @Summary Register new user
-// @Description register new user, returns user and token
+// @Description register new user, returns user and token, data of a guest session is moved to the new account.
+// @Description An invitation token is required while registration is invite only, its role replaces the default role
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Success 201 {object} models.User
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 409 {object} auth.RegistrationConflictError "email_already_registered, also when a concurrent signup took the email"
 // @Router /auth/register [post]
 func (h *authHandlers) Register() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -47,22 +99,42 @@ func (h *authHandlers) Register() echo.HandlerFunc {
 
 		user := &dto.RegisterUserRequest{}
 		if err := utils.ReadRequest(c, user); err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		invitation, err := h.regUC.Redeem(ctx, user.InvitationToken)
+		if err != nil {
+			return err
+		}
+		if invitation != nil {
+			user.RoleName = invitation.RoleName
 		}
 
 		createdUser, err := h.authUC.Register(ctx, user)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			if invitation != nil {
+				if err := h.regUC.Release(ctx, invitation); err != nil {
+					h.logger.Errorf("authHandlers.Register.Release: %v", err)
+				}
+			}
+			return err
+		}
+		if invitation != nil {
+			if err := h.regUC.Complete(ctx, invitation, createdUser.User.ID); err != nil {
+				h.logger.Errorf("authHandlers.Register.Complete UserID: %d, error: %v", createdUser.User.ID, err)
+			}
+		}
+
+		if err := h.mergeGuestSession(ctx, c, createdUser.User.ID); err != nil {
+			return err
 		}
 
 		sess, err := h.sessUC.CreateSession(ctx, &models.Session{
-			UserID: createdUser.User.ID,
+			UserID:    createdUser.User.ID,
+			IPAddress: c.RealIP(),
 		}, h.cfg.Session.Expire)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
@@ -73,11 +145,16 @@ func (h *authHandlers) Register() echo.HandlerFunc {
 
 // Login godoc
 // @Summary Login new user
-// @Description login user, returns user and set session
+// @Description login user, returns user and set session, data of a guest session is moved to the account.
+// @Description Users with SMS second factor get 202 with an mfa token instead, redeemed at /auth/login/otp.
+// @Description Risky logins may get the same challenge or a 401 as if the credentials were wrong.
+// @Description With remember_me the device also gets a remember-me cookie which starts a new session once this one ends.
+// @Description Users flagged by a password rotation campaign get password_rotation and have to change their password first
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.User
+// @Success 202 {object} dto.MFAChallengeResponse
 // @Router /auth/login [post]
 func (h *authHandlers) Login() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -86,33 +163,229 @@ func (h *authHandlers) Login() echo.HandlerFunc {
 
 		login := &dto.LoginUserRequest{}
 		if err := utils.ReadRequest(c, login); err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
+		attempt := &riskscore.Attempt{Username: login.Username, IPAddress: c.RealIP(), UserAgent: c.Request().UserAgent()}
 		userWithToken, err := h.authUC.Login(ctx, login)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			if status, _ := httpErrors.ErrorResponse(err); status == http.StatusUnauthorized || status == http.StatusNotFound {
+				h.observeLogin(ctx, attempt)
+			}
+			return err
 		}
 
-		sess, err := h.sessUC.CreateSession(ctx, &models.Session{
-			UserID: userWithToken.User.ID,
-		}, h.cfg.Session.Expire)
+		attempt.UserID = userWithToken.User.ID
+		attempt.Success = true
+		assessment, err := h.riskUC.AssessLogin(ctx, attempt)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+		// Refused logins look like wrong credentials, the response must not confirm the password
+		if assessment.Decision == riskscore.DecisionBlock {
+			return httpErrors.NewUnauthorizedError(errLoginRiskBlocked)
 		}
 
-		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+		stepUp := assessment.Decision == riskscore.DecisionStepUp
+		if userWithToken.User.SMS2FA || stepUp {
+			mfaToken, err := h.otpUC.SendLoginChallenge(ctx, userWithToken.User)
+			if err != nil {
+				// Users without a verified phone can't pass a forced second factor
+				if stepUp && errors.Is(err, otp.ErrPhoneNotVerified) {
+					return httpErrors.NewUnauthorizedError(errLoginRiskBlocked)
+				}
+				return err
+			}
+			return c.JSON(http.StatusAccepted, dto.MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken})
+		}
+
+		h.observeLogin(ctx, attempt)
+		if err := h.startSession(ctx, c, userWithToken.User.ID); err != nil {
+			return err
+		}
+		if login.RememberMe {
+			h.rememberDevice(ctx, c, userWithToken.User.ID)
+		}
+
+		return c.JSON(http.StatusOK, h.withPasswordRotation(ctx, withPendingDeletion(userWithToken)))
+	}
+}
+
+// LoginOTP godoc
+// @Summary Complete login with SMS code
+// @Description redeem the mfa token returned by login with the code sent by SMS, returns user and set session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.LoginOTPRequest true "mfa token and code"
+// @Success 200 {object} models.User
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/login/otp [post]
+func (h *authHandlers) LoginOTP() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.LoginOTP")
+		defer span.Finish()
+
+		req := &dto.LoginOTPRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		userID, err := h.otpUC.VerifyLoginChallenge(ctx, req.MFAToken, req.Code)
+		if err != nil {
+			return err
+		}
+
+		userWithToken, err := h.authUC.IssueToken(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		h.observeLogin(ctx, &riskscore.Attempt{UserID: userID, IPAddress: c.RealIP(), UserAgent: c.Request().UserAgent(), Success: true})
+
+		if err := h.startSession(ctx, c, userID); err != nil {
+			return err
+		}
+		if req.RememberMe {
+			h.rememberDevice(ctx, c, userID)
+		}
+
+		return c.JSON(http.StatusOK, h.withPasswordRotation(ctx, withPendingDeletion(userWithToken)))
+	}
+}
+
+// Report login outcome to the risk scorers, their history must not fail the login
+func (h *authHandlers) observeLogin(ctx context.Context, attempt *riskscore.Attempt) {
+	if err := h.riskUC.ObserveLogin(ctx, attempt); err != nil {
+		h.logger.Errorf("authHandlers.observeLogin userID: %d, error: %v", attempt.UserID, err)
+	}
+}
+
+// Offer cancellation to users logging in during their deletion grace period
+func withPendingDeletion(userWithToken *models.UserWithToken) *models.UserWithToken {
+	if userWithToken.User.PendingDeletion() {
+		userWithToken.PendingDeletion = &models.PendingDeletion{
+			ScheduledAt:  *userWithToken.User.DeletionScheduledAt,
+			CancelMethod: http.MethodDelete,
+			CancelPath:   cancelDeletionPath,
+		}
+	}
+	return userWithToken
+}
+
+// Point users flagged by a password rotation campaign to the password change, the session middleware refuses
+// everything else until then. A failing lookup leaves that to the middleware
+func (h *authHandlers) withPasswordRotation(ctx context.Context, userWithToken *models.UserWithToken) *models.UserWithToken {
+	required, err := h.rotationUC.IsRequired(ctx, userWithToken.User.ID)
+	if err != nil {
+		h.logger.Errorf("authHandlers.withPasswordRotation.IsRequired userID: %d, error: %v", userWithToken.User.ID, err)
+		return userWithToken
+	}
+	if required {
+		userWithToken.PasswordRotation = &models.PasswordRotationRequired{
+			ChangeMethod: http.MethodPut,
+			ChangePath:   passwordrotation.ChangePasswordPath,
+		}
+	}
+	return userWithToken
+}
+
+// Merge a guest session of the request into the user and set a new session cookie
+func (h *authHandlers) startSession(ctx context.Context, c echo.Context, userID int) error {
+	if err := h.mergeGuestSession(ctx, c, userID); err != nil {
+		return err
+	}
+
+	sess, err := h.sessUC.CreateSession(ctx, &models.Session{
+		UserID:    userID,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}, h.cfg.Session.Expire)
+	if err != nil {
+		return err
+	}
+
+	c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+	// Webhooks of the user must not fail the login
+	if err := h.webhooksUC.NotifyLogin(ctx, userID, c.RealIP(), c.Request().UserAgent()); err != nil {
+		h.logger.Errorf("authHandlers.startSession.NotifyLogin userID: %d, error: %v", userID, err)
+	}
+	return nil
+}
+
+// Set a remember-me cookie for the device, the login already succeeded so a failure only costs the cookie
+func (h *authHandlers) rememberDevice(ctx context.Context, c echo.Context, userID int) {
+	if !h.cfg.Remember.Enabled {
+		return
+	}
+	value, err := h.rememberUC.Issue(ctx, userID, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		h.logger.Errorf("authHandlers.rememberDevice.Issue userID: %d, error: %v", userID, err)
+		return
+	}
+	c.SetCookie(utils.CreateRememberCookie(h.cfg, value))
+}
+
+// Guest godoc
+// @Summary Start guest session
+// @Description issue an anonymous session with limited permissions, upgraded on register or login
+// @Tags Auth
+// @Produce json
+// @Success 201 {object} models.Session
+// @Router /auth/guest [post]
+func (h *authHandlers) Guest() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Guest")
+		defer span.Finish()
+
+		expire := h.cfg.Session.GuestExpire
+		if expire <= 0 {
+			expire = h.cfg.Session.Expire
+		}
+
+		sess := &models.Session{
+			Guest:     true,
+			GuestID:   uuid.New().String(),
+			IPAddress: c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+		}
+		sid, err := h.sessUC.CreateSession(ctx, sess, expire)
+		if err != nil {
+			return err
+		}
 
-		return c.JSON(http.StatusOK, userWithToken)
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sid))
+
+		return c.JSON(http.StatusCreated, sess)
 	}
 }
 
+// Move data of the guest session in the request cookie to the user, then drop the guest session
+func (h *authHandlers) mergeGuestSession(ctx context.Context, c echo.Context, userID int) error {
+	cookie, err := c.Cookie(h.cfg.Session.Name)
+	if err != nil {
+		return nil
+	}
+
+	sess, err := h.sessUC.GetSessionByID(ctx, cookie.Value)
+	if err != nil || !sess.Guest {
+		return nil
+	}
+
+	if err := h.guestUC.Merge(ctx, sess.GuestID, userID); err != nil {
+		return err
+	}
+
+	if err := h.sessUC.DeleteByID(ctx, cookie.Value); err != nil {
+		h.logger.Warnf("authHandlers.mergeGuestSession DeleteByID guestID: %s, error: %v", sess.GuestID, err)
+	}
+	return nil
+}
+
 // Logout godoc
 // @Summary Logout user
-// @Description logout user removing session
+// @Description logout user removing session, a remember-me cookie of the device stops working too
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -123,27 +396,263 @@ func (h *authHandlers) Logout() echo.HandlerFunc {
 		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Logout")
 		defer span.Finish()
 
-		cookie, err := c.Cookie("session-id")
+		cookie, err := c.Cookie(h.cfg.Session.Name)
 		if err != nil {
 			if errors.Is(err, http.ErrNoCookie) {
-				utils.LogResponseError(c, h.logger, err)
-				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+				return httpErrors.NewUnauthorizedError(err)
 			}
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError(err))
+			return httpErrors.NewInternalServerError(err)
 		}
 
 		if err := h.sessUC.DeleteByID(ctx, cookie.Value); err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+		if rememberCookie, err := c.Cookie(h.cfg.Remember.CookieName); err == nil && h.cfg.Remember.Enabled {
+			if err := h.rememberUC.Forget(ctx, rememberCookie.Value); err != nil {
+				return err
+			}
+			utils.DeleteSessionCookie(c, h.cfg.Remember.CookieName)
+		}
 
 		return c.NoContent(http.StatusOK)
 	}
 }
 
+// Reauthenticate godoc
+// @Summary Re-authenticate session
+// @Description confirm password to unlock sensitive actions for the step-up window
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.ReauthenticateRequest true "current password"
+// @Success 200 {object} models.Session
+// @Failure 401 {object} httpErrors.RestError
+// @Failure 429 {object} httpErrors.RestError
+// @Router /auth/reauthenticate [post]
+func (h *authHandlers) Reauthenticate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Reauthenticate")
+		defer span.Finish()
+
+		req := &dto.ReauthenticateRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		if err := h.authUC.VerifyPassword(ctx, user.User.ID, req.Password); err != nil {
+			return err
+		}
+
+		sid, _ := requestctx.SessionID.Get(c)
+		sess, err := h.sessUC.Reauthenticate(ctx, sid)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, sess)
+	}
+}
+
+// SendPhoneOTP godoc
+// @Summary Send phone verification code
+// @Description send a code by SMS to the phone number being verified
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.SendPhoneOTPRequest true "phone number in E.164 format"
+// @Success 204
+// @Failure 429 {object} httpErrors.RestError
+// @Router /auth/phone/send-otp [post]
+func (h *authHandlers) SendPhoneOTP() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.SendPhoneOTP")
+		defer span.Finish()
+
+		req := &dto.SendPhoneOTPRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		if err := h.otpUC.SendPhoneVerification(ctx, user.User.ID, req.Phone); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// VerifyPhone godoc
+// @Summary Verify phone number
+// @Description check the SMS code and store the phone number as verified
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.VerifyPhoneRequest true "code received by SMS"
+// @Success 200 {object} models.User
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/phone/verify [post]
+func (h *authHandlers) VerifyPhone() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.VerifyPhone")
+		defer span.Finish()
+
+		req := &dto.VerifyPhoneRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		updatedUser, err := h.otpUC.VerifyPhone(ctx, user.User.ID, req.Code)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updatedUser)
+	}
+}
+
+// SetSMS2FA godoc
+// @Summary Toggle SMS second factor
+// @Description enable or disable SMS codes at login, enabling requires a verified phone number
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.SMS2FARequest true "enabled flag"
+// @Success 204
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/phone/2fa [put]
+func (h *authHandlers) SetSMS2FA() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.SetSMS2FA")
+		defer span.Finish()
+
+		req := &dto.SMS2FARequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		if err := h.otpUC.SetSMS2FA(ctx, user.User.ID, req.Enabled); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// RequestDeletion godoc
+// @Summary Request account deletion
+// @Description mark the current account pending deletion, it is purged after the grace period unless cancelled
+// @Tags Auth
+// @Produce json
+// @Success 202 {object} models.User
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/me/deletion [post]
+func (h *authHandlers) RequestDeletion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.RequestDeletion")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		pending, err := h.authUC.RequestDeletion(ctx, user.User.ID)
+		if err != nil {
+			return err
+		}
+
+		projected, err := projectUser(c, h.zones, nil, pending)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, projected)
+	}
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description keep the current account, allowed until the scheduled purge
+// @Tags Auth
+// @Success 204
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/deletion [delete]
+func (h *authHandlers) CancelDeletion() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.CancelDeletion")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		if err := h.authUC.CancelDeletion(ctx, user.User.ID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description change the password of the current user after a recent re-authentication, completes the password rotation campaigns flagging them
+// @Tags Auth
+// @Accept json
+// @Param body body dto.ChangePasswordRequest true "current and new password"
+// @Success 204
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/me/password [put]
+func (h *authHandlers) ChangePassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ChangePassword")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
+		}
+
+		req := &dto.ChangePasswordRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		if err := h.authUC.ChangePassword(ctx, user.User.ID, req); err != nil {
+			return err
+		}
+		if err := h.rotationUC.Complete(ctx, user.User.ID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
 // Update godoc
 // @Summary Update user
 // @Description update existing user
@@ -152,6 +661,7 @@ func (h *authHandlers) Logout() echo.HandlerFunc {
 // @Param id path int true "user_id"
 // @Produce json
 // @Success 200 {object} models.User
+// @Failure 429 {object} auth.ChangeLimitError "too_many_changes, the field can be changed again at next_allowed_at"
 // @Router /auth/{id} [put]
 func (h *authHandlers) Update() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -160,22 +670,31 @@ func (h *authHandlers) Update() echo.HandlerFunc {
 
 		uID, err := strconv.Atoi(c.Param("user_id"))
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		user := &models.User{}
 		user.ID = uID
 
 		if err = utils.ReadRequest(c, user); err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		// Changing the email is sensitive, other fields are not
+		if user.Email != "" {
+			sess, _ := requestctx.Session.Get(c)
+			if err = h.sessUC.CheckStepUp(ctx, sess); err != nil {
+				return err
+			}
 		}
 
 		updatedUser, err := h.authUC.Update(ctx, user)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		if err := h.webhooksUC.Publish(ctx, updatedUser.ID, models.WebhookEventProfileUpdated, updatedUser); err != nil {
+			h.logger.Errorf("authHandlers.Update.Publish userID: %d, error: %v", updatedUser.ID, err)
 		}
 
 		return c.JSON(http.StatusOK, updatedUser)
@@ -189,6 +708,7 @@ func (h *authHandlers) Update() echo.HandlerFunc {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "user_id"
+// @Param fields query string false "comma separated user fields, e.g. id,username,email"
 // @Success 200 {object} models.User
 // @Failure 500 {object} httpErrors.RestError
 // @Router /auth/{id} [get]
@@ -199,17 +719,25 @@ func (h *authHandlers) GetUserByID() echo.HandlerFunc {
 
 		uID, err := strconv.Atoi(c.Param("user_id"))
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		fields, err := readUserFields(c)
+		if err != nil {
+			return err
 		}
 
 		user, err := h.authUC.GetByID(ctx, uID)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
-		return c.JSON(http.StatusOK, user)
+		response, err := projectUserWithRole(c, h.zones, fields, user)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -230,13 +758,11 @@ func (h *authHandlers) Delete() echo.HandlerFunc {
 
 		uID, err := strconv.Atoi(c.Param("user_id"))
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		if err = h.authUC.Delete(ctx, uID); err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		return c.NoContent(http.StatusOK)
@@ -249,6 +775,7 @@ func (h *authHandlers) Delete() echo.HandlerFunc {
 // @Tags Auth
 // @Accept json
 // @Param name query string false "username" Format(username)
+// @Param fields query string false "comma separated user fields, e.g. id,username,email"
 // @Produce json
 // @Success 200 {object} models.UsersList
 // @Failure 500 {object} httpErrors.RestError
@@ -258,25 +785,48 @@ func (h *authHandlers) FindByName() echo.HandlerFunc {
 		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.FindByName")
 		defer span.Finish()
 
-		if c.QueryParam("name") == "" {
-			utils.LogResponseError(c, h.logger, httpErrors.NewBadRequestError("name is required"))
-			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("name is required"))
-		}
+		return h.findByName(ctx, c, "")
+	}
+}
 
-		paginationQuery, err := utils.GetPaginationFromCtx(c)
-		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
-		}
+// Canary variant of FindByName skipping the count query, totals are zero and has_more tells whether a next page exists
+func (h *authHandlers) FindByNameUncounted() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.FindByNameUncounted")
+		defer span.Finish()
 
-		response, err := h.authUC.FindByName(ctx, c.QueryParam("name"), paginationQuery)
-		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
-		}
+		return h.findByName(ctx, c, utils.CountNone)
+	}
+}
 
-		return c.JSON(http.StatusOK, response)
+// Users search with count strategy, the configured one when empty
+func (h *authHandlers) findByName(ctx context.Context, c echo.Context, count utils.CountStrategy) error {
+	if c.QueryParam("name") == "" {
+		return httpErrors.NewBadRequestError("name is required")
 	}
+
+	paginationQuery, err := utils.GetPaginationFromCtx(c)
+	if err != nil {
+		return err
+	}
+	paginationQuery.Count = count
+
+	fields, err := readUserFields(c)
+	if err != nil {
+		return err
+	}
+
+	usersList, err := h.authUC.FindByName(ctx, c.QueryParam("name"), paginationQuery)
+	if err != nil {
+		return err
+	}
+
+	response, err := projectUsersList(c, h.zones, fields, usersList)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 // GetUsers godoc
@@ -287,6 +837,7 @@ func (h *authHandlers) FindByName() echo.HandlerFunc {
 // @Param page query int false "page number" Format(page)
 // @Param size query int false "number of elements per page" Format(size)
 // @Param orderBy query int false "filter name" Format(orderBy)
+// @Param fields query string false "comma separated user fields, e.g. id,username,email"
 // @Produce json
 // @Success 200 {object} models.UsersList
 // @Failure 500 {object} httpErrors.RestError
@@ -298,41 +849,99 @@ func (h *authHandlers) GetUsers() echo.HandlerFunc {
 
 		paginationQuery, err := utils.GetPaginationFromCtx(c)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		fields, err := readUserFields(c)
+		if err != nil {
+			return err
 		}
 
 		usersList, err := h.authUC.GetUsers(ctx, paginationQuery)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
+		}
+
+		response, err := projectUsersList(c, h.zones, fields, usersList)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+// ExchangeToken godoc
+// @Summary Exchange session for a scoped token
+// @Description issue a short lived bearer token limited to the requested scopes, accepted only by the /scoped routes
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body dto.TokenExchangeRequest true "requested scopes and lifetime"
+// @Success 201 {object} models.ScopedToken
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/token/exchange [post]
+func (h *authHandlers) ExchangeToken() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ExchangeToken")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return httpErrors.NewUnauthorizedError(err)
 		}
 
-		return c.JSON(http.StatusOK, usersList)
+		req := &dto.TokenExchangeRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		scoped, err := h.authUC.IssueScopedToken(ctx, user.User.ID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, scoped)
 	}
 }
 
 // GetMe godoc
 // @Summary Get user by id
-// @Description Get current user by id
+// @Description Get current user by id with its permissions and feature flags
 // @Tags Auth
 // @Accept json
+// @Param fields query string false "comma separated user fields, e.g. id,username,email"
 // @Produce json
 // @Success 200 {object} models.User
 // @Failure 500 {object} httpErrors.RestError
 // @Router /auth/me [get]
 func (h *authHandlers) GetMe() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, _ := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetMe")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetMe")
 		defer span.Finish()
 
-		user, ok := c.Get("user").(*models.UserWithRole)
+		user, ok := requestctx.User.Get(c)
 		if !ok {
-			utils.LogResponseError(c, h.logger, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
-			return utils.ErrResponseWithLog(c, h.logger, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			return httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
 		}
 
-		return c.JSON(http.StatusOK, user)
+		fields, err := readUserFields(c)
+		if err != nil {
+			return err
+		}
+
+		response, err := projectUserWithRole(c, h.zones, fields, user)
+		if err != nil {
+			return err
+		}
+
+		response.Access, err = h.authUC.GetAccess(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -350,10 +959,9 @@ func (h *authHandlers) GetCSRFToken() echo.HandlerFunc {
 		span, _ := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetCSRFToken")
 		defer span.Finish()
 
-		sid, ok := c.Get("sid").(string)
+		sid, ok := requestctx.SessionID.Get(c)
 		if !ok {
-			utils.LogResponseError(c, h.logger, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
-			return utils.ErrResponseWithLog(c, h.logger, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			return httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
 		}
 		token := csrf.MakeToken(sid, h.logger)
 		c.Response().Header().Set(csrf.CSRFHeader, token)
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	sessionMock "github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Guest use case recording merged guests
+type fakeGuestUC struct {
+	merged []string
+	err    error
+}
+
+func (f *fakeGuestUC) Merge(ctx context.Context, guestID string, userID int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.merged = append(f.merged, guestID)
+	return nil
+}
+
+func TestAuthHandlers_MergeGuestSession(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Session: config.Session{Name: "session-id"}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	ctrl := gomock.NewController(t)
+	sessUC := sessionMock.NewMockUCSession(ctrl)
+	guestUC := &fakeGuestUC{}
+	h := &authHandlers{cfg: cfg, sessUC: sessUC, guestUC: guestUC, logger: appLogger}
+	ctx := context.Background()
+
+	merge := func(sessionID string) error {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		if sessionID != "" {
+			req.AddCookie(&http.Cookie{Name: cfg.Session.Name, Value: sessionID})
+		}
+		return h.mergeGuestSession(ctx, echo.New().NewContext(req, httptest.NewRecorder()), 7)
+	}
+
+	// Without a cookie there is nothing to merge
+	require.NoError(t, merge(""))
+
+	// A user session in the cookie is left alone
+	sessUC.EXPECT().GetSessionByID(ctx, "user-sess").Return(&models.Session{SessionID: "user-sess", UserID: 3}, nil)
+	require.NoError(t, merge("user-sess"))
+	require.Empty(t, guestUC.merged)
+
+	// The guest session goes away once its data belongs to the user
+	sessUC.EXPECT().GetSessionByID(ctx, "guest-sess").Return(&models.Session{SessionID: "guest-sess", Guest: true, GuestID: "guest-1"}, nil)
+	sessUC.EXPECT().DeleteByID(ctx, "guest-sess").Return(nil)
+	require.NoError(t, merge("guest-sess"))
+	require.Equal(t, []string{"guest-1"}, guestUC.merged)
+
+	// A failed merge keeps the guest session so nothing is lost
+	guestUC.err = errors.New("merge failed")
+	sessUC.EXPECT().GetSessionByID(ctx, "guest-sess").Return(&models.Session{SessionID: "guest-sess", Guest: true, GuestID: "guest-1"}, nil)
+	require.Error(t, merge("guest-sess"))
+}
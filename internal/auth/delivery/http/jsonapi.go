@@ -0,0 +1,40 @@
+package http
+
+import (
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jsonapi"
+)
+
+// User related to its role, the role is included. Access of the current user goes to meta
+func (r *userWithRoleResponse) JSONAPI() *jsonapi.Payload {
+	role := r.Role.Resource()
+	payload := jsonapi.One(&jsonapi.Resource{
+		Identifier:    jsonapi.Identifier{Type: models.UsersResourceType, ID: int64(r.userID)},
+		Attributes:    r.User,
+		Relationships: map[string]jsonapi.Identifier{"role": role.Identifier},
+	})
+	payload.Include(role)
+	if r.Access != nil {
+		payload.Meta = map[string]interface{}{"access": r.Access}
+	}
+	return payload
+}
+
+// Page of users, totals go to meta
+func (r *usersListResponse) JSONAPI() *jsonapi.Payload {
+	resources := make([]*jsonapi.Resource, 0, len(r.Users))
+	for i, user := range r.Users {
+		resources = append(resources, &jsonapi.Resource{
+			Identifier: jsonapi.Identifier{Type: models.UsersResourceType, ID: int64(r.UsersList.Users[i].ID)},
+			Attributes: user,
+		})
+	}
+	payload := jsonapi.Many(resources)
+	payload.Page = &jsonapi.Page{Number: r.Page, Size: r.Size, TotalPages: r.TotalPages, HasMore: r.HasMore}
+	payload.Meta = map[string]interface{}{
+		"total_count":    r.TotalCount,
+		"total_pages":    r.TotalPages,
+		"count_strategy": r.CountStrategy,
+	}
+	return payload
+}
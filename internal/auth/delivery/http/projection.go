@@ -0,0 +1,101 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/projection"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+const (
+	fieldsQueryParam = "fields"
+	adminRoleName    = "administrator"
+)
+
+var (
+	// User fields anyone may read
+	publicUserFields = []string{"id", "username", "created_at", "updated_at", "login_at"}
+	// User fields visible to administrators or the user itself
+	privateUserFields = append(append([]string{}, publicUserFields...), "email", "phone", "phone_verified_at", "sms_2fa_enabled", "timezone",
+		"status", "deletion_requested_at", "deletion_scheduled_at")
+)
+
+// Single user response with projected user fields
+type userWithRoleResponse struct {
+	User map[string]interface{} `json:"user"`
+	Role models.Role            `json:"role"`
+	// Only for the current user
+	Access *models.Access `json:"access,omitempty"`
+
+	// Projected fields may leave the id out, resources need it
+	userID int
+}
+
+// Users list response with projected user fields
+type usersListResponse struct {
+	*models.UsersList
+	Users []map[string]interface{} `json:"users"`
+}
+
+// Read and validate ?fields= against fields the caller role may ever request
+func readUserFields(c echo.Context) (projection.Fields, error) {
+	fields := projection.Parse(c.QueryParam(fieldsQueryParam))
+	if err := fields.Validate(privateUserFields); err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+	return fields, nil
+}
+
+// Fields of user visible to the current caller
+func visibleUserFields(c echo.Context, userID int) []string {
+	viewer, ok := requestctx.User.Get(c)
+	if ok && (viewer.Role.Name == adminRoleName || viewer.User.ID == userID) {
+		return privateUserFields
+	}
+	return publicUserFields
+}
+
+// Zone of the current caller, anonymous callers get the configured default
+func viewerTimezone(c echo.Context) string {
+	if viewer, ok := requestctx.User.Get(c); ok {
+		return viewer.User.Timezone
+	}
+	return ""
+}
+
+// Copy of user with timestamps in the caller zone
+func localizeUser(c echo.Context, zones *clock.Zones, user *models.User) *models.User {
+	tz := viewerTimezone(c)
+	localized := *user
+	localized.CreatedAt = zones.ToUser(user.CreatedAt, tz)
+	localized.UpdatedAt = zones.ToUser(user.UpdatedAt, tz)
+	localized.LoginDate = zones.ToUser(user.LoginDate, tz)
+	return &localized
+}
+
+func projectUser(c echo.Context, zones *clock.Zones, fields projection.Fields, user *models.User) (map[string]interface{}, error) {
+	return projection.Select(localizeUser(c, zones, user), fields.Resolve(visibleUserFields(c, user.ID)))
+}
+
+func projectUserWithRole(c echo.Context, zones *clock.Zones, fields projection.Fields, user *models.UserWithRole) (*userWithRoleResponse, error) {
+	projected, err := projectUser(c, zones, fields, &user.User)
+	if err != nil {
+		return nil, err
+	}
+	return &userWithRoleResponse{User: projected, Role: user.Role, userID: user.User.ID}, nil
+}
+
+func projectUsersList(c echo.Context, zones *clock.Zones, fields projection.Fields, list *models.UsersList) (*usersListResponse, error) {
+	users := make([]map[string]interface{}, 0, len(list.Users))
+	for _, user := range list.Users {
+		projected, err := projectUser(c, zones, fields, user)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, projected)
+	}
+	return &usersListResponse{UsersList: list, Users: users}, nil
+}
@@ -6,22 +6,50 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 )
 
+// Route of CancelDeletion, offered at login during the deletion grace period
+const cancelDeletionPath = "/api/v1/auth/me/deletion"
+
+// Canary route name of the users search, its variant percentages are under canary.Routes
+const usersSearchCanary = "users_search"
+
 // Map auth routes
 func MapAuthRoutes(authGroup *echo.Group, h auth.Handlers, mw *middleware.MiddlewareManager, authUC auth.UseCase, cfg *config.Config) {
-	authGroup.POST("/register", h.Register())
-	authGroup.POST("/login", h.Login())
+	authGroup.POST("/register", h.Register(), mw.RateLimit("register"), mw.RequestSchema)
+	authGroup.POST("/login", h.Login(), mw.RateLimit("login"), mw.RequestSchema)
+	authGroup.POST("/login/otp", h.LoginOTP(), mw.RateLimit("login_otp"))
+	authGroup.POST("/guest", h.Guest(), mw.RateLimit("guest"))
+	authGroup.GET("/guest/token", h.GetCSRFToken(), mw.SessionOrGuestMiddleware)
 	authGroup.POST("/logout", h.Logout())
-	authGroup.GET("/find", h.FindByName())
-	authGroup.GET("/all", h.GetUsers())
-	authGroup.GET("/:user_id", h.GetUserByID())
+	authGroup.GET("/find", h.FindByName(), mw.OrganizationScope, mw.RateLimit("users_search"), mw.RequestSchema, mw.CanaryMiddleware(usersSearchCanary, map[string]echo.HandlerFunc{
+		"uncounted": h.FindByNameUncounted(),
+	}))
+	authGroup.GET("/all", h.GetUsers(), mw.OrganizationScope)
+	authGroup.GET("/:user_id", h.GetUserByID(), mw.RequestSchema)
 
-	authGroup.Use(mw.AuthJWTMiddleware(authUC, cfg))
+	// With bearer auth the session middleware takes JWTs itself, cookie clients need not send one too
+	if !cfg.Bearer.Enabled {
+		authGroup.Use(mw.AuthJWTMiddleware(authUC, cfg))
+	}
 	authGroup.Use(mw.AuthSessionMiddleware)
 
 	authGroup.GET("/me", h.GetMe())
+	authGroup.POST("/reauthenticate", h.Reauthenticate(), mw.CSRF, mw.RateLimit("reauthenticate"))
 	authGroup.GET("/token", h.GetCSRFToken())
-	authGroup.PUT("/:user_id", h.Update(), mw.OwnerOrAdminMiddleware(), mw.CSRF)
-	authGroup.DELETE("/:user_id", h.Delete(), mw.CSRF, mw.RoleBasedAuthMiddleware([]string{"administrator"}))
+	authGroup.POST("/token/exchange", h.ExchangeToken(), mw.CSRF)
+	authGroup.POST("/phone/send-otp", h.SendPhoneOTP(), mw.CSRF, mw.RateLimit("otp_send"))
+	authGroup.POST("/phone/verify", h.VerifyPhone(), mw.CSRF, mw.RateLimit("otp_verify"))
+	authGroup.PUT("/phone/2fa", h.SetSMS2FA(), mw.CSRF, mw.StepUp)
+	authGroup.POST("/me/deletion", h.RequestDeletion(), mw.CSRF, mw.StepUp)
+	authGroup.DELETE("/me/deletion", h.CancelDeletion(), mw.CSRF)
+	authGroup.PUT("/me/password", h.ChangePassword(), mw.CSRF, mw.StepUp)
+	authGroup.PUT("/:user_id", h.Update(), mw.OwnerOrAdminMiddleware(), mw.CSRF, mw.RequestSchema)
+	authGroup.DELETE("/:user_id", h.Delete(), mw.CSRF, mw.RoleBasedAuthMiddleware([]string{"administrator"}), mw.StepUp)
+}
+
+// Map auth routes reachable with a scoped token from the token exchange
+func MapScopedAuthRoutes(scopedGroup *echo.Group, h auth.Handlers, mw *middleware.MiddlewareManager) {
+	scopedGroup.GET("/me", h.GetMe(), mw.ScopedTokenMiddleware(models.ScopeReadProfile))
 }
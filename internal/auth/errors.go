@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Code of ChangeLimitError, clients match on it to tell when the field can be changed again
+const CodeTooManyChanges = "too_many_changes"
+
+// Profile field changed more often than its limit allows, implements httpErrors.RestErr so it maps to its own status
+type ChangeLimitError struct {
+	ErrStatus     int       `json:"status"`
+	ErrError      string    `json:"error"`
+	Code          string    `json:"code"`
+	Field         string    `json:"field"`
+	NextAllowedAt time.Time `json:"next_allowed_at"`
+}
+
+// Limit error of field, changeable again at nextAllowedAt
+func NewChangeLimitError(field string, nextAllowedAt time.Time) *ChangeLimitError {
+	return &ChangeLimitError{
+		ErrStatus:     http.StatusTooManyRequests,
+		ErrError:      fmt.Sprintf("%s was changed too often", field),
+		Code:          CodeTooManyChanges,
+		Field:         field,
+		NextAllowedAt: nextAllowedAt.UTC(),
+	}
+}
+
+// Error  Error() interface method
+func (e *ChangeLimitError) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s - next allowed at: %s", e.ErrStatus, e.ErrError, e.NextAllowedAt.Format(time.RFC3339))
+}
+
+// Error status
+func (e *ChangeLimitError) Status() int {
+	return e.ErrStatus
+}
+
+// Limit errors carry no causes
+func (e *ChangeLimitError) Causes() interface{} {
+	return nil
+}
+
+// Code of RegistrationConflictError, returned whether the email belongs to an existing user or a concurrent signup
+const CodeEmailAlreadyRegistered = "email_already_registered"
+
+// Registration of a taken email, implements httpErrors.RestErr so it maps to 409 instead of a database error
+type RegistrationConflictError struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+}
+
+// Email of a registration is taken
+var ErrEmailAlreadyRegistered = &RegistrationConflictError{
+	ErrStatus: http.StatusConflict,
+	ErrError:  "User with given email already exists",
+	Code:      CodeEmailAlreadyRegistered,
+}
+
+// Error  Error() interface method
+func (e *RegistrationConflictError) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s", e.ErrStatus, e.ErrError)
+}
+
+// Error status
+func (e *RegistrationConflictError) Status() int {
+	return e.ErrStatus
+}
+
+// Conflicts carry no causes, the violated constraint stays in logs
+func (e *RegistrationConflictError) Causes() interface{} {
+	return nil
+}
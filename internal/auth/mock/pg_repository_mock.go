@@ -6,66 +6,52 @@ package mock
 
 import (
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	models "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	utils "github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	gomock "github.com/golang/mock/gomock"
-	reflect "reflect"
 )
 
-// MockRepository is a mock of Repository interface
+// MockRepository is a mock of Repository interface.
 type MockRepository struct {
 	ctrl     *gomock.Controller
 	recorder *MockRepositoryMockRecorder
 }
 
-// MockRepositoryMockRecorder is the mock recorder for MockRepository
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
 type MockRepositoryMockRecorder struct {
 	mock *MockRepository
 }
 
-// NewMockRepository creates a new mock instance
+// NewMockRepository creates a new mock instance.
 func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
 	mock := &MockRepository{ctrl: ctrl}
 	mock.recorder = &MockRepositoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// Register mocks base method
-func (m *MockRepository) Register(ctx context.Context, user *models.User) (*models.User, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, user)
-	ret0, _ := ret[0].(*models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// Register indicates an expected call of Register
-func (mr *MockRepositoryMockRecorder) Register(ctx, user interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRepository)(nil).Register), ctx, user)
-}
-
-// Update mocks base method
-func (m *MockRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+// CancelDeletion mocks base method.
+func (m *MockRepository) CancelDeletion(ctx context.Context, userID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, user)
-	ret0, _ := ret[0].(*models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockRepositoryMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockRepositoryMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockRepository)(nil).CancelDeletion), ctx, userID)
 }
 
-// Delete mocks base method
+// Delete mocks base method.
 func (m *MockRepository) Delete(ctx context.Context, userID int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, userID)
@@ -73,28 +59,28 @@ func (m *MockRepository) Delete(ctx context.Context, userID int) error {
 	return ret0
 }
 
-// Delete indicates an expected call of Delete
+// Delete indicates an expected call of Delete.
 func (mr *MockRepositoryMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, userID)
 }
 
-// GetByID mocks base method
-func (m *MockRepository) GetByID(ctx context.Context, userID int) (*models.User, error) {
+// FindByEmail mocks base method.
+func (m *MockRepository) FindByEmail(ctx context.Context, userEmail string) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", ctx, userID)
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, userEmail)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByID indicates an expected call of GetByID
-func (mr *MockRepositoryMockRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockRepositoryMockRecorder) FindByEmail(ctx, userEmail interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockRepository)(nil).FindByEmail), ctx, userEmail)
 }
 
-// FindByName mocks base method
+// FindByName mocks base method.
 func (m *MockRepository) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindByName", ctx, name, query)
@@ -103,28 +89,43 @@ func (m *MockRepository) FindByName(ctx context.Context, name string, query *uti
 	return ret0, ret1
 }
 
-// FindByName indicates an expected call of FindByName
+// FindByName indicates an expected call of FindByName.
 func (mr *MockRepositoryMockRecorder) FindByName(ctx, name, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockRepository)(nil).FindByName), ctx, name, query)
 }
 
-// FindByEmail mocks base method
-func (m *MockRepository) FindByEmail(ctx context.Context, user *models.User) (*models.User, error) {
+// FindByUsername mocks base method.
+func (m *MockRepository) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByEmail", ctx, user)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "FindByUsername", ctx, username)
+	ret0, _ := ret[0].(*models.UserWithRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUsername indicates an expected call of FindByUsername.
+func (mr *MockRepositoryMockRecorder) FindByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsername", reflect.TypeOf((*MockRepository)(nil).FindByUsername), ctx, username)
+}
+
+// GetByID mocks base method.
+func (m *MockRepository) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, userID)
+	ret0, _ := ret[0].(*models.UserWithRole)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindByEmail indicates an expected call of FindByEmail
-func (mr *MockRepositoryMockRecorder) FindByEmail(ctx, user interface{}) *gomock.Call {
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRepositoryMockRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockRepository)(nil).FindByEmail), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, userID)
 }
 
-// GetUsers mocks base method
+// GetUsers mocks base method.
 func (m *MockRepository) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUsers", ctx, pq)
@@ -133,8 +134,125 @@ func (m *MockRepository) GetUsers(ctx context.Context, pq *utils.PaginationQuery
 	return ret0, ret1
 }
 
-// GetUsers indicates an expected call of GetUsers
+// GetUsers indicates an expected call of GetUsers.
 func (mr *MockRepositoryMockRecorder) GetUsers(ctx, pq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockRepository)(nil).GetUsers), ctx, pq)
 }
+
+// ListDueForDeletion mocks base method.
+func (m *MockRepository) ListDueForDeletion(ctx context.Context, limit int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueForDeletion", ctx, limit)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueForDeletion indicates an expected call of ListDueForDeletion.
+func (mr *MockRepositoryMockRecorder) ListDueForDeletion(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueForDeletion", reflect.TypeOf((*MockRepository)(nil).ListDueForDeletion), ctx, limit)
+}
+
+// PurgeScheduled mocks base method.
+func (m *MockRepository) PurgeScheduled(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeScheduled", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeScheduled indicates an expected call of PurgeScheduled.
+func (mr *MockRepositoryMockRecorder) PurgeScheduled(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeScheduled", reflect.TypeOf((*MockRepository)(nil).PurgeScheduled), ctx, userID)
+}
+
+// Register mocks base method.
+func (m *MockRepository) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, user, roleName)
+	ret0, _ := ret[0].(*models.UserWithRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockRepositoryMockRecorder) Register(ctx, user, roleName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRepository)(nil).Register), ctx, user, roleName)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockRepository) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, userID, grace)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockRepositoryMockRecorder) ScheduleDeletion(ctx, userID, grace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockRepository)(nil).ScheduleDeletion), ctx, userID, grace)
+}
+
+// SetPhoneVerified mocks base method.
+func (m *MockRepository) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPhoneVerified", ctx, userID, phone)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPhoneVerified indicates an expected call of SetPhoneVerified.
+func (mr *MockRepositoryMockRecorder) SetPhoneVerified(ctx, userID, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPhoneVerified", reflect.TypeOf((*MockRepository)(nil).SetPhoneVerified), ctx, userID, phone)
+}
+
+// SetSMS2FA mocks base method.
+func (m *MockRepository) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSMS2FA", ctx, userID, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSMS2FA indicates an expected call of SetSMS2FA.
+func (mr *MockRepositoryMockRecorder) SetSMS2FA(ctx, userID, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSMS2FA", reflect.TypeOf((*MockRepository)(nil).SetSMS2FA), ctx, userID, enabled)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, user)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockRepository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockRepositoryMockRecorder) UpdatePassword(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), ctx, userID, passwordHash)
+}
@@ -6,73 +6,177 @@ package mock
 
 import (
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	models "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	gomock "github.com/golang/mock/gomock"
-	reflect "reflect"
 )
 
-// MockRedisRepository is a mock of RedisRepository interface
+// MockRedisRepository is a mock of RedisRepository interface.
 type MockRedisRepository struct {
 	ctrl     *gomock.Controller
 	recorder *MockRedisRepositoryMockRecorder
 }
 
-// MockRedisRepositoryMockRecorder is the mock recorder for MockRedisRepository
+// MockRedisRepositoryMockRecorder is the mock recorder for MockRedisRepository.
 type MockRedisRepositoryMockRecorder struct {
 	mock *MockRedisRepository
 }
 
-// NewMockRedisRepository creates a new mock instance
+// NewMockRedisRepository creates a new mock instance.
 func NewMockRedisRepository(ctrl *gomock.Controller) *MockRedisRepository {
 	mock := &MockRedisRepository{ctrl: ctrl}
 	mock.recorder = &MockRedisRepositoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRedisRepository) EXPECT() *MockRedisRepositoryMockRecorder {
 	return m.recorder
 }
 
-// GetByIDCtx mocks base method
-func (m *MockRedisRepository) GetByIDCtx(ctx context.Context, key string) (*models.User, error) {
+// DeleteByPatternCtx mocks base method.
+func (m *MockRedisRepository) DeleteByPatternCtx(ctx context.Context, pattern string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPatternCtx", ctx, pattern)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByPatternCtx indicates an expected call of DeleteByPatternCtx.
+func (mr *MockRedisRepositoryMockRecorder) DeleteByPatternCtx(ctx, pattern interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPatternCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteByPatternCtx), ctx, pattern)
+}
+
+// DeleteUserCtx mocks base method.
+func (m *MockRedisRepository) DeleteUserCtx(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserCtx", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserCtx indicates an expected call of DeleteUserCtx.
+func (mr *MockRedisRepositoryMockRecorder) DeleteUserCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteUserCtx), ctx, key)
+}
+
+// GetAccessCtx mocks base method.
+func (m *MockRedisRepository) GetAccessCtx(ctx context.Context, key string) (*models.Access, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessCtx", ctx, key)
+	ret0, _ := ret[0].(*models.Access)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessCtx indicates an expected call of GetAccessCtx.
+func (mr *MockRedisRepositoryMockRecorder) GetAccessCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetAccessCtx), ctx, key)
+}
+
+// GetByIDCtx mocks base method.
+func (m *MockRedisRepository) GetByIDCtx(ctx context.Context, key string) (*models.UserWithRole, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByIDCtx", ctx, key)
-	ret0, _ := ret[0].(*models.User)
+	ret0, _ := ret[0].(*models.UserWithRole)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByIDCtx indicates an expected call of GetByIDCtx
+// GetByIDCtx indicates an expected call of GetByIDCtx.
 func (mr *MockRedisRepositoryMockRecorder) GetByIDCtx(ctx, key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetByIDCtx), ctx, key)
 }
 
-// SetUserCtx mocks base method
-func (m *MockRedisRepository) SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error {
+// GetUsersListCtx mocks base method.
+func (m *MockRedisRepository) GetUsersListCtx(ctx context.Context, key string) (*models.CachedUsersList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersListCtx", ctx, key)
+	ret0, _ := ret[0].(*models.CachedUsersList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersListCtx indicates an expected call of GetUsersListCtx.
+func (mr *MockRedisRepositoryMockRecorder) GetUsersListCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersListCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetUsersListCtx), ctx, key)
+}
+
+// ReleaseFieldChangeCtx mocks base method.
+func (m *MockRedisRepository) ReleaseFieldChangeCtx(ctx context.Context, key, member string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseFieldChangeCtx", ctx, key, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseFieldChangeCtx indicates an expected call of ReleaseFieldChangeCtx.
+func (mr *MockRedisRepositoryMockRecorder) ReleaseFieldChangeCtx(ctx, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseFieldChangeCtx", reflect.TypeOf((*MockRedisRepository)(nil).ReleaseFieldChangeCtx), ctx, key, member)
+}
+
+// ReserveFieldChangeCtx mocks base method.
+func (m *MockRedisRepository) ReserveFieldChangeCtx(ctx context.Context, key, member string, at time.Time, period time.Duration, limit int) (bool, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveFieldChangeCtx", ctx, key, member, at, period, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReserveFieldChangeCtx indicates an expected call of ReserveFieldChangeCtx.
+func (mr *MockRedisRepositoryMockRecorder) ReserveFieldChangeCtx(ctx, key, member, at, period, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveFieldChangeCtx", reflect.TypeOf((*MockRedisRepository)(nil).ReserveFieldChangeCtx), ctx, key, member, at, period, limit)
+}
+
+// SetAccessCtx mocks base method.
+func (m *MockRedisRepository) SetAccessCtx(ctx context.Context, key string, seconds int, access *models.Access) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccessCtx", ctx, key, seconds, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccessCtx indicates an expected call of SetAccessCtx.
+func (mr *MockRedisRepositoryMockRecorder) SetAccessCtx(ctx, key, seconds, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccessCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetAccessCtx), ctx, key, seconds, access)
+}
+
+// SetUserCtx mocks base method.
+func (m *MockRedisRepository) SetUserCtx(ctx context.Context, key string, seconds int, user *models.UserWithRole) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetUserCtx", ctx, key, seconds, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetUserCtx indicates an expected call of SetUserCtx
+// SetUserCtx indicates an expected call of SetUserCtx.
 func (mr *MockRedisRepositoryMockRecorder) SetUserCtx(ctx, key, seconds, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetUserCtx), ctx, key, seconds, user)
 }
 
-// DeleteUserCtx mocks base method
-func (m *MockRedisRepository) DeleteUserCtx(ctx context.Context, key string) error {
+// SetUsersListCtx mocks base method.
+func (m *MockRedisRepository) SetUsersListCtx(ctx context.Context, key string, seconds int, list *models.CachedUsersList) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserCtx", ctx, key)
+	ret := m.ctrl.Call(m, "SetUsersListCtx", ctx, key, seconds, list)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUserCtx indicates an expected call of DeleteUserCtx
-func (mr *MockRedisRepositoryMockRecorder) DeleteUserCtx(ctx, key interface{}) *gomock.Call {
+// SetUsersListCtx indicates an expected call of SetUsersListCtx.
+func (mr *MockRedisRepositoryMockRecorder) SetUsersListCtx(ctx, key, seconds, list interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteUserCtx), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUsersListCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetUsersListCtx), ctx, key, seconds, list)
 }
@@ -6,81 +6,81 @@ package mock
 
 import (
 	context "context"
+	reflect "reflect"
+
+	dto "github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
 	models "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	utils "github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	gomock "github.com/golang/mock/gomock"
-	reflect "reflect"
 )
 
-// MockUseCase is a mock of UseCase interface
+// MockUseCase is a mock of UseCase interface.
 type MockUseCase struct {
 	ctrl     *gomock.Controller
 	recorder *MockUseCaseMockRecorder
 }
 
-// MockUseCaseMockRecorder is the mock recorder for MockUseCase
+// MockUseCaseMockRecorder is the mock recorder for MockUseCase.
 type MockUseCaseMockRecorder struct {
 	mock *MockUseCase
 }
 
-// NewMockUseCase creates a new mock instance
+// NewMockUseCase creates a new mock instance.
 func NewMockUseCase(ctrl *gomock.Controller) *MockUseCase {
 	mock := &MockUseCase{ctrl: ctrl}
 	mock.recorder = &MockUseCaseMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockUseCase) EXPECT() *MockUseCaseMockRecorder {
 	return m.recorder
 }
 
-// Register mocks base method
-func (m *MockUseCase) Register(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+// BatchUsers mocks base method.
+func (m *MockUseCase) BatchUsers(ctx context.Context, req *models.UserBatchRequest) (*models.UserBatchResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, user)
-	ret0, _ := ret[0].(*models.UserWithToken)
+	ret := m.ctrl.Call(m, "BatchUsers", ctx, req)
+	ret0, _ := ret[0].(*models.UserBatchResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Register indicates an expected call of Register
-func (mr *MockUseCaseMockRecorder) Register(ctx, user interface{}) *gomock.Call {
+// BatchUsers indicates an expected call of BatchUsers.
+func (mr *MockUseCaseMockRecorder) BatchUsers(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUseCase)(nil).Register), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUsers", reflect.TypeOf((*MockUseCase)(nil).BatchUsers), ctx, req)
 }
 
-// Login mocks base method
-func (m *MockUseCase) Login(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+// CancelDeletion mocks base method.
+func (m *MockUseCase) CancelDeletion(ctx context.Context, userID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, user)
-	ret0, _ := ret[0].(*models.UserWithToken)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Login indicates an expected call of Login
-func (mr *MockUseCaseMockRecorder) Login(ctx, user interface{}) *gomock.Call {
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockUseCaseMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUseCase)(nil).Login), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockUseCase)(nil).CancelDeletion), ctx, userID)
 }
 
-// Update mocks base method
-func (m *MockUseCase) Update(ctx context.Context, user *models.User) (*models.User, error) {
+// ChangePassword mocks base method.
+func (m *MockUseCase) ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, user)
-	ret0, _ := ret[0].(*models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockUseCaseMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUseCaseMockRecorder) ChangePassword(ctx, userID, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUseCase)(nil).Update), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUseCase)(nil).ChangePassword), ctx, userID, req)
 }
 
-// Delete mocks base method
+// Delete mocks base method.
 func (m *MockUseCase) Delete(ctx context.Context, userID int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, userID)
@@ -88,43 +88,73 @@ func (m *MockUseCase) Delete(ctx context.Context, userID int) error {
 	return ret0
 }
 
-// Delete indicates an expected call of Delete
+// Delete indicates an expected call of Delete.
 func (mr *MockUseCaseMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUseCase)(nil).Delete), ctx, userID)
 }
 
-// GetByID mocks base method
-func (m *MockUseCase) GetByID(ctx context.Context, userID int) (*models.User, error) {
+// FindByName mocks base method.
+func (m *MockUseCase) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", ctx, userID)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "FindByName", ctx, name, query)
+	ret0, _ := ret[0].(*models.UsersList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByID indicates an expected call of GetByID
-func (mr *MockUseCaseMockRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
+// FindByName indicates an expected call of FindByName.
+func (mr *MockUseCaseMockRecorder) FindByName(ctx, name, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUseCase)(nil).GetByID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockUseCase)(nil).FindByName), ctx, name, query)
 }
 
-// FindByName mocks base method
-func (m *MockUseCase) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+// GetAccess mocks base method.
+func (m *MockUseCase) GetAccess(ctx context.Context, user *models.UserWithRole) (*models.Access, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByName", ctx, name, query)
-	ret0, _ := ret[0].(*models.UsersList)
+	ret := m.ctrl.Call(m, "GetAccess", ctx, user)
+	ret0, _ := ret[0].(*models.Access)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindByName indicates an expected call of FindByName
-func (mr *MockUseCaseMockRecorder) FindByName(ctx, name, query interface{}) *gomock.Call {
+// GetAccess indicates an expected call of GetAccess.
+func (mr *MockUseCaseMockRecorder) GetAccess(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockUseCase)(nil).FindByName), ctx, name, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccess", reflect.TypeOf((*MockUseCase)(nil).GetAccess), ctx, user)
+}
+
+// GetByEmail mocks base method.
+func (m *MockUseCase) GetByEmail(ctx context.Context, email string) (*models.UserWithRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*models.UserWithRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUseCaseMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUseCase)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockUseCase) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, userID)
+	ret0, _ := ret[0].(*models.UserWithRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockUseCaseMockRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUseCase)(nil).GetByID), ctx, userID)
 }
 
-// GetUsers mocks base method
+// GetUsers mocks base method.
 func (m *MockUseCase) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUsers", ctx, pq)
@@ -133,23 +163,184 @@ func (m *MockUseCase) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (
 	return ret0, ret1
 }
 
-// GetUsers indicates an expected call of GetUsers
+// GetUsers indicates an expected call of GetUsers.
 func (mr *MockUseCaseMockRecorder) GetUsers(ctx, pq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUseCase)(nil).GetUsers), ctx, pq)
 }
 
-// UploadAvatar mocks base method
-func (m *MockUseCase) UploadAvatar(ctx context.Context, userID int, file models.UploadInput) (*models.User, error) {
+// InvalidateUserCache mocks base method.
+func (m *MockUseCase) InvalidateUserCache(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUserCache", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserCache indicates an expected call of InvalidateUserCache.
+func (mr *MockUseCaseMockRecorder) InvalidateUserCache(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserCache", reflect.TypeOf((*MockUseCase)(nil).InvalidateUserCache), ctx, userID)
+}
+
+// InvalidateUsersCache mocks base method.
+func (m *MockUseCase) InvalidateUsersCache(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUsersCache", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUsersCache indicates an expected call of InvalidateUsersCache.
+func (mr *MockUseCaseMockRecorder) InvalidateUsersCache(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUsersCache", reflect.TypeOf((*MockUseCase)(nil).InvalidateUsersCache), ctx)
+}
+
+// IssueScopedToken mocks base method.
+func (m *MockUseCase) IssueScopedToken(ctx context.Context, userID int, req *dto.TokenExchangeRequest) (*models.ScopedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueScopedToken", ctx, userID, req)
+	ret0, _ := ret[0].(*models.ScopedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueScopedToken indicates an expected call of IssueScopedToken.
+func (mr *MockUseCaseMockRecorder) IssueScopedToken(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueScopedToken", reflect.TypeOf((*MockUseCase)(nil).IssueScopedToken), ctx, userID, req)
+}
+
+// IssueToken mocks base method.
+func (m *MockUseCase) IssueToken(ctx context.Context, userID int) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueToken", ctx, userID)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueToken indicates an expected call of IssueToken.
+func (mr *MockUseCaseMockRecorder) IssueToken(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueToken", reflect.TypeOf((*MockUseCase)(nil).IssueToken), ctx, userID)
+}
+
+// Login mocks base method.
+func (m *MockUseCase) Login(ctx context.Context, user *dto.LoginUserRequest) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, user)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockUseCaseMockRecorder) Login(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUseCase)(nil).Login), ctx, user)
+}
+
+// PurgeDueDeletions mocks base method.
+func (m *MockUseCase) PurgeDueDeletions(ctx context.Context, dryRun bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDueDeletions", ctx, dryRun)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDueDeletions indicates an expected call of PurgeDueDeletions.
+func (mr *MockUseCaseMockRecorder) PurgeDueDeletions(ctx, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDueDeletions", reflect.TypeOf((*MockUseCase)(nil).PurgeDueDeletions), ctx, dryRun)
+}
+
+// Register mocks base method.
+func (m *MockUseCase) Register(ctx context.Context, user *dto.RegisterUserRequest) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, user)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUseCaseMockRecorder) Register(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUseCase)(nil).Register), ctx, user)
+}
+
+// RequestDeletion mocks base method.
+func (m *MockUseCase) RequestDeletion(ctx context.Context, userID int) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDeletion", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDeletion indicates an expected call of RequestDeletion.
+func (mr *MockUseCaseMockRecorder) RequestDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDeletion", reflect.TypeOf((*MockUseCase)(nil).RequestDeletion), ctx, userID)
+}
+
+// SetPhoneVerified mocks base method.
+func (m *MockUseCase) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPhoneVerified", ctx, userID, phone)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPhoneVerified indicates an expected call of SetPhoneVerified.
+func (mr *MockUseCaseMockRecorder) SetPhoneVerified(ctx, userID, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPhoneVerified", reflect.TypeOf((*MockUseCase)(nil).SetPhoneVerified), ctx, userID, phone)
+}
+
+// SetSMS2FA mocks base method.
+func (m *MockUseCase) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UploadAvatar", ctx, userID, file)
+	ret := m.ctrl.Call(m, "SetSMS2FA", ctx, userID, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSMS2FA indicates an expected call of SetSMS2FA.
+func (mr *MockUseCaseMockRecorder) SetSMS2FA(ctx, userID, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSMS2FA", reflect.TypeOf((*MockUseCase)(nil).SetSMS2FA), ctx, userID, enabled)
+}
+
+// Update mocks base method.
+func (m *MockUseCase) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, user)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UploadAvatar indicates an expected call of UploadAvatar
-func (mr *MockUseCaseMockRecorder) UploadAvatar(ctx, userID, file interface{}) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockUseCaseMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUseCase)(nil).Update), ctx, user)
+}
+
+// VerifyPassword mocks base method.
+func (m *MockUseCase) VerifyPassword(ctx context.Context, userID int, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPassword", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyPassword indicates an expected call of VerifyPassword.
+func (mr *MockUseCaseMockRecorder) VerifyPassword(ctx, userID, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAvatar", reflect.TypeOf((*MockUseCase)(nil).UploadAvatar), ctx, userID, file)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPassword", reflect.TypeOf((*MockUseCase)(nil).VerifyPassword), ctx, userID, password)
 }
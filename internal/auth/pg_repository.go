@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
@@ -10,7 +11,7 @@ import (
 
 // Auth repository interface
 type Repository interface {
-	Register(ctx context.Context, user *models.User) (*models.UserWithRole, error)
+	Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, userID int) error
 	GetByID(ctx context.Context, userID int) (*models.UserWithRole, error)
@@ -18,4 +19,12 @@ type Repository interface {
 	FindByEmail(ctx context.Context, userEmail string) (*models.User, error)
 	FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+	SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error)
+	SetSMS2FA(ctx context.Context, userID int, enabled bool) error
+	// Replace password with its bcrypt hash
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	ListDueForDeletion(ctx context.Context, limit int) ([]int, error)
+	PurgeScheduled(ctx context.Context, userID int) error
 }
@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 )
@@ -12,4 +13,13 @@ type RedisRepository interface {
 	GetByIDCtx(ctx context.Context, key string) (*models.UserWithRole, error)
 	SetUserCtx(ctx context.Context, key string, seconds int, user *models.UserWithRole) error
 	DeleteUserCtx(ctx context.Context, key string) error
+	DeleteByPatternCtx(ctx context.Context, pattern string) error
+	GetUsersListCtx(ctx context.Context, key string) (*models.CachedUsersList, error)
+	SetUsersListCtx(ctx context.Context, key string, seconds int, list *models.CachedUsersList) error
+	GetAccessCtx(ctx context.Context, key string) (*models.Access, error)
+	SetAccessCtx(ctx context.Context, key string, seconds int, access *models.Access) error
+	// Record a change of a field at unless limit changes fall within period, else when the next one is allowed
+	ReserveFieldChangeCtx(ctx context.Context, key string, member string, at time.Time, period time.Duration, limit int) (bool, time.Time, error)
+	// Forget a change recorded by ReserveFieldChangeCtx
+	ReleaseFieldChangeCtx(ctx context.Context, key string, member string) error
 }
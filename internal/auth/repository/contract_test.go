@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/stdlib" // pgx driver
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Contract tests run against a migrated database, e.g.
+// POSTGRES_TEST_DSN="host=localhost port=5432 user=postgres password=postgres dbname=user_service_db sslmode=disable"
+const testDSNEnv = "POSTGRES_TEST_DSN"
+
+func testDSN(t testing.TB) string {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	return dsn
+}
+
+func TestAuthRepository_SqlxContract(t *testing.T) {
+	db, err := sqlx.Connect("pgx", testDSN(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	runRepositoryContract(t, NewAuthRepository(db, nil, nil, nil))
+}
+
+func TestAuthRepository_PgxPoolContract(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), testDSN(t))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	runRepositoryContract(t, NewAuthPgxRepository(pool, nil, nil))
+}
+
+func TestAuthRepository_MemoryContract(t *testing.T) {
+	runRepositoryContract(t, NewAuthMemoryRepository())
+}
+
+func runRepositoryContract(t *testing.T, repo auth.Repository) {
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	pq := &utils.PaginationQuery{Page: 1, Size: 10}
+
+	created, err := repo.Register(ctx, &models.User{
+		Username: fmt.Sprintf("contract_%d", suffix),
+		Email:    fmt.Sprintf("contract_%d@example.com", suffix),
+		Password: "hashed",
+	}, defaultRoleName)
+	require.NoError(t, err)
+	require.NotZero(t, created.User.ID)
+	require.Equal(t, defaultRoleName, created.Role.Name)
+
+	byEmail, err := repo.FindByEmail(ctx, created.User.Email)
+	require.NoError(t, err)
+	require.Equal(t, created.User.ID, byEmail.ID)
+
+	byUsername, err := repo.FindByUsername(ctx, created.User.Username)
+	require.NoError(t, err)
+	require.Equal(t, created.User.ID, byUsername.User.ID)
+	require.Equal(t, created.Role.ID, byUsername.Role.ID)
+
+	byID, err := repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	require.Equal(t, created.User.Username, byID.User.Username)
+
+	updated, err := repo.Update(ctx, &models.User{ID: created.User.ID, Username: created.User.Username + "_upd"})
+	require.NoError(t, err)
+	require.Equal(t, created.User.Username+"_upd", updated.Username)
+	require.Equal(t, created.User.Email, updated.Email)
+
+	require.True(t, errors.Is(repo.SetSMS2FA(ctx, created.User.ID, true), sql.ErrNoRows))
+	verified, err := repo.SetPhoneVerified(ctx, created.User.ID, "+15550001111")
+	require.NoError(t, err)
+	require.NotNil(t, verified.PhoneVerifiedAt)
+	require.NoError(t, repo.SetSMS2FA(ctx, created.User.ID, true))
+
+	require.NoError(t, repo.UpdatePassword(ctx, created.User.ID, "rehashed"))
+	rehashed, err := repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	require.Equal(t, "rehashed", rehashed.User.Password)
+	require.True(t, errors.Is(repo.UpdatePassword(ctx, -1, "rehashed"), sql.ErrNoRows))
+
+	// Users handed out do not alias the stored ones
+	*verified.PhoneVerifiedAt = time.Time{}
+	reread, err := repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	require.False(t, reread.User.PhoneVerifiedAt.IsZero())
+
+	// Same phone keeps verification, a different one drops it together with the second factor
+	samePhone, err := repo.Update(ctx, &models.User{ID: created.User.ID, Phone: "+15550001111"})
+	require.NoError(t, err)
+	require.NotNil(t, samePhone.PhoneVerifiedAt)
+	require.True(t, samePhone.SMS2FA)
+	newPhone, err := repo.Update(ctx, &models.User{ID: created.User.ID, Phone: "+15550002222"})
+	require.NoError(t, err)
+	require.Nil(t, newPhone.PhoneVerifiedAt)
+	require.False(t, newPhone.SMS2FA)
+
+	// Deletion keeps its first schedule and can be cancelled until purged
+	pending, err := repo.ScheduleDeletion(ctx, created.User.ID, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusPendingDeletion, pending.Status)
+	rescheduled, err := repo.ScheduleDeletion(ctx, created.User.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, pending.DeletionScheduledAt, rescheduled.DeletionScheduledAt)
+	require.NoError(t, repo.CancelDeletion(ctx, created.User.ID))
+	require.True(t, errors.Is(repo.CancelDeletion(ctx, created.User.ID), sql.ErrNoRows))
+	require.True(t, errors.Is(repo.PurgeScheduled(ctx, created.User.ID), sql.ErrNoRows))
+
+	_, err = repo.ScheduleDeletion(ctx, created.User.ID, 0)
+	require.NoError(t, err)
+	due, err := repo.ListDueForDeletion(ctx, 1000)
+	require.NoError(t, err)
+	require.Contains(t, due, created.User.ID)
+	require.NoError(t, repo.CancelDeletion(ctx, created.User.ID))
+
+	found, err := repo.FindByName(ctx, updated.Username, pq)
+	require.NoError(t, err)
+	require.Equal(t, 1, found.TotalCount)
+	require.Len(t, found.Users, 1)
+
+	list, err := repo.GetUsers(ctx, pq)
+	require.NoError(t, err)
+	require.NotZero(t, list.TotalCount)
+
+	require.NoError(t, repo.Delete(ctx, created.User.ID))
+
+	_, err = repo.GetByID(ctx, created.User.ID)
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+	require.True(t, errors.Is(repo.Delete(ctx, created.User.ID), sql.ErrNoRows))
+
+	runConcurrentSignups(t, repo, suffix)
+	runNameSearch(t, repo, suffix)
+}
+
+// Signups racing on one email register a single user, the others get the conflict instead of a database error
+func runConcurrentSignups(t *testing.T, repo auth.Repository, suffix int64) {
+	const signups = 8
+	ctx := context.Background()
+	email := fmt.Sprintf("race_%d@example.com", suffix)
+
+	var wg sync.WaitGroup
+	created := make(chan *models.UserWithRole, signups)
+	failed := make(chan error, signups)
+	for i := 0; i < signups; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user, err := repo.Register(ctx, &models.User{
+				Username: fmt.Sprintf("race_%d_%d", suffix, i),
+				Email:    email,
+				Password: "hashed",
+			}, defaultRoleName)
+			if err != nil {
+				failed <- err
+				return
+			}
+			created <- user
+		}(i)
+	}
+	wg.Wait()
+	close(created)
+	close(failed)
+
+	require.Len(t, created, 1)
+	winner := <-created
+	defer repo.Delete(ctx, winner.User.ID) // nolint: errcheck
+	require.Len(t, failed, signups-1)
+	for err := range failed {
+		require.ErrorIs(t, err, auth.ErrEmailAlreadyRegistered)
+	}
+}
+
+// Names starting with the search term come first, a misspelled name finds similar ones
+func runNameSearch(t *testing.T, repo auth.Repository, suffix int64) {
+	ctx := context.Background()
+	base := fmt.Sprintf("search%d", suffix)
+	ids := map[int]bool{}
+	for _, username := range []string{"aa_" + base, base + "_zz"} {
+		created, err := repo.Register(ctx, &models.User{Username: username, Email: username + "@example.com", Password: "hashed"}, defaultRoleName)
+		require.NoError(t, err)
+		ids[created.User.ID] = true
+		defer func() { require.NoError(t, repo.Delete(ctx, created.User.ID)) }()
+	}
+
+	found, err := repo.FindByName(ctx, strings.ToUpper(base), &utils.PaginationQuery{Page: 1, Size: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, found.TotalCount)
+	require.Equal(t, []string{base + "_zz", "aa_" + base}, []string{found.Users[0].Username, found.Users[1].Username})
+
+	misspelled := base[:len(base)-1] + "x"
+	for _, count := range []utils.CountStrategy{utils.CountExact, utils.CountNone} {
+		found, err = repo.FindByName(ctx, misspelled, &utils.PaginationQuery{Page: 1, Size: 10, Count: count})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(found.Users), 2, count)
+		require.True(t, ids[found.Users[0].ID] && ids[found.Users[1].ID], count)
+	}
+}
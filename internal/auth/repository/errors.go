@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+)
+
+// SQLSTATE of unique violations
+const uniqueViolation = "23505"
+
+// Serializes registrations of one email, case insensitively, so the existence check inside the transaction
+// sees the user a concurrent signup created
+const lockRegistrationQuery = `SELECT pg_advisory_xact_lock(hashtext('users_email:' || lower($1)))`
+
+// Registration error, unique violations of the email columns are the conflict the lock avoids and are still
+// reached by writers not taking it
+func registerErr(err error) error {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) && pgErr.SQLState() == uniqueViolation && strings.Contains(err.Error(), "email") {
+		return auth.ErrEmailAlreadyRegistered
+	}
+	return err
+}
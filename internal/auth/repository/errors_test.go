@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"net/http"
+	"testing"
+
+	pgxv3 "github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestRegisterErr(t *testing.T) {
+	t.Parallel()
+
+	// Both drivers, wrapped like the queries return them
+	for _, err := range []error{
+		errors.Wrap(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "idx_users_email_bidx",
+			Message: `duplicate key value violates unique constraint "idx_users_email_bidx"`}, "CreateUser"),
+		errors.Wrap(pgxv3.PgError{Code: uniqueViolation, ConstraintName: "users_email_key",
+			Message: `duplicate key value violates unique constraint "users_email_key"`}, "CreateUser"),
+	} {
+		mapped := registerErr(err)
+		require.ErrorIs(t, mapped, auth.ErrEmailAlreadyRegistered)
+		require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(errors.Wrap(mapped, "authRepo.Register")).Status())
+	}
+
+	// Other violations keep their error
+	username := &pgconn.PgError{Code: uniqueViolation, Message: `duplicate key value violates unique constraint "users_username_key"`}
+	require.Equal(t, error(username), registerErr(username))
+	other := errors.New("connection reset")
+	require.Equal(t, other, registerErr(other))
+}
@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultTimezone = "UTC"
+	defaultRoleName = "employee"
+)
+
+// Roles seeded by the initial migration
+var memoryRoles = map[string]models.Role{
+	"administrator": {ID: 1, Name: "administrator", Description: "Administrator"},
+	defaultRoleName: {ID: 2, Name: defaultRoleName, Description: "Employee User"},
+}
+
+// Auth Repository kept in process memory, dev mode stand-in for Postgres, PII is not encrypted.
+// Users handed out are copies, their pointer fields are never shared with the stored ones.
+type authMemoryRepo struct {
+	mu        sync.RWMutex
+	lastID    int
+	users     map[int]models.User
+	userRoles map[int]models.Role
+}
+
+// Auth in-memory Repository constructor
+func NewAuthMemoryRepository() auth.Repository {
+	return &authMemoryRepo{users: make(map[int]models.User), userRoles: make(map[int]models.Role)}
+}
+
+// Create new user with the given role
+func (r *authMemoryRepo) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.Register")
+	defer span.Finish()
+
+	role, ok := memoryRoles[roleName]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.Register.GetRoleByName")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return nil, auth.ErrEmailAlreadyRegistered
+		}
+		if existing.Username == user.Username {
+			return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadRequest.Error(), "users unique violation")
+		}
+	}
+
+	now := time.Now()
+	r.lastID++
+	created := models.User{
+		ID:        r.lastID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Password:  user.Password,
+		Phone:     user.Phone,
+		Timezone:  defaultTimezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+		LoginDate: now,
+	}
+	r.users[created.ID] = created
+	r.userRoles[created.ID] = role
+
+	return &models.UserWithRole{User: withStatus(created), Role: r.userRoles[created.ID]}, nil
+}
+
+// Update existing user, empty fields are kept
+func (r *authMemoryRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.Update")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.Update")
+	}
+	if user.Username != "" {
+		stored.Username = user.Username
+	}
+	if user.Email != "" {
+		stored.Email = user.Email
+	}
+	// A different phone number has to be verified again and drops the SMS second factor
+	if user.Phone != "" && user.Phone != stored.Phone {
+		stored.Phone = user.Phone
+		stored.PhoneVerifiedAt = nil
+		stored.SMS2FA = false
+	}
+	if user.Timezone != "" {
+		stored.Timezone = user.Timezone
+	}
+	stored.UpdatedAt = time.Now()
+	r.users[user.ID] = stored
+
+	updated := withStatus(stored)
+	return &updated, nil
+}
+
+// Delete existing user
+func (r *authMemoryRepo) Delete(ctx context.Context, userID int) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.Delete")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.delete(userID)
+}
+
+// Get user by id
+func (r *authMemoryRepo) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.GetByID")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.GetByID")
+	}
+	return &models.UserWithRole{User: withStatus(stored), Role: r.userRoles[userID]}, nil
+}
+
+// Find users whose name contains the search term, names starting with it first, or when none does users with
+// similar names like the trigram index finds them. Dev mode has no organization tables so listings are never
+// scoped to one
+func (r *authMemoryRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.FindByName")
+	defer span.Finish()
+
+	name = strings.ToLower(name)
+	contains := func(user *models.User) bool {
+		return strings.Contains(strings.ToLower(user.Username), name)
+	}
+	if r.any(contains) {
+		return r.list(query, contains, func(a, b *models.User) bool {
+			aPrefix, bPrefix := strings.HasPrefix(strings.ToLower(a.Username), name), strings.HasPrefix(strings.ToLower(b.Username), name)
+			if aPrefix != bPrefix {
+				return aPrefix
+			}
+			return a.Username < b.Username
+		}), nil
+	}
+	return r.list(query, func(user *models.User) bool {
+		return trigramSimilarity(user.Username, name) >= similarityThreshold
+	}, func(a, b *models.User) bool {
+		aSimilarity, bSimilarity := trigramSimilarity(a.Username, name), trigramSimilarity(b.Username, name)
+		if aSimilarity != bSimilarity {
+			return aSimilarity > bSimilarity
+		}
+		return a.Username < b.Username
+	}), nil
+}
+
+// Get users with pagination, never scoped to an organization
+func (r *authMemoryRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.GetUsers")
+	defer span.Finish()
+
+	return r.list(pq, func(*models.User) bool { return true }, byUsername), nil
+}
+
+// Find user by email
+func (r *authMemoryRepo) FindByEmail(ctx context.Context, userEmail string) (*models.User, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.FindByEmail")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.users {
+		if stored.Email == userEmail {
+			found := withStatus(stored)
+			return &found, nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.FindByEmail")
+}
+
+// Find user with role by username
+func (r *authMemoryRepo) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.FindByUsername")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, stored := range r.users {
+		if stored.Username == username {
+			return &models.UserWithRole{User: withStatus(stored), Role: r.userRoles[id]}, nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.FindByUsername")
+}
+
+// Store a phone number verified by SMS code
+func (r *authMemoryRepo) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.SetPhoneVerified")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.SetPhoneVerified")
+	}
+	now := time.Now()
+	stored.Phone = phone
+	stored.PhoneVerifiedAt = &now
+	stored.UpdatedAt = now
+	r.users[userID] = stored
+
+	updated := withStatus(stored)
+	return &updated, nil
+}
+
+// Toggle SMS second factor, enabling fails with sql.ErrNoRows unless the phone is verified
+func (r *authMemoryRepo) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.SetSMS2FA")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok || (enabled && stored.PhoneVerifiedAt == nil) {
+		return errors.Wrap(sql.ErrNoRows, "authMemoryRepo.SetSMS2FA")
+	}
+	stored.SMS2FA = enabled
+	stored.UpdatedAt = time.Now()
+	r.users[userID] = stored
+	return nil
+}
+
+// Replace password with its bcrypt hash
+func (r *authMemoryRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.UpdatePassword")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return errors.Wrap(sql.ErrNoRows, "authMemoryRepo.UpdatePassword")
+	}
+	stored.Password = passwordHash
+	stored.UpdatedAt = time.Now()
+	r.users[userID] = stored
+	return nil
+}
+
+// Schedule user deletion after grace, an already pending deletion keeps its schedule
+func (r *authMemoryRepo) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.ScheduleDeletion")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "authMemoryRepo.ScheduleDeletion")
+	}
+	now := time.Now()
+	if stored.DeletionRequestedAt == nil {
+		stored.DeletionRequestedAt = &now
+	}
+	if stored.DeletionScheduledAt == nil {
+		scheduledAt := now.Add(grace)
+		stored.DeletionScheduledAt = &scheduledAt
+	}
+	stored.UpdatedAt = now
+	r.users[userID] = stored
+
+	updated := withStatus(stored)
+	return &updated, nil
+}
+
+// Cancel pending deletion, sql.ErrNoRows when none is pending
+func (r *authMemoryRepo) CancelDeletion(ctx context.Context, userID int) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.CancelDeletion")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok || stored.DeletionScheduledAt == nil {
+		return errors.Wrap(sql.ErrNoRows, "authMemoryRepo.CancelDeletion")
+	}
+	stored.DeletionRequestedAt = nil
+	stored.DeletionScheduledAt = nil
+	stored.UpdatedAt = time.Now()
+	r.users[userID] = stored
+	return nil
+}
+
+// Ids of users whose deletion grace period is over, oldest schedule first
+func (r *authMemoryRepo) ListDueForDeletion(ctx context.Context, limit int) ([]int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.ListDueForDeletion")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	due := make([]models.User, 0)
+	for _, stored := range r.users {
+		if stored.DeletionScheduledAt != nil && !stored.DeletionScheduledAt.After(now) {
+			due = append(due, stored)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].DeletionScheduledAt.Before(*due[j].DeletionScheduledAt) })
+
+	ids := make([]int, 0, len(due))
+	for _, user := range due {
+		if len(ids) == limit {
+			break
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+// Delete user whose deletion is due, sql.ErrNoRows when it was cancelled meanwhile
+func (r *authMemoryRepo) PurgeScheduled(ctx context.Context, userID int) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authMemoryRepo.PurgeScheduled")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userID]
+	if !ok || stored.DeletionScheduledAt == nil || stored.DeletionScheduledAt.After(time.Now()) {
+		return errors.Wrap(sql.ErrNoRows, "authMemoryRepo.PurgeScheduled")
+	}
+	return r.delete(userID)
+}
+
+func (r *authMemoryRepo) delete(userID int) error {
+	if _, ok := r.users[userID]; !ok {
+		return errors.Wrap(sql.ErrNoRows, "authMemoryRepo.delete")
+	}
+	delete(r.users, userID)
+	delete(r.userRoles, userID)
+	return nil
+}
+
+// Listing order of every user
+func byUsername(a, b *models.User) bool {
+	return a.Username < b.Username
+}
+
+// Whether a stored user matches
+func (r *authMemoryRepo) any(match func(*models.User) bool) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.users {
+		if match(&stored) {
+			return true
+		}
+	}
+	return false
+}
+
+// Page of matching users in the order of less, with the columns the SQL listing selects
+func (r *authMemoryRepo) list(pq *utils.PaginationQuery, match func(*models.User) bool, less func(a, b *models.User) bool) *models.UsersList {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0)
+	for _, stored := range r.users {
+		if !match(&stored) {
+			continue
+		}
+		users = append(users, &models.User{
+			ID:        stored.ID,
+			Username:  stored.Username,
+			Email:     stored.Email,
+			CreatedAt: stored.CreatedAt,
+			UpdatedAt: stored.UpdatedAt,
+			LoginDate: stored.LoginDate,
+			Timezone:  stored.Timezone,
+			Phone:     stored.Phone,
+		})
+	}
+	sort.Slice(users, func(i, j int) bool { return less(users[i], users[j]) })
+
+	totalCount := len(users)
+	offset := pq.GetOffset()
+	if offset > totalCount {
+		offset = totalCount
+	}
+	end := totalCount
+	if limit := int(pageLimit(pq)); limit > 0 && offset+limit < totalCount {
+		end = offset + limit
+	}
+	return newUsersPage(totalCount, pq, users[offset:end])
+}
+
+// Copy of a stored user with its derived status
+func withStatus(user models.User) models.User {
+	user.PhoneVerifiedAt = utils.ClonePtr(user.PhoneVerifiedAt)
+	user.DeletionRequestedAt = utils.ClonePtr(user.DeletionRequestedAt)
+	user.DeletionScheduledAt = utils.ClonePtr(user.DeletionScheduledAt)
+	user.Status = userStatus(&user)
+	return user
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+type rowCounter func(ctx context.Context) (int64, error)
+
+// Total rows of a listing according to the query count strategy, listings without an estimator and tables
+// not analyzed yet are counted exactly
+func countRows(ctx context.Context, pq *utils.PaginationQuery, exact rowCounter, estimate rowCounter) (int, error) {
+	switch pq.GetCount() {
+	case utils.CountNone:
+		return 0, nil
+	case utils.CountEstimated:
+		if estimate != nil {
+			count, err := estimate(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if count >= 0 {
+				return int(count), nil
+			}
+		}
+	}
+
+	count, err := exact(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Rows to fetch for a page, one extra tells whether another page follows when the total is not exact
+func pageLimit(pq *utils.PaginationQuery) int32 {
+	limit := pq.GetLimit()
+	if limit > 0 && pq.GetCount() != utils.CountExact {
+		limit++
+	}
+	return int32(limit)
+}
+
+// Build paginated users response from a page fetched with pageLimit
+func newUsersPage(totalCount int, pq *utils.PaginationQuery, users []*models.User) *models.UsersList {
+	strategy := pq.GetCount()
+	if strategy == utils.CountExact {
+		return newUsersList(totalCount, pq, users)
+	}
+
+	limit := pq.GetLimit()
+	hasMore := limit > 0 && len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
+
+	list := &models.UsersList{
+		Page:          pq.GetPage(),
+		Size:          pq.GetSize(),
+		HasMore:       hasMore,
+		CountStrategy: string(strategy),
+		Users:         users,
+	}
+	if strategy == utils.CountEstimated {
+		// A stale estimate never claims fewer rows than the page has already proven
+		seen := pq.GetOffset() + len(users)
+		if hasMore {
+			seen++
+		}
+		if totalCount < seen {
+			totalCount = seen
+		}
+		list.TotalCount = totalCount
+		list.TotalPages = utils.GetTotalPages(totalCount, pq.GetSize())
+	}
+	return list
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestNewUsersPage(t *testing.T) {
+	t.Parallel()
+
+	page := func(n int) []*models.User {
+		users := make([]*models.User, n)
+		for i := range users {
+			users[i] = &models.User{ID: i + 1}
+		}
+		return users
+	}
+
+	pq := &utils.PaginationQuery{Page: 2, Size: 10, Count: utils.CountNone}
+	require.EqualValues(t, 11, pageLimit(pq))
+	list := newUsersPage(0, pq, page(11))
+	require.True(t, list.HasMore)
+	require.Len(t, list.Users, 10)
+	require.Zero(t, list.TotalCount)
+	require.Equal(t, "none", list.CountStrategy)
+
+	// Estimate lags behind the rows already seen
+	pq.Count = utils.CountEstimated
+	list = newUsersPage(5, pq, page(11))
+	require.True(t, list.HasMore)
+	require.Equal(t, 21, list.TotalCount)
+	require.Equal(t, 3, list.TotalPages)
+
+	list = newUsersPage(1000, pq, page(4))
+	require.False(t, list.HasMore)
+	require.Equal(t, 1000, list.TotalCount)
+
+	pq.Count = ""
+	require.EqualValues(t, 10, pageLimit(pq))
+	require.Equal(t, "exact", newUsersPage(14, pq, page(4)).CountStrategy)
+}
@@ -3,55 +3,97 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository/sqlcdb"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/stmtcache"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/explain"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
 // Auth Repository
 type authRepo struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	q      *sqlcdb.Queries
+	cipher *pii.Cipher
 }
 
-// Auth Repository constructor
-func NewAuthRepository(db *sqlx.DB) auth.Repository {
-	return &authRepo{db: db}
+// Auth Repository constructor, a nil cipher keeps PII in plaintext, a nil sampler explains nothing and a nil
+// preparer prepares no statements
+func NewAuthRepository(db *sqlx.DB, cipher *pii.Cipher, sampler *explain.Sampler, preparer *stmtcache.Preparer) auth.Repository {
+	return &authRepo{db: db, q: sqlcdb.New(profiling.SQL(sampler.SQL(preparer.SQL(db, tenant.SQL(db))))), cipher: cipher}
 }
 
-// Create new user
-func (r *authRepo) Register(ctx context.Context, user *models.User) (*models.UserWithRole, error) {
+// Create new user with the given role in one transaction
+func (r *authRepo) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.Register")
 	defer span.Finish()
 
-	u := &models.User{}
-	if err := r.db.QueryRowxContext(ctx, createUserQuery, &user.Username, &user.Email, &user.Password).StructScan(u); err != nil {
-		return nil, errors.Wrap(err, "authRepo.Register.StructScan")
+	enc, err := encryptUserPII(r.cipher, user.Email, user.Phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.encryptUserPII")
+	}
+
+	tx, err := tenant.DB(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.BeginTx")
 	}
+	defer tx.Rollback() // nolint: errcheck
+	q := r.q.WithTx(tx)
 
-	role := &models.Role{}
-	if err := r.db.QueryRowxContext(ctx, `
-		SELECT id, name, description, parent_role_id FROM roles WHERE name = $1 LIMIT 1
-	`, "employee").StructScan(role); err != nil {
-		return nil, errors.Wrap(err, "authRepo.Register.FetchRole.QueryRowContext")
+	if _, err = tx.ExecContext(ctx, lockRegistrationQuery, user.Email); err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.Lock")
+	}
+	_, err = q.FindUserByEmail(ctx, sqlcdb.FindUserByEmailParams{EmailBidx: enc.EmailBidx, Email: user.Email})
+	if err == nil {
+		return nil, auth.ErrEmailAlreadyRegistered
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "authRepo.Register.FindUserByEmail")
 	}
 
-	if _, err := r.db.ExecContext(ctx, `
-		INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2)
-	`, u.ID, role.ID); err != nil {
-		return nil, errors.Wrap(err, "authRepo.Register.SetUserRole.QueryRowContext")
+	u, err := q.CreateUser(ctx, sqlcdb.CreateUserParams{
+		Username:  user.Username,
+		Email:     enc.Email,
+		EmailBidx: enc.EmailBidx,
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		Password:  user.Password,
+	})
+	if err != nil {
+		return nil, errors.Wrap(registerErr(err), "authRepo.Register.CreateUser")
 	}
 
-	userWithRole := models.UserWithRole{
-		User: *u,
-		Role: *role,
+	role, err := q.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.GetRoleByName")
 	}
 
-	return &userWithRole, nil
+	if err = q.AssignUserRole(ctx, sqlcdb.AssignUserRoleParams{UserID: u.ID, RoleID: role.ID}); err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.AssignUserRole")
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.Commit")
+	}
+
+	created := &models.UserWithRole{
+		User: toUserModel(u),
+		Role: toRoleModel(role),
+	}
+	if err = decryptUserPII(r.cipher, &created.User); err != nil {
+		return nil, errors.Wrap(err, "authRepo.Register.decryptUserPII")
+	}
+	return created, nil
 }
 
 // Update existing user
@@ -59,14 +101,29 @@ func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User,
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.Update")
 	defer span.Finish()
 
-	u := &models.User{}
-	if err := r.db.GetContext(ctx, u, updateUserQuery, &user.Username, &user.Email,
-		&user.ID,
-	); err != nil {
-		return nil, errors.Wrap(err, "authRepo.Update.GetContext")
+	enc, err := encryptUserPII(r.cipher, user.Email, user.Phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.Update.encryptUserPII")
 	}
 
-	return u, nil
+	u, err := r.q.UpdateUser(ctx, sqlcdb.UpdateUserParams{
+		Username:  user.Username,
+		Email:     enc.Email,
+		EmailBidx: enc.EmailBidx,
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		Timezone:  user.Timezone,
+		ID:        int32(user.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.Update.UpdateUser")
+	}
+
+	updatedUser := toUserModel(u)
+	if err = decryptUserPII(r.cipher, &updatedUser); err != nil {
+		return nil, errors.Wrap(err, "authRepo.Update.decryptUserPII")
+	}
+	return &updatedUser, nil
 }
 
 // Delete existing user
@@ -74,13 +131,9 @@ func (r *authRepo) Delete(ctx context.Context, userID int) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.Delete")
 	defer span.Finish()
 
-	result, err := r.db.ExecContext(ctx, deleteUserQuery, userID)
+	rowsAffected, err := r.q.DeleteUser(ctx, int32(userID))
 	if err != nil {
-		return errors.WithMessage(err, "authRepo Delete ExecContext")
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return errors.Wrap(err, "authRepo.Delete.RowsAffected")
+		return errors.WithMessage(err, "authRepo Delete DeleteUser")
 	}
 	if rowsAffected == 0 {
 		return errors.Wrap(sql.ErrNoRows, "authRepo.Delete.rowsAffected")
@@ -94,65 +147,99 @@ func (r *authRepo) GetByID(ctx context.Context, userID int) (*models.UserWithRol
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetByID")
 	defer span.Finish()
 
-	user := &models.UserWithRole{}
-
-	foundUser := &models.UserWithRole{}
-	if err := r.db.QueryRowxContext(ctx, getUserRoleQuery, userID).StructScan(foundUser); err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByUsername.QueryRowxContext")
+	row, err := r.q.GetUserWithRole(ctx, int32(userID))
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetByID.GetUserWithRole")
 	}
-	return foundUser, nil
 
-	return user, nil
+	found := &models.UserWithRole{
+		User: toUserModel(row.User),
+		Role: toRoleModel(row.Role),
+	}
+	if err = decryptUserPII(r.cipher, &found.User); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetByID.decryptUserPII")
+	}
+	return found, nil
 }
 
-// Find users by name
+// Find users whose name contains the search term, names starting with it first. When no name contains it
+// the users with similar names are found, the most similar first
 func (r *authRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.FindByName")
 	defer span.Finish()
 
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount, name); err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByName.GetContext.totalCount")
+	// A name filter has no planner estimate, estimated listings count it exactly
+	totalCount, err := countRows(ctx, query, func(ctx context.Context) (int64, error) {
+		return r.q.CountUsersByName(ctx, sqlcdb.CountUsersByNameParams{Name: name, OrganizationID: query.OrganizationID})
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByName.CountUsersByName")
 	}
-
-	if totalCount == 0 {
-		return &models.UsersList{
-			TotalCount: totalCount,
-			TotalPages: utils.GetTotalPages(totalCount, query.GetSize()),
-			Page:       query.GetPage(),
-			Size:       query.GetSize(),
-			HasMore:    utils.GetHasMore(query.GetPage(), totalCount, query.GetSize()),
-			Users:      make([]*models.User, 0),
-		}, nil
+	// Uncounted listings ask whether any name contains the term
+	contains := totalCount > 0
+	if query.GetCount() == utils.CountNone {
+		contains, err = r.q.HasUsersByName(ctx, sqlcdb.HasUsersByNameParams{Name: name, OrganizationID: query.OrganizationID})
+		if err != nil {
+			return nil, errors.Wrap(err, "authRepo.FindByName.HasUsersByName")
+		}
+	}
+	if !contains {
+		totalCount, err = countRows(ctx, query, func(ctx context.Context) (int64, error) {
+			return r.q.CountUsersBySimilarName(ctx, sqlcdb.CountUsersBySimilarNameParams{Name: name, OrganizationID: query.OrganizationID})
+		}, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "authRepo.FindByName.CountUsersBySimilarName")
+		}
 	}
 
-	rows, err := r.db.QueryxContext(ctx, findUsers, name, query.GetOffset(), query.GetLimit())
-	if err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByName.QueryxContext")
+	if totalCount == 0 && query.GetCount() != utils.CountNone {
+		return newUsersPage(totalCount, query, make([]*models.User, 0)), nil
 	}
-	defer rows.Close()
 
-	var users = make([]*models.User, 0, query.GetSize())
-	for rows.Next() {
-		var user models.User
-		if err = rows.StructScan(&user); err != nil {
-			return nil, errors.Wrap(err, "authRepo.FindByName.StructScan")
+	var rows []sqlcdb.FindUsersByNameRow
+	if contains {
+		rows, err = r.q.FindUsersByName(ctx, sqlcdb.FindUsersByNameParams{
+			Name:           name,
+			OrganizationID: query.OrganizationID,
+			OffsetRows:     int32(query.GetOffset()),
+			LimitRows:      pageLimit(query),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "authRepo.FindByName.FindUsersByName")
+		}
+	} else {
+		similar, err := r.q.FindUsersBySimilarName(ctx, sqlcdb.FindUsersBySimilarNameParams{
+			Name:           name,
+			OrganizationID: query.OrganizationID,
+			OffsetRows:     int32(query.GetOffset()),
+			LimitRows:      pageLimit(query),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "authRepo.FindByName.FindUsersBySimilarName")
+		}
+		for _, row := range similar {
+			rows = append(rows, sqlcdb.FindUsersByNameRow(row))
 		}
-		users = append(users, &user)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByName.rows.Err")
+	users := make([]*models.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &models.User{
+			ID:        int(row.ID),
+			Username:  row.Username,
+			Email:     row.Email,
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+			LoginDate: row.LoginAt.Time,
+			Timezone:  row.Timezone,
+			Phone:     row.Phone,
+		})
+	}
+	if err = decryptUsersPII(r.cipher, users); err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByName.decryptUsersPII")
 	}
 
-	return &models.UsersList{
-		TotalCount: totalCount,
-		TotalPages: utils.GetTotalPages(totalCount, query.GetSize()),
-		Page:       query.GetPage(),
-		Size:       query.GetSize(),
-		HasMore:    utils.GetHasMore(query.GetPage(), totalCount, query.GetSize()),
-		Users:      users,
-	}, nil
+	return newUsersPage(totalCount, query, users), nil
 }
 
 // Get users with pagination
@@ -160,42 +247,50 @@ func (r *authRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*mo
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetUsers")
 	defer span.Finish()
 
-	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotal); err != nil {
-		return nil, errors.Wrap(err, "authRepo.GetUsers.GetContext.totalCount")
+	var count, estimate rowCounter = r.q.CountUsers, r.q.EstimateUsers
+	if pq.OrganizationID != 0 {
+		// Planner estimate covers the whole table, organization listings are counted exactly
+		count, estimate = func(ctx context.Context) (int64, error) {
+			return r.q.CountOrganizationUsers(ctx, pq.OrganizationID)
+		}, nil
+	}
+	totalCount, err := countRows(ctx, pq, count, estimate)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUsers.CountUsers")
 	}
 
-	if totalCount == 0 {
-		return &models.UsersList{
-			TotalCount: totalCount,
-			TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
-			Page:       pq.GetPage(),
-			Size:       pq.GetSize(),
-			HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
-			Users:      make([]*models.User, 0),
-		}, nil
+	if totalCount == 0 && pq.GetCount() == utils.CountExact {
+		return newUsersList(totalCount, pq, make([]*models.User, 0)), nil
 	}
 
-	var users = make([]*models.User, 0, pq.GetSize())
-	if err := r.db.SelectContext(
-		ctx,
-		&users,
-		getUsers,
-		pq.GetOrderBy(),
-		pq.GetOffset(),
-		pq.GetLimit(),
-	); err != nil {
-		return nil, errors.Wrap(err, "authRepo.GetUsers.SelectContext")
+	rows, err := r.q.ListUsers(ctx, sqlcdb.ListUsersParams{
+		OrganizationID: pq.OrganizationID,
+		OrderBy:        pq.GetOrderBy(),
+		OffsetRows:     int32(pq.GetOffset()),
+		LimitRows:      pageLimit(pq),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUsers.ListUsers")
 	}
 
-	return &models.UsersList{
-		TotalCount: totalCount,
-		TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
-		Page:       pq.GetPage(),
-		Size:       pq.GetSize(),
-		HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
-		Users:      users,
-	}, nil
+	users := make([]*models.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &models.User{
+			ID:        int(row.ID),
+			Username:  row.Username,
+			Email:     row.Email,
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+			LoginDate: row.LoginAt.Time,
+			Timezone:  row.Timezone,
+			Phone:     row.Phone,
+		})
+	}
+	if err = decryptUsersPII(r.cipher, users); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUsers.decryptUsersPII")
+	}
+
+	return newUsersPage(totalCount, pq, users), nil
 }
 
 // Find user by email
@@ -203,20 +298,169 @@ func (r *authRepo) FindByEmail(ctx context.Context, userEmail string) (*models.U
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.FindByEmail")
 	defer span.Finish()
 
-	foundUser := &models.User{}
-	if err := r.db.QueryRowxContext(ctx, findUserByEmail, userEmail).StructScan(foundUser); err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByEmail.QueryRowxContext")
+	u, err := r.q.FindUserByEmail(ctx, sqlcdb.FindUserByEmailParams{
+		EmailBidx: r.cipher.BlindIndex(piiFieldEmail, userEmail),
+		Email:     userEmail,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByEmail.FindUserByEmail")
 	}
-	return foundUser, nil
+
+	foundUser := toUserModel(u)
+	if err = decryptUserPII(r.cipher, &foundUser); err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByEmail.decryptUserPII")
+	}
+	return &foundUser, nil
+}
+
+// Store a phone number verified by SMS code
+func (r *authRepo) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.SetPhoneVerified")
+	defer span.Finish()
+
+	enc, err := encryptUserPII(r.cipher, "", phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.SetPhoneVerified.encryptUserPII")
+	}
+
+	u, err := r.q.SetUserPhoneVerified(ctx, sqlcdb.SetUserPhoneVerifiedParams{
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		ID:        int32(userID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.SetPhoneVerified.SetUserPhoneVerified")
+	}
+
+	updatedUser := toUserModel(u)
+	if err = decryptUserPII(r.cipher, &updatedUser); err != nil {
+		return nil, errors.Wrap(err, "authRepo.SetPhoneVerified.decryptUserPII")
+	}
+	return &updatedUser, nil
 }
 
+// Toggle SMS second factor, enabling fails with sql.ErrNoRows unless the phone is verified
+func (r *authRepo) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.SetSMS2FA")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.SetUserSMS2FA(ctx, sqlcdb.SetUserSMS2FAParams{Enabled: enabled, ID: int32(userID)})
+	if err != nil {
+		return errors.Wrap(err, "authRepo.SetSMS2FA.SetUserSMS2FA")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.SetSMS2FA.rowsAffected")
+	}
+	return nil
+}
+
+// Replace password with its bcrypt hash
+func (r *authRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdatePassword")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.UpdateUserPassword(ctx, sqlcdb.UpdateUserPasswordParams{Password: passwordHash, ID: int32(userID)})
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdatePassword.UpdateUserPassword")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdatePassword.rowsAffected")
+	}
+	return nil
+}
+
+// Schedule user deletion after grace, an already pending deletion keeps its schedule
+func (r *authRepo) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.ScheduleDeletion")
+	defer span.Finish()
+
+	u, err := r.q.ScheduleUserDeletion(ctx, sqlcdb.ScheduleUserDeletionParams{
+		GraceSeconds: int32(grace / time.Second),
+		ID:           int32(userID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.ScheduleDeletion.ScheduleUserDeletion")
+	}
+
+	user := toUserModel(u)
+	if err = decryptUserPII(r.cipher, &user); err != nil {
+		return nil, errors.Wrap(err, "authRepo.ScheduleDeletion.decryptUserPII")
+	}
+	return &user, nil
+}
+
+// Cancel pending deletion, sql.ErrNoRows when none is pending
+func (r *authRepo) CancelDeletion(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.CancelDeletion")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.CancelUserDeletion(ctx, int32(userID))
+	if err != nil {
+		return errors.Wrap(err, "authRepo.CancelDeletion.CancelUserDeletion")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.CancelDeletion.rowsAffected")
+	}
+	return nil
+}
+
+// Ids of users whose deletion grace period is over, oldest schedule first
+func (r *authRepo) ListDueForDeletion(ctx context.Context, limit int) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.ListDueForDeletion")
+	defer span.Finish()
+
+	rows, err := r.q.ListUsersDueForDeletion(ctx, int32(limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.ListDueForDeletion.ListUsersDueForDeletion")
+	}
+
+	ids := make([]int, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// Delete user whose deletion is due, sql.ErrNoRows when it was cancelled meanwhile
+func (r *authRepo) PurgeScheduled(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.PurgeScheduled")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.PurgeScheduledUser(ctx, int32(userID))
+	if err != nil {
+		return errors.Wrap(err, "authRepo.PurgeScheduled.PurgeScheduledUser")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.PurgeScheduled.rowsAffected")
+	}
+	return nil
+}
+
+// Find user with role by username
 func (r *authRepo) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.FindByUsername")
 	defer span.Finish()
 
-	foundUser := &models.UserWithRole{}
-	if err := r.db.QueryRowxContext(ctx, findByUsername, username).StructScan(foundUser); err != nil {
-		return nil, errors.Wrap(err, "authRepo.FindByUsername.QueryRowxContext")
+	row, err := r.q.FindUserWithRoleByUsername(ctx, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByUsername.FindUserWithRoleByUsername")
+	}
+
+	found := &models.UserWithRole{
+		User: toUserModel(row.User),
+		Role: toRoleModel(row.Role),
+	}
+	if err = decryptUserPII(r.cipher, &found.User); err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByUsername.decryptUserPII")
+	}
+	return found, nil
+}
+
+// Position in the write ahead log, the replayed one when the database is a replica
+func (r *authRepo) WALPosition(ctx context.Context) (consistency.LSN, error) {
+	var position string
+	if err := r.db.QueryRowxContext(ctx, walPositionQuery).Scan(&position); err != nil {
+		return 0, errors.Wrap(err, "authRepo.WALPosition.QueryRowxContext")
 	}
-	return foundUser, nil
+	return consistency.ParseLSN(position)
 }
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/stmtcache"
+)
+
+// Queries of login and session hydration, prepared by the benchmarks below
+var hotQueries = []string{"FindUserByEmail", "FindUserWithRoleByUsername", "GetUserWithRole"}
+
+// Hot reads with and without prepared statements, compare with
+// go test -run '^$' -bench AuthRepository_Hot ./internal/auth/repository
+func BenchmarkAuthRepository_HotReads(b *testing.B) {
+	db, err := sqlx.Connect("pgx", testDSN(b))
+	require.NoError(b, err)
+	defer db.Close()
+
+	for _, bench := range []struct {
+		name     string
+		preparer *stmtcache.Preparer
+	}{
+		{name: "unprepared"},
+		{name: "prepared", preparer: stmtcache.New(hotQueries)},
+	} {
+		repo := NewAuthRepository(db, nil, nil, bench.preparer)
+		user := benchmarkUser(b, repo)
+
+		b.Run(bench.name+"/FindByEmail", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindByEmail(context.Background(), user.User.Email); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bench.name+"/FindByUsername", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindByUsername(context.Background(), user.User.Username); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bench.name+"/GetByID", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetByID(context.Background(), user.User.ID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkUser(b *testing.B, repo auth.Repository) *models.UserWithRole {
+	suffix := time.Now().UnixNano()
+	user, err := repo.Register(context.Background(), &models.User{
+		Username: fmt.Sprintf("bench_%d", suffix),
+		Email:    fmt.Sprintf("bench_%d@example.com", suffix),
+		Password: "hashed",
+	}, defaultRoleName)
+	require.NoError(b, err)
+	b.Cleanup(func() { repo.Delete(context.Background(), user.User.ID) }) // nolint: errcheck
+	return user
+}
@@ -0,0 +1,472 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository/pgxdb"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/explain"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Auth Repository on top of native pgx pool
+type authPgxRepo struct {
+	pool   *pgxpool.Pool
+	q      *pgxdb.Queries
+	cipher *pii.Cipher
+}
+
+// Auth pgx pool Repository constructor, a nil cipher keeps PII in plaintext and a nil sampler explains nothing
+func NewAuthPgxRepository(pool *pgxpool.Pool, cipher *pii.Cipher, sampler *explain.Sampler) auth.Repository {
+	return &authPgxRepo{pool: pool, q: pgxdb.New(profiling.Pgx(sampler.Pgx(pool))), cipher: cipher}
+}
+
+// Create new user with the given role in one transaction
+func (r *authPgxRepo) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.Register")
+	defer span.Finish()
+
+	enc, err := encryptUserPII(r.cipher, user.Email, user.Phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.encryptUserPII")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.Begin")
+	}
+	defer tx.Rollback(ctx) // nolint: errcheck
+	q := r.q.WithTx(tx)
+
+	if _, err = tx.Exec(ctx, lockRegistrationQuery, user.Email); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.Lock")
+	}
+	_, err = q.FindUserByEmail(ctx, pgxdb.FindUserByEmailParams{EmailBidx: enc.EmailBidx, Email: user.Email})
+	if err == nil {
+		return nil, auth.ErrEmailAlreadyRegistered
+	}
+	if err = pgxErr(err); !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.FindUserByEmail")
+	}
+
+	u, err := q.CreateUser(ctx, pgxdb.CreateUserParams{
+		Username:  user.Username,
+		Email:     enc.Email,
+		EmailBidx: enc.EmailBidx,
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		Password:  user.Password,
+	})
+	if err != nil {
+		return nil, errors.Wrap(registerErr(pgxErr(err)), "authPgxRepo.Register.CreateUser")
+	}
+
+	role, err := q.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.Register.GetRoleByName")
+	}
+
+	if err = q.AssignUserRole(ctx, pgxdb.AssignUserRoleParams{UserID: u.ID, RoleID: role.ID}); err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.Register.AssignUserRole")
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.Commit")
+	}
+
+	created := &models.UserWithRole{
+		User: pgxToUserModel(u),
+		Role: pgxToRoleModel(role),
+	}
+	if err = decryptUserPII(r.cipher, &created.User); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Register.decryptUserPII")
+	}
+	return created, nil
+}
+
+// Update existing user
+func (r *authPgxRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.Update")
+	defer span.Finish()
+
+	enc, err := encryptUserPII(r.cipher, user.Email, user.Phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Update.encryptUserPII")
+	}
+
+	u, err := r.q.UpdateUser(ctx, pgxdb.UpdateUserParams{
+		Username:  user.Username,
+		Email:     enc.Email,
+		EmailBidx: enc.EmailBidx,
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		Timezone:  user.Timezone,
+		ID:        int32(user.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.Update.UpdateUser")
+	}
+
+	updatedUser := pgxToUserModel(u)
+	if err = decryptUserPII(r.cipher, &updatedUser); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.Update.decryptUserPII")
+	}
+	return &updatedUser, nil
+}
+
+// Delete existing user
+func (r *authPgxRepo) Delete(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.Delete")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.DeleteUser(ctx, int32(userID))
+	if err != nil {
+		return errors.WithMessage(err, "authPgxRepo Delete DeleteUser")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authPgxRepo.Delete.rowsAffected")
+	}
+
+	return nil
+}
+
+// Get user by id
+func (r *authPgxRepo) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.GetByID")
+	defer span.Finish()
+
+	row, err := r.q.GetUserWithRole(ctx, int32(userID))
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.GetByID.GetUserWithRole")
+	}
+
+	found := &models.UserWithRole{
+		User: pgxToUserModel(row.User),
+		Role: pgxToRoleModel(row.Role),
+	}
+	if err = decryptUserPII(r.cipher, &found.User); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.GetByID.decryptUserPII")
+	}
+	return found, nil
+}
+
+// Find users whose name contains the search term, names starting with it first. When no name contains it
+// the users with similar names are found, the most similar first
+func (r *authPgxRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.FindByName")
+	defer span.Finish()
+
+	// A name filter has no planner estimate, estimated listings count it exactly
+	totalCount, err := countRows(ctx, query, func(ctx context.Context) (int64, error) {
+		return r.q.CountUsersByName(ctx, pgxdb.CountUsersByNameParams{Name: name, OrganizationID: query.OrganizationID})
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByName.CountUsersByName")
+	}
+	// Uncounted listings ask whether any name contains the term
+	contains := totalCount > 0
+	if query.GetCount() == utils.CountNone {
+		contains, err = r.q.HasUsersByName(ctx, pgxdb.HasUsersByNameParams{Name: name, OrganizationID: query.OrganizationID})
+		if err != nil {
+			return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByName.HasUsersByName")
+		}
+	}
+	if !contains {
+		totalCount, err = countRows(ctx, query, func(ctx context.Context) (int64, error) {
+			return r.q.CountUsersBySimilarName(ctx, pgxdb.CountUsersBySimilarNameParams{Name: name, OrganizationID: query.OrganizationID})
+		}, nil)
+		if err != nil {
+			return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByName.CountUsersBySimilarName")
+		}
+	}
+
+	if totalCount == 0 && query.GetCount() != utils.CountNone {
+		return newUsersPage(totalCount, query, make([]*models.User, 0)), nil
+	}
+
+	var rows []pgxdb.FindUsersByNameRow
+	if contains {
+		rows, err = r.q.FindUsersByName(ctx, pgxdb.FindUsersByNameParams{
+			Name:           name,
+			OrganizationID: query.OrganizationID,
+			OffsetRows:     int32(query.GetOffset()),
+			LimitRows:      pageLimit(query),
+		})
+		if err != nil {
+			return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByName.FindUsersByName")
+		}
+	} else {
+		similar, err := r.q.FindUsersBySimilarName(ctx, pgxdb.FindUsersBySimilarNameParams{
+			Name:           name,
+			OrganizationID: query.OrganizationID,
+			OffsetRows:     int32(query.GetOffset()),
+			LimitRows:      pageLimit(query),
+		})
+		if err != nil {
+			return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByName.FindUsersBySimilarName")
+		}
+		for _, row := range similar {
+			rows = append(rows, pgxdb.FindUsersByNameRow(row))
+		}
+	}
+
+	users := make([]*models.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &models.User{
+			ID:        int(row.ID),
+			Username:  row.Username,
+			Email:     row.Email,
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+			LoginDate: row.LoginAt.Time,
+			Timezone:  row.Timezone,
+			Phone:     row.Phone,
+		})
+	}
+	if err = decryptUsersPII(r.cipher, users); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.FindByName.decryptUsersPII")
+	}
+
+	return newUsersPage(totalCount, query, users), nil
+}
+
+// Get users with pagination
+func (r *authPgxRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.GetUsers")
+	defer span.Finish()
+
+	var count, estimate rowCounter = r.q.CountUsers, r.q.EstimateUsers
+	if pq.OrganizationID != 0 {
+		// Planner estimate covers the whole table, organization listings are counted exactly
+		count, estimate = func(ctx context.Context) (int64, error) {
+			return r.q.CountOrganizationUsers(ctx, pq.OrganizationID)
+		}, nil
+	}
+	totalCount, err := countRows(ctx, pq, count, estimate)
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.GetUsers.CountUsers")
+	}
+
+	if totalCount == 0 && pq.GetCount() == utils.CountExact {
+		return newUsersList(totalCount, pq, make([]*models.User, 0)), nil
+	}
+
+	rows, err := r.q.ListUsers(ctx, pgxdb.ListUsersParams{
+		OrganizationID: pq.OrganizationID,
+		OrderBy:        pq.GetOrderBy(),
+		OffsetRows:     int32(pq.GetOffset()),
+		LimitRows:      pageLimit(pq),
+	})
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.GetUsers.ListUsers")
+	}
+
+	users := make([]*models.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &models.User{
+			ID:        int(row.ID),
+			Username:  row.Username,
+			Email:     row.Email,
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+			LoginDate: row.LoginAt.Time,
+			Timezone:  row.Timezone,
+			Phone:     row.Phone,
+		})
+	}
+	if err = decryptUsersPII(r.cipher, users); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.GetUsers.decryptUsersPII")
+	}
+
+	return newUsersPage(totalCount, pq, users), nil
+}
+
+// Find user by email
+func (r *authPgxRepo) FindByEmail(ctx context.Context, userEmail string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.FindByEmail")
+	defer span.Finish()
+
+	u, err := r.q.FindUserByEmail(ctx, pgxdb.FindUserByEmailParams{
+		EmailBidx: r.cipher.BlindIndex(piiFieldEmail, userEmail),
+		Email:     userEmail,
+	})
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByEmail.FindUserByEmail")
+	}
+
+	foundUser := pgxToUserModel(u)
+	if err = decryptUserPII(r.cipher, &foundUser); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.FindByEmail.decryptUserPII")
+	}
+	return &foundUser, nil
+}
+
+// Store a phone number verified by SMS code
+func (r *authPgxRepo) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.SetPhoneVerified")
+	defer span.Finish()
+
+	enc, err := encryptUserPII(r.cipher, "", phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.SetPhoneVerified.encryptUserPII")
+	}
+
+	u, err := r.q.SetUserPhoneVerified(ctx, pgxdb.SetUserPhoneVerifiedParams{
+		Phone:     enc.Phone,
+		PhoneBidx: enc.PhoneBidx,
+		ID:        int32(userID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.SetPhoneVerified.SetUserPhoneVerified")
+	}
+
+	updatedUser := pgxToUserModel(u)
+	if err = decryptUserPII(r.cipher, &updatedUser); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.SetPhoneVerified.decryptUserPII")
+	}
+	return &updatedUser, nil
+}
+
+// Toggle SMS second factor, enabling fails with sql.ErrNoRows unless the phone is verified
+func (r *authPgxRepo) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.SetSMS2FA")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.SetUserSMS2FA(ctx, pgxdb.SetUserSMS2FAParams{Enabled: enabled, ID: int32(userID)})
+	if err != nil {
+		return errors.Wrap(err, "authPgxRepo.SetSMS2FA.SetUserSMS2FA")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authPgxRepo.SetSMS2FA.rowsAffected")
+	}
+	return nil
+}
+
+// Replace password with its bcrypt hash
+func (r *authPgxRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.UpdatePassword")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.UpdateUserPassword(ctx, pgxdb.UpdateUserPasswordParams{Password: passwordHash, ID: int32(userID)})
+	if err != nil {
+		return errors.Wrap(err, "authPgxRepo.UpdatePassword.UpdateUserPassword")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authPgxRepo.UpdatePassword.rowsAffected")
+	}
+	return nil
+}
+
+// Schedule user deletion after grace, an already pending deletion keeps its schedule
+func (r *authPgxRepo) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.ScheduleDeletion")
+	defer span.Finish()
+
+	u, err := r.q.ScheduleUserDeletion(ctx, pgxdb.ScheduleUserDeletionParams{
+		GraceSeconds: int32(grace / time.Second),
+		ID:           int32(userID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.ScheduleDeletion.ScheduleUserDeletion")
+	}
+
+	user := pgxToUserModel(u)
+	if err = decryptUserPII(r.cipher, &user); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.ScheduleDeletion.decryptUserPII")
+	}
+	return &user, nil
+}
+
+// Cancel pending deletion, sql.ErrNoRows when none is pending
+func (r *authPgxRepo) CancelDeletion(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.CancelDeletion")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.CancelUserDeletion(ctx, int32(userID))
+	if err != nil {
+		return errors.Wrap(err, "authPgxRepo.CancelDeletion.CancelUserDeletion")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authPgxRepo.CancelDeletion.rowsAffected")
+	}
+	return nil
+}
+
+// Ids of users whose deletion grace period is over, oldest schedule first
+func (r *authPgxRepo) ListDueForDeletion(ctx context.Context, limit int) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.ListDueForDeletion")
+	defer span.Finish()
+
+	rows, err := r.q.ListUsersDueForDeletion(ctx, int32(limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.ListDueForDeletion.ListUsersDueForDeletion")
+	}
+
+	ids := make([]int, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// Delete user whose deletion is due, sql.ErrNoRows when it was cancelled meanwhile
+func (r *authPgxRepo) PurgeScheduled(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.PurgeScheduled")
+	defer span.Finish()
+
+	rowsAffected, err := r.q.PurgeScheduledUser(ctx, int32(userID))
+	if err != nil {
+		return errors.Wrap(err, "authPgxRepo.PurgeScheduled.PurgeScheduledUser")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authPgxRepo.PurgeScheduled.rowsAffected")
+	}
+	return nil
+}
+
+// Find user with role by username
+func (r *authPgxRepo) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authPgxRepo.FindByUsername")
+	defer span.Finish()
+
+	row, err := r.q.FindUserWithRoleByUsername(ctx, username)
+	if err != nil {
+		return nil, errors.Wrap(pgxErr(err), "authPgxRepo.FindByUsername.FindUserWithRoleByUsername")
+	}
+
+	found := &models.UserWithRole{
+		User: pgxToUserModel(row.User),
+		Role: pgxToRoleModel(row.Role),
+	}
+	if err = decryptUserPII(r.cipher, &found.User); err != nil {
+		return nil, errors.Wrap(err, "authPgxRepo.FindByUsername.decryptUserPII")
+	}
+	return found, nil
+}
+
+// Position in the write ahead log, the replayed one when the database is a replica
+func (r *authPgxRepo) WALPosition(ctx context.Context) (consistency.LSN, error) {
+	var position string
+	if err := r.pool.QueryRow(ctx, walPositionQuery).Scan(&position); err != nil {
+		return 0, errors.Wrap(err, "authPgxRepo.WALPosition.QueryRow")
+	}
+	return consistency.ParseLSN(position)
+}
+
+// Translate pgx sentinel errors so both backends surface identical errors to usecases
+func pgxErr(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
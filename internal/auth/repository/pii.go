@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+)
+
+// Associated data of encrypted user columns
+const (
+	piiFieldEmail = "users.email"
+	piiFieldPhone = "users.phone"
+)
+
+// Stored form of user PII, blind indexes stay empty when encryption is disabled
+type userPII struct {
+	Email     string
+	EmailBidx string
+	Phone     string
+	PhoneBidx string
+}
+
+// Encrypt user PII for writing, empty values stay empty so partial updates keep stored columns
+func encryptUserPII(c *pii.Cipher, email, phone string) (userPII, error) {
+	encEmail, err := c.Encrypt(piiFieldEmail, email)
+	if err != nil {
+		return userPII{}, errors.Wrap(err, "encryptUserPII.email")
+	}
+	encPhone, err := c.Encrypt(piiFieldPhone, phone)
+	if err != nil {
+		return userPII{}, errors.Wrap(err, "encryptUserPII.phone")
+	}
+	return userPII{
+		Email:     encEmail,
+		EmailBidx: c.BlindIndex(piiFieldEmail, email),
+		Phone:     encPhone,
+		PhoneBidx: c.BlindIndex(piiFieldPhone, phone),
+	}, nil
+}
+
+// Decrypt user PII in place after reading
+func decryptUserPII(c *pii.Cipher, user *models.User) error {
+	email, err := c.Decrypt(piiFieldEmail, user.Email)
+	if err != nil {
+		return errors.Wrap(err, "decryptUserPII.email")
+	}
+	phone, err := c.Decrypt(piiFieldPhone, user.Phone)
+	if err != nil {
+		return errors.Wrap(err, "decryptUserPII.phone")
+	}
+	user.Email, user.Phone = email, phone
+	return nil
+}
+
+// Decrypt every user of a list
+func decryptUsersPII(c *pii.Cipher, users []*models.User) error {
+	for _, user := range users {
+		if err := decryptUserPII(c, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
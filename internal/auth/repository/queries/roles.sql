@@ -0,0 +1,8 @@
+-- name: GetRoleByName :one
+SELECT id, name, description, parent_role_id
+FROM roles
+WHERE name = $1
+LIMIT 1;
+
+-- name: AssignUserRole :exec
+INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2);
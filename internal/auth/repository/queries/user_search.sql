@@ -0,0 +1,27 @@
+-- name: MaxUserID :one
+SELECT COALESCE(MAX(id), 0)::int AS max_id FROM users;
+
+-- name: RebuildUserSearch :execrows
+-- Write the index rows of users with ids in (after_id, until_id], rows already up to date are left alone
+INSERT INTO user_search (user_id, search_name)
+SELECT id, lower(username) FROM users
+WHERE id > sqlc.arg(after_id)::int AND id <= sqlc.arg(until_id)::int
+ON CONFLICT (user_id) DO UPDATE SET search_name = EXCLUDED.search_name
+WHERE user_search.search_name IS DISTINCT FROM EXCLUDED.search_name;
+
+-- name: CountStaleUserSearch :one
+-- Users missing from the index or indexed under an old name
+SELECT COUNT(users.id) FROM users
+LEFT JOIN user_search ON user_search.user_id = users.id
+WHERE user_search.search_name IS DISTINCT FROM lower(users.username);
+
+-- name: GetUserSearchHealth :one
+SELECT
+    EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') AS extension_installed,
+    EXISTS (
+        SELECT 1 FROM pg_trigger
+        WHERE tgname = 'trg_user_search_sync' AND tgrelid = 'users'::regclass AND tgenabled <> 'D'
+    ) AS trigger_enabled,
+    COALESCE((
+        SELECT indisvalid AND indisready FROM pg_index WHERE indexrelid = to_regclass('idx_user_search_name_trgm')
+    ), false)::boolean AS index_valid;
@@ -0,0 +1,181 @@
+-- name: CreateUser :one
+INSERT INTO users (username, email, email_bidx, phone, phone_bidx, password, created_at, updated_at, login_at)
+VALUES (sqlc.arg(username), sqlc.arg(email), NULLIF(sqlc.arg(email_bidx)::text, ''),
+        NULLIF(sqlc.arg(phone)::text, ''), NULLIF(sqlc.arg(phone_bidx)::text, ''), sqlc.arg(password), now(), now(), now())
+RETURNING id, username, email, password, created_at, updated_at, login_at, timezone, email_bidx, phone, phone_bidx,
+          phone_verified_at, sms_2fa_enabled, deletion_requested_at, deletion_scheduled_at;
+
+-- name: UpdateUser :one
+UPDATE users
+SET username   = COALESCE(NULLIF(sqlc.arg(username)::text, ''), username),
+    email      = COALESCE(NULLIF(sqlc.arg(email)::text, ''), email),
+    email_bidx = COALESCE(NULLIF(sqlc.arg(email_bidx)::text, ''), email_bidx),
+    phone      = COALESCE(NULLIF(sqlc.arg(phone)::text, ''), phone),
+    phone_bidx = COALESCE(NULLIF(sqlc.arg(phone_bidx)::text, ''), phone_bidx),
+    -- A different phone number has to be verified again and drops the SMS second factor
+    phone_verified_at = CASE
+        WHEN NULLIF(sqlc.arg(phone)::text, '') IS NULL
+          OR COALESCE(NULLIF(sqlc.arg(phone_bidx)::text, ''), sqlc.arg(phone)::text) = COALESCE(phone_bidx, phone)
+        THEN phone_verified_at END,
+    sms_2fa_enabled = CASE
+        WHEN NULLIF(sqlc.arg(phone)::text, '') IS NULL
+          OR COALESCE(NULLIF(sqlc.arg(phone_bidx)::text, ''), sqlc.arg(phone)::text) = COALESCE(phone_bidx, phone)
+        THEN sms_2fa_enabled ELSE false END,
+    timezone   = COALESCE(NULLIF(sqlc.arg(timezone)::text, ''), timezone),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING id, username, email, password, created_at, updated_at, login_at, timezone, email_bidx, phone, phone_bidx,
+          phone_verified_at, sms_2fa_enabled, deletion_requested_at, deletion_scheduled_at;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1;
+
+-- name: GetUserWithRole :one
+SELECT sqlc.embed(users), sqlc.embed(roles)
+FROM users
+JOIN user_roles ON user_roles.user_id = users.id
+JOIN roles ON roles.id = user_roles.role_id
+WHERE users.id = $1;
+
+-- name: CountUsersByName :one
+-- A zero organization_id counts users of every organization
+SELECT COUNT(user_id) FROM user_search
+WHERE search_name LIKE '%' || lower(sqlc.arg(name)::text) || '%'
+  AND (sqlc.arg(organization_id)::bigint = 0 OR user_id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint));
+
+-- name: HasUsersByName :one
+SELECT EXISTS (
+    SELECT 1 FROM user_search
+    WHERE search_name LIKE '%' || lower(sqlc.arg(name)::text) || '%'
+      AND (sqlc.arg(organization_id)::bigint = 0 OR user_id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint))
+) AS found;
+
+-- name: FindUsersByName :many
+-- Names starting with the search term come first
+SELECT users.id, users.username, users.email, users.created_at, users.updated_at, users.login_at, users.timezone,
+       COALESCE(users.phone, '')::text AS phone
+FROM user_search
+JOIN users ON users.id = user_search.user_id
+WHERE user_search.search_name LIKE '%' || lower(sqlc.arg(name)::text) || '%'
+  AND (sqlc.arg(organization_id)::bigint = 0 OR users.id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint))
+ORDER BY user_search.search_name LIKE lower(sqlc.arg(name)::text) || '%' DESC, users.username
+OFFSET sqlc.arg(offset_rows) LIMIT sqlc.arg(limit_rows);
+
+-- name: CountUsersBySimilarName :one
+-- Names within the pg_trgm similarity threshold of the search term
+SELECT COUNT(user_id) FROM user_search
+WHERE search_name % lower(sqlc.arg(name)::text)
+  AND (sqlc.arg(organization_id)::bigint = 0 OR user_id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint));
+
+-- name: FindUsersBySimilarName :many
+-- Most similar names first
+SELECT users.id, users.username, users.email, users.created_at, users.updated_at, users.login_at, users.timezone,
+       COALESCE(users.phone, '')::text AS phone
+FROM user_search
+JOIN users ON users.id = user_search.user_id
+WHERE user_search.search_name % lower(sqlc.arg(name)::text)
+  AND (sqlc.arg(organization_id)::bigint = 0 OR users.id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint))
+ORDER BY similarity(user_search.search_name, lower(sqlc.arg(name)::text)) DESC, users.username
+OFFSET sqlc.arg(offset_rows) LIMIT sqlc.arg(limit_rows);
+
+-- name: CountUsers :one
+SELECT COUNT(id) FROM users;
+
+-- name: CountOrganizationUsers :one
+SELECT COUNT(user_id) FROM organization_members WHERE organization_id = $1;
+
+-- name: EstimateUsers :one
+-- Planner statistics, -1 until the table was first analyzed
+SELECT reltuples::bigint AS estimate FROM pg_catalog.pg_class WHERE oid = 'users'::regclass;
+
+-- name: ListUsers :many
+-- A zero organization_id lists users of every organization
+SELECT id, username, email, created_at, updated_at, login_at, timezone, COALESCE(phone, '')::text AS phone
+FROM users
+WHERE (sqlc.arg(organization_id)::bigint = 0 OR id IN (SELECT user_id FROM organization_members WHERE organization_id = sqlc.arg(organization_id)::bigint))
+ORDER BY COALESCE(NULLIF(sqlc.arg(order_by)::text, ''), username)
+OFFSET sqlc.arg(offset_rows) LIMIT sqlc.arg(limit_rows);
+
+-- name: FindUserByEmail :one
+-- Rows written before encryption have no blind index yet and match on the plaintext email
+SELECT id, username, email, password, created_at, updated_at, login_at, timezone, email_bidx, phone, phone_bidx,
+       phone_verified_at, sms_2fa_enabled, deletion_requested_at, deletion_scheduled_at
+FROM users
+WHERE email_bidx = sqlc.arg(email_bidx)::text
+   OR (email_bidx IS NULL AND email = sqlc.arg(email));
+
+-- name: ListUsersForPIIRotation :many
+SELECT id, email, COALESCE(phone, '')::text AS phone
+FROM users
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_rows);
+
+-- name: UpdateUserPII :exec
+UPDATE users
+SET email      = sqlc.arg(email),
+    email_bidx = NULLIF(sqlc.arg(email_bidx)::text, ''),
+    phone      = NULLIF(sqlc.arg(phone)::text, ''),
+    phone_bidx = NULLIF(sqlc.arg(phone_bidx)::text, '')
+WHERE id = sqlc.arg(id);
+
+-- name: SetUserPhoneVerified :one
+UPDATE users
+SET phone             = sqlc.arg(phone)::text,
+    phone_bidx        = NULLIF(sqlc.arg(phone_bidx)::text, ''),
+    phone_verified_at = now(),
+    updated_at        = now()
+WHERE id = sqlc.arg(id)
+RETURNING id, username, email, password, created_at, updated_at, login_at, timezone, email_bidx, phone, phone_bidx,
+          phone_verified_at, sms_2fa_enabled, deletion_requested_at, deletion_scheduled_at;
+
+-- name: SetUserSMS2FA :execrows
+-- Enabling requires a verified phone, disabling always succeeds
+UPDATE users
+SET sms_2fa_enabled = sqlc.arg(enabled),
+    updated_at      = now()
+WHERE id = sqlc.arg(id)
+  AND (NOT sqlc.arg(enabled)::boolean OR phone_verified_at IS NOT NULL);
+
+-- name: UpdateUserPassword :execrows
+-- Password is the bcrypt hash
+UPDATE users
+SET password   = sqlc.arg(password),
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: ScheduleUserDeletion :one
+-- Repeated requests keep the first schedule
+UPDATE users
+SET deletion_requested_at = COALESCE(deletion_requested_at, now()),
+    deletion_scheduled_at = COALESCE(deletion_scheduled_at, now() + make_interval(secs => sqlc.arg(grace_seconds)::int)),
+    updated_at            = now()
+WHERE id = sqlc.arg(id)
+RETURNING id, username, email, password, created_at, updated_at, login_at, timezone, email_bidx, phone, phone_bidx,
+          phone_verified_at, sms_2fa_enabled, deletion_requested_at, deletion_scheduled_at;
+
+-- name: CancelUserDeletion :execrows
+UPDATE users
+SET deletion_requested_at = NULL,
+    deletion_scheduled_at = NULL,
+    updated_at            = now()
+WHERE id = $1
+  AND deletion_scheduled_at IS NOT NULL;
+
+-- name: ListUsersDueForDeletion :many
+SELECT id
+FROM users
+WHERE deletion_scheduled_at <= now()
+ORDER BY deletion_scheduled_at
+LIMIT $1;
+
+-- name: PurgeScheduledUser :execrows
+-- Schedule is checked again so a cancellation racing the purge wins
+DELETE FROM users WHERE id = $1 AND deletion_scheduled_at <= now();
+
+-- name: FindUserWithRoleByUsername :one
+SELECT sqlc.embed(users), sqlc.embed(roles)
+FROM users
+JOIN user_roles ON user_roles.user_id = users.id
+JOIN roles ON roles.id = user_roles.role_id
+WHERE users.username = $1;
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -13,6 +14,8 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 )
 
+const scanCount = 100
+
 // Auth redis repository
 type authRedisRepo struct {
 	redisClient *redis.Client
@@ -64,3 +67,150 @@ func (a *authRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
 	}
 	return nil
 }
+
+// Get cached users list page, nil without error on cache miss
+func (a *authRedisRepo) GetUsersListCtx(ctx context.Context, key string) (*models.CachedUsersList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetUsersListCtx")
+	defer span.Finish()
+
+	listBytes, err := a.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "authRedisRepo.GetUsersListCtx.redisClient.Get")
+	}
+	list := &models.CachedUsersList{}
+	if err = json.Unmarshal(listBytes, list); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetUsersListCtx.json.Unmarshal")
+	}
+	return list, nil
+}
+
+// Cache users list page with duration in seconds
+func (a *authRedisRepo) SetUsersListCtx(ctx context.Context, key string, seconds int, list *models.CachedUsersList) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetUsersListCtx")
+	defer span.Finish()
+
+	listBytes, err := json.Marshal(list)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetUsersListCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, listBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetUsersListCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Get cached access of a user, nil without error on cache miss
+func (a *authRedisRepo) GetAccessCtx(ctx context.Context, key string) (*models.Access, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetAccessCtx")
+	defer span.Finish()
+
+	accessBytes, err := a.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "authRedisRepo.GetAccessCtx.redisClient.Get")
+	}
+	access := &models.Access{}
+	if err = json.Unmarshal(accessBytes, access); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetAccessCtx.json.Unmarshal")
+	}
+	return access, nil
+}
+
+// Cache access of a user with duration in seconds
+func (a *authRedisRepo) SetAccessCtx(ctx context.Context, key string, seconds int, access *models.Access) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetAccessCtx")
+	defer span.Finish()
+
+	accessBytes, err := json.Marshal(access)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetAccessCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, accessBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetAccessCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Record a change of a field unless the limit is reached, atomically so concurrent updates can't all pass.
+// Returns 1 and the change, or 0 and the score of the change that has to leave the period first
+var reserveChangeScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", "(" .. ARGV[3])
+local count = redis.call("ZCARD", KEYS[1])
+local max = tonumber(ARGV[5])
+if count >= max then
+	local oldest = redis.call("ZRANGE", KEYS[1], count - max, count - max, "WITHSCORES")
+	return {0, oldest[2]}
+end
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {1, ARGV[1]}
+`)
+
+// Record a change of a field as member unless limit changes fall within period before at, then the time the
+// oldest of them leaves the period is returned. Changes are kept in a sorted set scored by their unix milliseconds
+func (a *authRedisRepo) ReserveFieldChangeCtx(ctx context.Context, key string, member string, at time.Time, period time.Duration, limit int) (bool, time.Time, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.ReserveFieldChangeCtx")
+	defer span.Finish()
+
+	result, err := reserveChangeScript.Run(ctx, a.redisClient, []string{key},
+		at.UnixMilli(), member, at.Add(-period).UnixMilli(), period.Milliseconds(), limit,
+	).Slice()
+	if err != nil {
+		return false, time.Time{}, errors.Wrap(err, "authRedisRepo.ReserveFieldChangeCtx.reserveChangeScript.Run")
+	}
+	if len(result) != 2 {
+		return false, time.Time{}, errors.Errorf("authRedisRepo.ReserveFieldChangeCtx: unexpected reply %v", result)
+	}
+	if reserved, _ := result[0].(int64); reserved == 1 {
+		return true, time.Time{}, nil
+	}
+	raw, _ := result[1].(string)
+	score, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return false, time.Time{}, errors.Wrap(err, "authRedisRepo.ReserveFieldChangeCtx.ParseFloat")
+	}
+	return false, time.UnixMilli(int64(score)).Add(period), nil
+}
+
+// Forget a change recorded as member
+func (a *authRedisRepo) ReleaseFieldChangeCtx(ctx context.Context, key string, member string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.ReleaseFieldChangeCtx")
+	defer span.Finish()
+
+	if err := a.redisClient.ZRem(ctx, key, member).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.ReleaseFieldChangeCtx.redisClient.ZRem")
+	}
+	return nil
+}
+
+// Delete all keys matching pattern
+func (a *authRedisRepo) DeleteByPatternCtx(ctx context.Context, pattern string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DeleteByPatternCtx")
+	defer span.Finish()
+
+	iter := a.redisClient.Scan(ctx, 0, pattern, scanCount).Iterator()
+	keys := make([]string, 0, scanCount)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanCount {
+			if err := a.redisClient.Del(ctx, keys...).Err(); err != nil {
+				return errors.Wrap(err, "authRedisRepo.DeleteByPatternCtx.redisClient.Del")
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DeleteByPatternCtx.redisClient.Scan")
+	}
+	if len(keys) > 0 {
+		if err := a.redisClient.Del(ctx, keys...).Err(); err != nil {
+			return errors.Wrap(err, "authRedisRepo.DeleteByPatternCtx.redisClient.Del")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRedisRepo_ReserveFieldChange(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	repo := NewAuthRedisRepo(client)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	period := 24 * time.Hour
+
+	// Concurrent updates can't all pass the check before any of them is recorded
+	var wg sync.WaitGroup
+	var reserved int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, _, err := repo.ReserveFieldChangeCtx(ctx, "changes:7:email", strconv.Itoa(i), now.Add(time.Duration(i)*time.Millisecond), period, 3)
+			require.NoError(t, err)
+			if ok {
+				atomic.AddInt32(&reserved, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int32(3), reserved)
+
+	// Refused until the oldest change leaves the period
+	members, err := client.ZRangeWithScores(ctx, "changes:7:email", 0, 0).Result()
+	require.NoError(t, err)
+	ok, nextAllowedAt, err := repo.ReserveFieldChangeCtx(ctx, "changes:7:email", "late", now.Add(time.Hour), period, 3)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, time.UnixMilli(int64(members[0].Score)).Add(period), nextAllowedAt)
+
+	// A released change frees its slot
+	require.NoError(t, repo.ReleaseFieldChangeCtx(ctx, "changes:7:email", members[0].Member.(string)))
+	ok, _, err = repo.ReserveFieldChangeCtx(ctx, "changes:7:email", "late", now.Add(time.Hour), period, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, _, err = repo.ReserveFieldChangeCtx(ctx, "changes:7:email", "next-day", now.Add(period+time.Hour), period, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	members, err = client.ZRangeWithScores(ctx, "changes:7:email", 0, -1).Result()
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+}
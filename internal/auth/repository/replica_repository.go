@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Hedged read results
+const (
+	HedgeUnhedged = "unhedged"
+	HedgeFirst    = "first"
+	HedgeWon      = "hedge"
+)
+
+// Position in the write ahead log, the current one on the primary and the replayed one on a replica
+const walPositionQuery = `SELECT (CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END)::text`
+
+// Repository able to tell its position in the write ahead log
+type walPositioner interface {
+	WALPosition(ctx context.Context) (consistency.LSN, error)
+}
+
+// Auth Repository serving the configured reads from replicas and everything else from the primary.
+// Hedged reads run a second attempt once the first one is slower than the delay, the loser is canceled.
+// With read-your-writes writes record the primary position in ctx, reads required to see a position are
+// served by a replica which has replayed it or by the primary, unhedged.
+type authReplicaRepo struct {
+	auth.Repository
+	replicas       []auth.Repository
+	methods        map[string]bool
+	hedgeDelay     time.Duration
+	readYourWrites bool
+	next           uint32
+	metrics        metric.Metrics
+}
+
+// Auth replica Repository constructor, metrics may be nil
+func NewAuthReplicaRepository(primary auth.Repository, replicas []auth.Repository, cfg *config.Config, metrics metric.Metrics) auth.Repository {
+	return &authReplicaRepo{
+		Repository: primary,
+		replicas:   replicas,
+		methods:    cfg.Replicas.Methods,
+		hedgeDelay: time.Duration(cfg.Replicas.HedgeDelayMs) * time.Millisecond,
+		metrics:    metrics,
+
+		readYourWrites: cfg.Replicas.ReadYourWrites,
+	}
+}
+
+// Create new user with the given role
+func (r *authReplicaRepo) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
+	registered, err := r.Repository.Register(ctx, user, roleName)
+	return registered, r.wrote(ctx, err)
+}
+
+// Update existing user
+func (r *authReplicaRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	updated, err := r.Repository.Update(ctx, user)
+	return updated, r.wrote(ctx, err)
+}
+
+// Delete existing user
+func (r *authReplicaRepo) Delete(ctx context.Context, userID int) error {
+	return r.wrote(ctx, r.Repository.Delete(ctx, userID))
+}
+
+// Set the verified phone of user
+func (r *authReplicaRepo) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	user, err := r.Repository.SetPhoneVerified(ctx, userID, phone)
+	return user, r.wrote(ctx, err)
+}
+
+// Switch SMS second factor of user
+func (r *authReplicaRepo) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	return r.wrote(ctx, r.Repository.SetSMS2FA(ctx, userID, enabled))
+}
+
+// Replace password with its bcrypt hash
+func (r *authReplicaRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	return r.wrote(ctx, r.Repository.UpdatePassword(ctx, userID, passwordHash))
+}
+
+// Schedule deletion of user after grace
+func (r *authReplicaRepo) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	user, err := r.Repository.ScheduleDeletion(ctx, userID, grace)
+	return user, r.wrote(ctx, err)
+}
+
+// Cancel scheduled deletion of user
+func (r *authReplicaRepo) CancelDeletion(ctx context.Context, userID int) error {
+	return r.wrote(ctx, r.Repository.CancelDeletion(ctx, userID))
+}
+
+// Delete user whose deletion is due
+func (r *authReplicaRepo) PurgeScheduled(ctx context.Context, userID int) error {
+	return r.wrote(ctx, r.Repository.PurgeScheduled(ctx, userID))
+}
+
+// Record the primary position after a successful write of a tracked request. The write is committed either
+// way, so a failed lookup only leaves the request without a token and is not returned
+func (r *authReplicaRepo) wrote(ctx context.Context, err error) error {
+	if err != nil || !r.readYourWrites || !consistency.Tracked(ctx) {
+		return err
+	}
+	primary, ok := r.Repository.(walPositioner)
+	if !ok {
+		return nil
+	}
+	if position, err := primary.WALPosition(ctx); err == nil {
+		consistency.Wrote(ctx, position)
+	}
+	return nil
+}
+
+// Get user by id
+func (r *authReplicaRepo) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	return replicaRead(ctx, r, "GetByID", func(ctx context.Context, repo auth.Repository) (*models.UserWithRole, error) {
+		return repo.GetByID(ctx, userID)
+	})
+}
+
+// Find users by name
+func (r *authReplicaRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+	return replicaRead(ctx, r, "FindByName", func(ctx context.Context, repo auth.Repository) (*models.UsersList, error) {
+		// Attempts run concurrently, each gets its own query
+		attemptQuery := *query
+		return repo.FindByName(ctx, name, &attemptQuery)
+	})
+}
+
+// Find user by email
+func (r *authReplicaRepo) FindByEmail(ctx context.Context, userEmail string) (*models.User, error) {
+	return replicaRead(ctx, r, "FindByEmail", func(ctx context.Context, repo auth.Repository) (*models.User, error) {
+		return repo.FindByEmail(ctx, userEmail)
+	})
+}
+
+// Find user by username
+func (r *authReplicaRepo) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
+	return replicaRead(ctx, r, "FindByUsername", func(ctx context.Context, repo auth.Repository) (*models.UserWithRole, error) {
+		return repo.FindByUsername(ctx, username)
+	})
+}
+
+// Get users with pagination
+func (r *authReplicaRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	return replicaRead(ctx, r, "GetUsers", func(ctx context.Context, repo auth.Repository) (*models.UsersList, error) {
+		attemptQuery := *pq
+		return repo.GetUsers(ctx, &attemptQuery)
+	})
+}
+
+type readResult[T any] struct {
+	value T
+	err   error
+	hedge bool
+}
+
+// Read from the next replica, hedged against the one after it. A failed attempt does not win while the
+// other one may still answer
+func replicaRead[T any](ctx context.Context, r *authReplicaRepo, method string, read func(ctx context.Context, repo auth.Repository) (T, error)) (T, error) {
+	if !r.methods[strings.ToLower(method)] || len(r.replicas) == 0 {
+		return read(ctx, r.Repository)
+	}
+	if required, ok := consistency.Required(ctx); ok && r.readYourWrites {
+		return read(ctx, r.caughtUp(ctx, required))
+	}
+	first, second := r.pick()
+	if r.hedgeDelay <= 0 {
+		return read(ctx, first)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan readResult[T], 2)
+	attempt := func(repo auth.Repository, hedge bool) {
+		value, err := read(ctx, repo)
+		results <- readResult[T]{value: value, err: err, hedge: hedge}
+	}
+
+	go attempt(first, false)
+	timer := time.NewTimer(r.hedgeDelay)
+	defer timer.Stop()
+	select {
+	case result := <-results:
+		r.count(method, HedgeUnhedged)
+		return result.value, result.err
+	case <-timer.C:
+	}
+
+	go attempt(second, true)
+	result := <-results
+	if result.err != nil {
+		if other := <-results; other.err == nil {
+			result = other
+		}
+	}
+	if result.hedge {
+		r.count(method, HedgeWon)
+	} else {
+		r.count(method, HedgeFirst)
+	}
+	return result.value, result.err
+}
+
+// Replica taking its turn and the target of its hedge, the primary when there is no other replica
+func (r *authReplicaRepo) pick() (auth.Repository, auth.Repository) {
+	turn := int(atomic.AddUint32(&r.next, 1) % uint32(len(r.replicas)))
+	if len(r.replicas) == 1 {
+		return r.replicas[0], r.Repository
+	}
+	return r.replicas[turn], r.replicas[(turn+1)%len(r.replicas)]
+}
+
+// First replica in turn which has replayed required, the primary when none has
+func (r *authReplicaRepo) caughtUp(ctx context.Context, required consistency.LSN) auth.Repository {
+	turn := int(atomic.AddUint32(&r.next, 1) % uint32(len(r.replicas)))
+	for i := range r.replicas {
+		replica := r.replicas[(turn+i)%len(r.replicas)]
+		positioner, ok := replica.(walPositioner)
+		if !ok {
+			continue
+		}
+		if position, err := positioner.WALPosition(ctx); err == nil && position >= required {
+			return replica
+		}
+	}
+	return r.Repository
+}
+
+func (r *authReplicaRepo) count(method, result string) {
+	if r.metrics != nil {
+		r.metrics.IncHedgedReads(method, result)
+	}
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func newTestReplicaCfg(hedgeDelayMs int) *config.Config {
+	return &config.Config{Replicas: config.Replicas{
+		Enabled:      true,
+		Methods:      map[string]bool{"getusers": true},
+		HedgeDelayMs: hedgeDelayMs,
+	}}
+}
+
+// Read answering once ctx is canceled, like a replica stuck on a slow query
+func stuckRead(ctx context.Context, _ *utils.PaginationQuery) (*models.UsersList, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAuthReplicaRepo_Routing(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	primary, replica := mock.NewMockRepository(ctrl), mock.NewMockRepository(ctrl)
+	repo := NewAuthReplicaRepository(primary, []auth.Repository{replica}, newTestReplicaCfg(0), nil)
+	ctx := context.Background()
+
+	// Listed reads go to the replica, others and writes stay on the primary
+	replica.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 1}, nil)
+	primary.EXPECT().GetByID(gomock.Any(), 1).Return(&models.UserWithRole{}, nil)
+	primary.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+
+	users, err := repo.GetUsers(ctx, &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, users.TotalCount)
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, 1))
+}
+
+func TestAuthReplicaRepo_Hedge(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	first, second := mock.NewMockRepository(ctrl), mock.NewMockRepository(ctrl)
+	repo := NewAuthReplicaRepository(mock.NewMockRepository(ctrl), []auth.Repository{first, second}, newTestReplicaCfg(10), nil).(*authReplicaRepo)
+	ctx := context.Background()
+
+	// Turns start at the second replica, a fast answer sends no hedge
+	second.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 2}, nil)
+	users, err := repo.GetUsers(ctx, &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 2, users.TotalCount)
+
+	// A stuck replica is hedged and canceled once the hedge answers
+	first.EXPECT().GetUsers(gomock.Any(), gomock.Any()).DoAndReturn(stuckRead)
+	second.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 3}, nil)
+	started := time.Now()
+	users, err = repo.GetUsers(ctx, &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 3, users.TotalCount)
+	require.Less(t, time.Since(started), time.Second)
+
+	// A failed hedge does not win over the slower attempt
+	second.EXPECT().GetUsers(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ *utils.PaginationQuery) (*models.UsersList, error) {
+		time.Sleep(50 * time.Millisecond)
+		return &models.UsersList{TotalCount: 4}, nil
+	})
+	first.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, errors.New("replica down"))
+	users, err = repo.GetUsers(ctx, &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 4, users.TotalCount)
+}
+
+func TestAuthReplicaRepo_HedgeAgainstPrimary(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	primary, replica := mock.NewMockRepository(ctrl), mock.NewMockRepository(ctrl)
+	repo := NewAuthReplicaRepository(primary, []auth.Repository{replica}, newTestReplicaCfg(10), nil)
+
+	replica.EXPECT().GetUsers(gomock.Any(), gomock.Any()).DoAndReturn(stuckRead)
+	primary.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 1}, nil)
+	users, err := repo.GetUsers(context.Background(), &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, users.TotalCount)
+}
+
+// Repository at a fixed position in the write ahead log
+type positionedRepo struct {
+	*mock.MockRepository
+	position consistency.LSN
+}
+
+func (r positionedRepo) WALPosition(context.Context) (consistency.LSN, error) {
+	return r.position, nil
+}
+
+func TestAuthReplicaRepo_ReadYourWrites(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	primary := positionedRepo{MockRepository: mock.NewMockRepository(ctrl), position: 0x30}
+	lagging := positionedRepo{MockRepository: mock.NewMockRepository(ctrl), position: 0x10}
+	caughtUp := positionedRepo{MockRepository: mock.NewMockRepository(ctrl), position: 0x30}
+	cfg := newTestReplicaCfg(10)
+	cfg.Replicas.ReadYourWrites = true
+	repo := NewAuthReplicaRepository(primary, []auth.Repository{lagging, caughtUp}, cfg, nil)
+
+	// A write answers the primary position as token
+	ctx, state := consistency.With(context.Background(), 0)
+	primary.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&models.User{}, nil)
+	_, err := repo.Update(ctx, &models.User{})
+	require.NoError(t, err)
+	token, ok := state.Token()
+	require.True(t, ok)
+	require.Equal(t, "0/30", token)
+
+	// Reads carrying it skip the lagging replica and are not hedged
+	ctx, _ = consistency.With(context.Background(), 0x30)
+	caughtUp.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 1}, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		users, err := repo.GetUsers(ctx, &utils.PaginationQuery{})
+		require.NoError(t, err)
+		require.Equal(t, 1, users.TotalCount)
+	}
+
+	// The primary serves them while no replica has caught up
+	ctx, _ = consistency.With(context.Background(), 0x40)
+	primary.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(&models.UsersList{TotalCount: 2}, nil)
+	users, err := repo.GetUsers(ctx, &utils.PaginationQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 2, users.TotalCount)
+
+	// Failed writes record nothing
+	ctx, state = consistency.With(context.Background(), 0)
+	primary.EXPECT().Delete(gomock.Any(), 1).Return(errors.New("db down"))
+	require.Error(t, repo.Delete(ctx, 1))
+	_, ok = state.Token()
+	require.False(t, ok)
+}
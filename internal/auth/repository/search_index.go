@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository/sqlcdb"
+)
+
+// Rebuilds the GIN index without blocking writes, can't run in a transaction
+const reindexUserSearchQuery = `REINDEX INDEX CONCURRENTLY idx_user_search_name_trgm`
+
+// State of the user search index
+type UserSearchHealth struct {
+	ExtensionInstalled bool `json:"extension_installed"`
+	TriggerEnabled     bool `json:"trigger_enabled"`
+	IndexValid         bool `json:"index_valid"`
+	// Users missing from the index or indexed under an old name, -1 when not counted
+	Stale    int64    `json:"stale"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Healthy when no problem was found
+func (h *UserSearchHealth) Healthy() bool {
+	return len(h.Problems) == 0
+}
+
+// Result of a search index rebuild
+type UserSearchRebuildStats struct {
+	Batches   int
+	Written   int64
+	Reindexed bool
+}
+
+// Trigram index of user names searches are served from, kept in step with users by a trigger. Rebuild repairs
+// rows written while the trigger was disabled and a GIN index left invalid by a failed build
+type UserSearchIndex struct {
+	db *sqlx.DB
+	q  *sqlcdb.Queries
+}
+
+// User search index constructor
+func NewUserSearchIndex(db *sqlx.DB) *UserSearchIndex {
+	return &UserSearchIndex{db: db, q: sqlcdb.New(db)}
+}
+
+// Check the extension, trigger and index. Counting stale rows reads every user, so only full checks do
+func (i *UserSearchIndex) Health(ctx context.Context, countStale bool) (*UserSearchHealth, error) {
+	row, err := i.q.GetUserSearchHealth(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "UserSearchIndex.Health.GetUserSearchHealth")
+	}
+	health := &UserSearchHealth{
+		ExtensionInstalled: row.ExtensionInstalled,
+		TriggerEnabled:     row.TriggerEnabled,
+		IndexValid:         row.IndexValid,
+		Stale:              -1,
+	}
+	if !health.ExtensionInstalled {
+		health.Problems = append(health.Problems, "pg_trgm extension is not installed")
+	}
+	if !health.TriggerEnabled {
+		health.Problems = append(health.Problems, "trigger keeping the index in step with users is missing or disabled")
+	}
+	if !health.IndexValid {
+		health.Problems = append(health.Problems, "trigram index is missing or invalid, rebuild it with -reindex")
+	}
+	if countStale {
+		if health.Stale, err = i.q.CountStaleUserSearch(ctx); err != nil {
+			return nil, errors.Wrap(err, "UserSearchIndex.Health.CountStaleUserSearch")
+		}
+		if health.Stale > 0 {
+			health.Problems = append(health.Problems, "users are missing from the index or indexed under an old name")
+		}
+	}
+	return health, nil
+}
+
+// Rewrite the index rows of every user in batches of user ids, then rebuild the GIN index when reindex.
+// Users signing up meanwhile are indexed by the trigger, so the run can be repeated any time
+func (i *UserSearchIndex) Rebuild(ctx context.Context, batchSize int, reindex bool) (*UserSearchRebuildStats, error) {
+	stats := &UserSearchRebuildStats{}
+	maxID, err := i.q.MaxUserID(ctx)
+	if err != nil {
+		return stats, errors.Wrap(err, "UserSearchIndex.Rebuild.MaxUserID")
+	}
+
+	for afterID := int32(0); afterID < maxID; afterID += int32(batchSize) {
+		untilID := afterID + int32(batchSize)
+		if untilID > maxID {
+			untilID = maxID
+		}
+		written, err := i.q.RebuildUserSearch(ctx, sqlcdb.RebuildUserSearchParams{AfterID: afterID, UntilID: untilID})
+		if err != nil {
+			return stats, errors.Wrapf(err, "UserSearchIndex.Rebuild.RebuildUserSearch after user %d", afterID)
+		}
+		stats.Batches++
+		stats.Written += written
+	}
+
+	if reindex {
+		if _, err := i.db.ExecContext(ctx, reindexUserSearchQuery); err != nil {
+			return stats, errors.Wrap(err, "UserSearchIndex.Rebuild.Reindex")
+		}
+		stats.Reindexed = true
+	}
+	return stats, nil
+}
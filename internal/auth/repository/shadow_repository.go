@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultShadowTimeout   = 2 * time.Second
+	defaultShadowQueueSize = 1000
+	maxDivergentPaths      = 10
+)
+
+// Shadow comparison results
+const (
+	ShadowMatch    = "match"
+	ShadowDiverged = "diverged"
+	ShadowDropped  = "dropped"
+)
+
+// Fields set by the backend on writes, they differ between backends by nature
+var shadowWriteVolatile = []string{"created_at", "updated_at", "login_at", "deletion_requested_at", "deletion_scheduled_at"}
+
+// Call replayed against the secondary backend, primary is the JSON snapshot of the primary result
+type shadowCall struct {
+	method     string
+	span       opentracing.SpanContext
+	primary    []byte
+	primaryErr error
+	ignore     map[string]bool
+	run        func(ctx context.Context) (interface{}, error)
+}
+
+// Auth Repository serving from primary and mirroring calls to a secondary backend while migrating, e.g. to
+// CockroachDB. Results are compared off the request path, mirrored calls run in order on a bounded queue, a full queue drops the call instead of slowing the request.
+type authShadowRepo struct {
+	primary   auth.Repository
+	secondary auth.Repository
+	cfg       config.Shadow
+	timeout   time.Duration
+	queue     chan shadowCall
+	sample    func() float64
+	metrics   metric.Metrics
+	logger    logger.Logger
+}
+
+// Auth shadow Repository constructor, workers stop with ctx, metrics may be nil
+func NewAuthShadowRepository(
+	ctx context.Context,
+	primary auth.Repository,
+	secondary auth.Repository,
+	cfg *config.Config,
+	metrics metric.Metrics,
+	logger logger.Logger,
+) auth.Repository {
+	r := newAuthShadowRepo(primary, secondary, cfg, metrics, logger)
+
+	workers := cfg.Shadow.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go r.work(ctx)
+	}
+	return r
+}
+
+func newAuthShadowRepo(primary auth.Repository, secondary auth.Repository, cfg *config.Config, metrics metric.Metrics, logger logger.Logger) *authShadowRepo {
+	timeout := time.Duration(cfg.Shadow.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultShadowTimeout
+	}
+	queueSize := cfg.Shadow.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultShadowQueueSize
+	}
+
+	return &authShadowRepo{
+		primary:   primary,
+		secondary: secondary,
+		cfg:       cfg.Shadow,
+		timeout:   timeout,
+		queue:     make(chan shadowCall, queueSize),
+		sample:    rand.Float64,
+		metrics:   metrics,
+		logger:    logger,
+	}
+}
+
+// Create new user
+func (r *authShadowRepo) Register(ctx context.Context, user *models.User, roleName string) (*models.UserWithRole, error) {
+	created, err := r.primary.Register(ctx, user, roleName)
+	if r.enabled("Register", true) && err == nil {
+		shadowUser := *user
+		// Backends assign their own ids
+		r.mirror(ctx, "Register", created, err, append(shadowWriteVolatile, "id"), func(ctx context.Context) (interface{}, error) {
+			return r.secondary.Register(ctx, &shadowUser, roleName)
+		})
+	}
+	return created, err
+}
+
+// Update existing user
+func (r *authShadowRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	updated, err := r.primary.Update(ctx, user)
+	if r.enabled("Update", true) && err == nil {
+		shadowUser := *user
+		r.mirror(ctx, "Update", updated, err, shadowWriteVolatile, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.Update(ctx, &shadowUser)
+		})
+	}
+	return updated, err
+}
+
+// Delete existing user
+func (r *authShadowRepo) Delete(ctx context.Context, userID int) error {
+	err := r.primary.Delete(ctx, userID)
+	if r.enabled("Delete", true) && err == nil {
+		r.mirror(ctx, "Delete", nil, err, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, r.secondary.Delete(ctx, userID)
+		})
+	}
+	return err
+}
+
+// Get user by id
+func (r *authShadowRepo) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	user, err := r.primary.GetByID(ctx, userID)
+	if r.enabled("GetByID", false) {
+		r.mirror(ctx, "GetByID", user, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.GetByID(ctx, userID)
+		})
+	}
+	return user, err
+}
+
+// Find users by name
+func (r *authShadowRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+	users, err := r.primary.FindByName(ctx, name, query)
+	if r.enabled("FindByName", false) {
+		shadowQuery := *query
+		r.mirror(ctx, "FindByName", users, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.FindByName(ctx, name, &shadowQuery)
+		})
+	}
+	return users, err
+}
+
+// Find user by email
+func (r *authShadowRepo) FindByEmail(ctx context.Context, userEmail string) (*models.User, error) {
+	user, err := r.primary.FindByEmail(ctx, userEmail)
+	if r.enabled("FindByEmail", false) {
+		r.mirror(ctx, "FindByEmail", user, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.FindByEmail(ctx, userEmail)
+		})
+	}
+	return user, err
+}
+
+// Find user by username
+func (r *authShadowRepo) FindByUsername(ctx context.Context, username string) (*models.UserWithRole, error) {
+	user, err := r.primary.FindByUsername(ctx, username)
+	if r.enabled("FindByUsername", false) {
+		r.mirror(ctx, "FindByUsername", user, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.FindByUsername(ctx, username)
+		})
+	}
+	return user, err
+}
+
+// Get users with pagination
+func (r *authShadowRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	users, err := r.primary.GetUsers(ctx, pq)
+	if r.enabled("GetUsers", false) {
+		shadowQuery := *pq
+		r.mirror(ctx, "GetUsers", users, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.GetUsers(ctx, &shadowQuery)
+		})
+	}
+	return users, err
+}
+
+// Mark phone verified
+func (r *authShadowRepo) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	user, err := r.primary.SetPhoneVerified(ctx, userID, phone)
+	if r.enabled("SetPhoneVerified", true) && err == nil {
+		r.mirror(ctx, "SetPhoneVerified", user, err, append(shadowWriteVolatile, "phone_verified_at"), func(ctx context.Context) (interface{}, error) {
+			return r.secondary.SetPhoneVerified(ctx, userID, phone)
+		})
+	}
+	return user, err
+}
+
+// Switch SMS second factor
+func (r *authShadowRepo) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	err := r.primary.SetSMS2FA(ctx, userID, enabled)
+	if r.enabled("SetSMS2FA", true) && err == nil {
+		r.mirror(ctx, "SetSMS2FA", nil, err, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, r.secondary.SetSMS2FA(ctx, userID, enabled)
+		})
+	}
+	return err
+}
+
+// Replace password
+func (r *authShadowRepo) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	err := r.primary.UpdatePassword(ctx, userID, passwordHash)
+	if r.enabled("UpdatePassword", true) && err == nil {
+		r.mirror(ctx, "UpdatePassword", nil, err, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, r.secondary.UpdatePassword(ctx, userID, passwordHash)
+		})
+	}
+	return err
+}
+
+// Schedule account deletion
+func (r *authShadowRepo) ScheduleDeletion(ctx context.Context, userID int, grace time.Duration) (*models.User, error) {
+	user, err := r.primary.ScheduleDeletion(ctx, userID, grace)
+	if r.enabled("ScheduleDeletion", true) && err == nil {
+		r.mirror(ctx, "ScheduleDeletion", user, err, shadowWriteVolatile, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.ScheduleDeletion(ctx, userID, grace)
+		})
+	}
+	return user, err
+}
+
+// Cancel scheduled deletion
+func (r *authShadowRepo) CancelDeletion(ctx context.Context, userID int) error {
+	err := r.primary.CancelDeletion(ctx, userID)
+	if r.enabled("CancelDeletion", true) && err == nil {
+		r.mirror(ctx, "CancelDeletion", nil, err, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, r.secondary.CancelDeletion(ctx, userID)
+		})
+	}
+	return err
+}
+
+// List users due for purge
+func (r *authShadowRepo) ListDueForDeletion(ctx context.Context, limit int) ([]int, error) {
+	userIDs, err := r.primary.ListDueForDeletion(ctx, limit)
+	if r.enabled("ListDueForDeletion", false) {
+		r.mirror(ctx, "ListDueForDeletion", userIDs, err, nil, func(ctx context.Context) (interface{}, error) {
+			return r.secondary.ListDueForDeletion(ctx, limit)
+		})
+	}
+	return userIDs, err
+}
+
+// Purge user scheduled for deletion
+func (r *authShadowRepo) PurgeScheduled(ctx context.Context, userID int) error {
+	err := r.primary.PurgeScheduled(ctx, userID)
+	if r.enabled("PurgeScheduled", true) && err == nil {
+		r.mirror(ctx, "PurgeScheduled", nil, err, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, r.secondary.PurgeScheduled(ctx, userID)
+		})
+	}
+	return err
+}
+
+// Is method mirrored, a method listed in config by lowercase name wins over the switch of its kind
+func (r *authShadowRepo) enabled(method string, write bool) bool {
+	if on, ok := r.cfg.Methods[strings.ToLower(method)]; ok {
+		return on
+	}
+	if write {
+		return r.cfg.Writes
+	}
+	return r.cfg.Reads
+}
+
+// Queue call for the secondary backend, the primary result is snapshotted now as callers may modify it
+func (r *authShadowRepo) mirror(
+	ctx context.Context,
+	method string,
+	primary interface{},
+	primaryErr error,
+	ignore []string,
+	run func(ctx context.Context) (interface{}, error),
+) {
+	snapshot, err := json.Marshal(primary)
+	if err != nil {
+		r.logger.Errorf("authShadowRepo.%s.json.Marshal: %v", method, err)
+		return
+	}
+
+	call := shadowCall{method: method, primary: snapshot, primaryErr: primaryErr, run: run, ignore: make(map[string]bool, len(ignore))}
+	for _, key := range ignore {
+		call.ignore[key] = true
+	}
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		call.span = span.Context()
+	}
+
+	select {
+	case r.queue <- call:
+	default:
+		r.count(method, ShadowDropped)
+	}
+}
+
+func (r *authShadowRepo) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case call := <-r.queue:
+			r.compare(ctx, call)
+		}
+	}
+}
+
+// Replay call on the secondary backend and log sampled divergences, only field paths are logged as values hold PII
+func (r *authShadowRepo) compare(ctx context.Context, call shadowCall) {
+	var opts []opentracing.StartSpanOption
+	if call.span != nil {
+		opts = append(opts, opentracing.FollowsFrom(call.span))
+	}
+	span := opentracing.StartSpan("authShadowRepo."+call.method, opts...)
+	defer span.Finish()
+
+	ctx, cancel := context.WithTimeout(opentracing.ContextWithSpan(ctx, span), r.timeout)
+	defer cancel()
+
+	secondary, secondaryErr := call.run(ctx)
+	paths, err := r.diverging(call, secondary, secondaryErr)
+	if err != nil {
+		r.logger.Errorf("authShadowRepo.%s.compare: %v", call.method, err)
+		return
+	}
+	if len(paths) == 0 {
+		r.count(call.method, ShadowMatch)
+		return
+	}
+
+	r.count(call.method, ShadowDiverged)
+	if r.sample() < r.cfg.SampleRate {
+		r.logger.Warnf("authShadowRepo.%s diverged: %s", call.method, strings.Join(paths, ", "))
+	}
+}
+
+// Paths where the secondary result differs from the primary, errors match by kind only
+func (r *authShadowRepo) diverging(call shadowCall, secondary interface{}, secondaryErr error) ([]string, error) {
+	if call.primaryErr != nil || secondaryErr != nil {
+		if errorKind(call.primaryErr) != errorKind(secondaryErr) {
+			return []string{fmt.Sprintf("error: %s != %s", errorKind(call.primaryErr), errorKind(secondaryErr))}, nil
+		}
+		return nil, nil
+	}
+
+	snapshot, err := json.Marshal(secondary)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal")
+	}
+
+	var primaryValue, secondaryValue interface{}
+	if err := json.Unmarshal(call.primary, &primaryValue); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+	if err := json.Unmarshal(snapshot, &secondaryValue); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+	return divergentPaths("", primaryValue, secondaryValue, call.ignore, nil), nil
+}
+
+func (r *authShadowRepo) count(method, result string) {
+	if r.metrics != nil {
+		r.metrics.IncShadowComparisons(method, result)
+	}
+}
+
+func errorKind(err error) string {
+	switch {
+	case err == nil:
+		return "none"
+	case errors.Is(err, sql.ErrNoRows):
+		return "not_found"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// Paths where two decoded JSON values differ, ignored keys are skipped at any depth
+func divergentPaths(path string, a, b interface{}, ignore map[string]bool, paths []string) []string {
+	if len(paths) >= maxDivergentPaths {
+		return paths
+	}
+
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := make(map[string]bool, len(aMap)+len(bMap))
+		for key := range aMap {
+			keys[key] = true
+		}
+		for key := range bMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			if !ignore[key] {
+				sorted = append(sorted, key)
+			}
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			paths = divergentPaths(joinPath(path, key), aMap[key], bMap[key], ignore, paths)
+		}
+		return paths
+	}
+
+	aList, aIsList := a.([]interface{})
+	bList, bIsList := b.([]interface{})
+	if aIsList && bIsList && len(aList) == len(bList) {
+		for i := range aList {
+			paths = divergentPaths(fmt.Sprintf("%s[%d]", path, i), aList[i], bList[i], ignore, paths)
+		}
+		return paths
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		if path == "" {
+			path = "result"
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+func TestAuthShadowRepo_Compare(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Logger: config.Logger{Development: true, Level: "error", Encoding: "console"},
+		Shadow: config.Shadow{Reads: true, Writes: true, Methods: map[string]bool{"getusers": false}},
+	}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	primary, secondary := NewAuthMemoryRepository(), NewAuthMemoryRepository()
+	repo := newAuthShadowRepo(primary, secondary, cfg, nil, appLogger)
+	ctx := context.Background()
+
+	require.True(t, repo.enabled("GetByID", false))
+	require.False(t, repo.enabled("GetUsers", false))
+
+	created, err := repo.Register(ctx, &models.User{Username: "shadow", Email: "shadow@example.com", Password: "hash"}, defaultRoleName)
+	require.NoError(t, err)
+	call := <-repo.queue
+	secondaryResult, secondaryErr := call.run(ctx)
+	paths, err := repo.diverging(call, secondaryResult, secondaryErr)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	// Reads compare timestamps too, each memory backend stamped its own write
+	_, err = repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	call = <-repo.queue
+	secondaryResult, secondaryErr = call.run(ctx)
+	paths, err = repo.diverging(call, secondaryResult, secondaryErr)
+	require.NoError(t, err)
+	require.NotContains(t, paths, "user.username")
+
+	_, err = secondary.Update(ctx, &models.User{ID: created.User.ID, Username: "drifted"})
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	call = <-repo.queue
+	secondaryResult, secondaryErr = call.run(ctx)
+	paths, err = repo.diverging(call, secondaryResult, secondaryErr)
+	require.NoError(t, err)
+	require.Contains(t, paths, "user.username")
+
+	require.NoError(t, secondary.Delete(ctx, created.User.ID))
+	_, err = repo.GetByID(ctx, created.User.ID)
+	require.NoError(t, err)
+	call = <-repo.queue
+	secondaryResult, secondaryErr = call.run(ctx)
+	paths, err = repo.diverging(call, secondaryResult, secondaryErr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"error: none != not_found"}, paths)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Default pg_trgm.similarity_threshold, names at least this similar match the % operator
+const similarityThreshold = 0.3
+
+// Trigram similarity the way pg_trgm computes it, for the memory repository to find what Postgres finds.
+// Words are runs of letters and digits, padded with two spaces in front and one behind
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrigramSimilarity(t *testing.T) {
+	t.Parallel()
+
+	// Values SELECT similarity(a, b) returns
+	require.InDelta(t, 0.36363637, trigramSimilarity("word", "two words"), 1e-6)
+	require.InDelta(t, 1, trigramSimilarity("John_Smith", "smith john"), 1e-6)
+	require.InDelta(t, 0.2857143, trigramSimilarity("jon", "john"), 1e-6)
+	require.Zero(t, trigramSimilarity("abc", "xyz"))
+	require.Zero(t, trigramSimilarity("", "john"))
+	require.GreaterOrEqual(t, trigramSimilarity("alexander", "alexandr"), similarityThreshold)
+}
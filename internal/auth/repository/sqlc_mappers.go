@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository/pgxdb"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository/sqlcdb"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Map generated user row to domain model
+func toUserModel(u sqlcdb.User) models.User {
+	user := models.User{
+		ID:        int(u.ID),
+		Username:  u.Username,
+		Email:     u.Email,
+		Password:  u.Password,
+		CreatedAt: u.CreatedAt.Time,
+		UpdatedAt: u.UpdatedAt.Time,
+		LoginDate: u.LoginAt.Time,
+		Timezone:  u.Timezone,
+		Phone:     u.Phone.String,
+		SMS2FA:    u.Sms2faEnabled,
+	}
+	if u.PhoneVerifiedAt.Valid {
+		user.PhoneVerifiedAt = &u.PhoneVerifiedAt.Time
+	}
+	if u.DeletionRequestedAt.Valid {
+		user.DeletionRequestedAt = &u.DeletionRequestedAt.Time
+	}
+	if u.DeletionScheduledAt.Valid {
+		user.DeletionScheduledAt = &u.DeletionScheduledAt.Time
+	}
+	user.Status = userStatus(&user)
+	return user
+}
+
+// Account state derived from the deletion schedule
+func userStatus(user *models.User) string {
+	if user.PendingDeletion() {
+		return models.UserStatusPendingDeletion
+	}
+	return models.UserStatusActive
+}
+
+// Map generated role row to domain model
+func toRoleModel(r sqlcdb.Role) models.Role {
+	return models.Role{
+		ID:          int(r.ID),
+		Name:        r.Name,
+		Description: r.Description.String,
+		ParentRoleId: sql.NullInt64{
+			Int64: int64(r.ParentRoleID.Int32),
+			Valid: r.ParentRoleID.Valid,
+		},
+	}
+}
+
+// Build paginated users response
+func newUsersList(totalCount int, pq *utils.PaginationQuery, users []*models.User) *models.UsersList {
+	return &models.UsersList{
+		TotalCount:    totalCount,
+		TotalPages:    utils.GetTotalPages(totalCount, pq.GetSize()),
+		Page:          pq.GetPage(),
+		Size:          pq.GetSize(),
+		HasMore:       utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		CountStrategy: string(utils.CountExact),
+		Users:         users,
+	}
+}
+
+// Map generated pgx user row to domain model
+func pgxToUserModel(u pgxdb.User) models.User {
+	user := models.User{
+		ID:        int(u.ID),
+		Username:  u.Username,
+		Email:     u.Email,
+		Password:  u.Password,
+		CreatedAt: u.CreatedAt.Time,
+		UpdatedAt: u.UpdatedAt.Time,
+		LoginDate: u.LoginAt.Time,
+		Timezone:  u.Timezone,
+		Phone:     u.Phone.String,
+		SMS2FA:    u.Sms2faEnabled,
+	}
+	if u.PhoneVerifiedAt.Valid {
+		user.PhoneVerifiedAt = &u.PhoneVerifiedAt.Time
+	}
+	if u.DeletionRequestedAt.Valid {
+		user.DeletionRequestedAt = &u.DeletionRequestedAt.Time
+	}
+	if u.DeletionScheduledAt.Valid {
+		user.DeletionScheduledAt = &u.DeletionScheduledAt.Time
+	}
+	user.Status = userStatus(&user)
+	return user
+}
+
+// Map generated pgx role row to domain model
+func pgxToRoleModel(r pgxdb.Role) models.Role {
+	return models.Role{
+		ID:          int(r.ID),
+		Name:        r.Name,
+		Description: r.Description.String,
+		ParentRoleId: sql.NullInt64{
+			Int64: int64(r.ParentRoleID.Int32),
+			Valid: r.ParentRoleID.Valid,
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+// Connection answering every query with the same rows, remembers the statements it ran
+type queryConn struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	queries  []string
+	args     [][]driver.NamedValue
+}
+
+func (c *queryConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *queryConn) Close() error                        { return nil }
+func (c *queryConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *queryConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	return &queryRows{columns: c.columns, rows: c.rows}, nil
+}
+
+func (c *queryConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	return driver.RowsAffected(c.affected), nil
+}
+
+type queryRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *queryRows) Columns() []string { return r.columns }
+func (r *queryRows) Close() error      { return nil }
+
+func (r *queryRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type queryConnector struct {
+	conn *queryConn
+}
+
+func (c queryConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c queryConnector) Driver() driver.Driver                        { return nil }
+
+func TestAuthRepository_SqlcQueries(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	conn := &queryConn{
+		columns: make([]string, 19),
+		rows: [][]driver.Value{{
+			int64(7), "alex", "alex@example.com", "hash", createdAt, createdAt, nil, "UTC", nil, "+15550100", nil,
+			createdAt, true, nil, nil,
+			int64(2), "user", "Regular user", nil,
+		}},
+		affected: 1,
+	}
+	db := sqlx.NewDb(sql.OpenDB(queryConnector{conn: conn}), "auth_sqlc")
+	defer db.Close()
+	repo := NewAuthRepository(db, nil, nil, nil)
+	ctx := context.Background()
+
+	// Generated rows are mapped to the domain models
+	found, err := repo.GetByID(ctx, 7)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(conn.queries[0], "-- name: GetUserWithRole :one"))
+	require.Equal(t, int64(7), conn.args[0][0].Value)
+	require.Equal(t, 7, found.User.ID)
+	require.Equal(t, "alex@example.com", found.User.Email)
+	require.Equal(t, createdAt, found.User.CreatedAt)
+	require.True(t, found.User.LoginDate.IsZero())
+	require.Equal(t, "+15550100", found.User.Phone)
+	require.Equal(t, createdAt, *found.User.PhoneVerifiedAt)
+	require.True(t, found.User.SMS2FA)
+	require.Nil(t, found.User.DeletionScheduledAt)
+	require.Equal(t, "user", found.Role.Name)
+	require.Equal(t, "Regular user", found.Role.Description)
+	require.False(t, found.Role.ParentRoleId.Valid)
+
+	require.NoError(t, repo.UpdatePassword(ctx, 7, "new-hash"))
+	require.True(t, strings.HasPrefix(conn.queries[1], "-- name: UpdateUserPassword :execrows"))
+	require.Equal(t, "new-hash", conn.args[1][0].Value)
+
+	// No row updated is a missing user
+	conn.affected = 0
+	require.ErrorIs(t, repo.UpdatePassword(ctx, 8, "new-hash"), sql.ErrNoRows)
+}
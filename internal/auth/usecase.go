@@ -1,4 +1,5 @@
 //go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
 package auth
 
 import (
@@ -15,7 +16,22 @@ type UseCase interface {
 	Login(ctx context.Context, user *dto.LoginUserRequest) (*models.UserWithToken, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, userID int) error
+	// Runs every item of the batch, observe:nodeadline
+	BatchUsers(ctx context.Context, req *models.UserBatchRequest) (*models.UserBatchResult, error)
 	GetByID(ctx context.Context, userID int) (*models.UserWithRole, error)
+	GetByEmail(ctx context.Context, email string) (*models.UserWithRole, error)
+	VerifyPassword(ctx context.Context, userID int, password string) error
+	ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error
+	IssueToken(ctx context.Context, userID int) (*models.UserWithToken, error)
+	GetAccess(ctx context.Context, user *models.UserWithRole) (*models.Access, error)
+	IssueScopedToken(ctx context.Context, userID int, req *dto.TokenExchangeRequest) (*models.ScopedToken, error)
+	SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error)
+	SetSMS2FA(ctx context.Context, userID int, enabled bool) error
+	RequestDeletion(ctx context.Context, userID int) (*models.User, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	PurgeDueDeletions(ctx context.Context, dryRun bool) (int, error)
 	FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+	InvalidateUserCache(ctx context.Context, userID int) error
+	InvalidateUsersCache(ctx context.Context) error
 }
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+const accessPrefix = "api-auth-access:"
+
+// Permissions of the user role and feature flags evaluated for the user, returned at login and by /auth/me. Cached
+// for CacheSeconds per user, role and billing plans so a role or plan change is picked up at once while flag edits
+// wait for the cache to expire
+func (u *authUC) GetAccess(ctx context.Context, user *models.UserWithRole) (*models.Access, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetAccess")
+	defer span.Finish()
+
+	plans, err := u.plans(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	cacheSeconds := u.cfg.Access.CacheSeconds
+	key := fmt.Sprintf("%s%d:%s", accessPrefix, user.User.ID, user.Role.Name)
+	if len(plans) > 0 {
+		key += ":" + strings.Join(plans, ",")
+	}
+	if cacheSeconds > 0 {
+		cached, err := u.redisRepo.GetAccessCtx(ctx, key)
+		if err != nil {
+			u.logger.Errorf("authUC.GetAccess.GetAccessCtx: %v", err)
+		}
+		if cached != nil {
+			return cached, nil
+		}
+	}
+
+	access := resolveAccess(&u.cfg.Access, user, plans)
+	if cacheSeconds > 0 {
+		if err := u.redisRepo.SetAccessCtx(ctx, key, cacheSeconds, access); err != nil {
+			u.logger.Errorf("authUC.GetAccess.SetAccessCtx: %v", err)
+		}
+	}
+	return access, nil
+}
+
+// Billing plans of the user, only looked up when a feature flag is limited to plans
+func (u *authUC) plans(ctx context.Context, userID int) ([]string, error) {
+	if u.billingUC == nil {
+		return nil, nil
+	}
+	for _, flag := range u.cfg.Access.Features {
+		if len(flag.Plans) > 0 {
+			return u.billingUC.Plans(ctx, userID)
+		}
+	}
+	return nil, nil
+}
+
+// Attach access to a login response, the login itself does not fail on it
+func (u *authUC) withAccess(ctx context.Context, user *models.UserWithRole, userWithToken *models.UserWithToken) *models.UserWithToken {
+	access, err := u.GetAccess(ctx, user)
+	if err != nil {
+		u.logger.Errorf("authUC.withAccess.GetAccess userID: %d, error: %v", user.User.ID, err)
+		return userWithToken
+	}
+	userWithToken.Access = access
+	return userWithToken
+}
+
+// Permissions and flags of the user from config, its role and flag names are lower case as viper folds map keys
+func resolveAccess(cfg *config.Access, user *models.UserWithRole, plans []string) *models.Access {
+	role := strings.ToLower(user.Role.Name)
+
+	seen := make(map[string]bool)
+	permissions := make([]string, 0)
+	for _, permission := range cfg.RolePermissions[role] {
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Strings(permissions)
+
+	features := make(map[string]bool, len(cfg.Features))
+	for name, flag := range cfg.Features {
+		features[name] = entitled(flag.Plans, plans) && flagEnabled(name, flag, role, user.User.ID)
+	}
+	return &models.Access{Permissions: permissions, Features: features}
+}
+
+// Is a user with plans entitled to a flag limited to flagPlans, any user is when the flag is not limited
+func entitled(flagPlans []string, plans []string) bool {
+	if len(flagPlans) == 0 {
+		return true
+	}
+	for _, flagPlan := range flagPlans {
+		for _, plan := range plans {
+			if flagPlan == plan {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Flag state for a user, the percentage bucket is stable across logins and differs per flag
+func flagEnabled(name string, flag config.FeatureFlag, role string, userID int) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if len(flag.Roles) > 0 {
+		allowed := false
+		for _, flagRole := range flag.Roles {
+			if strings.EqualFold(flagRole, role) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	if flag.Percent >= 100 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%s:%d", name, userID)
+	return int(h.Sum32()%100) < flag.Percent
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	billingMock "github.com/aditwar-man/go-microservice-boilerplate/internal/billing/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+func TestAuthUC_GetAccess(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Access: config.Access{
+			CacheSeconds: 60,
+			RolePermissions: map[string][]string{
+				"administrator": {"users:write", "users:read", "users:write"},
+			},
+			Features: map[string]config.FeatureFlag{
+				"everyone": {Enabled: true, Percent: 100},
+				"admins":   {Enabled: true, Roles: []string{"administrator"}, Percent: 100},
+				"nobody":   {Enabled: true, Percent: 0},
+				"off":      {Enabled: false, Percent: 100},
+			},
+		},
+	}
+	redisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mock.NewMockRepository(ctrl), redisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	admin := &models.UserWithRole{User: models.User{ID: 7}, Role: models.Role{Name: "Administrator"}}
+	redisRepo.EXPECT().GetAccessCtx(gomock.Any(), "api-auth-access:7:Administrator").Return(nil, nil)
+	redisRepo.EXPECT().SetAccessCtx(gomock.Any(), "api-auth-access:7:Administrator", 60, gomock.Any()).Return(nil)
+
+	access, err := authUC.GetAccess(context.Background(), admin)
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:read", "users:write"}, access.Permissions)
+	require.Equal(t, map[string]bool{"everyone": true, "admins": true, "nobody": false, "off": false}, access.Features)
+
+	// Cached result is returned as is
+	cached := &models.Access{Permissions: []string{"cached"}}
+	redisRepo.EXPECT().GetAccessCtx(gomock.Any(), "api-auth-access:7:Administrator").Return(cached, nil)
+	access, err = authUC.GetAccess(context.Background(), admin)
+	require.NoError(t, err)
+	require.Equal(t, cached, access)
+
+	cfg.Access.CacheSeconds = 0
+	access, err = authUC.GetAccess(context.Background(), &models.UserWithRole{User: models.User{ID: 8}, Role: models.Role{Name: "user"}})
+	require.NoError(t, err)
+	require.Empty(t, access.Permissions)
+	require.False(t, access.Features["admins"])
+	require.True(t, access.Features["everyone"])
+}
+
+func TestAuthUC_GetAccess_Plans(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Access: config.Access{
+			Features: map[string]config.FeatureFlag{
+				"everyone": {Enabled: true, Percent: 100},
+				"sso":      {Enabled: true, Plans: []string{"business"}, Percent: 100},
+				"reports":  {Enabled: true, Plans: []string{"team", "business"}, Percent: 100},
+			},
+		},
+	}
+	billingUC := billingMock.NewMockUseCase(ctrl)
+	authUC := NewAuthUseCase(cfg, mock.NewMockRepository(ctrl), mock.NewMockRedisRepository(ctrl), nil, nil, nil, billingUC, nil, nil, nil)
+
+	billingUC.EXPECT().Plans(gomock.Any(), 7).Return([]string{"team"}, nil)
+	access, err := authUC.GetAccess(context.Background(), &models.UserWithRole{User: models.User{ID: 7}, Role: models.Role{Name: "user"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"everyone": true, "sso": false, "reports": true}, access.Features)
+
+	billingUC.EXPECT().Plans(gomock.Any(), 8).Return([]string{}, nil)
+	access, err = authUC.GetAccess(context.Background(), &models.UserWithRole{User: models.User{ID: 8}, Role: models.Role{Name: "user"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"everyone": true, "sso": false, "reports": false}, access.Features)
+}
+
+func TestFlagEnabled_PercentIsStable(t *testing.T) {
+	t.Parallel()
+
+	flag := config.FeatureFlag{Enabled: true, Percent: 30}
+	enabled := 0
+	for userID := 1; userID <= 1000; userID++ {
+		on := flagEnabled("rollout", flag, "user", userID)
+		require.Equal(t, on, flagEnabled("rollout", flag, "user", userID))
+		if on {
+			enabled++
+		}
+	}
+	require.InDelta(t, 300, enabled, 60)
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultBatchMaxOperations = 100
+	defaultBatchConcurrency   = 8
+	errBatchTooLarge          = "Too many operations in batch"
+	errBatchUnknownOp         = "Unknown operation"
+	errBatchMissingUserID     = "User id is required"
+)
+
+// Run create, update and delete operations on users, UserBatch.Concurrency at a time.
+// Every operation commits or fails on its own, a failed item does not undo the others.
+func (u *authUC) BatchUsers(ctx context.Context, req *models.UserBatchRequest) (*models.UserBatchResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.BatchUsers")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+	maxOperations := u.cfg.UserBatch.MaxOperations
+	if maxOperations <= 0 {
+		maxOperations = defaultBatchMaxOperations
+	}
+	if len(req.Operations) > maxOperations {
+		return nil, httpErrors.NewRestError(http.StatusRequestEntityTooLarge, errBatchTooLarge, maxOperations)
+	}
+	concurrency := u.cfg.UserBatch.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]models.UserBatchItemResult, len(req.Operations))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range req.Operations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = u.batchItem(ctx, i, &req.Operations[i])
+		}(i)
+	}
+	wg.Wait()
+
+	result := &models.UserBatchResult{Results: results}
+	for _, item := range results {
+		if item.Error == "" {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+func (u *authUC) batchItem(ctx context.Context, index int, op *models.UserBatchOperation) models.UserBatchItemResult {
+	item := models.UserBatchItemResult{Index: index, Op: op.Op}
+	if err := ctx.Err(); err != nil {
+		return u.batchItemError(item, err)
+	}
+
+	switch op.Op {
+	case models.UserBatchCreate:
+		req := &dto.RegisterUserRequest{Username: op.User.Username, Email: op.User.Email, Password: op.User.Password}
+		if err := utils.ValidateStruct(ctx, req); err != nil {
+			return u.batchItemError(item, httpErrors.NewBadRequestError(err.Error()))
+		}
+		created, err := u.register(ctx, req)
+		if err != nil {
+			return u.batchItemError(item, err)
+		}
+		item.Status, item.User = http.StatusCreated, &created.User
+
+	case models.UserBatchUpdate:
+		user := op.User
+		if err := utils.ValidateStruct(ctx, &user); err != nil {
+			return u.batchItemError(item, httpErrors.NewBadRequestError(err.Error()))
+		}
+		updated, err := u.Update(ctx, &user)
+		if err != nil {
+			return u.batchItemError(item, err)
+		}
+		item.Status, item.User = http.StatusOK, updated
+
+	case models.UserBatchDelete:
+		if op.User.ID <= 0 {
+			return u.batchItemError(item, httpErrors.NewRestError(http.StatusBadRequest, errBatchMissingUserID, nil))
+		}
+		if err := u.Delete(ctx, op.User.ID); err != nil {
+			return u.batchItemError(item, err)
+		}
+		item.Status = http.StatusNoContent
+
+	default:
+		return u.batchItemError(item, httpErrors.NewRestError(http.StatusBadRequest, errBatchUnknownOp, op.Op))
+	}
+	return item
+}
+
+// Status and public message of the error, causes of server errors only go to the log like on single requests
+func (u *authUC) batchItemError(item models.UserBatchItemResult, err error) models.UserBatchItemResult {
+	restErr := httpErrors.ParseErrors(err)
+	item.Status = restErr.Status()
+	if item.Status >= http.StatusInternalServerError {
+		u.logger.Errorf("authUC.BatchUsers item %d %s: %v", item.Index, item.Op, err)
+	}
+	item.Error = http.StatusText(item.Status)
+	if parsed, ok := restErr.(httpErrors.RestError); ok && parsed.ErrError != "" {
+		item.Error = parsed.ErrError
+	}
+	return item
+}
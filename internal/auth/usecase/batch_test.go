@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestAuthUC_BatchUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{UserBatch: config.UserBatch{MaxOperations: 5, Concurrency: 2}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), "new@example.com").Return(nil, sql.ErrNoRows)
+	mockAuthRepo.EXPECT().Register(gomock.Any(), gomock.Any(), "employee").DoAndReturn(
+		func(_ context.Context, user *models.User, _ string) (*models.UserWithRole, error) {
+			require.NotEqual(t, "secret", user.Password)
+			return &models.UserWithRole{User: models.User{ID: 10, Username: user.Username, Email: user.Email, Password: user.Password}}, nil
+		})
+	mockAuthRepo.EXPECT().Delete(gomock.Any(), 7).Return(errors.Wrap(sql.ErrNoRows, "rowsAffected"))
+
+	result, err := authUC.BatchUsers(context.Background(), &models.UserBatchRequest{Operations: []models.UserBatchOperation{
+		{Op: models.UserBatchCreate, User: models.User{Username: "new", Email: "new@example.com", Password: "secret"}},
+		{Op: models.UserBatchUpdate, User: models.User{Username: "no id"}},
+		{Op: models.UserBatchDelete, User: models.User{ID: 7}},
+		{Op: "merge", User: models.User{ID: 7}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Succeeded)
+	require.Equal(t, 3, result.Failed)
+
+	statuses := make([]int, 0, len(result.Results))
+	for i, item := range result.Results {
+		require.Equal(t, i, item.Index)
+		statuses = append(statuses, item.Status)
+	}
+	require.Equal(t, []int{http.StatusCreated, http.StatusBadRequest, http.StatusNotFound, http.StatusBadRequest}, statuses)
+	require.Equal(t, 10, result.Results[0].User.ID)
+	require.Empty(t, result.Results[0].User.Password)
+
+	_, err = authUC.BatchUsers(context.Background(), &models.UserBatchRequest{Operations: make([]models.UserBatchOperation, 6)})
+	require.Equal(t, http.StatusRequestEntityTooLarge, httpErrors.ParseErrors(err).Status())
+}
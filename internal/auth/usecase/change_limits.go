@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const changesPrefix = "api-auth-changes:"
+
+// Value of a field changes can be limited for
+func fieldValue(user *models.User, field string) string {
+	switch field {
+	case "username":
+		return user.Username
+	case "email":
+		return user.Email
+	case "phone":
+		return user.Phone
+	case "timezone":
+		return user.Timezone
+	}
+	return ""
+}
+
+// Reserve the changes of limited fields the update makes under reservation, it fails with a ChangeLimitError for
+// the first one over its limit and releases those reserved before it. Administrators are not limited, nor is
+// anyone while FieldChanges is disabled
+func (u *authUC) reserveFieldChanges(ctx context.Context, update *models.User, reservation string, now time.Time) ([]string, error) {
+	limits := u.cfg.FieldChanges.Limits
+	if !u.cfg.FieldChanges.Enabled || len(limits) == 0 {
+		return nil, nil
+	}
+	if actor, err := utils.GetUserFromCtx(ctx); err == nil && actor.Role.Name == "administrator" {
+		return nil, nil
+	}
+
+	fields := make([]string, 0, len(limits))
+	for field := range limits {
+		if fieldValue(update, field) != "" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	sort.Strings(fields)
+
+	current, err := u.authRepo.GetByID(ctx, update.ID)
+	if err != nil {
+		return nil, err
+	}
+	reserved := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if fieldValue(update, field) == fieldValue(&current.User, field) {
+			continue
+		}
+		limit := limits[field]
+		period := time.Duration(limit.PeriodSeconds) * time.Second
+		ok, nextAllowedAt, err := u.redisRepo.ReserveFieldChangeCtx(ctx, changesKey(update.ID, field), reservation, now, period, limit.Max)
+		if err != nil {
+			u.releaseFieldChanges(ctx, update.ID, reserved, reservation)
+			return nil, err
+		}
+		if !ok {
+			u.releaseFieldChanges(ctx, update.ID, reserved, reservation)
+			return nil, auth.NewChangeLimitError(field, nextAllowedAt)
+		}
+		reserved = append(reserved, field)
+	}
+	return reserved, nil
+}
+
+// Release the reserved changes of an update that didn't happen, a failure only tightens the limit and is logged
+func (u *authUC) releaseFieldChanges(ctx context.Context, userID int, fields []string, reservation string) {
+	for _, field := range fields {
+		if err := u.redisRepo.ReleaseFieldChangeCtx(ctx, changesKey(userID, field), reservation); err != nil {
+			u.logger.Errorf("authUC.releaseFieldChanges userID: %d, field: %s, error: %v", userID, field, err)
+		}
+	}
+}
+
+func changesKey(userID int, field string) string {
+	return fmt.Sprintf("%s%d:%s", changesPrefix, userID, field)
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestAuthUC_Update_FieldChanges(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{FieldChanges: config.FieldChanges{Enabled: true, Limits: map[string]config.ChangeLimit{
+		"username": {Max: 1, PeriodSeconds: 30 * 86400},
+		"email":    {Max: 3, PeriodSeconds: 86400},
+	}}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	clk := clock.NewFrozen(time.Now())
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, clk, nil, appLogger)
+
+	current := &models.UserWithRole{User: models.User{ID: 7, Username: "ada", Email: "ada@example.com"}}
+	ctx := requestctx.User.With(context.Background(), current)
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRedisRepo.EXPECT().DeleteByPatternCtx(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// Username changed within the last 30 days
+	nextAllowedAt := clk.Now().Add(20 * 24 * time.Hour)
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).Return(current, nil)
+	mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:username", gomock.Any(), clk.Now(), 30*24*time.Hour, 1).
+		Return(false, nextAllowedAt, nil)
+	_, err := authUC.Update(ctx, &models.User{ID: 7, Username: "lovelace"})
+	require.Equal(t, http.StatusTooManyRequests, httpErrors.ParseErrors(err).Status())
+	limitErr, ok := err.(*auth.ChangeLimitError)
+	require.True(t, ok)
+	require.Equal(t, auth.CodeTooManyChanges, limitErr.Code)
+	require.Equal(t, "username", limitErr.Field)
+	require.True(t, nextAllowedAt.Equal(limitErr.NextAllowedAt))
+
+	// Unchanged fields are not counted, changed ones are reserved before the update
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).Return(current, nil)
+	reserve := mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any(), clk.Now(), 24*time.Hour, 3).
+		Return(true, time.Time{}, nil)
+	mockAuthRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&models.User{ID: 7, Username: "ada", Email: "lace@example.com"}, nil).After(reserve)
+	updated, err := authUC.Update(ctx, &models.User{ID: 7, Username: "ada", Email: " Lace@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "lace@example.com", updated.Email)
+
+	// Reservations of an update that failed are released, including the ones before a refused field
+	var reservation string
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).Return(current, nil)
+	mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any(), clk.Now(), 24*time.Hour, 3).
+		DoAndReturn(func(_ context.Context, _, member string, _ time.Time, _ time.Duration, _ int) (bool, time.Time, error) {
+			reservation = member
+			return true, time.Time{}, nil
+		})
+	mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:username", gomock.Any(), clk.Now(), 30*24*time.Hour, 1).
+		Return(false, nextAllowedAt, nil)
+	mockRedisRepo.EXPECT().ReleaseFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, member string) error {
+			require.Equal(t, reservation, member)
+			return nil
+		})
+	_, err = authUC.Update(ctx, &models.User{ID: 7, Username: "lovelace", Email: "lace@example.com"})
+	require.Error(t, err)
+
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).Return(current, nil)
+	mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any(), clk.Now(), 24*time.Hour, 3).
+		Return(true, time.Time{}, nil)
+	mockAuthRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset"))
+	mockRedisRepo.EXPECT().ReleaseFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any()).Return(nil)
+	_, err = authUC.Update(ctx, &models.User{ID: 7, Email: "lace@example.com"})
+	require.Error(t, err)
+
+	// A failing store fails the update rather than leaving the limit unenforced
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).Return(current, nil)
+	mockRedisRepo.EXPECT().ReserveFieldChangeCtx(gomock.Any(), "api-auth-changes:7:email", gomock.Any(), clk.Now(), 24*time.Hour, 3).
+		Return(false, time.Time{}, errors.New("redis down"))
+	_, err = authUC.Update(ctx, &models.User{ID: 7, Email: "lace@example.com"})
+	require.Error(t, err)
+
+	// Administrators are not limited
+	admin := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 1}, Role: models.Role{Name: "administrator"}})
+	mockAuthRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&models.User{ID: 7, Username: "lovelace"}, nil)
+	_, err = authUC.Update(admin, &models.User{ID: 7, Username: "lovelace"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultDeletionGrace         = 30 * 24 * time.Hour
+	defaultPurgeBatchSize        = 100
+	errNotPendingDeletion        = "Account is not pending deletion"
+	auditActionDeletionRequested = "user.deletion_requested"
+	auditActionDeletionCancel    = "user.deletion_cancelled"
+	auditActionDeleted           = "user.deleted"
+)
+
+// Mark account pending deletion, it is purged once the configured grace period is over
+func (u *authUC) RequestDeletion(ctx context.Context, userID int) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.RequestDeletion")
+	defer span.Finish()
+
+	grace := time.Duration(u.cfg.Deletion.GracePeriodHours) * time.Hour
+	if grace <= 0 {
+		grace = defaultDeletionGrace
+	}
+
+	user, err := u.authRepo.ScheduleDeletion(ctx, userID, grace)
+	if err != nil {
+		return nil, err
+	}
+	u.dropUserCache(ctx, userID)
+	u.recordUserEvent(ctx, auditActionDeletionRequested, userID, &userID, map[string]interface{}{"scheduled_at": user.DeletionScheduledAt})
+
+	user.SanitizePassword()
+	return user, nil
+}
+
+// Cancel pending deletion of the account, 409 when none is pending
+func (u *authUC) CancelDeletion(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.CancelDeletion")
+	defer span.Finish()
+
+	if err := u.authRepo.CancelDeletion(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httpErrors.NewRestErrorWithMessage(http.StatusConflict, errNotPendingDeletion, nil)
+		}
+		return err
+	}
+	u.dropUserCache(ctx, userID)
+	u.recordUserEvent(ctx, auditActionDeletionCancel, userID, &userID, nil)
+	return nil
+}
+
+// Delete one batch of accounts whose grace period is over, returns the number of purged accounts.
+// A dry run returns the number of accounts due in the batch and purges none
+func (u *authUC) PurgeDueDeletions(ctx context.Context, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.PurgeDueDeletions")
+	defer span.Finish()
+
+	limit := u.cfg.Deletion.PurgeBatchSize
+	if limit <= 0 {
+		limit = defaultPurgeBatchSize
+	}
+
+	ids, err := u.authRepo.ListDueForDeletion(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(ids), nil
+	}
+
+	purged := 0
+	for _, userID := range ids {
+		if err := u.authRepo.PurgeScheduled(ctx, userID); err != nil {
+			// Cancelled between listing and purge
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			u.logger.Errorf("authUC.PurgeDueDeletions.PurgeScheduled userID: %d, error: %v", userID, err)
+			continue
+		}
+		purged++
+		u.dropUserCache(ctx, userID)
+		u.recordUserEvent(ctx, auditActionDeleted, userID, nil, map[string]string{"reason": "scheduled"})
+	}
+	if purged > 0 {
+		u.invalidateUsersLists(ctx)
+	}
+	return purged, nil
+}
+
+func (u *authUC) dropUserCache(ctx context.Context, userID int) {
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID)); err != nil {
+		u.logger.Errorf("authUC.dropUserCache.DeleteUserCtx: %s", err)
+	}
+}
+
+// Audit account lifecycle events, actor is nil for the scheduled purge
+func (u *authUC) recordUserEvent(ctx context.Context, action string, userID int, actorID *int, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	if err := u.auditUC.Record(ctx, action, &models.AuditEvent{
+		ActorID:   actorID,
+		RequestID: requestID,
+		Resource:  "user:" + strconv.Itoa(userID),
+	}, metadata); err != nil {
+		u.logger.Errorf("authUC.recordUserEvent.Record action: %s, error: %v", action, err)
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestAuthUC_RequestDeletion(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Deletion: config.Deletion{GracePeriodHours: 48}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	scheduledAt := time.Now().Add(48 * time.Hour)
+	mockAuthRepo.EXPECT().ScheduleDeletion(gomock.Any(), 7, 48*time.Hour).Return(&models.User{
+		ID:                  7,
+		Password:            "hash",
+		Status:              models.UserStatusPendingDeletion,
+		DeletionScheduledAt: &scheduledAt,
+	}, nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), "api-auth:: 7").Return(nil)
+
+	user, err := authUC.RequestDeletion(context.Background(), 7)
+	require.NoError(t, err)
+	require.True(t, user.PendingDeletion())
+	require.Empty(t, user.Password)
+}
+
+func TestAuthUC_CancelDeletion_NotPending(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(&config.Config{}, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	mockAuthRepo.EXPECT().CancelDeletion(gomock.Any(), 7).Return(errors.Wrap(sql.ErrNoRows, "rowsAffected"))
+
+	err := authUC.CancelDeletion(context.Background(), 7)
+	var restErr httpErrors.RestErr
+	require.True(t, errors.As(err, &restErr))
+	require.Equal(t, http.StatusConflict, restErr.Status())
+}
+
+func TestAuthUC_PurgeDueDeletions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Deletion: config.Deletion{PurgeBatchSize: 10}, Cache: config.Cache{ListTTL: 30}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	mockAuthRepo.EXPECT().ListDueForDeletion(gomock.Any(), 10).Return([]int{1, 2}, nil)
+	mockAuthRepo.EXPECT().PurgeScheduled(gomock.Any(), 1).Return(nil)
+	// Cancelled after listing
+	mockAuthRepo.EXPECT().PurgeScheduled(gomock.Any(), 2).Return(errors.Wrap(sql.ErrNoRows, "rowsAffected"))
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), "api-auth:: 1").Return(nil)
+	mockRedisRepo.EXPECT().DeleteByPatternCtx(gomock.Any(), "api-auth:list:*").Return(nil)
+
+	purged, err := authUC.PurgeDueDeletions(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	// Dry run counts the due accounts and purges none
+	mockAuthRepo.EXPECT().ListDueForDeletion(gomock.Any(), 10).Return([]int{3, 4, 5}, nil)
+	due, err := authUC.PurgeDueDeletions(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, 3, due)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Change password after checking the current one, the new one must differ from it
+func (u *authUC) ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ChangePassword")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err)
+	}
+
+	user, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = user.User.ComparePasswords(req.CurrentPassword); err != nil {
+		return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.ChangePassword.ComparePasswords"))
+	}
+	newPassword := strings.TrimSpace(req.NewPassword)
+	if user.User.ComparePasswords(newPassword) == nil {
+		return httpErrors.NewBadRequestError("new password must differ from the current one")
+	}
+
+	user.User.Password = newPassword
+	if err = user.User.HashPassword(); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangePassword.HashPassword"))
+	}
+	if err = u.authRepo.UpdatePassword(ctx, userID, user.User.Password); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID)); err != nil {
+		u.logger.Errorf("AuthUC.ChangePassword.DeleteUserCtx: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestAuthUC_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(&config.Config{}, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	stored := models.User{ID: 7, Password: "old-password"}
+	require.NoError(t, stored.HashPassword())
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), 7).DoAndReturn(func(context.Context, int) (*models.UserWithRole, error) {
+		return &models.UserWithRole{User: stored}, nil
+	}).Times(3)
+
+	var newHash string
+	mockAuthRepo.EXPECT().UpdatePassword(gomock.Any(), 7, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, hash string) error {
+		newHash = hash
+		return nil
+	})
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), "api-auth:: 7").Return(nil)
+
+	ctx := context.Background()
+	require.NoError(t, authUC.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"}))
+	require.NoError(t, (&models.User{Password: newHash}).ComparePasswords("new-password"))
+
+	err := authUC.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password"})
+	require.Equal(t, http.StatusUnauthorized, httpErrors.ParseErrors(err).Status())
+	err = authUC.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "old-password"})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	err = authUC.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "short"})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+}
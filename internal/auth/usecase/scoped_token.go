@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultScopedTokenTTL   = 5 * time.Minute
+	defaultScopedTokenMax   = 15 * time.Minute
+	auditActionTokenScoped  = "user.token_exchanged"
+	scopedTokenTypeResponse = "Bearer"
+)
+
+// Exchange the session of userID for a short lived token limited to the requested scopes
+func (u *authUC) IssueScopedToken(ctx context.Context, userID int, req *dto.TokenExchangeRequest) (*models.ScopedToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.IssueScopedToken")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	expiresAt := time.Now().Add(u.scopedTokenTTL(req.TTLSeconds)).UTC()
+	token, err := utils.GenerateScopedJWTToken(userID, scopes, expiresAt, u.cfg)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.IssueScopedToken.GenerateScopedJWTToken"))
+	}
+
+	scope := strings.Join(scopes, " ")
+	u.recordUserEvent(ctx, auditActionTokenScoped, userID, &userID, map[string]interface{}{"scope": scope, "expires_at": expiresAt})
+
+	return &models.ScopedToken{
+		Token:     token,
+		TokenType: scopedTokenTypeResponse,
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Requested lifetime, config default when none and never above the configured maximum
+func (u *authUC) scopedTokenTTL(requestedSeconds int) time.Duration {
+	ttl := time.Duration(u.cfg.ScopedTokens.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultScopedTokenTTL
+	}
+	maxTTL := time.Duration(u.cfg.ScopedTokens.MaxTTLSeconds) * time.Second
+	if maxTTL <= 0 {
+		maxTTL = defaultScopedTokenMax
+	}
+
+	if requestedSeconds > 0 {
+		ttl = time.Duration(requestedSeconds) * time.Second
+	}
+	if ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return ttl
+}
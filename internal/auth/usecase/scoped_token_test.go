@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestAuthUC_IssueScopedToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server:       config.ServerConfig{JwtSecretKey: "secret"},
+		ScopedTokens: config.ScopedTokens{TTLSeconds: 60, MaxTTLSeconds: 120},
+	}
+	authUC := NewAuthUseCase(cfg, mock.NewMockRepository(ctrl), mock.NewMockRedisRepository(ctrl), nil, nil, nil, nil, nil, nil, nil)
+
+	scoped, err := authUC.IssueScopedToken(context.Background(), 7, &dto.TokenExchangeRequest{
+		Scopes:     []string{models.ScopeReadProfile, models.ScopeReadProfile},
+		TTLSeconds: 3600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ScopeReadProfile, scoped.Scope)
+	require.WithinDuration(t, time.Now().Add(2*time.Minute), scoped.ExpiresAt, 5*time.Second)
+
+	claims, err := utils.ParseScopedJWTToken(scoped.Token, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "7", claims.ID)
+	require.True(t, claims.HasScope(models.ScopeReadProfile))
+	require.False(t, claims.HasScope(models.ScopeReadContacts))
+
+	_, err = authUC.IssueScopedToken(context.Background(), 7, &dto.TokenExchangeRequest{Scopes: []string{"write:profile"}})
+	require.Error(t, err)
+}
@@ -3,23 +3,43 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/dedup"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
 const (
-	basePrefix    = "api-auth:"
-	cacheDuration = 3600
+	basePrefix         = "api-auth:"
+	cacheDuration      = 3600
+	listRefreshTimeout = 10 * time.Second
+
+	usersListCache   = "users_list"
+	cacheResultFresh = "fresh"
+	cacheResultStale = "stale"
+	cacheResultMiss  = "miss"
+
+	// Keys of config Pagination.Counts
+	paginationUsers       = "users"
+	paginationUsersSearch = "users_search"
 )
 
 // Auth UseCase
@@ -27,12 +47,47 @@ type authUC struct {
 	cfg       *config.Config
 	authRepo  auth.Repository
 	redisRepo auth.RedisRepository
+	auditUC   audit.UseCase
+	emailPol  emailpolicy.UseCase
+	settings  settings.UseCase
+	billingUC billing.UseCase
+	clock     clock.Clock
+	metrics   metric.Metrics
 	logger    logger.Logger
+
+	getByIDGroup  *dedup.Group
+	getUsersGroup *dedup.Group
+	// Users list keys with a background refresh in flight
+	refreshing sync.Map
 }
 
-// Auth UseCase constructor
-func NewAuthUseCase(cfg *config.Config, authRepo auth.Repository, redisRepo auth.RedisRepository, log logger.Logger) auth.UseCase {
-	return &authUC{cfg: cfg, authRepo: authRepo, redisRepo: redisRepo, logger: log}
+// Auth UseCase constructor, auditUC, emailPolicy, settingsUC, billingUC and metrics may be nil
+func NewAuthUseCase(
+	cfg *config.Config,
+	authRepo auth.Repository,
+	redisRepo auth.RedisRepository,
+	auditUC audit.UseCase,
+	emailPolicy emailpolicy.UseCase,
+	settingsUC settings.UseCase,
+	billingUC billing.UseCase,
+	clk clock.Clock,
+	metrics metric.Metrics,
+	log logger.Logger,
+) auth.UseCase {
+	return &authUC{
+		cfg:           cfg,
+		authRepo:      authRepo,
+		redisRepo:     redisRepo,
+		auditUC:       auditUC,
+		emailPol:      emailPolicy,
+		settings:      settingsUC,
+		billingUC:     billingUC,
+		clock:         clk,
+		metrics:       metrics,
+		logger:        log,
+		getByIDGroup:  dedup.NewGroup("getByID", cfg.Dedup.GetByID, metrics),
+		getUsersGroup: dedup.NewGroup("getUsers", cfg.Dedup.GetUsers, metrics),
+	}
 }
 
 // Create new user
@@ -40,9 +95,31 @@ func (u *authUC) Register(ctx context.Context, user *dto.RegisterUserRequest) (*
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Register")
 	defer span.Finish()
 
+	createdUser, err := u.register(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateJWTToken(createdUser, u.cfg)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Register.GenerateJWTToken"))
+	}
+
+	return &models.UserWithToken{
+		User:  &createdUser.User,
+		Token: token,
+	}, nil
+}
+
+// Create user without issuing a token, shared by registration and admin batch writes
+func (u *authUC) register(ctx context.Context, user *dto.RegisterUserRequest) (*models.UserWithRole, error) {
+	if err := u.checkEmailPolicy(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
 	existsUser, err := u.authRepo.FindByEmail(ctx, user.Email)
 	if existsUser != nil || err == nil {
-		return nil, httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrEmailAlreadyExists, nil)
+		return nil, auth.ErrEmailAlreadyRegistered
 	}
 
 	userModel := &models.User{
@@ -55,21 +132,32 @@ func (u *authUC) Register(ctx context.Context, user *dto.RegisterUserRequest) (*
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.Register.PrepareCreate"))
 	}
 
-	createdUser, err := u.authRepo.Register(ctx, userModel)
+	roleName := user.RoleName
+	if roleName == "" {
+		if roleName, err = u.defaultRole(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	createdUser, err := u.authRepo.Register(ctx, userModel, roleName)
 	if err != nil {
 		return nil, err
 	}
 	createdUser.User.SanitizePassword()
+	u.invalidateUsersLists(ctx)
+	return createdUser, nil
+}
 
-	token, err := utils.GenerateJWTToken(createdUser, u.cfg)
+// Role of new users, the default_role setting when settings are wired
+func (u *authUC) defaultRole(ctx context.Context) (string, error) {
+	if u.settings == nil {
+		return models.DefaultSettings(u.cfg.Registration.InviteOnly).DefaultRole, nil
+	}
+	current, err := u.settings.Current(ctx)
 	if err != nil {
-		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Register.GenerateJWTToken"))
+		return "", err
 	}
-
-	return &models.UserWithToken{
-		User:  &createdUser.User,
-		Token: token,
-	}, nil
+	return current.DefaultRole, nil
 }
 
 // Update existing user
@@ -80,9 +168,15 @@ func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, e
 	if err := user.PrepareUpdate(); err != nil {
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.Register.PrepareUpdate"))
 	}
+	reservation := uuid.NewString()
+	reserved, err := u.reserveFieldChanges(ctx, user, reservation, u.clock.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	updatedUser, err := u.authRepo.Update(ctx, user)
 	if err != nil {
+		u.releaseFieldChanges(ctx, user.ID, reserved, reservation)
 		return nil, err
 	}
 
@@ -91,6 +185,7 @@ func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, e
 	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(user.ID)); err != nil {
 		u.logger.Errorf("AuthUC.Update.DeleteUserCtx: %s", err)
 	}
+	u.invalidateUsersLists(ctx)
 
 	updatedUser.SanitizePassword()
 
@@ -109,6 +204,7 @@ func (u *authUC) Delete(ctx context.Context, userID int) error {
 	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID)); err != nil {
 		u.logger.Errorf("AuthUC.Delete.DeleteUserCtx: %s", err)
 	}
+	u.invalidateUsersLists(ctx)
 
 	return nil
 }
@@ -118,6 +214,35 @@ func (u *authUC) GetByID(ctx context.Context, userID int) (*models.UserWithRole,
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetByID")
 	defer span.Finish()
 
+	v, shared, err := u.getByIDGroup.Do(ctx, u.GenerateUserKey(userID), func(ctx context.Context) (interface{}, error) {
+		return u.getByID(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user := v.(*models.UserWithRole)
+	if shared {
+		// Every caller gets its own copy, the shared value may be touched by other requests
+		copied := *user
+		return &copied, nil
+	}
+	return user, nil
+}
+
+// Get user with role by email, used to map external identities onto local users
+func (u *authUC) GetByEmail(ctx context.Context, email string) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetByEmail")
+	defer span.Finish()
+
+	user, err := u.authRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return u.GetByID(ctx, user.ID)
+}
+
+func (u *authUC) getByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
 	cachedUser, err := u.redisRepo.GetByIDCtx(ctx, u.GenerateUserKey(userID))
 	if err != nil {
 		u.logger.Errorf("authUC.GetByID.GetByIDCtx: %v", err)
@@ -140,20 +265,164 @@ func (u *authUC) GetByID(ctx context.Context, userID int) (*models.UserWithRole,
 	return user, nil
 }
 
+// Check password of an existing user, bypasses the user cache
+func (u *authUC) VerifyPassword(ctx context.Context, userID int, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.VerifyPassword")
+	defer span.Finish()
+
+	user, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = user.User.ComparePasswords(password); err != nil {
+		return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.VerifyPassword.ComparePasswords"))
+	}
+	return nil
+}
+
 // Find users by name
 func (u *authUC) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.FindByName")
 	defer span.Finish()
 
+	// Callers may pick the strategy, e.g. canary variants trying another one
+	if query.Count == "" {
+		query.Count = u.countStrategy(paginationUsersSearch)
+	}
+	organizationID, scoped := scopedOrganizationID(ctx)
+	if scoped && organizationID == 0 {
+		return emptyUsersList(query), nil
+	}
+	query.OrganizationID = organizationID
 	return u.authRepo.FindByName(ctx, name, query)
 }
 
-// Get users with pagination
+// Get users with pagination, cached pages are fresh for ListTTL seconds, then served stale for up to
+// ListStaleSeconds while a background refresh repopulates them
 func (u *authUC) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetUsers")
 	defer span.Finish()
 
-	return u.authRepo.GetUsers(ctx, pq)
+	pq.Count = u.countStrategy(paginationUsers)
+	// Organization listings are small and change with memberships, they skip the list cache
+	if organizationID, scoped := scopedOrganizationID(ctx); scoped {
+		if organizationID == 0 {
+			return emptyUsersList(pq), nil
+		}
+		pq.OrganizationID = organizationID
+		return u.authRepo.GetUsers(ctx, pq)
+	}
+	key := u.generateUsersListKey(pq)
+	if u.cfg.Cache.ListTTL > 0 {
+		cached, err := u.redisRepo.GetUsersListCtx(ctx, key)
+		if err != nil {
+			u.logger.Errorf("authUC.GetUsers.GetUsersListCtx: %v", err)
+		}
+		if cached != nil {
+			if time.Since(cached.CachedAt) < time.Duration(u.cfg.Cache.ListTTL)*time.Second {
+				u.countCacheLookup(cacheResultFresh)
+				return cached.List, nil
+			}
+			u.countCacheLookup(cacheResultStale)
+			u.refreshUsersList(ctx, key, pq)
+			return cached.List, nil
+		}
+		u.countCacheLookup(cacheResultMiss)
+	}
+
+	v, shared, err := u.getUsersGroup.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return u.loadUsersList(ctx, key, pq)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := v.(*models.UsersList)
+	if shared {
+		copied := *list
+		copied.Users = make([]*models.User, len(list.Users))
+		for i, user := range list.Users {
+			userCopy := *user
+			copied.Users[i] = &userCopy
+		}
+		return &copied, nil
+	}
+	return list, nil
+}
+
+// Query users page and cache it, the redis entry outlives ListTTL by the staleness budget
+func (u *authUC) loadUsersList(ctx context.Context, key string, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	list, err := u.authRepo.GetUsers(ctx, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.cfg.Cache.ListTTL > 0 {
+		cached := &models.CachedUsersList{List: list, CachedAt: time.Now()}
+		if err = u.redisRepo.SetUsersListCtx(ctx, key, u.cfg.Cache.ListTTL+u.cfg.Cache.ListStaleSeconds, cached); err != nil {
+			u.logger.Errorf("authUC.loadUsersList.SetUsersListCtx: %v", err)
+		}
+	}
+	return list, nil
+}
+
+// Repopulate a stale users page in the background, at most one refresh per key runs at a time
+func (u *authUC) refreshUsersList(ctx context.Context, key string, pq *utils.PaginationQuery) {
+	if _, running := u.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	query := *pq
+	go func() {
+		defer u.refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), listRefreshTimeout)
+		defer cancel()
+
+		if _, _, err := u.getUsersGroup.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+			return u.loadUsersList(ctx, key, &query)
+		}); err != nil {
+			u.logger.Errorf("authUC.refreshUsersList: %v", err)
+		}
+	}()
+}
+
+// Drop cached users pages after a write, readers would otherwise see the old page for up to ListTTL
+func (u *authUC) invalidateUsersLists(ctx context.Context) {
+	if u.cfg.Cache.ListTTL <= 0 {
+		return
+	}
+	if err := u.redisRepo.DeleteByPatternCtx(ctx, basePrefix+"list:*"); err != nil {
+		u.logger.Errorf("authUC.invalidateUsersLists.DeleteByPatternCtx: %v", err)
+	}
+}
+
+// Refuse signups the email domain policy rejects, counted by reason
+func (u *authUC) checkEmailPolicy(ctx context.Context, email string) error {
+	if u.emailPol == nil {
+		return nil
+	}
+
+	decision, err := u.emailPol.Check(ctx, email)
+	if err != nil {
+		return err
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	u.logger.Infof("authUC.Register email domain %s rejected, reason: %s, rule: %s", decision.Domain, decision.Reason, decision.Rule)
+	if u.metrics != nil {
+		u.metrics.IncSignupRejections(decision.Reason)
+	}
+	return emailpolicy.DecisionError(decision)
+}
+
+func (u *authUC) countCacheLookup(result string) {
+	if u.metrics != nil {
+		u.metrics.IncCacheLookups(usersListCache, result)
+	}
 }
 
 // Login user, returns user model with jwt token
@@ -177,10 +446,68 @@ func (u *authUC) Login(ctx context.Context, user *dto.LoginUserRequest) (*models
 		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.GetUsers.GenerateJWTToken"))
 	}
 
-	return &models.UserWithToken{
+	return u.withAccess(ctx, foundUser, &models.UserWithToken{
 		User:  &foundUser.User,
 		Token: token,
-	}, nil
+	}), nil
+}
+
+// Issue jwt token for an already authenticated user, used once a second factor is passed
+func (u *authUC) IssueToken(ctx context.Context, userID int) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.IssueToken")
+	defer span.Finish()
+
+	foundUser, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	foundUser.User.SanitizePassword()
+
+	token, err := utils.GenerateJWTToken(foundUser, u.cfg)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.IssueToken.GenerateJWTToken"))
+	}
+
+	return u.withAccess(ctx, foundUser, &models.UserWithToken{
+		User:  &foundUser.User,
+		Token: token,
+	}), nil
+}
+
+// Store phone number verified by SMS code
+func (u *authUC) SetPhoneVerified(ctx context.Context, userID int, phone string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.SetPhoneVerified")
+	defer span.Finish()
+
+	updatedUser, err := u.authRepo.SetPhoneVerified(ctx, userID, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID)); err != nil {
+		u.logger.Errorf("AuthUC.SetPhoneVerified.DeleteUserCtx: %s", err)
+	}
+	u.invalidateUsersLists(ctx)
+
+	updatedUser.SanitizePassword()
+
+	return updatedUser, nil
+}
+
+// Toggle SMS second factor, enabling fails with sql.ErrNoRows unless the phone is verified
+func (u *authUC) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.SetSMS2FA")
+	defer span.Finish()
+
+	if err := u.authRepo.SetSMS2FA(ctx, userID, enabled); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID)); err != nil {
+		u.logger.Errorf("AuthUC.SetSMS2FA.DeleteUserCtx: %s", err)
+	}
+	return nil
 }
 
 // Upload user avatar
@@ -200,6 +527,50 @@ func (u *authUC) UploadAvatar(ctx context.Context, userID int, file models.Uploa
 	return updatedUser, nil
 }
 
+// Drop cached user, used by the change feed
+func (u *authUC) InvalidateUserCache(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.InvalidateUserCache")
+	defer span.Finish()
+
+	return u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID))
+}
+
+// Drop every cached user, used by the change feed
+func (u *authUC) InvalidateUsersCache(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.InvalidateUsersCache")
+	defer span.Finish()
+
+	return u.redisRepo.DeleteByPatternCtx(ctx, basePrefix+"*")
+}
+
 func (u *authUC) GenerateUserKey(userID int) string {
 	return fmt.Sprintf("%s: %d", basePrefix, userID)
 }
+
+// Configured count strategy of a listing endpoint, endpoints not listed count exactly
+func (u *authUC) countStrategy(endpoint string) utils.CountStrategy {
+	return utils.ParseCountStrategy(u.cfg.Pagination.Counts[endpoint])
+}
+
+// Users list keys share basePrefix so InvalidateUsersCache drops them as well
+func (u *authUC) generateUsersListKey(pq *utils.PaginationQuery) string {
+	return fmt.Sprintf("%slist:%s", basePrefix, pq.GetQueryString())
+}
+
+// Organization the request is scoped to by the organization scope middleware, zero for callers outside any
+func scopedOrganizationID(ctx context.Context) (int64, bool) {
+	if membership, ok := requestctx.Organization.From(ctx); ok && membership != nil {
+		return membership.OrganizationID, true
+	}
+	return 0, false
+}
+
+// Listing of callers scoped to no organization
+func emptyUsersList(pq *utils.PaginationQuery) *models.UsersList {
+	return &models.UsersList{
+		Page:          pq.GetPage(),
+		Size:          pq.GetSize(),
+		CountStrategy: string(pq.GetCount()),
+		Users:         make([]*models.User, 0),
+	}
+}
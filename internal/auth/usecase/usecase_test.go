@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestAuthUC_GetUsers_StaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Cache: config.Cache{ListTTL: 30, ListStaleSeconds: 120}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	pq := &utils.PaginationQuery{Page: 1, Size: 10}
+	key := "api-auth:list:" + pq.GetQueryString()
+	fresh := &models.UsersList{TotalCount: 2}
+	stale := &models.UsersList{TotalCount: 1}
+
+	// Fresh hit never reaches the database
+	mockRedisRepo.EXPECT().GetUsersListCtx(gomock.Any(), key).
+		Return(&models.CachedUsersList{List: fresh, CachedAt: time.Now()}, nil)
+	list, err := authUC.GetUsers(ctx, pq)
+	require.NoError(t, err)
+	require.Equal(t, 2, list.TotalCount)
+
+	// Stale hit is served at once and refreshed in the background
+	refreshed := make(chan struct{})
+	mockRedisRepo.EXPECT().GetUsersListCtx(gomock.Any(), key).
+		Return(&models.CachedUsersList{List: stale, CachedAt: time.Now().Add(-time.Minute)}, nil)
+	mockAuthRepo.EXPECT().GetUsers(gomock.Any(), gomock.Eq(pq)).Return(fresh, nil)
+	mockRedisRepo.EXPECT().SetUsersListCtx(gomock.Any(), key, 150, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, cached *models.CachedUsersList) error {
+			require.Equal(t, fresh, cached.List)
+			close(refreshed)
+			return nil
+		})
+
+	list, err = authUC.GetUsers(ctx, pq)
+	require.NoError(t, err)
+	require.Equal(t, 1, list.TotalCount)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale users list was not refreshed")
+	}
+}
+
+func TestAuthUC_GetUsers_Miss(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Cache: config.Cache{ListTTL: 30}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	pq := &utils.PaginationQuery{Page: 1, Size: 10}
+	key := "api-auth:list:" + pq.GetQueryString()
+	users := &models.UsersList{TotalCount: 3}
+
+	mockRedisRepo.EXPECT().GetUsersListCtx(gomock.Any(), key).Return(nil, nil)
+	mockAuthRepo.EXPECT().GetUsers(gomock.Any(), gomock.Eq(pq)).Return(users, nil)
+	mockRedisRepo.EXPECT().SetUsersListCtx(gomock.Any(), key, 30, gomock.Any()).Return(nil)
+
+	list, err := authUC.GetUsers(context.Background(), pq)
+	require.NoError(t, err)
+	require.Equal(t, 3, list.TotalCount)
+}
+
+func TestAuthUC_GetUsers_OrganizationScope(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Cache: config.Cache{ListTTL: 30}}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+
+	ctx := requestctx.Organization.With(context.Background(), &models.OrganizationMember{OrganizationID: 7, UserID: 1})
+	users := &models.UsersList{TotalCount: 2}
+
+	// Scoped listings bypass the list cache shared by every caller
+	mockAuthRepo.EXPECT().GetUsers(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+			require.Equal(t, int64(7), pq.OrganizationID)
+			return users, nil
+		})
+	list, err := authUC.GetUsers(ctx, &utils.PaginationQuery{Page: 1, Size: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, list.TotalCount)
+
+	mockAuthRepo.EXPECT().FindByName(gomock.Any(), "jo", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, pq *utils.PaginationQuery) (*models.UsersList, error) {
+			require.Equal(t, int64(7), pq.OrganizationID)
+			return users, nil
+		})
+	_, err = authUC.FindByName(ctx, "jo", &utils.PaginationQuery{Page: 1, Size: 10})
+	require.NoError(t, err)
+
+	// A strategy picked by the caller is kept
+	mockAuthRepo.EXPECT().FindByName(gomock.Any(), "jo", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, pq *utils.PaginationQuery) (*models.UsersList, error) {
+			require.Equal(t, utils.CountNone, pq.Count)
+			return users, nil
+		})
+	_, err = authUC.FindByName(ctx, "jo", &utils.PaginationQuery{Page: 1, Size: 10, Count: utils.CountNone})
+	require.NoError(t, err)
+
+	// Callers outside any organization see no one
+	outside := requestctx.Organization.With(context.Background(), &models.OrganizationMember{UserID: 2})
+	list, err = authUC.GetUsers(outside, &utils.PaginationQuery{Page: 1, Size: 10})
+	require.NoError(t, err)
+	require.Empty(t, list.Users)
+	require.Zero(t, list.TotalCount)
+	list, err = authUC.FindByName(outside, "jo", &utils.PaginationQuery{Page: 1, Size: 10})
+	require.NoError(t, err)
+	require.Empty(t, list.Users)
+}
+
+func TestAuthUC_Register_EmailAlreadyRegistered(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, nil, nil, nil)
+	request := &dto.RegisterUserRequest{Username: "ann", Email: "ann@example.com", Password: "secret123"}
+
+	// Existing user
+	mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), request.Email).Return(&models.User{ID: 3, Email: request.Email}, nil)
+	_, err := authUC.Register(context.Background(), request)
+	require.ErrorIs(t, err, auth.ErrEmailAlreadyRegistered)
+
+	// Concurrent signup committed between the check and the insert
+	mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), request.Email).Return(nil, sql.ErrNoRows)
+	mockAuthRepo.EXPECT().Register(gomock.Any(), gomock.Any(), "employee").Return(nil, errors.Wrap(auth.ErrEmailAlreadyRegistered, "authRepo.Register.CreateUser"))
+	_, err = authUC.Register(context.Background(), request)
+	restErr := httpErrors.ParseErrors(err)
+	require.Equal(t, http.StatusConflict, restErr.Status())
+	require.Equal(t, auth.CodeEmailAlreadyRegistered, restErr.(*auth.RegistrationConflictError).Code)
+}
@@ -0,0 +1,9 @@
+package billing
+
+import "github.com/labstack/echo/v4"
+
+// Billing HTTP Handlers interface
+type Handlers interface {
+	Webhook() echo.HandlerFunc
+	ListEntitlements() echo.HandlerFunc
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Billing handlers
+type billingHandlers struct {
+	cfg       *config.Config
+	billingUC billing.UseCase
+	logger    logger.Logger
+}
+
+// NewBillingHandlers Billing handlers constructor
+func NewBillingHandlers(cfg *config.Config, billingUC billing.UseCase, log logger.Logger) billing.Handlers {
+	return &billingHandlers{cfg: cfg, billingUC: billingUC, logger: log}
+}
+
+// Webhook godoc
+// @Summary Billing provider webhook
+// @Description Subscription events of the billing provider, signed over the raw body. Each event is applied once,
+// @Description redeliveries answer 200 with the duplicate outcome
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.BillingEventResult
+// @Failure 400 {object} httpErrors.RestError
+// @Router /billing/webhooks [post]
+func (h *billingHandlers) Webhook() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "billingHandlers.Webhook")
+		defer span.Finish()
+
+		payload, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		result, err := h.billingUC.HandleWebhook(ctx, payload, c.Request().Header.Get(h.cfg.Billing.SignatureHeader))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// ListEntitlements godoc
+// @Summary List entitlements
+// @Description Billing entitlements of the current user and of their organization, including ended ones
+// @Tags Billing
+// @Produce json
+// @Success 200 {array} models.Entitlement
+// @Failure 401 {object} httpErrors.RestError
+// @Router /billing/entitlements [get]
+func (h *billingHandlers) ListEntitlements() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "billingHandlers.ListEntitlements")
+		defer span.Finish()
+
+		entitlements, err := h.billingUC.ListEntitlements(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, entitlements)
+	}
+}
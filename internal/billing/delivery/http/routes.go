@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map billing routes, the webhook is authenticated by its signature instead of a session
+func MapBillingRoutes(billingGroup *echo.Group, h billing.Handlers, mw *middleware.MiddlewareManager) {
+	billingGroup.POST("/webhooks", h.Webhook())
+	billingGroup.GET("/entitlements", h.ListEntitlements(), mw.AuthSessionMiddleware)
+}
@@ -0,0 +1,21 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package billing
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Billing Repository interface, processed provider events and the entitlements they produced
+type Repository interface {
+	EventProcessed(ctx context.Context, eventID string) (bool, error)
+	// Record event as processed, recording it again is a no-op
+	RecordEvent(ctx context.Context, eventID string, eventType string, now time.Time) error
+	// Insert or update the entitlement of its subscription unless a newer event already changed it, applied is
+	// false then and the stored entitlement is returned. sql.ErrNoRows when its organization or user is unknown
+	UpsertEntitlement(ctx context.Context, entitlement *models.Entitlement) (stored *models.Entitlement, applied bool, err error)
+	// Entitlements held by the user or by the organization, organizationID is 0 for none
+	ListEntitlements(ctx context.Context, userID int, organizationID int64) ([]*models.Entitlement, error)
+}
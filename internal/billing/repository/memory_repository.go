@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Billing Repository kept in process memory, dev mode stand-in for Postgres. Subjects are not checked
+type billingMemoryRepo struct {
+	mu           sync.RWMutex
+	lastID       int64
+	events       map[string]bool
+	entitlements map[string]*models.Entitlement
+}
+
+// Billing in-memory Repository constructor
+func NewBillingMemoryRepository() billing.Repository {
+	return &billingMemoryRepo{events: make(map[string]bool), entitlements: make(map[string]*models.Entitlement)}
+}
+
+// Has the event been processed
+func (r *billingMemoryRepo) EventProcessed(ctx context.Context, eventID string) (bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "billingMemoryRepo.EventProcessed")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.events[eventID], nil
+}
+
+// Record event as processed
+func (r *billingMemoryRepo) RecordEvent(ctx context.Context, eventID string, _ string, _ time.Time) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "billingMemoryRepo.RecordEvent")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[eventID] = true
+	return nil
+}
+
+// Insert or update the entitlement of its subscription unless a newer event already changed it
+func (r *billingMemoryRepo) UpsertEntitlement(ctx context.Context, entitlement *models.Entitlement) (*models.Entitlement, bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "billingMemoryRepo.UpsertEntitlement")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	stored, ok := r.entitlements[entitlement.SubscriptionID]
+	if ok && stored.EventAt.After(entitlement.EventAt) {
+		copied := *stored
+		return &copied, false, nil
+	}
+
+	updated := *entitlement
+	if ok {
+		updated.ID = stored.ID
+		updated.CreatedAt = stored.CreatedAt
+	} else {
+		r.lastID++
+		updated.ID = r.lastID
+		updated.CreatedAt = now
+	}
+	updated.UpdatedAt = now
+	r.entitlements[updated.SubscriptionID] = &updated
+	copied := updated
+	return &copied, true, nil
+}
+
+// Entitlements held by the user or by the organization
+func (r *billingMemoryRepo) ListEntitlements(ctx context.Context, userID int, organizationID int64) ([]*models.Entitlement, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "billingMemoryRepo.ListEntitlements")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entitlements := make([]*models.Entitlement, 0)
+	for _, stored := range r.entitlements {
+		if (stored.UserID != nil && *stored.UserID == userID) ||
+			(stored.OrganizationID != nil && *stored.OrganizationID == organizationID) {
+			copied := *stored
+			entitlements = append(entitlements, &copied)
+		}
+	}
+	sort.Slice(entitlements, func(i, j int) bool { return entitlements[i].ID < entitlements[j].ID })
+	return entitlements, nil
+}
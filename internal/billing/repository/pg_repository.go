@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Billing Repository
+type billingRepo struct {
+	db *sqlx.DB
+}
+
+// Billing Repository constructor
+func NewBillingRepository(db *sqlx.DB) billing.Repository {
+	return &billingRepo{db: db}
+}
+
+// Has the event been processed
+func (r *billingRepo) EventProcessed(ctx context.Context, eventID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingRepo.EventProcessed")
+	defer span.Finish()
+
+	var processed bool
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, &processed, eventProcessedQuery, eventID); err != nil {
+		return false, errors.Wrap(err, "billingRepo.EventProcessed.GetContext")
+	}
+	return processed, nil
+}
+
+// Record event as processed
+func (r *billingRepo) RecordEvent(ctx context.Context, eventID string, eventType string, now time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingRepo.RecordEvent")
+	defer span.Finish()
+
+	if _, err := tenant.DB(ctx, r.db).ExecContext(ctx, recordEventQuery, eventID, eventType, now); err != nil {
+		return errors.Wrap(err, "billingRepo.RecordEvent.ExecContext")
+	}
+	return nil
+}
+
+// Insert or update the entitlement of its subscription unless a newer event already changed it
+func (r *billingRepo) UpsertEntitlement(ctx context.Context, entitlement *models.Entitlement) (*models.Entitlement, bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingRepo.UpsertEntitlement")
+	defer span.Finish()
+
+	stored := &models.Entitlement{}
+	err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		upsertEntitlementQuery,
+		entitlement.SubscriptionID,
+		entitlement.CustomerID,
+		entitlement.OrganizationID,
+		entitlement.UserID,
+		entitlement.Plan,
+		entitlement.Status,
+		entitlement.CurrentPeriodEnd,
+		entitlement.EventAt,
+	).StructScan(stored)
+	if err == nil {
+		return stored, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, errors.Wrap(err, "billingRepo.UpsertEntitlement.StructScan")
+	}
+
+	// Nothing written, either a newer event changed the subscription or its subject is unknown
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, stored, getEntitlementBySubscriptionQuery, entitlement.SubscriptionID); err != nil {
+		return nil, false, errors.Wrap(err, "billingRepo.UpsertEntitlement.GetContext")
+	}
+	return stored, false, nil
+}
+
+// Entitlements held by the user or by the organization
+func (r *billingRepo) ListEntitlements(ctx context.Context, userID int, organizationID int64) ([]*models.Entitlement, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingRepo.ListEntitlements")
+	defer span.Finish()
+
+	entitlements := make([]*models.Entitlement, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &entitlements, listEntitlementsQuery, userID, organizationID); err != nil {
+		return nil, errors.Wrap(err, "billingRepo.ListEntitlements.SelectContext")
+	}
+	return entitlements, nil
+}
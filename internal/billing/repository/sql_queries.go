@@ -0,0 +1,38 @@
+package repository
+
+const (
+	entitlementColumns = `id, subscription_id, customer_id, organization_id, user_id, plan, status, current_period_end,
+						event_at, created_at, updated_at`
+
+	eventProcessedQuery = `SELECT EXISTS (SELECT 1 FROM billing_events WHERE id = $1)`
+
+	recordEventQuery = `INSERT INTO billing_events (id, type, created_at) VALUES ($1, $2, $3)
+						ON CONFLICT (id) DO NOTHING`
+
+	// Subjects are checked in the insert so an unknown one inserts nothing instead of failing the foreign key
+	upsertEntitlementQuery = `INSERT INTO billing_entitlements (subscription_id, customer_id, organization_id, user_id, plan,
+							status, current_period_end, event_at)
+						SELECT $1, $2, $3, $4, $5, $6, $7, $8
+						WHERE ($3::BIGINT IS NULL OR EXISTS (SELECT 1 FROM organizations WHERE id = $3))
+							AND ($4::INT IS NULL OR EXISTS (SELECT 1 FROM users WHERE id = $4))
+						ON CONFLICT (subscription_id) DO UPDATE SET
+							customer_id = EXCLUDED.customer_id,
+							organization_id = EXCLUDED.organization_id,
+							user_id = EXCLUDED.user_id,
+							plan = EXCLUDED.plan,
+							status = EXCLUDED.status,
+							current_period_end = EXCLUDED.current_period_end,
+							event_at = EXCLUDED.event_at,
+							updated_at = now()
+						WHERE billing_entitlements.event_at <= EXCLUDED.event_at
+						RETURNING ` + entitlementColumns
+
+	getEntitlementBySubscriptionQuery = `SELECT ` + entitlementColumns + `
+						FROM billing_entitlements
+						WHERE subscription_id = $1`
+
+	listEntitlementsQuery = `SELECT ` + entitlementColumns + `
+						FROM billing_entitlements
+						WHERE user_id = $1 OR organization_id = $2
+						ORDER BY id`
+)
@@ -0,0 +1,22 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package billing
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Billing UseCase interface
+type UseCase interface {
+	// Verify the signature of a provider webhook and apply its event once, payload is the raw request body.
+	// cache:emit=entitlements
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*models.BillingEventResult, error)
+	// Entitlements of the current user and of their organization
+	ListEntitlements(ctx context.Context) ([]*models.Entitlement, error)
+	// Plans the user is entitled to by their own or their organization's active subscriptions, cached until an
+	// entitlement or a membership of the user changes.
+	// cache:key=plans:{userID} cache:ttl=60 cache:invalidate=entitlements,members:{userID}
+	Plans(ctx context.Context, userID int) ([]string, error)
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Provider events changing subscriptions, other events are recorded and ignored
+const (
+	eventSubscriptionCreated = "customer.subscription.created"
+	eventSubscriptionUpdated = "customer.subscription.updated"
+	eventSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+// Subscription metadata naming the entitled subject
+const (
+	metadataOrganizationID = "organization_id"
+	metadataUserID         = "user_id"
+)
+
+const (
+	errSignatureMissing  = "billing webhook signature is missing or malformed"
+	errSignatureStale    = "billing webhook signature timestamp is outside the tolerance"
+	errSignatureMismatch = "billing webhook signature does not match"
+	errNoWebhookKey      = "billing webhook key is not configured"
+)
+
+// Billing UseCase. Provider webhooks are signed in the Stripe style over the raw body with the secret named by
+// Billing.WebhookKeySecret and sent in SignatureHeader, older than ToleranceSeconds they are refused. Subscriptions
+// carry an organization_id or user_id in their metadata and entitle it to their plan while active, Plans sets the
+// organization quotas of a plan.
+type billingUC struct {
+	cfg      *config.Config
+	repo     billing.Repository
+	orgsRepo organizations.Repository
+	key      []byte
+	clock    clock.Clock
+	logger   logger.Logger
+}
+
+// Billing UseCase constructor, key is the webhook signing key from LoadWebhookKey
+func NewBillingUseCase(
+	cfg *config.Config,
+	repo billing.Repository,
+	orgsRepo organizations.Repository,
+	key []byte,
+	clk clock.Clock,
+	logger logger.Logger,
+) billing.UseCase {
+	return &billingUC{cfg: cfg, repo: repo, orgsRepo: orgsRepo, key: key, clock: clk, logger: logger}
+}
+
+// Verify and apply a provider event. Events are applied at most once by id and subscriptions only move forward
+// in event time, so redeliveries and out of order deliveries are answered without changing anything. Failures
+// are returned before the event is recorded, the provider retries it and applying it again is harmless
+func (u *billingUC) HandleWebhook(ctx context.Context, payload []byte, signature string) (*models.BillingEventResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingUC.HandleWebhook")
+	defer span.Finish()
+
+	tolerance := time.Duration(u.cfg.Billing.ToleranceSeconds) * time.Second
+	if err := verifySignature(signature, payload, u.key, u.clock.Now(), tolerance); err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+
+	event := &dto.BillingEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "billingUC.HandleWebhook.Unmarshal"))
+	}
+	if err := utils.ValidateStruct(ctx, event); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "billingUC.HandleWebhook.ValidateStruct"))
+	}
+
+	result := &models.BillingEventResult{EventID: event.ID, Outcome: models.BillingOutcomeDuplicate}
+	processed, err := u.repo.EventProcessed(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if processed {
+		return result, nil
+	}
+
+	if result.Outcome, err = u.apply(ctx, event); err != nil {
+		return nil, err
+	}
+	if err := u.repo.RecordEvent(ctx, event.ID, event.Type, u.clock.Now()); err != nil {
+		return nil, err
+	}
+	u.logger.Infof("billingUC.HandleWebhook event: %s, type: %s, outcome: %s", event.ID, event.Type, result.Outcome)
+	return result, nil
+}
+
+// Entitlements of the current user and of their organization
+func (u *billingUC) ListEntitlements(ctx context.Context) ([]*models.Entitlement, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingUC.ListEntitlements")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	return u.entitlements(ctx, user.User.ID)
+}
+
+// Plans of the active entitlements of the user and their organization, sorted
+func (u *billingUC) Plans(ctx context.Context, userID int) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "billingUC.Plans")
+	defer span.Finish()
+
+	entitlements, err := u.entitlements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	plans := make([]string, 0)
+	for _, entitlement := range entitlements {
+		if entitlement.Active() && !seen[entitlement.Plan] {
+			seen[entitlement.Plan] = true
+			plans = append(plans, entitlement.Plan)
+		}
+	}
+	sort.Strings(plans)
+	return plans, nil
+}
+
+// Apply a verified event, the outcome says whether it changed an entitlement
+func (u *billingUC) apply(ctx context.Context, event *dto.BillingEvent) (string, error) {
+	switch event.Type {
+	case eventSubscriptionCreated, eventSubscriptionUpdated, eventSubscriptionDeleted:
+	default:
+		return models.BillingOutcomeIgnored, nil
+	}
+
+	subscription := &dto.BillingSubscription{}
+	if err := json.Unmarshal(event.Data.Object, subscription); err != nil {
+		return "", httpErrors.NewBadRequestError(errors.WithMessage(err, "billingUC.apply.Unmarshal"))
+	}
+	if err := utils.ValidateStruct(ctx, subscription); err != nil {
+		return "", httpErrors.NewBadRequestError(errors.WithMessage(err, "billingUC.apply.ValidateStruct"))
+	}
+
+	entitlement, ok := subscriptionEntitlement(subscription, event)
+	if !ok {
+		u.logger.Warnf("billingUC.apply subscription %s of event %s names no organization or user", subscription.ID, event.ID)
+		return models.BillingOutcomeIgnored, nil
+	}
+	stored, applied, err := u.repo.UpsertEntitlement(ctx, entitlement)
+	if errors.Is(err, sql.ErrNoRows) {
+		u.logger.Warnf("billingUC.apply subscription %s of event %s names an unknown organization or user", subscription.ID, event.ID)
+		return models.BillingOutcomeIgnored, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !applied {
+		return models.BillingOutcomeStale, nil
+	}
+
+	if stored.OrganizationID != nil {
+		if err := u.applyQuotas(ctx, *stored.OrganizationID); err != nil {
+			return "", err
+		}
+	}
+	return models.BillingOutcomeApplied, nil
+}
+
+// Set the organization quotas to those of its most recently changed active entitlement to a configured plan,
+// back to the configured defaults without one
+func (u *billingUC) applyQuotas(ctx context.Context, organizationID int64) error {
+	organization, err := u.orgsRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	entitlements, err := u.repo.ListEntitlements(ctx, 0, organizationID)
+	if err != nil {
+		return err
+	}
+
+	maxMembers, maxInvitations := u.cfg.Organizations.MaxMembers, u.cfg.Organizations.MaxPendingInvitations
+	var latest *models.Entitlement
+	for _, entitlement := range entitlements {
+		if !entitlement.Active() {
+			continue
+		}
+		if _, ok := u.cfg.Billing.Plans[entitlement.Plan]; !ok {
+			u.logger.Warnf("billingUC.applyQuotas organization: %d, unknown plan: %s", organizationID, entitlement.Plan)
+			continue
+		}
+		if latest == nil || entitlement.EventAt.After(latest.EventAt) {
+			latest = entitlement
+		}
+	}
+	if latest != nil {
+		plan := u.cfg.Billing.Plans[latest.Plan]
+		maxMembers, maxInvitations = plan.MaxMembers, plan.MaxPendingInvitations
+	}
+
+	if organization.MaxMembers == maxMembers && organization.MaxPendingInvitations == maxInvitations {
+		return nil
+	}
+	organization.MaxMembers = maxMembers
+	organization.MaxPendingInvitations = maxInvitations
+	_, err = u.orgsRepo.Update(ctx, organization)
+	return err
+}
+
+// Entitlements of the user and of their organization
+func (u *billingUC) entitlements(ctx context.Context, userID int) ([]*models.Entitlement, error) {
+	var organizationID int64
+	membership, err := u.orgsRepo.GetMembership(ctx, userID)
+	switch {
+	case err == nil:
+		organizationID = membership.OrganizationID
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+	return u.repo.ListEntitlements(ctx, userID, organizationID)
+}
+
+// Entitlement to the subscription plan of the organization or user in its metadata, false without exactly one
+func subscriptionEntitlement(subscription *dto.BillingSubscription, event *dto.BillingEvent) (*models.Entitlement, bool) {
+	entitlement := &models.Entitlement{
+		SubscriptionID: subscription.ID,
+		CustomerID:     subscription.Customer,
+		Plan:           subscription.Plan.ID,
+		Status:         subscription.Status,
+		EventAt:        time.Unix(event.Created, 0).UTC(),
+	}
+	if event.Type == eventSubscriptionDeleted {
+		entitlement.Status = models.SubscriptionStatusCanceled
+	}
+	if subscription.CurrentPeriodEnd > 0 {
+		periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0).UTC()
+		entitlement.CurrentPeriodEnd = &periodEnd
+	}
+
+	if raw, ok := subscription.Metadata[metadataOrganizationID]; ok {
+		organizationID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		entitlement.OrganizationID = &organizationID
+	}
+	if raw, ok := subscription.Metadata[metadataUserID]; ok {
+		userID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, false
+		}
+		entitlement.UserID = &userID
+	}
+	return entitlement, (entitlement.OrganizationID == nil) != (entitlement.UserID == nil)
+}
+
+// Check a Stripe style signature header, t=<unix seconds> and v1=<hex HMAC-SHA256 of "<t>.<payload>">. Several v1
+// values may be sent while the provider rolls its key, one matching is enough
+func verifySignature(header string, payload []byte, key []byte, now time.Time, tolerance time.Duration) error {
+	if len(key) == 0 {
+		return errors.New(errNoWebhookKey)
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errors.New(errSignatureMissing)
+	}
+	if tolerance > 0 && now.Sub(time.Unix(seconds, 0)).Abs() > tolerance {
+		return errors.New(errSignatureStale)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return errors.New(errSignatureMismatch)
+}
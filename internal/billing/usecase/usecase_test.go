@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	organizationsRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+var testKey = []byte("billing-webhook-key")
+
+func sign(payload []byte, at time.Time) string {
+	timestamp := fmt.Sprint(at.Unix())
+	mac := hmac.New(sha256.New, testKey)
+	mac.Write([]byte(timestamp + "." + string(payload)))
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscriptionEvent(id string, eventType string, created time.Time, status string, metadata string) []byte {
+	return []byte(fmt.Sprintf(`{"id":%q,"type":%q,"created":%d,"data":{"object":{"id":"sub_1","customer":"cus_1",`+
+		`"status":%q,"plan":{"id":"team"},"metadata":%s}}}`, id, eventType, created.Unix(), status, metadata))
+}
+
+func TestBillingUC_HandleWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	clk := clock.NewFrozen(now)
+	orgsRepo := organizationsRepository.NewOrganizationsMemoryRepository()
+	organization, err := orgsRepo.Create(ctx, &models.Organization{Name: "Acme", OwnerID: 1, MaxMembers: 3, MaxPendingInvitations: 2})
+	require.NoError(t, err)
+	cfg := &config.Config{
+		Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2},
+		Billing: config.Billing{
+			Enabled:          true,
+			ToleranceSeconds: 300,
+			Plans:            map[string]config.BillingPlan{"team": {MaxMembers: 50, MaxPendingInvitations: 20}},
+		},
+	}
+	uc := NewBillingUseCase(cfg, repository.NewBillingMemoryRepository(), orgsRepo, testKey, clk, testutil.Logger(cfg))
+	metadata := fmt.Sprintf(`{"organization_id":"%d"}`, organization.ID)
+
+	// Signatures
+	payload := subscriptionEvent("evt_1", eventSubscriptionCreated, now, models.SubscriptionStatusActive, metadata)
+	_, err = uc.HandleWebhook(ctx, payload, "t=1,v1=00")
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.HandleWebhook(ctx, payload, sign(payload, now.Add(-10*time.Minute)))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.HandleWebhook(ctx, payload, sign([]byte("{}"), now))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	// Applied once, redelivery is a duplicate
+	result, err := uc.HandleWebhook(ctx, payload, sign(payload, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeApplied, result.Outcome)
+	result, err = uc.HandleWebhook(ctx, payload, sign(payload, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeDuplicate, result.Outcome)
+
+	updated, err := orgsRepo.GetByID(ctx, organization.ID)
+	require.NoError(t, err)
+	require.Equal(t, 50, updated.MaxMembers)
+	require.Equal(t, 20, updated.MaxPendingInvitations)
+
+	// Members of the organization hold its plans
+	plans, err := uc.Plans(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"team"}, plans)
+
+	// Older event delivered late does not undo a newer one
+	deleted := subscriptionEvent("evt_3", eventSubscriptionDeleted, now.Add(time.Minute), models.SubscriptionStatusActive, metadata)
+	result, err = uc.HandleWebhook(ctx, deleted, sign(deleted, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeApplied, result.Outcome)
+	late := subscriptionEvent("evt_2", eventSubscriptionUpdated, now.Add(30*time.Second), models.SubscriptionStatusActive, metadata)
+	result, err = uc.HandleWebhook(ctx, late, sign(late, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeStale, result.Outcome)
+
+	// Canceled subscription puts the quotas back to the defaults
+	updated, err = orgsRepo.GetByID(ctx, organization.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, updated.MaxMembers)
+	require.Equal(t, 2, updated.MaxPendingInvitations)
+	plans, err = uc.Plans(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, plans)
+
+	// Events of other types and subscriptions without a subject are ignored
+	other := []byte(`{"id":"evt_4","type":"invoice.paid","created":1700000000,"data":{"object":{}}}`)
+	result, err = uc.HandleWebhook(ctx, other, sign(other, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeIgnored, result.Outcome)
+	orphan := subscriptionEvent("evt_5", eventSubscriptionCreated, now, models.SubscriptionStatusActive, `{}`)
+	result, err = uc.HandleWebhook(ctx, orphan, sign(orphan, now))
+	require.NoError(t, err)
+	require.Equal(t, models.BillingOutcomeIgnored, result.Outcome)
+}
+
+func TestBillingUC_Plans(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	cfg := &config.Config{
+		Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2},
+		Billing: config.Billing{
+			Enabled:          true,
+			ToleranceSeconds: 300,
+			Plans:            map[string]config.BillingPlan{"team": {MaxMembers: 50, MaxPendingInvitations: 20}},
+		},
+	}
+	uc := NewBillingUseCase(cfg, repository.NewBillingMemoryRepository(), organizationsRepository.NewOrganizationsMemoryRepository(), testKey, clock.NewFrozen(now), testutil.Logger(cfg))
+
+	payload := subscriptionEvent("evt_1", eventSubscriptionCreated, now, models.SubscriptionStatusTrialing, `{"user_id":"7"}`)
+	_, err := uc.HandleWebhook(ctx, payload, sign(payload, now))
+	require.NoError(t, err)
+
+	plans, err := uc.Plans(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, []string{"team"}, plans)
+	plans, err = uc.Plans(ctx, 8)
+	require.NoError(t, err)
+	require.Empty(t, plans)
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Key the billing provider signs webhooks with, nil while billing is disabled
+func LoadWebhookKey(ctx context.Context, cfg *config.Config) ([]byte, error) {
+	if !cfg.Billing.Enabled || cfg.Billing.WebhookKeySecret == "" {
+		return nil, nil
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	key, err := provider.Get(ctx, cfg.Billing.WebhookKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(key), nil
+}
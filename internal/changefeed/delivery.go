@@ -0,0 +1,8 @@
+package changefeed
+
+import "github.com/labstack/echo/v4"
+
+// Change feed HTTP Handlers interface
+type Handlers interface {
+	GetUserChanges() echo.HandlerFunc
+}
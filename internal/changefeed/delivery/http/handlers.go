@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Change feed handlers
+type changeFeedHandlers struct {
+	cfg          *config.Config
+	changeFeedUC changefeed.UseCase
+	logger       logger.Logger
+}
+
+// NewChangeFeedHandlers Change feed handlers constructor
+func NewChangeFeedHandlers(cfg *config.Config, changeFeedUC changefeed.UseCase, log logger.Logger) changefeed.Handlers {
+	return &changeFeedHandlers{cfg: cfg, changeFeedUC: changeFeedUC, logger: log}
+}
+
+// GetUserChanges godoc
+// @Summary Sync user changes
+// @Description Users created, updated or deleted since the cursor. Without since only a starting cursor is returned,
+// @Description 400 with code invalid_cursor means the cursor was altered or issued elsewhere, 410 that it outlived the change log,
+// @Description in both cases the full list must be downloaded again
+// @Tags Users
+// @Produce json
+// @Param since query string false "cursor returned as next_cursor by the previous call"
+// @Param limit query int false "max change log entries to read, default 100, max 500"
+// @Success 200 {object} models.UserChanges
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 410 {object} httpErrors.RestError
+// @Router /users/changes [get]
+func (h *changeFeedHandlers) GetUserChanges() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "changeFeedHandlers.GetUserChanges")
+		defer span.Finish()
+
+		limit := 0
+		if raw := c.QueryParam("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				return httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error())
+			}
+			limit = parsed
+		}
+
+		changes, err := h.changeFeedUC.GetUserChanges(ctx, c.QueryParam("since"), limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, changes)
+	}
+}
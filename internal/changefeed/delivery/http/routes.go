@@ -0,0 +1,20 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map change feed routes
+func MapChangeFeedRoutes(usersGroup *echo.Group, h changefeed.Handlers, mw *middleware.MiddlewareManager, authUC auth.UseCase, cfg *config.Config) {
+	if !cfg.Bearer.Enabled {
+		usersGroup.Use(mw.AuthJWTMiddleware(authUC, cfg))
+	}
+	usersGroup.Use(mw.AuthSessionMiddleware)
+
+	usersGroup.GET("/changes", h.GetUserChanges())
+}
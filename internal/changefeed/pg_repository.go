@@ -0,0 +1,16 @@
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Change feed repository interface
+type Repository interface {
+	GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*models.ChangeEvent, error)
+	GetTableEventsAfter(ctx context.Context, table string, afterID int64, limit int) ([]*models.ChangeEvent, error)
+	GetLastEventID(ctx context.Context) (int64, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
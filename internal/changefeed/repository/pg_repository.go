@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Change feed Repository
+type changeFeedRepo struct {
+	db *sqlx.DB
+}
+
+// Change feed Repository constructor
+func NewChangeFeedRepository(db *sqlx.DB) changefeed.Repository {
+	return &changeFeedRepo{db: db}
+}
+
+// Get logged events after given id
+func (r *changeFeedRepo) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*models.ChangeEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedRepo.GetEventsAfter")
+	defer span.Finish()
+
+	events := make([]*models.ChangeEvent, 0, limit)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &events, getEventsAfterQuery, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "changeFeedRepo.GetEventsAfter.SelectContext")
+	}
+	return events, nil
+}
+
+// Get logged events of one table after given id
+func (r *changeFeedRepo) GetTableEventsAfter(ctx context.Context, table string, afterID int64, limit int) ([]*models.ChangeEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedRepo.GetTableEventsAfter")
+	defer span.Finish()
+
+	events := make([]*models.ChangeEvent, 0, limit)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &events, getTableEventsAfterQuery, table, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "changeFeedRepo.GetTableEventsAfter.SelectContext")
+	}
+	return events, nil
+}
+
+// Get id of the newest logged event
+func (r *changeFeedRepo) GetLastEventID(ctx context.Context) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedRepo.GetLastEventID")
+	defer span.Finish()
+
+	var id int64
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, &id, getLastEventIDQuery); err != nil {
+		return 0, errors.Wrap(err, "changeFeedRepo.GetLastEventID.GetContext")
+	}
+	return id, nil
+}
+
+// Prune logged events
+func (r *changeFeedRepo) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedRepo.DeleteOlderThan")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, deleteOlderThanQuery, before)
+	if err != nil {
+		return 0, errors.Wrap(err, "changeFeedRepo.DeleteOlderThan.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "changeFeedRepo.DeleteOlderThan.RowsAffected")
+	}
+	return rowsAffected, nil
+}
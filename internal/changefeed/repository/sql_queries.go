@@ -0,0 +1,19 @@
+package repository
+
+const (
+	getEventsAfterQuery = `SELECT id, table_name, operation, entity_id, created_at
+						FROM cache_invalidation_log
+						WHERE id > $1
+						ORDER BY id
+						LIMIT $2`
+
+	getTableEventsAfterQuery = `SELECT id, table_name, operation, entity_id, created_at
+						FROM cache_invalidation_log
+						WHERE table_name = $1 AND id > $2
+						ORDER BY id
+						LIMIT $3`
+
+	getLastEventIDQuery = `SELECT COALESCE(MAX(id), 0) FROM cache_invalidation_log`
+
+	deleteOlderThanQuery = `DELETE FROM cache_invalidation_log WHERE created_at < $1`
+)
@@ -0,0 +1,30 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package changefeed
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Notification channel of the cache invalidation trigger, named in its migration
+const Channel = "cache_invalidation"
+
+// Cache which must drop entries when the underlying rows change
+type CacheInvalidator interface {
+	InvalidateUserCache(ctx context.Context, userID int) error
+	InvalidateUsersCache(ctx context.Context) error
+}
+
+// Source of the current state of changed users
+type UserReader interface {
+	GetByID(ctx context.Context, userID int) (*models.UserWithRole, error)
+}
+
+// Change feed use case
+type UseCase interface {
+	HandleNotification(ctx context.Context, payload string) error
+	// Replays the whole missed log on reconnect, observe:nodeadline
+	Backfill(ctx context.Context) error
+	GetUserChanges(ctx context.Context, since string, limit int) (*models.UserChanges, error)
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+const (
+	defaultSyncLimit = 100
+	maxSyncLimit     = 500
+
+	errCursorExpired = "Sync cursor expired, download the full list and sync again"
+
+	// Cursor scope of the users sync, cursors of other listings are rejected
+	userChangesScope = "user_changes"
+)
+
+// Sync cursor payload, field names are part of the cursor format and must stay stable
+type syncCursor struct {
+	AfterID  int64 `json:"a"`
+	IssuedAt int64 `json:"t"`
+}
+
+// Users changed after the cursor, one entry per user with its current state.
+// An empty cursor returns no changes and the head of the log to start from, clients then download the full list.
+// Cursors older than the change log retention are rejected with 410 since pruned deletions would be missed,
+// forged or foreign cursors with 400 invalid_cursor.
+func (u *changeFeedUC) GetUserChanges(ctx context.Context, since string, limit int) (*models.UserChanges, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedUC.GetUserChanges")
+	defer span.Finish()
+
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	}
+	if limit > maxSyncLimit {
+		limit = maxSyncLimit
+	}
+
+	if since == "" {
+		headID, err := u.repo.GetLastEventID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		next, err := u.encodeCursor(headID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.UserChanges{Changes: []*models.UserChange{}, NextCursor: next}, nil
+	}
+
+	position := syncCursor{}
+	if err := u.cursors.Decode(since, userChangesScope, &position); err != nil {
+		return nil, err
+	}
+	afterID, issuedAt := position.AfterID, time.Unix(position.IssuedAt, 0)
+	if u.clock.Since(issuedAt) > u.retention() {
+		return nil, httpErrors.NewRestError(http.StatusGone, errCursorExpired, issuedAt)
+	}
+
+	events, err := u.repo.GetTableEventsAfter(ctx, usersTable, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collapse events per user, a user inserted within the page is reported as created
+	order := make([]int, 0, len(events))
+	inserted := make(map[int]bool, len(events))
+	for _, event := range events {
+		if _, seen := inserted[event.EntityID]; !seen {
+			order = append(order, event.EntityID)
+			inserted[event.EntityID] = false
+		}
+		if event.Operation == "INSERT" {
+			inserted[event.EntityID] = true
+		}
+		afterID = event.ID
+	}
+
+	changes := make([]*models.UserChange, 0, len(order))
+	for _, userID := range order {
+		change, err := u.userChange(ctx, userID, inserted[userID])
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	next, err := u.encodeCursor(afterID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserChanges{
+		Changes:    changes,
+		NextCursor: next,
+		HasMore:    len(events) == limit,
+	}, nil
+}
+
+func (u *changeFeedUC) userChange(ctx context.Context, userID int, inserted bool) (*models.UserChange, error) {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.UserChange{ID: userID, Op: models.UserChangeDeleted}, nil
+		}
+		return nil, err
+	}
+
+	user.User.SanitizePassword()
+	change := &models.UserChange{ID: userID, Op: models.UserChangeUpdated, User: &user.User}
+	if inserted {
+		change.Op = models.UserChangeCreated
+	}
+	return change, nil
+}
+
+// Cursor is the last returned log id and the time it was issued, signed and opaque to clients
+func (u *changeFeedUC) encodeCursor(lastID int64) (string, error) {
+	return u.cursors.Encode(userChangesScope, syncCursor{AfterID: lastID, IssuedAt: u.clock.Now().Unix()})
+}
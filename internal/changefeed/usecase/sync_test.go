@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/cursor"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+type fakeLog struct {
+	changefeed.Repository
+	events []*models.ChangeEvent
+}
+
+func (f *fakeLog) GetLastEventID(ctx context.Context) (int64, error) {
+	return f.events[len(f.events)-1].ID, nil
+}
+
+func (f *fakeLog) GetTableEventsAfter(ctx context.Context, table string, afterID int64, limit int) ([]*models.ChangeEvent, error) {
+	events := make([]*models.ChangeEvent, 0, limit)
+	for _, event := range f.events {
+		if event.Table == table && event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+type fakeUsers map[int]*models.User
+
+func (f fakeUsers) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	user, ok := f[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.UserWithRole{User: *user}, nil
+}
+
+func TestChangeFeedUC_GetUserChanges(t *testing.T) {
+	t.Parallel()
+
+	log := &fakeLog{events: []*models.ChangeEvent{
+		{ID: 1, Table: usersTable, Operation: "UPDATE", EntityID: 1},
+		{ID: 2, Table: usersTable, Operation: "INSERT", EntityID: 2},
+		{ID: 3, Table: rolesTable, Operation: "UPDATE", EntityID: 1},
+		{ID: 4, Table: usersTable, Operation: "UPDATE", EntityID: 2},
+		{ID: 5, Table: usersTable, Operation: "DELETE", EntityID: 3},
+	}}
+	users := fakeUsers{1: {ID: 1, Password: "hash"}, 2: {ID: 2}}
+	clk := clock.NewFrozen(time.Now())
+	cursors, err := cursor.NewSigner(map[int][]byte{1: []byte("0123456789abcdef0123456789abcdef")}, 1)
+	require.NoError(t, err)
+	uc := &changeFeedUC{cfg: &config.Config{}, repo: log, users: users, cursors: cursors, clock: clk}
+	ctx := context.Background()
+
+	start, err := uc.GetUserChanges(ctx, "", 0)
+	require.NoError(t, err)
+	require.Empty(t, start.Changes)
+	head, err := uc.encodeCursor(5)
+	require.NoError(t, err)
+	require.Equal(t, head, start.NextCursor)
+
+	first, err := uc.encodeCursor(0)
+	require.NoError(t, err)
+	page, err := uc.GetUserChanges(ctx, first, 3)
+	require.NoError(t, err)
+	require.True(t, page.HasMore)
+	require.Len(t, page.Changes, 2)
+	require.Equal(t, models.UserChangeUpdated, page.Changes[0].Op)
+	require.Empty(t, page.Changes[0].User.Password)
+	require.Equal(t, models.UserChangeCreated, page.Changes[1].Op)
+
+	page, err = uc.GetUserChanges(ctx, page.NextCursor, 3)
+	require.NoError(t, err)
+	require.False(t, page.HasMore)
+	require.Equal(t, []*models.UserChange{{ID: 3, Op: models.UserChangeDeleted}}, page.Changes)
+
+	clk.Set(clk.Now().Add(25 * time.Hour))
+	_, err = uc.GetUserChanges(ctx, page.NextCursor, 3)
+	require.Equal(t, http.StatusGone, httpErrors.ParseErrors(err).Status())
+
+	_, err = uc.GetUserChanges(ctx, "not a cursor", 3)
+	require.ErrorIs(t, err, cursor.ErrInvalid)
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+
+	// A cursor of another listing doesn't verify here even though its payload looks alike
+	foreign, err := cursors.Encode("users", syncCursor{AfterID: 0, IssuedAt: clk.Now().Unix()})
+	require.NoError(t, err)
+	_, err = uc.GetUserChanges(ctx, foreign, 3)
+	require.ErrorIs(t, err, cursor.ErrInvalid)
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/cursor"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	usersTable     = "users"
+	rolesTable     = "roles"
+	userRolesTable = "user_roles"
+
+	defaultBackfillBatchSize = 500
+	defaultRetentionHours    = 24
+)
+
+// Change feed UseCase
+type changeFeedUC struct {
+	cfg          *config.Config
+	repo         changefeed.Repository
+	invalidators []changefeed.CacheInvalidator
+	users        changefeed.UserReader
+	cursors      *cursor.Signer
+	clock        clock.Clock
+	logger       logger.Logger
+
+	mu         sync.Mutex
+	lastID     int64
+	lastSeenAt time.Time
+}
+
+// Change feed UseCase constructor
+func NewChangeFeedUseCase(
+	cfg *config.Config,
+	repo changefeed.Repository,
+	invalidators []changefeed.CacheInvalidator,
+	users changefeed.UserReader,
+	cursors *cursor.Signer,
+	clk clock.Clock,
+	log logger.Logger,
+) changefeed.UseCase {
+	return &changeFeedUC{cfg: cfg, repo: repo, invalidators: invalidators, users: users, cursors: cursors, clock: clk, logger: log}
+}
+
+// Handle notification payload published by the cache invalidation trigger
+func (u *changeFeedUC) HandleNotification(ctx context.Context, payload string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedUC.HandleNotification")
+	defer span.Finish()
+
+	event := &models.ChangeEvent{}
+	if err := json.Unmarshal([]byte(payload), event); err != nil {
+		return errors.Wrap(err, "changeFeedUC.HandleNotification.json.Unmarshal")
+	}
+
+	u.invalidate(ctx, event)
+	u.markSeen(event.ID)
+
+	return nil
+}
+
+// Replay events missed while the listener was disconnected
+func (u *changeFeedUC) Backfill(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "changeFeedUC.Backfill")
+	defer span.Finish()
+
+	u.mu.Lock()
+	lastID, lastSeenAt := u.lastID, u.lastSeenAt
+	u.mu.Unlock()
+
+	// First connection of this instance: start from the head of the log
+	if lastSeenAt.IsZero() {
+		headID, err := u.repo.GetLastEventID(ctx)
+		if err != nil {
+			return err
+		}
+		u.markSeen(headID)
+		return u.prune(ctx)
+	}
+
+	// Events older than the retention window are gone, nothing short of a full flush is safe
+	if u.clock.Since(lastSeenAt) > u.retention() {
+		u.logger.Warnf("changeFeedUC.Backfill: disconnected since %s, flushing users cache", lastSeenAt)
+		for _, inv := range u.invalidators {
+			if err := inv.InvalidateUsersCache(ctx); err != nil {
+				u.logger.Errorf("changeFeedUC.Backfill.InvalidateUsersCache: %s", err)
+			}
+		}
+		headID, err := u.repo.GetLastEventID(ctx)
+		if err != nil {
+			return err
+		}
+		u.markSeen(headID)
+		return u.prune(ctx)
+	}
+
+	batchSize := u.cfg.ChangeFeed.BackfillBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+
+	replayed := 0
+	for {
+		events, err := u.repo.GetEventsAfter(ctx, lastID, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			u.invalidate(ctx, event)
+			lastID = event.ID
+		}
+		replayed += len(events)
+		u.markSeen(lastID)
+
+		if len(events) < batchSize {
+			break
+		}
+	}
+
+	u.logger.Infof("changeFeedUC.Backfill: replayed %d events", replayed)
+
+	return u.prune(ctx)
+}
+
+func (u *changeFeedUC) invalidate(ctx context.Context, event *models.ChangeEvent) {
+	for _, inv := range u.invalidators {
+		var err error
+		switch event.Table {
+		case usersTable, userRolesTable:
+			err = inv.InvalidateUserCache(ctx, event.EntityID)
+		case rolesTable:
+			// Role rows are embedded into every cached user holding them
+			err = inv.InvalidateUsersCache(ctx)
+		default:
+			u.logger.Warnf("changeFeedUC.invalidate: unknown table %s", event.Table)
+		}
+		if err != nil {
+			u.logger.Errorf("changeFeedUC.invalidate Table: %s, EntityID: %d, Error: %s", event.Table, event.EntityID, err)
+		}
+	}
+}
+
+func (u *changeFeedUC) markSeen(id int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if id > u.lastID {
+		u.lastID = id
+	}
+	u.lastSeenAt = u.clock.Now()
+}
+
+func (u *changeFeedUC) prune(ctx context.Context) error {
+	deleted, err := u.repo.DeleteOlderThan(ctx, u.clock.Now().Add(-u.retention()))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		u.logger.Infof("changeFeedUC.prune: deleted %d events", deleted)
+	}
+	return nil
+}
+
+func (u *changeFeedUC) retention() time.Duration {
+	hours := u.cfg.ChangeFeed.RetentionHours
+	if hours <= 0 {
+		hours = defaultRetentionHours
+	}
+	return time.Duration(hours) * time.Hour
+}
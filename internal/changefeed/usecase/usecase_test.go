@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+// Change log of the backfill tests, remembers the cutoff of the last prune
+type replayLog struct {
+	changefeed.Repository
+	events       []*models.ChangeEvent
+	prunedBefore time.Time
+}
+
+func (r *replayLog) GetLastEventID(ctx context.Context) (int64, error) {
+	if len(r.events) == 0 {
+		return 0, nil
+	}
+	return r.events[len(r.events)-1].ID, nil
+}
+
+func (r *replayLog) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*models.ChangeEvent, error) {
+	events := make([]*models.ChangeEvent, 0, limit)
+	for _, event := range r.events {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *replayLog) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	r.prunedBefore = before
+	return 0, nil
+}
+
+// Invalidations in the order they were asked for, "user:<id>" or "users"
+type recordingCache []string
+
+func (r *recordingCache) InvalidateUserCache(ctx context.Context, userID int) error {
+	*r = append(*r, fmt.Sprintf("user:%d", userID))
+	return nil
+}
+
+func (r *recordingCache) InvalidateUsersCache(ctx context.Context) error {
+	*r = append(*r, "users")
+	return nil
+}
+
+func TestChangeFeedUC_HandleNotification(t *testing.T) {
+	t.Parallel()
+
+	cache := &recordingCache{}
+	cfg := &config.Config{ChangeFeed: config.ChangeFeed{BackfillBatchSize: 2, RetentionHours: 1}}
+	uc := NewChangeFeedUseCase(cfg, &replayLog{}, []changefeed.CacheInvalidator{cache}, nil, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg)).(*changeFeedUC)
+	ctx := context.Background()
+
+	require.NoError(t, uc.HandleNotification(ctx, `{"id":7,"table":"users","op":"UPDATE","entity_id":3}`))
+	require.NoError(t, uc.HandleNotification(ctx, `{"id":8,"table":"user_roles","op":"INSERT","entity_id":4}`))
+	require.NoError(t, uc.HandleNotification(ctx, `{"id":9,"table":"roles","op":"UPDATE","entity_id":1}`))
+	require.NoError(t, uc.HandleNotification(ctx, `{"id":10,"table":"sessions","op":"DELETE","entity_id":1}`))
+	require.Equal(t, recordingCache{"user:3", "user:4", "users"}, *cache)
+	require.Equal(t, int64(10), uc.lastID)
+
+	// Notifications delivered out of order never move the position back
+	require.NoError(t, uc.HandleNotification(ctx, `{"id":5,"table":"users","op":"UPDATE","entity_id":2}`))
+	require.Equal(t, int64(10), uc.lastID)
+
+	require.Error(t, uc.HandleNotification(ctx, `not json`))
+}
+
+func TestChangeFeedUC_Backfill(t *testing.T) {
+	t.Parallel()
+
+	log := &replayLog{events: []*models.ChangeEvent{{ID: 1, Table: usersTable, EntityID: 1}}}
+	cache := &recordingCache{}
+	clk := clock.NewFrozen(time.Now())
+	cfg := &config.Config{ChangeFeed: config.ChangeFeed{BackfillBatchSize: 2, RetentionHours: 1}}
+	uc := NewChangeFeedUseCase(cfg, log, []changefeed.CacheInvalidator{cache}, nil, nil, clk, testutil.Logger(cfg)).(*changeFeedUC)
+	ctx := context.Background()
+
+	// The first connection starts at the head of the log without replaying it
+	require.NoError(t, uc.Backfill(ctx))
+	require.Empty(t, *cache)
+	require.Equal(t, int64(1), uc.lastID)
+	require.Equal(t, clk.Now().Add(-time.Hour), log.prunedBefore)
+
+	// A reconnect replays what was missed in batches
+	log.events = append(log.events,
+		&models.ChangeEvent{ID: 2, Table: usersTable, EntityID: 2},
+		&models.ChangeEvent{ID: 3, Table: userRolesTable, EntityID: 3},
+		&models.ChangeEvent{ID: 4, Table: rolesTable, EntityID: 1},
+	)
+	clk.Advance(10 * time.Minute)
+	require.NoError(t, uc.Backfill(ctx))
+	require.Equal(t, recordingCache{"user:2", "user:3", "users"}, *cache)
+	require.Equal(t, int64(4), uc.lastID)
+
+	// Past the retention the log may miss events, the whole cache is flushed instead
+	*cache = nil
+	log.events = append(log.events, &models.ChangeEvent{ID: 5, Table: usersTable, EntityID: 5})
+	clk.Advance(2 * time.Hour)
+	require.NoError(t, uc.Backfill(ctx))
+	require.Equal(t, recordingCache{"users"}, *cache)
+	require.Equal(t, int64(5), uc.lastID)
+}
@@ -0,0 +1,17 @@
+package contacts
+
+import "github.com/labstack/echo/v4"
+
+// Contacts HTTP Handlers interface
+type Handlers interface {
+	GetContacts() echo.HandlerFunc
+	CreateAddress() echo.HandlerFunc
+	UpdateAddress() echo.HandlerFunc
+	DeleteAddress() echo.HandlerFunc
+	CreatePhone() echo.HandlerFunc
+	UpdatePhone() echo.HandlerFunc
+	DeletePhone() echo.HandlerFunc
+	CreateSocialLink() echo.HandlerFunc
+	UpdateSocialLink() echo.HandlerFunc
+	DeleteSocialLink() echo.HandlerFunc
+}
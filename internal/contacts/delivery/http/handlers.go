@@ -0,0 +1,326 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Contacts handlers
+type contactsHandlers struct {
+	cfg        *config.Config
+	contactsUC contacts.UseCase
+	logger     logger.Logger
+}
+
+// NewContactsHandlers Contacts handlers constructor
+func NewContactsHandlers(cfg *config.Config, contactsUC contacts.UseCase, log logger.Logger) contacts.Handlers {
+	return &contactsHandlers{cfg: cfg, contactsUC: contactsUC, logger: log}
+}
+
+// GetContacts godoc
+// @Summary Get contacts
+// @Description Get addresses, phone numbers and social links of the current user
+// @Tags Contacts
+// @Produce json
+// @Success 200 {object} models.UserContacts
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/me/contacts [get]
+func (h *contactsHandlers) GetContacts() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.GetContacts")
+		defer span.Finish()
+
+		userContacts, err := h.contactsUC.GetContacts(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, userContacts)
+	}
+}
+
+// CreateAddress godoc
+// @Summary Add address
+// @Description Add address of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param body body dto.AddressRequest true "address"
+// @Success 201 {object} models.Address
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/contacts/addresses [post]
+func (h *contactsHandlers) CreateAddress() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.CreateAddress")
+		defer span.Finish()
+
+		req := &dto.AddressRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.contactsUC.CreateAddress(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// UpdateAddress godoc
+// @Summary Update address
+// @Description Replace address of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "address_id"
+// @Param body body dto.AddressRequest true "address"
+// @Success 200 {object} models.Address
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/addresses/{id} [put]
+func (h *contactsHandlers) UpdateAddress() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.UpdateAddress")
+		defer span.Finish()
+
+		addressID, err := strconv.ParseInt(c.Param("address_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.AddressRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		updated, err := h.contactsUC.UpdateAddress(ctx, addressID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeleteAddress godoc
+// @Summary Delete address
+// @Description Delete address of the current user
+// @Tags Contacts
+// @Param id path int true "address_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/addresses/{id} [delete]
+func (h *contactsHandlers) DeleteAddress() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.DeleteAddress")
+		defer span.Finish()
+
+		addressID, err := strconv.ParseInt(c.Param("address_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.contactsUC.DeleteAddress(ctx, addressID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// CreatePhone godoc
+// @Summary Add phone number
+// @Description Add phone number of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param body body dto.PhoneNumberRequest true "phone number"
+// @Success 201 {object} models.PhoneNumber
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/contacts/phones [post]
+func (h *contactsHandlers) CreatePhone() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.CreatePhone")
+		defer span.Finish()
+
+		req := &dto.PhoneNumberRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.contactsUC.CreatePhone(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// UpdatePhone godoc
+// @Summary Update phone number
+// @Description Replace phone number of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "phone_id"
+// @Param body body dto.PhoneNumberRequest true "phone number"
+// @Success 200 {object} models.PhoneNumber
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/phones/{id} [put]
+func (h *contactsHandlers) UpdatePhone() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.UpdatePhone")
+		defer span.Finish()
+
+		phoneID, err := strconv.ParseInt(c.Param("phone_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.PhoneNumberRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		updated, err := h.contactsUC.UpdatePhone(ctx, phoneID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeletePhone godoc
+// @Summary Delete phone number
+// @Description Delete phone number of the current user
+// @Tags Contacts
+// @Param id path int true "phone_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/phones/{id} [delete]
+func (h *contactsHandlers) DeletePhone() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.DeletePhone")
+		defer span.Finish()
+
+		phoneID, err := strconv.ParseInt(c.Param("phone_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.contactsUC.DeletePhone(ctx, phoneID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// CreateSocialLink godoc
+// @Summary Add social link
+// @Description Add social link of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param body body dto.SocialLinkRequest true "social link"
+// @Success 201 {object} models.SocialLink
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/contacts/links [post]
+func (h *contactsHandlers) CreateSocialLink() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.CreateSocialLink")
+		defer span.Finish()
+
+		req := &dto.SocialLinkRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.contactsUC.CreateSocialLink(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// UpdateSocialLink godoc
+// @Summary Update social link
+// @Description Replace social link of the current user
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "link_id"
+// @Param body body dto.SocialLinkRequest true "social link"
+// @Success 200 {object} models.SocialLink
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/links/{id} [put]
+func (h *contactsHandlers) UpdateSocialLink() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.UpdateSocialLink")
+		defer span.Finish()
+
+		linkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.SocialLinkRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		updated, err := h.contactsUC.UpdateSocialLink(ctx, linkID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeleteSocialLink godoc
+// @Summary Delete social link
+// @Description Delete social link of the current user
+// @Tags Contacts
+// @Param id path int true "link_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/contacts/links/{id} [delete]
+func (h *contactsHandlers) DeleteSocialLink() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "contactsHandlers.DeleteSocialLink")
+		defer span.Finish()
+
+		linkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.contactsUC.DeleteSocialLink(ctx, linkID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Map contacts routes under /me/contacts, authGroup must already carry the JWT and session middlewares
+func MapContactsRoutes(authGroup *echo.Group, h contacts.Handlers, mw *middleware.MiddlewareManager) {
+	contactsGroup := authGroup.Group("/me/contacts")
+
+	contactsGroup.GET("", h.GetContacts())
+	contactsGroup.POST("/addresses", h.CreateAddress(), mw.CSRF)
+	contactsGroup.PUT("/addresses/:address_id", h.UpdateAddress(), mw.CSRF)
+	contactsGroup.DELETE("/addresses/:address_id", h.DeleteAddress(), mw.CSRF)
+	contactsGroup.POST("/phones", h.CreatePhone(), mw.CSRF)
+	contactsGroup.PUT("/phones/:phone_id", h.UpdatePhone(), mw.CSRF)
+	contactsGroup.DELETE("/phones/:phone_id", h.DeletePhone(), mw.CSRF)
+	contactsGroup.POST("/links", h.CreateSocialLink(), mw.CSRF)
+	contactsGroup.PUT("/links/:link_id", h.UpdateSocialLink(), mw.CSRF)
+	contactsGroup.DELETE("/links/:link_id", h.DeleteSocialLink(), mw.CSRF)
+}
+
+// Map read only contacts routes reachable with a scoped token from the token exchange
+func MapScopedContactsRoutes(scopedGroup *echo.Group, h contacts.Handlers, mw *middleware.MiddlewareManager) {
+	scopedGroup.GET("/me/contacts", h.GetContacts(), mw.ScopedTokenMiddleware(models.ScopeReadContacts))
+}
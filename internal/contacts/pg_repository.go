@@ -0,0 +1,24 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package contacts
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Contacts repository interface, updates and deletes are scoped to the owning user and return sql.ErrNoRows otherwise
+type Repository interface {
+	ListAddresses(ctx context.Context, userID int) ([]*models.Address, error)
+	CreateAddress(ctx context.Context, address *models.Address) (*models.Address, error)
+	UpdateAddress(ctx context.Context, address *models.Address) (*models.Address, error)
+	DeleteAddress(ctx context.Context, userID int, addressID int64) error
+	ListPhones(ctx context.Context, userID int) ([]*models.PhoneNumber, error)
+	CreatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error)
+	UpdatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error)
+	DeletePhone(ctx context.Context, userID int, phoneID int64) error
+	ListSocialLinks(ctx context.Context, userID int) ([]*models.SocialLink, error)
+	CreateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error)
+	UpdateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error)
+	DeleteSocialLink(ctx context.Context, userID int, linkID int64) error
+}
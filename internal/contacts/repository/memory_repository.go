@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Contacts Repository kept in process memory, dev mode stand-in for Postgres
+type contactsMemoryRepo struct {
+	mu        sync.RWMutex
+	lastID    int64
+	addresses map[int64]models.Address
+	phones    map[int64]models.PhoneNumber
+	links     map[int64]models.SocialLink
+}
+
+// Contacts in-memory Repository constructor
+func NewContactsMemoryRepository() contacts.Repository {
+	return &contactsMemoryRepo{
+		addresses: make(map[int64]models.Address),
+		phones:    make(map[int64]models.PhoneNumber),
+		links:     make(map[int64]models.SocialLink),
+	}
+}
+
+// List addresses of a user, primary first
+func (r *contactsMemoryRepo) ListAddresses(ctx context.Context, userID int) ([]*models.Address, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.ListAddresses")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addresses := make([]*models.Address, 0)
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			address := address
+			addresses = append(addresses, &address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].IsPrimary != addresses[j].IsPrimary {
+			return addresses[i].IsPrimary
+		}
+		return addresses[i].ID < addresses[j].ID
+	})
+	return addresses, nil
+}
+
+// Create address, a primary address demotes the previous one
+func (r *contactsMemoryRepo) CreateAddress(ctx context.Context, address *models.Address) (*models.Address, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.CreateAddress")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *address
+	created.ID = r.lastID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if created.IsPrimary {
+		r.clearPrimaryAddress(created.UserID)
+	}
+	r.addresses[created.ID] = created
+	return &created, nil
+}
+
+// Update address of its user, a primary address demotes the previous one
+func (r *contactsMemoryRepo) UpdateAddress(ctx context.Context, address *models.Address) (*models.Address, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.UpdateAddress")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.addresses[address.ID]
+	if !ok || stored.UserID != address.UserID {
+		return nil, errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.UpdateAddress")
+	}
+	updated := *address
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	if updated.IsPrimary {
+		r.clearPrimaryAddress(updated.UserID)
+	}
+	r.addresses[updated.ID] = updated
+	return &updated, nil
+}
+
+// Delete address of a user
+func (r *contactsMemoryRepo) DeleteAddress(ctx context.Context, userID int, addressID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.DeleteAddress")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.addresses[addressID]; !ok || stored.UserID != userID {
+		return errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.DeleteAddress")
+	}
+	delete(r.addresses, addressID)
+	return nil
+}
+
+// List phone numbers of a user, primary first
+func (r *contactsMemoryRepo) ListPhones(ctx context.Context, userID int) ([]*models.PhoneNumber, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.ListPhones")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	phones := make([]*models.PhoneNumber, 0)
+	for _, phone := range r.phones {
+		if phone.UserID == userID {
+			phone := phone
+			phones = append(phones, &phone)
+		}
+	}
+	sort.Slice(phones, func(i, j int) bool {
+		if phones[i].IsPrimary != phones[j].IsPrimary {
+			return phones[i].IsPrimary
+		}
+		return phones[i].ID < phones[j].ID
+	})
+	return phones, nil
+}
+
+// Create phone number, a primary number demotes the previous one
+func (r *contactsMemoryRepo) CreatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.CreatePhone")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *phone
+	created.ID = r.lastID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	if created.IsPrimary {
+		r.clearPrimaryPhone(created.UserID)
+	}
+	r.phones[created.ID] = created
+	return &created, nil
+}
+
+// Update phone number of its user, a primary number demotes the previous one
+func (r *contactsMemoryRepo) UpdatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.UpdatePhone")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.phones[phone.ID]
+	if !ok || stored.UserID != phone.UserID {
+		return nil, errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.UpdatePhone")
+	}
+	updated := *phone
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	if updated.IsPrimary {
+		r.clearPrimaryPhone(updated.UserID)
+	}
+	r.phones[updated.ID] = updated
+	return &updated, nil
+}
+
+// Delete phone number of a user
+func (r *contactsMemoryRepo) DeletePhone(ctx context.Context, userID int, phoneID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.DeletePhone")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.phones[phoneID]; !ok || stored.UserID != userID {
+		return errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.DeletePhone")
+	}
+	delete(r.phones, phoneID)
+	return nil
+}
+
+// List social links of a user ordered by network
+func (r *contactsMemoryRepo) ListSocialLinks(ctx context.Context, userID int) ([]*models.SocialLink, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.ListSocialLinks")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	links := make([]*models.SocialLink, 0)
+	for _, link := range r.links {
+		if link.UserID == userID {
+			link := link
+			links = append(links, &link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Network < links[j].Network })
+	return links, nil
+}
+
+// Create social link
+func (r *contactsMemoryRepo) CreateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.CreateSocialLink")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *link
+	created.ID = r.lastID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.links[created.ID] = created
+	return &created, nil
+}
+
+// Update social link of its user
+func (r *contactsMemoryRepo) UpdateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.UpdateSocialLink")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.links[link.ID]
+	if !ok || stored.UserID != link.UserID {
+		return nil, errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.UpdateSocialLink")
+	}
+	updated := *link
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	r.links[updated.ID] = updated
+	return &updated, nil
+}
+
+// Delete social link of a user
+func (r *contactsMemoryRepo) DeleteSocialLink(ctx context.Context, userID int, linkID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "contactsMemoryRepo.DeleteSocialLink")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.links[linkID]; !ok || stored.UserID != userID {
+		return errors.Wrap(sql.ErrNoRows, "contactsMemoryRepo.DeleteSocialLink")
+	}
+	delete(r.links, linkID)
+	return nil
+}
+
+func (r *contactsMemoryRepo) clearPrimaryAddress(userID int) {
+	for id, address := range r.addresses {
+		if address.UserID == userID && address.IsPrimary {
+			address.IsPrimary = false
+			r.addresses[id] = address
+		}
+	}
+}
+
+func (r *contactsMemoryRepo) clearPrimaryPhone(userID int) {
+	for id, phone := range r.phones {
+		if phone.UserID == userID && phone.IsPrimary {
+			phone.IsPrimary = false
+			r.phones[id] = phone
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+)
+
+// Associated data of encrypted contact columns
+const (
+	piiFieldAddressLine1 = "user_addresses.line1"
+	piiFieldAddressLine2 = "user_addresses.line2"
+	piiFieldPhoneNumber  = "user_phones.number"
+)
+
+// Contacts Repository
+type contactsRepo struct {
+	db     *sqlx.DB
+	cipher *pii.Cipher
+}
+
+// Contacts Repository constructor, street lines and phone numbers are encrypted like user PII
+func NewContactsRepository(db *sqlx.DB, cipher *pii.Cipher) contacts.Repository {
+	return &contactsRepo{db: db, cipher: cipher}
+}
+
+// List addresses of a user, primary first
+func (r *contactsRepo) ListAddresses(ctx context.Context, userID int) ([]*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.ListAddresses")
+	defer span.Finish()
+
+	addresses := make([]*models.Address, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &addresses, listAddressesQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.ListAddresses.SelectContext")
+	}
+	for _, address := range addresses {
+		if err := r.decryptAddress(address); err != nil {
+			return nil, errors.Wrap(err, "contactsRepo.ListAddresses.decryptAddress")
+		}
+	}
+	return addresses, nil
+}
+
+// Create address, a primary address demotes the previous one
+func (r *contactsRepo) CreateAddress(ctx context.Context, address *models.Address) (*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.CreateAddress")
+	defer span.Finish()
+
+	line1, line2, err := r.encryptAddress(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreateAddress.encryptAddress")
+	}
+
+	created := &models.Address{}
+	err = r.inTx(ctx, func(tx *sqlx.Tx) error {
+		if address.IsPrimary {
+			if _, err := tx.ExecContext(ctx, clearPrimaryAddressQuery, address.UserID, 0); err != nil {
+				return errors.Wrap(err, "clearPrimaryAddress")
+			}
+		}
+		return tx.QueryRowxContext(
+			ctx,
+			createAddressQuery,
+			address.UserID,
+			address.Label,
+			line1,
+			line2,
+			address.City,
+			address.Region,
+			address.PostalCode,
+			address.Country,
+			address.IsPrimary,
+		).StructScan(created)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreateAddress")
+	}
+	if err := r.decryptAddress(created); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreateAddress.decryptAddress")
+	}
+	return created, nil
+}
+
+// Update address of its user, a primary address demotes the previous one
+func (r *contactsRepo) UpdateAddress(ctx context.Context, address *models.Address) (*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.UpdateAddress")
+	defer span.Finish()
+
+	line1, line2, err := r.encryptAddress(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdateAddress.encryptAddress")
+	}
+
+	updated := &models.Address{}
+	err = r.inTx(ctx, func(tx *sqlx.Tx) error {
+		if address.IsPrimary {
+			if _, err := tx.ExecContext(ctx, clearPrimaryAddressQuery, address.UserID, address.ID); err != nil {
+				return errors.Wrap(err, "clearPrimaryAddress")
+			}
+		}
+		return tx.QueryRowxContext(
+			ctx,
+			updateAddressQuery,
+			address.Label,
+			line1,
+			line2,
+			address.City,
+			address.Region,
+			address.PostalCode,
+			address.Country,
+			address.IsPrimary,
+			address.ID,
+			address.UserID,
+		).StructScan(updated)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdateAddress")
+	}
+	if err := r.decryptAddress(updated); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdateAddress.decryptAddress")
+	}
+	return updated, nil
+}
+
+// Delete address of a user
+func (r *contactsRepo) DeleteAddress(ctx context.Context, userID int, addressID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.DeleteAddress")
+	defer span.Finish()
+
+	return r.delete(ctx, deleteAddressQuery, addressID, userID, "contactsRepo.DeleteAddress")
+}
+
+// List phone numbers of a user, primary first
+func (r *contactsRepo) ListPhones(ctx context.Context, userID int) ([]*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.ListPhones")
+	defer span.Finish()
+
+	phones := make([]*models.PhoneNumber, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &phones, listPhonesQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.ListPhones.SelectContext")
+	}
+	for _, phone := range phones {
+		if err := r.decryptPhone(phone); err != nil {
+			return nil, errors.Wrap(err, "contactsRepo.ListPhones.decryptPhone")
+		}
+	}
+	return phones, nil
+}
+
+// Create phone number, a primary number demotes the previous one
+func (r *contactsRepo) CreatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.CreatePhone")
+	defer span.Finish()
+
+	number, err := r.cipher.Encrypt(piiFieldPhoneNumber, phone.Number)
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreatePhone.Encrypt")
+	}
+
+	created := &models.PhoneNumber{}
+	err = r.inTx(ctx, func(tx *sqlx.Tx) error {
+		if phone.IsPrimary {
+			if _, err := tx.ExecContext(ctx, clearPrimaryPhoneQuery, phone.UserID, 0); err != nil {
+				return errors.Wrap(err, "clearPrimaryPhone")
+			}
+		}
+		return tx.QueryRowxContext(ctx, createPhoneQuery, phone.UserID, phone.Label, number, phone.IsPrimary).StructScan(created)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreatePhone")
+	}
+	if err := r.decryptPhone(created); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreatePhone.decryptPhone")
+	}
+	return created, nil
+}
+
+// Update phone number of its user, a primary number demotes the previous one
+func (r *contactsRepo) UpdatePhone(ctx context.Context, phone *models.PhoneNumber) (*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.UpdatePhone")
+	defer span.Finish()
+
+	number, err := r.cipher.Encrypt(piiFieldPhoneNumber, phone.Number)
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdatePhone.Encrypt")
+	}
+
+	updated := &models.PhoneNumber{}
+	err = r.inTx(ctx, func(tx *sqlx.Tx) error {
+		if phone.IsPrimary {
+			if _, err := tx.ExecContext(ctx, clearPrimaryPhoneQuery, phone.UserID, phone.ID); err != nil {
+				return errors.Wrap(err, "clearPrimaryPhone")
+			}
+		}
+		return tx.QueryRowxContext(ctx, updatePhoneQuery, phone.Label, number, phone.IsPrimary, phone.ID, phone.UserID).StructScan(updated)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdatePhone")
+	}
+	if err := r.decryptPhone(updated); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdatePhone.decryptPhone")
+	}
+	return updated, nil
+}
+
+// Delete phone number of a user
+func (r *contactsRepo) DeletePhone(ctx context.Context, userID int, phoneID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.DeletePhone")
+	defer span.Finish()
+
+	return r.delete(ctx, deletePhoneQuery, phoneID, userID, "contactsRepo.DeletePhone")
+}
+
+// List social links of a user ordered by network
+func (r *contactsRepo) ListSocialLinks(ctx context.Context, userID int) ([]*models.SocialLink, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.ListSocialLinks")
+	defer span.Finish()
+
+	links := make([]*models.SocialLink, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &links, listSocialLinksQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.ListSocialLinks.SelectContext")
+	}
+	return links, nil
+}
+
+// Create social link
+func (r *contactsRepo) CreateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.CreateSocialLink")
+	defer span.Finish()
+
+	created := &models.SocialLink{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(ctx, createSocialLinkQuery, link.UserID, link.Network, link.URL).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.CreateSocialLink.StructScan")
+	}
+	return created, nil
+}
+
+// Update social link of its user
+func (r *contactsRepo) UpdateSocialLink(ctx context.Context, link *models.SocialLink) (*models.SocialLink, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.UpdateSocialLink")
+	defer span.Finish()
+
+	updated := &models.SocialLink{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(ctx, updateSocialLinkQuery, link.Network, link.URL, link.ID, link.UserID).StructScan(updated); err != nil {
+		return nil, errors.Wrap(err, "contactsRepo.UpdateSocialLink.StructScan")
+	}
+	return updated, nil
+}
+
+// Delete social link of a user
+func (r *contactsRepo) DeleteSocialLink(ctx context.Context, userID int, linkID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsRepo.DeleteSocialLink")
+	defer span.Finish()
+
+	return r.delete(ctx, deleteSocialLinkQuery, linkID, userID, "contactsRepo.DeleteSocialLink")
+}
+
+func (r *contactsRepo) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "Commit")
+}
+
+func (r *contactsRepo) delete(ctx context.Context, query string, id int64, userID int, op string) error {
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return errors.Wrap(err, op+".ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op+".RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, op+".rowsAffected")
+	}
+	return nil
+}
+
+func (r *contactsRepo) encryptAddress(address *models.Address) (string, string, error) {
+	line1, err := r.cipher.Encrypt(piiFieldAddressLine1, address.Line1)
+	if err != nil {
+		return "", "", err
+	}
+	line2, err := r.cipher.Encrypt(piiFieldAddressLine2, address.Line2)
+	if err != nil {
+		return "", "", err
+	}
+	return line1, line2, nil
+}
+
+func (r *contactsRepo) decryptAddress(address *models.Address) error {
+	line1, err := r.cipher.Decrypt(piiFieldAddressLine1, address.Line1)
+	if err != nil {
+		return err
+	}
+	line2, err := r.cipher.Decrypt(piiFieldAddressLine2, address.Line2)
+	if err != nil {
+		return err
+	}
+	address.Line1, address.Line2 = line1, line2
+	return nil
+}
+
+func (r *contactsRepo) decryptPhone(phone *models.PhoneNumber) error {
+	number, err := r.cipher.Decrypt(piiFieldPhoneNumber, phone.Number)
+	if err != nil {
+		return err
+	}
+	phone.Number = number
+	return nil
+}
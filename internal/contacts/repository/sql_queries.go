@@ -0,0 +1,59 @@
+package repository
+
+const (
+	listAddressesQuery = `SELECT id, user_id, label, line1, line2, city, region, postal_code, country, is_primary, created_at, updated_at
+						FROM user_addresses
+						WHERE user_id = $1
+						ORDER BY is_primary DESC, id`
+
+	createAddressQuery = `INSERT INTO user_addresses (user_id, label, line1, line2, city, region, postal_code, country, is_primary, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now(), now())
+						RETURNING *`
+
+	updateAddressQuery = `UPDATE user_addresses
+						SET label = $1, line1 = $2, line2 = $3, city = $4, region = $5, postal_code = $6, country = $7, is_primary = $8, updated_at = now()
+						WHERE id = $9 AND user_id = $10
+						RETURNING *`
+
+	clearPrimaryAddressQuery = `UPDATE user_addresses
+						SET is_primary = false, updated_at = now()
+						WHERE user_id = $1 AND id <> $2 AND is_primary`
+
+	deleteAddressQuery = `DELETE FROM user_addresses WHERE id = $1 AND user_id = $2`
+
+	listPhonesQuery = `SELECT id, user_id, label, number, is_primary, created_at, updated_at
+						FROM user_phones
+						WHERE user_id = $1
+						ORDER BY is_primary DESC, id`
+
+	createPhoneQuery = `INSERT INTO user_phones (user_id, label, number, is_primary, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, now(), now())
+						RETURNING *`
+
+	updatePhoneQuery = `UPDATE user_phones
+						SET label = $1, number = $2, is_primary = $3, updated_at = now()
+						WHERE id = $4 AND user_id = $5
+						RETURNING *`
+
+	clearPrimaryPhoneQuery = `UPDATE user_phones
+						SET is_primary = false, updated_at = now()
+						WHERE user_id = $1 AND id <> $2 AND is_primary`
+
+	deletePhoneQuery = `DELETE FROM user_phones WHERE id = $1 AND user_id = $2`
+
+	listSocialLinksQuery = `SELECT id, user_id, network, url, created_at, updated_at
+						FROM user_social_links
+						WHERE user_id = $1
+						ORDER BY network`
+
+	createSocialLinkQuery = `INSERT INTO user_social_links (user_id, network, url, created_at, updated_at)
+						VALUES ($1, $2, $3, now(), now())
+						RETURNING *`
+
+	updateSocialLinkQuery = `UPDATE user_social_links
+						SET network = $1, url = $2, updated_at = now()
+						WHERE id = $3 AND user_id = $4
+						RETURNING *`
+
+	deleteSocialLinkQuery = `DELETE FROM user_social_links WHERE id = $1 AND user_id = $2`
+)
@@ -0,0 +1,24 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package contacts
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Contacts UseCase interface, every method acts on the user of the context
+type UseCase interface {
+	GetContacts(ctx context.Context) (*models.UserContacts, error)
+	CreateAddress(ctx context.Context, req *dto.AddressRequest) (*models.Address, error)
+	UpdateAddress(ctx context.Context, addressID int64, req *dto.AddressRequest) (*models.Address, error)
+	DeleteAddress(ctx context.Context, addressID int64) error
+	CreatePhone(ctx context.Context, req *dto.PhoneNumberRequest) (*models.PhoneNumber, error)
+	UpdatePhone(ctx context.Context, phoneID int64, req *dto.PhoneNumberRequest) (*models.PhoneNumber, error)
+	DeletePhone(ctx context.Context, phoneID int64) error
+	CreateSocialLink(ctx context.Context, req *dto.SocialLinkRequest) (*models.SocialLink, error)
+	UpdateSocialLink(ctx context.Context, linkID int64, req *dto.SocialLinkRequest) (*models.SocialLink, error)
+	DeleteSocialLink(ctx context.Context, linkID int64) error
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	errAddressLimit    = "Address limit reached"
+	errPhoneLimit      = "Phone number limit reached"
+	errSocialLinkLimit = "Social link limit reached"
+	errNetworkExists   = "Social link for this network already exists"
+)
+
+// Contacts UseCase
+type contactsUC struct {
+	cfg    *config.Config
+	repo   contacts.Repository
+	logger logger.Logger
+}
+
+// Contacts UseCase constructor
+func NewContactsUseCase(cfg *config.Config, repo contacts.Repository, logger logger.Logger) contacts.UseCase {
+	return &contactsUC{cfg: cfg, repo: repo, logger: logger}
+}
+
+// Get addresses, phone numbers and social links of the current user
+func (u *contactsUC) GetContacts(ctx context.Context) (*models.UserContacts, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.GetContacts")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := u.repo.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	phones, err := u.repo.ListPhones(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := u.repo.ListSocialLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserContacts{Addresses: addresses, Phones: phones, SocialLinks: links}, nil
+}
+
+// Add address of the current user, up to Contacts.MaxAddresses
+func (u *contactsUC) CreateAddress(ctx context.Context, req *dto.AddressRequest) (*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.CreateAddress")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAddress(ctx, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.repo.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := u.cfg.Contacts.MaxAddresses; limit > 0 && len(existing) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errAddressLimit, limit)
+	}
+
+	return u.repo.CreateAddress(ctx, toAddress(userID, 0, req))
+}
+
+// Replace address of the current user
+func (u *contactsUC) UpdateAddress(ctx context.Context, addressID int64, req *dto.AddressRequest) (*models.Address, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.UpdateAddress")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAddress(ctx, req); err != nil {
+		return nil, err
+	}
+	return u.repo.UpdateAddress(ctx, toAddress(userID, addressID, req))
+}
+
+// Delete address of the current user
+func (u *contactsUC) DeleteAddress(ctx context.Context, addressID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.DeleteAddress")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteAddress(ctx, userID, addressID)
+}
+
+// Add phone number of the current user, up to Contacts.MaxPhones
+func (u *contactsUC) CreatePhone(ctx context.Context, req *dto.PhoneNumberRequest) (*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.CreatePhone")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePhone(ctx, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.repo.ListPhones(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := u.cfg.Contacts.MaxPhones; limit > 0 && len(existing) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errPhoneLimit, limit)
+	}
+
+	return u.repo.CreatePhone(ctx, toPhoneNumber(userID, 0, req))
+}
+
+// Replace phone number of the current user
+func (u *contactsUC) UpdatePhone(ctx context.Context, phoneID int64, req *dto.PhoneNumberRequest) (*models.PhoneNumber, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.UpdatePhone")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePhone(ctx, req); err != nil {
+		return nil, err
+	}
+	return u.repo.UpdatePhone(ctx, toPhoneNumber(userID, phoneID, req))
+}
+
+// Delete phone number of the current user
+func (u *contactsUC) DeletePhone(ctx context.Context, phoneID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.DeletePhone")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeletePhone(ctx, userID, phoneID)
+}
+
+// Add social link of the current user, one per network and up to Contacts.MaxSocialLinks
+func (u *contactsUC) CreateSocialLink(ctx context.Context, req *dto.SocialLinkRequest) (*models.SocialLink, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.CreateSocialLink")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSocialLink(ctx, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.repo.ListSocialLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := u.cfg.Contacts.MaxSocialLinks; limit > 0 && len(existing) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errSocialLinkLimit, limit)
+	}
+	if networkTaken(existing, req.Network, 0) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errNetworkExists, req.Network)
+	}
+
+	return u.repo.CreateSocialLink(ctx, toSocialLink(userID, 0, req))
+}
+
+// Replace social link of the current user
+func (u *contactsUC) UpdateSocialLink(ctx context.Context, linkID int64, req *dto.SocialLinkRequest) (*models.SocialLink, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.UpdateSocialLink")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSocialLink(ctx, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.repo.ListSocialLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if networkTaken(existing, req.Network, linkID) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errNetworkExists, req.Network)
+	}
+
+	return u.repo.UpdateSocialLink(ctx, toSocialLink(userID, linkID, req))
+}
+
+// Delete social link of the current user
+func (u *contactsUC) DeleteSocialLink(ctx context.Context, linkID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "contactsUC.DeleteSocialLink")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteSocialLink(ctx, userID, linkID)
+}
+
+func currentUserID(ctx context.Context) (int, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return 0, httpErrors.NewUnauthorizedError(err)
+	}
+	return user.User.ID, nil
+}
+
+// Normalize address in place before validating, country codes are stored upper case
+func validateAddress(ctx context.Context, req *dto.AddressRequest) error {
+	req.Label = strings.TrimSpace(req.Label)
+	req.Line1 = strings.TrimSpace(req.Line1)
+	req.Line2 = strings.TrimSpace(req.Line2)
+	req.City = strings.TrimSpace(req.City)
+	req.Region = strings.TrimSpace(req.Region)
+	req.PostalCode = strings.ToUpper(strings.TrimSpace(req.PostalCode))
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	return nil
+}
+
+func validatePhone(ctx context.Context, req *dto.PhoneNumberRequest) error {
+	req.Label = strings.TrimSpace(req.Label)
+	req.Number = strings.TrimSpace(req.Number)
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	return nil
+}
+
+func validateSocialLink(ctx context.Context, req *dto.SocialLinkRequest) error {
+	req.Network = strings.ToLower(strings.TrimSpace(req.Network))
+	req.URL = strings.TrimSpace(req.URL)
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	return nil
+}
+
+func networkTaken(links []*models.SocialLink, network string, exceptID int64) bool {
+	for _, link := range links {
+		if link.Network == network && link.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func toAddress(userID int, addressID int64, req *dto.AddressRequest) *models.Address {
+	return &models.Address{
+		ID:         addressID,
+		UserID:     userID,
+		Label:      req.Label,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		IsPrimary:  req.IsPrimary,
+	}
+}
+
+func toPhoneNumber(userID int, phoneID int64, req *dto.PhoneNumberRequest) *models.PhoneNumber {
+	return &models.PhoneNumber{ID: phoneID, UserID: userID, Label: req.Label, Number: req.Number, IsPrimary: req.IsPrimary}
+}
+
+func toSocialLink(userID int, linkID int64, req *dto.SocialLinkRequest) *models.SocialLink {
+	return &models.SocialLink{ID: linkID, UserID: userID, Network: req.Network, URL: req.URL}
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestContactsUC_CreateAddress(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Contacts: config.Contacts{MaxAddresses: 1}}
+	mockRepo := mock.NewMockRepository(ctrl)
+	uc := NewContactsUseCase(cfg, mockRepo, testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+
+	mockRepo.EXPECT().ListAddresses(gomock.Any(), 1).Return([]*models.Address{}, nil)
+	mockRepo.EXPECT().CreateAddress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, address *models.Address) (*models.Address, error) {
+			require.Equal(t, 1, address.UserID)
+			require.Equal(t, "DE", address.Country)
+			require.Equal(t, "Hauptstr. 1", address.Line1)
+			return address, nil
+		})
+	_, err := uc.CreateAddress(ctx, &dto.AddressRequest{Line1: " Hauptstr. 1 ", City: "Berlin", Country: "de"})
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().ListAddresses(gomock.Any(), 1).Return([]*models.Address{{ID: 1}}, nil)
+	_, err = uc.CreateAddress(ctx, &dto.AddressRequest{Line1: "Hauptstr. 2", City: "Berlin", Country: "DE"})
+	require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+
+	_, err = uc.CreateAddress(ctx, &dto.AddressRequest{Line1: "Hauptstr. 2", City: "Berlin", Country: "XX"})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+}
+
+func TestContactsUC_SocialLinkNetworkIsUnique(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+	mockRepo := mock.NewMockRepository(ctrl)
+	uc := NewContactsUseCase(cfg, mockRepo, testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+	existing := []*models.SocialLink{{ID: 3, Network: "github", URL: "https://github.com/a"}}
+
+	mockRepo.EXPECT().ListSocialLinks(gomock.Any(), 1).Return(existing, nil)
+	_, err := uc.CreateSocialLink(ctx, &dto.SocialLinkRequest{Network: "GitHub", URL: "https://github.com/b"})
+	require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+
+	mockRepo.EXPECT().ListSocialLinks(gomock.Any(), 1).Return(existing, nil)
+	mockRepo.EXPECT().UpdateSocialLink(gomock.Any(), &models.SocialLink{ID: 3, UserID: 1, Network: "github", URL: "https://github.com/b"}).
+		Return(&models.SocialLink{ID: 3}, nil)
+	_, err = uc.UpdateSocialLink(ctx, 3, &dto.SocialLinkRequest{Network: "github", URL: "https://github.com/b"})
+	require.NoError(t, err)
+}
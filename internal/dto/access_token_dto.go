@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// Personal access token to mint, without ExpiresAt it is valid until revoked unless AccessTokens.MaxLifetimeDays is set
+type PersonalAccessTokenRequest struct {
+	Name      string     `json:"name" validate:"required,lte=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=api read:profile read:contacts"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
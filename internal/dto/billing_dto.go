@@ -0,0 +1,31 @@
+package dto
+
+import "encoding/json"
+
+// Billing provider webhook event in the Stripe shape, Created is in unix seconds
+type BillingEvent struct {
+	ID      string           `json:"id" validate:"required,lte=255"`
+	Type    string           `json:"type" validate:"required,lte=100"`
+	Created int64            `json:"created" validate:"required"`
+	Data    BillingEventData `json:"data"`
+}
+
+// Object the event is about, decoded by event type
+type BillingEventData struct {
+	Object json.RawMessage `json:"object"`
+}
+
+// Subscription object of customer.subscription.* events, the subject is the organization_id or user_id in Metadata
+type BillingSubscription struct {
+	ID               string            `json:"id" validate:"required,lte=255"`
+	Customer         string            `json:"customer" validate:"required,lte=255"`
+	Status           string            `json:"status" validate:"required,lte=50"`
+	CurrentPeriodEnd int64             `json:"current_period_end"`
+	Metadata         map[string]string `json:"metadata"`
+	Plan             BillingPlanRef    `json:"plan"`
+}
+
+// Plan of a subscription, ID names one of the configured billing plans
+type BillingPlanRef struct {
+	ID string `json:"id" validate:"required,lte=100"`
+}
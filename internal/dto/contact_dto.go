@@ -0,0 +1,23 @@
+package dto
+
+type AddressRequest struct {
+	Label      string `json:"label" validate:"omitempty,lte=40"`
+	Line1      string `json:"line1" validate:"required,lte=200"`
+	Line2      string `json:"line2" validate:"omitempty,lte=200"`
+	City       string `json:"city" validate:"required,lte=100"`
+	Region     string `json:"region" validate:"omitempty,lte=100"`
+	PostalCode string `json:"postal_code" validate:"omitempty,lte=20"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2"`
+	IsPrimary  bool   `json:"is_primary"`
+}
+
+type PhoneNumberRequest struct {
+	Label     string `json:"label" validate:"omitempty,lte=40"`
+	Number    string `json:"number" validate:"required,e164"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+type SocialLinkRequest struct {
+	Network string `json:"network" validate:"required,oneof=website github gitlab linkedin twitter mastodon facebook instagram youtube"`
+	URL     string `json:"url" validate:"required,lte=500,http_url"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type LogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error dpanic panic fatal"`
+}
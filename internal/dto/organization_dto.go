@@ -0,0 +1,25 @@
+package dto
+
+type OrganizationRequest struct {
+	Name               string `json:"name" validate:"required,lte=255"`
+	BillingEmail       string `json:"billing_email" validate:"omitempty,lte=255,email"`
+	AllowMemberInvites bool   `json:"allow_member_invites"`
+}
+
+type OrganizationQuotasRequest struct {
+	MaxMembers            int `json:"max_members" validate:"gte=0"`
+	MaxPendingInvitations int `json:"max_pending_invitations" validate:"gte=0"`
+}
+
+type OrganizationMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type OrganizationInvitationRequest struct {
+	Email string `json:"email" validate:"required,lte=255,email"`
+	Role  string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required,lte=100"`
+}
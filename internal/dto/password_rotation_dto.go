@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+
+// Campaign flagging the users matching Filter, or the listed ones
+type PasswordRotationCampaignRequest struct {
+	Name    string                         `json:"name" validate:"required,lte=100"`
+	Reason  string                         `json:"reason" validate:"lte=1000"`
+	Filter  *models.PasswordRotationFilter `json:"filter"`
+	UserIDs []int                          `json:"user_ids" validate:"omitempty,dive,gt=0"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type RegistrationInvitationRequest struct {
+	RoleName         string `json:"role_name" validate:"omitempty,lte=30"`
+	OrganizationID   *int64 `json:"organization_id" validate:"omitempty,gt=0"`
+	OrganizationRole string `json:"organization_role" validate:"required_with=OrganizationID,omitempty,oneof=admin member"`
+	// Zero takes the configured default
+	MaxUses    int `json:"max_uses" validate:"gte=0,lte=10000"`
+	TTLSeconds int `json:"ttl_seconds" validate:"gte=0"`
+}
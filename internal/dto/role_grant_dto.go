@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// Grant of a role to a user until ExpiresAt
+type RoleGrantRequest struct {
+	Role      string    `json:"role" validate:"required,lte=30"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+	Reason    string    `json:"reason" validate:"lte=1000"`
+}
+
+// Permissions to check for the current user, one permission is the action on the resource
+type PermissionCheckRequest struct {
+	Checks []PermissionCheck `json:"checks" validate:"required,min=1,max=100,dive"`
+}
+
+// Action on a resource, checked as the permission "<resource>:<action>"
+type PermissionCheck struct {
+	Resource string `json:"resource" validate:"required,lte=100"`
+	Action   string `json:"action" validate:"required,lte=100"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+import "encoding/json"
+
+type SettingRequest struct {
+	Value json.RawMessage `json:"value" validate:"required"`
+}
@@ -4,9 +4,56 @@ type RegisterUserRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 	Email    string `json:"email" validate:"omitempty,lte=60,email"`
+	// Required while registration is invite only
+	InvitationToken string `json:"invitation_token,omitempty" validate:"omitempty,lte=100"`
+	// Role of the redeemed invitation, never read from the request
+	RoleName string `json:"-"`
+}
+
+type ReauthenticateRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// Bcrypt ignores everything past 72 bytes
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,gte=8,lte=72"`
 }
 
 type LoginUserRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	// Keep this device signed in past the session with a remember-me cookie
+	RememberMe bool `json:"remember_me"`
+}
+
+type LoginOTPRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,numeric"`
+	// Remember-me choice of the login the mfa token came from
+	RememberMe bool `json:"remember_me"`
+}
+
+// Returned by login instead of a session when the user has a second factor
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+type SendPhoneOTPRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric"`
+}
+
+type SMS2FARequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Exchange of the current session for a scoped token, TTLSeconds defaults to and is capped by config
+type TokenExchangeRequest struct {
+	Scopes     []string `json:"scopes" validate:"required,min=1,dive,oneof=read:profile read:contacts"`
+	TTLSeconds int      `json:"ttl_seconds" validate:"omitempty,min=1"`
 }
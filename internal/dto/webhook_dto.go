@@ -0,0 +1,6 @@
+package dto
+
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,lte=500,http_url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=login.new_device login.suspicious profile.updated role_grant.expired"`
+}
@@ -0,0 +1,10 @@
+package emailpolicy
+
+import "github.com/labstack/echo/v4"
+
+// Email domain policy HTTP Handlers interface
+type Handlers interface {
+	GetRules() echo.HandlerFunc
+	AddRule() echo.HandlerFunc
+	RemoveRule() echo.HandlerFunc
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Email domain policy handlers
+type emailPolicyHandlers struct {
+	cfg           *config.Config
+	emailPolicyUC emailpolicy.UseCase
+	logger        logger.Logger
+}
+
+// NewEmailPolicyHandlers Email domain policy handlers constructor
+func NewEmailPolicyHandlers(cfg *config.Config, emailPolicyUC emailpolicy.UseCase, log logger.Logger) emailpolicy.Handlers {
+	return &emailPolicyHandlers{cfg: cfg, emailPolicyUC: emailPolicyUC, logger: log}
+}
+
+// GetRules godoc
+// @Summary Get email domain rules
+// @Description Allow and deny domains of registration, config and admin managed entries merged, admin only
+// @Tags EmailPolicy
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.EmailDomainRules
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/email-domains [get]
+func (h *emailPolicyHandlers) GetRules() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "emailPolicyHandlers.GetRules")
+		defer span.Finish()
+
+		rules, err := h.emailPolicyUC.GetRules(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rules)
+	}
+}
+
+// AddRule godoc
+// @Summary Add email domain rule
+// @Description Add a domain to the allow or deny list of registration, subdomains match too, admin only
+// @Tags EmailPolicy
+// @Accept json
+// @Produce json
+// @Param list path string true "allow or deny"
+// @Param rule body models.EmailDomainRule true "rule"
+// @Success 201 {object} models.EmailDomainRule
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/email-domains/{list} [post]
+func (h *emailPolicyHandlers) AddRule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "emailPolicyHandlers.AddRule")
+		defer span.Finish()
+
+		rule := &models.EmailDomainRule{}
+		if err := utils.ReadRequest(c, rule); err != nil {
+			return err
+		}
+
+		if err := h.emailPolicyUC.AddRule(ctx, c.Param("list"), rule.Domain); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// RemoveRule godoc
+// @Summary Remove email domain rule
+// @Description Remove an admin managed domain from the allow or deny list of registration, admin only
+// @Tags EmailPolicy
+// @Accept json
+// @Produce json
+// @Param list path string true "allow or deny"
+// @Param rule body models.EmailDomainRule true "rule"
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/email-domains/{list} [delete]
+func (h *emailPolicyHandlers) RemoveRule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "emailPolicyHandlers.RemoveRule")
+		defer span.Finish()
+
+		rule := &models.EmailDomainRule{}
+		if err := utils.ReadRequest(c, rule); err != nil {
+			return err
+		}
+
+		if err := h.emailPolicyUC.RemoveRule(ctx, c.Param("list"), rule.Domain); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
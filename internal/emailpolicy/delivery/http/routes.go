@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map email domain policy routes, group is already restricted to administrators
+func MapEmailPolicyRoutes(adminGroup *echo.Group, h emailpolicy.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.GET("/email-domains", h.GetRules())
+	adminGroup.POST("/email-domains/:list", h.AddRule(), mw.CSRF)
+	adminGroup.DELETE("/email-domains/:list", h.RemoveRule(), mw.CSRF)
+}
@@ -0,0 +1,76 @@
+package emailpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Rejection reasons, returned as error codes and used as metric labels
+const (
+	ReasonInvalidEmail = "invalid_email"
+	ReasonDenied       = "domain_denied"
+	ReasonNotAllowed   = "domain_not_allowed"
+	ReasonDisposable   = "disposable_domain"
+	ReasonNoMX         = "domain_without_mx"
+)
+
+// Typed policy error, implements httpErrors.RestErr so it maps to its own status
+type Error struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+}
+
+var (
+	ErrInvalidEmail = &Error{ErrStatus: http.StatusBadRequest, ErrError: "email address is invalid", Code: ReasonInvalidEmail}
+	ErrDenied       = &Error{ErrStatus: http.StatusUnprocessableEntity, ErrError: "email domain is not accepted", Code: ReasonDenied}
+	ErrNotAllowed   = &Error{ErrStatus: http.StatusUnprocessableEntity, ErrError: "email domain is not on the allow list", Code: ReasonNotAllowed}
+	ErrDisposable   = &Error{ErrStatus: http.StatusUnprocessableEntity, ErrError: "disposable email addresses are not accepted", Code: ReasonDisposable}
+	ErrNoMX         = &Error{ErrStatus: http.StatusUnprocessableEntity, ErrError: "email domain does not receive mail", Code: ReasonNoMX}
+)
+
+var reasonErrors = map[string]*Error{
+	ReasonInvalidEmail: ErrInvalidEmail,
+	ReasonDenied:       ErrDenied,
+	ReasonNotAllowed:   ErrNotAllowed,
+	ReasonDisposable:   ErrDisposable,
+	ReasonNoMX:         ErrNoMX,
+}
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s", e.ErrStatus, e.ErrError)
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Policy errors carry no causes, the matched rule stays in logs
+func (e *Error) Causes() interface{} {
+	return nil
+}
+
+// Error of a rejected decision
+func DecisionError(decision *models.EmailDomainDecision) error {
+	if decision.Allowed {
+		return nil
+	}
+	if err, ok := reasonErrors[decision.Reason]; ok {
+		return err
+	}
+	return ErrDenied
+}
+
+// Get rejection reason, empty for errors outside the email policy
+func ErrorReason(err error) string {
+	var policyErr *Error
+	if errors.As(err, &policyErr) {
+		return policyErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,10 @@
+package emailpolicy
+
+import "context"
+
+// Email domain policy redis repository, holds admin managed rules
+type RedisRepository interface {
+	GetRules(ctx context.Context, list string) ([]string, error)
+	AddRule(ctx context.Context, list string, domain string) error
+	RemoveRule(ctx context.Context, list string, domain string) error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
+)
+
+// Email domain policy redis repository
+type emailPolicyRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Email domain policy redis repository constructor
+func NewEmailPolicyRedisRepo(redisClient *redis.Client, prefix string) emailpolicy.RedisRepository {
+	return &emailPolicyRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Get admin managed domains of a list
+func (r *emailPolicyRedisRepo) GetRules(ctx context.Context, list string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyRedisRepo.GetRules")
+	defer span.Finish()
+
+	rules, err := r.redisClient.SMembers(ctx, r.createKey(list)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "emailPolicyRedisRepo.GetRules.SMembers")
+	}
+	return rules, nil
+}
+
+// Add domain to a list
+func (r *emailPolicyRedisRepo) AddRule(ctx context.Context, list string, domain string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyRedisRepo.AddRule")
+	defer span.Finish()
+
+	if err := r.redisClient.SAdd(ctx, r.createKey(list), domain).Err(); err != nil {
+		return errors.Wrap(err, "emailPolicyRedisRepo.AddRule.SAdd")
+	}
+	return nil
+}
+
+// Remove domain from a list
+func (r *emailPolicyRedisRepo) RemoveRule(ctx context.Context, list string, domain string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyRedisRepo.RemoveRule")
+	defer span.Finish()
+
+	if err := r.redisClient.SRem(ctx, r.createKey(list), domain).Err(); err != nil {
+		return errors.Wrap(err, "emailPolicyRedisRepo.RemoveRule.SRem")
+	}
+	return nil
+}
+
+func (r *emailPolicyRedisRepo) createKey(list string) string {
+	return fmt.Sprintf("%s:%s", r.prefix, list)
+}
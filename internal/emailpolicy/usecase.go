@@ -0,0 +1,28 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package emailpolicy
+
+import (
+	"context"
+	"net"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+const (
+	ListAllow = "allow"
+	ListDeny  = "deny"
+)
+
+// Email domain policy use case
+type UseCase interface {
+	Check(ctx context.Context, email string) (*models.EmailDomainDecision, error)
+	GetRules(ctx context.Context) (*models.EmailDomainRules, error)
+	AddRule(ctx context.Context, list string, domain string) error
+	RemoveRule(ctx context.Context, list string, domain string) error
+}
+
+// DNS lookups of the MX check, satisfied by *net.Resolver
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+// Well known disposable mailbox providers, config DisposableDomains extends the list
+var disposableDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"emailondeck.com",
+	"fakeinbox.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"guerrillamail.net",
+	"maildrop.cc",
+	"mailinator.com",
+	"mailnesia.com",
+	"mintemail.com",
+	"mohmal.com",
+	"sharklasers.com",
+	"spamgourmet.com",
+	"temp-mail.org",
+	"tempmail.dev",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
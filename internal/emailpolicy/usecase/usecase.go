@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultRefreshSeconds = 30
+	defaultMXTimeout      = 2 * time.Second
+)
+
+// Config and admin managed domains, reloaded from redis once stale
+type domainRules struct {
+	allow    []string
+	deny     []string
+	loadedAt time.Time
+}
+
+// Email domain policy UseCase
+type emailPolicyUC struct {
+	cfg        *config.Config
+	redisRepo  emailpolicy.RedisRepository
+	resolver   emailpolicy.Resolver
+	clock      clock.Clock
+	logger     logger.Logger
+	disposable map[string]bool
+
+	mu    sync.RWMutex
+	rules *domainRules
+}
+
+// Email domain policy UseCase constructor, resolver is only used with CheckMX
+func NewEmailPolicyUseCase(
+	cfg *config.Config,
+	redisRepo emailpolicy.RedisRepository,
+	resolver emailpolicy.Resolver,
+	clk clock.Clock,
+	logger logger.Logger,
+) emailpolicy.UseCase {
+	disposable := make(map[string]bool, len(disposableDomains)+len(cfg.EmailPolicy.DisposableDomains))
+	for _, domain := range append(append([]string{}, disposableDomains...), cfg.EmailPolicy.DisposableDomains...) {
+		disposable[normalizeDomain(domain)] = true
+	}
+	return &emailPolicyUC{cfg: cfg, redisRepo: redisRepo, resolver: resolver, clock: clk, logger: logger, disposable: disposable}
+}
+
+// Check domain of email, deny rules win, a non empty allow list rejects everything it does not match and
+// explicitly allowed domains skip the disposable and MX checks. Rules match the domain and its subdomains.
+func (u *emailPolicyUC) Check(ctx context.Context, email string) (*models.EmailDomainDecision, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyUC.Check")
+	defer span.Finish()
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return &models.EmailDomainDecision{Allowed: false, Reason: emailpolicy.ReasonInvalidEmail}, nil
+	}
+	domain := normalizeDomain(email[at+1:])
+	if !u.cfg.EmailPolicy.Enabled {
+		return &models.EmailDomainDecision{Allowed: true, Domain: domain}, nil
+	}
+
+	rules, err := u.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule, ok := matchDomain(domain, rules.deny); ok {
+		return &models.EmailDomainDecision{Allowed: false, Domain: domain, Reason: emailpolicy.ReasonDenied, Rule: rule}, nil
+	}
+	if len(rules.allow) > 0 {
+		if rule, ok := matchDomain(domain, rules.allow); ok {
+			return &models.EmailDomainDecision{Allowed: true, Domain: domain, Rule: rule}, nil
+		}
+		return &models.EmailDomainDecision{Allowed: false, Domain: domain, Reason: emailpolicy.ReasonNotAllowed}, nil
+	}
+
+	if u.cfg.EmailPolicy.BlockDisposable {
+		if rule, ok := u.matchDisposable(domain); ok {
+			return &models.EmailDomainDecision{Allowed: false, Domain: domain, Reason: emailpolicy.ReasonDisposable, Rule: rule}, nil
+		}
+	}
+
+	if u.cfg.EmailPolicy.CheckMX && !u.receivesMail(ctx, domain) {
+		return &models.EmailDomainDecision{Allowed: false, Domain: domain, Reason: emailpolicy.ReasonNoMX}, nil
+	}
+	return &models.EmailDomainDecision{Allowed: true, Domain: domain}, nil
+}
+
+// Get config and admin managed rules
+func (u *emailPolicyUC) GetRules(ctx context.Context) (*models.EmailDomainRules, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyUC.GetRules")
+	defer span.Finish()
+
+	rules, err := u.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.EmailDomainRules{
+		Allow:           rules.allow,
+		Deny:            rules.deny,
+		BlockDisposable: u.cfg.EmailPolicy.BlockDisposable,
+		CheckMX:         u.cfg.EmailPolicy.CheckMX,
+	}, nil
+}
+
+// Add admin managed rule
+func (u *emailPolicyUC) AddRule(ctx context.Context, list string, domain string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyUC.AddRule")
+	defer span.Finish()
+
+	if err := validateList(list); err != nil {
+		return err
+	}
+	domain = normalizeDomain(domain)
+	if !strings.Contains(domain, ".") {
+		return httpErrors.NewBadRequestError(errors.Errorf("invalid domain %q", domain))
+	}
+
+	if err := u.redisRepo.AddRule(ctx, list, domain); err != nil {
+		return err
+	}
+	u.invalidate()
+	return nil
+}
+
+// Remove admin managed rule
+func (u *emailPolicyUC) RemoveRule(ctx context.Context, list string, domain string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "emailPolicyUC.RemoveRule")
+	defer span.Finish()
+
+	if err := validateList(list); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.RemoveRule(ctx, list, normalizeDomain(domain)); err != nil {
+		return err
+	}
+	u.invalidate()
+	return nil
+}
+
+func (u *emailPolicyUC) loadRules(ctx context.Context) (*domainRules, error) {
+	refresh := u.cfg.EmailPolicy.RefreshSeconds
+	if refresh <= 0 {
+		refresh = defaultRefreshSeconds
+	}
+
+	u.mu.RLock()
+	cached := u.rules
+	u.mu.RUnlock()
+	if cached != nil && u.clock.Since(cached.loadedAt) < time.Duration(refresh)*time.Second {
+		return cached, nil
+	}
+
+	allow, err := u.redisRepo.GetRules(ctx, emailpolicy.ListAllow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := u.redisRepo.GetRules(ctx, emailpolicy.ListDeny)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &domainRules{
+		allow:    normalizeDomains(append(append([]string{}, u.cfg.EmailPolicy.Allow...), allow...)),
+		deny:     normalizeDomains(append(append([]string{}, u.cfg.EmailPolicy.Deny...), deny...)),
+		loadedAt: u.clock.Now(),
+	}
+
+	u.mu.Lock()
+	u.rules = rules
+	u.mu.Unlock()
+	return rules, nil
+}
+
+func (u *emailPolicyUC) invalidate() {
+	u.mu.Lock()
+	u.rules = nil
+	u.mu.Unlock()
+}
+
+func (u *emailPolicyUC) matchDisposable(domain string) (string, bool) {
+	for candidate := domain; candidate != ""; candidate = parentDomain(candidate) {
+		if u.disposable[candidate] {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// Domain publishes a usable MX record, lookup failures other than a missing domain let the signup through
+func (u *emailPolicyUC) receivesMail(ctx context.Context, domain string) bool {
+	timeout := time.Duration(u.cfg.EmailPolicy.MXTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultMXTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	records, err := u.resolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false
+		}
+		u.logger.Warnf("emailPolicyUC.receivesMail.LookupMX domain: %s, error: %v", domain, err)
+		return true
+	}
+	// RFC 7505 null MX, the domain explicitly accepts no mail
+	if len(records) == 1 && records[0].Host == "." {
+		return false
+	}
+	return len(records) > 0
+}
+
+// Rule matching domain itself or one of its parents
+func matchDomain(domain string, rules []string) (string, bool) {
+	for _, rule := range rules {
+		if domain == rule || strings.HasSuffix(domain, "."+rule) {
+			return rule, true
+		}
+	}
+	return "", false
+}
+
+func parentDomain(domain string) string {
+	dot := strings.Index(domain, ".")
+	if dot < 0 {
+		return ""
+	}
+	return domain[dot+1:]
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+func validateList(list string) error {
+	if list != emailpolicy.ListAllow && list != emailpolicy.ListDeny {
+		return httpErrors.NewBadRequestError("list must be allow or deny")
+	}
+	return nil
+}
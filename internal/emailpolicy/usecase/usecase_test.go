@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+type memoryRepo map[string][]string
+
+func (m memoryRepo) GetRules(_ context.Context, list string) ([]string, error) {
+	return m[list], nil
+}
+
+func (m memoryRepo) AddRule(_ context.Context, list string, domain string) error {
+	m[list] = append(m[list], domain)
+	return nil
+}
+
+func (m memoryRepo) RemoveRule(_ context.Context, list string, domain string) error {
+	return nil
+}
+
+type fakeResolver map[string][]*net.MX
+
+func (f fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestEmailPolicyUC_Check(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{EmailPolicy: config.EmailPolicy{
+		Enabled:         true,
+		Deny:            []string{"spam.example"},
+		BlockDisposable: true,
+		CheckMX:         true,
+	}}
+	resolver := fakeResolver{
+		"example.com":  {{Host: "mx.example.com.", Pref: 10}},
+		"nullmx.com":   {{Host: ".", Pref: 0}},
+		"corp.example": {{Host: "mx.corp.example.", Pref: 10}},
+	}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	uc := NewEmailPolicyUseCase(cfg, memoryRepo{}, resolver, clock.NewFrozen(time.Now()), appLogger)
+	ctx := context.Background()
+
+	cases := []struct {
+		email  string
+		reason string
+	}{
+		{"user@example.com", ""},
+		{"User@Example.COM.", ""},
+		{"not-an-email", emailpolicy.ReasonInvalidEmail},
+		{"user@mail.spam.example", emailpolicy.ReasonDenied},
+		{"user@mailinator.com", emailpolicy.ReasonDisposable},
+		{"user@nullmx.com", emailpolicy.ReasonNoMX},
+		{"user@missing.example", emailpolicy.ReasonNoMX},
+	}
+	for _, tc := range cases {
+		decision, err := uc.Check(ctx, tc.email)
+		require.NoError(t, err)
+		require.Equal(t, tc.reason == "", decision.Allowed, tc.email)
+		require.Equal(t, tc.reason, decision.Reason, tc.email)
+	}
+
+	require.NoError(t, uc.AddRule(ctx, emailpolicy.ListAllow, "corp.example"))
+	decision, err := uc.Check(ctx, "user@corp.example")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.Equal(t, "corp.example", decision.Rule)
+
+	decision, err = uc.Check(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, emailpolicy.ReasonNotAllowed, decision.Reason)
+	require.Equal(t, emailpolicy.ReasonNotAllowed, emailpolicy.ErrorReason(emailpolicy.DecisionError(decision)))
+
+	require.Error(t, uc.AddRule(ctx, "maybe", "corp.example"))
+	require.Error(t, uc.AddRule(ctx, emailpolicy.ListDeny, "localhost"))
+}
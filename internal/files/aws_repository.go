@@ -0,0 +1,23 @@
+package files
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+// Files object storage repository interface
+type AWSRepository interface {
+	PutObject(ctx context.Context, input models.UploadInput, objectKey string) (*storage.ObjectInfo, error)
+	GetObject(ctx context.Context, bucket string, objectKey string) (io.ReadCloser, error)
+	MoveObject(ctx context.Context, srcBucket string, dstBucket string, objectKey string) error
+	RemoveObject(ctx context.Context, bucket string, objectKey string) error
+	NewMultipartUpload(ctx context.Context, bucket string, objectKey string, contentType string) (string, error)
+	PresignUploadPart(ctx context.Context, bucket string, objectKey string, uploadID string, partNumber int, expires time.Duration) (string, error)
+	ListParts(ctx context.Context, bucket string, objectKey string, uploadID string) ([]storage.Part, error)
+	CompleteMultipartUpload(ctx context.Context, bucket string, objectKey string, uploadID string, parts []storage.Part) error
+	AbortMultipartUpload(ctx context.Context, bucket string, objectKey string, uploadID string) error
+}
@@ -0,0 +1,17 @@
+package files
+
+import "github.com/labstack/echo/v4"
+
+// Files HTTP Handlers interface
+type Handlers interface {
+	Upload() echo.HandlerFunc
+	UploadStream() echo.HandlerFunc
+	GetUploadProgress() echo.HandlerFunc
+	GetByID() echo.HandlerFunc
+	Download() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	InitiateMultipart() echo.HandlerFunc
+	PresignPart() echo.HandlerFunc
+	CompleteMultipart() echo.HandlerFunc
+	AbortMultipart() echo.HandlerFunc
+}
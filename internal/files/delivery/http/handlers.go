@@ -0,0 +1,324 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	fileFormField  = "file"
+	uploadIDHeader = "X-Upload-ID"
+)
+
+// Files handlers
+type filesHandlers struct {
+	cfg     *config.Config
+	filesUC files.UseCase
+	logger  logger.Logger
+}
+
+// NewFilesHandlers Files handlers constructor
+func NewFilesHandlers(cfg *config.Config, filesUC files.UseCase, log logger.Logger) files.Handlers {
+	return &filesHandlers{cfg: cfg, filesUC: filesUC, logger: log}
+}
+
+// Upload godoc
+// @Summary Upload file
+// @Description Upload file as multipart form field "file", it stays quarantined with status pending until scanned, guests may upload
+// @Tags Files
+// @Accept mpfd
+// @Produce json
+// @Success 202 {object} models.File
+// @Failure 400 {object} httpErrors.RestError
+// @Router /files [post]
+func (h *filesHandlers) Upload() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.Upload")
+		defer span.Finish()
+
+		fileHeader, err := c.FormFile(fileFormField)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.WithMessage(err, "c.FormFile"))
+		}
+
+		content, err := fileHeader.Open()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		file, err := h.filesUC.Upload(ctx, models.UploadInput{
+			File:        content,
+			Name:        fileHeader.Filename,
+			Size:        fileHeader.Size,
+			ContentType: fileHeader.Header.Get(echo.HeaderContentType),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, file)
+	}
+}
+
+// UploadStream godoc
+// @Summary Stream file upload
+// @Description Upload file as the raw request body, streamed into quarantine without buffering. Progress is readable under the X-Upload-ID header value while uploading, guests may upload
+// @Tags Files
+// @Accept octet-stream
+// @Produce json
+// @Param name query string true "file name"
+// @Param X-Upload-ID header string false "upload id to poll progress with, uuid"
+// @Success 202 {object} models.File
+// @Failure 413 {object} httpErrors.RestError
+// @Failure 415 {object} httpErrors.RestError
+// @Router /files/stream [post]
+func (h *filesHandlers) UploadStream() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.UploadStream")
+		defer span.Finish()
+
+		uploadID := c.Request().Header.Get(uploadIDHeader)
+		if uploadID != "" {
+			if _, err := uuid.Parse(uploadID); err != nil {
+				return httpErrors.NewBadRequestError(errors.WithMessage(err, uploadIDHeader))
+			}
+		}
+
+		file, err := h.filesUC.UploadStream(ctx, models.UploadInput{
+			File:        c.Request().Body,
+			Name:        c.QueryParam("name"),
+			Size:        c.Request().ContentLength,
+			ContentType: c.Request().Header.Get(echo.HeaderContentType),
+		}, uploadID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, file)
+	}
+}
+
+// GetUploadProgress godoc
+// @Summary Get streamed upload progress
+// @Description Bytes received so far by a streamed upload and its outcome, owner only
+// @Tags Files
+// @Produce json
+// @Param upload_id path string true "upload_id"
+// @Success 200 {object} models.UploadProgress
+// @Failure 404 {object} httpErrors.RestError
+// @Router /files/stream/{upload_id}/progress [get]
+func (h *filesHandlers) GetUploadProgress() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.GetUploadProgress")
+		defer span.Finish()
+
+		progress, err := h.filesUC.GetUploadProgress(ctx, c.Param("upload_id"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, progress)
+	}
+}
+
+// GetByID godoc
+// @Summary Get file
+// @Description Get file record with scan status, owner only
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param file_id path int true "file_id"
+// @Success 200 {object} models.File
+// @Failure 404 {object} httpErrors.RestError
+// @Router /files/{file_id} [get]
+func (h *filesHandlers) GetByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.GetByID")
+		defer span.Finish()
+
+		fileID, err := strconv.ParseInt(c.Param("file_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		file, err := h.filesUC.GetByID(ctx, fileID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, file)
+	}
+}
+
+// Download godoc
+// @Summary Download file
+// @Description Stream file content, only files which passed scanning, owner only
+// @Tags Files
+// @Produce octet-stream
+// @Param file_id path int true "file_id"
+// @Success 200 {file} file
+// @Failure 409 {object} httpErrors.RestError
+// @Router /files/{file_id}/download [get]
+func (h *filesHandlers) Download() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.Download")
+		defer span.Finish()
+
+		fileID, err := strconv.ParseInt(c.Param("file_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		file, object, err := h.filesUC.Download(ctx, fileID)
+		if err != nil {
+			return err
+		}
+		defer object.Close()
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+strconv.Quote(file.Name))
+		return c.Stream(http.StatusOK, file.ContentType, object)
+	}
+}
+
+// Delete godoc
+// @Summary Delete file
+// @Description Delete file record, its stored content is removed once no other file shares it, owner only
+// @Tags Files
+// @Produce json
+// @Param file_id path int true "file_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /files/{file_id} [delete]
+func (h *filesHandlers) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.Delete")
+		defer span.Finish()
+
+		fileID, err := strconv.ParseInt(c.Param("file_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.filesUC.Delete(ctx, fileID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// InitiateMultipart godoc
+// @Summary Initiate multipart upload
+// @Description Start uploading a large file in parts, declare its size and hex SHA-256, parts are then PUT to presigned URLs, guests may upload
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param body body models.MultipartUploadRequest true "upload"
+// @Success 201 {object} models.FileUpload
+// @Failure 400 {object} httpErrors.RestError
+// @Router /files/multipart [post]
+func (h *filesHandlers) InitiateMultipart() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.InitiateMultipart")
+		defer span.Finish()
+
+		req := &models.MultipartUploadRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		upload, err := h.filesUC.InitiateMultipart(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, upload)
+	}
+}
+
+// PresignPart godoc
+// @Summary Presign part upload
+// @Description Get a presigned URL to PUT one part of an open multipart upload, keep the returned ETag header, owner only
+// @Tags Files
+// @Produce json
+// @Param upload_id path string true "upload_id"
+// @Param part_number path int true "part_number"
+// @Success 200 {object} models.PresignedPart
+// @Failure 409 {object} httpErrors.RestError
+// @Router /files/multipart/{upload_id}/parts/{part_number} [get]
+func (h *filesHandlers) PresignPart() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.PresignPart")
+		defer span.Finish()
+
+		partNumber, err := strconv.Atoi(c.Param("part_number"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		part, err := h.filesUC.PresignPart(ctx, c.Param("upload_id"), partNumber)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, part)
+	}
+}
+
+// CompleteMultipart godoc
+// @Summary Complete multipart upload
+// @Description Assemble uploaded parts and verify the declared checksum, the file then waits for scanning like a regular upload, owner only
+// @Tags Files
+// @Produce json
+// @Param upload_id path string true "upload_id"
+// @Success 202 {object} models.File
+// @Failure 409 {object} httpErrors.RestError
+// @Failure 422 {object} httpErrors.RestError
+// @Router /files/multipart/{upload_id}/complete [post]
+func (h *filesHandlers) CompleteMultipart() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.CompleteMultipart")
+		defer span.Finish()
+
+		file, err := h.filesUC.CompleteMultipart(ctx, c.Param("upload_id"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, file)
+	}
+}
+
+// AbortMultipart godoc
+// @Summary Abort multipart upload
+// @Description Abort an open multipart upload and drop its parts, owner only
+// @Tags Files
+// @Param upload_id path string true "upload_id"
+// @Success 204
+// @Failure 409 {object} httpErrors.RestError
+// @Router /files/multipart/{upload_id} [delete]
+func (h *filesHandlers) AbortMultipart() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "filesHandlers.AbortMultipart")
+		defer span.Finish()
+
+		if err := h.filesUC.AbortMultipart(ctx, c.Param("upload_id")); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
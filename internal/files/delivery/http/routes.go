@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map files routes
+func MapFilesRoutes(filesGroup *echo.Group, h files.Handlers, mw *middleware.MiddlewareManager) {
+	filesGroup.Use(mw.SessionOrGuestMiddleware)
+
+	filesGroup.POST("", h.Upload(), mw.CSRF)
+	// Body is read by the handler only, no body buffering middleware on this route
+	filesGroup.POST("/stream", h.UploadStream(), mw.CSRF)
+	filesGroup.GET("/stream/:upload_id/progress", h.GetUploadProgress())
+	filesGroup.POST("/multipart", h.InitiateMultipart(), mw.CSRF)
+	filesGroup.GET("/multipart/:upload_id/parts/:part_number", h.PresignPart())
+	filesGroup.POST("/multipart/:upload_id/complete", h.CompleteMultipart(), mw.CSRF)
+	filesGroup.DELETE("/multipart/:upload_id", h.AbortMultipart(), mw.CSRF)
+	filesGroup.GET("/:file_id", h.GetByID())
+	filesGroup.GET("/:file_id/download", h.Download())
+	filesGroup.DELETE("/:file_id", h.Delete(), mw.CSRF)
+}
@@ -0,0 +1,25 @@
+package files
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Files repository interface
+type Repository interface {
+	Create(ctx context.Context, file *models.File) (*models.File, error)
+	GetByID(ctx context.Context, fileID int64) (*models.File, error)
+	UpdateStatus(ctx context.Context, file *models.File) error
+	CreateFromBlob(ctx context.Context, file *models.File) (*models.File, error)
+	PromoteToBlob(ctx context.Context, file *models.File, blob *models.FileBlob) (*models.FileBlob, error)
+	Delete(ctx context.Context, fileID int64) (*models.File, *models.FileBlob, error)
+	DeleteUnreferencedBlobs(ctx context.Context, limit int) ([]*models.FileBlob, error)
+	CreateUpload(ctx context.Context, upload *models.FileUpload) (*models.FileUpload, error)
+	GetUpload(ctx context.Context, id string) (*models.FileUpload, error)
+	UpdateUploadStatus(ctx context.Context, upload *models.FileUpload, from string) error
+	ListStaleUploads(ctx context.Context, limit int) ([]*models.FileUpload, error)
+	MergeGuestData(ctx context.Context, tx *sqlx.Tx, guestID string, userID int) (int64, error)
+}
@@ -0,0 +1,14 @@
+package files
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Files redis repository, progress of streamed uploads is shared by every instance
+type RedisRepository interface {
+	SetProgress(ctx context.Context, progress *models.UploadProgress, seconds int) error
+	// Nil without error when there is no progress for the upload
+	GetProgress(ctx context.Context, uploadID string) (*models.UploadProgress, error)
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+// Files object storage on the configured blob store
+type filesBlobRepository struct {
+	blobs storage.BlobStore
+}
+
+// Files blob storage repository constructor
+func NewFilesBlobRepository(blobs storage.BlobStore) files.AWSRepository {
+	return &filesBlobRepository{blobs: blobs}
+}
+
+// Upload object
+func (r *filesBlobRepository) PutObject(ctx context.Context, input models.UploadInput, objectKey string) (*storage.ObjectInfo, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.PutObject")
+	defer span.Finish()
+
+	info, err := r.blobs.Put(ctx, input.BucketName, objectKey, input.File, input.Size, input.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesBlobRepository.PutObject")
+	}
+	return info, nil
+}
+
+// Get object
+func (r *filesBlobRepository) GetObject(ctx context.Context, bucket string, objectKey string) (io.ReadCloser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.GetObject")
+	defer span.Finish()
+
+	object, err := r.blobs.Get(ctx, bucket, objectKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesBlobRepository.GetObject")
+	}
+	return object, nil
+}
+
+// Move object to another bucket
+func (r *filesBlobRepository) MoveObject(ctx context.Context, srcBucket string, dstBucket string, objectKey string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.MoveObject")
+	defer span.Finish()
+
+	if err := r.blobs.Move(ctx, srcBucket, dstBucket, objectKey); err != nil {
+		return errors.Wrap(err, "filesBlobRepository.MoveObject")
+	}
+	return nil
+}
+
+// Remove object
+func (r *filesBlobRepository) RemoveObject(ctx context.Context, bucket string, objectKey string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.RemoveObject")
+	defer span.Finish()
+
+	if err := r.blobs.Remove(ctx, bucket, objectKey); err != nil {
+		return errors.Wrap(err, "filesBlobRepository.RemoveObject")
+	}
+	return nil
+}
+
+// Start multipart upload, returns the storage upload id
+func (r *filesBlobRepository) NewMultipartUpload(ctx context.Context, bucket string, objectKey string, contentType string) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.NewMultipartUpload")
+	defer span.Finish()
+
+	uploadID, err := r.blobs.NewMultipartUpload(ctx, bucket, objectKey, contentType)
+	if err != nil {
+		return "", errors.Wrap(err, "filesBlobRepository.NewMultipartUpload")
+	}
+	return uploadID, nil
+}
+
+// Presign PUT of a single part
+func (r *filesBlobRepository) PresignUploadPart(
+	ctx context.Context,
+	bucket string,
+	objectKey string,
+	uploadID string,
+	partNumber int,
+	expires time.Duration,
+) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.PresignUploadPart")
+	defer span.Finish()
+
+	presigned, err := r.blobs.PresignPart(ctx, bucket, objectKey, uploadID, partNumber, expires)
+	if err != nil {
+		return "", errors.Wrap(err, "filesBlobRepository.PresignUploadPart")
+	}
+	return presigned, nil
+}
+
+// List all uploaded parts ordered by part number
+func (r *filesBlobRepository) ListParts(ctx context.Context, bucket string, objectKey string, uploadID string) ([]storage.Part, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.ListParts")
+	defer span.Finish()
+
+	parts, err := r.blobs.ListParts(ctx, bucket, objectKey, uploadID)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesBlobRepository.ListParts")
+	}
+	return parts, nil
+}
+
+// Assemble uploaded parts into the object
+func (r *filesBlobRepository) CompleteMultipartUpload(
+	ctx context.Context,
+	bucket string,
+	objectKey string,
+	uploadID string,
+	parts []storage.Part,
+) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.CompleteMultipartUpload")
+	defer span.Finish()
+
+	if err := r.blobs.CompleteMultipartUpload(ctx, bucket, objectKey, uploadID, parts); err != nil {
+		return errors.Wrap(err, "filesBlobRepository.CompleteMultipartUpload")
+	}
+	return nil
+}
+
+// Abort multipart upload and drop its parts, an already gone upload is not an error
+func (r *filesBlobRepository) AbortMultipartUpload(ctx context.Context, bucket string, objectKey string, uploadID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesBlobRepository.AbortMultipartUpload")
+	defer span.Finish()
+
+	if err := r.blobs.AbortMultipartUpload(ctx, bucket, objectKey, uploadID); err != nil {
+		return errors.Wrap(err, "filesBlobRepository.AbortMultipartUpload")
+	}
+	return nil
+}
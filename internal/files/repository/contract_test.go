@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/stdlib" // pgx driver
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Contract tests run against a migrated database, e.g.
+// POSTGRES_TEST_DSN="host=localhost port=5432 user=postgres password=postgres dbname=user_service_db sslmode=disable"
+const testDSNEnv = "POSTGRES_TEST_DSN"
+
+func TestFilesRepository_SqlxContract(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sqlx.Connect("pgx", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	runRepositoryContract(t, NewFilesRepository(db))
+}
+
+func TestFilesRepository_MemoryContract(t *testing.T) {
+	runRepositoryContract(t, NewFilesMemoryRepository())
+}
+
+func runRepositoryContract(t *testing.T, repo files.Repository) {
+	ctx := context.Background()
+	guestID := uuid.New().String()
+	sum := sha256.Sum256([]byte(guestID))
+	checksum := hex.EncodeToString(sum[:])
+
+	pending := &models.File{
+		GuestID:        &guestID,
+		Name:           "report.pdf",
+		ContentType:    "application/pdf",
+		Size:           1024,
+		Bucket:         "quarantine",
+		ObjectKey:      "contract/" + guestID,
+		Status:         models.FileStatusPending,
+		ChecksumSHA256: &checksum,
+	}
+	created, err := repo.Create(ctx, pending)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	// Records handed out do not alias the stored ones
+	*created.GuestID = "changed"
+	stored, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, guestID, *stored.GuestID)
+
+	_, err = repo.CreateFromBlob(ctx, pending)
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+
+	blob, err := repo.PromoteToBlob(ctx, stored, &models.FileBlob{ChecksumSHA256: checksum, Bucket: "files", ObjectKey: stored.ObjectKey, Size: stored.Size})
+	require.NoError(t, err)
+	require.Equal(t, 1, blob.RefCount)
+	_, err = repo.PromoteToBlob(ctx, stored, &models.FileBlob{ChecksumSHA256: checksum, Bucket: "files", ObjectKey: stored.ObjectKey, Size: stored.Size})
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+
+	shared, err := repo.CreateFromBlob(ctx, pending)
+	require.NoError(t, err)
+	require.Equal(t, models.FileStatusClean, shared.Status)
+	require.Equal(t, blob.ID, *shared.BlobID)
+
+	scanResult := "clean"
+	require.NoError(t, repo.UpdateStatus(ctx, &models.File{ID: shared.ID, Bucket: "files", Status: models.FileStatusClean, ScanResult: &scanResult}))
+	scanResult = "changed"
+	stored, err = repo.GetByID(ctx, shared.ID)
+	require.NoError(t, err)
+	require.Equal(t, "clean", *stored.ScanResult)
+
+	// The blob goes with its last reference
+	_, deletedBlob, err := repo.Delete(ctx, created.ID)
+	require.NoError(t, err)
+	require.Nil(t, deletedBlob)
+	_, deletedBlob, err = repo.Delete(ctx, shared.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deletedBlob)
+	require.Equal(t, blob.ID, deletedBlob.ID)
+	_, _, err = repo.Delete(ctx, shared.ID)
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+	_, err = repo.GetByID(ctx, shared.ID)
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+
+	upload, err := repo.CreateUpload(ctx, &models.FileUpload{
+		ID:             uuid.New().String(),
+		UploadID:       "multipart",
+		GuestID:        &guestID,
+		Name:           "video.mp4",
+		ContentType:    "video/mp4",
+		Size:           1 << 20,
+		PartSize:       1 << 19,
+		PartCount:      2,
+		Bucket:         "quarantine",
+		ObjectKey:      "contract/upload/" + guestID,
+		ChecksumSHA256: checksum,
+		Status:         models.FileUploadStatusUploading,
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	stale, err := repo.ListStaleUploads(ctx, 1000)
+	require.NoError(t, err)
+	require.Contains(t, uploadIDs(stale), upload.ID)
+
+	upload.Status = models.FileUploadStatusAborted
+	require.NoError(t, repo.UpdateUploadStatus(ctx, upload, models.FileUploadStatusUploading))
+	require.True(t, errors.Is(repo.UpdateUploadStatus(ctx, upload, models.FileUploadStatusUploading), sql.ErrNoRows))
+	aborted, err := repo.GetUpload(ctx, upload.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.FileUploadStatusAborted, aborted.Status)
+	stale, err = repo.ListStaleUploads(ctx, 1000)
+	require.NoError(t, err)
+	require.NotContains(t, uploadIDs(stale), upload.ID)
+
+	_, err = repo.GetUpload(ctx, uuid.New().String())
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func uploadIDs(uploads []*models.FileUpload) []string {
+	ids := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		ids = append(ids, upload.ID)
+	}
+	return ids
+}
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Files Repository kept in process memory, dev mode stand-in for Postgres. Records are copied in and out,
+// callers never share pointer fields with the stored ones.
+type filesMemoryRepo struct {
+	mu         sync.RWMutex
+	lastID     int64
+	lastBlobID int64
+	files      map[int64]models.File
+	uploads    map[string]models.FileUpload
+	blobs      map[int64]models.FileBlob
+}
+
+// Files in-memory Repository constructor
+func NewFilesMemoryRepository() files.Repository {
+	return &filesMemoryRepo{
+		files:   make(map[int64]models.File),
+		uploads: make(map[string]models.FileUpload),
+		blobs:   make(map[int64]models.FileBlob),
+	}
+}
+
+// Create file record
+func (r *filesMemoryRepo) Create(ctx context.Context, file *models.File) (*models.File, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.Create")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(file), nil
+}
+
+func (r *filesMemoryRepo) create(file *models.File) *models.File {
+	r.lastID++
+	created := cloneFile(file)
+	created.ID = r.lastID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.files[created.ID] = *created
+	if created.BlobID != nil {
+		r.addBlobRef(*created.BlobID, 1)
+	}
+	return cloneFile(created)
+}
+
+// Create clean file record sharing the stored blob with the same checksum, sql.ErrNoRows when there is none
+func (r *filesMemoryRepo) CreateFromBlob(ctx context.Context, file *models.File) (*models.File, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.CreateFromBlob")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	blob, ok := r.blobByChecksum(file.ChecksumSHA256)
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.CreateFromBlob")
+	}
+	shared := *file
+	shared.Bucket, shared.ObjectKey = blob.Bucket, blob.ObjectKey
+	shared.Status = models.FileStatusClean
+	shared.BlobID = &blob.ID
+	return r.create(&shared), nil
+}
+
+// Mark pending file clean and point it at the blob with its checksum, the given blob is registered unless
+// the same content is already stored. Returns the blob the file now shares, sql.ErrNoRows when the file
+// is no longer pending
+func (r *filesMemoryRepo) PromoteToBlob(ctx context.Context, file *models.File, blob *models.FileBlob) (*models.FileBlob, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.PromoteToBlob")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.files[file.ID]
+	if !ok || stored.Status != models.FileStatusPending {
+		return nil, errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.PromoteToBlob")
+	}
+
+	shared, ok := r.blobByChecksum(&blob.ChecksumSHA256)
+	if !ok {
+		r.lastBlobID++
+		shared = *blob
+		shared.ID = r.lastBlobID
+		shared.RefCount = 0
+		shared.CreatedAt = time.Now()
+		shared.UpdatedAt = shared.CreatedAt
+		r.blobs[shared.ID] = shared
+	}
+
+	blobID := shared.ID
+	stored.Bucket, stored.ObjectKey = shared.Bucket, shared.ObjectKey
+	stored.BlobID = &blobID
+	stored.Status = models.FileStatusClean
+	stored.ScanResult = nil
+	stored.UpdatedAt = time.Now()
+	r.files[file.ID] = stored
+	r.addBlobRef(shared.ID, 1)
+
+	shared = r.blobs[shared.ID]
+	return &shared, nil
+}
+
+// Delete file record, returns the deleted record and its blob when this was the last reference to it
+func (r *filesMemoryRepo) Delete(ctx context.Context, fileID int64) (*models.File, *models.FileBlob, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.Delete")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[fileID]
+	if !ok {
+		return nil, nil, errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.Delete")
+	}
+	delete(r.files, fileID)
+
+	if file.BlobID == nil {
+		return &file, nil, nil
+	}
+	r.addBlobRef(*file.BlobID, -1)
+	blob := r.blobs[*file.BlobID]
+	if blob.RefCount > 0 {
+		return &file, nil, nil
+	}
+	delete(r.blobs, blob.ID)
+	return &file, &blob, nil
+}
+
+// Delete blobs no file references anymore
+func (r *filesMemoryRepo) DeleteUnreferencedBlobs(ctx context.Context, limit int) ([]*models.FileBlob, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.DeleteUnreferencedBlobs")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	blobs := make([]*models.FileBlob, 0)
+	for id, blob := range r.blobs {
+		if len(blobs) == limit {
+			break
+		}
+		if blob.RefCount == 0 {
+			blob := blob
+			blobs = append(blobs, &blob)
+			delete(r.blobs, id)
+		}
+	}
+	return blobs, nil
+}
+
+func (r *filesMemoryRepo) blobByChecksum(checksum *string) (models.FileBlob, bool) {
+	if checksum == nil {
+		return models.FileBlob{}, false
+	}
+	for _, blob := range r.blobs {
+		if blob.ChecksumSHA256 == *checksum {
+			return blob, true
+		}
+	}
+	return models.FileBlob{}, false
+}
+
+func (r *filesMemoryRepo) addBlobRef(blobID int64, delta int) {
+	blob := r.blobs[blobID]
+	blob.RefCount += delta
+	blob.UpdatedAt = time.Now()
+	r.blobs[blobID] = blob
+}
+
+// Get file record by id
+func (r *filesMemoryRepo) GetByID(ctx context.Context, fileID int64) (*models.File, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.GetByID")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	file, ok := r.files[fileID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.GetByID")
+	}
+	return cloneFile(&file), nil
+}
+
+// Update file bucket, status and scan result
+func (r *filesMemoryRepo) UpdateStatus(ctx context.Context, file *models.File) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.UpdateStatus")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.files[file.ID]
+	if !ok {
+		return errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.UpdateStatus")
+	}
+	stored.Bucket = file.Bucket
+	stored.Status = file.Status
+	stored.ScanResult = utils.ClonePtr(file.ScanResult)
+	stored.UpdatedAt = time.Now()
+	r.files[file.ID] = stored
+	return nil
+}
+
+// Create multipart upload record
+func (r *filesMemoryRepo) CreateUpload(ctx context.Context, upload *models.FileUpload) (*models.FileUpload, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.CreateUpload")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := cloneUpload(upload)
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.uploads[created.ID] = *created
+	return cloneUpload(created), nil
+}
+
+// Get multipart upload record by id
+func (r *filesMemoryRepo) GetUpload(ctx context.Context, id string) (*models.FileUpload, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.GetUpload")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.GetUpload")
+	}
+	return cloneUpload(&upload), nil
+}
+
+// Move upload from one status to another, sql.ErrNoRows when it already left the from status
+func (r *filesMemoryRepo) UpdateUploadStatus(ctx context.Context, upload *models.FileUpload, from string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.UpdateUploadStatus")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.uploads[upload.ID]
+	if !ok || stored.Status != from {
+		return errors.Wrap(sql.ErrNoRows, "filesMemoryRepo.UpdateUploadStatus")
+	}
+	stored.Status = upload.Status
+	stored.FileID = utils.ClonePtr(upload.FileID)
+	stored.UpdatedAt = time.Now()
+	r.uploads[upload.ID] = stored
+	return nil
+}
+
+// List uploads still in progress past their expiry, oldest first
+func (r *filesMemoryRepo) ListStaleUploads(ctx context.Context, limit int) ([]*models.FileUpload, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.ListStaleUploads")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	uploads := make([]*models.FileUpload, 0)
+	for _, upload := range r.uploads {
+		if upload.Status == models.FileUploadStatusUploading && upload.ExpiresAt.Before(now) {
+			uploads = append(uploads, cloneUpload(&upload))
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ExpiresAt.Before(uploads[j].ExpiresAt) })
+	if len(uploads) > limit {
+		uploads = uploads[:limit]
+	}
+	return uploads, nil
+}
+
+// Reassign files and uploads of a guest to the account, the transaction is ignored
+func (r *filesMemoryRepo) MergeGuestData(ctx context.Context, _ *sqlx.Tx, guestID string, userID int) (int64, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "filesMemoryRepo.MergeGuestData")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var merged int64
+	for id, file := range r.files {
+		if file.GuestID != nil && *file.GuestID == guestID {
+			file.OwnerID, file.GuestID = utils.ClonePtr(&userID), nil
+			file.UpdatedAt = time.Now()
+			r.files[id] = file
+			merged++
+		}
+	}
+	for id, upload := range r.uploads {
+		if upload.GuestID != nil && *upload.GuestID == guestID {
+			upload.OwnerID, upload.GuestID = utils.ClonePtr(&userID), nil
+			upload.UpdatedAt = time.Now()
+			r.uploads[id] = upload
+		}
+	}
+	return merged, nil
+}
+
+func cloneFile(file *models.File) *models.File {
+	cloned := *file
+	cloned.OwnerID = utils.ClonePtr(file.OwnerID)
+	cloned.GuestID = utils.ClonePtr(file.GuestID)
+	cloned.ScanResult = utils.ClonePtr(file.ScanResult)
+	cloned.ChecksumSHA256 = utils.ClonePtr(file.ChecksumSHA256)
+	cloned.BlobID = utils.ClonePtr(file.BlobID)
+	return &cloned
+}
+
+func cloneUpload(upload *models.FileUpload) *models.FileUpload {
+	cloned := *upload
+	cloned.OwnerID = utils.ClonePtr(upload.OwnerID)
+	cloned.GuestID = utils.ClonePtr(upload.GuestID)
+	cloned.FileID = utils.ClonePtr(upload.FileID)
+	return &cloned
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Files Repository
+type filesRepo struct {
+	db *sqlx.DB
+}
+
+// Files Repository constructor
+func NewFilesRepository(db *sqlx.DB) files.Repository {
+	return &filesRepo{db: db}
+}
+
+// Create file record
+func (r *filesRepo) Create(ctx context.Context, file *models.File) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.Create")
+	defer span.Finish()
+
+	created := &models.File{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createFileQuery,
+		file.OwnerID,
+		file.GuestID,
+		file.Name,
+		file.ContentType,
+		file.Size,
+		file.Bucket,
+		file.ObjectKey,
+		file.Status,
+		file.ChecksumSHA256,
+		file.BlobID,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.Create.StructScan")
+	}
+	return created, nil
+}
+
+// Get file record by id
+func (r *filesRepo) GetByID(ctx context.Context, fileID int64) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.GetByID")
+	defer span.Finish()
+
+	file := &models.File{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, file, getFileByIDQuery, fileID); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.GetByID.GetContext")
+	}
+	return file, nil
+}
+
+// Create clean file record sharing the stored blob with the same checksum, sql.ErrNoRows when there is none
+func (r *filesRepo) CreateFromBlob(ctx context.Context, file *models.File) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.CreateFromBlob")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesRepo.CreateFromBlob.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	blob := &models.FileBlob{}
+	if err := tx.GetContext(ctx, blob, lockBlobByChecksumQuery, file.ChecksumSHA256); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.CreateFromBlob.GetContext")
+	}
+
+	created := &models.File{}
+	if err := tx.QueryRowxContext(
+		ctx,
+		createFileQuery,
+		file.OwnerID,
+		file.GuestID,
+		file.Name,
+		file.ContentType,
+		file.Size,
+		blob.Bucket,
+		blob.ObjectKey,
+		models.FileStatusClean,
+		blob.ChecksumSHA256,
+		blob.ID,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.CreateFromBlob.StructScan")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.CreateFromBlob.Commit")
+	}
+	return created, nil
+}
+
+// Mark pending file clean and point it at the blob with its checksum, the given blob is registered unless
+// the same content is already stored. Returns the blob the file now shares, sql.ErrNoRows when the file
+// is no longer pending
+func (r *filesRepo) PromoteToBlob(ctx context.Context, file *models.File, blob *models.FileBlob) (*models.FileBlob, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.PromoteToBlob")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if _, err := tx.ExecContext(ctx, createBlobQuery, blob.ChecksumSHA256, blob.Bucket, blob.ObjectKey, blob.Size); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.ExecContext.blob")
+	}
+	shared := &models.FileBlob{}
+	if err := tx.GetContext(ctx, shared, lockBlobByChecksumQuery, blob.ChecksumSHA256); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.GetContext")
+	}
+
+	result, err := tx.ExecContext(ctx, promoteFileQuery, shared.Bucket, shared.ObjectKey, shared.ID, models.FileStatusClean, file.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return nil, errors.Wrap(sql.ErrNoRows, "filesRepo.PromoteToBlob.rowsAffected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.PromoteToBlob.Commit")
+	}
+	return shared, nil
+}
+
+// Delete file record, returns the deleted record and its blob when this was the last reference to it
+func (r *filesRepo) Delete(ctx context.Context, fileID int64) (*models.File, *models.FileBlob, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.Delete")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "filesRepo.Delete.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	file := &models.File{}
+	if err := tx.QueryRowxContext(ctx, deleteFileQuery, fileID).StructScan(file); err != nil {
+		return nil, nil, errors.Wrap(err, "filesRepo.Delete.StructScan")
+	}
+
+	var blob *models.FileBlob
+	if file.BlobID != nil {
+		blob = &models.FileBlob{}
+		if err := tx.GetContext(ctx, blob, deleteUnreferencedBlobQuery, *file.BlobID); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, errors.Wrap(err, "filesRepo.Delete.GetContext")
+			}
+			blob = nil
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, errors.Wrap(err, "filesRepo.Delete.Commit")
+	}
+	return file, blob, nil
+}
+
+// Delete blobs no file references anymore, left behind when files go with their owner account
+func (r *filesRepo) DeleteUnreferencedBlobs(ctx context.Context, limit int) ([]*models.FileBlob, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.DeleteUnreferencedBlobs")
+	defer span.Finish()
+
+	blobs := make([]*models.FileBlob, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &blobs, deleteUnreferencedBlobsQuery, limit); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.DeleteUnreferencedBlobs.SelectContext")
+	}
+	return blobs, nil
+}
+
+// Reassign files of a guest to the account, runs inside the caller transaction
+func (r *filesRepo) MergeGuestData(ctx context.Context, tx *sqlx.Tx, guestID string, userID int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.MergeGuestData")
+	defer span.Finish()
+
+	result, err := tx.ExecContext(ctx, mergeGuestFilesQuery, userID, guestID)
+	if err != nil {
+		return 0, errors.Wrap(err, "filesRepo.MergeGuestData.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "filesRepo.MergeGuestData.RowsAffected")
+	}
+
+	// Uploads still in progress follow their guest so they can be completed after login
+	if _, err := tx.ExecContext(ctx, mergeGuestUploadsQuery, userID, guestID); err != nil {
+		return 0, errors.Wrap(err, "filesRepo.MergeGuestData.ExecContext.uploads")
+	}
+	return rowsAffected, nil
+}
+
+// Update file bucket, status and scan result
+func (r *filesRepo) UpdateStatus(ctx context.Context, file *models.File) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.UpdateStatus")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, updateFileStatusQuery, file.Bucket, file.Status, file.ScanResult, file.ID)
+	if err != nil {
+		return errors.Wrap(err, "filesRepo.UpdateStatus.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "filesRepo.UpdateStatus.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "filesRepo.UpdateStatus.rowsAffected")
+	}
+	return nil
+}
+
+// Create multipart upload record
+func (r *filesRepo) CreateUpload(ctx context.Context, upload *models.FileUpload) (*models.FileUpload, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.CreateUpload")
+	defer span.Finish()
+
+	created := &models.FileUpload{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createUploadQuery,
+		upload.ID,
+		upload.UploadID,
+		upload.OwnerID,
+		upload.GuestID,
+		upload.Name,
+		upload.ContentType,
+		upload.Size,
+		upload.PartSize,
+		upload.PartCount,
+		upload.Bucket,
+		upload.ObjectKey,
+		upload.ChecksumSHA256,
+		upload.Status,
+		upload.ExpiresAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.CreateUpload.StructScan")
+	}
+	return created, nil
+}
+
+// Get multipart upload record by id
+func (r *filesRepo) GetUpload(ctx context.Context, id string) (*models.FileUpload, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.GetUpload")
+	defer span.Finish()
+
+	upload := &models.FileUpload{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, upload, getUploadQuery, id); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.GetUpload.GetContext")
+	}
+	return upload, nil
+}
+
+// Move upload from one status to another, sql.ErrNoRows when it already left the from status
+func (r *filesRepo) UpdateUploadStatus(ctx context.Context, upload *models.FileUpload, from string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.UpdateUploadStatus")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, updateUploadStatusQuery, upload.Status, upload.FileID, upload.ID, from)
+	if err != nil {
+		return errors.Wrap(err, "filesRepo.UpdateUploadStatus.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "filesRepo.UpdateUploadStatus.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "filesRepo.UpdateUploadStatus.rowsAffected")
+	}
+	return nil
+}
+
+// List uploads still in progress past their expiry, oldest first
+func (r *filesRepo) ListStaleUploads(ctx context.Context, limit int) ([]*models.FileUpload, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRepo.ListStaleUploads")
+	defer span.Finish()
+
+	uploads := make([]*models.FileUpload, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &uploads, listStaleUploadsQuery, limit); err != nil {
+		return nil, errors.Wrap(err, "filesRepo.ListStaleUploads.SelectContext")
+	}
+	return uploads, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Files redis repository
+type filesRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Files redis repository constructor
+func NewFilesRedisRepo(redisClient *redis.Client, prefix string) files.RedisRepository {
+	return &filesRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Store upload progress for seconds
+func (r *filesRedisRepo) SetProgress(ctx context.Context, progress *models.UploadProgress, seconds int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRedisRepo.SetProgress")
+	defer span.Finish()
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return errors.Wrap(err, "filesRedisRepo.SetProgress.json.Marshal")
+	}
+	if err := r.redisClient.Set(ctx, r.key(progress.ID), progressBytes, time.Duration(seconds)*time.Second).Err(); err != nil {
+		return errors.Wrap(err, "filesRedisRepo.SetProgress.redisClient.Set")
+	}
+	return nil
+}
+
+// Get upload progress, nil without error when there is none
+func (r *filesRedisRepo) GetProgress(ctx context.Context, uploadID string) (*models.UploadProgress, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesRedisRepo.GetProgress")
+	defer span.Finish()
+
+	progressBytes, err := r.redisClient.Get(ctx, r.key(uploadID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "filesRedisRepo.GetProgress.redisClient.Get")
+	}
+	progress := &models.UploadProgress{}
+	if err := json.Unmarshal(progressBytes, progress); err != nil {
+		return nil, errors.Wrap(err, "filesRedisRepo.GetProgress.json.Unmarshal")
+	}
+	return progress, nil
+}
+
+func (r *filesRedisRepo) key(uploadID string) string {
+	return r.prefix + ":" + uploadID
+}
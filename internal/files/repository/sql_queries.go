@@ -0,0 +1,68 @@
+package repository
+
+const (
+	createFileQuery = `INSERT INTO files (owner_id, guest_id, name, content_type, size, bucket, object_key, status, checksum_sha256, blob_id,
+							created_at, updated_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now(), now())
+						RETURNING *`
+
+	getFileByIDQuery = `SELECT id, owner_id, guest_id, name, content_type, size, bucket, object_key, status, scan_result, checksum_sha256, blob_id,
+							created_at, updated_at
+						FROM files
+						WHERE id = $1`
+
+	deleteFileQuery = `DELETE FROM files WHERE id = $1 RETURNING *`
+
+	// Row lock keeps the blob from being deleted until the new reference is committed
+	lockBlobByChecksumQuery = `SELECT id, checksum_sha256, bucket, object_key, size, ref_count, created_at, updated_at
+						FROM file_blobs
+						WHERE checksum_sha256 = $1
+						FOR UPDATE`
+
+	createBlobQuery = `INSERT INTO file_blobs (checksum_sha256, bucket, object_key, size, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, now(), now())
+						ON CONFLICT (checksum_sha256) DO NOTHING`
+
+	promoteFileQuery = `UPDATE files
+						SET bucket = $1, object_key = $2, blob_id = $3, status = $4, scan_result = NULL, updated_at = now()
+						WHERE id = $5 AND status = 'pending'`
+
+	deleteUnreferencedBlobQuery = `DELETE FROM file_blobs WHERE id = $1 AND ref_count = 0 RETURNING *`
+
+	deleteUnreferencedBlobsQuery = `DELETE FROM file_blobs
+						WHERE id IN (SELECT id FROM file_blobs WHERE ref_count = 0 ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+						RETURNING *`
+
+	updateFileStatusQuery = `UPDATE files
+						SET bucket = $1, status = $2, scan_result = $3, updated_at = now()
+						WHERE id = $4`
+
+	mergeGuestFilesQuery = `UPDATE files
+						SET owner_id = $1, guest_id = NULL, updated_at = now()
+						WHERE guest_id = $2`
+
+	mergeGuestUploadsQuery = `UPDATE file_uploads
+						SET owner_id = $1, guest_id = NULL, updated_at = now()
+						WHERE guest_id = $2`
+
+	createUploadQuery = `INSERT INTO file_uploads (id, upload_id, owner_id, guest_id, name, content_type, size, part_size, part_count,
+							bucket, object_key, checksum_sha256, status, created_at, updated_at, expires_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now(), now(), $14)
+						RETURNING *`
+
+	getUploadQuery = `SELECT id, upload_id, owner_id, guest_id, name, content_type, size, part_size, part_count,
+							bucket, object_key, checksum_sha256, status, file_id, created_at, updated_at, expires_at
+						FROM file_uploads
+						WHERE id = $1`
+
+	updateUploadStatusQuery = `UPDATE file_uploads
+						SET status = $1, file_id = $2, updated_at = now()
+						WHERE id = $3 AND status = $4`
+
+	listStaleUploadsQuery = `SELECT id, upload_id, owner_id, guest_id, name, content_type, size, part_size, part_count,
+							bucket, object_key, checksum_sha256, status, file_id, created_at, updated_at, expires_at
+						FROM file_uploads
+						WHERE status = 'uploading' AND expires_at < now()
+						ORDER BY expires_at
+						LIMIT $1`
+)
@@ -0,0 +1,33 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package files
+
+import (
+	"context"
+	"io"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+)
+
+// Scan job type consumed by the job queue worker
+const ScanJobType = "files.scan"
+
+// Files use case
+type UseCase interface {
+	Upload(ctx context.Context, input models.UploadInput) (*models.File, error)
+	// Lasts as long as the request body, observe:nodeadline
+	UploadStream(ctx context.Context, input models.UploadInput, uploadID string) (*models.File, error)
+	GetUploadProgress(ctx context.Context, uploadID string) (*models.UploadProgress, error)
+	GetByID(ctx context.Context, fileID int64) (*models.File, error)
+	// Object is read after returning, observe:nodeadline
+	Download(ctx context.Context, fileID int64) (*models.File, io.ReadCloser, error)
+	Delete(ctx context.Context, fileID int64) error
+	RemoveUnreferencedBlobs(ctx context.Context) (int, error)
+	InitiateMultipart(ctx context.Context, req *models.MultipartUploadRequest) (*models.FileUpload, error)
+	PresignPart(ctx context.Context, id string, partNumber int) (*models.PresignedPart, error)
+	CompleteMultipart(ctx context.Context, id string) (*models.File, error)
+	AbortMultipart(ctx context.Context, id string) error
+	AbortStaleUploads(ctx context.Context) (int, error)
+	// Bounded by the scanner timeout, observe:nodeadline
+	HandleScanJob(ctx context.Context, job *jobqueue.Job) error
+}
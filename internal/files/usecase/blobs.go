@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+const unreferencedBlobsBatchSize = 100
+
+// Create record of a file uploaded to quarantine with its checksum. Content already stored clean is shared,
+// the quarantined copy is dropped and nothing is scanned, otherwise the file waits for the scanner
+func (u *filesUC) createFile(ctx context.Context, file *models.File) (*models.File, error) {
+	shared, err := u.repo.CreateFromBlob(ctx, file)
+	if err == nil {
+		u.removeObject(ctx, file.Bucket, file.ObjectKey)
+		return shared, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := u.repo.Create(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.queue.Enqueue(ctx, files.ScanJobType, scanJob{FileID: created.ID}); err != nil {
+		return nil, errors.Wrap(err, "filesUC.createFile.Enqueue")
+	}
+	return created, nil
+}
+
+// Mark scanned file clean as a reference to the blob with its content, the promoted object becomes the blob
+// unless another upload of the same content got there first
+func (u *filesUC) promote(ctx context.Context, file *models.File) error {
+	blob, err := u.repo.PromoteToBlob(ctx, file, &models.FileBlob{
+		ChecksumSHA256: *file.ChecksumSHA256,
+		Bucket:         file.Bucket,
+		ObjectKey:      file.ObjectKey,
+		Size:           file.Size,
+	})
+	if err != nil {
+		// Deleted by its owner while scanning
+		if errors.Is(err, sql.ErrNoRows) {
+			u.removeObject(ctx, file.Bucket, file.ObjectKey)
+			return nil
+		}
+		return err
+	}
+	if blob.Bucket != file.Bucket || blob.ObjectKey != file.ObjectKey {
+		u.removeObject(ctx, file.Bucket, file.ObjectKey)
+	}
+	return nil
+}
+
+// Delete file of the caller, its stored object is removed once no other file shares it
+func (u *filesUC) Delete(ctx context.Context, fileID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.Delete")
+	defer span.Finish()
+
+	if _, err := u.GetByID(ctx, fileID); err != nil {
+		return err
+	}
+
+	file, blob, err := u.repo.Delete(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case blob != nil:
+		u.removeObject(ctx, blob.Bucket, blob.ObjectKey)
+	case file.BlobID == nil && file.Status != models.FileStatusInfected:
+		// Pending, failed or uploaded before content was hashed, the object is not shared
+		u.removeObject(ctx, file.Bucket, file.ObjectKey)
+	}
+	return nil
+}
+
+// Remove one batch of blobs left without references, returns the number of removed blobs
+func (u *filesUC) RemoveUnreferencedBlobs(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.RemoveUnreferencedBlobs")
+	defer span.Finish()
+
+	blobs, err := u.repo.DeleteUnreferencedBlobs(ctx, unreferencedBlobsBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, blob := range blobs {
+		u.removeObject(ctx, blob.Bucket, blob.ObjectKey)
+	}
+	return len(blobs), nil
+}
+
+// Records are gone by the time objects are removed, a failure only leaves an orphaned object behind
+func (u *filesUC) removeObject(ctx context.Context, bucket string, objectKey string) {
+	if err := u.awsRepo.RemoveObject(ctx, bucket, objectKey); err != nil {
+		u.logger.Errorf("filesUC.removeObject bucket: %s, objectKey: %s, error: %v", bucket, objectKey, err)
+	}
+}
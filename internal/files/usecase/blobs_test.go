@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scanner"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+func upload(t *testing.T, uc files.UseCase, ctx context.Context, content string) *models.File {
+	file, err := uc.Upload(ctx, models.UploadInput{File: strings.NewReader(content), Name: "a.txt", Size: int64(len(content))})
+	require.NoError(t, err)
+	return file
+}
+
+func scan(t *testing.T, uc files.UseCase, file *models.File) {
+	payload, err := json.Marshal(scanJob{FileID: file.ID})
+	require.NoError(t, err)
+	require.NoError(t, uc.HandleScanJob(context.Background(), &jobqueue.Job{Type: files.ScanJobType, Payload: payload}))
+}
+
+func requireObject(t *testing.T, awsRepo files.AWSRepository, bucket string, objectKey string, exists bool) {
+	object, err := awsRepo.GetObject(context.Background(), bucket, objectKey)
+	if !exists {
+		require.Error(t, err)
+		return
+	}
+	require.NoError(t, err)
+	object.Close()
+}
+
+func TestFilesUC_DeduplicatedUploads(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	noop, err := scanner.NewScanner(scanner.Options{Driver: scanner.DriverNoop})
+	require.NoError(t, err)
+	cfg := &config.Config{Files: config.Files{
+		Bucket:           "files",
+		QuarantineBucket: "files-quarantine",
+		Stream:           config.Stream{MaxSizeMB: 1, ContentTypes: []string{"text/plain"}, ProgressPrefix: "progress", ProgressEveryMB: 1},
+	}}
+	awsRepo := repository.NewFilesBlobRepository(storage.NewLocal(store))
+	uc := NewFilesUseCase(cfg, repository.NewFilesMemoryRepository(), awsRepo, repository.NewFilesRedisRepo(redisClient, cfg.Files.Stream.ProgressPrefix), noop, jobqueue.NewQueue(redisClient, "files"), testutil.Logger(cfg))
+	alice, bob := testutil.AsUser(1), testutil.AsUser(2)
+
+	first := upload(t, uc, alice, "avatar")
+	require.Equal(t, models.FileStatusPending, first.Status)
+	require.Equal(t, "87bbe879c7a5f5784a70384bb49fa9513a6a3fbe4c2d388635e3c87611c03fae", *first.ChecksumSHA256)
+	scan(t, uc, first)
+	first, err = uc.GetByID(alice, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.FileStatusClean, first.Status)
+	require.NotNil(t, first.BlobID)
+
+	// Same content is shared at once, without scanning or a second object
+	second := upload(t, uc, bob, "avatar")
+	require.Equal(t, models.FileStatusClean, second.Status)
+	require.Equal(t, first.BlobID, second.BlobID)
+	require.Equal(t, first.ObjectKey, second.ObjectKey)
+
+	other := upload(t, uc, bob, "attachment")
+	require.Equal(t, models.FileStatusPending, other.Status)
+	require.NotEqual(t, *first.ChecksumSHA256, *other.ChecksumSHA256)
+
+	// Object stays until the last reference is deleted
+	require.NoError(t, uc.Delete(alice, first.ID))
+	_, object, err := uc.Download(bob, second.ID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(object)
+	object.Close()
+	require.NoError(t, err)
+	require.Equal(t, "avatar", string(content))
+
+	require.NoError(t, uc.Delete(bob, second.ID))
+	requireObject(t, awsRepo, second.Bucket, second.ObjectKey, false)
+
+	require.NoError(t, uc.Delete(bob, other.ID))
+	requireObject(t, awsRepo, other.Bucket, other.ObjectKey, false)
+}
+
+func TestFilesUC_ConcurrentDuplicatesShareOneBlob(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	noop, err := scanner.NewScanner(scanner.Options{Driver: scanner.DriverNoop})
+	require.NoError(t, err)
+	cfg := &config.Config{Files: config.Files{
+		Bucket:           "files",
+		QuarantineBucket: "files-quarantine",
+		Stream:           config.Stream{MaxSizeMB: 1, ContentTypes: []string{"text/plain"}, ProgressPrefix: "progress", ProgressEveryMB: 1},
+	}}
+	awsRepo := repository.NewFilesBlobRepository(storage.NewLocal(store))
+	uc := NewFilesUseCase(cfg, repository.NewFilesMemoryRepository(), awsRepo, repository.NewFilesRedisRepo(redisClient, cfg.Files.Stream.ProgressPrefix), noop, jobqueue.NewQueue(redisClient, "files"), testutil.Logger(cfg))
+	alice, bob := testutil.AsUser(1), testutil.AsUser(2)
+
+	// Both are pending when scanned, the second promotion finds the first blob
+	first := upload(t, uc, alice, "report")
+	second := upload(t, uc, bob, "report")
+	require.Equal(t, models.FileStatusPending, second.Status)
+	scan(t, uc, first)
+	scan(t, uc, second)
+
+	first, err = uc.GetByID(alice, first.ID)
+	require.NoError(t, err)
+	promoted, err := uc.GetByID(bob, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, first.BlobID, promoted.BlobID)
+	require.Equal(t, first.ObjectKey, promoted.ObjectKey)
+	requireObject(t, awsRepo, cfg.Files.Bucket, second.ObjectKey, false)
+
+	require.NoError(t, uc.Delete(alice, first.ID))
+	requireObject(t, awsRepo, first.Bucket, first.ObjectKey, true)
+	require.NoError(t, uc.Delete(bob, second.ID))
+	requireObject(t, awsRepo, first.Bucket, first.ObjectKey, false)
+}
+
+func TestFilesUC_DeleteForbidden(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	noop, err := scanner.NewScanner(scanner.Options{Driver: scanner.DriverNoop})
+	require.NoError(t, err)
+	cfg := &config.Config{Files: config.Files{
+		Bucket:           "files",
+		QuarantineBucket: "files-quarantine",
+		Stream:           config.Stream{MaxSizeMB: 1, ContentTypes: []string{"text/plain"}, ProgressPrefix: "progress", ProgressEveryMB: 1},
+	}}
+	awsRepo := repository.NewFilesBlobRepository(storage.NewLocal(store))
+	uc := NewFilesUseCase(cfg, repository.NewFilesMemoryRepository(), awsRepo, repository.NewFilesRedisRepo(redisClient, cfg.Files.Stream.ProgressPrefix), noop, jobqueue.NewQueue(redisClient, "files"), testutil.Logger(cfg))
+	file := upload(t, uc, testutil.AsUser(1), "private")
+
+	require.Error(t, uc.Delete(testutil.AsUser(2), file.ID))
+	requireObject(t, awsRepo, file.Bucket, file.ObjectKey, true)
+
+	// Deleted before scanning, the queued job has nothing left to do
+	require.NoError(t, uc.Delete(testutil.AsUser(1), file.ID))
+	requireObject(t, awsRepo, file.Bucket, file.ObjectKey, false)
+	scan(t, uc, file)
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+const (
+	// S3 limits, every part but the last must be at least 5 MiB and an upload has at most 10000 parts
+	minPartSize = 5 << 20
+	maxParts    = 10000
+
+	defaultPartSize       = 16 << 20
+	defaultPresignTTL     = 15 * time.Minute
+	defaultUploadTTL      = 24 * time.Hour
+	staleUploadsBatchSize = 100
+)
+
+const (
+	errUploadNotOpen    = "Upload is not in progress"
+	errUploadExpired    = "Upload expired"
+	errUploadIncomplete = "Upload is incomplete"
+	errChecksumMismatch = "Checksum mismatch"
+)
+
+// Start multipart upload into quarantine, parts are then uploaded through presigned URLs
+func (u *filesUC) InitiateMultipart(ctx context.Context, req *models.MultipartUploadRequest) (*models.FileUpload, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.InitiateMultipart")
+	defer span.Finish()
+
+	cfg := u.cfg.Files.Multipart
+	if maxSize := int64(cfg.MaxSizeMB) << 20; maxSize > 0 && req.Size > maxSize {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("file exceeds %d MB", cfg.MaxSizeMB))
+	}
+
+	ownerID, guestID, err := callerOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize := partSizeFor(req.Size, int64(cfg.PartSizeMB)<<20)
+	upload := &models.FileUpload{
+		ID:             uuid.New().String(),
+		OwnerID:        ownerID,
+		GuestID:        guestID,
+		Name:           req.Name,
+		ContentType:    req.ContentType,
+		Size:           req.Size,
+		PartSize:       partSize,
+		PartCount:      int((req.Size + partSize - 1) / partSize),
+		Bucket:         u.cfg.Files.QuarantineBucket,
+		ObjectKey:      newObjectKey(ownerID, guestID, req.Name),
+		ChecksumSHA256: strings.ToLower(req.ChecksumSHA256),
+		Status:         models.FileUploadStatusUploading,
+		ExpiresAt:      time.Now().Add(durationOr(time.Duration(cfg.UploadTTLMinutes)*time.Minute, defaultUploadTTL)),
+	}
+	if upload.ContentType == "" {
+		upload.ContentType = "application/octet-stream"
+	}
+
+	upload.UploadID, err = u.awsRepo.NewMultipartUpload(ctx, upload.Bucket, upload.ObjectKey, upload.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.repo.CreateUpload(ctx, upload)
+	if err != nil {
+		if abortErr := u.awsRepo.AbortMultipartUpload(ctx, upload.Bucket, upload.ObjectKey, upload.UploadID); abortErr != nil {
+			u.logger.Errorf("filesUC.InitiateMultipart.AbortMultipartUpload objectKey: %s, error: %v", upload.ObjectKey, abortErr)
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// Presign PUT of one part of an open upload
+func (u *filesUC) PresignPart(ctx context.Context, id string, partNumber int) (*models.PresignedPart, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.PresignPart")
+	defer span.Finish()
+
+	upload, err := u.getOpenUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if partNumber < 1 || partNumber > upload.PartCount {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("part_number must be between 1 and %d", upload.PartCount))
+	}
+
+	ttl := durationOr(time.Duration(u.cfg.Files.Multipart.PresignSeconds)*time.Second, defaultPresignTTL)
+	presigned, err := u.awsRepo.PresignUploadPart(ctx, upload.Bucket, upload.ObjectKey, upload.UploadID, partNumber, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PresignedPart{PartNumber: partNumber, URL: presigned, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// Assemble uploaded parts, verify the declared checksum and hand the file over to scanning,
+// content already stored clean is shared instead
+func (u *filesUC) CompleteMultipart(ctx context.Context, id string) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.CompleteMultipart")
+	defer span.Finish()
+
+	upload, err := u.getOpenUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parts are taken from storage rather than the client, so nothing uploaded is left out or forged
+	parts, err := u.awsRepo.ListParts(ctx, upload.Bucket, upload.ObjectKey, upload.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	completeParts, problems := verifyParts(upload, parts)
+	if len(problems) > 0 {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errUploadIncomplete, problems)
+	}
+	if err := u.awsRepo.CompleteMultipartUpload(ctx, upload.Bucket, upload.ObjectKey, upload.UploadID, completeParts); err != nil {
+		return nil, err
+	}
+
+	checksum, err := u.objectChecksum(ctx, upload.Bucket, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != upload.ChecksumSHA256 {
+		u.logger.Warnf("filesUC.CompleteMultipart checksum mismatch uploadID: %s, expected: %s, actual: %s", upload.ID, upload.ChecksumSHA256, checksum)
+		if err := u.awsRepo.RemoveObject(ctx, upload.Bucket, upload.ObjectKey); err != nil {
+			u.logger.Errorf("filesUC.CompleteMultipart.RemoveObject objectKey: %s, error: %v", upload.ObjectKey, err)
+		}
+		upload.Status = models.FileUploadStatusFailed
+		if err := u.repo.UpdateUploadStatus(ctx, upload, models.FileUploadStatusUploading); err != nil {
+			return nil, err
+		}
+		return nil, httpErrors.NewRestError(http.StatusUnprocessableEntity, errChecksumMismatch, map[string]string{
+			"expected": upload.ChecksumSHA256,
+			"actual":   checksum,
+		})
+	}
+
+	file, err := u.createFile(ctx, &models.File{
+		OwnerID:        upload.OwnerID,
+		GuestID:        upload.GuestID,
+		Name:           upload.Name,
+		ContentType:    upload.ContentType,
+		Size:           upload.Size,
+		Bucket:         upload.Bucket,
+		ObjectKey:      upload.ObjectKey,
+		Status:         models.FileStatusPending,
+		ChecksumSHA256: &checksum,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upload.Status = models.FileUploadStatusCompleted
+	upload.FileID = &file.ID
+	if err := u.repo.UpdateUploadStatus(ctx, upload, models.FileUploadStatusUploading); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Abort open upload of the caller and drop its parts
+func (u *filesUC) AbortMultipart(ctx context.Context, id string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.AbortMultipart")
+	defer span.Finish()
+
+	upload, err := u.getUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+	if upload.Status != models.FileUploadStatusUploading {
+		return httpErrors.NewRestError(http.StatusConflict, errUploadNotOpen, upload.Status)
+	}
+	return u.abortUpload(ctx, upload)
+}
+
+// Abort one batch of uploads left open past their expiry, returns the number of aborted uploads
+func (u *filesUC) AbortStaleUploads(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.AbortStaleUploads")
+	defer span.Finish()
+
+	uploads, err := u.repo.ListStaleUploads(ctx, staleUploadsBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	aborted := 0
+	for _, upload := range uploads {
+		if err := u.abortUpload(ctx, upload); err != nil {
+			// Completed or aborted between listing and abort
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			u.logger.Errorf("filesUC.AbortStaleUploads.abortUpload uploadID: %s, error: %v", upload.ID, err)
+			continue
+		}
+		aborted++
+	}
+	return aborted, nil
+}
+
+func (u *filesUC) abortUpload(ctx context.Context, upload *models.FileUpload) error {
+	if err := u.awsRepo.AbortMultipartUpload(ctx, upload.Bucket, upload.ObjectKey, upload.UploadID); err != nil {
+		return err
+	}
+	// Completion may have assembled the object before failing, do not leave it behind
+	if err := u.awsRepo.RemoveObject(ctx, upload.Bucket, upload.ObjectKey); err != nil {
+		return err
+	}
+	upload.Status = models.FileUploadStatusAborted
+	return u.repo.UpdateUploadStatus(ctx, upload, models.FileUploadStatusUploading)
+}
+
+func (u *filesUC) getUpload(ctx context.Context, id string) (*models.FileUpload, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, httpErrors.NewBadRequestError("invalid upload id")
+	}
+	upload, err := u.repo.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedByCaller(ctx, upload.OwnerID, upload.GuestID) {
+		u.logger.Errorf("filesUC.getUpload uploadID: %s, not owned by caller", upload.ID)
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return upload, nil
+}
+
+// Upload of the caller which can still receive parts
+func (u *filesUC) getOpenUpload(ctx context.Context, id string) (*models.FileUpload, error) {
+	upload, err := u.getUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != models.FileUploadStatusUploading {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errUploadNotOpen, upload.Status)
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errUploadExpired, upload.ExpiresAt)
+	}
+	return upload, nil
+}
+
+func (u *filesUC) objectChecksum(ctx context.Context, bucket string, objectKey string) (string, error) {
+	object, err := u.awsRepo.GetObject(ctx, bucket, objectKey)
+	if err != nil {
+		return "", err
+	}
+	defer object.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, object); err != nil {
+		return "", errors.Wrap(err, "filesUC.objectChecksum.Copy")
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Check every expected part is present with the expected size, returns the parts to assemble or the problems found
+func verifyParts(upload *models.FileUpload, parts []storage.Part) ([]storage.Part, []string) {
+	uploaded := make(map[int]storage.Part, len(parts))
+	for _, part := range parts {
+		uploaded[part.Number] = part
+	}
+
+	var problems []string
+	completeParts := make([]storage.Part, 0, upload.PartCount)
+	for number := 1; number <= upload.PartCount; number++ {
+		part, ok := uploaded[number]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("part %d is missing", number))
+			continue
+		}
+		expected := upload.PartSize
+		if number == upload.PartCount {
+			expected = upload.Size - upload.PartSize*int64(upload.PartCount-1)
+		}
+		if part.Size != expected {
+			problems = append(problems, fmt.Sprintf("part %d has %d bytes, expected %d", number, part.Size, expected))
+			continue
+		}
+		completeParts = append(completeParts, storage.Part{Number: number, ETag: part.ETag})
+	}
+	if len(uploaded) > upload.PartCount {
+		problems = append(problems, fmt.Sprintf("%d parts uploaded, expected %d", len(uploaded), upload.PartCount))
+	}
+	return completeParts, problems
+}
+
+// Part size from config within S3 limits, grown in whole MiB when the file would need too many parts
+func partSizeFor(size int64, configured int64) int64 {
+	partSize := configured
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+	if size > partSize*maxParts {
+		perPart := (size + maxParts - 1) / maxParts
+		partSize = (perPart + 1<<20 - 1) &^ (1<<20 - 1)
+	}
+	return partSize
+}
+
+func durationOr(d time.Duration, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
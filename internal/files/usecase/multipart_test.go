@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+func TestPartSizeFor(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, int64(defaultPartSize), partSizeFor(100<<20, 0))
+	require.Equal(t, int64(minPartSize), partSizeFor(100<<20, 1<<20))
+
+	// 200 GiB does not fit into 10000 parts of 16 MiB
+	size := int64(200) << 30
+	partSize := partSizeFor(size, 16<<20)
+	require.Zero(t, partSize%(1<<20))
+	require.LessOrEqual(t, (size+partSize-1)/partSize, int64(maxParts))
+}
+
+func TestVerifyParts(t *testing.T) {
+	t.Parallel()
+
+	upload := &models.FileUpload{Size: 12 << 20, PartSize: 5 << 20, PartCount: 3}
+
+	complete, problems := verifyParts(upload, []storage.Part{
+		{Number: 2, ETag: "b", Size: 5 << 20},
+		{Number: 1, ETag: "a", Size: 5 << 20},
+		{Number: 3, ETag: "c", Size: 2 << 20},
+	})
+	require.Empty(t, problems)
+	require.Equal(t, []storage.Part{{Number: 1, ETag: "a"}, {Number: 2, ETag: "b"}, {Number: 3, ETag: "c"}}, complete)
+
+	_, problems = verifyParts(upload, []storage.Part{
+		{Number: 1, ETag: "a", Size: 5 << 20},
+		{Number: 3, ETag: "c", Size: 1 << 20},
+	})
+	require.Equal(t, []string{"part 2 is missing", "part 3 has 1048576 bytes, expected 2097152"}, problems)
+}
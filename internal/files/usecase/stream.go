@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+const (
+	defaultStreamProgressTTL   = time.Hour
+	defaultStreamProgressEvery = 8 << 20
+)
+
+const (
+	errStreamTooLarge          = "File exceeds the streamed upload limit"
+	errStreamUnsupportedType   = "Unsupported content type"
+	errStreamProgressNotExists = "Upload progress not found"
+)
+
+// Stream request body into quarantine without buffering it, progress is readable under uploadID while
+// the body arrives. A size of -1 means unknown length, the limit is then enforced while reading
+func (u *filesUC) UploadStream(ctx context.Context, input models.UploadInput, uploadID string) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.UploadStream")
+	defer span.Finish()
+
+	cfg := u.cfg.Files.Stream
+	if input.Name == "" {
+		return nil, httpErrors.NewBadRequestError("name is required")
+	}
+	maxSize := int64(cfg.MaxSizeMB) << 20
+	if maxSize > 0 && input.Size > maxSize {
+		return nil, httpErrors.NewRestError(http.StatusRequestEntityTooLarge, errStreamTooLarge, fmt.Sprintf("%d MB", cfg.MaxSizeMB))
+	}
+	contentType, err := u.streamContentType(input.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, guestID, err := callerOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uploadID == "" {
+		uploadID = uuid.New().String()
+	}
+
+	file := &models.File{
+		OwnerID:     ownerID,
+		GuestID:     guestID,
+		Name:        input.Name,
+		ContentType: contentType,
+		Size:        input.Size,
+		Bucket:      u.cfg.Files.QuarantineBucket,
+		ObjectKey:   newObjectKey(ownerID, guestID, input.Name),
+		Status:      models.FileStatusPending,
+	}
+	body := &streamReader{
+		ctx:     ctx,
+		reader:  input.File,
+		maxSize: maxSize,
+		every:   int64(cfg.ProgressEveryMB) << 20,
+		progress: &models.UploadProgress{
+			ID:      uploadID,
+			OwnerID: ownerID,
+			GuestID: guestID,
+			Name:    input.Name,
+			Size:    input.Size,
+			Status:  models.StreamStatusUploading,
+		},
+		report: u.reportProgress,
+	}
+	if body.every <= 0 {
+		body.every = defaultStreamProgressEvery
+	}
+	body.report(ctx, body.progress)
+
+	hash := sha256.New()
+	input.File = io.TeeReader(body, hash)
+	input.BucketName = file.Bucket
+	input.ContentType = contentType
+	info, err := u.awsRepo.PutObject(ctx, input, file.ObjectKey)
+	if body.err != nil {
+		err = body.err
+	}
+	if err == nil && input.Size >= 0 && body.progress.Received != input.Size {
+		err = httpErrors.NewBadRequestError(fmt.Sprintf("received %d of %d bytes", body.progress.Received, input.Size))
+	}
+	if err != nil {
+		if info != nil {
+			u.removeObject(ctx, file.Bucket, file.ObjectKey)
+		}
+		u.finishProgress(body.progress, models.StreamStatusFailed)
+		return nil, err
+	}
+	file.Size = body.progress.Received
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	file.ChecksumSHA256 = &checksum
+
+	created, err := u.createFile(ctx, file)
+	if err != nil {
+		u.finishProgress(body.progress, models.StreamStatusFailed)
+		return nil, err
+	}
+	body.progress.FileID = &created.ID
+	u.finishProgress(body.progress, models.StreamStatusCompleted)
+	return created, nil
+}
+
+// Get progress of a streamed upload, owner or uploading guest only
+func (u *filesUC) GetUploadProgress(ctx context.Context, uploadID string) (*models.UploadProgress, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.GetUploadProgress")
+	defer span.Finish()
+
+	progress, err := u.redisRepo.GetProgress(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	// Someone else's upload is reported as missing so ids can't be probed
+	if progress == nil || !ownedByCaller(ctx, progress.OwnerID, progress.GuestID) {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, errStreamProgressNotExists, uploadID)
+	}
+	return progress, nil
+}
+
+// Media type accepted for streaming, without parameters, octet-stream when the client sent none
+func (u *filesUC) streamContentType(contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", httpErrors.NewBadRequestError(errStreamUnsupportedType)
+	}
+	allowed := u.cfg.Files.Stream.ContentTypes
+	if len(allowed) == 0 {
+		return contentType, nil
+	}
+	for _, accepted := range allowed {
+		if strings.EqualFold(accepted, mediaType) {
+			return contentType, nil
+		}
+	}
+	return "", httpErrors.NewRestError(http.StatusUnsupportedMediaType, errStreamUnsupportedType, mediaType)
+}
+
+// Progress is best effort, a failing redis does not fail the upload
+func (u *filesUC) reportProgress(ctx context.Context, progress *models.UploadProgress) {
+	progress.UpdatedAt = time.Now().UTC()
+	ttl := u.cfg.Files.Stream.ProgressTTLSeconds
+	if ttl <= 0 {
+		ttl = int(defaultStreamProgressTTL / time.Second)
+	}
+	if err := u.redisRepo.SetProgress(ctx, progress, ttl); err != nil {
+		u.logger.Warnf("filesUC.reportProgress uploadID: %s, %v", progress.ID, err)
+	}
+}
+
+// Final progress is stored even when the request was canceled
+func (u *filesUC) finishProgress(progress *models.UploadProgress, status string) {
+	progress.Status = status
+	u.reportProgress(context.Background(), progress)
+}
+
+// Request body counting the bytes read, stopping on cancellation or past the limit and reporting
+// progress every so many bytes
+type streamReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	maxSize  int64
+	every    int64
+	reported int64
+	progress *models.UploadProgress
+	report   func(ctx context.Context, progress *models.UploadProgress)
+	err      error
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if err := r.ctx.Err(); err != nil {
+		r.err = err
+		return 0, err
+	}
+
+	n, err := r.reader.Read(p)
+	r.progress.Received += int64(n)
+	if r.maxSize > 0 && r.progress.Received > r.maxSize {
+		r.err = httpErrors.NewRestError(http.StatusRequestEntityTooLarge, errStreamTooLarge, fmt.Sprintf("%d bytes", r.maxSize))
+		return n, r.err
+	}
+	if r.progress.Received-r.reported >= r.every {
+		r.reported = r.progress.Received
+		r.report(r.ctx, r.progress)
+	}
+	return n, err
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scanner"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+func TestFilesUC_UploadStream(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	noop, err := scanner.NewScanner(scanner.Options{Driver: scanner.DriverNoop})
+	require.NoError(t, err)
+	cfg := &config.Config{Files: config.Files{
+		Bucket:           "files",
+		QuarantineBucket: "files-quarantine",
+		Stream:           config.Stream{MaxSizeMB: 1, ContentTypes: []string{"text/plain"}, ProgressPrefix: "progress", ProgressEveryMB: 1},
+	}}
+	awsRepo := repository.NewFilesBlobRepository(storage.NewLocal(store))
+	uc := NewFilesUseCase(cfg, repository.NewFilesMemoryRepository(), awsRepo, repository.NewFilesRedisRepo(redisClient, cfg.Files.Stream.ProgressPrefix), noop, jobqueue.NewQueue(redisClient, "files"), testutil.Logger(cfg))
+	alice := testutil.AsUser(1)
+	content := bytes.Repeat([]byte("a"), 3<<18)
+
+	file, err := uc.UploadStream(alice, models.UploadInput{File: bytes.NewReader(content), Name: "a.txt", Size: -1, ContentType: "text/plain; charset=utf-8"}, "upload-1")
+	require.NoError(t, err)
+	require.Equal(t, models.FileStatusPending, file.Status)
+	require.Equal(t, int64(len(content)), file.Size)
+	object, err := awsRepo.GetObject(context.Background(), file.Bucket, file.ObjectKey)
+	require.NoError(t, err)
+	stored, err := io.ReadAll(object)
+	object.Close()
+	require.NoError(t, err)
+	require.Equal(t, content, stored)
+
+	progress, err := uc.GetUploadProgress(alice, "upload-1")
+	require.NoError(t, err)
+	require.Equal(t, models.StreamStatusCompleted, progress.Status)
+	require.Equal(t, int64(len(content)), progress.Received)
+	require.Equal(t, file.ID, *progress.FileID)
+
+	// Other callers can't tell the upload exists
+	_, err = uc.GetUploadProgress(testutil.AsUser(2), "upload-1")
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+	_, err = uc.GetUploadProgress(alice, "missing")
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+}
+
+func TestFilesUC_UploadStreamLimits(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	noop, err := scanner.NewScanner(scanner.Options{Driver: scanner.DriverNoop})
+	require.NoError(t, err)
+	cfg := &config.Config{Files: config.Files{
+		Bucket:           "files",
+		QuarantineBucket: "files-quarantine",
+		Stream:           config.Stream{MaxSizeMB: 1, ContentTypes: []string{"text/plain"}, ProgressPrefix: "progress", ProgressEveryMB: 1},
+	}}
+	awsRepo := repository.NewFilesBlobRepository(storage.NewLocal(store))
+	uc := NewFilesUseCase(cfg, repository.NewFilesMemoryRepository(), awsRepo, repository.NewFilesRedisRepo(redisClient, cfg.Files.Stream.ProgressPrefix), noop, jobqueue.NewQueue(redisClient, "files"), testutil.Logger(cfg))
+	alice := testutil.AsUser(1)
+	tooLarge := bytes.Repeat([]byte("a"), 1<<20+1)
+
+	_, err = uc.UploadStream(alice, models.UploadInput{File: bytes.NewReader(tooLarge), Name: "a.txt", Size: int64(len(tooLarge))}, "")
+	testutil.RequireStatus(t, err, http.StatusRequestEntityTooLarge)
+
+	// Unknown length is cut off while reading
+	_, err = uc.UploadStream(alice, models.UploadInput{File: bytes.NewReader(tooLarge), Name: "a.txt", Size: -1, ContentType: "text/plain"}, "upload-2")
+	testutil.RequireStatus(t, err, http.StatusRequestEntityTooLarge)
+	progress, err := uc.GetUploadProgress(alice, "upload-2")
+	require.NoError(t, err)
+	require.Equal(t, models.StreamStatusFailed, progress.Status)
+
+	_, err = uc.UploadStream(alice, models.UploadInput{File: strings.NewReader("x"), Name: "a.png", Size: 1, ContentType: "image/png"}, "")
+	testutil.RequireStatus(t, err, http.StatusUnsupportedMediaType)
+
+	_, err = uc.UploadStream(alice, models.UploadInput{File: strings.NewReader("x"), Name: "a.txt", Size: 2, ContentType: "text/plain"}, "")
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	_, err = uc.UploadStream(context.Background(), models.UploadInput{File: strings.NewReader("x"), Name: "a.txt", Size: 1, ContentType: "text/plain"}, "")
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+
+	canceled, cancel := context.WithCancel(alice)
+	cancel()
+	_, err = uc.UploadStream(canceled, models.UploadInput{File: strings.NewReader("x"), Name: "a.txt", Size: 1, ContentType: "text/plain"}, "")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scanner"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Scan job payload
+type scanJob struct {
+	FileID int64 `json:"file_id"`
+}
+
+// Files UseCase
+type filesUC struct {
+	cfg       *config.Config
+	repo      files.Repository
+	awsRepo   files.AWSRepository
+	redisRepo files.RedisRepository
+	scanner   scanner.Scanner
+	queue     *jobqueue.Queue
+	logger    logger.Logger
+}
+
+// Files UseCase constructor
+func NewFilesUseCase(
+	cfg *config.Config,
+	repo files.Repository,
+	awsRepo files.AWSRepository,
+	redisRepo files.RedisRepository,
+	scanner scanner.Scanner,
+	queue *jobqueue.Queue,
+	logger logger.Logger,
+) files.UseCase {
+	return &filesUC{cfg: cfg, repo: repo, awsRepo: awsRepo, redisRepo: redisRepo, scanner: scanner, queue: queue, logger: logger}
+}
+
+// Upload file to quarantine and schedule scanning, owned by the current user or guest.
+// Content already stored clean is shared instead, the file is clean right away
+func (u *filesUC) Upload(ctx context.Context, input models.UploadInput) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.Upload")
+	defer span.Finish()
+
+	if maxSize := int64(u.cfg.Files.MaxSizeMB) << 20; maxSize > 0 && input.Size > maxSize {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("file exceeds %d MB", u.cfg.Files.MaxSizeMB))
+	}
+
+	file := &models.File{
+		Name:        input.Name,
+		ContentType: input.ContentType,
+		Size:        input.Size,
+		Bucket:      u.cfg.Files.QuarantineBucket,
+		Status:      models.FileStatusPending,
+	}
+
+	ownerID, guestID, err := callerOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	file.OwnerID, file.GuestID = ownerID, guestID
+	file.ObjectKey = newObjectKey(ownerID, guestID, input.Name)
+	input.BucketName = u.cfg.Files.QuarantineBucket
+	hash := sha256.New()
+	input.File = io.TeeReader(input.File, hash)
+	if _, err := u.awsRepo.PutObject(ctx, input, file.ObjectKey); err != nil {
+		return nil, err
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	file.ChecksumSHA256 = &checksum
+
+	return u.createFile(ctx, file)
+}
+
+// Get file record, owner or uploading guest only
+func (u *filesUC) GetByID(ctx context.Context, fileID int64) (*models.File, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.GetByID")
+	defer span.Finish()
+
+	file, err := u.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.validateOwner(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (u *filesUC) validateOwner(ctx context.Context, file *models.File) error {
+	if ownedByCaller(ctx, file.OwnerID, file.GuestID) {
+		return nil
+	}
+
+	u.logger.Errorf("filesUC.validateOwner fileID: %d, not owned by caller", file.ID)
+	return httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+}
+
+// Current user or guest becoming the owner of new uploads
+func callerOwner(ctx context.Context) (*int, *string, error) {
+	if user, err := utils.GetUserFromCtx(ctx); err == nil {
+		return &user.User.ID, nil, nil
+	}
+	if guestID, ok := utils.GetGuestIDFromCtx(ctx); ok {
+		return nil, &guestID, nil
+	}
+	return nil, nil, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
+}
+
+func ownedByCaller(ctx context.Context, ownerID *int, guestID *string) bool {
+	if user, err := utils.GetUserFromCtx(ctx); err == nil {
+		return ownerID != nil && *ownerID == user.User.ID
+	}
+	if callerGuestID, ok := utils.GetGuestIDFromCtx(ctx); ok {
+		return guestID != nil && *guestID == callerGuestID
+	}
+	return false
+}
+
+// Object key namespaced by owner, keeping the original extension
+func newObjectKey(ownerID *int, guestID *string, name string) string {
+	ownerPrefix := ""
+	if ownerID != nil {
+		ownerPrefix = strconv.Itoa(*ownerID)
+	} else if guestID != nil {
+		ownerPrefix = "guest-" + *guestID
+	}
+	return fmt.Sprintf("%s/%s%s", ownerPrefix, uuid.New().String(), filepath.Ext(name))
+}
+
+// Open file content, only files which passed scanning are served
+func (u *filesUC) Download(ctx context.Context, fileID int64) (*models.File, io.ReadCloser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.Download")
+	defer span.Finish()
+
+	file, err := u.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.Status != models.FileStatusClean {
+		return nil, nil, httpErrors.NewRestError(http.StatusConflict, "File is not available", file.Status)
+	}
+
+	object, err := u.awsRepo.GetObject(ctx, file.Bucket, file.ObjectKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, object, nil
+}
+
+// Scan job handler, promotes clean files and drops infected ones
+func (u *filesUC) HandleScanJob(ctx context.Context, job *jobqueue.Job) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filesUC.HandleScanJob")
+	defer span.Finish()
+
+	payload := &scanJob{}
+	if err := json.Unmarshal(job.Payload, payload); err != nil {
+		return errors.Wrap(err, "filesUC.HandleScanJob.json.Unmarshal")
+	}
+
+	file, err := u.repo.GetByID(ctx, payload.FileID)
+	if err != nil {
+		// Deleted by its owner before scanning
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if file.Status != models.FileStatusPending {
+		return nil
+	}
+
+	result, err := u.scan(ctx, file)
+	if err != nil {
+		if job.IsLastAttempt() {
+			file.Status = models.FileStatusFailed
+			scanResult := err.Error()
+			file.ScanResult = &scanResult
+			if updateErr := u.repo.UpdateStatus(ctx, file); updateErr != nil {
+				u.logger.Errorf("filesUC.HandleScanJob.UpdateStatus fileID: %d, error: %v", file.ID, updateErr)
+			}
+		}
+		return err
+	}
+
+	if !result.Clean {
+		if err := u.awsRepo.RemoveObject(ctx, file.Bucket, file.ObjectKey); err != nil {
+			return err
+		}
+		file.Status = models.FileStatusInfected
+		file.ScanResult = &result.Signature
+		u.logger.Warnf("filesUC.HandleScanJob infected fileID: %d, objectKey: %s, signature: %s", file.ID, file.ObjectKey, result.Signature)
+		return u.repo.UpdateStatus(ctx, file)
+	}
+
+	if err := u.awsRepo.MoveObject(ctx, file.Bucket, u.cfg.Files.Bucket, file.ObjectKey); err != nil {
+		return err
+	}
+	file.Bucket = u.cfg.Files.Bucket
+	// Uploaded before content was hashed, the object stays its own
+	if file.ChecksumSHA256 == nil {
+		file.Status = models.FileStatusClean
+		file.ScanResult = nil
+		return u.repo.UpdateStatus(ctx, file)
+	}
+	return u.promote(ctx, file)
+}
+
+func (u *filesUC) scan(ctx context.Context, file *models.File) (*scanner.Result, error) {
+	object, err := u.awsRepo.GetObject(ctx, file.Bucket, file.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	return u.scanner.Scan(ctx, object)
+}
@@ -0,0 +1,17 @@
+package guest
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Moves data created by a guest to an account, called inside the merge transaction
+type DataMerger interface {
+	MergeGuestData(ctx context.Context, tx *sqlx.Tx, guestID string, userID int) (int64, error)
+}
+
+// Guest repository
+type Repository interface {
+	Merge(ctx context.Context, guestID string, userID int) (int64, error)
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/guest"
+)
+
+// Guest Repository for in-memory data mergers, dev mode stand-in for Postgres
+type guestMemoryRepo struct {
+	mergers []guest.DataMerger
+}
+
+// Guest in-memory Repository constructor, mergers run in order without a transaction
+func NewGuestMemoryRepository(mergers ...guest.DataMerger) guest.Repository {
+	return &guestMemoryRepo{mergers: mergers}
+}
+
+// Reassign all guest data to the user, a failing merger leaves earlier ones applied
+func (r *guestMemoryRepo) Merge(ctx context.Context, guestID string, userID int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "guestMemoryRepo.Merge")
+	defer span.Finish()
+
+	var merged int64
+	for _, merger := range r.mergers {
+		rows, err := merger.MergeGuestData(ctx, nil, guestID, userID)
+		if err != nil {
+			return 0, errors.Wrap(err, "guestMemoryRepo.Merge.MergeGuestData")
+		}
+		merged += rows
+	}
+	return merged, nil
+}
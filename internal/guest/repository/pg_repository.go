@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/guest"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Guest Repository
+type guestRepo struct {
+	db      *sqlx.DB
+	mergers []guest.DataMerger
+}
+
+// Guest Repository constructor, mergers run in order inside one transaction
+func NewGuestRepository(db *sqlx.DB, mergers ...guest.DataMerger) guest.Repository {
+	return &guestRepo{db: db, mergers: mergers}
+}
+
+// Reassign all guest data to the user, either every merger applies or none does
+func (r *guestRepo) Merge(ctx context.Context, guestID string, userID int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "guestRepo.Merge")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "guestRepo.Merge.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var merged int64
+	for _, merger := range r.mergers {
+		rows, err := merger.MergeGuestData(ctx, tx, guestID, userID)
+		if err != nil {
+			return 0, errors.Wrap(err, "guestRepo.Merge.MergeGuestData")
+		}
+		merged += rows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "guestRepo.Merge.Commit")
+	}
+	return merged, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+// Connection recording how its transactions end
+type txConn struct {
+	commits   int
+	rollbacks int
+}
+
+func (c *txConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *txConn) Close() error                        { return nil }
+func (c *txConn) Begin() (driver.Tx, error)           { return txEnd{conn: c}, nil }
+
+type txEnd struct {
+	conn *txConn
+}
+
+func (t txEnd) Commit() error   { t.conn.commits++; return nil }
+func (t txEnd) Rollback() error { t.conn.rollbacks++; return nil }
+
+type txConnector struct {
+	conn *txConn
+}
+
+func (c txConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c txConnector) Driver() driver.Driver                        { return nil }
+
+// Merger moving rows of one table, remembers the transaction it ran in
+type fakeMerger struct {
+	rows int64
+	err  error
+	tx   *sqlx.Tx
+}
+
+func (m *fakeMerger) MergeGuestData(ctx context.Context, tx *sqlx.Tx, guestID string, userID int) (int64, error) {
+	m.tx = tx
+	return m.rows, m.err
+}
+
+func newTxDB(t *testing.T) (*sqlx.DB, *txConn) {
+	conn := &txConn{}
+	db := sqlx.NewDb(sql.OpenDB(txConnector{conn: conn}), "guest_tx")
+	t.Cleanup(func() { db.Close() })
+	return db, conn
+}
+
+func TestGuestRepo_Merge(t *testing.T) {
+	t.Parallel()
+
+	db, conn := newTxDB(t)
+	carts, wishlists := &fakeMerger{rows: 2}, &fakeMerger{rows: 3}
+	merged, err := NewGuestRepository(db, carts, wishlists).Merge(context.Background(), "guest-1", 7)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), merged)
+	require.Same(t, carts.tx, wishlists.tx)
+	require.Equal(t, 1, conn.commits)
+	require.Zero(t, conn.rollbacks)
+}
+
+func TestGuestRepo_Merge_Rollback(t *testing.T) {
+	t.Parallel()
+
+	// A failing merger rolls back the rows the earlier ones moved and stops the later ones
+	db, conn := newTxDB(t)
+	carts, wishlists, orders := &fakeMerger{rows: 2}, &fakeMerger{err: errors.New("duplicate key")}, &fakeMerger{rows: 1}
+	merged, err := NewGuestRepository(db, carts, wishlists, orders).Merge(context.Background(), "guest-1", 7)
+	require.ErrorContains(t, err, "duplicate key")
+	require.Zero(t, merged)
+	require.NotNil(t, carts.tx)
+	require.Nil(t, orders.tx)
+	require.Zero(t, conn.commits)
+	require.Equal(t, 1, conn.rollbacks)
+}
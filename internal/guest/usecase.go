@@ -0,0 +1,9 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package guest
+
+import "context"
+
+// Guest use case
+type UseCase interface {
+	Merge(ctx context.Context, guestID string, userID int) error
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/guest"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Guest UseCase
+type guestUC struct {
+	repo   guest.Repository
+	logger logger.Logger
+}
+
+// Guest UseCase constructor
+func NewGuestUseCase(repo guest.Repository, logger logger.Logger) guest.UseCase {
+	return &guestUC{repo: repo, logger: logger}
+}
+
+// Merge guest data into the account
+func (u *guestUC) Merge(ctx context.Context, guestID string, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "guestUC.Merge")
+	defer span.Finish()
+
+	merged, err := u.repo.Merge(ctx, guestID, userID)
+	if err != nil {
+		return err
+	}
+
+	u.logger.Infof("guestUC.Merge guestID: %s, userID: %d, rows: %d", guestID, userID, merged)
+	return nil
+}
@@ -0,0 +1,9 @@
+package hrsync
+
+import "github.com/labstack/echo/v4"
+
+// HR sync HTTP Handlers interface
+type Handlers interface {
+	Sync() echo.HandlerFunc
+	ListReports() echo.HandlerFunc
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// HR sync handlers
+type hrSyncHandlers struct {
+	cfg      *config.Config
+	hrSyncUC hrsync.UseCase
+	logger   logger.Logger
+}
+
+// NewHRSyncHandlers HR sync handlers constructor
+func NewHRSyncHandlers(cfg *config.Config, hrSyncUC hrsync.UseCase, log logger.Logger) hrsync.Handlers {
+	return &hrSyncHandlers{cfg: cfg, hrSyncUC: hrSyncUC, logger: log}
+}
+
+// Sync godoc
+// @Summary Sync users from HR
+// @Description Apply HR records changed since the last pass and return the reconciliation report, admin only
+// @Tags HRSync
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.HRSyncReport
+// @Failure 409 {object} httpErrors.RestError
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/hr-sync [post]
+func (h *hrSyncHandlers) Sync() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "hrSyncHandlers.Sync")
+		defer span.Finish()
+
+		report, err := h.hrSyncUC.Sync(ctx, hrsync.TriggerManual)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// ListReports godoc
+// @Summary List HR sync reports
+// @Description Reconciliation reports of the most recent sync passes, newest first, admin only
+// @Tags HRSync
+// @Accept json
+// @Produce json
+// @Param limit query int false "number of reports"
+// @Success 200 {object} models.HRSyncReportsList
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/hr-sync/reports [get]
+func (h *hrSyncHandlers) ListReports() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "hrSyncHandlers.ListReports")
+		defer span.Finish()
+
+		var limit int
+		if raw := c.QueryParam("limit"); raw != "" {
+			var err error
+			if limit, err = strconv.Atoi(raw); err != nil {
+				return httpErrors.NewBadRequestError("limit must be a number")
+			}
+		}
+
+		reports, err := h.hrSyncUC.ListReports(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, reports)
+	}
+}
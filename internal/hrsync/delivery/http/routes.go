@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map HR sync routes, group is already restricted to administrators
+func MapHRSyncRoutes(adminGroup *echo.Group, h hrsync.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.POST("/hr-sync", h.Sync(), mw.CSRF)
+	adminGroup.GET("/hr-sync/reports", h.ListReports())
+}
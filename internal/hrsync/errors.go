@@ -0,0 +1,10 @@
+package hrsync
+
+import (
+	"net/http"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+// Another sync pass holds the lock
+var ErrSyncInProgress = httpErrors.NewRestError(http.StatusConflict, "hr sync already in progress", nil)
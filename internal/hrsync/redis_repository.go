@@ -0,0 +1,21 @@
+package hrsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// HR sync redis repository, holds the feed cursor, links of HR records to local users and recent reports
+type RedisRepository interface {
+	GetCursor(ctx context.Context) (string, error)
+	SetCursor(ctx context.Context, cursor string) error
+	GetLink(ctx context.Context, externalID string) (int, error)
+	SetLink(ctx context.Context, externalID string, userID int) error
+	SaveReport(ctx context.Context, report *models.HRSyncReport, keep int) error
+	ListReports(ctx context.Context, limit int) ([]*models.HRSyncReport, error)
+	// Lock held for ttl so passes never overlap, false when another pass holds it
+	Lock(ctx context.Context, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context) error
+}
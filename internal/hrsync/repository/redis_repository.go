@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Deletes the lock only while this instance still holds it
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// HR sync redis repository
+type hrSyncRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+	instance    string
+}
+
+// HR sync redis repository constructor
+func NewHRSyncRedisRepo(redisClient *redis.Client, prefix string) hrsync.RedisRepository {
+	return &hrSyncRedisRepo{redisClient: redisClient, prefix: prefix, instance: uuid.New().String()}
+}
+
+// Get feed cursor of the last applied page, empty before the first pass
+func (r *hrSyncRedisRepo) GetCursor(ctx context.Context) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.GetCursor")
+	defer span.Finish()
+
+	cursor, err := r.redisClient.Get(ctx, r.key("cursor")).Result()
+	if err != nil && err != redis.Nil {
+		return "", errors.Wrap(err, "hrSyncRedisRepo.GetCursor.Get")
+	}
+	return cursor, nil
+}
+
+// Set feed cursor
+func (r *hrSyncRedisRepo) SetCursor(ctx context.Context, cursor string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.SetCursor")
+	defer span.Finish()
+
+	if err := r.redisClient.Set(ctx, r.key("cursor"), cursor, 0).Err(); err != nil {
+		return errors.Wrap(err, "hrSyncRedisRepo.SetCursor.Set")
+	}
+	return nil
+}
+
+// Get local user id linked to an HR record, 0 when not linked yet
+func (r *hrSyncRedisRepo) GetLink(ctx context.Context, externalID string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.GetLink")
+	defer span.Finish()
+
+	userID, err := r.redisClient.HGet(ctx, r.key("links"), externalID).Int()
+	if err != nil && err != redis.Nil {
+		return 0, errors.Wrap(err, "hrSyncRedisRepo.GetLink.HGet")
+	}
+	return userID, nil
+}
+
+// Link HR record to a local user
+func (r *hrSyncRedisRepo) SetLink(ctx context.Context, externalID string, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.SetLink")
+	defer span.Finish()
+
+	if err := r.redisClient.HSet(ctx, r.key("links"), externalID, userID).Err(); err != nil {
+		return errors.Wrap(err, "hrSyncRedisRepo.SetLink.HSet")
+	}
+	return nil
+}
+
+// Save report and drop all but the newest keep reports
+func (r *hrSyncRedisRepo) SaveReport(ctx context.Context, report *models.HRSyncReport, keep int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.SaveReport")
+	defer span.Finish()
+
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "hrSyncRedisRepo.SaveReport.json.Marshal")
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.LPush(ctx, r.key("reports"), reportBytes)
+	pipe.LTrim(ctx, r.key("reports"), 0, int64(keep-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "hrSyncRedisRepo.SaveReport.pipe.Exec")
+	}
+	return nil
+}
+
+// List newest reports first
+func (r *hrSyncRedisRepo) ListReports(ctx context.Context, limit int) ([]*models.HRSyncReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.ListReports")
+	defer span.Finish()
+
+	raw, err := r.redisClient.LRange(ctx, r.key("reports"), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "hrSyncRedisRepo.ListReports.LRange")
+	}
+
+	reports := make([]*models.HRSyncReport, 0, len(raw))
+	for _, item := range raw {
+		report := &models.HRSyncReport{}
+		if err := json.Unmarshal([]byte(item), report); err != nil {
+			return nil, errors.Wrap(err, "hrSyncRedisRepo.ListReports.json.Unmarshal")
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Take the sync lock for ttl
+func (r *hrSyncRedisRepo) Lock(ctx context.Context, ttl time.Duration) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.Lock")
+	defer span.Finish()
+
+	acquired, err := r.redisClient.SetNX(ctx, r.key("lock"), r.instance, ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "hrSyncRedisRepo.Lock.SetNX")
+	}
+	return acquired, nil
+}
+
+// Release the sync lock held by this instance
+func (r *hrSyncRedisRepo) Unlock(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncRedisRepo.Unlock")
+	defer span.Finish()
+
+	if err := unlockScript.Run(ctx, r.redisClient, []string{r.key("lock")}, r.instance).Err(); err != nil && err != redis.Nil {
+		return errors.Wrap(err, "hrSyncRedisRepo.Unlock.Run")
+	}
+	return nil
+}
+
+func (r *hrSyncRedisRepo) key(name string) string {
+	return r.prefix + ":" + name
+}
@@ -0,0 +1,54 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package hrsync
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+)
+
+// What started a sync pass
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// How a field conflict between HR and the local user is resolved
+const (
+	// HR record overwrites the local value
+	ResolveHR = "hr"
+	// Local value is kept and reported
+	ResolveLocal = "local"
+	// Most recently updated side wins
+	ResolveNewest = "newest"
+)
+
+// Resolutions recorded on mismatches
+const (
+	ResolutionUpdated    = "updated"
+	ResolutionKeptLocal  = "kept_local"
+	ResolutionInactiveHR = "inactive_in_hr"
+)
+
+// HR user sync use case
+type UseCase interface {
+	// observe:nodeadline, a pass drains the whole feed
+	Sync(ctx context.Context, trigger string) (*models.HRSyncReport, error)
+	ListReports(ctx context.Context, limit int) (*models.HRSyncReportsList, error)
+}
+
+// Local users the HR records are applied to, implemented by the auth use case
+type Users interface {
+	GetByID(ctx context.Context, userID int) (*models.UserWithRole, error)
+	GetByEmail(ctx context.Context, email string) (*models.UserWithRole, error)
+	Register(ctx context.Context, user *dto.RegisterUserRequest) (*models.UserWithToken, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+}
+
+// Feed of changed HR records
+type Feed interface {
+	Changes(ctx context.Context, cursor string, limit int) (*services.HRChanges, error)
+}
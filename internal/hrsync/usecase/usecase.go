@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+)
+
+const (
+	defaultPageSize    = 100
+	defaultMaxPages    = 50
+	defaultKeepReports = 20
+	maxListedReports   = 100
+	lockTTL            = 30 * time.Minute
+)
+
+// Synced user fields, in report order
+var syncedFields = []string{"username", "email", "phone", "timezone"}
+
+// HR user sync UseCase, users are synced from the HR system declared as the hr service
+type hrSyncUC struct {
+	cfg       *config.Config
+	feed      hrsync.Feed
+	users     hrsync.Users
+	redisRepo hrsync.RedisRepository
+	clock     clock.Clock
+	logger    logger.Logger
+}
+
+// HR user sync UseCase constructor
+func NewHRSyncUseCase(
+	cfg *config.Config,
+	feed hrsync.Feed,
+	users hrsync.Users,
+	redisRepo hrsync.RedisRepository,
+	clk clock.Clock,
+	logger logger.Logger,
+) hrsync.UseCase {
+	return &hrSyncUC{cfg: cfg, feed: feed, users: users, redisRepo: redisRepo, clock: clk, logger: logger}
+}
+
+// Apply HR records changed since the stored cursor in pages of PageSize, the cursor advances after every page so an
+// interrupted pass resumes where it stopped. The report is saved even when the feed fails.
+func (u *hrSyncUC) Sync(ctx context.Context, trigger string) (*models.HRSyncReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncUC.Sync")
+	defer span.Finish()
+
+	acquired, err := u.redisRepo.Lock(ctx, lockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, hrsync.ErrSyncInProgress
+	}
+	defer func() {
+		if err := u.redisRepo.Unlock(context.Background()); err != nil {
+			u.logger.Errorf("hrSyncUC.Sync.Unlock: %v", err)
+		}
+	}()
+
+	cursor, err := u.redisRepo.GetCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.HRSyncReport{
+		ID:         uuid.New().String(),
+		Trigger:    trigger,
+		StartedAt:  u.clock.Now(),
+		FromCursor: cursor,
+		ToCursor:   cursor,
+		Mismatches: make([]models.HRSyncMismatch, 0),
+	}
+
+	syncErr := u.drain(ctx, report)
+	report.FinishedAt = u.clock.Now()
+
+	u.logger.Infof(
+		"hrSyncUC.Sync %s: fetched %d, created %d, updated %d, unchanged %d, skipped %d, failed %d, mismatches %d, cursor %q",
+		report.ID, report.Fetched, report.Created, report.Updated, report.Unchanged, report.Skipped, report.Failed, len(report.Mismatches), report.ToCursor,
+	)
+	if err := u.redisRepo.SaveReport(ctx, report, u.keepReports()); err != nil {
+		u.logger.Errorf("hrSyncUC.Sync.SaveReport: %v", err)
+	}
+
+	if syncErr != nil {
+		return nil, syncErr
+	}
+	return report, nil
+}
+
+// List most recent reports
+func (u *hrSyncUC) ListReports(ctx context.Context, limit int) (*models.HRSyncReportsList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "hrSyncUC.ListReports")
+	defer span.Finish()
+
+	if limit <= 0 || limit > maxListedReports {
+		limit = u.keepReports()
+	}
+
+	reports, err := u.redisRepo.ListReports(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.HRSyncReportsList{Reports: reports}, nil
+}
+
+func (u *hrSyncUC) drain(ctx context.Context, report *models.HRSyncReport) error {
+	pageSize := u.cfg.HRSync.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	maxPages := u.cfg.HRSync.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	for page := 0; page < maxPages; page++ {
+		if ctx.Err() != nil {
+			report.Interrupted = true
+			return ctx.Err()
+		}
+
+		changes, err := u.feed.Changes(ctx, report.ToCursor, pageSize)
+		if err != nil {
+			report.Interrupted = true
+			return errors.Wrap(err, "hrSyncUC.Sync.Changes")
+		}
+
+		for _, record := range changes.Users {
+			u.apply(ctx, record, report)
+		}
+
+		if changes.NextCursor != "" && changes.NextCursor != report.ToCursor {
+			if err := u.redisRepo.SetCursor(ctx, changes.NextCursor); err != nil {
+				report.Interrupted = true
+				return err
+			}
+			report.ToCursor = changes.NextCursor
+		}
+		if !changes.HasMore {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Apply one HR record, outcomes and conflicts are recorded on the report. Unknown records are created when
+// Create is set
+func (u *hrSyncUC) apply(ctx context.Context, record services.HRUser, report *models.HRSyncReport) {
+	report.Fetched++
+	record.Email = strings.ToLower(strings.TrimSpace(record.Email))
+	fail := func(err error) {
+		report.Failed++
+		report.Errors = append(report.Errors, models.HRSyncError{ExternalID: record.ExternalID, Error: err.Error()})
+	}
+
+	if record.ExternalID == "" {
+		fail(errors.New("record without external id"))
+		return
+	}
+
+	user, err := u.findUser(ctx, record)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if user == nil {
+		if !record.Active || !u.cfg.HRSync.Create {
+			report.Skipped++
+			return
+		}
+		if err := u.create(ctx, record); err != nil {
+			fail(err)
+			return
+		}
+		report.Created++
+		return
+	}
+
+	if !record.Active {
+		report.Skipped++
+		report.Mismatches = append(report.Mismatches, models.HRSyncMismatch{
+			ExternalID: record.ExternalID,
+			UserID:     user.ID,
+			Field:      "active",
+			Local:      "true",
+			External:   "false",
+			Resolution: hrsync.ResolutionInactiveHR,
+		})
+		return
+	}
+
+	update := &models.User{ID: user.ID}
+	changed := false
+	for _, field := range syncedFields {
+		local, external := fieldValue(user, field), externalValue(record, field)
+		if external == "" || sameValue(field, local, external) {
+			continue
+		}
+
+		resolution := hrsync.ResolutionKeptLocal
+		if u.hrWins(field, record, user) {
+			resolution = hrsync.ResolutionUpdated
+			setField(update, field, external)
+			changed = true
+		}
+		report.Mismatches = append(report.Mismatches, models.HRSyncMismatch{
+			ExternalID: record.ExternalID,
+			UserID:     user.ID,
+			Field:      field,
+			Local:      local,
+			External:   external,
+			Resolution: resolution,
+		})
+	}
+
+	if !changed {
+		report.Unchanged++
+		return
+	}
+	if _, err := u.users.Update(ctx, update); err != nil {
+		fail(err)
+		return
+	}
+	report.Updated++
+}
+
+// Local user of an HR record, by link first and by email for records seen the first time
+func (u *hrSyncUC) findUser(ctx context.Context, record services.HRUser) (*models.User, error) {
+	userID, err := u.redisRepo.GetLink(ctx, record.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 {
+		user, err := u.users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "linked user %d", userID)
+		}
+		return &user.User, nil
+	}
+
+	if record.Email == "" {
+		return nil, nil
+	}
+	user, err := u.users.GetByEmail(ctx, record.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := u.redisRepo.SetLink(ctx, record.ExternalID, user.User.ID); err != nil {
+		return nil, err
+	}
+	return &user.User, nil
+}
+
+// Register HR record as a local user, the random password is never handed out so the account
+// is reached through a password reset or an external identity provider
+func (u *hrSyncUC) create(ctx context.Context, record services.HRUser) error {
+	password, err := randomPassword()
+	if err != nil {
+		return err
+	}
+
+	created, err := u.users.Register(ctx, &dto.RegisterUserRequest{
+		Username: record.Username,
+		Email:    record.Email,
+		Password: password,
+	})
+	if err != nil {
+		return err
+	}
+	if err := u.redisRepo.SetLink(ctx, record.ExternalID, created.User.ID); err != nil {
+		return err
+	}
+
+	if record.Phone != "" || record.Timezone != "" {
+		if _, err := u.users.Update(ctx, &models.User{ID: created.User.ID, Phone: record.Phone, Timezone: record.Timezone}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Does the HR value replace the local one under the configured rule of field: hr, local or newest. Fields
+// without a rule follow the HR record
+func (u *hrSyncUC) hrWins(field string, record services.HRUser, user *models.User) bool {
+	switch u.cfg.HRSync.Conflicts[field] {
+	case hrsync.ResolveLocal:
+		return false
+	case hrsync.ResolveNewest:
+		return record.UpdatedAt.After(user.UpdatedAt)
+	default:
+		return true
+	}
+}
+
+func (u *hrSyncUC) keepReports() int {
+	if u.cfg.HRSync.KeepReports <= 0 {
+		return defaultKeepReports
+	}
+	return u.cfg.HRSync.KeepReports
+}
+
+func fieldValue(user *models.User, field string) string {
+	switch field {
+	case "username":
+		return user.Username
+	case "email":
+		return user.Email
+	case "phone":
+		return user.Phone
+	case "timezone":
+		return user.Timezone
+	}
+	return ""
+}
+
+func externalValue(record services.HRUser, field string) string {
+	switch field {
+	case "username":
+		return record.Username
+	case "email":
+		return record.Email
+	case "phone":
+		return record.Phone
+	case "timezone":
+		return record.Timezone
+	}
+	return ""
+}
+
+func setField(user *models.User, field string, value string) {
+	switch field {
+	case "username":
+		user.Username = value
+	case "email":
+		user.Email = value
+	case "phone":
+		user.Phone = value
+	case "timezone":
+		user.Timezone = value
+	}
+}
+
+func sameValue(field string, local string, external string) bool {
+	if field == "email" {
+		return strings.EqualFold(strings.TrimSpace(local), strings.TrimSpace(external))
+	}
+	return local == external
+}
+
+func randomPassword() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "hrSyncUC.randomPassword.rand.Read")
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
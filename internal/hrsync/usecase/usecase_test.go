@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+)
+
+type pagedFeed map[string]*services.HRChanges
+
+func (f pagedFeed) Changes(_ context.Context, cursor string, _ int) (*services.HRChanges, error) {
+	if page, ok := f[cursor]; ok {
+		return page, nil
+	}
+	return &services.HRChanges{NextCursor: cursor}, nil
+}
+
+type memoryUsers map[int]*models.User
+
+func (m memoryUsers) GetByID(_ context.Context, userID int) (*models.UserWithRole, error) {
+	user, ok := m[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.UserWithRole{User: *user}, nil
+}
+
+func (m memoryUsers) GetByEmail(_ context.Context, email string) (*models.UserWithRole, error) {
+	for _, user := range m {
+		if user.Email == email {
+			return &models.UserWithRole{User: *user}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m memoryUsers) Register(_ context.Context, req *dto.RegisterUserRequest) (*models.UserWithToken, error) {
+	user := &models.User{ID: len(m) + 1, Username: req.Username, Email: req.Email}
+	m[user.ID] = user
+	return &models.UserWithToken{User: user}, nil
+}
+
+func (m memoryUsers) Update(_ context.Context, update *models.User) (*models.User, error) {
+	user := m[update.ID]
+	for _, field := range syncedFields {
+		if value := fieldValue(update, field); value != "" {
+			setField(user, field, value)
+		}
+	}
+	return user, nil
+}
+
+type memoryRepo struct {
+	cursor  string
+	links   map[string]int
+	reports []*models.HRSyncReport
+	locked  bool
+}
+
+func (r *memoryRepo) GetCursor(context.Context) (string, error) { return r.cursor, nil }
+
+func (r *memoryRepo) SetCursor(_ context.Context, cursor string) error {
+	r.cursor = cursor
+	return nil
+}
+
+func (r *memoryRepo) GetLink(_ context.Context, externalID string) (int, error) {
+	return r.links[externalID], nil
+}
+
+func (r *memoryRepo) SetLink(_ context.Context, externalID string, userID int) error {
+	r.links[externalID] = userID
+	return nil
+}
+
+func (r *memoryRepo) SaveReport(_ context.Context, report *models.HRSyncReport, _ int) error {
+	r.reports = append([]*models.HRSyncReport{report}, r.reports...)
+	return nil
+}
+
+func (r *memoryRepo) ListReports(_ context.Context, limit int) ([]*models.HRSyncReport, error) {
+	if limit > len(r.reports) {
+		limit = len(r.reports)
+	}
+	return r.reports[:limit], nil
+}
+
+func (r *memoryRepo) Lock(context.Context, time.Duration) (bool, error) {
+	if r.locked {
+		return false, nil
+	}
+	r.locked = true
+	return true, nil
+}
+
+func (r *memoryRepo) Unlock(context.Context) error {
+	r.locked = false
+	return nil
+}
+
+func TestHRSyncUC_Sync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cfg := &config.Config{HRSync: config.HRSync{
+		Create:    true,
+		Conflicts: map[string]string{"username": hrsync.ResolveLocal, "phone": hrsync.ResolveNewest},
+	}}
+	users := memoryUsers{
+		1: {ID: 1, Username: "ann", Email: "ann@example.com", Phone: "+15550001", UpdatedAt: now},
+		2: {ID: 2, Username: "bob", Email: "bob@example.com", UpdatedAt: now},
+	}
+	feed := pagedFeed{
+		"": {
+			Users: []services.HRUser{
+				// Linked by email, email follows HR by default, username is kept, phone is older in HR
+				{ExternalID: "e1", Username: "annie", Email: "ANN@example.com", Phone: "+15550002", Timezone: "Europe/Berlin", Active: true, UpdatedAt: now.Add(-time.Hour)},
+				{ExternalID: "e2", Email: "bob@example.com", Active: false},
+			},
+			NextCursor: "c1",
+			HasMore:    true,
+		},
+		"c1": {
+			Users: []services.HRUser{
+				{ExternalID: "e3", Username: "carl", Email: "carl@example.com", Active: true},
+				{ExternalID: "e4", Username: "dora", Email: "dora@example.com", Active: false},
+				{Username: "nobody"},
+			},
+			NextCursor: "c2",
+		},
+	}
+	repo := &memoryRepo{links: map[string]int{}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	uc := NewHRSyncUseCase(cfg, feed, users, repo, clock.NewFrozen(now), appLogger)
+	ctx := context.Background()
+
+	report, err := uc.Sync(ctx, hrsync.TriggerManual)
+	require.NoError(t, err)
+	require.Equal(t, "c2", report.ToCursor)
+	require.Equal(t, "c2", repo.cursor)
+	require.Equal(t, 5, report.Fetched)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 2, report.Skipped)
+	require.Equal(t, 1, report.Failed)
+
+	resolutions := make(map[string]string)
+	for _, mismatch := range report.Mismatches {
+		resolutions[mismatch.ExternalID+":"+mismatch.Field] = mismatch.Resolution
+	}
+	require.Equal(t, map[string]string{
+		"e1:username": hrsync.ResolutionKeptLocal,
+		"e1:phone":    hrsync.ResolutionKeptLocal,
+		"e1:timezone": hrsync.ResolutionUpdated,
+		"e2:active":   hrsync.ResolutionInactiveHR,
+	}, resolutions)
+	require.Equal(t, "ann", users[1].Username)
+	require.Equal(t, "Europe/Berlin", users[1].Timezone)
+	require.Equal(t, 1, repo.links["e1"])
+	require.Equal(t, 3, repo.links["e3"])
+
+	// Next pass resumes from the stored cursor
+	report, err = uc.Sync(ctx, hrsync.TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.Fetched)
+
+	list, err := uc.ListReports(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Reports, 2)
+
+	repo.locked = true
+	_, err = uc.Sync(ctx, hrsync.TriggerManual)
+	require.ErrorIs(t, err, hrsync.ErrSyncInProgress)
+}
@@ -0,0 +1,10 @@
+package ipfilter
+
+import "github.com/labstack/echo/v4"
+
+// IP filter HTTP Handlers interface
+type Handlers interface {
+	GetRules() echo.HandlerFunc
+	AddRule() echo.HandlerFunc
+	RemoveRule() echo.HandlerFunc
+}
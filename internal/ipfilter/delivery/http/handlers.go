@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// IP filter handlers
+type ipFilterHandlers struct {
+	cfg        *config.Config
+	ipFilterUC ipfilter.UseCase
+	logger     logger.Logger
+}
+
+// NewIPFilterHandlers IP filter handlers constructor
+func NewIPFilterHandlers(cfg *config.Config, ipFilterUC ipfilter.UseCase, log logger.Logger) ipfilter.Handlers {
+	return &ipFilterHandlers{cfg: cfg, ipFilterUC: ipFilterUC, logger: log}
+}
+
+// GetRules godoc
+// @Summary Get ip filter rules
+// @Description Allow and deny rules of a route group, config and admin managed entries merged, admin only
+// @Tags IPFilter
+// @Accept json
+// @Produce json
+// @Param group path string true "route group"
+// @Success 200 {object} models.IPFilterRules
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/ipfilter/{group} [get]
+func (h *ipFilterHandlers) GetRules() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "ipFilterHandlers.GetRules")
+		defer span.Finish()
+
+		rules, err := h.ipFilterUC.GetRules(ctx, c.Param("group"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, rules)
+	}
+}
+
+// AddRule godoc
+// @Summary Add ip filter rule
+// @Description Add a CIDR or single address to the allow or deny list of a route group, admin only
+// @Tags IPFilter
+// @Accept json
+// @Produce json
+// @Param group path string true "route group"
+// @Param list path string true "allow or deny"
+// @Param rule body models.IPFilterRule true "rule"
+// @Success 201 {object} models.IPFilterRule
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/ipfilter/{group}/{list} [post]
+func (h *ipFilterHandlers) AddRule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "ipFilterHandlers.AddRule")
+		defer span.Finish()
+
+		rule := &models.IPFilterRule{}
+		if err := utils.ReadRequest(c, rule); err != nil {
+			return err
+		}
+
+		if err := h.ipFilterUC.AddRule(ctx, c.Param("group"), c.Param("list"), rule.CIDR); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// RemoveRule godoc
+// @Summary Remove ip filter rule
+// @Description Remove an admin managed rule from the allow or deny list of a route group, admin only
+// @Tags IPFilter
+// @Accept json
+// @Produce json
+// @Param group path string true "route group"
+// @Param list path string true "allow or deny"
+// @Param rule body models.IPFilterRule true "rule"
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/ipfilter/{group}/{list} [delete]
+func (h *ipFilterHandlers) RemoveRule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "ipFilterHandlers.RemoveRule")
+		defer span.Finish()
+
+		rule := &models.IPFilterRule{}
+		if err := utils.ReadRequest(c, rule); err != nil {
+			return err
+		}
+
+		if err := h.ipFilterUC.RemoveRule(ctx, c.Param("group"), c.Param("list"), rule.CIDR); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
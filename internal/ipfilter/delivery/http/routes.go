@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map ip filter routes, group is already restricted to administrators
+func MapIPFilterRoutes(adminGroup *echo.Group, h ipfilter.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.GET("/ipfilter/:group", h.GetRules())
+	adminGroup.POST("/ipfilter/:group/:list", h.AddRule(), mw.CSRF)
+	adminGroup.DELETE("/ipfilter/:group/:list", h.RemoveRule(), mw.CSRF)
+}
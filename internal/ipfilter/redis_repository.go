@@ -0,0 +1,10 @@
+package ipfilter
+
+import "context"
+
+// IP filter redis repository, holds admin managed rules
+type RedisRepository interface {
+	GetRules(ctx context.Context, group string, list string) ([]string, error)
+	AddRule(ctx context.Context, group string, list string, cidr string) error
+	RemoveRule(ctx context.Context, group string, list string, cidr string) error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+)
+
+// IP filter redis repository
+type ipFilterRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// IP filter redis repository constructor
+func NewIPFilterRedisRepo(redisClient *redis.Client, prefix string) ipfilter.RedisRepository {
+	return &ipFilterRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Get admin managed rules of a group list
+func (r *ipFilterRedisRepo) GetRules(ctx context.Context, group string, list string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterRedisRepo.GetRules")
+	defer span.Finish()
+
+	rules, err := r.redisClient.SMembers(ctx, r.createKey(group, list)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "ipFilterRedisRepo.GetRules.SMembers")
+	}
+	return rules, nil
+}
+
+// Add rule to a group list
+func (r *ipFilterRedisRepo) AddRule(ctx context.Context, group string, list string, cidr string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterRedisRepo.AddRule")
+	defer span.Finish()
+
+	if err := r.redisClient.SAdd(ctx, r.createKey(group, list), cidr).Err(); err != nil {
+		return errors.Wrap(err, "ipFilterRedisRepo.AddRule.SAdd")
+	}
+	return nil
+}
+
+// Remove rule from a group list
+func (r *ipFilterRedisRepo) RemoveRule(ctx context.Context, group string, list string, cidr string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterRedisRepo.RemoveRule")
+	defer span.Finish()
+
+	if err := r.redisClient.SRem(ctx, r.createKey(group, list), cidr).Err(); err != nil {
+		return errors.Wrap(err, "ipFilterRedisRepo.RemoveRule.SRem")
+	}
+	return nil
+}
+
+func (r *ipFilterRedisRepo) createKey(group string, list string) string {
+	return fmt.Sprintf("%s:%s:%s", r.prefix, group, list)
+}
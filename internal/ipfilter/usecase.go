@@ -0,0 +1,21 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package ipfilter
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+const (
+	ListAllow = "allow"
+	ListDeny  = "deny"
+)
+
+// IP filter use case
+type UseCase interface {
+	Check(ctx context.Context, group string, ip string) (*models.IPFilterDecision, error)
+	GetRules(ctx context.Context, group string) (*models.IPFilterRules, error)
+	AddRule(ctx context.Context, group string, list string, cidr string) error
+	RemoveRule(ctx context.Context, group string, list string, cidr string) error
+}
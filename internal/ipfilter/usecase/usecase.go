@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const defaultRefreshSeconds = 30
+
+// Parsed rules of a group, reloaded from redis once stale
+type groupRules struct {
+	allow    []rule
+	deny     []rule
+	loadedAt time.Time
+}
+
+type rule struct {
+	source string
+	ipNet  *net.IPNet
+}
+
+// IP filter UseCase
+type ipFilterUC struct {
+	cfg       *config.Config
+	redisRepo ipfilter.RedisRepository
+	clock     clock.Clock
+	logger    logger.Logger
+
+	mu    sync.RWMutex
+	cache map[string]*groupRules
+}
+
+// IP filter UseCase constructor
+func NewIPFilterUseCase(cfg *config.Config, redisRepo ipfilter.RedisRepository, clk clock.Clock, logger logger.Logger) ipfilter.UseCase {
+	return &ipFilterUC{cfg: cfg, redisRepo: redisRepo, clock: clk, logger: logger, cache: make(map[string]*groupRules)}
+}
+
+// Check client ip, deny rules win and a non empty allow list rejects everything it does not match
+func (u *ipFilterUC) Check(ctx context.Context, group string, ip string) (*models.IPFilterDecision, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterUC.Check")
+	defer span.Finish()
+
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return &models.IPFilterDecision{Allowed: false, Reason: "invalid client ip"}, nil
+	}
+
+	rules, err := u.loadRules(ctx, group)
+	if err != nil {
+		// Fail open for the redis managed rules only, the config file rules still apply
+		u.logger.Errorf("ipFilterUC.Check loadRules group: %s, error: %v", group, err)
+		rules = u.staticRules(group)
+	}
+
+	for _, r := range rules.deny {
+		if r.ipNet.Contains(clientIP) {
+			return &models.IPFilterDecision{Allowed: false, Reason: "denied", Rule: r.source}, nil
+		}
+	}
+
+	if len(rules.allow) == 0 {
+		return &models.IPFilterDecision{Allowed: true}, nil
+	}
+	for _, r := range rules.allow {
+		if r.ipNet.Contains(clientIP) {
+			return &models.IPFilterDecision{Allowed: true, Rule: r.source}, nil
+		}
+	}
+	return &models.IPFilterDecision{Allowed: false, Reason: "not in allow list"}, nil
+}
+
+// Get config and admin managed rules of a group
+func (u *ipFilterUC) GetRules(ctx context.Context, group string) (*models.IPFilterRules, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterUC.GetRules")
+	defer span.Finish()
+
+	rules, err := u.loadRules(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.IPFilterRules{Group: group, Allow: make([]string, 0, len(rules.allow)), Deny: make([]string, 0, len(rules.deny))}
+	for _, r := range rules.allow {
+		result.Allow = append(result.Allow, r.source)
+	}
+	for _, r := range rules.deny {
+		result.Deny = append(result.Deny, r.source)
+	}
+	return result, nil
+}
+
+// Add admin managed rule
+func (u *ipFilterUC) AddRule(ctx context.Context, group string, list string, cidr string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterUC.AddRule")
+	defer span.Finish()
+
+	if err := validateList(list); err != nil {
+		return err
+	}
+	if _, err := parseRule(cidr); err != nil {
+		return httpErrors.NewBadRequestError(errors.Wrap(err, "ipFilterUC.AddRule.parseRule"))
+	}
+
+	if err := u.redisRepo.AddRule(ctx, group, list, cidr); err != nil {
+		return err
+	}
+	u.invalidate(group)
+	return nil
+}
+
+// Remove admin managed rule
+func (u *ipFilterUC) RemoveRule(ctx context.Context, group string, list string, cidr string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ipFilterUC.RemoveRule")
+	defer span.Finish()
+
+	if err := validateList(list); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.RemoveRule(ctx, group, list, cidr); err != nil {
+		return err
+	}
+	u.invalidate(group)
+	return nil
+}
+
+func (u *ipFilterUC) loadRules(ctx context.Context, group string) (*groupRules, error) {
+	refresh := u.cfg.IPFilter.RefreshSeconds
+	if refresh <= 0 {
+		refresh = defaultRefreshSeconds
+	}
+
+	u.mu.RLock()
+	cached, ok := u.cache[group]
+	u.mu.RUnlock()
+	if ok && u.clock.Since(cached.loadedAt) < time.Duration(refresh)*time.Second {
+		return cached, nil
+	}
+
+	static := u.cfg.IPFilter.Groups[group]
+	allow, err := u.redisRepo.GetRules(ctx, group, ipfilter.ListAllow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := u.redisRepo.GetRules(ctx, group, ipfilter.ListDeny)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &groupRules{
+		allow:    u.parseRules(group, append(append([]string{}, static.Allow...), allow...)),
+		deny:     u.parseRules(group, append(append([]string{}, static.Deny...), deny...)),
+		loadedAt: u.clock.Now(),
+	}
+
+	u.mu.Lock()
+	u.cache[group] = rules
+	u.mu.Unlock()
+	return rules, nil
+}
+
+// Rules of the config file alone
+func (u *ipFilterUC) staticRules(group string) *groupRules {
+	static := u.cfg.IPFilter.Groups[group]
+	return &groupRules{allow: u.parseRules(group, static.Allow), deny: u.parseRules(group, static.Deny)}
+}
+
+// Parse rules, invalid entries are logged and skipped so one typo cannot lock everybody out
+func (u *ipFilterUC) parseRules(group string, sources []string) []rule {
+	rules := make([]rule, 0, len(sources))
+	for _, source := range sources {
+		ipNet, err := parseRule(source)
+		if err != nil {
+			u.logger.Errorf("ipFilterUC.parseRules group: %s, rule: %s, error: %v", group, source, err)
+			continue
+		}
+		rules = append(rules, rule{source: source, ipNet: ipNet})
+	}
+	return rules
+}
+
+func (u *ipFilterUC) invalidate(group string) {
+	u.mu.Lock()
+	delete(u.cache, group)
+	u.mu.Unlock()
+}
+
+// Parse CIDR or a single address
+func parseRule(source string) (*net.IPNet, error) {
+	source = strings.TrimSpace(source)
+	if strings.Contains(source, "/") {
+		_, ipNet, err := net.ParseCIDR(source)
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(source)
+	if ip == nil {
+		return nil, errors.Errorf("invalid ip address %q", source)
+	}
+	if ip.To4() != nil {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func validateList(list string) error {
+	if list != ipfilter.ListAllow && list != ipfilter.ListDeny {
+		return httpErrors.NewBadRequestError("list must be allow or deny")
+	}
+	return nil
+}
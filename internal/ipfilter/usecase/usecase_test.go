@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+type memoryRepo map[string][]string
+
+func (m memoryRepo) GetRules(_ context.Context, group string, list string) ([]string, error) {
+	return m[group+":"+list], nil
+}
+
+func (m memoryRepo) AddRule(_ context.Context, group string, list string, cidr string) error {
+	m[group+":"+list] = append(m[group+":"+list], cidr)
+	return nil
+}
+
+func (m memoryRepo) RemoveRule(_ context.Context, group string, list string, cidr string) error {
+	return nil
+}
+
+type downRepo struct{ memoryRepo }
+
+func (downRepo) GetRules(context.Context, string, string) ([]string, error) {
+	return nil, errors.New("redis: connection refused")
+}
+
+func TestIPFilterUC_Check(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{IPFilter: config.IPFilter{
+		Groups: map[string]config.IPFilterGroup{
+			"admin": {Allow: []string{"10.0.0.0/8", "bogus"}},
+		},
+	}}
+	repo := memoryRepo{}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	uc := NewIPFilterUseCase(cfg, repo, clock.NewFrozen(time.Now()), appLogger)
+	ctx := context.Background()
+
+	decision, err := uc.Check(ctx, "admin", "10.1.2.3")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	decision, err = uc.Check(ctx, "admin", "192.168.1.1")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+
+	require.NoError(t, uc.AddRule(ctx, "admin", ipfilter.ListDeny, "10.1.2.3"))
+	decision, err = uc.Check(ctx, "admin", "10.1.2.3")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, "10.1.2.3", decision.Rule)
+
+	decision, err = uc.Check(ctx, "public", "192.168.1.1")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	require.Error(t, uc.AddRule(ctx, "admin", "maybe", "10.0.0.1"))
+	require.Error(t, uc.AddRule(ctx, "admin", ipfilter.ListAllow, "not-an-ip"))
+}
+
+func TestIPFilterUC_Check_RedisDown(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{IPFilter: config.IPFilter{
+		Groups: map[string]config.IPFilterGroup{
+			"admin": {Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.9.9.9"}},
+		},
+	}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	uc := NewIPFilterUseCase(cfg, downRepo{}, clock.NewFrozen(time.Now()), appLogger)
+	ctx := context.Background()
+
+	// The config file rules are applied without redis
+	decision, err := uc.Check(ctx, "admin", "10.1.2.3")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	decision, err = uc.Check(ctx, "admin", "192.168.1.1")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+
+	decision, err = uc.Check(ctx, "admin", "10.9.9.9")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+
+	_, err = uc.GetRules(ctx, "admin")
+	require.Error(t, err)
+}
@@ -0,0 +1,12 @@
+package jobs
+
+import "github.com/labstack/echo/v4"
+
+// Background jobs admin HTTP Handlers interface
+type Handlers interface {
+	List() echo.HandlerFunc
+	Retry() echo.HandlerFunc
+	Cancel() echo.HandlerFunc
+	PurgeDead() echo.HandlerFunc
+	Stats() echo.HandlerFunc
+}
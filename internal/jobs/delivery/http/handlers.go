@@ -0,0 +1,161 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/jobs"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Jobs handlers
+type jobsHandlers struct {
+	cfg    *config.Config
+	jobsUC jobs.UseCase
+	logger logger.Logger
+}
+
+// NewJobsHandlers Jobs handlers constructor
+func NewJobsHandlers(cfg *config.Config, jobsUC jobs.UseCase, log logger.Logger) jobs.Handlers {
+	return &jobsHandlers{cfg: cfg, jobsUC: jobsUC, logger: log}
+}
+
+// List godoc
+// @Summary List background jobs
+// @Description Page of pending, processing, delayed or dead jobs, newest first and delayed ones by due time, admin only
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param state query string false "pending, processing, delayed or dead" default(pending)
+// @Param page query int false "page number" Format(page)
+// @Param size query int false "number of elements per page" Format(size)
+// @Success 200 {object} models.JobsList
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/jobs [get]
+func (h *jobsHandlers) List() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "jobsHandlers.List")
+		defer span.Finish()
+
+		paginationQuery, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			return err
+		}
+
+		list, err := h.jobsUC.List(ctx, c.QueryParam("state"), paginationQuery)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// Retry godoc
+// @Summary Retry dead job
+// @Description Move a dead job back to the pending queue with a fresh attempts budget, admin only
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "job id"
+// @Success 200 {object} jobqueue.Job
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/jobs/{id}/retry [post]
+func (h *jobsHandlers) Retry() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "jobsHandlers.Retry")
+		defer span.Finish()
+
+		job, err := h.jobsUC.Retry(ctx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, job)
+	}
+}
+
+// Cancel godoc
+// @Summary Cancel job
+// @Description Remove a pending job or cancel a running one, admin only
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "job id"
+// @Success 200 {object} models.JobCancelResult
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/jobs/{id}/cancel [post]
+func (h *jobsHandlers) Cancel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "jobsHandlers.Cancel")
+		defer span.Finish()
+
+		result, err := h.jobsUC.Cancel(ctx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// PurgeDead godoc
+// @Summary Purge dead jobs
+// @Description Drop every job of the dead letter queue, admin only
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.JobPurgeResult
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/jobs/dead [delete]
+func (h *jobsHandlers) PurgeDead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "jobsHandlers.PurgeDead")
+		defer span.Finish()
+
+		result, err := h.jobsUC.PurgeDead(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// Stats godoc
+// @Summary Job queue stats
+// @Description Queue lengths, outcome totals and per minute throughput of the last minutes, admin only
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param minutes query int false "window in minutes" default(60)
+// @Success 200 {object} jobqueue.Stats
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/jobs/stats [get]
+func (h *jobsHandlers) Stats() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "jobsHandlers.Stats")
+		defer span.Finish()
+
+		var minutes int
+		if raw := c.QueryParam("minutes"); raw != "" {
+			var err error
+			if minutes, err = strconv.Atoi(raw); err != nil {
+				return httpErrors.NewBadRequestError("minutes must be a number")
+			}
+		}
+
+		stats, err := h.jobsUC.Stats(ctx, minutes)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, stats)
+	}
+}
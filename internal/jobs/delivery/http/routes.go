@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/jobs"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map background jobs routes, group is already restricted to administrators
+func MapJobsRoutes(adminGroup *echo.Group, h jobs.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.GET("/jobs", h.List())
+	adminGroup.GET("/jobs/stats", h.Stats())
+	adminGroup.DELETE("/jobs/dead", h.PurgeDead(), mw.CSRF)
+	adminGroup.POST("/jobs/:id/retry", h.Retry(), mw.CSRF)
+	adminGroup.POST("/jobs/:id/cancel", h.Cancel(), mw.CSRF)
+}
@@ -0,0 +1,19 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package jobs
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Background jobs admin use case
+type UseCase interface {
+	List(ctx context.Context, state string, pq *utils.PaginationQuery) (*models.JobsList, error)
+	Retry(ctx context.Context, id string) (*jobqueue.Job, error)
+	Cancel(ctx context.Context, id string) (*models.JobCancelResult, error)
+	PurgeDead(ctx context.Context) (*models.JobPurgeResult, error)
+	Stats(ctx context.Context, minutes int) (*jobqueue.Stats, error)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/jobs"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultStatsMinutes = 60
+	maxStatsMinutes     = 24 * 60
+)
+
+// Jobs UseCase
+type jobsUC struct {
+	queue  *jobqueue.Queue
+	logger logger.Logger
+}
+
+// Jobs UseCase constructor
+func NewJobsUseCase(queue *jobqueue.Queue, log logger.Logger) jobs.UseCase {
+	return &jobsUC{queue: queue, logger: log}
+}
+
+// Page of jobs in given state
+func (u *jobsUC) List(ctx context.Context, state string, pq *utils.PaginationQuery) (*models.JobsList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "jobsUC.List")
+	defer span.Finish()
+
+	if state == "" {
+		state = jobqueue.StatePending
+	}
+
+	list, total, err := u.queue.List(ctx, state, pq.GetOffset(), pq.GetLimit())
+	if err != nil {
+		return nil, mapQueueError(err)
+	}
+
+	totalCount := int(total)
+	return &models.JobsList{
+		State:      state,
+		TotalCount: totalCount,
+		TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		Jobs:       list,
+	}, nil
+}
+
+// Requeue a dead job
+func (u *jobsUC) Retry(ctx context.Context, id string) (*jobqueue.Job, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "jobsUC.Retry")
+	defer span.Finish()
+
+	job, err := u.queue.Retry(ctx, id)
+	if err != nil {
+		return nil, mapQueueError(err)
+	}
+	return job, nil
+}
+
+// Cancel a pending or running job
+func (u *jobsUC) Cancel(ctx context.Context, id string) (*models.JobCancelResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "jobsUC.Cancel")
+	defer span.Finish()
+
+	state, err := u.queue.Cancel(ctx, id)
+	if err != nil {
+		return nil, mapQueueError(err)
+	}
+	return &models.JobCancelResult{ID: id, State: state}, nil
+}
+
+// Drop every dead job
+func (u *jobsUC) PurgeDead(ctx context.Context) (*models.JobPurgeResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "jobsUC.PurgeDead")
+	defer span.Finish()
+
+	purged, err := u.queue.PurgeDead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Infof("jobsUC.PurgeDead queue: %s, purged: %d", u.queue.Name(), purged)
+	return &models.JobPurgeResult{Purged: purged}, nil
+}
+
+// Queue lengths and throughput of the last minutes
+func (u *jobsUC) Stats(ctx context.Context, minutes int) (*jobqueue.Stats, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "jobsUC.Stats")
+	defer span.Finish()
+
+	if minutes <= 0 {
+		minutes = defaultStatsMinutes
+	}
+	if minutes > maxStatsMinutes {
+		return nil, httpErrors.NewBadRequestError(errors.Errorf("minutes must not exceed %d", maxStatsMinutes).Error())
+	}
+	return u.queue.Stats(ctx, minutes)
+}
+
+func mapQueueError(err error) error {
+	switch {
+	case errors.Is(err, jobqueue.ErrJobNotFound):
+		return httpErrors.NewNotFoundError(err.Error())
+	case errors.Is(err, jobqueue.ErrUnknownState):
+		return httpErrors.NewBadRequestError(err.Error())
+	default:
+		return err
+	}
+}
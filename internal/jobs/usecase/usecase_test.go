@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestJobsUC_ListRetryCancel(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := jobqueue.NewQueue(client, "jobs")
+	uc := NewJobsUseCase(queue, testutil.Logger(&config.Config{}))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.Enqueue(ctx, "scan", map[string]int{"file_id": i})
+		require.NoError(t, err)
+	}
+
+	// Pending jobs are listed when no state is asked for
+	list, err := uc.List(ctx, "", &utils.PaginationQuery{Page: 1, Size: 2})
+	require.NoError(t, err)
+	require.Equal(t, jobqueue.StatePending, list.State)
+	require.Equal(t, 3, list.TotalCount)
+	require.Equal(t, 2, list.TotalPages)
+	require.Len(t, list.Jobs, 2)
+
+	_, err = uc.List(ctx, "running", &utils.PaginationQuery{Page: 1, Size: 2})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	cancelled, err := uc.Cancel(ctx, list.Jobs[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, jobqueue.StatePending, cancelled.State)
+	_, err = uc.Cancel(ctx, list.Jobs[0].ID)
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+
+	// Only dead jobs are retried
+	_, err = uc.Retry(ctx, list.Jobs[1].ID)
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+
+	purged, err := uc.PurgeDead(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged.Purged)
+
+	stats, err := uc.Stats(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Pending)
+	require.Len(t, stats.PerMinute, defaultStatsMinutes)
+	_, err = uc.Stats(ctx, maxStatsMinutes+1)
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+}
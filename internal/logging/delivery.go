@@ -0,0 +1,10 @@
+package logging
+
+import "github.com/labstack/echo/v4"
+
+// Logging HTTP Handlers interface
+type Handlers interface {
+	List() echo.HandlerFunc
+	SetLevel() echo.HandlerFunc
+	ResetLevel() echo.HandlerFunc
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/logging"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Logging handlers
+type loggingHandlers struct {
+	cfg       *config.Config
+	loggingUC logging.UseCase
+	logger    logger.Logger
+}
+
+// NewLoggingHandlers Logging handlers constructor
+func NewLoggingHandlers(cfg *config.Config, loggingUC logging.UseCase, log logger.Logger) logging.Handlers {
+	return &loggingHandlers{cfg: cfg, loggingUC: loggingUC, logger: log}
+}
+
+// List godoc
+// @Summary List log levels
+// @Description Default level and the level every named module logs at, admin only
+// @Tags Logging
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.LogLevels
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/logging [get]
+func (h *loggingHandlers) List() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "loggingHandlers.List")
+		defer span.Finish()
+
+		levels, err := h.loggingUC.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, levels)
+	}
+}
+
+// SetLevel godoc
+// @Summary Set module log level
+// @Description Override the level of a module and its submodules on every instance without a restart, admin only
+// @Tags Logging
+// @Accept json
+// @Produce json
+// @Param module path string true "module, e.g. internal/session"
+// @Param level body dto.LogLevelRequest true "level"
+// @Success 200 {object} models.LogLevels
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/logging/{module} [put]
+func (h *loggingHandlers) SetLevel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "loggingHandlers.SetLevel")
+		defer span.Finish()
+
+		req := &dto.LogLevelRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		levels, err := h.loggingUC.SetLevel(ctx, c.Param("*"), req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, levels)
+	}
+}
+
+// ResetLevel godoc
+// @Summary Reset module log level
+// @Description Drop the level override of a module on every instance, admin only
+// @Tags Logging
+// @Accept json
+// @Produce json
+// @Param module path string true "module, e.g. internal/session"
+// @Success 200 {object} models.LogLevels
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/logging/{module} [delete]
+func (h *loggingHandlers) ResetLevel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "loggingHandlers.ResetLevel")
+		defer span.Finish()
+
+		levels, err := h.loggingUC.ResetLevel(ctx, c.Param("*"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, levels)
+	}
+}
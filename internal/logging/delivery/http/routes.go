@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/logging"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+)
+
+// Map logging routes on the admin group, the module is the rest of the path, e.g. /logging/internal/session
+func MapLoggingRoutes(adminGroup *echo.Group, h logging.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.GET("/logging", h.List())
+	adminGroup.PUT("/logging/*", h.SetLevel(), mw.CSRF)
+	adminGroup.DELETE("/logging/*", h.ResetLevel(), mw.CSRF)
+}
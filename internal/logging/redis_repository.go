@@ -0,0 +1,14 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package logging
+
+import "context"
+
+// Log level overrides shared by every instance, changes are announced to all of them
+type RedisRepository interface {
+	GetLevels(ctx context.Context) (map[string]string, error)
+	SetLevel(ctx context.Context, module, level string) error
+	// Returns false when module had no override
+	DeleteLevel(ctx context.Context, module string) (bool, error)
+	// Blocks until ctx is done, handle is called with the module of every published change
+	ListenChanges(ctx context.Context, handle func(ctx context.Context, module string) error)
+}
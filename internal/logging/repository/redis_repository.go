@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/logging"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Logging redis repository
+type loggingRedisRepo struct {
+	redisClient *redis.Client
+	key         string
+	channel     string
+	logger      logger.Logger
+}
+
+// Logging redis repository constructor
+func NewLoggingRedisRepo(redisClient *redis.Client, key, channel string, logger logger.Logger) logging.RedisRepository {
+	return &loggingRedisRepo{redisClient: redisClient, key: key, channel: channel, logger: logger}
+}
+
+// Get level overrides by module
+func (r *loggingRedisRepo) GetLevels(ctx context.Context) (map[string]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingRedisRepo.GetLevels")
+	defer span.Finish()
+
+	levels, err := r.redisClient.HGetAll(ctx, r.key).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "loggingRedisRepo.GetLevels.HGetAll")
+	}
+	return levels, nil
+}
+
+// Store level override and announce it in the same transaction
+func (r *loggingRedisRepo) SetLevel(ctx context.Context, module, level string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingRedisRepo.SetLevel")
+	defer span.Finish()
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.HSet(ctx, r.key, module, level)
+	pipe.Publish(ctx, r.channel, module)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "loggingRedisRepo.SetLevel.pipe.Exec")
+	}
+	return nil
+}
+
+// Remove level override and announce it in the same transaction
+func (r *loggingRedisRepo) DeleteLevel(ctx context.Context, module string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingRedisRepo.DeleteLevel")
+	defer span.Finish()
+
+	pipe := r.redisClient.TxPipeline()
+	deleted := pipe.HDel(ctx, r.key, module)
+	pipe.Publish(ctx, r.channel, module)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errors.Wrap(err, "loggingRedisRepo.DeleteLevel.pipe.Exec")
+	}
+	return deleted.Val() > 0, nil
+}
+
+// Listen for published changes until ctx is done
+func (r *loggingRedisRepo) ListenChanges(ctx context.Context, handle func(ctx context.Context, module string) error) {
+	pubsub := r.redisClient.Subscribe(ctx, r.channel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := handle(ctx, msg.Payload); err != nil {
+				r.logger.Errorf("loggingRedisRepo.ListenChanges module: %s, error: %v", msg.Payload, err)
+			}
+		}
+	}
+}
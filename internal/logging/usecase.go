@@ -0,0 +1,20 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package logging
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Logging use case
+type UseCase interface {
+	List(ctx context.Context) (*models.LogLevels, error)
+	SetLevel(ctx context.Context, module string, req *dto.LogLevelRequest) (*models.LogLevels, error)
+	ResetLevel(ctx context.Context, module string) (*models.LogLevels, error)
+	// Apply the stored overrides to this instance
+	Load(ctx context.Context) error
+	HandleChange(ctx context.Context, module string) error
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/logging"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	auditActionLevelChanged = "logging.level_changed"
+	auditActionLevelReset   = "logging.level_reset"
+)
+
+// Module paths as passed to logger.Named, e.g. internal/session or pkg
+var modulePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}(/[a-z0-9_-]{1,64}){0,7}$`)
+
+// Logging UseCase
+type loggingUC struct {
+	redisRepo logging.RedisRepository
+	levels    *logger.Levels
+	auditUC   audit.UseCase
+	logger    logger.Logger
+}
+
+// Logging UseCase constructor, levels are the ones of the logger every module logger was named from, auditUC may be nil
+func NewLoggingUseCase(redisRepo logging.RedisRepository, levels *logger.Levels, auditUC audit.UseCase, log logger.Logger) logging.UseCase {
+	return &loggingUC{redisRepo: redisRepo, levels: levels, auditUC: auditUC, logger: log}
+}
+
+// List named modules and overrides with the level they log at
+func (u *loggingUC) List(ctx context.Context) (*models.LogLevels, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "loggingUC.List")
+	defer span.Finish()
+
+	return &models.LogLevels{Default: u.levels.Default(), Modules: u.levels.List()}, nil
+}
+
+// Override the level of module and its submodules on every instance
+func (u *loggingUC) SetLevel(ctx context.Context, module string, req *dto.LogLevelRequest) (*models.LogLevels, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingUC.SetLevel")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if !modulePattern.MatchString(module) {
+		return nil, httpErrors.NewBadRequestError("invalid module")
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	if err := u.redisRepo.SetLevel(ctx, module, req.Level); err != nil {
+		return nil, err
+	}
+	if err := u.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	u.record(ctx, auditActionLevelChanged, module, user.User.ID, map[string]string{"level": req.Level})
+	return u.List(ctx)
+}
+
+// Drop the override of module, it logs at the level of its parent again
+func (u *loggingUC) ResetLevel(ctx context.Context, module string) (*models.LogLevels, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingUC.ResetLevel")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+
+	deleted, err := u.redisRepo.DeleteLevel(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "module has no level override", module)
+	}
+	if err := u.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	u.record(ctx, auditActionLevelReset, module, user.User.ID, nil)
+	return u.List(ctx)
+}
+
+// Apply the stored overrides
+func (u *loggingUC) Load(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingUC.Load")
+	defer span.Finish()
+
+	overrides, err := u.redisRepo.GetLevels(ctx)
+	if err != nil {
+		return err
+	}
+	u.levels.Replace(overrides)
+	return nil
+}
+
+// Apply overrides after an instance published a change
+func (u *loggingUC) HandleChange(ctx context.Context, module string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "loggingUC.HandleChange")
+	defer span.Finish()
+
+	if err := u.Load(ctx); err != nil {
+		return err
+	}
+	u.logger.Infof("loggingUC.HandleChange: level of %s changed", module)
+	return nil
+}
+
+func (u *loggingUC) record(ctx context.Context, action, module string, actorID int, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	if err := u.auditUC.Record(ctx, action, &models.AuditEvent{
+		ActorID:   &actorID,
+		RequestID: requestID,
+		Resource:  "log_level:" + module,
+	}, metadata); err != nil {
+		u.logger.Errorf("loggingUC.record.Record action: %s, error: %v", action, err)
+	}
+}
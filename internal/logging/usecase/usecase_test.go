@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/logging/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+func TestLoggingUC_SetLevel(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	appLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "info"}})
+	appLogger.InitLogger()
+	sessionLogger := appLogger.Named("internal/session")
+	redisRepo := mock.NewMockRedisRepository(ctrl)
+	uc := NewLoggingUseCase(redisRepo, appLogger.Levels(), nil, appLogger)
+	ctx := testutil.AsAdmin()
+
+	redisRepo.EXPECT().SetLevel(gomock.Any(), "internal/session", "debug").Return(nil)
+	redisRepo.EXPECT().GetLevels(gomock.Any()).Return(map[string]string{"internal/session": "debug"}, nil)
+	levels, err := uc.SetLevel(ctx, "internal/session", &dto.LogLevelRequest{Level: "debug"})
+	require.NoError(t, err)
+	require.Equal(t, "info", levels.Default)
+	require.Len(t, levels.Modules, 1)
+	require.Equal(t, "debug", levels.Modules[0].Level)
+	require.True(t, levels.Modules[0].Overridden)
+	sessionLogger.Debugf("visible at debug")
+
+	_, err = uc.SetLevel(ctx, "internal/session", &dto.LogLevelRequest{Level: "verbose"})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.SetLevel(ctx, "../etc", &dto.LogLevelRequest{Level: "debug"})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.SetLevel(context.Background(), "internal/session", &dto.LogLevelRequest{Level: "debug"})
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestLoggingUC_ResetAndChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	appLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "warn"}})
+	appLogger.InitLogger()
+	redisRepo := mock.NewMockRedisRepository(ctrl)
+	uc := NewLoggingUseCase(redisRepo, appLogger.Levels(), nil, appLogger)
+	ctx := testutil.AsAdmin()
+
+	// Change published by another instance
+	redisRepo.EXPECT().GetLevels(gomock.Any()).Return(map[string]string{"internal/auth": "debug"}, nil)
+	require.NoError(t, uc.HandleChange(ctx, "internal/auth"))
+	levels, err := uc.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "debug", levels.Modules[0].Level)
+
+	redisRepo.EXPECT().DeleteLevel(gomock.Any(), "internal/auth").Return(true, nil)
+	redisRepo.EXPECT().GetLevels(gomock.Any()).Return(map[string]string{}, nil)
+	levels, err = uc.ResetLevel(ctx, "internal/auth")
+	require.NoError(t, err)
+	require.Empty(t, levels.Modules)
+
+	redisRepo.EXPECT().DeleteLevel(gomock.Any(), "internal/auth").Return(false, nil)
+	_, err = uc.ResetLevel(ctx, "internal/auth")
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/accesslog"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Access log middleware, writes every request to the access log. Callers are logged by user id, usernames are
+// personal data the access log is kept longer than
+func (mw *MiddlewareManager) AccessLogMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		// The error handler writes the status of a failed request, the entry needs it
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		req := c.Request()
+		res := c.Response()
+		entry := &accesslog.Entry{
+			Time:      start,
+			RemoteIP:  c.RealIP(),
+			Method:    req.Method,
+			URI:       req.RequestURI,
+			Proto:     req.Proto,
+			Status:    res.Status,
+			Bytes:     res.Size,
+			Referer:   req.Referer(),
+			UserAgent: req.UserAgent(),
+			Duration:  time.Since(start),
+		}
+		if user, ok := requestctx.User.Get(c); ok && user != nil {
+			entry.User = strconv.Itoa(user.User.ID)
+		}
+		if err := mw.accessLog.Write(entry); err != nil {
+			mw.logger.Errorf("AccessLog RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/accesslog"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	appLogger := logger.NewApiLogger(&config.Config{})
+	accessLog, err := accesslog.New(accesslog.Options{Format: config.AccessLogCombined, Dir: dir}, appLogger)
+	require.NoError(t, err)
+	mw := &MiddlewareManager{logger: appLogger, accessLog: accessLog}
+
+	e := echo.New()
+	e.Use(mw.AccessLogMiddleware)
+	e.GET("/me", func(c echo.Context) error {
+		requestctx.User.Set(c, &models.UserWithRole{User: models.User{ID: 42, Username: "ann"}})
+		return c.String(http.StatusOK, "ann")
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/me", "/missing?q=1"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", "test")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+	}
+	require.NoError(t, accessLog.Close(context.Background()))
+
+	rotated, err := filepath.Glob(filepath.Join(dir, "access-*.log"))
+	require.NoError(t, err)
+	require.Len(t, rotated, 1)
+	content, err := os.ReadFile(rotated[0])
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	require.Contains(t, lines[0], ` - 42 [`)
+	require.Contains(t, lines[0], `"GET /me HTTP/1.1" 200 3 "-" "test"`)
+	require.NotContains(t, lines[0], "ann")
+	// Failed requests are logged with the status the error handler wrote
+	require.Contains(t, lines[1], ` - - [`)
+	require.Contains(t, lines[1], `"GET /missing?q=1 HTTP/1.1" 404 `)
+}
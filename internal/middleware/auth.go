@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,37 +15,106 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
-// Auth sessions middleware using redis
+// Routes open to users a password rotation campaign flagged, enough to read themselves, fetch a CSRF token,
+// step up and change their password
+var passwordRotationRoutes = map[string]bool{
+	http.MethodGet + " /api/v1/auth/me":                        true,
+	http.MethodGet + " /api/v1/auth/token":                     true,
+	http.MethodPost + " /api/v1/auth/reauthenticate":           true,
+	http.MethodPut + " " + passwordrotation.ChangePasswordPath: true,
+}
+
+// Auth sessions middleware using redis. With config.Bearer enabled an Authorization: Bearer header holding a JWT,
+// a token of a trusted issuer or an API key is taken instead of the session cookie, so browsers and server clients
+// share endpoints. Bearer callers need no CSRF token
 func (mw *MiddlewareManager) AuthSessionMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.sessionMiddleware(next, false)
+}
+
+// Sessions middleware that also admits guest sessions, guest id is put into the request context
+func (mw *MiddlewareManager) SessionOrGuestMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.sessionMiddleware(next, true)
+}
+
+func (mw *MiddlewareManager) sessionMiddleware(next echo.HandlerFunc, allowGuest bool) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		if mw.cfg.Bearer.Enabled {
+			if token, ok := bearerToken(c); ok {
+				return mw.bearerAuth(c, next, token)
+			}
+		}
+
+		var sid string
+		var sess *models.Session
 		cookie, err := c.Cookie(mw.cfg.Session.Name)
 		if err != nil {
 			mw.logger.Errorf("AuthSessionMiddleware RequestID: %s, Error: %s",
 				utils.GetRequestID(c),
 				err.Error(),
 			)
+		} else {
+			sid = cookie.Value
+			sess, err = mw.sessUC.GetSessionByID(c.Request().Context(), sid)
+			if err != nil {
+				mw.logger.Errorf("GetSessionByID RequestID: %s, CookieValue: %s, Error: %s",
+					utils.GetRequestID(c),
+					sid,
+					err.Error(),
+				)
+			}
+		}
+
+		// A session that is gone, not revoked, may be started again by a remember-me cookie of the device
+		if err != nil && (err == http.ErrNoCookie || session.ErrorKind(err) == session.KindNotFound || session.ErrorKind(err) == session.KindExpired) {
+			rememberedSID, remembered, rememberErr := mw.rememberedSession(c)
+			if rememberErr != nil {
+				mw.logger.Errorf("rememberedSession RequestID: %s, Error: %s", utils.GetRequestID(c), rememberErr.Error())
+				return c.JSON(httpErrors.ErrorResponse(rememberErr))
+			}
+			if remembered != nil {
+				sid, sess, err = rememberedSID, remembered, nil
+			}
+		}
+
+		if err != nil {
 			if err == http.ErrNoCookie {
 				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
 			}
+			// Typed session errors keep their own status, anything else is a plain 401
+			if session.ErrorKind(err) != "" {
+				return c.JSON(httpErrors.ErrorResponse(err))
+			}
 			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
 		}
 
-		sid := cookie.Value
-
-		sess, err := mw.sessUC.GetSessionByID(c.Request().Context(), cookie.Value)
-		if err != nil {
-			mw.logger.Errorf("GetSessionByID RequestID: %s, CookieValue: %s, Error: %s",
-				utils.GetRequestID(c),
-				cookie.Value,
-				err.Error(),
-			)
+		if !mw.sessionTenantMatches(c, sess) {
 			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
 		}
 
+		if sess.Guest {
+			if !allowGuest {
+				mw.logger.Errorf("AuthSessionMiddleware RequestID: %s, Error: guest session not allowed", utils.GetRequestID(c))
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			}
+
+			requestctx.SessionID.Set(c, sid)
+			requestctx.Session.Set(c, sess)
+			requestctx.GuestID.Set(c, sess.GuestID)
+			return next(c)
+		}
+
+		if err := mw.sessUC.CheckHijack(c.Request().Context(), sess, utils.GetIPAddress(c), c.Request().UserAgent()); err != nil {
+			mw.logger.Warnf("CheckHijack RequestID: %s, Error: %s", utils.GetRequestID(c), err.Error())
+		}
+
 		user, err := mw.authUC.GetByID(c.Request().Context(), sess.UserID)
 		if err != nil {
 			mw.logger.Errorf("GetByID RequestID: %s, Error: %s",
@@ -55,27 +124,102 @@ func (mw *MiddlewareManager) AuthSessionMiddleware(next echo.HandlerFunc) echo.H
 			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
 		}
 
-		c.Set("sid", sid)
-		c.Set("uid", sess.SessionID)
-		c.Set("user", user)
-
-		fmt.Println("UUUDUUD: ", user)
+		if err := mw.checkPasswordRotation(c, user.User.ID); err != nil {
+			mw.logger.Errorf("checkPasswordRotation RequestID: %s, UserID: %d, Error: %s", utils.GetRequestID(c), user.User.ID, err.Error())
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
 
-		ctx := context.WithValue(c.Request().Context(), utils.UserCtxKey{}, user)
-		c.SetRequest(c.Request().WithContext(ctx))
+		requestctx.SessionID.Set(c, sid)
+		requestctx.Session.Set(c, sess)
+		requestctx.Tenant.Set(c, sess.TenantID)
+		setPrincipal(c, &models.Principal{User: user, Source: models.PrincipalSourceSession, SessionID: sid}, sess.TenantID)
 
 		mw.logger.Info(
 			"SessionMiddleware, RequestID: %s,  IP: %s, UserID: %d, CookieSessionID: %s",
 			utils.GetRequestID(c),
 			utils.GetIPAddress(c),
 			user.User.ID,
-			cookie.Value,
+			sid,
 		)
 
 		return next(c)
 	}
 }
 
+// In database per tenant mode a session only works for the tenant it was started for
+func (mw *MiddlewareManager) sessionTenantMatches(c echo.Context, sess *models.Session) bool {
+	if mw.tenants == nil {
+		return true
+	}
+	if routed, _ := requestctx.Tenant.Get(c); sess.TenantID != routed {
+		mw.logger.Errorf("AuthSessionMiddleware RequestID: %s, Error: session of tenant %q used for %q", utils.GetRequestID(c), sess.TenantID, routed)
+		return false
+	}
+	return true
+}
+
+// Users flagged by a password rotation campaign get ErrRequired outside of passwordRotationRoutes
+func (mw *MiddlewareManager) checkPasswordRotation(c echo.Context, userID int) error {
+	if !mw.cfg.Rotation.Enabled || mw.rotationUC == nil {
+		return nil
+	}
+	if passwordRotationRoutes[c.Request().Method+" "+c.Request().URL.Path] {
+		return nil
+	}
+	required, err := mw.rotationUC.IsRequired(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return passwordrotation.ErrRequired
+	}
+	return nil
+}
+
+// Start a session from the remember-me cookie of the request and set both cookies, nil without a usable cookie.
+// Errors are for replayed cookies and failing stores, the cookie is dropped when it can't be used again
+func (mw *MiddlewareManager) rememberedSession(c echo.Context) (string, *models.Session, error) {
+	if !mw.cfg.Remember.Enabled || mw.rememberUC == nil {
+		return "", nil, nil
+	}
+	cookie, err := c.Cookie(mw.cfg.Remember.CookieName)
+	if err != nil {
+		return "", nil, nil
+	}
+
+	ctx := c.Request().Context()
+	userID, value, err := mw.rememberUC.Redeem(ctx, cookie.Value, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		if errors.Is(err, remember.ErrNotFound) || errors.Is(err, remember.ErrTheft) {
+			utils.DeleteSessionCookie(c, mw.cfg.Remember.CookieName)
+		}
+		if errors.Is(err, remember.ErrNotFound) {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+
+	sid, err := mw.sessUC.CreateSession(ctx, &models.Session{
+		UserID:    userID,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}, mw.cfg.Session.Expire)
+	if err != nil {
+		return "", nil, err
+	}
+	sess, err := mw.sessUC.GetSessionByID(ctx, sid)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c.SetCookie(utils.CreateSessionCookie(mw.cfg, sid))
+	if value != "" {
+		c.SetCookie(utils.CreateRememberCookie(mw.cfg, value))
+	}
+	mw.logger.Infof("rememberedSession RequestID: %s, UserID: %d, session started from remember-me cookie", utils.GetRequestID(c), userID)
+	return sid, sess, nil
+}
+
 // JWT way of auth using cookie or Authorization header
 func (mw *MiddlewareManager) AuthJWTMiddleware(authUC auth.UseCase, cfg *config.Config) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -119,7 +263,7 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(authUC auth.UseCase, cfg *config.
 // Admin role
 func (mw *MiddlewareManager) AdminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		user, ok := c.Get("user").(*models.UserWithRole)
+		user, ok := requestctx.User.Get(c)
 		if !ok || *&user.Role.Name != "administrator" {
 			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
 		}
@@ -138,7 +282,7 @@ func (mw *MiddlewareManager) OwnerOrAdminMiddleware() echo.MiddlewareFunc {
 				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
 			}
 
-			user, ok := c.Get("user").(*models.UserWithRole)
+			user, ok := requestctx.User.Get(c)
 			if !ok {
 				mw.logger.Errorf("Error c.Get(user) RequestID: %s, ERROR: %s,", utils.GetRequestID(c), "invalid user ctx")
 				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
@@ -166,7 +310,7 @@ func (mw *MiddlewareManager) OwnerOrAdminMiddleware() echo.MiddlewareFunc {
 func (mw *MiddlewareManager) RoleBasedAuthMiddleware(roles []string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			user, ok := c.Get("user").(*models.UserWithRole)
+			user, ok := requestctx.User.Get(c)
 
 			if !ok {
 				mw.logger.Errorf("Error c.Get(user) RequestID: %s, UserID: %d, ERROR: %s,",
@@ -198,6 +342,9 @@ func (mw *MiddlewareManager) validateJWTToken(tokenString string, authUC auth.Us
 	if tokenString == "" {
 		return httpErrors.InvalidJWTToken
 	}
+	if mw.issuers.Trusts(tokenString) {
+		return mw.validateIssuerToken(tokenString, authUC, c)
+	}
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -215,6 +362,10 @@ func (mw *MiddlewareManager) validateJWTToken(tokenString string, authUC auth.Us
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// Scoped tokens only open the /scoped routes
+		if typ, _ := claims["typ"].(string); typ == utils.ScopedTokenType {
+			return httpErrors.InvalidJWTToken
+		}
 		userIDStr, ok := claims["id"].(string)
 		if !ok {
 			return httpErrors.InvalidJWTClaims
@@ -230,11 +381,7 @@ func (mw *MiddlewareManager) validateJWTToken(tokenString string, authUC auth.Us
 			return err
 		}
 
-		c.Set("user", u)
-
-		ctx := context.WithValue(c.Request().Context(), utils.UserCtxKey{}, u)
-		// req := c.Request().WithContext(ctx)
-		c.SetRequest(c.Request().WithContext(ctx))
+		setPrincipal(c, &models.Principal{User: u, Source: models.PrincipalSourceJWT}, "")
 	}
 	return nil
 }
@@ -267,8 +414,8 @@ func (mw *MiddlewareManager) CheckAuth(next echo.HandlerFunc) echo.HandlerFunc {
 			return ctx.JSON(http.StatusUnauthorized, httpErrors.NoCookie)
 		}
 
-		ctx.Set("uid", session.SessionID)
-		ctx.Set("sid", sid)
+		requestctx.SessionID.Set(ctx, sid)
+		requestctx.Session.Set(ctx, session)
 		return next(ctx)
 	}
 }
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	accessTokensMock "github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens/mock"
+	authMock "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	rotationMock "github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/apikey"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestCheckPasswordRotation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rotationUC := rotationMock.NewMockUseCase(ctrl)
+	rotationUC.EXPECT().IsRequired(gomock.Any(), 7).Return(true, nil)
+	mw := &MiddlewareManager{cfg: &config.Config{Rotation: config.PasswordRotation{Enabled: true}}, rotationUC: rotationUC}
+
+	check := func(method, path string) error {
+		c := echo.New().NewContext(httptest.NewRequest(method, path, nil), httptest.NewRecorder())
+		return mw.checkPasswordRotation(c, 7)
+	}
+	require.True(t, passwordrotation.IsRequired(check(http.MethodGet, "/api/v1/files")))
+	// Enough to change the password is left open without asking
+	require.NoError(t, check(http.MethodGet, "/api/v1/auth/me"))
+	require.NoError(t, check(http.MethodGet, "/api/v1/auth/token"))
+	require.NoError(t, check(http.MethodPost, "/api/v1/auth/reauthenticate"))
+	require.NoError(t, check(http.MethodPut, passwordrotation.ChangePasswordPath))
+
+	mw.cfg.Rotation.Enabled = false
+	require.NoError(t, check(http.MethodGet, "/api/v1/files"))
+}
+
+func TestSessionMiddleware_Bearer(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	authUC := authMock.NewMockUseCase(ctrl)
+	cfg := &config.Config{Bearer: config.BearerAuth{Enabled: true}, Server: config.ServerConfig{JwtSecretKey: "secret", CSRF: true}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	apiKeys := &apikey.Keyring{}
+	apiKeys.Add(apikey.Key{Name: "billing", ServiceRole: "billing"}, "key-1")
+	mw := &MiddlewareManager{cfg: cfg, authUC: authUC, logger: appLogger, apiKeys: apiKeys}
+
+	user := &models.UserWithRole{User: models.User{ID: 3, Email: "a@b.c"}}
+	token, err := utils.GenerateJWTToken(user, cfg)
+	require.NoError(t, err)
+	authUC.EXPECT().GetByID(gomock.Any(), 3).Return(user, nil)
+
+	serve := func(authorization string) (*models.Principal, int) {
+		var principal *models.Principal
+		handler := mw.AuthSessionMiddleware(mw.CSRF(func(c echo.Context) error {
+			principal, _ = requestctx.Principal.Get(c)
+			return c.NoContent(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/me", nil)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(echo.New().NewContext(req, rec)))
+		return principal, rec.Code
+	}
+
+	// JWTs and API keys work without a session cookie or a CSRF token
+	principal, code := serve("Bearer " + token)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, models.PrincipalSourceJWT, principal.Source)
+	require.Equal(t, 3, principal.User.User.ID)
+
+	principal, code = serve("Bearer key-1")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, models.PrincipalSourceAPIKey, principal.Source)
+	require.Equal(t, "billing", principal.APIKey)
+	require.Equal(t, "billing", principal.User.Role.Name)
+	require.False(t, principal.IsUser())
+
+	_, code = serve("Bearer key-2")
+	require.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestPersonalAccessTokens(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	authUC := authMock.NewMockUseCase(ctrl)
+	tokensUC := accessTokensMock.NewMockUseCase(ctrl)
+	cfg := &config.Config{Bearer: config.BearerAuth{Enabled: true}, Server: config.ServerConfig{CSRF: true}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	mw := &MiddlewareManager{cfg: cfg, authUC: authUC, logger: appLogger, tokensUC: tokensUC}
+
+	user := &models.UserWithRole{User: models.User{ID: 3}}
+	authUC.EXPECT().GetByID(gomock.Any(), 3).Return(user, nil).AnyTimes()
+	tokensUC.EXPECT().Authenticate(gomock.Any(), "pat_api").
+		Return(&models.PersonalAccessToken{ID: 1, UserID: 3, Scopes: []string{models.ScopeAPI}}, nil).AnyTimes()
+	tokensUC.EXPECT().Authenticate(gomock.Any(), "pat_profile").
+		Return(&models.PersonalAccessToken{ID: 2, UserID: 3, Scopes: []string{models.ScopeReadProfile}}, nil).AnyTimes()
+	tokensUC.EXPECT().Authenticate(gomock.Any(), "pat_revoked").Return(nil, sql.ErrNoRows).AnyTimes()
+
+	serve := func(middleware echo.MiddlewareFunc, token string) (*models.Principal, int) {
+		var principal *models.Principal
+		handler := middleware(mw.CSRF(func(c echo.Context) error {
+			principal, _ = requestctx.Principal.Get(c)
+			return c.NoContent(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/me", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(echo.New().NewContext(req, rec)))
+		return principal, rec.Code
+	}
+	session := mw.AuthSessionMiddleware
+	profile := mw.ScopedTokenMiddleware(models.ScopeReadProfile)
+
+	// The api scope stands in for a session, other scopes only open the scoped routes
+	principal, code := serve(session, "pat_api")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, models.PrincipalSourceToken, principal.Source)
+	require.Equal(t, int64(1), principal.TokenID)
+	require.Equal(t, 3, principal.User.User.ID)
+
+	_, code = serve(session, "pat_profile")
+	require.Equal(t, http.StatusForbidden, code)
+	principal, code = serve(profile, "pat_profile")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, models.ScopeReadProfile, principal.Scope)
+	_, code = serve(profile, "pat_api")
+	require.Equal(t, http.StatusForbidden, code)
+
+	_, code = serve(session, "pat_revoked")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// Refused while disabled
+	mw.tokensUC = nil
+	_, code = serve(profile, "pat_profile")
+	require.Equal(t, http.StatusUnauthorized, code)
+}
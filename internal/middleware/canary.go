@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+// Canary middleware of route, sends requests to the variant handlers registered next to the stable handler of
+// the route by the percentages configured for it, or to the variant or stable handler the request names in the
+// canary header or cookie. Names are lowercase like config keys. Every request is timed with its variant and
+// status, stable included, so variants are compared with the handler they would replace
+func (mw *MiddlewareManager) CanaryMiddleware(route string, variants map[string]echo.HandlerFunc) echo.MiddlewareFunc {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cfg := mw.cfg.Canary
+			if !cfg.Enabled {
+				return next(c)
+			}
+
+			variant := pickCanary(c, cfg, route, names)
+			handler, ok := variants[variant]
+			if !ok {
+				variant, handler = config.CanaryStable, next
+			}
+
+			start := time.Now()
+			err := handler(c)
+			if mw.metrics != nil {
+				mw.metrics.ObserveCanary(c.Request().Context(), route, variant, canaryStatus(c, err), time.Since(start).Seconds())
+			}
+			return err
+		}
+	}
+}
+
+// Variant named by the request, else one drawn by the configured percentages of the registered variants
+func pickCanary(c echo.Context, cfg config.Canary, route string, names []string) string {
+	requested := ""
+	if cfg.Header != "" {
+		requested = c.Request().Header.Get(cfg.Header)
+	}
+	if cookie, err := c.Cookie(cfg.Cookie); requested == "" && cfg.Cookie != "" && err == nil {
+		requested = cookie.Value
+	}
+	if requested = strings.ToLower(strings.TrimSpace(requested)); requested != "" {
+		if requested == config.CanaryStable {
+			return requested
+		}
+		for _, name := range names {
+			if name == requested {
+				return name
+			}
+		}
+	}
+
+	roll := rand.Float64() * 100
+	for _, name := range names {
+		if roll -= cfg.Routes[route][name]; roll < 0 {
+			return name
+		}
+	}
+	return config.CanaryStable
+}
+
+// Status of the response, handler errors are only written by the error handler later
+func canaryStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return httpErrors.ParseErrors(err).Status()
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+type canaryMetrics struct {
+	metric.Metrics
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *canaryMetrics) ObserveCanary(ctx context.Context, route, variant string, status int, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[route+" "+variant+" "+http.StatusText(status)]++
+}
+
+func TestCanaryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	canary := config.Canary{
+		Enabled: true,
+		Header:  "X-Canary",
+		Cookie:  "canary",
+		Routes:  map[string]map[string]float64{"search": {"v2": 0, "unregistered": 100}},
+	}
+	metrics := &canaryMetrics{counts: map[string]int{}}
+	mw := &MiddlewareManager{cfg: &config.Config{Canary: canary}, metrics: metrics}
+
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrors.ErrorHandler
+	e.GET("/search", func(c echo.Context) error {
+		return c.String(http.StatusOK, "stable")
+	}, mw.CanaryMiddleware("search", map[string]echo.HandlerFunc{
+		"v2": func(c echo.Context) error {
+			return httpErrors.NewBadRequestError("v2 failed")
+		},
+	}))
+	search := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/search", nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Percentages of variants without a handler are ignored
+	require.Equal(t, "stable", search(http.Header{}).Body.String())
+
+	// Named by header or cookie
+	require.Equal(t, http.StatusBadRequest, search(http.Header{"X-Canary": {"V2"}}).Code)
+	require.Equal(t, http.StatusBadRequest, search(http.Header{"Cookie": {"canary=v2"}}).Code)
+	require.Equal(t, "stable", search(http.Header{"X-Canary": {"stable"}, "Cookie": {"canary=v2"}}).Body.String())
+	require.Equal(t, "stable", search(http.Header{"X-Canary": {"unregistered"}}).Body.String())
+
+	// Drawn by percentage
+	mw.cfg.Canary.Routes = map[string]map[string]float64{"search": {"v2": 100}}
+	require.Equal(t, http.StatusBadRequest, search(http.Header{}).Code)
+
+	require.Equal(t, map[string]int{"search stable OK": 3, "search v2 Bad Request": 3}, metrics.counts)
+
+	// Disabled routes every request to stable untimed
+	mw.cfg.Canary.Enabled = false
+	require.Equal(t, "stable", search(http.Header{"X-Canary": {"v2"}}).Body.String())
+	require.Len(t, metrics.counts, 2)
+}
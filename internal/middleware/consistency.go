@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+// Read-your-writes middleware, reads of a request carrying a consistency token only see replicas caught up to
+// it. The token of the request is answered right before the response is written, so clients pass on the
+// furthest position they have seen
+func (mw *MiddlewareManager) ConsistencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var required consistency.LSN
+		if token := c.Request().Header.Get(consistency.HeaderToken); token != "" {
+			position, err := consistency.ParseLSN(token)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("malformed "+consistency.HeaderToken+" header"))
+			}
+			required = position
+		}
+
+		ctx, state := consistency.With(c.Request().Context(), required)
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.Response().Before(func() {
+			if token, ok := state.Token(); ok {
+				c.Response().Header().Set(consistency.HeaderToken, token)
+			}
+		})
+		return next(c)
+	}
+}
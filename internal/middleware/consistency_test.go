@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
+)
+
+func TestConsistencyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mw := &MiddlewareManager{cfg: &config.Config{}}
+	e := echo.New()
+	e.Use(mw.ConsistencyMiddleware)
+	e.PUT("/profile", func(c echo.Context) error {
+		consistency.Wrote(c.Request().Context(), 0x16_B374D848)
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/profile", func(c echo.Context) error {
+		required, _ := consistency.Required(c.Request().Context())
+		return c.String(http.StatusOK, required.String())
+	})
+	serve := func(method, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/profile", nil)
+		if token != "" {
+			req.Header.Set(consistency.HeaderToken, token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "")
+	require.Equal(t, "16/B374D848", rec.Header().Get(consistency.HeaderToken))
+
+	rec = serve(http.MethodGet, "16/B374D848")
+	require.Equal(t, "16/B374D848", rec.Body.String())
+	require.Equal(t, "16/B374D848", rec.Header().Get(consistency.HeaderToken))
+
+	rec = serve(http.MethodGet, "")
+	require.Empty(t, rec.Header().Get(consistency.HeaderToken))
+
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "yesterday").Code)
+}
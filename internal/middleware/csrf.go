@@ -5,17 +5,22 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/csrf"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
-// CSRF Middleware
+// CSRF Middleware, callers authenticated by a bearer credential are not exposed to CSRF and skip it
 func (mw *MiddlewareManager) CSRF(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
 		if !mw.cfg.Server.CSRF {
 			return next(ctx)
 		}
+		if principal, ok := requestctx.Principal.Get(ctx); ok && principal.Source != models.PrincipalSourceSession {
+			return next(ctx)
+		}
 
 		token := ctx.Request().Header.Get(csrf.CSRFHeader)
 		if token == "" {
@@ -27,7 +32,7 @@ func (mw *MiddlewareManager) CSRF(next echo.HandlerFunc) echo.HandlerFunc {
 			return ctx.JSON(http.StatusForbidden, httpErrors.NewRestError(http.StatusForbidden, "Invalid CSRF Token", "no CSRF Token"))
 		}
 
-		sid, ok := ctx.Get("sid").(string)
+		sid, ok := requestctx.SessionID.Get(ctx)
 		if !csrf.ValidateToken(token, sid, mw.logger) || !ok {
 			mw.logger.Errorf("CSRF Middleware csrf.ValidateToken Token: %s, Error: %s, RequestId: %s",
 				token,
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// Client label of requests without a known client name
+const deprecationOtherClient = "other"
+
+// Deprecated fields middleware, collects the warnings of the request, sets the Deprecation and Sunset headers
+// right before the response is written and counts every deprecated field per client. Clients are named by the
+// ClientHeader request header, names outside Clients are counted as other to bound the metric
+func (mw *MiddlewareManager) DeprecationMiddleware(metrics metric.Metrics) echo.MiddlewareFunc {
+	cfg := mw.cfg.Deprecation
+	clients := make(map[string]string, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		clients[strings.ToLower(client)] = client
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, collector := deprecation.NewContext(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			c.Response().Before(func() {
+				warnings := collector.Warnings()
+				deprecation.SetHeaders(c.Response().Header(), warnings)
+				if metrics == nil || len(warnings) == 0 {
+					return
+				}
+				client, ok := clients[strings.ToLower(c.Request().Header.Get(cfg.ClientHeader))]
+				if !ok {
+					client = deprecationOtherClient
+				}
+				for _, warning := range warnings {
+					metrics.IncDeprecatedFields(client, c.Path(), warning.Field)
+				}
+			})
+			return next(c)
+		}
+	}
+}
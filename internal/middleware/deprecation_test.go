@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/binder"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+type deprecatedFieldsMetrics struct {
+	metric.Metrics
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *deprecatedFieldsMetrics) IncDeprecatedFields(client, path, field string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[client+" "+path+" "+field]++
+}
+
+type profileRequest struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name" deprecated:"use name" sunset:"2027-06-30"`
+}
+
+type profileResponse struct {
+	Name  string `json:"name"`
+	Login string `json:"login,omitempty" deprecated:"use name" sunset:"2027-03-31"`
+}
+
+func TestDeprecationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mw := &MiddlewareManager{cfg: &config.Config{Deprecation: config.Deprecation{
+		Enabled:      true,
+		ClientHeader: "X-Client-Name",
+		Clients:      []string{"web", "ios"},
+	}}}
+	metrics := &deprecatedFieldsMetrics{counts: map[string]int{}}
+
+	e := echo.New()
+	e.Binder = binder.New(0, nil)
+	e.JSONSerializer = deprecation.Serializer{}
+	e.Use(mw.DeprecationMiddleware(metrics))
+	e.POST("/profile", func(c echo.Context) error {
+		req := &profileRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+		name := req.Name
+		if name == "" {
+			name = req.FullName
+		}
+		return c.JSON(http.StatusOK, &profileResponse{Name: name, Login: name})
+	})
+	e.DELETE("/profile", func(c echo.Context) error {
+		if err := utils.ReadRequest(c, &profileRequest{}); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	serve := func(method, body, client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/profile", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Client-Name", client)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, `{"full_name":"Jane"}`, "iOS")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(deprecation.HeaderDeprecation))
+	require.Equal(t, "Wed, 31 Mar 2027 00:00:00 GMT", rec.Header().Get(deprecation.HeaderSunset))
+	require.JSONEq(t, `{
+		"name": "Jane",
+		"login": "Jane",
+		"warnings": [
+			{"field": "full_name", "message": "use name", "sunset": "2027-06-30"},
+			{"field": "login", "message": "use name", "sunset": "2027-03-31"}
+		]
+	}`, rec.Body.String())
+	require.Equal(t, map[string]int{"ios /profile full_name": 1, "ios /profile login": 1}, metrics.counts)
+
+	// Deprecated request fields are reported on bodiless responses by headers alone
+	rec = serve(http.MethodDelete, `{"full_name":"Jane"}`, "curl/8.0")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(deprecation.HeaderDeprecation))
+	require.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", rec.Header().Get(deprecation.HeaderSunset))
+	require.Equal(t, 1, metrics.counts["other /profile full_name"])
+
+	rec = serve(http.MethodPost, `{"name":""}`, "curl/8.0")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(deprecation.HeaderDeprecation))
+	require.JSONEq(t, `{"name": ""}`, rec.Body.String())
+	require.Len(t, metrics.counts, 3)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Error middleware, answers the errors handlers and route middlewares return, wrapped or not, as rest errors
+// so handlers only return them. Errors are logged with the request id, route and user and recorded on the
+// request span. Echo errors, e.g. of the router or of echo middlewares, are left to the echo error handler.
+func (mw *MiddlewareManager) ErrorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err == nil {
+			return nil
+		}
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return err
+		}
+
+		restErr := httpErrors.ParseErrors(err)
+		mw.logError(c, restErr.Status(), err)
+		if span := opentracing.SpanFromContext(c.Request().Context()); span != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(log.Int("status", restErr.Status()), log.Error(err))
+		}
+
+		// Streamed responses can not be answered anymore
+		if c.Response().Committed {
+			return nil
+		}
+		if c.Request().Method == http.MethodHead {
+			return c.NoContent(restErr.Status())
+		}
+		return c.JSON(restErr.Status(), restErr)
+	}
+}
+
+// Server errors are logged as errors, errors of the client as warnings
+func (mw *MiddlewareManager) logError(c echo.Context, status int, err error) {
+	var userID int
+	if user, ok := requestctx.User.Get(c); ok && user != nil {
+		userID = user.User.ID
+	}
+	format := "ErrorMiddleware RequestID: %s, IPAddress: %s, Method: %s, Path: %s, UserID: %d, Status: %d, Error: %s"
+	args := []interface{}{utils.GetRequestID(c), utils.GetIPAddress(c), c.Request().Method, c.Path(), userID, status, err}
+	if status >= http.StatusInternalServerError {
+		mw.logger.Errorf(format, args...)
+		return
+	}
+	mw.logger.Warnf(format, args...)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	mw := &MiddlewareManager{cfg: cfg, logger: appLogger}
+
+	e := echo.New()
+	e.Use(mw.ErrorMiddleware)
+	e.Any("/bad", func(c echo.Context) error {
+		return errors.Wrap(httpErrors.NewBadRequestError("name is required"), "handler")
+	})
+	e.GET("/missing", func(c echo.Context) error { return errors.Wrap(sql.ErrNoRows, "repo.GetByID") })
+	e.GET("/echo", func(c echo.Context) error { return echo.ErrForbidden })
+	e.GET("/streamed", func(c echo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		return errors.New("connection reset")
+	})
+
+	serve := func(method string, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		var body map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	// Wrapped rest errors keep their status
+	rec, body := serve(http.MethodPost, "/bad")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "Bad request", body["error"])
+
+	rec, _ = serve(http.MethodHead, "/bad")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, rec.Body.Bytes())
+
+	rec, body = serve(http.MethodGet, "/missing")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.EqualValues(t, http.StatusNotFound, body["status"])
+
+	// Echo errors are answered by the echo error handler
+	rec, body = serve(http.MethodGet, "/echo")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, "Forbidden", body["message"])
+
+	// Committed responses are left as they are
+	rec, _ = serve(http.MethodGet, "/streamed")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
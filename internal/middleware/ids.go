@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/idcodec"
+)
+
+// Decode id codes of path and query params, so handlers and route middleware read integer ids as before.
+// Registered only when ids are obfuscated
+func (mw *MiddlewareManager) IDParamsMiddleware(codec *idcodec.Codec) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			names, values := c.ParamNames(), c.ParamValues()
+			decodedValues := make([]string, len(values))
+			for i, value := range values {
+				decoded := value
+				if i < len(names) {
+					var err error
+					if decoded, err = codec.DecodeParam(names[i], value); err != nil {
+						return c.JSON(httpErrors.ErrorResponse(err))
+					}
+				}
+				decodedValues[i] = decoded
+			}
+			c.SetParamValues(decodedValues...)
+
+			req := c.Request()
+			if req.URL.RawQuery != "" {
+				query := req.URL.Query()
+				for name, values := range query {
+					for i, value := range values {
+						decoded, err := codec.DecodeParam(name, value)
+						if err != nil {
+							return c.JSON(httpErrors.ErrorResponse(err))
+						}
+						values[i] = decoded
+					}
+				}
+				req.URL.RawQuery = query.Encode()
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const auditActionIPBlocked = "ipfilter.blocked"
+
+// IP allow/deny list middleware for a route group, blocked requests are audited
+func (mw *MiddlewareManager) IPFilter(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !mw.cfg.IPFilter.Enabled || mw.ipFilterUC == nil || mw.ipFilterBypassed(c.Request().URL.Path) {
+				return next(c)
+			}
+
+			ctx := utils.GetRequestCtx(c)
+			ip := c.RealIP()
+			decision, err := mw.ipFilterUC.Check(ctx, group, ip)
+			if err != nil {
+				// Fail open, Check applies the config file rules by itself when redis is down
+				mw.logger.Errorf("IPFilter Middleware ipFilterUC.Check, Group: %s, Error: %s, RequestId: %s",
+					group,
+					err,
+					utils.GetRequestID(c),
+				)
+				return next(c)
+			}
+			if decision.Allowed {
+				return next(c)
+			}
+
+			mw.logger.Warnf("IPFilter Middleware blocked, Group: %s, IP: %s, Reason: %s, RequestId: %s",
+				group,
+				ip,
+				decision.Reason,
+				utils.GetRequestID(c),
+			)
+			if mw.auditUC != nil {
+				if err := mw.auditUC.Record(ctx, auditActionIPBlocked, &models.AuditEvent{
+					IPAddress: ip,
+					RequestID: utils.GetRequestID(c),
+					Resource:  c.Request().Method + " " + c.Request().URL.Path,
+				}, map[string]string{"group": group, "reason": decision.Reason, "rule": decision.Rule}); err != nil {
+					mw.logger.Errorf("IPFilter Middleware auditUC.Record, Error: %s, RequestId: %s", err, utils.GetRequestID(c))
+				}
+			}
+
+			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError("ip address not allowed"))
+		}
+	}
+}
+
+func (mw *MiddlewareManager) ipFilterBypassed(path string) bool {
+	for _, prefix := range mw.cfg.IPFilter.BypassPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jwks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+const (
+	principalUser      = "user"
+	principalService   = "service"
+	defaultUserClaim   = "email"
+	defaultServiceRole = "service"
+)
+
+// Verify a token of a trusted external issuer and put the mapped principal into the context
+func (mw *MiddlewareManager) validateIssuerToken(tokenString string, authUC auth.UseCase, c echo.Context) error {
+	token, err := mw.issuers.Verify(c.Request().Context(), tokenString)
+	if err != nil {
+		return err
+	}
+
+	user, err := mw.issuerPrincipal(c.Request().Context(), token, authUC)
+	if err != nil {
+		return errors.Wrapf(err, "issuer %s", token.Issuer.Name)
+	}
+
+	requestctx.Issuer.Set(c, token.Issuer.Name)
+	setPrincipal(c, &models.Principal{User: user, Source: models.PrincipalSourceIssuer, Issuer: token.Issuer.Name}, "")
+	return nil
+}
+
+// Principal of the issuer config: user matches UserClaim against local user emails, service admits the token
+// subject as a service principal holding ServiceRole
+func (mw *MiddlewareManager) issuerPrincipal(ctx context.Context, token *jwks.Token, authUC auth.UseCase) (*models.UserWithRole, error) {
+	issuerCfg := mw.cfg.JWTIssuers[token.Issuer.Name]
+
+	switch issuerCfg.Principal {
+	case principalService:
+		subject := token.Claim("sub")
+		if subject == "" {
+			return nil, errors.New("missing sub claim")
+		}
+		role := issuerCfg.ServiceRole
+		if role == "" {
+			role = defaultServiceRole
+		}
+		return &models.UserWithRole{
+			User: models.User{Username: subject},
+			Role: models.Role{Name: role},
+		}, nil
+	case principalUser, "":
+		claim := issuerCfg.UserClaim
+		if claim == "" {
+			claim = defaultUserClaim
+		}
+		value := token.Claim(claim)
+		if value == "" {
+			return nil, errors.Errorf("missing %s claim", claim)
+		}
+		// Emails the issuer has not verified could claim any local account
+		if verified, ok := token.Claims["email_verified"].(bool); ok && !verified && claim == defaultUserClaim {
+			return nil, errors.New("email is not verified")
+		}
+		return authUC.GetByEmail(ctx, value)
+	default:
+		return nil, errors.Errorf("unknown principal type %q", issuerCfg.Principal)
+	}
+}
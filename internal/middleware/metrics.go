@@ -4,14 +4,34 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/slo"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
 )
 
-// Prometheus metrics middleware
-func (mw *MiddlewareManager) MetricsMiddleware(metrics metric.Metrics) echo.MiddlewareFunc {
+// Prometheus metrics middleware, also records every response against the SLO of its route group.
+// Requests run inside a server span continuing the trace of the caller, handler spans become its children
+// and the response time sample carries its trace id as exemplar.
+func (mw *MiddlewareManager) MetricsMiddleware(metrics metric.Metrics, objectives *slo.Tracker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			req := c.Request()
+			parent, _ := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+			span := opentracing.StartSpan("HTTP "+req.Method+" "+c.Path(), ext.RPCServerOption(parent))
+			ext.HTTPMethod.Set(span, req.Method)
+			ext.HTTPUrl.Set(span, req.URL.Path)
+			ctx := opentracing.ContextWithSpan(req.Context(), span)
+			c.SetRequest(req.WithContext(ctx))
+			if traceID, ok := tracing.TraceID(ctx); ok {
+				c.Response().Header().Set(tracing.HeaderTraceID, traceID)
+				if link := tracing.Link(mw.cfg.Jaeger.TraceLinkTemplate, traceID); link != "" {
+					c.Response().Header().Set(tracing.HeaderTraceLink, link)
+				}
+			}
+
 			start := time.Now()
 			err := next(c)
 			var status int
@@ -20,8 +40,17 @@ func (mw *MiddlewareManager) MetricsMiddleware(metrics metric.Metrics) echo.Midd
 			} else {
 				status = c.Response().Status
 			}
-			metrics.ObserveResponseTime(status, c.Request().Method, c.Path(), time.Since(start).Seconds())
-			metrics.IncHits(status, c.Request().Method, c.Path())
+			elapsed := time.Since(start)
+
+			ext.HTTPStatusCode.Set(span, uint16(status))
+			if status >= 500 {
+				ext.Error.Set(span, true)
+			}
+			span.Finish()
+
+			metrics.ObserveResponseTime(ctx, status, req.Method, c.Path(), elapsed.Seconds())
+			metrics.IncHits(status, req.Method, c.Path())
+			objectives.Record(req.URL.Path, status, elapsed)
 			return err
 		}
 	}
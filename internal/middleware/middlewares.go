@@ -1,22 +1,94 @@
 package middleware
 
 import (
+	"sync"
+
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/accesslog"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/apikey"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jwks"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/ratelimit"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/reqschema"
 )
 
 // Middleware manager
 type MiddlewareManager struct {
-	sessUC  session.UCSession
-	authUC  auth.UseCase
-	cfg     *config.Config
-	origins []string
-	logger  logger.Logger
+	sessUC     session.UCSession
+	authUC     auth.UseCase
+	cfg        *config.Config
+	origins    []string
+	logger     logger.Logger
+	limiter    *ratelimit.Limiter
+	auditUC    audit.UseCase
+	ipFilterUC ipfilter.UseCase
+	issuers    *jwks.Verifier
+	rbacUC     rbac.RbacUsecase
+	orgsUC     organizations.UseCase
+	rememberUC remember.UseCase
+	rotationUC passwordrotation.UseCase
+	tenants    *tenant.Router
+	apiKeys    *apikey.Keyring
+	tokensUC   accesstokens.UseCase
+	schemas    *reqschema.Validator
+	metrics    metric.Metrics
+	accessLog  *accesslog.Log
+	// Routes of the request schema middleware missing from the spec, logged once
+	unschemedRoutes sync.Map
 }
 
 // Middleware manager constructor
-func NewMiddlewareManager(sessUC session.UCSession, authUC auth.UseCase, cfg *config.Config, origins []string, logger logger.Logger) *MiddlewareManager {
-	return &MiddlewareManager{sessUC: sessUC, authUC: authUC, cfg: cfg, origins: origins, logger: logger}
+func NewMiddlewareManager(
+	sessUC session.UCSession,
+	authUC auth.UseCase,
+	cfg *config.Config,
+	origins []string,
+	logger logger.Logger,
+	limiter *ratelimit.Limiter,
+	auditUC audit.UseCase,
+	ipFilterUC ipfilter.UseCase,
+	issuers *jwks.Verifier,
+	rbacUC rbac.RbacUsecase,
+	orgsUC organizations.UseCase,
+	rememberUC remember.UseCase,
+	rotationUC passwordrotation.UseCase,
+	tenants *tenant.Router,
+	apiKeys *apikey.Keyring,
+	tokensUC accesstokens.UseCase,
+	schemas *reqschema.Validator,
+	metrics metric.Metrics,
+	accessLog *accesslog.Log,
+) *MiddlewareManager {
+	return &MiddlewareManager{
+		sessUC:     sessUC,
+		authUC:     authUC,
+		cfg:        cfg,
+		origins:    origins,
+		logger:     logger,
+		limiter:    limiter,
+		auditUC:    auditUC,
+		ipFilterUC: ipFilterUC,
+		issuers:    issuers,
+		rbacUC:     rbacUC,
+		orgsUC:     orgsUC,
+		rememberUC: rememberUC,
+		rotationUC: rotationUC,
+		tenants:    tenants,
+		apiKeys:    apiKeys,
+		tokensUC:   tokensUC,
+		schemas:    schemas,
+		metrics:    metrics,
+		accessLog:  accessLog,
+	}
 }
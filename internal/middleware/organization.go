@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+// Scope user listings to the organization of the caller, anonymous requests are left unscoped
+func (mw *MiddlewareManager) OrganizationScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, ok := requestctx.User.Get(c)
+		if !ok {
+			var err error
+			if user, err = mw.sessionUser(c); err != nil {
+				return c.JSON(httpErrors.ErrorResponse(err))
+			}
+		}
+		if user == nil || user.Role.Name == "administrator" {
+			return next(c)
+		}
+
+		membership, err := mw.orgsUC.GetMembership(c.Request().Context(), user.User.ID)
+		if err != nil {
+			// Listing unscoped would leak other organizations' users, fail closed
+			return err
+		}
+		// Users outside any organization are scoped to none and see no one
+		if membership == nil {
+			membership = &models.OrganizationMember{UserID: user.User.ID}
+		}
+		requestctx.Organization.Set(c, membership)
+		return next(c)
+	}
+}
+
+// User of the session cookie with the checks of the session middleware, nil without a valid user session.
+// Errors are for users a password rotation campaign flagged
+func (mw *MiddlewareManager) sessionUser(c echo.Context) (*models.UserWithRole, error) {
+	cookie, err := c.Cookie(mw.cfg.Session.Name)
+	if err != nil {
+		return nil, nil
+	}
+	sess, err := mw.sessUC.GetSessionByID(c.Request().Context(), cookie.Value)
+	if err != nil || sess.Guest || !mw.sessionTenantMatches(c, sess) {
+		return nil, nil
+	}
+	user, err := mw.authUC.GetByID(c.Request().Context(), sess.UserID)
+	if err != nil {
+		return nil, nil
+	}
+	if err := mw.checkPasswordRotation(c, user.User.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
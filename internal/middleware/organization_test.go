@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	authMock "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	orgsMock "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/mock"
+	rotationMock "github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/mock"
+	sessionMock "github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestOrganizationScope(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	orgsUC := orgsMock.NewMockUseCase(ctrl)
+	mw := &MiddlewareManager{cfg: &config.Config{Session: config.Session{Name: "session-id"}}, orgsUC: orgsUC}
+
+	serve := func(user *models.UserWithRole) (*httptest.ResponseRecorder, *models.OrganizationMember) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/auth/all", nil), rec)
+		if user != nil {
+			requestctx.User.Set(c, user)
+		}
+		var scope *models.OrganizationMember
+		err := mw.OrganizationScope(func(c echo.Context) error {
+			scope, _ = requestctx.Organization.Get(c)
+			return c.NoContent(http.StatusOK)
+		})(c)
+		require.NoError(t, err)
+		return rec, scope
+	}
+
+	// Anonymous listings stay public
+	rec, scope := serve(nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Nil(t, scope)
+
+	membership := &models.OrganizationMember{OrganizationID: 4, UserID: 7}
+	orgsUC.EXPECT().GetMembership(gomock.Any(), 7).Return(membership, nil)
+	rec, scope = serve(&models.UserWithRole{User: models.User{ID: 7}, Role: models.Role{Name: "user"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, membership, scope)
+
+	// Users outside any organization are scoped to none
+	orgsUC.EXPECT().GetMembership(gomock.Any(), 8).Return(nil, nil)
+	rec, scope = serve(&models.UserWithRole{User: models.User{ID: 8}, Role: models.Role{Name: "user"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, &models.OrganizationMember{UserID: 8}, scope)
+
+	rec, scope = serve(&models.UserWithRole{User: models.User{ID: 1}, Role: models.Role{Name: "administrator"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Nil(t, scope)
+}
+
+func TestOrganizationScope_SessionCookie(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	orgsUC := orgsMock.NewMockUseCase(ctrl)
+	sessUC := sessionMock.NewMockUCSession(ctrl)
+	authUC := authMock.NewMockUseCase(ctrl)
+	rotationUC := rotationMock.NewMockUseCase(ctrl)
+	cfg := &config.Config{Session: config.Session{Name: "session-id"}, Rotation: config.PasswordRotation{Enabled: true}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	mw := &MiddlewareManager{cfg: cfg, orgsUC: orgsUC, sessUC: sessUC, authUC: authUC, rotationUC: rotationUC, logger: appLogger}
+
+	serve := func(sessionID, routedTenant string) (int, *models.OrganizationMember) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/all", nil)
+		req.AddCookie(&http.Cookie{Name: cfg.Session.Name, Value: sessionID})
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		requestctx.Tenant.Set(c, routedTenant)
+		var scope *models.OrganizationMember
+		err := mw.OrganizationScope(func(c echo.Context) error {
+			scope, _ = requestctx.Organization.Get(c)
+			return c.NoContent(http.StatusOK)
+		})(c)
+		require.NoError(t, err)
+		return rec.Code, scope
+	}
+	user := &models.UserWithRole{User: models.User{ID: 7}, Role: models.Role{Name: "user"}}
+	membership := &models.OrganizationMember{OrganizationID: 4, UserID: 7}
+
+	sessUC.EXPECT().GetSessionByID(gomock.Any(), "user-sess").Return(&models.Session{SessionID: "user-sess", UserID: 7}, nil)
+	authUC.EXPECT().GetByID(gomock.Any(), 7).Return(user, nil)
+	rotationUC.EXPECT().IsRequired(gomock.Any(), 7).Return(false, nil)
+	orgsUC.EXPECT().GetMembership(gomock.Any(), 7).Return(membership, nil)
+	code, scope := serve("user-sess", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, membership, scope)
+
+	// Guest sessions are anonymous
+	sessUC.EXPECT().GetSessionByID(gomock.Any(), "guest-sess").Return(&models.Session{SessionID: "guest-sess", Guest: true}, nil)
+	code, scope = serve("guest-sess", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, scope)
+
+	// Users a password rotation campaign flagged have to change their password first
+	sessUC.EXPECT().GetSessionByID(gomock.Any(), "user-sess").Return(&models.Session{SessionID: "user-sess", UserID: 7}, nil)
+	authUC.EXPECT().GetByID(gomock.Any(), 7).Return(user, nil)
+	rotationUC.EXPECT().IsRequired(gomock.Any(), 7).Return(true, nil)
+	code, _ = serve("user-sess", "")
+	require.Equal(t, http.StatusForbidden, code)
+
+	// Sessions of another tenant are not the caller's
+	mw.tenants = &tenant.Router{}
+	sessUC.EXPECT().GetSessionByID(gomock.Any(), "user-sess").Return(&models.Session{SessionID: "user-sess", UserID: 7, TenantID: "acme"}, nil)
+	code, scope = serve("user-sess", "globex")
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, scope)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Request scoped permission memo, every permission check of a request after the first one for a role is free
+func (mw *MiddlewareManager) PermissionsMemoMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.SetRequest(c.Request().WithContext(rbac.WithMemo(c.Request().Context())))
+		return next(c)
+	}
+}
+
+// Permission based auth middleware, the role of ctx user or a role granted to them until later has to be granted permission
+func (mw *MiddlewareManager) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := requestctx.User.Get(c)
+			if !ok {
+				mw.logger.Errorf("RequirePermission RequestID: %s, Error: invalid user ctx", utils.GetRequestID(c))
+				return httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
+			}
+
+			granted, err := mw.rbacUC.HasUserPermission(c.Request().Context(), user, permission)
+			if err != nil {
+				return err
+			}
+			if !granted {
+				mw.logger.Warnf("RequirePermission RequestID: %s, UserID: %d, Role: %s, Permission: %s, Error: not granted",
+					utils.GetRequestID(c),
+					user.User.ID,
+					user.Role.Name,
+					permission,
+				)
+				return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
+			}
+			return next(c)
+		}
+	}
+}
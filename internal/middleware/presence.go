@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Presence heartbeat of every request a user authenticated. Registered globally, so it reads the principal
+// the route auth middleware put into the context once the handler returns
+func (mw *MiddlewareManager) PresenceMiddleware(presenceUC presence.UseCase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if principal, ok := requestctx.Principal.Get(c); ok && principal.IsUser() {
+				if touchErr := presenceUC.Touch(c.Request().Context(), principal.User.User.ID); touchErr != nil {
+					mw.logger.Errorf("PresenceMiddleware RequestID: %s, UserID: %d, Error: %s", utils.GetRequestID(c), principal.User.User.ID, touchErr.Error())
+				}
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Put the authenticated caller into the context, every auth middleware ends here
+func setPrincipal(c echo.Context, principal *models.Principal, tenantID string) {
+	requestctx.User.Set(c, principal.User)
+	requestctx.Principal.Set(c, principal)
+	c.SetRequest(c.Request().WithContext(withUserLabels(c.Request().Context(), principal.User, tenantID)))
+}
+
+// Personal access token was not granted the scope of the route
+var errInsufficientScope = errors.New("insufficient scope")
+
+// Token of an Authorization: Bearer header
+func bearerToken(c echo.Context) (string, bool) {
+	headerParts := strings.Split(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+	if len(headerParts) != 2 || !strings.EqualFold(headerParts[0], "Bearer") || headerParts[1] == "" {
+		return "", false
+	}
+	return headerParts[1], true
+}
+
+// Bearer side of the session middleware, a JWT, a token of a trusted issuer, a personal access token holding the
+// api scope or an API key
+func (mw *MiddlewareManager) bearerAuth(c echo.Context, next echo.HandlerFunc, token string) error {
+	// Tokens carry no tenant, their user id would be looked up in whichever tenant the request is routed to
+	if mw.tenants != nil {
+		mw.logger.Errorf("bearerAuth RequestID: %s, Error: bearer credentials in database per tenant mode", utils.GetRequestID(c))
+		return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(token, models.PersonalAccessTokenPrefix):
+		err = mw.validatePersonalToken(token, models.ScopeAPI, c)
+	case strings.Count(token, ".") == 2:
+		err = mw.validateJWTToken(token, mw.authUC, c, mw.cfg)
+	default:
+		err = mw.validateAPIKey(token, c)
+	}
+	if errors.Is(err, errInsufficientScope) {
+		mw.logger.Warnf("bearerAuth RequestID: %s, Error: %s", utils.GetRequestID(c), err.Error())
+		return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
+	}
+	if err != nil {
+		mw.logger.Errorf("bearerAuth RequestID: %s, Error: %s", utils.GetRequestID(c), err.Error())
+		return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+	}
+
+	principal, _ := requestctx.Principal.Get(c)
+	if principal.IsUser() {
+		if err := mw.checkPasswordRotation(c, principal.User.User.ID); err != nil {
+			mw.logger.Errorf("checkPasswordRotation RequestID: %s, UserID: %d, Error: %s", utils.GetRequestID(c), principal.User.User.ID, err.Error())
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+	}
+	return next(c)
+}
+
+// Resolve an API key to its local user or service principal
+func (mw *MiddlewareManager) validateAPIKey(token string, c echo.Context) error {
+	key, ok := mw.apiKeys.Lookup(token)
+	if !ok {
+		return errors.New("unknown api key")
+	}
+
+	user := &models.UserWithRole{User: models.User{Username: key.Name}, Role: models.Role{Name: key.ServiceRole}}
+	if key.UserEmail != "" {
+		var err error
+		if user, err = mw.authUC.GetByEmail(c.Request().Context(), key.UserEmail); err != nil {
+			return errors.Wrapf(err, "api key %s", key.Name)
+		}
+	}
+	setPrincipal(c, &models.Principal{User: user, Source: models.PrincipalSourceAPIKey, APIKey: key.Name}, "")
+	return nil
+}
+
+// Resolve a personal access token granted scope to its owner, errInsufficientScope when it lacks the scope
+func (mw *MiddlewareManager) validatePersonalToken(token string, scope string, c echo.Context) error {
+	if mw.tokensUC == nil {
+		return errors.New("personal access tokens are disabled")
+	}
+	pat, err := mw.tokensUC.Authenticate(c.Request().Context(), token)
+	if err != nil {
+		return errors.Wrap(err, "personal access token")
+	}
+	if !pat.HasScope(scope) {
+		return errors.Wrapf(errInsufficientScope, "personal access token %d, scope %s, granted %s", pat.ID, scope, pat.Scope())
+	}
+
+	user, err := mw.authUC.GetByID(c.Request().Context(), pat.UserID)
+	if err != nil {
+		return errors.Wrapf(err, "personal access token %d", pat.ID)
+	}
+	requestctx.Scope.Set(c, pat.Scope())
+	setPrincipal(c, &models.Principal{User: user, Source: models.PrincipalSourceToken, Scope: pat.Scope(), TokenID: pat.ID}, "")
+	return nil
+}
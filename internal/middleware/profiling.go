@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
+)
+
+// Label the request goroutine with its route and method for CPU profiles, auth middlewares add
+// the user and tenant once known. Disabled unless Server.PprofLabels.
+func (mw *MiddlewareManager) ProfilingLabelsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	if !mw.cfg.Server.PprofLabels {
+		return next
+	}
+	return func(c echo.Context) error {
+		var err error
+		profiling.Do(c.Request().Context(), c.Path(), c.Request().Method, func(ctx context.Context) {
+			c.SetRequest(c.Request().WithContext(ctx))
+			err = next(c)
+		})
+		return err
+	}
+}
+
+// Add user and tenant labels to a request context, a no-op when the request is not labeled
+func withUserLabels(ctx context.Context, user *models.UserWithRole, tenantID string) context.Context {
+	return profiling.AddLabels(ctx, profiling.LabelUser, strconv.Itoa(user.User.ID), profiling.LabelTenant, tenantID)
+}
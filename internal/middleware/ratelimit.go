@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/ratelimit"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	rateLimitWarningHeader   = "X-RateLimit-Warning"
+	rateLimitScopeHeader     = "X-RateLimit-Scope"
+)
+
+// Levels of hierarchical limits, sent in the scope header so callers can tell their own limit from a shared one
+const (
+	rateLimitScopeCaller = "caller"
+	rateLimitScopeTenant = "tenant"
+	rateLimitScopeGlobal = "global"
+)
+
+// Rate limit middleware for a named route policy, limit headers are sent on every response and warn-only policies
+// log without rejecting. Policies with a tenant or global limit count the caller, its tenant and the deployment
+// within the same window, so one noisy tenant can't use up what the others share. The headers are then of the
+// level closest to its limit
+func (mw *MiddlewareManager) RateLimit(route string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			policy, ok := mw.cfg.RateLimit.Routes[route]
+			if !mw.cfg.RateLimit.Enabled || !ok || mw.limiter == nil {
+				return next(c)
+			}
+
+			ctx := utils.GetRequestCtx(c)
+			window := time.Duration(policy.Window) * time.Second
+			var res *ratelimit.Result
+			var err error
+			if policy.TenantLimit > 0 || policy.GlobalLimit > 0 {
+				res, err = mw.limiter.AllowLevels(ctx, rateLimitLevels(c, route, policy), window)
+			} else {
+				res, err = mw.limiter.Allow(ctx, route+":"+rateLimitCaller(c), policy.Limit, window)
+			}
+			if err != nil {
+				// Fail open, a limiter outage must not take the route down
+				mw.logger.Errorf("RateLimit Middleware limiter.Allow, Route: %s, Error: %s, RequestId: %s",
+					route,
+					err,
+					utils.GetRequestID(c),
+				)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(rateLimitLimitHeader, strconv.Itoa(res.Limit))
+			header.Set(rateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+			header.Set(rateLimitResetHeader, strconv.FormatInt(res.Reset.Unix(), 10))
+			if res.Level != "" {
+				header.Set(rateLimitScopeHeader, res.Level)
+			}
+
+			if res.Allowed {
+				return next(c)
+			}
+
+			if policy.WarnOnly {
+				mw.logger.Warnf("RateLimit Middleware limit exceeded in warn-only mode, Route: %s, IP: %s, Scope: %s, RequestId: %s",
+					route,
+					c.RealIP(),
+					res.Level,
+					utils.GetRequestID(c),
+				)
+				header.Set(rateLimitWarningHeader, "limit exceeded")
+				return next(c)
+			}
+
+			retryAfter := int(time.Until(res.Reset).Seconds()) + 1
+			header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.JSON(http.StatusTooManyRequests, httpErrors.NewRestError(http.StatusTooManyRequests, "Too Many Requests", "rate limit exceeded"))
+		}
+	}
+}
+
+// Caller a limit counts, the user when the auth or organization middleware ran before and the IP otherwise
+func rateLimitCaller(c echo.Context) string {
+	if user, ok := requestctx.User.Get(c); ok {
+		return "user:" + strconv.Itoa(user.User.ID)
+	}
+	if member, ok := requestctx.Organization.Get(c); ok {
+		return "user:" + strconv.Itoa(member.UserID)
+	}
+	return c.RealIP()
+}
+
+// Tenant a limit counts, the tenant database in database mode and the organization of the caller otherwise.
+// Empty for callers outside any tenant, only their own and the global limit apply
+func rateLimitTenant(c echo.Context) string {
+	if tenantID, ok := requestctx.Tenant.Get(c); ok && tenantID != "" {
+		return "tenant:" + tenantID
+	}
+	if member, ok := requestctx.Organization.Get(c); ok && member.OrganizationID != 0 {
+		return "org:" + strconv.FormatInt(member.OrganizationID, 10)
+	}
+	return ""
+}
+
+// Levels of a hierarchical policy from the caller up, unset limits are left out
+func rateLimitLevels(c echo.Context, route string, policy config.RateLimitRoute) []ratelimit.Level {
+	levels := []ratelimit.Level{{Name: rateLimitScopeCaller, Key: route + ":" + rateLimitCaller(c), Limit: policy.Limit}}
+	if tenant := rateLimitTenant(c); tenant != "" && policy.TenantLimit > 0 {
+		levels = append(levels, ratelimit.Level{Name: rateLimitScopeTenant, Key: route + ":" + tenant, Limit: policy.TenantLimit})
+	}
+	if policy.GlobalLimit > 0 {
+		levels = append(levels, ratelimit.Level{Name: rateLimitScopeGlobal, Key: route + ":global", Limit: policy.GlobalLimit})
+	}
+	return levels
+}
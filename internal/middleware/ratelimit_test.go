@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/ratelimit"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestRateLimit_Hierarchical(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := &config.Config{RateLimit: config.RateLimit{Enabled: true, Routes: map[string]config.RateLimitRoute{
+		"users_search": {Limit: 2, Window: 60, TenantLimit: 3, GlobalLimit: 5},
+	}}}
+	mw := &MiddlewareManager{cfg: cfg, limiter: ratelimit.NewLimiter(client, "test")}
+
+	e := echo.New()
+	// Stands in for the organization middleware, callers name themselves and their organization
+	member := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, _ := strconv.Atoi(c.QueryParam("user"))
+			orgID, _ := strconv.ParseInt(c.QueryParam("org"), 10, 64)
+			if orgID != 0 {
+				requestctx.Organization.Set(c, &models.OrganizationMember{UserID: userID, OrganizationID: orgID})
+			}
+			return next(c)
+		}
+	}
+	e.GET("/find", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, member, mw.RateLimit("users_search"))
+	find := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/find?"+query, nil))
+		return rec
+	}
+
+	rec := find("user=1&org=10")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "caller", rec.Header().Get(rateLimitScopeHeader))
+	require.Equal(t, "1", rec.Header().Get(rateLimitRemainingHeader))
+
+	require.Equal(t, http.StatusOK, find("user=1&org=10").Code)
+	rec = find("user=1&org=10")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "caller", rec.Header().Get(rateLimitScopeHeader))
+	require.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Another member of the organization gets what is left of its tenant limit
+	require.Equal(t, http.StatusOK, find("user=2&org=10").Code)
+	rec = find("user=2&org=10")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "tenant", rec.Header().Get(rateLimitScopeHeader))
+
+	// Other tenants and anonymous callers share what is left globally
+	require.Equal(t, http.StatusOK, find("user=3&org=20").Code)
+	require.Equal(t, http.StatusOK, find("").Code)
+	rec = find("user=4&org=30")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "global", rec.Header().Get(rateLimitScopeHeader))
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := &config.Config{RateLimit: config.RateLimit{Enabled: true, Routes: map[string]config.RateLimitRoute{
+		"login":  {Limit: 1, Window: 60},
+		"search": {Limit: 1, Window: 60, WarnOnly: true},
+	}}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	mw := &MiddlewareManager{cfg: cfg, limiter: ratelimit.NewLimiter(client, "test"), logger: appLogger}
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.POST("/login", ok, mw.RateLimit("login"))
+	e.GET("/search", ok, mw.RateLimit("search"))
+	e.GET("/open", ok, mw.RateLimit("unknown"))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	// Limit headers are sent on allowed requests too
+	rec := serve(http.MethodPost, "/login")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "1", rec.Header().Get(rateLimitLimitHeader))
+	require.Equal(t, "0", rec.Header().Get(rateLimitRemainingHeader))
+	require.NotEmpty(t, rec.Header().Get(rateLimitResetHeader))
+	require.Empty(t, rec.Header().Get(rateLimitScopeHeader))
+
+	rec = serve(http.MethodPost, "/login")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+	require.Empty(t, rec.Header().Get(rateLimitWarningHeader))
+
+	// Anonymous callers cannot pick another address to count against
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Warn-only policies let the request through and flag it
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/search").Code)
+	rec = serve(http.MethodGet, "/search")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "limit exceeded", rec.Header().Get(rateLimitWarningHeader))
+	require.Equal(t, "0", rec.Header().Get(rateLimitRemainingHeader))
+	require.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Routes without a policy are not limited
+	rec = serve(http.MethodGet, "/open")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(rateLimitLimitHeader))
+
+	// Limiter outages fail open
+	server.SetError("LOADING")
+	rec = serve(http.MethodPost, "/login")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(rateLimitLimitHeader))
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/replay"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Traffic recording middleware, hands every request and its response to the recorder which sanitizes them.
+// The request body is read ahead and given back to the handler, the response body is copied as it is written.
+// Registered after gzip so recorded bodies are plain.
+func (mw *MiddlewareManager) ReplayRecorder(recorder *replay.Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var reqBody []byte
+			if req.Body != nil {
+				read, err := io.ReadAll(io.LimitReader(req.Body, replay.MaxBodyBytes+1))
+				if err != nil {
+					return err
+				}
+				reqBody = read
+				req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(read), req.Body))
+			}
+
+			capture := &capturingWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = capture
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			res := c.Response()
+			if err := recorder.Record(c.RealIP(), req, reqBody, res.Status, res.Header(), capture.body.Bytes()); err != nil {
+				mw.logger.Errorf("ReplayRecorder RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+			}
+			return nil
+		}
+	}
+}
+
+// Response writer keeping a copy of the body, up to one byte over the recording limit
+type capturingWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	if room := replay.MaxBodyBytes + 1 - w.body.Len(); room > 0 {
+		if len(b) < room {
+			room = len(b)
+		}
+		w.body.Write(b[:room])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *capturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
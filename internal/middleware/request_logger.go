@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
@@ -21,8 +22,10 @@ func (mw *MiddlewareManager) RequestLoggerMiddleware(next echo.HandlerFunc) echo
 		s := time.Since(start).String()
 		requestID := utils.GetRequestID(ctx)
 
-		mw.logger.Infof("RequestID: %s, Method: %s, URI: %s, Status: %v, Size: %v, Time: %s",
-			requestID, req.Method, req.URL, status, size, s,
+		traceID := res.Header().Get(tracing.HeaderTraceID)
+
+		mw.logger.Infof("RequestID: %s, TraceID: %s, Method: %s, URI: %s, Status: %v, Size: %v, Time: %s",
+			requestID, traceID, req.Method, req.URL, status, size, s,
 		)
 		return err
 	}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Request schema middleware, validates the request against the operation of its route in the OpenAPI spec before
+// the handler runs. Invalid requests are answered 400 listing every failing field, each counted per route.
+// Routes the spec does not describe pass unchecked, logged once per route
+func (mw *MiddlewareManager) RequestSchema(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if mw.schemas == nil {
+			return next(c)
+		}
+
+		problems, ok := mw.schemas.Validate(c.Request(), c.Path(), c.ParamValues())
+		if !ok {
+			if _, logged := mw.unschemedRoutes.LoadOrStore(c.Request().Method+" "+c.Path(), true); !logged {
+				mw.logger.Warnf("RequestSchema Method: %s, Path: %s, Error: no operation in the spec, requests pass unchecked",
+					c.Request().Method,
+					c.Path(),
+				)
+			}
+			return next(c)
+		}
+		if len(problems) == 0 {
+			return next(c)
+		}
+
+		for _, problem := range problems {
+			if mw.metrics != nil {
+				mw.metrics.IncRequestSchemaFailures(c.Path(), problem.Field)
+			}
+		}
+		mw.logger.Warnf("RequestSchema RequestID: %s, Path: %s, Problems: %v", utils.GetRequestID(c), c.Path(), problems)
+		return c.JSON(http.StatusBadRequest, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.ErrBadRequest, problems))
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/reqschema"
+)
+
+type schemaFailureMetrics struct {
+	metric.Metrics
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *schemaFailureMetrics) IncRequestSchemaFailures(path, field string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[path+" "+field]++
+}
+
+const widgetsSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+paths:
+  /widgets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string, minLength: 1}
+      responses:
+        "201": {description: created}
+`
+
+func TestRequestSchema(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	schemas, err := reqschema.New([]byte(widgetsSpec), "/api/v1")
+	require.NoError(t, err)
+	metrics := &schemaFailureMetrics{counts: make(map[string]int)}
+	mw := &MiddlewareManager{cfg: cfg, logger: appLogger, schemas: schemas, metrics: metrics}
+
+	e := echo.New()
+	var handled string
+	handler := func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		require.NoError(t, err)
+		handled = string(body)
+		return c.NoContent(http.StatusCreated)
+	}
+	e.POST("/api/v1/widgets", handler, mw.RequestSchema)
+	e.PUT("/api/v1/widgets", handler, mw.RequestSchema)
+
+	serve := func(method, body string) int {
+		req := httptest.NewRequest(method, "/api/v1/widgets", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Valid bodies reach the handler intact
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, `{"name": "bolt"}`))
+	require.Equal(t, `{"name": "bolt"}`, handled)
+
+	handled = ""
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{"name": ""}`))
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{}`))
+	require.Empty(t, handled)
+	require.Equal(t, map[string]int{"/api/v1/widgets body.name": 2}, metrics.counts)
+
+	// Routes missing from the spec pass unchecked
+	require.Equal(t, http.StatusCreated, serve(http.MethodPut, `{}`))
+
+	mw.schemas = nil
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, `{}`))
+}
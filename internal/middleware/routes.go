@@ -0,0 +1,16 @@
+package middleware
+
+// Requirement each auth middleware enforces, by its name in the route table of pkg/routes
+var AuthRequirements = map[string]string{
+	"internal/middleware.MiddlewareManager.AuthSessionMiddleware":    "session",
+	"internal/middleware.MiddlewareManager.SessionOrGuestMiddleware": "session_or_guest",
+	"internal/middleware.MiddlewareManager.CheckAuth":                "session",
+	"internal/middleware.MiddlewareManager.AuthJWTMiddleware":        "jwt",
+	"internal/middleware.MiddlewareManager.ScopedTokenMiddleware":    "scoped_token",
+	"internal/middleware.MiddlewareManager.AdminMiddleware":          "admin",
+	"internal/middleware.MiddlewareManager.OwnerOrAdminMiddleware":   "owner_or_admin",
+	"internal/middleware.MiddlewareManager.RoleBasedAuthMiddleware":  "role",
+	"internal/middleware.MiddlewareManager.RequirePermission":        "permission",
+	"internal/middleware.MiddlewareManager.StepUp":                   "step_up",
+	"internal/middleware.MiddlewareManager.IPFilter":                 "ip_allowlist",
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/routes"
+)
+
+func TestAuthRequirements(t *testing.T) {
+	t.Parallel()
+
+	// Every auth middleware is known to the route table by the name it is listed under
+	mw := &MiddlewareManager{cfg: &config.Config{}}
+	for _, m := range []echo.MiddlewareFunc{
+		mw.AuthSessionMiddleware,
+		mw.SessionOrGuestMiddleware,
+		mw.CheckAuth,
+		mw.AuthJWTMiddleware(nil, nil),
+		mw.ScopedTokenMiddleware("profile"),
+		mw.AdminMiddleware,
+		mw.OwnerOrAdminMiddleware(),
+		mw.RoleBasedAuthMiddleware([]string{"administrator"}),
+		mw.RequirePermission("roles:read"),
+		mw.StepUp,
+		mw.IPFilter("admin"),
+	} {
+		require.Contains(t, AuthRequirements, routes.Name(m))
+	}
+	require.Len(t, AuthRequirements, 11)
+}
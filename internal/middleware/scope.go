@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Scoped token middleware, admits only bearer tokens from the token exchange and personal access tokens that were
+// granted scope
+func (mw *MiddlewareManager) ScopedTokenMiddleware(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			headerParts := strings.Split(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if len(headerParts) != 2 || !strings.EqualFold(headerParts[0], "Bearer") {
+				mw.logger.Errorf("ScopedTokenMiddleware RequestID: %s, Error: missing bearer token", utils.GetRequestID(c))
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			}
+
+			if strings.HasPrefix(headerParts[1], models.PersonalAccessTokenPrefix) {
+				return mw.personalTokenScope(c, next, headerParts[1], scope)
+			}
+
+			claims, err := utils.ParseScopedJWTToken(headerParts[1], mw.cfg)
+			if err != nil {
+				mw.logger.Errorf("ScopedTokenMiddleware.ParseScopedJWTToken RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			}
+			if !claims.HasScope(scope) {
+				mw.logger.Warnf("ScopedTokenMiddleware RequestID: %s, Scope: %s, Granted: %s, Error: insufficient scope",
+					utils.GetRequestID(c),
+					scope,
+					claims.Scope,
+				)
+				return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
+			}
+
+			userID, err := strconv.Atoi(claims.ID)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.InvalidJWTClaims))
+			}
+			user, err := mw.authUC.GetByID(c.Request().Context(), userID)
+			if err != nil {
+				mw.logger.Errorf("ScopedTokenMiddleware.GetByID RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+			}
+
+			requestctx.Scope.Set(c, claims.Scope)
+			setPrincipal(c, &models.Principal{User: user, Source: models.PrincipalSourceScoped, Scope: claims.Scope}, "")
+			return next(c)
+		}
+	}
+}
+
+// Personal access token side of the scoped token middleware
+func (mw *MiddlewareManager) personalTokenScope(c echo.Context, next echo.HandlerFunc, token string, scope string) error {
+	err := mw.validatePersonalToken(token, scope, c)
+	if errors.Is(err, errInsufficientScope) {
+		mw.logger.Warnf("ScopedTokenMiddleware RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+		return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.PermissionDenied))
+	}
+	if err != nil {
+		mw.logger.Errorf("ScopedTokenMiddleware.validatePersonalToken RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+		return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+	}
+	return next(c)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+// Route override value leaving the header out
+const securityHeaderOmit = "-"
+
+// Response security headers middleware, policy headers come from config with per route overrides
+func (mw *MiddlewareManager) SecurityHeaders() echo.MiddlewareFunc {
+	cfg := mw.cfg.Security
+	hsts := hstsValue(cfg)
+
+	// Longest prefix first, so the first match is the most specific one
+	routes := append([]config.SecurityRoute(nil), cfg.Routes...)
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].PathPrefix) > len(routes[j].PathPrefix) })
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			frameOptions, referrerPolicy, csp := cfg.FrameOptions, cfg.ReferrerPolicy, cfg.ContentSecurityPolicy
+			path := c.Request().URL.Path
+			for _, route := range routes {
+				if strings.HasPrefix(path, route.PathPrefix) {
+					frameOptions = overrideHeader(frameOptions, route.FrameOptions)
+					referrerPolicy = overrideHeader(referrerPolicy, route.ReferrerPolicy)
+					csp = overrideHeader(csp, route.ContentSecurityPolicy)
+					break
+				}
+			}
+
+			header := c.Response().Header()
+			if cfg.ContentTypeNosniff {
+				header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			}
+			if hsts != "" && c.Scheme() == "https" {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+			if frameOptions != "" {
+				header.Set(echo.HeaderXFrameOptions, frameOptions)
+			}
+			if referrerPolicy != "" {
+				header.Set(echo.HeaderReferrerPolicy, referrerPolicy)
+			}
+			if csp != "" {
+				header.Set(echo.HeaderContentSecurityPolicy, csp)
+			}
+			return next(c)
+		}
+	}
+}
+
+func overrideHeader(value string, override string) string {
+	switch override {
+	case "":
+		return value
+	case securityHeaderOmit:
+		return ""
+	default:
+		return override
+	}
+}
+
+func hstsValue(cfg config.Security) string {
+	if cfg.HSTSMaxAgeSeconds <= 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.Itoa(cfg.HSTSMaxAgeSeconds)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if cfg.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
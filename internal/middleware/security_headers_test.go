@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	mw := &MiddlewareManager{cfg: &config.Config{Security: config.Security{
+		Headers:               true,
+		HSTSMaxAgeSeconds:     31536000,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'",
+		Routes: []config.SecurityRoute{
+			{PathPrefix: "/admin", ContentSecurityPolicy: "default-src 'self'"},
+			{PathPrefix: "/admin/login", ContentSecurityPolicy: "default-src 'self' 'unsafe-inline'", FrameOptions: "-"},
+		},
+	}}}
+
+	e := echo.New()
+	e.Use(mw.SecurityHeaders())
+	e.GET("/*", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	serve := func(path string, https bool) http.Header {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if https {
+			req.Header.Set(echo.HeaderXForwardedProto, "https")
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Header()
+	}
+
+	header := serve("/api/v1/users", true)
+	require.Equal(t, "max-age=31536000; includeSubDomains", header.Get(echo.HeaderStrictTransportSecurity))
+	require.Equal(t, "nosniff", header.Get(echo.HeaderXContentTypeOptions))
+	require.Equal(t, "DENY", header.Get(echo.HeaderXFrameOptions))
+	require.Equal(t, "no-referrer", header.Get(echo.HeaderReferrerPolicy))
+	require.Equal(t, "default-src 'none'", header.Get(echo.HeaderContentSecurityPolicy))
+
+	// HSTS is meaningless over plain http
+	require.Empty(t, serve("/api/v1/users", false).Get(echo.HeaderStrictTransportSecurity))
+
+	header = serve("/admin/ui/", false)
+	require.Equal(t, "default-src 'self'", header.Get(echo.HeaderContentSecurityPolicy))
+	require.Equal(t, "DENY", header.Get(echo.HeaderXFrameOptions))
+
+	// Longest prefix wins regardless of the configured order
+	header = serve("/admin/login", false)
+	require.Equal(t, "default-src 'self' 'unsafe-inline'", header.Get(echo.HeaderContentSecurityPolicy))
+	require.Empty(t, header.Get(echo.HeaderXFrameOptions))
+	require.Equal(t, "no-referrer", header.Get(echo.HeaderReferrerPolicy))
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Step-up middleware for sensitive routes, requires a recent login or re-authentication, must run after AuthSessionMiddleware
+func (mw *MiddlewareManager) StepUp(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sess, _ := requestctx.Session.Get(c)
+		if err := mw.sessUC.CheckStepUp(utils.GetRequestCtx(c), sess); err != nil {
+			mw.logger.Warnf("StepUp Middleware RequestID: %s, Error: %s",
+				utils.GetRequestID(c),
+				err,
+			)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		return next(c)
+	}
+}
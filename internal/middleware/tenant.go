@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Health checks answer for the instance, not for a tenant
+const healthPathPrefix = "/api/v1/health"
+
+// Route the request to the database of the tenant named by the tenancy header, repositories read it from the
+// request context. Passes everything through in shared mode
+func (mw *MiddlewareManager) TenantDB(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if mw.tenants == nil || strings.HasPrefix(c.Request().URL.Path, healthPathPrefix) {
+			return next(c)
+		}
+
+		tenantID := c.Request().Header.Get(mw.cfg.Tenancy.Header)
+		if tenantID == "" {
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(mw.cfg.Tenancy.Header+" header is required"))
+		}
+		db, err := mw.tenants.DB(c.Request().Context(), tenantID)
+		if err != nil {
+			mw.logger.Errorf("TenantDB RequestID: %s, Tenant: %s, Error: %s", utils.GetRequestID(c), tenantID, err.Error())
+			if errors.Is(err, tenant.ErrUnknownTenant) {
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("unknown tenant"))
+			}
+			return c.JSON(http.StatusServiceUnavailable, httpErrors.NewRestError(http.StatusServiceUnavailable, "tenant database unavailable", nil))
+		}
+
+		requestctx.Tenant.Set(c, tenantID)
+		c.SetRequest(c.Request().WithContext(tenant.WithDB(c.Request().Context(), db)))
+		return next(c)
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+// Permissions granted to a user and feature flags evaluated for it, lets clients render role dependent UI
+type Access struct {
+	Permissions []string        `json:"permissions"`
+	Features    map[string]bool `json:"features"`
+}
+
+// Answer to one permission check, in the order of the request
+type PermissionCheckResult struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Allowed  bool   `json:"allowed"`
+}
+
+// Answers to a bulk permission check
+type PermissionCheckResults struct {
+	Results []PermissionCheckResult `json:"results"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Personal access tokens start with this prefix, it tells them apart from JWTs and API keys
+const PersonalAccessTokenPrefix = "pat_"
+
+// Scope of personal access tokens standing in for a session of their owner on the session routes
+const ScopeAPI = "api"
+
+// Token a user minted for their own account, Token is the plaintext and only returned on creation.
+// Prefix is its start, kept to tell tokens apart in listings
+type PersonalAccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Whether the token has expired at now, tokens without expiry never do
+func (t *PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Whether the token was granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes space separated like the scope of a scoped token
+func (t *PersonalAccessToken) Scope() string {
+	return strings.Join(t.Scopes, " ")
+}
@@ -0,0 +1,9 @@
+package models
+
+// Rows rewritten by an anonymization run, with DryRun the rows that would be
+type AnonymizeReport struct {
+	Users     int  `json:"users"`
+	Addresses int  `json:"addresses"`
+	Phones    int  `json:"phones"`
+	DryRun    bool `json:"dry_run"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Security audit event, every event is chained to the previous one by PrevHash
+type AuditEvent struct {
+	ID        int64           `json:"id" db:"id"`
+	Action    string          `json:"action" db:"action"`
+	ActorID   *int            `json:"actor_id,omitempty" db:"actor_id"`
+	IPAddress string          `json:"ip_address,omitempty" db:"ip_address"`
+	RequestID string          `json:"request_id,omitempty" db:"request_id"`
+	Resource  string          `json:"resource,omitempty" db:"resource"`
+	Metadata  json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	ChainSeq  int64           `json:"chain_seq" db:"chain_seq"`
+	PrevHash  string          `json:"prev_hash" db:"prev_hash"`
+	Hash      string          `json:"hash" db:"hash"`
+}
+
+// Hash of the event as stored, HMAC-SHA256 when a signing key is set and SHA-256 otherwise.
+// Covers every stored field and the previous hash, so changing, removing or reordering events breaks the chain.
+func (e *AuditEvent) ChainHash(key []byte) string {
+	actorID := ""
+	if e.ActorID != nil {
+		actorID = strconv.Itoa(*e.ActorID)
+	}
+	fields, _ := json.Marshal([]string{
+		strconv.FormatInt(e.ChainSeq, 10),
+		e.PrevHash,
+		strconv.FormatInt(e.ID, 10),
+		e.Action,
+		actorID,
+		e.IPAddress,
+		e.RequestID,
+		e.Resource,
+		string(e.Metadata),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	if len(key) == 0 {
+		sum := sha256.Sum256(fields)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(fields)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Chain head written to write-once storage, a later chain must still contain it unchanged
+type AuditAnchor struct {
+	ChainSeq   int64     `json:"chain_seq"`
+	Hash       string    `json:"hash"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// Newest chained event removed by retention, the remaining chain links to its hash
+type AuditChainFloor struct {
+	ChainSeq int64     `json:"chain_seq" db:"chain_seq"`
+	Hash     string    `json:"hash" db:"hash"`
+	PrunedAt time.Time `json:"pruned_at" db:"pruned_at"`
+}
+
+// Audit chain defect found by verification
+type AuditChainProblem struct {
+	ChainSeq int64  `json:"chain_seq"`
+	EventID  int64  `json:"event_id,omitempty"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail"`
+}
+
+// Audit chain verification result, PrunedThrough is the seq of the last event removed by retention
+type AuditVerification struct {
+	Valid         bool                `json:"valid"`
+	Events        int64               `json:"events"`
+	PrunedThrough int64               `json:"pruned_through,omitempty"`
+	HeadSeq       int64               `json:"head_seq"`
+	HeadHash      string              `json:"head_hash"`
+	Anchors       int                 `json:"anchors"`
+	Problems      []AuditChainProblem `json:"problems"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Provider subscription statuses which entitle to the plan, past due subscriptions keep it while the provider
+// retries the payment
+const (
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusTrialing = "trialing"
+	SubscriptionStatusPastDue  = "past_due"
+	SubscriptionStatusCanceled = "canceled"
+)
+
+// Outcomes of a billing webhook event
+const (
+	BillingOutcomeApplied   = "applied"
+	BillingOutcomeDuplicate = "duplicate"
+	BillingOutcomeStale     = "stale"
+	BillingOutcomeIgnored   = "ignored"
+)
+
+// Entitlement of an organization or a user to a billing plan, one per provider subscription. EventAt is the
+// creation time of the provider event it was last changed by, events older than it are not applied
+type Entitlement struct {
+	ID               int64      `json:"id" db:"id"`
+	SubscriptionID   string     `json:"subscription_id" db:"subscription_id"`
+	CustomerID       string     `json:"customer_id" db:"customer_id"`
+	OrganizationID   *int64     `json:"organization_id,omitempty" db:"organization_id"`
+	UserID           *int       `json:"user_id,omitempty" db:"user_id"`
+	Plan             string     `json:"plan" db:"plan"`
+	Status           string     `json:"status" db:"status"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty" db:"current_period_end"`
+	EventAt          time.Time  `json:"-" db:"event_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Does the subscription status entitle to the plan
+func (e *Entitlement) Active() bool {
+	switch e.Status {
+	case SubscriptionStatusActive, SubscriptionStatusTrialing, SubscriptionStatusPastDue:
+		return true
+	}
+	return false
+}
+
+// Outcome of a billing webhook event, providers only need a 2xx to stop retrying
+type BillingEventResult struct {
+	EventID string `json:"event_id"`
+	Outcome string `json:"outcome"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Cache invalidation change event, written by database triggers
+type ChangeEvent struct {
+	ID        int64     `json:"id" db:"id"`
+	Table     string    `json:"table" db:"table_name"`
+	Operation string    `json:"op" db:"operation"`
+	EntityID  int       `json:"entity_id" db:"entity_id"`
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Postal address of a user
+type Address struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int       `json:"-" db:"user_id"`
+	Label      string    `json:"label,omitempty" db:"label"`
+	Line1      string    `json:"line1" db:"line1"`
+	Line2      string    `json:"line2,omitempty" db:"line2"`
+	City       string    `json:"city" db:"city"`
+	Region     string    `json:"region,omitempty" db:"region"`
+	PostalCode string    `json:"postal_code,omitempty" db:"postal_code"`
+	Country    string    `json:"country" db:"country"`
+	IsPrimary  bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Contact phone number of a user, unrelated to the verified login phone
+type PhoneNumber struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Label     string    `json:"label,omitempty" db:"label"`
+	Number    string    `json:"number" db:"number"`
+	IsPrimary bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Profile link of a user, one per network
+type SocialLink struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Network   string    `json:"network" db:"network"`
+	URL       string    `json:"url" db:"url"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// All contact sub-resources of a user
+type UserContacts struct {
+	Addresses   []*Address     `json:"addresses"`
+	Phones      []*PhoneNumber `json:"phones"`
+	SocialLinks []*SocialLink  `json:"social_links"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// Email domain rules of registration, config and admin managed entries merged
+type EmailDomainRules struct {
+	Allow           []string `json:"allow"`
+	Deny            []string `json:"deny"`
+	BlockDisposable bool     `json:"block_disposable"`
+	CheckMX         bool     `json:"check_mx"`
+}
+
+// Outcome of checking an email address against the domain policy
+type EmailDomainDecision struct {
+	Allowed bool   `json:"allowed"`
+	Domain  string `json:"domain,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+}
+
+// Admin managed email domain rule
+type EmailDomainRule struct {
+	Domain string `json:"domain" validate:"required,fqdn"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	// Uploaded to the quarantine bucket, waiting for the scanner
+	FileStatusPending = "pending"
+	// Scanned clean and promoted to the files bucket
+	FileStatusClean = "clean"
+	// Scanner found malware, object removed
+	FileStatusInfected = "infected"
+	// Scanning kept failing, object left in quarantine
+	FileStatusFailed = "failed"
+)
+
+// Uploaded file record, clean files with the same content checksum share one stored blob
+type File struct {
+	ID             int64     `json:"id" db:"id"`
+	OwnerID        *int      `json:"owner_id,omitempty" db:"owner_id"`
+	GuestID        *string   `json:"-" db:"guest_id"`
+	Name           string    `json:"name" db:"name"`
+	ContentType    string    `json:"content_type" db:"content_type"`
+	Size           int64     `json:"size" db:"size"`
+	Bucket         string    `json:"-" db:"bucket"`
+	ObjectKey      string    `json:"-" db:"object_key"`
+	Status         string    `json:"status" db:"status"`
+	ScanResult     *string   `json:"scan_result,omitempty" db:"scan_result"`
+	ChecksumSHA256 *string   `json:"checksum_sha256,omitempty" db:"checksum_sha256"`
+	BlobID         *int64    `json:"-" db:"blob_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Stored object shared by every clean file with the same content, removed when no file references it
+type FileBlob struct {
+	ID             int64     `json:"id" db:"id"`
+	ChecksumSHA256 string    `json:"checksum_sha256" db:"checksum_sha256"`
+	Bucket         string    `json:"bucket" db:"bucket"`
+	ObjectKey      string    `json:"object_key" db:"object_key"`
+	Size           int64     `json:"size" db:"size"`
+	RefCount       int       `json:"ref_count" db:"ref_count"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
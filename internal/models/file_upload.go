@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	// Initiated, parts are being uploaded through presigned URLs
+	FileUploadStatusUploading = "uploading"
+	// Parts assembled, checksum verified and file record created
+	FileUploadStatusCompleted = "completed"
+	// Aborted by the owner or expired before completion
+	FileUploadStatusAborted = "aborted"
+	// Assembled object did not match the declared checksum
+	FileUploadStatusFailed = "failed"
+)
+
+// Multipart upload of a large file, tracked until it is completed or aborted
+type FileUpload struct {
+	ID             string    `json:"id" db:"id"`
+	UploadID       string    `json:"-" db:"upload_id"`
+	OwnerID        *int      `json:"owner_id,omitempty" db:"owner_id"`
+	GuestID        *string   `json:"-" db:"guest_id"`
+	Name           string    `json:"name" db:"name"`
+	ContentType    string    `json:"content_type" db:"content_type"`
+	Size           int64     `json:"size" db:"size"`
+	PartSize       int64     `json:"part_size" db:"part_size"`
+	PartCount      int       `json:"part_count" db:"part_count"`
+	Bucket         string    `json:"-" db:"bucket"`
+	ObjectKey      string    `json:"-" db:"object_key"`
+	ChecksumSHA256 string    `json:"checksum_sha256" db:"checksum_sha256"`
+	Status         string    `json:"status" db:"status"`
+	FileID         *int64    `json:"file_id,omitempty" db:"file_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// Multipart upload initiation request, checksum is the hex SHA-256 of the whole file
+type MultipartUploadRequest struct {
+	Name           string `json:"name" validate:"required,lte=255"`
+	ContentType    string `json:"content_type" validate:"omitempty,lte=255"`
+	Size           int64  `json:"size" validate:"required,gt=0"`
+	ChecksumSHA256 string `json:"checksum_sha256" validate:"required,len=64,hexadecimal"`
+}
+
+// Presigned URL for uploading one part with PUT, the ETag response header must be kept by the client
+type PresignedPart struct {
+	PartNumber int       `json:"part_number"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Streamed upload states
+const (
+	StreamStatusUploading = "uploading"
+	StreamStatusCompleted = "completed"
+	StreamStatusFailed    = "failed"
+)
+
+// Progress of a streamed upload, readable by its owner while the request body is still arriving
+type UploadProgress struct {
+	ID       string  `json:"id"`
+	OwnerID  *int    `json:"owner_id,omitempty"`
+	GuestID  *string `json:"guest_id,omitempty"`
+	Name     string  `json:"name"`
+	Received int64   `json:"received"`
+	// -1 when the client did not send the length
+	Size      int64     `json:"size"`
+	Status    string    `json:"status"`
+	FileID    *int64    `json:"file_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Outcome of one pass over the HR feed, Mismatches lists every field where the HR record and
+// the local user disagreed together with how the conflict was resolved
+type HRSyncReport struct {
+	ID          string           `json:"id"`
+	Trigger     string           `json:"trigger"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+	FromCursor  string           `json:"from_cursor"`
+	ToCursor    string           `json:"to_cursor"`
+	Fetched     int              `json:"fetched"`
+	Created     int              `json:"created"`
+	Updated     int              `json:"updated"`
+	Unchanged   int              `json:"unchanged"`
+	Skipped     int              `json:"skipped"`
+	Failed      int              `json:"failed"`
+	Mismatches  []HRSyncMismatch `json:"mismatches"`
+	Errors      []HRSyncError    `json:"errors,omitempty"`
+	Interrupted bool             `json:"interrupted,omitempty"`
+}
+
+// Field of a user differing between HR and the local users table
+type HRSyncMismatch struct {
+	ExternalID string `json:"external_id"`
+	UserID     int    `json:"user_id"`
+	Field      string `json:"field"`
+	Local      string `json:"local"`
+	External   string `json:"external"`
+	Resolution string `json:"resolution"`
+}
+
+// HR record that could not be applied
+type HRSyncError struct {
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
+
+// Recent sync reports, newest first
+type HRSyncReportsList struct {
+	Reports []*HRSyncReport `json:"reports"`
+}
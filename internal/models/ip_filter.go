@@ -0,0 +1,20 @@
+package models
+
+// Allow and deny rules of a route group, config and admin managed entries merged
+type IPFilterRules struct {
+	Group string   `json:"group"`
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Outcome of checking a client ip against a route group
+type IPFilterDecision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+}
+
+// Admin managed ip filter rule
+type IPFilterRule struct {
+	CIDR string `json:"cidr" validate:"required"`
+}
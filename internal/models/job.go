@@ -0,0 +1,25 @@
+package models
+
+import "github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+
+// Background jobs page of one state
+type JobsList struct {
+	State      string          `json:"state"`
+	TotalCount int             `json:"total_count"`
+	TotalPages int             `json:"total_pages"`
+	Page       int             `json:"page"`
+	Size       int             `json:"size"`
+	HasMore    bool            `json:"has_more"`
+	Jobs       []*jobqueue.Job `json:"jobs"`
+}
+
+// Outcome of a job cancellation, State is the state the job was in
+type JobCancelResult struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+// Outcome of a dead letter purge
+type JobPurgeResult struct {
+	Purged int64 `json:"purged"`
+}
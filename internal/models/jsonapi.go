@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jsonapi"
+)
+
+// JSON:API resource types
+const (
+	UsersResourceType         = "users"
+	RolesResourceType         = "roles"
+	OrganizationsResourceType = "organizations"
+)
+
+// Role as a JSON:API resource
+func (r *Role) Resource() *jsonapi.Resource {
+	return &jsonapi.Resource{
+		Identifier: jsonapi.Identifier{Type: RolesResourceType, ID: int64(r.ID)},
+		Attributes: r,
+	}
+}
+
+// Organization as a JSON:API resource related to its owner
+func (o *Organization) JSONAPI() *jsonapi.Payload {
+	return jsonapi.One(&jsonapi.Resource{
+		Identifier: jsonapi.Identifier{Type: OrganizationsResourceType, ID: o.ID},
+		Attributes: o,
+		Relationships: map[string]jsonapi.Identifier{
+			"owner": {Type: UsersResourceType, ID: int64(o.OwnerID)},
+		},
+	})
+}
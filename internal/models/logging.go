@@ -0,0 +1,9 @@
+package models
+
+import "github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+
+// Runtime log levels, modules without an override log at the configured default
+type LogLevels struct {
+	Default string               `json:"default"`
+	Modules []logger.ModuleLevel `json:"modules"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Roles of organization members, the owner role is held by the creator only
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization model, quotas are set by administrators and the rest by the organization owner or admins.
+// A zero quota is unlimited.
+type Organization struct {
+	ID                    int64     `json:"id" db:"id"`
+	OwnerID               int       `json:"owner_id" db:"owner_id"`
+	Name                  string    `json:"name" db:"name"`
+	BillingEmail          string    `json:"billing_email" db:"billing_email"`
+	AllowMemberInvites    bool      `json:"allow_member_invites" db:"allow_member_invites"`
+	MaxMembers            int       `json:"max_members" db:"max_members"`
+	MaxPendingInvitations int       `json:"max_pending_invitations" db:"max_pending_invitations"`
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Membership of a user in an organization
+type OrganizationMember struct {
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Username       string    `json:"username,omitempty" db:"username"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// Can member manage the organization, its members and invitations
+func (m *OrganizationMember) IsManager() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// Invitation of an email address into an organization, Token is only returned on creation
+type OrganizationInvitation struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	Token          string     `json:"token,omitempty"`
+	TokenHash      string     `json:"-"`
+	InvitedBy      int        `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OTP challenge purposes
+const (
+	OTPPurposePhoneVerify = "phone_verify"
+	OTPPurposeLogin       = "login"
+)
+
+// One time code challenge, only a hash of the code is stored
+type OTPChallenge struct {
+	ID        string    `json:"id"`
+	Purpose   string    `json:"purpose"`
+	UserID    int       `json:"user_id"`
+	Phone     string    `json:"phone"`
+	CodeHash  string    `json:"code_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// How the cohort of a password rotation campaign was picked
+const (
+	PasswordRotationSourceFilter = "filter"
+	PasswordRotationSourceList   = "list"
+	PasswordRotationSourceUpload = "upload"
+)
+
+// Cohort filter of a password rotation campaign, every set field has to match
+type PasswordRotationFilter struct {
+	RoleNames      []string   `json:"role_names,omitempty" validate:"omitempty,dive,required,lte=30"`
+	OrganizationID *int64     `json:"organization_id,omitempty" validate:"omitempty,gt=0"`
+	CreatedBefore  *time.Time `json:"created_before,omitempty"`
+	LoginBefore    *time.Time `json:"login_before,omitempty"`
+}
+
+// Empty filter would flag every user
+func (f *PasswordRotationFilter) Empty() bool {
+	return f == nil || (len(f.RoleNames) == 0 && f.OrganizationID == nil && f.CreatedBefore == nil && f.LoginBefore == nil)
+}
+
+// Password rotation campaign with its progress, a cancelled campaign requires nothing anymore
+type PasswordRotationCampaign struct {
+	ID          int64           `json:"id" db:"id"`
+	Name        string          `json:"name" db:"name"`
+	Reason      string          `json:"reason,omitempty" db:"reason"`
+	Source      string          `json:"source" db:"source"`
+	Filter      json.RawMessage `json:"filter,omitempty" db:"-"`
+	CreatedBy   *int            `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	CancelledAt *time.Time      `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	Flagged     int             `json:"flagged" db:"flagged"`
+	Rotated     int             `json:"rotated" db:"rotated"`
+	// Uploaded entries which matched no user, set on creation only
+	Unmatched []string `json:"unmatched,omitempty" db:"-"`
+}
+
+// User flagged by a campaign
+type PasswordRotationUser struct {
+	UserID   int    `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+}
+
+// Progress of a campaign, PendingUserIDs lists the first users still to rotate
+type PasswordRotationReport struct {
+	*PasswordRotationCampaign
+	Pending        int        `json:"pending"`
+	CompletionRate float64    `json:"completion_rate"`
+	LastRotatedAt  *time.Time `json:"last_rotated_at,omitempty"`
+	PendingUserIDs []int      `json:"pending_user_ids"`
+}
+
+// Set at login while a campaign requires a new password, every other request is refused until then
+type PasswordRotationRequired struct {
+	ChangeMethod string `json:"change_method"`
+	ChangePath   string `json:"change_path"`
+}
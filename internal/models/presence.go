@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Presence change statuses
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+)
+
+// Presence of a user, LastSeenAt is missing for users never seen online
+type Presence struct {
+	UserID     int        `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// Presences in the order they were asked for
+type PresenceList struct {
+	Presences []*Presence `json:"presences"`
+}
+
+// Presence change published on the presence channel
+type PresenceEvent struct {
+	UserID int       `json:"user_id"`
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// Ways the caller of a request authenticated
+const (
+	PrincipalSourceSession = "session"
+	PrincipalSourceJWT     = "jwt"
+	PrincipalSourceIssuer  = "issuer"
+	PrincipalSourceAPIKey  = "api_key"
+	PrincipalSourceScoped  = "scoped_token"
+	PrincipalSourceToken   = "personal_token"
+)
+
+// Caller of a request whichever credential it sent. Service principals of issuers and API keys carry a user
+// without id, named after the service and holding its role
+type Principal struct {
+	User   *UserWithRole `json:"user"`
+	Source string        `json:"source"`
+	// Session of cookie auth
+	SessionID string `json:"session_id,omitempty"`
+	// Trusted issuer of an issuer token
+	Issuer string `json:"issuer,omitempty"`
+	// Name of the API key
+	APIKey string `json:"api_key,omitempty"`
+	// Scopes of a scoped token or a personal access token
+	Scope string `json:"scope,omitempty"`
+	// Id of the personal access token
+	TokenID int64 `json:"token_id,omitempty"`
+}
+
+// Whether the principal stands for a local user rather than a service
+func (p *Principal) IsUser() bool {
+	return p != nil && p.User != nil && p.User.User.ID != 0
+}
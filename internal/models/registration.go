@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Invitation to register, issued by administrators. RoleName replaces the default role and OrganizationID
+// adds the new user to an organization with OrganizationRole. Token is only returned on creation.
+type RegistrationInvitation struct {
+	ID               int64      `json:"id" db:"id"`
+	Token            string     `json:"token,omitempty" db:"-"`
+	TokenHash        string     `json:"-" db:"token_hash"`
+	RoleName         string     `json:"role_name,omitempty" db:"role_name"`
+	OrganizationID   *int64     `json:"organization_id,omitempty" db:"organization_id"`
+	OrganizationRole string     `json:"organization_role,omitempty" db:"organization_role"`
+	MaxUses          int        `json:"max_uses" db:"max_uses"`
+	Uses             int        `json:"uses" db:"uses"`
+	CreatedBy        *int       `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt        time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Can the invitation still be used at now
+func (i *RegistrationInvitation) Usable(now time.Time) bool {
+	return i.RevokedAt == nil && i.Uses < i.MaxUses && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Device remembered by a series, as listed to its user
+type RememberDevice struct {
+	Series     string    `json:"series"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Remember-me series of one device. Only hashes of its tokens are stored, the previous one is accepted until
+// RotatedAt plus the grace period
+type RememberSeries struct {
+	RememberDevice
+	UserID       int        `json:"user_id"`
+	TokenHash    string     `json:"token_hash"`
+	PreviousHash string     `json:"previous_hash,omitempty"`
+	RotatedAt    *time.Time `json:"rotated_at,omitempty"`
+}
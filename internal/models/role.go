@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"time"
 )
 
 type Role struct {
@@ -19,3 +20,20 @@ type RolesList struct {
 	HasMore    bool    `json:"has_more"`
 	Roles      []*Role `json:"roles"`
 }
+
+// Role granted to a user on top of their own role until ExpiresAt
+type RoleGrant struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Role      string     `json:"role" db:"role"`
+	GrantedBy *int       `json:"granted_by,omitempty" db:"granted_by"`
+	Reason    string     `json:"reason" db:"reason"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Whether the grant still adds its role at now
+func (g *RoleGrant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && now.Before(g.ExpiresAt)
+}
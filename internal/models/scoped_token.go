@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Scopes a session can be exchanged for, each unlocks read access to one resource
+const (
+	ScopeReadProfile  = "read:profile"
+	ScopeReadContacts = "read:contacts"
+)
+
+// Short lived token limited to Scope, space separated like the OAuth scope parameter
+type ScopedToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,7 +1,87 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Session model
 type Session struct {
-	SessionID string `json:"session_id" redis:"session_id"`
-	UserID    int    `json:"user_id" redis:"user_id"`
+	SessionID string    `json:"session_id" redis:"session_id"`
+	UserID    int       `json:"user_id" redis:"user_id"`
+	IPAddress string    `json:"ip_address,omitempty" redis:"ip_address"`
+	UserAgent string    `json:"user_agent,omitempty" redis:"user_agent"`
+	TenantID  string    `json:"tenant_id,omitempty" redis:"tenant_id"`
+	CreatedAt time.Time `json:"created_at,omitempty" redis:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty" redis:"expires_at"`
+	// Guest sessions carry no user, data they create is keyed by GuestID until merged into an account
+	Guest   bool   `json:"guest,omitempty" redis:"guest"`
+	GuestID string `json:"guest_id,omitempty" redis:"guest_id"`
+	// Last time the user proved their credentials, gates sensitive actions
+	LastAuthenticatedAt time.Time `json:"last_authenticated_at,omitempty" redis:"last_authenticated_at"`
+	// Small per-session state by key, written through the session usecase within the configured size caps
+	Data map[string]json.RawMessage `json:"data,omitempty" redis:"data"`
+}
+
+// Impersonation in progress, kept in session data while an administrator acts as the session user
+type SessionImpersonation struct {
+	ImpersonatorID int       `json:"impersonator_id"`
+	Reason         string    `json:"reason,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// Bulk session revocation criteria, every non empty field must match
+type SessionRevokeCriteria struct {
+	UserIDs       []int      `json:"user_ids,omitempty"`
+	IPRange       string     `json:"ip_range,omitempty" validate:"omitempty,cidr"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	TenantID      string     `json:"tenant_id,omitempty"`
+	DryRun        bool       `json:"dry_run"`
+}
+
+// Bulk session revocation result, Revoked stays zero on dry run
+type SessionRevokeResult struct {
+	Matched int  `json:"matched"`
+	Revoked int  `json:"revoked"`
+	DryRun  bool `json:"dry_run"`
+	// Revoked sessions, recorded in the session event stream
+	Sessions []*Session `json:"-"`
+}
+
+// Session lifecycle event types
+const (
+	SessionEventCreated         = "created"
+	SessionEventRefreshed       = "refreshed"
+	SessionEventRevoked         = "revoked"
+	SessionEventExpired         = "expired"
+	SessionEventHijackSuspected = "hijack_suspected"
+)
+
+// Reasons of revoked and hijack_suspected session events
+const (
+	SessionReasonLogout           = "logout"
+	SessionReasonEvicted          = "evicted"
+	SessionReasonAdmin            = "admin"
+	SessionReasonUserAgentChanged = "user_agent_changed"
+)
+
+// Session lifecycle event, appended to the session event stream and never changed.
+// ID is the stream entry id, ordered by time, and doubles as the paging cursor
+type SessionEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	SessionID string    `json:"session_id"`
+	UserID    int       `json:"user_id,omitempty"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Page of session events newest first, NextCursor is passed as before to fetch older events
+type SessionEvents struct {
+	Events     []*SessionEvent `json:"events"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	HasMore    bool            `json:"has_more"`
 }
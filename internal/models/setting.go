@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Keys of the runtime settings
+const (
+	SettingAnnouncementBanner  = "announcement_banner"
+	SettingRegistrationEnabled = "registration_enabled"
+	SettingInviteOnly          = "invite_only"
+	SettingDefaultRole         = "default_role"
+)
+
+// Stored runtime setting, Value is the JSON encoded value of the key
+type Setting struct {
+	Key       string          `json:"key" db:"key"`
+	Value     json.RawMessage `json:"value" db:"value"`
+	UpdatedBy *int            `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Typed view of the runtime settings, keys never stored keep their default
+type Settings struct {
+	AnnouncementBanner  string `json:"announcement_banner"`
+	RegistrationEnabled bool   `json:"registration_enabled"`
+	InviteOnly          bool   `json:"invite_only"`
+	DefaultRole         string `json:"default_role"`
+}
+
+// Settings before any administrator changed them, InviteOnly defaults to the registration config
+func DefaultSettings(inviteOnly bool) Settings {
+	return Settings{RegistrationEnabled: true, InviteOnly: inviteOnly, DefaultRole: "employee"}
+}
+
+// Settings anyone may read, shown by clients before sign in
+type PublicSettings struct {
+	AnnouncementBanner  string `json:"announcement_banner"`
+	RegistrationEnabled bool   `json:"registration_enabled"`
+	InviteOnly          bool   `json:"invite_only"`
+}
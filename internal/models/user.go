@@ -16,13 +16,33 @@ type User struct {
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at" redis:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at" redis:"updated_at"`
 	LoginDate time.Time `json:"login_at" db:"login_at" redis:"login_at"`
+	Timezone  string    `json:"timezone,omitempty" db:"timezone" redis:"timezone" validate:"omitempty,timezone"`
+	Phone     string    `json:"phone,omitempty" db:"phone" redis:"phone" validate:"omitempty,e164"`
+	// Set by SMS code verification only, changing the phone clears both
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty" db:"phone_verified_at" redis:"phone_verified_at"`
+	SMS2FA          bool       `json:"sms_2fa_enabled" db:"sms_2fa_enabled" redis:"sms_2fa_enabled"`
+	// Active, or pending_deletion between a deletion request and the scheduled purge
+	Status              string     `json:"status,omitempty" db:"-" redis:"status"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty" db:"deletion_requested_at" redis:"deletion_requested_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" db:"deletion_scheduled_at" redis:"deletion_scheduled_at"`
 }
 
+// User account states
+const (
+	UserStatusActive          = "active"
+	UserStatusPendingDeletion = "pending_deletion"
+)
+
 type UserWithRole struct {
 	User User `json:"user" db:"user"`
 	Role Role `json:"role" db:"role"`
 }
 
+// Is account waiting for its scheduled deletion
+func (u *User) PendingDeletion() bool {
+	return u.DeletionScheduledAt != nil
+}
+
 // Hash user password with bcrypt
 func (u *User) HashPassword() error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
@@ -67,16 +87,37 @@ func (u *User) PrepareUpdate() error {
 
 // All Users response
 type UsersList struct {
-	TotalCount int     `json:"total_count"`
-	TotalPages int     `json:"total_pages"`
-	Page       int     `json:"page"`
-	Size       int     `json:"size"`
-	HasMore    bool    `json:"has_more"`
-	Users      []*User `json:"users"`
+	TotalCount int  `json:"total_count"`
+	TotalPages int  `json:"total_pages"`
+	Page       int  `json:"page"`
+	Size       int  `json:"size"`
+	HasMore    bool `json:"has_more"`
+	// exact, estimated or none, totals are zero with none
+	CountStrategy string  `json:"count_strategy"`
+	Users         []*User `json:"users"`
+}
+
+// Cached users list page, CachedAt decides whether it is fresh or stale
+type CachedUsersList struct {
+	List     *UsersList `json:"list"`
+	CachedAt time.Time  `json:"cached_at"`
 }
 
 // Find user query
 type UserWithToken struct {
 	User  *User  `json:"user"`
 	Token string `json:"token"`
+	// Set at login while the account waits for deletion, the user may still cancel it
+	PendingDeletion *PendingDeletion `json:"pending_deletion,omitempty"`
+	// Set at login while the user has to change their password first
+	PasswordRotation *PasswordRotationRequired `json:"password_rotation,omitempty"`
+	// Permissions and feature flags of the user, set at login
+	Access *Access `json:"access,omitempty"`
+}
+
+// Scheduled deletion and how to cancel it
+type PendingDeletion struct {
+	ScheduledAt  time.Time `json:"scheduled_at"`
+	CancelMethod string    `json:"cancel_method"`
+	CancelPath   string    `json:"cancel_path"`
 }
@@ -0,0 +1,36 @@
+package models
+
+// Operations of an admin batch write on users
+const (
+	UserBatchCreate = "create"
+	UserBatchUpdate = "update"
+	UserBatchDelete = "delete"
+)
+
+// Admin batch write on users, each operation is validated and executed on its own
+type UserBatchRequest struct {
+	Operations []UserBatchOperation `json:"operations" validate:"required,min=1"`
+}
+
+// One operation of a batch, create uses username, email and password of User,
+// update the non empty fields of User and delete only its id
+type UserBatchOperation struct {
+	Op   string `json:"op"`
+	User User   `json:"user" validate:"-"`
+}
+
+// Outcome of one operation, Status is the HTTP status the single request would have returned
+type UserBatchItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	User   *User  `json:"user,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Outcomes of a batch in request order
+type UserBatchResult struct {
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []UserBatchItemResult `json:"results"`
+}
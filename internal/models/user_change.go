@@ -0,0 +1,22 @@
+package models
+
+// Kinds of user changes returned by the differential sync
+const (
+	UserChangeCreated = "created"
+	UserChangeUpdated = "updated"
+	UserChangeDeleted = "deleted"
+)
+
+// Latest state of a user changed since the sync cursor, User is empty for deletions
+type UserChange struct {
+	ID   int    `json:"id"`
+	Op   string `json:"op"`
+	User *User  `json:"user,omitempty"`
+}
+
+// Page of user changes, NextCursor is passed as since to fetch the following page
+type UserChanges struct {
+	Changes    []*UserChange `json:"changes"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Account events users can subscribe to
+const (
+	WebhookEventLoginNewDevice   = "login.new_device"
+	WebhookEventLoginSuspicious  = "login.suspicious"
+	WebhookEventProfileUpdated   = "profile.updated"
+	WebhookEventRoleGrantExpired = "role_grant.expired"
+)
+
+// Callback registered by a user for events on their own account, Secret is only returned on creation
+type Webhook struct {
+	ID        int64     `json:"id"`
+	UserID    int       `json:"user_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Is webhook subscribed to event
+func (w *Webhook) Subscribed(event string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Body posted to a webhook, ID stays the same across retries so receivers can deduplicate
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	UserID    int             `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery attempt of an event to a webhook
+type WebhookDelivery struct {
+	ID         int64     `json:"id" db:"id"`
+	WebhookID  int64     `json:"webhook_id" db:"webhook_id"`
+	UserID     int       `json:"-" db:"user_id"`
+	EventID    string    `json:"event_id" db:"event_id"`
+	Event      string    `json:"event" db:"event"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	StatusCode int       `json:"status_code" db:"status_code"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	Succeeded  bool      `json:"succeeded" db:"succeeded"`
+	DurationMs int       `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Recent delivery attempts of a webhook, newest first
+type WebhookDeliveriesList struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,20 @@
+package organizations
+
+import "github.com/labstack/echo/v4"
+
+// Organizations HTTP Handlers interface
+type Handlers interface {
+	Create() echo.HandlerFunc
+	GetMine() echo.HandlerFunc
+	GetByID() echo.HandlerFunc
+	Update() echo.HandlerFunc
+	UpdateQuotas() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	ListMembers() echo.HandlerFunc
+	UpdateMemberRole() echo.HandlerFunc
+	RemoveMember() echo.HandlerFunc
+	CreateInvitation() echo.HandlerFunc
+	ListInvitations() echo.HandlerFunc
+	RevokeInvitation() echo.HandlerFunc
+	AcceptInvitation() echo.HandlerFunc
+}
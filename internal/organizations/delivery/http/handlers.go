@@ -0,0 +1,426 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Organizations handlers
+type organizationsHandlers struct {
+	cfg            *config.Config
+	organizationUC organizations.UseCase
+	logger         logger.Logger
+}
+
+// NewOrganizationsHandlers Organizations handlers constructor
+func NewOrganizationsHandlers(cfg *config.Config, organizationUC organizations.UseCase, log logger.Logger) organizations.Handlers {
+	return &organizationsHandlers{cfg: cfg, organizationUC: organizationUC, logger: log}
+}
+
+// Create godoc
+// @Summary Create organization
+// @Description Create organization owned by the current user, who must not belong to one yet
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param body body dto.OrganizationRequest true "name and settings"
+// @Success 201 {object} models.Organization
+// @Failure 409 {object} httpErrors.RestError
+// @Router /organizations [post]
+func (h *organizationsHandlers) Create() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.Create")
+		defer span.Finish()
+
+		req := &dto.OrganizationRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.organizationUC.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// GetMine godoc
+// @Summary Get my organization
+// @Description Get organization of the current user
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} models.Organization
+// @Failure 404 {object} httpErrors.RestError
+// @Router /organizations/me [get]
+func (h *organizationsHandlers) GetMine() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.GetMine")
+		defer span.Finish()
+
+		organization, err := h.organizationUC.GetMine(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, organization)
+	}
+}
+
+// GetByID godoc
+// @Summary Get organization
+// @Description Get organization by id, members and administrators only
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "organization_id"
+// @Success 200 {object} models.Organization
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id} [get]
+func (h *organizationsHandlers) GetByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.GetByID")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		organization, err := h.organizationUC.GetByID(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, organization)
+	}
+}
+
+// Update godoc
+// @Summary Update organization
+// @Description Update name and settings, owner and admins only
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "organization_id"
+// @Param body body dto.OrganizationRequest true "name and settings"
+// @Success 200 {object} models.Organization
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id} [put]
+func (h *organizationsHandlers) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.Update")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.OrganizationRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		updated, err := h.organizationUC.Update(ctx, organizationID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// UpdateQuotas godoc
+// @Summary Set organization quotas
+// @Description Set member and pending invitation quotas, administrators only. Zero is unlimited
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "organization_id"
+// @Param body body dto.OrganizationQuotasRequest true "quotas"
+// @Success 200 {object} models.Organization
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id}/quotas [put]
+func (h *organizationsHandlers) UpdateQuotas() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.UpdateQuotas")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.OrganizationQuotasRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		updated, err := h.organizationUC.UpdateQuotas(ctx, organizationID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
+
+// Delete godoc
+// @Summary Delete organization
+// @Description Delete organization with its memberships and invitations, owner only
+// @Tags Organizations
+// @Param id path int true "organization_id"
+// @Success 204
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id} [delete]
+func (h *organizationsHandlers) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.Delete")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.organizationUC.Delete(ctx, organizationID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Description List members with their roles, members only
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "organization_id"
+// @Success 200 {array} models.OrganizationMember
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id}/members [get]
+func (h *organizationsHandlers) ListMembers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.ListMembers")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		members, err := h.organizationUC.ListMembers(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, members)
+	}
+}
+
+// UpdateMemberRole godoc
+// @Summary Change member role
+// @Description Make a member an admin or back, owner only
+// @Tags Organizations
+// @Accept json
+// @Param id path int true "organization_id"
+// @Param user_id path int true "user_id"
+// @Param body body dto.OrganizationMemberRequest true "role"
+// @Success 204
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id}/members/{user_id} [put]
+func (h *organizationsHandlers) UpdateMemberRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.UpdateMemberRole")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.OrganizationMemberRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		if err := h.organizationUC.UpdateMemberRole(ctx, organizationID, userID, req); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// RemoveMember godoc
+// @Summary Remove member
+// @Description Remove a member or leave the organization, the owner can't leave
+// @Tags Organizations
+// @Param id path int true "organization_id"
+// @Param user_id path int true "user_id"
+// @Success 204
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (h *organizationsHandlers) RemoveMember() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.RemoveMember")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.organizationUC.RemoveMember(ctx, organizationID, userID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Invite into organization
+// @Description Invite an email as admin or member, the token is only returned here
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "organization_id"
+// @Param body body dto.OrganizationInvitationRequest true "email and role"
+// @Success 201 {object} models.OrganizationInvitation
+// @Failure 409 {object} httpErrors.RestError
+// @Router /organizations/{id}/invitations [post]
+func (h *organizationsHandlers) CreateInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.CreateInvitation")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		req := &dto.OrganizationInvitationRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.organizationUC.CreateInvitation(ctx, organizationID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// ListInvitations godoc
+// @Summary List pending invitations
+// @Description List invitations neither accepted nor expired, owner and admins only
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "organization_id"
+// @Success 200 {array} models.OrganizationInvitation
+// @Failure 403 {object} httpErrors.RestError
+// @Router /organizations/{id}/invitations [get]
+func (h *organizationsHandlers) ListInvitations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.ListInvitations")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		invitations, err := h.organizationUC.ListInvitations(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, invitations)
+	}
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revoke a pending invitation, owner and admins only
+// @Tags Organizations
+// @Param id path int true "organization_id"
+// @Param invitation_id path int true "invitation_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /organizations/{id}/invitations/{invitation_id} [delete]
+func (h *organizationsHandlers) RevokeInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.RevokeInvitation")
+		defer span.Finish()
+
+		organizationID, err := strconv.ParseInt(c.Param("organization_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		invitationID, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.organizationUC.RevokeInvitation(ctx, organizationID, invitationID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Join the organization of an invitation sent to the current user's email
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param body body dto.AcceptInvitationRequest true "invitation token"
+// @Success 200 {object} models.OrganizationMember
+// @Failure 410 {object} httpErrors.RestError
+// @Router /organizations/invitations/accept [post]
+func (h *organizationsHandlers) AcceptInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "organizationsHandlers.AcceptInvitation")
+		defer span.Finish()
+
+		req := &dto.AcceptInvitationRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		membership, err := h.organizationUC.AcceptInvitation(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, membership)
+	}
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+)
+
+// Map organizations routes
+func MapOrganizationsRoutes(organizationsGroup *echo.Group, h organizations.Handlers, mw *middleware.MiddlewareManager) {
+	organizationsGroup.Use(mw.AuthSessionMiddleware)
+
+	organizationsGroup.POST("", h.Create(), mw.CSRF)
+	organizationsGroup.GET("/me", h.GetMine())
+	organizationsGroup.POST("/invitations/accept", h.AcceptInvitation(), mw.CSRF)
+	organizationsGroup.GET("/:organization_id", h.GetByID())
+	organizationsGroup.PUT("/:organization_id", h.Update(), mw.CSRF)
+	organizationsGroup.PUT("/:organization_id/quotas", h.UpdateQuotas(), mw.AdminMiddleware, mw.CSRF)
+	organizationsGroup.DELETE("/:organization_id", h.Delete(), mw.CSRF, mw.StepUp)
+	organizationsGroup.GET("/:organization_id/members", h.ListMembers())
+	organizationsGroup.PUT("/:organization_id/members/:user_id", h.UpdateMemberRole(), mw.CSRF)
+	organizationsGroup.DELETE("/:organization_id/members/:user_id", h.RemoveMember(), mw.CSRF)
+	organizationsGroup.GET("/:organization_id/invitations", h.ListInvitations())
+	organizationsGroup.POST("/:organization_id/invitations", h.CreateInvitation(), mw.CSRF)
+	organizationsGroup.DELETE("/:organization_id/invitations/:invitation_id", h.RevokeInvitation(), mw.CSRF)
+}
@@ -0,0 +1,26 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package organizations
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Organizations Repository
+type Repository interface {
+	Create(ctx context.Context, organization *models.Organization) (*models.Organization, error)
+	GetByID(ctx context.Context, organizationID int64) (*models.Organization, error)
+	Update(ctx context.Context, organization *models.Organization) (*models.Organization, error)
+	Delete(ctx context.Context, organizationID int64) error
+	GetMembership(ctx context.Context, userID int) (*models.OrganizationMember, error)
+	ListMembers(ctx context.Context, organizationID int64) ([]*models.OrganizationMember, error)
+	AddMember(ctx context.Context, organizationID int64, userID int, role string) error
+	UpdateMemberRole(ctx context.Context, organizationID int64, userID int, role string) error
+	RemoveMember(ctx context.Context, organizationID int64, userID int) error
+	CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) (*models.OrganizationInvitation, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error)
+	ListPendingInvitations(ctx context.Context, organizationID int64) ([]*models.OrganizationInvitation, error)
+	DeleteInvitation(ctx context.Context, organizationID int64, invitationID int64) error
+	AcceptInvitation(ctx context.Context, invitation *models.OrganizationInvitation, userID int) error
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+)
+
+// Organizations Repository kept in process memory, dev mode stand-in for Postgres
+type organizationsMemoryRepo struct {
+	mu               sync.RWMutex
+	lastID           int64
+	lastInvitationID int64
+	organizations    map[int64]models.Organization
+	members          map[int]models.OrganizationMember
+	invitations      map[int64]models.OrganizationInvitation
+}
+
+// Organizations in-memory Repository constructor
+func NewOrganizationsMemoryRepository() organizations.Repository {
+	return &organizationsMemoryRepo{
+		organizations: make(map[int64]models.Organization),
+		members:       make(map[int]models.OrganizationMember),
+		invitations:   make(map[int64]models.OrganizationInvitation),
+	}
+}
+
+// Create organization with its owner as the first member
+func (r *organizationsMemoryRepo) Create(ctx context.Context, organization *models.Organization) (*models.Organization, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.Create")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[organization.OwnerID]; ok {
+		return nil, errors.New("organizationsMemoryRepo.Create: owner is a member of another organization")
+	}
+
+	r.lastID++
+	now := time.Now()
+	created := *organization
+	created.ID = r.lastID
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	r.organizations[created.ID] = created
+	r.members[created.OwnerID] = models.OrganizationMember{
+		OrganizationID: created.ID,
+		UserID:         created.OwnerID,
+		Role:           models.OrganizationRoleOwner,
+		CreatedAt:      now,
+	}
+	return &created, nil
+}
+
+// Get organization by id
+func (r *organizationsMemoryRepo) GetByID(ctx context.Context, organizationID int64) (*models.Organization, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.GetByID")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	organization, ok := r.organizations[organizationID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.GetByID")
+	}
+	return &organization, nil
+}
+
+// Update settings and quotas of organization
+func (r *organizationsMemoryRepo) Update(ctx context.Context, organization *models.Organization) (*models.Organization, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.Update")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.organizations[organization.ID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.Update")
+	}
+	updated.Name = organization.Name
+	updated.BillingEmail = organization.BillingEmail
+	updated.AllowMemberInvites = organization.AllowMemberInvites
+	updated.MaxMembers = organization.MaxMembers
+	updated.MaxPendingInvitations = organization.MaxPendingInvitations
+	updated.UpdatedAt = time.Now()
+	r.organizations[updated.ID] = updated
+	return &updated, nil
+}
+
+// Delete organization by id, memberships and invitations go with it
+func (r *organizationsMemoryRepo) Delete(ctx context.Context, organizationID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.Delete")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.organizations[organizationID]; !ok {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.Delete")
+	}
+	delete(r.organizations, organizationID)
+	for userID, member := range r.members {
+		if member.OrganizationID == organizationID {
+			delete(r.members, userID)
+		}
+	}
+	for id, invitation := range r.invitations {
+		if invitation.OrganizationID == organizationID {
+			delete(r.invitations, id)
+		}
+	}
+	return nil
+}
+
+// Get organization membership of a user
+func (r *organizationsMemoryRepo) GetMembership(ctx context.Context, userID int) (*models.OrganizationMember, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.GetMembership")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[userID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.GetMembership")
+	}
+	return &member, nil
+}
+
+// List members of organization, oldest first
+func (r *organizationsMemoryRepo) ListMembers(ctx context.Context, organizationID int64) ([]*models.OrganizationMember, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.ListMembers")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]*models.OrganizationMember, 0)
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID {
+			member := member
+			members = append(members, &member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].CreatedAt.Before(members[j].CreatedAt)
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
+
+// Add user to organization
+func (r *organizationsMemoryRepo) AddMember(ctx context.Context, organizationID int64, userID int, role string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.AddMember")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.organizations[organizationID]; !ok {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.AddMember")
+	}
+	if _, ok := r.members[userID]; ok {
+		return errors.New("organizationsMemoryRepo.AddMember: user is a member of another organization")
+	}
+	r.members[userID] = models.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           role,
+		CreatedAt:      time.Now(),
+	}
+	return nil
+}
+
+// Change role of a member
+func (r *organizationsMemoryRepo) UpdateMemberRole(ctx context.Context, organizationID int64, userID int, role string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.UpdateMemberRole")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	member, ok := r.members[userID]
+	if !ok || member.OrganizationID != organizationID {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.UpdateMemberRole")
+	}
+	member.Role = role
+	r.members[userID] = member
+	return nil
+}
+
+// Remove member from organization
+func (r *organizationsMemoryRepo) RemoveMember(ctx context.Context, organizationID int64, userID int) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.RemoveMember")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	member, ok := r.members[userID]
+	if !ok || member.OrganizationID != organizationID {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.RemoveMember")
+	}
+	delete(r.members, userID)
+	return nil
+}
+
+// Create invitation, the token is not stored, only its hash
+func (r *organizationsMemoryRepo) CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) (*models.OrganizationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.CreateInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastInvitationID++
+	created := *invitation
+	created.ID = r.lastInvitationID
+	created.Token = ""
+	created.CreatedAt = time.Now()
+	r.invitations[created.ID] = created
+	return &created, nil
+}
+
+// Get invitation by the hash of its token
+func (r *organizationsMemoryRepo) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.GetInvitationByTokenHash")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.GetInvitationByTokenHash")
+}
+
+// List invitations of organization neither accepted nor expired
+func (r *organizationsMemoryRepo) ListPendingInvitations(ctx context.Context, organizationID int64) ([]*models.OrganizationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.ListPendingInvitations")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	invitations := make([]*models.OrganizationInvitation, 0)
+	for _, invitation := range r.invitations {
+		if invitation.OrganizationID == organizationID && invitation.AcceptedAt == nil && invitation.ExpiresAt.After(now) {
+			invitation := invitation
+			invitations = append(invitations, &invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID < invitations[j].ID })
+	return invitations, nil
+}
+
+// Delete invitation of organization
+func (r *organizationsMemoryRepo) DeleteInvitation(ctx context.Context, organizationID int64, invitationID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.DeleteInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[invitationID]
+	if !ok || invitation.OrganizationID != organizationID {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.DeleteInvitation")
+	}
+	delete(r.invitations, invitationID)
+	return nil
+}
+
+// Mark invitation accepted and add the user with its role
+func (r *organizationsMemoryRepo) AcceptInvitation(ctx context.Context, invitation *models.OrganizationInvitation, userID int) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "organizationsMemoryRepo.AcceptInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.invitations[invitation.ID]
+	if !ok || stored.AcceptedAt != nil {
+		return errors.Wrap(sql.ErrNoRows, "organizationsMemoryRepo.AcceptInvitation")
+	}
+	if _, ok := r.members[userID]; ok {
+		return errors.New("organizationsMemoryRepo.AcceptInvitation: user is a member of another organization")
+	}
+
+	now := time.Now()
+	stored.AcceptedAt = &now
+	r.invitations[stored.ID] = stored
+	r.members[userID] = models.OrganizationMember{
+		OrganizationID: stored.OrganizationID,
+		UserID:         userID,
+		Role:           stored.Role,
+		CreatedAt:      now,
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+)
+
+// Associated data of the encrypted invitation email
+const piiFieldInvitationEmail = "organization_invitations.email"
+
+// Stored invitation, the email is encrypted like user PII
+type invitationRow struct {
+	ID             int64      `db:"id"`
+	OrganizationID int64      `db:"organization_id"`
+	Email          string     `db:"email"`
+	Role           string     `db:"role"`
+	TokenHash      string     `db:"token_hash"`
+	InvitedBy      int        `db:"invited_by"`
+	ExpiresAt      time.Time  `db:"expires_at"`
+	AcceptedAt     *time.Time `db:"accepted_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// Organizations Repository
+type organizationsRepo struct {
+	db     *sqlx.DB
+	cipher *pii.Cipher
+}
+
+// Organizations Repository constructor
+func NewOrganizationsRepository(db *sqlx.DB, cipher *pii.Cipher) organizations.Repository {
+	return &organizationsRepo{db: db, cipher: cipher}
+}
+
+// Create organization with its owner as the first member
+func (r *organizationsRepo) Create(ctx context.Context, organization *models.Organization) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.Create")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.Create.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	created := &models.Organization{}
+	if err := tx.QueryRowxContext(
+		ctx,
+		createOrganizationQuery,
+		organization.OwnerID,
+		organization.Name,
+		organization.BillingEmail,
+		organization.AllowMemberInvites,
+		organization.MaxMembers,
+		organization.MaxPendingInvitations,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.Create.StructScan")
+	}
+	if _, err := tx.ExecContext(ctx, addMemberQuery, created.ID, created.OwnerID, models.OrganizationRoleOwner); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.Create.addMember")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.Create.Commit")
+	}
+	return created, nil
+}
+
+// Get organization by id
+func (r *organizationsRepo) GetByID(ctx context.Context, organizationID int64) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.GetByID")
+	defer span.Finish()
+
+	organization := &models.Organization{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, organization, getOrganizationByIDQuery, organizationID); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.GetByID.GetContext")
+	}
+	return organization, nil
+}
+
+// Update settings and quotas of organization
+func (r *organizationsRepo) Update(ctx context.Context, organization *models.Organization) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.Update")
+	defer span.Finish()
+
+	updated := &models.Organization{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		updateOrganizationQuery,
+		organization.ID,
+		organization.Name,
+		organization.BillingEmail,
+		organization.AllowMemberInvites,
+		organization.MaxMembers,
+		organization.MaxPendingInvitations,
+	).StructScan(updated); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.Update.StructScan")
+	}
+	return updated, nil
+}
+
+// Delete organization by id, memberships and invitations go with it
+func (r *organizationsRepo) Delete(ctx context.Context, organizationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.Delete")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, deleteOrganizationQuery, organizationID)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.Delete.ExecContext")
+	}
+	return checkAffected(result, "organizationsRepo.Delete")
+}
+
+// Get organization membership of a user
+func (r *organizationsRepo) GetMembership(ctx context.Context, userID int) (*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.GetMembership")
+	defer span.Finish()
+
+	member := &models.OrganizationMember{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, member, getMembershipQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.GetMembership.GetContext")
+	}
+	return member, nil
+}
+
+// List members of organization, oldest first
+func (r *organizationsRepo) ListMembers(ctx context.Context, organizationID int64) ([]*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.ListMembers")
+	defer span.Finish()
+
+	members := make([]*models.OrganizationMember, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &members, listMembersQuery, organizationID); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.ListMembers.SelectContext")
+	}
+	return members, nil
+}
+
+// Add user to organization
+func (r *organizationsRepo) AddMember(ctx context.Context, organizationID int64, userID int, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.AddMember")
+	defer span.Finish()
+
+	if _, err := tenant.DB(ctx, r.db).ExecContext(ctx, addMemberQuery, organizationID, userID, role); err != nil {
+		return errors.Wrap(err, "organizationsRepo.AddMember.ExecContext")
+	}
+	return nil
+}
+
+// Change role of a member
+func (r *organizationsRepo) UpdateMemberRole(ctx context.Context, organizationID int64, userID int, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.UpdateMemberRole")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, updateMemberRoleQuery, organizationID, userID, role)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.UpdateMemberRole.ExecContext")
+	}
+	return checkAffected(result, "organizationsRepo.UpdateMemberRole")
+}
+
+// Remove member from organization
+func (r *organizationsRepo) RemoveMember(ctx context.Context, organizationID int64, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.RemoveMember")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, removeMemberQuery, organizationID, userID)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.RemoveMember.ExecContext")
+	}
+	return checkAffected(result, "organizationsRepo.RemoveMember")
+}
+
+// Create invitation, the token is not stored, only its hash
+func (r *organizationsRepo) CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) (*models.OrganizationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.CreateInvitation")
+	defer span.Finish()
+
+	email, err := r.cipher.Encrypt(piiFieldInvitationEmail, invitation.Email)
+	if err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.CreateInvitation.Encrypt")
+	}
+
+	row := &invitationRow{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createInvitationQuery,
+		invitation.OrganizationID,
+		email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	).StructScan(row); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.CreateInvitation.StructScan")
+	}
+	created, err := r.toInvitation(row)
+	if err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.CreateInvitation.toInvitation")
+	}
+	return created, nil
+}
+
+// Get invitation by the hash of its token
+func (r *organizationsRepo) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.GetInvitationByTokenHash")
+	defer span.Finish()
+
+	row := &invitationRow{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, row, getInvitationByTokenHashQuery, tokenHash); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.GetInvitationByTokenHash.GetContext")
+	}
+	invitation, err := r.toInvitation(row)
+	if err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.GetInvitationByTokenHash.toInvitation")
+	}
+	return invitation, nil
+}
+
+// List invitations of organization neither accepted nor expired
+func (r *organizationsRepo) ListPendingInvitations(ctx context.Context, organizationID int64) ([]*models.OrganizationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.ListPendingInvitations")
+	defer span.Finish()
+
+	rows := make([]*invitationRow, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, listPendingInvitationsQuery, organizationID); err != nil {
+		return nil, errors.Wrap(err, "organizationsRepo.ListPendingInvitations.SelectContext")
+	}
+
+	invitations := make([]*models.OrganizationInvitation, 0, len(rows))
+	for _, row := range rows {
+		invitation, err := r.toInvitation(row)
+		if err != nil {
+			return nil, errors.Wrap(err, "organizationsRepo.ListPendingInvitations.toInvitation")
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, nil
+}
+
+// Delete invitation of organization
+func (r *organizationsRepo) DeleteInvitation(ctx context.Context, organizationID int64, invitationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.DeleteInvitation")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, deleteInvitationQuery, invitationID, organizationID)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.DeleteInvitation.ExecContext")
+	}
+	return checkAffected(result, "organizationsRepo.DeleteInvitation")
+}
+
+// Mark invitation accepted and add the user with its role in one transaction
+func (r *organizationsRepo) AcceptInvitation(ctx context.Context, invitation *models.OrganizationInvitation, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsRepo.AcceptInvitation")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.AcceptInvitation.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	result, err := tx.ExecContext(ctx, acceptInvitationQuery, invitation.ID)
+	if err != nil {
+		return errors.Wrap(err, "organizationsRepo.AcceptInvitation.ExecContext")
+	}
+	if err := checkAffected(result, "organizationsRepo.AcceptInvitation"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, addMemberQuery, invitation.OrganizationID, userID, invitation.Role); err != nil {
+		return errors.Wrap(err, "organizationsRepo.AcceptInvitation.addMember")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "organizationsRepo.AcceptInvitation.Commit")
+	}
+	return nil
+}
+
+func (r *organizationsRepo) toInvitation(row *invitationRow) (*models.OrganizationInvitation, error) {
+	email, err := r.cipher.Decrypt(piiFieldInvitationEmail, row.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OrganizationInvitation{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		Email:          email,
+		Role:           row.Role,
+		TokenHash:      row.TokenHash,
+		InvitedBy:      row.InvitedBy,
+		ExpiresAt:      row.ExpiresAt,
+		AcceptedAt:     row.AcceptedAt,
+		CreatedAt:      row.CreatedAt,
+	}, nil
+}
+
+func checkAffected(result sql.Result, op string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op+".RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, op+".rowsAffected")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+const (
+	createOrganizationQuery = `INSERT INTO organizations (owner_id, name, billing_email, allow_member_invites, max_members, max_pending_invitations, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, $5, $6, now(), now())
+						RETURNING id, owner_id, name, billing_email, allow_member_invites, max_members, max_pending_invitations, created_at, updated_at`
+
+	getOrganizationByIDQuery = `SELECT id, owner_id, name, billing_email, allow_member_invites, max_members, max_pending_invitations, created_at, updated_at
+						FROM organizations
+						WHERE id = $1`
+
+	updateOrganizationQuery = `UPDATE organizations
+						SET name = $2, billing_email = $3, allow_member_invites = $4, max_members = $5, max_pending_invitations = $6, updated_at = now()
+						WHERE id = $1
+						RETURNING id, owner_id, name, billing_email, allow_member_invites, max_members, max_pending_invitations, created_at, updated_at`
+
+	deleteOrganizationQuery = `DELETE FROM organizations WHERE id = $1`
+
+	addMemberQuery = `INSERT INTO organization_members (organization_id, user_id, role, created_at)
+						VALUES ($1, $2, $3, now())`
+
+	getMembershipQuery = `SELECT organization_id, user_id, role, created_at
+						FROM organization_members
+						WHERE user_id = $1`
+
+	listMembersQuery = `SELECT m.organization_id, m.user_id, u.username, m.role, m.created_at
+						FROM organization_members m
+						JOIN users u ON u.id = m.user_id
+						WHERE m.organization_id = $1
+						ORDER BY m.created_at, m.user_id`
+
+	updateMemberRoleQuery = `UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2`
+
+	removeMemberQuery = `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	createInvitationQuery = `INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, now())
+						RETURNING id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at`
+
+	getInvitationByTokenHashQuery = `SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+						FROM organization_invitations
+						WHERE token_hash = $1`
+
+	listPendingInvitationsQuery = `SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+						FROM organization_invitations
+						WHERE organization_id = $1 AND accepted_at IS NULL AND expires_at > now()
+						ORDER BY id`
+
+	deleteInvitationQuery = `DELETE FROM organization_invitations WHERE id = $1 AND organization_id = $2`
+
+	// Accepted at most once, a second accept of the same token affects no row
+	acceptInvitationQuery = `UPDATE organization_invitations SET accepted_at = now() WHERE id = $1 AND accepted_at IS NULL`
+)
@@ -0,0 +1,34 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package organizations
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Organizations use case
+type UseCase interface {
+	Create(ctx context.Context, req *dto.OrganizationRequest) (*models.Organization, error)
+	GetByID(ctx context.Context, organizationID int64) (*models.Organization, error)
+	GetMine(ctx context.Context) (*models.Organization, error)
+	Update(ctx context.Context, organizationID int64, req *dto.OrganizationRequest) (*models.Organization, error)
+	UpdateQuotas(ctx context.Context, organizationID int64, req *dto.OrganizationQuotasRequest) (*models.Organization, error)
+	// cache:emit=entitlements
+	Delete(ctx context.Context, organizationID int64) error
+	ListMembers(ctx context.Context, organizationID int64) ([]*models.OrganizationMember, error)
+	UpdateMemberRole(ctx context.Context, organizationID int64, userID int, req *dto.OrganizationMemberRequest) error
+	// cache:emit=members:{userID}
+	RemoveMember(ctx context.Context, organizationID int64, userID int) error
+	CreateInvitation(ctx context.Context, organizationID int64, req *dto.OrganizationInvitationRequest) (*models.OrganizationInvitation, error)
+	ListInvitations(ctx context.Context, organizationID int64) ([]*models.OrganizationInvitation, error)
+	RevokeInvitation(ctx context.Context, organizationID int64, invitationID int64) error
+	// cache:emit=entitlements
+	AcceptInvitation(ctx context.Context, req *dto.AcceptInvitationRequest) (*models.OrganizationMember, error)
+	GetMembership(ctx context.Context, userID int) (*models.OrganizationMember, error)
+	// Adds a user without an invitation, for flows the caller is not a manager of such as invited registrations.
+	// cache:emit=members:{userID}
+	AddMember(ctx context.Context, organizationID int64, userID int, role string) (*models.OrganizationMember, error)
+}
@@ -0,0 +1,481 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	administratorRole    = "administrator"
+	invitationPrefix     = "orginv_"
+	defaultInvitationTTL = 7 * 24 * time.Hour
+
+	errAlreadyMember      = "User already belongs to an organization"
+	errMemberQuota        = "Organization member quota reached"
+	errInvitationQuota    = "Organization invitation quota reached"
+	errInvitationExpired  = "Invitation expired or already accepted"
+	errInvitationEmail    = "Invitation was sent to another email"
+	errOwnerMembership    = "Owner can't be removed or change role"
+	errNotOrganizationMgr = "Only the owner or admins manage the organization"
+)
+
+// Organizations UseCase
+type organizationsUC struct {
+	cfg    *config.Config
+	repo   organizations.Repository
+	clock  clock.Clock
+	logger logger.Logger
+}
+
+// Organizations UseCase constructor
+func NewOrganizationsUseCase(cfg *config.Config, repo organizations.Repository, clk clock.Clock, logger logger.Logger) organizations.UseCase {
+	return &organizationsUC{cfg: cfg, repo: repo, clock: clk, logger: logger}
+}
+
+// Create organization owned by the current user, who must not belong to one yet. Quotas start from config.
+func (u *organizationsUC) Create(ctx context.Context, req *dto.OrganizationRequest) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.Create")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	membership, err := u.GetMembership(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errAlreadyMember, nil)
+	}
+
+	return u.repo.Create(ctx, &models.Organization{
+		OwnerID:               user.User.ID,
+		Name:                  req.Name,
+		BillingEmail:          req.BillingEmail,
+		AllowMemberInvites:    req.AllowMemberInvites,
+		MaxMembers:            u.cfg.Organizations.MaxMembers,
+		MaxPendingInvitations: u.cfg.Organizations.MaxPendingInvitations,
+	})
+}
+
+// Get organization by id, members and administrators only
+func (u *organizationsUC) GetByID(ctx context.Context, organizationID int64) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.GetByID")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.Role.Name != administratorRole {
+		if _, err := u.member(ctx, organizationID); err != nil {
+			return nil, err
+		}
+	}
+	return u.repo.GetByID(ctx, organizationID)
+}
+
+// Get organization of the current user
+func (u *organizationsUC) GetMine(ctx context.Context) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.GetMine")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	membership, err := u.repo.GetMembership(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetByID(ctx, membership.OrganizationID)
+}
+
+// Update name and settings, owner and admins only
+func (u *organizationsUC) Update(ctx context.Context, organizationID int64, req *dto.OrganizationRequest) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.Update")
+	defer span.Finish()
+
+	if _, err := u.manager(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	organization, err := u.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.Name = req.Name
+	organization.BillingEmail = req.BillingEmail
+	organization.AllowMemberInvites = req.AllowMemberInvites
+	return u.repo.Update(ctx, organization)
+}
+
+// Set quotas, administrators only since they follow the billing plan
+func (u *organizationsUC) UpdateQuotas(ctx context.Context, organizationID int64, req *dto.OrganizationQuotasRequest) (*models.Organization, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.UpdateQuotas")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if user.Role.Name != administratorRole {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	organization, err := u.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.MaxMembers = req.MaxMembers
+	organization.MaxPendingInvitations = req.MaxPendingInvitations
+	return u.repo.Update(ctx, organization)
+}
+
+// Delete organization, owner only
+func (u *organizationsUC) Delete(ctx context.Context, organizationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.Delete")
+	defer span.Finish()
+
+	membership, err := u.member(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if membership.Role != models.OrganizationRoleOwner {
+		return httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return u.repo.Delete(ctx, organizationID)
+}
+
+// List members of organization, members only
+func (u *organizationsUC) ListMembers(ctx context.Context, organizationID int64) ([]*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.ListMembers")
+	defer span.Finish()
+
+	if _, err := u.member(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListMembers(ctx, organizationID)
+}
+
+// Change role of a member between admin and member, owner only
+func (u *organizationsUC) UpdateMemberRole(ctx context.Context, organizationID int64, userID int, req *dto.OrganizationMemberRequest) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.UpdateMemberRole")
+	defer span.Finish()
+
+	membership, err := u.member(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if membership.Role != models.OrganizationRoleOwner {
+		return httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err)
+	}
+	if userID == membership.UserID {
+		return httpErrors.NewRestError(http.StatusConflict, errOwnerMembership, nil)
+	}
+	return u.repo.UpdateMemberRole(ctx, organizationID, userID, req.Role)
+}
+
+// Remove member, the owner removes anyone, admins remove members and everyone but the owner may leave
+func (u *organizationsUC) RemoveMember(ctx context.Context, organizationID int64, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.RemoveMember")
+	defer span.Finish()
+
+	membership, err := u.member(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	target, err := u.repo.GetMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if target.OrganizationID != organizationID {
+		return errors.Wrap(sql.ErrNoRows, "organizationsUC.RemoveMember")
+	}
+	if target.Role == models.OrganizationRoleOwner {
+		return httpErrors.NewRestError(http.StatusConflict, errOwnerMembership, nil)
+	}
+
+	leaving := userID == membership.UserID
+	if !leaving && !canManage(membership, target.Role) {
+		return httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return u.repo.RemoveMember(ctx, organizationID, userID)
+}
+
+// Invite an email into the organization. Owner and admins invite, members only when the organization
+// allows it and only as members. Pending invitations count against the member quota. The token is
+// only returned here.
+func (u *organizationsUC) CreateInvitation(ctx context.Context, organizationID int64, req *dto.OrganizationInvitationRequest) (*models.OrganizationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.CreateInvitation")
+	defer span.Finish()
+
+	membership, err := u.member(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	organization, err := u.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := canManage(membership, req.Role) ||
+		(organization.AllowMemberInvites && req.Role == models.OrganizationRoleMember)
+	if !allowed {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+
+	pending, err := u.repo.ListPendingInvitations(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := organization.MaxPendingInvitations; limit > 0 && len(pending) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errInvitationQuota, limit)
+	}
+	members, err := u.repo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := organization.MaxMembers; limit > 0 && len(members)+len(pending) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errMemberQuota, limit)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "organizationsUC.CreateInvitation.generateToken")
+	}
+	created, err := u.repo.CreateInvitation(ctx, &models.OrganizationInvitation{
+		OrganizationID: organizationID,
+		Email:          normalizeEmail(req.Email),
+		Role:           req.Role,
+		TokenHash:      hashToken(token),
+		InvitedBy:      membership.UserID,
+		ExpiresAt:      u.clock.Now().Add(u.invitationTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	created.Token = token
+	return created, nil
+}
+
+// List pending invitations, owner and admins only
+func (u *organizationsUC) ListInvitations(ctx context.Context, organizationID int64) ([]*models.OrganizationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.ListInvitations")
+	defer span.Finish()
+
+	if _, err := u.manager(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListPendingInvitations(ctx, organizationID)
+}
+
+// Revoke invitation, owner and admins only
+func (u *organizationsUC) RevokeInvitation(ctx context.Context, organizationID int64, invitationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.RevokeInvitation")
+	defer span.Finish()
+
+	if _, err := u.manager(ctx, organizationID); err != nil {
+		return err
+	}
+	return u.repo.DeleteInvitation(ctx, organizationID, invitationID)
+}
+
+// Join the organization of an invitation sent to the current user's email
+func (u *organizationsUC) AcceptInvitation(ctx context.Context, req *dto.AcceptInvitationRequest) (*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.AcceptInvitation")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+
+	invitation, err := u.repo.GetInvitationByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || !u.clock.Now().Before(invitation.ExpiresAt) {
+		return nil, httpErrors.NewRestError(http.StatusGone, errInvitationExpired, nil)
+	}
+	if invitation.Email != normalizeEmail(user.User.Email) {
+		return nil, httpErrors.NewRestError(http.StatusForbidden, errInvitationEmail, nil)
+	}
+
+	membership, err := u.GetMembership(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errAlreadyMember, nil)
+	}
+
+	organization, err := u.repo.GetByID(ctx, invitation.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := u.repo.ListMembers(ctx, organization.ID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := organization.MaxMembers; limit > 0 && len(members) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errMemberQuota, limit)
+	}
+
+	if err := u.repo.AcceptInvitation(ctx, invitation, user.User.ID); err != nil {
+		return nil, err
+	}
+	return u.repo.GetMembership(ctx, user.User.ID)
+}
+
+// Add user to organization with a member or admin role, quota and single membership still apply
+func (u *organizationsUC) AddMember(ctx context.Context, organizationID int64, userID int, role string) (*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.AddMember")
+	defer span.Finish()
+
+	if role != models.OrganizationRoleAdmin && role != models.OrganizationRoleMember {
+		return nil, httpErrors.NewBadRequestError("organization role must be admin or member")
+	}
+
+	membership, err := u.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errAlreadyMember, nil)
+	}
+
+	organization, err := u.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := u.repo.ListMembers(ctx, organization.ID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := organization.MaxMembers; limit > 0 && len(members) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errMemberQuota, limit)
+	}
+
+	if err := u.repo.AddMember(ctx, organization.ID, userID, role); err != nil {
+		return nil, err
+	}
+	return u.repo.GetMembership(ctx, userID)
+}
+
+// Organization membership of a user, nil when the user belongs to none
+func (u *organizationsUC) GetMembership(ctx context.Context, userID int) (*models.OrganizationMember, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "organizationsUC.GetMembership")
+	defer span.Finish()
+
+	membership, err := u.repo.GetMembership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+// Membership of the current user in organization, forbidden for outsiders
+func (u *organizationsUC) member(ctx context.Context, organizationID int64) (*models.OrganizationMember, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	membership, err := u.GetMembership(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || membership.OrganizationID != organizationID {
+		return nil, httpErrors.NewForbiddenError(httpErrors.PermissionDenied)
+	}
+	return membership, nil
+}
+
+// Membership of the current user in organization, forbidden unless owner or admin
+func (u *organizationsUC) manager(ctx context.Context, organizationID int64) (*models.OrganizationMember, error) {
+	membership, err := u.member(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.IsManager() {
+		return nil, httpErrors.NewRestError(http.StatusForbidden, errNotOrganizationMgr, nil)
+	}
+	return membership, nil
+}
+
+func (u *organizationsUC) invitationTTL() time.Duration {
+	if u.cfg.Organizations.InvitationTTL > 0 {
+		return time.Duration(u.cfg.Organizations.InvitationTTL) * time.Second
+	}
+	return defaultInvitationTTL
+}
+
+// Can membership act on members or invitations of role, admins are managed by the owner only
+func canManage(membership *models.OrganizationMember, role string) bool {
+	switch membership.Role {
+	case models.OrganizationRoleOwner:
+		return true
+	case models.OrganizationRoleAdmin:
+		return role == models.OrganizationRoleMember
+	}
+	return false
+}
+
+func generateToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return invitationPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+func TestOrganizationsUC_GetByID(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockRepository(ctrl)
+	cfg := &config.Config{Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2, InvitationTTL: 3600}}
+	uc := NewOrganizationsUseCase(cfg, mockRepo, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 1, Email: "owner@example.com"}})
+
+	mockRepo.EXPECT().GetMembership(gomock.Any(), 1).Return(&models.OrganizationMember{OrganizationID: 10, UserID: 1, Role: models.OrganizationRoleMember}, nil).Times(2)
+	mockRepo.EXPECT().GetByID(gomock.Any(), int64(10)).Return(&models.Organization{ID: 10, OwnerID: 2}, nil)
+	organization, err := uc.GetByID(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), organization.ID)
+
+	_, err = uc.GetByID(ctx, 11)
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+}
+
+func TestOrganizationsUC_Members(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2, InvitationTTL: 3600}}
+	uc := NewOrganizationsUseCase(cfg, repository.NewOrganizationsMemoryRepository(), clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	owner := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 1, Email: "owner@example.com"}})
+
+	organization, err := uc.Create(owner, &dto.OrganizationRequest{Name: "acme"})
+	require.NoError(t, err)
+	require.Equal(t, 3, organization.MaxMembers)
+
+	_, err = uc.Create(owner, &dto.OrganizationRequest{Name: "other"})
+	testutil.RequireStatus(t, err, http.StatusConflict)
+
+	join := func(userID int, email, role string) context.Context {
+		invitation, err := uc.CreateInvitation(owner, organization.ID, &dto.OrganizationInvitationRequest{Email: email, Role: role})
+		require.NoError(t, err)
+		ctx := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: userID, Email: email}})
+		_, err = uc.AcceptInvitation(ctx, &dto.AcceptInvitationRequest{Token: invitation.Token})
+		require.NoError(t, err)
+		return ctx
+	}
+	admin := join(2, "admin@example.com", models.OrganizationRoleAdmin)
+	member := join(3, "member@example.com", models.OrganizationRoleMember)
+
+	members, err := uc.ListMembers(member, organization.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+
+	// Admins manage members but neither other admins nor the owner
+	err = uc.RemoveMember(admin, organization.ID, 1)
+	testutil.RequireStatus(t, err, http.StatusConflict)
+	err = uc.UpdateMemberRole(admin, organization.ID, 3, &dto.OrganizationMemberRequest{Role: models.OrganizationRoleAdmin})
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+	_, err = uc.CreateInvitation(admin, organization.ID, &dto.OrganizationInvitationRequest{Email: "x@example.com", Role: models.OrganizationRoleAdmin})
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+
+	// Members invite only when the organization allows it
+	_, err = uc.CreateInvitation(member, organization.ID, &dto.OrganizationInvitationRequest{Email: "x@example.com", Role: models.OrganizationRoleMember})
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+
+	// Member quota counts members and pending invitations
+	_, err = uc.CreateInvitation(owner, organization.ID, &dto.OrganizationInvitationRequest{Email: "x@example.com", Role: models.OrganizationRoleMember})
+	testutil.RequireStatus(t, err, http.StatusConflict)
+
+	require.NoError(t, uc.RemoveMember(member, organization.ID, 3))
+	require.NoError(t, uc.RemoveMember(owner, organization.ID, 2))
+	members, err = uc.ListMembers(owner, organization.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+}
+
+func TestOrganizationsUC_AcceptInvitation(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFrozen(time.Now())
+	cfg := &config.Config{Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2, InvitationTTL: 3600}}
+	uc := NewOrganizationsUseCase(cfg, repository.NewOrganizationsMemoryRepository(), clk, testutil.Logger(cfg))
+	owner := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 1, Email: "owner@example.com"}})
+	invited := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 2, Email: "invited@example.com"}})
+
+	organization, err := uc.Create(owner, &dto.OrganizationRequest{Name: "acme"})
+	require.NoError(t, err)
+	invitation, err := uc.CreateInvitation(owner, organization.ID, &dto.OrganizationInvitationRequest{Email: "Invited@Example.com", Role: models.OrganizationRoleMember})
+	require.NoError(t, err)
+	require.NotEmpty(t, invitation.Token)
+
+	_, err = uc.AcceptInvitation(requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 2, Email: "someone@example.com"}}), &dto.AcceptInvitationRequest{Token: invitation.Token})
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+
+	_, err = uc.AcceptInvitation(invited, &dto.AcceptInvitationRequest{Token: "orginv_unknown"})
+	require.Error(t, err)
+
+	membership, err := uc.AcceptInvitation(invited, &dto.AcceptInvitationRequest{Token: invitation.Token})
+	require.NoError(t, err)
+	require.Equal(t, organization.ID, membership.OrganizationID)
+	require.Equal(t, models.OrganizationRoleMember, membership.Role)
+
+	_, err = uc.AcceptInvitation(requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 3, Email: "invited@example.com"}}), &dto.AcceptInvitationRequest{Token: invitation.Token})
+	testutil.RequireStatus(t, err, http.StatusGone)
+
+	expiring, err := uc.CreateInvitation(owner, organization.ID, &dto.OrganizationInvitationRequest{Email: "late@example.com", Role: models.OrganizationRoleMember})
+	require.NoError(t, err)
+	clk.Advance(2 * time.Hour)
+	_, err = uc.AcceptInvitation(requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 4, Email: "late@example.com"}}), &dto.AcceptInvitationRequest{Token: expiring.Token})
+	testutil.RequireStatus(t, err, http.StatusGone)
+}
+
+func TestOrganizationsUC_AddMember(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Organizations: config.Organizations{MaxMembers: 3, MaxPendingInvitations: 2, InvitationTTL: 3600}}
+	uc := NewOrganizationsUseCase(cfg, repository.NewOrganizationsMemoryRepository(), clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	owner := requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: 1, Email: "owner@example.com"}})
+	organization, err := uc.Create(owner, &dto.OrganizationRequest{Name: "acme"})
+	require.NoError(t, err)
+
+	_, err = uc.AddMember(context.Background(), organization.ID, 2, models.OrganizationRoleOwner)
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	membership, err := uc.AddMember(context.Background(), organization.ID, 2, models.OrganizationRoleAdmin)
+	require.NoError(t, err)
+	require.Equal(t, models.OrganizationRoleAdmin, membership.Role)
+
+	_, err = uc.AddMember(context.Background(), organization.ID, 2, models.OrganizationRoleMember)
+	testutil.RequireStatus(t, err, http.StatusConflict)
+
+	_, err = uc.AddMember(context.Background(), organization.ID, 3, models.OrganizationRoleMember)
+	require.NoError(t, err)
+	// Quota of 3 counts the owner
+	_, err = uc.AddMember(context.Background(), organization.ID, 4, models.OrganizationRoleMember)
+	testutil.RequireStatus(t, err, http.StatusConflict)
+}
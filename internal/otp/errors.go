@@ -0,0 +1,14 @@
+package otp
+
+import (
+	"net/http"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+var (
+	ErrInvalidCode      = httpErrors.NewRestErrorWithMessage(http.StatusUnauthorized, "invalid or expired code", nil)
+	ErrTooManyAttempts  = httpErrors.NewRestErrorWithMessage(http.StatusTooManyRequests, "too many attempts, request a new code", nil)
+	ErrResendTooSoon    = httpErrors.NewRestErrorWithMessage(http.StatusTooManyRequests, "code already sent, try again later", nil)
+	ErrPhoneNotVerified = httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, "phone number is not verified", nil)
+)
@@ -0,0 +1,18 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package otp
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// OTP challenge redis repository
+type RedisRepository interface {
+	CreateChallenge(ctx context.Context, key string, challenge *models.OTPChallenge, ttl time.Duration) error
+	GetChallenge(ctx context.Context, key string) (*models.OTPChallenge, error)
+	IncrAttempts(ctx context.Context, key string) (int64, error)
+	DeleteChallenge(ctx context.Context, key string) error
+	AcquireResend(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/otp"
+)
+
+const (
+	basePrefix     = "api-otp:"
+	resendPrefix   = "api-otp-resend:"
+	attemptsSuffix = ":attempts"
+)
+
+// OTP redis repository
+type otpRedisRepo struct {
+	redisClient *redis.Client
+}
+
+// OTP redis repository constructor
+func NewOTPRedisRepo(redisClient *redis.Client) otp.RedisRepository {
+	return &otpRedisRepo{redisClient: redisClient}
+}
+
+// Store challenge and reset its attempts counter, both expire after ttl
+func (r *otpRedisRepo) CreateChallenge(ctx context.Context, key string, challenge *models.OTPChallenge, ttl time.Duration) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpRedisRepo.CreateChallenge")
+	defer span.Finish()
+
+	challengeBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return errors.Wrap(err, "otpRedisRepo.CreateChallenge.json.Marshal")
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, basePrefix+key, challengeBytes, ttl)
+	pipe.Set(ctx, basePrefix+key+attemptsSuffix, 0, ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "otpRedisRepo.CreateChallenge.pipe.Exec")
+	}
+	return nil
+}
+
+// Get challenge, a missing or expired one is otp.ErrInvalidCode
+func (r *otpRedisRepo) GetChallenge(ctx context.Context, key string) (*models.OTPChallenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpRedisRepo.GetChallenge")
+	defer span.Finish()
+
+	challengeBytes, err := r.redisClient.Get(ctx, basePrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(otp.ErrInvalidCode, "otpRedisRepo.GetChallenge.redisClient.Get")
+		}
+		return nil, errors.Wrap(err, "otpRedisRepo.GetChallenge.redisClient.Get")
+	}
+
+	challenge := &models.OTPChallenge{}
+	if err = json.Unmarshal(challengeBytes, challenge); err != nil {
+		return nil, errors.Wrap(err, "otpRedisRepo.GetChallenge.json.Unmarshal")
+	}
+	return challenge, nil
+}
+
+// Count a verification attempt, returns attempts made so far
+func (r *otpRedisRepo) IncrAttempts(ctx context.Context, key string) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpRedisRepo.IncrAttempts")
+	defer span.Finish()
+
+	attempts, err := r.redisClient.Incr(ctx, basePrefix+key+attemptsSuffix).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "otpRedisRepo.IncrAttempts.redisClient.Incr")
+	}
+	return attempts, nil
+}
+
+// Delete challenge with its attempts counter
+func (r *otpRedisRepo) DeleteChallenge(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpRedisRepo.DeleteChallenge")
+	defer span.Finish()
+
+	if err := r.redisClient.Del(ctx, basePrefix+key, basePrefix+key+attemptsSuffix).Err(); err != nil {
+		return errors.Wrap(err, "otpRedisRepo.DeleteChallenge.redisClient.Del")
+	}
+	return nil
+}
+
+// Take the resend slot of key, false while a previous code is still within its resend interval
+func (r *otpRedisRepo) AcquireResend(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpRedisRepo.AcquireResend")
+	defer span.Finish()
+
+	ok, err := r.redisClient.SetNX(ctx, resendPrefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "otpRedisRepo.AcquireResend.redisClient.SetNX")
+	}
+	return ok, nil
+}
@@ -0,0 +1,18 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package otp
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// OTP use case
+type UseCase interface {
+	SendPhoneVerification(ctx context.Context, userID int, phone string) error
+	VerifyPhone(ctx context.Context, userID int, code string) (*models.User, error)
+	SendLoginChallenge(ctx context.Context, user *models.User) (string, error)
+	VerifyLoginChallenge(ctx context.Context, mfaToken string, code string) (int, error)
+	SetSMS2FA(ctx context.Context, userID int, enabled bool) error
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/otp"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/sms"
+)
+
+const (
+	defaultCodeLength  = 6
+	defaultTTL         = 300
+	defaultMaxAttempts = 5
+
+	phoneVerifyMessage = "Your verification code is %s"
+	loginMessage       = "Your login code is %s"
+)
+
+// OTP UseCase
+type otpUC struct {
+	cfg       *config.Config
+	authUC    auth.UseCase
+	redisRepo otp.RedisRepository
+	sender    sms.Sender
+	logger    logger.Logger
+}
+
+// OTP UseCase constructor
+func NewOTPUseCase(cfg *config.Config, authUC auth.UseCase, redisRepo otp.RedisRepository, sender sms.Sender, log logger.Logger) otp.UseCase {
+	return &otpUC{cfg: cfg, authUC: authUC, redisRepo: redisRepo, sender: sender, logger: log}
+}
+
+// Send code to a phone number the user wants to verify, replaces any pending verification
+func (u *otpUC) SendPhoneVerification(ctx context.Context, userID int, phone string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpUC.SendPhoneVerification")
+	defer span.Finish()
+
+	challenge := &models.OTPChallenge{
+		ID:      uuid.New().String(),
+		Purpose: models.OTPPurposePhoneVerify,
+		UserID:  userID,
+		Phone:   phone,
+	}
+	return u.sendChallenge(ctx, phoneVerifyKey(userID), challenge, phoneVerifyMessage)
+}
+
+// Check phone verification code and store the phone as verified
+func (u *otpUC) VerifyPhone(ctx context.Context, userID int, code string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpUC.VerifyPhone")
+	defer span.Finish()
+
+	challenge, err := u.verifyChallenge(ctx, phoneVerifyKey(userID), code)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.authUC.SetPhoneVerified(ctx, userID, challenge.Phone)
+}
+
+// Send login code to the verified phone of user, returns the token the code is redeemed with
+func (u *otpUC) SendLoginChallenge(ctx context.Context, user *models.User) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpUC.SendLoginChallenge")
+	defer span.Finish()
+
+	if user.Phone == "" || user.PhoneVerifiedAt == nil {
+		return "", otp.ErrPhoneNotVerified
+	}
+
+	challenge := &models.OTPChallenge{
+		ID:      uuid.New().String(),
+		Purpose: models.OTPPurposeLogin,
+		UserID:  user.ID,
+		Phone:   user.Phone,
+	}
+	if err := u.sendChallenge(ctx, loginKey(challenge.ID), challenge, loginMessage); err != nil {
+		return "", err
+	}
+	return challenge.ID, nil
+}
+
+// Check login code, returns the user the challenge was issued for
+func (u *otpUC) VerifyLoginChallenge(ctx context.Context, mfaToken string, code string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpUC.VerifyLoginChallenge")
+	defer span.Finish()
+
+	challenge, err := u.verifyChallenge(ctx, loginKey(mfaToken), code)
+	if err != nil {
+		return 0, err
+	}
+	return challenge.UserID, nil
+}
+
+// Toggle SMS second factor of user
+func (u *otpUC) SetSMS2FA(ctx context.Context, userID int, enabled bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "otpUC.SetSMS2FA")
+	defer span.Finish()
+
+	if err := u.authUC.SetSMS2FA(ctx, userID, enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return otp.ErrPhoneNotVerified
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *otpUC) sendChallenge(ctx context.Context, key string, challenge *models.OTPChallenge, message string) error {
+	if resend := u.cfg.OTP.ResendSeconds; resend > 0 {
+		ok, err := u.redisRepo.AcquireResend(ctx, challenge.Purpose+":"+strconv.Itoa(challenge.UserID), time.Duration(resend)*time.Second)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return otp.ErrResendTooSoon
+		}
+	}
+
+	code, err := generateCode(u.codeLength())
+	if err != nil {
+		return errors.Wrap(err, "otpUC.sendChallenge.generateCode")
+	}
+
+	ttl := time.Duration(u.ttl()) * time.Second
+	challenge.CodeHash = hashCode(challenge.ID, code)
+	challenge.ExpiresAt = time.Now().Add(ttl)
+
+	if err = u.redisRepo.CreateChallenge(ctx, key, challenge, ttl); err != nil {
+		return err
+	}
+
+	if err = u.sender.Send(ctx, challenge.Phone, fmt.Sprintf(message, code)); err != nil {
+		if delErr := u.redisRepo.DeleteChallenge(ctx, key); delErr != nil {
+			u.logger.Errorf("otpUC.sendChallenge.DeleteChallenge: %v", delErr)
+		}
+		return errors.Wrap(err, "otpUC.sendChallenge.Send")
+	}
+	return nil
+}
+
+// Count the attempt before comparing so parallel guesses share the same budget, challenge is single use
+func (u *otpUC) verifyChallenge(ctx context.Context, key string, code string) (*models.OTPChallenge, error) {
+	challenge, err := u.redisRepo.GetChallenge(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := u.redisRepo.IncrAttempts(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > int64(u.maxAttempts()) {
+		if err = u.redisRepo.DeleteChallenge(ctx, key); err != nil {
+			u.logger.Errorf("otpUC.verifyChallenge.DeleteChallenge: %v", err)
+		}
+		return nil, otp.ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashCode(challenge.ID, code)), []byte(challenge.CodeHash)) != 1 {
+		return nil, otp.ErrInvalidCode
+	}
+
+	if err = u.redisRepo.DeleteChallenge(ctx, key); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (u *otpUC) codeLength() int {
+	if u.cfg.OTP.CodeLength > 0 {
+		return u.cfg.OTP.CodeLength
+	}
+	return defaultCodeLength
+}
+
+func (u *otpUC) ttl() int {
+	if u.cfg.OTP.TTL > 0 {
+		return u.cfg.OTP.TTL
+	}
+	return defaultTTL
+}
+
+func (u *otpUC) maxAttempts() int {
+	if u.cfg.OTP.MaxAttempts > 0 {
+		return u.cfg.OTP.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+func phoneVerifyKey(userID int) string {
+	return models.OTPPurposePhoneVerify + ":" + strconv.Itoa(userID)
+}
+
+func loginKey(mfaToken string) string {
+	return models.OTPPurposeLogin + ":" + mfaToken
+}
+
+// Uniformly random numeric code of length digits
+func generateCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+// Codes are salted with the challenge id so equal codes never share a hash
+func hashCode(challengeID, code string) string {
+	sum := sha256.Sum256([]byte(challengeID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	authMock "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/otp"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/otp/mock"
+)
+
+type stubSender struct {
+	to      string
+	message string
+}
+
+func (s *stubSender) Send(ctx context.Context, to string, message string) error {
+	s.to, s.message = to, message
+	return nil
+}
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+func TestOTPUC_VerifyPhone(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuthUC := authMock.NewMockUseCase(ctrl)
+	sender := &stubSender{}
+	cfg := &config.Config{OTP: config.OTP{CodeLength: 6, TTL: 300, MaxAttempts: 3, ResendSeconds: 60}}
+	otpUC := NewOTPUseCase(cfg, mockAuthUC, mockRedisRepo, sender, nil)
+
+	ctx := context.Background()
+	var stored *models.OTPChallenge
+
+	mockRedisRepo.EXPECT().AcquireResend(gomock.Any(), "phone_verify:1", time.Minute).Return(true, nil)
+	mockRedisRepo.EXPECT().CreateChallenge(gomock.Any(), "phone_verify:1", gomock.Any(), 5*time.Minute).
+		DoAndReturn(func(_ context.Context, _ string, ch *models.OTPChallenge, _ time.Duration) error {
+			stored = ch
+			return nil
+		})
+
+	require.NoError(t, otpUC.SendPhoneVerification(ctx, 1, "+15550001111"))
+	require.Equal(t, "+15550001111", sender.to)
+	code := codePattern.FindString(sender.message)
+	require.NotEmpty(t, code)
+	require.NotContains(t, stored.CodeHash, code)
+
+	mockRedisRepo.EXPECT().GetChallenge(gomock.Any(), "phone_verify:1").Return(stored, nil).Times(2)
+	mockRedisRepo.EXPECT().IncrAttempts(gomock.Any(), "phone_verify:1").Return(int64(1), nil)
+	_, err := otpUC.VerifyPhone(ctx, 1, "000000x")
+	require.ErrorIs(t, err, otp.ErrInvalidCode)
+
+	mockRedisRepo.EXPECT().IncrAttempts(gomock.Any(), "phone_verify:1").Return(int64(2), nil)
+	mockRedisRepo.EXPECT().DeleteChallenge(gomock.Any(), "phone_verify:1").Return(nil)
+	mockAuthUC.EXPECT().SetPhoneVerified(gomock.Any(), 1, "+15550001111").Return(&models.User{ID: 1}, nil)
+	user, err := otpUC.VerifyPhone(ctx, 1, code)
+	require.NoError(t, err)
+	require.Equal(t, 1, user.ID)
+}
+
+func TestOTPUC_VerifyLoginChallenge_TooManyAttempts(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	cfg := &config.Config{OTP: config.OTP{MaxAttempts: 3}}
+	otpUC := NewOTPUseCase(cfg, authMock.NewMockUseCase(ctrl), mockRedisRepo, &stubSender{}, nil)
+
+	challenge := &models.OTPChallenge{ID: "token", UserID: 1, CodeHash: hashCode("token", "123456")}
+	mockRedisRepo.EXPECT().GetChallenge(gomock.Any(), "login:token").Return(challenge, nil)
+	mockRedisRepo.EXPECT().IncrAttempts(gomock.Any(), "login:token").Return(int64(4), nil)
+	mockRedisRepo.EXPECT().DeleteChallenge(gomock.Any(), "login:token").Return(nil)
+
+	_, err := otpUC.VerifyLoginChallenge(context.Background(), "token", "123456")
+	require.ErrorIs(t, err, otp.ErrTooManyAttempts)
+}
+
+func TestOTPUC_SendLoginChallenge(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuthUC := authMock.NewMockUseCase(ctrl)
+	otpUC := NewOTPUseCase(&config.Config{}, mockAuthUC, mockRedisRepo, &stubSender{}, nil)
+	ctx := context.Background()
+
+	_, err := otpUC.SendLoginChallenge(ctx, &models.User{ID: 1, Phone: "+15550001111"})
+	require.ErrorIs(t, err, otp.ErrPhoneNotVerified)
+
+	mockRedisRepo.EXPECT().CreateChallenge(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	verifiedAt := time.Now()
+	token, err := otpUC.SendLoginChallenge(ctx, &models.User{ID: 1, Phone: "+15550001111", PhoneVerifiedAt: &verifiedAt})
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	mockAuthUC.EXPECT().SetSMS2FA(gomock.Any(), 1, true).Return(errors.Wrap(sql.ErrNoRows, "authRepo.SetSMS2FA"))
+	require.ErrorIs(t, otpUC.SetSMS2FA(ctx, 1, true), otp.ErrPhoneNotVerified)
+}
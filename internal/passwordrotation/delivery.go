@@ -0,0 +1,15 @@
+package passwordrotation
+
+import "github.com/labstack/echo/v4"
+
+// Route of the password change, the only one besides GetMe open to users a campaign flagged
+const ChangePasswordPath = "/api/v1/auth/me/password"
+
+// Password rotation HTTP Handlers interface
+type Handlers interface {
+	CreateCampaign() echo.HandlerFunc
+	UploadCampaign() echo.HandlerFunc
+	ListCampaigns() echo.HandlerFunc
+	GetReport() echo.HandlerFunc
+	CancelCampaign() echo.HandlerFunc
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const cohortFormField = "file"
+
+// Password rotation handlers
+type passwordRotationHandlers struct {
+	cfg        *config.Config
+	rotationUC passwordrotation.UseCase
+	logger     logger.Logger
+}
+
+// NewPasswordRotationHandlers Password rotation handlers constructor
+func NewPasswordRotationHandlers(cfg *config.Config, rotationUC passwordrotation.UseCase, log logger.Logger) passwordrotation.Handlers {
+	return &passwordRotationHandlers{cfg: cfg, rotationUC: rotationUC, logger: log}
+}
+
+// CreateCampaign godoc
+// @Summary Create password rotation campaign
+// @Description Require the users matching filter, or the listed user_ids, to change their password at next login, admin only
+// @Tags PasswordRotation
+// @Accept json
+// @Produce json
+// @Param campaign body dto.PasswordRotationCampaignRequest true "campaign"
+// @Success 201 {object} models.PasswordRotationCampaign
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/password-rotations [post]
+func (h *passwordRotationHandlers) CreateCampaign() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "passwordRotationHandlers.CreateCampaign")
+		defer span.Finish()
+
+		req := &dto.PasswordRotationCampaignRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		campaign, err := h.rotationUC.CreateCampaign(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, campaign)
+	}
+}
+
+// UploadCampaign godoc
+// @Summary Upload password rotation cohort
+// @Description Create a campaign from multipart form field "file", user ids or usernames in the first column of a CSV. Entries matching no user are returned as unmatched, admin only
+// @Tags PasswordRotation
+// @Accept mpfd
+// @Produce json
+// @Param name formData string true "campaign name"
+// @Param reason formData string false "reason"
+// @Param file formData file true "cohort"
+// @Success 201 {object} models.PasswordRotationCampaign
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 413 {object} httpErrors.RestError
+// @Router /admin/password-rotations/upload [post]
+func (h *passwordRotationHandlers) UploadCampaign() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "passwordRotationHandlers.UploadCampaign")
+		defer span.Finish()
+
+		fileHeader, err := c.FormFile(cohortFormField)
+		if err != nil {
+			return httpErrors.NewBadRequestError(errors.WithMessage(err, "c.FormFile"))
+		}
+
+		cohort, err := fileHeader.Open()
+		if err != nil {
+			return err
+		}
+		defer cohort.Close()
+
+		campaign, err := h.rotationUC.UploadCampaign(ctx, c.FormValue("name"), c.FormValue("reason"), cohort)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, campaign)
+	}
+}
+
+// ListCampaigns godoc
+// @Summary List password rotation campaigns
+// @Description Campaigns with their flagged and rotated counts, newest first, admin only
+// @Tags PasswordRotation
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.PasswordRotationCampaign
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/password-rotations [get]
+func (h *passwordRotationHandlers) ListCampaigns() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "passwordRotationHandlers.ListCampaigns")
+		defer span.Finish()
+
+		campaigns, err := h.rotationUC.ListCampaigns(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, campaigns)
+	}
+}
+
+// GetReport godoc
+// @Summary Get password rotation report
+// @Description Progress of a campaign with the first users still to rotate, admin only
+// @Tags PasswordRotation
+// @Accept json
+// @Produce json
+// @Param campaign_id path int true "campaign_id"
+// @Success 200 {object} models.PasswordRotationReport
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/password-rotations/{campaign_id} [get]
+func (h *passwordRotationHandlers) GetReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "passwordRotationHandlers.GetReport")
+		defer span.Finish()
+
+		campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		report, err := h.rotationUC.GetReport(ctx, campaignID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// CancelCampaign godoc
+// @Summary Cancel password rotation campaign
+// @Description Stop requiring a password change from the users of a campaign, unless another open campaign flags them, admin only
+// @Tags PasswordRotation
+// @Accept json
+// @Produce json
+// @Param campaign_id path int true "campaign_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/password-rotations/{campaign_id} [delete]
+func (h *passwordRotationHandlers) CancelCampaign() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "passwordRotationHandlers.CancelCampaign")
+		defer span.Finish()
+
+		campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.rotationUC.CancelCampaign(ctx, campaignID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
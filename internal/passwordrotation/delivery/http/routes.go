@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+)
+
+// Map password rotation campaign routes, group is already restricted to administrators
+func MapPasswordRotationRoutes(adminGroup *echo.Group, h passwordrotation.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.POST("/password-rotations", h.CreateCampaign(), mw.CSRF)
+	adminGroup.POST("/password-rotations/upload", h.UploadCampaign(), mw.CSRF)
+	adminGroup.GET("/password-rotations", h.ListCampaigns())
+	adminGroup.GET("/password-rotations/:campaign_id", h.GetReport())
+	adminGroup.DELETE("/password-rotations/:campaign_id", h.CancelCampaign(), mw.CSRF)
+}
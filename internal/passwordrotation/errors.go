@@ -0,0 +1,40 @@
+package passwordrotation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code of ErrRequired, clients match on it to show the password change form
+const CodeRequired = "password_rotation_required"
+
+// Typed rotation error, implements httpErrors.RestErr so it maps to its own status
+type Error struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+}
+
+var ErrRequired = &Error{ErrStatus: http.StatusForbidden, ErrError: "password has to be changed first", Code: CodeRequired}
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s", e.ErrStatus, e.ErrError)
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Rotation errors carry no causes
+func (e *Error) Causes() interface{} {
+	return nil
+}
+
+// Whether err asks for a password change
+func IsRequired(err error) bool {
+	var rotationErr *Error
+	return errors.As(err, &rotationErr) && rotationErr.Code == CodeRequired
+}
@@ -0,0 +1,29 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package passwordrotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Password rotation Repository
+type Repository interface {
+	// Create campaign and flag the users matching filter, returns the flagged users
+	CreateByFilter(ctx context.Context, campaign *models.PasswordRotationCampaign, filter *models.PasswordRotationFilter) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error)
+	// Create campaign and flag the listed users, unknown ids and usernames are skipped
+	CreateByList(ctx context.Context, campaign *models.PasswordRotationCampaign, userIDs []int, usernames []string) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error)
+	GetByID(ctx context.Context, campaignID int64) (*models.PasswordRotationCampaign, error)
+	// Newest first
+	List(ctx context.Context) ([]*models.PasswordRotationCampaign, error)
+	// Users of the campaign still to rotate, lowest ids first
+	ListPending(ctx context.Context, campaignID int64, limit int) ([]int, error)
+	LastRotatedAt(ctx context.Context, campaignID int64) (*time.Time, error)
+	// Cancel campaign, returns its users no other campaign requires a rotation of anymore
+	Cancel(ctx context.Context, campaignID int64, now time.Time) ([]int, error)
+	// Mark every open flag of the user rotated, returns how many there were
+	Complete(ctx context.Context, userID int, now time.Time) (int, error)
+	// Users any open campaign requires a rotation of
+	ListPendingUserIDs(ctx context.Context) ([]int, error)
+}
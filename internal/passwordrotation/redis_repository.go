@@ -0,0 +1,12 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package passwordrotation
+
+import "context"
+
+// Mirror of the users required to rotate, checked on every authenticated request
+type RedisRepository interface {
+	AddPending(ctx context.Context, userIDs []int) error
+	RemovePending(ctx context.Context, userIDs []int) error
+	IsPending(ctx context.Context, userID int) (bool, error)
+	ReplacePending(ctx context.Context, userIDs []int) error
+}
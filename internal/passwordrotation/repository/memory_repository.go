@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Users are read a page at a time while matching a filter
+const memoryUsersPageSize = 100
+
+// Flag of a user in a campaign, rotatedAt is nil while pending
+type memoryFlag struct {
+	rotatedAt *time.Time
+}
+
+// Password rotation Repository for in-memory campaigns, dev mode stand-in for Postgres. Cohorts are
+// resolved through the auth and organizations repositories
+type passwordRotationMemoryRepo struct {
+	mu        sync.RWMutex
+	authRepo  auth.Repository
+	orgsRepo  organizations.Repository
+	nextID    int64
+	campaigns map[int64]models.PasswordRotationCampaign
+	// Flags by campaign then user
+	flags map[int64]map[int]*memoryFlag
+}
+
+// Password rotation in-memory Repository constructor
+func NewPasswordRotationMemoryRepository(authRepo auth.Repository, orgsRepo organizations.Repository) passwordrotation.Repository {
+	return &passwordRotationMemoryRepo{
+		authRepo:  authRepo,
+		orgsRepo:  orgsRepo,
+		campaigns: make(map[int64]models.PasswordRotationCampaign),
+		flags:     make(map[int64]map[int]*memoryFlag),
+	}
+}
+
+// Create campaign and flag the users matching filter
+func (r *passwordRotationMemoryRepo) CreateByFilter(
+	ctx context.Context,
+	campaign *models.PasswordRotationCampaign,
+	filter *models.PasswordRotationFilter,
+) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.CreateByFilter")
+	defer span.Finish()
+
+	matched := make([]*models.PasswordRotationUser, 0)
+	pq := &utils.PaginationQuery{Size: memoryUsersPageSize, Page: 1}
+	for {
+		page, err := r.authRepo.GetUsers(ctx, pq)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "passwordRotationMemoryRepo.CreateByFilter.GetUsers")
+		}
+		for _, listed := range page.Users {
+			ok, err := r.matches(ctx, listed.ID, filter)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				matched = append(matched, &models.PasswordRotationUser{UserID: listed.ID, Username: listed.Username})
+			}
+		}
+		if !page.HasMore || len(page.Users) == 0 {
+			break
+		}
+		pq.Page++
+	}
+	return r.create(campaign, matched), matched, nil
+}
+
+// Create campaign and flag the listed users, unknown ids and usernames are skipped
+func (r *passwordRotationMemoryRepo) CreateByList(
+	ctx context.Context,
+	campaign *models.PasswordRotationCampaign,
+	userIDs []int,
+	usernames []string,
+) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.CreateByList")
+	defer span.Finish()
+
+	found := make(map[int]*models.UserWithRole)
+	for _, userID := range userIDs {
+		user, err := r.authRepo.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, nil, errors.Wrap(err, "passwordRotationMemoryRepo.CreateByList.GetByID")
+		}
+		found[user.User.ID] = user
+	}
+	for _, username := range usernames {
+		user, err := r.authRepo.FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, nil, errors.Wrap(err, "passwordRotationMemoryRepo.CreateByList.FindByUsername")
+		}
+		found[user.User.ID] = user
+	}
+
+	matched := make([]*models.PasswordRotationUser, 0, len(found))
+	for _, user := range found {
+		if user.User.PendingDeletion() {
+			continue
+		}
+		matched = append(matched, &models.PasswordRotationUser{UserID: user.User.ID, Username: user.User.Username})
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].UserID < matched[j].UserID })
+	return r.create(campaign, matched), matched, nil
+}
+
+// Get campaign with its progress
+func (r *passwordRotationMemoryRepo) GetByID(ctx context.Context, campaignID int64) (*models.PasswordRotationCampaign, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.GetByID")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.campaigns[campaignID]; !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "passwordRotationMemoryRepo.GetByID")
+	}
+	return r.withProgress(campaignID), nil
+}
+
+// List campaigns with their progress, newest first
+func (r *passwordRotationMemoryRepo) List(ctx context.Context) ([]*models.PasswordRotationCampaign, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.List")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	campaigns := make([]*models.PasswordRotationCampaign, 0, len(r.campaigns))
+	for campaignID := range r.campaigns {
+		campaigns = append(campaigns, r.withProgress(campaignID))
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		if !campaigns[i].CreatedAt.Equal(campaigns[j].CreatedAt) {
+			return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+		}
+		return campaigns[i].ID > campaigns[j].ID
+	})
+	return campaigns, nil
+}
+
+// Users of the campaign still to rotate
+func (r *passwordRotationMemoryRepo) ListPending(ctx context.Context, campaignID int64, limit int) ([]int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.ListPending")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userIDs := make([]int, 0)
+	for userID, flag := range r.flags[campaignID] {
+		if flag.rotatedAt == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Ints(userIDs)
+	if limit > 0 && len(userIDs) > limit {
+		userIDs = userIDs[:limit]
+	}
+	return userIDs, nil
+}
+
+// Time of the latest rotation in the campaign, nil before the first
+func (r *passwordRotationMemoryRepo) LastRotatedAt(ctx context.Context, campaignID int64) (*time.Time, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.LastRotatedAt")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lastRotatedAt *time.Time
+	for _, flag := range r.flags[campaignID] {
+		if flag.rotatedAt != nil && (lastRotatedAt == nil || flag.rotatedAt.After(*lastRotatedAt)) {
+			lastRotatedAt = utils.ClonePtr(flag.rotatedAt)
+		}
+	}
+	return lastRotatedAt, nil
+}
+
+// Cancel campaign, cancelling twice is sql.ErrNoRows
+func (r *passwordRotationMemoryRepo) Cancel(ctx context.Context, campaignID int64, now time.Time) ([]int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.Cancel")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	campaign, ok := r.campaigns[campaignID]
+	if !ok || campaign.CancelledAt != nil {
+		return nil, errors.Wrap(sql.ErrNoRows, "passwordRotationMemoryRepo.Cancel")
+	}
+	campaign.CancelledAt = &now
+	r.campaigns[campaignID] = campaign
+
+	released := make([]int, 0)
+	for userID, flag := range r.flags[campaignID] {
+		if flag.rotatedAt == nil && !r.pendingLocked(userID) {
+			released = append(released, userID)
+		}
+	}
+	sort.Ints(released)
+	return released, nil
+}
+
+// Mark every open flag of the user rotated
+func (r *passwordRotationMemoryRepo) Complete(ctx context.Context, userID int, now time.Time) (int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.Complete")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var completed int
+	for campaignID, flags := range r.flags {
+		flag, ok := flags[userID]
+		if !ok || flag.rotatedAt != nil || r.campaigns[campaignID].CancelledAt != nil {
+			continue
+		}
+		rotatedAt := now
+		flag.rotatedAt = &rotatedAt
+		completed++
+	}
+	return completed, nil
+}
+
+// Users any open campaign requires a rotation of
+func (r *passwordRotationMemoryRepo) ListPendingUserIDs(ctx context.Context) ([]int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "passwordRotationMemoryRepo.ListPendingUserIDs")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pending := make(map[int]struct{})
+	for campaignID, flags := range r.flags {
+		if r.campaigns[campaignID].CancelledAt != nil {
+			continue
+		}
+		for userID, flag := range flags {
+			if flag.rotatedAt == nil {
+				pending[userID] = struct{}{}
+			}
+		}
+	}
+	userIDs := make([]int, 0, len(pending))
+	for userID := range pending {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Ints(userIDs)
+	return userIDs, nil
+}
+
+// Whether the user matches every set field of filter, accounts waiting for deletion never match
+func (r *passwordRotationMemoryRepo) matches(ctx context.Context, userID int, filter *models.PasswordRotationFilter) (bool, error) {
+	user, err := r.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, errors.Wrap(err, "passwordRotationMemoryRepo.matches.GetByID")
+	}
+	if user.User.PendingDeletion() {
+		return false, nil
+	}
+	if len(filter.RoleNames) > 0 && !contains(filter.RoleNames, user.Role.Name) {
+		return false, nil
+	}
+	if filter.CreatedBefore != nil && !user.User.CreatedAt.Before(*filter.CreatedBefore) {
+		return false, nil
+	}
+	if filter.LoginBefore != nil {
+		loginAt := user.User.LoginDate
+		if loginAt.IsZero() {
+			loginAt = user.User.CreatedAt
+		}
+		if !loginAt.Before(*filter.LoginBefore) {
+			return false, nil
+		}
+	}
+	if filter.OrganizationID != nil {
+		member, err := r.orgsRepo.GetMembership(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, errors.Wrap(err, "passwordRotationMemoryRepo.matches.GetMembership")
+		}
+		if member.OrganizationID != *filter.OrganizationID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (r *passwordRotationMemoryRepo) create(campaign *models.PasswordRotationCampaign, users []*models.PasswordRotationUser) *models.PasswordRotationCampaign {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	stored := *campaign
+	stored.ID = r.nextID
+	stored.Filter = append(json.RawMessage(nil), campaign.Filter...)
+	stored.Unmatched = nil
+	r.campaigns[stored.ID] = stored
+
+	flags := make(map[int]*memoryFlag, len(users))
+	for _, user := range users {
+		flags[user.UserID] = &memoryFlag{}
+	}
+	r.flags[stored.ID] = flags
+	return r.withProgress(stored.ID)
+}
+
+// Copy of a stored campaign with its counts, callers hold the lock
+func (r *passwordRotationMemoryRepo) withProgress(campaignID int64) *models.PasswordRotationCampaign {
+	campaign := r.campaigns[campaignID]
+	campaign.CancelledAt = utils.ClonePtr(campaign.CancelledAt)
+	campaign.Filter = append(json.RawMessage(nil), campaign.Filter...)
+	campaign.Flagged = len(r.flags[campaignID])
+	campaign.Rotated = 0
+	for _, flag := range r.flags[campaignID] {
+		if flag.rotatedAt != nil {
+			campaign.Rotated++
+		}
+	}
+	return &campaign
+}
+
+// Whether an open campaign still requires the user to rotate, callers hold the lock
+func (r *passwordRotationMemoryRepo) pendingLocked(userID int) bool {
+	for campaignID, flags := range r.flags {
+		if r.campaigns[campaignID].CancelledAt != nil {
+			continue
+		}
+		if flag, ok := flags[userID]; ok && flag.rotatedAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Stored campaign, the jsonb filter is read as text and empty for uploaded cohorts
+type campaignRow struct {
+	ID          int64      `db:"id"`
+	Name        string     `db:"name"`
+	Reason      string     `db:"reason"`
+	Source      string     `db:"source"`
+	Filter      string     `db:"filter"`
+	CreatedBy   *int       `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CancelledAt *time.Time `db:"cancelled_at"`
+	Flagged     int        `db:"flagged"`
+	Rotated     int        `db:"rotated"`
+}
+
+func (row *campaignRow) toCampaign() *models.PasswordRotationCampaign {
+	campaign := &models.PasswordRotationCampaign{
+		ID:          row.ID,
+		Name:        row.Name,
+		Reason:      row.Reason,
+		Source:      row.Source,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+		CancelledAt: row.CancelledAt,
+		Flagged:     row.Flagged,
+		Rotated:     row.Rotated,
+	}
+	if row.Filter != "" {
+		campaign.Filter = json.RawMessage(row.Filter)
+	}
+	return campaign
+}
+
+// Password rotation Repository
+type passwordRotationRepo struct {
+	db *sqlx.DB
+}
+
+// Password rotation Repository constructor
+func NewPasswordRotationRepository(db *sqlx.DB) passwordrotation.Repository {
+	return &passwordRotationRepo{db: db}
+}
+
+// Create campaign and flag the users matching filter in one transaction
+func (r *passwordRotationRepo) CreateByFilter(
+	ctx context.Context,
+	campaign *models.PasswordRotationCampaign,
+	filter *models.PasswordRotationFilter,
+) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.CreateByFilter")
+	defer span.Finish()
+
+	roleNames, err := json.Marshal(append([]string{}, filter.RoleNames...))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "passwordRotationRepo.CreateByFilter.json.Marshal")
+	}
+	return r.create(ctx, "passwordRotationRepo.CreateByFilter", campaign, flagByFilterQuery,
+		string(roleNames), filter.OrganizationID, filter.CreatedBefore, filter.LoginBefore)
+}
+
+// Create campaign and flag the listed users in one transaction
+func (r *passwordRotationRepo) CreateByList(
+	ctx context.Context,
+	campaign *models.PasswordRotationCampaign,
+	userIDs []int,
+	usernames []string,
+) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.CreateByList")
+	defer span.Finish()
+
+	// Ids are passed as text so the json array casts element by element
+	ids := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		ids = append(ids, strconv.Itoa(userID))
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "passwordRotationRepo.CreateByList.json.Marshal")
+	}
+	usernamesJSON, err := json.Marshal(append([]string{}, usernames...))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "passwordRotationRepo.CreateByList.json.Marshal")
+	}
+	return r.create(ctx, "passwordRotationRepo.CreateByList", campaign, flagByListQuery, string(idsJSON), string(usernamesJSON))
+}
+
+func (r *passwordRotationRepo) create(
+	ctx context.Context,
+	op string,
+	campaign *models.PasswordRotationCampaign,
+	flagQuery string,
+	flagArgs ...interface{},
+) (*models.PasswordRotationCampaign, []*models.PasswordRotationUser, error) {
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op+".BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	row := &campaignRow{}
+	if err := tx.QueryRowxContext(
+		ctx,
+		createCampaignQuery,
+		campaign.Name,
+		campaign.Reason,
+		campaign.Source,
+		string(campaign.Filter),
+		campaign.CreatedBy,
+		campaign.CreatedAt,
+	).StructScan(row); err != nil {
+		return nil, nil, errors.Wrap(err, op+".createCampaign")
+	}
+
+	created := row.toCampaign()
+	flagged := make([]*models.PasswordRotationUser, 0)
+	args := append([]interface{}{created.ID, created.CreatedAt}, flagArgs...)
+	if err := tx.SelectContext(ctx, &flagged, flagQuery, args...); err != nil {
+		return nil, nil, errors.Wrap(err, op+".flag")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, errors.Wrap(err, op+".Commit")
+	}
+	created.Flagged = len(flagged)
+	return created, flagged, nil
+}
+
+// Get campaign with its progress
+func (r *passwordRotationRepo) GetByID(ctx context.Context, campaignID int64) (*models.PasswordRotationCampaign, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.GetByID")
+	defer span.Finish()
+
+	row := &campaignRow{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, row, getCampaignQuery, campaignID); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.GetByID.GetContext")
+	}
+	return row.toCampaign(), nil
+}
+
+// List campaigns with their progress, newest first
+func (r *passwordRotationRepo) List(ctx context.Context) ([]*models.PasswordRotationCampaign, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.List")
+	defer span.Finish()
+
+	rows := make([]*campaignRow, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, listCampaignsQuery); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.List.SelectContext")
+	}
+
+	campaigns := make([]*models.PasswordRotationCampaign, 0, len(rows))
+	for _, row := range rows {
+		campaigns = append(campaigns, row.toCampaign())
+	}
+	return campaigns, nil
+}
+
+// Users of the campaign still to rotate
+func (r *passwordRotationRepo) ListPending(ctx context.Context, campaignID int64, limit int) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.ListPending")
+	defer span.Finish()
+
+	userIDs := make([]int, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &userIDs, listPendingQuery, campaignID, limit); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.ListPending.SelectContext")
+	}
+	return userIDs, nil
+}
+
+// Time of the latest rotation in the campaign, nil before the first
+func (r *passwordRotationRepo) LastRotatedAt(ctx context.Context, campaignID int64) (*time.Time, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.LastRotatedAt")
+	defer span.Finish()
+
+	var lastRotatedAt sql.NullTime
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, &lastRotatedAt, lastRotatedAtQuery, campaignID); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.LastRotatedAt.GetContext")
+	}
+	if !lastRotatedAt.Valid {
+		return nil, nil
+	}
+	return &lastRotatedAt.Time, nil
+}
+
+// Cancel campaign, cancelling twice is sql.ErrNoRows
+func (r *passwordRotationRepo) Cancel(ctx context.Context, campaignID int64, now time.Time) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.Cancel")
+	defer span.Finish()
+
+	tx, err := tenant.DB(ctx, r.db).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.Cancel.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	result, err := tx.ExecContext(ctx, cancelCampaignQuery, campaignID, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.Cancel.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.Cancel.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return nil, errors.Wrap(sql.ErrNoRows, "passwordRotationRepo.Cancel.rowsAffected")
+	}
+
+	released := make([]int, 0)
+	if err := tx.SelectContext(ctx, &released, releasedUsersQuery, campaignID); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.Cancel.SelectContext")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.Cancel.Commit")
+	}
+	return released, nil
+}
+
+// Mark every open flag of the user rotated
+func (r *passwordRotationRepo) Complete(ctx context.Context, userID int, now time.Time) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.Complete")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, completeQuery, userID, now)
+	if err != nil {
+		return 0, errors.Wrap(err, "passwordRotationRepo.Complete.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "passwordRotationRepo.Complete.RowsAffected")
+	}
+	return int(rowsAffected), nil
+}
+
+// Users any open campaign requires a rotation of
+func (r *passwordRotationRepo) ListPendingUserIDs(ctx context.Context) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRepo.ListPendingUserIDs")
+	defer span.Finish()
+
+	userIDs := make([]int, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &userIDs, listPendingUserIDsQuery); err != nil {
+		return nil, errors.Wrap(err, "passwordRotationRepo.ListPendingUserIDs.SelectContext")
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+)
+
+// Password rotation redis repository, pending users are members of one set
+type passwordRotationRedisRepo struct {
+	redisClient *redis.Client
+	key         string
+}
+
+// Password rotation redis repository constructor
+func NewPasswordRotationRedisRepo(redisClient *redis.Client, key string) passwordrotation.RedisRepository {
+	return &passwordRotationRedisRepo{redisClient: redisClient, key: key}
+}
+
+// Add users to the pending set
+func (r *passwordRotationRedisRepo) AddPending(ctx context.Context, userIDs []int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRedisRepo.AddPending")
+	defer span.Finish()
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := r.redisClient.SAdd(ctx, r.key, members(userIDs)...).Err(); err != nil {
+		return errors.Wrap(err, "passwordRotationRedisRepo.AddPending.SAdd")
+	}
+	return nil
+}
+
+// Remove users from the pending set
+func (r *passwordRotationRedisRepo) RemovePending(ctx context.Context, userIDs []int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRedisRepo.RemovePending")
+	defer span.Finish()
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := r.redisClient.SRem(ctx, r.key, members(userIDs)...).Err(); err != nil {
+		return errors.Wrap(err, "passwordRotationRedisRepo.RemovePending.SRem")
+	}
+	return nil
+}
+
+// Whether the user is in the pending set
+func (r *passwordRotationRedisRepo) IsPending(ctx context.Context, userID int) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRedisRepo.IsPending")
+	defer span.Finish()
+
+	pending, err := r.redisClient.SIsMember(ctx, r.key, strconv.Itoa(userID)).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "passwordRotationRedisRepo.IsPending.SIsMember")
+	}
+	return pending, nil
+}
+
+// Replace the pending set atomically
+func (r *passwordRotationRedisRepo) ReplacePending(ctx context.Context, userIDs []int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationRedisRepo.ReplacePending")
+	defer span.Finish()
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Del(ctx, r.key)
+	if len(userIDs) > 0 {
+		pipe.SAdd(ctx, r.key, members(userIDs)...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "passwordRotationRedisRepo.ReplacePending.Exec")
+	}
+	return nil
+}
+
+func members(userIDs []int) []interface{} {
+	values := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		values = append(values, strconv.Itoa(userID))
+	}
+	return values
+}
@@ -0,0 +1,78 @@
+package repository
+
+const (
+	createCampaignQuery = `INSERT INTO password_rotation_campaigns (name, reason, source, filter, created_by, created_at)
+						VALUES ($1, $2, $3, NULLIF($4, '')::jsonb, $5, $6)
+						RETURNING id, name, reason, source, COALESCE(filter::text, '') AS filter, created_by, created_at, cancelled_at`
+
+	// Lists are passed as json arrays, accounts waiting for deletion are never flagged
+	flagByFilterQuery = `WITH flagged AS (
+							INSERT INTO password_rotation_users (campaign_id, user_id, flagged_at)
+							SELECT $1, u.id, $2
+							FROM users u
+							WHERE ($3::jsonb = '[]'::jsonb OR EXISTS (
+									SELECT 1
+									FROM user_roles ur
+									JOIN roles r ON r.id = ur.role_id
+									WHERE ur.user_id = u.id AND r.name IN (SELECT jsonb_array_elements_text($3::jsonb))))
+							  AND ($4::bigint IS NULL OR EXISTS (
+									SELECT 1 FROM organization_members om WHERE om.user_id = u.id AND om.organization_id = $4::bigint))
+							  AND ($5::timestamp IS NULL OR u.created_at < $5::timestamp)
+							  AND ($6::timestamp IS NULL OR COALESCE(u.login_at, u.created_at) < $6::timestamp)
+							  AND u.deletion_scheduled_at IS NULL
+							RETURNING user_id
+						)
+						SELECT f.user_id, u.username FROM flagged f JOIN users u ON u.id = f.user_id ORDER BY f.user_id`
+
+	flagByListQuery = `WITH flagged AS (
+							INSERT INTO password_rotation_users (campaign_id, user_id, flagged_at)
+							SELECT DISTINCT $1::bigint, u.id, $2::timestamp
+							FROM users u
+							WHERE (u.id IN (SELECT jsonb_array_elements_text($3::jsonb)::int)
+								OR u.username IN (SELECT jsonb_array_elements_text($4::jsonb)))
+							  AND u.deletion_scheduled_at IS NULL
+							RETURNING user_id
+						)
+						SELECT f.user_id, u.username FROM flagged f JOIN users u ON u.id = f.user_id ORDER BY f.user_id`
+
+	campaignColumns = `SELECT c.id, c.name, c.reason, c.source, COALESCE(c.filter::text, '') AS filter, c.created_by, c.created_at, c.cancelled_at,
+							COUNT(pu.user_id) AS flagged, COUNT(pu.rotated_at) AS rotated
+						FROM password_rotation_campaigns c
+						LEFT JOIN password_rotation_users pu ON pu.campaign_id = c.id`
+
+	getCampaignQuery = campaignColumns + `
+						WHERE c.id = $1
+						GROUP BY c.id`
+
+	listCampaignsQuery = campaignColumns + `
+						GROUP BY c.id
+						ORDER BY c.created_at DESC, c.id DESC`
+
+	listPendingQuery = `SELECT user_id FROM password_rotation_users
+						WHERE campaign_id = $1 AND rotated_at IS NULL
+						ORDER BY user_id
+						LIMIT $2`
+
+	lastRotatedAtQuery = `SELECT MAX(rotated_at) FROM password_rotation_users WHERE campaign_id = $1`
+
+	cancelCampaignQuery = `UPDATE password_rotation_campaigns SET cancelled_at = $2 WHERE id = $1 AND cancelled_at IS NULL`
+
+	// Pending users of the cancelled campaign no open campaign flags anymore
+	releasedUsersQuery = `SELECT pu.user_id FROM password_rotation_users pu
+						WHERE pu.campaign_id = $1 AND pu.rotated_at IS NULL
+						  AND NOT EXISTS (
+							SELECT 1
+							FROM password_rotation_users other
+							JOIN password_rotation_campaigns oc ON oc.id = other.campaign_id
+							WHERE other.user_id = pu.user_id AND other.rotated_at IS NULL AND oc.cancelled_at IS NULL)`
+
+	completeQuery = `UPDATE password_rotation_users pu SET rotated_at = $2
+						FROM password_rotation_campaigns c
+						WHERE c.id = pu.campaign_id AND pu.user_id = $1 AND pu.rotated_at IS NULL AND c.cancelled_at IS NULL`
+
+	listPendingUserIDsQuery = `SELECT DISTINCT pu.user_id
+						FROM password_rotation_users pu
+						JOIN password_rotation_campaigns c ON c.id = pu.campaign_id
+						WHERE pu.rotated_at IS NULL AND c.cancelled_at IS NULL
+						ORDER BY pu.user_id`
+)
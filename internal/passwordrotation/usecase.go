@@ -0,0 +1,27 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package passwordrotation
+
+import (
+	"context"
+	"io"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Password rotation use case
+type UseCase interface {
+	CreateCampaign(ctx context.Context, req *dto.PasswordRotationCampaignRequest) (*models.PasswordRotationCampaign, error)
+	// Cohort of user ids or usernames, one per line or first column of a CSV, observe:nodeadline
+	UploadCampaign(ctx context.Context, name string, reason string, cohort io.Reader) (*models.PasswordRotationCampaign, error)
+	ListCampaigns(ctx context.Context) ([]*models.PasswordRotationCampaign, error)
+	GetReport(ctx context.Context, campaignID int64) (*models.PasswordRotationReport, error)
+	CancelCampaign(ctx context.Context, campaignID int64) error
+	// Whether an open campaign requires the user to change their password
+	IsRequired(ctx context.Context, userID int) (bool, error)
+	// Mark the user's flags rotated after they changed their password
+	Complete(ctx context.Context, userID int) error
+	// Rebuild the pending users mirror from Postgres
+	Load(ctx context.Context) error
+}
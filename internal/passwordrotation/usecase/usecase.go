@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultUploadMaxRows      = 100000
+	defaultReportPendingLimit = 100
+
+	auditActionCampaignCreated   = "password_rotation.campaign_created"
+	auditActionCampaignCancelled = "password_rotation.campaign_cancelled"
+	auditActionRotated           = "password_rotation.rotated"
+)
+
+// Header cells of an uploaded cohort, skipped when on the first row
+var cohortHeaders = map[string]bool{"id": true, "user_id": true, "username": true}
+
+// Password rotation UseCase
+type passwordRotationUC struct {
+	cfg       *config.Config
+	repo      passwordrotation.Repository
+	redisRepo passwordrotation.RedisRepository
+	auditUC   audit.UseCase
+	clock     clock.Clock
+	logger    logger.Logger
+}
+
+// Password rotation UseCase constructor, auditUC may be nil
+func NewPasswordRotationUseCase(
+	cfg *config.Config,
+	repo passwordrotation.Repository,
+	redisRepo passwordrotation.RedisRepository,
+	auditUC audit.UseCase,
+	clk clock.Clock,
+	log logger.Logger,
+) passwordrotation.UseCase {
+	return &passwordRotationUC{cfg: cfg, repo: repo, redisRepo: redisRepo, auditUC: auditUC, clock: clk, logger: log}
+}
+
+// Flag the users matching the filter, or the listed ones, exactly one of them is required
+func (u *passwordRotationUC) CreateCampaign(ctx context.Context, req *dto.PasswordRotationCampaignRequest) (*models.PasswordRotationCampaign, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.CreateCampaign")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "passwordRotationUC.CreateCampaign.ValidateStruct"))
+	}
+	hasFilter, hasList := !req.Filter.Empty(), len(req.UserIDs) > 0
+	if hasFilter == hasList {
+		return nil, httpErrors.NewBadRequestError("either a non-empty filter or user_ids is required")
+	}
+	campaign, err := u.newCampaign(ctx, req.Name, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var flagged []*models.PasswordRotationUser
+	if hasFilter {
+		filter, err := json.Marshal(req.Filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "passwordRotationUC.CreateCampaign.json.Marshal")
+		}
+		campaign.Source = models.PasswordRotationSourceFilter
+		campaign.Filter = filter
+		campaign, flagged, err = u.repo.CreateByFilter(ctx, campaign, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		campaign.Source = models.PasswordRotationSourceList
+		campaign, flagged, err = u.repo.CreateByList(ctx, campaign, req.UserIDs, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	u.flagged(ctx, campaign, flagged)
+	return campaign, nil
+}
+
+// Flag the uploaded cohort, entries matching no user are returned as unmatched
+func (u *passwordRotationUC) UploadCampaign(ctx context.Context, name string, reason string, cohort io.Reader) (*models.PasswordRotationCampaign, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.UploadCampaign")
+	defer span.Finish()
+
+	if name == "" || len(name) > 100 {
+		return nil, httpErrors.NewBadRequestError("name is required and at most 100 characters")
+	}
+	if len(reason) > 1000 {
+		return nil, httpErrors.NewBadRequestError("reason is at most 1000 characters")
+	}
+	userIDs, usernames, err := u.readCohort(cohort)
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := u.newCampaign(ctx, name, reason)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Source = models.PasswordRotationSourceUpload
+
+	campaign, flagged, err := u.repo.CreateByList(ctx, campaign, userIDs, usernames)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Unmatched = unmatched(userIDs, usernames, flagged)
+	u.flagged(ctx, campaign, flagged)
+	return campaign, nil
+}
+
+// List campaigns with their progress, newest first
+func (u *passwordRotationUC) ListCampaigns(ctx context.Context) ([]*models.PasswordRotationCampaign, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.ListCampaigns")
+	defer span.Finish()
+
+	return u.repo.List(ctx)
+}
+
+// Progress of a campaign with the first users still to rotate
+func (u *passwordRotationUC) GetReport(ctx context.Context, campaignID int64) (*models.PasswordRotationReport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.GetReport")
+	defer span.Finish()
+
+	campaign, err := u.repo.GetByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	limit := u.cfg.Rotation.ReportPendingLimit
+	if limit <= 0 {
+		limit = defaultReportPendingLimit
+	}
+	pending, err := u.repo.ListPending(ctx, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	lastRotatedAt, err := u.repo.LastRotatedAt(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PasswordRotationReport{
+		PasswordRotationCampaign: campaign,
+		Pending:                  campaign.Flagged - campaign.Rotated,
+		LastRotatedAt:            lastRotatedAt,
+		PendingUserIDs:           pending,
+	}
+	if campaign.Flagged > 0 {
+		report.CompletionRate = float64(campaign.Rotated) / float64(campaign.Flagged)
+	}
+	return report, nil
+}
+
+// Cancel campaign, its users are released unless another open campaign flags them too
+func (u *passwordRotationUC) CancelCampaign(ctx context.Context, campaignID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.CancelCampaign")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(err)
+	}
+	released, err := u.repo.Cancel(ctx, campaignID, u.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := u.redisRepo.RemovePending(ctx, released); err != nil {
+		return err
+	}
+
+	u.record(ctx, auditActionCampaignCancelled, user.User.ID, "password_rotation:"+strconv.FormatInt(campaignID, 10), map[string]int{
+		"released": len(released),
+	})
+	return nil
+}
+
+// Whether an open campaign requires the user to change their password, always false when disabled
+func (u *passwordRotationUC) IsRequired(ctx context.Context, userID int) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.IsRequired")
+	defer span.Finish()
+
+	if !u.cfg.Rotation.Enabled {
+		return false, nil
+	}
+	return u.redisRepo.IsPending(ctx, userID)
+}
+
+// Mark the user's flags rotated after they changed their password
+func (u *passwordRotationUC) Complete(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.Complete")
+	defer span.Finish()
+
+	completed, err := u.repo.Complete(ctx, userID, u.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := u.redisRepo.RemovePending(ctx, []int{userID}); err != nil {
+		return err
+	}
+	if completed > 0 {
+		u.record(ctx, auditActionRotated, userID, "user:"+strconv.Itoa(userID), map[string]int{"campaigns": completed})
+	}
+	return nil
+}
+
+// Rebuild the pending users mirror from Postgres
+func (u *passwordRotationUC) Load(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passwordRotationUC.Load")
+	defer span.Finish()
+
+	userIDs, err := u.repo.ListPendingUserIDs(ctx)
+	if err != nil {
+		return err
+	}
+	return u.redisRepo.ReplacePending(ctx, userIDs)
+}
+
+// Campaign created by the calling admin
+func (u *passwordRotationUC) newCampaign(ctx context.Context, name string, reason string) (*models.PasswordRotationCampaign, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	createdBy := user.User.ID
+	return &models.PasswordRotationCampaign{
+		Name:      name,
+		Reason:    reason,
+		CreatedBy: &createdBy,
+		CreatedAt: u.clock.Now().UTC(),
+	}, nil
+}
+
+// Mirror the flagged users and audit the campaign. The campaign is already stored, so a failing redis is
+// logged only and the mirror catches up on the next Load
+func (u *passwordRotationUC) flagged(ctx context.Context, campaign *models.PasswordRotationCampaign, flagged []*models.PasswordRotationUser) {
+	userIDs := make([]int, 0, len(flagged))
+	for _, user := range flagged {
+		userIDs = append(userIDs, user.UserID)
+	}
+	if err := u.redisRepo.AddPending(ctx, userIDs); err != nil {
+		u.logger.Errorf("passwordRotationUC.flagged.AddPending campaignID: %d, error: %v", campaign.ID, err)
+	}
+
+	u.record(ctx, auditActionCampaignCreated, *campaign.CreatedBy, "password_rotation:"+strconv.FormatInt(campaign.ID, 10), map[string]interface{}{
+		"name":      campaign.Name,
+		"source":    campaign.Source,
+		"flagged":   len(flagged),
+		"unmatched": len(campaign.Unmatched),
+	})
+}
+
+// First column of every row, numeric entries are user ids and the others usernames. Blank rows and a
+// header row are skipped, duplicates are kept once
+func (u *passwordRotationUC) readCohort(cohort io.Reader) ([]int, []string, error) {
+	maxRows := u.cfg.Rotation.UploadMaxRows
+	if maxRows <= 0 {
+		maxRows = defaultUploadMaxRows
+	}
+
+	reader := csv.NewReader(cohort)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	userIDs := make([]int, 0)
+	usernames := make([]string, 0)
+	seen := make(map[string]bool)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, httpErrors.NewBadRequestError(fmt.Sprintf("cohort row %d: %v", row, err))
+		}
+		entry := strings.TrimSpace(record[0])
+		if entry == "" || (row == 1 && cohortHeaders[strings.ToLower(entry)]) || seen[entry] {
+			continue
+		}
+		if len(seen) == maxRows {
+			return nil, nil, httpErrors.NewRestError(http.StatusRequestEntityTooLarge, "cohort is too large", fmt.Sprintf("%d rows", maxRows))
+		}
+		seen[entry] = true
+
+		if userID, err := strconv.Atoi(entry); err == nil && userID > 0 {
+			userIDs = append(userIDs, userID)
+		} else {
+			usernames = append(usernames, entry)
+		}
+	}
+	if len(seen) == 0 {
+		return nil, nil, httpErrors.NewBadRequestError("cohort is empty")
+	}
+	return userIDs, usernames, nil
+}
+
+// Uploaded entries which matched none of the flagged users
+func unmatched(userIDs []int, usernames []string, flagged []*models.PasswordRotationUser) []string {
+	flaggedIDs := make(map[int]bool, len(flagged))
+	flaggedNames := make(map[string]bool, len(flagged))
+	for _, user := range flagged {
+		flaggedIDs[user.UserID] = true
+		flaggedNames[user.Username] = true
+	}
+
+	missing := make([]string, 0)
+	for _, userID := range userIDs {
+		if !flaggedIDs[userID] {
+			missing = append(missing, strconv.Itoa(userID))
+		}
+	}
+	for _, username := range usernames {
+		if !flaggedNames[username] {
+			missing = append(missing, username)
+		}
+	}
+	return missing
+}
+
+func (u *passwordRotationUC) record(ctx context.Context, action string, actorID int, resource string, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	event := &models.AuditEvent{
+		ActorID:   &actorID,
+		RequestID: requestID,
+		Resource:  resource,
+	}
+	if err := u.auditUC.Record(ctx, action, event, metadata); err != nil {
+		u.logger.Errorf("passwordRotationUC.record.Record action: %s, error: %v", action, err)
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	authRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	organizationsRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+func requirePending(t *testing.T, uc passwordrotation.UseCase, userID int, pending bool) {
+	t.Helper()
+	required, err := uc.IsRequired(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, pending, required, "user %d", userID)
+}
+
+func TestPasswordRotationUC_CreateCampaign(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	cfg := &config.Config{
+		Rotation: config.PasswordRotation{Enabled: true, PendingKey: "rotation:pending", ReportPendingLimit: 1},
+	}
+	// Users alice (1, administrator), bob (2) and carol (3)
+	authRepo := authRepository.NewAuthMemoryRepository()
+	for _, user := range []struct{ name, role string }{{"alice", "administrator"}, {"bob", "employee"}, {"carol", "employee"}} {
+		_, err := authRepo.Register(context.Background(), &models.User{Username: user.name, Email: user.name + "@example.com"}, user.role)
+		require.NoError(t, err)
+	}
+	repo := repository.NewPasswordRotationMemoryRepository(authRepo, organizationsRepository.NewOrganizationsMemoryRepository())
+	clk := clock.NewFrozen(time.Now())
+	uc := NewPasswordRotationUseCase(cfg, repo, repository.NewPasswordRotationRedisRepo(client, cfg.Rotation.PendingKey), nil, clk, testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	_, err := uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{Name: "empty"})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{
+		Name:    "both",
+		Filter:  &models.PasswordRotationFilter{RoleNames: []string{"employee"}},
+		UserIDs: []int{2},
+	})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.CreateCampaign(context.Background(), &dto.PasswordRotationCampaignRequest{Name: "anonymous", UserIDs: []int{2}})
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+
+	campaign, err := uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{
+		Name:   "breach",
+		Filter: &models.PasswordRotationFilter{RoleNames: []string{"employee"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.PasswordRotationSourceFilter, campaign.Source)
+	require.Equal(t, 2, campaign.Flagged)
+	require.JSONEq(t, `{"role_names":["employee"]}`, string(campaign.Filter))
+	requirePending(t, uc, 1, false)
+	requirePending(t, uc, 2, true)
+	requirePending(t, uc, 3, true)
+
+	clk.Advance(time.Minute)
+	require.NoError(t, uc.Complete(context.Background(), 2))
+	requirePending(t, uc, 2, false)
+
+	report, err := uc.GetReport(ctx, campaign.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Rotated)
+	require.Equal(t, 1, report.Pending)
+	require.Equal(t, 0.5, report.CompletionRate)
+	require.Equal(t, []int{3}, report.PendingUserIDs)
+	require.NotNil(t, report.LastRotatedAt)
+
+	_, err = uc.GetReport(ctx, campaign.ID+1)
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+}
+
+func TestPasswordRotationUC_UploadCampaign(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	cfg := &config.Config{
+		Rotation: config.PasswordRotation{Enabled: true, PendingKey: "rotation:pending", UploadMaxRows: 3, ReportPendingLimit: 1},
+	}
+	// Users alice (1, administrator), bob (2) and carol (3)
+	authRepo := authRepository.NewAuthMemoryRepository()
+	for _, user := range []struct{ name, role string }{{"alice", "administrator"}, {"bob", "employee"}, {"carol", "employee"}} {
+		_, err := authRepo.Register(context.Background(), &models.User{Username: user.name, Email: user.name + "@example.com"}, user.role)
+		require.NoError(t, err)
+	}
+	repo := repository.NewPasswordRotationMemoryRepository(authRepo, organizationsRepository.NewOrganizationsMemoryRepository())
+	uc := NewPasswordRotationUseCase(cfg, repo, repository.NewPasswordRotationRedisRepo(client, cfg.Rotation.PendingKey), nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	campaign, err := uc.UploadCampaign(ctx, "upload", "", strings.NewReader("username,note\nbob,leaked\n\n3\n3\nmallory\n"))
+	require.NoError(t, err)
+	require.Equal(t, models.PasswordRotationSourceUpload, campaign.Source)
+	require.Equal(t, 2, campaign.Flagged)
+	require.Equal(t, []string{"mallory"}, campaign.Unmatched)
+	requirePending(t, uc, 2, true)
+	requirePending(t, uc, 3, true)
+
+	_, err = uc.UploadCampaign(ctx, "too large", "", strings.NewReader("1\n2\n3\n4\n"))
+	testutil.RequireStatus(t, err, http.StatusRequestEntityTooLarge)
+	_, err = uc.UploadCampaign(ctx, "empty", "", strings.NewReader("user_id\n\n"))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.UploadCampaign(ctx, "", "", strings.NewReader("2\n"))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestPasswordRotationUC_CancelCampaign(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	cfg := &config.Config{
+		Rotation: config.PasswordRotation{Enabled: true, PendingKey: "rotation:pending", ReportPendingLimit: 1},
+	}
+	// Users alice (1, administrator), bob (2) and carol (3)
+	authRepo := authRepository.NewAuthMemoryRepository()
+	for _, user := range []struct{ name, role string }{{"alice", "administrator"}, {"bob", "employee"}, {"carol", "employee"}} {
+		_, err := authRepo.Register(context.Background(), &models.User{Username: user.name, Email: user.name + "@example.com"}, user.role)
+		require.NoError(t, err)
+	}
+	repo := repository.NewPasswordRotationMemoryRepository(authRepo, organizationsRepository.NewOrganizationsMemoryRepository())
+	uc := NewPasswordRotationUseCase(cfg, repo, repository.NewPasswordRotationRedisRepo(client, cfg.Rotation.PendingKey), nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	first, err := uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{Name: "first", UserIDs: []int{2, 3}})
+	require.NoError(t, err)
+	_, err = uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{Name: "second", UserIDs: []int{3}})
+	require.NoError(t, err)
+
+	// Carol is still flagged by the second campaign
+	require.NoError(t, uc.CancelCampaign(ctx, first.ID))
+	requirePending(t, uc, 2, false)
+	requirePending(t, uc, 3, true)
+	testutil.RequireStatus(t, uc.CancelCampaign(ctx, first.ID), http.StatusNotFound)
+
+	campaigns, err := uc.ListCampaigns(ctx)
+	require.NoError(t, err)
+	require.Len(t, campaigns, 2)
+	require.Equal(t, "second", campaigns[0].Name)
+	require.NotNil(t, campaigns[1].CancelledAt)
+}
+
+func TestPasswordRotationUC_Load(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	cfg := &config.Config{
+		Rotation: config.PasswordRotation{Enabled: true, PendingKey: "rotation:pending", ReportPendingLimit: 1},
+	}
+	// Users alice (1, administrator), bob (2) and carol (3)
+	authRepo := authRepository.NewAuthMemoryRepository()
+	for _, user := range []struct{ name, role string }{{"alice", "administrator"}, {"bob", "employee"}, {"carol", "employee"}} {
+		_, err := authRepo.Register(context.Background(), &models.User{Username: user.name, Email: user.name + "@example.com"}, user.role)
+		require.NoError(t, err)
+	}
+	repo := repository.NewPasswordRotationMemoryRepository(authRepo, organizationsRepository.NewOrganizationsMemoryRepository())
+	uc := NewPasswordRotationUseCase(cfg, repo, repository.NewPasswordRotationRedisRepo(client, cfg.Rotation.PendingKey), nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	_, err := uc.CreateCampaign(ctx, &dto.PasswordRotationCampaignRequest{Name: "first", UserIDs: []int{2}})
+	require.NoError(t, err)
+	require.NoError(t, uc.Complete(ctx, 2))
+
+	require.NoError(t, uc.Load(ctx))
+	requirePending(t, uc, 2, false)
+}
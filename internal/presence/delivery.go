@@ -0,0 +1,9 @@
+package presence
+
+import "github.com/labstack/echo/v4"
+
+// Presence HTTP Handlers interface
+type Handlers interface {
+	GetPresence() echo.HandlerFunc
+	GetPresences() echo.HandlerFunc
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Presence handlers
+type presenceHandlers struct {
+	cfg        *config.Config
+	presenceUC presence.UseCase
+	logger     logger.Logger
+}
+
+// NewPresenceHandlers Presence handlers constructor
+func NewPresenceHandlers(cfg *config.Config, presenceUC presence.UseCase, log logger.Logger) presence.Handlers {
+	return &presenceHandlers{cfg: cfg, presenceUC: presenceUC, logger: log}
+}
+
+// GetPresence godoc
+// @Summary Get user presence
+// @Description Whether the user made a request within the presence TTL and when it was last seen
+// @Tags Users
+// @Produce json
+// @Param user_id path int true "user_id"
+// @Success 200 {object} models.Presence
+// @Failure 400 {object} httpErrors.RestError
+// @Router /users/{user_id}/presence [get]
+func (h *presenceHandlers) GetPresence() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "presenceHandlers.GetPresence")
+		defer span.Finish()
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return err
+		}
+
+		p, err := h.presenceUC.GetPresence(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, p)
+	}
+}
+
+// GetPresences godoc
+// @Summary Get presence of users
+// @Description Presences of up to 100 users in the order they are given
+// @Tags Users
+// @Produce json
+// @Param user_id query []int true "user ids, repeated" collectionFormat(multi)
+// @Success 200 {object} models.PresenceList
+// @Failure 400 {object} httpErrors.RestError
+// @Router /users/presence [get]
+func (h *presenceHandlers) GetPresences() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "presenceHandlers.GetPresences")
+		defer span.Finish()
+
+		raw := c.QueryParams()["user_id"]
+		userIDs := make([]int, 0, len(raw))
+		for _, value := range raw {
+			userID, err := strconv.Atoi(value)
+			if err != nil {
+				return httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error())
+			}
+			userIDs = append(userIDs, userID)
+		}
+
+		presences, err := h.presenceUC.GetPresences(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, presences)
+	}
+}
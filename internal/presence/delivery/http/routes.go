@@ -0,0 +1,16 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+)
+
+// Map presence routes
+func MapPresenceRoutes(usersGroup *echo.Group, h presence.Handlers, mw *middleware.MiddlewareManager) {
+	usersGroup.Use(mw.AuthSessionMiddleware)
+
+	usersGroup.GET("/presence", h.GetPresences())
+	usersGroup.GET("/:user_id/presence", h.GetPresence())
+}
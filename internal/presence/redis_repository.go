@@ -0,0 +1,21 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package presence
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Presence redis repository, online users are a sorted set scored by their last heartbeat
+type RedisRepository interface {
+	// Record a heartbeat, reports whether the user was not online before
+	Touch(ctx context.Context, userID int, at time.Time) (bool, error)
+	// Take users whose last heartbeat is before cutoff out of the online set, at most limit of them. Every user
+	// is returned to a single caller so only one instance publishes it offline
+	Expire(ctx context.Context, cutoff time.Time, limit int) ([]int, error)
+	// Presences of users, online when their last heartbeat is not before cutoff
+	GetMany(ctx context.Context, userIDs []int, cutoff time.Time) ([]*models.Presence, error)
+	Publish(ctx context.Context, event *models.PresenceEvent) error
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+)
+
+const (
+	defaultPrefix = "api-presence:"
+	onlineKey     = "online"
+	lastSeenKey   = "last-seen"
+)
+
+// Expired users leave the online set in the same call that reads them, so concurrent sweeps split them
+var expireScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1], 'LIMIT', 0, ARGV[2])
+if #ids > 0 then
+	redis.call('ZREM', KEYS[1], unpack(ids))
+end
+return ids
+`)
+
+// Presence redis repository
+type presenceRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+	channel     string
+}
+
+// Presence redis repository constructor, an empty prefix uses api-presence:
+func NewPresenceRedisRepo(redisClient *redis.Client, prefix string, channel string) presence.RedisRepository {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &presenceRedisRepo{redisClient: redisClient, prefix: prefix, channel: channel}
+}
+
+// Record a heartbeat
+func (r *presenceRedisRepo) Touch(ctx context.Context, userID int, at time.Time) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceRedisRepo.Touch")
+	defer span.Finish()
+
+	member := strconv.Itoa(userID)
+	pipe := r.redisClient.TxPipeline()
+	added := pipe.ZAdd(ctx, r.prefix+onlineKey, &redis.Z{Score: float64(at.UnixMilli()), Member: member})
+	pipe.HSet(ctx, r.prefix+lastSeenKey, member, at.UnixMilli())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errors.Wrap(err, "presenceRedisRepo.Touch.Exec")
+	}
+	return added.Val() == 1, nil
+}
+
+// Take expired users out of the online set
+func (r *presenceRedisRepo) Expire(ctx context.Context, cutoff time.Time, limit int) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceRedisRepo.Expire")
+	defer span.Finish()
+
+	members, err := expireScript.Run(ctx, r.redisClient, []string{r.prefix + onlineKey}, cutoff.UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, errors.Wrap(err, "presenceRedisRepo.Expire.Run")
+	}
+	userIDs := make([]int, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.Atoi(member)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+// Presences of users
+func (r *presenceRedisRepo) GetMany(ctx context.Context, userIDs []int, cutoff time.Time) ([]*models.Presence, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceRedisRepo.GetMany")
+	defer span.Finish()
+
+	members := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = strconv.Itoa(userID)
+	}
+
+	pipe := r.redisClient.Pipeline()
+	scores := make([]*redis.FloatCmd, len(members))
+	for i, member := range members {
+		scores[i] = pipe.ZScore(ctx, r.prefix+onlineKey, member)
+	}
+	lastSeen := pipe.HMGet(ctx, r.prefix+lastSeenKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, errors.Wrap(err, "presenceRedisRepo.GetMany.Exec")
+	}
+
+	presences := make([]*models.Presence, len(userIDs))
+	for i, userID := range userIDs {
+		p := &models.Presence{UserID: userID}
+		if score, err := scores[i].Result(); err == nil {
+			p.Online = int64(score) >= cutoff.UnixMilli()
+		}
+		if value, ok := lastSeen.Val()[i].(string); ok {
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				seen := time.UnixMilli(ms).UTC()
+				p.LastSeenAt = &seen
+			}
+		}
+		presences[i] = p
+	}
+	return presences, nil
+}
+
+// Publish a presence change on the presence channel
+func (r *presenceRedisRepo) Publish(ctx context.Context, event *models.PresenceEvent) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceRedisRepo.Publish")
+	defer span.Finish()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "presenceRedisRepo.Publish.Marshal")
+	}
+	if err := r.redisClient.Publish(ctx, r.channel, payload).Err(); err != nil {
+		return errors.Wrap(err, "presenceRedisRepo.Publish.Publish")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package presence
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Presence use case
+type UseCase interface {
+	// Heartbeat of the user, written at most once per TouchIntervalSeconds by an instance
+	Touch(ctx context.Context, userID int) error
+	GetPresence(ctx context.Context, userID int) (*models.Presence, error)
+	GetPresences(ctx context.Context, userIDs []int) (*models.PresenceList, error)
+	// Publish users without a heartbeat for TTLSeconds offline, returns how many went offline
+	Sweep(ctx context.Context) (int, error)
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultTTLSeconds           = 120
+	defaultTouchIntervalSeconds = 30
+	maxBulkUsers                = 100
+	sweepBatchSize              = 500
+	// Local touch times are pruned once this many users are tracked
+	maxTouched = 10000
+)
+
+// Presence UseCase
+type presenceUC struct {
+	cfg       *config.Config
+	redisRepo presence.RedisRepository
+	clock     clock.Clock
+	logger    logger.Logger
+
+	mu      sync.Mutex
+	touched map[int]time.Time
+}
+
+// Presence UseCase constructor
+func NewPresenceUseCase(cfg *config.Config, redisRepo presence.RedisRepository, clk clock.Clock, log logger.Logger) presence.UseCase {
+	return &presenceUC{cfg: cfg, redisRepo: redisRepo, clock: clk, logger: log, touched: make(map[int]time.Time)}
+}
+
+// Heartbeat of the user, a user coming online is published
+func (u *presenceUC) Touch(ctx context.Context, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceUC.Touch")
+	defer span.Finish()
+
+	now := u.clock.Now()
+	if !u.shouldTouch(userID, now) {
+		return nil
+	}
+
+	cameOnline, err := u.redisRepo.Touch(ctx, userID, now)
+	if err != nil {
+		u.forgetTouch(userID)
+		return err
+	}
+	if cameOnline {
+		if err := u.redisRepo.Publish(ctx, &models.PresenceEvent{UserID: userID, Status: models.PresenceOnline, At: now}); err != nil {
+			u.logger.Errorf("presenceUC.Touch.Publish userID: %d, error: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// Presence of a user
+func (u *presenceUC) GetPresence(ctx context.Context, userID int) (*models.Presence, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceUC.GetPresence")
+	defer span.Finish()
+
+	presences, err := u.redisRepo.GetMany(ctx, []int{userID}, u.cutoff())
+	if err != nil {
+		return nil, err
+	}
+	return presences[0], nil
+}
+
+// Presences of up to maxBulkUsers users
+func (u *presenceUC) GetPresences(ctx context.Context, userIDs []int) (*models.PresenceList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceUC.GetPresences")
+	defer span.Finish()
+
+	if len(userIDs) == 0 {
+		return &models.PresenceList{Presences: []*models.Presence{}}, nil
+	}
+	if len(userIDs) > maxBulkUsers {
+		return nil, httpErrors.NewBadRequestError("at most 100 users per presence lookup")
+	}
+
+	presences, err := u.redisRepo.GetMany(ctx, userIDs, u.cutoff())
+	if err != nil {
+		return nil, err
+	}
+	return &models.PresenceList{Presences: presences}, nil
+}
+
+// Publish users without a heartbeat for TTLSeconds offline
+func (u *presenceUC) Sweep(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "presenceUC.Sweep")
+	defer span.Finish()
+
+	now, cutoff := u.clock.Now(), u.cutoff()
+	total := 0
+	for {
+		userIDs, err := u.redisRepo.Expire(ctx, cutoff, sweepBatchSize)
+		if err != nil {
+			return total, err
+		}
+		for _, userID := range userIDs {
+			if err := u.redisRepo.Publish(ctx, &models.PresenceEvent{UserID: userID, Status: models.PresenceOffline, At: now}); err != nil {
+				u.logger.Errorf("presenceUC.Sweep.Publish userID: %d, error: %v", userID, err)
+			}
+		}
+		total += len(userIDs)
+		if len(userIDs) < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Users are offline once their last heartbeat is before the cutoff
+func (u *presenceUC) cutoff() time.Time {
+	ttl := u.cfg.Presence.TTLSeconds
+	if ttl <= 0 {
+		ttl = defaultTTLSeconds
+	}
+	return u.clock.Now().Add(-time.Duration(ttl) * time.Second)
+}
+
+// Whether this instance has not written a heartbeat of the user within the touch interval, and records one if so
+func (u *presenceUC) shouldTouch(userID int, now time.Time) bool {
+	interval := u.cfg.Presence.TouchIntervalSeconds
+	if interval <= 0 {
+		interval = defaultTouchIntervalSeconds
+	}
+	window := time.Duration(interval) * time.Second
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if last, ok := u.touched[userID]; ok && now.Sub(last) < window {
+		return false
+	}
+	if len(u.touched) >= maxTouched {
+		for id, last := range u.touched {
+			if now.Sub(last) >= window {
+				delete(u.touched, id)
+			}
+		}
+	}
+	u.touched[userID] = now
+	return true
+}
+
+// A failed heartbeat is retried by the next request
+func (u *presenceUC) forgetTouch(userID int) {
+	u.mu.Lock()
+	delete(u.touched, userID)
+	u.mu.Unlock()
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+func TestPresenceUC(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+	events := redisClient.Subscribe(ctx, "presence_changed")
+	defer events.Close()
+	_, err := events.Receive(ctx)
+	require.NoError(t, err)
+	nextEvent := func() *models.PresenceEvent {
+		msg, err := events.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		event := &models.PresenceEvent{}
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), event))
+		return event
+	}
+
+	cfg := &config.Config{Presence: config.Presence{Channel: "presence_changed", TTLSeconds: 60, TouchIntervalSeconds: 10}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	clk := clock.NewFrozen(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	uc := NewPresenceUseCase(cfg, repository.NewPresenceRedisRepo(redisClient, "", cfg.Presence.Channel), clk, appLogger)
+
+	// Coming online is published once
+	require.NoError(t, uc.Touch(ctx, 1))
+	require.Equal(t, &models.PresenceEvent{UserID: 1, Status: models.PresenceOnline, At: clk.Now()}, nextEvent())
+	clk.Advance(20 * time.Second)
+	require.NoError(t, uc.Touch(ctx, 1))
+
+	presences, err := uc.GetPresences(ctx, []int{1, 2})
+	require.NoError(t, err)
+	seen := clk.Now()
+	require.Equal(t, []*models.Presence{{UserID: 1, Online: true, LastSeenAt: &seen}, {UserID: 2}}, presences.Presences)
+
+	// Past the TTL the user reads offline and the sweep publishes it once
+	clk.Advance(61 * time.Second)
+	p, err := uc.GetPresence(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, p.Online)
+	require.Equal(t, seen, *p.LastSeenAt)
+
+	swept, err := uc.Sweep(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, swept)
+	require.Equal(t, &models.PresenceEvent{UserID: 1, Status: models.PresenceOffline, At: clk.Now()}, nextEvent())
+	swept, err = uc.Sweep(ctx)
+	require.NoError(t, err)
+	require.Zero(t, swept)
+
+	require.NoError(t, uc.Touch(ctx, 1))
+	require.Equal(t, models.PresenceOnline, nextEvent().Status)
+
+	_, err = uc.GetPresences(ctx, make([]int, maxBulkUsers+1))
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, err.(httpErrors.RestErr).Status())
+}
+
+func TestPresenceUC_TouchThrottle(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cfg := &config.Config{Presence: config.Presence{Channel: "presence_changed", TouchIntervalSeconds: 10}}
+	clk := clock.NewFrozen(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	uc := NewPresenceUseCase(cfg, repository.NewPresenceRedisRepo(redisClient, "", cfg.Presence.Channel), clk, nil)
+	ctx := context.Background()
+
+	require.NoError(t, uc.Touch(ctx, 1))
+	first := mr.HGet("api-presence:last-seen", "1")
+	clk.Advance(5 * time.Second)
+	require.NoError(t, uc.Touch(ctx, 1))
+	require.Equal(t, first, mr.HGet("api-presence:last-seen", "1"))
+	clk.Advance(5 * time.Second)
+	require.NoError(t, uc.Touch(ctx, 1))
+	require.NotEqual(t, first, mr.HGet("api-presence:last-seen", "1"))
+}
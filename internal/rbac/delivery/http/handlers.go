@@ -2,11 +2,14 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/opentracing/opentracing-go"
@@ -15,6 +18,10 @@ import (
 // Auth HTTP Handlers interface
 type Handlers interface {
 	GetRoles() echo.HandlerFunc
+	GrantRole() echo.HandlerFunc
+	ListGrants() echo.HandlerFunc
+	RevokeGrant() echo.HandlerFunc
+	CheckPermissions() echo.HandlerFunc
 }
 
 type rbacHandlers struct {
@@ -46,16 +53,137 @@ func (h *rbacHandlers) GetRoles() echo.HandlerFunc {
 
 		paginationQuery, err := utils.GetPaginationFromCtx(c)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		RolesList, err := h.rbacUsecase.GetRoles(ctx, paginationQuery)
 		if err != nil {
-			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return err
 		}
 
 		return c.JSON(http.StatusOK, RolesList)
 	}
 }
+
+// GrantRole godoc
+// @Summary Grant role temporarily
+// @Description Grant a role to the user on top of their own until expires_at, admin only
+// @Tags RBAC
+// @Accept json
+// @Produce json
+// @Param user_id path int true "user_id"
+// @Param grant body dto.RoleGrantRequest true "grant"
+// @Success 201 {object} models.RoleGrant
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/users/{user_id}/role-grants [post]
+func (h *rbacHandlers) GrantRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rbacHandlers.GrantRole")
+		defer span.Finish()
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+		req := &dto.RoleGrantRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		grant, err := h.rbacUsecase.GrantRole(ctx, userID, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, grant)
+	}
+}
+
+// ListGrants godoc
+// @Summary List role grants
+// @Description Role grants of the user including expired and revoked ones, newest first, admin only
+// @Tags RBAC
+// @Produce json
+// @Param user_id path int true "user_id"
+// @Success 200 {array} models.RoleGrant
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/users/{user_id}/role-grants [get]
+func (h *rbacHandlers) ListGrants() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rbacHandlers.ListGrants")
+		defer span.Finish()
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		grants, err := h.rbacUsecase.ListGrants(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, grants)
+	}
+}
+
+// RevokeGrant godoc
+// @Summary Revoke role grant
+// @Description Revoke a role grant before it expires, admin only
+// @Tags RBAC
+// @Param grant_id path int true "grant_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/role-grants/{grant_id} [delete]
+func (h *rbacHandlers) RevokeGrant() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rbacHandlers.RevokeGrant")
+		defer span.Finish()
+
+		grantID, err := strconv.ParseInt(c.Param("grant_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.rbacUsecase.RevokeGrant(ctx, grantID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// CheckPermissions godoc
+// @Summary Check permissions
+// @Description Allow or deny each resource and action for the current user in request order, for gating UI elements
+// @Tags RBAC
+// @Accept json
+// @Produce json
+// @Param checks body dto.PermissionCheckRequest true "checks"
+// @Success 200 {object} models.PermissionCheckResults
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/permissions/check [post]
+func (h *rbacHandlers) CheckPermissions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rbacHandlers.CheckPermissions")
+		defer span.Finish()
+
+		user, ok := requestctx.User.Get(c)
+		if !ok {
+			return httpErrors.NewUnauthorizedError(httpErrors.Unauthorized)
+		}
+		req := &dto.PermissionCheckRequest{}
+		if err := c.Bind(req); err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		results, err := h.rbacUsecase.CheckUserPermissions(ctx, user, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, results)
+	}
+}
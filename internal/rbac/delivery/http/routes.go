@@ -9,5 +9,13 @@ import (
 
 func MapRbacRoutes(rGroup *echo.Group, h Handlers, mw *middleware.MiddlewareManager, authUsecase auth.UseCase, cfg *config.Config) {
 	rGroup.Use(mw.AuthJWTMiddleware(authUsecase, cfg))
-	rGroup.GET("/roles/all", h.GetRoles(), mw.AdminMiddleware)
+	rGroup.GET("/roles/all", h.GetRoles(), mw.RequirePermission("roles:read"))
+	rGroup.POST("/permissions/check", h.CheckPermissions())
+}
+
+// Map temporary role grant routes, group is already restricted to administrators
+func MapRoleGrantRoutes(adminGroup *echo.Group, h Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.POST("/users/:user_id/role-grants", h.GrantRole(), mw.CSRF)
+	adminGroup.GET("/users/:user_id/role-grants", h.ListGrants())
+	adminGroup.DELETE("/role-grants/:grant_id", h.RevokeGrant(), mw.CSRF)
 }
@@ -0,0 +1,79 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+)
+
+type memoCtxKey struct{}
+
+// Role permissions resolved while serving one request, later checks of the request reuse them
+type Memo struct {
+	mu     sync.Mutex
+	roles  map[string][]string
+	grants map[int][]string
+}
+
+// Attach an empty memo to ctx, done once per request
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoCtxKey{}, &Memo{roles: make(map[string][]string), grants: make(map[int][]string)})
+}
+
+// Memo of the request, nil outside of one. A nil memo holds nothing and ignores writes
+func MemoFromCtx(ctx context.Context) *Memo {
+	memo, _ := ctx.Value(memoCtxKey{}).(*Memo)
+	return memo
+}
+
+// Permissions of the memoized roles
+func (m *Memo) Get(roles []string) map[string][]string {
+	found := make(map[string][]string)
+	if m == nil {
+		return found
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, role := range roles {
+		if permissions, ok := m.roles[role]; ok {
+			found[role] = permissions
+		}
+	}
+	return found
+}
+
+// Remember permissions of the roles
+func (m *Memo) Put(permissions map[string][]string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for role, granted := range permissions {
+		m.roles[role] = granted
+	}
+}
+
+// Granted roles of the user, false when not memoized
+func (m *Memo) GetGrants(userID int) ([]string, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	roles, ok := m.grants[userID]
+	return roles, ok
+}
+
+// Remember granted roles of the user
+func (m *Memo) PutGrants(userID int, roles []string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grants[userID] = roles
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Temporary role grants
+type GrantRepository interface {
+	// Grant the role, sql.ErrNoRows when the user or the role does not exist
+	CreateGrant(ctx context.Context, grant *models.RoleGrant) (*models.RoleGrant, error)
+	// Grants of the user including revoked ones, newest first
+	ListGrants(ctx context.Context, userID int) ([]*models.RoleGrant, error)
+	// Sorted names of the roles granted to the user and not expired at now
+	GetActiveGrantRoles(ctx context.Context, userID int, now time.Time) ([]string, error)
+	// Revoke the grant early, sql.ErrNoRows when it does not exist or is already revoked
+	RevokeGrant(ctx context.Context, grantID int64, now time.Time) (*models.RoleGrant, error)
+	// Revoke up to limit grants expired at now, returns them
+	RevokeExpired(ctx context.Context, now time.Time, limit int) ([]*models.RoleGrant, error)
+}
+
+type grantRepo struct {
+	db *sqlx.DB
+}
+
+// Role grant Repository constructor
+func NewGrantRepository(db *sqlx.DB) GrantRepository {
+	return &grantRepo{db: db}
+}
+
+func (r *grantRepo) CreateGrant(ctx context.Context, grant *models.RoleGrant) (*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "grantRepo.CreateGrant")
+	defer span.Finish()
+
+	created := &models.RoleGrant{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createRoleGrant,
+		grant.UserID,
+		grant.Role,
+		grant.GrantedBy,
+		grant.Reason,
+		grant.ExpiresAt,
+		grant.CreatedAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "grantRepo.CreateGrant.StructScan")
+	}
+	return created, nil
+}
+
+func (r *grantRepo) ListGrants(ctx context.Context, userID int) ([]*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "grantRepo.ListGrants")
+	defer span.Finish()
+
+	grants := make([]*models.RoleGrant, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &grants, listRoleGrants, userID); err != nil {
+		return nil, errors.Wrap(err, "grantRepo.ListGrants.SelectContext")
+	}
+	return grants, nil
+}
+
+func (r *grantRepo) GetActiveGrantRoles(ctx context.Context, userID int, now time.Time) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "grantRepo.GetActiveGrantRoles")
+	defer span.Finish()
+
+	roles := make([]string, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &roles, getActiveGrantRoles, userID, now); err != nil {
+		return nil, errors.Wrap(err, "grantRepo.GetActiveGrantRoles.SelectContext")
+	}
+	return roles, nil
+}
+
+func (r *grantRepo) RevokeGrant(ctx context.Context, grantID int64, now time.Time) (*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "grantRepo.RevokeGrant")
+	defer span.Finish()
+
+	revoked := &models.RoleGrant{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(ctx, revokeRoleGrant, grantID, now).StructScan(revoked); err != nil {
+		return nil, errors.Wrap(err, "grantRepo.RevokeGrant.StructScan")
+	}
+	return revoked, nil
+}
+
+func (r *grantRepo) RevokeExpired(ctx context.Context, now time.Time, limit int) ([]*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "grantRepo.RevokeExpired")
+	defer span.Finish()
+
+	revoked := make([]*models.RoleGrant, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &revoked, revokeExpiredRoleGrants, now, limit); err != nil {
+		return nil, errors.Wrap(err, "grantRepo.RevokeExpired.SelectContext")
+	}
+	return revoked, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Roles seeded by the initial migration
+var seedRoles = []models.Role{
+	{ID: 1, Name: "administrator", Description: "Administrator"},
+	{ID: 2, Name: "employee", Description: "Employee User"},
+}
+
+type roleMemoryRepo struct {
+	roles []models.Role
+}
+
+// Role in-memory Repository constructor with the migration seed, dev mode stand-in for Postgres
+func NewRoleMemoryRepository() RoleRepository {
+	return &roleMemoryRepo{roles: seedRoles}
+}
+
+func (r *roleMemoryRepo) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "roleMemoryRepo.GetRoles")
+	defer span.Finish()
+
+	roles := make([]*models.Role, 0, len(r.roles))
+	for i := range r.roles {
+		role := r.roles[i]
+		roles = append(roles, &role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	totalCount := len(roles)
+	offset, limit := pq.GetOffset(), pq.GetLimit()
+	if offset > totalCount {
+		offset = totalCount
+	}
+	if limit > 0 && offset+limit < totalCount {
+		roles = roles[offset : offset+limit]
+	} else {
+		roles = roles[offset:]
+	}
+
+	return &models.RolesList{
+		TotalCount: totalCount,
+		TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		Roles:      roles,
+	}, nil
+}
+
+// Seeded roles carry no permissions, the configured role permissions apply in dev mode
+func (r *roleMemoryRepo) GetPermissionsByRoles(ctx context.Context, roles []string) (map[string][]string, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "roleMemoryRepo.GetPermissionsByRoles")
+	defer span.Finish()
+
+	return make(map[string][]string), nil
+}
+
+type grantMemoryRepo struct {
+	mu     sync.Mutex
+	roles  []models.Role
+	grants []*models.RoleGrant
+	nextID int64
+}
+
+// Role grant in-memory Repository constructor, dev mode stand-in for Postgres. Roles are the migration seed,
+// users are not checked
+func NewGrantMemoryRepository() GrantRepository {
+	return &grantMemoryRepo{roles: seedRoles}
+}
+
+func (r *grantMemoryRepo) CreateGrant(ctx context.Context, grant *models.RoleGrant) (*models.RoleGrant, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "grantMemoryRepo.CreateGrant")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	known := false
+	for _, role := range r.roles {
+		known = known || role.Name == grant.Role
+	}
+	if !known {
+		return nil, errors.Wrap(sql.ErrNoRows, "grantMemoryRepo.CreateGrant")
+	}
+	r.nextID++
+	created := *grant
+	created.ID = r.nextID
+	r.grants = append(r.grants, &created)
+	stored := created
+	return &stored, nil
+}
+
+func (r *grantMemoryRepo) ListGrants(ctx context.Context, userID int) ([]*models.RoleGrant, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "grantMemoryRepo.ListGrants")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	grants := make([]*models.RoleGrant, 0)
+	for i := len(r.grants) - 1; i >= 0; i-- {
+		if r.grants[i].UserID == userID {
+			grant := *r.grants[i]
+			grants = append(grants, &grant)
+		}
+	}
+	return grants, nil
+}
+
+func (r *grantMemoryRepo) GetActiveGrantRoles(ctx context.Context, userID int, now time.Time) ([]string, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "grantMemoryRepo.GetActiveGrantRoles")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	roles := make([]string, 0)
+	for _, grant := range r.grants {
+		if grant.UserID == userID && grant.Active(now) && !seen[grant.Role] {
+			seen[grant.Role] = true
+			roles = append(roles, grant.Role)
+		}
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+func (r *grantMemoryRepo) RevokeGrant(ctx context.Context, grantID int64, now time.Time) (*models.RoleGrant, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "grantMemoryRepo.RevokeGrant")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, grant := range r.grants {
+		if grant.ID == grantID && grant.RevokedAt == nil {
+			revokedAt := now
+			grant.RevokedAt = &revokedAt
+			revoked := *grant
+			return &revoked, nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "grantMemoryRepo.RevokeGrant")
+}
+
+func (r *grantMemoryRepo) RevokeExpired(ctx context.Context, now time.Time, limit int) ([]*models.RoleGrant, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "grantMemoryRepo.RevokeExpired")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make([]*models.RoleGrant, 0)
+	for _, grant := range r.grants {
+		if grant.RevokedAt == nil && !now.Before(grant.ExpiresAt) {
+			expired = append(expired, grant)
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(expired[j].ExpiresAt) })
+	if limit > 0 && len(expired) > limit {
+		expired = expired[:limit]
+	}
+
+	revoked := make([]*models.RoleGrant, 0, len(expired))
+	for _, grant := range expired {
+		revokedAt := now
+		grant.RevokedAt = &revokedAt
+		copied := *grant
+		revoked = append(revoked, &copied)
+	}
+	return revoked, nil
+}
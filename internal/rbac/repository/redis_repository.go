@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+const permissionsPrefix = "api-rbac-permissions:"
+
+type RoleRedisRepository interface {
+	GetPermissions(ctx context.Context, roles []string) (map[string][]string, error)
+	SetPermissions(ctx context.Context, permissions map[string][]string, seconds int) error
+}
+
+type roleRedisRepo struct {
+	redisClient *redis.Client
+}
+
+// Role redis repository constructor, caches resolved permissions per role name
+func NewRoleRedisRepository(redisClient *redis.Client) RoleRedisRepository {
+	return &roleRedisRepo{redisClient: redisClient}
+}
+
+// Cached permissions of the roles in one MGET, roles missing from the cache are left out
+func (r *roleRedisRepo) GetPermissions(ctx context.Context, roles []string) (map[string][]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRedisRepo.GetPermissions")
+	defer span.Finish()
+
+	keys := make([]string, 0, len(roles))
+	for _, role := range roles {
+		keys = append(keys, permissionsPrefix+role)
+	}
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "roleRedisRepo.GetPermissions.MGet")
+	}
+
+	permissions := make(map[string][]string, len(roles))
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		granted := make([]string, 0)
+		if err := json.Unmarshal([]byte(raw), &granted); err != nil {
+			return nil, errors.Wrap(err, "roleRedisRepo.GetPermissions.json.Unmarshal")
+		}
+		permissions[roles[i]] = granted
+	}
+	return permissions, nil
+}
+
+// Cache permissions of the roles with duration in seconds in one pipeline
+func (r *roleRedisRepo) SetPermissions(ctx context.Context, permissions map[string][]string, seconds int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRedisRepo.SetPermissions")
+	defer span.Finish()
+
+	pipe := r.redisClient.Pipeline()
+	for role, granted := range permissions {
+		raw, err := json.Marshal(granted)
+		if err != nil {
+			return errors.Wrap(err, "roleRedisRepo.SetPermissions.json.Marshal")
+		}
+		pipe.Set(ctx, permissionsPrefix+role, raw, time.Duration(seconds)*time.Second)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "roleRedisRepo.SetPermissions.Exec")
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/opentracing/opentracing-go"
@@ -12,6 +13,7 @@ import (
 
 type RoleRepository interface {
 	GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error)
+	GetPermissionsByRoles(ctx context.Context, roles []string) (map[string][]string, error)
 	// AssignUserRole(ctx context.Context, userId int, roleId int) (*models.UserWithRole, error)
 }
 
@@ -28,7 +30,7 @@ func (r *roleRepo) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*mo
 	defer span.Finish()
 
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotal); err != nil {
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, &totalCount, getTotal); err != nil {
 		return nil, errors.Wrap(err, "roleRepo.GetRoles.GetContext.totalCount")
 	}
 
@@ -44,7 +46,7 @@ func (r *roleRepo) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*mo
 	}
 
 	var roles = make([]*models.Role, 0, pq.GetSize())
-	if err := r.db.SelectContext(
+	if err := tenant.DB(ctx, r.db).SelectContext(
 		ctx,
 		&roles,
 		fetchRolesList,
@@ -65,6 +67,30 @@ func (r *roleRepo) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*mo
 	}, nil
 }
 
+// Permission names granted to each of the roles in one query, roles without permissions are left out
+func (r *roleRepo) GetPermissionsByRoles(ctx context.Context, roles []string) (map[string][]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRepo.GetPermissionsByRoles")
+	defer span.Finish()
+
+	query, args, err := sqlx.In(getPermissionsByRoles, roles)
+	if err != nil {
+		return nil, errors.Wrap(err, "roleRepo.GetPermissionsByRoles.In")
+	}
+	var rows []struct {
+		Role       string `db:"role"`
+		Permission string `db:"permission"`
+	}
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "roleRepo.GetPermissionsByRoles.SelectContext")
+	}
+
+	permissions := make(map[string][]string, len(roles))
+	for _, row := range rows {
+		permissions[row.Role] = append(permissions[row.Role], row.Permission)
+	}
+	return permissions, nil
+}
+
 // func (r *roleRepo) AssignUserRole(ctx context.Context, userId int, roleId int) (*models.UserWithRole, error) {
 
 // }
@@ -7,4 +7,59 @@ const (
 		ORDER BY COALESCE(NULLIF($1, ''), name) OFFSET $2 LIMIT $3
 	`
 	getTotal = `SELECT COUNT(id) FROM roles`
+
+	getPermissionsByRoles = `
+		SELECT DISTINCT r.name AS role, p.name AS permission
+		FROM roles r
+		JOIN role_permissions rp ON rp.role_id = r.id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.name IN (?)
+		ORDER BY r.name, p.name
+	`
+)
+
+const (
+	createRoleGrant = `
+		INSERT INTO role_grants (user_id, role_id, granted_by, reason, expires_at, created_at)
+		SELECT u.id, r.id, $3, $4, $5, $6
+		FROM users u, roles r
+		WHERE u.id = $1 AND r.name = $2
+		RETURNING id, user_id, $2::text AS role, granted_by, reason, expires_at, created_at, revoked_at
+	`
+
+	listRoleGrants = `
+		SELECT g.id, g.user_id, r.name AS role, g.granted_by, g.reason, g.expires_at, g.created_at, g.revoked_at
+		FROM role_grants g
+		JOIN roles r ON r.id = g.role_id
+		WHERE g.user_id = $1
+		ORDER BY g.created_at DESC, g.id DESC
+	`
+
+	getActiveGrantRoles = `
+		SELECT DISTINCT r.name
+		FROM role_grants g
+		JOIN roles r ON r.id = g.role_id
+		WHERE g.user_id = $1 AND g.revoked_at IS NULL AND g.expires_at > $2
+		ORDER BY r.name
+	`
+
+	revokeRoleGrant = `
+		UPDATE role_grants g SET revoked_at = $2
+		FROM roles r
+		WHERE g.id = $1 AND g.revoked_at IS NULL AND r.id = g.role_id
+		RETURNING g.id, g.user_id, r.name AS role, g.granted_by, g.reason, g.expires_at, g.created_at, g.revoked_at
+	`
+
+	revokeExpiredRoleGrants = `
+		UPDATE role_grants g SET revoked_at = $1
+		FROM roles r
+		WHERE r.id = g.role_id AND g.id IN (
+			SELECT id FROM role_grants
+			WHERE revoked_at IS NULL AND expires_at <= $1
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING g.id, g.user_id, r.name AS role, g.granted_by, g.reason, g.expires_at, g.created_at, g.revoked_at
+	`
 )
@@ -1,12 +1,30 @@
+//go:generate go run ../../cmd/gen decorator -interface RbacUsecase usecase.go
 package rbac
 
 import (
 	"context"
 
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
 type RbacUsecase interface {
 	GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error)
+	ResolvePermissions(ctx context.Context, roles []string) (map[string][]string, error)
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+	// Own role of the user and the roles granted to them which have not expired
+	UserRoles(ctx context.Context, user *models.UserWithRole) ([]string, error)
+	// Whether the user's own role or one of their active grants is granted the permission
+	HasUserPermission(ctx context.Context, user *models.UserWithRole, permission string) (bool, error)
+	// Allow or deny each check for the user, roles and their permissions are resolved once for all of them
+	CheckUserPermissions(ctx context.Context, user *models.UserWithRole, req *dto.PermissionCheckRequest) (*models.PermissionCheckResults, error)
+	// Grant the role to the user until req.ExpiresAt, the caller is recorded as granter
+	GrantRole(ctx context.Context, userID int, req *dto.RoleGrantRequest) (*models.RoleGrant, error)
+	// Grants of the user including revoked ones, newest first
+	ListGrants(ctx context.Context, userID int) ([]*models.RoleGrant, error)
+	// Revoke the grant before it expires
+	RevokeGrant(ctx context.Context, grantID int64) error
+	// Revoke expired grants and notify their users, returns how many were revoked
+	RevokeExpiredGrants(ctx context.Context) (int, error)
 }
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultGrantExpiryBatchSize = 500
+
+	auditActionRoleGranted      = "rbac.role_granted"
+	auditActionRoleGrantRevoked = "rbac.role_grant_revoked"
+	auditActionRoleGrantExpired = "rbac.role_grant_expired"
+)
+
+// Own role of the user and the roles granted to them which have not expired, memoized for the request.
+// Grants are read with the expiry at now, so they stop applying before the scheduler revokes them
+func (u *rbacUsecase) UserRoles(ctx context.Context, user *models.UserWithRole) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.UserRoles")
+	defer span.Finish()
+
+	memo := rbac.MemoFromCtx(ctx)
+	granted, ok := memo.GetGrants(user.User.ID)
+	if !ok {
+		var err error
+		granted, err = u.grantRepo.GetActiveGrantRoles(ctx, user.User.ID, u.clock.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		memo.PutGrants(user.User.ID, granted)
+	}
+	return normalizeRoles(append([]string{user.Role.Name}, granted...)), nil
+}
+
+// Whether the user's own role or one of their active grants is granted the permission
+func (u *rbacUsecase) HasUserPermission(ctx context.Context, user *models.UserWithRole, permission string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.HasUserPermission")
+	defer span.Finish()
+
+	granted, err := u.userPermissions(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return granted[permission], nil
+}
+
+// Answer every check with one resolution of the user's roles, so a page gates all its elements in one call
+func (u *rbacUsecase) CheckUserPermissions(ctx context.Context, user *models.UserWithRole, req *dto.PermissionCheckRequest) (*models.PermissionCheckResults, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.CheckUserPermissions")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "rbacUsecase.CheckUserPermissions.ValidateStruct"))
+	}
+	granted, err := u.userPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.PermissionCheckResult, 0, len(req.Checks))
+	for _, check := range req.Checks {
+		results = append(results, models.PermissionCheckResult{
+			Resource: check.Resource,
+			Action:   check.Action,
+			Allowed:  granted[check.Resource+":"+check.Action],
+		})
+	}
+	return &models.PermissionCheckResults{Results: results}, nil
+}
+
+// Permissions of the user's own role and active grants together
+func (u *rbacUsecase) userPermissions(ctx context.Context, user *models.UserWithRole) (map[string]bool, error) {
+	roles, err := u.UserRoles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := u.ResolvePermissions(ctx, roles)
+	if err != nil {
+		return nil, err
+	}
+	granted := make(map[string]bool)
+	for _, role := range roles {
+		for _, permission := range resolved[role] {
+			granted[permission] = true
+		}
+	}
+	return granted, nil
+}
+
+// Grant the role to the user until req.ExpiresAt, at most Access.Grants.MaxHours ahead when set
+func (u *rbacUsecase) GrantRole(ctx context.Context, userID int, req *dto.RoleGrantRequest) (*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.GrantRole")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "rbacUsecase.GrantRole.ValidateStruct"))
+	}
+	actor, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	now := u.clock.Now().UTC()
+	expiresAt := req.ExpiresAt.UTC()
+	if !expiresAt.After(now) {
+		return nil, httpErrors.NewBadRequestError("expires_at must be in the future")
+	}
+	if maxHours := u.cfg.Access.Grants.MaxHours; maxHours > 0 && expiresAt.After(now.Add(time.Duration(maxHours)*time.Hour)) {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("expires_at must be within %d hours", maxHours))
+	}
+
+	grantedBy := actor.User.ID
+	grant, err := u.grantRepo.CreateGrant(ctx, &models.RoleGrant{
+		UserID:    userID,
+		Role:      strings.ToLower(req.Role),
+		GrantedBy: &grantedBy,
+		Reason:    req.Reason,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.record(ctx, auditActionRoleGranted, &grantedBy, "user:"+strconv.Itoa(userID), map[string]interface{}{
+		"grant_id":   grant.ID,
+		"role":       grant.Role,
+		"expires_at": grant.ExpiresAt,
+		"reason":     grant.Reason,
+	})
+	return grant, nil
+}
+
+// Grants of the user including revoked ones, newest first
+func (u *rbacUsecase) ListGrants(ctx context.Context, userID int) ([]*models.RoleGrant, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.ListGrants")
+	defer span.Finish()
+
+	return u.grantRepo.ListGrants(ctx, userID)
+}
+
+// Revoke the grant before it expires, revoking twice is not found
+func (u *rbacUsecase) RevokeGrant(ctx context.Context, grantID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.RevokeGrant")
+	defer span.Finish()
+
+	actor, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(err)
+	}
+	grant, err := u.grantRepo.RevokeGrant(ctx, grantID, u.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	u.record(ctx, auditActionRoleGrantRevoked, &actor.User.ID, "user:"+strconv.Itoa(grant.UserID), map[string]interface{}{
+		"grant_id": grant.ID,
+		"role":     grant.Role,
+	})
+	return nil
+}
+
+// Revoke expired grants in batches until none is left, each revocation is audited without an actor and
+// published to the user's webhooks. A failing audit or webhook is logged only, the grant stays revoked
+func (u *rbacUsecase) RevokeExpiredGrants(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.RevokeExpiredGrants")
+	defer span.Finish()
+
+	batchSize := u.cfg.Access.Grants.ExpiryBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultGrantExpiryBatchSize
+	}
+
+	var total int
+	for {
+		revoked, err := u.grantRepo.RevokeExpired(ctx, u.clock.Now().UTC(), batchSize)
+		if err != nil {
+			return total, err
+		}
+		for _, grant := range revoked {
+			u.expired(ctx, grant)
+		}
+		total += len(revoked)
+		if len(revoked) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (u *rbacUsecase) expired(ctx context.Context, grant *models.RoleGrant) {
+	u.record(ctx, auditActionRoleGrantExpired, nil, "user:"+strconv.Itoa(grant.UserID), map[string]interface{}{
+		"grant_id":   grant.ID,
+		"role":       grant.Role,
+		"expires_at": grant.ExpiresAt,
+	})
+	if u.webhooksUC == nil {
+		return
+	}
+	if err := u.webhooksUC.Publish(ctx, grant.UserID, models.WebhookEventRoleGrantExpired, map[string]interface{}{
+		"grant_id":   grant.ID,
+		"role":       grant.Role,
+		"expires_at": grant.ExpiresAt,
+	}); err != nil {
+		u.logger.Errorf("rbacUsecase.expired.Publish grantID: %d, error: %v", grant.ID, err)
+	}
+}
+
+func (u *rbacUsecase) record(ctx context.Context, action string, actorID *int, resource string, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	event := &models.AuditEvent{
+		ActorID:   actorID,
+		RequestID: requestID,
+		Resource:  resource,
+	}
+	if err := u.auditUC.Record(ctx, action, event, metadata); err != nil {
+		u.logger.Errorf("rbacUsecase.record.Record action: %s, error: %v", action, err)
+	}
+}
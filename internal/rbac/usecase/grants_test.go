@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+	rbacRepo "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	webhooksMock "github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+// Audit use case keeping the recorded actions
+type recordingAudit struct {
+	audit.UseCase
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *recordingAudit) Record(_ context.Context, action string, _ *models.AuditEvent, _ interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func testUser(id int, role string) *models.UserWithRole {
+	return &models.UserWithRole{User: models.User{ID: id}, Role: models.Role{Name: role}}
+}
+
+func TestRbacUsecase_RoleGrants(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	cfg := &config.Config{Access: config.Access{
+		CacheSeconds:      60,
+		LocalCacheSeconds: 60,
+		RolePermissions: map[string][]string{
+			"administrator": {"users:read", "users:delete"},
+			"user":          {"profile:write"},
+		},
+	}}
+	repo := &countingRoleRepo{}
+	clk := clock.NewFrozen(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	recorder := &recordingAudit{}
+	webhooksUC := webhooksMock.NewMockUseCase(gomock.NewController(t))
+	uc := NewRbacUsecase(cfg, repo, rbacRepo.NewGrantMemoryRepository(), rbacRepo.NewRoleRedisRepository(redisClient), recorder, webhooksUC, clk, nil, testutil.Logger(cfg))
+
+	admin := testUser(1, "administrator")
+	ctx := requestctx.User.With(context.Background(), admin)
+	employee := testUser(2, "user")
+
+	// Expiry has to be in the future and within MaxHours
+	_, err := uc.GrantRole(ctx, employee.User.ID, &dto.RoleGrantRequest{Role: "administrator", ExpiresAt: clk.Now().Add(-time.Minute)})
+	require.Error(t, err)
+	cfg.Access.Grants.MaxHours = 24
+	_, err = uc.GrantRole(ctx, employee.User.ID, &dto.RoleGrantRequest{Role: "administrator", ExpiresAt: clk.Now().Add(25 * time.Hour)})
+	require.Error(t, err)
+	_, err = uc.GrantRole(ctx, employee.User.ID, &dto.RoleGrantRequest{Role: "auditor", ExpiresAt: clk.Now().Add(time.Hour)})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	grant, err := uc.GrantRole(ctx, employee.User.ID, &dto.RoleGrantRequest{Role: "Administrator", ExpiresAt: clk.Now().Add(time.Hour), Reason: "incident"})
+	require.NoError(t, err)
+	require.Equal(t, "administrator", grant.Role)
+	require.Equal(t, admin.User.ID, *grant.GrantedBy)
+
+	// The grant adds its role's permissions until it expires, even before it is revoked
+	granted, err := uc.HasUserPermission(rbac.WithMemo(context.Background()), employee, "users:delete")
+	require.NoError(t, err)
+	require.True(t, granted)
+	clk.Advance(time.Hour)
+	granted, err = uc.HasUserPermission(rbac.WithMemo(context.Background()), employee, "users:delete")
+	require.NoError(t, err)
+	require.False(t, granted)
+	granted, err = uc.HasUserPermission(rbac.WithMemo(context.Background()), employee, "profile:write")
+	require.NoError(t, err)
+	require.True(t, granted)
+
+	// Expired grants are revoked once and their users notified
+	webhooksUC.EXPECT().Publish(gomock.Any(), employee.User.ID, models.WebhookEventRoleGrantExpired, gomock.Any()).Return(nil)
+	revoked, err := uc.RevokeExpiredGrants(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, revoked)
+	revoked, err = uc.RevokeExpiredGrants(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+
+	grants, err := uc.ListGrants(ctx, employee.User.ID)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.NotNil(t, grants[0].RevokedAt)
+
+	// Grants revoked early stop applying and can't be revoked again
+	grant, err = uc.GrantRole(ctx, employee.User.ID, &dto.RoleGrantRequest{Role: "administrator", ExpiresAt: clk.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, uc.RevokeGrant(ctx, grant.ID))
+	require.ErrorIs(t, uc.RevokeGrant(ctx, grant.ID), sql.ErrNoRows)
+	roles, err := uc.UserRoles(context.Background(), employee)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user"}, roles)
+
+	require.Equal(t, []string{
+		auditActionRoleGranted,
+		auditActionRoleGrantExpired,
+		auditActionRoleGranted,
+		auditActionRoleGrantRevoked,
+	}, recorder.actions)
+}
+
+func TestRbacUsecase_CheckUserPermissions(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	cfg := &config.Config{Access: config.Access{
+		CacheSeconds:      60,
+		LocalCacheSeconds: 60,
+		RolePermissions: map[string][]string{
+			"administrator": {"users:read", "users:delete"},
+			"user":          {"profile:write"},
+		},
+	}}
+	repo := &countingRoleRepo{}
+	uc := NewRbacUsecase(cfg, repo, rbacRepo.NewGrantMemoryRepository(), rbacRepo.NewRoleRedisRepository(redisClient), nil, nil, clock.NewFrozen(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)), nil, testutil.Logger(cfg))
+	ctx := rbac.WithMemo(context.Background())
+
+	// Results follow the request order, the user's roles are resolved in one batch for all checks
+	results, err := uc.CheckUserPermissions(ctx, testUser(1, "user"), &dto.PermissionCheckRequest{Checks: []dto.PermissionCheck{
+		{Resource: "users", Action: "delete"},
+		{Resource: "profile", Action: "write"},
+		{Resource: "profile", Action: "read"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []models.PermissionCheckResult{
+		{Resource: "users", Action: "delete", Allowed: false},
+		{Resource: "profile", Action: "write", Allowed: true},
+		{Resource: "profile", Action: "read", Allowed: false},
+	}, results.Results)
+	require.Len(t, repo.batches, 1)
+
+	// Checks need a resource and an action
+	_, err = uc.CheckUserPermissions(ctx, testUser(1, "user"), &dto.PermissionCheckRequest{})
+	require.Error(t, err)
+	_, err = uc.CheckUserPermissions(ctx, testUser(1, "user"), &dto.PermissionCheckRequest{Checks: []dto.PermissionCheck{{Resource: "users"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+)
+
+// Layers answering permission lookups, in the order they are asked
+const (
+	sourceRequest = "request"
+	sourceLocal   = "local"
+	sourceRedis   = "redis"
+	sourceDB      = "db"
+)
+
+// Process cache entry of one role
+type localPermissions struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// Permissions granted to each of the roles. Every layer answers the roles it holds and passes the rest on
+// in one batch: the request memo, the process cache kept LocalCacheSeconds, redis kept CacheSeconds and finally the
+// database merged with the configured role permissions. Role names are lower cased
+func (u *rbacUsecase) ResolvePermissions(ctx context.Context, roles []string) (map[string][]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.ResolvePermissions")
+	defer span.Finish()
+
+	roles = normalizeRoles(roles)
+	memo := rbac.MemoFromCtx(ctx)
+	resolved := memo.Get(roles)
+	u.countLookups(sourceRequest, len(resolved))
+	missing := missingRoles(roles, resolved)
+	if len(missing) == 0 {
+		return resolved, nil
+	}
+
+	found := u.getLocal(missing)
+	u.countLookups(sourceLocal, len(found))
+	if missing = missingRoles(missing, found); len(missing) > 0 {
+		loaded, err := u.load(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for role, permissions := range loaded {
+			found[role] = permissions
+		}
+	}
+
+	memo.Put(found)
+	for role, permissions := range found {
+		resolved[role] = permissions
+	}
+	return resolved, nil
+}
+
+// Whether the role is granted the permission
+func (u *rbacUsecase) HasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rbacUsecase.HasPermission")
+	defer span.Finish()
+
+	resolved, err := u.ResolvePermissions(ctx, []string{role})
+	if err != nil {
+		return false, err
+	}
+	for _, granted := range resolved[strings.ToLower(role)] {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Roles missing from the process cache, concurrent requests for the same roles share one load.
+// The returned map may be shared and is not modified
+func (u *rbacUsecase) load(ctx context.Context, roles []string) (map[string][]string, error) {
+	v, _, err := u.loadGroup.Do(ctx, strings.Join(roles, ","), func(ctx context.Context) (interface{}, error) {
+		return u.loadRemote(ctx, roles)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string][]string), nil
+}
+
+func (u *rbacUsecase) loadRemote(ctx context.Context, roles []string) (map[string][]string, error) {
+	cacheSeconds := u.cfg.Access.CacheSeconds
+	loaded := make(map[string][]string, len(roles))
+	if cacheSeconds > 0 {
+		cached, err := u.redisRepo.GetPermissions(ctx, roles)
+		if err != nil {
+			u.logger.Errorf("rbacUsecase.loadRemote.GetPermissions: %v", err)
+		}
+		for role, permissions := range cached {
+			loaded[role] = permissions
+		}
+		u.countLookups(sourceRedis, len(cached))
+	}
+
+	if missing := missingRoles(roles, loaded); len(missing) > 0 {
+		granted, err := u.roleRepo.GetPermissionsByRoles(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		fromDB := make(map[string][]string, len(missing))
+		for _, role := range missing {
+			fromDB[role] = mergePermissions(u.cfg.Access.RolePermissions[role], granted[role])
+			loaded[role] = fromDB[role]
+		}
+		u.countLookups(sourceDB, len(missing))
+
+		if cacheSeconds > 0 {
+			if err := u.redisRepo.SetPermissions(ctx, fromDB, cacheSeconds); err != nil {
+				u.logger.Errorf("rbacUsecase.loadRemote.SetPermissions: %v", err)
+			}
+		}
+	}
+
+	u.setLocal(loaded)
+	return loaded, nil
+}
+
+func (u *rbacUsecase) getLocal(roles []string) map[string][]string {
+	found := make(map[string][]string)
+	now := time.Now()
+
+	u.localMu.RLock()
+	defer u.localMu.RUnlock()
+	for _, role := range roles {
+		if entry, ok := u.local[role]; ok && now.Before(entry.expiresAt) {
+			found[role] = entry.permissions
+		}
+	}
+	return found
+}
+
+func (u *rbacUsecase) setLocal(permissions map[string][]string) {
+	seconds := u.cfg.Access.LocalCacheSeconds
+	if seconds <= 0 {
+		return
+	}
+	expiresAt := time.Now().Add(time.Duration(seconds) * time.Second)
+
+	u.localMu.Lock()
+	defer u.localMu.Unlock()
+	for role, granted := range permissions {
+		u.local[role] = localPermissions{permissions: granted, expiresAt: expiresAt}
+	}
+}
+
+func (u *rbacUsecase) countLookups(source string, roles int) {
+	if u.metrics == nil {
+		return
+	}
+	for i := 0; i < roles; i++ {
+		u.metrics.IncPermissionLookups(source)
+	}
+}
+
+// Lower cased roles without duplicates, sorted so equal batches share a load
+func normalizeRoles(roles []string) []string {
+	seen := make(map[string]bool, len(roles))
+	normalized := make([]string, 0, len(roles))
+	for _, role := range roles {
+		role = strings.ToLower(role)
+		if !seen[role] {
+			seen[role] = true
+			normalized = append(normalized, role)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func missingRoles(roles []string, found map[string][]string) []string {
+	missing := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if _, ok := found[role]; !ok {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// Sorted union of the permission lists
+func mergePermissions(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, list := range lists {
+		for _, permission := range list {
+			if !seen[permission] {
+				seen[permission] = true
+				merged = append(merged, permission)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
+	rbacRepo "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// Role repository counting batched lookups
+type countingRoleRepo struct {
+	rbacRepo.RoleRepository
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (r *countingRoleRepo) GetPermissionsByRoles(_ context.Context, roles []string) (map[string][]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, roles)
+	return map[string][]string{"administrator": {"users:delete"}}, nil
+}
+
+// Metrics recording permission lookups by source
+type lookupMetrics struct {
+	metric.Metrics
+	mu      sync.Mutex
+	sources map[string]int
+}
+
+func (m *lookupMetrics) IncPermissionLookups(source string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources[source]++
+}
+
+func (m *lookupMetrics) take() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sources := m.sources
+	m.sources = make(map[string]int)
+	return sources
+}
+
+func TestRbacUsecase_ResolvePermissions(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	cfg := &config.Config{Access: config.Access{
+		CacheSeconds:      60,
+		LocalCacheSeconds: 60,
+		RolePermissions: map[string][]string{
+			"administrator": {"users:read", "users:delete"},
+			"user":          {"profile:write"},
+		},
+	}}
+	repo := &countingRoleRepo{}
+	metrics := &lookupMetrics{sources: make(map[string]int)}
+	uc := NewRbacUsecase(cfg, repo, rbacRepo.NewGrantMemoryRepository(), rbacRepo.NewRoleRedisRepository(redisClient), nil, nil, clock.NewFrozen(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)), metrics, testutil.Logger(cfg))
+	ctx := rbac.WithMemo(context.Background())
+
+	// Database rows are merged with the configured permissions, all roles in one batch
+	resolved, err := uc.ResolvePermissions(ctx, []string{"Administrator", "user", "guest", "user"})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"administrator": {"users:delete", "users:read"},
+		"guest":         {},
+		"user":          {"profile:write"},
+	}, resolved)
+	require.Equal(t, [][]string{{"administrator", "guest", "user"}}, repo.batches)
+	require.Equal(t, map[string]int{sourceDB: 3}, metrics.take())
+
+	// Same request answers from its memo
+	granted, err := uc.HasPermission(ctx, "administrator", "users:delete")
+	require.NoError(t, err)
+	require.True(t, granted)
+	granted, err = uc.HasPermission(ctx, "user", "users:delete")
+	require.NoError(t, err)
+	require.False(t, granted)
+	require.Equal(t, map[string]int{sourceRequest: 2}, metrics.take())
+
+	// Next request hits the process cache
+	_, err = uc.ResolvePermissions(rbac.WithMemo(context.Background()), []string{"user"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{sourceLocal: 1}, metrics.take())
+	require.Len(t, repo.batches, 1)
+}
+
+func TestRbacUsecase_ResolvePermissionsFromRedis(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+	cfg := &config.Config{Access: config.Access{
+		CacheSeconds:      60,
+		LocalCacheSeconds: 60,
+		RolePermissions: map[string][]string{
+			"administrator": {"users:read", "users:delete"},
+			"user":          {"profile:write"},
+		},
+	}}
+	repo := &countingRoleRepo{}
+	metrics := &lookupMetrics{sources: make(map[string]int)}
+	clk := clock.NewFrozen(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	uc := NewRbacUsecase(cfg, repo, rbacRepo.NewGrantMemoryRepository(), rbacRepo.NewRoleRedisRepository(redisClient), nil, nil, clk, metrics, testutil.Logger(cfg))
+	_, err := uc.ResolvePermissions(context.Background(), []string{"administrator"})
+	require.NoError(t, err)
+	metrics.take()
+
+	// Another instance with a cold process cache, only the role redis does not hold goes to the database
+	uc = NewRbacUsecase(cfg, repo, rbacRepo.NewGrantMemoryRepository(), rbacRepo.NewRoleRedisRepository(redisClient), nil, nil, clk, metrics, testutil.Logger(cfg))
+	resolved, err := uc.ResolvePermissions(context.Background(), []string{"administrator", "user"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:delete", "users:read"}, resolved["administrator"])
+	require.Equal(t, map[string]int{sourceRedis: 1, sourceDB: 1}, metrics.take())
+	require.Equal(t, [][]string{{"administrator"}, {"user"}}, repo.batches)
+
+	// Without a memo every call goes to the process cache
+	for i := 0; i < 2; i++ {
+		_, err = uc.HasPermission(context.Background(), "user", "profile:write")
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{sourceLocal: 2}, metrics.take())
+}
@@ -2,24 +2,63 @@ package usecase
 
 import (
 	"context"
+	"sync"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/rbac"
 	rbacRepo "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/dedup"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	"github.com/opentracing/opentracing-go"
 )
 
 type rbacUsecase struct {
-	cfg      *config.Config
-	roleRepo rbacRepo.RoleRepository
-	logger   logger.Logger
+	cfg        *config.Config
+	roleRepo   rbacRepo.RoleRepository
+	grantRepo  rbacRepo.GrantRepository
+	redisRepo  rbacRepo.RoleRedisRepository
+	auditUC    audit.UseCase
+	webhooksUC webhooks.UseCase
+	clock      clock.Clock
+	metrics    metric.Metrics
+	logger     logger.Logger
+
+	loadGroup *dedup.Group
+	localMu   sync.RWMutex
+	local     map[string]localPermissions
 }
 
-func NewRbacUsecase(cfg *config.Config, roleRepo rbacRepo.RoleRepository, logger logger.Logger) rbac.RbacUsecase {
-	return &rbacUsecase{cfg: cfg, roleRepo: roleRepo, logger: logger}
+// Rbac usecase constructor, auditUC, webhooksUC and metrics may be nil
+func NewRbacUsecase(
+	cfg *config.Config,
+	roleRepo rbacRepo.RoleRepository,
+	grantRepo rbacRepo.GrantRepository,
+	redisRepo rbacRepo.RoleRedisRepository,
+	auditUC audit.UseCase,
+	webhooksUC webhooks.UseCase,
+	clk clock.Clock,
+	metrics metric.Metrics,
+	logger logger.Logger,
+) rbac.RbacUsecase {
+	return &rbacUsecase{
+		cfg:        cfg,
+		roleRepo:   roleRepo,
+		grantRepo:  grantRepo,
+		redisRepo:  redisRepo,
+		auditUC:    auditUC,
+		webhooksUC: webhooksUC,
+		clock:      clk,
+		metrics:    metrics,
+		logger:     logger,
+		loadGroup:  dedup.NewGroup("permissions", cfg.Dedup.Permissions, metrics),
+		local:      make(map[string]localPermissions),
+	}
 }
 
 func (u *rbacUsecase) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error) {
@@ -0,0 +1,32 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package readmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Position of the projection in the change log, At is when it was last advanced
+type Offset struct {
+	EventID int64
+	At      time.Time
+}
+
+// Read models redis repository: users by id, a username index and a set of user ids per role
+type RedisRepository interface {
+	// Offset of the projection, nil when the read models were never built or redis lost them
+	GetOffset(ctx context.Context) (*Offset, error)
+	// Replace the read models of users and remove the ones of deleted ids, then move the offset to next, in
+	// one step. Nothing is written unless the stored offset still is expected, nil meaning none is stored, so
+	// concurrent projectors never apply a batch twice. A nil next leaves the offset unset while rebuilding
+	Apply(ctx context.Context, expected *Offset, next *Offset, users []*models.UserWithRole, deleted []int) (bool, error)
+	// Drop every read model and the offset
+	Clear(ctx context.Context) error
+	// Nil without error for users not in the read models
+	GetUser(ctx context.Context, userID int) (*models.UserWithRole, error)
+	// Zero without error for unknown usernames
+	GetUserIDByUsername(ctx context.Context, username string) (int, error)
+	GetRoleUserIDs(ctx context.Context, role string) ([]int, error)
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel"
+)
+
+const (
+	defaultPrefix = "api-readmodel:"
+	offsetKey     = "offset"
+	usernamesKey  = "usernames"
+	scanCount     = 100
+)
+
+// Writes a batch only while the stored offset is the expected one. Every user is a group of four arguments,
+// id, json, username and role, an empty json removes the user. The username and role a user was indexed under
+// are kept in its meta hash so moving it drops the old entries. Returns 1 when applied.
+var applyScript = redis.NewScript(`
+local prefix = ARGV[1]
+local stored = redis.call('HGET', prefix .. 'offset', 'event_id')
+if ARGV[2] == '' then
+	if stored then return 0 end
+elseif stored ~= ARGV[2] then
+	return 0
+end
+
+local usernames = prefix .. 'usernames'
+local i = 5
+while i <= #ARGV do
+	local id, value, username, role = ARGV[i], ARGV[i + 1], ARGV[i + 2], ARGV[i + 3]
+	local meta = prefix .. 'meta:' .. id
+	local old = redis.call('HMGET', meta, 'username', 'role')
+	if old[1] and old[1] ~= '' and redis.call('HGET', usernames, old[1]) == id then
+		redis.call('HDEL', usernames, old[1])
+	end
+	if old[2] and old[2] ~= '' then
+		redis.call('SREM', prefix .. 'role:' .. old[2], id)
+	end
+	if value == '' then
+		redis.call('DEL', prefix .. 'user:' .. id, meta)
+	else
+		redis.call('SET', prefix .. 'user:' .. id, value)
+		redis.call('HSET', meta, 'username', username, 'role', role)
+		if username ~= '' then
+			redis.call('HSET', usernames, username, id)
+		end
+		if role ~= '' then
+			redis.call('SADD', prefix .. 'role:' .. role, id)
+		end
+	end
+	i = i + 4
+end
+
+if ARGV[3] ~= '' then
+	redis.call('HSET', prefix .. 'offset', 'event_id', ARGV[3], 'at', ARGV[4])
+end
+return 1
+`)
+
+// Read models redis repository
+type readModelRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Read models redis repository constructor, an empty prefix uses api-readmodel:
+func NewReadModelRedisRepo(redisClient *redis.Client, prefix string) readmodel.RedisRepository {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &readModelRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Offset of the projection
+func (r *readModelRedisRepo) GetOffset(ctx context.Context) (*readmodel.Offset, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.GetOffset")
+	defer span.Finish()
+
+	values, err := r.redisClient.HMGet(ctx, r.prefix+offsetKey, "event_id", "at").Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "readModelRedisRepo.GetOffset.HMGet")
+	}
+	eventID, ok := values[0].(string)
+	if !ok {
+		return nil, nil
+	}
+	offset := &readmodel.Offset{}
+	if offset.EventID, err = strconv.ParseInt(eventID, 10, 64); err != nil {
+		return nil, errors.Wrap(err, "readModelRedisRepo.GetOffset.ParseInt")
+	}
+	if at, ok := values[1].(string); ok {
+		if ms, err := strconv.ParseInt(at, 10, 64); err == nil {
+			offset.At = time.UnixMilli(ms)
+		}
+	}
+	return offset, nil
+}
+
+// Apply a batch if the offset did not move
+func (r *readModelRedisRepo) Apply(ctx context.Context, expected *readmodel.Offset, next *readmodel.Offset, users []*models.UserWithRole, deleted []int) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.Apply")
+	defer span.Finish()
+
+	args := make([]interface{}, 0, 4+4*(len(users)+len(deleted)))
+	args = append(args, r.prefix, "", "", "")
+	if expected != nil {
+		args[1] = strconv.FormatInt(expected.EventID, 10)
+	}
+	if next != nil {
+		args[2] = strconv.FormatInt(next.EventID, 10)
+		args[3] = strconv.FormatInt(next.At.UnixMilli(), 10)
+	}
+	for _, user := range users {
+		value, err := json.Marshal(user)
+		if err != nil {
+			return false, errors.Wrap(err, "readModelRedisRepo.Apply.json.Marshal")
+		}
+		args = append(args, strconv.Itoa(user.User.ID), string(value), user.User.Username, user.Role.Name)
+	}
+	for _, userID := range deleted {
+		args = append(args, strconv.Itoa(userID), "", "", "")
+	}
+
+	applied, err := applyScript.Run(ctx, r.redisClient, nil, args...).Int()
+	if err != nil {
+		return false, errors.Wrap(err, "readModelRedisRepo.Apply.Run")
+	}
+	return applied == 1, nil
+}
+
+// Drop every key under the prefix
+func (r *readModelRedisRepo) Clear(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.Clear")
+	defer span.Finish()
+
+	iter := r.redisClient.Scan(ctx, 0, r.prefix+"*", scanCount).Iterator()
+	keys := make([]string, 0, scanCount)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanCount {
+			if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+				return errors.Wrap(err, "readModelRedisRepo.Clear.Del")
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "readModelRedisRepo.Clear.Scan")
+	}
+	if len(keys) > 0 {
+		if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+			return errors.Wrap(err, "readModelRedisRepo.Clear.Del")
+		}
+	}
+	return nil
+}
+
+// User by id
+func (r *readModelRedisRepo) GetUser(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.GetUser")
+	defer span.Finish()
+
+	value, err := r.redisClient.Get(ctx, r.prefix+"user:"+strconv.Itoa(userID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "readModelRedisRepo.GetUser.Get")
+	}
+	user := &models.UserWithRole{}
+	if err = json.Unmarshal(value, user); err != nil {
+		return nil, errors.Wrap(err, "readModelRedisRepo.GetUser.json.Unmarshal")
+	}
+	return user, nil
+}
+
+// User id of a username
+func (r *readModelRedisRepo) GetUserIDByUsername(ctx context.Context, username string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.GetUserIDByUsername")
+	defer span.Finish()
+
+	userID, err := r.redisClient.HGet(ctx, r.prefix+usernamesKey, username).Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "readModelRedisRepo.GetUserIDByUsername.HGet")
+	}
+	return userID, nil
+}
+
+// Ids of the users holding a role, ascending
+func (r *readModelRedisRepo) GetRoleUserIDs(ctx context.Context, role string) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelRedisRepo.GetRoleUserIDs")
+	defer span.Finish()
+
+	members, err := r.redisClient.SMembers(ctx, r.prefix+"role:"+role).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "readModelRedisRepo.GetRoleUserIDs.SMembers")
+	}
+	userIDs := make([]int, 0, len(members))
+	for _, member := range members {
+		if userID, err := strconv.Atoi(member); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Ints(userIDs)
+	return userIDs, nil
+}
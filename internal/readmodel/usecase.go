@@ -0,0 +1,32 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package readmodel
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Change log written by database triggers in the transaction of every user change, the outbox of user events
+type ChangeReader interface {
+	GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*models.ChangeEvent, error)
+	GetLastEventID(ctx context.Context) (int64, error)
+}
+
+// Source of the current state of users
+type UserReader interface {
+	GetByID(ctx context.Context, userID int) (*models.UserWithRole, error)
+	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+}
+
+// Read models use case, keeps redis read models of users in step with the change log
+type UseCase interface {
+	// Apply the change log since the stored offset, rebuilding the read models from Postgres when redis lost
+	// them or the log was pruned past the offset. Returns how many users were written, observe:nodeadline
+	Project(ctx context.Context) (int, error)
+	GetUser(ctx context.Context, userID int) (*models.UserWithRole, error)
+	GetUserIDByUsername(ctx context.Context, username string) (int, error)
+	GetRoleUserIDs(ctx context.Context, role string) ([]int, error)
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	usersTable     = "users"
+	rolesTable     = "roles"
+	userRolesTable = "user_roles"
+
+	defaultBatchSize      = 500
+	defaultRetentionHours = 24
+)
+
+// Read models UseCase
+type readModelUC struct {
+	cfg       *config.Config
+	redisRepo readmodel.RedisRepository
+	changes   readmodel.ChangeReader
+	users     readmodel.UserReader
+	clock     clock.Clock
+	logger    logger.Logger
+}
+
+// Read models UseCase constructor
+func NewReadModelUseCase(
+	cfg *config.Config,
+	redisRepo readmodel.RedisRepository,
+	changes readmodel.ChangeReader,
+	users readmodel.UserReader,
+	clk clock.Clock,
+	log logger.Logger,
+) readmodel.UseCase {
+	return &readModelUC{cfg: cfg, redisRepo: redisRepo, changes: changes, users: users, clock: clk, logger: log}
+}
+
+// Apply the change log since the stored offset
+func (u *readModelUC) Project(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelUC.Project")
+	defer span.Finish()
+
+	offset, err := u.redisRepo.GetOffset(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Without an offset redis lost the read models, with a stale one the log was pruned past it
+	if offset == nil || u.clock.Since(offset.At) > u.retention() {
+		return u.rebuild(ctx)
+	}
+
+	batchSize := u.batchSize()
+	projected := 0
+	for {
+		events, err := u.changes.GetEventsAfter(ctx, offset.EventID, batchSize)
+		if err != nil {
+			return projected, err
+		}
+		next := &readmodel.Offset{EventID: offset.EventID, At: u.clock.Now()}
+		userIDs := make([]int, 0, len(events))
+		seen := make(map[int]bool, len(events))
+		for _, event := range events {
+			next.EventID = event.ID
+			switch event.Table {
+			case usersTable, userRolesTable:
+				if !seen[event.EntityID] {
+					seen[event.EntityID] = true
+					userIDs = append(userIDs, event.EntityID)
+				}
+			case rolesTable:
+				// Role rows are embedded into every user holding them
+				u.logger.Infof("readModelUC.Project: role %d changed, rebuilding", event.EntityID)
+				written, err := u.rebuild(ctx)
+				return projected + written, err
+			default:
+				u.logger.Warnf("readModelUC.Project: unknown table %s", event.Table)
+			}
+		}
+
+		users, deleted, err := u.load(ctx, userIDs)
+		if err != nil {
+			return projected, err
+		}
+		// Moves the offset even without events, so an idle log does not read as pruned
+		applied, err := u.redisRepo.Apply(ctx, offset, next, users, deleted)
+		if err != nil {
+			return projected, err
+		}
+		if !applied {
+			// Another instance applied this batch first
+			return projected, nil
+		}
+		projected += len(users) + len(deleted)
+		offset = next
+
+		if len(events) < batchSize {
+			return projected, nil
+		}
+	}
+}
+
+// User by id, nil without error for users not projected
+func (u *readModelUC) GetUser(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelUC.GetUser")
+	defer span.Finish()
+
+	return u.redisRepo.GetUser(ctx, userID)
+}
+
+// User id of a username, zero without error for unknown usernames
+func (u *readModelUC) GetUserIDByUsername(ctx context.Context, username string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelUC.GetUserIDByUsername")
+	defer span.Finish()
+
+	return u.redisRepo.GetUserIDByUsername(ctx, username)
+}
+
+// Ids of the users holding a role
+func (u *readModelUC) GetRoleUserIDs(ctx context.Context, role string) ([]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "readModelUC.GetRoleUserIDs")
+	defer span.Finish()
+
+	return u.redisRepo.GetRoleUserIDs(ctx, role)
+}
+
+// Build the read models of every user. The head of the log is read first, changes made while users are
+// copied are applied again on the next run, which is harmless as users are always written whole
+func (u *readModelUC) rebuild(ctx context.Context) (int, error) {
+	if err := u.redisRepo.Clear(ctx); err != nil {
+		return 0, err
+	}
+	headID, err := u.changes.GetLastEventID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	projected := 0
+	pq := &utils.PaginationQuery{Size: u.batchSize(), Page: 1, Count: utils.CountNone}
+	for {
+		list, err := u.users.GetUsers(ctx, pq)
+		if err != nil {
+			return projected, err
+		}
+		userIDs := make([]int, 0, len(list.Users))
+		for _, user := range list.Users {
+			userIDs = append(userIDs, user.ID)
+		}
+		users, _, err := u.load(ctx, userIDs)
+		if err != nil {
+			return projected, err
+		}
+		applied, err := u.redisRepo.Apply(ctx, nil, nil, users, nil)
+		if err != nil {
+			return projected, err
+		}
+		if !applied {
+			// Another instance finished a rebuild meanwhile
+			return projected, nil
+		}
+		projected += len(users)
+
+		if !list.HasMore {
+			break
+		}
+		pq.Page++
+	}
+
+	if _, err := u.redisRepo.Apply(ctx, nil, &readmodel.Offset{EventID: headID, At: u.clock.Now()}, nil, nil); err != nil {
+		return projected, err
+	}
+	u.logger.Infof("readModelUC.rebuild: projected %d users up to event %d", projected, headID)
+	return projected, nil
+}
+
+// Current state of users, ids no longer in Postgres are returned as deleted
+func (u *readModelUC) load(ctx context.Context, userIDs []int) ([]*models.UserWithRole, []int, error) {
+	users := make([]*models.UserWithRole, 0, len(userIDs))
+	var deleted []int
+	for _, userID := range userIDs {
+		user, err := u.users.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				deleted = append(deleted, userID)
+				continue
+			}
+			return nil, nil, err
+		}
+		user.User.SanitizePassword()
+		users = append(users, user)
+	}
+	return users, deleted, nil
+}
+
+func (u *readModelUC) batchSize() int {
+	if u.cfg.ReadModel.BatchSize > 0 {
+		return u.cfg.ReadModel.BatchSize
+	}
+	return defaultBatchSize
+}
+
+// Change log retention of the change feed, events older than it may be gone
+func (u *readModelUC) retention() time.Duration {
+	hours := u.cfg.ChangeFeed.RetentionHours
+	if hours <= 0 {
+		hours = defaultRetentionHours
+	}
+	return time.Duration(hours) * time.Hour
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Users table and its change log
+type stubSource struct {
+	users  map[int]*models.UserWithRole
+	events []*models.ChangeEvent
+}
+
+func (s *stubSource) change(table string, entityID int) {
+	s.events = append(s.events, &models.ChangeEvent{ID: int64(len(s.events) + 1), Table: table, EntityID: entityID})
+}
+
+func (s *stubSource) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*models.ChangeEvent, error) {
+	var events []*models.ChangeEvent
+	for _, event := range s.events {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *stubSource) GetLastEventID(ctx context.Context) (int64, error) {
+	return int64(len(s.events)), nil
+}
+
+func (s *stubSource) GetByID(ctx context.Context, userID int) (*models.UserWithRole, error) {
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, errors.Wrap(sql.ErrNoRows, "stubSource.GetByID")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (s *stubSource) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
+	list := &models.UsersList{}
+	for id := 1; id <= 10; id++ {
+		if user, ok := s.users[id]; ok {
+			list.Users = append(list.Users, &models.User{ID: user.User.ID})
+		}
+	}
+	return list, nil
+}
+
+func user(id int, username string, role string) *models.UserWithRole {
+	return &models.UserWithRole{User: models.User{ID: id, Username: username, Password: "hash"}, Role: models.Role{Name: role}}
+}
+
+func TestReadModelUC_Project(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cfg := &config.Config{ReadModel: config.ReadModel{BatchSize: 2}, ChangeFeed: config.ChangeFeed{RetentionHours: 1}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	clk := clock.NewFrozen(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	source := &stubSource{users: map[int]*models.UserWithRole{1: user(1, "ann", "admin"), 2: user(2, "bob", "user")}}
+	source.change(usersTable, 1)
+	redisRepo := repository.NewReadModelRedisRepo(redisClient, "")
+	uc := NewReadModelUseCase(cfg, redisRepo, source, source, clk, appLogger)
+	ctx := context.Background()
+
+	// The first run builds every user and starts at the head of the log
+	projected, err := uc.Project(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, projected)
+	ann, err := uc.GetUser(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "ann", ann.User.Username)
+	require.Empty(t, ann.User.Password)
+
+	// Renames, role moves and deletes replace the old index entries
+	source.users[1] = user(1, "anna", "user")
+	delete(source.users, 2)
+	source.change(usersTable, 1)
+	source.change(userRolesTable, 1)
+	source.change(usersTable, 2)
+	projected, err = uc.Project(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, projected)
+
+	userID, err := uc.GetUserIDByUsername(ctx, "ann")
+	require.NoError(t, err)
+	require.Zero(t, userID)
+	userID, err = uc.GetUserIDByUsername(ctx, "anna")
+	require.NoError(t, err)
+	require.Equal(t, 1, userID)
+	admins, err := uc.GetRoleUserIDs(ctx, "admin")
+	require.NoError(t, err)
+	require.Empty(t, admins)
+	users, err := uc.GetRoleUserIDs(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, users)
+	bob, err := uc.GetUser(ctx, 2)
+	require.NoError(t, err)
+	require.Nil(t, bob)
+
+	offset, err := redisRepo.GetOffset(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), offset.EventID)
+
+	// A batch applied elsewhere is not applied again
+	applied, err := redisRepo.Apply(ctx, &readmodel.Offset{EventID: 1}, &readmodel.Offset{EventID: 4, At: clk.Now()}, []*models.UserWithRole{user(2, "bob", "user")}, nil)
+	require.NoError(t, err)
+	require.False(t, applied)
+
+	// Redis losing the read models rebuilds them
+	mr.FlushAll()
+	projected, err = uc.Project(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, projected)
+	userID, err = uc.GetUserIDByUsername(ctx, "anna")
+	require.NoError(t, err)
+	require.Equal(t, 1, userID)
+
+	// So does a projection stalled past the log retention
+	clk.Advance(2 * time.Hour)
+	source.users[3] = user(3, "cid", "user")
+	projected, err = uc.Project(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, projected)
+	users, err = uc.GetRoleUserIDs(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3}, users)
+}
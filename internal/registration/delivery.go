@@ -0,0 +1,10 @@
+package registration
+
+import "github.com/labstack/echo/v4"
+
+// Registration HTTP Handlers interface
+type Handlers interface {
+	CreateInvitation() echo.HandlerFunc
+	ListInvitations() echo.HandlerFunc
+	RevokeInvitation() echo.HandlerFunc
+}
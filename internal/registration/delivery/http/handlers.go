@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Registration handlers
+type registrationHandlers struct {
+	cfg            *config.Config
+	registrationUC registration.UseCase
+	logger         logger.Logger
+}
+
+// NewRegistrationHandlers Registration handlers constructor
+func NewRegistrationHandlers(cfg *config.Config, registrationUC registration.UseCase, log logger.Logger) registration.Handlers {
+	return &registrationHandlers{cfg: cfg, registrationUC: registrationUC, logger: log}
+}
+
+// CreateInvitation godoc
+// @Summary Create registration invitation
+// @Description Issue an invitation token to register while registration is invite only, it may assign a role
+// @Description and an organization. The token is only returned by this call, admin only
+// @Tags Registration
+// @Accept json
+// @Produce json
+// @Param invitation body dto.RegistrationInvitationRequest true "invitation"
+// @Success 201 {object} models.RegistrationInvitation
+// @Failure 400 {object} httpErrors.RestError
+// @Router /admin/registration/invitations [post]
+func (h *registrationHandlers) CreateInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "registrationHandlers.CreateInvitation")
+		defer span.Finish()
+
+		req := &dto.RegistrationInvitationRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		invitation, err := h.registrationUC.CreateInvitation(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, invitation)
+	}
+}
+
+// ListInvitations godoc
+// @Summary List registration invitations
+// @Description Invitations which have not expired yet with their uses, newest first, admin only
+// @Tags Registration
+// @Produce json
+// @Success 200 {array} models.RegistrationInvitation
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/registration/invitations [get]
+func (h *registrationHandlers) ListInvitations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "registrationHandlers.ListInvitations")
+		defer span.Finish()
+
+		invitations, err := h.registrationUC.ListInvitations(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, invitations)
+	}
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke registration invitation
+// @Description Revoke an invitation, accounts already registered with it are kept, admin only
+// @Tags Registration
+// @Param invitation_id path int true "invitation_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/registration/invitations/{invitation_id} [delete]
+func (h *registrationHandlers) RevokeInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "registrationHandlers.RevokeInvitation")
+		defer span.Finish()
+
+		invitationID, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.registrationUC.RevokeInvitation(ctx, invitationID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+)
+
+// Map registration invitation routes, group is already restricted to administrators
+func MapRegistrationRoutes(adminGroup *echo.Group, h registration.Handlers, mw *middleware.MiddlewareManager) {
+	adminGroup.POST("/registration/invitations", h.CreateInvitation(), mw.CSRF)
+	adminGroup.GET("/registration/invitations", h.ListInvitations())
+	adminGroup.DELETE("/registration/invitations/:invitation_id", h.RevokeInvitation(), mw.CSRF)
+}
@@ -0,0 +1,20 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package registration
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Registration Repository
+type Repository interface {
+	CreateInvitation(ctx context.Context, invitation *models.RegistrationInvitation) (*models.RegistrationInvitation, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.RegistrationInvitation, error)
+	ListInvitations(ctx context.Context, now time.Time) ([]*models.RegistrationInvitation, error)
+	RevokeInvitation(ctx context.Context, invitationID int64, now time.Time) error
+	// Takes one use unless the invitation is revoked, expired or used up, sql.ErrNoRows then
+	UseInvitation(ctx context.Context, invitationID int64, now time.Time) error
+	ReleaseInvitation(ctx context.Context, invitationID int64) error
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+)
+
+// Registration Repository kept in process memory, dev mode stand-in for Postgres
+type registrationMemoryRepo struct {
+	mu          sync.Mutex
+	lastID      int64
+	invitations map[int64]models.RegistrationInvitation
+}
+
+// Registration in-memory Repository constructor
+func NewRegistrationMemoryRepository() registration.Repository {
+	return &registrationMemoryRepo{invitations: make(map[int64]models.RegistrationInvitation)}
+}
+
+// Create invitation, the token is not stored, only its hash
+func (r *registrationMemoryRepo) CreateInvitation(ctx context.Context, invitation *models.RegistrationInvitation) (*models.RegistrationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.CreateInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *invitation
+	created.ID = r.lastID
+	created.Token = ""
+	created.Uses = 0
+	created.CreatedAt = time.Now()
+	r.invitations[created.ID] = created
+	return &created, nil
+}
+
+// Get invitation by the hash of its token
+func (r *registrationMemoryRepo) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.RegistrationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.GetInvitationByTokenHash")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, errors.Wrap(sql.ErrNoRows, "registrationMemoryRepo.GetInvitationByTokenHash")
+}
+
+// List invitations not expired at now, newest first
+func (r *registrationMemoryRepo) ListInvitations(ctx context.Context, now time.Time) ([]*models.RegistrationInvitation, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.ListInvitations")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitations := make([]*models.RegistrationInvitation, 0)
+	for _, invitation := range r.invitations {
+		if invitation.ExpiresAt.After(now) {
+			invitation := invitation
+			invitations = append(invitations, &invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID > invitations[j].ID })
+	return invitations, nil
+}
+
+// Revoke invitation, revoked invitations are kept with their uses
+func (r *registrationMemoryRepo) RevokeInvitation(ctx context.Context, invitationID int64, now time.Time) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.RevokeInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[invitationID]
+	if !ok || invitation.RevokedAt != nil {
+		return errors.Wrap(sql.ErrNoRows, "registrationMemoryRepo.RevokeInvitation")
+	}
+	invitation.RevokedAt = &now
+	r.invitations[invitationID] = invitation
+	return nil
+}
+
+// Take one use of a usable invitation
+func (r *registrationMemoryRepo) UseInvitation(ctx context.Context, invitationID int64, now time.Time) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.UseInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[invitationID]
+	if !ok || !invitation.Usable(now) {
+		return errors.Wrap(sql.ErrNoRows, "registrationMemoryRepo.UseInvitation")
+	}
+	invitation.Uses++
+	r.invitations[invitationID] = invitation
+	return nil
+}
+
+// Give one use back
+func (r *registrationMemoryRepo) ReleaseInvitation(ctx context.Context, invitationID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "registrationMemoryRepo.ReleaseInvitation")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if invitation, ok := r.invitations[invitationID]; ok && invitation.Uses > 0 {
+		invitation.Uses--
+		r.invitations[invitationID] = invitation
+	}
+	return nil
+}
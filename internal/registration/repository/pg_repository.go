@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Registration Repository
+type registrationRepo struct {
+	db *sqlx.DB
+}
+
+// Registration Repository constructor
+func NewRegistrationRepository(db *sqlx.DB) registration.Repository {
+	return &registrationRepo{db: db}
+}
+
+// Create invitation, the token is not stored, only its hash
+func (r *registrationRepo) CreateInvitation(ctx context.Context, invitation *models.RegistrationInvitation) (*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.CreateInvitation")
+	defer span.Finish()
+
+	created := &models.RegistrationInvitation{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createInvitationQuery,
+		invitation.TokenHash,
+		invitation.RoleName,
+		invitation.OrganizationID,
+		invitation.OrganizationRole,
+		invitation.MaxUses,
+		invitation.CreatedBy,
+		invitation.ExpiresAt,
+	).StructScan(created); err != nil {
+		return nil, errors.Wrap(err, "registrationRepo.CreateInvitation.StructScan")
+	}
+	return created, nil
+}
+
+// Get invitation by the hash of its token
+func (r *registrationRepo) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.GetInvitationByTokenHash")
+	defer span.Finish()
+
+	invitation := &models.RegistrationInvitation{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, invitation, getInvitationByTokenHashQuery, tokenHash); err != nil {
+		return nil, errors.Wrap(err, "registrationRepo.GetInvitationByTokenHash.GetContext")
+	}
+	return invitation, nil
+}
+
+// List invitations not expired at now, newest first
+func (r *registrationRepo) ListInvitations(ctx context.Context, now time.Time) ([]*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.ListInvitations")
+	defer span.Finish()
+
+	invitations := make([]*models.RegistrationInvitation, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &invitations, listInvitationsQuery, now); err != nil {
+		return nil, errors.Wrap(err, "registrationRepo.ListInvitations.SelectContext")
+	}
+	return invitations, nil
+}
+
+// Revoke invitation, revoked invitations are kept with their uses
+func (r *registrationRepo) RevokeInvitation(ctx context.Context, invitationID int64, now time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.RevokeInvitation")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, revokeInvitationQuery, invitationID, now)
+	if err != nil {
+		return errors.Wrap(err, "registrationRepo.RevokeInvitation.ExecContext")
+	}
+	return checkAffected(result, "registrationRepo.RevokeInvitation")
+}
+
+// Take one use of a usable invitation
+func (r *registrationRepo) UseInvitation(ctx context.Context, invitationID int64, now time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.UseInvitation")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, useInvitationQuery, invitationID, now)
+	if err != nil {
+		return errors.Wrap(err, "registrationRepo.UseInvitation.ExecContext")
+	}
+	return checkAffected(result, "registrationRepo.UseInvitation")
+}
+
+// Give one use back
+func (r *registrationRepo) ReleaseInvitation(ctx context.Context, invitationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationRepo.ReleaseInvitation")
+	defer span.Finish()
+
+	if _, err := tenant.DB(ctx, r.db).ExecContext(ctx, releaseInvitationQuery, invitationID); err != nil {
+		return errors.Wrap(err, "registrationRepo.ReleaseInvitation.ExecContext")
+	}
+	return nil
+}
+
+func checkAffected(result sql.Result, op string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op+".RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, op+".rowsAffected")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+const (
+	createInvitationQuery = `INSERT INTO registration_invitations (token_hash, role_name, organization_id, organization_role, max_uses, created_by, expires_at, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+						RETURNING id, token_hash, role_name, organization_id, organization_role, max_uses, uses, created_by, expires_at, revoked_at, created_at`
+
+	getInvitationByTokenHashQuery = `SELECT id, token_hash, role_name, organization_id, organization_role, max_uses, uses, created_by, expires_at, revoked_at, created_at
+						FROM registration_invitations
+						WHERE token_hash = $1`
+
+	listInvitationsQuery = `SELECT id, token_hash, role_name, organization_id, organization_role, max_uses, uses, created_by, expires_at, revoked_at, created_at
+						FROM registration_invitations
+						WHERE expires_at > $1
+						ORDER BY created_at DESC, id DESC`
+
+	revokeInvitationQuery = `UPDATE registration_invitations SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	useInvitationQuery = `UPDATE registration_invitations SET uses = uses + 1
+						WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2 AND uses < max_uses`
+
+	releaseInvitationQuery = `UPDATE registration_invitations SET uses = uses - 1 WHERE id = $1 AND uses > 0`
+)
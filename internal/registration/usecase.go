@@ -0,0 +1,34 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package registration
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Source of the roles an invitation may assign
+type RoleReader interface {
+	GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error)
+}
+
+// Organizations invited users are added to
+type Organizations interface {
+	AddMember(ctx context.Context, organizationID int64, userID int, role string) (*models.OrganizationMember, error)
+}
+
+// Registration use case
+type UseCase interface {
+	CreateInvitation(ctx context.Context, req *dto.RegistrationInvitationRequest) (*models.RegistrationInvitation, error)
+	ListInvitations(ctx context.Context) ([]*models.RegistrationInvitation, error)
+	RevokeInvitation(ctx context.Context, invitationID int64) error
+	// Checks registration is open and takes one use of the invitation, nil invitation without a token
+	Redeem(ctx context.Context, token string) (*models.RegistrationInvitation, error)
+	// Gives the use taken by Redeem back after the registration failed
+	Release(ctx context.Context, invitation *models.RegistrationInvitation) error
+	// Adds the registered user to the organization of the invitation
+	Complete(ctx context.Context, invitation *models.RegistrationInvitation, userID int) error
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	invitationPrefix     = "reginv_"
+	defaultInvitationTTL = 7 * 24 * time.Hour
+	defaultMaxUses       = 1
+	rolesPageSize        = 100
+
+	errRegistrationClosed = "Registration is closed"
+	errInvitationRequired = "Registration requires an invitation"
+	errInvitationInvalid  = "Invitation is invalid"
+	errInvitationUnusable = "Invitation expired, revoked or used up"
+
+	auditActionInvitationCreated  = "registration.invitation_created"
+	auditActionInvitationRevoked  = "registration.invitation_revoked"
+	auditActionInvitationRedeemed = "registration.invitation_redeemed"
+)
+
+// Registration UseCase
+type registrationUC struct {
+	cfg        *config.Config
+	repo       registration.Repository
+	settingsUC settings.UseCase
+	roles      registration.RoleReader
+	orgs       registration.Organizations
+	auditUC    audit.UseCase
+	clock      clock.Clock
+	logger     logger.Logger
+}
+
+// Registration UseCase constructor, auditUC may be nil
+func NewRegistrationUseCase(
+	cfg *config.Config,
+	repo registration.Repository,
+	settingsUC settings.UseCase,
+	roles registration.RoleReader,
+	orgs registration.Organizations,
+	auditUC audit.UseCase,
+	clk clock.Clock,
+	log logger.Logger,
+) registration.UseCase {
+	return &registrationUC{
+		cfg:        cfg,
+		repo:       repo,
+		settingsUC: settingsUC,
+		roles:      roles,
+		orgs:       orgs,
+		auditUC:    auditUC,
+		clock:      clk,
+		logger:     log,
+	}
+}
+
+// Create invitation, the token is returned once and only its hash is stored
+func (u *registrationUC) CreateInvitation(ctx context.Context, req *dto.RegistrationInvitationRequest) (*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.CreateInvitation")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return nil, httpErrors.NewBadRequestError(err)
+	}
+	if req.RoleName != "" {
+		if err := u.checkRoleExists(ctx, req.RoleName); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "registrationUC.CreateInvitation.generateToken")
+	}
+
+	maxUses := req.MaxUses
+	if maxUses == 0 {
+		maxUses = u.cfg.Registration.InvitationMaxUses
+	}
+	if maxUses <= 0 {
+		maxUses = defaultMaxUses
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if ttl == 0 {
+		ttl = u.invitationTTL()
+	}
+
+	invitation := &models.RegistrationInvitation{
+		TokenHash:      hashToken(token),
+		RoleName:       req.RoleName,
+		OrganizationID: req.OrganizationID,
+		MaxUses:        maxUses,
+		CreatedBy:      &user.User.ID,
+		ExpiresAt:      u.clock.Now().Add(ttl),
+	}
+	if req.OrganizationID != nil {
+		invitation.OrganizationRole = req.OrganizationRole
+	}
+
+	created, err := u.repo.CreateInvitation(ctx, invitation)
+	if err != nil {
+		return nil, err
+	}
+	created.Token = token
+
+	u.record(ctx, auditActionInvitationCreated, created.ID, &user.User.ID, map[string]interface{}{
+		"role_name":       created.RoleName,
+		"organization_id": created.OrganizationID,
+		"max_uses":        created.MaxUses,
+		"expires_at":      created.ExpiresAt,
+	})
+	return created, nil
+}
+
+// List invitations which have not expired yet, newest first
+func (u *registrationUC) ListInvitations(ctx context.Context) ([]*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.ListInvitations")
+	defer span.Finish()
+
+	return u.repo.ListInvitations(ctx, u.clock.Now())
+}
+
+// Revoke invitation, registrations already made with it are kept
+func (u *registrationUC) RevokeInvitation(ctx context.Context, invitationID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.RevokeInvitation")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(err)
+	}
+
+	if err := u.repo.RevokeInvitation(ctx, invitationID, u.clock.Now()); err != nil {
+		return err
+	}
+	u.record(ctx, auditActionInvitationRevoked, invitationID, &user.User.ID, nil)
+	return nil
+}
+
+// Check registration is open and take one use of the invitation. Without a token registration must not be
+// invite only, with one the invitation must be usable whatever the mode.
+func (u *registrationUC) Redeem(ctx context.Context, token string) (*models.RegistrationInvitation, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.Redeem")
+	defer span.Finish()
+
+	current, err := u.settingsUC.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !current.RegistrationEnabled {
+		return nil, httpErrors.NewRestError(http.StatusForbidden, errRegistrationClosed, nil)
+	}
+	if token == "" {
+		if current.InviteOnly {
+			return nil, httpErrors.NewRestError(http.StatusForbidden, errInvitationRequired, nil)
+		}
+		return nil, nil
+	}
+
+	invitation, err := u.repo.GetInvitationByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusForbidden, errInvitationInvalid, nil)
+		}
+		return nil, err
+	}
+
+	// Usable is checked again by the update, two registrations racing for the last use can't both win
+	now := u.clock.Now()
+	if !invitation.Usable(now) {
+		return nil, httpErrors.NewRestError(http.StatusGone, errInvitationUnusable, nil)
+	}
+	if err := u.repo.UseInvitation(ctx, invitation.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httpErrors.NewRestError(http.StatusGone, errInvitationUnusable, nil)
+		}
+		return nil, err
+	}
+	invitation.Uses++
+	return invitation, nil
+}
+
+// Give the use taken by Redeem back
+func (u *registrationUC) Release(ctx context.Context, invitation *models.RegistrationInvitation) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.Release")
+	defer span.Finish()
+
+	return u.repo.ReleaseInvitation(ctx, invitation.ID)
+}
+
+// Add the registered user to the organization of the invitation. The account exists at this point,
+// a failed membership is returned to be logged but does not undo the registration.
+func (u *registrationUC) Complete(ctx context.Context, invitation *models.RegistrationInvitation, userID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "registrationUC.Complete")
+	defer span.Finish()
+
+	u.record(ctx, auditActionInvitationRedeemed, invitation.ID, &userID, map[string]interface{}{
+		"user_id":         userID,
+		"role_name":       invitation.RoleName,
+		"organization_id": invitation.OrganizationID,
+	})
+
+	if invitation.OrganizationID == nil {
+		return nil
+	}
+	_, err := u.orgs.AddMember(ctx, *invitation.OrganizationID, userID, invitation.OrganizationRole)
+	return err
+}
+
+func (u *registrationUC) checkRoleExists(ctx context.Context, name string) error {
+	pq := &utils.PaginationQuery{Page: 1, Size: rolesPageSize}
+	for {
+		roles, err := u.roles.GetRoles(ctx, pq)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles.Roles {
+			if role.Name == name {
+				return nil
+			}
+		}
+		if !roles.HasMore {
+			return httpErrors.NewBadRequestError("role does not exist")
+		}
+		pq.Page++
+	}
+}
+
+func (u *registrationUC) record(ctx context.Context, action string, invitationID int64, actorID *int, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	if err := u.auditUC.Record(ctx, action, &models.AuditEvent{
+		ActorID:   actorID,
+		RequestID: requestID,
+		Resource:  "registration_invitation:" + strconv.FormatInt(invitationID, 10),
+	}, metadata); err != nil {
+		u.logger.Errorf("registrationUC.record.Record action: %s, error: %v", action, err)
+	}
+}
+
+func (u *registrationUC) invitationTTL() time.Duration {
+	if u.cfg.Registration.InvitationTTL > 0 {
+		return time.Duration(u.cfg.Registration.InvitationTTL) * time.Second
+	}
+	return defaultInvitationTTL
+}
+
+func generateToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return invitationPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
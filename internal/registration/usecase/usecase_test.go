@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration/repository"
+	settingsMock "github.com/aditwar-man/go-microservice-boilerplate/internal/settings/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+type stubRoles []string
+
+func (s stubRoles) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error) {
+	roles := make([]*models.Role, 0, len(s))
+	for i, name := range s {
+		roles = append(roles, &models.Role{ID: i + 1, Name: name})
+	}
+	return &models.RolesList{TotalCount: len(roles), Page: pq.GetPage(), Roles: roles}, nil
+}
+
+type stubOrganizations struct {
+	added map[int]int64
+}
+
+func (s *stubOrganizations) AddMember(ctx context.Context, organizationID int64, userID int, role string) (*models.OrganizationMember, error) {
+	s.added[userID] = organizationID
+	return &models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+func TestRegistrationUC_Redeem(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsUC := settingsMock.NewMockUseCase(ctrl)
+	clk := clock.NewFrozen(time.Now())
+	cfg := &config.Config{Registration: config.Registration{InvitationTTL: 3600, InvitationMaxUses: 2}}
+	uc := NewRegistrationUseCase(cfg, repository.NewRegistrationMemoryRepository(), settingsUC, stubRoles{"administrator", "employee"}, &stubOrganizations{added: make(map[int]int64)}, nil, clk, testutil.Logger(cfg))
+	ctx := context.Background()
+
+	open := models.DefaultSettings(false)
+	inviteOnly := models.DefaultSettings(true)
+	closed := models.DefaultSettings(false)
+	closed.RegistrationEnabled = false
+
+	settingsUC.EXPECT().Current(gomock.Any()).Return(&open, nil)
+	invitation, err := uc.Redeem(ctx, "")
+	require.NoError(t, err)
+	require.Nil(t, invitation)
+
+	settingsUC.EXPECT().Current(gomock.Any()).Return(&closed, nil)
+	_, err = uc.Redeem(ctx, "")
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+
+	settingsUC.EXPECT().Current(gomock.Any()).Return(&inviteOnly, nil).AnyTimes()
+	_, err = uc.Redeem(ctx, "")
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+	_, err = uc.Redeem(ctx, "reginv_unknown")
+	testutil.RequireStatus(t, err, http.StatusForbidden)
+
+	created, err := uc.CreateInvitation(testutil.AsAdmin(), &dto.RegistrationInvitationRequest{RoleName: "administrator"})
+	require.NoError(t, err)
+	require.Equal(t, 2, created.MaxUses)
+
+	// Two uses, a failed registration gives its use back
+	invitation, err = uc.Redeem(ctx, created.Token)
+	require.NoError(t, err)
+	require.Equal(t, "administrator", invitation.RoleName)
+	require.NoError(t, uc.Release(ctx, invitation))
+	_, err = uc.Redeem(ctx, created.Token)
+	require.NoError(t, err)
+	_, err = uc.Redeem(ctx, created.Token)
+	require.NoError(t, err)
+	_, err = uc.Redeem(ctx, created.Token)
+	testutil.RequireStatus(t, err, http.StatusGone)
+
+	expiring, err := uc.CreateInvitation(testutil.AsAdmin(), &dto.RegistrationInvitationRequest{})
+	require.NoError(t, err)
+	clk.Advance(2 * time.Hour)
+	_, err = uc.Redeem(ctx, expiring.Token)
+	testutil.RequireStatus(t, err, http.StatusGone)
+}
+
+func TestRegistrationUC_Invitations(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsUC := settingsMock.NewMockUseCase(ctrl)
+	inviteOnly := models.DefaultSettings(true)
+	settingsUC.EXPECT().Current(gomock.Any()).Return(&inviteOnly, nil).AnyTimes()
+	orgs := &stubOrganizations{added: make(map[int]int64)}
+	cfg := &config.Config{Registration: config.Registration{InvitationTTL: 3600, InvitationMaxUses: 2}}
+	uc := NewRegistrationUseCase(cfg, repository.NewRegistrationMemoryRepository(), settingsUC, stubRoles{"administrator", "employee"}, orgs, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	_, err := uc.CreateInvitation(ctx, &dto.RegistrationInvitationRequest{RoleName: "superuser"})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	organizationID := int64(7)
+	_, err = uc.CreateInvitation(ctx, &dto.RegistrationInvitationRequest{OrganizationID: &organizationID})
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+
+	created, err := uc.CreateInvitation(ctx, &dto.RegistrationInvitationRequest{
+		OrganizationID:   &organizationID,
+		OrganizationRole: models.OrganizationRoleMember,
+		MaxUses:          5,
+	})
+	require.NoError(t, err)
+
+	invitation, err := uc.Redeem(context.Background(), created.Token)
+	require.NoError(t, err)
+	require.NoError(t, uc.Complete(context.Background(), invitation, 42))
+	require.Equal(t, organizationID, orgs.added[42])
+
+	list, err := uc.ListInvitations(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, 1, list[0].Uses)
+	require.Empty(t, list[0].Token)
+
+	require.NoError(t, uc.RevokeInvitation(ctx, created.ID))
+	_, err = uc.Redeem(context.Background(), created.Token)
+	testutil.RequireStatus(t, err, http.StatusGone)
+	require.Error(t, uc.RevokeInvitation(ctx, created.ID))
+}
@@ -0,0 +1,9 @@
+package remember
+
+import "github.com/labstack/echo/v4"
+
+// Remember-me HTTP Handlers interface
+type Handlers interface {
+	ListDevices() echo.HandlerFunc
+	RevokeDevice() echo.HandlerFunc
+}
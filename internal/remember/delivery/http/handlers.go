@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Remember-me handlers
+type rememberHandlers struct {
+	cfg        *config.Config
+	rememberUC remember.UseCase
+	logger     logger.Logger
+}
+
+// NewRememberHandlers Remember-me handlers constructor
+func NewRememberHandlers(cfg *config.Config, rememberUC remember.UseCase, log logger.Logger) remember.Handlers {
+	return &rememberHandlers{cfg: cfg, rememberUC: rememberUC, logger: log}
+}
+
+// ListDevices godoc
+// @Summary List remembered devices
+// @Description Devices the current user asked to be remembered on, each can start a new session without a password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.RememberDevice
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/remember [get]
+func (h *rememberHandlers) ListDevices() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rememberHandlers.ListDevices")
+		defer span.Finish()
+
+		devices, err := h.rememberUC.ListDevices(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, devices)
+	}
+}
+
+// RevokeDevice godoc
+// @Summary Forget remembered device
+// @Description Its remember-me cookie stops working, sessions already started on it are left alone
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param series path string true "series"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/remember/{series} [delete]
+func (h *rememberHandlers) RevokeDevice() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "rememberHandlers.RevokeDevice")
+		defer span.Finish()
+
+		if err := h.rememberUC.RevokeDevice(ctx, c.Param("series")); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+)
+
+// Map remembered device routes under /remember, authGroup must already carry the JWT and session middlewares
+func MapRememberRoutes(authGroup *echo.Group, h remember.Handlers, mw *middleware.MiddlewareManager) {
+	authGroup.GET("/remember", h.ListDevices())
+	authGroup.DELETE("/remember/:series", h.RevokeDevice(), mw.CSRF)
+}
@@ -0,0 +1,12 @@
+package remember
+
+import (
+	"net/http"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+var (
+	ErrNotFound = httpErrors.NewRestErrorWithMessage(http.StatusUnauthorized, "remember-me token is invalid or expired", nil)
+	ErrTheft    = httpErrors.NewRestErrorWithMessage(http.StatusUnauthorized, "remember-me token was already used, every device has been signed out", nil)
+)
@@ -0,0 +1,21 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package remember
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Remember-me series, each expires at its ExpiresAt
+type RedisRepository interface {
+	Create(ctx context.Context, series *models.RememberSeries) error
+	// Missing or expired series is ErrNotFound
+	Get(ctx context.Context, series string) (*models.RememberSeries, error)
+	// Replace the series unless its token is no longer currentHash, false when another request rotated it first
+	Rotate(ctx context.Context, series *models.RememberSeries, currentHash string) (bool, error)
+	// Returns false when the user had no such series
+	Delete(ctx context.Context, userID int, series string) (bool, error)
+	ListByUser(ctx context.Context, userID int) ([]*models.RememberSeries, error)
+	DeleteByUser(ctx context.Context, userID int) (int, error)
+}
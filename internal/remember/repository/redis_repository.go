@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+)
+
+const (
+	seriesPrefix = ":series:"
+	userPrefix   = ":user:"
+)
+
+// Remember-me redis repository, series are indexed by user in a set
+type rememberRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Remember-me redis repository constructor
+func NewRememberRedisRepo(redisClient *redis.Client, prefix string) remember.RedisRepository {
+	return &rememberRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Store series and add it to the index of its user
+func (r *rememberRedisRepo) Create(ctx context.Context, series *models.RememberSeries) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.Create")
+	defer span.Finish()
+
+	seriesBytes, err := json.Marshal(series)
+	if err != nil {
+		return errors.Wrap(err, "rememberRedisRepo.Create.json.Marshal")
+	}
+
+	// Series expire at a fixed time, the newest one outlives the others of its user
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, r.seriesKey(series.Series), seriesBytes, time.Until(series.ExpiresAt))
+	pipe.SAdd(ctx, r.userKey(series.UserID), series.Series)
+	pipe.ExpireAt(ctx, r.userKey(series.UserID), series.ExpiresAt)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "rememberRedisRepo.Create.pipe.Exec")
+	}
+	return nil
+}
+
+// Get series, a missing or expired one is remember.ErrNotFound
+func (r *rememberRedisRepo) Get(ctx context.Context, series string) (*models.RememberSeries, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.Get")
+	defer span.Finish()
+
+	seriesBytes, err := r.redisClient.Get(ctx, r.seriesKey(series)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(remember.ErrNotFound, "rememberRedisRepo.Get.redisClient.Get")
+		}
+		return nil, errors.Wrap(err, "rememberRedisRepo.Get.redisClient.Get")
+	}
+	return unmarshalSeries(seriesBytes)
+}
+
+// Replace series when its stored token is still currentHash, the check and the write are one transaction
+func (r *rememberRedisRepo) Rotate(ctx context.Context, series *models.RememberSeries, currentHash string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.Rotate")
+	defer span.Finish()
+
+	seriesBytes, err := json.Marshal(series)
+	if err != nil {
+		return false, errors.Wrap(err, "rememberRedisRepo.Rotate.json.Marshal")
+	}
+
+	key := r.seriesKey(series.Series)
+	rotated := false
+	err = r.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		storedBytes, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return nil
+			}
+			return err
+		}
+		stored, err := unmarshalSeries(storedBytes)
+		if err != nil {
+			return err
+		}
+		if stored.TokenHash != currentHash {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, seriesBytes, time.Until(series.ExpiresAt))
+			return nil
+		})
+		rotated = err == nil
+		return err
+	}, key)
+	if err != nil {
+		if errors.Is(err, redis.TxFailedErr) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "rememberRedisRepo.Rotate.redisClient.Watch")
+	}
+	return rotated, nil
+}
+
+// Delete series of user, series of other users are left alone
+func (r *rememberRedisRepo) Delete(ctx context.Context, userID int, series string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.Delete")
+	defer span.Finish()
+
+	removed, err := r.redisClient.SRem(ctx, r.userKey(userID), series).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "rememberRedisRepo.Delete.redisClient.SRem")
+	}
+	if removed == 0 {
+		return false, nil
+	}
+	if err := r.redisClient.Del(ctx, r.seriesKey(series)).Err(); err != nil {
+		return false, errors.Wrap(err, "rememberRedisRepo.Delete.redisClient.Del")
+	}
+	return true, nil
+}
+
+// List series of user, expired ones are dropped from the index
+func (r *rememberRedisRepo) ListByUser(ctx context.Context, userID int) ([]*models.RememberSeries, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.ListByUser")
+	defer span.Finish()
+
+	members, err := r.redisClient.SMembers(ctx, r.userKey(userID)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "rememberRedisRepo.ListByUser.redisClient.SMembers")
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, r.seriesKey(member))
+	}
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "rememberRedisRepo.ListByUser.redisClient.MGet")
+	}
+
+	list := make([]*models.RememberSeries, 0, len(values))
+	var expired []interface{}
+	for i, value := range values {
+		seriesString, ok := value.(string)
+		if !ok {
+			expired = append(expired, members[i])
+			continue
+		}
+		series, err := unmarshalSeries([]byte(seriesString))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, series)
+	}
+	if len(expired) > 0 {
+		if err := r.redisClient.SRem(ctx, r.userKey(userID), expired...).Err(); err != nil {
+			return nil, errors.Wrap(err, "rememberRedisRepo.ListByUser.redisClient.SRem")
+		}
+	}
+	return list, nil
+}
+
+// Delete every series of user, returns how many were still live
+func (r *rememberRedisRepo) DeleteByUser(ctx context.Context, userID int) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberRedisRepo.DeleteByUser")
+	defer span.Finish()
+
+	members, err := r.redisClient.SMembers(ctx, r.userKey(userID)).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "rememberRedisRepo.DeleteByUser.redisClient.SMembers")
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, r.seriesKey(member))
+	}
+	pipe := r.redisClient.TxPipeline()
+	var deleted *redis.IntCmd
+	if len(keys) > 0 {
+		deleted = pipe.Del(ctx, keys...)
+	}
+	pipe.Del(ctx, r.userKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Wrap(err, "rememberRedisRepo.DeleteByUser.pipe.Exec")
+	}
+	if deleted == nil {
+		return 0, nil
+	}
+	return int(deleted.Val()), nil
+}
+
+func (r *rememberRedisRepo) seriesKey(series string) string {
+	return r.prefix + seriesPrefix + series
+}
+
+func (r *rememberRedisRepo) userKey(userID int) string {
+	return r.prefix + userPrefix + strconv.Itoa(userID)
+}
+
+func unmarshalSeries(seriesBytes []byte) (*models.RememberSeries, error) {
+	series := &models.RememberSeries{}
+	if err := json.Unmarshal(seriesBytes, series); err != nil {
+		return nil, errors.Wrap(err, "rememberRedisRepo.json.Unmarshal")
+	}
+	return series, nil
+}
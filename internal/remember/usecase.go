@@ -0,0 +1,22 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package remember
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Remember-me use case, cookie values carry the series and its current token
+type UseCase interface {
+	// Start a series for the device of the user, returns the cookie value
+	Issue(ctx context.Context, userID int, ipAddress string, userAgent string) (string, error)
+	// Exchange cookie value for the user it remembers and the rotated cookie value. The value is empty when
+	// the previous token was accepted within the grace period, the rotating request sets the new one
+	Redeem(ctx context.Context, value string, ipAddress string, userAgent string) (int, string, error)
+	// End the series of cookie value, on logout
+	Forget(ctx context.Context, value string) error
+	ListDevices(ctx context.Context) ([]*models.RememberDevice, error)
+	RevokeDevice(ctx context.Context, series string) error
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultExpire = 30 * 24 * time.Hour
+	defaultGrace  = 30 * time.Second
+
+	auditActionTheftDetected = "remember.theft_detected"
+	auditActionDeviceRevoked = "remember.device_revoked"
+)
+
+// Remember-me UseCase, a login asking for it gets a long lived cookie per device which mints a new session once
+// the session is gone
+type rememberUC struct {
+	cfg       *config.Config
+	redisRepo remember.RedisRepository
+	sessUC    session.UCSession
+	auditUC   audit.UseCase
+	clock     clock.Clock
+	logger    logger.Logger
+}
+
+// Remember-me UseCase constructor, auditUC may be nil
+func NewRememberUseCase(
+	cfg *config.Config,
+	redisRepo remember.RedisRepository,
+	sessUC session.UCSession,
+	auditUC audit.UseCase,
+	clk clock.Clock,
+	log logger.Logger,
+) remember.UseCase {
+	return &rememberUC{cfg: cfg, redisRepo: redisRepo, sessUC: sessUC, auditUC: auditUC, clock: clk, logger: log}
+}
+
+// Start a series for the device, the cookie value is "<series>.<token>" and only the token hash is stored
+func (u *rememberUC) Issue(ctx context.Context, userID int, ipAddress string, userAgent string) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberUC.Issue")
+	defer span.Finish()
+
+	seriesID, err := generateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "rememberUC.Issue.generateToken")
+	}
+	token, err := generateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "rememberUC.Issue.generateToken")
+	}
+
+	now := u.clock.Now().UTC()
+	series := &models.RememberSeries{
+		RememberDevice: models.RememberDevice{
+			Series:     seriesID,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			CreatedAt:  now,
+			LastUsedAt: now,
+			ExpiresAt:  now.Add(u.expire()),
+		},
+		UserID:    userID,
+		TokenHash: hashToken(seriesID, token),
+	}
+	if err := u.redisRepo.Create(ctx, series); err != nil {
+		return "", err
+	}
+	return seriesID + "." + token, nil
+}
+
+// Exchange cookie value for its user, rotating the token. A token older than the previous one, or the previous
+// one past the grace period, means the cookie was copied: every series and session of the user is ended
+func (u *rememberUC) Redeem(ctx context.Context, value string, ipAddress string, userAgent string) (int, string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberUC.Redeem")
+	defer span.Finish()
+
+	seriesID, token, ok := parseValue(value)
+	if !ok {
+		return 0, "", remember.ErrNotFound
+	}
+	series, err := u.redisRepo.Get(ctx, seriesID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	presented := hashToken(seriesID, token)
+	if !equalHash(presented, series.TokenHash) {
+		if u.withinGrace(series, presented) {
+			return series.UserID, "", nil
+		}
+		return 0, "", u.theft(ctx, series, ipAddress, userAgent)
+	}
+
+	next, err := generateToken()
+	if err != nil {
+		return 0, "", errors.Wrap(err, "rememberUC.Redeem.generateToken")
+	}
+	now := u.clock.Now().UTC()
+	rotated := *series
+	rotated.PreviousHash = series.TokenHash
+	rotated.TokenHash = hashToken(seriesID, next)
+	rotated.RotatedAt = &now
+	rotated.LastUsedAt = now
+	rotated.IPAddress = ipAddress
+	rotated.UserAgent = userAgent
+
+	ok, err = u.redisRepo.Rotate(ctx, &rotated, series.TokenHash)
+	if err != nil {
+		return 0, "", err
+	}
+	if !ok {
+		// A concurrent request of the same device rotated first, the token it presented is the previous one now
+		current, err := u.redisRepo.Get(ctx, seriesID)
+		if err != nil {
+			return 0, "", err
+		}
+		if u.withinGrace(current, presented) {
+			return current.UserID, "", nil
+		}
+		return 0, "", u.theft(ctx, current, ipAddress, userAgent)
+	}
+	return series.UserID, seriesID + "." + next, nil
+}
+
+// End the series of cookie value, values not matching a live token are ignored
+func (u *rememberUC) Forget(ctx context.Context, value string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberUC.Forget")
+	defer span.Finish()
+
+	seriesID, token, ok := parseValue(value)
+	if !ok {
+		return nil
+	}
+	series, err := u.redisRepo.Get(ctx, seriesID)
+	if err != nil {
+		if errors.Is(err, remember.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	presented := hashToken(seriesID, token)
+	if !equalHash(presented, series.TokenHash) && !equalHash(presented, series.PreviousHash) {
+		return nil
+	}
+	_, err = u.redisRepo.Delete(ctx, series.UserID, seriesID)
+	return err
+}
+
+// List remembered devices of the current user
+func (u *rememberUC) ListDevices(ctx context.Context) ([]*models.RememberDevice, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberUC.ListDevices")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(err)
+	}
+	list, err := u.redisRepo.ListByUser(ctx, user.User.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]*models.RememberDevice, 0, len(list))
+	for _, series := range list {
+		device := series.RememberDevice
+		devices = append(devices, &device)
+	}
+	return devices, nil
+}
+
+// Forget a remembered device of the current user, its sessions are left alone
+func (u *rememberUC) RevokeDevice(ctx context.Context, seriesID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "rememberUC.RevokeDevice")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(err)
+	}
+	deleted, err := u.redisRepo.Delete(ctx, user.User.ID, seriesID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return httpErrors.NewRestError(http.StatusNotFound, "remembered device not found", seriesID)
+	}
+
+	u.record(ctx, auditActionDeviceRevoked, user.User.ID, user.User.ID, "", map[string]string{"series": seriesID})
+	return nil
+}
+
+func (u *rememberUC) withinGrace(series *models.RememberSeries, presented string) bool {
+	return series.RotatedAt != nil &&
+		equalHash(presented, series.PreviousHash) &&
+		u.clock.Since(*series.RotatedAt) <= u.grace()
+}
+
+// Sign the user out everywhere, the copied cookie must not keep a session alive
+func (u *rememberUC) theft(ctx context.Context, series *models.RememberSeries, ipAddress string, userAgent string) error {
+	deleted, err := u.redisRepo.DeleteByUser(ctx, series.UserID)
+	if err != nil {
+		return err
+	}
+	result, err := u.sessUC.RevokeSessions(ctx, &models.SessionRevokeCriteria{UserIDs: []int{series.UserID}})
+	if err != nil {
+		return err
+	}
+
+	u.logger.Warnf("rememberUC.theft userID: %d, series: %s, ip: %s", series.UserID, series.Series, ipAddress)
+	u.record(ctx, auditActionTheftDetected, 0, series.UserID, ipAddress, map[string]interface{}{
+		"series":           series.Series,
+		"user_agent":       userAgent,
+		"series_revoked":   deleted,
+		"sessions_revoked": result.Revoked,
+	})
+	return remember.ErrTheft
+}
+
+func (u *rememberUC) record(ctx context.Context, action string, actorID, userID int, ipAddress string, metadata interface{}) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	event := &models.AuditEvent{
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Resource:  "user:" + strconv.Itoa(userID),
+	}
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+	if err := u.auditUC.Record(ctx, action, event, metadata); err != nil {
+		u.logger.Errorf("rememberUC.record.Record action: %s, error: %v", action, err)
+	}
+}
+
+func (u *rememberUC) expire() time.Duration {
+	if u.cfg.Remember.ExpireDays > 0 {
+		return time.Duration(u.cfg.Remember.ExpireDays) * 24 * time.Hour
+	}
+	return defaultExpire
+}
+
+func (u *rememberUC) grace() time.Duration {
+	if u.cfg.Remember.GraceSeconds > 0 {
+		return time.Duration(u.cfg.Remember.GraceSeconds) * time.Second
+	}
+	return defaultGrace
+}
+
+func parseValue(value string) (string, string, bool) {
+	seriesID, token, ok := strings.Cut(value, ".")
+	if !ok || seriesID == "" || token == "" {
+		return "", "", false
+	}
+	return seriesID, token, true
+}
+
+func generateToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Token hashes are bound to their series
+func hashToken(seriesID, token string) string {
+	sum := sha256.Sum256([]byte(seriesID + ":" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+func equalHash(a, b string) bool {
+	return b != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/remember/repository"
+	sessionMock "github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+func TestRememberUC_Redeem(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := &config.Config{
+		Logger:   config.Logger{Level: "info"},
+		Remember: config.Remember{Enabled: true, Prefix: "remember", ExpireDays: 30, GraceSeconds: 30},
+	}
+	clk := clock.NewFrozen(time.Now())
+	uc := NewRememberUseCase(cfg, repository.NewRememberRedisRepo(client, cfg.Remember.Prefix), sessionMock.NewMockUCSession(ctrl), nil, clk, testutil.Logger(cfg))
+	ctx := context.Background()
+
+	first, err := uc.Issue(ctx, 1, "10.0.0.1", "browser")
+	require.NoError(t, err)
+
+	userID, second, err := uc.Redeem(ctx, first, "10.0.0.2", "browser")
+	require.NoError(t, err)
+	require.Equal(t, 1, userID)
+	require.NotEqual(t, first, second)
+
+	// A request racing the rotation still carries the previous token
+	clk.Advance(10 * time.Second)
+	userID, rotated, err := uc.Redeem(ctx, first, "10.0.0.2", "browser")
+	require.NoError(t, err)
+	require.Equal(t, 1, userID)
+	require.Empty(t, rotated)
+
+	third, err := uc.Issue(ctx, 1, "10.0.0.3", "phone")
+	require.NoError(t, err)
+	devices, err := uc.ListDevices(testutil.AsUser(1))
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+
+	_, _, err = uc.Redeem(ctx, "unknown.token", "10.0.0.2", "browser")
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+	_, _, err = uc.Redeem(ctx, "garbage", "10.0.0.2", "browser")
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+
+	// Forgotten on logout
+	require.NoError(t, uc.Forget(ctx, third))
+	_, _, err = uc.Redeem(ctx, third, "10.0.0.3", "phone")
+	testutil.RequireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestRememberUC_RedeemTheft(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := &config.Config{
+		Logger:   config.Logger{Level: "info"},
+		Remember: config.Remember{Enabled: true, Prefix: "remember", ExpireDays: 30, GraceSeconds: 30},
+	}
+	sessUC := sessionMock.NewMockUCSession(ctrl)
+	clk := clock.NewFrozen(time.Now())
+	uc := NewRememberUseCase(cfg, repository.NewRememberRedisRepo(client, cfg.Remember.Prefix), sessUC, nil, clk, testutil.Logger(cfg))
+	ctx := context.Background()
+
+	stolen, err := uc.Issue(ctx, 1, "10.0.0.1", "browser")
+	require.NoError(t, err)
+	other, err := uc.Issue(ctx, 1, "10.0.0.3", "phone")
+	require.NoError(t, err)
+	_, current, err := uc.Redeem(ctx, stolen, "10.0.0.1", "browser")
+	require.NoError(t, err)
+
+	// The previous token replayed after the grace period means two holders of the cookie
+	clk.Advance(time.Minute)
+	sessUC.EXPECT().RevokeSessions(gomock.Any(), &models.SessionRevokeCriteria{UserIDs: []int{1}}).
+		Return(&models.SessionRevokeResult{Revoked: 2}, nil)
+	_, _, err = uc.Redeem(ctx, stolen, "10.0.0.9", "curl")
+	require.ErrorIs(t, err, remember.ErrTheft)
+
+	// Every series of the user ended, the legitimate one too
+	_, _, err = uc.Redeem(ctx, current, "10.0.0.1", "browser")
+	require.ErrorIs(t, err, remember.ErrNotFound)
+	_, _, err = uc.Redeem(ctx, other, "10.0.0.3", "phone")
+	require.ErrorIs(t, err, remember.ErrNotFound)
+	devices, err := uc.ListDevices(testutil.AsUser(1))
+	require.NoError(t, err)
+	require.Empty(t, devices)
+}
+
+func TestRememberUC_RevokeDevice(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := &config.Config{
+		Logger:   config.Logger{Level: "info"},
+		Remember: config.Remember{Enabled: true, Prefix: "remember", ExpireDays: 30, GraceSeconds: 30},
+	}
+	uc := NewRememberUseCase(cfg, repository.NewRememberRedisRepo(client, cfg.Remember.Prefix), sessionMock.NewMockUCSession(ctrl), nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := context.Background()
+
+	value, err := uc.Issue(ctx, 1, "10.0.0.1", "browser")
+	require.NoError(t, err)
+	devices, err := uc.ListDevices(testutil.AsUser(1))
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, "browser", devices[0].UserAgent)
+
+	// Series of other users look missing
+	testutil.RequireStatus(t, uc.RevokeDevice(testutil.AsUser(2), devices[0].Series), http.StatusNotFound)
+	testutil.RequireStatus(t, uc.RevokeDevice(ctx, devices[0].Series), http.StatusUnauthorized)
+	_, value, err = uc.Redeem(ctx, value, "10.0.0.1", "browser")
+	require.NoError(t, err)
+
+	require.NoError(t, uc.RevokeDevice(testutil.AsUser(1), devices[0].Series))
+	_, _, err = uc.Redeem(ctx, value, "10.0.0.1", "browser")
+	require.ErrorIs(t, err, remember.ErrNotFound)
+}
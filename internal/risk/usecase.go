@@ -0,0 +1,17 @@
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package risk
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/riskscore"
+)
+
+// Login risk use case
+type UseCase interface {
+	// Score a login whose credentials matched, every decision is logged, the ones above allow are audited
+	// and alert the user
+	AssessLogin(ctx context.Context, attempt *riskscore.Attempt) (*riskscore.Assessment, error)
+	// Report outcome of a login attempt to the scorers keeping history
+	ObserveLogin(ctx context.Context, attempt *riskscore.Attempt) error
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/risk"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/riskscore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const auditActionLoginRisk = "login.risk"
+
+// Login risk UseCase
+type riskUC struct {
+	engine     *riskscore.Engine
+	auditUC    audit.UseCase
+	webhooksUC webhooks.UseCase
+	clock      clock.Clock
+	metrics    metric.Metrics
+	logger     logger.Logger
+}
+
+// Login risk UseCase constructor, a nil engine allows every login. auditUC, webhooksUC and metrics may be nil
+func NewRiskUseCase(
+	engine *riskscore.Engine,
+	auditUC audit.UseCase,
+	webhooksUC webhooks.UseCase,
+	clk clock.Clock,
+	metrics metric.Metrics,
+	log logger.Logger,
+) risk.UseCase {
+	return &riskUC{engine: engine, auditUC: auditUC, webhooksUC: webhooksUC, clock: clk, metrics: metrics, logger: log}
+}
+
+// Score login and act on the decision the caller can't take itself, auditing it and alerting the user
+func (u *riskUC) AssessLogin(ctx context.Context, attempt *riskscore.Attempt) (*riskscore.Assessment, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "riskUC.AssessLogin")
+	defer span.Finish()
+
+	if u.engine == nil {
+		return &riskscore.Assessment{Decision: riskscore.DecisionAllow}, nil
+	}
+	if attempt.At.IsZero() {
+		attempt.At = u.clock.Now()
+	}
+
+	// Failing scorers are left out of the score, logins go on while a store is down
+	assessment, err := u.engine.Assess(ctx, attempt)
+	if err != nil {
+		u.logger.Errorf("riskUC.AssessLogin userID: %d, error: %v", attempt.UserID, err)
+	}
+
+	signals, _ := json.Marshal(assessment.Signals)
+	u.logger.Infof("riskUC.AssessLogin userID: %d, IP: %s, score: %.2f, decision: %s, signals: %s",
+		attempt.UserID, attempt.IPAddress, assessment.Score, assessment.Decision, signals)
+	if u.metrics != nil {
+		u.metrics.IncRiskDecisions(assessment.Decision)
+	}
+	if assessment.Decision == riskscore.DecisionAllow {
+		return assessment, nil
+	}
+
+	u.audit(ctx, attempt, assessment)
+	if u.webhooksUC != nil {
+		// The user hears of the attempt whatever its outcome, a failed alert must not change it
+		if err := u.webhooksUC.Publish(ctx, attempt.UserID, models.WebhookEventLoginSuspicious, map[string]interface{}{
+			"ip_address": attempt.IPAddress,
+			"user_agent": attempt.UserAgent,
+			"decision":   assessment.Decision,
+			"reasons":    reasons(assessment),
+		}); err != nil {
+			u.logger.Errorf("riskUC.AssessLogin.Publish userID: %d, error: %v", attempt.UserID, err)
+		}
+	}
+	return assessment, nil
+}
+
+// Report outcome of a login attempt
+func (u *riskUC) ObserveLogin(ctx context.Context, attempt *riskscore.Attempt) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "riskUC.ObserveLogin")
+	defer span.Finish()
+
+	if u.engine == nil {
+		return nil
+	}
+	if attempt.At.IsZero() {
+		attempt.At = u.clock.Now()
+	}
+	return u.engine.Observe(ctx, attempt)
+}
+
+// Audit decision, tuning thresholds starts from these records
+func (u *riskUC) audit(ctx context.Context, attempt *riskscore.Attempt, assessment *riskscore.Assessment) {
+	if u.auditUC == nil {
+		return
+	}
+	userID := attempt.UserID
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	if err := u.auditUC.Record(ctx, auditActionLoginRisk, &models.AuditEvent{
+		ActorID:   &userID,
+		IPAddress: attempt.IPAddress,
+		RequestID: requestID,
+		Resource:  "user:" + strconv.Itoa(userID),
+	}, map[string]interface{}{
+		"decision":   assessment.Decision,
+		"score":      assessment.Score,
+		"signals":    assessment.Signals,
+		"user_agent": attempt.UserAgent,
+	}); err != nil {
+		u.logger.Errorf("riskUC.audit userID: %d, error: %v", userID, err)
+	}
+}
+
+func reasons(assessment *riskscore.Assessment) []string {
+	reasons := make([]string, 0, len(assessment.Signals))
+	for _, signal := range assessment.Signals {
+		reasons = append(reasons, signal.Reason)
+	}
+	return reasons
+}
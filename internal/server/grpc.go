@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const grpcContentType = "application/grpc"
+
+// gRPC server sharing the REST port
+type grpcServer struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+// Build gRPC server with health service and optional reflection
+func (s *Server) newGRPCServer() *grpcServer {
+	server := grpc.NewServer(grpc.MaxHeaderListSize(maxHeaderBytes))
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	if s.cfg.Server.GRPCReflection && s.cfg.Exposure.Active().GRPCReflection {
+		reflection.Register(server)
+	}
+
+	return &grpcServer{server: server, health: healthServer}
+}
+
+// Route HTTP/2 requests with a gRPC content-type to the gRPC server and everything else to next.
+// Calls share the http server timeouts, WriteTimeout bounds streaming calls as well.
+func (g *grpcServer) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get(echo.HeaderContentType), grpcContentType) {
+			g.server.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Report NOT_SERVING to health checks and close gRPC transports
+func (g *grpcServer) shutdown() {
+	g.health.Shutdown()
+	g.server.Stop()
+}
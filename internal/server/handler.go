@@ -1,27 +1,139 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/docs"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/accesslog"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/apikey"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/binder"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/cacheable"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/compaction"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/consistency"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/csrf"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/cursor"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/stmtcache"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/explain"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/idcodec"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jsonapi"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jwks"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/observe"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/outbox"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/ratelimit"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/replay"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/reqschema"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/riskscore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/routes"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scanner"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scheduler"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/slo"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/sms"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
 	echoSwagger "github.com/swaggo/echo-swagger"
 
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens"
+	accessTokensHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens/delivery/http"
+	accessTokensRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens/repository"
+	accessTokensUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/accesstokens/usecase"
+	adminHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/admin/delivery/http"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	auditRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/audit/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/auth"
 	authHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/delivery/http"
 	authRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/billing"
+	billingHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/billing/delivery/http"
+	billingRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/billing/repository"
+	billingUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/billing/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed"
+	changefeedHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed/delivery/http"
+	changefeedRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/contacts"
+	contactsHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/contacts/delivery/http"
+	contactsRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/contacts/repository"
+	emailPolicyHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy/delivery/http"
+	emailPolicyRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy/repository"
+	emailPolicyUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/emailpolicy/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/files"
+	filesHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/files/delivery/http"
+	filesRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/files/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/guest"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync"
+	hrSyncHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync/delivery/http"
+	hrSyncRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync/repository"
+	hrSyncUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/hrsync/usecase"
+	ipFilterHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter/delivery/http"
+	ipFilterRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter/repository"
+	jobsHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/jobs/delivery/http"
+	loggingHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/logging/delivery/http"
+	loggingRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/logging/repository"
+	loggingUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/logging/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/organizations"
+	organizationsHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/delivery/http"
+	organizationsRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/repository"
+	organizationsUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/organizations/usecase"
+	otpRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/otp/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation"
+	passwordRotationHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/delivery/http"
+	passwordRotationRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/repository"
+	passwordRotationUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/passwordrotation/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/presence"
+	presenceHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/presence/delivery/http"
+	presenceRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/presence/repository"
+	presenceUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/presence/usecase"
 	rbacHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/delivery/http"
 	rbacRepo "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/repository"
+	readModelRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel/repository"
+	readModelUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/readmodel/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/registration"
+	registrationHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/registration/delivery/http"
+	registrationRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/registration/repository"
+	registrationUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/registration/usecase"
+	rememberHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/remember/delivery/http"
+	rememberRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/remember/repository"
+	rememberUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/remember/usecase"
 	sessionRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/session/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	settingsHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/settings/delivery/http"
+	settingsRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/settings/repository"
+	settingsUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/settings/usecase"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	webhooksHttp "github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks/delivery/http"
+	webhooksRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks/repository"
 
+	auditUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/audit/usecase"
 	authUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/auth/usecase"
+	changefeedUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/changefeed/usecase"
+	contactsUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/contacts/usecase"
+	filesUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/files/usecase"
+	guestRepository "github.com/aditwar-man/go-microservice-boilerplate/internal/guest/repository"
+	guestUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/guest/usecase"
+	ipFilterUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/ipfilter/usecase"
+	jobsUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/jobs/usecase"
+	otpUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/otp/usecase"
 	rbacUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/rbac/usecase"
+	riskUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/risk/usecase"
 	sessUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/session/usecase"
+	webhooksUseCase "github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks/usecase"
 
 	apiMiddlewares "github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
 )
@@ -37,67 +149,567 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 		s.cfg.Metrics.ServiceName,
 	)
 
-	aRepo := authRepository.NewAuthRepository(s.db)
-	roleRepo := rbacRepo.NewRoleRepository(s.db)
-	sRepo := sessionRepository.NewSessionRepository(s.redisClient, s.cfg)
+	zones, err := clock.NewZones(s.cfg.Clock.StorageTimezone, s.cfg.Clock.DefaultUserTimezone)
+	if err != nil {
+		return err
+	}
+	clk := clock.New(zones.Storage)
+
+	objectives, err := slo.NewFromConfig(s.cfg, clk)
+	if err != nil {
+		return err
+	}
+	go objectives.Run(s.ctx, time.Duration(s.cfg.SLO.RefreshSeconds)*time.Second, metrics)
+
+	piiCipher, err := pii.NewFromConfig(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	auditChainKey, err := auditUseCase.LoadChainKey(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	billingKey, err := billingUseCase.LoadWebhookKey(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	cursors, err := cursor.NewFromConfig(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	ids, err := idcodec.NewFromConfig(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	apiKeys, err := apikey.NewFromConfig(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	recorder, err := replay.NewRecorderFromConfig(s.ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	riskEngine, err := riskscore.NewEngineFromConfig(s.cfg, s.redisClient)
+	if err != nil {
+		return err
+	}
+
+	var (
+		aRepo     auth.Repository
+		roleRepo  rbacRepo.RoleRepository
+		grantRepo rbacRepo.GrantRepository
+		auditRepo audit.Repository
+		filesRepo files.Repository
+		guestRepo guest.Repository
+		contRepo  contacts.Repository
+		hooksRepo webhooks.Repository
+		patRepo   accesstokens.Repository
+		orgsRepo  organizations.Repository
+		setsRepo  settings.Repository
+		regRepo   registration.Repository
+		rotRepo   passwordrotation.Repository
+		billRepo  billing.Repository
+	)
+	if s.cfg.Dev.Enabled {
+		aRepo = authRepository.NewAuthMemoryRepository()
+		roleRepo = rbacRepo.NewRoleMemoryRepository()
+		grantRepo = rbacRepo.NewGrantMemoryRepository()
+		auditRepo = auditRepository.NewAuditMemoryRepository(auditChainKey)
+		filesRepo = filesRepository.NewFilesMemoryRepository()
+		guestRepo = guestRepository.NewGuestMemoryRepository(filesRepo)
+		contRepo = contactsRepository.NewContactsMemoryRepository()
+		hooksRepo = webhooksRepository.NewWebhooksMemoryRepository()
+		patRepo = accessTokensRepository.NewAccessTokensMemoryRepository()
+		orgsRepo = organizationsRepository.NewOrganizationsMemoryRepository()
+		setsRepo = settingsRepository.NewSettingsMemoryRepository()
+		regRepo = registrationRepository.NewRegistrationMemoryRepository()
+		rotRepo = passwordRotationRepository.NewPasswordRotationMemoryRepository(aRepo, orgsRepo)
+		billRepo = billingRepository.NewBillingMemoryRepository()
+	} else {
+		querySampler := explain.NewSampler(s.cfg, s.logger.Named("internal/auth"))
+		preparer := stmtcache.NewFromConfig(s.cfg)
+		aRepo = authRepository.NewAuthRepository(s.db, piiCipher, querySampler, preparer)
+		if s.pgxPool != nil {
+			aRepo = authRepository.NewAuthPgxRepository(s.pgxPool, piiCipher, querySampler)
+		}
+		// Lag tolerant reads are served by the replicas
+		if len(s.replicaDBs) > 0 {
+			replicas := make([]auth.Repository, 0, len(s.replicaDBs))
+			for _, replicaDB := range s.replicaDBs {
+				replicas = append(replicas, authRepository.NewAuthRepository(replicaDB, piiCipher, querySampler, preparer))
+			}
+			aRepo = authRepository.NewAuthReplicaRepository(aRepo, replicas, s.cfg, metrics)
+		}
+		// Migration target receives shadow traffic, requests are still served from the primary
+		if s.shadowDB != nil {
+			aRepo = authRepository.NewAuthShadowRepository(s.ctx, aRepo, authRepository.NewAuthRepository(s.shadowDB, piiCipher, nil, nil), s.cfg, metrics, s.logger.Named("internal/auth"))
+		}
+		roleRepo = rbacRepo.NewRoleRepository(s.db)
+		grantRepo = rbacRepo.NewGrantRepository(s.db)
+		auditRepo = auditRepository.NewAuditRepository(s.db, auditChainKey)
+		filesRepo = filesRepository.NewFilesRepository(s.db)
+		guestRepo = guestRepository.NewGuestRepository(s.db, filesRepo)
+		contRepo = contactsRepository.NewContactsRepository(s.db, piiCipher)
+		hooksRepo = webhooksRepository.NewWebhooksRepository(s.db, piiCipher)
+		patRepo = accessTokensRepository.NewAccessTokensRepository(s.db)
+		orgsRepo = organizationsRepository.NewOrganizationsRepository(s.db, piiCipher)
+		setsRepo = settingsRepository.NewSettingsRepository(s.db)
+		regRepo = registrationRepository.NewRegistrationRepository(s.db)
+		rotRepo = passwordRotationRepository.NewPasswordRotationRepository(s.db)
+		billRepo = billingRepository.NewBillingRepository(s.db)
+	}
+	filesAWSRepo := filesRepository.NewFilesBlobRepository(s.blobs)
+	sRepo := sessionRepository.NewClassedSessionRepository(sessionRepository.NewSessionRepository(s.redisClient, s.cfg, metrics), s.cfg, metrics, clk)
+	sessEventRepo := sessionRepository.NewEventRepository(s.redisClient, s.cfg)
 	authRedisRepo := authRepository.NewAuthRedisRepo(s.redisClient)
+	emailPolicyRedisRepo := emailPolicyRepository.NewEmailPolicyRedisRepo(s.redisClient, s.cfg.EmailPolicy.Prefix)
+	ipFilterRedisRepo := ipFilterRepository.NewIPFilterRedisRepo(s.redisClient, s.cfg.IPFilter.Prefix)
+	otpRedisRepo := otpRepository.NewOTPRedisRepo(s.redisClient)
+	webhooksRedisRepo := webhooksRepository.NewWebhooksRedisRepo(s.redisClient, s.cfg.Webhooks.DevicesPrefix)
+	roleRedisRepo := rbacRepo.NewRoleRedisRepository(s.redisClient)
+	settingsRedisRepo := settingsRepository.NewSettingsRedisRepo(s.redisClient, s.cfg.Settings.Channel, s.logger.Named("internal/settings"))
+	filesRedisRepo := filesRepository.NewFilesRedisRepo(s.redisClient, s.cfg.Files.Stream.ProgressPrefix)
+	rememberRedisRepo := rememberRepository.NewRememberRedisRepo(s.redisClient, s.cfg.Remember.Prefix)
+	rotationRedisRepo := passwordRotationRepository.NewPasswordRotationRedisRepo(s.redisClient, s.cfg.Rotation.PendingKey)
+	loggingRedisRepo := loggingRepository.NewLoggingRedisRepo(s.redisClient, s.cfg.Logger.LevelsKey, s.cfg.Logger.LevelsChannel, s.logger.Named("internal/logging"))
+	var auditAnchorRepo audit.AnchorRepository
+	if s.cfg.AuditChain.AnchorEnabled && s.awsClient != nil {
+		auditAnchorRepo = auditRepository.NewAuditAnchorAWSRepository(
+			s.awsClient,
+			s.cfg.AuditChain.AnchorBucket,
+			s.cfg.AuditChain.AnchorPrefix,
+			time.Duration(s.cfg.AuditChain.RetentionDays)*24*time.Hour,
+		)
+	}
+
+	uploadScanner, err := scanner.NewScanner(scanner.Options{
+		Driver:    s.cfg.Scanner.Driver,
+		Address:   s.cfg.Scanner.Address,
+		Timeout:   time.Duration(s.cfg.Scanner.TimeoutSeconds) * time.Second,
+		ChunkSize: s.cfg.Scanner.ChunkSize,
+	})
+	if err != nil {
+		return err
+	}
+	jobQueue := jobqueue.NewQueue(s.redisClient, s.cfg.JobQueue.Name)
+
+	smsSender, err := sms.NewFromConfig(s.ctx, s.cfg, s.logger)
+	if err != nil {
+		return err
+	}
+
+	// Fakes record outbound messages to the outbox instead of sending them
+	var outboxBlobs storage.BlobStore
+	if s.cfg.Dev.Enabled {
+		outboxBlobs = s.blobs
+	}
+	fakes := outbox.NewFromConfig(s.cfg, outboxBlobs, clk, s.logger)
+	var webhooksTransport http.RoundTripper
+	if fakes != nil {
+		smsSender = fakes.SMS()
+		webhooksTransport = fakes.Transport(outbox.ChannelWebhook)
+	}
 
-	// Init useCases
-	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, s.logger)
-	sessUC := sessUseCase.NewSessionUseCase(sRepo, s.cfg)
-	rbacUc := rbacUseCase.NewRbacUsecase(s.cfg, roleRepo, s.logger)
+	// Init useCases, observed with a deadline, span, call logger and latency metric unless disabled
+	var observer *observe.Observer
+	if s.cfg.Observe.Enabled {
+		observer = observe.New(metrics, s.logger, time.Duration(s.cfg.Observe.UseCaseTimeoutMs)*time.Millisecond)
+	}
+	auditUC := auditUseCase.NewObservedUseCase(auditUseCase.NewAuditUseCase(s.cfg, auditRepo, auditAnchorRepo, auditChainKey, clk, s.logger.Named("internal/audit")), observer)
+	emailPolicyUC := emailPolicyUseCase.NewObservedUseCase(emailPolicyUseCase.NewEmailPolicyUseCase(s.cfg, emailPolicyRedisRepo, net.DefaultResolver, clk, s.logger.Named("internal/emailpolicy")), observer)
+	webhooksUC := webhooksUseCase.NewObservedUseCase(webhooksUseCase.NewWebhooksUseCase(s.cfg, hooksRepo, webhooksRedisRepo, jobQueue, webhooksTransport, clk, s.logger.Named("internal/webhooks")), observer)
+	accessTokensUC := accessTokensUseCase.NewObservedUseCase(accessTokensUseCase.NewAccessTokensUseCase(s.cfg, patRepo, auditUC, clk, s.logger.Named("internal/accesstokens")), observer)
+	rbacUc := rbacUseCase.NewObservedRbacUsecase(rbacUseCase.NewRbacUsecase(s.cfg, roleRepo, grantRepo, roleRedisRepo, auditUC, webhooksUC, clk, metrics, s.logger.Named("internal/rbac")), observer)
+	settingsUC := settingsUseCase.NewObservedUseCase(settingsUseCase.NewSettingsUseCase(s.cfg, setsRepo, settingsRedisRepo, rbacUc, auditUC, clk, s.logger.Named("internal/settings")), observer)
+	// Results of methods annotated for caching, keyed by ids of one database so tenants would read each other's
+	var useCaseCache *cacheable.Cache
+	if s.cfg.Cache.UseCases && !s.cfg.Tenancy.Isolated() {
+		useCaseCache = cacheable.New(cacheable.NewRedisStore(s.redisClient, s.cfg.Cache.UseCasePrefix), time.Duration(s.cfg.Cache.UseCaseTTL)*time.Second, metrics, s.logger.Named("cacheable"))
+	}
+	// Plans of the billing entitlements gate feature flags only while billing is enabled
+	var billingUC billing.UseCase
+	if s.cfg.Billing.Enabled {
+		billingUC = billingUseCase.NewObservedUseCase(billingUseCase.NewCachedUseCase(billingUseCase.NewBillingUseCase(s.cfg, billRepo, orgsRepo, billingKey, clk, s.logger.Named("internal/billing")), useCaseCache), observer)
+	}
+	authUC := authUseCase.NewObservedUseCase(authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, auditUC, emailPolicyUC, settingsUC, billingUC, clk, metrics, s.logger.Named("internal/auth")), observer)
+	sessUC := sessUseCase.NewObservedUCSession(sessUseCase.NewSessionUseCase(sRepo, sessEventRepo, s.cfg, clk, metrics, auditUC), observer)
+	rememberUC := rememberUseCase.NewObservedUseCase(rememberUseCase.NewRememberUseCase(s.cfg, rememberRedisRepo, sessUC, auditUC, clk, s.logger.Named("internal/remember")), observer)
+	ipFilterUC := ipFilterUseCase.NewObservedUseCase(ipFilterUseCase.NewIPFilterUseCase(s.cfg, ipFilterRedisRepo, clk, s.logger.Named("internal/ipfilter")), observer)
+	filesUC := filesUseCase.NewObservedUseCase(filesUseCase.NewFilesUseCase(s.cfg, filesRepo, filesAWSRepo, filesRedisRepo, uploadScanner, jobQueue, s.logger.Named("internal/files")), observer)
+	guestUC := guestUseCase.NewObservedUseCase(guestUseCase.NewGuestUseCase(guestRepo, s.logger.Named("internal/guest")), observer)
+	jobsUC := jobsUseCase.NewObservedUseCase(jobsUseCase.NewJobsUseCase(jobQueue, s.logger.Named("internal/jobs")), observer)
+	otpUC := otpUseCase.NewObservedUseCase(otpUseCase.NewOTPUseCase(s.cfg, authUC, otpRedisRepo, smsSender, s.logger.Named("internal/otp")), observer)
+	contactsUC := contactsUseCase.NewObservedUseCase(contactsUseCase.NewContactsUseCase(s.cfg, contRepo, s.logger.Named("internal/contacts")), observer)
+	orgsUC := organizationsUseCase.NewObservedUseCase(organizationsUseCase.NewCachedUseCase(organizationsUseCase.NewOrganizationsUseCase(s.cfg, orgsRepo, clk, s.logger.Named("internal/organizations")), useCaseCache), observer)
+	loggingUC := loggingUseCase.NewObservedUseCase(loggingUseCase.NewLoggingUseCase(loggingRedisRepo, s.logger.Levels(), auditUC, s.logger.Named("internal/logging")), observer)
+	rotationUC := passwordRotationUseCase.NewObservedUseCase(passwordRotationUseCase.NewPasswordRotationUseCase(s.cfg, rotRepo, rotationRedisRepo, auditUC, clk, s.logger.Named("internal/passwordrotation")), observer)
+	regUC := registrationUseCase.NewObservedUseCase(registrationUseCase.NewRegistrationUseCase(s.cfg, regRepo, settingsUC, rbacUc, orgsUC, auditUC, clk, s.logger.Named("internal/registration")), observer)
 
 	// Init handlers
-	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, sessUC, s.logger)
-	rbacHandlers := rbacHttp.NewRbacHandlers(s.cfg, rbacUc, s.logger)
+	riskUC := riskUseCase.NewObservedUseCase(riskUseCase.NewRiskUseCase(riskEngine, auditUC, webhooksUC, clk, metrics, s.logger.Named("internal/risk")), observer)
+	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, sessUC, guestUC, otpUC, webhooksUC, riskUC, regUC, rememberUC, rotationUC, zones, s.logger.Named("internal/auth"))
+	rbacHandlers := rbacHttp.NewRbacHandlers(s.cfg, rbacUc, s.logger.Named("internal/rbac"))
+	adminHandlers := adminHttp.NewAdminHandlers(s.cfg, s.cfgWatcher, authUC, sessUC, objectives, s.logger.Named("internal/admin"))
+	ipFilterHandlers := ipFilterHttp.NewIPFilterHandlers(s.cfg, ipFilterUC, s.logger.Named("internal/ipfilter"))
+	emailPolicyHandlers := emailPolicyHttp.NewEmailPolicyHandlers(s.cfg, emailPolicyUC, s.logger.Named("internal/emailpolicy"))
+	filesHandlers := filesHttp.NewFilesHandlers(s.cfg, filesUC, s.logger.Named("internal/files"))
+	jobsHandlers := jobsHttp.NewJobsHandlers(s.cfg, jobsUC, s.logger.Named("internal/jobs"))
+	contactsHandlers := contactsHttp.NewContactsHandlers(s.cfg, contactsUC, s.logger.Named("internal/contacts"))
+	accessTokensHandlers := accessTokensHttp.NewAccessTokensHandlers(s.cfg, accessTokensUC, s.logger.Named("internal/accesstokens"))
+	webhooksHandlers := webhooksHttp.NewWebhooksHandlers(s.cfg, webhooksUC, s.logger.Named("internal/webhooks"))
+	orgsHandlers := organizationsHttp.NewOrganizationsHandlers(s.cfg, orgsUC, s.logger.Named("internal/organizations"))
+	settingsHandlers := settingsHttp.NewSettingsHandlers(s.cfg, settingsUC, s.logger.Named("internal/settings"))
+	registrationHandlers := registrationHttp.NewRegistrationHandlers(s.cfg, regUC, s.logger.Named("internal/registration"))
+	loggingHandlers := loggingHttp.NewLoggingHandlers(s.cfg, loggingUC, s.logger.Named("internal/logging"))
+	rotationHandlers := passwordRotationHttp.NewPasswordRotationHandlers(s.cfg, rotationUC, s.logger.Named("internal/passwordrotation"))
+	rememberHandlers := rememberHttp.NewRememberHandlers(s.cfg, rememberUC, s.logger.Named("internal/remember"))
+
+	worker := jobqueue.NewWorker(jobQueue, jobqueue.Options{
+		MaxAttempts:     s.cfg.JobQueue.MaxAttempts,
+		PollTimeout:     time.Duration(s.cfg.JobQueue.PollTimeoutSeconds) * time.Second,
+		Concurrency:     s.cfg.JobQueue.Concurrency,
+		RetryBackoff:    time.Duration(s.cfg.JobQueue.RetryBackoffSeconds) * time.Second,
+		MaxRetryBackoff: time.Duration(s.cfg.JobQueue.MaxRetryBackoffSeconds) * time.Second,
+	}, s.logger)
+	worker.Handle(files.ScanJobType, filesUC.HandleScanJob)
+	worker.Handle(webhooks.DeliveryJobType, webhooksUC.HandleDeliveryJob)
+	go worker.Run(s.ctx)
+
+	sched := scheduler.New(s.redisClient, s.cfg.Scheduler.Prefix, s.logger)
+	// Cleanup jobs report per job metrics and only count in dry run mode, the account purge included
+	compactor := compaction.New(sched, s.cfg.Compaction.DryRun, metrics, s.logger)
+	compactor.Every("account_purge", time.Duration(s.cfg.Deletion.PurgeIntervalSeconds)*time.Second, authUC.PurgeDueDeletions)
+	if s.cfg.Compaction.Enabled {
+		compactionInterval := time.Duration(s.cfg.Compaction.IntervalSeconds) * time.Second
+		compactor.Every("session_index_compaction", compactionInterval, sessUC.CompactIndexes)
+		compactor.Every("login_history_compaction", compactionInterval, sessUC.PruneLoginHistory)
+		compactor.Every("audit_compaction", compactionInterval, auditUC.Prune)
+	}
+	sched.Every("multipart_abort", time.Duration(s.cfg.Files.Multipart.AbortIntervalSeconds)*time.Second, func(ctx context.Context) error {
+		aborted, err := filesUC.AbortStaleUploads(ctx)
+		if aborted > 0 {
+			s.logger.Infof("Aborted %d stale multipart uploads", aborted)
+		}
+		return err
+	})
+	sched.Every("file_blob_sweep", time.Duration(s.cfg.Files.BlobSweepSeconds)*time.Second, func(ctx context.Context) error {
+		removed, err := filesUC.RemoveUnreferencedBlobs(ctx)
+		if removed > 0 {
+			s.logger.Infof("Removed %d unreferenced file blobs", removed)
+		}
+		return err
+	})
+
+	sched.Every("role_grant_expiry", time.Duration(s.cfg.Access.Grants.ExpiryIntervalSeconds)*time.Second, func(ctx context.Context) error {
+		revoked, err := rbacUc.RevokeExpiredGrants(ctx)
+		if revoked > 0 {
+			s.logger.Infof("Revoked %d expired role grants", revoked)
+		}
+		return err
+	})
 
-	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, s.cfg, []string{"*"}, s.logger)
+	// Chain head is anchored outside the database so a rewritten chain is still detected
+	if auditAnchorRepo != nil {
+		sched.Every("audit_anchor", time.Duration(s.cfg.AuditChain.AnchorIntervalSeconds)*time.Second, func(ctx context.Context) error {
+			_, err := auditUC.Anchor(ctx)
+			return err
+		})
+	}
 
-	e.Use(mw.RequestLoggerMiddleware)
+	// Users are synced from the HR system declared as the hr downstream service
+	var hrSyncUC hrsync.UseCase
+	if s.cfg.HRSync.Enabled {
+		hrClient, err := services.NewHRClient(s.services)
+		if err != nil {
+			return err
+		}
+		hrSyncRedisRepo := hrSyncRepository.NewHRSyncRedisRepo(s.redisClient, s.cfg.HRSync.Prefix)
+		hrSyncUC = hrSyncUseCase.NewObservedUseCase(hrSyncUseCase.NewHRSyncUseCase(s.cfg, hrClient, authUC, hrSyncRedisRepo, clk, s.logger.Named("internal/hrsync")), observer)
+		sched.Every("hr_sync", time.Duration(s.cfg.HRSync.IntervalSeconds)*time.Second, func(ctx context.Context) error {
+			_, err := hrSyncUC.Sync(ctx, hrsync.TriggerSchedule)
+			if errors.Is(err, hrsync.ErrSyncInProgress) {
+				return nil
+			}
+			return err
+		})
+	}
+	var presenceUC presence.UseCase
+	if s.cfg.Presence.Enabled {
+		presenceRedisRepo := presenceRepository.NewPresenceRedisRepo(s.redisClient, s.cfg.Presence.Prefix, s.cfg.Presence.Channel)
+		presenceUC = presenceUseCase.NewObservedUseCase(presenceUseCase.NewPresenceUseCase(s.cfg, presenceRedisRepo, clk, s.logger.Named("internal/presence")), observer)
+		sched.Every("presence_sweep", time.Duration(s.cfg.Presence.SweepIntervalSeconds)*time.Second, func(ctx context.Context) error {
+			_, err := presenceUC.Sweep(ctx)
+			return err
+		})
+	}
 
-	docs.SwaggerInfo.Title = "Go example REST API"
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	// Settings changed on any instance drop the cached settings of every other one
+	go settingsRedisRepo.ListenChanges(s.ctx, settingsUC.HandleChange)
+
+	// Log level overrides are kept in redis so every instance converges on them, a failed load leaves the configured level
+	if err := loggingUC.Load(s.ctx); err != nil {
+		s.logger.Errorf("loggingUC.Load: %v", err)
+	}
+	go loggingRedisRepo.ListenChanges(s.ctx, loggingUC.HandleChange)
+
+	// Pending rotations are mirrored into redis for the session middleware, a failed load keeps the previous mirror
+	if s.cfg.Rotation.Enabled {
+		if err := rotationUC.Load(s.ctx); err != nil {
+			s.logger.Errorf("rotationUC.Load: %v", err)
+		}
+	}
+
+	// Change log is written by Postgres triggers, dev mode has neither the listener nor the sync endpoint
+	var changeFeedUC changefeed.UseCase
+	if !s.cfg.Dev.Enabled {
+		changeFeedRepo := changefeedRepository.NewChangeFeedRepository(s.db)
+		changeFeedUC = changefeedUseCase.NewObservedUseCase(
+			changefeedUseCase.NewChangeFeedUseCase(s.cfg, changeFeedRepo, []changefeed.CacheInvalidator{authUC}, authUC, cursors, clk, s.logger.Named("internal/changefeed")),
+			observer,
+		)
+		if s.cfg.ChangeFeed.Enabled {
+			listener := postgres.NewListener(s.cfg, changefeed.Channel, changeFeedUC.HandleNotification, changeFeedUC.Backfill, s.logger)
+			go listener.Run(s.ctx)
+		}
+		// Read models hold the users of one database, tenants would overwrite each other
+		if s.cfg.ReadModel.Enabled && !s.cfg.Tenancy.Isolated() {
+			readModelRedisRepo := readModelRepository.NewReadModelRedisRepo(s.redisClient, s.cfg.ReadModel.Prefix)
+			readModelUC := readModelUseCase.NewObservedUseCase(readModelUseCase.NewReadModelUseCase(s.cfg, readModelRedisRepo, changeFeedRepo, aRepo, clk, s.logger.Named("internal/readmodel")), observer)
+			sched.Every("read_model_projection", time.Duration(s.cfg.ReadModel.IntervalSeconds)*time.Second, func(ctx context.Context) error {
+				_, err := readModelUC.Project(ctx)
+				return err
+			})
+		}
+	}
+	go sched.Run(s.ctx)
+
+	// Database per tenant mode routes repositories to the pool of the request tenant, dev mode keeps everything in memory
+	var tenants *tenant.Router
+	if !s.cfg.Dev.Enabled {
+		tenants = tenant.NewRouter(s.cfg, s.logger.Named("tenant"))
+	}
+	if tenants != nil {
+		go func() {
+			<-s.ctx.Done()
+			tenants.Close()
+		}()
+	}
+
+	limiter := ratelimit.NewLimiter(s.redisClient, s.cfg.RateLimit.Prefix)
+	// Generated docs are the spec unless another one is configured
+	schemas, err := reqschema.NewFromConfig(s.cfg, docs.SwaggerInfo.ReadDoc())
+	if err != nil {
+		return err
+	}
+	// Every instance logs and uploads its own requests
+	if s.cfg.AccessLog.Enabled {
+		accessLogOpts := accesslog.Options{
+			Format:   s.cfg.AccessLog.Format,
+			Dir:      s.cfg.AccessLog.Dir,
+			MaxBytes: int64(s.cfg.AccessLog.MaxMegabytes) << 20,
+			MaxFiles: s.cfg.AccessLog.MaxFiles,
+		}
+		if s.cfg.AccessLog.Upload {
+			accessLogOpts.Blobs = s.blobs
+			accessLogOpts.Bucket = s.cfg.AccessLog.Bucket
+			accessLogOpts.Prefix = s.cfg.AccessLog.Prefix
+			accessLogOpts.UploadInterval = time.Duration(s.cfg.AccessLog.UploadIntervalSeconds) * time.Second
+		}
+		if s.accessLog, err = accesslog.New(accessLogOpts, s.logger.Named("accesslog")); err != nil {
+			return err
+		}
+		go s.accessLog.Run(s.ctx)
+	}
+	// Personal access tokens are refused by the bearer middlewares while disabled
+	var patAuthUC accesstokens.UseCase
+	if s.cfg.AccessTokens.Enabled {
+		patAuthUC = accessTokensUC
+	}
+	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, s.cfg, []string{"*"}, s.logger.Named("internal/middleware"), limiter, auditUC, ipFilterUC, jwks.NewFromConfig(s.cfg, s.logger), rbacUc, orgsUC, rememberUC, rotationUC, tenants, apiKeys, patAuthUC, schemas, metrics, s.accessLog)
+
+	// Middlewares and routes registered from here on are listed by /admin/routes
+	routeTable := routes.New(e, apiMiddlewares.AuthRequirements)
+
+	e.Binder = binder.New(s.cfg.Server.MaxBodyBytes, ids)
+	if e.IPExtractor, err = ipExtractor(s.cfg.Server.TrustedProxies); err != nil {
+		return err
+	}
+	if ids != nil {
+		routeTable.Use(mw.IDParamsMiddleware(ids))
+	}
+	routeTable.Use(mw.RequestLoggerMiddleware)
+	if s.accessLog != nil {
+		routeTable.Use(mw.AccessLogMiddleware)
+	}
+
+	// Optional surfaces are registered only when the exposure profile allows them
+	exposure := s.cfg.Exposure.Active()
+	if exposure.Swagger {
+		docs.SwaggerInfo.Title = "Go example REST API"
+		e.GET("/swagger/*", echoSwagger.WrapHandler)
+	}
+	if fakes != nil {
+		fakes.MapRoutes(e)
+	}
 
 	if s.cfg.Server.SSL {
-		e.Pre(middleware.HTTPSRedirect())
+		routeTable.Pre(middleware.HTTPSRedirect())
 	}
 
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderXRequestID, csrf.CSRFHeader},
-	}))
-	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
+	corsConfig := middleware.CORSConfig{
+		AllowOrigins:  []string{"*"},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderXRequestID, echo.HeaderAuthorization, csrf.CSRFHeader},
+		ExposeHeaders: []string{echo.HeaderXRequestID, tracing.HeaderTraceID, tracing.HeaderTraceLink},
+	}
+	if s.cfg.Deprecation.Enabled {
+		corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, s.cfg.Deprecation.ClientHeader)
+		corsConfig.ExposeHeaders = append(corsConfig.ExposeHeaders, deprecation.HeaderDeprecation, deprecation.HeaderSunset)
+	}
+	if s.cfg.Canary.Enabled && s.cfg.Canary.Header != "" {
+		corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, s.cfg.Canary.Header)
+	}
+	readYourWrites := s.cfg.Replicas.Enabled && s.cfg.Replicas.ReadYourWrites && len(s.replicaDBs) > 0
+	if readYourWrites {
+		corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, consistency.HeaderToken)
+		corsConfig.ExposeHeaders = append(corsConfig.ExposeHeaders, consistency.HeaderToken)
+	}
+	routeTable.Use(middleware.CORSWithConfig(corsConfig))
+	routeTable.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		StackSize:         1 << 10, // 1 KB
 		DisablePrintStack: true,
 		DisableStackAll:   true,
 	}))
-	e.Use(middleware.RequestID())
-	e.Use(mw.MetricsMiddleware(metrics))
+	routeTable.Use(middleware.RequestID())
+	routeTable.Use(mw.MetricsMiddleware(metrics, objectives))
+	routeTable.Use(mw.ProfilingLabelsMiddleware)
+	routeTable.Use(mw.PermissionsMemoMiddleware)
+	if readYourWrites {
+		routeTable.Use(mw.ConsistencyMiddleware)
+	}
+	if presenceUC != nil {
+		routeTable.Use(mw.PresenceMiddleware(presenceUC))
+	}
+	if s.cfg.Deprecation.Enabled {
+		e.JSONSerializer = deprecation.Serializer{}
+		routeTable.Use(mw.DeprecationMiddleware(metrics))
+	}
+	if s.cfg.JSONAPI.Enabled {
+		serializer := jsonapi.Serializer{Next: e.JSONSerializer}
+		if ids != nil {
+			serializer.EncodeID = ids.Encode
+		}
+		e.JSONSerializer = serializer
+	}
+	if !s.cfg.Problems.Legacy {
+		e.JSONSerializer = httpErrors.ProblemSerializer{Next: e.JSONSerializer, TypeBaseURL: s.cfg.Problems.TypeBaseURL}
+		e.HTTPErrorHandler = httpErrors.ErrorHandler
+		e.GET(httpErrors.ProblemsPath+"/:type", httpErrors.ProblemPage(time.Duration(s.cfg.Problems.PageMaxAgeSeconds)*time.Second))
+	}
+	// Outermost serializer, ids are encoded in whatever the others wrote
+	if ids != nil {
+		e.JSONSerializer = idcodec.Serializer{Next: e.JSONSerializer, Codec: ids}
+	}
 
-	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
+	routeTable.Use(middleware.GzipWithConfig(middleware.GzipConfig{
 		Level: 5,
 		Skipper: func(c echo.Context) bool {
 			return strings.Contains(c.Request().URL.Path, "swagger")
 		},
 	}))
-	e.Use(middleware.Secure())
-	e.Use(middleware.BodyLimit("2M"))
-	if s.cfg.Server.Debug {
-		e.Use(mw.DebugMiddleware)
+	if s.cfg.Security.Headers {
+		routeTable.Use(mw.SecurityHeaders())
+	}
+	routeTable.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit: "2M",
+		// Streamed uploads enforce their own limit while reading
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Method == http.MethodPost && c.Request().URL.Path == "/api/v1/files/stream"
+		},
+	}))
+	if s.cfg.Server.Debug && exposure.Debug {
+		routeTable.Use(mw.DebugMiddleware)
+	}
+	if recorder != nil {
+		routeTable.Use(mw.ReplayRecorder(recorder))
 	}
+	// Innermost, the middlewares above see the error responses handlers return as written responses
+	routeTable.Use(mw.ErrorMiddleware)
 
-	v1 := e.Group("/api/v1")
+	v1 := e.Group("/api/v1", mw.TenantDB)
 
 	health := v1.Group("/health")
 	authGroup := v1.Group("/auth")
+	filesGroup := v1.Group("/files")
+	scopedGroup := v1.Group("/scoped")
+	adminGroup := v1.Group("/admin", mw.IPFilter("admin"), mw.AuthSessionMiddleware, mw.AdminMiddleware)
 
 	authHttp.MapAuthRoutes(authGroup, authHandlers, mw, authUC, s.cfg)
 	rbacHttp.MapRbacRoutes(authGroup, rbacHandlers, mw, authUC, s.cfg)
+	contactsHttp.MapContactsRoutes(authGroup, contactsHandlers, mw)
+	if s.cfg.Remember.Enabled {
+		rememberHttp.MapRememberRoutes(authGroup, rememberHandlers, mw)
+	}
+	if s.cfg.Webhooks.Enabled {
+		webhooksHttp.MapWebhooksRoutes(authGroup, webhooksHandlers, mw)
+	}
+	if s.cfg.AccessTokens.Enabled {
+		accessTokensHttp.MapAccessTokensRoutes(authGroup, accessTokensHandlers, mw)
+	}
+	authHttp.MapScopedAuthRoutes(scopedGroup, authHandlers, mw)
+	contactsHttp.MapScopedContactsRoutes(scopedGroup, contactsHandlers, mw)
+	if changeFeedUC != nil {
+		changeFeedHandlers := changefeedHttp.NewChangeFeedHandlers(s.cfg, changeFeedUC, s.logger.Named("internal/changefeed"))
+		changefeedHttp.MapChangeFeedRoutes(v1.Group("/users"), changeFeedHandlers, mw, authUC, s.cfg)
+	}
+	if presenceUC != nil {
+		presenceHandlers := presenceHttp.NewPresenceHandlers(s.cfg, presenceUC, s.logger.Named("internal/presence"))
+		presenceHttp.MapPresenceRoutes(v1.Group("/users"), presenceHandlers, mw)
+	}
+	filesHttp.MapFilesRoutes(filesGroup, filesHandlers, mw)
+	organizationsHttp.MapOrganizationsRoutes(v1.Group("/organizations"), orgsHandlers, mw)
+	if billingUC != nil {
+		billingHandlers := billingHttp.NewBillingHandlers(s.cfg, billingUC, s.logger.Named("internal/billing"))
+		billingHttp.MapBillingRoutes(v1.Group("/billing"), billingHandlers, mw)
+	}
+	adminHttp.MapAdminRoutes(adminGroup, adminHandlers, mw)
+	rbacHttp.MapRoleGrantRoutes(adminGroup, rbacHandlers, mw)
+	if s.cfg.Server.AdminUI {
+		adminHttp.MapAdminUIRoutes(v1.Group("/admin", mw.IPFilter("admin")), adminGroup, adminHandlers)
+	}
+	ipFilterHttp.MapIPFilterRoutes(adminGroup, ipFilterHandlers, mw)
+	emailPolicyHttp.MapEmailPolicyRoutes(adminGroup, emailPolicyHandlers, mw)
+	if s.cfg.Rotation.Enabled {
+		passwordRotationHttp.MapPasswordRotationRoutes(adminGroup, rotationHandlers, mw)
+	}
+	settingsHttp.MapSettingsRoutes(v1.Group("/settings"), adminGroup, settingsHandlers, mw)
+	registrationHttp.MapRegistrationRoutes(adminGroup, registrationHandlers, mw)
+	loggingHttp.MapLoggingRoutes(adminGroup, loggingHandlers, mw)
+	jobsHttp.MapJobsRoutes(adminGroup, jobsHandlers, mw)
+	if hrSyncUC != nil {
+		hrSyncHttp.MapHRSyncRoutes(adminGroup, hrSyncHttp.NewHRSyncHandlers(s.cfg, hrSyncUC, s.logger.Named("internal/hrsync")), mw)
+	}
+	s.mapModules(v1, mw, routeTable)
+	routeTable.MapRoutes(adminGroup)
 
 	health.GET("", func(c echo.Context) error {
 		s.logger.Infof("Health check RequestID: %s", utils.GetRequestID(c))
 		return c.JSON(http.StatusOK, map[string]string{"status": "OK"})
 	})
+	// Only the cheap checks, stale rows are counted by the searchindex command
+	if !s.cfg.Dev.Enabled {
+		searchIndex := authRepository.NewUserSearchIndex(s.db)
+		health.GET("/search", func(c echo.Context) error {
+			result, err := searchIndex.Health(c.Request().Context(), false)
+			if err != nil {
+				s.logger.Errorf("Search index health check RequestID: %s, Error: %s", utils.GetRequestID(c), err)
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "ERROR"})
+			}
+			if !result.Healthy() {
+				return c.JSON(http.StatusServiceUnavailable, result)
+			}
+			return c.JSON(http.StatusOK, result)
+		})
+	}
 
 	return nil
 }
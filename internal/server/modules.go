@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+
+	apiMiddlewares "github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/routes"
+)
+
+// Self wiring bounded context mounted under /api/v1/<name>, scaffolded with `go run ./cmd/gen module <name>`
+type Module interface {
+	Name() string
+	MapRoutes(group *echo.Group, mw *apiMiddlewares.MiddlewareManager)
+}
+
+// Mount registered modules, their routes are listed under the module name
+func (s *Server) mapModules(v1 *echo.Group, mw *apiMiddlewares.MiddlewareManager, routeTable *routes.Table) {
+	for _, module := range s.modules() {
+		routeTable.Within(module.Name(), func() {
+			module.MapRoutes(v1.Group("/"+module.Name()), mw)
+		})
+		s.logger.Infof("Module mapped: %s", module.Name())
+	}
+}
@@ -0,0 +1,8 @@
+// Code generated by cmd/gen. Entries are appended by `go run ./cmd/gen module <name>`, hand edits to the list are kept.
+
+package server
+
+// Modules registered by the scaffolding generator
+func (s *Server) modules() []Module {
+	return []Module{}
+}
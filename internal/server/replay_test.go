@@ -0,0 +1,85 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/csrf"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/redis"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/replay"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+// Golden cassettes, recorded with Replay.Record and trimmed to the flows worth guarding.
+// Run with REPLAY_UPDATE=1 to rewrite their responses after an intended behavior change, then review the diff.
+const replayDir = "testdata/replay"
+
+// Paths differing on every run, on top of the ignore rules of each cassette
+var replayIgnore = []string{
+	"header.X-Ratelimit-Reset",
+	"body.**.created_at",
+	"body.**.updated_at",
+	"body.**.login_at",
+}
+
+// Dev mode handler stack, every cassette gets its own so it starts from empty repositories
+func newReplayHandler(t *testing.T) *echo.Echo {
+	cfgFile, err := config.LoadConfig("../../config/config-local")
+	require.NoError(t, err)
+	cfg, err := config.ParseConfig(cfgFile)
+	require.NoError(t, err)
+	cfg.Dev.Enabled = true
+	cfg.Server.SSL = false
+	cfg.Replay.Record = false
+	// Cassettes were recorded with the legacy error body
+	cfg.Problems.Legacy = true
+	cfg.Metrics.URL = "127.0.0.1:0"
+
+	redisClient, closeRedis, err := redis.NewInProcessRedisClient()
+	require.NoError(t, err)
+	t.Cleanup(closeRedis)
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	blobs := storage.NewLocal(store)
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	s := NewServer(cfg, nil, nil, nil, nil, nil, redisClient, nil, blobs, nil, appLogger)
+	t.Cleanup(s.cancel)
+	e := echo.New()
+	require.NoError(t, s.MapHandlers(e))
+	return e
+}
+
+func TestReplayCassettes(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(replayDir, "*"+replay.CassetteExt))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		path := path
+		t.Run(strings.TrimSuffix(filepath.Base(path), replay.CassetteExt), func(t *testing.T) {
+			replayer := &replay.Replayer{
+				Handler:     newReplayHandler(t),
+				Ignore:      replayIgnore,
+				Carry:       []string{csrf.CSRFHeader},
+				BearerField: "token",
+				Sanitizer:   replay.NewSanitizer(nil, nil, nil),
+				Update:      os.Getenv("REPLAY_UPDATE") == "1",
+			}
+			mismatches, err := replayer.ReplayFile(path)
+			require.NoError(t, err)
+			for _, mismatch := range mismatches {
+				t.Error(mismatch)
+			}
+		})
+	}
+}
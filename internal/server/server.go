@@ -2,23 +2,32 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/aditwar-man/go-microservice-boilerplate/config"
-	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
 	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/accesslog"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/services"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tlscert"
 )
 
 const (
-	certFile       = "ssl/server.crt"
-	keyFile        = "ssl/server.pem"
 	maxHeaderBytes = 1 << 20
 	ctxTimeout     = 5
 )
@@ -26,99 +35,205 @@ const (
 type Server struct {
 	echo        *echo.Echo
 	cfg         *config.Config
+	cfgWatcher  *config.Watcher
 	db          *sqlx.DB
+	shadowDB    *sqlx.DB
+	replicaDBs  []*sqlx.DB
+	pgxPool     *pgxpool.Pool
 	redisClient *redis.Client
 	awsClient   *minio.Client
+	blobs       storage.BlobStore
+	services    *services.Registry
+	grpc        *grpcServer
+	accessLog   *accesslog.Log
 	logger      logger.Logger
+
+	// Lifetime of background workers, cancelled on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewServer(
 	cfg *config.Config,
+	cfgWatcher *config.Watcher,
 	db *sqlx.DB,
+	shadowDB *sqlx.DB,
+	replicaDBs []*sqlx.DB,
+	pgxPool *pgxpool.Pool,
 	redisClient *redis.Client,
 	minio *minio.Client,
+	blobs storage.BlobStore,
+	serviceRegistry *services.Registry,
 	logger logger.Logger,
 ) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Server{
+		ctx:         ctx,
+		cancel:      cancel,
 		echo:        echo.New(),
 		cfg:         cfg,
+		cfgWatcher:  cfgWatcher,
 		db:          db,
+		shadowDB:    shadowDB,
+		replicaDBs:  replicaDBs,
+		pgxPool:     pgxPool,
 		redisClient: redisClient,
 		awsClient:   minio,
+		blobs:       blobs,
+		services:    serviceRegistry,
 		logger:      logger,
 	}
 }
 
 func (s *Server) Run() error {
-	if s.cfg.Server.SSL {
-		if err := s.MapHandlers(s.echo); err != nil {
+	if err := s.MapHandlers(s.echo); err != nil {
+		return err
+	}
+
+	if s.cfg.Server.GRPC {
+		s.grpc = s.newGRPCServer()
+	}
+
+	server := s.newHTTPServer()
+	listener, err := s.newListener()
+	if err != nil {
+		return err
+	}
+
+	// Static certificate files are the fallback when ACME is disabled
+	cert, key := config.CertFile, config.KeyFile
+	if s.cfg.Server.SSL && s.cfg.ACME.Enabled {
+		manager, err := tlscert.NewManager(s.cfg, s.redisClient, s.awsClient)
+		if err != nil {
 			return err
 		}
-		s.echo.Server.ReadTimeout = time.Second * s.cfg.Server.ReadTimeout
-		s.echo.Server.WriteTimeout = time.Second * s.cfg.Server.WriteTimeout
+		server.TLSConfig = manager.TLSConfig()
+		cert, key = "", ""
 
 		go func() {
-			s.logger.Infof("Server is listening on PORT: %s", s.cfg.Server.Port)
-			s.echo.Server.ReadTimeout = time.Second * s.cfg.Server.ReadTimeout
-			s.echo.Server.WriteTimeout = time.Second * s.cfg.Server.WriteTimeout
-			s.echo.Server.MaxHeaderBytes = maxHeaderBytes
-			if err := s.echo.StartTLS(s.cfg.Server.Port, certFile, keyFile); err != nil {
-				s.logger.Fatalf("Error starting TLS Server: ", err)
+			s.logger.Infof("Starting ACME HTTP-01 challenge Server on PORT: %s, Domains: %v", s.cfg.ACME.HTTPPort, s.cfg.ACME.Domains)
+			if err := http.ListenAndServe(s.cfg.ACME.HTTPPort, manager.HTTPHandler(nil)); err != nil {
+				s.logger.Errorf("Error ACME challenge ListenAndServe: %s", err)
 			}
 		}()
+	}
 
+	go func() {
+		s.logger.Infof("Server is listening on PORT: %s, SSL: %v, ACME: %v, H2C: %v, gRPC: %v", s.cfg.Server.Port, s.cfg.Server.SSL, s.cfg.ACME.Enabled, s.cfg.Server.H2C, s.cfg.Server.GRPC)
+		var err error
+		if s.cfg.Server.SSL {
+			err = server.ServeTLS(listener, cert, key)
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			s.logger.Fatalf("Error starting Server: %s", err)
+		}
+	}()
+
+	// Local blob storage receives presigned part uploads on its own port
+	if blobs, ok := s.blobs.(storage.Handler); ok {
+		go func() {
+			port := storage.HandlerPort(s.cfg)
+			s.logger.Infof("Starting Blob store Server on PORT: %s", port)
+			if err := http.ListenAndServe(port, blobs.Handler()); err != nil {
+				s.logger.Errorf("Error Blob store ListenAndServe: %s", err)
+			}
+		}()
+	}
+
+	if s.cfg.Exposure.Active().Pprof {
 		go func() {
 			s.logger.Infof("Starting Debug Server on PORT: %s", s.cfg.Server.PprofPort)
 			if err := http.ListenAndServe(s.cfg.Server.PprofPort, http.DefaultServeMux); err != nil {
 				s.logger.Errorf("Error PPROF ListenAndServe: %s", err)
 			}
 		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	<-quit
+	s.cancel()
 
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
+	defer shutdown()
 
-		<-quit
+	err = server.Shutdown(ctx)
+	if s.grpc != nil {
+		s.grpc.shutdown()
+	}
+	// Requests drained by the shutdown are logged by now, the last file is uploaded with the rest
+	if s.accessLog != nil {
+		if err := s.accessLog.Close(ctx); err != nil {
+			s.logger.Errorf("Error closing access log: %s", err)
+		}
+	}
 
-		ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
-		defer shutdown()
+	s.logger.Info("Server Exited Properly")
+	return err
+}
 
-		s.logger.Info("Server Exited Properly")
-		return s.echo.Server.Shutdown(ctx)
+// Build http server with timeouts, optional gRPC routing and optional cleartext HTTP/2.
+// gRPC needs HTTP/2, so without SSL it turns on H2C.
+func (s *Server) newHTTPServer() *http.Server {
+	var handler http.Handler = s.echo
+	if s.grpc != nil {
+		handler = s.grpc.handler(handler)
+	}
+	if (s.cfg.Server.H2C || s.grpc != nil) && !s.cfg.Server.SSL {
+		handler = h2c.NewHandler(handler, &http2.Server{
+			IdleTimeout: time.Second * s.cfg.Server.IdleTimeout,
+		})
 	}
 
 	server := &http.Server{
-		Addr:           s.cfg.Server.Port,
-		ReadTimeout:    time.Second * s.cfg.Server.ReadTimeout,
-		WriteTimeout:   time.Second * s.cfg.Server.WriteTimeout,
-		MaxHeaderBytes: maxHeaderBytes,
+		Addr:              s.cfg.Server.Port,
+		Handler:           handler,
+		ReadTimeout:       time.Second * s.cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: time.Second * s.cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      time.Second * s.cfg.Server.WriteTimeout,
+		IdleTimeout:       time.Second * s.cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
 	}
+	server.SetKeepAlivesEnabled(!s.cfg.Server.DisableKeepAlives)
+	s.echo.Server = server
 
-	go func() {
-		s.logger.Infof("Server is listening on PORT: %s", s.cfg.Server.Port)
-		if err := s.echo.StartServer(server); err != nil {
-			s.logger.Fatalf("Error starting Server: ", err)
-		}
-	}()
-
-	go func() {
-		s.logger.Infof("Starting Debug Server on PORT: %s", s.cfg.Server.PprofPort)
-		if err := http.ListenAndServe(s.cfg.Server.PprofPort, http.DefaultServeMux); err != nil {
-			s.logger.Errorf("Error PPROF ListenAndServe: %s", err)
-		}
-	}()
+	return server
+}
 
-	// if err := s.MapHandlers(s.echo); err != nil {
-	// 	return err
-	// }
+// Build tcp listener with keep-alive period and connection limit
+func (s *Server) newListener() (net.Listener, error) {
+	lc := net.ListenConfig{
+		KeepAlive: time.Second * s.cfg.Server.TCPKeepAlivePeriod,
+	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	listener, err := lc.Listen(s.ctx, "tcp", s.cfg.Server.Port)
+	if err != nil {
+		return nil, err
+	}
 
-	<-quit
+	if s.cfg.Server.MaxConnections > 0 {
+		listener = netutil.LimitListener(listener, s.cfg.Server.MaxConnections)
+	}
 
-	ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
-	defer shutdown()
+	return listener, nil
+}
 
-	s.logger.Info("Server Exited Properly")
-	return s.echo.Server.Shutdown(ctx)
+// Client ip extractor for RealIP, X-Forwarded-For is only read behind the configured proxies so clients
+// cannot choose the address rate limits and ip filters see
+func ipExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrap(err, "server.ipExtractor.ParseCIDR")
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
 }
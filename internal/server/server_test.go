@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestServer_H2C(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.GET("/ping", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Request().Proto)
+	})
+	s := &Server{echo: e, cfg: &config.Config{Server: config.ServerConfig{Port: "127.0.0.1:0", H2C: true}}, ctx: context.Background()}
+	listener, err := s.newListener()
+	require.NoError(t, err)
+	server := s.newHTTPServer()
+	go server.Serve(listener)
+	defer server.Close()
+
+	// Prior knowledge clients speak HTTP/2 without TLS
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	res, err := client.Get("http://" + listener.Addr().String() + "/ping")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "HTTP/2.0", res.Proto)
+
+	// HTTP/1.1 clients are still served
+	res, err = http.Get("http://" + listener.Addr().String() + "/ping")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "HTTP/1.1", res.Proto)
+}
+
+func TestServer_GRPCSharesPort(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.GET("/ping", func(c echo.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+	s := &Server{echo: e, cfg: &config.Config{Server: config.ServerConfig{Port: "127.0.0.1:0"}}, ctx: context.Background()}
+	s.grpc = s.newGRPCServer()
+	defer s.grpc.shutdown()
+	listener, err := s.newListener()
+	require.NoError(t, err)
+	server := s.newHTTPServer()
+	go server.Serve(listener)
+	defer server.Close()
+
+	// gRPC calls are routed to the gRPC server, over cleartext HTTP/2 without SSL
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+
+	// Everything else reaches echo
+	res, err := http.Get("http://" + listener.Addr().String() + "/ping")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NotEqual(t, grpcContentType, res.Header.Get(echo.HeaderContentType))
+}
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Session data key bound to the type of its value
+type DataKey[T any] string
+
+// Known session data keys
+var (
+	DataLocale        = DataKey[string]("locale")
+	DataImpersonation = DataKey[models.SessionImpersonation]("impersonation")
+)
+
+// Value of the key in an already loaded session, ok is false when it is not set
+func (k DataKey[T]) From(sess *models.Session) (value T, ok bool, err error) {
+	raw, found := sess.Data[string(k)]
+	if !found {
+		return value, false, nil
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, false, errors.Wrapf(err, "session.DataKey.From.json.Unmarshal key: %s", k)
+	}
+	return value, true, nil
+}
+
+// Read the value of the key from the session store, ok is false when it is not set
+func (k DataKey[T]) Get(ctx context.Context, uc UCSession, sessionID string) (value T, ok bool, err error) {
+	raw, err := uc.GetSessionData(ctx, sessionID, string(k))
+	if err != nil || raw == nil {
+		return value, false, err
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, false, errors.Wrapf(err, "session.DataKey.Get.json.Unmarshal key: %s", k)
+	}
+	return value, true, nil
+}
+
+// Store the value of the key in the session
+func (k DataKey[T]) Set(ctx context.Context, uc UCSession, sessionID string, value T) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "session.DataKey.Set.json.Marshal key: %s", k)
+	}
+	return uc.SetSessionData(ctx, sessionID, string(k), raw)
+}
+
+// Remove the key from the session
+func (k DataKey[T]) Delete(ctx context.Context, uc UCSession, sessionID string) error {
+	return uc.SetSessionData(ctx, sessionID, string(k), nil)
+}
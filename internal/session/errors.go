@@ -0,0 +1,68 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// StatusAuthenticationTimeout non standard status telling clients the session expired and a new login is due
+const StatusAuthenticationTimeout = 419
+
+// Session error kinds, used as metric labels
+const (
+	KindNotFound         = "not_found"
+	KindExpired          = "expired"
+	KindRevoked          = "revoked"
+	KindStoreUnavailable = "store_unavailable"
+	KindEvicted          = "evicted"
+	KindLimitReached     = "limit_reached"
+	KindDataTooLarge     = "data_too_large"
+)
+
+// Concurrent session limit policies
+const (
+	LimitPolicyReject      = "reject"
+	LimitPolicyEvictOldest = "evict_oldest"
+)
+
+// Typed session error, implements httpErrors.RestErr so it maps to its own status
+type Error struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Kind      string `json:"-"`
+}
+
+var (
+	ErrNotFound         = &Error{ErrStatus: http.StatusUnauthorized, ErrError: "session not found", Kind: KindNotFound}
+	ErrExpired          = &Error{ErrStatus: StatusAuthenticationTimeout, ErrError: "session expired", Kind: KindExpired}
+	ErrRevoked          = &Error{ErrStatus: http.StatusUnauthorized, ErrError: "session revoked", Kind: KindRevoked}
+	ErrStoreUnavailable = &Error{ErrStatus: http.StatusServiceUnavailable, ErrError: "session store unavailable", Kind: KindStoreUnavailable}
+	ErrEvicted          = &Error{ErrStatus: http.StatusUnauthorized, ErrError: "session ended by a login on another device", Kind: KindEvicted}
+	ErrLimitReached     = &Error{ErrStatus: http.StatusConflict, ErrError: "concurrent session limit reached", Kind: KindLimitReached}
+	ErrDataTooLarge     = &Error{ErrStatus: http.StatusRequestEntityTooLarge, ErrError: "session data exceeds its size cap", Kind: KindDataTooLarge}
+)
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s", e.ErrStatus, e.ErrError)
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Session errors carry no causes, details stay in logs
+func (e *Error) Causes() interface{} {
+	return nil
+}
+
+// Get session error kind, empty for errors outside the session layer
+func ErrorKind(err error) string {
+	var sessErr *Error
+	if errors.As(err, &sessErr) {
+		return sessErr.Kind
+	}
+	return ""
+}
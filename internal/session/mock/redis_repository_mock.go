@@ -1,40 +1,57 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: redis_repository.go
+// Source: internal/session/redis_repository.go
 
 // Package mock is a generated GoMock package.
 package mock
 
 import (
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	models "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	gomock "github.com/golang/mock/gomock"
-	reflect "reflect"
 )
 
-// MockSessRepository is a mock of SessRepository interface
+// MockSessRepository is a mock of SessRepository interface.
 type MockSessRepository struct {
 	ctrl     *gomock.Controller
 	recorder *MockSessRepositoryMockRecorder
 }
 
-// MockSessRepositoryMockRecorder is the mock recorder for MockSessRepository
+// MockSessRepositoryMockRecorder is the mock recorder for MockSessRepository.
 type MockSessRepositoryMockRecorder struct {
 	mock *MockSessRepository
 }
 
-// NewMockSessRepository creates a new mock instance
+// NewMockSessRepository creates a new mock instance.
 func NewMockSessRepository(ctrl *gomock.Controller) *MockSessRepository {
 	mock := &MockSessRepository{ctrl: ctrl}
 	mock.recorder = &MockSessRepositoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockSessRepository) EXPECT() *MockSessRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreateSession mocks base method
+// CompactUserIndexes mocks base method.
+func (m *MockSessRepository) CompactUserIndexes(ctx context.Context, dryRun bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompactUserIndexes", ctx, dryRun)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompactUserIndexes indicates an expected call of CompactUserIndexes.
+func (mr *MockSessRepositoryMockRecorder) CompactUserIndexes(ctx, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactUserIndexes", reflect.TypeOf((*MockSessRepository)(nil).CompactUserIndexes), ctx, dryRun)
+}
+
+// CreateSession mocks base method.
 func (m *MockSessRepository) CreateSession(ctx context.Context, session *models.Session, expire int) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", ctx, session, expire)
@@ -43,13 +60,41 @@ func (m *MockSessRepository) CreateSession(ctx context.Context, session *models.
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession
+// CreateSession indicates an expected call of CreateSession.
 func (mr *MockSessRepositoryMockRecorder) CreateSession(ctx, session, expire interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessRepository)(nil).CreateSession), ctx, session, expire)
 }
 
-// GetSessionByID mocks base method
+// DeleteByID mocks base method.
+func (m *MockSessRepository) DeleteByID(ctx context.Context, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockSessRepositoryMockRecorder) DeleteByID(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSessRepository)(nil).DeleteByID), ctx, sessionID)
+}
+
+// EvictSessions mocks base method.
+func (m *MockSessRepository) EvictSessions(ctx context.Context, userID int, sessionIDs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictSessions", ctx, userID, sessionIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictSessions indicates an expected call of EvictSessions.
+func (mr *MockSessRepositoryMockRecorder) EvictSessions(ctx, userID, sessionIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictSessions", reflect.TypeOf((*MockSessRepository)(nil).EvictSessions), ctx, userID, sessionIDs)
+}
+
+// GetSessionByID mocks base method.
 func (m *MockSessRepository) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionByID", ctx, sessionID)
@@ -58,22 +103,149 @@ func (m *MockSessRepository) GetSessionByID(ctx context.Context, sessionID strin
 	return ret0, ret1
 }
 
-// GetSessionByID indicates an expected call of GetSessionByID
+// GetSessionByID indicates an expected call of GetSessionByID.
 func (mr *MockSessRepositoryMockRecorder) GetSessionByID(ctx, sessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockSessRepository)(nil).GetSessionByID), ctx, sessionID)
 }
 
-// DeleteByID mocks base method
-func (m *MockSessRepository) DeleteByID(ctx context.Context, sessionID string) error {
+// ListUserSessions mocks base method.
+func (m *MockSessRepository) ListUserSessions(ctx context.Context, userID int) ([]*models.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", ctx, sessionID)
+	ret := m.ctrl.Call(m, "ListUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockSessRepositoryMockRecorder) ListUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockSessRepository)(nil).ListUserSessions), ctx, userID)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockSessRepository) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, criteria)
+	ret0, _ := ret[0].(*models.SessionRevokeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockSessRepositoryMockRecorder) RevokeSessions(ctx, criteria interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockSessRepository)(nil).RevokeSessions), ctx, criteria)
+}
+
+// UpdateSession mocks base method.
+func (m *MockSessRepository) UpdateSession(ctx context.Context, sessionID string, session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", ctx, sessionID, session)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteByID indicates an expected call of DeleteByID
-func (mr *MockSessRepositoryMockRecorder) DeleteByID(ctx, sessionID interface{}) *gomock.Call {
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockSessRepositoryMockRecorder) UpdateSession(ctx, sessionID, session interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSessRepository)(nil).DeleteByID), ctx, sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockSessRepository)(nil).UpdateSession), ctx, sessionID, session)
+}
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AppendEvent mocks base method.
+func (m *MockEventRepository) AppendEvent(ctx context.Context, event *models.SessionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendEvent indicates an expected call of AppendEvent.
+func (mr *MockEventRepositoryMockRecorder) AppendEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendEvent", reflect.TypeOf((*MockEventRepository)(nil).AppendEvent), ctx, event)
+}
+
+// ListEvents mocks base method.
+func (m *MockEventRepository) ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", ctx, before, limit)
+	ret0, _ := ret[0].(*models.SessionEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockEventRepositoryMockRecorder) ListEvents(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockEventRepository)(nil).ListEvents), ctx, before, limit)
+}
+
+// ListUserEvents mocks base method.
+func (m *MockEventRepository) ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserEvents", ctx, userID, before, limit)
+	ret0, _ := ret[0].(*models.SessionEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserEvents indicates an expected call of ListUserEvents.
+func (mr *MockEventRepositoryMockRecorder) ListUserEvents(ctx, userID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserEvents", reflect.TypeOf((*MockEventRepository)(nil).ListUserEvents), ctx, userID, before, limit)
+}
+
+// MarkHijackSuspected mocks base method.
+func (m *MockEventRepository) MarkHijackSuspected(ctx context.Context, sessionID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkHijackSuspected", ctx, sessionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkHijackSuspected indicates an expected call of MarkHijackSuspected.
+func (mr *MockEventRepositoryMockRecorder) MarkHijackSuspected(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkHijackSuspected", reflect.TypeOf((*MockEventRepository)(nil).MarkHijackSuspected), ctx, sessionID)
+}
+
+// TrimEvents mocks base method.
+func (m *MockEventRepository) TrimEvents(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrimEvents", ctx, before, dryRun)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrimEvents indicates an expected call of TrimEvents.
+func (mr *MockEventRepositoryMockRecorder) TrimEvents(ctx, before, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrimEvents", reflect.TypeOf((*MockEventRepository)(nil).TrimEvents), ctx, before, dryRun)
 }
@@ -1,40 +1,85 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: usecase.go
+// Source: internal/session/usecase.go
 
 // Package mock is a generated GoMock package.
 package mock
 
 import (
 	context "context"
+	json "encoding/json"
+	reflect "reflect"
+
 	models "github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	gomock "github.com/golang/mock/gomock"
-	reflect "reflect"
 )
 
-// MockUCSession is a mock of UCSession interface
+// MockUCSession is a mock of UCSession interface.
 type MockUCSession struct {
 	ctrl     *gomock.Controller
 	recorder *MockUCSessionMockRecorder
 }
 
-// MockUCSessionMockRecorder is the mock recorder for MockUCSession
+// MockUCSessionMockRecorder is the mock recorder for MockUCSession.
 type MockUCSessionMockRecorder struct {
 	mock *MockUCSession
 }
 
-// NewMockUCSession creates a new mock instance
+// NewMockUCSession creates a new mock instance.
 func NewMockUCSession(ctrl *gomock.Controller) *MockUCSession {
 	mock := &MockUCSession{ctrl: ctrl}
 	mock.recorder = &MockUCSessionMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockUCSession) EXPECT() *MockUCSessionMockRecorder {
 	return m.recorder
 }
 
-// CreateSession mocks base method
+// CheckHijack mocks base method.
+func (m *MockUCSession) CheckHijack(ctx context.Context, session *models.Session, ipAddress, userAgent string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckHijack", ctx, session, ipAddress, userAgent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckHijack indicates an expected call of CheckHijack.
+func (mr *MockUCSessionMockRecorder) CheckHijack(ctx, session, ipAddress, userAgent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckHijack", reflect.TypeOf((*MockUCSession)(nil).CheckHijack), ctx, session, ipAddress, userAgent)
+}
+
+// CheckStepUp mocks base method.
+func (m *MockUCSession) CheckStepUp(ctx context.Context, session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckStepUp", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckStepUp indicates an expected call of CheckStepUp.
+func (mr *MockUCSessionMockRecorder) CheckStepUp(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckStepUp", reflect.TypeOf((*MockUCSession)(nil).CheckStepUp), ctx, session)
+}
+
+// CompactIndexes mocks base method.
+func (m *MockUCSession) CompactIndexes(ctx context.Context, dryRun bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompactIndexes", ctx, dryRun)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompactIndexes indicates an expected call of CompactIndexes.
+func (mr *MockUCSessionMockRecorder) CompactIndexes(ctx, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactIndexes", reflect.TypeOf((*MockUCSession)(nil).CompactIndexes), ctx, dryRun)
+}
+
+// CreateSession mocks base method.
 func (m *MockUCSession) CreateSession(ctx context.Context, session *models.Session, expire int) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", ctx, session, expire)
@@ -43,13 +88,27 @@ func (m *MockUCSession) CreateSession(ctx context.Context, session *models.Sessi
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession
+// CreateSession indicates an expected call of CreateSession.
 func (mr *MockUCSessionMockRecorder) CreateSession(ctx, session, expire interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockUCSession)(nil).CreateSession), ctx, session, expire)
 }
 
-// GetSessionByID mocks base method
+// DeleteByID mocks base method.
+func (m *MockUCSession) DeleteByID(ctx context.Context, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockUCSessionMockRecorder) DeleteByID(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockUCSession)(nil).DeleteByID), ctx, sessionID)
+}
+
+// GetSessionByID mocks base method.
 func (m *MockUCSession) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSessionByID", ctx, sessionID)
@@ -58,22 +117,112 @@ func (m *MockUCSession) GetSessionByID(ctx context.Context, sessionID string) (*
 	return ret0, ret1
 }
 
-// GetSessionByID indicates an expected call of GetSessionByID
+// GetSessionByID indicates an expected call of GetSessionByID.
 func (mr *MockUCSessionMockRecorder) GetSessionByID(ctx, sessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockUCSession)(nil).GetSessionByID), ctx, sessionID)
 }
 
-// DeleteByID mocks base method
-func (m *MockUCSession) DeleteByID(ctx context.Context, sessionID string) error {
+// GetSessionData mocks base method.
+func (m *MockUCSession) GetSessionData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", ctx, sessionID)
+	ret := m.ctrl.Call(m, "GetSessionData", ctx, sessionID, key)
+	ret0, _ := ret[0].(json.RawMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionData indicates an expected call of GetSessionData.
+func (mr *MockUCSessionMockRecorder) GetSessionData(ctx, sessionID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionData", reflect.TypeOf((*MockUCSession)(nil).GetSessionData), ctx, sessionID, key)
+}
+
+// ListEvents mocks base method.
+func (m *MockUCSession) ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", ctx, before, limit)
+	ret0, _ := ret[0].(*models.SessionEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockUCSessionMockRecorder) ListEvents(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockUCSession)(nil).ListEvents), ctx, before, limit)
+}
+
+// ListUserEvents mocks base method.
+func (m *MockUCSession) ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserEvents", ctx, userID, before, limit)
+	ret0, _ := ret[0].(*models.SessionEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserEvents indicates an expected call of ListUserEvents.
+func (mr *MockUCSessionMockRecorder) ListUserEvents(ctx, userID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserEvents", reflect.TypeOf((*MockUCSession)(nil).ListUserEvents), ctx, userID, before, limit)
+}
+
+// PruneLoginHistory mocks base method.
+func (m *MockUCSession) PruneLoginHistory(ctx context.Context, dryRun bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneLoginHistory", ctx, dryRun)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneLoginHistory indicates an expected call of PruneLoginHistory.
+func (mr *MockUCSessionMockRecorder) PruneLoginHistory(ctx, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneLoginHistory", reflect.TypeOf((*MockUCSession)(nil).PruneLoginHistory), ctx, dryRun)
+}
+
+// Reauthenticate mocks base method.
+func (m *MockUCSession) Reauthenticate(ctx context.Context, sessionID string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reauthenticate", ctx, sessionID)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reauthenticate indicates an expected call of Reauthenticate.
+func (mr *MockUCSessionMockRecorder) Reauthenticate(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reauthenticate", reflect.TypeOf((*MockUCSession)(nil).Reauthenticate), ctx, sessionID)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockUCSession) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, criteria)
+	ret0, _ := ret[0].(*models.SessionRevokeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockUCSessionMockRecorder) RevokeSessions(ctx, criteria interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockUCSession)(nil).RevokeSessions), ctx, criteria)
+}
+
+// SetSessionData mocks base method.
+func (m *MockUCSession) SetSessionData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSessionData", ctx, sessionID, key, value)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteByID indicates an expected call of DeleteByID
-func (mr *MockUCSessionMockRecorder) DeleteByID(ctx, sessionID interface{}) *gomock.Call {
+// SetSessionData indicates an expected call of SetSessionData.
+func (mr *MockUCSessionMockRecorder) SetSessionData(ctx, sessionID, key, value interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockUCSession)(nil).DeleteByID), ctx, sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSessionData", reflect.TypeOf((*MockUCSession)(nil).SetSessionData), ctx, sessionID, key, value)
 }
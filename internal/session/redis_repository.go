@@ -3,6 +3,7 @@ package session
 
 import (
 	"context"
+	"time"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 )
@@ -12,4 +13,20 @@ type SessRepository interface {
 	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
 	GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error)
 	DeleteByID(ctx context.Context, sessionID string) error
+	UpdateSession(ctx context.Context, sessionID string, session *models.Session) error
+	RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error)
+	ListUserSessions(ctx context.Context, userID int) ([]*models.Session, error)
+	EvictSessions(ctx context.Context, userID int, sessionIDs []string) error
+	// Remove members of expired sessions from every user index, a dry run only counts them
+	CompactUserIndexes(ctx context.Context, dryRun bool) (int, error)
+}
+
+// Session lifecycle event store, streams are append only and capped by length
+type EventRepository interface {
+	AppendEvent(ctx context.Context, event *models.SessionEvent) error
+	ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error)
+	ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error)
+	MarkHijackSuspected(ctx context.Context, sessionID string) (bool, error)
+	// Remove events older than before from every stream, a dry run only counts them
+	TrimEvents(ctx context.Context, before time.Time, dryRun bool) (int, error)
 }
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// Redis operation classes, used as metric labels
+const (
+	classRead  = "read"
+	classWrite = "write"
+)
+
+// Results of a classed call, used as metric labels
+const (
+	classResultOK       = "ok"
+	classResultRetried  = "retried"
+	classResultFallback = "fallback"
+	classResultFailed   = "failed"
+)
+
+// Session repository applying the read and write operation classes of the redis config. Reads get the read
+// timeout and lookups fall back to recently read sessions, writes get the write timeout and are retried.
+// Bulk revocation walks the whole keyspace, so it keeps the client timeouts and is never retried
+type classedSessionRepo struct {
+	session.SessRepository
+	read     config.RedisOpClass
+	write    config.RedisOpClass
+	fallback *sessionFallback
+	metrics  metric.Metrics
+}
+
+// Classed session repository constructor, metrics may be nil
+func NewClassedSessionRepository(repo session.SessRepository, cfg *config.Config, metrics metric.Metrics, clk clock.Clock) session.SessRepository {
+	return &classedSessionRepo{
+		SessRepository: repo,
+		read:           cfg.Redis.Read,
+		write:          cfg.Redis.Write,
+		fallback:       newSessionFallback(cfg.Redis.Read, clk),
+		metrics:        metrics,
+	}
+}
+
+// Get session by id, while the store fails a session read within the fallback window is served from memory.
+// A revocation the store could not confirm is not seen until the window passes
+func (r *classedSessionRepo) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	start := time.Now()
+	var sess *models.Session
+	retried, err := r.do(ctx, r.read, func(ctx context.Context) error {
+		var err error
+		sess, err = r.SessRepository.GetSessionByID(ctx, sessionID)
+		return err
+	})
+	switch {
+	case err == nil:
+		r.fallback.put(sessionID, sess)
+	case errors.Is(err, session.ErrStoreUnavailable):
+		if cached := r.fallback.get(sessionID); cached != nil {
+			r.observe(classRead, classResultFallback, start)
+			return cached, nil
+		}
+	default:
+		r.fallback.drop(sessionID)
+	}
+	r.observe(classRead, classResult(retried, err), start)
+	return sess, err
+}
+
+// Live sessions of a user, read class without fallback
+func (r *classedSessionRepo) ListUserSessions(ctx context.Context, userID int) ([]*models.Session, error) {
+	start := time.Now()
+	var sessions []*models.Session
+	retried, err := r.do(ctx, r.read, func(ctx context.Context) error {
+		var err error
+		sessions, err = r.SessRepository.ListUserSessions(ctx, userID)
+		return err
+	})
+	r.observe(classRead, classResult(retried, err), start)
+	return sessions, err
+}
+
+// Create session, a failed attempt may still have been written so it is deleted before the next one. When
+// that delete fails too the orphan counts against the session limit of the user until it expires
+func (r *classedSessionRepo) CreateSession(ctx context.Context, sess *models.Session, expire int) (string, error) {
+	start := time.Now()
+	var sessionKey string
+	attempted := false
+	retried, err := r.do(ctx, r.write, func(ctx context.Context) error {
+		if attempted && sess.SessionID != "" {
+			_ = r.SessRepository.DeleteByID(ctx, sessionKeyOf(basePrefix, sess.SessionID))
+		}
+		attempted = true
+		var err error
+		sessionKey, err = r.SessRepository.CreateSession(ctx, sess, expire)
+		return err
+	})
+	r.observe(classWrite, classResult(retried, err), start)
+	return sessionKey, err
+}
+
+// Overwrite session payload, the write is idempotent so it is retried
+func (r *classedSessionRepo) UpdateSession(ctx context.Context, sessionID string, sess *models.Session) error {
+	start := time.Now()
+	r.fallback.drop(sessionID)
+	retried, err := r.do(ctx, r.write, func(ctx context.Context) error {
+		return r.SessRepository.UpdateSession(ctx, sessionID, sess)
+	})
+	r.observe(classWrite, classResult(retried, err), start)
+	return err
+}
+
+// Delete session by id
+func (r *classedSessionRepo) DeleteByID(ctx context.Context, sessionID string) error {
+	start := time.Now()
+	r.fallback.drop(sessionID)
+	retried, err := r.do(ctx, r.write, func(ctx context.Context) error {
+		return r.SessRepository.DeleteByID(ctx, sessionID)
+	})
+	r.observe(classWrite, classResult(retried, err), start)
+	return err
+}
+
+// End sessions of a user to make room for a newer one
+func (r *classedSessionRepo) EvictSessions(ctx context.Context, userID int, sessionIDs []string) error {
+	start := time.Now()
+	for _, sessionID := range sessionIDs {
+		r.fallback.drop(sessionKeyOf(basePrefix, sessionID))
+	}
+	retried, err := r.do(ctx, r.write, func(ctx context.Context) error {
+		return r.SessRepository.EvictSessions(ctx, userID, sessionIDs)
+	})
+	r.observe(classWrite, classResult(retried, err), start)
+	return err
+}
+
+// Revoke sessions matching criteria, the fallback forgets every session since the matches are not known up front
+func (r *classedSessionRepo) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	if !criteria.DryRun {
+		r.fallback.clear()
+	}
+	return r.SessRepository.RevokeSessions(ctx, criteria)
+}
+
+// Run op with the class timeout per attempt, zero keeps the client timeouts. Retries are extra attempts while the
+// store is unavailable and ctx is alive, the backoff doubles between them. Reports whether a retry was needed
+func (r *classedSessionRepo) do(ctx context.Context, class config.RedisOpClass, op func(ctx context.Context) error) (bool, error) {
+	backoff := time.Duration(class.RetryBackoffMs) * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := attemptOp(ctx, class, op)
+		if err == nil || attempt >= class.Retries || !errors.Is(err, session.ErrStoreUnavailable) {
+			return attempt > 0, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return true, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func attemptOp(ctx context.Context, class config.RedisOpClass, op func(ctx context.Context) error) error {
+	if class.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(class.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+	return op(ctx)
+}
+
+// Answers of the store such as a missing or revoked session are not failures of it
+func classResult(retried bool, err error) string {
+	if err != nil && (session.ErrorKind(err) == "" || errors.Is(err, session.ErrStoreUnavailable)) {
+		return classResultFailed
+	}
+	if retried {
+		return classResultRetried
+	}
+	return classResultOK
+}
+
+func (r *classedSessionRepo) observe(class, result string, start time.Time) {
+	if r.metrics != nil {
+		r.metrics.ObserveSessionStoreClass(class, result, time.Since(start).Seconds())
+	}
+}
+
+// Sessions recently read from the store by session key, least recently read ones are dropped past size
+type sessionFallback struct {
+	mu      sync.Mutex
+	maxAge  time.Duration
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+	clock   clock.Clock
+}
+
+type fallbackEntry struct {
+	sessionID string
+	session   *models.Session
+	readAt    time.Time
+}
+
+// Fallback of the read class keeping sessions read within FallbackSeconds, at most FallbackSize of them. Nil when
+// either is zero
+func newSessionFallback(class config.RedisOpClass, clk clock.Clock) *sessionFallback {
+	if class.FallbackSeconds <= 0 || class.FallbackSize <= 0 {
+		return nil
+	}
+	return &sessionFallback{
+		maxAge:  time.Duration(class.FallbackSeconds) * time.Second,
+		size:    class.FallbackSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		clock:   clk,
+	}
+}
+
+func (f *sessionFallback) put(sessionID string, sess *models.Session) {
+	if f == nil || sess == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry := fallbackEntry{sessionID: sessionID, session: cloneSession(sess), readAt: f.clock.Now()}
+	if element, ok := f.entries[sessionID]; ok {
+		element.Value = entry
+		f.lru.MoveToFront(element)
+		return
+	}
+	f.entries[sessionID] = f.lru.PushFront(entry)
+	if f.lru.Len() > f.size {
+		oldest := f.lru.Back()
+		f.lru.Remove(oldest)
+		delete(f.entries, oldest.Value.(fallbackEntry).sessionID)
+	}
+}
+
+// Copy of a session read within the window which has not expired yet, nil otherwise
+func (f *sessionFallback) get(sessionID string) *models.Session {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	element, ok := f.entries[sessionID]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(fallbackEntry)
+	now := f.clock.Now()
+	if now.Sub(entry.readAt) > f.maxAge || (!entry.session.ExpiresAt.IsZero() && !now.Before(entry.session.ExpiresAt)) {
+		f.lru.Remove(element)
+		delete(f.entries, sessionID)
+		return nil
+	}
+	// Sessions are handed out by pointer and their data may be written, so callers get a copy
+	return cloneSession(entry.session)
+}
+
+func (f *sessionFallback) drop(sessionID string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if element, ok := f.entries[sessionID]; ok {
+		f.lru.Remove(element)
+		delete(f.entries, sessionID)
+	}
+}
+
+func (f *sessionFallback) clear() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lru.Init()
+	f.entries = make(map[string]*list.Element)
+}
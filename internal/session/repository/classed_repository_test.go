@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+func newTestClassedCfg() *config.Config {
+	return &config.Config{Redis: config.RedisConfig{
+		Read:  config.RedisOpClass{TimeoutMs: 20, FallbackSeconds: 30, FallbackSize: 2},
+		Write: config.RedisOpClass{TimeoutMs: 1000, Retries: 2, RetryBackoffMs: 1},
+	}}
+}
+
+func TestClassedSessionRepo_ReadFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner, _ := newTestSessionRepo(t, 0)
+	clk := clock.NewFrozen(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := NewClassedSessionRepository(inner, newTestClassedCfg(), nil, clk)
+
+	key, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60)
+	require.NoError(t, err)
+	evictedKey, err := repo.CreateSession(ctx, &models.Session{UserID: 2}, 60)
+	require.NoError(t, err)
+	_, err = repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	evicted, err := repo.GetSessionByID(ctx, evictedKey)
+	require.NoError(t, err)
+	require.NoError(t, repo.EvictSessions(ctx, 2, []string{evicted.SessionID}))
+
+	require.NoError(t, inner.redisClient.Close())
+
+	// Recently read sessions keep validating while the store is down, ended ones don't come back
+	sess, err := repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 1, sess.UserID)
+	_, err = repo.GetSessionByID(ctx, evictedKey)
+	require.ErrorIs(t, err, session.ErrStoreUnavailable)
+	_, err = repo.ListUserSessions(ctx, 1)
+	require.ErrorIs(t, err, session.ErrStoreUnavailable)
+
+	clk.Advance(31 * time.Second)
+	_, err = repo.GetSessionByID(ctx, key)
+	require.ErrorIs(t, err, session.ErrStoreUnavailable)
+}
+
+func TestClassedSessionRepo_ReadTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	inner := mock.NewMockSessRepository(ctrl)
+	repo := NewClassedSessionRepository(inner, newTestClassedCfg(), nil, clock.New(nil))
+
+	inner.EXPECT().GetSessionByID(gomock.Any(), "key").DoAndReturn(func(ctx context.Context, _ string) (*models.Session, error) {
+		<-ctx.Done()
+		return nil, errors.Wrap(session.ErrStoreUnavailable, ctx.Err().Error())
+	})
+
+	started := time.Now()
+	_, err := repo.GetSessionByID(context.Background(), "key")
+	require.ErrorIs(t, err, session.ErrStoreUnavailable)
+	require.Less(t, time.Since(started), time.Second)
+}
+
+func TestClassedSessionRepo_WriteRetry(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	inner := mock.NewMockSessRepository(ctrl)
+	repo := NewClassedSessionRepository(inner, newTestClassedCfg(), nil, clock.New(nil))
+
+	// The failed attempt may have been stored, so it is deleted before the retry
+	gomock.InOrder(
+		inner.EXPECT().CreateSession(gomock.Any(), gomock.Any(), 60).DoAndReturn(func(_ context.Context, sess *models.Session, _ int) (string, error) {
+			sess.SessionID = "first"
+			return "", session.ErrStoreUnavailable
+		}),
+		inner.EXPECT().DeleteByID(gomock.Any(), sessionKeyOf(basePrefix, "first")).Return(nil),
+		inner.EXPECT().CreateSession(gomock.Any(), gomock.Any(), 60).DoAndReturn(func(_ context.Context, sess *models.Session, _ int) (string, error) {
+			sess.SessionID = "second"
+			return sessionKeyOf(basePrefix, "second"), nil
+		}),
+	)
+	key, err := repo.CreateSession(context.Background(), &models.Session{UserID: 1}, 60)
+	require.NoError(t, err)
+	require.Equal(t, sessionKeyOf(basePrefix, "second"), key)
+
+	// Store answers are not retried, failures stop after the configured retries
+	inner.EXPECT().UpdateSession(gomock.Any(), "revoked", gomock.Any()).Return(session.ErrRevoked)
+	require.ErrorIs(t, repo.UpdateSession(context.Background(), "revoked", &models.Session{}), session.ErrRevoked)
+	inner.EXPECT().DeleteByID(gomock.Any(), "down").Return(session.ErrStoreUnavailable).Times(3)
+	require.ErrorIs(t, repo.DeleteByID(context.Background(), "down"), session.ErrStoreUnavailable)
+}
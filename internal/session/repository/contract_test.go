@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+)
+
+func TestSessionRepository_RedisContract(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := newTestSessionRepo(t, 0)
+	runRepositoryContract(t, repo)
+}
+
+func TestSessionRepository_MemoryContract(t *testing.T) {
+	t.Parallel()
+
+	runRepositoryContract(t, NewSessionMemoryRepository(&config.Config{Session: config.Session{Expire: 3600}}))
+}
+
+func runRepositoryContract(t *testing.T, repo session.SessRepository) {
+	ctx := context.Background()
+	createdAt := time.Now().Add(-time.Hour).UTC()
+
+	sess := &models.Session{
+		UserID:    1,
+		IPAddress: "10.0.0.5",
+		CreatedAt: createdAt,
+		Data:      map[string]json.RawMessage{"theme": json.RawMessage(`"dark"`)},
+	}
+	key, err := repo.CreateSession(ctx, sess, 60)
+	require.NoError(t, err)
+	require.NotEmpty(t, sess.SessionID)
+
+	// Stored sessions do not alias the caller's
+	sess.Data["theme"][1] = 'X'
+	stored, err := repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, sess.SessionID, stored.SessionID)
+	require.JSONEq(t, `"dark"`, string(stored.Data["theme"]))
+
+	stored.TenantID = "acme"
+	require.NoError(t, repo.UpdateSession(ctx, key, stored))
+	updated, err := repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "acme", updated.TenantID)
+	require.ErrorIs(t, repo.UpdateSession(ctx, "missing", &models.Session{}), session.ErrNotFound)
+	_, err = repo.GetSessionByID(ctx, "missing")
+	require.ErrorIs(t, err, session.ErrNotFound)
+
+	// Guests are never listed
+	_, err = repo.CreateSession(ctx, &models.Session{Guest: true, GuestID: "guest"}, 60)
+	require.NoError(t, err)
+	second, err := repo.CreateSession(ctx, &models.Session{UserID: 1, IPAddress: "192.168.1.1", CreatedAt: createdAt.Add(time.Minute)}, 60)
+	require.NoError(t, err)
+	listed, err := repo.ListUserSessions(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	listed, err = repo.ListUserSessions(ctx, 2)
+	require.NoError(t, err)
+	require.Empty(t, listed)
+
+	dryRun, err := repo.RevokeSessions(ctx, &models.SessionRevokeCriteria{IPRange: "10.0.0.0/8", DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, dryRun.Matched)
+	require.Zero(t, dryRun.Revoked)
+
+	revoked, err := repo.RevokeSessions(ctx, &models.SessionRevokeCriteria{UserIDs: []int{1}, IPRange: "10.0.0.0/8"})
+	require.NoError(t, err)
+	require.Equal(t, 1, revoked.Revoked)
+	require.Len(t, revoked.Sessions, 1)
+	_, err = repo.GetSessionByID(ctx, key)
+	require.ErrorIs(t, err, session.ErrRevoked)
+	_, err = repo.RevokeSessions(ctx, &models.SessionRevokeCriteria{IPRange: "not a range"})
+	require.Error(t, err)
+
+	secondSession, err := repo.GetSessionByID(ctx, second)
+	require.NoError(t, err)
+	require.NoError(t, repo.EvictSessions(ctx, 1, []string{secondSession.SessionID}))
+	_, err = repo.GetSessionByID(ctx, second)
+	require.ErrorIs(t, err, session.ErrEvicted)
+	listed, err = repo.ListUserSessions(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, listed)
+
+	third, err := repo.CreateSession(ctx, &models.Session{UserID: 3}, 60)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteByID(ctx, third))
+	_, err = repo.GetSessionByID(ctx, third)
+	require.ErrorIs(t, err, session.ErrNotFound)
+}
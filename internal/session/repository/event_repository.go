@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+)
+
+const (
+	eventStreamKey        = "api-session-events"
+	userEventStreamPrefix = "api-session-events-user:"
+	hijackMarkerPrefix    = "api-session-hijack:"
+	// Stream entry field holding the json encoded event
+	eventField = "event"
+	// Streams per SCAN call and entries per XRANGE call of a trim
+	trimScanCount = 100
+	trimPageSize  = 1000
+)
+
+// Session event repository, every event goes to the stream of its user and to the stream of all users
+type eventRepo struct {
+	redisClient *redis.Client
+	cfg         *config.Config
+}
+
+// Session event repository constructor
+func NewEventRepository(redisClient *redis.Client, cfg *config.Config) session.EventRepository {
+	return &eventRepo{redisClient: redisClient, cfg: cfg}
+}
+
+// Append event to both streams in one transaction, ID is set to the id of the user stream entry
+func (r *eventRepo) AppendEvent(ctx context.Context, event *models.SessionEvent) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "eventRepo.AppendEvent")
+	defer span.Finish()
+
+	event.ID = ""
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.WithMessage(err, "eventRepo.AppendEvent.json.Marshal")
+	}
+
+	userStream := r.userStreamKey(event.UserID)
+	pipe := r.redisClient.TxPipeline()
+	userAdd := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: userStream,
+		MaxLen: r.cfg.Session.EventMaxLen,
+		Approx: true,
+		Values: []interface{}{eventField, eventBytes},
+	})
+	if r.cfg.Session.EventRetention > 0 {
+		pipe.Expire(ctx, userStream, time.Second*time.Duration(r.cfg.Session.EventRetention))
+	}
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventStreamKey,
+		MaxLen: r.cfg.Session.EventGlobalMaxLen,
+		Approx: true,
+		Values: []interface{}{eventField, eventBytes},
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrapf(session.ErrStoreUnavailable, "eventRepo.AppendEvent.pipe.Exec: %v", err)
+	}
+
+	event.ID = userAdd.Val()
+	return nil
+}
+
+// Events of a user newest first, older than the before entry id when set
+func (r *eventRepo) ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "eventRepo.ListUserEvents")
+	defer span.Finish()
+
+	return r.list(ctx, r.userStreamKey(userID), before, limit)
+}
+
+// Events of all users newest first, older than the before entry id when set
+func (r *eventRepo) ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "eventRepo.ListEvents")
+	defer span.Finish()
+
+	return r.list(ctx, eventStreamKey, before, limit)
+}
+
+// Mark session as suspected of hijacking, false when it already was so the suspicion is recorded once.
+// The marker lives as long as the longest session
+func (r *eventRepo) MarkHijackSuspected(ctx context.Context, sessionID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "eventRepo.MarkHijackSuspected")
+	defer span.Finish()
+
+	marked, err := r.redisClient.SetNX(ctx, hijackMarkerPrefix+sessionID, 1, time.Second*time.Duration(r.cfg.Session.Expire)).Result()
+	if err != nil {
+		return false, errors.Wrapf(session.ErrStoreUnavailable, "eventRepo.MarkHijackSuspected.SetNX: %v", err)
+	}
+	return marked, nil
+}
+
+// Trim every user stream and the stream of all users to the events at or after before. Entry ids start with
+// the append time in milliseconds, so the cut is by id and the streams are never read except on a dry run
+func (r *eventRepo) TrimEvents(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "eventRepo.TrimEvents")
+	defer span.Finish()
+
+	minID := fmt.Sprintf("%d-0", before.UnixMilli())
+	trimmed, err := r.trim(ctx, eventStreamKey, minID, dryRun)
+	if err != nil {
+		return 0, err
+	}
+
+	iter := r.redisClient.Scan(ctx, 0, userEventStreamPrefix+"*", trimScanCount).Iterator()
+	for iter.Next(ctx) {
+		count, err := r.trim(ctx, iter.Val(), minID, dryRun)
+		if err != nil {
+			return trimmed, err
+		}
+		trimmed += count
+	}
+	if err := iter.Err(); err != nil {
+		return trimmed, errors.Wrap(err, "eventRepo.TrimEvents.Scan")
+	}
+	return trimmed, nil
+}
+
+// Remove entries of stream below minID, or count them on a dry run
+func (r *eventRepo) trim(ctx context.Context, stream string, minID string, dryRun bool) (int, error) {
+	if !dryRun {
+		trimmed, err := r.redisClient.XTrimMinID(ctx, stream, minID).Result()
+		if err != nil {
+			return 0, errors.Wrapf(err, "eventRepo.trim.XTrimMinID %s", stream)
+		}
+		return int(trimmed), nil
+	}
+
+	count := 0
+	start := "-"
+	for {
+		messages, err := r.redisClient.XRangeN(ctx, stream, start, "("+minID, trimPageSize).Result()
+		if err != nil {
+			return 0, errors.Wrapf(err, "eventRepo.trim.XRangeN %s", stream)
+		}
+		count += len(messages)
+		if len(messages) < trimPageSize {
+			return count, nil
+		}
+		start = "(" + messages[len(messages)-1].ID
+	}
+}
+
+// Page of a stream read backwards, one entry more than the limit tells whether older ones remain
+func (r *eventRepo) list(ctx context.Context, stream string, before string, limit int) (*models.SessionEvents, error) {
+	end := "+"
+	if before != "" {
+		end = "(" + before
+	}
+	messages, err := r.redisClient.XRevRangeN(ctx, stream, end, "-", int64(limit)+1).Result()
+	if err != nil {
+		return nil, errors.Wrapf(session.ErrStoreUnavailable, "eventRepo.list.XRevRangeN: %v", err)
+	}
+
+	page := &models.SessionEvents{Events: make([]*models.SessionEvent, 0, len(messages))}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		page.HasMore = true
+	}
+	for _, message := range messages {
+		raw, _ := message.Values[eventField].(string)
+		event := &models.SessionEvent{}
+		if err := json.Unmarshal([]byte(raw), event); err != nil {
+			return nil, errors.Wrap(err, "eventRepo.list.json.Unmarshal")
+		}
+		event.ID = message.ID
+		page.Events = append(page.Events, event)
+	}
+	if page.HasMore {
+		page.NextCursor = page.Events[len(page.Events)-1].ID
+	}
+	return page, nil
+}
+
+func (r *eventRepo) userStreamKey(userID int) string {
+	return fmt.Sprintf("%s%d", userEventStreamPrefix, userID)
+}
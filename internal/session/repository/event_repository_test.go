@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+func TestEventRepo(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Close)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{Session: config.Session{Expire: 3600, EventMaxLen: 100, EventGlobalMaxLen: 100, EventRetention: 60}}
+	repo := NewEventRepository(client, cfg)
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	types := []string{models.SessionEventCreated, models.SessionEventRefreshed, models.SessionEventRevoked}
+	for i, eventType := range types {
+		event := &models.SessionEvent{Type: eventType, SessionID: "s1", UserID: 1, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, repo.AppendEvent(ctx, event))
+		require.NotEmpty(t, event.ID)
+	}
+	require.NoError(t, repo.AppendEvent(ctx, &models.SessionEvent{Type: models.SessionEventCreated, SessionID: "s2", UserID: 2}))
+
+	// Newest first, pages continue from the cursor without overlap
+	page, err := repo.ListUserEvents(ctx, 1, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page.Events, 2)
+	require.True(t, page.HasMore)
+	require.Equal(t, models.SessionEventRevoked, page.Events[0].Type)
+	require.Equal(t, models.SessionEventRefreshed, page.Events[1].Type)
+	require.Equal(t, now.Add(2*time.Second), page.Events[0].CreatedAt.UTC())
+
+	page, err = repo.ListUserEvents(ctx, 1, page.NextCursor, 2)
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+	require.Equal(t, models.SessionEventCreated, page.Events[0].Type)
+
+	all, err := repo.ListEvents(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, all.Events, 4)
+	require.Equal(t, 2, all.Events[0].UserID)
+
+	// User streams expire after the retention, the stream of all users is capped by length only
+	require.Equal(t, time.Minute, server.TTL(userEventStreamPrefix+"1"))
+	require.Zero(t, server.TTL(eventStreamKey))
+
+	empty, err := repo.ListUserEvents(ctx, 3, "", 10)
+	require.NoError(t, err)
+	require.Empty(t, empty.Events)
+
+	marked, err := repo.MarkHijackSuspected(ctx, "s1")
+	require.NoError(t, err)
+	require.True(t, marked)
+	marked, err = repo.MarkHijackSuspected(ctx, "s1")
+	require.NoError(t, err)
+	require.False(t, marked)
+}
+
+func TestEventRepo_TrimEvents(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Close)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := NewEventRepository(client, &config.Config{Session: config.Session{EventMaxLen: 100, EventGlobalMaxLen: 100}})
+	ctx := context.Background()
+
+	// Entry ids carry the time of the append
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, appended := range []struct {
+		userID int
+		at     time.Time
+	}{
+		{userID: 1, at: now.AddDate(0, 0, -100)},
+		{userID: 2, at: now.AddDate(0, 0, -95)},
+		{userID: 1, at: now.AddDate(0, 0, -1)},
+	} {
+		server.SetTime(appended.at)
+		require.NoError(t, repo.AppendEvent(ctx, &models.SessionEvent{Type: models.SessionEventCreated, UserID: appended.userID}))
+	}
+	before := now.AddDate(0, 0, -90)
+
+	// Both old events are in a user stream and the stream of all users
+	due, err := repo.TrimEvents(ctx, before, true)
+	require.NoError(t, err)
+	require.Equal(t, 4, due)
+	all, err := repo.ListEvents(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, all.Events, 3)
+
+	trimmed, err := repo.TrimEvents(ctx, before, false)
+	require.NoError(t, err)
+	require.Equal(t, 4, trimmed)
+	user1, err := repo.ListUserEvents(ctx, 1, "", 10)
+	require.NoError(t, err)
+	require.Len(t, user1.Events, 1)
+	user2, err := repo.ListUserEvents(ctx, 2, "", 10)
+	require.NoError(t, err)
+	require.Empty(t, user2.Events)
+	all, err = repo.ListEvents(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, all.Events, 1)
+}
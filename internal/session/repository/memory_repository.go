@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+)
+
+type memorySession struct {
+	session   models.Session
+	expiresAt time.Time
+}
+
+type memoryTombstone struct {
+	evicted   bool
+	expiresAt time.Time
+}
+
+// Session repository kept in process memory, stand-in for redis in usecase tests. Keys, expiry and
+// tombstones behave like the redis store, sessions are copied in and out.
+type sessionMemoryRepo struct {
+	cfg        *config.Config
+	mu         sync.Mutex
+	sessions   map[string]memorySession
+	tombstones map[string]memoryTombstone
+	userIndex  map[int]map[string]struct{}
+}
+
+// Session in-memory repository constructor
+func NewSessionMemoryRepository(cfg *config.Config) session.SessRepository {
+	return &sessionMemoryRepo{
+		cfg:        cfg,
+		sessions:   make(map[string]memorySession),
+		tombstones: make(map[string]memoryTombstone),
+		userIndex:  make(map[int]map[string]struct{}),
+	}
+}
+
+// Create session
+func (s *sessionMemoryRepo) CreateSession(ctx context.Context, sess *models.Session, expire int) (string, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.CreateSession")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess.SessionID = uuid.New().String()
+	sessionKey := fmt.Sprintf("%s: %s", basePrefix, sess.SessionID)
+	s.sessions[sessionKey] = memorySession{session: *cloneSession(sess), expiresAt: time.Now().Add(time.Second * time.Duration(expire))}
+	if !sess.Guest {
+		if s.userIndex[sess.UserID] == nil {
+			s.userIndex[sess.UserID] = make(map[string]struct{})
+		}
+		s.userIndex[sess.UserID][sessionKey] = struct{}{}
+	}
+	return sessionKey, nil
+}
+
+// Get session by id, a missing session is reported as revoked, evicted or not found
+func (s *sessionMemoryRepo) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.GetSessionByID")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.live(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return cloneSession(&stored.session), nil
+}
+
+// Delete session by id
+func (s *sessionMemoryRepo) DeleteByID(ctx context.Context, sessionID string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.DeleteByID")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// Overwrite session payload keeping its expiry
+func (s *sessionMemoryRepo) UpdateSession(ctx context.Context, sessionID string, sess *models.Session) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.UpdateSession")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.live(sessionID)
+	if err != nil {
+		return err
+	}
+	stored.session = *cloneSession(sess)
+	s.sessions[sessionID] = stored
+	return nil
+}
+
+// Revoke sessions matching criteria
+func (s *sessionMemoryRepo) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.RevokeSessions")
+	defer span.Finish()
+
+	var ipNet *net.IPNet
+	if criteria.IPRange != "" {
+		_, parsed, err := net.ParseCIDR(criteria.IPRange)
+		if err != nil {
+			return nil, errors.Wrap(err, "sessionMemoryRepo.RevokeSessions.net.ParseCIDR")
+		}
+		ipNet = parsed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userIDs := criteria.UserIDs
+	if len(userIDs) == 0 {
+		for userID := range s.userIndex {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	result := &models.SessionRevokeResult{DryRun: criteria.DryRun}
+	for _, userID := range userIDs {
+		for _, sessionKey := range s.indexed(userID) {
+			sess := s.sessions[sessionKey].session
+			if !matchesCriteria(&sess, criteria, ipNet) {
+				continue
+			}
+			result.Matched++
+			if criteria.DryRun {
+				continue
+			}
+			s.end(userID, sessionKey, false)
+			result.Revoked++
+			result.Sessions = append(result.Sessions, cloneSession(&sess))
+		}
+	}
+	return result, nil
+}
+
+// Live sessions of a user, oldest first
+func (s *sessionMemoryRepo) ListUserSessions(ctx context.Context, userID int) ([]*models.Session, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.ListUserSessions")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionKeys := s.indexed(userID)
+	if len(sessionKeys) == 0 {
+		return nil, nil
+	}
+	sessions := make([]*models.Session, 0, len(sessionKeys))
+	for _, sessionKey := range sessionKeys {
+		stored := s.sessions[sessionKey].session
+		sessions = append(sessions, cloneSession(&stored))
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	return sessions, nil
+}
+
+// End sessions of a user to make room for a newer one, lookups of them report eviction
+func (s *sessionMemoryRepo) EvictSessions(ctx context.Context, userID int, sessionIDs []string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.EvictSessions")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sessionID := range sessionIDs {
+		s.end(userID, fmt.Sprintf("%s: %s", basePrefix, sessionID), true)
+	}
+	return nil
+}
+
+// Drop members of expired sessions from every user index, a dry run only counts them
+func (s *sessionMemoryRepo) CompactUserIndexes(ctx context.Context, dryRun bool) (int, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionMemoryRepo.CompactUserIndexes")
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stale := 0
+	for userID, sessionKeys := range s.userIndex {
+		for sessionKey := range sessionKeys {
+			if _, err := s.live(sessionKey); err == nil {
+				continue
+			}
+			stale++
+			if !dryRun {
+				delete(sessionKeys, sessionKey)
+			}
+		}
+		if len(sessionKeys) == 0 {
+			delete(s.userIndex, userID)
+		}
+	}
+	return stale, nil
+}
+
+// Stored session unless it expired, otherwise the error its tombstone tells
+func (s *sessionMemoryRepo) live(sessionKey string) (memorySession, error) {
+	now := time.Now()
+	if stored, ok := s.sessions[sessionKey]; ok {
+		if now.Before(stored.expiresAt) {
+			return stored, nil
+		}
+		delete(s.sessions, sessionKey)
+	}
+	tombstone, ok := s.tombstones[sessionKey]
+	switch {
+	case !ok:
+		return memorySession{}, session.ErrNotFound
+	case !now.Before(tombstone.expiresAt):
+		delete(s.tombstones, sessionKey)
+		return memorySession{}, session.ErrNotFound
+	case tombstone.evicted:
+		return memorySession{}, session.ErrEvicted
+	default:
+		return memorySession{}, session.ErrRevoked
+	}
+}
+
+// Live session keys of a user index, expired members are pruned
+func (s *sessionMemoryRepo) indexed(userID int) []string {
+	sessionKeys := make([]string, 0, len(s.userIndex[userID]))
+	for sessionKey := range s.userIndex[userID] {
+		if _, err := s.live(sessionKey); err != nil {
+			delete(s.userIndex[userID], sessionKey)
+			continue
+		}
+		sessionKeys = append(sessionKeys, sessionKey)
+	}
+	if len(s.userIndex[userID]) == 0 {
+		delete(s.userIndex, userID)
+	}
+	sort.Strings(sessionKeys)
+	return sessionKeys
+}
+
+// Delete session leaving a tombstone for the longest session lifetime
+func (s *sessionMemoryRepo) end(userID int, sessionKey string, evicted bool) {
+	delete(s.sessions, sessionKey)
+	delete(s.userIndex[userID], sessionKey)
+	s.tombstones[sessionKey] = memoryTombstone{
+		evicted:   evicted,
+		expiresAt: time.Now().Add(time.Second * time.Duration(s.cfg.Session.Expire)),
+	}
+}
+
+func cloneSession(sess *models.Session) *models.Session {
+	cloned := *sess
+	if sess.Data != nil {
+		cloned.Data = make(map[string]json.RawMessage, len(sess.Data))
+		for key, value := range sess.Data {
+			cloned.Data[key] = append(json.RawMessage(nil), value...)
+		}
+	}
+	return &cloned
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -14,28 +15,107 @@ import (
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
 )
 
 const (
-	basePrefix = "api-session:"
+	basePrefix      = "api-session:"
+	userIndexPrefix = "api-session-user:"
+	revokedPrefix   = "api-session-revoked:"
+	scanCount       = 100
+	revokeBatchSize = 500
+	// Tombstone value telling an evicted session from a revoked one
+	evictedTombstone = "evicted"
 )
 
+// Session lookups resolve the tombstone of a missing session in the same round trip.
+// Returns {1, session}, {0, tombstone} or {0}.
+var getSessionScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	return {1, value}
+end
+local tombstone = redis.call('GET', KEYS[2])
+if tombstone then
+	return {0, tombstone}
+end
+return {0}
+`)
+
+// Overwrite keeping the expiry, a missing session returns its tombstone like getSessionScript
+var updateSessionScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'XX', 'KEEPTTL') then
+	return {1}
+end
+local tombstone = redis.call('GET', KEYS[2])
+if tombstone then
+	return {0, tombstone}
+end
+return {0}
+`)
+
+// Live sessions of a user index, expired members are removed in the same call. Session keys are
+// read from the index, so the store must not be a cluster. ARGV[1] is the MGET batch size.
+var listUserSessionsScript = redis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+local batch = tonumber(ARGV[1])
+local live = {}
+local stale = {}
+for start = 1, #keys, batch do
+	local chunk = {unpack(keys, start, math.min(start + batch - 1, #keys))}
+	local values = redis.call('MGET', unpack(chunk))
+	for i, key in ipairs(chunk) do
+		if values[i] then
+			table.insert(live, values[i])
+		else
+			table.insert(stale, key)
+		end
+	end
+end
+for start = 1, #stale, batch do
+	redis.call('SREM', KEYS[1], unpack(stale, start, math.min(start + batch - 1, #stale)))
+end
+return live
+`)
+
+// Members of a user index whose session key is gone, removed unless ARGV[1] is 1 for a dry run.
+// ARGV[2] is the SREM batch size. Returns their number
+var compactUserIndexScript = redis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+local stale = {}
+for _, key in ipairs(keys) do
+	if redis.call('EXISTS', key) == 0 then
+		table.insert(stale, key)
+	end
+end
+if ARGV[1] ~= '1' then
+	local batch = tonumber(ARGV[2])
+	for start = 1, #stale, batch do
+		redis.call('SREM', KEYS[1], unpack(stale, start, math.min(start + batch - 1, #stale)))
+	end
+end
+return #stale
+`)
+
 // Session repository
 type sessionRepo struct {
 	redisClient *redis.Client
 	basePrefix  string
 	cfg         *config.Config
+	metrics     metric.Metrics
 }
 
-// Session repository constructor
-func NewSessionRepository(redisClient *redis.Client, cfg *config.Config) session.SessRepository {
-	return &sessionRepo{redisClient: redisClient, basePrefix: basePrefix, cfg: cfg}
+// Session repository constructor, metrics may be nil. Every operation takes a single round trip
+// to redis, multi-key ones are pipelined or run as scripts.
+func NewSessionRepository(redisClient *redis.Client, cfg *config.Config, metrics metric.Metrics) session.SessRepository {
+	return &sessionRepo{redisClient: redisClient, basePrefix: basePrefix, cfg: cfg, metrics: metrics}
 }
 
 // Create session in redis
 func (s *sessionRepo) CreateSession(ctx context.Context, sess *models.Session, expire int) (string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.CreateSession")
 	defer span.Finish()
+	defer s.observe("create", time.Now())
 
 	sess.SessionID = uuid.New().String()
 	sessionKey := s.createKey(sess.SessionID)
@@ -44,24 +124,38 @@ func (s *sessionRepo) CreateSession(ctx context.Context, sess *models.Session, e
 	if err != nil {
 		return "", errors.WithMessage(err, "sessionRepo.CreateSession.json.Marshal")
 	}
-	if err = s.redisClient.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire)).Err(); err != nil {
-		return "", errors.Wrap(err, "sessionRepo.CreateSession.redisClient.Set")
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire))
+	// Per user index of session keys, kept alive as long as the newest session, guests have no user
+	if !sess.Guest {
+		indexKey := s.userIndexKey(sess.UserID)
+		pipe.SAdd(ctx, indexKey, sessionKey)
+		pipe.Expire(ctx, indexKey, time.Second*time.Duration(expire))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return "", errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.CreateSession.pipe.Exec: %v", err)
 	}
 	return sessionKey, nil
 }
 
-// Get session by id
+// Get session by id, a missing session is reported as revoked, evicted or not found
 func (s *sessionRepo) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.GetSessionByID")
 	defer span.Finish()
+	defer s.observe("get", time.Now())
 
-	sessBytes, err := s.redisClient.Get(ctx, sessionID).Bytes()
+	reply, err := getSessionScript.Run(ctx, s.redisClient, []string{sessionID, revokedPrefix + sessionID}).Slice()
 	if err != nil {
-		return nil, errors.Wrap(err, "sessionRep.GetSessionByID.redisClient.Get")
+		return nil, errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.GetSessionByID.getSessionScript: %v", err)
+	}
+	raw, err := scriptValue(reply)
+	if err != nil {
+		return nil, err
 	}
 
 	sess := &models.Session{}
-	if err = json.Unmarshal(sessBytes, &sess); err != nil {
+	if err = json.Unmarshal([]byte(raw), &sess); err != nil {
 		return nil, errors.Wrap(err, "sessionRepo.GetSessionByID.json.Unmarshal")
 	}
 	return sess, nil
@@ -71,13 +165,276 @@ func (s *sessionRepo) GetSessionByID(ctx context.Context, sessionID string) (*mo
 func (s *sessionRepo) DeleteByID(ctx context.Context, sessionID string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteByID")
 	defer span.Finish()
+	defer s.observe("delete", time.Now())
 
 	if err := s.redisClient.Del(ctx, sessionID).Err(); err != nil {
-		return errors.Wrap(err, "sessionRepo.DeleteByID")
+		return errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.DeleteByID: %v", err)
+	}
+	return nil
+}
+
+// Value of a session script reply, or the error telling a revoked session from one that never existed or timed out
+func scriptValue(reply []interface{}) (string, error) {
+	if len(reply) == 0 {
+		return "", errors.New("sessionRepo.scriptValue: empty reply")
+	}
+	found, _ := reply[0].(int64)
+	value := ""
+	if len(reply) > 1 {
+		value, _ = reply[1].(string)
+	}
+
+	switch {
+	case found == 1:
+		return value, nil
+	case len(reply) == 1:
+		return "", session.ErrNotFound
+	case value == evictedTombstone:
+		return "", session.ErrEvicted
+	default:
+		return "", session.ErrRevoked
+	}
+}
+
+// Live sessions of a user, expired members are pruned from the index
+func (s *sessionRepo) ListUserSessions(ctx context.Context, userID int) ([]*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.ListUserSessions")
+	defer span.Finish()
+	defer s.observe("list_user", time.Now())
+
+	values, err := listUserSessionsScript.Run(ctx, s.redisClient, []string{s.userIndexKey(userID)}, revokeBatchSize).Slice()
+	if err != nil {
+		return nil, errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.ListUserSessions.listUserSessionsScript: %v", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	sessions := make([]*models.Session, 0, len(values))
+	for _, value := range values {
+		raw, _ := value.(string)
+		sess := &models.Session{}
+		if err := json.Unmarshal([]byte(raw), sess); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.ListUserSessions.json.Unmarshal")
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+// End sessions of a user to make room for a newer one, lookups of them report eviction
+func (s *sessionRepo) EvictSessions(ctx context.Context, userID int, sessionIDs []string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.EvictSessions")
+	defer span.Finish()
+	defer s.observe("evict", time.Now())
+
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	sessionKeys := make([]string, 0, len(sessionIDs))
+	members := make([]interface{}, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		sessionKey := s.createKey(sessionID)
+		sessionKeys = append(sessionKeys, sessionKey)
+		members = append(members, sessionKey)
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, sessionKeys...)
+	for _, sessionKey := range sessionKeys {
+		pipe.Set(ctx, revokedPrefix+sessionKey, evictedTombstone, time.Second*time.Duration(s.cfg.Session.Expire))
+	}
+	pipe.SRem(ctx, s.userIndexKey(userID), members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.EvictSessions.pipe.Exec: %v", err)
+	}
+	return nil
+}
+
+// Overwrite session payload keeping its expiry
+func (s *sessionRepo) UpdateSession(ctx context.Context, sessionID string, sess *models.Session) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.UpdateSession")
+	defer span.Finish()
+	defer s.observe("update", time.Now())
+
+	sessBytes, err := json.Marshal(sess)
+	if err != nil {
+		return errors.WithMessage(err, "sessionRepo.UpdateSession.json.Marshal")
+	}
+	reply, err := updateSessionScript.Run(ctx, s.redisClient, []string{sessionID, revokedPrefix + sessionID}, sessBytes).Slice()
+	if err != nil {
+		return errors.Wrapf(session.ErrStoreUnavailable, "sessionRepo.UpdateSession.updateSessionScript: %v", err)
+	}
+	_, err = scriptValue(reply)
+	return err
+}
+
+// Revoke sessions matching criteria, walks the per user index instead of the whole keyspace
+func (s *sessionRepo) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.RevokeSessions")
+	defer span.Finish()
+
+	var ipNet *net.IPNet
+	if criteria.IPRange != "" {
+		_, parsed, err := net.ParseCIDR(criteria.IPRange)
+		if err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.RevokeSessions.net.ParseCIDR")
+		}
+		ipNet = parsed
+	}
+
+	result := &models.SessionRevokeResult{DryRun: criteria.DryRun}
+
+	if len(criteria.UserIDs) > 0 {
+		for _, userID := range criteria.UserIDs {
+			if err := s.revokeFromIndex(ctx, s.userIndexKey(userID), criteria, ipNet, result); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	iter := s.redisClient.Scan(ctx, 0, userIndexPrefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		if err := s.revokeFromIndex(ctx, iter.Val(), criteria, ipNet, result); err != nil {
+			return nil, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.RevokeSessions.Scan")
+	}
+
+	return result, nil
+}
+
+// Match and delete sessions of one user index in batches, expired members are pruned from the index
+func (s *sessionRepo) revokeFromIndex(
+	ctx context.Context,
+	indexKey string,
+	criteria *models.SessionRevokeCriteria,
+	ipNet *net.IPNet,
+	result *models.SessionRevokeResult,
+) error {
+	sessionKeys, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.revokeFromIndex.SMembers")
+	}
+
+	for start := 0; start < len(sessionKeys); start += revokeBatchSize {
+		end := start + revokeBatchSize
+		if end > len(sessionKeys) {
+			end = len(sessionKeys)
+		}
+		batch := sessionKeys[start:end]
+
+		values, err := s.redisClient.MGet(ctx, batch...).Result()
+		if err != nil {
+			return errors.Wrap(err, "sessionRepo.revokeFromIndex.MGet")
+		}
+
+		revoke := make([]string, 0, len(batch))
+		revoked := make([]*models.Session, 0, len(batch))
+		stale := make([]interface{}, 0)
+		for i, value := range values {
+			raw, ok := value.(string)
+			if !ok {
+				stale = append(stale, batch[i])
+				continue
+			}
+
+			sess := &models.Session{}
+			if err := json.Unmarshal([]byte(raw), sess); err != nil {
+				return errors.Wrap(err, "sessionRepo.revokeFromIndex.json.Unmarshal")
+			}
+			if matchesCriteria(sess, criteria, ipNet) {
+				revoke = append(revoke, batch[i])
+				revoked = append(revoked, sess)
+			}
+		}
+
+		result.Matched += len(revoke)
+		if criteria.DryRun || (len(revoke) == 0 && len(stale) == 0) {
+			continue
+		}
+
+		// Revoked keys leave a tombstone for the longest session lifetime so lookups can report revocation
+		pipe := s.redisClient.TxPipeline()
+		if len(revoke) > 0 {
+			pipe.Del(ctx, revoke...)
+			for _, key := range revoke {
+				pipe.Set(ctx, revokedPrefix+key, 1, time.Second*time.Duration(s.cfg.Session.Expire))
+				stale = append(stale, key)
+			}
+		}
+		pipe.SRem(ctx, indexKey, stale...)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return errors.Wrap(err, "sessionRepo.revokeFromIndex.pipe.Exec")
+		}
+		result.Revoked += len(revoke)
+		result.Sessions = append(result.Sessions, revoked...)
 	}
+
 	return nil
 }
 
+// Walk every user index and drop members of expired sessions, users who never list their sessions keep
+// them until the index itself expires
+func (s *sessionRepo) CompactUserIndexes(ctx context.Context, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.CompactUserIndexes")
+	defer span.Finish()
+
+	flag := 0
+	if dryRun {
+		flag = 1
+	}
+
+	stale := 0
+	iter := s.redisClient.Scan(ctx, 0, userIndexPrefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		count, err := compactUserIndexScript.Run(ctx, s.redisClient, []string{iter.Val()}, flag, revokeBatchSize).Int()
+		if err != nil {
+			return stale, errors.Wrap(err, "sessionRepo.CompactUserIndexes.compactUserIndexScript")
+		}
+		stale += count
+	}
+	if err := iter.Err(); err != nil {
+		return stale, errors.Wrap(err, "sessionRepo.CompactUserIndexes.Scan")
+	}
+	return stale, nil
+}
+
+func matchesCriteria(sess *models.Session, criteria *models.SessionRevokeCriteria, ipNet *net.IPNet) bool {
+	if ipNet != nil {
+		ip := net.ParseIP(sess.IPAddress)
+		if ip == nil || !ipNet.Contains(ip) {
+			return false
+		}
+	}
+	if criteria.CreatedBefore != nil && (sess.CreatedAt.IsZero() || !sess.CreatedAt.Before(*criteria.CreatedBefore)) {
+		return false
+	}
+	if criteria.TenantID != "" && sess.TenantID != criteria.TenantID {
+		return false
+	}
+	return true
+}
+
+// Record latency of a store operation, the histogram shows the effect of round trip changes across releases
+func (s *sessionRepo) observe(op string, start time.Time) {
+	if s.metrics != nil {
+		s.metrics.ObserveSessionStore(op, time.Since(start).Seconds())
+	}
+}
+
+func (s *sessionRepo) userIndexKey(userID int) string {
+	return fmt.Sprintf("%s%d", userIndexPrefix, userID)
+}
+
 func (s *sessionRepo) createKey(sessionID string) string {
-	return fmt.Sprintf("%s: %s", s.basePrefix, sessionID)
+	return sessionKeyOf(s.basePrefix, sessionID)
+}
+
+// Redis key of a session id
+func sessionKeyOf(prefix string, sessionID string) string {
+	return fmt.Sprintf("%s: %s", prefix, sessionID)
 }
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+)
+
+// Simulated network latency per round trip in benchmarks, miniredis answers in microseconds
+// so without it pipelining would barely show in ns/op
+const benchRoundTrip = 200 * time.Microsecond
+
+// Counts round trips to redis, a pipeline or transaction is one
+type roundTrips struct {
+	count int64
+	delay time.Duration
+}
+
+func (r *roundTrips) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	r.trip()
+	return ctx, nil
+}
+
+func (r *roundTrips) AfterProcess(context.Context, redis.Cmder) error {
+	return nil
+}
+
+func (r *roundTrips) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	r.trip()
+	return ctx, nil
+}
+
+func (r *roundTrips) AfterProcessPipeline(context.Context, []redis.Cmder) error {
+	return nil
+}
+
+func (r *roundTrips) trip() {
+	atomic.AddInt64(&r.count, 1)
+	if r.delay > 0 {
+		time.Sleep(r.delay)
+	}
+}
+
+func newTestSessionRepo(tb testing.TB, delay time.Duration) (*sessionRepo, *roundTrips) {
+	server := miniredis.NewMiniRedis()
+	require.NoError(tb, server.Start())
+	tb.Cleanup(server.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	tb.Cleanup(func() { client.Close() })
+	// Loaded up front, the first Run of a script otherwise falls back from EVALSHA to EVAL
+	for _, script := range []*redis.Script{getSessionScript, updateSessionScript, listUserSessionsScript, compactUserIndexScript} {
+		require.NoError(tb, script.Load(context.Background(), client).Err())
+	}
+	trips := &roundTrips{delay: delay}
+	client.AddHook(trips)
+
+	cfg := &config.Config{Session: config.Session{Expire: 3600}}
+	return NewSessionRepository(client, cfg, nil).(*sessionRepo), trips
+}
+
+func TestSessionRepo_GetSessionByID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(t, 0)
+
+	key, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60)
+	require.NoError(t, err)
+
+	atomic.StoreInt64(&trips.count, 0)
+	sess, err := repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 1, sess.UserID)
+	require.Equal(t, int64(1), atomic.LoadInt64(&trips.count))
+
+	_, err = repo.GetSessionByID(ctx, "missing")
+	require.ErrorIs(t, err, session.ErrNotFound)
+
+	result, err := repo.RevokeSessions(ctx, &models.SessionRevokeCriteria{UserIDs: []int{1}})
+	require.NoError(t, err)
+	require.Len(t, result.Sessions, 1)
+	require.Equal(t, 1, result.Sessions[0].UserID)
+	_, err = repo.GetSessionByID(ctx, key)
+	require.ErrorIs(t, err, session.ErrRevoked)
+}
+
+func TestSessionRepo_UpdateSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(t, 0)
+
+	key, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60)
+	require.NoError(t, err)
+
+	atomic.StoreInt64(&trips.count, 0)
+	require.NoError(t, repo.UpdateSession(ctx, key, &models.Session{UserID: 1, TenantID: "acme"}))
+	require.Equal(t, int64(1), atomic.LoadInt64(&trips.count))
+
+	sess, err := repo.GetSessionByID(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "acme", sess.TenantID)
+	ttl, err := repo.redisClient.TTL(ctx, key).Result()
+	require.NoError(t, err)
+	require.Greater(t, ttl, time.Duration(0))
+
+	require.ErrorIs(t, repo.UpdateSession(ctx, "missing", &models.Session{}), session.ErrNotFound)
+}
+
+func TestSessionRepo_ListAndEvict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(t, 0)
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		_, err := repo.CreateSession(ctx, &models.Session{UserID: 7}, 60)
+		require.NoError(t, err)
+	}
+	sessions, err := repo.ListUserSessions(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	for _, sess := range sessions {
+		ids = append(ids, sess.SessionID)
+	}
+
+	// Expired member is pruned from the index while listing
+	require.NoError(t, repo.redisClient.Del(ctx, repo.createKey(ids[2])).Err())
+	atomic.StoreInt64(&trips.count, 0)
+	sessions, err = repo.ListUserSessions(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, int64(1), atomic.LoadInt64(&trips.count))
+	members, err := repo.redisClient.SCard(ctx, repo.userIndexKey(7)).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), members)
+
+	atomic.StoreInt64(&trips.count, 0)
+	require.NoError(t, repo.EvictSessions(ctx, 7, ids[:2]))
+	require.Equal(t, int64(1), atomic.LoadInt64(&trips.count))
+
+	_, err = repo.GetSessionByID(ctx, repo.createKey(ids[0]))
+	require.ErrorIs(t, err, session.ErrEvicted)
+	sessions, err = repo.ListUserSessions(ctx, 7)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestSessionRepo_CompactUserIndexes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo, _ := newTestSessionRepo(t, 0)
+
+	keys := make([]string, 0, 4)
+	for _, userID := range []int{1, 1, 2, 3} {
+		key, err := repo.CreateSession(ctx, &models.Session{UserID: userID}, 60)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	// Sessions expired without the index being listed
+	require.NoError(t, repo.redisClient.Del(ctx, keys[0], keys[2]).Err())
+
+	stale, err := repo.CompactUserIndexes(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, stale)
+	members, err := repo.redisClient.SCard(ctx, repo.userIndexKey(2)).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), members)
+
+	stale, err = repo.CompactUserIndexes(ctx, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, stale)
+	require.Equal(t, []string{keys[1]}, repo.redisClient.SMembers(ctx, repo.userIndexKey(1)).Val())
+	// An index left empty is gone
+	require.Zero(t, repo.redisClient.Exists(ctx, repo.userIndexKey(2)).Val())
+	require.Equal(t, int64(1), repo.redisClient.SCard(ctx, repo.userIndexKey(3)).Val())
+
+	stale, err = repo.CompactUserIndexes(ctx, false)
+	require.NoError(t, err)
+	require.Zero(t, stale)
+}
+
+// Benchmarks compare each operation with the command sequence it replaced, run with
+// go test -bench SessionRepo -run ^$ ./internal/session/repository
+
+func BenchmarkSessionRepo_GetSessionByID(b *testing.B) {
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(b, benchRoundTrip)
+	key, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60)
+	require.NoError(b, err)
+	_, err = repo.RevokeSessions(ctx, &models.SessionRevokeCriteria{UserIDs: []int{1}})
+	require.NoError(b, err)
+
+	// Revoked session is the worst case, the lookup falls through to the tombstone
+	b.Run("sequential", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if err := repo.redisClient.Get(ctx, key).Err(); err == redis.Nil {
+				repo.redisClient.Get(ctx, revokedPrefix+key)
+			}
+		})
+	})
+	b.Run("script", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if _, err := repo.GetSessionByID(ctx, key); err != session.ErrRevoked {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+func BenchmarkSessionRepo_UpdateSession(b *testing.B) {
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(b, benchRoundTrip)
+	sess := &models.Session{UserID: 1}
+	sessBytes, err := json.Marshal(sess)
+	require.NoError(b, err)
+
+	b.Run("sequential", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if !repo.redisClient.SetXX(ctx, "missing", sessBytes, redis.KeepTTL).Val() {
+				repo.redisClient.Get(ctx, revokedPrefix+"missing")
+			}
+		})
+	})
+	b.Run("script", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if err := repo.UpdateSession(ctx, "missing", sess); err != session.ErrNotFound {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+func BenchmarkSessionRepo_ListUserSessions(b *testing.B) {
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(b, benchRoundTrip)
+	for i := 0; i < 5; i++ {
+		_, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60)
+		require.NoError(b, err)
+	}
+	indexKey := repo.userIndexKey(1)
+	staleKey := repo.createKey("stale")
+
+	// An expired member is added back before each call so the prune step runs every time
+	b.Run("sequential", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			repo.redisClient.SAdd(ctx, indexKey, staleKey)
+			atomic.AddInt64(&trips.count, -1)
+			keys := repo.redisClient.SMembers(ctx, indexKey).Val()
+			repo.redisClient.MGet(ctx, keys...)
+			repo.redisClient.SRem(ctx, indexKey, staleKey)
+		})
+	})
+	b.Run("script", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			repo.redisClient.SAdd(ctx, indexKey, staleKey)
+			atomic.AddInt64(&trips.count, -1)
+			if _, err := repo.ListUserSessions(ctx, 1); err != nil {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+func BenchmarkSessionRepo_EvictSessions(b *testing.B) {
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(b, benchRoundTrip)
+	ids := []string{"a", "b", "c"}
+
+	b.Run("sequential", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			for _, id := range ids {
+				if err := repo.EvictSessions(ctx, 1, []string{id}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+	b.Run("pipeline", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if err := repo.EvictSessions(ctx, 1, ids); err != nil {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+func BenchmarkSessionRepo_CreateSession(b *testing.B) {
+	ctx := context.Background()
+	repo, trips := newTestSessionRepo(b, benchRoundTrip)
+
+	b.Run("pipeline", func(b *testing.B) {
+		benchTrips(b, trips, func() {
+			if _, err := repo.CreateSession(ctx, &models.Session{UserID: 1}, 60); err != nil {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+// Run op b.N times and report its round trips next to ns/op
+func benchTrips(b *testing.B, trips *roundTrips, op func()) {
+	atomic.StoreInt64(&trips.count, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadInt64(&trips.count))/float64(b.N), "roundtrips/op")
+}
@@ -1,8 +1,10 @@
 //go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UCSession usecase.go
 package session
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 )
@@ -12,4 +14,16 @@ type UCSession interface {
 	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
 	GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error)
 	DeleteByID(ctx context.Context, sessionID string) error
+	Reauthenticate(ctx context.Context, sessionID string) (*models.Session, error)
+	CheckStepUp(ctx context.Context, session *models.Session) error
+	RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error)
+	CheckHijack(ctx context.Context, session *models.Session, ipAddress string, userAgent string) error
+	ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error)
+	ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error)
+	GetSessionData(ctx context.Context, sessionID string, key string) (json.RawMessage, error)
+	SetSessionData(ctx context.Context, sessionID string, key string, value json.RawMessage) error
+	// observe:nodeadline, walks every user index
+	CompactIndexes(ctx context.Context, dryRun bool) (int, error)
+	// observe:nodeadline, walks every event stream
+	PruneLoginHistory(ctx context.Context, dryRun bool) (int, error)
 }
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+const (
+	defaultMaxDataKeys  = 16
+	defaultMaxDataBytes = 4096
+	maxDataKeyLength    = 64
+)
+
+// Raw value of a session data key, nil when it is not set
+func (u *sessionUC) GetSessionData(ctx context.Context, sessionID string, key string) (json.RawMessage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionData")
+	defer span.Finish()
+
+	sess, err := u.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return sess.Data[key], nil
+}
+
+// Set a session data key, a nil or null value removes it. Concurrent writes to the same session are
+// last writer wins, the whole data map is written back
+func (u *sessionUC) SetSessionData(ctx context.Context, sessionID string, key string, value json.RawMessage) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.SetSessionData")
+	defer span.Finish()
+
+	if key == "" || len(key) > maxDataKeyLength {
+		return httpErrors.NewBadRequestError(fmt.Sprintf("session data key must be 1 to %d bytes", maxDataKeyLength))
+	}
+	if value != nil {
+		compact := &bytes.Buffer{}
+		if err := json.Compact(compact, value); err != nil {
+			return httpErrors.NewBadRequestError("session data value must be valid json")
+		}
+		value = compact.Bytes()
+	}
+
+	sess, err := u.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if value == nil || string(value) == "null" {
+		if _, ok := sess.Data[key]; !ok {
+			return nil
+		}
+		delete(sess.Data, key)
+	} else {
+		if sess.Data == nil {
+			sess.Data = make(map[string]json.RawMessage, 1)
+		}
+		sess.Data[key] = value
+		if err := u.checkDataSize(sess.Data); err != nil {
+			return u.countError(err)
+		}
+	}
+
+	return u.countError(u.sessionRepo.UpdateSession(ctx, sessionID, sess))
+}
+
+func (u *sessionUC) checkDataSize(data map[string]json.RawMessage) error {
+	maxKeys, maxBytes := u.cfg.Session.MaxDataKeys, u.cfg.Session.MaxDataBytes
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxDataKeys
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxDataBytes
+	}
+	if len(data) > maxKeys {
+		return session.ErrDataTooLarge
+	}
+
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	if size > maxBytes {
+		return session.ErrDataTooLarge
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+func TestSessionUC_SessionData(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	cfg := &config.Config{Session: config.Session{MaxDataKeys: 2, MaxDataBytes: 128}}
+	sessUC := NewSessionUseCase(mockSessRepo, nil, cfg, clock.NewFrozen(time.Now()), nil, nil)
+
+	ctx := context.Background()
+	sid := "session id"
+	stored := &models.Session{UserID: 1}
+	mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), sid).DoAndReturn(func(context.Context, string) (*models.Session, error) {
+		copied := *stored
+		copied.Data = make(map[string]json.RawMessage, len(stored.Data))
+		for key, value := range stored.Data {
+			copied.Data[key] = value
+		}
+		return &copied, nil
+	}).AnyTimes()
+	mockSessRepo.EXPECT().UpdateSession(gomock.Any(), sid, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, sess *models.Session) error {
+		stored = sess
+		return nil
+	}).AnyTimes()
+
+	impersonation := models.SessionImpersonation{ImpersonatorID: 7, Reason: "support ticket"}
+	require.NoError(t, session.DataImpersonation.Set(ctx, sessUC, sid, impersonation))
+	require.NoError(t, session.DataLocale.Set(ctx, sessUC, sid, "de-DE"))
+
+	got, ok, err := session.DataImpersonation.Get(ctx, sessUC, sid)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, impersonation, got)
+	locale, ok, err := session.DataLocale.From(stored)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "de-DE", locale)
+
+	// Key and byte caps
+	err = sessUC.SetSessionData(ctx, sid, "theme", json.RawMessage(`"dark"`))
+	require.ErrorIs(t, err, session.ErrDataTooLarge)
+	err = session.DataLocale.Set(ctx, sessUC, sid, strings.Repeat("x", 128))
+	require.ErrorIs(t, err, session.ErrDataTooLarge)
+	require.Len(t, stored.Data, 2)
+
+	require.Error(t, sessUC.SetSessionData(ctx, sid, "theme", json.RawMessage(`{`)))
+	require.Error(t, sessUC.SetSessionData(ctx, sid, "", json.RawMessage(`1`)))
+
+	require.NoError(t, session.DataImpersonation.Delete(ctx, sessUC, sid))
+	_, ok, err = session.DataImpersonation.Get(ctx, sessUC, sid)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, sessUC.SetSessionData(ctx, sid, "theme", json.RawMessage(` "dark" `)))
+	require.Equal(t, json.RawMessage(`"dark"`), stored.Data["theme"])
+}
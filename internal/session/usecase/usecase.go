@@ -2,31 +2,139 @@ package usecase
 
 import (
 	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
 )
 
+const (
+	defaultStepUpMaxAge = 5 * time.Minute
+
+	defaultEventLimit = 50
+	maxEventLimit     = 500
+
+	auditActionSessionEvicted = "session.evicted"
+)
+
+// Session event stream entry id, the paging cursor of event listings
+var eventCursorPattern = regexp.MustCompile(`^[0-9]+-[0-9]+$`)
+
 // Session use case
 type sessionUC struct {
 	sessionRepo session.SessRepository
+	eventRepo   session.EventRepository
 	cfg         *config.Config
+	clock       clock.Clock
+	metrics     metric.Metrics
+	auditUC     audit.UseCase
 }
 
-// New session use case constructor
-func NewSessionUseCase(sessionRepo session.SessRepository, cfg *config.Config) session.UCSession {
-	return &sessionUC{sessionRepo: sessionRepo, cfg: cfg}
+// New session use case constructor, eventRepo, metrics and auditUC may be nil. Without eventRepo no
+// lifecycle events are recorded
+func NewSessionUseCase(
+	sessionRepo session.SessRepository,
+	eventRepo session.EventRepository,
+	cfg *config.Config,
+	clk clock.Clock,
+	metrics metric.Metrics,
+	auditUC audit.UseCase,
+) session.UCSession {
+	return &sessionUC{sessionRepo: sessionRepo, eventRepo: eventRepo, cfg: cfg, clock: clk, metrics: metrics, auditUC: auditUC}
 }
 
-// Create new session
-func (u *sessionUC) CreateSession(ctx context.Context, session *models.Session, expire int) (string, error) {
+// Create new session, user sessions over the concurrent limit are rejected or evict the oldest ones
+func (u *sessionUC) CreateSession(ctx context.Context, sess *models.Session, expire int) (string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.CreateSession")
 	defer span.Finish()
 
-	return u.sessionRepo.CreateSession(ctx, session, expire)
+	var evicted []*models.Session
+	if !sess.Guest && u.cfg.Session.MaxConcurrent > 0 {
+		var err error
+		if evicted, err = u.enforceLimit(ctx, sess.UserID, u.cfg.Session.MaxConcurrent); err != nil {
+			return "", u.countError(err)
+		}
+	}
+
+	// Sessions belong to the tenant they were started for, the session middleware holds them to it
+	if tenantID, ok := requestctx.Tenant.From(ctx); ok && sess.TenantID == "" {
+		sess.TenantID = tenantID
+	}
+	sess.CreatedAt = u.clock.Now()
+	sess.ExpiresAt = sess.CreatedAt.Add(time.Duration(expire) * time.Second)
+	sess.LastAuthenticatedAt = sess.CreatedAt
+
+	sid, err := u.sessionRepo.CreateSession(ctx, sess, expire)
+	if err != nil {
+		return "", u.countError(err)
+	}
+
+	u.recordEvent(ctx, models.SessionEventCreated, sess, "")
+	for _, old := range evicted {
+		u.recordEviction(ctx, sess, old)
+		u.recordEvent(ctx, models.SessionEventRevoked, old, models.SessionReasonEvicted)
+	}
+	return sid, nil
+}
+
+// Make room for one more session of the user, returns the evicted sessions.
+// Concurrent logins may briefly overshoot the limit, the next login trims it again
+func (u *sessionUC) enforceLimit(ctx context.Context, userID int, limit int) ([]*models.Session, error) {
+	sessions, err := u.sessionRepo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	excess := len(sessions) - limit + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+	if u.cfg.Session.LimitPolicy == session.LimitPolicyReject {
+		return nil, session.ErrLimitReached
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	evicted := sessions[:excess]
+	sessionIDs := make([]string, 0, len(evicted))
+	for _, old := range evicted {
+		sessionIDs = append(sessionIDs, old.SessionID)
+	}
+	if err := u.sessionRepo.EvictSessions(ctx, userID, sessionIDs); err != nil {
+		return nil, err
+	}
+	return evicted, nil
+}
+
+// Audit eviction so the user can see which device was signed out and by which login
+func (u *sessionUC) recordEviction(ctx context.Context, current *models.Session, evicted *models.Session) {
+	if u.auditUC == nil {
+		return
+	}
+	userID := current.UserID
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	// Audit failures must not fail the login, the eviction already happened
+	_ = u.auditUC.Record(ctx, auditActionSessionEvicted, &models.AuditEvent{
+		ActorID:   &userID,
+		IPAddress: current.IPAddress,
+		RequestID: requestID,
+		Resource:  "session:" + evicted.SessionID,
+	}, map[string]interface{}{
+		"evicted_ip_address": evicted.IPAddress,
+		"evicted_user_agent": evicted.UserAgent,
+		"evicted_created_at": evicted.CreatedAt,
+		"user_agent":         current.UserAgent,
+	})
 }
 
 // Delete session by id
@@ -34,7 +142,18 @@ func (u *sessionUC) DeleteByID(ctx context.Context, sessionID string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteByID")
 	defer span.Finish()
 
-	return u.sessionRepo.DeleteByID(ctx, sessionID)
+	// The event needs the owner of the session, a session already gone has nothing left to record
+	var sess *models.Session
+	if u.eventRepo != nil {
+		sess, _ = u.sessionRepo.GetSessionByID(ctx, sessionID)
+	}
+	if err := u.sessionRepo.DeleteByID(ctx, sessionID); err != nil {
+		return u.countError(err)
+	}
+	if sess != nil {
+		u.recordEvent(ctx, models.SessionEventRevoked, sess, models.SessionReasonLogout)
+	}
+	return nil
 }
 
 // get session by id
@@ -42,5 +161,189 @@ func (u *sessionUC) GetSessionByID(ctx context.Context, sessionID string) (*mode
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionByID")
 	defer span.Finish()
 
-	return u.sessionRepo.GetSessionByID(ctx, sessionID)
+	sess, err := u.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, u.countError(err)
+	}
+
+	// Redis expiry is the primary TTL, this guards against clock skew and keys persisted without expiry
+	if !sess.ExpiresAt.IsZero() && !u.clock.Now().Before(sess.ExpiresAt) {
+		u.recordEvent(ctx, models.SessionEventExpired, sess, "")
+		return nil, u.countError(session.ErrExpired)
+	}
+	return sess, nil
+}
+
+// Mark session as freshly authenticated after the user re-entered credentials
+func (u *sessionUC) Reauthenticate(ctx context.Context, sessionID string) (*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.Reauthenticate")
+	defer span.Finish()
+
+	sess, err := u.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.LastAuthenticatedAt = u.clock.Now()
+	if err := u.sessionRepo.UpdateSession(ctx, sessionID, sess); err != nil {
+		return nil, u.countError(err)
+	}
+	u.recordEvent(ctx, models.SessionEventRefreshed, sess, "")
+	return sess, nil
+}
+
+// Reject sessions whose last authentication is older than the step-up window
+func (u *sessionUC) CheckStepUp(ctx context.Context, session *models.Session) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "sessionUC.CheckStepUp")
+	defer span.Finish()
+
+	maxAge := time.Duration(u.cfg.Session.StepUpMaxAge) * time.Second
+	if maxAge <= 0 {
+		maxAge = defaultStepUpMaxAge
+	}
+	if session == nil || session.LastAuthenticatedAt.IsZero() || u.clock.Since(session.LastAuthenticatedAt) > maxAge {
+		return httpErrors.NewRestError(http.StatusUnauthorized, httpErrors.StepUpRequired.Error(), "re-authentication required")
+	}
+	return nil
+}
+
+// Revoke sessions matching criteria, at least one criterion is required
+func (u *sessionUC) RevokeSessions(ctx context.Context, criteria *models.SessionRevokeCriteria) (*models.SessionRevokeResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.RevokeSessions")
+	defer span.Finish()
+
+	if len(criteria.UserIDs) == 0 && criteria.IPRange == "" && criteria.CreatedBefore == nil && criteria.TenantID == "" {
+		return nil, httpErrors.NewBadRequestError("at least one revocation criterion is required")
+	}
+
+	result, err := u.sessionRepo.RevokeSessions(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range result.Sessions {
+		u.recordEvent(ctx, models.SessionEventRevoked, sess, models.SessionReasonAdmin)
+	}
+	return result, nil
+}
+
+// Record a hijack suspicion when a request presents the session from another user agent than the one it was
+// created by, once per session. The request is not refused, consumers of the event stream decide
+func (u *sessionUC) CheckHijack(ctx context.Context, sess *models.Session, ipAddress string, userAgent string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.CheckHijack")
+	defer span.Finish()
+
+	if u.eventRepo == nil || sess.Guest || sess.UserAgent == "" || sess.UserAgent == userAgent {
+		return nil
+	}
+	marked, err := u.eventRepo.MarkHijackSuspected(ctx, sess.SessionID)
+	if err != nil || !marked {
+		return u.countError(err)
+	}
+
+	// The event carries the client presenting the session, the session keeps the one it was created by
+	suspect := *sess
+	suspect.IPAddress = ipAddress
+	suspect.UserAgent = userAgent
+	u.recordEvent(ctx, models.SessionEventHijackSuspected, &suspect, models.SessionReasonUserAgentChanged)
+	return nil
+}
+
+// Lifecycle events of a user newest first, before is the next cursor of the previous page
+func (u *sessionUC) ListUserEvents(ctx context.Context, userID int, before string, limit int) (*models.SessionEvents, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.ListUserEvents")
+	defer span.Finish()
+
+	limit, err := u.eventPage(before, limit)
+	if err != nil {
+		return nil, err
+	}
+	if u.eventRepo == nil {
+		return &models.SessionEvents{Events: []*models.SessionEvent{}}, nil
+	}
+	events, err := u.eventRepo.ListUserEvents(ctx, userID, before, limit)
+	return events, u.countError(err)
+}
+
+// Lifecycle events of all users newest first, before is the next cursor of the previous page
+func (u *sessionUC) ListEvents(ctx context.Context, before string, limit int) (*models.SessionEvents, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.ListEvents")
+	defer span.Finish()
+
+	limit, err := u.eventPage(before, limit)
+	if err != nil {
+		return nil, err
+	}
+	if u.eventRepo == nil {
+		return &models.SessionEvents{Events: []*models.SessionEvent{}}, nil
+	}
+	events, err := u.eventRepo.ListEvents(ctx, before, limit)
+	return events, u.countError(err)
+}
+
+// Drop expired sessions from the per user indexes, returns the number of members removed or, on a dry run,
+// the number which would be
+func (u *sessionUC) CompactIndexes(ctx context.Context, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.CompactIndexes")
+	defer span.Finish()
+
+	return u.sessionRepo.CompactUserIndexes(ctx, dryRun)
+}
+
+// Remove lifecycle events older than the login history retention, nothing without a retention
+func (u *sessionUC) PruneLoginHistory(ctx context.Context, dryRun bool) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.PruneLoginHistory")
+	defer span.Finish()
+
+	days := u.cfg.Compaction.LoginHistoryDays
+	if u.eventRepo == nil || days <= 0 {
+		return 0, nil
+	}
+	return u.eventRepo.TrimEvents(ctx, u.clock.Now().AddDate(0, 0, -days), dryRun)
+}
+
+// Validate event page cursor, returns the limit clamped to the page size bounds
+func (u *sessionUC) eventPage(before string, limit int) (int, error) {
+	if before != "" && !eventCursorPattern.MatchString(before) {
+		return 0, httpErrors.NewBadRequestError("invalid before cursor")
+	}
+	if limit <= 0 {
+		return defaultEventLimit, nil
+	}
+	if limit > maxEventLimit {
+		return maxEventLimit, nil
+	}
+	return limit, nil
+}
+
+// Append lifecycle event of a user session, guest sessions belong to no user and are left out.
+// Failures must not fail the session operation which already happened, they are counted in metrics
+func (u *sessionUC) recordEvent(ctx context.Context, eventType string, sess *models.Session, reason string) {
+	if u.eventRepo == nil || sess.Guest {
+		return
+	}
+	err := u.eventRepo.AppendEvent(ctx, &models.SessionEvent{
+		Type:      eventType,
+		SessionID: sess.SessionID,
+		UserID:    sess.UserID,
+		TenantID:  sess.TenantID,
+		IPAddress: sess.IPAddress,
+		UserAgent: sess.UserAgent,
+		Reason:    reason,
+		CreatedAt: u.clock.Now(),
+	})
+	if err != nil {
+		_ = u.countError(err)
+		return
+	}
+	if u.metrics != nil {
+		u.metrics.IncSessionEvents(eventType)
+	}
+}
+
+// Record typed session errors in metrics, passes err through
+func (u *sessionUC) countError(err error) error {
+	if kind := session.ErrorKind(err); kind != "" && u.metrics != nil {
+		u.metrics.IncSessionErrors(kind)
+	}
+	return err
 }
@@ -2,13 +2,20 @@ package usecase
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/session"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/session/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
 )
 
 func TestSessionUC_CreateSession(t *testing.T) {
@@ -18,7 +25,7 @@ func TestSessionUC_CreateSession(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSessRepo := mock.NewMockSessRepository(ctrl)
-	sessUC := NewSessionUseCase(mockSessRepo, nil)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, &config.Config{}, clock.NewFrozen(time.Now()), nil, nil)
 
 	ctx := context.Background()
 	sess := &models.Session{}
@@ -39,7 +46,7 @@ func TestSessionUC_GetSessionByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSessRepo := mock.NewMockSessRepository(ctrl)
-	sessUC := NewSessionUseCase(mockSessRepo, nil)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, nil, clock.NewFrozen(time.Now()), nil, nil)
 
 	ctx := context.Background()
 	sess := &models.Session{}
@@ -60,7 +67,7 @@ func TestSessionUC_DeleteByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSessRepo := mock.NewMockSessRepository(ctrl)
-	sessUC := NewSessionUseCase(mockSessRepo, nil)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, nil, clock.NewFrozen(time.Now()), nil, nil)
 
 	ctx := context.Background()
 	sid := "session id"
@@ -71,3 +78,236 @@ func TestSessionUC_DeleteByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, err)
 }
+
+func TestSessionUC_RevokeSessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, nil, clock.NewFrozen(time.Now()), nil, nil)
+
+	ctx := context.Background()
+
+	_, err := sessUC.RevokeSessions(ctx, &models.SessionRevokeCriteria{DryRun: true})
+	require.Error(t, err)
+
+	criteria := &models.SessionRevokeCriteria{UserIDs: []int{1, 2}, DryRun: true}
+	mockSessRepo.EXPECT().RevokeSessions(gomock.Any(), gomock.Eq(criteria)).Return(&models.SessionRevokeResult{Matched: 3, DryRun: true}, nil)
+
+	result, err := sessUC.RevokeSessions(ctx, criteria)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Matched)
+	require.Zero(t, result.Revoked)
+}
+
+func TestSessionUC_GetSessionByID_Expired(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFrozen(now)
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, &config.Config{}, clk, nil, nil)
+
+	ctx := context.Background()
+	sess := &models.Session{}
+	mockSessRepo.EXPECT().CreateSession(gomock.Any(), gomock.Eq(sess), 60).Return("session id", nil)
+
+	_, err := sessUC.CreateSession(ctx, sess, 60)
+	require.NoError(t, err)
+	require.Equal(t, now, sess.CreatedAt)
+	require.Equal(t, now.Add(time.Minute), sess.ExpiresAt)
+
+	mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), "session id").Return(sess, nil).Times(2)
+
+	_, err = sessUC.GetSessionByID(ctx, "session id")
+	require.NoError(t, err)
+
+	clk.Advance(time.Minute)
+	_, err = sessUC.GetSessionByID(ctx, "session id")
+	require.ErrorIs(t, err, session.ErrExpired)
+
+	status, _ := httpErrors.ErrorResponse(err)
+	require.Equal(t, session.StatusAuthenticationTimeout, status)
+}
+
+func TestSessionUC_GetSessionByID_TypedErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil, nil, clock.NewFrozen(time.Now()), nil, nil)
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{session.ErrNotFound, http.StatusUnauthorized},
+		{session.ErrRevoked, http.StatusUnauthorized},
+		{errors.Wrap(session.ErrStoreUnavailable, "dial tcp: connection refused"), http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), "session id").Return(nil, tc.err)
+
+		_, err := sessUC.GetSessionByID(context.Background(), "session id")
+		require.ErrorIs(t, err, tc.err)
+
+		status, _ := httpErrors.ErrorResponse(err)
+		require.Equal(t, tc.status, status)
+	}
+}
+
+func TestSessionUC_CheckStepUp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFrozen(now)
+	cfg := &config.Config{Session: config.Session{StepUpMaxAge: 300}}
+	sessUC := NewSessionUseCase(mock.NewMockSessRepository(ctrl), nil, cfg, clk, nil, nil)
+
+	ctx := context.Background()
+	sess := &models.Session{LastAuthenticatedAt: now}
+	require.NoError(t, sessUC.CheckStepUp(ctx, sess))
+
+	clk.Advance(6 * time.Minute)
+	require.Error(t, sessUC.CheckStepUp(ctx, sess))
+	require.Error(t, sessUC.CheckStepUp(ctx, nil))
+}
+
+func TestSessionUC_CreateSession_ConcurrentLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	existing := func() []*models.Session {
+		return []*models.Session{
+			{SessionID: "newer", UserID: 1, CreatedAt: now.Add(-time.Minute)},
+			{SessionID: "oldest", UserID: 1, CreatedAt: now.Add(-time.Hour)},
+		}
+	}
+
+	t.Run("evict oldest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := &config.Config{Session: config.Session{MaxConcurrent: 2, LimitPolicy: session.LimitPolicyEvictOldest}}
+		mockSessRepo := mock.NewMockSessRepository(ctrl)
+		sessUC := NewSessionUseCase(mockSessRepo, nil, cfg, clock.NewFrozen(now), nil, nil)
+
+		sess := &models.Session{UserID: 1}
+		mockSessRepo.EXPECT().ListUserSessions(gomock.Any(), 1).Return(existing(), nil)
+		mockSessRepo.EXPECT().EvictSessions(gomock.Any(), 1, []string{"oldest"}).Return(nil)
+		mockSessRepo.EXPECT().CreateSession(gomock.Any(), sess, 10).Return("sid", nil)
+
+		sid, err := sessUC.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+		require.Equal(t, "sid", sid)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := &config.Config{Session: config.Session{MaxConcurrent: 2, LimitPolicy: session.LimitPolicyReject}}
+		mockSessRepo := mock.NewMockSessRepository(ctrl)
+		sessUC := NewSessionUseCase(mockSessRepo, nil, cfg, clock.NewFrozen(now), nil, nil)
+
+		mockSessRepo.EXPECT().ListUserSessions(gomock.Any(), 1).Return(existing(), nil)
+
+		_, err := sessUC.CreateSession(context.Background(), &models.Session{UserID: 1}, 10)
+		require.ErrorIs(t, err, session.ErrLimitReached)
+	})
+
+	t.Run("guests are not limited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := &config.Config{Session: config.Session{MaxConcurrent: 1, LimitPolicy: session.LimitPolicyReject}}
+		mockSessRepo := mock.NewMockSessRepository(ctrl)
+		sessUC := NewSessionUseCase(mockSessRepo, nil, cfg, clock.NewFrozen(now), nil, nil)
+
+		guest := &models.Session{Guest: true, GuestID: "g"}
+		mockSessRepo.EXPECT().CreateSession(gomock.Any(), guest, 10).Return("sid", nil)
+
+		_, err := sessUC.CreateSession(context.Background(), guest, 10)
+		require.NoError(t, err)
+	})
+}
+
+func TestSessionUC_Events(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{}
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	mockEventRepo := mock.NewMockEventRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, mockEventRepo, cfg, clock.NewFrozen(now), nil, nil)
+	ctx := context.Background()
+
+	var events []*models.SessionEvent
+	mockEventRepo.EXPECT().AppendEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *models.SessionEvent) error {
+		events = append(events, event)
+		return nil
+	}).AnyTimes()
+
+	sess := &models.Session{SessionID: "s1", UserID: 1, IPAddress: "10.0.0.1", UserAgent: "firefox"}
+	mockSessRepo.EXPECT().CreateSession(gomock.Any(), sess, 10).Return("key", nil)
+	_, err := sessUC.CreateSession(ctx, sess, 10)
+	require.NoError(t, err)
+
+	// Guest sessions belong to no user and leave no events
+	guest := &models.Session{Guest: true, GuestID: "g"}
+	mockSessRepo.EXPECT().CreateSession(gomock.Any(), guest, 10).Return("guest key", nil)
+	_, err = sessUC.CreateSession(ctx, guest, 10)
+	require.NoError(t, err)
+
+	// A changed user agent is suspected once per session, the request itself goes on
+	mockEventRepo.EXPECT().MarkHijackSuspected(gomock.Any(), "s1").Return(true, nil)
+	mockEventRepo.EXPECT().MarkHijackSuspected(gomock.Any(), "s1").Return(false, nil)
+	require.NoError(t, sessUC.CheckHijack(ctx, sess, "10.9.9.9", "curl"))
+	require.NoError(t, sessUC.CheckHijack(ctx, sess, "10.9.9.9", "curl"))
+	require.NoError(t, sessUC.CheckHijack(ctx, sess, "10.0.0.2", "firefox"))
+
+	mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), "key").Return(sess, nil)
+	mockSessRepo.EXPECT().DeleteByID(gomock.Any(), "key").Return(nil)
+	require.NoError(t, sessUC.DeleteByID(ctx, "key"))
+
+	require.Len(t, events, 3)
+	require.Equal(t, models.SessionEventCreated, events[0].Type)
+	require.Equal(t, now, events[0].CreatedAt)
+	require.Equal(t, models.SessionEventHijackSuspected, events[1].Type)
+	require.Equal(t, models.SessionReasonUserAgentChanged, events[1].Reason)
+	require.Equal(t, "curl", events[1].UserAgent)
+	require.Equal(t, "10.9.9.9", events[1].IPAddress)
+	require.Equal(t, "firefox", sess.UserAgent)
+	require.Equal(t, models.SessionEventRevoked, events[2].Type)
+	require.Equal(t, models.SessionReasonLogout, events[2].Reason)
+
+	// Bulk revocation records every revoked session
+	mockSessRepo.EXPECT().RevokeSessions(gomock.Any(), gomock.Any()).Return(&models.SessionRevokeResult{
+		Matched: 1, Revoked: 1, Sessions: []*models.Session{{SessionID: "s3", UserID: 3}},
+	}, nil)
+	_, err = sessUC.RevokeSessions(ctx, &models.SessionRevokeCriteria{UserIDs: []int{3}})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, models.SessionReasonAdmin, events[3].Reason)
+	require.Equal(t, 3, events[3].UserID)
+
+	mockEventRepo.EXPECT().ListUserEvents(gomock.Any(), 1, "1700000000000-0", 500).Return(&models.SessionEvents{}, nil)
+	_, err = sessUC.ListUserEvents(ctx, 1, "1700000000000-0", 10000)
+	require.NoError(t, err)
+	_, err = sessUC.ListEvents(ctx, "+", 0)
+	status, _ := httpErrors.ErrorResponse(err)
+	require.Equal(t, http.StatusBadRequest, status)
+}
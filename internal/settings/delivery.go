@@ -0,0 +1,10 @@
+package settings
+
+import "github.com/labstack/echo/v4"
+
+// Settings HTTP Handlers interface
+type Handlers interface {
+	GetPublic() echo.HandlerFunc
+	List() echo.HandlerFunc
+	Update() echo.HandlerFunc
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Settings handlers
+type settingsHandlers struct {
+	cfg        *config.Config
+	settingsUC settings.UseCase
+	logger     logger.Logger
+}
+
+// NewSettingsHandlers Settings handlers constructor
+func NewSettingsHandlers(cfg *config.Config, settingsUC settings.UseCase, log logger.Logger) settings.Handlers {
+	return &settingsHandlers{cfg: cfg, settingsUC: settingsUC, logger: log}
+}
+
+// GetPublic godoc
+// @Summary Get public settings
+// @Description Announcement banner and whether registration is open, readable without signing in
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.PublicSettings
+// @Failure 500 {object} httpErrors.RestError
+// @Router /settings/public [get]
+func (h *settingsHandlers) GetPublic() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "settingsHandlers.GetPublic")
+		defer span.Finish()
+
+		public, err := h.settingsUC.GetPublic(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, public)
+	}
+}
+
+// List godoc
+// @Summary List settings
+// @Description Every runtime setting with its stored or default value, admin only
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Setting
+// @Failure 500 {object} httpErrors.RestError
+// @Router /admin/settings [get]
+func (h *settingsHandlers) List() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "settingsHandlers.List")
+		defer span.Finish()
+
+		list, err := h.settingsUC.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// Update godoc
+// @Summary Update setting
+// @Description Change a runtime setting, every instance applies it without a restart, admin only
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Param key path string true "setting key"
+// @Param setting body dto.SettingRequest true "new value"
+// @Success 200 {object} models.Setting
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /admin/settings/{key} [put]
+func (h *settingsHandlers) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "settingsHandlers.Update")
+		defer span.Finish()
+
+		req := &dto.SettingRequest{}
+		if err := utils.ReadRequest(c, req); err != nil {
+			return err
+		}
+
+		updated, err := h.settingsUC.Update(ctx, c.Param("key"), req.Value)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, updated)
+	}
+}
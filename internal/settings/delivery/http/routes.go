@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+)
+
+// Map settings routes, public settings go on the public group, the rest on the admin group
+func MapSettingsRoutes(publicGroup *echo.Group, adminGroup *echo.Group, h settings.Handlers, mw *middleware.MiddlewareManager) {
+	publicGroup.GET("/public", h.GetPublic())
+	adminGroup.GET("/settings", h.List())
+	adminGroup.PUT("/settings/:key", h.Update(), mw.CSRF)
+}
@@ -0,0 +1,14 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package settings
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Settings Repository
+type Repository interface {
+	List(ctx context.Context) ([]*models.Setting, error)
+	Upsert(ctx context.Context, setting *models.Setting) (*models.Setting, error)
+}
@@ -0,0 +1,11 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package settings
+
+import "context"
+
+// Settings redis repository, carries change notifications between instances
+type RedisRepository interface {
+	PublishChange(ctx context.Context, key string) error
+	// Blocks until ctx is done, handle is called with the key of every published change
+	ListenChanges(ctx context.Context, handle func(ctx context.Context, key string) error)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+)
+
+// Settings Repository kept in process memory, dev mode stand-in for Postgres
+type settingsMemoryRepo struct {
+	mu       sync.RWMutex
+	settings map[string]models.Setting
+}
+
+// Settings in-memory Repository constructor
+func NewSettingsMemoryRepository() settings.Repository {
+	return &settingsMemoryRepo{settings: make(map[string]models.Setting)}
+}
+
+// List stored settings ordered by key
+func (r *settingsMemoryRepo) List(ctx context.Context) ([]*models.Setting, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "settingsMemoryRepo.List")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*models.Setting, 0, len(r.settings))
+	for _, setting := range r.settings {
+		setting := setting
+		list = append(list, &setting)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+// Insert or replace setting value
+func (r *settingsMemoryRepo) Upsert(ctx context.Context, setting *models.Setting) (*models.Setting, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "settingsMemoryRepo.Upsert")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *setting
+	stored.Value = append([]byte(nil), setting.Value...)
+	stored.UpdatedAt = time.Now()
+	r.settings[stored.Key] = stored
+	return &stored, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Stored setting, the jsonb value is read as text
+type settingRow struct {
+	Key       string    `db:"key"`
+	Value     string    `db:"value"`
+	UpdatedBy *int      `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Settings Repository
+type settingsRepo struct {
+	db *sqlx.DB
+}
+
+// Settings Repository constructor
+func NewSettingsRepository(db *sqlx.DB) settings.Repository {
+	return &settingsRepo{db: db}
+}
+
+// List stored settings ordered by key
+func (r *settingsRepo) List(ctx context.Context) ([]*models.Setting, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsRepo.List")
+	defer span.Finish()
+
+	rows := make([]*settingRow, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, listSettingsQuery); err != nil {
+		return nil, errors.Wrap(err, "settingsRepo.List.SelectContext")
+	}
+
+	list := make([]*models.Setting, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, toSetting(row))
+	}
+	return list, nil
+}
+
+// Insert or replace setting value
+func (r *settingsRepo) Upsert(ctx context.Context, setting *models.Setting) (*models.Setting, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsRepo.Upsert")
+	defer span.Finish()
+
+	row := &settingRow{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(ctx, upsertSettingQuery, setting.Key, string(setting.Value), setting.UpdatedBy).StructScan(row); err != nil {
+		return nil, errors.Wrap(err, "settingsRepo.Upsert.StructScan")
+	}
+	return toSetting(row), nil
+}
+
+func toSetting(row *settingRow) *models.Setting {
+	return &models.Setting{
+		Key:       row.Key,
+		Value:     json.RawMessage(row.Value),
+		UpdatedBy: row.UpdatedBy,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
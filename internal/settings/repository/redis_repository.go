@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Settings redis repository
+type settingsRedisRepo struct {
+	redisClient *redis.Client
+	channel     string
+	logger      logger.Logger
+}
+
+// Settings redis repository constructor
+func NewSettingsRedisRepo(redisClient *redis.Client, channel string, logger logger.Logger) settings.RedisRepository {
+	return &settingsRedisRepo{redisClient: redisClient, channel: channel, logger: logger}
+}
+
+// Publish the key of a changed setting to every instance
+func (r *settingsRedisRepo) PublishChange(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsRedisRepo.PublishChange")
+	defer span.Finish()
+
+	if err := r.redisClient.Publish(ctx, r.channel, key).Err(); err != nil {
+		return errors.Wrap(err, "settingsRedisRepo.PublishChange.Publish")
+	}
+	return nil
+}
+
+// Listen for published changes until ctx is done
+func (r *settingsRedisRepo) ListenChanges(ctx context.Context, handle func(ctx context.Context, key string) error) {
+	pubsub := r.redisClient.Subscribe(ctx, r.channel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := handle(ctx, msg.Payload); err != nil {
+				r.logger.Errorf("settingsRedisRepo.ListenChanges key: %s, error: %v", msg.Payload, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package repository
+
+const (
+	listSettingsQuery = `SELECT key, value, updated_by, updated_at FROM settings ORDER BY key`
+
+	upsertSettingQuery = `INSERT INTO settings (key, value, updated_by, updated_at)
+						VALUES ($1, $2, $3, now())
+						ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+						RETURNING key, value, updated_by, updated_at`
+)
@@ -0,0 +1,26 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package settings
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Source of the roles a default role must name
+type RoleReader interface {
+	GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error)
+}
+
+// Settings use case
+type UseCase interface {
+	// Typed settings, cached until RefreshSeconds pass or a change is published
+	Current(ctx context.Context) (*models.Settings, error)
+	GetPublic(ctx context.Context) (*models.PublicSettings, error)
+	List(ctx context.Context) ([]*models.Setting, error)
+	Update(ctx context.Context, key string, value json.RawMessage) (*models.Setting, error)
+	HandleChange(ctx context.Context, key string) error
+}
@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/audit"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultRefreshSeconds = 60
+	maxBannerLength       = 500
+	rolesPageSize         = 100
+
+	auditActionUpdated = "settings.updated"
+)
+
+// Typed field of every known key, the order is the order settings are listed in
+var fields = []struct {
+	key   string
+	field func(s *models.Settings) interface{}
+}{
+	{models.SettingAnnouncementBanner, func(s *models.Settings) interface{} { return &s.AnnouncementBanner }},
+	{models.SettingRegistrationEnabled, func(s *models.Settings) interface{} { return &s.RegistrationEnabled }},
+	{models.SettingInviteOnly, func(s *models.Settings) interface{} { return &s.InviteOnly }},
+	{models.SettingDefaultRole, func(s *models.Settings) interface{} { return &s.DefaultRole }},
+}
+
+// Settings UseCase
+type settingsUC struct {
+	cfg       *config.Config
+	repo      settings.Repository
+	redisRepo settings.RedisRepository
+	roles     settings.RoleReader
+	auditUC   audit.UseCase
+	clock     clock.Clock
+	logger    logger.Logger
+
+	mu       sync.RWMutex
+	cached   *models.Settings
+	loadedAt time.Time
+}
+
+// Settings UseCase constructor, auditUC may be nil
+func NewSettingsUseCase(
+	cfg *config.Config,
+	repo settings.Repository,
+	redisRepo settings.RedisRepository,
+	roles settings.RoleReader,
+	auditUC audit.UseCase,
+	clk clock.Clock,
+	log logger.Logger,
+) settings.UseCase {
+	return &settingsUC{cfg: cfg, repo: repo, redisRepo: redisRepo, roles: roles, auditUC: auditUC, clock: clk, logger: log}
+}
+
+// Typed settings, stored values over the defaults
+func (u *settingsUC) Current(ctx context.Context) (*models.Settings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsUC.Current")
+	defer span.Finish()
+
+	refresh := u.cfg.Settings.RefreshSeconds
+	if refresh <= 0 {
+		refresh = defaultRefreshSeconds
+	}
+
+	u.mu.RLock()
+	cached, loadedAt := u.cached, u.loadedAt
+	u.mu.RUnlock()
+	if cached != nil && u.clock.Since(loadedAt) < time.Duration(refresh)*time.Second {
+		current := *cached
+		return &current, nil
+	}
+
+	stored, err := u.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loaded := models.DefaultSettings(u.cfg.Registration.InviteOnly)
+	for _, setting := range stored {
+		field, ok := fieldOf(&loaded, setting.Key)
+		if !ok {
+			continue
+		}
+		// A value broken outside of Update keeps the default rather than failing every reader
+		if err := json.Unmarshal(setting.Value, field); err != nil {
+			u.logger.Errorf("settingsUC.Current.json.Unmarshal key: %s, error: %v", setting.Key, err)
+		}
+	}
+
+	u.mu.Lock()
+	u.cached, u.loadedAt = &loaded, u.clock.Now()
+	u.mu.Unlock()
+
+	current := loaded
+	return &current, nil
+}
+
+// Settings shown before sign in
+func (u *settingsUC) GetPublic(ctx context.Context) (*models.PublicSettings, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsUC.GetPublic")
+	defer span.Finish()
+
+	current, err := u.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PublicSettings{
+		AnnouncementBanner:  current.AnnouncementBanner,
+		RegistrationEnabled: current.RegistrationEnabled,
+		InviteOnly:          current.InviteOnly,
+	}, nil
+}
+
+// List every known setting, keys never stored carry their default value
+func (u *settingsUC) List(ctx context.Context) ([]*models.Setting, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsUC.List")
+	defer span.Finish()
+
+	stored, err := u.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.Setting, len(stored))
+	for _, setting := range stored {
+		byKey[setting.Key] = setting
+	}
+
+	defaults := models.DefaultSettings(u.cfg.Registration.InviteOnly)
+	list := make([]*models.Setting, 0, len(fields))
+	for _, f := range fields {
+		if setting, ok := byKey[f.key]; ok {
+			list = append(list, setting)
+			continue
+		}
+		value, err := json.Marshal(f.field(&defaults))
+		if err != nil {
+			return nil, errors.Wrap(err, "settingsUC.List.json.Marshal")
+		}
+		list = append(list, &models.Setting{Key: f.key, Value: value})
+	}
+	return list, nil
+}
+
+// Validate and store a setting, then drop the cached settings of every instance
+func (u *settingsUC) Update(ctx context.Context, key string, value json.RawMessage) (*models.Setting, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "settingsUC.Update")
+	defer span.Finish()
+
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := u.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	oldField, ok := fieldOf(current, key)
+	if !ok {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, "unknown setting", key)
+	}
+	oldValue, err := json.Marshal(oldField)
+	if err != nil {
+		return nil, errors.Wrap(err, "settingsUC.Update.json.Marshal")
+	}
+
+	newValue, err := u.validate(ctx, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := u.repo.Upsert(ctx, &models.Setting{Key: key, Value: newValue, UpdatedBy: &user.User.ID})
+	if err != nil {
+		return nil, err
+	}
+	u.invalidate()
+	if err := u.redisRepo.PublishChange(ctx, key); err != nil {
+		// Other instances pick the change up once their cache is stale
+		u.logger.Errorf("settingsUC.Update.PublishChange key: %s, error: %v", key, err)
+	}
+
+	u.recordUpdate(ctx, key, user.User.ID, oldValue, newValue)
+	return updated, nil
+}
+
+// Drop cached settings after another instance published a change
+func (u *settingsUC) HandleChange(ctx context.Context, key string) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "settingsUC.HandleChange")
+	defer span.Finish()
+
+	u.invalidate()
+	u.logger.Infof("settingsUC.HandleChange: setting %s changed", key)
+	return nil
+}
+
+// Decode value into the type of key and check it, returns the value re-encoded for storage
+func (u *settingsUC) validate(ctx context.Context, key string, value json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(value)) == 0 || bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return nil, httpErrors.NewBadRequestError("value is required")
+	}
+
+	decoded := models.DefaultSettings(u.cfg.Registration.InviteOnly)
+	field, _ := fieldOf(&decoded, key)
+	if err := json.Unmarshal(value, field); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "settingsUC.validate.json.Unmarshal"))
+	}
+
+	switch key {
+	case models.SettingAnnouncementBanner:
+		if len(decoded.AnnouncementBanner) > maxBannerLength {
+			return nil, httpErrors.NewBadRequestError("announcement banner is too long")
+		}
+	case models.SettingDefaultRole:
+		if err := u.checkRoleExists(ctx, decoded.DefaultRole); err != nil {
+			return nil, err
+		}
+	}
+
+	normalized, err := json.Marshal(field)
+	if err != nil {
+		return nil, errors.Wrap(err, "settingsUC.validate.json.Marshal")
+	}
+	return normalized, nil
+}
+
+func (u *settingsUC) checkRoleExists(ctx context.Context, name string) error {
+	pq := &utils.PaginationQuery{Page: 1, Size: rolesPageSize}
+	for {
+		roles, err := u.roles.GetRoles(ctx, pq)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles.Roles {
+			if role.Name == name {
+				return nil
+			}
+		}
+		if !roles.HasMore {
+			return httpErrors.NewBadRequestError("default role does not exist")
+		}
+		pq.Page++
+	}
+}
+
+func (u *settingsUC) recordUpdate(ctx context.Context, key string, actorID int, oldValue, newValue json.RawMessage) {
+	if u.auditUC == nil {
+		return
+	}
+	requestID, _ := ctx.Value(utils.ReqIDCtxKey{}).(string)
+	if err := u.auditUC.Record(ctx, auditActionUpdated, &models.AuditEvent{
+		ActorID:   &actorID,
+		RequestID: requestID,
+		Resource:  "setting:" + key,
+	}, map[string]json.RawMessage{"old": oldValue, "new": newValue}); err != nil {
+		u.logger.Errorf("settingsUC.recordUpdate.Record key: %s, error: %v", key, err)
+	}
+}
+
+func (u *settingsUC) invalidate() {
+	u.mu.Lock()
+	u.cached = nil
+	u.mu.Unlock()
+}
+
+func fieldOf(s *models.Settings, key string) (interface{}, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f.field(s), true
+		}
+	}
+	return nil, false
+}
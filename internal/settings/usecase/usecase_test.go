@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings/mock"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/settings/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+type stubRoles []string
+
+func (s stubRoles) GetRoles(ctx context.Context, pq *utils.PaginationQuery) (*models.RolesList, error) {
+	roles := make([]*models.Role, 0, len(s))
+	for i, name := range s {
+		roles = append(roles, &models.Role{ID: i + 1, Name: name})
+	}
+	return &models.RolesList{TotalCount: len(roles), Page: pq.GetPage(), Roles: roles}, nil
+}
+
+func TestSettingsUC_Defaults(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Settings: config.RuntimeSettings{RefreshSeconds: 60}}
+	uc := NewSettingsUseCase(cfg, repository.NewSettingsMemoryRepository(), mock.NewMockRedisRepository(ctrl), stubRoles{"administrator", "employee", "contractor"}, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+
+	current, err := uc.Current(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, models.DefaultSettings(false), *current)
+
+	list, err := uc.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	require.Equal(t, models.SettingRegistrationEnabled, list[1].Key)
+	require.JSONEq(t, `true`, string(list[1].Value))
+}
+
+func TestSettingsUC_Update(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	redisRepo := mock.NewMockRedisRepository(ctrl)
+	cfg := &config.Config{Settings: config.RuntimeSettings{RefreshSeconds: 60}}
+	uc := NewSettingsUseCase(cfg, repository.NewSettingsMemoryRepository(), redisRepo, stubRoles{"administrator", "employee", "contractor"}, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsAdmin()
+
+	redisRepo.EXPECT().PublishChange(gomock.Any(), models.SettingRegistrationEnabled).Return(nil)
+	updated, err := uc.Update(ctx, models.SettingRegistrationEnabled, json.RawMessage(`false`))
+	require.NoError(t, err)
+	require.Equal(t, 1, *updated.UpdatedBy)
+
+	redisRepo.EXPECT().PublishChange(gomock.Any(), models.SettingDefaultRole).Return(nil)
+	_, err = uc.Update(ctx, models.SettingDefaultRole, json.RawMessage(`"contractor"`))
+	require.NoError(t, err)
+
+	public, err := uc.GetPublic(ctx)
+	require.NoError(t, err)
+	require.False(t, public.RegistrationEnabled)
+	current, err := uc.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "contractor", current.DefaultRole)
+
+	_, err = uc.Update(ctx, "maintenance_mode", json.RawMessage(`true`))
+	testutil.RequireStatus(t, err, http.StatusNotFound)
+	_, err = uc.Update(ctx, models.SettingRegistrationEnabled, json.RawMessage(`"yes"`))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.Update(ctx, models.SettingDefaultRole, json.RawMessage(`"superuser"`))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+	_, err = uc.Update(ctx, models.SettingAnnouncementBanner, json.RawMessage(`null`))
+	testutil.RequireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestSettingsUC_Cache(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockRepository(ctrl)
+	clk := clock.NewFrozen(time.Now())
+	cfg := &config.Config{Settings: config.RuntimeSettings{RefreshSeconds: 60}}
+	uc := NewSettingsUseCase(cfg, mockRepo, mock.NewMockRedisRepository(ctrl), stubRoles{"administrator", "employee", "contractor"}, nil, clk, testutil.Logger(cfg))
+	ctx := context.Background()
+
+	banner := &models.Setting{Key: models.SettingAnnouncementBanner, Value: json.RawMessage(`"maintenance at 22:00"`)}
+	mockRepo.EXPECT().List(gomock.Any()).Return([]*models.Setting{banner}, nil).Times(3)
+
+	for i := 0; i < 2; i++ {
+		current, err := uc.Current(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "maintenance at 22:00", current.AnnouncementBanner)
+	}
+
+	// Stale after RefreshSeconds
+	clk.Advance(time.Minute)
+	_, err := uc.Current(ctx)
+	require.NoError(t, err)
+
+	// Change published by another instance
+	require.NoError(t, uc.HandleChange(ctx, models.SettingAnnouncementBanner))
+	_, err = uc.Current(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,42 @@
+// Package testutil holds helpers shared by the use case tests
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
+)
+
+// Fail unless err is an http error of status
+func RequireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, status, httpErrors.ParseErrors(err).Status(), "%v", err)
+}
+
+// Context of a signed in user
+func AsUser(id int) context.Context {
+	return requestctx.User.With(context.Background(), &models.UserWithRole{User: models.User{ID: id}})
+}
+
+// Context of the administrator, user 1
+func AsAdmin() context.Context {
+	return requestctx.User.With(context.Background(), &models.UserWithRole{
+		User: models.User{ID: 1},
+		Role: models.Role{Name: "administrator"},
+	})
+}
+
+// Initialized logger of cfg for use case constructors
+func Logger(cfg *config.Config) logger.Logger {
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	return appLogger
+}
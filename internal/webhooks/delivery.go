@@ -0,0 +1,11 @@
+package webhooks
+
+import "github.com/labstack/echo/v4"
+
+// Webhooks HTTP Handlers interface
+type Handlers interface {
+	ListWebhooks() echo.HandlerFunc
+	CreateWebhook() echo.HandlerFunc
+	DeleteWebhook() echo.HandlerFunc
+	ListDeliveries() echo.HandlerFunc
+}
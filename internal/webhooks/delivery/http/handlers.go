@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Webhooks handlers
+type webhooksHandlers struct {
+	cfg        *config.Config
+	webhooksUC webhooks.UseCase
+	logger     logger.Logger
+}
+
+// NewWebhooksHandlers Webhooks handlers constructor
+func NewWebhooksHandlers(cfg *config.Config, webhooksUC webhooks.UseCase, log logger.Logger) webhooks.Handlers {
+	return &webhooksHandlers{cfg: cfg, webhooksUC: webhooksUC, logger: log}
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List webhooks of the current user, secrets are not returned
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/me/webhooks [get]
+func (h *webhooksHandlers) ListWebhooks() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "webhooksHandlers.ListWebhooks")
+		defer span.Finish()
+
+		hooks, err := h.webhooksUC.ListWebhooks(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, hooks)
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Register webhook
+// @Description Register webhook of the current user, the signing secret is only returned in this response
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param body body dto.WebhookRequest true "webhook"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/me/webhooks [post]
+func (h *webhooksHandlers) CreateWebhook() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "webhooksHandlers.CreateWebhook")
+		defer span.Finish()
+
+		req := &dto.WebhookRequest{}
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		created, err := h.webhooksUC.CreateWebhook(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, created)
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Description Delete webhook of the current user and its delivery log
+// @Tags Webhooks
+// @Param id path int true "webhook_id"
+// @Success 204
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/webhooks/{id} [delete]
+func (h *webhooksHandlers) DeleteWebhook() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "webhooksHandlers.DeleteWebhook")
+		defer span.Finish()
+
+		webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		if err := h.webhooksUC.DeleteWebhook(ctx, webhookID); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Recent delivery attempts of a webhook of the current user, newest first
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "webhook_id"
+// @Param limit query int false "number of attempts"
+// @Success 200 {object} models.WebhookDeliveriesList
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/me/webhooks/{id}/deliveries [get]
+func (h *webhooksHandlers) ListDeliveries() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "webhooksHandlers.ListDeliveries")
+		defer span.Finish()
+
+		webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+		if err != nil {
+			return httpErrors.NewBadRequestError(err)
+		}
+
+		limit := 0
+		if raw := c.QueryParam("limit"); raw != "" {
+			if limit, err = strconv.Atoi(raw); err != nil {
+				return httpErrors.NewBadRequestError(err)
+			}
+		}
+
+		deliveries, err := h.webhooksUC.ListDeliveries(ctx, webhookID, limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, deliveries)
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/middleware"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+)
+
+// Map webhooks routes under /me/webhooks, authGroup must already carry the JWT and session middlewares
+func MapWebhooksRoutes(authGroup *echo.Group, h webhooks.Handlers, mw *middleware.MiddlewareManager) {
+	webhooksGroup := authGroup.Group("/me/webhooks")
+
+	webhooksGroup.GET("", h.ListWebhooks())
+	webhooksGroup.POST("", h.CreateWebhook(), mw.CSRF)
+	webhooksGroup.DELETE("/:webhook_id", h.DeleteWebhook(), mw.CSRF)
+	webhooksGroup.GET("/:webhook_id/deliveries", h.ListDeliveries())
+}
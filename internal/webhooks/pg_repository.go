@@ -0,0 +1,18 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package webhooks
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Webhooks repository interface, reads and deletes are scoped to the owning user and return sql.ErrNoRows otherwise
+type Repository interface {
+	ListWebhooks(ctx context.Context, userID int) ([]*models.Webhook, error)
+	GetWebhook(ctx context.Context, userID int, webhookID int64) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int, webhookID int64) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, userID int, webhookID int64, limit int) ([]*models.WebhookDelivery, error)
+}
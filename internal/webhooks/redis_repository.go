@@ -0,0 +1,9 @@
+package webhooks
+
+import "context"
+
+// Devices seen per user, used to tell logins from a new device
+type RedisRepository interface {
+	// Remember device, returns whether it is new and how many devices were known before
+	AddDevice(ctx context.Context, userID int, fingerprint string) (bool, int64, error)
+}
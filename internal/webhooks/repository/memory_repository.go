@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+)
+
+// Delivery attempts kept per webhook by the in-memory repository, older ones are dropped
+const memoryDeliveriesLimit = 1000
+
+// Webhooks Repository kept in process memory, dev mode stand-in for Postgres
+type webhooksMemoryRepo struct {
+	mu             sync.RWMutex
+	lastID         int64
+	lastDeliveryID int64
+	hooks          map[int64]models.Webhook
+	deliveries     map[int64][]models.WebhookDelivery
+}
+
+// Webhooks in-memory Repository constructor
+func NewWebhooksMemoryRepository() webhooks.Repository {
+	return &webhooksMemoryRepo{hooks: make(map[int64]models.Webhook), deliveries: make(map[int64][]models.WebhookDelivery)}
+}
+
+// List webhooks of a user
+func (r *webhooksMemoryRepo) ListWebhooks(ctx context.Context, userID int) ([]*models.Webhook, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.ListWebhooks")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]*models.Webhook, 0)
+	for _, hook := range r.hooks {
+		if hook.UserID == userID {
+			hook := hook
+			hooks = append(hooks, &hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks, nil
+}
+
+// Get webhook of a user
+func (r *webhooksMemoryRepo) GetWebhook(ctx context.Context, userID int, webhookID int64) (*models.Webhook, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.GetWebhook")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hook, ok := r.hooks[webhookID]
+	if !ok || hook.UserID != userID {
+		return nil, errors.Wrap(sql.ErrNoRows, "webhooksMemoryRepo.GetWebhook")
+	}
+	return &hook, nil
+}
+
+// Create webhook
+func (r *webhooksMemoryRepo) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.CreateWebhook")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	created := *webhook
+	created.ID = r.lastID
+	created.Events = append([]string(nil), webhook.Events...)
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.hooks[created.ID] = created
+	return &created, nil
+}
+
+// Delete webhook of a user, its delivery log goes with it
+func (r *webhooksMemoryRepo) DeleteWebhook(ctx context.Context, userID int, webhookID int64) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.DeleteWebhook")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if hook, ok := r.hooks[webhookID]; !ok || hook.UserID != userID {
+		return errors.Wrap(sql.ErrNoRows, "webhooksMemoryRepo.DeleteWebhook")
+	}
+	delete(r.hooks, webhookID)
+	delete(r.deliveries, webhookID)
+	return nil
+}
+
+// Log delivery attempt
+func (r *webhooksMemoryRepo) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.CreateDelivery")
+	defer span.Finish()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.hooks[delivery.WebhookID]; !ok {
+		return nil
+	}
+	r.lastDeliveryID++
+	created := *delivery
+	created.ID = r.lastDeliveryID
+	created.CreatedAt = time.Now()
+
+	log := append(r.deliveries[delivery.WebhookID], created)
+	if len(log) > memoryDeliveriesLimit {
+		log = log[len(log)-memoryDeliveriesLimit:]
+	}
+	r.deliveries[delivery.WebhookID] = log
+	return nil
+}
+
+// List delivery attempts of a webhook of a user, newest first
+func (r *webhooksMemoryRepo) ListDeliveries(ctx context.Context, userID int, webhookID int64, limit int) ([]*models.WebhookDelivery, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "webhooksMemoryRepo.ListDeliveries")
+	defer span.Finish()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log := r.deliveries[webhookID]
+	deliveries := make([]*models.WebhookDelivery, 0, limit)
+	for i := len(log) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if log[i].UserID == userID {
+			delivery := log[i]
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+)
+
+// Associated data of the encrypted signing secret
+const piiFieldWebhookSecret = "user_webhooks.secret"
+
+// Stored webhook, events are kept comma separated
+type webhookRow struct {
+	ID        int64     `db:"id"`
+	UserID    int       `db:"user_id"`
+	URL       string    `db:"url"`
+	Events    string    `db:"events"`
+	Secret    string    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Webhooks Repository
+type webhooksRepo struct {
+	db     *sqlx.DB
+	cipher *pii.Cipher
+}
+
+// Webhooks Repository constructor, signing secrets are encrypted like user PII
+func NewWebhooksRepository(db *sqlx.DB, cipher *pii.Cipher) webhooks.Repository {
+	return &webhooksRepo{db: db, cipher: cipher}
+}
+
+// List webhooks of a user
+func (r *webhooksRepo) ListWebhooks(ctx context.Context, userID int) ([]*models.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.ListWebhooks")
+	defer span.Finish()
+
+	rows := make([]*webhookRow, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &rows, listWebhooksQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.ListWebhooks.SelectContext")
+	}
+
+	hooks := make([]*models.Webhook, 0, len(rows))
+	for _, row := range rows {
+		hook, err := r.toWebhook(row)
+		if err != nil {
+			return nil, errors.Wrap(err, "webhooksRepo.ListWebhooks.toWebhook")
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// Get webhook of a user
+func (r *webhooksRepo) GetWebhook(ctx context.Context, userID int, webhookID int64) (*models.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.GetWebhook")
+	defer span.Finish()
+
+	row := &webhookRow{}
+	if err := tenant.DB(ctx, r.db).GetContext(ctx, row, getWebhookQuery, webhookID, userID); err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.GetWebhook.GetContext")
+	}
+	hook, err := r.toWebhook(row)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.GetWebhook.toWebhook")
+	}
+	return hook, nil
+}
+
+// Create webhook
+func (r *webhooksRepo) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.CreateWebhook")
+	defer span.Finish()
+
+	secret, err := r.cipher.Encrypt(piiFieldWebhookSecret, webhook.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.CreateWebhook.Encrypt")
+	}
+
+	row := &webhookRow{}
+	if err := tenant.DB(ctx, r.db).QueryRowxContext(
+		ctx,
+		createWebhookQuery,
+		webhook.UserID,
+		webhook.URL,
+		strings.Join(webhook.Events, ","),
+		secret,
+	).StructScan(row); err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.CreateWebhook.StructScan")
+	}
+	created, err := r.toWebhook(row)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.CreateWebhook.toWebhook")
+	}
+	return created, nil
+}
+
+// Delete webhook of a user, its delivery log goes with it
+func (r *webhooksRepo) DeleteWebhook(ctx context.Context, userID int, webhookID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.DeleteWebhook")
+	defer span.Finish()
+
+	result, err := tenant.DB(ctx, r.db).ExecContext(ctx, deleteWebhookQuery, webhookID, userID)
+	if err != nil {
+		return errors.Wrap(err, "webhooksRepo.DeleteWebhook.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "webhooksRepo.DeleteWebhook.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "webhooksRepo.DeleteWebhook")
+	}
+	return nil
+}
+
+// Log delivery attempt
+func (r *webhooksRepo) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.CreateDelivery")
+	defer span.Finish()
+
+	if _, err := tenant.DB(ctx, r.db).ExecContext(
+		ctx,
+		createDeliveryQuery,
+		delivery.WebhookID,
+		delivery.UserID,
+		delivery.EventID,
+		delivery.Event,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Succeeded,
+		delivery.DurationMs,
+	); err != nil {
+		return errors.Wrap(err, "webhooksRepo.CreateDelivery.ExecContext")
+	}
+	return nil
+}
+
+// List delivery attempts of a webhook of a user, newest first
+func (r *webhooksRepo) ListDeliveries(ctx context.Context, userID int, webhookID int64, limit int) ([]*models.WebhookDelivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRepo.ListDeliveries")
+	defer span.Finish()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	if err := tenant.DB(ctx, r.db).SelectContext(ctx, &deliveries, listDeliveriesQuery, webhookID, userID, limit); err != nil {
+		return nil, errors.Wrap(err, "webhooksRepo.ListDeliveries.SelectContext")
+	}
+	return deliveries, nil
+}
+
+func (r *webhooksRepo) toWebhook(row *webhookRow) (*models.Webhook, error) {
+	secret, err := r.cipher.Decrypt(piiFieldWebhookSecret, row.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Webhook{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		URL:       row.URL,
+		Events:    strings.Split(row.Events, ","),
+		Secret:    secret,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+)
+
+// Webhooks redis repository
+type webhooksRedisRepo struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Webhooks redis repository constructor
+func NewWebhooksRedisRepo(redisClient *redis.Client, prefix string) webhooks.RedisRepository {
+	return &webhooksRedisRepo{redisClient: redisClient, prefix: prefix}
+}
+
+// Add device fingerprint to the set of the user
+func (r *webhooksRedisRepo) AddDevice(ctx context.Context, userID int, fingerprint string) (bool, int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksRedisRepo.AddDevice")
+	defer span.Finish()
+
+	key := r.prefix + ":" + strconv.Itoa(userID)
+	pipe := r.redisClient.TxPipeline()
+	known := pipe.SCard(ctx, key)
+	added := pipe.SAdd(ctx, key, fingerprint)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, 0, errors.Wrap(err, "webhooksRedisRepo.AddDevice.pipe.Exec")
+	}
+	return added.Val() == 1, known.Val(), nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+const (
+	listWebhooksQuery = `SELECT id, user_id, url, events, secret, created_at, updated_at
+						FROM user_webhooks
+						WHERE user_id = $1
+						ORDER BY id`
+
+	getWebhookQuery = `SELECT id, user_id, url, events, secret, created_at, updated_at
+						FROM user_webhooks
+						WHERE id = $1 AND user_id = $2`
+
+	createWebhookQuery = `INSERT INTO user_webhooks (user_id, url, events, secret, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, now(), now())
+						RETURNING id, user_id, url, events, secret, created_at, updated_at`
+
+	deleteWebhookQuery = `DELETE FROM user_webhooks WHERE id = $1 AND user_id = $2`
+
+	createDeliveryQuery = `INSERT INTO user_webhook_deliveries (webhook_id, user_id, event_id, event, attempt, status_code, error, succeeded, duration_ms, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())`
+
+	listDeliveriesQuery = `SELECT id, webhook_id, user_id, event_id, event, attempt, status_code, error, succeeded, duration_ms, created_at
+						FROM user_webhook_deliveries
+						WHERE webhook_id = $1 AND user_id = $2
+						ORDER BY id DESC
+						LIMIT $3`
+)
@@ -0,0 +1,37 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+//go:generate go run ../../cmd/gen decorator -interface UseCase usecase.go
+package webhooks
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+)
+
+// Delivery job type consumed by the job queue worker
+const DeliveryJobType = "webhooks.deliver"
+
+// Request headers of a delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+	// Schema version of the event data, receivers upcast older versions with pkg/eventschema
+	HeaderSchemaVersion = "X-Webhook-Schema-Version"
+)
+
+// Webhooks UseCase interface, management methods act on the user of the context
+type UseCase interface {
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	CreateWebhook(ctx context.Context, req *dto.WebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int64) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit int) (*models.WebhookDeliveriesList, error)
+	// Queue a delivery of event to every webhook of the user subscribed to it
+	Publish(ctx context.Context, userID int, event string, data interface{}) error
+	// Publish login.new_device when the user agent was not seen for the user before
+	NotifyLogin(ctx context.Context, userID int, ipAddress string, userAgent string) error
+	// Bounded by the webhook timeout, observe:nodeadline
+	HandleDeliveryJob(ctx context.Context, job *jobqueue.Job) error
+}
@@ -0,0 +1,385 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/eventschema"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultDeliveryLogLimit = 50
+	secretPrefix            = "whsec_"
+	// Response bodies are not stored, only drained up to this size so connections are reused
+	maxDrainedBody = 64 << 10
+
+	errWebhookLimit = "Webhook limit reached"
+	errInsecureURL  = "Webhook url must use https"
+)
+
+// Queued delivery of one event to one webhook
+type deliveryJob struct {
+	WebhookID int64               `json:"webhook_id"`
+	UserID    int                 `json:"user_id"`
+	Event     models.WebhookEvent `json:"event"`
+	// Schema version of the event data, 0 for jobs queued before versions were sent
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+// Webhooks UseCase
+type webhooksUC struct {
+	cfg       *config.Config
+	repo      webhooks.Repository
+	redisRepo webhooks.RedisRepository
+	queue     *jobqueue.Queue
+	client    *http.Client
+	schemas   *eventschema.Registry
+	clock     clock.Clock
+	logger    logger.Logger
+}
+
+// Webhooks UseCase constructor, deliveries go through transport instead of the network when it is set
+func NewWebhooksUseCase(
+	cfg *config.Config,
+	repo webhooks.Repository,
+	redisRepo webhooks.RedisRepository,
+	queue *jobqueue.Queue,
+	transport http.RoundTripper,
+	clk clock.Clock,
+	logger logger.Logger,
+) webhooks.UseCase {
+	client := newDeliveryClient(cfg.Webhooks.AllowPrivateTargets)
+	if transport != nil {
+		client.Transport = transport
+	}
+	return &webhooksUC{
+		cfg:       cfg,
+		repo:      repo,
+		redisRepo: redisRepo,
+		queue:     queue,
+		client:    client,
+		schemas:   eventschema.Events(),
+		clock:     clk,
+		logger:    logger,
+	}
+}
+
+// List webhooks of the current user, secrets are not returned
+func (u *webhooksUC) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.ListWebhooks")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := u.repo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	return hooks, nil
+}
+
+// Register webhook of the current user, up to Webhooks.MaxPerUser. The generated signing secret
+// is only returned here.
+func (u *webhooksUC) CreateWebhook(ctx context.Context, req *dto.WebhookRequest) (*models.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.CreateWebhook")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.validateWebhook(ctx, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.repo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := u.cfg.Webhooks.MaxPerUser; limit > 0 && len(existing) >= limit {
+		return nil, httpErrors.NewRestError(http.StatusConflict, errWebhookLimit, limit)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "webhooksUC.CreateWebhook.generateSecret")
+	}
+	return u.repo.CreateWebhook(ctx, &models.Webhook{UserID: userID, URL: req.URL, Events: req.Events, Secret: secret})
+}
+
+// Delete webhook of the current user
+func (u *webhooksUC) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.DeleteWebhook")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteWebhook(ctx, userID, webhookID)
+}
+
+// Recent delivery attempts of a webhook of the current user, limit is capped by Webhooks.DeliveryLogLimit
+func (u *webhooksUC) ListDeliveries(ctx context.Context, webhookID int64, limit int) (*models.WebhookDeliveriesList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.ListDeliveries")
+	defer span.Finish()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.repo.GetWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	maxLimit := u.cfg.Webhooks.DeliveryLogLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultDeliveryLogLimit
+	}
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	deliveries, err := u.repo.ListDeliveries(ctx, userID, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookDeliveriesList{Deliveries: deliveries}, nil
+}
+
+// Queue a delivery of event to every webhook of the user subscribed to it. Data has to match the latest schema of
+// the event, deliveries carry its version
+func (u *webhooksUC) Publish(ctx context.Context, userID int, event string, data interface{}) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.Publish")
+	defer span.Finish()
+
+	if !u.cfg.Webhooks.Enabled {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "webhooksUC.Publish.json.Marshal")
+	}
+	// Checked whether anyone is subscribed or not, a payload breaking its schema is a bug of the publisher
+	schemaVersion, err := u.schemas.Validate(event, raw)
+	if err != nil {
+		return err
+	}
+
+	hooks, err := u.repo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if !hook.Subscribed(event) {
+			continue
+		}
+		job := &deliveryJob{
+			WebhookID:     hook.ID,
+			UserID:        userID,
+			Event:         models.WebhookEvent{ID: uuid.New().String(), Type: event, UserID: userID, CreatedAt: u.clock.Now().UTC(), Data: raw},
+			SchemaVersion: schemaVersion,
+		}
+		if _, err := u.queue.Enqueue(ctx, webhooks.DeliveryJobType, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publish login.new_device when the user agent was not seen for the user before. The first
+// device of a user is only remembered, there is nothing to tell it apart from.
+func (u *webhooksUC) NotifyLogin(ctx context.Context, userID int, ipAddress string, userAgent string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.NotifyLogin")
+	defer span.Finish()
+
+	if !u.cfg.Webhooks.Enabled {
+		return nil
+	}
+
+	isNew, known, err := u.redisRepo.AddDevice(ctx, userID, deviceFingerprint(userAgent))
+	if err != nil {
+		return err
+	}
+	if !isNew || known == 0 {
+		return nil
+	}
+
+	return u.Publish(ctx, userID, models.WebhookEventLoginNewDevice, map[string]string{
+		"ip_address": ipAddress,
+		"user_agent": userAgent,
+	})
+}
+
+// Post event to the webhook and log the attempt. Failures are returned so the worker retries,
+// a webhook deleted in the meantime ends the job.
+func (u *webhooksUC) HandleDeliveryJob(ctx context.Context, job *jobqueue.Job) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "webhooksUC.HandleDeliveryJob")
+	defer span.Finish()
+
+	payload := &deliveryJob{}
+	if err := json.Unmarshal(job.Payload, payload); err != nil {
+		return errors.Wrap(err, "webhooksUC.HandleDeliveryJob.json.Unmarshal")
+	}
+
+	hook, err := u.repo.GetWebhook(ctx, payload.UserID, payload.WebhookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: hook.ID,
+		UserID:    hook.UserID,
+		EventID:   payload.Event.ID,
+		Event:     payload.Event.Type,
+		Attempt:   job.Attempts,
+	}
+	start := u.clock.Now()
+	statusCode, deliverErr := u.deliver(ctx, hook, &payload.Event, payload.SchemaVersion)
+	delivery.DurationMs = int(u.clock.Now().Sub(start).Milliseconds())
+	delivery.StatusCode = statusCode
+	delivery.Succeeded = deliverErr == nil
+	if deliverErr != nil {
+		delivery.Error = deliverErr.Error()
+	}
+
+	if err := u.repo.CreateDelivery(ctx, delivery); err != nil {
+		u.logger.Errorf("webhooksUC.HandleDeliveryJob.CreateDelivery webhookID: %d, error: %v", hook.ID, err)
+	}
+	return deliverErr
+}
+
+func (u *webhooksUC) deliver(ctx context.Context, hook *models.Webhook, event *models.WebhookEvent, schemaVersion int) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, errors.Wrap(err, "webhooksUC.deliver.json.Marshal")
+	}
+
+	timeout := time.Duration(u.cfg.Webhooks.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "webhooksUC.deliver.NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderEvent, event.Type)
+	req.Header.Set(webhooks.HeaderDelivery, event.ID)
+	if schemaVersion > 0 {
+		req.Header.Set(webhooks.HeaderSchemaVersion, strconv.Itoa(schemaVersion))
+	}
+	req.Header.Set(webhooks.HeaderSignature, Sign(hook.Secret, u.clock.Now(), body))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "webhooksUC.deliver.client.Do")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Signature header value, t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed by the secret>.
+// Receivers recompute it and reject stale timestamps.
+func Sign(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Trim and validate the request, plain http targets need Webhooks.AllowHTTP
+func (u *webhooksUC) validateWebhook(ctx context.Context, req *dto.WebhookRequest) error {
+	req.URL = strings.TrimSpace(req.URL)
+	if err := utils.ValidateStruct(ctx, req); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	if target.Scheme != "https" && !u.cfg.Webhooks.AllowHTTP {
+		return httpErrors.NewBadRequestError(errInsecureURL)
+	}
+
+	events := make([]string, 0, len(req.Events))
+	seen := make(map[string]bool, len(req.Events))
+	for _, event := range req.Events {
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	req.Events = events
+	return nil
+}
+
+func currentUserID(ctx context.Context) (int, error) {
+	user, err := utils.GetUserFromCtx(ctx)
+	if err != nil {
+		return 0, httpErrors.NewUnauthorizedError(err)
+	}
+	return user.User.ID, nil
+}
+
+func generateSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func deviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:])
+}
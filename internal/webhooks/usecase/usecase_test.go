@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/dto"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/testutil"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks"
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/webhooks/repository"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/jobqueue"
+)
+
+func TestWebhooksUC_CreateWebhook(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Webhooks: config.Webhooks{MaxPerUser: 1}}
+	uc := NewWebhooksUseCase(cfg, repository.NewWebhooksMemoryRepository(), nil, nil, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+
+	_, err := uc.CreateWebhook(ctx, &dto.WebhookRequest{URL: "http://example.com/hook", Events: []string{models.WebhookEventProfileUpdated}})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+
+	_, err = uc.CreateWebhook(ctx, &dto.WebhookRequest{URL: "https://example.com/hook", Events: []string{"user.deleted"}})
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+
+	created, err := uc.CreateWebhook(ctx, &dto.WebhookRequest{
+		URL:    " https://example.com/hook ",
+		Events: []string{models.WebhookEventProfileUpdated, models.WebhookEventProfileUpdated},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/hook", created.URL)
+	require.Equal(t, []string{models.WebhookEventProfileUpdated}, created.Events)
+	require.True(t, strings.HasPrefix(created.Secret, secretPrefix))
+
+	_, err = uc.CreateWebhook(ctx, &dto.WebhookRequest{URL: "https://example.com/other", Events: []string{models.WebhookEventLoginNewDevice}})
+	require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+
+	hooks, err := uc.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	require.Empty(t, hooks[0].Secret)
+}
+
+func TestWebhooksUC_HandleDeliveryJob(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusOK
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Webhooks: config.Webhooks{AllowHTTP: true, AllowPrivateTargets: true}}
+	uc := NewWebhooksUseCase(cfg, repository.NewWebhooksMemoryRepository(), nil, nil, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+	hook, err := uc.CreateWebhook(ctx, &dto.WebhookRequest{URL: server.URL, Events: []string{models.WebhookEventProfileUpdated}})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(&deliveryJob{
+		WebhookID:     hook.ID,
+		UserID:        1,
+		Event:         models.WebhookEvent{ID: "evt-1", Type: models.WebhookEventProfileUpdated, UserID: 1, Data: json.RawMessage(`{"id":1}`)},
+		SchemaVersion: 1,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.HandleDeliveryJob(ctx, &jobqueue.Job{Payload: payload, Attempts: 1}))
+	require.Equal(t, models.WebhookEventProfileUpdated, received.Header.Get(webhooks.HeaderEvent))
+	require.Equal(t, "evt-1", received.Header.Get(webhooks.HeaderDelivery))
+	require.Equal(t, "1", received.Header.Get(webhooks.HeaderSchemaVersion))
+
+	// Receivers verify the signature over the timestamp and the raw body
+	parts := strings.Split(received.Header.Get(webhooks.HeaderSignature), ",")
+	require.Len(t, parts, 2)
+	timestamp := strings.TrimPrefix(parts[0], "t=")
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write([]byte(timestamp + "." + string(receivedBody)))
+	require.Equal(t, "v1="+hex.EncodeToString(mac.Sum(nil)), parts[1])
+
+	status = http.StatusBadGateway
+	require.Error(t, uc.HandleDeliveryJob(ctx, &jobqueue.Job{Payload: payload, Attempts: 2}))
+
+	log, err := uc.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, log.Deliveries, 2)
+	require.False(t, log.Deliveries[0].Succeeded)
+	require.Equal(t, http.StatusBadGateway, log.Deliveries[0].StatusCode)
+	require.Equal(t, 2, log.Deliveries[0].Attempt)
+	require.True(t, log.Deliveries[1].Succeeded)
+
+	// Deleted webhooks end the job
+	require.NoError(t, uc.DeleteWebhook(ctx, hook.ID))
+	require.NoError(t, uc.HandleDeliveryJob(ctx, &jobqueue.Job{Payload: payload, Attempts: 3}))
+}
+
+func TestWebhooksUC_Publish_ValidatesSchema(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Webhooks: config.Webhooks{Enabled: true}}
+	uc := NewWebhooksUseCase(cfg, repository.NewWebhooksMemoryRepository(), nil, nil, nil, clock.NewFrozen(time.Now()), testutil.Logger(cfg))
+	ctx := testutil.AsUser(1)
+
+	require.NoError(t, uc.Publish(ctx, 1, models.WebhookEventLoginNewDevice, map[string]string{"ip_address": "10.0.0.1", "user_agent": "curl"}))
+	require.Error(t, uc.Publish(ctx, 1, models.WebhookEventLoginNewDevice, map[string]string{"ip_address": "10.0.0.1"}))
+	require.Error(t, uc.Publish(ctx, 1, models.WebhookEventProfileUpdated, map[string]interface{}{"id": "1"}))
+	require.Error(t, uc.Publish(ctx, 1, "user.unknown", map[string]string{}))
+}
+
+func TestRefusePrivate(t *testing.T) {
+	t.Parallel()
+
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "169.254.169.254:80", "[::1]:443", "0.0.0.0:80"} {
+		require.Error(t, refusePrivate("tcp", address, nil), address)
+	}
+	require.NoError(t, refusePrivate("tcp", "93.184.216.34:443", nil))
+}
@@ -0,0 +1 @@
+ALTER TABLE users RENAME COLUMN password TO password_hash;
@@ -0,0 +1,3 @@
+-- The repositories have always written the hashed password into users.password,
+-- align the schema with the code so generated queries compile against it.
+ALTER TABLE users RENAME COLUMN password_hash TO password;
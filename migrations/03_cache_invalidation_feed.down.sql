@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS user_roles_cache_invalidation ON user_roles;
+DROP TRIGGER IF EXISTS roles_cache_invalidation ON roles;
+DROP TRIGGER IF EXISTS users_cache_invalidation ON users;
+DROP FUNCTION IF EXISTS notify_cache_invalidation();
+DROP TABLE IF EXISTS cache_invalidation_log CASCADE;
@@ -0,0 +1,50 @@
+-- Change log backing the cache invalidation feed, listeners replay it after reconnecting
+CREATE TABLE cache_invalidation_log (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL,
+    operation VARCHAR(10) NOT NULL,
+    entity_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cache_invalidation_log_created_at ON cache_invalidation_log(created_at);
+
+-- TG_ARGV[0] names the column identifying the cached entity
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+    rec RECORD;
+    log_id BIGINT;
+    entity INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        rec := OLD;
+    ELSE
+        rec := NEW;
+    END IF;
+
+    entity := (to_jsonb(rec) ->> TG_ARGV[0])::INT;
+
+    INSERT INTO cache_invalidation_log (table_name, operation, entity_id)
+    VALUES (TG_TABLE_NAME, TG_OP, entity)
+    RETURNING id INTO log_id;
+
+    PERFORM pg_notify('cache_invalidation', json_build_object(
+        'id', log_id,
+        'table', TG_TABLE_NAME,
+        'op', TG_OP,
+        'entity_id', entity
+    )::text);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE 'plpgsql';
+
+CREATE TRIGGER users_cache_invalidation AFTER UPDATE OR DELETE
+ON users FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation('id');
+
+CREATE TRIGGER roles_cache_invalidation AFTER UPDATE OR DELETE
+ON roles FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation('id');
+
+CREATE TRIGGER user_roles_cache_invalidation AFTER INSERT OR UPDATE OR DELETE
+ON user_roles FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation('user_id');
@@ -0,0 +1,13 @@
+-- Append only security audit trail
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(100) NOT NULL,
+    actor_id INT,
+    ip_address VARCHAR(45),
+    request_id VARCHAR(64),
+    resource VARCHAR(255),
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_action_created_at ON audit_events(action, created_at);
@@ -0,0 +1,16 @@
+-- Uploaded files, objects stay in the quarantine bucket until scanned clean
+CREATE TABLE files (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    scan_result TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_files_owner_id ON files(owner_id);
@@ -0,0 +1,2 @@
+-- IANA zone user facing timestamps are rendered in
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,5 @@
+DELETE FROM files WHERE owner_id IS NULL;
+DROP INDEX IF EXISTS idx_files_guest_id;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_owner_or_guest;
+ALTER TABLE files DROP COLUMN IF EXISTS guest_id;
+ALTER TABLE files ALTER COLUMN owner_id SET NOT NULL;
@@ -0,0 +1,6 @@
+-- Files uploaded by guest sessions, reassigned to the account on register or login
+ALTER TABLE files ALTER COLUMN owner_id DROP NOT NULL;
+ALTER TABLE files ADD COLUMN guest_id UUID;
+ALTER TABLE files ADD CONSTRAINT files_owner_or_guest CHECK (owner_id IS NOT NULL OR guest_id IS NOT NULL);
+
+CREATE INDEX idx_files_guest_id ON files(guest_id) WHERE guest_id IS NOT NULL;
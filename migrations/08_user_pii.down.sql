@@ -0,0 +1,12 @@
+-- Encrypted values are not decrypted here, only roll back before any row was encrypted
+DROP INDEX IF EXISTS idx_users_email_plain;
+DROP INDEX IF EXISTS idx_users_phone_bidx;
+DROP INDEX IF EXISTS idx_users_email_bidx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS phone_bidx;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+ALTER TABLE users DROP COLUMN IF EXISTS email_bidx;
+
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+CREATE INDEX idx_users_email ON users(email);
@@ -0,0 +1,14 @@
+-- Email and phone are encrypted by the application, *_bidx hold keyed hashes for equality lookups.
+-- Existing plaintext rows are encrypted with `go run ./cmd/pii rotate`.
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+
+ALTER TABLE users ADD COLUMN email_bidx VARCHAR(64);
+ALTER TABLE users ADD COLUMN phone TEXT;
+ALTER TABLE users ADD COLUMN phone_bidx VARCHAR(64);
+
+CREATE UNIQUE INDEX idx_users_email_bidx ON users(email_bidx);
+CREATE INDEX idx_users_phone_bidx ON users(phone_bidx);
+-- Rows not yet encrypted keep email uniqueness on the plaintext value
+CREATE UNIQUE INDEX idx_users_email_plain ON users(email) WHERE email_bidx IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS sms_2fa_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
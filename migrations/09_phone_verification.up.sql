@@ -0,0 +1,3 @@
+-- Phone is verified by an SMS code, SMS second factor is only honoured for a verified phone
+ALTER TABLE users ADD COLUMN phone_verified_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN sms_2fa_enabled BOOLEAN NOT NULL DEFAULT false;
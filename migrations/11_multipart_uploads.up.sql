@@ -0,0 +1,25 @@
+-- Multipart uploads in progress, parts go straight to object storage through presigned URLs
+CREATE TABLE file_uploads (
+    id UUID PRIMARY KEY,
+    upload_id VARCHAR(255) NOT NULL,
+    owner_id INT REFERENCES users(id) ON DELETE CASCADE,
+    guest_id UUID,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    part_size BIGINT NOT NULL,
+    part_count INT NOT NULL,
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    checksum_sha256 CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'uploading',
+    file_id BIGINT REFERENCES files(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT file_uploads_owner_or_guest CHECK (owner_id IS NOT NULL OR guest_id IS NOT NULL)
+);
+
+CREATE INDEX idx_file_uploads_owner_id ON file_uploads(owner_id);
+CREATE INDEX idx_file_uploads_guest_id ON file_uploads(guest_id) WHERE guest_id IS NOT NULL;
+CREATE INDEX idx_file_uploads_stale ON file_uploads(expires_at) WHERE status = 'uploading';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_social_links CASCADE;
+DROP TABLE IF EXISTS user_phones CASCADE;
+DROP TABLE IF EXISTS user_addresses CASCADE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cache_invalidation_log_table_name_id;
+
+DROP TRIGGER IF EXISTS users_cache_invalidation ON users;
+CREATE TRIGGER users_cache_invalidation AFTER UPDATE OR DELETE
+ON users FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation('id');
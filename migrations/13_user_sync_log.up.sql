@@ -0,0 +1,6 @@
+-- Log user inserts as well, the change log doubles as the source of the differential sync endpoint
+DROP TRIGGER IF EXISTS users_cache_invalidation ON users;
+CREATE TRIGGER users_cache_invalidation AFTER INSERT OR UPDATE OR DELETE
+ON users FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation('id');
+
+CREATE INDEX IF NOT EXISTS idx_cache_invalidation_log_table_name_id ON cache_invalidation_log(table_name, id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_audit_events_chain_seq;
+
+ALTER TABLE audit_events
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS chain_seq;
@@ -0,0 +1,7 @@
+-- Hash chain over audit events, rows recorded before the chain keep a NULL chain_seq and are not covered
+ALTER TABLE audit_events
+    ADD COLUMN chain_seq BIGINT,
+    ADD COLUMN prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_audit_events_chain_seq ON audit_events(chain_seq);
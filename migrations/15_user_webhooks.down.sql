@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS user_webhooks CASCADE;
@@ -0,0 +1,29 @@
+-- Callbacks users register for events on their own account, events is a comma separated list
+CREATE TABLE user_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url VARCHAR(500) NOT NULL,
+    events TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_webhooks_user_id ON user_webhooks(user_id);
+
+-- One row per delivery attempt
+CREATE TABLE user_webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES user_webhooks(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    event_id VARCHAR(36) NOT NULL,
+    event VARCHAR(100) NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    succeeded BOOLEAN NOT NULL,
+    duration_ms INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_webhook_deliveries_webhook_id ON user_webhook_deliveries(webhook_id, id DESC);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS files_blob_refs_update ON files;
+DROP TRIGGER IF EXISTS files_blob_refs ON files;
+DROP FUNCTION IF EXISTS count_file_blob_refs();
+ALTER TABLE files DROP COLUMN IF EXISTS blob_id;
+ALTER TABLE files DROP COLUMN IF EXISTS checksum_sha256;
+DROP TABLE IF EXISTS file_blobs;
@@ -0,0 +1,40 @@
+-- Stored objects shared by every file with the same content, ref_count is kept by the trigger on files
+CREATE TABLE file_blobs (
+    id BIGSERIAL PRIMARY KEY,
+    checksum_sha256 CHAR(64) NOT NULL UNIQUE,
+    bucket VARCHAR(63) NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    size BIGINT NOT NULL,
+    ref_count INT NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unreferenced blobs left by cascading deletes, removed by the sweeper
+CREATE INDEX idx_file_blobs_unreferenced ON file_blobs(id) WHERE ref_count = 0;
+
+-- Checksum is known from upload, blob_id is set once the file is clean and shares the blob object
+ALTER TABLE files ADD COLUMN checksum_sha256 CHAR(64);
+ALTER TABLE files ADD COLUMN blob_id BIGINT REFERENCES file_blobs(id);
+
+CREATE INDEX idx_files_blob_id ON files(blob_id) WHERE blob_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION count_file_blob_refs()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.blob_id IS NOT NULL THEN
+        UPDATE file_blobs SET ref_count = ref_count - 1, updated_at = now() WHERE id = OLD.blob_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.blob_id IS NOT NULL THEN
+        UPDATE file_blobs SET ref_count = ref_count + 1, updated_at = now() WHERE id = NEW.blob_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE 'plpgsql';
+
+-- Also fires for files removed with their owner account
+CREATE TRIGGER files_blob_refs AFTER INSERT OR DELETE
+ON files FOR EACH ROW EXECUTE FUNCTION count_file_blob_refs();
+
+CREATE TRIGGER files_blob_refs_update AFTER UPDATE OF blob_id
+ON files FOR EACH ROW WHEN (OLD.blob_id IS DISTINCT FROM NEW.blob_id) EXECUTE FUNCTION count_file_blob_refs();
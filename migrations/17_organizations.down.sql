@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS organization_invitations CASCADE;
+DROP TABLE IF EXISTS organization_members CASCADE;
+DROP TABLE IF EXISTS organizations CASCADE;
@@ -0,0 +1,16 @@
+-- Invitations administrators issue to sign up while registration is invite only, the token is stored hashed
+CREATE TABLE registration_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role_name VARCHAR(30) NOT NULL DEFAULT '',
+    organization_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE,
+    organization_role VARCHAR(20) NOT NULL DEFAULT '',
+    max_uses INT NOT NULL,
+    uses INT NOT NULL DEFAULT 0,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_registration_invitations_created_at ON registration_invitations(created_at);
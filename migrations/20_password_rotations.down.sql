@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS password_rotation_users CASCADE;
+DROP TABLE IF EXISTS password_rotation_campaigns CASCADE;
@@ -0,0 +1,21 @@
+-- Campaigns flag cohorts of users who have to change their password at next login
+CREATE TABLE password_rotation_campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL,
+    filter JSONB,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    cancelled_at TIMESTAMP
+);
+
+CREATE TABLE password_rotation_users (
+    campaign_id BIGINT NOT NULL REFERENCES password_rotation_campaigns(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    flagged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rotated_at TIMESTAMP,
+    PRIMARY KEY (campaign_id, user_id)
+);
+
+CREATE INDEX idx_password_rotation_users_pending ON password_rotation_users(user_id) WHERE rotated_at IS NULL;
@@ -0,0 +1,14 @@
+-- Roles granted to users on top of their own role until expires_at, revoked_at is set on expiry or early revocation
+CREATE TABLE role_grants (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    granted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_role_grants_active_user ON role_grants(user_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_role_grants_active_expiry ON role_grants(expires_at) WHERE revoked_at IS NULL;
@@ -0,0 +1,16 @@
+-- Personal access tokens users mint for themselves, only the sha256 of the token is kept. scopes is space
+-- separated, revoked_at is set when the owner revokes the token
+CREATE TABLE personal_access_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_personal_access_tokens_active_user ON personal_access_tokens(user_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,7 @@
+-- Last chained audit event removed by retention, the chain left starts right after it. A single row
+CREATE TABLE audit_chain_floor (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    chain_seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    pruned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS billing_entitlements CASCADE;
+DROP TABLE IF EXISTS billing_events CASCADE;
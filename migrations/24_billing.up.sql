@@ -0,0 +1,26 @@
+-- Billing provider events already processed, providers deliver webhooks at least once
+CREATE TABLE billing_events (
+    id VARCHAR(255) PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Entitlement of an organization or a user to a plan, one per provider subscription. event_at is the creation
+-- time of the provider event it was last changed by
+CREATE TABLE billing_entitlements (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id VARCHAR(255) NOT NULL UNIQUE,
+    customer_id VARCHAR(255) NOT NULL,
+    organization_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    plan VARCHAR(100) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    current_period_end TIMESTAMP,
+    event_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((organization_id IS NULL) <> (user_id IS NULL))
+);
+
+CREATE INDEX idx_billing_entitlements_organization_id ON billing_entitlements(organization_id) WHERE organization_id IS NOT NULL;
+CREATE INDEX idx_billing_entitlements_user_id ON billing_entitlements(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_user_search_sync ON users;
+DROP FUNCTION IF EXISTS user_search_sync();
+DROP TABLE IF EXISTS user_search CASCADE;
@@ -0,0 +1,23 @@
+-- Trigram search index of user names, kept in step with users by trigger. Searches match the lowercase name with
+-- LIKE for substrings and prefixes and with the pg_trgm % operator for similar names, both served by the GIN index
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE user_search (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    search_name TEXT NOT NULL
+);
+
+CREATE INDEX idx_user_search_name_trgm ON user_search USING gin (search_name gin_trgm_ops);
+
+CREATE FUNCTION user_search_sync() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO user_search (user_id, search_name) VALUES (NEW.id, lower(NEW.username))
+    ON CONFLICT (user_id) DO UPDATE SET search_name = EXCLUDED.search_name;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_search_sync AFTER INSERT OR UPDATE OF username ON users
+    FOR EACH ROW EXECUTE FUNCTION user_search_sync();
+
+INSERT INTO user_search (user_id, search_name) SELECT id, lower(username) FROM users;
@@ -0,0 +1,319 @@
+// Package accesslog writes served requests in a classic access log format for compliance tooling, apart from
+// the structured application logs. The file is rotated every hour and past a size, rotated files can be uploaded
+// to blob storage. Every instance uploads its own files, so the upload runs on a local ticker and not on the
+// scheduler, which runs a task on one instance only.
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+// Name of the file being written in the directory, rotated files are access-<hour>[.n].log
+const (
+	activeFile    = "access.log"
+	rotatedPrefix = "access-"
+	rotatedSuffix = ".log"
+	hourLayout    = "2006-01-02T15"
+)
+
+// Access log options, zero MaxBytes rotates hourly only and zero MaxFiles keeps every rotated file.
+// Rotated files are uploaded when Blobs is set
+type Options struct {
+	Format         string
+	Dir            string
+	MaxBytes       int64
+	MaxFiles       int
+	Blobs          storage.BlobStore
+	Bucket         string
+	Prefix         string
+	UploadInterval time.Duration
+	Clock          clock.Clock
+}
+
+// Rotating access log file, safe for concurrent use
+type Log struct {
+	opts   Options
+	format Format
+	host   string
+	logger logger.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// Bytes of the header, the file holds requests once it is longer
+	headerSize int64
+	hour       time.Time
+	closed     bool
+	// Serializes uploads of the ticker and Close
+	uploadMu sync.Mutex
+}
+
+// Open the access log in opts.Dir, a file left by an earlier run is appended to within its hour
+func New(opts Options, logger logger.Logger) (*Log, error) {
+	format, err := NewFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.New(nil)
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "accesslog.New.MkdirAll")
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	l := &Log{opts: opts, format: format, host: host, logger: logger}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write the entry, rotating the file first when its hour is over or the line would take it past MaxBytes.
+// Entries written after Close are dropped
+func (l *Log) Write(e *Entry) error {
+	line := l.format.Line(e)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	now := l.opts.Clock.Now()
+	// The first request names the hour of the file, an idle file is not rotated
+	if l.size == l.headerSize {
+		l.hour = now.UTC().Truncate(time.Hour)
+	}
+	if l.stale(now) || (l.opts.MaxBytes > 0 && l.size > l.headerSize && l.size+int64(len(line)) > l.opts.MaxBytes) {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return errors.Wrap(err, "accesslog.Write")
+}
+
+// Upload rotated files every UploadInterval until ctx is done, returns right away without blob storage
+func (l *Log) Run(ctx context.Context) {
+	if l.opts.Blobs == nil {
+		return
+	}
+	ticker := time.NewTicker(l.opts.UploadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A file of an hour without requests since is rotated here, Write would wait for the next request
+			l.mu.Lock()
+			var err error
+			if !l.closed && l.stale(l.opts.Clock.Now()) {
+				err = l.rotate()
+			}
+			l.mu.Unlock()
+			if err != nil {
+				l.logger.Errorf("accesslog: rotate: %v", err)
+			}
+			if uploaded, err := l.Upload(ctx); err != nil {
+				l.logger.Errorf("accesslog: upload: %v", err)
+			} else if uploaded > 0 {
+				l.logger.Infof("accesslog: uploaded %d file(s) to %s", uploaded, l.opts.Bucket)
+			}
+		}
+	}
+}
+
+// Put rotated files into the bucket under <Prefix><host>/ and remove them, files failing stay for the next
+// upload. Returns the number of files uploaded
+func (l *Log) Upload(ctx context.Context) (int, error) {
+	if l.opts.Blobs == nil {
+		return 0, nil
+	}
+	l.uploadMu.Lock()
+	defer l.uploadMu.Unlock()
+
+	names, err := l.rotated()
+	if err != nil {
+		return 0, err
+	}
+	uploaded := 0
+	for _, name := range names {
+		if err := l.upload(ctx, name); err != nil {
+			return uploaded, err
+		}
+		uploaded++
+	}
+	return uploaded, nil
+}
+
+// Key of a rotated file in the bucket
+func (l *Log) Key(name string) string {
+	return l.opts.Prefix + l.host + "/" + name
+}
+
+func (l *Log) upload(ctx context.Context, name string) error {
+	path := filepath.Join(l.opts.Dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "accesslog.upload.Open")
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "accesslog.upload.Stat")
+	}
+	if _, err := l.opts.Blobs.Put(ctx, l.opts.Bucket, l.Key(name), f, info.Size(), "text/plain"); err != nil {
+		return errors.Wrapf(err, "accesslog.upload.Put %s", name)
+	}
+	return errors.Wrap(os.Remove(path), "accesslog.upload.Remove")
+}
+
+// Rotate the file and upload what is left, for after the server stopped serving requests
+func (l *Log) Close(ctx context.Context) error {
+	l.mu.Lock()
+	var err error
+	if !l.closed {
+		l.closed = true
+		if l.size > l.headerSize {
+			err = l.rotate()
+		}
+		if closeErr := l.file.Close(); err == nil {
+			err = errors.Wrap(closeErr, "accesslog.Close")
+		}
+	}
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	_, err = l.Upload(ctx)
+	return err
+}
+
+// Whether the file holds requests of an hour before now
+func (l *Log) stale(now time.Time) bool {
+	return l.size > l.headerSize && now.UTC().Truncate(time.Hour).After(l.hour)
+}
+
+func (l *Log) open() error {
+	path := filepath.Join(l.opts.Dir, activeFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return errors.Wrap(err, "accesslog.open.OpenFile")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "accesslog.open.Stat")
+	}
+	l.file, l.size, l.headerSize = f, info.Size(), 0
+	// A file left by an earlier run belongs to the hour it was last written in
+	l.hour = l.opts.Clock.Now().UTC().Truncate(time.Hour)
+	if l.size > 0 {
+		l.hour = info.ModTime().UTC().Truncate(time.Hour)
+		return nil
+	}
+	if header := l.format.Header(l.opts.Clock.Now()); header != nil {
+		n, err := f.Write(header)
+		l.size += int64(n)
+		l.headerSize = l.size
+		if err != nil {
+			return errors.Wrap(err, "accesslog.open.Write")
+		}
+	}
+	return nil
+}
+
+// Move the file aside under the name of its hour and start a new one, caller holds mu
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return errors.Wrap(err, "accesslog.rotate.Close")
+	}
+	stamp := l.hour.Format(hourLayout)
+	name := rotatedPrefix + stamp + rotatedSuffix
+	for n := 1; ; n++ {
+		if _, err := os.Stat(filepath.Join(l.opts.Dir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s%s.%d%s", rotatedPrefix, stamp, n, rotatedSuffix)
+	}
+	if err := os.Rename(filepath.Join(l.opts.Dir, activeFile), filepath.Join(l.opts.Dir, name)); err != nil {
+		return errors.Wrap(err, "accesslog.rotate.Rename")
+	}
+	if err := l.prune(); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// Remove the oldest rotated files past MaxFiles
+func (l *Log) prune() error {
+	if l.opts.MaxFiles == 0 {
+		return nil
+	}
+	names, err := l.rotated()
+	if err != nil {
+		return err
+	}
+	for len(names) > l.opts.MaxFiles {
+		if err := os.Remove(filepath.Join(l.opts.Dir, names[0])); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "accesslog.prune.Remove")
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Rotated files oldest first
+func (l *Log) rotated() ([]string, error) {
+	entries, err := os.ReadDir(l.opts.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "accesslog.rotated.ReadDir")
+	}
+	type rotatedFile struct {
+		name string
+		hour string
+		seq  int
+	}
+	var files []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, rotatedPrefix) || !strings.HasSuffix(name, rotatedSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, rotatedPrefix), rotatedSuffix)
+		file := rotatedFile{name: name, hour: stamp}
+		if hour, seq, ok := strings.Cut(stamp, "."); ok {
+			file.hour = hour
+			fmt.Sscanf(seq, "%d", &file.seq) // nolint: errcheck
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].hour != files[j].hour {
+			return files[i].hour < files[j].hour
+		}
+		return files[i].seq < files[j].seq
+	})
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.name
+	}
+	return names, nil
+}
@@ -0,0 +1,162 @@
+package accesslog
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:      time.Date(2026, 10, 16, 13, 55, 36, 0, time.FixedZone("", -7*3600)),
+		RemoteIP:  "10.0.0.1",
+		User:      "ann lee",
+		Method:    "GET",
+		URI:       "/api/v1/auth/find?name=ann",
+		Proto:     "HTTP/1.1",
+		Status:    200,
+		Bytes:     2326,
+		UserAgent: `curl/8.0 "x"` + "\n",
+		Duration:  12500 * time.Microsecond,
+	}
+}
+
+func TestFormats(t *testing.T) {
+	t.Parallel()
+
+	line := func(name string, e *Entry) string {
+		format, err := NewFormat(name)
+		require.NoError(t, err)
+		return string(format.Line(e))
+	}
+
+	require.Equal(t, `10.0.0.1 - ann+lee [16/Oct/2026:13:55:36 -0700] "GET /api/v1/auth/find?name=ann HTTP/1.1" 200 2326`+"\n",
+		line(config.AccessLogCommon, testEntry()))
+	require.Equal(t, `10.0.0.1 - ann+lee [16/Oct/2026:13:55:36 -0700] "GET /api/v1/auth/find?name=ann HTTP/1.1" 200 2326 "-" "curl/8.0 \"x\"\x0a"`+"\n",
+		line(config.AccessLogCombined, testEntry()))
+	require.Equal(t, `2026-10-16 20:55:36 10.0.0.1 ann+lee GET /api/v1/auth/find name=ann 200 2326 0.013 HTTP/1.1 curl/8.0+"x"\x0a -`+"\n",
+		line(config.AccessLogW3C, testEntry()))
+
+	anonymous := testEntry()
+	anonymous.User, anonymous.Bytes, anonymous.URI = "", 0, "/"
+	require.Contains(t, line(config.AccessLogCommon, anonymous), ` - - [`)
+	require.Contains(t, line(config.AccessLogCommon, anonymous), `" 200 -`)
+	require.Contains(t, line(config.AccessLogW3C, anonymous), ` - GET / - 200 0 `)
+
+	w3c, err := NewFormat(config.AccessLogW3C)
+	require.NoError(t, err)
+	require.Equal(t, "#Version: 1.0\n#Date: 2026-10-16 20:55:36\n#Fields: "+w3cFields+"\n", string(w3c.Header(testEntry().Time)))
+
+	_, err = NewFormat("nginx")
+	require.Error(t, err)
+}
+
+func TestLog_Rotate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clk := clock.NewFrozen(time.Date(2026, 10, 16, 13, 10, 0, 0, time.UTC))
+	// Files hold two requests
+	format := mustFormat(t, config.AccessLogW3C)
+	maxBytes := int64(len(format.Header(clk.Now())) + 2*len(format.Line(testEntry())))
+	l, err := New(Options{Format: config.AccessLogW3C, Dir: dir, MaxBytes: maxBytes, MaxFiles: 2, Clock: clk}, logger.NewApiLogger(&config.Config{}))
+	require.NoError(t, err)
+
+	// A file of only the header is not rotated
+	clk.Advance(time.Hour)
+	require.NoError(t, l.Write(testEntry()))
+	require.NoError(t, l.Write(testEntry()))
+	require.Empty(t, rotatedNames(t, l))
+
+	// Past MaxBytes the file is rotated within its hour
+	require.NoError(t, l.Write(testEntry()))
+	require.Equal(t, []string{"access-2026-10-16T14.log"}, rotatedNames(t, l))
+	require.NoError(t, l.Write(testEntry()))
+	require.NoError(t, l.Write(testEntry()))
+	require.Equal(t, []string{"access-2026-10-16T14.log", "access-2026-10-16T14.1.log"}, rotatedNames(t, l))
+
+	// A new hour starts a new file and MaxFiles drops the oldest
+	clk.Advance(time.Hour)
+	require.NoError(t, l.Write(testEntry()))
+	require.Equal(t, []string{"access-2026-10-16T14.1.log", "access-2026-10-16T14.2.log"}, rotatedNames(t, l))
+
+	// Every file starts with the header
+	for _, name := range append(rotatedNames(t, l), activeFile) {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(content), "#Version: 1.0\n"), name)
+	}
+
+	// Closing rotates the file being written, later requests are dropped
+	require.NoError(t, l.Close(context.Background()))
+	require.Equal(t, []string{"access-2026-10-16T14.2.log", "access-2026-10-16T15.log"}, rotatedNames(t, l))
+	require.NoError(t, l.Write(testEntry()))
+	require.Equal(t, []string{"access-2026-10-16T14.2.log", "access-2026-10-16T15.log"}, rotatedNames(t, l))
+}
+
+func TestLog_Upload(t *testing.T) {
+	t.Parallel()
+
+	store, err := blobstore.New(t.TempDir(), "http://localhost")
+	require.NoError(t, err)
+	blobs := storage.NewLocal(store)
+
+	dir := t.TempDir()
+	clk := clock.NewFrozen(time.Date(2026, 10, 16, 13, 10, 0, 0, time.UTC))
+	opts := Options{Format: config.AccessLogCommon, Dir: dir, Blobs: blobs, Bucket: "access-logs", Prefix: "http/", UploadInterval: time.Hour, Clock: clk}
+	l, err := New(opts, logger.NewApiLogger(&config.Config{}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, l.Write(testEntry()))
+	uploaded, err := l.Upload(ctx)
+	require.NoError(t, err)
+	require.Zero(t, uploaded)
+
+	clk.Advance(time.Hour)
+	require.NoError(t, l.Write(testEntry()))
+	uploaded, err = l.Upload(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, uploaded)
+	require.Empty(t, rotatedNames(t, l))
+	require.Equal(t, string(mustFormat(t, config.AccessLogCommon).Line(testEntry())), getObject(t, blobs, l.Key("access-2026-10-16T13.log")))
+
+	// Closing uploads the file being written
+	require.NoError(t, l.Close(ctx))
+	require.Empty(t, rotatedNames(t, l))
+	require.NotEmpty(t, getObject(t, blobs, l.Key("access-2026-10-16T14.log")))
+	require.True(t, strings.HasPrefix(l.Key("x.log"), "http/"))
+}
+
+func mustFormat(t *testing.T, name string) Format {
+	format, err := NewFormat(name)
+	require.NoError(t, err)
+	return format
+}
+
+func rotatedNames(t *testing.T, l *Log) []string {
+	names, err := l.rotated()
+	require.NoError(t, err)
+	return names
+}
+
+func getObject(t *testing.T, blobs storage.BlobStore, key string) string {
+	r, err := blobs.Get(context.Background(), "access-logs", key)
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(content)
+}
@@ -0,0 +1,149 @@
+package accesslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+// Fields of the W3C extended log format lines, in the order they are written
+const w3cFields = "date time c-ip cs-username cs-method cs-uri-stem cs-uri-query sc-status sc-bytes time-taken cs-version cs(User-Agent) cs(Referer)"
+
+// Served request
+type Entry struct {
+	// When the request was received
+	Time     time.Time
+	RemoteIP string
+	// Username of the authenticated caller, empty for anonymous requests
+	User   string
+	Method string
+	// Path and query as requested
+	URI       string
+	Proto     string
+	Status    int
+	Bytes     int64
+	Referer   string
+	UserAgent string
+	Duration  time.Duration
+}
+
+// Line format of the access log
+type Format interface {
+	// Lines starting every file, nil for none
+	Header(opened time.Time) []byte
+	// Line of the entry ending in a newline
+	Line(e *Entry) []byte
+}
+
+// Format of the config name
+func NewFormat(name string) (Format, error) {
+	switch name {
+	case config.AccessLogCommon:
+		return common{}, nil
+	case config.AccessLogCombined:
+		return common{combined: true}, nil
+	case config.AccessLogW3C:
+		return w3c{}, nil
+	}
+	return nil, errors.Errorf("accesslog: unknown format %q", name)
+}
+
+// NCSA Common Log Format, combined adds the referer and user agent like Apache and nginx do
+type common struct {
+	combined bool
+}
+
+func (common) Header(time.Time) []byte {
+	return nil
+}
+
+func (f common) Line(e *Entry) []byte {
+	var b strings.Builder
+	b.WriteString(token(e.RemoteIP))
+	b.WriteString(" - ")
+	b.WriteString(token(e.User))
+	b.WriteString(e.Time.Format(" [02/Jan/2006:15:04:05 -0700] "))
+	b.WriteString(quote(e.Method + " " + e.URI + " " + e.Proto))
+	b.WriteString(" " + strconv.Itoa(e.Status) + " ")
+	if e.Bytes > 0 {
+		b.WriteString(strconv.FormatInt(e.Bytes, 10))
+	} else {
+		b.WriteString("-")
+	}
+	if f.combined {
+		b.WriteString(" " + quote(orDash(e.Referer)) + " " + quote(orDash(e.UserAgent)))
+	}
+	b.WriteString("\n")
+	return []byte(b.String())
+}
+
+// W3C extended log format, times are UTC and time-taken is in seconds
+type w3c struct{}
+
+func (w3c) Header(opened time.Time) []byte {
+	return []byte(fmt.Sprintf("#Version: 1.0\n#Date: %s\n#Fields: %s\n", opened.UTC().Format("2006-01-02 15:04:05"), w3cFields))
+}
+
+func (w3c) Line(e *Entry) []byte {
+	stem, query, _ := strings.Cut(e.URI, "?")
+	fields := []string{
+		e.Time.UTC().Format("2006-01-02 15:04:05"),
+		token(e.RemoteIP),
+		token(e.User),
+		token(e.Method),
+		token(stem),
+		token(query),
+		strconv.Itoa(e.Status),
+		strconv.FormatInt(e.Bytes, 10),
+		strconv.FormatFloat(e.Duration.Seconds(), 'f', 3, 64),
+		token(e.Proto),
+		token(e.UserAgent),
+		token(e.Referer),
+	}
+	return []byte(strings.Join(fields, " ") + "\n")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Unquoted field, spaces become + like IIS writes them and an empty value is -
+func token(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return escape(s, func(c byte) bool { return c == ' ' }, "+")
+}
+
+// Quoted field with quotes and backslashes escaped
+func quote(s string) string {
+	return `"` + escape(s, func(c byte) bool { return c == '"' || c == '\\' }, "") + `"`
+}
+
+// Escape control bytes as \xhh so a client can't forge lines, special bytes get replacement or a backslash
+func escape(s string, special func(c byte) bool, replacement string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		case special(c) && replacement != "":
+			b.WriteString(replacement)
+		case special(c):
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
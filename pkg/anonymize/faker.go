@@ -0,0 +1,129 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Hex characters of the hash kept in unique values, 48 bits keep collisions unlikely across millions of rows
+const uniqueSuffixLen = 12
+
+var (
+	adjectives = []string{
+		"amber", "brisk", "calm", "dusty", "eager", "fuzzy", "gentle", "hazel", "icy", "jolly",
+		"keen", "lucky", "mellow", "nimble", "olive", "proud", "quiet", "rusty", "sunny", "tidy",
+	}
+	nouns = []string{
+		"badger", "comet", "dolphin", "falcon", "gecko", "heron", "ibis", "jackal", "koala", "lynx",
+		"marmot", "newt", "otter", "panda", "quail", "raven", "salmon", "tapir", "urchin", "walrus",
+	}
+	streets = []string{
+		"Maple", "Oak", "Cedar", "Elm", "Birch", "Willow", "Spruce", "Aspen", "Chestnut", "Juniper",
+	}
+	streetKinds = []string{"Street", "Avenue", "Road", "Lane", "Way", "Drive"}
+)
+
+// Faker maps real values to fake ones deterministically: the same value and salt always give the same fake,
+// so a value repeated across tables stays equal after anonymization and anything joined on it keeps working.
+// Fakes are derived from an HMAC of the value, without the salt they can't be traced back by hashing guesses.
+// Empty values stay empty.
+type Faker struct {
+	salt []byte
+}
+
+// Faker constructor, salt must be at least 16 bytes
+func NewFaker(salt []byte) (*Faker, error) {
+	if len(salt) < 16 {
+		return nil, errors.New("anonymize: salt must be at least 16 bytes")
+	}
+	return &Faker{salt: salt}, nil
+}
+
+// Unique username like amber_falcon_1a2b3c4d5e6f
+func (f *Faker) Username(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("username", value)
+	return fmt.Sprintf("%s_%s_%s", pick(adjectives, sum, 0), pick(nouns, sum, 1), suffix(sum))
+}
+
+// Unique email on the reserved example.com domain, case and surrounding spaces of the input are ignored
+// like the email blind index does
+func (f *Faker) Email(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("email", value)
+	return fmt.Sprintf("%s.%s.%s@example.com", pick(adjectives, sum, 0), pick(nouns, sum, 1), suffix(sum))
+}
+
+// E.164 number in the fictional +1 555 range
+func (f *Faker) Phone(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("phone", value)
+	return fmt.Sprintf("+1555%07d", binary.BigEndian.Uint32(sum[4:8])%10_000_000)
+}
+
+// Street address line like 42 Maple Avenue
+func (f *Faker) StreetLine(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("street", value)
+	return fmt.Sprintf("%d %s %s", 1+binary.BigEndian.Uint16(sum[4:6])%999, pick(streets, sum, 0), pick(streetKinds, sum, 1))
+}
+
+// Secondary address line like Apt 12
+func (f *Faker) SecondaryLine(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("secondary", value)
+	return fmt.Sprintf("Apt %d", 1+binary.BigEndian.Uint16(sum[4:6])%300)
+}
+
+// Five digit postal code
+func (f *Faker) PostalCode(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := f.sum("postal", value)
+	return fmt.Sprintf("%05d", binary.BigEndian.Uint32(sum[4:8])%100_000)
+}
+
+// Opaque stand-in for a secret like a password, equal secrets get equal stand-ins so a recorded login
+// with the right password still succeeds on replay and one with a wrong password still fails
+func (f *Faker) Secret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "secret_" + suffix(f.sum("secret", value))
+}
+
+// Kind separates the mappings so a phone and an email that happen to be equal don't share a fake
+func (f *Faker) sum(kind string, value string) []byte {
+	mac := hmac.New(sha256.New, f.salt)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func pick(words []string, sum []byte, index int) string {
+	return words[int(sum[index])%len(words)]
+}
+
+func suffix(sum []byte) string {
+	return hex.EncodeToString(sum[8:])[:uniqueSuffixLen]
+}
@@ -0,0 +1,44 @@
+package anonymize
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaker(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewFaker([]byte("short"))
+	require.Error(t, err)
+
+	faker, err := NewFaker([]byte("staging-salt-0123456789"))
+	require.NoError(t, err)
+	other, err := NewFaker([]byte("another-salt-0123456789"))
+	require.NoError(t, err)
+
+	validate := validator.New()
+	email := faker.Email("Jane.Doe@corp.io")
+	require.NoError(t, validate.Var(email, "email"))
+	require.Equal(t, email, faker.Email(" jane.doe@CORP.io "))
+	require.NotEqual(t, email, faker.Email("john.doe@corp.io"))
+	require.NotEqual(t, email, other.Email("jane.doe@corp.io"))
+	require.NotContains(t, email, "jane")
+
+	phone := faker.Phone("+442071838750")
+	require.NoError(t, validate.Var(phone, "e164"))
+	require.Equal(t, phone, faker.Phone("+442071838750"))
+
+	require.Regexp(t, regexp.MustCompile(`^[a-z]+_[a-z]+_[0-9a-f]{12}$`), faker.Username("jdoe"))
+	require.Regexp(t, regexp.MustCompile(`^\d+ [A-Z][a-z]+ [A-Z][a-z]+$`), faker.StreetLine("221B Baker Street"))
+	require.Regexp(t, regexp.MustCompile(`^Apt \d+$`), faker.SecondaryLine("Flat 3"))
+	require.Regexp(t, regexp.MustCompile(`^\d{5}$`), faker.PostalCode("NW1 6XE"))
+	require.Regexp(t, regexp.MustCompile(`^secret_[0-9a-f]{12}$`), faker.Secret("hunter22"))
+	require.NotEqual(t, faker.Secret("hunter22"), faker.Secret("hunter23"))
+
+	for _, fake := range []string{faker.Username(""), faker.Email(" "), faker.Phone(""), faker.StreetLine(""), faker.SecondaryLine(""), faker.PostalCode(""), faker.Secret("")} {
+		require.Empty(t, fake)
+	}
+}
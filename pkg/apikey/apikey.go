@@ -0,0 +1,76 @@
+// Package apikey resolves the API keys of server clients sent as bearer tokens. Keys are loaded from the secrets
+// provider and only their sha256 digests are kept in memory.
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Configured API key, without UserEmail it is a service principal holding ServiceRole
+type Key struct {
+	Name        string
+	UserEmail   string
+	ServiceRole string
+}
+
+// Keys by digest, a nil keyring knows no keys
+type Keyring struct {
+	keys map[[sha256.Size]byte]Key
+}
+
+// Keyring of config.Bearer.APIKeys, nil when bearer auth is off or no keys are configured
+func NewFromConfig(ctx context.Context, cfg *config.Config) (*Keyring, error) {
+	if !cfg.Bearer.Enabled || len(cfg.Bearer.APIKeys) == 0 {
+		return nil, nil
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := &Keyring{keys: make(map[[sha256.Size]byte]Key, len(cfg.Bearer.APIKeys))}
+	for name, keyCfg := range cfg.Bearer.APIKeys {
+		if keyCfg.UserEmail == "" && keyCfg.ServiceRole == "" {
+			return nil, errors.Errorf("apikey: key %s needs UserEmail or ServiceRole", name)
+		}
+		value, err := provider.Get(ctx, keyCfg.KeySecret)
+		if err != nil {
+			return nil, errors.Wrapf(err, "apikey.NewFromConfig.%s", name)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, errors.Errorf("apikey: key %s is empty", name)
+		}
+		keyring.Add(Key{Name: name, UserEmail: keyCfg.UserEmail, ServiceRole: keyCfg.ServiceRole}, value)
+	}
+	return keyring, nil
+}
+
+// Register a key by its value
+func (k *Keyring) Add(key Key, value string) {
+	if k.keys == nil {
+		k.keys = make(map[[sha256.Size]byte]Key)
+	}
+	k.keys[sha256.Sum256([]byte(value))] = key
+}
+
+// Key of a bearer token
+func (k *Keyring) Lookup(token string) (Key, bool) {
+	if k == nil || token == "" {
+		return Key{}, false
+	}
+	key, ok := k.keys[sha256.Sum256([]byte(token))]
+	return key, ok
+}
@@ -0,0 +1,36 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	t.Setenv("APIKEY_TEST_BILLING", " key-1\n")
+
+	keyring, err := NewFromConfig(context.Background(), &config.Config{})
+	require.NoError(t, err)
+	require.Nil(t, keyring)
+	_, ok := keyring.Lookup("key-1")
+	require.False(t, ok)
+
+	cfg := &config.Config{Bearer: config.BearerAuth{Enabled: true, APIKeys: map[string]config.APIKey{
+		"billing": {KeySecret: "APIKEY_TEST_BILLING", ServiceRole: "billing"},
+	}}}
+	keyring, err = NewFromConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	key, ok := keyring.Lookup("key-1")
+	require.True(t, ok)
+	require.Equal(t, Key{Name: "billing", ServiceRole: "billing"}, key)
+	_, ok = keyring.Lookup("key-2")
+	require.False(t, ok)
+
+	// A key must act as someone
+	cfg.Bearer.APIKeys["billing"] = config.APIKey{KeySecret: "APIKEY_TEST_BILLING"}
+	_, err = NewFromConfig(context.Background(), cfg)
+	require.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package binder
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/idcodec"
+)
+
+// DefaultMaxBodyBytes body size limit used when none is configured
+const DefaultMaxBodyBytes = 1 << 20
+
+// Strict echo binder, json bodies are decoded with Decode, path, query and form binding is left to echo.
+// With an id codec the id codes of json bodies are decoded first
+type Binder struct {
+	maxBodyBytes int64
+	ids          *idcodec.Codec
+	fallback     echo.DefaultBinder
+}
+
+// Binder constructor, maxBodyBytes <= 0 uses DefaultMaxBodyBytes, ids may be nil
+func New(maxBodyBytes int64, ids *idcodec.Codec) *Binder {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Binder{maxBodyBytes: maxBodyBytes, ids: ids}
+}
+
+// Bind path params, query params for GET, DELETE and HEAD, then the body
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	if err := b.fallback.BindPathParams(c, i); err != nil {
+		return err
+	}
+	method := c.Request().Method
+	if method == http.MethodGet || method == http.MethodDelete || method == http.MethodHead {
+		if err := b.fallback.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	return b.BindBody(c, i)
+}
+
+// Bind request body, json strictly and everything else with the echo default binder
+func (b *Binder) BindBody(c echo.Context, i interface{}) error {
+	req := c.Request()
+	if req.ContentLength == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return b.fallback.BindBody(c, i)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, b.maxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &Error{ErrStatus: http.StatusRequestEntityTooLarge, ErrError: "request body too large"}
+		}
+		return badRequest("unreadable request body")
+	}
+	if b.ids != nil {
+		decoded, err := b.ids.DecodeJSON(body)
+		if errors.Is(err, idcodec.ErrInvalid) {
+			return err
+		}
+		// Malformed bodies are reported by Decode
+		if err == nil {
+			body = decoded
+		}
+	}
+	warnings, err := DecodeWithWarnings(body, i)
+	if err != nil {
+		return err
+	}
+	deprecation.Add(req.Context(), warnings...)
+	return nil
+}
@@ -0,0 +1,175 @@
+package binder
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/idcodec"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type item struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type base struct {
+	ID int `json:"id"`
+}
+
+type request struct {
+	base
+	Title   string            `json:"title"`
+	Timeout time.Duration     `json:"timeout"`
+	Retry   *time.Duration    `json:"retry"`
+	Level   level             `json:"level"`
+	Items   []item            `json:"items"`
+	Labels  map[string]string `json:"labels"`
+	Ignored string            `json:"-"`
+}
+
+func fieldErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+	var bindErr *Error
+	require.True(t, errors.As(err, &bindErr), "unexpected error %v", err)
+	require.Equal(t, http.StatusBadRequest, bindErr.Status())
+	return bindErr.Fields
+}
+
+func TestDecode(t *testing.T) {
+	var req request
+	err := Decode([]byte(`{"id":7,"Title":"t","timeout":"1m30s","retry":500,"level":"high","items":[{"name":"a","price":1.5}],"labels":{"k":"v"}}`), &req)
+	require.NoError(t, err)
+	require.Equal(t, 7, req.ID)
+	require.Equal(t, "t", req.Title)
+	require.Equal(t, 90*time.Second, req.Timeout)
+	require.Equal(t, time.Duration(500), *req.Retry)
+	require.Equal(t, level(2), req.Level)
+	require.Equal(t, []item{{Name: "a", Price: 1.5}}, req.Items)
+	require.Equal(t, map[string]string{"k": "v"}, req.Labels)
+}
+
+func TestDecode_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []FieldError
+	}{
+		{"unknown field", `{"title":"t","admin":true}`, []FieldError{{Field: "admin", Message: "unknown field"}}},
+		{"ignored field", `{"Ignored":"x"}`, []FieldError{{Field: "Ignored", Message: "unknown field"}}},
+		{"nested unknown field", `{"items":[{"name":"a"},{"name":"b","qty":2}]}`, []FieldError{{Field: "items[1].qty", Message: "unknown field"}}},
+		{"wrong type", `{"items":[{"price":"cheap"}],"title":1}`, []FieldError{
+			{Field: "items[0].price", Message: "expected number"},
+			{Field: "title", Message: "expected string"},
+		}},
+		{"integer overflow", `{"id":1e3}`, []FieldError{{Field: "id", Message: "expected 64 bit integer"}}},
+		{"invalid duration", `{"timeout":"soon"}`, []FieldError{{Field: "timeout", Message: "invalid duration"}}},
+		{"custom type", `{"level":"medium"}`, []FieldError{{Field: "level", Message: "unknown level"}}},
+		{"map value", `{"labels":{"k":1}}`, []FieldError{{Field: "labels.k", Message: "expected string"}}},
+		{"root type", `[]`, []FieldError{{Field: "", Message: "expected object"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req request
+			require.Equal(t, tt.want, fieldErrors(t, Decode([]byte(tt.body), &req)))
+		})
+	}
+}
+
+func TestDecode_Malformed(t *testing.T) {
+	var req request
+	require.Empty(t, fieldErrors(t, Decode([]byte(`{"title":`), &req)))
+	require.Empty(t, fieldErrors(t, Decode([]byte(`{"title":"a"}{"title":"b"}`), &req)))
+}
+
+func TestDecodeWithWarnings(t *testing.T) {
+	type legacyItem struct {
+		Name string `json:"name"`
+		SKU  string `json:"sku" deprecated:"use name"`
+	}
+	type legacyRequest struct {
+		Title   string       `json:"title"`
+		Caption *string      `json:"caption" deprecated:"use title" sunset:"2027-01-31"`
+		Items   []legacyItem `json:"items"`
+	}
+
+	var req legacyRequest
+	warnings, err := DecodeWithWarnings([]byte(`{"Caption":"c","items":[{"sku":"a"},{"name":"b"},{"sku":"c"}]}`), &req)
+	require.NoError(t, err)
+	require.Equal(t, "c", *req.Caption)
+	require.ElementsMatch(t, []deprecation.Warning{
+		{Field: "caption", Message: "use title", Sunset: "2027-01-31"},
+		{Field: "items[].sku", Message: "use name"},
+		{Field: "items[].sku", Message: "use name"},
+	}, warnings)
+
+	// Explicit nulls don't count as use
+	warnings, err = DecodeWithWarnings([]byte(`{"title":"t","caption":null}`), &req)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestBinder_Bind(t *testing.T) {
+	e := echo.New()
+	b := New(32, nil)
+
+	bind := func(body string) error {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		var req request
+		return b.Bind(&req, e.NewContext(r, httptest.NewRecorder()))
+	}
+
+	require.NoError(t, bind(`{"title":"ok"}`))
+	require.Len(t, fieldErrors(t, bind(`{"titel":"ok"}`)), 1)
+
+	var bindErr *Error
+	require.True(t, errors.As(bind(`{"title":"`+strings.Repeat("x", 64)+`"}`), &bindErr))
+	require.Equal(t, http.StatusRequestEntityTooLarge, bindErr.Status())
+}
+
+func TestBinder_BindIDs(t *testing.T) {
+	codec, err := idcodec.New(bytes.Repeat([]byte{1}, 16), nil)
+	require.NoError(t, err)
+	e := echo.New()
+	b := New(0, codec)
+
+	bind := func(body string) (request, error) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		var req request
+		return req, b.Bind(&req, e.NewContext(r, httptest.NewRecorder()))
+	}
+
+	req, err := bind(`{"id":"` + codec.Encode(7) + `","title":"t"}`)
+	require.NoError(t, err)
+	require.Equal(t, 7, req.ID)
+
+	_, err = bind(`{"id":7}`)
+	require.ErrorIs(t, err, idcodec.ErrInvalid)
+	// Strings which are no codes fail as mistyped fields
+	_, err = bind(`{"id":"forged"}`)
+	require.Len(t, fieldErrors(t, err), 1)
+}
@@ -0,0 +1,366 @@
+package binder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	fieldsCache sync.Map
+)
+
+// Decode json strictly into target, a non nil pointer.
+// Unknown fields, mismatched types and trailing data are rejected with the json path of every offending field,
+// time.Duration fields accept "1m30s" strings as well as nanoseconds, json.Unmarshaler and encoding.TextUnmarshaler
+// types are decoded by their own methods.
+func Decode(data []byte, target interface{}) error {
+	_, err := DecodeWithWarnings(data, target)
+	return err
+}
+
+// Decode like Decode and report the fields tagged deprecated which the body sets
+func DecodeWithWarnings(data []byte, target interface{}) ([]deprecation.Warning, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, errors.New("binder.Decode: target must be a non nil pointer")
+	}
+
+	tree, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrs []FieldError
+	tree = normalize(tree, rv.Type().Elem(), "", false, &fieldErrs)
+	if len(fieldErrs) > 0 {
+		sort.Slice(fieldErrs, func(i, j int) bool { return fieldErrs[i].Field < fieldErrs[j].Field })
+		return nil, badRequest("invalid request body", fieldErrs...)
+	}
+
+	normalized, err := json.Marshal(tree)
+	if err != nil {
+		return nil, badRequest("invalid request body")
+	}
+	if err = json.Unmarshal(normalized, target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, badRequest("invalid request body", FieldError{Field: typeErr.Field, Message: "expected " + typeErr.Type.String()})
+		}
+		return nil, badRequest("invalid request body", FieldError{Message: err.Error()})
+	}
+
+	var warnings []deprecation.Warning
+	collectDeprecated(tree, rv.Type().Elem(), "", &warnings)
+	return warnings, nil
+}
+
+// Parse body into a generic tree, numbers are kept as json.Number so nothing is lost before type checks
+func parse(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, badRequest(fmt.Sprintf("malformed json at offset %d: %s", syntaxErr.Offset, syntaxErr.Error()))
+		}
+		return nil, badRequest("malformed json: " + err.Error())
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, badRequest("unexpected data after json body")
+	}
+	return tree, nil
+}
+
+// Check value against type t, collecting errors with their path, and rewrite values encoding/json cannot decode itself
+func normalize(value interface{}, t reflect.Type, path string, quoted bool, errs *[]FieldError) interface{} {
+	if value == nil {
+		return nil
+	}
+	if quoted {
+		// ",string" fields hold their value inside a json string
+		if _, ok := value.(string); !ok {
+			addError(errs, path, "expected string")
+		}
+		return value
+	}
+
+	if t == durationType {
+		return normalizeDuration(value, path, errs)
+	}
+	if t.Kind() == reflect.Ptr {
+		return normalize(value, t.Elem(), path, false, errs)
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		checkCustom(value, t, path, errs)
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return value
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			addError(errs, path, "expected object")
+			return value
+		}
+		fields := structFields(t)
+		for key, v := range obj {
+			f, found := lookupField(fields, key)
+			if !found {
+				addError(errs, joinPath(path, key), "unknown field")
+				continue
+			}
+			obj[key] = normalize(v, f.typ, joinPath(path, key), f.quoted, errs)
+		}
+		return obj
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			addError(errs, path, "expected object")
+			return value
+		}
+		for key, v := range obj {
+			obj[key] = normalize(v, t.Elem(), joinPath(path, key), false, errs)
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// []byte is base64 in a string
+			if _, ok := value.(string); !ok {
+				addError(errs, path, "expected base64 string")
+			}
+			return value
+		}
+		arr, ok := value.([]interface{})
+		if !ok {
+			addError(errs, path, "expected array")
+			return value
+		}
+		if t.Kind() == reflect.Array && len(arr) > t.Len() {
+			addError(errs, path, fmt.Sprintf("expected at most %d items", t.Len()))
+		}
+		for i, v := range arr {
+			arr[i] = normalize(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i), false, errs)
+		}
+		return arr
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			addError(errs, path, "expected string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			addError(errs, path, "expected boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(json.Number)
+		if !ok {
+			addError(errs, path, "expected integer")
+		} else if _, err := strconv.ParseInt(n.String(), 10, t.Bits()); err != nil {
+			addError(errs, path, fmt.Sprintf("expected %d bit integer", t.Bits()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(json.Number)
+		if !ok {
+			addError(errs, path, "expected unsigned integer")
+		} else if _, err := strconv.ParseUint(n.String(), 10, t.Bits()); err != nil {
+			addError(errs, path, fmt.Sprintf("expected %d bit unsigned integer", t.Bits()))
+		}
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(json.Number)
+		if !ok {
+			addError(errs, path, "expected number")
+		} else if _, err := strconv.ParseFloat(n.String(), t.Bits()); err != nil {
+			addError(errs, path, "expected number")
+		}
+	}
+	return value
+}
+
+// Walk a decoded body for deprecated fields it sets, slice indexes collapse to [] so each field is reported once
+func collectDeprecated(value interface{}, t reflect.Type, path string, warnings *[]deprecation.Warning) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := structFields(t)
+		for key, v := range obj {
+			f, found := lookupField(fields, key)
+			if !found || v == nil {
+				continue
+			}
+			fieldPath := joinPath(path, f.name)
+			if f.deprecated != nil {
+				warning := *f.deprecated
+				warning.Field = fieldPath
+				*warnings = append(*warnings, warning)
+			}
+			collectDeprecated(v, f.typ, fieldPath, warnings)
+		}
+	case reflect.Map:
+		if obj, ok := value.(map[string]interface{}); ok {
+			for key, v := range obj {
+				collectDeprecated(v, t.Elem(), joinPath(path, key), warnings)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := value.([]interface{}); ok {
+			for _, v := range arr {
+				collectDeprecated(v, t.Elem(), path+"[]", warnings)
+			}
+		}
+	}
+}
+
+// Durations are written as strings like "1m30s" or as integer nanoseconds
+func normalizeDuration(value interface{}, path string, errs *[]FieldError) interface{} {
+	switch v := value.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			addError(errs, path, "invalid duration")
+			return value
+		}
+		return json.Number(strconv.FormatInt(int64(d), 10))
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err != nil {
+			addError(errs, path, "invalid duration")
+		}
+		return value
+	default:
+		addError(errs, path, "expected duration")
+		return value
+	}
+}
+
+// Run the custom unmarshaler of t on value so its error is reported with the field path
+func checkCustom(value interface{}, t reflect.Type, path string, errs *[]FieldError) {
+	target := reflect.New(t).Interface()
+	if u, ok := target.(json.Unmarshaler); ok {
+		raw, err := json.Marshal(value)
+		if err == nil {
+			err = u.UnmarshalJSON(raw)
+		}
+		if err != nil {
+			addError(errs, path, err.Error())
+		}
+		return
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		addError(errs, path, "expected string")
+		return
+	}
+	if err := target.(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		addError(errs, path, err.Error())
+	}
+}
+
+// Json visible field of a struct
+type field struct {
+	name       string
+	typ        reflect.Type
+	quoted     bool
+	deprecated *deprecation.Warning
+}
+
+// Json fields of struct type t, promoted fields of embedded structs included, as encoding/json sees them
+func structFields(t reflect.Type) []field {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, typ: sf.Type, quoted: hasOption(opts, "string")}
+		if warning, ok := deprecation.FieldWarning(sf, ""); ok {
+			f.deprecated = &warning
+		}
+		fields = append(fields, f)
+	}
+
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+// Exact name first, then case insensitive like encoding/json
+func lookupField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func addError(errs *[]FieldError, path, message string) {
+	*errs = append(*errs, FieldError{Field: path, Message: message})
+}
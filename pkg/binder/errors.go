@@ -0,0 +1,46 @@
+package binder
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Invalid field of a request body, Field is the json path, e.g. "items[2].price"
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Binding error, implements httpErrors.RestErr so handlers answer with its status and field errors
+type Error struct {
+	ErrStatus int          `json:"status"`
+	ErrError  string       `json:"error"`
+	Fields    []FieldError `json:"fields,omitempty"`
+}
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("status: %d - errors: %s", e.ErrStatus, e.ErrError)
+	}
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field+": "+f.Message)
+	}
+	return fmt.Sprintf("status: %d - errors: %s - fields: %s", e.ErrStatus, e.ErrError, strings.Join(fields, "; "))
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Field errors are part of the response body already
+func (e *Error) Causes() interface{} {
+	return e.Fields
+}
+
+func badRequest(message string, fields ...FieldError) *Error {
+	return &Error{ErrStatus: http.StatusBadRequest, ErrError: message, Fields: fields}
+}
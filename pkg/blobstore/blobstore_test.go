@@ -0,0 +1,74 @@
+package blobstore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_MultipartThroughPresignedURLs(t *testing.T) {
+	t.Parallel()
+
+	store, err := New(t.TempDir(), "")
+	require.NoError(t, err)
+	server := httptest.NewServer(store.Handler())
+	defer server.Close()
+	store.baseURL = server.URL
+
+	uploadID, err := store.NewMultipartUpload("files", "a/b.txt")
+	require.NoError(t, err)
+
+	put := func(url string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+
+	parts := make([]Part, 0, 2)
+	for i, body := range []string{"hello ", "world"} {
+		res := put(store.PresignPart(uploadID, i+1, time.Minute), body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		parts = append(parts, Part{Number: i + 1, ETag: res.Header.Get("ETag")})
+	}
+
+	expired := put(store.PresignPart(uploadID, 3, -time.Minute), "late")
+	require.Equal(t, http.StatusForbidden, expired.StatusCode)
+	forged := put(strings.Replace(store.PresignPart(uploadID, 1, time.Minute), "/parts/1", "/parts/2", 1), "x")
+	require.Equal(t, http.StatusForbidden, forged.StatusCode)
+
+	listed, err := store.ListParts(uploadID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	require.NoError(t, store.CompleteMultipartUpload(uploadID, parts))
+	_, err = store.ListParts(uploadID)
+	require.ErrorIs(t, err, ErrNoSuchUpload)
+
+	require.NoError(t, store.Move("files", "clean", "a/b.txt"))
+	object, err := store.Open("clean", "a/b.txt")
+	require.NoError(t, err)
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+}
+
+func TestStore_RejectsEscapingKeys(t *testing.T) {
+	t.Parallel()
+
+	store, err := New(t.TempDir(), "")
+	require.NoError(t, err)
+
+	_, err = store.Put("files", "../../etc/passwd", strings.NewReader("x"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = store.Put(".uploads", "key", strings.NewReader("x"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,85 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const partsPathPrefix = "/uploads/"
+
+// URL accepting a PUT of one part until expires, signed with the store secret
+func (s *Store) PresignPart(uploadID string, number int, expires time.Duration) string {
+	deadline := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", deadline)
+	query.Set("signature", s.sign(uploadID, number, deadline))
+	return fmt.Sprintf("%s%s%s/parts/%d?%s", s.baseURL, partsPathPrefix, uploadID, number, query.Encode())
+}
+
+// Handler receiving presigned part uploads, PUT /uploads/{upload_id}/parts/{part_number}
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		uploadID, number, err := parsePartPath(r.URL.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if !s.validSignature(uploadID, number, r.URL.Query()) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		etag, err := s.PutPart(uploadID, number, r.Body)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrNoSuchUpload) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (s *Store) sign(uploadID string, number int, deadline string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%d\n%s", uploadID, number, deadline)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Store) validSignature(uploadID string, number int, query url.Values) bool {
+	deadline := query.Get("expires")
+	expiresAt, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(s.sign(uploadID, number, deadline)), []byte(query.Get("signature")))
+}
+
+func parsePartPath(path string) (string, int, error) {
+	segments := strings.Split(strings.TrimPrefix(path, partsPathPrefix), "/")
+	if !strings.HasPrefix(path, partsPathPrefix) || len(segments) != 3 || segments[1] != "parts" {
+		return "", 0, errors.New("unknown path")
+	}
+	number, err := strconv.Atoi(segments[2])
+	if err != nil || number < 1 {
+		return "", 0, errors.New("invalid part number")
+	}
+	return segments[0], number, nil
+}
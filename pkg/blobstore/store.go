@@ -0,0 +1,307 @@
+package blobstore
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	uploadsDir = ".uploads"
+	targetFile = "target.json"
+)
+
+var (
+	// Object or part does not exist
+	ErrNotFound = errors.New("blobstore: not found")
+	// Multipart upload does not exist or was already completed or aborted
+	ErrNoSuchUpload = errors.New("blobstore: no such upload")
+	// Bucket or key would escape the store root
+	ErrInvalidKey = errors.New("blobstore: invalid key")
+)
+
+// Uploaded part of a multipart upload
+type Part struct {
+	Number int
+	ETag   string
+	Size   int64
+}
+
+// Object storage on the local filesystem standing in for MinIO in dev mode,
+// objects live under <root>/<bucket>/<key> and parts of multipart uploads under <root>/.uploads/<id>
+type Store struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+// Store constructor, baseURL is where Handler is served and prefixes presigned part URLs
+func New(root string, baseURL string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(root, uploadsDir), 0o755); err != nil {
+		return nil, errors.Wrap(err, "blobstore.New.MkdirAll")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "blobstore.New.rand.Read")
+	}
+	return &Store{root: root, baseURL: strings.TrimRight(baseURL, "/"), secret: secret}, nil
+}
+
+// Write object, replacing an existing one
+func (s *Store) Put(bucket string, key string, r io.Reader) (int64, error) {
+	path, err := s.objectPath(bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	return writeFile(path, r)
+}
+
+// Open object for reading
+func (s *Store) Open(bucket string, key string) (io.ReadCloser, error) {
+	path, err := s.objectPath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrap(err, "blobstore.Open")
+	}
+	return file, nil
+}
+
+// Move object to another bucket under the same key
+func (s *Store) Move(srcBucket string, dstBucket string, key string) error {
+	src, err := s.objectPath(srcBucket, key)
+	if err != nil {
+		return err
+	}
+	dst, err := s.objectPath(dstBucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return errors.Wrap(err, "blobstore.Move.MkdirAll")
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return errors.Wrap(err, "blobstore.Move.Rename")
+	}
+	return nil
+}
+
+// Remove object, a missing object is not an error
+func (s *Store) Remove(bucket string, key string) error {
+	path, err := s.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "blobstore.Remove")
+	}
+	return nil
+}
+
+// Start multipart upload of bucket/key, returns the upload id
+func (s *Store) NewMultipartUpload(bucket string, key string) (string, error) {
+	if _, err := s.objectPath(bucket, key); err != nil {
+		return "", err
+	}
+
+	uploadID := uuid.New().String()
+	dir := s.uploadPath(uploadID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", errors.Wrap(err, "blobstore.NewMultipartUpload.MkdirAll")
+	}
+	target, err := json.Marshal(upload{Bucket: bucket, Key: key})
+	if err != nil {
+		return "", errors.Wrap(err, "blobstore.NewMultipartUpload.json.Marshal")
+	}
+	if err := os.WriteFile(filepath.Join(dir, targetFile), target, 0o644); err != nil {
+		return "", errors.Wrap(err, "blobstore.NewMultipartUpload.WriteFile")
+	}
+	return uploadID, nil
+}
+
+// Store one part, returns its quoted MD5 ETag like S3 does
+func (s *Store) PutPart(uploadID string, number int, r io.Reader) (string, error) {
+	if _, err := s.readUpload(uploadID); err != nil {
+		return "", err
+	}
+
+	hash := md5.New()
+	if _, err := writeFile(s.partPath(uploadID, number), io.TeeReader(r, hash)); err != nil {
+		return "", err
+	}
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// List uploaded parts ordered by part number
+func (s *Store) ListParts(uploadID string) ([]Part, error) {
+	if _, err := s.readUpload(uploadID); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(s.uploadPath(uploadID))
+	if err != nil {
+		return nil, errors.Wrap(err, "blobstore.ListParts.ReadDir")
+	}
+
+	parts := make([]Part, 0, len(entries))
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		etag, size, err := fileETag(s.partPath(uploadID, number))
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, Part{Number: number, ETag: etag, Size: size})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+// Concatenate the given parts into the target object and drop the upload, ETags must match the stored parts
+func (s *Store) CompleteMultipartUpload(uploadID string, parts []Part) error {
+	target, err := s.readUpload(uploadID)
+	if err != nil {
+		return err
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		path := s.partPath(uploadID, part.Number)
+		etag, _, err := fileETag(path)
+		if err != nil {
+			return err
+		}
+		if etag != part.ETag {
+			return errors.Errorf("blobstore: part %d etag mismatch", part.Number)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return errors.Wrap(err, "blobstore.CompleteMultipartUpload.Open")
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+
+	if _, err := s.Put(target.Bucket, target.Key, io.MultiReader(readers...)); err != nil {
+		return err
+	}
+	return s.AbortMultipartUpload(uploadID)
+}
+
+// Drop multipart upload and its parts, ErrNoSuchUpload when it is already gone
+func (s *Store) AbortMultipartUpload(uploadID string) error {
+	if _, err := s.readUpload(uploadID); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.uploadPath(uploadID)); err != nil {
+		return errors.Wrap(err, "blobstore.AbortMultipartUpload.RemoveAll")
+	}
+	return nil
+}
+
+// Multipart upload target
+type upload struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+func (s *Store) readUpload(uploadID string) (*upload, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, ErrNoSuchUpload
+	}
+	data, err := os.ReadFile(filepath.Join(s.uploadPath(uploadID), targetFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSuchUpload
+		}
+		return nil, errors.Wrap(err, "blobstore.readUpload.ReadFile")
+	}
+	target := &upload{}
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, errors.Wrap(err, "blobstore.readUpload.json.Unmarshal")
+	}
+	return target, nil
+}
+
+func (s *Store) objectPath(bucket string, key string) (string, error) {
+	if bucket == "" || key == "" || strings.ContainsAny(bucket, `/\`) || strings.HasPrefix(bucket, ".") {
+		return "", ErrInvalidKey
+	}
+	bucketDir := filepath.Join(s.root, bucket)
+	path := filepath.Join(bucketDir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, bucketDir+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return path, nil
+}
+
+func (s *Store) uploadPath(uploadID string) string {
+	return filepath.Join(s.root, uploadsDir, uploadID)
+}
+
+func (s *Store) partPath(uploadID string, number int) string {
+	return filepath.Join(s.uploadPath(uploadID), strconv.Itoa(number))
+}
+
+// Write through a temporary file so readers never see a partial object
+func writeFile(path string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, errors.Wrap(err, "blobstore.writeFile.MkdirAll")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return 0, errors.Wrap(err, "blobstore.writeFile.CreateTemp")
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "blobstore.writeFile.Copy")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, errors.Wrap(err, "blobstore.writeFile.Rename")
+	}
+	return written, nil
+}
+
+func fileETag(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", 0, ErrNotFound
+		}
+		return "", 0, errors.Wrap(err, "blobstore.fileETag.Open")
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "blobstore.fileETag.Copy")
+	}
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil))), size, nil
+}
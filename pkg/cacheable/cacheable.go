@@ -0,0 +1,107 @@
+// Package cacheable is the runtime of the caching usecase decorators `go run ./cmd/gen decorator` writes for
+// interfaces with methods annotated in their doc comment:
+//
+//	cache:key=plans:{userID}        cache the result under the key, {name} and {name.Field} are parameters
+//	cache:ttl=60                    seconds the result is kept, the cache default when missing
+//	cache:invalidate=plans:{userID} events dropping the result, comma separated
+//	cache:emit=plans:{userID}       events a method raises once it succeeded, comma separated
+//
+// A result loaded while an event is raised may be cached stale, for its ttl at most
+package cacheable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// Lookup results, the result label of the cache lookups metric
+const (
+	ResultFresh = "fresh"
+	ResultMiss  = "miss"
+)
+
+// Cached results by key with the events invalidating them
+type Store interface {
+	// Nil without error for missing keys
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, events []string) error
+	// Drop the results of every key set with one of events
+	Invalidate(ctx context.Context, events []string) error
+}
+
+// Cache shared by the decorated usecases. Store failures are logged and the usecase is called as if
+// there was no cache
+type Cache struct {
+	store   Store
+	ttl     time.Duration
+	metrics metric.Metrics
+	logger  logger.Logger
+}
+
+// Cache constructor, ttl applies to methods not annotated with cache:ttl
+func New(store Store, ttl time.Duration, metrics metric.Metrics, logger logger.Logger) *Cache {
+	return &Cache{store: store, ttl: ttl, metrics: metrics, logger: logger}
+}
+
+// Key or event rendered from its template, literals and parameter values in order
+func Key(parts ...interface{}) string {
+	var b strings.Builder
+	for _, part := range parts {
+		fmt.Fprint(&b, part)
+	}
+	return b.String()
+}
+
+// Result of method for key, loaded and cached when missing. A zero ttl is the cache default
+func Load[T any](ctx context.Context, c *Cache, method string, key string, ttl time.Duration, events []string, load func() (T, error)) (T, error) {
+	key = method + ":" + key
+	cached, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.logger.Errorf("cacheable.Load.Get method: %s, error: %v", method, err)
+	}
+	if cached != nil {
+		var result T
+		if err := json.Unmarshal(cached, &result); err == nil {
+			c.count(method, ResultFresh)
+			return result, nil
+		}
+		c.logger.Errorf("cacheable.Load.Unmarshal method: %s, error: %v", method, err)
+	}
+	c.count(method, ResultMiss)
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		c.logger.Errorf("cacheable.Load.Marshal method: %s, error: %v", method, err)
+		return result, nil
+	}
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	if err := c.store.Set(ctx, key, encoded, ttl, events); err != nil {
+		c.logger.Errorf("cacheable.Load.Set method: %s, error: %v", method, err)
+	}
+	return result, nil
+}
+
+// Raise events, dropping the results they invalidate
+func (c *Cache) Emit(ctx context.Context, events ...string) {
+	if err := c.store.Invalidate(ctx, events); err != nil {
+		c.logger.Errorf("cacheable.Emit.Invalidate events: %v, error: %v", events, err)
+	}
+}
+
+func (c *Cache) count(method string, result string) {
+	if c.metrics != nil {
+		c.metrics.IncCacheLookups(method, result)
+	}
+}
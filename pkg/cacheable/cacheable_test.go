@@ -0,0 +1,101 @@
+package cacheable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+type lookupMetrics struct {
+	metric.Metrics
+	counts map[string]int
+}
+
+func (m *lookupMetrics) IncCacheLookups(cache, result string) {
+	m.counts[cache+" "+result]++
+}
+
+type plan struct {
+	Name  string `json:"name"`
+	Seats int    `json:"seats"`
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	metrics := &lookupMetrics{counts: map[string]int{}}
+	cache := New(NewRedisStore(client, "test:"), time.Minute, metrics, appLogger)
+	ctx := context.Background()
+
+	calls := 0
+	load := func(userID int) (*plan, error) {
+		return Load(ctx, cache, "billing.Plan", Key("plan:", userID), 0, []string{Key("plans:", userID), "plans"}, func() (*plan, error) {
+			calls++
+			return &plan{Name: "pro", Seats: userID}, nil
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := load(7)
+		require.NoError(t, err)
+		require.Equal(t, &plan{Name: "pro", Seats: 7}, result)
+	}
+	require.Equal(t, 1, calls)
+	require.Equal(t, map[string]int{"billing.Plan miss": 1, "billing.Plan fresh": 1}, metrics.counts)
+	require.Equal(t, time.Minute, server.TTL("test:billing.Plan:plan:7"))
+
+	// Events of other users leave the result
+	_, err := load(8)
+	require.NoError(t, err)
+	cache.Emit(ctx, "plans:8")
+	_, err = load(7)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	cache.Emit(ctx, "plans")
+	_, err = load(7)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	// Errors are not cached
+	failing := errors.New("billing down")
+	for i := 0; i < 2; i++ {
+		_, err = Load(ctx, cache, "billing.Plan", "failing", time.Second, nil, func() (*plan, error) {
+			calls++
+			return nil, failing
+		})
+		require.ErrorIs(t, err, failing)
+	}
+	require.Equal(t, 5, calls)
+
+	// Without redis the usecase still answers
+	server.Close()
+	_, err = load(7)
+	require.NoError(t, err)
+	require.Equal(t, 6, calls)
+	cache.Emit(ctx, "plans")
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "plans:7:pro", Key("plans:", 7, ":", "pro"))
+	require.Equal(t, "", Key())
+}
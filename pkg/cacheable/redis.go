@@ -0,0 +1,90 @@
+package cacheable
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPrefix = "api-cache:"
+	eventsKey     = "event:"
+)
+
+// Write the result and add its key to the set of every event, sets live as long as their longest result
+var setScript = redis.NewScript(`
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+for i = 2, #KEYS do
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if redis.call("PTTL", KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call("PEXPIRE", KEYS[i], ARGV[2])
+	end
+end
+`)
+
+// Drop the results in the sets of the events and the sets
+var invalidateScript = redis.NewScript(`
+for i = 1, #KEYS do
+	local keys = redis.call("SMEMBERS", KEYS[i])
+	for _, key in ipairs(keys) do
+		redis.call("DEL", key)
+	end
+	redis.call("DEL", KEYS[i])
+end
+`)
+
+// Redis store, results are strings and events sets of the keys they invalidate
+type redisStore struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Redis store constructor, an empty prefix uses api-cache:
+func NewRedisStore(redisClient *redis.Client, prefix string) Store {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &redisStore{redisClient: redisClient, prefix: prefix}
+}
+
+// Get result
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.redisClient.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "redisStore.Get")
+	}
+	return value, nil
+}
+
+// Set result for ttl, invalidated by events
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, events []string) error {
+	keys := append([]string{s.prefix + key}, s.eventKeys(events)...)
+	if err := setScript.Run(ctx, s.redisClient, keys, value, ttl.Milliseconds()).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return errors.Wrap(err, "redisStore.Set.setScript.Run")
+	}
+	return nil
+}
+
+// Invalidate results of events
+func (s *redisStore) Invalidate(ctx context.Context, events []string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := invalidateScript.Run(ctx, s.redisClient, s.eventKeys(events)).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return errors.Wrap(err, "redisStore.Invalidate.invalidateScript.Run")
+	}
+	return nil
+}
+
+func (s *redisStore) eventKeys(events []string) []string {
+	keys := make([]string, 0, len(events))
+	for _, event := range events {
+		keys = append(keys, s.prefix+eventsKey+event)
+	}
+	return keys
+}
@@ -0,0 +1,77 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Source of current time, injected so tests can freeze it
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+}
+
+// Wall clock reporting time in the storage location
+type realClock struct {
+	loc *time.Location
+}
+
+// Wall clock constructor, nil location means UTC
+func New(loc *time.Location) Clock {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return realClock{loc: loc}
+}
+
+func (c realClock) Now() time.Time {
+	return time.Now().In(c.loc)
+}
+
+func (c realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (c realClock) Until(t time.Time) time.Duration {
+	return time.Until(t)
+}
+
+// Clock standing still until moved by Set or Advance
+type Frozen struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// Frozen clock constructor
+func NewFrozen(now time.Time) *Frozen {
+	return &Frozen{now: now}
+}
+
+func (c *Frozen) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+func (c *Frozen) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *Frozen) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Move clock to given time
+func (c *Frozen) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Move clock forward
+func (c *Frozen) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Canonical storage zone and fallback zone of user facing timestamps
+type Zones struct {
+	Storage     *time.Location
+	DefaultUser *time.Location
+}
+
+// Zones constructor from IANA names, empty names mean UTC
+func NewZones(storage string, defaultUser string) (*Zones, error) {
+	storageLoc, err := time.LoadLocation(storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "clock.NewZones.storage")
+	}
+	defaultUserLoc, err := time.LoadLocation(defaultUser)
+	if err != nil {
+		return nil, errors.Wrap(err, "clock.NewZones.defaultUser")
+	}
+	return &Zones{Storage: storageLoc, DefaultUser: defaultUserLoc}, nil
+}
+
+// Convert stored timestamp to the user zone, unknown or empty zones fall back to DefaultUser.
+// Timestamps without zone read back from the database carry storage wall time labeled UTC, so they are reinterpreted first.
+func (z *Zones) ToUser(t time.Time, tz string) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if t.Location() == time.UTC && z.Storage != time.UTC {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), z.Storage)
+	}
+	return t.In(z.userLocation(tz))
+}
+
+func (z *Zones) userLocation(tz string) *time.Location {
+	if tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return z.DefaultUser
+}
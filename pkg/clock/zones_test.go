@@ -0,0 +1,26 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZones_ToUser(t *testing.T) {
+	t.Parallel()
+
+	zones, err := NewZones("Europe/Berlin", "UTC")
+	require.NoError(t, err)
+
+	// 12:00 Berlin wall time as scanned from a timestamp column
+	stored := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "2024-01-15T11:00:00Z", zones.ToUser(stored, "").Format(time.RFC3339))
+	require.Equal(t, "2024-01-15T06:00:00-05:00", zones.ToUser(stored, "America/New_York").Format(time.RFC3339))
+	require.Equal(t, "2024-01-15T11:00:00Z", zones.ToUser(stored, "Not/AZone").Format(time.RFC3339))
+	require.True(t, zones.ToUser(time.Time{}, "America/New_York").IsZero())
+
+	_, err = NewZones("Not/AZone", "UTC")
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Package compaction runs cleanup jobs on the scheduler. A job removes what outlived its retention, on a dry run
+// it only counts it, and every run is counted per job in the metrics.
+package compaction
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/scheduler"
+)
+
+// Run results counted in the metrics
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Cleanup job, returns the number of items removed or, on a dry run, the number which would be
+type Job func(ctx context.Context, dryRun bool) (int, error)
+
+// Runs cleanup jobs, all of them dry when dryRun is set
+type Runner struct {
+	sched   *scheduler.Scheduler
+	dryRun  bool
+	metrics metric.Metrics
+	logger  logger.Logger
+}
+
+// Runner constructor, metrics may be nil
+func New(sched *scheduler.Scheduler, dryRun bool, metrics metric.Metrics, logger logger.Logger) *Runner {
+	return &Runner{sched: sched, dryRun: dryRun, metrics: metrics, logger: logger}
+}
+
+// Register job running every interval
+func (r *Runner) Every(name string, interval time.Duration, job Job) {
+	r.sched.Every(name, interval, func(ctx context.Context) error {
+		return r.Run(ctx, name, job)
+	})
+}
+
+// Run job once, items counted before a failure are still reported
+func (r *Runner) Run(ctx context.Context, name string, job Job) error {
+	count, err := job(ctx, r.dryRun)
+
+	if r.metrics != nil {
+		result := ResultOK
+		if err != nil {
+			result = ResultError
+		}
+		r.metrics.IncCompactionRuns(name, result)
+		if count > 0 {
+			r.metrics.AddCompactedItems(name, r.dryRun, count)
+		}
+	}
+	if count > 0 {
+		if r.dryRun {
+			r.logger.Infof("compaction %s would remove %d items, dry run", name, count)
+		} else {
+			r.logger.Infof("compaction %s removed %d items", name, count)
+		}
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package compaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+type compactionMetrics struct {
+	metric.Metrics
+	runs  map[string]int
+	items map[string]int
+}
+
+func (m *compactionMetrics) IncCompactionRuns(job, result string) {
+	m.runs[job+" "+result]++
+}
+
+func (m *compactionMetrics) AddCompactedItems(job string, dryRun bool, count int) {
+	mode := "removed"
+	if dryRun {
+		mode = "dry_run"
+	}
+	m.items[job+" "+mode] += count
+}
+
+func TestRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Logger: config.Logger{Development: true, Level: "error", Encoding: "console"}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	ctx := context.Background()
+
+	var dryRuns []bool
+	job := func(_ context.Context, dryRun bool) (int, error) {
+		dryRuns = append(dryRuns, dryRun)
+		return 3, nil
+	}
+	failing := func(context.Context, bool) (int, error) {
+		return 1, errors.New("store unavailable")
+	}
+
+	metrics := &compactionMetrics{runs: map[string]int{}, items: map[string]int{}}
+	runner := New(nil, false, metrics, appLogger)
+	require.NoError(t, runner.Run(ctx, "sessions", job))
+	require.Error(t, runner.Run(ctx, "audit", failing))
+
+	dry := New(nil, true, metrics, appLogger)
+	require.NoError(t, dry.Run(ctx, "sessions", job))
+
+	require.Equal(t, []bool{false, true}, dryRuns)
+	require.Equal(t, map[string]int{"sessions ok": 2, "audit error": 1}, metrics.runs)
+	// Items counted before a failure are reported
+	require.Equal(t, map[string]int{"sessions removed": 3, "sessions dry_run": 3, "audit removed": 1}, metrics.items)
+
+	// Without metrics jobs still run
+	require.NoError(t, New(nil, false, nil, appLogger).Run(ctx, "sessions", job))
+}
@@ -0,0 +1,100 @@
+// Package consistency carries read-your-writes tokens through a request. A token is the WAL position of the
+// primary after a write, answered to the client which sends it back on its next requests, whose reads may then
+// only be served by a replica that has replayed at least that far.
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Header answering the token of the writes of a request and carrying it on later requests
+const HeaderToken = "X-Consistency-Token"
+
+// Position in the write ahead log, a Postgres LSN
+type LSN uint64
+
+// Parse a Postgres LSN as printed by pg_current_wal_lsn, e.g. "16/B374D848"
+func ParseLSN(s string) (LSN, error) {
+	high, low, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, errors.Errorf("consistency: malformed LSN %q", s)
+	}
+	h, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "consistency: malformed LSN %q", s)
+	}
+	l, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "consistency: malformed LSN %q", s)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// Postgres notation of the LSN
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint64(l)>>32, uint64(l)&0xFFFFFFFF)
+}
+
+// Positions a request has to read at and has written up to
+type State struct {
+	required LSN
+
+	mu      sync.Mutex
+	written LSN
+}
+
+type stateCtxKey struct{}
+
+// Copy of ctx tracking its writes, reads have to see at least required, zero when the request carried no token
+func With(ctx context.Context, required LSN) (context.Context, *State) {
+	state := &State{required: required}
+	return context.WithValue(ctx, stateCtxKey{}, state), state
+}
+
+// Position reads of ctx have to see, false when it carries no token
+func Required(ctx context.Context) (LSN, bool) {
+	state, ok := ctx.Value(stateCtxKey{}).(*State)
+	if !ok || state.required == 0 {
+		return 0, false
+	}
+	return state.required, true
+}
+
+// Whether writes of ctx are tracked
+func Tracked(ctx context.Context) bool {
+	_, ok := ctx.Value(stateCtxKey{}).(*State)
+	return ok
+}
+
+// Record a write of ctx, the primary was at position after it
+func Wrote(ctx context.Context, position LSN) {
+	state, ok := ctx.Value(stateCtxKey{}).(*State)
+	if !ok {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if position > state.written {
+		state.written = position
+	}
+}
+
+// Token of the request, the furthest of what it wrote and what it was required to read, false without either
+func (s *State) Token() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position := s.written
+	if s.required > position {
+		position = s.required
+	}
+	if position == 0 {
+		return "", false
+	}
+	return position.String(), true
+}
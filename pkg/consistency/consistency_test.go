@@ -0,0 +1,56 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLSN(t *testing.T) {
+	t.Parallel()
+
+	lsn, err := ParseLSN("16/B374D848")
+	require.NoError(t, err)
+	require.Equal(t, LSN(0x16_B374D848), lsn)
+	require.Equal(t, "16/B374D848", lsn.String())
+	require.Equal(t, "0/0", LSN(0).String())
+
+	for _, malformed := range []string{"", "16", "16/", "G/1", "1/100000000"} {
+		_, err = ParseLSN(malformed)
+		require.Error(t, err, malformed)
+	}
+}
+
+func TestState(t *testing.T) {
+	t.Parallel()
+
+	// Untracked contexts require nothing and drop writes
+	_, ok := Required(context.Background())
+	require.False(t, ok)
+	require.False(t, Tracked(context.Background()))
+	Wrote(context.Background(), 1)
+
+	ctx, state := With(context.Background(), 0)
+	require.True(t, Tracked(ctx))
+	_, ok = Required(ctx)
+	require.False(t, ok)
+	_, ok = state.Token()
+	require.False(t, ok)
+
+	// The token is the furthest position written
+	Wrote(ctx, 0x20)
+	Wrote(ctx, 0x10)
+	token, ok := state.Token()
+	require.True(t, ok)
+	require.Equal(t, "0/20", token)
+
+	// A request which did not write passes on the position it had to read
+	ctx, state = With(context.Background(), 0x30)
+	required, ok := Required(ctx)
+	require.True(t, ok)
+	require.Equal(t, LSN(0x30), required)
+	Wrote(ctx, 0x20)
+	token, _ = state.Token()
+	require.Equal(t, "0/30", token)
+}
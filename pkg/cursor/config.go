@@ -0,0 +1,47 @@
+package cursor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/pii"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Key version used when no keys secret is configured
+const derivedKeyVersion = 0
+
+// HMAC signer from app config. Pagination.CursorKeysSecret holds "1:<base64>,2:<base64>" like the PII keys,
+// without it a key derived from the JWT secret is used so every instance issues the same cursors.
+func NewFromConfig(ctx context.Context, cfg *config.Config) (*Signer, error) {
+	if cfg.Pagination.CursorKeysSecret == "" {
+		if cfg.Server.JwtSecretKey == "" {
+			return nil, errors.New("cursor: no signing key, set Pagination.CursorKeysSecret or Server.JwtSecretKey")
+		}
+		mac := hmac.New(sha256.New, []byte(cfg.Server.JwtSecretKey))
+		mac.Write([]byte("pagination cursor"))
+		return NewSigner(map[int][]byte{derivedKeyVersion: mac.Sum(nil)}, derivedKeyVersion)
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawKeys, err := provider.Get(ctx, cfg.Pagination.CursorKeysSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "cursor.NewFromConfig.keys")
+	}
+	keys, err := pii.ParseKeys(rawKeys)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(keys, cfg.Pagination.CursorActiveKey)
+}
@@ -0,0 +1,138 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Token format version, bumped when the layout changes, older versions stay decodable for a release
+const version = "1"
+
+// Reasons a cursor is rejected, logged only, clients just see Code
+const (
+	ReasonMalformed   = "malformed"
+	ReasonVersion     = "unsupported_version"
+	ReasonUnknownKey  = "unknown_key"
+	ReasonSignature   = "bad_signature"
+	ReasonBadContents = "bad_contents"
+)
+
+// Rejected cursor, implements httpErrors.RestErr so it is answered with 400 and a stable code clients can match on
+// to restart paging from the beginning
+type Error struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+	Reason    string `json:"-"`
+}
+
+// Matched with errors.Is whatever the reason
+var ErrInvalid = &Error{ErrStatus: http.StatusBadRequest, ErrError: "Invalid cursor", Code: "invalid_cursor"}
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s - reason: %s", e.ErrStatus, e.ErrError, e.Reason)
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Cursor errors carry no causes, the reason stays in logs
+func (e *Error) Causes() interface{} {
+	return nil
+}
+
+// Any cursor error is ErrInvalid
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func invalid(reason string) error {
+	return &Error{ErrStatus: ErrInvalid.ErrStatus, ErrError: ErrInvalid.ErrError, Code: ErrInvalid.Code, Reason: reason}
+}
+
+// Signer issues opaque pagination cursors: v<version>.<key version>.<base64 payload>.<base64 HMAC-SHA256>.
+// The MAC covers the scope, a string describing the listing and its filters, so a cursor issued for one filter set
+// fails verification on another. Older key versions keep verifying until they are removed, new cursors use the active one.
+type Signer struct {
+	keys   map[int][]byte
+	active int
+}
+
+// Signer constructor, keys must be at least 32 bytes and the active version must be present
+func NewSigner(keys map[int][]byte, active int) (*Signer, error) {
+	for keyVersion, key := range keys {
+		if len(key) < 32 {
+			return nil, errors.Errorf("cursor: key v%d must be at least 32 bytes", keyVersion)
+		}
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, errors.Errorf("cursor: active key v%d not configured", active)
+	}
+	return &Signer{keys: keys, active: active}, nil
+}
+
+// Encode payload as a cursor valid for scope only
+func (s *Signer) Encode(scope string, payload interface{}) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "cursor.Encode.json.Marshal")
+	}
+	body := "v" + version + "." + strconv.Itoa(s.active) + "." + base64.RawURLEncoding.EncodeToString(raw)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(s.keys[s.active], body, scope)), nil
+}
+
+// Verify cursor against scope and decode its payload, every failure is ErrInvalid
+func (s *Signer) Decode(token string, scope string, payload interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || !strings.HasPrefix(parts[0], "v") {
+		return invalid(ReasonMalformed)
+	}
+	if parts[0] != "v"+version {
+		return invalid(ReasonVersion)
+	}
+	keyVersion, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return invalid(ReasonMalformed)
+	}
+	key, ok := s.keys[keyVersion]
+	if !ok {
+		return invalid(ReasonUnknownKey)
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return invalid(ReasonMalformed)
+	}
+	body := strings.Join(parts[:3], ".")
+	if !hmac.Equal(sum, s.mac(key, body, scope)) {
+		return invalid(ReasonSignature)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return invalid(ReasonMalformed)
+	}
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return invalid(ReasonBadContents)
+	}
+	return nil
+}
+
+func (s *Signer) mac(key []byte, body string, scope string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	mac.Write([]byte{0})
+	mac.Write([]byte(scope))
+	return mac.Sum(nil)
+}
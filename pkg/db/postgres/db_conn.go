@@ -17,9 +17,9 @@ const (
 	connMaxIdleTime = 20
 )
 
-// Return new Postgresql db instance
-func NewPsqlDB(c *config.Config) (*sqlx.DB, error) {
-	dataSourceName := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s search_path=%s",
+// Build Postgresql connection string from config
+func DataSourceName(c *config.Config) string {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s search_path=%s",
 		c.Postgres.PostgresqlHost,
 		c.Postgres.PostgresqlPort,
 		c.Postgres.PostgresqlUser,
@@ -27,8 +27,15 @@ func NewPsqlDB(c *config.Config) (*sqlx.DB, error) {
 		c.Postgres.PostgresqlPassword,
 		c.Postgres.DefaultSchema,
 	)
+	if c.Clock.StorageTimezone != "" {
+		dsn += " timezone=" + c.Clock.StorageTimezone
+	}
+	return dsn
+}
 
-	db, err := sqlx.Connect(c.Postgres.PgDriver, dataSourceName)
+// Return new Postgresql db instance
+func NewPsqlDB(c *config.Config) (*sqlx.DB, error) {
+	db, err := sqlx.Connect(c.Postgres.PgDriver, DataSourceName(c))
 	if err != nil {
 		return nil, err
 	}
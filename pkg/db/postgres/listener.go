@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	listenerMinBackoff = 1
+	listenerMaxBackoff = 30
+)
+
+// Notification payload handler
+type NotificationHandler func(ctx context.Context, payload string) error
+
+// Reconnect handler, called after every successful (re)connection so missed notifications can be backfilled
+type ReconnectHandler func(ctx context.Context) error
+
+// Postgresql LISTEN/NOTIFY listener with reconnect
+type Listener struct {
+	cfg         *config.Config
+	channel     string
+	onNotify    NotificationHandler
+	onReconnect ReconnectHandler
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	logger      logger.Logger
+}
+
+// Listener constructor
+func NewListener(
+	cfg *config.Config,
+	channel string,
+	onNotify NotificationHandler,
+	onReconnect ReconnectHandler,
+	logger logger.Logger,
+) *Listener {
+	minBackoff := time.Duration(cfg.ChangeFeed.ReconnectMinBackoff) * time.Second
+	if minBackoff <= 0 {
+		minBackoff = listenerMinBackoff * time.Second
+	}
+	maxBackoff := time.Duration(cfg.ChangeFeed.ReconnectMaxBackoff) * time.Second
+	if maxBackoff < minBackoff {
+		maxBackoff = listenerMaxBackoff * time.Second
+	}
+
+	return &Listener{
+		cfg:         cfg,
+		channel:     channel,
+		onNotify:    onNotify,
+		onReconnect: onReconnect,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+		logger:      logger,
+	}
+}
+
+// Listen until ctx is done, reconnecting with exponential backoff
+func (l *Listener) Run(ctx context.Context) {
+	backoff := l.minBackoff
+	for {
+		err := l.listen(ctx, func() { backoff = l.minBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+
+		l.logger.Errorf("Listener channel: %s, Error: %s, Reconnect in: %s", l.channel, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > l.maxBackoff {
+			backoff = l.maxBackoff
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, onConnected func()) error {
+	conn, err := pgx.Connect(ctx, DataSourceName(l.cfg))
+	if err != nil {
+		return errors.Wrap(err, "Listener.pgx.Connect")
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return errors.Wrap(err, "Listener.conn.Exec.LISTEN")
+	}
+	onConnected()
+	l.logger.Infof("Listener connected, channel: %s", l.channel)
+
+	// LISTEN is active before backfill runs, so nothing committed in between can be lost
+	if l.onReconnect != nil {
+		if err = l.onReconnect(ctx); err != nil {
+			l.logger.Errorf("Listener channel: %s, backfill Error: %s", l.channel, err)
+		}
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Listener.conn.WaitForNotification")
+		}
+		if err = l.onNotify(ctx, notification.Payload); err != nil {
+			l.logger.Errorf("Listener channel: %s, Payload: %s, Error: %s", l.channel, notification.Payload, err)
+		}
+	}
+}
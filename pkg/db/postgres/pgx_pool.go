@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+const (
+	// Repository backends selectable with postgres.Backend
+	BackendSqlx    = "sqlx"
+	BackendPgxPool = "pgxpool"
+
+	pgxPoolMaxConns        = 60
+	pgxPoolMinConns        = 5
+	pgxPoolConnMaxLifetime = 120
+	pgxPoolConnMaxIdleTime = 20
+	pgxPoolConnectTimeout  = 5
+)
+
+// Return new pgx native connection pool
+func NewPgxPool(c *config.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(DataSourceName(c))
+	if err != nil {
+		return nil, err
+	}
+
+	poolCfg.MaxConns = pgxPoolMaxConns
+	if c.Postgres.MaxConns > 0 {
+		poolCfg.MaxConns = c.Postgres.MaxConns
+	}
+	poolCfg.MinConns = pgxPoolMinConns
+	poolCfg.MaxConnLifetime = pgxPoolConnMaxLifetime * time.Second
+	poolCfg.MaxConnIdleTime = pgxPoolConnMaxIdleTime * time.Second
+	// pgx caches a prepared statement per query by default, poolers in transaction mode don't keep them so
+	// queries are sent unprepared unless prepared statements are enabled
+	if !c.Postgres.Statements.Enabled {
+		poolCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pgxPoolConnectTimeout*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err = pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
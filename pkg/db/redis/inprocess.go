@@ -0,0 +1,22 @@
+package redis
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// Returns client of a redis server running inside the process, for dev mode only, data is lost on exit
+func NewInProcessRedisClient() (*redis.Client, func(), error) {
+	server, err := miniredis.Run()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "miniredis.Run")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	closer := func() {
+		client.Close()
+		server.Close()
+	}
+	return client, closer, nil
+}
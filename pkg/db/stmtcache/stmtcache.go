@@ -0,0 +1,109 @@
+// Package stmtcache reuses prepared statements for hot sqlc queries. Without it every call parses and plans the
+// query again, prepared once per pool the statement is re-prepared by database/sql only on connections which
+// have not seen it. Poolers multiplexing transactions over server connections, e.g. pgbouncer in transaction
+// mode, lose prepared statements between calls, so it is off unless configured.
+package stmtcache
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
+)
+
+// Queries to prepare by sqlc name, a nil Preparer prepares nothing
+type Preparer struct {
+	names map[string]bool
+}
+
+// Preparer from app config, nil when prepared statements are disabled
+func NewFromConfig(cfg *config.Config) *Preparer {
+	statements := cfg.Postgres.Statements
+	if !statements.Enabled {
+		return nil
+	}
+	return New(statements.Queries)
+}
+
+// Preparer of the queries named by their sqlc "-- name:" annotation
+func New(names []string) *Preparer {
+	p := &Preparer{names: make(map[string]bool, len(names))}
+	for _, name := range names {
+		p.names[name] = true
+	}
+	return p
+}
+
+// Wrap conn, the named queries are run as statements prepared on db. Calls which ctx routes to another database,
+// like a tenant one, go to conn unchanged
+func (p *Preparer) SQL(db *sqlx.DB, conn profiling.SQLConn) profiling.SQLConn {
+	if p == nil || len(p.names) == 0 {
+		return conn
+	}
+	return &preparedConn{SQLConn: conn, db: db, names: p.names, stmts: make(map[string]*sql.Stmt)}
+}
+
+type preparedConn struct {
+	profiling.SQLConn
+	db    *sqlx.DB
+	names map[string]bool
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func (c *preparedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return c.SQLConn.ExecContext(ctx, query, args...)
+}
+
+func (c *preparedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return c.SQLConn.QueryContext(ctx, query, args...)
+}
+
+func (c *preparedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return c.SQLConn.QueryRowContext(ctx, query, args...)
+}
+
+// Prepared statement of query, nil when it is not to be prepared or preparing failed
+func (c *preparedConn) stmt(ctx context.Context, query string) *sql.Stmt {
+	if tenant.DB(ctx, c.db) != c.db || !c.names[profiling.QueryName(query)] {
+		return nil
+	}
+
+	c.mu.Lock()
+	stmt, ok := c.stmts[query]
+	c.mu.Unlock()
+	if ok {
+		return stmt
+	}
+
+	// Preparing does not hold the lock, the first statement stored wins. A failed prepare is tried again on
+	// the next call, meanwhile the query runs unprepared
+	prepared, err := c.db.PrepareContext(context.WithoutCancel(ctx), query)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stored, ok := c.stmts[query]; ok {
+		prepared.Close() // nolint: errcheck
+		return stored
+	}
+	c.stmts[query] = prepared
+	return prepared
+}
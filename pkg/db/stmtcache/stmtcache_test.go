@@ -0,0 +1,91 @@
+package stmtcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/tenant"
+)
+
+// Driver counting prepares, every query answers one row with 1
+type countingDriver struct {
+	prepares atomic.Int64
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) {
+	return &countingConn{driver: d}, nil
+}
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) {
+	c.driver.prepares.Add(1)
+	return countingStmt{}, nil
+}
+
+func (c *countingConn) Close() error              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type countingStmt struct{}
+
+func (countingStmt) Close() error                               { return nil }
+func (countingStmt) NumInput() int                              { return -1 }
+func (countingStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (countingStmt) Query([]driver.Value) (driver.Rows, error)  { return &oneRow{}, nil }
+
+type oneRow struct {
+	done bool
+}
+
+func (r *oneRow) Columns() []string { return []string{"one"} }
+func (r *oneRow) Close() error      { return nil }
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+var testDriver = &countingDriver{}
+
+func TestPreparer_SQL(t *testing.T) {
+	db := sqlx.NewDb(sql.OpenDB(testConnector{}), "stmtcache_counting")
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	conn := New([]string{"GetUserWithRole"}).SQL(db, tenant.SQL(db))
+	ctx := context.Background()
+
+	query := func(sql string) int64 {
+		before := testDriver.prepares.Load()
+		for i := 0; i < 3; i++ {
+			var one int
+			require.NoError(t, conn.QueryRowContext(ctx, sql, i).Scan(&one))
+			require.Equal(t, 1, one)
+		}
+		return testDriver.prepares.Load() - before
+	}
+
+	// Named queries are prepared once, others on every call
+	require.Equal(t, int64(1), query("-- name: GetUserWithRole :one\nSELECT 1"))
+	require.Equal(t, int64(3), query("-- name: ListUsers :many\nSELECT 1"))
+
+	// A nil preparer wraps nothing
+	var preparer *Preparer
+	require.Equal(t, tenant.SQL(db), preparer.SQL(db, tenant.SQL(db)))
+}
+
+type testConnector struct{}
+
+func (testConnector) Connect(context.Context) (driver.Conn, error) { return testDriver.Open("") }
+func (testConnector) Driver() driver.Driver                        { return testDriver }
@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
+)
+
+type dbCtxKey struct{}
+
+// Copy of ctx routed to the database of its tenant
+func WithDB(ctx context.Context, db *sqlx.DB) context.Context {
+	return context.WithValue(ctx, dbCtxKey{}, db)
+}
+
+// Database of the tenant ctx was routed to, fallback in shared mode and for work outside of a request
+func DB(ctx context.Context, fallback *sqlx.DB) *sqlx.DB {
+	if db, ok := ctx.Value(dbCtxKey{}).(*sqlx.DB); ok && db != nil {
+		return db
+	}
+	return fallback
+}
+
+// Connection picking the database of ctx on every call, for sqlc generated queries built once at startup
+func SQL(fallback *sqlx.DB) profiling.SQLConn {
+	return routedConn{fallback: fallback}
+}
+
+type routedConn struct {
+	fallback *sqlx.DB
+}
+
+func (c routedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return DB(ctx, c.fallback).ExecContext(ctx, query, args...)
+}
+
+func (c routedConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return DB(ctx, c.fallback).PrepareContext(ctx, query)
+}
+
+func (c routedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return DB(ctx, c.fallback).QueryContext(ctx, query, args...)
+}
+
+func (c routedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return DB(ctx, c.fallback).QueryRowContext(ctx, query, args...)
+}
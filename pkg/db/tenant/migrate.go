@@ -0,0 +1,128 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Same advisory lock for every instance, migrations of one database never run twice at once
+const migrationLockID = 7215004
+
+// Bookkeeping compatible with golang-migrate, so `make migrate_up` and Migrate agree on the version
+const (
+	createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+	getMigrationVersionQuery   = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	clearMigrationVersionQuery = `DELETE FROM schema_migrations`
+	setMigrationVersionQuery   = `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`
+)
+
+var upMigration = regexp.MustCompile(`^(\d+)_.*\.up\.sql$`)
+
+type migration struct {
+	version int64
+	name    string
+}
+
+// Apply the up migrations of dir newer than the recorded version, each in its own transaction together with
+// the version bump. Returns how many were applied
+func Migrate(ctx context.Context, db *sqlx.DB, dir string) (int, error) {
+	migrations, err := loadMigrations(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "tenant.Migrate.Connx")
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return 0, errors.Wrap(err, "tenant.Migrate.pg_advisory_lock")
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID) // nolint: errcheck
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTableQuery); err != nil {
+		return 0, errors.Wrap(err, "tenant.Migrate.createMigrationsTable")
+	}
+	var current int64
+	var dirty bool
+	if err := conn.QueryRowxContext(ctx, getMigrationVersionQuery).Scan(&current, &dirty); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.Wrap(err, "tenant.Migrate.getMigrationVersion")
+	}
+	if dirty {
+		return 0, errors.Errorf("tenant.Migrate: database is dirty at version %d, fix it with `migrate force`", current)
+	}
+
+	var applied int
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+func applyMigration(ctx context.Context, conn *sqlx.Conn, m migration) error {
+	statements, err := os.ReadFile(m.name)
+	if err != nil {
+		return errors.Wrap(err, "tenant.applyMigration.ReadFile")
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "tenant.applyMigration.BeginTxx")
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if _, err := tx.ExecContext(ctx, string(statements)); err != nil {
+		return errors.Wrapf(err, "tenant.applyMigration %s", filepath.Base(m.name))
+	}
+	if _, err := tx.ExecContext(ctx, clearMigrationVersionQuery); err != nil {
+		return errors.Wrap(err, "tenant.applyMigration.clearMigrationVersion")
+	}
+	if _, err := tx.ExecContext(ctx, setMigrationVersionQuery, m.version); err != nil {
+		return errors.Wrap(err, "tenant.applyMigration.setMigrationVersion")
+	}
+	return errors.Wrap(tx.Commit(), "tenant.applyMigration.Commit")
+}
+
+// Up migrations of dir by version
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "tenant.loadMigrations.ReadDir")
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		match := upMigration.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tenant.loadMigrations %s", entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, errors.Errorf("tenant.loadMigrations: %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+		migrations = append(migrations, migration{version: version, name: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
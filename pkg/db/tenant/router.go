@@ -0,0 +1,218 @@
+// Package tenant routes requests to the database of their tenant in database per tenant mode. Pools are
+// opened on first use, migrated when configured and closed again once they are the least recently used past
+// the cap. Repositories read the routed pool from the request context and fall back to the shared one.
+package tenant
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/postgres"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultMaxOpen  = 32
+	defaultMaxConns = 10
+
+	// Opening includes migrations, so it is not bound to the request which happened to trigger it
+	openTimeout = 5 * time.Minute
+	// Requests may still hold an evicted pool, it is closed once they had time to finish
+	evictedCloseDelay = time.Minute
+)
+
+// Tenant not in the configured list
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Open the pool of a tenant
+type openFunc func(ctx context.Context, tenant string) (*sqlx.DB, error)
+
+type pool struct {
+	tenant string
+	ready  chan struct{}
+	db     *sqlx.DB
+	err    error
+}
+
+// Router of tenant pools, nil in shared mode
+type Router struct {
+	mu      sync.Mutex
+	tenants map[string]bool
+	maxOpen int
+	// Most recently used first
+	lru   *list.List
+	pools map[string]*list.Element
+	open  openFunc
+	// Closing evicted pools, replaced in tests
+	closeLater func(db *sqlx.DB)
+	logger     logger.Logger
+}
+
+// Router from app config, nil unless every tenant has its own database
+func NewRouter(cfg *config.Config, logger logger.Logger) *Router {
+	if !cfg.Tenancy.Isolated() {
+		return nil
+	}
+	r := newRouter(cfg.Tenancy, logger)
+	r.open = func(ctx context.Context, tenant string) (*sqlx.DB, error) {
+		return openTenantDB(ctx, cfg, tenant, logger)
+	}
+	return r
+}
+
+func newRouter(tenancy config.Tenancy, logger logger.Logger) *Router {
+	maxOpen := tenancy.MaxOpen
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpen
+	}
+	tenants := make(map[string]bool, len(tenancy.Tenants))
+	for _, tenant := range tenancy.Tenants {
+		tenants[tenant] = true
+	}
+	return &Router{
+		tenants: tenants,
+		maxOpen: maxOpen,
+		lru:     list.New(),
+		pools:   make(map[string]*list.Element),
+		closeLater: func(db *sqlx.DB) {
+			time.AfterFunc(evictedCloseDelay, func() { db.Close() })
+		},
+		logger: logger,
+	}
+}
+
+// Pool of the tenant, opened on first use. Concurrent callers wait for the same open, a failed open is
+// retried by the next caller
+func (r *Router) DB(ctx context.Context, tenant string) (*sqlx.DB, error) {
+	if !r.tenants[tenant] {
+		return nil, errors.Wrap(ErrUnknownTenant, tenant)
+	}
+
+	r.mu.Lock()
+	element, ok := r.pools[tenant]
+	if ok {
+		r.lru.MoveToFront(element)
+	} else {
+		element = r.lru.PushFront(&pool{tenant: tenant, ready: make(chan struct{})})
+		r.pools[tenant] = element
+		r.evictLocked()
+		go r.openPool(element.Value.(*pool))
+	}
+	p := element.Value.(*pool)
+	r.mu.Unlock()
+
+	select {
+	case <-p.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.db, nil
+}
+
+// Number of pools open or opening
+func (r *Router) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lru.Len()
+}
+
+// Close every pool, for shutdown
+func (r *Router) Close() {
+	r.mu.Lock()
+	pools := make([]*pool, 0, r.lru.Len())
+	for element := r.lru.Front(); element != nil; element = element.Next() {
+		pools = append(pools, element.Value.(*pool))
+	}
+	r.lru.Init()
+	r.pools = make(map[string]*list.Element)
+	r.mu.Unlock()
+
+	for _, p := range pools {
+		<-p.ready
+		if p.db != nil {
+			if err := p.db.Close(); err != nil {
+				r.logger.Warnf("tenant.Router.Close tenant: %s, error: %v", p.tenant, err)
+			}
+		}
+	}
+}
+
+func (r *Router) openPool(p *pool) {
+	ctx, cancel := context.WithTimeout(context.Background(), openTimeout)
+	defer cancel()
+
+	p.db, p.err = r.open(ctx, p.tenant)
+	if p.err != nil {
+		r.logger.Errorf("tenant.Router.open tenant: %s, error: %v", p.tenant, p.err)
+		r.remove(p)
+	}
+	close(p.ready)
+}
+
+// Drop least recently used pools past the cap, callers hold the lock
+func (r *Router) evictLocked() {
+	for r.lru.Len() > r.maxOpen {
+		element := r.lru.Back()
+		p := element.Value.(*pool)
+		r.lru.Remove(element)
+		delete(r.pools, p.tenant)
+
+		go func() {
+			<-p.ready
+			if p.db != nil {
+				r.logger.Infof("tenant.Router evicted tenant: %s", p.tenant)
+				r.closeLater(p.db)
+			}
+		}()
+	}
+}
+
+// Forget a failed pool unless it was replaced meanwhile
+func (r *Router) remove(p *pool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, ok := r.pools[p.tenant]; ok && element.Value.(*pool) == p {
+		r.lru.Remove(element)
+		delete(r.pools, p.tenant)
+	}
+}
+
+// Connect to the database of the tenant and migrate it when configured
+func openTenantDB(ctx context.Context, cfg *config.Config, tenant string, logger logger.Logger) (*sqlx.DB, error) {
+	tenantCfg := *cfg
+	tenantCfg.Postgres.PostgresqlDbname = fmt.Sprintf(cfg.Tenancy.DbnameTemplate, tenant)
+	db, err := postgres.NewPsqlDB(&tenantCfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tenant.openTenantDB %s", tenantCfg.Postgres.PostgresqlDbname)
+	}
+
+	maxConns := cfg.Tenancy.MaxConns
+	if maxConns <= 0 {
+		maxConns = defaultMaxConns
+	}
+	db.SetMaxOpenConns(maxConns)
+	db.SetMaxIdleConns(maxConns)
+
+	if cfg.Tenancy.Migrate {
+		applied, err := Migrate(ctx, db, cfg.Tenancy.MigrationsPath)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrapf(err, "tenant.openTenantDB.Migrate %s", tenant)
+		}
+		if applied > 0 {
+			logger.Infof("tenant.openTenantDB tenant: %s, applied %d migrations", tenant, applied)
+		}
+	}
+	return db, nil
+}
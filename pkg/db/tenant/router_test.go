@@ -0,0 +1,130 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+func newTestRouter(t *testing.T, maxOpen int) (*Router, *sync.Map, *int32) {
+	t.Helper()
+
+	cfg := &config.Config{Logger: config.Logger{Level: "info"}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	r := newRouter(config.Tenancy{Tenants: []string{"acme", "globex", "initech"}, MaxOpen: maxOpen}, appLogger)
+	opened := new(int32)
+	r.open = func(ctx context.Context, tenant string) (*sqlx.DB, error) {
+		atomic.AddInt32(opened, 1)
+		// Not connected until used
+		return sqlx.Open("pgx", "dbname="+tenant)
+	}
+	closed := &sync.Map{}
+	r.closeLater = func(db *sqlx.DB) { closed.Store(db, true) }
+	t.Cleanup(r.Close)
+	return r, closed, opened
+}
+
+func TestRouter_DB(t *testing.T) {
+	t.Parallel()
+
+	r, closed, opened := newTestRouter(t, 2)
+	ctx := context.Background()
+
+	_, err := r.DB(ctx, "hooli")
+	require.ErrorIs(t, err, ErrUnknownTenant)
+
+	var wg sync.WaitGroup
+	dbs := make([]*sqlx.DB, 8)
+	for i := range dbs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dbs[i], _ = r.DB(ctx, "acme")
+		}(i)
+	}
+	wg.Wait()
+	for _, db := range dbs {
+		require.Same(t, dbs[0], db)
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(opened))
+
+	globex, err := r.DB(ctx, "globex")
+	require.NoError(t, err)
+	// Acme is used again, so globex is the least recently used one when initech opens
+	_, err = r.DB(ctx, "acme")
+	require.NoError(t, err)
+	_, err = r.DB(ctx, "initech")
+	require.NoError(t, err)
+	require.Equal(t, 2, r.Len())
+	require.Eventually(t, func() bool {
+		_, ok := closed.Load(globex)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	reopened, err := r.DB(ctx, "globex")
+	require.NoError(t, err)
+	require.NotSame(t, globex, reopened)
+	require.EqualValues(t, 4, atomic.LoadInt32(opened))
+}
+
+func TestRouter_DBOpenFailure(t *testing.T) {
+	t.Parallel()
+
+	r, _, _ := newTestRouter(t, 2)
+	failing := errors.New("connection refused")
+	r.open = func(ctx context.Context, tenant string) (*sqlx.DB, error) { return nil, failing }
+
+	_, err := r.DB(context.Background(), "acme")
+	require.ErrorIs(t, err, failing)
+	require.Equal(t, 0, r.Len())
+
+	// The next caller opens again
+	r.open = func(ctx context.Context, tenant string) (*sqlx.DB, error) { return sqlx.Open("pgx", "dbname="+tenant) }
+	db, err := r.DB(context.Background(), "acme")
+	require.NoError(t, err)
+	require.NotNil(t, db)
+}
+
+func TestDB(t *testing.T) {
+	t.Parallel()
+
+	shared, err := sqlx.Open("pgx", "dbname=shared")
+	require.NoError(t, err)
+	routed, err := sqlx.Open("pgx", "dbname=acme")
+	require.NoError(t, err)
+
+	require.Same(t, shared, DB(context.Background(), shared))
+	require.Same(t, routed, DB(WithDB(context.Background(), routed), shared))
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"10_b.up.sql", "10_b.down.sql", "02_a.up.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1"), 0o600))
+	}
+	migrations, err := loadMigrations(dir)
+	require.NoError(t, err)
+	require.Equal(t, []migration{
+		{version: 2, name: filepath.Join(dir, "02_a.up.sql")},
+		{version: 10, name: filepath.Join(dir, "10_b.up.sql")},
+	}, migrations)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2_c.up.sql"), []byte("SELECT 1"), 0o600))
+	_, err = loadMigrations(dir)
+	require.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package dedup
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// Group shares one in flight call between concurrent callers with the same key
+type Group struct {
+	name    string
+	enabled bool
+	metrics metric.Metrics
+	group   singleflight.Group
+}
+
+// Group constructor, a disabled group calls fn directly, metrics may be nil
+func NewGroup(name string, enabled bool, metrics metric.Metrics) *Group {
+	return &Group{name: name, enabled: enabled, metrics: metrics}
+}
+
+// Run fn once per key for all concurrent callers, shared reports whether the result went to more than one caller.
+// fn runs detached from the caller cancellation so one cancelled request does not fail the others waiting on it.
+func (g *Group) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (v interface{}, shared bool, err error) {
+	if !g.enabled {
+		v, err = fn(ctx)
+		return v, false, err
+	}
+
+	ch := g.group.DoChan(key, func() (interface{}, error) {
+		return fn(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	case res := <-ch:
+		if g.metrics != nil {
+			g.metrics.IncDedupCalls(g.name, res.Shared)
+		}
+		return res.Val, res.Shared, res.Err
+	}
+}
@@ -0,0 +1,66 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Do(t *testing.T) {
+	t.Parallel()
+
+	group := NewGroup("getByID", true, nil)
+	release := make(chan struct{})
+	var calls int32
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "user", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan interface{}, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _, err := group.Do(context.Background(), "user:1", fn)
+			require.NoError(t, err)
+			results <- v
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for v := range results {
+		require.Equal(t, "user", v)
+	}
+}
+
+func TestGroup_DoDisabled(t *testing.T) {
+	t.Parallel()
+
+	group := NewGroup("getUsers", false, nil)
+	var calls int32
+	fn := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, shared, err := group.Do(context.Background(), "users", fn)
+		require.NoError(t, err)
+		require.False(t, shared)
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,216 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Struct tags marking a DTO field deprecated: deprecated holds what to use instead, sunset the date the field
+// goes away as YYYY-MM-DD and may be left out
+const (
+	TagDeprecated = "deprecated"
+	TagSunset     = "sunset"
+)
+
+// Response headers, Deprecation is set on every response of a request touching a deprecated field
+// and Sunset to the earliest removal date among them
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// Use of a deprecated field, Field is the json path with slice indexes collapsed to [], e.g. "items[].price"
+type Warning struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Sunset  string `json:"sunset,omitempty"`
+}
+
+// Warnings collected while serving one request, safe for concurrent use and usable as nil
+type Collector struct {
+	mu       sync.Mutex
+	warnings []Warning
+	seen     map[string]bool
+}
+
+type ctxKey struct{}
+
+// Context carrying a new collector
+func NewContext(ctx context.Context) (context.Context, *Collector) {
+	collector := &Collector{seen: make(map[string]bool)}
+	return context.WithValue(ctx, ctxKey{}, collector), collector
+}
+
+// Collector of the request, nil outside of one
+func FromContext(ctx context.Context) *Collector {
+	collector, _ := ctx.Value(ctxKey{}).(*Collector)
+	return collector
+}
+
+// Record warnings on the collector of ctx, a no-op without one
+func Add(ctx context.Context, warnings ...Warning) {
+	FromContext(ctx).Add(warnings...)
+}
+
+// Record warnings, each field once
+func (c *Collector) Add(warnings ...Warning) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, warning := range warnings {
+		if c.seen[warning.Field] {
+			continue
+		}
+		c.seen[warning.Field] = true
+		c.warnings = append(c.warnings, warning)
+	}
+}
+
+// Recorded warnings ordered by field
+func (c *Collector) Warnings() []Warning {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	warnings := append([]Warning(nil), c.warnings...)
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Field < warnings[j].Field })
+	return warnings
+}
+
+// Set Deprecation and Sunset headers for warnings, nothing when there are none
+func SetHeaders(header http.Header, warnings []Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	header.Set(HeaderDeprecation, "true")
+
+	var sunset time.Time
+	for _, warning := range warnings {
+		date, err := time.Parse(time.DateOnly, warning.Sunset)
+		if err == nil && (sunset.IsZero() || date.Before(sunset)) {
+			sunset = date
+		}
+	}
+	if !sunset.IsZero() {
+		header.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Warning for a deprecated struct field at path, ok is false for fields without the tag
+func FieldWarning(sf reflect.StructField, path string) (Warning, bool) {
+	message, ok := sf.Tag.Lookup(TagDeprecated)
+	if !ok {
+		return Warning{}, false
+	}
+	if message == "" {
+		message = "deprecated"
+	}
+	return Warning{Field: path, Message: message, Sunset: sf.Tag.Get(TagSunset)}, true
+}
+
+// Warnings for deprecated fields set in a response value, zero valued fields are left out
+// like omitempty would and are not reported
+func Inspect(v interface{}) []Warning {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !hasDeprecated(rv.Type(), 0) {
+		return nil
+	}
+	var warnings []Warning
+	inspect(rv, "", &warnings, 0)
+	return warnings
+}
+
+// Deep enough for any DTO while stopping on cyclic pointers
+const maxInspectDepth = 8
+
+// Types known to hold deprecated fields or not, most responses have none and are not walked
+var deprecatedTypes sync.Map
+
+func hasDeprecated(t reflect.Type, depth int) bool {
+	if cached, ok := deprecatedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	if depth > maxInspectDepth {
+		return false
+	}
+
+	found := false
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		found = hasDeprecated(t.Elem(), depth+1)
+	case reflect.Interface:
+		// Dynamic values are checked when walking
+		found = true
+	case reflect.Struct:
+		for i := 0; i < t.NumField() && !found; i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() || sf.Tag.Get("json") == "-" {
+				continue
+			}
+			_, tagged := sf.Tag.Lookup(TagDeprecated)
+			found = tagged || hasDeprecated(sf.Type, depth+1)
+		}
+	}
+	if depth == 0 {
+		deprecatedTypes.Store(t, found)
+	}
+	return found
+}
+
+func inspect(v reflect.Value, path string, warnings *[]Warning, depth int) {
+	if depth > maxInspectDepth {
+		return
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fieldPath := path
+			if !sf.Anonymous || name != "" {
+				if name == "" {
+					name = sf.Name
+				}
+				fieldPath = joinPath(path, name)
+			}
+			fv := v.Field(i)
+			if warning, ok := FieldWarning(sf, fieldPath); ok && !fv.IsZero() {
+				*warnings = append(*warnings, warning)
+			}
+			inspect(fv, fieldPath, warnings, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			inspect(v.Index(i), path+"[]", warnings, depth+1)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,76 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type legacyItem struct {
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency,omitempty" deprecated:"prices are in the account currency"`
+}
+
+type legacyOrder struct {
+	ID     int           `json:"id"`
+	Items  []*legacyItem `json:"items"`
+	Coupon string        `json:"coupon,omitempty" deprecated:"use discounts" sunset:"2027-01-31"`
+	Secret string        `json:"-" deprecated:"never serialized"`
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, Inspect(nil))
+	require.Nil(t, Inspect(&struct{ Name string }{Name: "x"}))
+	require.Empty(t, Inspect(&legacyOrder{ID: 1, Items: []*legacyItem{{Price: 1}}, Secret: "s"}))
+
+	warnings := Inspect([]legacyOrder{{
+		ID:     1,
+		Items:  []*legacyItem{{Price: 1}, {Price: 2, Currency: "EUR"}, nil},
+		Coupon: "SUMMER",
+	}})
+	require.Equal(t, []Warning{
+		{Field: "[].items[].currency", Message: "prices are in the account currency"},
+		{Field: "[].coupon", Message: "use discounts", Sunset: "2027-01-31"},
+	}, warnings)
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	// No collector outside of a request
+	Add(context.Background(), Warning{Field: "a"})
+	require.Nil(t, FromContext(context.Background()).Warnings())
+
+	ctx, collector := NewContext(context.Background())
+	Add(ctx, Warning{Field: "b", Sunset: "2027-05-01"}, Warning{Field: "a", Sunset: "not a date"})
+	Add(ctx, Warning{Field: "b", Message: "again"})
+	require.Equal(t, []Warning{{Field: "a", Sunset: "not a date"}, {Field: "b", Sunset: "2027-05-01"}}, collector.Warnings())
+
+	header := http.Header{}
+	SetHeaders(header, nil)
+	require.Empty(t, header)
+	SetHeaders(header, collector.Warnings())
+	require.Equal(t, "true", header.Get(HeaderDeprecation))
+	require.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", header.Get(HeaderSunset))
+}
+
+func TestAppendWarnings(t *testing.T) {
+	t.Parallel()
+
+	warnings := []Warning{{Field: "a", Message: "m"}}
+	cases := map[string]string{
+		`{"z":1,"a":2}`:     `{"z":1,"a":2,"warnings":[{"field":"a","message":"m"}]}`,
+		`{}`:                `{"warnings":[{"field":"a","message":"m"}]}`,
+		`[1,2]`:             `[1,2]`,
+		`{"warnings":null}`: `{"warnings":null}`,
+	}
+	for body, expected := range cases {
+		spliced, err := appendWarnings([]byte(body), warnings)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(spliced))
+	}
+}
@@ -0,0 +1,77 @@
+package deprecation
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Key of the warnings list added to json object responses
+const warningsKey = "warnings"
+
+// Json serializer recording deprecated response fields on the request collector and appending every warning of
+// the request to object responses as a "warnings" list. Responses without warnings, arrays and objects which
+// already have a warnings key are written unchanged.
+type Serializer struct {
+	echo.DefaultJSONSerializer
+}
+
+// Serialize converts an interface into a json and writes it to the response
+func (s Serializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	collector := FromContext(c.Request().Context())
+	if collector == nil {
+		return s.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+	collector.Add(Inspect(i)...)
+	warnings := collector.Warnings()
+	if len(warnings) == 0 {
+		return s.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+
+	body, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	body, err = appendWarnings(body, warnings)
+	if err != nil {
+		return err
+	}
+	if indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", indent); err != nil {
+			return err
+		}
+		body = indented.Bytes()
+	}
+	_, err = c.Response().Write(append(body, '\n'))
+	return err
+}
+
+// Splice the warnings into a json object keeping its key order
+func appendWarnings(body []byte, warnings []Warning) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return body, nil
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &keys); err != nil {
+		return nil, err
+	}
+	if _, taken := keys[warningsKey]; taken {
+		return body, nil
+	}
+
+	list, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+	spliced := make([]byte, 0, len(trimmed)+len(list)+len(warningsKey)+4)
+	spliced = append(spliced, trimmed[:len(trimmed)-1]...)
+	if len(keys) > 0 {
+		spliced = append(spliced, ',')
+	}
+	spliced = append(spliced, `"`+warningsKey+`":`...)
+	spliced = append(spliced, list...)
+	return append(spliced, '}'), nil
+}
@@ -0,0 +1,224 @@
+// Package eventschema keeps the versioned schemas of published events. Publishers validate a payload against
+// the latest version of its event and send the version along with it, consumers bring payloads of older
+// versions up to the version they understand with the registered upcasters, so events can evolve without
+// breaking the services receiving them.
+//
+// Schemas are JSON Schema in the dialect of OpenAPI 3, one file per version named <event>.v<version>.json.
+// A new version of an event adds its schema file and an upcaster from the version before it.
+package eventschema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+)
+
+// Schemas of the user lifecycle events published to webhooks
+//
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// Converts the payload of an event from the version before the one it is registered for
+type Upcaster func(data map[string]interface{}) (map[string]interface{}, error)
+
+// Schemas and upcasters by event, read only once loaded
+type Registry struct {
+	events map[string][]*version
+}
+
+type version struct {
+	schema *openapi3.Schema
+	upcast Upcaster
+}
+
+var schemaFile = regexp.MustCompile(`^(.+)\.v(\d+)\.json$`)
+
+// Registry of the user lifecycle events
+func Events() *Registry {
+	files, err := fs.Sub(schemas, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	registry, err := Load(files)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// Registry of the schema files at the root of fsys, versions of an event have to be numbered from 1 without gaps
+func Load(fsys fs.FS) (*Registry, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "eventschema.Load.Glob")
+	}
+
+	found := make(map[string]map[int]*openapi3.Schema)
+	for _, file := range files {
+		match := schemaFile.FindStringSubmatch(file)
+		if match == nil {
+			return nil, errors.Errorf("eventschema.Load: %s is not named <event>.v<version>.json", file)
+		}
+		number, _ := strconv.Atoi(match[2])
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.Wrap(err, "eventschema.Load.ReadFile")
+		}
+		schema := &openapi3.Schema{}
+		if err := json.Unmarshal(raw, schema); err != nil {
+			return nil, errors.Wrapf(err, "eventschema.Load.Unmarshal %s", file)
+		}
+		if found[match[1]] == nil {
+			found[match[1]] = make(map[int]*openapi3.Schema)
+		}
+		found[match[1]][number] = schema
+	}
+
+	r := &Registry{events: make(map[string][]*version, len(found))}
+	for event, numbered := range found {
+		versions := make([]*version, len(numbered))
+		for number, schema := range numbered {
+			if number < 1 || number > len(numbered) {
+				return nil, errors.Errorf("eventschema.Load: versions of %s are not numbered from 1 without gaps", event)
+			}
+			versions[number-1] = &version{schema: schema}
+		}
+		r.events[event] = versions
+	}
+	return r, nil
+}
+
+// Register the upcaster bringing payloads of event from version-1 to version
+func (r *Registry) Upcaster(event string, version int, upcast Upcaster) *Registry {
+	versions := r.events[event]
+	if version < 2 || version > len(versions) {
+		panic(fmt.Sprintf("eventschema: %s has no version %d to upcast to", event, version))
+	}
+	versions[version-1].upcast = upcast
+	return r
+}
+
+// Events with a schema, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.events))
+	for event := range r.events {
+		names = append(names, event)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Latest version of event, false when it has no schema
+func (r *Registry) Latest(event string) (int, bool) {
+	versions, ok := r.events[event]
+	return len(versions), ok
+}
+
+// Validate data against the latest schema of event before it is published and return that version.
+// Events without a schema fail, every published event must have one
+func (r *Registry) Validate(event string, data []byte) (int, error) {
+	latest, ok := r.Latest(event)
+	if !ok {
+		return 0, errors.Errorf("eventschema: %s has no schema", event)
+	}
+	return latest, r.ValidateVersion(event, latest, data)
+}
+
+// Validate data against the schema of a version of event
+func (r *Registry) ValidateVersion(event string, number int, data []byte) error {
+	v, err := r.version(event, number)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Wrapf(err, "eventschema: %s v%d payload", event, number)
+	}
+	if err := v.schema.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+		return errors.Wrapf(err, "eventschema: %s v%d payload", event, number)
+	}
+	return nil
+}
+
+// Bring data of a version of event up to the latest version, consumers pass the version sent with the event.
+// Version 0, of events published before versions were sent, is taken for 1
+func (r *Registry) Upcast(event string, number int, data []byte) ([]byte, int, error) {
+	latest, ok := r.Latest(event)
+	if !ok {
+		return nil, 0, errors.Errorf("eventschema: %s has no schema", event)
+	}
+	return r.UpcastTo(event, number, latest, data)
+}
+
+// Bring data of a version of event up to version target, the version a consumer is built against. Data of
+// target or a later version is returned as is
+func (r *Registry) UpcastTo(event string, number int, target int, data []byte) ([]byte, int, error) {
+	if number == 0 {
+		number = 1
+	}
+	if _, err := r.version(event, number); err != nil {
+		return nil, 0, err
+	}
+	if _, err := r.version(event, target); err != nil {
+		return nil, 0, err
+	}
+	if number >= target {
+		return data, number, nil
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, 0, errors.Wrapf(err, "eventschema: %s v%d payload", event, number)
+	}
+	for ; number < target; number++ {
+		upcast := r.events[event][number].upcast
+		if upcast == nil {
+			return nil, 0, errors.Errorf("eventschema: %s has no upcaster to v%d", event, number+1)
+		}
+		var err error
+		if value, err = upcast(value); err != nil {
+			return nil, 0, errors.Wrapf(err, "eventschema: %s upcast to v%d", event, number+1)
+		}
+	}
+	upcasted, err := json.Marshal(value)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "eventschema.UpcastTo.Marshal")
+	}
+	return upcasted, number, nil
+}
+
+// Upcast data of a version of event to version target and decode it into out
+func (r *Registry) Decode(event string, number int, target int, data []byte, out interface{}) error {
+	upcasted, _, err := r.UpcastTo(event, number, target, data)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(json.Unmarshal(upcasted, out), "eventschema.Decode.Unmarshal")
+}
+
+// Schema of a version of event as JSON, for consumers validating what they receive
+func (r *Registry) Schema(event string, number int) ([]byte, error) {
+	v, err := r.version(event, number)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v.schema)
+}
+
+func (r *Registry) version(event string, number int) (*version, error) {
+	versions, ok := r.events[event]
+	if !ok {
+		return nil, errors.Errorf("eventschema: %s has no schema", event)
+	}
+	if number < 1 || number > len(versions) {
+		return nil, errors.Errorf("eventschema: %s has no version %d", event, number)
+	}
+	return versions[number-1], nil
+}
@@ -0,0 +1,75 @@
+package eventschema
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	registry := Events()
+	require.Equal(t, []string{"login.new_device", "login.suspicious", "profile.updated", "role_grant.expired"}, registry.Names())
+
+	version, err := registry.Validate("role_grant.expired", []byte(`{"grant_id":3,"role":"editor","expires_at":"2024-01-02T03:04:05Z"}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	_, err = registry.Validate("role_grant.expired", []byte(`{"grant_id":3,"role":"editor","expires_at":"soon"}`))
+	require.Error(t, err)
+	_, err = registry.Validate("login.suspicious", []byte(`{"ip_address":"10.0.0.1","user_agent":"curl","decision":"maybe","reasons":[]}`))
+	require.Error(t, err)
+	_, err = registry.Validate("user.unknown", []byte(`{}`))
+	require.Error(t, err)
+
+	schema, err := registry.Schema("login.new_device", 1)
+	require.NoError(t, err)
+	require.Contains(t, string(schema), `"ip_address"`)
+}
+
+func TestRegistry_Upcast(t *testing.T) {
+	t.Parallel()
+
+	registry, err := Load(fstest.MapFS{
+		"user.renamed.v1.json": {Data: []byte(`{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`)},
+		"user.renamed.v2.json": {Data: []byte(`{"type":"object","required":["first_name","last_name"]}`)},
+		"user.renamed.v3.json": {Data: []byte(`{"type":"object","required":["first_name","last_name","display_name"]}`)},
+	})
+	require.NoError(t, err)
+	registry.
+		Upcaster("user.renamed", 2, func(data map[string]interface{}) (map[string]interface{}, error) {
+			data["first_name"], data["last_name"] = data["name"], ""
+			delete(data, "name")
+			return data, nil
+		}).
+		Upcaster("user.renamed", 3, func(data map[string]interface{}) (map[string]interface{}, error) {
+			data["display_name"] = data["first_name"]
+			return data, nil
+		})
+
+	// Published before versions were sent
+	data, version, err := registry.Upcast("user.renamed", 0, []byte(`{"name":"Ada"}`))
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+	require.JSONEq(t, `{"first_name":"Ada","last_name":"","display_name":"Ada"}`, string(data))
+	require.NoError(t, registry.ValidateVersion("user.renamed", 3, data))
+
+	// Consumer built against v2 decodes v1 and leaves v3 as it is
+	var renamed struct {
+		FirstName string `json:"first_name"`
+	}
+	require.NoError(t, registry.Decode("user.renamed", 1, 2, []byte(`{"name":"Ada"}`), &renamed))
+	require.Equal(t, "Ada", renamed.FirstName)
+	data, version, err = registry.UpcastTo("user.renamed", 3, 2, []byte(`{"first_name":"Ada"}`))
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+	require.JSONEq(t, `{"first_name":"Ada"}`, string(data))
+
+	_, _, err = registry.Upcast("user.renamed", 4, []byte(`{}`))
+	require.Error(t, err)
+
+	_, err = Load(fstest.MapFS{"user.renamed.v2.json": {Data: []byte(`{}`)}})
+	require.Error(t, err)
+}
@@ -0,0 +1,229 @@
+// Package explain logs query plans of a sample of slow queries. Plans come from EXPLAIN ANALYZE, which runs
+// the query again, so only reads are sampled and at most MaxConcurrent plans are fetched at a time.
+package explain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/observe"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/profiling"
+)
+
+const (
+	explainPrefix = "EXPLAIN (ANALYZE, BUFFERS) "
+
+	defaultTimeout       = 5 * time.Second
+	defaultMaxConcurrent = 1
+)
+
+// Fetch the plan lines of a query
+type explainFunc func(ctx context.Context, query string, args []interface{}) ([]string, error)
+
+// Sampler of slow query plans, a nil Sampler wraps nothing
+type Sampler struct {
+	slow       time.Duration
+	sampleRate float64
+	timeout    time.Duration
+	slots      chan struct{}
+	logger     logger.Logger
+	random     func() float64
+}
+
+// Sampler from app config, nil when disabled
+func NewSampler(cfg *config.Config, logger logger.Logger) *Sampler {
+	explainCfg := cfg.Postgres.Explain
+	if !explainCfg.Enabled {
+		return nil
+	}
+
+	timeout := time.Duration(explainCfg.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	maxConcurrent := explainCfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrent
+	}
+	return &Sampler{
+		slow:       time.Duration(explainCfg.SlowQueryMs) * time.Millisecond,
+		sampleRate: explainCfg.SampleRate,
+		timeout:    timeout,
+		slots:      make(chan struct{}, maxConcurrent),
+		logger:     logger,
+		random:     rand.Float64,
+	}
+}
+
+// Wrap database/sql connection, slow reads through it are sampled
+func (s *Sampler) SQL(conn profiling.SQLConn) profiling.SQLConn {
+	if s == nil {
+		return conn
+	}
+	return &sqlConn{SQLConn: conn, sampler: s}
+}
+
+// Wrap pgx connection, like SQL
+func (s *Sampler) Pgx(conn profiling.PgxConn) profiling.PgxConn {
+	if s == nil {
+		return conn
+	}
+	return &pgxConn{PgxConn: conn, sampler: s}
+}
+
+// Sample the query when it was slow, elapsed is the time until its first result. The plan is fetched and
+// logged in the background with the logger of the calling usecase
+func (s *Sampler) observe(ctx context.Context, query string, args []interface{}, elapsed time.Duration, explain explainFunc) {
+	if elapsed < s.slow || !readOnly(query) || s.random() >= s.sampleRate {
+		return
+	}
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		// Busy explaining, this sample is dropped
+		return
+	}
+
+	log := observe.Logger(ctx, s.logger)
+	go func() {
+		defer func() { <-s.slots }()
+
+		explainCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+
+		name := profiling.QueryName(query)
+		plan, err := explain(explainCtx, explainPrefix+query, args)
+		if err != nil {
+			log.Warnf("explain: query %s took %s, params: %v, plan: %v", name, elapsed, Redact(args), err)
+			return
+		}
+		log.Warnf("explain: query %s took %s, params: %v, plan:\n%s", name, elapsed, Redact(args), strings.Join(plan, "\n"))
+	}()
+}
+
+// Parameters fit for logs, strings and bytes are replaced by their length
+func Redact(args []interface{}) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case nil:
+			redacted = append(redacted, "NULL")
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+			redacted = append(redacted, fmt.Sprint(value))
+		case time.Time:
+			redacted = append(redacted, value.Format(time.RFC3339))
+		case string:
+			redacted = append(redacted, fmt.Sprintf("<string:%d>", len(value)))
+		case []byte:
+			redacted = append(redacted, fmt.Sprintf("<bytes:%d>", len(value)))
+		default:
+			redacted = append(redacted, fmt.Sprintf("<%T>", value))
+		}
+	}
+	return redacted
+}
+
+// Whether the statement only reads, sqlc name comments are skipped and CTEs must not modify data
+func readOnly(query string) bool {
+	var statement []string
+	for _, line := range strings.Split(query, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			statement = append(statement, strings.ToUpper(line))
+		}
+	}
+	words := strings.Fields(strings.Join(statement, " "))
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "SELECT":
+		return true
+	case "WITH":
+		for _, word := range words {
+			switch strings.Trim(word, "(),") {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+type sqlConn struct {
+	profiling.SQLConn
+	sampler *Sampler
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	started := time.Now()
+	rows, err := c.SQLConn.QueryContext(ctx, query, args...)
+	if err == nil {
+		c.sampler.observe(ctx, query, args, time.Since(started), c.explain)
+	}
+	return rows, err
+}
+
+func (c *sqlConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	started := time.Now()
+	row := c.SQLConn.QueryRowContext(ctx, query, args...)
+	if row.Err() == nil {
+		c.sampler.observe(ctx, query, args, time.Since(started), c.explain)
+	}
+	return row
+}
+
+func (c *sqlConn) explain(ctx context.Context, query string, args []interface{}) ([]string, error) {
+	rows, err := c.SQLConn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		plan = append(plan, line)
+	}
+	return plan, rows.Err()
+}
+
+type pgxConn struct {
+	profiling.PgxConn
+	sampler *Sampler
+}
+
+func (c *pgxConn) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	started := time.Now()
+	rows, err := c.PgxConn.Query(ctx, query, args...)
+	if err == nil {
+		c.sampler.observe(ctx, query, args, time.Since(started), c.explain)
+	}
+	return rows, err
+}
+
+func (c *pgxConn) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	started := time.Now()
+	row := c.PgxConn.QueryRow(ctx, query, args...)
+	c.sampler.observe(ctx, query, args, time.Since(started), c.explain)
+	return row
+}
+
+func (c *pgxConn) explain(ctx context.Context, query string, args []interface{}) ([]string, error) {
+	rows, err := c.PgxConn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,114 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Logger recording warnings, the only level the sampler logs at
+type warnLogger struct {
+	logger.Logger
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *warnLogger) Warnf(template string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(template, args...))
+}
+
+func (l *warnLogger) logged() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warnings...)
+}
+
+func newTestSampler(sampleRate float64) (*Sampler, *warnLogger) {
+	log := &warnLogger{}
+	return &Sampler{
+		slow:       100 * time.Millisecond,
+		sampleRate: sampleRate,
+		timeout:    time.Second,
+		slots:      make(chan struct{}, 1),
+		logger:     log,
+		random:     func() float64 { return 0.5 },
+	}, log
+}
+
+func TestSampler_Observe(t *testing.T) {
+	t.Parallel()
+
+	const query = "-- name: FindUsersByName :many\nSELECT * FROM users WHERE name ILIKE $1 LIMIT $2"
+	explained := make(chan string, 1)
+	explainFn := func(ctx context.Context, query string, args []interface{}) ([]string, error) {
+		explained <- query
+		return []string{"Seq Scan on users  (actual rows=3 loops=1)", "Execution Time: 250 ms"}, nil
+	}
+
+	sampler, log := newTestSampler(0.6)
+	sampler.observe(context.Background(), query, []interface{}{"%jane%", 10}, 50*time.Millisecond, explainFn)
+	sampler.observe(context.Background(), "UPDATE users SET name = $1", []interface{}{"jane"}, time.Second, explainFn)
+	sampler.observe(context.Background(), query, []interface{}{"%jane%", 10}, time.Second, explainFn)
+
+	require.Equal(t, explainPrefix+query, <-explained)
+	require.Eventually(t, func() bool { return len(log.logged()) == 1 }, time.Second, time.Millisecond)
+	warning := log.logged()[0]
+	require.Contains(t, warning, "query FindUsersByName took 1s, params: [<string:6> 10]")
+	require.Contains(t, warning, "Seq Scan on users")
+	require.NotContains(t, warning, "jane")
+	require.Empty(t, explained)
+
+	// Outside of the sample
+	unsampled, log := newTestSampler(0.4)
+	unsampled.observe(context.Background(), query, nil, time.Second, explainFn)
+	require.Empty(t, explained)
+	require.Empty(t, log.logged())
+}
+
+func TestSampler_ExplainError(t *testing.T) {
+	t.Parallel()
+
+	sampler, log := newTestSampler(1)
+	sampler.observe(context.Background(), "SELECT 1", nil, time.Second, func(context.Context, string, []interface{}) ([]string, error) {
+		return nil, errors.New("canceling statement due to statement timeout")
+	})
+	require.Eventually(t, func() bool { return len(log.logged()) == 1 }, time.Second, time.Millisecond)
+	require.Contains(t, log.logged()[0], "statement timeout")
+}
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, readOnly("-- name: ListUsers :many\nSELECT id FROM users"))
+	require.True(t, readOnly("WITH recent AS (SELECT id FROM users) SELECT * FROM recent"))
+	require.False(t, readOnly("WITH moved AS (DELETE FROM users RETURNING id) SELECT * FROM moved"))
+	require.False(t, readOnly("-- name: CreateUser :one\nINSERT INTO users (name) VALUES ($1)"))
+	require.False(t, readOnly("-- only a comment"))
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.Equal(t,
+		[]string{"NULL", "42", "true", "2026-01-02T03:04:05Z", "<string:16>", "<bytes:3>", "<[]int>"},
+		Redact([]interface{}{nil, int64(42), true, at, "jane@example.com", []byte("abc"), []int{1}}),
+	)
+}
+
+func TestNilSampler(t *testing.T) {
+	t.Parallel()
+
+	var sampler *Sampler
+	require.Nil(t, sampler.SQL(nil))
+	require.Nil(t, sampler.Pgx(nil))
+}
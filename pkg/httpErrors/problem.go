@@ -0,0 +1,230 @@
+package httpErrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
+)
+
+// RFC 7807 problem details media type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Path of the bundled problem type pages
+const ProblemsPath = "/problems"
+
+// Extension member listing the fields which failed validation
+const invalidParamsKey = "invalid_params"
+
+// Extension members naming the trace of the request
+const (
+	traceIDKey   = "trace_id"
+	traceLinkKey = "trace_link"
+)
+
+// Problem details, members of the error other than status and message become extension members
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// Field which failed validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Problem details of a rest error, typed by its status under typeBaseURL
+func NewProblem(restErr RestErr, typeBaseURL string, instance string) *Problem {
+	problem := &Problem{
+		Type:       ProblemType(typeBaseURL, restErr.Status()),
+		Title:      http.StatusText(restErr.Status()),
+		Status:     restErr.Status(),
+		Instance:   instance,
+		Extensions: make(map[string]interface{}),
+	}
+
+	// Typed errors carry their message under "error" and extras such as a code next to it
+	if body, err := json.Marshal(restErr); err == nil {
+		var members map[string]interface{}
+		if json.Unmarshal(body, &members) == nil {
+			for key, value := range members {
+				switch key {
+				case "status":
+				case "error":
+					problem.Detail, _ = value.(string)
+				default:
+					problem.Extensions[key] = value
+				}
+			}
+		}
+	}
+	if problem.Title == "" {
+		problem.Title = problem.Detail
+	}
+	if invalidParams := validationParams(restErr.Causes()); len(invalidParams) > 0 {
+		problem.Extensions[invalidParamsKey] = invalidParams
+	}
+	return problem
+}
+
+// MarshalJSON writes the standard members followed by the extension members
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// Problem type URI of a status, the bundled page when typeBaseURL is empty
+func ProblemType(typeBaseURL string, status int) string {
+	if typeBaseURL == "" {
+		typeBaseURL = ProblemsPath
+	}
+	return strings.TrimSuffix(typeBaseURL, "/") + "/" + problemSlug(status)
+}
+
+// "Not Found" becomes "not-found", statuses without a text keep their number
+func problemSlug(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return strconv.Itoa(status)
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "-", "'", "").Replace(text))
+}
+
+func validationParams(causes interface{}) []InvalidParam {
+	err, ok := causes.(error)
+	if !ok {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	params := make([]InvalidParam, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		reason := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			reason += "=" + fieldErr.Param()
+		}
+		name := fieldErr.Namespace()
+		if dot := strings.Index(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+		params = append(params, InvalidParam{Name: name, Reason: reason})
+	}
+	return params
+}
+
+// Json serializer answering rest errors as problem details with the problem+json media type, the request id
+// becomes the problem instance and the trace headers become trace_id and trace_link. Every other value is
+// written by Next unchanged.
+type ProblemSerializer struct {
+	Next        echo.JSONSerializer
+	TypeBaseURL string
+}
+
+// Serialize converts an interface into a json and writes it to the response
+func (s ProblemSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	restErr, ok := i.(RestErr)
+	if !ok {
+		return s.Next.Serialize(c, i, indent)
+	}
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	problem := NewProblem(restErr, s.TypeBaseURL, header.Get(echo.HeaderXRequestID))
+	if traceID := header.Get(tracing.HeaderTraceID); traceID != "" {
+		problem.Extensions[traceIDKey] = traceID
+		if link := header.Get(tracing.HeaderTraceLink); link != "" {
+			problem.Extensions[traceLinkKey] = link
+		}
+	}
+	return s.Next.Serialize(c, problem, indent)
+}
+
+// Deserialize reads a json from a request body and converts it into an interface
+func (s ProblemSerializer) Deserialize(c echo.Context, i interface{}) error {
+	return s.Next.Deserialize(c, i)
+}
+
+// Echo error handler answering router and middleware errors as rest errors, so they are written as problems too
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var restErr RestErr
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		restErr = NewRestError(httpErr.Code, fmt.Sprint(httpErr.Message), httpErr.Internal)
+	} else {
+		restErr = ParseErrors(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(restErr.Status())
+	} else {
+		err = c.JSON(restErr.Status(), restErr)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// Static page documenting a problem type, cached by clients and proxies for maxAge
+func ProblemPage(maxAge time.Duration) echo.HandlerFunc {
+	pages := make(map[string]int)
+	for status := 400; status < 600; status++ {
+		if http.StatusText(status) != "" {
+			pages[problemSlug(status)] = status
+		}
+	}
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+
+	return func(c echo.Context) error {
+		status, ok := pages[c.Param("type")]
+		if !ok {
+			return echo.ErrNotFound
+		}
+
+		etag := `"` + c.Param("type") + `"`
+		c.Response().Header().Set(echo.HeaderCacheControl, cacheControl)
+		c.Response().Header().Set("ETag", etag)
+		if c.Request().Header.Get("If-None-Match") == etag {
+			return c.NoContent(http.StatusNotModified)
+		}
+
+		title := html.EscapeString(http.StatusText(status))
+		return c.HTML(http.StatusOK, fmt.Sprintf(
+			"<!doctype html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head>"+
+				"<body><h1>%s</h1><p>The request failed with HTTP status %d. The detail and instance members of the "+
+				"problem describe this occurrence, quote the instance when reporting it.</p></body></html>\n",
+			title, title, status,
+		))
+	}
+}
@@ -0,0 +1,120 @@
+package httpErrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
+)
+
+type codedError struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+}
+
+func (e *codedError) Error() string       { return e.ErrError }
+func (e *codedError) Status() int         { return e.ErrStatus }
+func (e *codedError) Causes() interface{} { return nil }
+
+func newProblemEcho() *echo.Echo {
+	e := echo.New()
+	e.JSONSerializer = ProblemSerializer{Next: echo.DefaultJSONSerializer{}, TypeBaseURL: "https://errors.example.com/"}
+	e.HTTPErrorHandler = ErrorHandler
+	return e
+}
+
+func serve(e *echo.Echo, method string, target string, header http.Header) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	return rec, body
+}
+
+func TestProblemSerializer(t *testing.T) {
+	t.Parallel()
+
+	type signUp struct {
+		Email string `validate:"required,email"`
+		Age   int    `validate:"gte=18"`
+	}
+	validationErr := validator.New().Struct(&signUp{Email: "nope", Age: 3})
+
+	e := newProblemEcho()
+	e.GET("/validation", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+		return c.JSON(ErrorResponse(errors.Wrap(validationErr, "ReadRequest")))
+	})
+	e.GET("/coded", func(c echo.Context) error {
+		c.Response().Header().Set(tracing.HeaderTraceID, "4bf92f3577b34da6")
+		c.Response().Header().Set(tracing.HeaderTraceLink, "https://jaeger.example.com/trace/4bf92f3577b34da6")
+		return c.JSON(ErrorResponse(&codedError{ErrStatus: http.StatusBadRequest, ErrError: "Invalid cursor", Code: "invalid_cursor"}))
+	})
+	e.GET("/ok", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "OK"})
+	})
+
+	rec, body := serve(e, http.MethodGet, "/validation", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	require.Equal(t, "https://errors.example.com/bad-request", body["type"])
+	require.Equal(t, "Bad Request", body["title"])
+	require.Equal(t, "Invalid email", body["detail"])
+	require.Equal(t, "req-1", body["instance"])
+	require.NotContains(t, body, traceIDKey)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "Email", "reason": "email"},
+		map[string]interface{}{"name": "Age", "reason": "gte=18"},
+	}, body[invalidParamsKey])
+
+	// Members of typed errors are kept as extension members
+	_, body = serve(e, http.MethodGet, "/coded", nil)
+	require.Equal(t, "invalid_cursor", body["code"])
+	require.Equal(t, "Invalid cursor", body["detail"])
+	require.NotContains(t, body, "error")
+
+	// The trace of the request is quoted next to the instance
+	require.Equal(t, "4bf92f3577b34da6", body[traceIDKey])
+	require.Equal(t, "https://jaeger.example.com/trace/4bf92f3577b34da6", body[traceLinkKey])
+
+	rec, body = serve(e, http.MethodGet, "/ok", nil)
+	require.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	require.Equal(t, map[string]interface{}{"status": "OK"}, body)
+
+	// Router errors are problems too
+	rec, body = serve(e, http.MethodGet, "/missing", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "https://errors.example.com/not-found", body["type"])
+}
+
+func TestProblemPage(t *testing.T) {
+	t.Parallel()
+
+	e := newProblemEcho()
+	e.GET(ProblemsPath+"/:type", ProblemPage(time.Hour))
+
+	rec, _ := serve(e, http.MethodGet, ProblemType("", http.StatusTooManyRequests), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "public, max-age=3600", rec.Header().Get(echo.HeaderCacheControl))
+	require.Contains(t, rec.Body.String(), "<h1>Too Many Requests</h1>")
+
+	rec, _ = serve(e, http.MethodGet, ProblemType("", http.StatusTooManyRequests), http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec, _ = serve(e, http.MethodGet, ProblemsPath+"/no-such-problem", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,186 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultRetryBackoff = 200 * time.Millisecond
+	maxErrorBodyBytes   = 4 << 10
+)
+
+// Non 2xx downstream response
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// Client options, Authorize decorates every outgoing request
+type Options struct {
+	Timeout      time.Duration
+	Retries      int
+	RetryBackoff time.Duration
+	Headers      map[string]string
+	Authorize    func(req *http.Request)
+}
+
+// Instrumented JSON http client, propagates tracing spans and request ids and retries idempotent calls
+type Client struct {
+	name       string
+	baseURL    string
+	opts       Options
+	httpClient *http.Client
+	logger     logger.Logger
+}
+
+// Http client constructor
+func New(name string, baseURL string, opts Options, logger logger.Logger) *Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	return &Client{
+		name:       name,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		opts:       opts,
+		httpClient: &http.Client{Timeout: opts.Timeout},
+		logger:     logger,
+	}
+}
+
+// Name of the downstream service
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Send request with json body and decode json response into out, nil body or out are skipped
+func (c *Client) Do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "httpclient."+c.name+"."+method)
+	defer span.Finish()
+
+	var payload []byte
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "httpclient.Do.json.Marshal")
+		}
+		payload = raw
+	}
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts += c.opts.Retries
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var retry bool
+		retry, err = c.do(ctx, span, method, path, payload, out)
+		if err == nil || !retry || attempt == attempts {
+			break
+		}
+
+		c.logger.Warnf("httpclient %s %s %s attempt %d failed: %v", c.name, method, path, attempt, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.opts.RetryBackoff * time.Duration(attempt)):
+		}
+	}
+	if err != nil {
+		ext.Error.Set(span, true)
+	}
+	return err
+}
+
+// Get json resource
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post json body
+func (c *Client) Post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+func (c *Client) do(ctx context.Context, span opentracing.Span, method string, path string, payload []byte, out interface{}) (bool, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return false, errors.Wrap(err, "httpclient.do.NewRequest")
+	}
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	if payload != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	if requestID, ok := ctx.Value(utils.ReqIDCtxKey{}).(string); ok && requestID != "" {
+		req.Header.Set(echo.HeaderXRequestID, requestID)
+	}
+	for key, value := range c.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	if c.opts.Authorize != nil {
+		c.opts.Authorize(req)
+	}
+
+	ext.SpanKindRPCClient.Set(span)
+	ext.HTTPMethod.Set(span, method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	if err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		c.logger.Debugf("httpclient %s inject span: %v", c.name, err)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, errors.Wrapf(err, "httpclient.%s.Do", c.name)
+	}
+	defer res.Body.Close()
+
+	ext.HTTPStatusCode.Set(span, uint16(res.StatusCode))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		errBody, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+		return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests, &StatusError{StatusCode: res.StatusCode, Body: string(errBody)}
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return false, errors.Wrapf(err, "httpclient.%s.json.Decode", c.name)
+	}
+	return false, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+func TestClient_Get(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, "req-1", r.Header.Get("X-Request-Id"))
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"profile"}`))
+	}))
+	defer srv.Close()
+
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+
+	client := New("profile", srv.URL, Options{
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+		Authorize: func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer secret")
+		},
+	}, appLogger)
+
+	ctx := context.WithValue(context.Background(), utils.ReqIDCtxKey{}, "req-1")
+	out := struct {
+		Name string `json:"name"`
+	}{}
+	require.NoError(t, client.Get(ctx, "/profiles/1", &out))
+	require.Equal(t, "profile", out.Name)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	err := client.Post(ctx, "/profiles", map[string]string{"a": "b"}, nil)
+	require.NoError(t, err)
+
+	atomic.StoreInt32(&calls, 0)
+	err = client.Post(ctx, "/profiles", nil, nil)
+	statusErr, ok := err.(*StatusError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+}
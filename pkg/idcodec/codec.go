@@ -0,0 +1,177 @@
+// Package idcodec turns integer ids into opaque strings for public APIs. Ids are encrypted as a single AES block
+// with a zero check half, so codes are stable per id, reveal neither the id nor the row count and can't be
+// forged by guessing. Repositories keep integer keys, only the http layer sees codes.
+package idcodec
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Length of an encoded id, a base64 AES block without padding
+const codeLen = 22
+
+// Rejected id, implements httpErrors.RestErr so it is answered with 400 naming the member or param
+type Error struct {
+	ErrStatus int    `json:"status"`
+	ErrError  string `json:"error"`
+	Code      string `json:"code"`
+	Field     string `json:"field,omitempty"`
+}
+
+// Matched with errors.Is whatever the field
+var ErrInvalid = &Error{ErrStatus: http.StatusBadRequest, ErrError: "Invalid id", Code: "invalid_id"}
+
+// Error  Error() interface method
+func (e *Error) Error() string {
+	return fmt.Sprintf("status: %d - errors: %s - field: %s", e.ErrStatus, e.ErrError, e.Field)
+}
+
+// Error status
+func (e *Error) Status() int {
+	return e.ErrStatus
+}
+
+// Id errors carry no causes
+func (e *Error) Causes() interface{} {
+	return nil
+}
+
+// Any id error is ErrInvalid
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func invalid(field string) *Error {
+	return &Error{ErrStatus: ErrInvalid.ErrStatus, ErrError: ErrInvalid.ErrError, Code: ErrInvalid.Code, Field: field}
+}
+
+// Id codec, safe for concurrent use
+type Codec struct {
+	block cipher.Block
+	keep  map[string]bool
+}
+
+// Codec with an AES key of 16, 24 or 32 bytes, names in keep are never treated as ids
+func New(key []byte, keep []string) (*Codec, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "idcodec.New.aes.NewCipher")
+	}
+	codec := &Codec{block: block, keep: make(map[string]bool, len(keep))}
+	for _, name := range keep {
+		codec.keep[strings.ToLower(name)] = true
+	}
+	return codec, nil
+}
+
+// Codec from app config, nil when ids are not obfuscated as internal deployments leave them. IDs.KeySecret names
+// the secret holding the base64 AES key, without it the key is derived from the JWT secret so every instance
+// issues the same codes
+func NewFromConfig(ctx context.Context, cfg *config.Config) (*Codec, error) {
+	if !cfg.IDs.Obfuscate {
+		return nil, nil
+	}
+	if cfg.IDs.KeySecret == "" {
+		if cfg.Server.JwtSecretKey == "" {
+			return nil, errors.New("idcodec: no key, set IDs.KeySecret or Server.JwtSecretKey")
+		}
+		mac := hmac.New(sha256.New, []byte(cfg.Server.JwtSecretKey))
+		mac.Write([]byte("public ids"))
+		return New(mac.Sum(nil), cfg.IDs.Keep)
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawKey, err := provider.Get(ctx, cfg.IDs.KeySecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "idcodec.NewFromConfig.key")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rawKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "idcodec.NewFromConfig.base64")
+	}
+	return New(key, cfg.IDs.Keep)
+}
+
+// Opaque code of an id
+func (c *Codec) Encode(id int64) string {
+	var block [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(block[:8], uint64(id))
+	c.block.Encrypt(block[:], block[:])
+	return base64.RawURLEncoding.EncodeToString(block[:])
+}
+
+// Id of a code, ErrInvalid for anything Encode did not return
+func (c *Codec) Decode(code string) (int64, error) {
+	if len(code) != codeLen {
+		return 0, ErrInvalid
+	}
+	block, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil || len(block) != aes.BlockSize {
+		return 0, ErrInvalid
+	}
+	c.block.Decrypt(block, block)
+	if binary.BigEndian.Uint64(block[8:]) != 0 {
+		return 0, ErrInvalid
+	}
+	return int64(binary.BigEndian.Uint64(block[:8])), nil
+}
+
+// Whether a json member or param of this name holds ids, names kept by config never do
+func (c *Codec) IsID(name string) bool {
+	name = strings.ToLower(name)
+	if c.keep[name] {
+		return false
+	}
+	return name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_ids")
+}
+
+// Integer value of a param named like an id, decoded from its code. Values which are not codes pass unchanged
+// unless they are plain integers, those were meant to be ids but skip the codec
+func (c *Codec) DecodeParam(name string, value string) (string, error) {
+	if value == "" || !c.IsID(name) {
+		return value, nil
+	}
+	if id, err := c.Decode(value); err == nil {
+		return strconv.FormatInt(id, 10), nil
+	}
+	if isInteger(value) {
+		return "", invalid(name)
+	}
+	return value, nil
+}
+
+func isInteger(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
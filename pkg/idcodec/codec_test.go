@@ -0,0 +1,125 @@
+package idcodec
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestCodec_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	codec, err := New(bytes.Repeat([]byte{7}, 32), []string{"hr_employee_id"})
+	require.NoError(t, err)
+	code := codec.Encode(42)
+	require.Len(t, code, codeLen)
+	require.Equal(t, code, codec.Encode(42))
+	require.NotEqual(t, code, codec.Encode(43))
+
+	id, err := codec.Decode(code)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), id)
+
+	// Codes of another key, tampered codes and plain ids are all invalid
+	other, err := New(bytes.Repeat([]byte{8}, 32), nil)
+	require.NoError(t, err)
+	_, err = codec.Decode(other.Encode(42))
+	require.ErrorIs(t, err, ErrInvalid)
+	tampered := []byte(code)
+	tampered[3] ^= 1
+	_, err = codec.Decode(string(tampered))
+	require.ErrorIs(t, err, ErrInvalid)
+	_, err = codec.Decode("42")
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestCodec_DecodeParam(t *testing.T) {
+	t.Parallel()
+
+	codec, err := New(bytes.Repeat([]byte{7}, 32), []string{"hr_employee_id"})
+	require.NoError(t, err)
+	value, err := codec.DecodeParam("user_id", codec.Encode(5))
+	require.NoError(t, err)
+	require.Equal(t, "5", value)
+
+	_, err = codec.DecodeParam("user_id", "5")
+	require.ErrorIs(t, err, ErrInvalid)
+
+	// String ids and params which are not ids pass unchanged
+	value, err = codec.DecodeParam("upload_id", "3f1c9a4e-2b7d-4c55-9e0a-1d2b3c4d5e6f")
+	require.NoError(t, err)
+	require.Equal(t, "3f1c9a4e-2b7d-4c55-9e0a-1d2b3c4d5e6f", value)
+	value, err = codec.DecodeParam("page", "5")
+	require.NoError(t, err)
+	require.Equal(t, "5", value)
+	value, err = codec.DecodeParam("hr_employee_id", "5")
+	require.NoError(t, err)
+	require.Equal(t, "5", value)
+}
+
+func TestCodec_JSON(t *testing.T) {
+	t.Parallel()
+
+	codec, err := New(bytes.Repeat([]byte{7}, 32), []string{"hr_employee_id"})
+	require.NoError(t, err)
+	body := []byte(`{"user":{"user_id":1,"first_name":"a","organization_id":null},"users":[{"id":2},{"id":3}],` +
+		`"role_ids":[4,5],"session_id":"s-1","hr_employee_id":6,"total_count":2,"has_more":false}`)
+
+	encoded, err := codec.EncodeJSON(body)
+	require.NoError(t, err)
+	require.Equal(t, `{"user":{"user_id":"`+codec.Encode(1)+`","first_name":"a","organization_id":null},"users":[{"id":"`+
+		codec.Encode(2)+`"},{"id":"`+codec.Encode(3)+`"}],"role_ids":["`+codec.Encode(4)+`","`+codec.Encode(5)+
+		`"],"session_id":"s-1","hr_employee_id":6,"total_count":2,"has_more":false}`, string(encoded))
+
+	decoded, err := codec.DecodeJSON(encoded)
+	require.NoError(t, err)
+	require.JSONEq(t, string(body), string(decoded))
+
+	// Plain ids in requests would let clients enumerate
+	_, err = codec.DecodeJSON([]byte(`{"name":"a","items":[{"organization_id":7}]}`))
+	require.ErrorIs(t, err, ErrInvalid)
+	require.Equal(t, "organization_id", err.(*Error).Field)
+}
+
+func TestSerializer(t *testing.T) {
+	t.Parallel()
+
+	codec, err := New(bytes.Repeat([]byte{7}, 32), []string{"hr_employee_id"})
+	require.NoError(t, err)
+	e := echo.New()
+	e.JSONSerializer = Serializer{Next: echo.DefaultJSONSerializer{}, Codec: codec}
+	e.GET("/users", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{"id": 9})
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"id":"`+codec.Encode(9)+`"}`+"\n", rec.Body.String())
+}
+
+func TestNewFromConfig(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewFromConfig(context.Background(), &config.Config{})
+	require.NoError(t, err)
+	require.Nil(t, codec)
+
+	_, err = NewFromConfig(context.Background(), &config.Config{IDs: config.IDs{Obfuscate: true}})
+	require.Error(t, err)
+
+	// Instances sharing the JWT secret issue the same codes
+	cfg := &config.Config{IDs: config.IDs{Obfuscate: true}, Server: config.ServerConfig{JwtSecretKey: "secret"}}
+	first, err := NewFromConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	second, err := NewFromConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, first.Encode(1), second.Encode(1))
+}
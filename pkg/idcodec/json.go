@@ -0,0 +1,131 @@
+package idcodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Encode the integer ids of a json document, member order is kept and the result is compact
+func (c *Codec) EncodeJSON(body []byte) ([]byte, error) {
+	return c.rewrite(body, func(name string, token json.Token) (json.Token, error) {
+		number, ok := token.(json.Number)
+		if !ok || !isInteger(number.String()) {
+			return token, nil
+		}
+		id, err := number.Int64()
+		if err != nil {
+			return token, nil
+		}
+		return c.Encode(id), nil
+	})
+}
+
+// Decode the id codes of a json document. Integers sent as ids are rejected, other strings pass unchanged
+// and fail binding if the field is an integer
+func (c *Codec) DecodeJSON(body []byte) ([]byte, error) {
+	return c.rewrite(body, func(name string, token json.Token) (json.Token, error) {
+		switch value := token.(type) {
+		case json.Number:
+			return nil, invalid(name)
+		case string:
+			if id, err := c.Decode(value); err == nil {
+				return json.Number(strconv.FormatInt(id, 10)), nil
+			}
+		}
+		return token, nil
+	})
+}
+
+// Container being read, name is the member holding it or, for objects, the member being read
+type rewriteFrame struct {
+	object    bool
+	name      string
+	expectKey bool
+	count     int
+}
+
+// Rewrite the scalar values of members named like ids, arrays pass their member name on to their elements
+func (c *Codec) rewrite(body []byte, convert func(name string, token json.Token) (json.Token, error)) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	out.Grow(len(body))
+	var stack []*rewriteFrame
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var top *rewriteFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && stack[len(stack)-1].object {
+				stack[len(stack)-1].expectKey = true
+			}
+			continue
+		}
+
+		name := ""
+		if top != nil {
+			if top.count > 0 && (!top.object || top.expectKey) {
+				out.WriteByte(',')
+			}
+			if top.object && top.expectKey {
+				key, _ := token.(string)
+				writeToken(&out, key)
+				out.WriteByte(':')
+				top.name, top.expectKey = key, false
+				top.count++
+				continue
+			}
+			if !top.object {
+				top.count++
+			}
+			name = top.name
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			out.WriteByte(byte(delim))
+			frame := &rewriteFrame{object: delim == '{', expectKey: delim == '{'}
+			if !frame.object {
+				frame.name = name
+			}
+			stack = append(stack, frame)
+			continue
+		}
+
+		if c.IsID(name) {
+			if token, err = convert(name, token); err != nil {
+				return nil, err
+			}
+		}
+		writeToken(&out, token)
+		if top != nil && top.object {
+			top.expectKey = true
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func writeToken(out *bytes.Buffer, token json.Token) {
+	switch value := token.(type) {
+	case nil:
+		out.WriteString("null")
+	case json.Number:
+		out.WriteString(value.String())
+	default:
+		encoded, _ := json.Marshal(value)
+		out.Write(encoded)
+	}
+}
@@ -0,0 +1,62 @@
+package idcodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Json serializer encoding the ids of every response written by Next, so it wraps the other serializers.
+// Requests are decoded by the binder and the id params middleware instead
+type Serializer struct {
+	Next  echo.JSONSerializer
+	Codec *Codec
+}
+
+// Serialize converts an interface into a json and writes it to the response
+func (s Serializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	response := c.Response()
+	writer := response.Writer
+	captured := &captureWriter{ResponseWriter: writer}
+	response.Writer = captured
+	err := s.Next.Serialize(c, i, indent)
+	response.Writer = writer
+	if err != nil {
+		return err
+	}
+
+	body, err := s.Codec.EncodeJSON(captured.body.Bytes())
+	if err != nil {
+		return err
+	}
+	if indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", indent); err != nil {
+			return err
+		}
+		body = indented.Bytes()
+	}
+	// Size counted the captured body, it is replaced by the encoded one
+	response.Size -= int64(captured.body.Len())
+	_, err = response.Write(append(body, '\n'))
+	return err
+}
+
+// Deserialize reads a json from a request body and converts it into an interface
+func (s Serializer) Deserialize(c echo.Context, i interface{}) error {
+	return s.Next.Deserialize(c, i)
+}
+
+// Response writer keeping the body, the status was written before serializing
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *captureWriter) WriteHeader(int) {}
@@ -0,0 +1,372 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// Job states, processing jobs are held in a list per worker and delayed ones wait in a sorted set for a retry
+const (
+	StatePending    = "pending"
+	StateProcessing = "processing"
+	StateDelayed    = "delayed"
+	StateDead       = "dead"
+)
+
+// Job outcomes counted by workers
+const (
+	OutcomeProcessed = "processed"
+	OutcomeFailed    = "failed"
+	OutcomeDead      = "dead"
+	OutcomeCancelled = "cancelled"
+)
+
+const (
+	scanPageSize   = 500
+	statsRetention = 24 * time.Hour
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrUnknownState = errors.New("unknown job state")
+)
+
+// Queue statistics, PerMinute holds outcome counts of the last minutes, oldest first
+type Stats struct {
+	Queue      string           `json:"queue"`
+	Pending    int64            `json:"pending"`
+	Processing int64            `json:"processing"`
+	Delayed    int64            `json:"delayed"`
+	Dead       int64            `json:"dead"`
+	Totals     map[string]int64 `json:"totals"`
+	PerMinute  []MinuteStats    `json:"per_minute"`
+	// Processed jobs per minute averaged over PerMinute
+	Throughput float64 `json:"throughput"`
+}
+
+// Outcome counts of one minute
+type MinuteStats struct {
+	Minute   time.Time        `json:"minute"`
+	Outcomes map[string]int64 `json:"outcomes"`
+}
+
+// Queue name
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Page of jobs in given state with the total count of the state. Lists are newest first, delayed jobs are
+// ordered by the time they are due again
+func (q *Queue) List(ctx context.Context, state string, offset, limit int) ([]*Job, int64, error) {
+	var raws []string
+	var total int64
+	switch state {
+	case StatePending, StateDead:
+		key := q.pendingKey()
+		if state == StateDead {
+			key = q.deadKey()
+		}
+		pipe := q.redisClient.Pipeline()
+		rangeCmd := pipe.LRange(ctx, key, int64(offset), int64(offset+limit-1))
+		lenCmd := pipe.LLen(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, 0, errors.Wrap(err, "Queue.List.pipe.Exec")
+		}
+		raws, total = rangeCmd.Val(), lenCmd.Val()
+	case StateDelayed:
+		pipe := q.redisClient.Pipeline()
+		rangeCmd := pipe.ZRange(ctx, q.delayedKey(), int64(offset), int64(offset+limit-1))
+		cardCmd := pipe.ZCard(ctx, q.delayedKey())
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, 0, errors.Wrap(err, "Queue.List.pipe.Exec")
+		}
+		raws, total = rangeCmd.Val(), cardCmd.Val()
+	case StateProcessing:
+		var err error
+		if raws, total, err = q.listProcessing(ctx, offset, limit); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, ErrUnknownState
+	}
+
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			return nil, 0, errors.Wrap(err, "Queue.List.json.Unmarshal")
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, total, nil
+}
+
+// Page of the processing lists of all workers taken one after the other
+func (q *Queue) listProcessing(ctx context.Context, offset, limit int) ([]string, int64, error) {
+	ids, lens, err := q.processingLens(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var raws []string
+	var total int64
+	skip := int64(offset)
+	for i, id := range ids {
+		total += lens[i]
+		if skip >= lens[i] {
+			skip -= lens[i]
+			continue
+		}
+		if len(raws) >= limit {
+			continue
+		}
+		page, err := q.redisClient.LRange(ctx, q.processingKey(id), skip, skip+int64(limit-len(raws))-1).Result()
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "Queue.listProcessing.LRange")
+		}
+		raws = append(raws, page...)
+		skip = 0
+	}
+	return raws, total, nil
+}
+
+// Registered worker ids with the lengths of their processing lists
+func (q *Queue) processingLens(ctx context.Context) ([]string, []int64, error) {
+	ids, err := q.workerIDs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipe := q.redisClient.Pipeline()
+	cmds := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.LLen(ctx, q.processingKey(id))
+	}
+	if len(ids) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, nil, errors.Wrap(err, "Queue.processingLens.pipe.Exec")
+		}
+	}
+	lens := make([]int64, len(ids))
+	for i, cmd := range cmds {
+		lens[i] = cmd.Val()
+	}
+	return ids, lens, nil
+}
+
+// Move a dead job back to pending with a fresh attempts budget
+func (q *Queue) Retry(ctx context.Context, id string) (*Job, error) {
+	raw, job, err := q.find(ctx, q.deadKey(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := q.redisClient.LRem(ctx, q.deadKey(), 1, raw).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Queue.Retry.LRem")
+	}
+	// Another admin retried or the list was purged in between
+	if removed == 0 {
+		return nil, ErrJobNotFound
+	}
+
+	job.Attempts = 0
+	job.RetryAt = nil
+	if err := q.push(ctx, q.pendingKey(), job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Cancel a job, pending and delayed jobs are removed, running ones get their context cancelled by the worker
+// holding them. Returns the state the job was cancelled in.
+func (q *Queue) Cancel(ctx context.Context, id string) (string, error) {
+	raw, _, err := q.find(ctx, q.pendingKey(), id)
+	if err == nil {
+		removed, err := q.redisClient.LRem(ctx, q.pendingKey(), 1, raw).Result()
+		if err != nil {
+			return "", errors.Wrap(err, "Queue.Cancel.LRem")
+		}
+		if removed > 0 {
+			q.recordOutcome(ctx, OutcomeCancelled)
+			return StatePending, nil
+		}
+	} else if !errors.Is(err, ErrJobNotFound) {
+		return "", err
+	}
+
+	raw, err = q.findDelayed(ctx, id)
+	if err == nil {
+		removed, err := q.redisClient.ZRem(ctx, q.delayedKey(), raw).Result()
+		if err != nil {
+			return "", errors.Wrap(err, "Queue.Cancel.ZRem")
+		}
+		if removed > 0 {
+			q.recordOutcome(ctx, OutcomeCancelled)
+			return StateDelayed, nil
+		}
+	} else if !errors.Is(err, ErrJobNotFound) {
+		return "", err
+	}
+
+	// Neither waiting nor due, it may have been picked up in the meantime
+	if err := q.findProcessing(ctx, id); err != nil {
+		return "", err
+	}
+	if err := q.redisClient.Publish(ctx, q.cancelChannel(), id).Err(); err != nil {
+		return "", errors.Wrap(err, "Queue.Cancel.Publish")
+	}
+	return StateProcessing, nil
+}
+
+// Drop every dead job, returns how many were dropped
+func (q *Queue) PurgeDead(ctx context.Context) (int64, error) {
+	pipe := q.redisClient.TxPipeline()
+	lenCmd := pipe.LLen(ctx, q.deadKey())
+	pipe.Del(ctx, q.deadKey())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Wrap(err, "Queue.PurgeDead.pipe.Exec")
+	}
+	return lenCmd.Val(), nil
+}
+
+// Queue lengths, outcome totals and per minute outcomes of the last minutes
+func (q *Queue) Stats(ctx context.Context, minutes int) (*Stats, error) {
+	now := time.Now().UTC().Truncate(time.Minute)
+
+	pipe := q.redisClient.Pipeline()
+	pendingCmd := pipe.LLen(ctx, q.pendingKey())
+	delayedCmd := pipe.ZCard(ctx, q.delayedKey())
+	deadCmd := pipe.LLen(ctx, q.deadKey())
+	totalsCmd := pipe.HGetAll(ctx, q.statsKey())
+	minuteCmds := make([]*redis.StringStringMapCmd, minutes)
+	for i := 0; i < minutes; i++ {
+		minuteCmds[i] = pipe.HGetAll(ctx, q.minuteStatsKey(now.Add(-time.Duration(minutes-1-i)*time.Minute)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "Queue.Stats.pipe.Exec")
+	}
+	_, lens, err := q.processingLens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{
+		Queue:     q.name,
+		Pending:   pendingCmd.Val(),
+		Delayed:   delayedCmd.Val(),
+		Dead:      deadCmd.Val(),
+		Totals:    parseCounts(totalsCmd.Val()),
+		PerMinute: make([]MinuteStats, 0, minutes),
+	}
+	for _, n := range lens {
+		stats.Processing += n
+	}
+	var processed int64
+	for i, cmd := range minuteCmds {
+		outcomes := parseCounts(cmd.Val())
+		processed += outcomes[OutcomeProcessed]
+		stats.PerMinute = append(stats.PerMinute, MinuteStats{
+			Minute:   now.Add(-time.Duration(minutes-1-i) * time.Minute),
+			Outcomes: outcomes,
+		})
+	}
+	if minutes > 0 {
+		stats.Throughput = float64(processed) / float64(minutes)
+	}
+	return stats, nil
+}
+
+// Count job outcome in the queue totals and the current minute bucket, best effort
+func (q *Queue) recordOutcome(ctx context.Context, outcome string) {
+	minuteKey := q.minuteStatsKey(time.Now().UTC())
+
+	pipe := q.redisClient.Pipeline()
+	pipe.HIncrBy(ctx, q.statsKey(), outcome, 1)
+	pipe.HIncrBy(ctx, minuteKey, outcome, 1)
+	pipe.Expire(ctx, minuteKey, statsRetention)
+	_, _ = pipe.Exec(ctx)
+}
+
+// Find job by id in a list, pages through the list so large dead lists are not loaded at once
+func (q *Queue) find(ctx context.Context, key string, id string) (string, *Job, error) {
+	for start := int64(0); ; start += scanPageSize {
+		page, err := q.redisClient.LRange(ctx, key, start, start+scanPageSize-1).Result()
+		if err != nil {
+			return "", nil, errors.Wrap(err, "Queue.find.LRange")
+		}
+		for _, raw := range page {
+			job := &Job{}
+			if err := json.Unmarshal([]byte(raw), job); err != nil {
+				continue
+			}
+			if job.ID == id {
+				return raw, job, nil
+			}
+		}
+		if len(page) < scanPageSize {
+			return "", nil, ErrJobNotFound
+		}
+	}
+}
+
+// Find job by id in the delayed set
+func (q *Queue) findDelayed(ctx context.Context, id string) (string, error) {
+	for start := int64(0); ; start += scanPageSize {
+		page, err := q.redisClient.ZRange(ctx, q.delayedKey(), start, start+scanPageSize-1).Result()
+		if err != nil {
+			return "", errors.Wrap(err, "Queue.findDelayed.ZRange")
+		}
+		for _, raw := range page {
+			job := &Job{}
+			if err := json.Unmarshal([]byte(raw), job); err == nil && job.ID == id {
+				return raw, nil
+			}
+		}
+		if len(page) < scanPageSize {
+			return "", ErrJobNotFound
+		}
+	}
+}
+
+// Find job by id in the processing lists of the workers
+func (q *Queue) findProcessing(ctx context.Context, id string) error {
+	ids, err := q.workerIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, workerID := range ids {
+		_, _, err := q.find(ctx, q.processingKey(workerID), id)
+		if !errors.Is(err, ErrJobNotFound) {
+			return err
+		}
+	}
+	return ErrJobNotFound
+}
+
+func (q *Queue) statsKey() string {
+	return q.name + ":stats"
+}
+
+func (q *Queue) minuteStatsKey(t time.Time) string {
+	return q.name + ":stats:" + strconv.FormatInt(t.Truncate(time.Minute).Unix(), 10)
+}
+
+func (q *Queue) cancelChannel() string {
+	return q.name + ":cancel"
+}
+
+func parseCounts(values map[string]string) map[string]int64 {
+	counts := make(map[string]int64, len(values))
+	for field, value := range values {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			counts[field] = n
+		}
+	}
+	return counts
+}
@@ -0,0 +1,139 @@
+package jobqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_List(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	ctx := context.Background()
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		job, err := queue.Enqueue(ctx, "scan", map[string]int{"file_id": i})
+		require.NoError(t, err)
+		ids = append(ids, job.ID)
+	}
+
+	// Newest first, the total counts the whole state
+	jobs, total, err := queue.List(ctx, StatePending, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Equal(t, []string{ids[2], ids[1]}, []string{jobs[0].ID, jobs[1].ID})
+	jobs, _, err = queue.List(ctx, StatePending, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, ids[0], jobs[0].ID)
+
+	jobs, total, err = queue.List(ctx, StateDead, 0, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, jobs)
+
+	_, _, err = queue.List(ctx, "running", 0, 10)
+	require.ErrorIs(t, err, ErrUnknownState)
+}
+
+func TestQueue_Retry(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	ctx := context.Background()
+
+	retryAt := time.Now()
+	require.NoError(t, queue.push(ctx, queue.deadKey(), &Job{ID: "job-1", Type: "scan", Attempts: 5, LastError: "timeout", RetryAt: &retryAt}))
+
+	// A retried job starts over with a fresh attempts budget
+	job, err := queue.Retry(ctx, "job-1")
+	require.NoError(t, err)
+	require.Zero(t, job.Attempts)
+	require.Nil(t, job.RetryAt)
+	jobs, total, err := queue.List(ctx, StatePending, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	require.Equal(t, "job-1", jobs[0].ID)
+	require.Equal(t, "timeout", jobs[0].LastError)
+
+	// Only dead jobs are retried
+	_, err = queue.Retry(ctx, "job-1")
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	ctx := context.Background()
+
+	pending, err := queue.Enqueue(ctx, "scan", nil)
+	require.NoError(t, err)
+	state, err := queue.Cancel(ctx, pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatePending, state)
+	stats, err := queue.Stats(ctx, 1)
+	require.NoError(t, err)
+	require.Zero(t, stats.Pending)
+	require.Equal(t, int64(1), stats.Totals[OutcomeCancelled])
+
+	// A running job is cancelled by the worker holding it, told over the cancel channel
+	require.NoError(t, queue.push(ctx, queue.processingKey("worker-1"), &Job{ID: "job-2", Type: "scan"}))
+	require.NoError(t, client.SAdd(ctx, queue.workersKey(), "worker-1").Err())
+	sub := client.Subscribe(ctx, queue.cancelChannel())
+	defer sub.Close()
+	_, err = sub.Receive(ctx)
+	require.NoError(t, err)
+	state, err = queue.Cancel(ctx, "job-2")
+	require.NoError(t, err)
+	require.Equal(t, StateProcessing, state)
+	msg, err := sub.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "job-2", msg.Payload)
+
+	_, err = queue.Cancel(ctx, "job-3")
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestQueue_PurgeDead(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	ctx := context.Background()
+
+	for _, id := range []string{"job-1", "job-2"} {
+		require.NoError(t, queue.push(ctx, queue.deadKey(), &Job{ID: id, Type: "scan"}))
+	}
+	purged, err := queue.PurgeDead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+	purged, err = queue.PurgeDead(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+}
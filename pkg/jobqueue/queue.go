@@ -0,0 +1,141 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Queued unit of work, Payload is decoded by the handler registered for Type
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	LastError  string          `json:"last_error,omitempty"`
+	// Time a failed job is due again, set while it waits in the delayed set
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+
+	maxAttempts int
+}
+
+// Whether a failure of the running attempt sends the job to the dead list
+func (j *Job) IsLastAttempt() bool {
+	return j.maxAttempts > 0 && j.Attempts >= j.maxAttempts
+}
+
+// Move due jobs of the delayed set to the pending list, returns how many were moved
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, raw in ipairs(due) do
+	redis.call('ZREM', KEYS[1], raw)
+	redis.call('LPUSH', KEYS[2], raw)
+end
+return #due
+`)
+
+// Redis list backed job queue
+type Queue struct {
+	redisClient *redis.Client
+	name        string
+}
+
+// Job queue constructor
+func NewQueue(redisClient *redis.Client, name string) *Queue {
+	return &Queue{redisClient: redisClient, name: name}
+}
+
+// Enqueue job of given type, payload is marshaled to json
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}) (*Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "Queue.Enqueue.json.Marshal")
+	}
+
+	job := &Job{
+		ID:         uuid.New().String(),
+		Type:       jobType,
+		Payload:    raw,
+		EnqueuedAt: time.Now().UTC(),
+	}
+	if err := q.push(ctx, q.pendingKey(), job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *Queue) push(ctx context.Context, key string, job *Job) error {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "Queue.push.json.Marshal")
+	}
+	if err := q.redisClient.LPush(ctx, key, jobBytes).Err(); err != nil {
+		return errors.Wrap(err, "Queue.push.LPush")
+	}
+	return nil
+}
+
+// Put a failed job into the delayed set until at
+func (q *Queue) schedule(ctx context.Context, job *Job, at time.Time) error {
+	retryAt := at.UTC()
+	job.RetryAt = &retryAt
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "Queue.schedule.json.Marshal")
+	}
+	if err := q.redisClient.ZAdd(ctx, q.delayedKey(), &redis.Z{Score: float64(at.UnixMilli()), Member: jobBytes}).Err(); err != nil {
+		return errors.Wrap(err, "Queue.schedule.ZAdd")
+	}
+	return nil
+}
+
+// Move delayed jobs due at now to the pending list, at most batch of them
+func (q *Queue) promote(ctx context.Context, now time.Time, batch int) (int, error) {
+	n, err := promoteScript.Run(ctx, q.redisClient, []string{q.delayedKey(), q.pendingKey()}, now.UnixMilli(), batch).Int()
+	if err != nil {
+		return 0, errors.Wrap(err, "Queue.promote.promoteScript.Run")
+	}
+	return n, nil
+}
+
+// Ids of the registered workers, sorted so pages of processing jobs are stable
+func (q *Queue) workerIDs(ctx context.Context) ([]string, error) {
+	ids, err := q.redisClient.SMembers(ctx, q.workersKey()).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Queue.workerIDs.SMembers")
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (q *Queue) pendingKey() string {
+	return q.name + ":pending"
+}
+
+// Processing list of one worker, jobs stay there while it runs them
+func (q *Queue) processingKey(workerID string) string {
+	return q.name + ":processing:" + workerID
+}
+
+func (q *Queue) delayedKey() string {
+	return q.name + ":delayed"
+}
+
+func (q *Queue) workersKey() string {
+	return q.name + ":workers"
+}
+
+// Expiring key a live worker keeps refreshing
+func (q *Queue) heartbeatKey(workerID string) string {
+	return q.name + ":heartbeat:" + workerID
+}
+
+func (q *Queue) deadKey() string {
+	return q.name + ":dead"
+}
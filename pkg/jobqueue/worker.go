@@ -0,0 +1,341 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultMaxAttempts       = 5
+	defaultPollTimeout       = 5 * time.Second
+	defaultConcurrency       = 1
+	defaultRetryBackoff      = 5 * time.Second
+	defaultMaxRetryBackoff   = 10 * time.Minute
+	defaultHeartbeatInterval = 10 * time.Second
+	// Missed heartbeats before a worker counts as dead and its jobs are reclaimed
+	heartbeatMisses = 3
+	promoteInterval = time.Second
+	promoteBatch    = 100
+)
+
+// Move the jobs of a worker without a heartbeat back to pending and forget the worker, -1 while it is alive
+var reclaimScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return -1
+end
+local n = 0
+while redis.call('RPOPLPUSH', KEYS[2], KEYS[3]) do
+	n = n + 1
+end
+redis.call('SREM', KEYS[4], ARGV[1])
+return n
+`)
+
+// Job handler, returning an error schedules a retry until attempts run out
+type Handler func(ctx context.Context, job *Job) error
+
+// Worker options, failed jobs wait RetryBackoff doubled per attempt up to MaxRetryBackoff before they run again
+type Options struct {
+	MaxAttempts       int
+	PollTimeout       time.Duration
+	Concurrency       int
+	RetryBackoff      time.Duration
+	MaxRetryBackoff   time.Duration
+	HeartbeatInterval time.Duration
+}
+
+// Worker consuming a queue, jobs are moved to a processing list of the worker while running and to a dead list
+// once exhausted. Workers keep a heartbeat, the jobs of one that stopped beating are requeued by the others
+type Worker struct {
+	id       string
+	queue    *Queue
+	opts     Options
+	logger   logger.Logger
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	runningMu sync.Mutex
+	running   map[string]context.CancelFunc
+}
+
+// Worker constructor
+func NewWorker(queue *Queue, opts Options, logger logger.Logger) *Worker {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.PollTimeout <= 0 {
+		opts.PollTimeout = defaultPollTimeout
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	if opts.MaxRetryBackoff <= 0 {
+		opts.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if opts.MaxRetryBackoff < opts.RetryBackoff {
+		opts.MaxRetryBackoff = opts.RetryBackoff
+	}
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	return &Worker{id: uuid.New().String(), queue: queue, opts: opts, logger: logger, handlers: make(map[string]Handler), running: make(map[string]context.CancelFunc)}
+}
+
+// Register handler for a job type
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[jobType] = handler
+}
+
+// Run consumers until ctx is cancelled, jobs of workers that stopped beating are requeued on start and on
+// every heartbeat. Jobs still running at the end go back to pending
+func (w *Worker) Run(ctx context.Context) {
+	if err := w.heartbeat(ctx); err != nil {
+		w.logger.Errorf("jobqueue.Worker heartbeat queue: %s, error: %v", w.queue.name, err)
+	}
+	w.reclaim(ctx)
+	defer w.stop()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		w.listenCancel(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		w.maintain(ctx)
+	}()
+
+	for i := 0; i < w.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.consume(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) consume(ctx context.Context) {
+	for ctx.Err() == nil {
+		raw, err := w.queue.redisClient.BRPopLPush(ctx, w.queue.pendingKey(), w.queue.processingKey(w.id), w.opts.PollTimeout).Result()
+		if err != nil {
+			if err != redis.Nil && ctx.Err() == nil {
+				w.logger.Errorf("jobqueue.Worker BRPopLPush queue: %s, error: %v", w.queue.name, err)
+				time.Sleep(w.opts.PollTimeout)
+			}
+			continue
+		}
+		w.process(ctx, raw)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, raw string) {
+	defer func() {
+		if err := w.queue.redisClient.LRem(context.Background(), w.queue.processingKey(w.id), 1, raw).Err(); err != nil {
+			w.logger.Errorf("jobqueue.Worker LRem queue: %s, error: %v", w.queue.name, err)
+		}
+	}()
+
+	job := &Job{}
+	if err := json.Unmarshal([]byte(raw), job); err != nil {
+		w.logger.Errorf("jobqueue.Worker json.Unmarshal queue: %s, error: %v", w.queue.name, err)
+		return
+	}
+
+	w.mu.RLock()
+	handler, ok := w.handlers[job.Type]
+	w.mu.RUnlock()
+	if !ok {
+		w.logger.Errorf("jobqueue.Worker no handler for job type: %s, id: %s", job.Type, job.ID)
+		w.bury(job)
+		return
+	}
+
+	job.Attempts++
+	job.maxAttempts = w.opts.MaxAttempts
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	w.track(job.ID, cancel)
+	err := handler(jobCtx, job)
+	w.untrack(job.ID)
+	cancelled := jobCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+
+	if cancelled {
+		w.logger.Warnf("jobqueue.Worker job cancelled type: %s, id: %s", job.Type, job.ID)
+		w.queue.recordOutcome(context.Background(), OutcomeCancelled)
+		return
+	}
+	if err != nil {
+		job.LastError = err.Error()
+		if job.Attempts >= w.opts.MaxAttempts {
+			w.logger.Errorf("jobqueue.Worker job exhausted type: %s, id: %s, attempts: %d, error: %v", job.Type, job.ID, job.Attempts, err)
+			w.bury(job)
+			return
+		}
+		backoff := w.backoff(job.Attempts)
+		w.logger.Warnf("jobqueue.Worker job failed type: %s, id: %s, attempt: %d, retry in: %s, error: %v", job.Type, job.ID, job.Attempts, backoff, err)
+		w.queue.recordOutcome(context.Background(), OutcomeFailed)
+		if err := w.queue.schedule(context.Background(), job, time.Now().Add(backoff)); err != nil {
+			w.logger.Errorf("jobqueue.Worker requeue id: %s, error: %v", job.ID, err)
+		}
+		return
+	}
+	w.queue.recordOutcome(context.Background(), OutcomeProcessed)
+}
+
+// Cancel running jobs of this worker named on the queue cancel channel
+func (w *Worker) listenCancel(ctx context.Context) {
+	pubsub := w.queue.redisClient.Subscribe(ctx, w.queue.cancelChannel())
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			w.runningMu.Lock()
+			if cancel, found := w.running[msg.Payload]; found {
+				cancel()
+			}
+			w.runningMu.Unlock()
+		}
+	}
+}
+
+func (w *Worker) track(id string, cancel context.CancelFunc) {
+	w.runningMu.Lock()
+	w.running[id] = cancel
+	w.runningMu.Unlock()
+}
+
+func (w *Worker) untrack(id string) {
+	w.runningMu.Lock()
+	delete(w.running, id)
+	w.runningMu.Unlock()
+}
+
+func (w *Worker) bury(job *Job) {
+	w.queue.recordOutcome(context.Background(), OutcomeDead)
+	if err := w.queue.push(context.Background(), w.queue.deadKey(), job); err != nil {
+		w.logger.Errorf("jobqueue.Worker bury id: %s, error: %v", job.ID, err)
+	}
+}
+
+// Delay before the next attempt of a job that failed attempts times
+func (w *Worker) backoff(attempts int) time.Duration {
+	backoff := w.opts.RetryBackoff
+	for i := 1; i < attempts && backoff < w.opts.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > w.opts.MaxRetryBackoff {
+		backoff = w.opts.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// Promote due retries and keep the heartbeat until ctx is done
+func (w *Worker) maintain(ctx context.Context) {
+	promote := time.NewTicker(promoteInterval)
+	defer promote.Stop()
+	beat := time.NewTicker(w.opts.HeartbeatInterval)
+	defer beat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-promote.C:
+			w.promote(ctx)
+		case <-beat.C:
+			if err := w.heartbeat(ctx); err != nil && ctx.Err() == nil {
+				w.logger.Errorf("jobqueue.Worker heartbeat queue: %s, error: %v", w.queue.name, err)
+			}
+			w.reclaim(ctx)
+		}
+	}
+}
+
+func (w *Worker) promote(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := w.queue.promote(ctx, time.Now(), promoteBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Errorf("jobqueue.Worker promote queue: %s, error: %v", w.queue.name, err)
+			}
+			return
+		}
+		if n < promoteBatch {
+			return
+		}
+	}
+}
+
+// Register the worker and refresh its heartbeat, it is re-registered in case another worker took it for dead
+func (w *Worker) heartbeat(ctx context.Context) error {
+	pipe := w.queue.redisClient.TxPipeline()
+	pipe.Set(ctx, w.queue.heartbeatKey(w.id), 1, heartbeatMisses*w.opts.HeartbeatInterval)
+	pipe.SAdd(ctx, w.queue.workersKey(), w.id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Requeue the jobs of registered workers without a heartbeat
+func (w *Worker) reclaim(ctx context.Context) {
+	ids, err := w.queue.workerIDs(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Errorf("jobqueue.Worker reclaim queue: %s, error: %v", w.queue.name, err)
+		}
+		return
+	}
+	for _, id := range ids {
+		if id == w.id {
+			continue
+		}
+		if err := w.reclaimWorker(ctx, id); err != nil && ctx.Err() == nil {
+			w.logger.Errorf("jobqueue.Worker reclaim queue: %s, worker: %s, error: %v", w.queue.name, id, err)
+		}
+	}
+}
+
+func (w *Worker) reclaimWorker(ctx context.Context, id string) error {
+	keys := []string{w.queue.heartbeatKey(id), w.queue.processingKey(id), w.queue.pendingKey(), w.queue.workersKey()}
+	n, err := reclaimScript.Run(ctx, w.queue.redisClient, keys, id).Int()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		w.logger.Warnf("jobqueue.Worker requeued %d job(s) of dead worker: %s, queue: %s", n, id, w.queue.name)
+	}
+	return nil
+}
+
+// Give the jobs still held back to pending and deregister, Run's ctx is done by now
+func (w *Worker) stop() {
+	ctx := context.Background()
+	if err := w.queue.redisClient.Del(ctx, w.queue.heartbeatKey(w.id)).Err(); err != nil {
+		w.logger.Errorf("jobqueue.Worker stop queue: %s, error: %v", w.queue.name, err)
+		return
+	}
+	if err := w.reclaimWorker(ctx, w.id); err != nil {
+		w.logger.Errorf("jobqueue.Worker stop queue: %s, error: %v", w.queue.name, err)
+	}
+}
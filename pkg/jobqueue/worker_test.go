@@ -0,0 +1,175 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Take the next pending job into the processing list of w like its consumers do
+func take(t *testing.T, w *Worker) string {
+	raw, err := w.queue.redisClient.RPopLPush(context.Background(), w.queue.pendingKey(), w.queue.processingKey(w.id)).Result()
+	require.NoError(t, err)
+	return raw
+}
+
+func TestWorker_Reclaim(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	ctx := context.Background()
+	w := NewWorker(queue, Options{HeartbeatInterval: time.Second}, appLogger)
+	live := NewWorker(queue, Options{HeartbeatInterval: time.Second}, appLogger)
+	dead := NewWorker(queue, Options{HeartbeatInterval: time.Second}, appLogger)
+
+	for _, worker := range []*Worker{w, live, dead} {
+		require.NoError(t, worker.heartbeat(ctx))
+		_, err := queue.Enqueue(ctx, "scan", map[string]int{"file_id": 1})
+		require.NoError(t, err)
+		take(t, worker)
+	}
+	stats, err := queue.Stats(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats.Processing)
+
+	// Jobs of live workers are left alone
+	w.reclaim(ctx)
+	require.False(t, server.Exists(queue.pendingKey()))
+
+	// Once a worker misses its heartbeats, another one requeues its jobs
+	server.Del(queue.heartbeatKey(dead.id))
+	w.reclaim(ctx)
+	pending, err := queue.redisClient.LLen(ctx, queue.pendingKey()).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pending)
+	ids, err := queue.workerIDs(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{w.id, live.id}, ids)
+
+	jobs, total, err := queue.List(ctx, StateProcessing, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Len(t, jobs, 1)
+
+	// A stopping worker gives its jobs back
+	live.stop()
+	pending, err = queue.redisClient.LLen(ctx, queue.pendingKey()).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), pending)
+
+	// The heartbeat expires after the missed beats
+	server.FastForward(heartbeatMisses * time.Second)
+	require.False(t, server.Exists(queue.heartbeatKey(w.id)))
+}
+
+func TestWorker_RetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	ctx := context.Background()
+	w := NewWorker(queue, Options{MaxAttempts: 3, RetryBackoff: time.Minute, MaxRetryBackoff: 90 * time.Second}, appLogger)
+	w.Handle("scan", func(ctx context.Context, job *Job) error {
+		return errors.New("scanner unavailable")
+	})
+
+	job, err := queue.Enqueue(ctx, "scan", map[string]int{"file_id": 1})
+	require.NoError(t, err)
+
+	// A failed job waits in the delayed set instead of running again right away
+	before := time.Now()
+	w.process(ctx, take(t, w))
+	jobs, total, err := queue.List(ctx, StateDelayed, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	require.Equal(t, job.ID, jobs[0].ID)
+	require.Equal(t, 1, jobs[0].Attempts)
+	require.Equal(t, "scanner unavailable", jobs[0].LastError)
+	require.WithinDuration(t, before.Add(time.Minute), *jobs[0].RetryAt, time.Second)
+
+	moved, err := queue.promote(ctx, before, promoteBatch)
+	require.NoError(t, err)
+	require.Zero(t, moved)
+	moved, err = queue.promote(ctx, before.Add(time.Minute+time.Second), promoteBatch)
+	require.NoError(t, err)
+	require.Equal(t, 1, moved)
+
+	// The backoff doubles up to its cap and the last attempt buries the job
+	w.process(ctx, take(t, w))
+	jobs, _, err = queue.List(ctx, StateDelayed, 0, 10)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(90*time.Second), *jobs[0].RetryAt, time.Second)
+	_, err = queue.promote(ctx, time.Now().Add(2*time.Minute), promoteBatch)
+	require.NoError(t, err)
+	w.process(ctx, take(t, w))
+
+	stats, err := queue.Stats(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), stats.Delayed)
+	require.Equal(t, int64(0), stats.Processing)
+	require.Equal(t, int64(1), stats.Dead)
+	require.Equal(t, int64(2), stats.Totals[OutcomeFailed])
+
+	// Retried dead jobs start over right away
+	retried, err := queue.Retry(ctx, job.ID)
+	require.NoError(t, err)
+	require.Nil(t, retried.RetryAt)
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	t.Parallel()
+
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	w := NewWorker(nil, Options{RetryBackoff: time.Second, MaxRetryBackoff: 10 * time.Second}, appLogger)
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 40: 10 * time.Second} {
+		require.Equal(t, want, w.backoff(attempts), attempts)
+	}
+
+	w = NewWorker(nil, Options{RetryBackoff: time.Hour}, appLogger)
+	require.Equal(t, time.Hour, w.backoff(3))
+}
+
+func TestQueue_CancelDelayed(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewQueue(client, "jobs")
+	ctx := context.Background()
+	job := &Job{ID: "job-1", Type: "scan"}
+	require.NoError(t, queue.schedule(ctx, job, time.Now().Add(time.Hour)))
+
+	state, err := queue.Cancel(ctx, "job-1")
+	require.NoError(t, err)
+	require.Equal(t, StateDelayed, state)
+	_, err = queue.Cancel(ctx, "job-1")
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	_, _, err = queue.List(ctx, "running", 0, 10)
+	require.ErrorIs(t, err, ErrUnknownState)
+}
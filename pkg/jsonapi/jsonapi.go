@@ -0,0 +1,83 @@
+package jsonapi
+
+import (
+	"mime"
+	"strings"
+)
+
+// JSON:API media type, responses use it when the Accept header asks for it
+const MediaType = "application/vnd.api+json"
+
+// Type and id of a resource
+type Identifier struct {
+	Type string
+	ID   int64
+}
+
+// Resource object, Attributes is a struct or map written as a json object without its id member
+type Resource struct {
+	Identifier
+	Attributes interface{}
+	// To-one relationships by name, their <name>_id foreign keys are dropped from the attributes
+	Relationships map[string]Identifier
+}
+
+// Page of a collection, TotalPages is zero when the total is not counted
+type Page struct {
+	Number     int
+	Size       int
+	TotalPages int
+	HasMore    bool
+}
+
+// Primary data of a response with the resources it relates to
+type Payload struct {
+	Data []*Resource
+	// Data is a single resource instead of a collection
+	Single   bool
+	Included []*Resource
+	// Pagination links are written for collections with a page
+	Page *Page
+	Meta map[string]interface{}
+}
+
+// Value with a JSON:API representation, written by the Serializer instead of the value itself
+type Marshaler interface {
+	JSONAPI() *Payload
+}
+
+// Payload of a single resource
+func One(resource *Resource) *Payload {
+	return &Payload{Data: []*Resource{resource}, Single: true}
+}
+
+// Payload of a collection
+func Many(resources []*Resource) *Payload {
+	return &Payload{Data: resources}
+}
+
+// Add resource to the included ones unless it is there already
+func (p *Payload) Include(resource *Resource) {
+	for _, included := range p.Included {
+		if included.Identifier == resource.Identifier {
+			return
+		}
+	}
+	p.Included = append(p.Included, resource)
+}
+
+// Does the Accept header ask for JSON:API. Instances of the media type with parameters other than q
+// do not count, like the spec requires
+func Accepts(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != MediaType {
+			continue
+		}
+		delete(params, "q")
+		if len(params) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Version written in the jsonapi member of documents
+const version = "1.0"
+
+// Json serializer writing Marshaler values as JSON:API documents when the request accepts the media type,
+// every other value and request is written by Next unchanged. EncodeID formats resource ids, decimal when nil
+type Serializer struct {
+	Next     echo.JSONSerializer
+	EncodeID func(int64) string
+}
+
+// Serialize converts an interface into a json and writes it to the response
+func (s Serializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	marshaler, ok := i.(Marshaler)
+	if !ok {
+		return s.Next.Serialize(c, i, indent)
+	}
+	// The representation depends on Accept, caches must keep both
+	header := c.Response().Header()
+	header.Add(echo.HeaderVary, echo.HeaderAccept)
+	if !Accepts(c.Request().Header.Get(echo.HeaderAccept)) {
+		return s.Next.Serialize(c, i, indent)
+	}
+
+	doc, err := s.document(c.Request().URL, marshaler.JSONAPI())
+	if err != nil {
+		return err
+	}
+	header.Set(echo.HeaderContentType, MediaType)
+	return s.Next.Serialize(c, doc, indent)
+}
+
+// Deserialize reads a json from a request body and converts it into an interface
+func (s Serializer) Deserialize(c echo.Context, i interface{}) error {
+	return s.Next.Deserialize(c, i)
+}
+
+type document struct {
+	Data     interface{}            `json:"data"`
+	Included []*resourceObject      `json:"included,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+	Links    *links                 `json:"links,omitempty"`
+	JSONAPI  map[string]string      `json:"jsonapi"`
+}
+
+type resourceObject struct {
+	Type          string                     `json:"type"`
+	ID            string                     `json:"id"`
+	Attributes    map[string]json.RawMessage `json:"attributes,omitempty"`
+	Relationships map[string]relationship    `json:"relationships,omitempty"`
+}
+
+type identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type relationship struct {
+	Data identifier `json:"data"`
+}
+
+type links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// Top level document of payload requested at u
+func (s Serializer) document(u *url.URL, payload *Payload) (*document, error) {
+	doc := &document{Meta: payload.Meta, JSONAPI: map[string]string{"version": version}}
+
+	data := make([]*resourceObject, 0, len(payload.Data))
+	for _, resource := range payload.Data {
+		object, err := s.resource(resource)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, object)
+	}
+	switch {
+	case !payload.Single:
+		doc.Data = data
+	case len(data) > 0:
+		doc.Data = data[0]
+	}
+
+	for _, resource := range payload.Included {
+		object, err := s.resource(resource)
+		if err != nil {
+			return nil, err
+		}
+		doc.Included = append(doc.Included, object)
+	}
+
+	doc.Links = &links{Self: u.RequestURI()}
+	if payload.Page != nil && !payload.Single {
+		pageLinks(doc.Links, u, payload.Page)
+	}
+	return doc, nil
+}
+
+func (s Serializer) resource(resource *Resource) (*resourceObject, error) {
+	object := &resourceObject{Type: resource.Type, ID: s.id(resource.ID)}
+
+	if resource.Attributes != nil {
+		encoded, err := json.Marshal(resource.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &object.Attributes); err != nil {
+			return nil, err
+		}
+		// The id is a member of the resource object, not an attribute
+		delete(object.Attributes, "id")
+	}
+
+	if len(resource.Relationships) > 0 {
+		object.Relationships = make(map[string]relationship, len(resource.Relationships))
+		for name, related := range resource.Relationships {
+			delete(object.Attributes, name+"_id")
+			object.Relationships[name] = relationship{Data: identifier{Type: related.Type, ID: s.id(related.ID)}}
+		}
+	}
+	return object, nil
+}
+
+func (s Serializer) id(id int64) string {
+	if s.EncodeID != nil {
+		return s.EncodeID(id)
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// Links to the first, previous, next and last pages, keeping the other query params of u.
+// Pages are numbered from 1, page 0 is the first one too
+func pageLinks(l *links, u *url.URL, page *Page) {
+	number := page.Number
+	if number < 1 {
+		number = 1
+	}
+	l.First = pageURL(u, 1, page.Size)
+	if number > 1 {
+		l.Prev = pageURL(u, number-1, page.Size)
+	}
+	if page.HasMore {
+		l.Next = pageURL(u, number+1, page.Size)
+	}
+	if page.TotalPages > 0 {
+		l.Last = pageURL(u, page.TotalPages, page.Size)
+	}
+}
+
+func pageURL(u *url.URL, number int, size int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(number))
+	query.Set("size", strconv.Itoa(size))
+	paged := *u
+	paged.RawQuery = query.Encode()
+	return paged.RequestURI()
+}
@@ -0,0 +1,131 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	OwnerID int64  `json:"owner_id"`
+}
+
+func (a *account) JSONAPI() *Payload {
+	owner := &Resource{Identifier: Identifier{Type: "users", ID: a.OwnerID}, Attributes: map[string]string{"username": "ann"}}
+	payload := One(&Resource{
+		Identifier:    Identifier{Type: "accounts", ID: a.ID},
+		Attributes:    a,
+		Relationships: map[string]Identifier{"owner": owner.Identifier},
+	})
+	payload.Include(owner)
+	payload.Include(owner)
+	return payload
+}
+
+type accountsPage struct {
+	Accounts []*account `json:"accounts"`
+}
+
+func (p *accountsPage) JSONAPI() *Payload {
+	resources := make([]*Resource, 0, len(p.Accounts))
+	for _, a := range p.Accounts {
+		resources = append(resources, &Resource{Identifier: Identifier{Type: "accounts", ID: a.ID}, Attributes: a})
+	}
+	payload := Many(resources)
+	payload.Page = &Page{Number: 2, Size: 1, TotalPages: 3, HasMore: true}
+	payload.Meta = map[string]interface{}{"total_count": 3}
+	return payload
+}
+
+func serve(e *echo.Echo, target string, accept string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	return rec, body
+}
+
+func TestSerializer(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.JSONSerializer = Serializer{Next: echo.DefaultJSONSerializer{}}
+	e.GET("/accounts/7", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &account{ID: 7, Name: "acme", OwnerID: 3})
+	})
+	e.GET("/accounts", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &accountsPage{Accounts: []*account{{ID: 8, Name: "initech", OwnerID: 3}}})
+	})
+	e.GET("/plain", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "OK"})
+	})
+
+	// Plain json unless asked for
+	rec, body := serve(e, "/accounts/7", "application/json")
+	require.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	require.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+	require.Equal(t, "acme", body["name"])
+
+	rec, body = serve(e, "/accounts/7", "application/json, "+MediaType)
+	require.Equal(t, MediaType, rec.Header().Get(echo.HeaderContentType))
+	require.Equal(t, map[string]interface{}{
+		"type":       "accounts",
+		"id":         "7",
+		"attributes": map[string]interface{}{"name": "acme"},
+		"relationships": map[string]interface{}{
+			"owner": map[string]interface{}{"data": map[string]interface{}{"type": "users", "id": "3"}},
+		},
+	}, body["data"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"type": "users", "id": "3", "attributes": map[string]interface{}{"username": "ann"}},
+	}, body["included"])
+	require.Equal(t, map[string]interface{}{"self": "/accounts/7"}, body["links"])
+	require.Equal(t, map[string]interface{}{"version": "1.0"}, body["jsonapi"])
+
+	rec, body = serve(e, "/accounts?page=2&size=1&orderBy=name", MediaType)
+	require.Equal(t, MediaType, rec.Header().Get(echo.HeaderContentType))
+	require.Len(t, body["data"], 1)
+	require.Equal(t, map[string]interface{}{"total_count": float64(3)}, body["meta"])
+	require.Equal(t, map[string]interface{}{
+		"self":  "/accounts?page=2&size=1&orderBy=name",
+		"first": "/accounts?orderBy=name&page=1&size=1",
+		"prev":  "/accounts?orderBy=name&page=1&size=1",
+		"next":  "/accounts?orderBy=name&page=3&size=1",
+		"last":  "/accounts?orderBy=name&page=3&size=1",
+	}, body["links"])
+
+	// Other values are untouched
+	rec, body = serve(e, "/plain", MediaType)
+	require.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	require.Empty(t, rec.Header().Get(echo.HeaderVary))
+	require.Equal(t, "OK", body["status"])
+
+	// Ids go through EncodeID
+	e.JSONSerializer = Serializer{Next: echo.DefaultJSONSerializer{}, EncodeID: func(id int64) string { return "x" + string(rune('0'+id)) }}
+	_, body = serve(e, "/accounts/7", MediaType)
+	require.Equal(t, "x7", body["data"].(map[string]interface{})["id"])
+	require.Equal(t, "x3", body["included"].([]interface{})[0].(map[string]interface{})["id"])
+}
+
+func TestAccepts(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Accepts(MediaType))
+	require.True(t, Accepts("text/html, "+MediaType+";q=0.9"))
+	require.True(t, Accepts(MediaType+`;ext="https://example.com/ext", `+MediaType))
+	require.False(t, Accepts(MediaType+`;ext="https://example.com/ext"`))
+	require.False(t, Accepts("application/json"))
+	require.False(t, Accepts("*/*"))
+	require.False(t, Accepts(""))
+}
@@ -0,0 +1,28 @@
+package jwks
+
+import (
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+// Verifier for the issuers of the app config, e.g. a Keycloak realm or an Auth0 tenant. Without issuers it trusts
+// nothing
+func NewFromConfig(cfg *config.Config, logger logger.Logger) *Verifier {
+	issuers := make([]*Issuer, 0, len(cfg.JWTIssuers))
+	for name, issuerCfg := range cfg.JWTIssuers {
+		issuers = append(issuers, &Issuer{
+			Name:       name,
+			Issuer:     issuerCfg.Issuer,
+			Audience:   issuerCfg.Audience,
+			Algorithms: issuerCfg.Algorithms,
+			Leeway:     time.Duration(issuerCfg.LeewaySeconds) * time.Second,
+			Keys: NewKeySet(issuerCfg.JWKSURL, KeySetOptions{
+				CacheTTL:   time.Duration(issuerCfg.CacheSeconds) * time.Second,
+				MinRefresh: time.Duration(issuerCfg.MinRefreshSeconds) * time.Second,
+			}, logger),
+		})
+	}
+	return NewVerifier(issuers...)
+}
@@ -0,0 +1,168 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const testIssuer = "https://idp.example.com/"
+
+// Issuer test double publishing its current keys
+type fakeIdP struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int32
+}
+
+func (f *fakeIdP) rotate(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	f.mu.Lock()
+	f.keys = map[string]*rsa.PrivateKey{kid: key}
+	f.mu.Unlock()
+}
+
+func (f *fakeIdP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&f.fetches, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	set := jsonWebKeySet{}
+	for kid, key := range f.keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(set)
+}
+
+func (f *fakeIdP) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	f.mu.Lock()
+	key := f.keys[kid]
+	f.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func claims(overrides jwt.MapClaims) jwt.MapClaims {
+	c := jwt.MapClaims{
+		"iss":   testIssuer,
+		"sub":   "user-1",
+		"aud":   []string{"account", "user-service"},
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"email": "user@example.com",
+	}
+	for k, v := range overrides {
+		c[k] = v
+	}
+	return c
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	idp := &fakeIdP{}
+	idp.rotate(t, "k1")
+	srv := httptest.NewServer(idp)
+	defer srv.Close()
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	v := NewVerifier(&Issuer{
+		Name:     "idp",
+		Issuer:   testIssuer,
+		Audience: "user-service",
+		Leeway:   time.Second,
+		Keys:     NewKeySet(srv.URL, KeySetOptions{MinRefresh: time.Nanosecond}, appLogger),
+	})
+	ctx := context.Background()
+
+	tokenString := idp.sign(t, "k1", claims(nil))
+	require.True(t, v.Trusts(tokenString))
+	token, err := v.Verify(ctx, tokenString)
+	require.NoError(t, err)
+	require.Equal(t, "idp", token.Issuer.Name)
+	require.Equal(t, "user@example.com", token.Claim("email"))
+
+	// Cached keys are reused
+	_, err = v.Verify(ctx, tokenString)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&idp.fetches))
+
+	// Unknown kid after a rotation refetches the set
+	idp.rotate(t, "k2")
+	_, err = v.Verify(ctx, idp.sign(t, "k2", claims(nil)))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, atomic.LoadInt32(&idp.fetches))
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	idp := &fakeIdP{}
+	idp.rotate(t, "k1")
+	srv := httptest.NewServer(idp)
+	defer srv.Close()
+	appLogger := logger.NewApiLogger(&config.Config{})
+	appLogger.InitLogger()
+	v := NewVerifier(&Issuer{
+		Name:     "idp",
+		Issuer:   testIssuer,
+		Audience: "user-service",
+		Leeway:   time.Second,
+		Keys:     NewKeySet(srv.URL, KeySetOptions{MinRefresh: time.Nanosecond}, appLogger),
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		err    error
+	}{
+		{"expired", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), ErrInvalidToken},
+		{"missing exp", claims(jwt.MapClaims{"exp": nil}), ErrInvalidToken},
+		{"not yet valid", claims(jwt.MapClaims{"nbf": time.Now().Add(time.Minute).Unix()}), ErrInvalidToken},
+		{"wrong audience", claims(jwt.MapClaims{"aud": "account"}), ErrInvalidToken},
+		{"untrusted issuer", claims(jwt.MapClaims{"iss": "https://evil.example.com/"}), ErrUntrustedIssuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(ctx, idp.sign(t, "k1", tt.claims))
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("hmac algorithm", func(t *testing.T) {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		_, err = v.Verify(ctx, signed)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		other := &fakeIdP{}
+		other.rotate(t, "k9")
+		_, err := v.Verify(ctx, other.sign(t, "k9", claims(nil)))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
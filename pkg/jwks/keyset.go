@@ -0,0 +1,217 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpclient"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	defaultCacheTTL   = time.Hour
+	defaultMinRefresh = time.Minute
+	defaultTimeout    = 5 * time.Second
+)
+
+// ErrKeyNotFound no key with the token kid, even after a refresh
+var ErrKeyNotFound = errors.New("jwks: signing key not found")
+
+// Json web key as published by the issuer, only public key fields are read
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// Key set options, zero values use defaults
+type KeySetOptions struct {
+	// How long fetched keys are trusted before the set is fetched again
+	CacheTTL time.Duration
+	// Lowest interval between refreshes triggered by unknown key ids
+	MinRefresh time.Duration
+	Timeout    time.Duration
+}
+
+// Cached public keys of a JWKS url.
+// Keys are fetched lazily, refetched once CacheTTL passes and when a token names an unknown kid,
+// so issuer key rotation is picked up without restarts. A failed refresh keeps serving the previous keys.
+type KeySet struct {
+	client *httpclient.Client
+	opts   KeySetOptions
+	logger logger.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	refreshedAt time.Time
+	// Serializes fetches so a burst of unknown kids hits the issuer once
+	fetchMu sync.Mutex
+}
+
+// Key set constructor
+func NewKeySet(url string, opts KeySetOptions, logger logger.Logger) *KeySet {
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = defaultCacheTTL
+	}
+	if opts.MinRefresh <= 0 {
+		opts.MinRefresh = defaultMinRefresh
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	return &KeySet{
+		client: httpclient.New("jwks", url, httpclient.Options{Timeout: opts.Timeout, Retries: 1}, logger),
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Public key by key id, an empty kid matches the only key of a single key set
+func (k *KeySet) Key(ctx context.Context, kid string) (interface{}, error) {
+	key, found, fresh := k.lookup(kid)
+	if found && fresh {
+		return key, nil
+	}
+	if err := k.refresh(ctx, !fresh); err != nil {
+		if found {
+			k.logger.Warnf("jwks refresh failed, using cached keys: %v", err)
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, found, _ = k.lookup(kid); found {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (k *KeySet) lookup(kid string) (key interface{}, found bool, fresh bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	fresh = k.keys != nil && time.Since(k.fetchedAt) < k.opts.CacheTTL
+	if kid == "" && len(k.keys) == 1 {
+		for _, key = range k.keys {
+			return key, true, fresh
+		}
+	}
+	key, found = k.keys[kid]
+	return key, found, fresh
+}
+
+// Fetch the key set, refreshes for unknown kids are throttled by MinRefresh unless the cache expired
+func (k *KeySet) refresh(ctx context.Context, expired bool) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.RLock()
+	throttled := !k.refreshedAt.IsZero() && time.Since(k.refreshedAt) < k.opts.MinRefresh
+	if expired {
+		// Another caller may have refreshed while this one waited for fetchMu
+		throttled = k.keys != nil && time.Since(k.fetchedAt) < k.opts.CacheTTL
+	}
+	k.mu.RUnlock()
+	if throttled {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.refreshedAt = time.Now()
+	k.mu.Unlock()
+
+	var set jsonWebKeySet
+	if err := k.client.Get(ctx, "", &set); err != nil {
+		return errors.Wrap(err, "jwks.KeySet.refresh")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			k.logger.Warnf("jwks skipping key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+func (j jsonWebKey) publicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "base64url")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
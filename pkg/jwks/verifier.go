@@ -0,0 +1,153 @@
+package jwks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUntrustedIssuer token iss is not a configured issuer
+	ErrUntrustedIssuer = errors.New("jwks: untrusted issuer")
+	// ErrInvalidToken signature, algorithm or registered claims check failed
+	ErrInvalidToken = errors.New("jwks: invalid token")
+)
+
+// Trusted token issuer
+type Issuer struct {
+	// Config name of the issuer, e.g. keycloak
+	Name string
+	// Expected iss claim
+	Issuer string
+	// Expected aud claim, empty skips the check
+	Audience string
+	// Accepted signing algorithms, RS256 when empty
+	Algorithms []string
+	// Allowed clock skew for exp, nbf and iat
+	Leeway time.Duration
+	Keys   *KeySet
+}
+
+// Verified token
+type Token struct {
+	Issuer *Issuer
+	Claims jwt.MapClaims
+}
+
+// String claim or empty string
+func (t *Token) Claim(name string) string {
+	value, _ := t.Claims[name].(string)
+	return value
+}
+
+// Verifies tokens signed by any of the trusted issuers
+type Verifier struct {
+	issuers map[string]*Issuer
+	now     func() time.Time
+}
+
+// Verifier constructor, issuers are matched by their iss claim
+func NewVerifier(issuers ...*Issuer) *Verifier {
+	v := &Verifier{issuers: make(map[string]*Issuer, len(issuers)), now: time.Now}
+	for _, issuer := range issuers {
+		v.issuers[issuer.Issuer] = issuer
+	}
+	return v
+}
+
+// True when iss of the unverified token belongs to a trusted issuer
+func (v *Verifier) Trusts(tokenString string) bool {
+	if v == nil || len(v.issuers) == 0 {
+		return false
+	}
+	_, ok := v.issuer(tokenString)
+	return ok
+}
+
+// Verify signature against the issuer key set, the algorithm allow list and the registered claims
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Token, error) {
+	issuer, ok := v.issuer(tokenString)
+	if !ok {
+		return nil, ErrUntrustedIssuer
+	}
+
+	parser := &jwt.Parser{ValidMethods: issuer.algorithms(), SkipClaimsValidation: true}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return issuer.Keys.Key(ctx, kid)
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Inner != nil && errors.Is(validationErr.Inner, ErrKeyNotFound) {
+			return nil, errors.Wrap(ErrKeyNotFound, issuer.Name)
+		}
+		return nil, errors.Wrapf(ErrInvalidToken, "%s: %v", issuer.Name, err)
+	}
+
+	if err = issuer.validateClaims(claims, v.now()); err != nil {
+		return nil, errors.Wrapf(ErrInvalidToken, "%s: %v", issuer.Name, err)
+	}
+	return &Token{Issuer: issuer, Claims: claims}, nil
+}
+
+// Issuer of the token, read before the signature is checked
+func (v *Verifier) issuer(tokenString string) (*Issuer, bool) {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return nil, false
+	}
+	iss, _ := claims["iss"].(string)
+	issuer, ok := v.issuers[iss]
+	return issuer, ok
+}
+
+func (i *Issuer) algorithms() []string {
+	if len(i.Algorithms) == 0 {
+		return []string{jwt.SigningMethodRS256.Alg()}
+	}
+	return i.Algorithms
+}
+
+// Registered claims with leeway, aud may be a string or an array
+func (i *Issuer) validateClaims(claims jwt.MapClaims, now time.Time) error {
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return errors.New("missing exp")
+	}
+	if now.After(time.Unix(exp, 0).Add(i.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(i.Leeway).Before(time.Unix(nbf, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if iat, ok := numericClaim(claims, "iat"); ok && now.Add(i.Leeway).Before(time.Unix(iat, 0)) {
+		return errors.New("token used before issued")
+	}
+	if i.Audience != "" && !hasAudience(claims["aud"], i.Audience) {
+		return fmt.Errorf("audience does not contain %q", i.Audience)
+	}
+	return nil
+}
+
+func numericClaim(claims jwt.MapClaims, name string) (int64, bool) {
+	value, ok := claims[name].(float64)
+	return int64(value), ok
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Log levels by module, changed at runtime. A module logs at the level of its longest overridden
+// prefix, "internal" covers "internal/session", or at the configured level without one.
+type Levels struct {
+	fallback  zapcore.Level
+	mu        sync.RWMutex
+	overrides map[string]zapcore.Level
+	modules   map[string]struct{}
+}
+
+// Module level overview
+type ModuleLevel struct {
+	Module     string `json:"module"`
+	Level      string `json:"level"`
+	Overridden bool   `json:"overridden"`
+}
+
+func newLevels(fallback zapcore.Level) *Levels {
+	return &Levels{fallback: fallback, overrides: make(map[string]zapcore.Level), modules: make(map[string]struct{})}
+}
+
+// Check level name, true for the names the logger config accepts
+func ValidLevel(name string) bool {
+	_, ok := loggerLevelMap[name]
+	return ok
+}
+
+// Configured level every module without override logs at
+func (l *Levels) Default() string {
+	return l.fallback.String()
+}
+
+// Replace every override, unknown level names are skipped
+func (l *Levels) Replace(overrides map[string]string) {
+	parsed := make(map[string]zapcore.Level, len(overrides))
+	for module, name := range overrides {
+		if level, ok := loggerLevelMap[name]; ok {
+			parsed[module] = level
+		}
+	}
+
+	l.mu.Lock()
+	l.overrides = parsed
+	l.mu.Unlock()
+}
+
+// Named modules and overridden prefixes with the level they log at, sorted by module
+func (l *Levels) List() []ModuleLevel {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	modules := make(map[string]struct{}, len(l.modules)+len(l.overrides))
+	for module := range l.modules {
+		modules[module] = struct{}{}
+	}
+	for module := range l.overrides {
+		modules[module] = struct{}{}
+	}
+
+	list := make([]ModuleLevel, 0, len(modules))
+	for module := range modules {
+		level, overridden := l.level(module)
+		list = append(list, ModuleLevel{Module: module, Level: level.String(), Overridden: overridden})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Module < list[j].Module })
+	return list
+}
+
+// Whether module logs entries of level
+func (l *Levels) Enabled(module string, level zapcore.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	enabled, _ := l.level(module)
+	return level >= enabled
+}
+
+func (l *Levels) register(module string) {
+	l.mu.Lock()
+	l.modules[module] = struct{}{}
+	l.mu.Unlock()
+}
+
+func (l *Levels) level(module string) (zapcore.Level, bool) {
+	for prefix := module; prefix != ""; {
+		if level, ok := l.overrides[prefix]; ok {
+			return level, true
+		}
+		slash := strings.LastIndex(prefix, "/")
+		if slash < 0 {
+			break
+		}
+		prefix = prefix[:slash]
+	}
+	return l.fallback, false
+}
+
+// Core logging at the level of its module
+type moduleCore struct {
+	zapcore.Core
+	levels *Levels
+	module string
+}
+
+func (c *moduleCore) Enabled(level zapcore.Level) bool {
+	return c.levels.Enabled(c.module, level)
+}
+
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{Core: c.Core.With(fields), levels: c.levels, module: c.module}
+}
+
+func (c *moduleCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
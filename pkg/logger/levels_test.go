@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestLevels(t *testing.T) {
+	t.Parallel()
+
+	appLogger := NewApiLogger(&config.Config{Logger: config.Logger{Level: "info"}})
+	appLogger.InitLogger()
+	appLogger.Named("internal/session")
+	appLogger.Named("internal/auth")
+	levels := appLogger.Levels()
+
+	require.False(t, levels.Enabled("internal/session", zapcore.DebugLevel))
+
+	// Longest overridden prefix wins
+	levels.Replace(map[string]string{"internal": "error", "internal/session": "debug", "pkg": "verbose"})
+	require.True(t, levels.Enabled("internal/session", zapcore.DebugLevel))
+	require.True(t, levels.Enabled("internal/session/repository", zapcore.DebugLevel))
+	require.False(t, levels.Enabled("internal/auth", zapcore.WarnLevel))
+	require.False(t, levels.Enabled("internal/sessions", zapcore.DebugLevel))
+	require.True(t, levels.Enabled("pkg", zapcore.InfoLevel))
+	require.False(t, levels.Enabled("", zapcore.DebugLevel))
+
+	require.Equal(t, []ModuleLevel{
+		{Module: "internal", Level: "error", Overridden: true},
+		{Module: "internal/auth", Level: "error", Overridden: true},
+		{Module: "internal/session", Level: "debug", Overridden: true},
+	}, levels.List())
+
+	levels.Replace(nil)
+	require.Equal(t, "info", levels.Default())
+	require.Equal(t, []ModuleLevel{
+		{Module: "internal/auth", Level: "info"},
+		{Module: "internal/session", Level: "info"},
+	}, levels.List())
+}
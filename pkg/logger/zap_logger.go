@@ -24,12 +24,18 @@ type Logger interface {
 	DPanicf(template string, args ...interface{})
 	Fatal(args ...interface{})
 	Fatalf(template string, args ...interface{})
+	// Logger of a module, e.g. "internal/session", logging at the level set for it at runtime
+	Named(module string) Logger
+	// Runtime log levels shared by the logger and every module logger
+	Levels() *Levels
 }
 
 // Logger
 type apiLogger struct {
 	cfg         *config.Config
 	sugarLogger *zap.SugaredLogger
+	base        *zap.Logger
+	levels      *Levels
 }
 
 // App Logger constructor
@@ -84,15 +90,39 @@ func (l *apiLogger) InitLogger() {
 	}
 
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	core := zapcore.NewCore(encoder, logWriter, zap.NewAtomicLevelAt(logLevel))
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	// Levels are checked by the module core, the base core takes every entry
+	core := zapcore.NewCore(encoder, logWriter, zap.NewAtomicLevelAt(zapcore.DebugLevel))
+	l.base = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	l.levels = newLevels(logLevel)
 
-	l.sugarLogger = logger.Sugar()
+	l.sugarLogger = l.base.WithOptions(l.moduleCore("")).Sugar()
 	if err := l.sugarLogger.Sync(); err != nil {
 		l.sugarLogger.Error(err)
 	}
 }
 
+// Logger of a module, entries are named after it
+func (l *apiLogger) Named(module string) Logger {
+	l.levels.register(module)
+	return &apiLogger{
+		cfg:         l.cfg,
+		sugarLogger: l.base.Named(module).WithOptions(l.moduleCore(module)).Sugar(),
+		base:        l.base,
+		levels:      l.levels,
+	}
+}
+
+// Runtime log levels
+func (l *apiLogger) Levels() *Levels {
+	return l.levels
+}
+
+func (l *apiLogger) moduleCore(module string) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &moduleCore{Core: core, levels: l.levels, module: module}
+	})
+}
+
 // Logger methods
 
 func (l *apiLogger) Debug(args ...interface{}) {
@@ -1,18 +1,40 @@
 package metric
 
 import (
+	"context"
 	"log"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/tracing"
 )
 
-// App Metrics interface
+// App Metrics interface, durations observed with a ctx carry the trace id of its sampled span as exemplar
 type Metrics interface {
 	IncHits(status int, method, path string)
-	ObserveResponseTime(status int, method, path string, observeTime float64)
+	ObserveResponseTime(ctx context.Context, status int, method, path string, observeTime float64)
+	IncSessionErrors(kind string)
+	IncDedupCalls(method string, shared bool)
+	IncCacheLookups(cache, result string)
+	SetSLOBurnRate(slo, sli, window string, rate float64)
+	SetSLOBudgetRemaining(slo, sli string, remaining float64)
+	ObserveUseCase(ctx context.Context, method, status string, seconds float64)
+	IncSignupRejections(reason string)
+	IncShadowComparisons(method, result string)
+	ObserveSessionStore(op string, seconds float64)
+	ObserveSessionStoreClass(class, result string, seconds float64)
+	IncPermissionLookups(source string)
+	IncDeprecatedFields(client, path, field string)
+	IncSessionEvents(eventType string)
+	IncRiskDecisions(decision string)
+	IncHedgedReads(method, result string)
+	IncRequestSchemaFailures(path, field string)
+	IncCompactionRuns(job, result string)
+	AddCompactedItems(job string, dryRun bool, count int)
+	ObserveCanary(ctx context.Context, route, variant string, status int, seconds float64)
 }
 
 // Prometheus Metrics struct
@@ -20,6 +42,47 @@ type PrometheusMetrics struct {
 	HitsTotal prometheus.Counter
 	Hits      *prometheus.CounterVec
 	Times     *prometheus.HistogramVec
+	// Session layer failures by kind
+	SessionErrors *prometheus.CounterVec
+	// Deduplicated calls by method, shared="true" calls got a result shared with other callers
+	DedupCalls *prometheus.CounterVec
+	// Cache lookups by cache name and result, result is fresh, stale or miss
+	CacheLookups *prometheus.CounterVec
+	// Error budget burn rate by objective, sli and window, 1 spends the budget exactly over the SLO window
+	SLOBurnRate *prometheus.GaugeVec
+	// Unspent fraction of the error budget by objective and sli, negative once exhausted
+	SLOBudgetRemaining *prometheus.GaugeVec
+	// Usecase call duration by method and status, recorded by the generated decorators
+	UseCaseTimes *prometheus.HistogramVec
+	// Registrations refused by the email domain policy by reason
+	SignupRejections *prometheus.CounterVec
+	// Shadow backend comparisons by repository method and result, result is match, diverged or dropped
+	ShadowComparisons *prometheus.CounterVec
+	// Session store operation duration by op
+	SessionStoreTimes *prometheus.HistogramVec
+	// Session store call duration by operation class and result, class is read or write, result is ok,
+	// retried, fallback or failed
+	SessionStoreClassTimes *prometheus.HistogramVec
+	// Role permission lookups by the layer which answered them, source is request, local, redis or db
+	PermissionLookups *prometheus.CounterVec
+	// Requests setting or receiving a deprecated DTO field by client, route and json path of the field
+	DeprecatedFields *prometheus.CounterVec
+	// Session lifecycle events by type, a rise of revoked or hijack_suspected events is worth an alert
+	SessionEvents *prometheus.CounterVec
+	// Login risk assessments by decision, allow, alert, step_up or block
+	RiskDecisions *prometheus.CounterVec
+	// Replica reads by repository method and hedging result, unhedged, first or hedge. A high share of hedge
+	// wins means the delay is below the usual latency
+	HedgedReads *prometheus.CounterVec
+	// Requests rejected by the request schema middleware by route and failing field
+	RequestSchemaFailures *prometheus.CounterVec
+	// Compaction job runs by job and result, ok or error
+	CompactionRuns *prometheus.CounterVec
+	// Items removed by compaction jobs, mode is dry_run for items only counted
+	CompactedItems *prometheus.CounterVec
+	// Canary routed request duration by route, variant and status, variants are compared to stable by their
+	// error rate and latency
+	CanaryTimes *prometheus.HistogramVec
 }
 
 // Create metrics with address and name
@@ -55,13 +118,228 @@ func CreateMetrics(address string, name string) (Metrics, error) {
 		return nil, err
 	}
 
+	metr.SessionErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_session_errors",
+		},
+		[]string{"kind"},
+	)
+
+	if err := prometheus.Register(metr.SessionErrors); err != nil {
+		return nil, err
+	}
+
+	metr.DedupCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_dedup_calls",
+		},
+		[]string{"method", "shared"},
+	)
+
+	if err := prometheus.Register(metr.DedupCalls); err != nil {
+		return nil, err
+	}
+
+	metr.CacheLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_cache_lookups",
+		},
+		[]string{"cache", "result"},
+	)
+
+	if err := prometheus.Register(metr.CacheLookups); err != nil {
+		return nil, err
+	}
+
+	metr.SLOBurnRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: name + "_slo_burn_rate",
+		},
+		[]string{"slo", "sli", "window"},
+	)
+
+	if err := prometheus.Register(metr.SLOBurnRate); err != nil {
+		return nil, err
+	}
+
+	metr.SLOBudgetRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: name + "_slo_budget_remaining",
+		},
+		[]string{"slo", "sli"},
+	)
+
+	if err := prometheus.Register(metr.SLOBudgetRemaining); err != nil {
+		return nil, err
+	}
+
+	metr.UseCaseTimes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: name + "_usecase_seconds",
+		},
+		[]string{"method", "status"},
+	)
+
+	if err := prometheus.Register(metr.UseCaseTimes); err != nil {
+		return nil, err
+	}
+
+	metr.SignupRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_signup_rejections",
+		},
+		[]string{"reason"},
+	)
+
+	if err := prometheus.Register(metr.SignupRejections); err != nil {
+		return nil, err
+	}
+
+	metr.ShadowComparisons = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_shadow_comparisons",
+		},
+		[]string{"method", "result"},
+	)
+
+	if err := prometheus.Register(metr.ShadowComparisons); err != nil {
+		return nil, err
+	}
+
+	metr.SessionStoreTimes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_session_store_seconds",
+			Buckets: []float64{.0002, .0005, .001, .0025, .005, .01, .025, .05, .1},
+		},
+		[]string{"op"},
+	)
+
+	if err := prometheus.Register(metr.SessionStoreTimes); err != nil {
+		return nil, err
+	}
+
+	metr.SessionStoreClassTimes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_session_store_class_seconds",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"class", "result"},
+	)
+
+	if err := prometheus.Register(metr.SessionStoreClassTimes); err != nil {
+		return nil, err
+	}
+
+	metr.PermissionLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_permission_lookups_total",
+		},
+		[]string{"source"},
+	)
+
+	if err := prometheus.Register(metr.PermissionLookups); err != nil {
+		return nil, err
+	}
+
+	metr.DeprecatedFields = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_deprecated_fields_total",
+		},
+		[]string{"client", "path", "field"},
+	)
+
+	if err := prometheus.Register(metr.DeprecatedFields); err != nil {
+		return nil, err
+	}
+
+	metr.SessionEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_session_events",
+		},
+		[]string{"type"},
+	)
+
+	if err := prometheus.Register(metr.SessionEvents); err != nil {
+		return nil, err
+	}
+
+	metr.RiskDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_risk_decisions",
+		},
+		[]string{"decision"},
+	)
+
+	if err := prometheus.Register(metr.RiskDecisions); err != nil {
+		return nil, err
+	}
+
+	metr.HedgedReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_hedged_reads_total",
+		},
+		[]string{"method", "result"},
+	)
+
+	if err := prometheus.Register(metr.HedgedReads); err != nil {
+		return nil, err
+	}
+
+	metr.RequestSchemaFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_request_schema_failures_total",
+		},
+		[]string{"path", "field"},
+	)
+
+	if err := prometheus.Register(metr.RequestSchemaFailures); err != nil {
+		return nil, err
+	}
+
+	metr.CompactionRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_compaction_runs_total",
+		},
+		[]string{"job", "result"},
+	)
+
+	if err := prometheus.Register(metr.CompactionRuns); err != nil {
+		return nil, err
+	}
+
+	metr.CompactedItems = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_compacted_items_total",
+		},
+		[]string{"job", "mode"},
+	)
+
+	if err := prometheus.Register(metr.CompactedItems); err != nil {
+		return nil, err
+	}
+
+	metr.CanaryTimes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: name + "_canary_request_duration_seconds",
+		},
+		[]string{"route", "variant", "status"},
+	)
+
+	if err := prometheus.Register(metr.CanaryTimes); err != nil {
+		return nil, err
+	}
+
 	if err := prometheus.Register(prometheus.NewBuildInfoCollector()); err != nil {
 		return nil, err
 	}
 
 	go func() {
 		router := echo.New()
-		router.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+		// Exemplars are only exposed in the OpenMetrics format, scrapers asking for it get them
+		router.GET("/metrics", echo.WrapHandler(promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+		)))
 		log.Printf("Metrics server is running on port: %s", address)
 		if err := router.Start(address); err != nil {
 			log.Fatal(err)
@@ -78,6 +356,116 @@ func (metr *PrometheusMetrics) IncHits(status int, method, path string) {
 }
 
 // Observer response time
-func (metr *PrometheusMetrics) ObserveResponseTime(status int, method, path string, observeTime float64) {
-	metr.Times.WithLabelValues(strconv.Itoa(status), method, path).Observe(observeTime)
+func (metr *PrometheusMetrics) ObserveResponseTime(ctx context.Context, status int, method, path string, observeTime float64) {
+	observe(ctx, metr.Times.WithLabelValues(strconv.Itoa(status), method, path), observeTime)
+}
+
+// Count session error by kind
+func (metr *PrometheusMetrics) IncSessionErrors(kind string) {
+	metr.SessionErrors.WithLabelValues(kind).Inc()
+}
+
+// Count deduplicated call, shared calls are the savings
+func (metr *PrometheusMetrics) IncDedupCalls(method string, shared bool) {
+	metr.DedupCalls.WithLabelValues(method, strconv.FormatBool(shared)).Inc()
+}
+
+// Count cache lookup by result
+func (metr *PrometheusMetrics) IncCacheLookups(cache, result string) {
+	metr.CacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// Set error budget burn rate of an objective over a window
+func (metr *PrometheusMetrics) SetSLOBurnRate(slo, sli, window string, rate float64) {
+	metr.SLOBurnRate.WithLabelValues(slo, sli, window).Set(rate)
+}
+
+// Set remaining error budget of an objective
+func (metr *PrometheusMetrics) SetSLOBudgetRemaining(slo, sli string, remaining float64) {
+	metr.SLOBudgetRemaining.WithLabelValues(slo, sli).Set(remaining)
+}
+
+// Observe usecase call duration
+func (metr *PrometheusMetrics) ObserveUseCase(ctx context.Context, method, status string, seconds float64) {
+	observe(ctx, metr.UseCaseTimes.WithLabelValues(method, status), seconds)
+}
+
+// Count signup refused by the email domain policy
+func (metr *PrometheusMetrics) IncSignupRejections(reason string) {
+	metr.SignupRejections.WithLabelValues(reason).Inc()
+}
+
+// Count comparison of a shadowed repository call by result
+func (metr *PrometheusMetrics) IncShadowComparisons(method, result string) {
+	metr.ShadowComparisons.WithLabelValues(method, result).Inc()
+}
+
+// Observe session store operation duration
+func (metr *PrometheusMetrics) ObserveSessionStore(op string, seconds float64) {
+	metr.SessionStoreTimes.WithLabelValues(op).Observe(seconds)
+}
+
+// Observe session store call duration by operation class and result, retries included
+func (metr *PrometheusMetrics) ObserveSessionStoreClass(class, result string, seconds float64) {
+	metr.SessionStoreClassTimes.WithLabelValues(class, result).Observe(seconds)
+}
+
+// Count role permission lookup by the layer which answered it
+func (metr *PrometheusMetrics) IncPermissionLookups(source string) {
+	metr.PermissionLookups.WithLabelValues(source).Inc()
+}
+
+// Count use of a deprecated DTO field by a client on a route
+func (metr *PrometheusMetrics) IncDeprecatedFields(client, path, field string) {
+	metr.DeprecatedFields.WithLabelValues(client, path, field).Inc()
+}
+
+// Count session lifecycle event by type
+func (metr *PrometheusMetrics) IncSessionEvents(eventType string) {
+	metr.SessionEvents.WithLabelValues(eventType).Inc()
+}
+
+// Count login risk decision
+func (metr *PrometheusMetrics) IncRiskDecisions(decision string) {
+	metr.RiskDecisions.WithLabelValues(decision).Inc()
+}
+
+// Count replica read by hedging result
+func (metr *PrometheusMetrics) IncHedgedReads(method, result string) {
+	metr.HedgedReads.WithLabelValues(method, result).Inc()
+}
+
+// Count field failing the request schema of route path
+func (metr *PrometheusMetrics) IncRequestSchemaFailures(path, field string) {
+	metr.RequestSchemaFailures.WithLabelValues(path, field).Inc()
+}
+
+// Count compaction job run by result
+func (metr *PrometheusMetrics) IncCompactionRuns(job, result string) {
+	metr.CompactionRuns.WithLabelValues(job, result).Inc()
+}
+
+// Count items a compaction job removed, or only counted on a dry run
+func (metr *PrometheusMetrics) AddCompactedItems(job string, dryRun bool, count int) {
+	mode := "removed"
+	if dryRun {
+		mode = "dry_run"
+	}
+	metr.CompactedItems.WithLabelValues(job, mode).Add(float64(count))
+}
+
+// Observe duration of a request routed to a variant, stable included
+func (metr *PrometheusMetrics) ObserveCanary(ctx context.Context, route, variant string, status int, seconds float64) {
+	observe(ctx, metr.CanaryTimes.WithLabelValues(route, variant, strconv.Itoa(status)), seconds)
+}
+
+// Observe value with the trace id of ctx as exemplar, without a sampled trace the value is observed plainly
+func observe(ctx context.Context, observer prometheus.Observer, value float64) {
+	if traceID, ok := tracing.TraceID(ctx); ok {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{tracing.TraceIDLabel: traceID})
+			return
+		}
+	}
+	observer.Observe(value)
 }
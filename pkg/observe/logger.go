@@ -0,0 +1,71 @@
+package observe
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+type loggerCtxKey struct{}
+
+// Logger of the observed call in ctx, fallback outside of one
+func Logger(ctx context.Context, fallback logger.Logger) logger.Logger {
+	if log, ok := ctx.Value(loggerCtxKey{}).(logger.Logger); ok {
+		return log
+	}
+	return fallback
+}
+
+// Logger prefixing every entry with the call method, request and user
+type prefixLogger struct {
+	logger.Logger
+	prefix string
+}
+
+func (l *prefixLogger) Debug(args ...interface{}) {
+	l.Logger.Debug(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) Debugf(template string, args ...interface{}) {
+	l.Logger.Debugf(l.prefix+template, args...)
+}
+
+func (l *prefixLogger) Info(args ...interface{}) {
+	l.Logger.Info(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) Infof(template string, args ...interface{}) {
+	l.Logger.Infof(l.prefix+template, args...)
+}
+
+func (l *prefixLogger) Warn(args ...interface{}) {
+	l.Logger.Warn(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) Warnf(template string, args ...interface{}) {
+	l.Logger.Warnf(l.prefix+template, args...)
+}
+
+func (l *prefixLogger) Error(args ...interface{}) {
+	l.Logger.Error(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) Errorf(template string, args ...interface{}) {
+	l.Logger.Errorf(l.prefix+template, args...)
+}
+
+func (l *prefixLogger) DPanic(args ...interface{}) {
+	l.Logger.DPanic(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) DPanicf(template string, args ...interface{}) {
+	l.Logger.DPanicf(l.prefix+template, args...)
+}
+
+func (l *prefixLogger) Fatal(args ...interface{}) {
+	l.Logger.Fatal(append([]interface{}{l.prefix}, args...)...)
+}
+
+func (l *prefixLogger) Fatalf(template string, args ...interface{}) {
+	l.Logger.Fatalf(l.prefix+template, args...)
+}
@@ -0,0 +1,119 @@
+// Package observe is the runtime of the usecase decorators generated by `go run ./cmd/gen decorator`
+package observe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/utils"
+)
+
+// Call outcomes, the status label of the usecase metric
+const (
+	StatusOK          = "ok"
+	StatusClientError = "client_error"
+	StatusError       = "error"
+	StatusTimeout     = "timeout"
+)
+
+// Observer shared by all decorated usecases
+type Observer struct {
+	metrics metric.Metrics
+	logger  logger.Logger
+	timeout time.Duration
+	// Module loggers by package of the decorated usecase
+	modules sync.Map
+}
+
+// Observer constructor, calls arriving without a deadline get timeout, zero leaves them unbounded
+func New(metrics metric.Metrics, logger logger.Logger, timeout time.Duration) *Observer {
+	return &Observer{metrics: metrics, logger: logger, timeout: timeout}
+}
+
+// One usecase call, finished with Done
+type Call struct {
+	observer *Observer
+	method   string
+	started  time.Time
+	span     opentracing.Span
+	cancel   context.CancelFunc
+}
+
+// Start call of method, the returned ctx carries the deadline, span and a logger prefixed with request and user.
+// Methods handing back streams read after returning pass deadline false.
+func (o *Observer) Start(ctx context.Context, method string, deadline bool) (context.Context, *Call) {
+	call := &Call{observer: o, method: method, started: time.Now()}
+	if _, ok := ctx.Deadline(); !ok && deadline && o.timeout > 0 {
+		ctx, call.cancel = context.WithTimeout(ctx, o.timeout)
+	}
+
+	call.span, ctx = opentracing.StartSpanFromContext(ctx, method)
+	prefix := method
+	if requestID, ok := ctx.Value(utils.ReqIDCtxKey{}).(string); ok && requestID != "" {
+		call.span.SetTag("request_id", requestID)
+		prefix += " RequestID: " + requestID
+	}
+	if user, err := utils.GetUserFromCtx(ctx); err == nil {
+		call.span.SetTag("user_id", user.User.ID)
+		prefix += fmt.Sprintf(" UserID: %d", user.User.ID)
+	}
+
+	ctx = context.WithValue(ctx, loggerCtxKey{}, &prefixLogger{Logger: o.moduleLogger(method), prefix: prefix + ", "})
+	return ctx, call
+}
+
+// Logger of the module of method, decorated usecases live in internal/<package> and name their
+// methods <package>.<Method>
+func (o *Observer) moduleLogger(method string) logger.Logger {
+	if o.logger == nil {
+		return nil
+	}
+	module := "internal/" + strings.SplitN(method, ".", 2)[0]
+	if log, ok := o.modules.Load(module); ok {
+		return log.(logger.Logger)
+	}
+	log, _ := o.modules.LoadOrStore(module, o.logger.Named(module))
+	return log.(logger.Logger)
+}
+
+// Finish the call with the error the usecase returned
+func (c *Call) Done(err error) {
+	status := Status(err)
+	if status != StatusOK {
+		ext.Error.Set(c.span, true)
+		c.span.LogKV("error", err.Error())
+	}
+	if c.observer.metrics != nil {
+		c.observer.metrics.ObserveUseCase(opentracing.ContextWithSpan(context.Background(), c.span), c.method, status, time.Since(c.started).Seconds())
+	}
+
+	c.span.Finish()
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Metric status of a usecase error, errors mapping to 4xx responses are the caller's fault
+func Status(err error) string {
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return StatusTimeout
+	case httpErrors.ParseErrors(err).Status() < http.StatusInternalServerError:
+		return StatusClientError
+	default:
+		return StatusError
+	}
+}
@@ -0,0 +1,43 @@
+package observe
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+func TestObserver_Start(t *testing.T) {
+	t.Parallel()
+
+	observer := New(nil, nil, time.Minute)
+
+	ctx, call := observer.Start(context.Background(), "widgets.Get", true)
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	require.NotNil(t, Logger(ctx, nil))
+	call.Done(nil)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	ctx, call = observer.Start(context.Background(), "widgets.Open", false)
+	_, ok = ctx.Deadline()
+	require.False(t, ok)
+	call.Done(nil)
+	require.NoError(t, ctx.Err())
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, StatusOK, Status(nil))
+	require.Equal(t, StatusTimeout, Status(errors.Wrap(context.DeadlineExceeded, "repo.Get")))
+	require.Equal(t, StatusClientError, Status(errors.Wrap(sql.ErrNoRows, "repo.Get")))
+	require.Equal(t, StatusClientError, Status(httpErrors.NewBadRequestError("invalid")))
+	require.Equal(t, StatusError, Status(errors.New("connection reset")))
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
+)
+
+// Path of the outbox listing
+const Path = "/dev/outbox"
+
+// Messages of the listing
+type listing struct {
+	Messages []Message `json:"messages"`
+}
+
+// Register the listing, GET lists the newest messages filtered by ?channel= and capped by ?limit=,
+// DELETE forgets them
+func (o *Outbox) MapRoutes(e *echo.Echo) {
+	e.GET(Path, o.list)
+	e.DELETE(Path, o.clear)
+}
+
+func (o *Outbox) list(c echo.Context) error {
+	limit := 0
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return c.JSON(httpErrors.ErrorResponse(httpErrors.NewBadRequestError("invalid limit")))
+		}
+		limit = parsed
+	}
+	return c.JSON(http.StatusOK, listing{Messages: o.List(c.QueryParam("channel"), limit)})
+}
+
+func (o *Outbox) clear(c echo.Context) error {
+	o.Clear()
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,185 @@
+// Package outbox records outbound messages instead of sending them, the fakes mode of local development.
+// Senders are swapped for ones writing to the outbox, every message is logged, kept in memory for the
+// /dev/outbox listing and, when a blob store is given, stored as a json object so it outlives a restart.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/sms"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+// Channels of recorded messages
+const (
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+const (
+	defaultCapacity = 500
+	defaultBucket   = "outbox"
+)
+
+// Message which would have been sent
+type Message struct {
+	ID      string            `json:"id"`
+	Channel string            `json:"channel"`
+	To      string            `json:"to"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+	// Bucket and key of the copy in the blob store
+	Object    string    `json:"object,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Recorded messages, the newest capacity of them are listed
+type Outbox struct {
+	capacity int
+	blobs    storage.BlobStore
+	bucket   string
+	clock    clock.Clock
+	logger   logger.Logger
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Outbox from app config, nil unless fakes are enabled and allowed by the exposure profile. blobs may be nil
+func NewFromConfig(cfg *config.Config, blobs storage.BlobStore, clk clock.Clock, logger logger.Logger) *Outbox {
+	if !cfg.Fakes.Enabled || !cfg.Exposure.Active().Fakes {
+		return nil
+	}
+	return New(cfg.Fakes.Capacity, blobs, cfg.Fakes.Bucket, clk, logger)
+}
+
+// Outbox constructor, messages are copied to bucket of blobs when set
+func New(capacity int, blobs storage.BlobStore, bucket string, clk clock.Clock, logger logger.Logger) *Outbox {
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	if bucket == "" {
+		bucket = defaultBucket
+	}
+	return &Outbox{capacity: capacity, blobs: blobs, bucket: bucket, clock: clk, logger: logger}
+}
+
+// Record message, ID and CreatedAt are set
+func (o *Outbox) Record(ctx context.Context, msg *Message) error {
+	msg.ID = uuid.New().String()
+	msg.CreatedAt = o.clock.Now()
+
+	if o.blobs != nil {
+		raw, err := json.Marshal(msg)
+		if err != nil {
+			return errors.Wrap(err, "outbox.Record.json.Marshal")
+		}
+		key := fmt.Sprintf("%s/%d-%s.json", msg.Channel, msg.CreatedAt.UnixNano(), msg.ID)
+		if _, err := o.blobs.Put(ctx, o.bucket, key, bytes.NewReader(raw), int64(len(raw)), "application/json"); err != nil {
+			return errors.Wrap(err, "outbox.Record.Put")
+		}
+		msg.Object = o.bucket + "/" + key
+	}
+
+	o.mu.Lock()
+	o.messages = append(o.messages, *msg)
+	if len(o.messages) > o.capacity {
+		o.messages = append([]Message(nil), o.messages[len(o.messages)-o.capacity:]...)
+	}
+	o.mu.Unlock()
+
+	o.logger.Infof("outbox %s to: %s, body: %s", msg.Channel, msg.To, msg.Body)
+	return nil
+}
+
+// Recorded messages of channel newest first, all channels when empty. A positive limit caps the result
+func (o *Outbox) List(channel string, limit int) []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	messages := make([]Message, 0)
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if limit > 0 && len(messages) == limit {
+			break
+		}
+		if channel == "" || o.messages[i].Channel == channel {
+			messages = append(messages, o.messages[i])
+		}
+	}
+	return messages
+}
+
+// Forget the recorded messages, copies in the blob store are kept
+func (o *Outbox) Clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = nil
+}
+
+// SMS sender recording to the outbox
+func (o *Outbox) SMS() sms.Sender {
+	return smsSender{outbox: o}
+}
+
+type smsSender struct {
+	outbox *Outbox
+}
+
+func (s smsSender) Send(ctx context.Context, to string, message string) error {
+	return s.outbox.Record(ctx, &Message{Channel: ChannelSMS, To: to, Body: message})
+}
+
+// HTTP transport recording requests to channel, every request is answered 200 without a body
+func (o *Outbox) Transport(channel string) http.RoundTripper {
+	return transport{outbox: o, channel: channel}
+}
+
+type transport struct {
+	outbox  *Outbox
+	channel string
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "outbox.transport.ReadAll")
+		}
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		headers[name] = req.Header.Get(name)
+	}
+	msg := &Message{Channel: t.channel, To: req.Method + " " + req.URL.String(), Headers: headers, Body: string(body)}
+	if err := t.outbox.Record(req.Context(), msg); err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
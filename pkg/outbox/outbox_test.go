@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/storage"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Logger: config.Logger{Development: true, Level: "error", Encoding: "console"}}
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	local, err := blobstore.New(t.TempDir(), "")
+	require.NoError(t, err)
+	blobs := storage.NewLocal(local)
+	box := New(2, blobs, "", clock.NewFrozen(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)), appLogger)
+	ctx := context.Background()
+
+	require.NoError(t, box.SMS().Send(ctx, "+15550100", "code 123456"))
+
+	// Webhook deliveries are answered without reaching the target
+	client := &http.Client{Transport: box.Transport(ChannelWebhook)}
+	req, err := http.NewRequest(http.MethodPost, "https://hooks.example.com/in", strings.NewReader(`{"type":"user.login"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Webhook-Event", "user.login")
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	messages := box.List("", 0)
+	require.Len(t, messages, 2)
+	hook := messages[0]
+	require.Equal(t, ChannelWebhook, hook.Channel)
+	require.Equal(t, "POST https://hooks.example.com/in", hook.To)
+	require.Equal(t, `{"type":"user.login"}`, hook.Body)
+	require.Equal(t, "user.login", hook.Headers["X-Webhook-Event"])
+
+	// Every message is also kept in the blob store
+	bucket, key, _ := strings.Cut(messages[1].Object, "/")
+	require.Equal(t, defaultBucket, bucket)
+	object, err := blobs.Get(ctx, bucket, key)
+	require.NoError(t, err)
+	defer object.Close()
+	var stored Message
+	require.NoError(t, json.NewDecoder(object).Decode(&stored))
+	require.Equal(t, "code 123456", stored.Body)
+	require.Equal(t, "+15550100", stored.To)
+
+	// Only the newest messages are listed
+	require.NoError(t, box.SMS().Send(ctx, "+15550101", "code 654321"))
+	require.Len(t, box.List("", 0), 2)
+	sent := box.List(ChannelSMS, 0)
+	require.Len(t, sent, 1)
+	require.Equal(t, "+15550101", sent[0].To)
+
+	// Listing endpoint
+	e := echo.New()
+	box.MapRoutes(e)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?channel=webhook&limit=5", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed listing
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Messages, 1)
+	require.Equal(t, hook.ID, listed.Messages[0].ID)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?limit=x", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, Path, nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, box.List("", 0))
+}
+
+func TestNewFromConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Fakes:    config.Fakes{Enabled: true},
+		Exposure: config.Exposure{Profiles: map[string]config.ExposureProfile{config.ProfileDev: {}}},
+	}
+	require.Nil(t, NewFromConfig(cfg, nil, clock.NewFrozen(time.Now()), nil))
+
+	cfg.Exposure.Profiles[config.ProfileDev] = config.ExposureProfile{Fakes: true}
+	require.NotNil(t, NewFromConfig(cfg, nil, clock.NewFrozen(time.Now()), nil))
+}
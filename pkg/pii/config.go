@@ -0,0 +1,25 @@
+package pii
+
+import (
+	"context"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Cipher from app config, nil when PII encryption is disabled
+func NewFromConfig(ctx context.Context, cfg *config.Config) (*Cipher, error) {
+	if !cfg.PII.Enabled {
+		return nil, nil
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Load(ctx, provider, cfg.PII.KeysSecret, cfg.PII.BlindIndexSecret, cfg.PII.ActiveKey)
+}
@@ -0,0 +1,188 @@
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Envelope prefix of encrypted values, followed by the key version: enc:v2:<base64 nonce|ciphertext>
+const envelopePrefix = "enc:v"
+
+// Cipher encrypts column values with versioned AES-256-GCM keys and computes blind indexes for equality lookups.
+// A nil Cipher leaves values in plaintext, so encryption can be switched on per environment.
+type Cipher struct {
+	keys     map[int]cipher.AEAD
+	active   int
+	indexKey []byte
+}
+
+// Cipher constructor, every key must be 32 bytes and the active version must be present
+func NewCipher(keys map[int][]byte, active int, indexKey []byte) (*Cipher, error) {
+	if len(indexKey) < 32 {
+		return nil, errors.New("pii: blind index key must be at least 32 bytes")
+	}
+	aeads := make(map[int]cipher.AEAD, len(keys))
+	for version, key := range keys {
+		if len(key) != 32 {
+			return nil, errors.Errorf("pii: key v%d must be 32 bytes", version)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "pii.NewCipher.aes.NewCipher")
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.Wrap(err, "pii.NewCipher.cipher.NewGCM")
+		}
+		aeads[version] = aead
+	}
+	if _, ok := aeads[active]; !ok {
+		return nil, errors.Errorf("pii: active key v%d not configured", active)
+	}
+	return &Cipher{keys: aeads, active: active, indexKey: indexKey}, nil
+}
+
+// Load cipher from the secrets provider, keysSecret holds "1:<base64>,2:<base64>" and indexSecret a base64 key
+func Load(ctx context.Context, provider secrets.Provider, keysSecret, indexSecret string, active int) (*Cipher, error) {
+	rawKeys, err := provider.Get(ctx, keysSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "pii.Load.keys")
+	}
+	keys, err := ParseKeys(rawKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIndexKey, err := provider.Get(ctx, indexSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "pii.Load.indexKey")
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(rawIndexKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "pii.Load.indexKey.base64")
+	}
+
+	return NewCipher(keys, active, indexKey)
+}
+
+// Parse comma separated version:base64 key list
+func ParseKeys(raw string) (map[int][]byte, error) {
+	keys := make(map[int][]byte)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		versionPart, keyPart, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, errors.New("pii: key entry must be version:base64")
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil || version <= 0 {
+			return nil, errors.Errorf("pii: invalid key version %q", versionPart)
+		}
+		key, err := base64.StdEncoding.DecodeString(keyPart)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pii: key v%d", version)
+		}
+		keys[version] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("pii: no keys configured")
+	}
+	return keys, nil
+}
+
+// Encrypt value with the active key, field is bound as associated data so values can't be moved between columns
+func (c *Cipher) Encrypt(field, plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	aead := c.keys[c.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "pii.Encrypt.rand")
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+
+	return envelopePrefix + strconv.Itoa(c.active) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt value, values without envelope are legacy plaintext and returned as is
+func (c *Cipher) Decrypt(field, value string) (string, error) {
+	version, payload, ok := parseEnvelope(value)
+	if !ok {
+		return value, nil
+	}
+	if c == nil {
+		return "", errors.New("pii: encrypted value but encryption is disabled")
+	}
+
+	aead, ok := c.keys[version]
+	if !ok {
+		return "", errors.Errorf("pii: key v%d not configured", version)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "pii.Decrypt.base64")
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("pii: ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", errors.Wrap(err, "pii.Decrypt.Open")
+	}
+	return string(plaintext), nil
+}
+
+// Value is plaintext or encrypted with a key other than the active one
+func (c *Cipher) NeedsRotation(value string) bool {
+	if c == nil || value == "" {
+		return false
+	}
+	version, _, ok := parseEnvelope(value)
+	return !ok || version != c.active
+}
+
+// Deterministic keyed hash of the normalized value, empty when encryption is disabled or value is empty
+func (c *Cipher) BlindIndex(field, value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if c == nil || value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseEnvelope(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return 0, "", false
+	}
+	versionPart, payload, ok := strings.Cut(strings.TrimPrefix(value, envelopePrefix), ":")
+	if !ok {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(versionPart)
+	if err != nil {
+		return 0, "", false
+	}
+	return version, payload, true
+}
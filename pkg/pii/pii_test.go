@@ -0,0 +1,72 @@
+package pii
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCipher(t *testing.T, active int) *Cipher {
+	t.Helper()
+
+	c, err := NewCipher(map[int][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+	}, active, bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	return c
+}
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	c := testCipher(t, 1)
+
+	encrypted, err := c.Encrypt("email", "alex@example.com")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "alex")
+
+	decrypted, err := c.Decrypt("email", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "alex@example.com", decrypted)
+
+	_, err = c.Decrypt("phone", encrypted)
+	require.Error(t, err)
+
+	plaintext, err := c.Decrypt("email", "legacy@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "legacy@example.com", plaintext)
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	t.Parallel()
+
+	old := testCipher(t, 1)
+	current := testCipher(t, 2)
+
+	encrypted, err := old.Encrypt("email", "alex@example.com")
+	require.NoError(t, err)
+	require.True(t, current.NeedsRotation(encrypted))
+	require.True(t, current.NeedsRotation("alex@example.com"))
+	require.False(t, old.NeedsRotation(encrypted))
+
+	decrypted, err := current.Decrypt("email", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "alex@example.com", decrypted)
+
+	require.Equal(t, old.BlindIndex("email", "Alex@Example.com "), current.BlindIndex("email", "alex@example.com"))
+	require.NotEqual(t, current.BlindIndex("email", "alex@example.com"), current.BlindIndex("phone", "alex@example.com"))
+}
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseKeys("1:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=, 2:AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Len(t, keys[2], 32)
+
+	_, err = ParseKeys("v1-bogus")
+	require.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package profiling
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// database/sql connection as used by sqlc generated queries
+type SQLConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// pgx connection as used by sqlc generated queries
+type PgxConn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row
+}
+
+// Wrap database/sql connection, query execution is labeled with the query name. Rows are read
+// after the call returns and only carry the labels of the request.
+func SQL(conn SQLConn) SQLConn {
+	return &sqlConn{conn: conn}
+}
+
+// Wrap pgx connection, like SQL
+func Pgx(conn PgxConn) PgxConn {
+	return &pgxConn{conn: conn}
+}
+
+type sqlConn struct {
+	conn SQLConn
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, q string, args ...interface{}) (result sql.Result, err error) {
+	query(ctx, q, func(ctx context.Context) {
+		result, err = c.conn.ExecContext(ctx, q, args...)
+	})
+	return result, err
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, q string) (stmt *sql.Stmt, err error) {
+	query(ctx, q, func(ctx context.Context) {
+		stmt, err = c.conn.PrepareContext(ctx, q)
+	})
+	return stmt, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, q string, args ...interface{}) (rows *sql.Rows, err error) {
+	query(ctx, q, func(ctx context.Context) {
+		rows, err = c.conn.QueryContext(ctx, q, args...)
+	})
+	return rows, err
+}
+
+func (c *sqlConn) QueryRowContext(ctx context.Context, q string, args ...interface{}) (row *sql.Row) {
+	query(ctx, q, func(ctx context.Context) {
+		row = c.conn.QueryRowContext(ctx, q, args...)
+	})
+	return row
+}
+
+type pgxConn struct {
+	conn PgxConn
+}
+
+func (c *pgxConn) Exec(ctx context.Context, q string, args ...interface{}) (tag pgconn.CommandTag, err error) {
+	query(ctx, q, func(ctx context.Context) {
+		tag, err = c.conn.Exec(ctx, q, args...)
+	})
+	return tag, err
+}
+
+func (c *pgxConn) Query(ctx context.Context, q string, args ...interface{}) (rows pgx.Rows, err error) {
+	query(ctx, q, func(ctx context.Context) {
+		rows, err = c.conn.Query(ctx, q, args...)
+	})
+	return rows, err
+}
+
+func (c *pgxConn) QueryRow(ctx context.Context, q string, args ...interface{}) (row pgx.Row) {
+	query(ctx, q, func(ctx context.Context) {
+		row = c.conn.QueryRow(ctx, q, args...)
+	})
+	return row
+}
@@ -0,0 +1,65 @@
+// Package profiling attaches pprof labels to request goroutines and database calls, so CPU profiles
+// taken from the debug port can be broken down with go tool pprof -tagfocus / -tags, e.g.
+// -tagfocus route=/api/v1/auth/find.
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+)
+
+// Label keys
+const (
+	LabelRoute  = "route"
+	LabelMethod = "method"
+	LabelUser   = "user"
+	LabelTenant = "tenant"
+	LabelQuery  = "db_query"
+)
+
+// Run fn with route and method labels on the goroutine and its context, labels of the goroutine
+// are restored when fn returns
+func Do(ctx context.Context, route string, method string, fn func(ctx context.Context)) {
+	pprof.Do(ctx, pprof.Labels(LabelRoute, route, LabelMethod, method), fn)
+}
+
+// Add labels to a context labeled by Do and to the running goroutine. Contexts without a route label
+// are returned unchanged, so callers need not know whether labeling is enabled.
+func AddLabels(ctx context.Context, kv ...string) context.Context {
+	if !Labeled(ctx) {
+		return ctx
+	}
+	ctx = pprof.WithLabels(ctx, pprof.Labels(kv...))
+	pprof.SetGoroutineLabels(ctx)
+	return ctx
+}
+
+// Is ctx inside Do
+func Labeled(ctx context.Context) bool {
+	_, ok := pprof.Label(ctx, LabelRoute)
+	return ok
+}
+
+// Run a database call with the query label, only within labeled requests
+func query(ctx context.Context, sql string, fn func(ctx context.Context)) {
+	if !Labeled(ctx) {
+		fn(ctx)
+		return
+	}
+	pprof.Do(ctx, pprof.Labels(LabelQuery, QueryName(sql)), fn)
+}
+
+// Name of a query, the sqlc "-- name:" annotation when present or else its leading keyword
+func QueryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest := strings.TrimPrefix(sql, "-- name:"); rest != sql {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "FindByName", QueryName("-- name: FindByName :many\nSELECT id FROM users"))
+	require.Equal(t, "SELECT", QueryName("  select id FROM users"))
+	require.Equal(t, "", QueryName(""))
+}
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := AddLabels(context.Background(), LabelUser, "1")
+	_, ok := pprof.Label(ctx, LabelUser)
+	require.False(t, ok, "unlabeled requests stay unlabeled")
+
+	Do(context.Background(), "/api/v1/auth/find", "GET", func(ctx context.Context) {
+		ctx = AddLabels(ctx, LabelUser, "7", LabelTenant, "acme")
+		user, _ := pprof.Label(ctx, LabelUser)
+		require.Equal(t, "7", user)
+
+		query(ctx, "-- name: FindByName :many", func(ctx context.Context) {
+			name, _ := pprof.Label(ctx, LabelQuery)
+			require.Equal(t, "FindByName", name)
+			route, _ := pprof.Label(ctx, LabelRoute)
+			require.Equal(t, "/api/v1/auth/find", route)
+		})
+	})
+}
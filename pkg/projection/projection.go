@@ -0,0 +1,93 @@
+package projection
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Requested sparse fieldset, e.g. ?fields=id,name,email
+type Fields []string
+
+// Parse comma separated fields query param, empty input means all fields
+func Parse(raw string) Fields {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	fields := make(Fields, 0)
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Is fieldset empty
+func (f Fields) IsEmpty() bool {
+	return len(f) == 0
+}
+
+// Validate every requested field is in whitelist
+func (f Fields) Validate(whitelist []string) error {
+	allowed := toSet(whitelist)
+	for _, field := range f {
+		if _, ok := allowed[field]; !ok {
+			return fmt.Errorf("field %q is not allowed, allowed fields: %s", field, strings.Join(whitelist, ","))
+		}
+	}
+	return nil
+}
+
+// Resolve fields to output: requested fields that are visible, or every visible field when nothing requested
+func (f Fields) Resolve(visible []string) []string {
+	if f.IsEmpty() {
+		return visible
+	}
+
+	allowed := toSet(visible)
+	keep := make([]string, 0, len(f))
+	for _, field := range f {
+		if _, ok := allowed[field]; ok {
+			keep = append(keep, field)
+		}
+	}
+	return keep
+}
+
+// Select keeps only given top level JSON keys of v
+func Select(v interface{}, keep []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	full := make(map[string]interface{})
+	if err = json.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]interface{}, len(keep))
+	for _, field := range keep {
+		if value, ok := full[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected, nil
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
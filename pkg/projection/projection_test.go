@@ -0,0 +1,46 @@
+package projection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, Parse(""))
+	require.Nil(t, Parse("  "))
+	require.Equal(t, Fields{"id", "email"}, Parse("id, email,,id"))
+}
+
+func TestFields_Validate(t *testing.T) {
+	t.Parallel()
+
+	whitelist := []string{"id", "username"}
+	require.NoError(t, Fields{"id"}.Validate(whitelist))
+	require.NoError(t, Fields(nil).Validate(whitelist))
+	require.Error(t, Fields{"id", "password"}.Validate(whitelist))
+}
+
+func TestFields_Resolve(t *testing.T) {
+	t.Parallel()
+
+	visible := []string{"id", "username"}
+	require.Equal(t, visible, Fields(nil).Resolve(visible))
+	require.Equal(t, []string{"id"}, Fields{"id", "email"}.Resolve(visible))
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	projected, err := Select(&testUser{ID: 1, Username: "john", Email: "john@example.com"}, []string{"id", "email", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": float64(1), "email": "john@example.com"}, projected)
+}
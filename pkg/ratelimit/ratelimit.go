@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// Increment the window counter, start its expiry on the first hit and return the count with the remaining ttl
+var hitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// Check every level before counting, a hit over one limit is counted by none. Returns the index of the
+// rejecting level, 0 when allowed, then count and remaining ttl of each level
+var hitLevelsScript = redis.NewScript(`
+local rejected = 0
+local counts = {}
+for i = 1, #KEYS do
+	counts[i] = tonumber(redis.call("GET", KEYS[i]) or "0")
+	if rejected == 0 and counts[i] >= tonumber(ARGV[i + 1]) then
+		rejected = i
+	end
+end
+if rejected == 0 then
+	for i = 1, #KEYS do
+		counts[i] = redis.call("INCR", KEYS[i])
+		if counts[i] == 1 then
+			redis.call("PEXPIRE", KEYS[i], ARGV[1])
+		end
+	end
+end
+local result = {rejected}
+for i = 1, #KEYS do
+	table.insert(result, counts[i])
+	table.insert(result, redis.call("PTTL", KEYS[i]))
+end
+return result
+`)
+
+// Outcome of a single hit against a fixed window. Level names the level of a hierarchical limit the numbers
+// are of, empty for single limits
+type Result struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	Allowed   bool
+	Level     string
+}
+
+// One level of a hierarchical limit, e.g. a user within a tenant within the deployment
+type Level struct {
+	Name  string
+	Key   string
+	Limit int
+}
+
+// Redis backed fixed window rate limiter
+type Limiter struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Rate limiter constructor
+func NewLimiter(redisClient *redis.Client, prefix string) *Limiter {
+	return &Limiter{redisClient: redisClient, prefix: prefix}
+}
+
+// Count a hit for key and report the state of its current window
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	redisKey := l.prefix + ":" + key
+
+	res, err := hitScript.Run(ctx, l.redisClient, []string{redisKey}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, errors.Wrap(err, "Limiter.Allow.hitScript.Run")
+	}
+
+	count := int(res[0])
+	resetIn := time.Duration(res[1]) * time.Millisecond
+	if resetIn < 0 {
+		resetIn = window
+	}
+
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &Result{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Now().Add(resetIn),
+		Allowed:   count <= limit,
+	}, nil
+}
+
+// Count a hit against every level at once when all of them allow it. A hit rejected by one level is counted by
+// none, so callers over their own limit don't use up the capacity their tenant and the deployment share. The
+// result is of the rejecting level, or of the level with the fewest remaining hits when allowed
+func (l *Limiter) AllowLevels(ctx context.Context, levels []Level, window time.Duration) (*Result, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("Limiter.AllowLevels: no levels")
+	}
+	keys := make([]string, 0, len(levels))
+	args := make([]interface{}, 0, len(levels)+1)
+	args = append(args, window.Milliseconds())
+	for _, level := range levels {
+		keys = append(keys, l.prefix+":"+level.Key)
+		args = append(args, level.Limit)
+	}
+
+	res, err := hitLevelsScript.Run(ctx, l.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, errors.Wrap(err, "Limiter.AllowLevels.hitLevelsScript.Run")
+	}
+
+	rejected := int(res[0])
+	var result *Result
+	for i, level := range levels {
+		count, resetIn := int(res[1+2*i]), time.Duration(res[2+2*i])*time.Millisecond
+		if resetIn < 0 {
+			resetIn = window
+		}
+		remaining := level.Limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		if rejected == i+1 || (rejected == 0 && (result == nil || remaining < result.Remaining)) {
+			result = &Result{
+				Limit:     level.Limit,
+				Remaining: remaining,
+				Reset:     time.Now().Add(resetIn),
+				Allowed:   rejected == 0,
+				Level:     level.Name,
+			}
+		}
+	}
+	return result, nil
+}
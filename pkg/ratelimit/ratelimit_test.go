@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	limiter := NewLimiter(client, "test")
+	ctx := context.Background()
+	hit := func(key string) *Result {
+		res, err := limiter.Allow(ctx, key, 2, time.Minute)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := hit("login:10.0.0.1")
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Limit)
+	require.Equal(t, 1, res.Remaining)
+	require.Empty(t, res.Level)
+	require.WithinDuration(t, time.Now().Add(time.Minute), res.Reset, time.Second)
+	require.Equal(t, time.Minute, server.TTL("test:login:10.0.0.1"))
+
+	require.True(t, hit("login:10.0.0.1").Allowed)
+	res = hit("login:10.0.0.1")
+	require.False(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+
+	// Keys are counted apart
+	require.True(t, hit("login:10.0.0.2").Allowed)
+
+	// The window starts over once it expired
+	server.FastForward(time.Minute)
+	res = hit("login:10.0.0.1")
+	require.True(t, res.Allowed)
+	require.Equal(t, 1, res.Remaining)
+
+	server.SetError("LOADING")
+	_, err := limiter.Allow(ctx, "login:10.0.0.1", 2, time.Minute)
+	require.Error(t, err)
+}
+
+func TestLimiter_AllowLevels(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	limiter := NewLimiter(client, "test")
+	ctx := context.Background()
+	hit := func(user, tenant string) *Result {
+		res, err := limiter.AllowLevels(ctx, []Level{
+			{Name: "caller", Key: "search:" + user, Limit: 3},
+			{Name: "tenant", Key: "search:" + tenant, Limit: 5},
+			{Name: "global", Key: "search:global", Limit: 8},
+		}, time.Minute)
+		require.NoError(t, err)
+		return res
+	}
+
+	// The tightest level is reported
+	res := hit("ann", "acme")
+	require.True(t, res.Allowed)
+	require.Equal(t, "caller", res.Level)
+	require.Equal(t, 2, res.Remaining)
+	require.Equal(t, time.Minute, server.TTL("test:search:global"))
+
+	// A noisy user is stopped by its own limit and uses up nothing of its tenant
+	for i := 0; i < 5; i++ {
+		res = hit("bob", "acme")
+	}
+	require.False(t, res.Allowed)
+	require.Equal(t, "caller", res.Level)
+	require.Equal(t, 0, res.Remaining)
+	require.Equal(t, "4", mustGet(t, server, "test:search:acme"))
+
+	// The noisy tenant is stopped by the tenant limit and leaves the rest of the global capacity to others
+	require.True(t, hit("cid", "acme").Allowed)
+	res = hit("cid", "acme")
+	require.False(t, res.Allowed)
+	require.Equal(t, "tenant", res.Level)
+	require.Equal(t, "5", mustGet(t, server, "test:search:global"))
+	for i := 0; i < 3; i++ {
+		require.True(t, hit("dan", "initech").Allowed)
+	}
+	res = hit("eve", "globex")
+	require.False(t, res.Allowed)
+	require.Equal(t, "global", res.Level)
+	require.Equal(t, 8, res.Limit)
+
+	// Every level starts over with its window
+	server.FastForward(time.Minute)
+	require.True(t, hit("eve", "globex").Allowed)
+
+	_, err := limiter.AllowLevels(ctx, nil, time.Minute)
+	require.Error(t, err)
+}
+
+func mustGet(t *testing.T, server *miniredis.Miniredis, key string) string {
+	value, err := server.Get(key)
+	require.NoError(t, err)
+	return value
+}
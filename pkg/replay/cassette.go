@@ -0,0 +1,115 @@
+// Package replay records sanitized request/response pairs of live traffic into cassettes and replays them
+// against a handler, diffing every response with the recorded one to catch unintended behavior changes.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Extension of cassette files
+const CassetteExt = ".json"
+
+// Cassette is an ordered list of exchanges of one client, replayed in order since later requests depend on
+// the state earlier ones left behind. Ignore lists the rules of paths left out of the diff, see Rule.
+type Cassette struct {
+	Ignore    []string   `json:"ignore,omitempty"`
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// Exchange is a request and the response it got
+type Exchange struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Recorded request, URL is the request URI with its query. JSON bodies are kept as is, anything else as a string
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Recorded response, bodies are kept like request bodies
+type Response struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Load cassette from file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "replay.Load.ReadFile")
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, errors.Wrapf(err, "replay.Load.Unmarshal %s", path)
+	}
+	return cassette, nil
+}
+
+// Save cassette indented so goldens diff well in review, the file is replaced atomically
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return errors.Wrap(err, "replay.Save.Encode")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "replay.Save.CreateTemp")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "replay.Save.Write")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "replay.Save.Close")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "replay.Save.Rename")
+}
+
+func isJSON(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Body as stored in a cassette
+func encodeBody(header http.Header, body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if isJSON(header) && json.Valid(body) {
+		return append(json.RawMessage(nil), body...)
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
+
+// Body bytes of a stored body
+func decodeBody(header http.Header, body json.RawMessage) ([]byte, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if isJSON(header) && body[0] != '"' {
+		return body, nil
+	}
+	var text string
+	if err := json.Unmarshal(body, &text); err != nil {
+		return nil, errors.Wrap(err, "replay.decodeBody")
+	}
+	return []byte(text), nil
+}
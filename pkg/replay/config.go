@@ -0,0 +1,43 @@
+package replay
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Recorder from app config, nil when recording is off or the exposure profile disallows it. Every request and its
+// response are written into a cassette per client under Replay.Dir, rotated after MaxExchanges. Credentials are
+// redacted, RedactHeaders and RedactFields add to the built in lists. Personal data is faked with the salt of
+// Anonymize.SaltSecret so recordings of a database copy and of its anonymized version line up. Refused in Production mode.
+func NewRecorderFromConfig(ctx context.Context, cfg *config.Config) (*Recorder, error) {
+	if !cfg.Replay.Record || !cfg.Exposure.Active().Replay {
+		return nil, nil
+	}
+	if strings.EqualFold(cfg.Server.Mode, "Production") {
+		return nil, errors.New("replay: refusing to record traffic in Production mode")
+	}
+
+	provider, err := secrets.NewProvider(secrets.Options{
+		Driver: cfg.Secrets.Driver,
+		Prefix: cfg.Secrets.Prefix,
+		Dir:    cfg.Secrets.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	salt, err := provider.Get(ctx, cfg.Anonymize.SaltSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "replay.NewRecorderFromConfig.salt")
+	}
+	faker, err := anonymize.NewFaker([]byte(salt))
+	if err != nil {
+		return nil, err
+	}
+	return NewRecorder(cfg.Replay.Dir, NewSanitizer(faker, cfg.Replay.RedactHeaders, cfg.Replay.RedactFields), cfg.Replay.MaxExchanges)
+}
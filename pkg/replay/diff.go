@@ -0,0 +1,248 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Longest value shown in a difference
+const maxShownValue = 120
+
+// Placeholder of a value present on one side only
+const missing = "<missing>"
+
+// Headers never compared, they differ on every request
+var IgnoredHeaders = []string{"Date", "X-Request-Id", "Content-Length"}
+
+// Difference between a recorded and a replayed response
+type Difference struct {
+	Path     string
+	Recorded string
+	Replayed string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: recorded %s, replayed %s", d.Path, d.Recorded, d.Replayed)
+}
+
+// Rule selects paths left out of the diff. Paths are dot separated, "status", "header.<Name>" or
+// "body.<field>.<field>", "[]" stands for any element of an array, "*" for any single segment and "**" for any
+// number of them: "body.users[].id", "body.**.created_at", "header.X-Ratelimit-Reset". An ignored path
+// skips everything below it.
+type Rule []string
+
+// Parse rule
+func ParseRule(text string) Rule {
+	var rule Rule
+	for _, segment := range strings.Split(text, ".") {
+		for strings.HasSuffix(segment, "[]") && segment != "[]" {
+			rule = append(rule, strings.TrimSuffix(segment, "[]"))
+			segment = "[]"
+		}
+		rule = append(rule, segment)
+	}
+	if len(rule) == 2 && rule[0] == "header" {
+		rule[1] = http.CanonicalHeaderKey(rule[1])
+	}
+	return rule
+}
+
+// Parse rules
+func ParseRules(texts []string) []Rule {
+	rules := make([]Rule, 0, len(texts))
+	for _, text := range texts {
+		rules = append(rules, ParseRule(text))
+	}
+	return rules
+}
+
+// Match path given as segments
+func (r Rule) Match(path []string) bool {
+	if len(r) == 0 {
+		return len(path) == 0
+	}
+	if r[0] == "**" {
+		for skip := 0; skip <= len(path); skip++ {
+			if r[1:].Match(path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || (r[0] != "*" && r[0] != path[0]) {
+		return false
+	}
+	return r[1:].Match(path[1:])
+}
+
+type differ struct {
+	rules       []Rule
+	differences []Difference
+}
+
+// Diff recorded and replayed response, replayed headers must be sanitized like recorded ones were
+func Diff(recorded Response, replayed Response, rules []Rule) []Difference {
+	d := &differ{rules: rules}
+	if recorded.Status != replayed.Status && !d.ignored([]string{"status"}) {
+		d.add("status", strconv.Itoa(recorded.Status), strconv.Itoa(replayed.Status))
+	}
+	d.headers(recorded.Header, replayed.Header)
+	d.body(recorded, replayed)
+	return d.differences
+}
+
+func (d *differ) ignored(path []string) bool {
+	for _, rule := range d.rules {
+		if rule.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *differ) add(path string, recorded string, replayed string) {
+	d.differences = append(d.differences, Difference{Path: path, Recorded: recorded, Replayed: replayed})
+}
+
+func (d *differ) headers(recorded http.Header, replayed http.Header) {
+	skip := make(map[string]bool, len(IgnoredHeaders))
+	for _, name := range IgnoredHeaders {
+		skip[name] = true
+	}
+	names := make(map[string]bool)
+	for name := range recorded {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range replayed {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		if skip[name] || d.ignored([]string{"header", name}) {
+			continue
+		}
+		want, got := headerValue(recorded, name), headerValue(replayed, name)
+		if want != got {
+			d.add("header."+name, want, got)
+		}
+	}
+}
+
+func headerValue(header http.Header, name string) string {
+	values := header.Values(name)
+	if len(values) == 0 {
+		return missing
+	}
+	return strconv.Quote(strings.Join(values, ", "))
+}
+
+func (d *differ) body(recorded Response, replayed Response) {
+	if d.ignored([]string{"body"}) {
+		return
+	}
+	want, wantJSON := decodeTree(recorded)
+	got, gotJSON := decodeTree(replayed)
+	if wantJSON && gotJSON {
+		d.value([]string{"body"}, "body", want, got)
+		return
+	}
+	if !bytes.Equal(recorded.Body, replayed.Body) {
+		d.add("body", show(recorded.Body), show(replayed.Body))
+	}
+}
+
+func decodeTree(response Response) (interface{}, bool) {
+	if len(response.Body) == 0 || !isJSON(response.Header) {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(response.Body))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, false
+	}
+	return tree, true
+}
+
+func (d *differ) value(path []string, display string, want interface{}, got interface{}) {
+	if d.ignored(path) {
+		return
+	}
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool, len(want)+len(got))
+		for key := range want {
+			keys[key] = true
+		}
+		for key := range got {
+			keys[key] = true
+		}
+		for _, key := range sortedKeys(keys) {
+			child := append(append([]string(nil), path...), key)
+			wantValue, inWant := want[key]
+			gotValue, inGot := got[key]
+			switch {
+			case d.ignored(child):
+			case !inWant:
+				d.add(display+"."+key, missing, show(gotValue))
+			case !inGot:
+				d.add(display+"."+key, show(wantValue), missing)
+			default:
+				d.value(child, display+"."+key, wantValue, gotValue)
+			}
+		}
+		return
+	case []interface{}:
+		got, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+		if len(want) != len(got) {
+			d.add(display+".length", strconv.Itoa(len(want)), strconv.Itoa(len(got)))
+		}
+		child := append(append([]string(nil), path...), "[]")
+		for i := 0; i < len(want) && i < len(got); i++ {
+			d.value(child, fmt.Sprintf("%s[%d]", display, i), want[i], got[i])
+		}
+		return
+	}
+	if wantShown, gotShown := show(want), show(got); wantShown != gotShown {
+		d.add(display, wantShown, gotShown)
+	}
+}
+
+func show(value interface{}) string {
+	var shown string
+	switch value := value.(type) {
+	case json.RawMessage:
+		shown = string(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		shown = string(encoded)
+	}
+	if len(shown) > maxShownValue {
+		shown = shown[:maxShownValue] + "..."
+	}
+	return shown
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Bodies over this size are not recorded, the exchange is dropped since it can't be replayed
+const MaxBodyBytes = 1 << 20
+
+// Exchanges per cassette when the recorder is given none
+const defaultMaxExchanges = 200
+
+// Headers left out of recordings, they describe the connection or encoding rather than the API
+var transportHeaders = []string{"Accept-Encoding", "Connection", "Content-Encoding", "Content-Length", "Keep-Alive", "Transfer-Encoding", "Upgrade"}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Recorder writes sanitized exchanges into one cassette per client under a directory. A cassette is rotated
+// once it holds maxExchanges, copy the interesting ones into testdata and add ignore rules to make a golden.
+type Recorder struct {
+	dir          string
+	sanitizer    *Sanitizer
+	maxExchanges int
+	now          func() time.Time
+
+	mu         sync.Mutex
+	recordings map[string]*recording
+}
+
+type recording struct {
+	path     string
+	cassette *Cassette
+}
+
+// Recorder constructor, creates dir when missing
+func NewRecorder(dir string, sanitizer *Sanitizer, maxExchanges int) (*Recorder, error) {
+	if maxExchanges <= 0 {
+		maxExchanges = defaultMaxExchanges
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "replay.NewRecorder.MkdirAll")
+	}
+	return &Recorder{
+		dir:          dir,
+		sanitizer:    sanitizer,
+		maxExchanges: maxExchanges,
+		now:          time.Now,
+		recordings:   make(map[string]*recording),
+	}, nil
+}
+
+// Record request of client and the response it got, bodies are the raw bytes as sent
+func (r *Recorder) Record(client string, req *http.Request, reqBody []byte, status int, header http.Header, body []byte) error {
+	if len(reqBody) > MaxBodyBytes || len(body) > MaxBodyBytes {
+		return nil
+	}
+	exchange := Exchange{
+		Request: Request{
+			Method: req.Method,
+			URL:    r.sanitizer.URL(req.URL.RequestURI()),
+			Header: r.sanitizer.Header(withoutTransport(req.Header)),
+			Body:   encodeBody(req.Header, r.sanitizer.RequestBody(req.Header, reqBody)),
+		},
+		Response: Response{
+			Status: status,
+			Header: r.sanitizer.Header(withoutIgnored(header)),
+			Body:   encodeBody(header, r.sanitizer.ResponseBody(header, body)),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.recordings[client]
+	if !ok || len(current.cassette.Exchanges) >= r.maxExchanges {
+		name := r.now().UTC().Format("20060102T150405.000") + "-" + unsafeFileChars.ReplaceAllString(client, "-") + CassetteExt
+		current = &recording{path: filepath.Join(r.dir, name), cassette: &Cassette{}}
+		r.recordings[client] = current
+	}
+	current.cassette.Exchanges = append(current.cassette.Exchanges, exchange)
+	return current.cassette.Save(current.path)
+}
+
+func withoutTransport(header http.Header) http.Header {
+	kept := header.Clone()
+	for _, name := range transportHeaders {
+		kept.Del(name)
+	}
+	return kept
+}
+
+// Response headers worth keeping in a cassette
+func withoutIgnored(header http.Header) http.Header {
+	kept := withoutTransport(header)
+	for _, name := range IgnoredHeaders {
+		kept.Del(name)
+	}
+	return kept
+}
@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+)
+
+func TestRuleMatch(t *testing.T) {
+	t.Parallel()
+
+	path := func(text string) []string { return ParseRule(text) }
+
+	require.True(t, ParseRule("body.user.id").Match(path("body.user.id")))
+	require.False(t, ParseRule("body.user.id").Match(path("body.user")))
+	require.True(t, ParseRule("body.users[].id").Match([]string{"body", "users", "[]", "id"}))
+	require.True(t, ParseRule("body.*.id").Match(path("body.user.id")))
+	require.False(t, ParseRule("body.*.id").Match(path("body.user.role.id")))
+	require.True(t, ParseRule("body.**.created_at").Match(path("body.created_at")))
+	require.True(t, ParseRule("body.**.created_at").Match([]string{"body", "users", "[]", "created_at"}))
+	require.False(t, ParseRule("body.**.created_at").Match(path("body.user.created_at.seconds")))
+	require.True(t, ParseRule("header.x-ratelimit-reset").Match([]string{"header", "X-Ratelimit-Reset"}))
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	recorded := Response{
+		Status: http.StatusOK,
+		Header: http.Header{"Content-Type": {"application/json"}, "X-Frame-Options": {"DENY"}},
+		Body:   json.RawMessage(`{"users":[{"id":1,"name":"a","created_at":"x"},{"id":2,"name":"b","created_at":"y"}],"total":2}`),
+	}
+	replayed := Response{
+		Status: http.StatusOK,
+		Header: jsonHeader,
+		Body:   json.RawMessage(`{"users":[{"id":1,"name":"a","created_at":"z"},{"id":2,"name":"c","created_at":"z"}],"total":2.0,"next":null}`),
+	}
+
+	differences := Diff(recorded, replayed, ParseRules([]string{"body.**.created_at"}))
+	require.Equal(t, []Difference{
+		{Path: "header.X-Frame-Options", Recorded: `"DENY"`, Replayed: missing},
+		{Path: "body.next", Recorded: missing, Replayed: "null"},
+		{Path: "body.total", Recorded: "2", Replayed: "2.0"},
+		{Path: "body.users[1].name", Recorded: `"b"`, Replayed: `"c"`},
+	}, differences)
+
+	require.Empty(t, Diff(recorded, replayed, ParseRules([]string{"body", "header.X-Frame-Options"})))
+
+	replayed.Status = http.StatusNotFound
+	replayed.Body = json.RawMessage(`{"users":[]}`)
+	differences = Diff(recorded, replayed, ParseRules([]string{"header.*", "body.total"}))
+	require.Equal(t, []Difference{
+		{Path: "status", Recorded: "200", Replayed: "404"},
+		{Path: "body.users.length", Recorded: "2", Replayed: "0"},
+	}, differences)
+}
+
+func TestSanitizer(t *testing.T) {
+	t.Parallel()
+
+	faker, err := anonymize.NewFaker([]byte("replay-test-salt-0123"))
+	require.NoError(t, err)
+	s := NewSanitizer(faker, []string{"X-Api-Key"}, []string{"pin"})
+	jsonHeader := http.Header{"Content-Type": {"application/json; charset=UTF-8"}}
+
+	header := s.Header(http.Header{"Authorization": {"Bearer abc"}, "X-Api-Key": {"k"}, "Accept": {"application/json"}})
+	require.Equal(t, Redacted, header.Get("Authorization"))
+	require.Equal(t, Redacted, header.Get("X-Api-Key"))
+	require.Equal(t, "application/json", header.Get("Accept"))
+
+	body := `{"username":"jdoe","email":"Jane@Corp.io","password":"hunter22","pin":1234,"contact":{"phone":"+442071838750","number":"call me"},"token":""}`
+	var request map[string]interface{}
+	require.NoError(t, json.Unmarshal(s.RequestBody(jsonHeader, []byte(body)), &request))
+	require.Equal(t, faker.Username("jdoe"), request["username"])
+	require.Equal(t, faker.Email("jane@corp.io"), request["email"])
+	require.Equal(t, faker.Secret("hunter22"), request["password"])
+	require.Equal(t, Redacted, request["pin"])
+	require.Equal(t, faker.Phone("+442071838750"), request["contact"].(map[string]interface{})["phone"])
+	// Malformed values are not faked into valid ones
+	require.Equal(t, Redacted, request["contact"].(map[string]interface{})["number"])
+	require.Equal(t, "", request["token"])
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(s.ResponseBody(jsonHeader, []byte(body)), &response))
+	require.Equal(t, Redacted, response["password"])
+	require.Equal(t, request["email"], response["email"])
+
+	require.Equal(t, "plain password=hunter22", string(s.RequestBody(http.Header{}, []byte("plain password=hunter22"))))
+	require.Equal(t, "/reset?lang=en&token="+faker.Secret("t0k"), s.URL("/reset?token=t0k&lang=en"))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	faker, err := anonymize.NewFaker([]byte("replay-test-salt-0123"))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, NewSanitizer(faker, nil, nil), 0)
+	require.NoError(t, err)
+
+	// Login hands out a session cookie, a csrf header and a bearer token, update needs all three
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Email, Password string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Password != faker.Secret("hunter22") {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"Unauthorized"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s-" + req.Email})
+		w.Header().Set("X-CSRF-Token", "csrf-"+req.Email)
+		w.Write([]byte(`{"token":"jwt-` + req.Email + `","email":"` + req.Email + `"}`))
+	})
+	mux.HandleFunc("/update", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("sid")
+		if err != nil || r.Header.Get("X-CSRF-Token") != "csrf-"+strings.TrimPrefix(cookie.Value, "s-") ||
+			r.Header.Get("Authorization") != "Bearer jwt-"+strings.TrimPrefix(cookie.Value, "s-") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Record the way the middleware does, with real values the recorder has to sanitize
+	record := func(method string, target string, header http.Header, body string, status int, resHeader http.Header, resBody string) {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header = header
+		require.NoError(t, recorder.Record("192.0.2.7", req, []byte(body), status, resHeader, []byte(resBody)))
+	}
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	record(http.MethodPost, "/login", jsonHeader, `{"email":"jane@corp.io","password":"wrong"}`,
+		http.StatusUnauthorized, jsonHeader, `{"error":"Unauthorized"}`)
+	record(http.MethodPost, "/login", jsonHeader, `{"email":"jane@corp.io","password":"hunter22"}`,
+		http.StatusOK, http.Header{"Content-Type": {"application/json"}, "Set-Cookie": {"sid=s-jane@corp.io"}, "X-Csrf-Token": {"csrf-jane@corp.io"}},
+		`{"token":"jwt-jane@corp.io","email":"jane@corp.io"}`)
+	record(http.MethodPost, "/update", http.Header{"Cookie": {"sid=s-jane@corp.io"}, "X-Csrf-Token": {"csrf-jane@corp.io"}, "Authorization": {"Bearer jwt-jane@corp.io"}}, "",
+		http.StatusNoContent, http.Header{}, "")
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+CassetteExt))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	raw, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "jane")
+	require.NotContains(t, string(raw), "hunter22")
+
+	replayer := &Replayer{Handler: mux, Carry: []string{"X-CSRF-Token"}, BearerField: "token", Sanitizer: NewSanitizer(nil, nil, nil)}
+	mismatches, err := replayer.ReplayFile(paths[0])
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+
+	// A changed handler is reported exchange by exchange
+	replayer.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/update" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	mismatches, err = replayer.ReplayFile(paths[0])
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	require.Equal(t, 2, mismatches[0].Index)
+	require.Equal(t, []Difference{{Path: "status", Recorded: "204", Replayed: "200"}}, mismatches[0].Differences)
+
+	// Update mode takes the replayed responses as the new goldens
+	replayer.Update = true
+	_, err = replayer.ReplayFile(paths[0])
+	require.NoError(t, err)
+	replayer.Update = false
+	mismatches, err = replayer.ReplayFile(paths[0])
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+}
@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Origin and remote address of replayed requests, the address is from the documentation range
+const (
+	replayHost       = "http://localhost"
+	replayRemoteAddr = "192.0.2.1:40000"
+)
+
+// Replayer sends the requests of a cassette to a handler in order and diffs every response with the recorded one.
+// Credentials were redacted when recording, so cookies set by replayed responses are sent back like a browser
+// would, Carry headers are taken from the latest response having them and requests recorded with an
+// Authorization header get the latest token found in BearerField of a response.
+type Replayer struct {
+	Handler http.Handler
+	// Rules applied to every cassette on top of its own
+	Ignore []string
+	// Response headers echoed by later requests, like a CSRF token
+	Carry []string
+	// Top level field of JSON responses holding a bearer token
+	BearerField string
+	// Applied to replayed responses before the diff, without a faker
+	Sanitizer *Sanitizer
+	// Rewrite the recorded responses with the replayed ones instead of reporting differences
+	Update bool
+}
+
+// Mismatch of one replayed exchange
+type Mismatch struct {
+	Index       int
+	Method      string
+	URL         string
+	Differences []Difference
+}
+
+func (m Mismatch) String() string {
+	lines := make([]string, 0, len(m.Differences)+1)
+	lines = append(lines, fmt.Sprintf("exchange %d %s %s:", m.Index, m.Method, m.URL))
+	for _, difference := range m.Differences {
+		lines = append(lines, "\t"+difference.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Replay cassette file, in update mode the file is rewritten
+func (r *Replayer) ReplayFile(path string) ([]Mismatch, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := r.Replay(cassette)
+	if err != nil || !r.Update {
+		return mismatches, err
+	}
+	return nil, cassette.Save(path)
+}
+
+// Replay cassette, in update mode its responses are replaced and no mismatch is reported
+func (r *Replayer) Replay(cassette *Cassette) ([]Mismatch, error) {
+	rules := ParseRules(append(append([]string(nil), r.Ignore...), cassette.Ignore...))
+	cookies := make(map[string]*http.Cookie)
+	carried := make(map[string]string)
+	var bearer string
+
+	var mismatches []Mismatch
+	for i := range cassette.Exchanges {
+		exchange := &cassette.Exchanges[i]
+		req, err := r.request(exchange.Request, cookies, carried, bearer)
+		if err != nil {
+			return nil, err
+		}
+
+		rec := httptest.NewRecorder()
+		r.Handler.ServeHTTP(rec, req)
+		result := rec.Result()
+		for _, cookie := range result.Cookies() {
+			if cookie.MaxAge < 0 {
+				delete(cookies, cookie.Name)
+				continue
+			}
+			cookies[cookie.Name] = cookie
+		}
+		for _, name := range r.Carry {
+			if value := result.Header.Get(name); value != "" {
+				carried[http.CanonicalHeaderKey(name)] = value
+			}
+		}
+		if token := r.bearer(result.Header, rec.Body.Bytes()); token != "" {
+			bearer = token
+		}
+
+		replayed := Response{
+			Status: result.StatusCode,
+			Header: r.Sanitizer.Header(withoutIgnored(result.Header)),
+			Body:   encodeBody(result.Header, r.Sanitizer.ResponseBody(result.Header, rec.Body.Bytes())),
+		}
+		if r.Update {
+			exchange.Response = replayed
+			continue
+		}
+		if differences := Diff(exchange.Response, replayed, rules); len(differences) > 0 {
+			mismatches = append(mismatches, Mismatch{
+				Index:       i,
+				Method:      exchange.Request.Method,
+				URL:         exchange.Request.URL,
+				Differences: differences,
+			})
+		}
+	}
+	return mismatches, nil
+}
+
+func (r *Replayer) request(recorded Request, cookies map[string]*http.Cookie, carried map[string]string, bearer string) (*http.Request, error) {
+	body, err := decodeBody(recorded.Header, recorded.Body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(recorded.Method, replayHost+recorded.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "replay.request.NewRequest")
+	}
+	req.RemoteAddr = replayRemoteAddr
+	for name, values := range recorded.Header {
+		req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+
+	req.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	for name, value := range carried {
+		if req.Header.Get(name) != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	if bearer != "" && req.Header.Get("Authorization") != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return req, nil
+}
+
+func (r *Replayer) bearer(header http.Header, body []byte) string {
+	if r.BearerField == "" || !isJSON(header) {
+		return ""
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	token, _ := fields[r.BearerField].(string)
+	return token
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/anonymize"
+)
+
+// Placeholder of redacted values
+const Redacted = "[REDACTED]"
+
+var (
+	// Headers always redacted, they carry credentials
+	DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-CSRF-Token"}
+	// JSON fields and query parameters always redacted, matched by name at any depth
+	DefaultRedactFields = []string{"password", "token", "secret", "code_hash"}
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	phonePattern = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+)
+
+// Sanitizer strips credentials and personal data from recorded traffic. Personal data is replaced by
+// deterministic fakes so a user registered under one email still logs in with it on replay. Secret fields are
+// replaced by Redacted in responses and by stand-ins in requests, where equal secrets have to stay equal.
+type Sanitizer struct {
+	headers map[string]bool
+	fields  map[string]bool
+	fakes   map[string]func(string) string
+	secret  func(string) string
+}
+
+// Sanitizer constructor, headers and fields are redacted on top of the defaults.
+// Without a faker personal data is kept, which replay relies on since recorded requests are already fake.
+func NewSanitizer(faker *anonymize.Faker, headers []string, fields []string) *Sanitizer {
+	s := &Sanitizer{headers: make(map[string]bool), fields: make(map[string]bool)}
+	for _, name := range append(append([]string(nil), DefaultRedactHeaders...), headers...) {
+		s.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range append(append([]string(nil), DefaultRedactFields...), fields...) {
+		s.fields[strings.ToLower(name)] = true
+	}
+	if faker != nil {
+		s.secret = faker.Secret
+		s.fakes = map[string]func(string) string{
+			"username":    faker.Username,
+			"email":       wellFormed(emailPattern, faker.Email),
+			"phone":       wellFormed(phonePattern, faker.Phone),
+			"number":      wellFormed(phonePattern, faker.Phone),
+			"line1":       faker.StreetLine,
+			"line2":       faker.SecondaryLine,
+			"postal_code": faker.PostalCode,
+		}
+	}
+	return s
+}
+
+// Fake only well formed values, faking a malformed one would turn a recorded validation error into a success
+// on replay, malformed values are redacted instead
+func wellFormed(pattern *regexp.Regexp, fake func(string) string) func(string) string {
+	return func(value string) string {
+		if !pattern.MatchString(strings.TrimSpace(value)) {
+			return Redacted
+		}
+		return fake(value)
+	}
+}
+
+// Sanitized copy of a header set
+func (s *Sanitizer) Header(header http.Header) http.Header {
+	sanitized := make(http.Header, len(header))
+	for name, values := range header {
+		values = append([]string(nil), values...)
+		if s.headers[http.CanonicalHeaderKey(name)] {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+		sanitized[name] = values
+	}
+	return sanitized
+}
+
+// Sanitized request URI, query parameters are treated like JSON fields of a request body
+func (s *Sanitizer) URL(uri string) string {
+	parsed, err := url.ParseRequestURI(uri)
+	if err != nil || parsed.RawQuery == "" {
+		return uri
+	}
+	query := parsed.Query()
+	for name, values := range query {
+		for i := range values {
+			values[i] = s.value(name, values[i], true).(string)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.RequestURI()
+}
+
+// Sanitized request body, only JSON bodies are inspected, anything else is returned as is
+func (s *Sanitizer) RequestBody(header http.Header, body []byte) []byte {
+	return s.body(header, body, true)
+}
+
+// Sanitized response body, like request bodies
+func (s *Sanitizer) ResponseBody(header http.Header, body []byte) []byte {
+	return s.body(header, body, false)
+}
+
+func (s *Sanitizer) body(header http.Header, body []byte, request bool) []byte {
+	if len(body) == 0 || !isJSON(header) {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return body
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s.walk(tree, request)); err != nil {
+		return body
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (s *Sanitizer) walk(node interface{}, request bool) interface{} {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			node[key] = s.value(key, value, request)
+		}
+	case []interface{}:
+		for i, value := range node {
+			node[i] = s.walk(value, request)
+		}
+	}
+	return node
+}
+
+// Value of a named field or parameter, empty values are kept so tests still see what was left blank
+func (s *Sanitizer) value(name string, value interface{}, request bool) interface{} {
+	name = strings.ToLower(name)
+	if s.fields[name] {
+		if value == nil || value == "" {
+			return value
+		}
+		if text, ok := value.(string); ok && request && s.secret != nil {
+			return s.secret(text)
+		}
+		return Redacted
+	}
+	if text, ok := value.(string); ok {
+		if fake, ok := s.fakes[name]; ok && text != "" {
+			return fake(text)
+		}
+		return text
+	}
+	return s.walk(value, request)
+}
@@ -0,0 +1,200 @@
+// Package reqschema validates requests against the operations of an OpenAPI spec before they reach a handler.
+// Operations are found by method and path template, so the parameter names of the spec and of the router need
+// not match, only their positions.
+package reqschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+// Field of a problem with the request body as a whole
+const FieldBody = "body"
+
+// Problem found in a request, Field is "body" or the dotted path below it with array indexes collapsed
+// to [], e.g. "body.items[].price", or the location and name of a parameter, e.g. "query.page"
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Operations of a spec by method and path template
+type Validator struct {
+	spec       *openapi3.T
+	operations map[string]*operation
+}
+
+type operation struct {
+	path string
+	item *openapi3.PathItem
+	op   *openapi3.Operation
+	// Names of the path parameters in the spec, in path order
+	params []string
+}
+
+// Validator from app config, nil when disabled. Spec is read from RequestSchema.Spec, fallback is the
+// document used when it is empty, like the generated docs
+func NewFromConfig(cfg *config.Config, fallback string) (*Validator, error) {
+	schemaCfg := cfg.RequestSchema
+	if !schemaCfg.Enabled {
+		return nil, nil
+	}
+
+	doc := []byte(fallback)
+	if schemaCfg.Spec != "" {
+		var err error
+		if doc, err = os.ReadFile(schemaCfg.Spec); err != nil {
+			return nil, errors.Wrap(err, "reqschema.NewFromConfig.ReadFile")
+		}
+	}
+	return New(doc, schemaCfg.PathPrefix)
+}
+
+// Validator of a Swagger 2.0 or OpenAPI 3 document in JSON or YAML, its paths are served under prefix
+func New(doc []byte, prefix string) (*Validator, error) {
+	raw, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "reqschema.New.YAMLToJSON")
+	}
+	var version struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return nil, errors.Wrap(err, "reqschema.New.Unmarshal")
+	}
+
+	var spec *openapi3.T
+	if strings.HasPrefix(version.Swagger, "2.") {
+		spec2 := &openapi2.T{}
+		if err := json.Unmarshal(raw, spec2); err != nil {
+			return nil, errors.Wrap(err, "reqschema.New.openapi2")
+		}
+		if spec, err = openapi2conv.ToV3(spec2); err != nil {
+			return nil, errors.Wrap(err, "reqschema.New.ToV3")
+		}
+	} else if spec, err = openapi3.NewLoader().LoadFromData(raw); err != nil {
+		return nil, errors.Wrap(err, "reqschema.New.LoadFromData")
+	}
+
+	v := &Validator{spec: spec, operations: make(map[string]*operation)}
+	if spec.Paths == nil {
+		return v, nil
+	}
+	for path, item := range spec.Paths.Map() {
+		template, params := pathTemplate(strings.TrimSuffix(prefix, "/") + path)
+		for method, op := range item.Operations() {
+			v.operations[method+" "+template] = &operation{path: path, item: item, op: op, params: params}
+		}
+	}
+	return v, nil
+}
+
+// Problems of req against the operation of its method on route, the router path with :name parameters whose
+// values are given in path order. ok is false when the spec has no such operation and nothing was checked
+func (v *Validator) Validate(req *http.Request, route string, values []string) (problems []FieldError, ok bool) {
+	template, _ := pathTemplate(route)
+	op, ok := v.operations[req.Method+" "+template]
+	if !ok {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(op.params))
+	for i, name := range op.params {
+		if i < len(values) {
+			params[name] = values[i]
+		}
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      &routers.Route{Spec: v.spec, Path: op.path, PathItem: op.item, Method: req.Method, Operation: op.op},
+		Options: &openapi3filter.Options{
+			MultiError: true,
+			// Credentials are checked by the auth middlewares
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+		problems = fieldErrors(err, nil)
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].Field < problems[j].Field })
+	}
+	return problems, true
+}
+
+// Flatten err into field problems, requestErr is the request error err was found under
+func fieldErrors(err error, requestErr *openapi3filter.RequestError) []FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		problems := make([]FieldError, 0, len(e))
+		for _, nested := range e {
+			problems = append(problems, fieldErrors(nested, requestErr)...)
+		}
+		return problems
+	case *openapi3filter.SecurityRequirementsError:
+		return nil
+	case *openapi3filter.RequestError:
+		if e.Err == nil {
+			return []FieldError{{Field: field(e, nil), Message: e.Reason}}
+		}
+		return fieldErrors(e.Err, e)
+	case *openapi3.SchemaError:
+		return []FieldError{{Field: field(requestErr, e.JSONPointer()), Message: e.Reason}}
+	case *openapi3filter.ParseError:
+		return []FieldError{{Field: field(requestErr, nil), Message: e.Reason}}
+	}
+
+	message := err.Error()
+	if requestErr != nil && requestErr.Reason != "" {
+		message = requestErr.Reason
+	}
+	return []FieldError{{Field: field(requestErr, nil), Message: message}}
+}
+
+// Field of a problem under requestErr, pointer is the json path within the body or parameter value
+func field(requestErr *openapi3filter.RequestError, pointer []string) string {
+	name := FieldBody
+	if requestErr != nil && requestErr.Parameter != nil {
+		name = requestErr.Parameter.In + "." + requestErr.Parameter.Name
+	}
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			name += "[]"
+			continue
+		}
+		name += "." + segment
+	}
+	return name
+}
+
+// Path with every parameter segment replaced by {}, either {name} of the spec or :name of the router,
+// and the parameter names in order
+func pathTemplate(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			params = append(params, segment[1:])
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			params = append(params, segment[1:len(segment)-1])
+		default:
+			continue
+		}
+		segments[i] = "{}"
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,98 @@
+package reqschema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+paths:
+  /orders/{id}:
+    put:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+        - {name: notify, in: query, schema: {type: boolean}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [customer, items]
+              properties:
+                customer: {type: string, maxLength: 5}
+                items:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      price: {type: number, minimum: 0}
+      responses:
+        "200": {description: ok}
+`
+
+const testSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "test", "version": "1"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "parameters": [{"type": "integer", "name": "id", "in": "path", "required": true}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`
+
+func TestValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	v, err := New([]byte(testSpec), "/api/v1/")
+	require.NoError(t, err)
+
+	validate := func(target string, body string) ([]FieldError, bool) {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		values := []string{strings.Split(strings.TrimPrefix(target, "/api/v1/orders/"), "?")[0]}
+		return v.Validate(req, "/api/v1/orders/:order_id", values)
+	}
+
+	problems, ok := validate("/api/v1/orders/7?notify=true", `{"customer": "ann", "items": [{"price": 1.5}]}`)
+	require.True(t, ok)
+	require.Empty(t, problems)
+
+	// Every failing field is reported, array indexes collapsed
+	problems, ok = validate("/api/v1/orders/x?notify=maybe", `{"customer": "annabelle", "items": [{"price": 1}, {"price": -1}]}`)
+	require.True(t, ok)
+	fields := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		fields = append(fields, problem.Field)
+		require.NotEmpty(t, problem.Message)
+	}
+	require.Equal(t, []string{"body.customer", "body.items[].price", "path.id", "query.notify"}, fields)
+
+	problems, _ = validate("/api/v1/orders/7", "")
+	require.Equal(t, []FieldError{{Field: FieldBody, Message: problems[0].Message}}, problems)
+
+	// Nothing is checked on operations the spec does not have
+	_, ok = v.Validate(httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil), "/api/v1/orders/:order_id", []string{"7"})
+	require.False(t, ok)
+}
+
+func TestNew_Swagger2(t *testing.T) {
+	t.Parallel()
+
+	v, err := New([]byte(testSwagger), "/api/v1")
+	require.NoError(t, err)
+
+	problems, ok := v.Validate(httptest.NewRequest(http.MethodGet, "/api/v1/users/ab", nil), "/api/v1/users/:user_id", []string{"ab"})
+	require.True(t, ok)
+	require.Len(t, problems, 1)
+	require.Equal(t, "path.id", problems[0].Field)
+}
@@ -0,0 +1,66 @@
+// Package requestctx holds the values auth middlewares resolve for a request behind typed keys. Values set on
+// an echo context are mirrored into its request context, so handlers read them from echo and usecases from
+// the context.Context they are handed, both through the same key.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+// Request value key bound to the type of its value, also the context.Context key of the value
+type Key[T any] string
+
+// Known request values
+var (
+	// Authenticated user, from a session, a bearer token or a trusted issuer token
+	User = Key[*models.UserWithRole]("user")
+	// Session of the request and the session id from its cookie
+	Session   = Key[*models.Session]("session")
+	SessionID = Key[string]("sid")
+	// Tenant of the session, empty for single tenant sessions
+	Tenant = Key[string]("tenant")
+	// Guest id of a guest session, data it creates is keyed by it
+	GuestID = Key[string]("guest_id")
+	// Space separated scopes granted to a scoped token
+	Scope = Key[string]("scope")
+	// Name of the trusted issuer which signed the bearer token
+	Issuer = Key[string]("issuer")
+	// Organization membership of the caller on routes scoped to its organization, zero OrganizationID outside any
+	Organization = Key[*models.OrganizationMember]("organization")
+	// Caller with the credential it authenticated with, set next to User by every auth middleware
+	Principal = Key[*models.Principal]("principal")
+)
+
+// Echo store name, prefixed so it never meets values set by name elsewhere
+func (k Key[T]) echoKey() string {
+	return "requestctx." + string(k)
+}
+
+// Set value on the echo context and on its request context
+func (k Key[T]) Set(c echo.Context, value T) {
+	c.Set(k.echoKey(), value)
+	c.SetRequest(c.Request().WithContext(k.With(c.Request().Context(), value)))
+}
+
+// Value set on the echo context, or on its request context when it was only put there, ok is false when unset
+func (k Key[T]) Get(c echo.Context) (T, bool) {
+	if value, ok := c.Get(k.echoKey()).(T); ok {
+		return value, true
+	}
+	return k.From(c.Request().Context())
+}
+
+// Copy of ctx carrying value
+func (k Key[T]) With(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// Value carried by ctx, ok is false when unset
+func (k Key[T]) From(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(k).(T)
+	return value, ok
+}
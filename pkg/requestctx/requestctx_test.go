@@ -0,0 +1,52 @@
+package requestctx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	_, ok := User.Get(c)
+	require.False(t, ok)
+
+	user := &models.UserWithRole{User: models.User{ID: 7}}
+	User.Set(c, user)
+	SessionID.Set(c, "sid-1")
+	Tenant.Set(c, "acme")
+
+	got, ok := User.Get(c)
+	require.True(t, ok)
+	require.Same(t, user, got)
+
+	// Usecases see the same values through the request context
+	ctx := c.Request().Context()
+	fromCtx, ok := User.From(ctx)
+	require.True(t, ok)
+	require.Same(t, user, fromCtx)
+	tenant, _ := Tenant.From(ctx)
+	require.Equal(t, "acme", tenant)
+
+	// Keys of the same value type don't share values, plain string keys don't reach them
+	_, ok = Scope.From(ctx)
+	require.False(t, ok)
+	require.Nil(t, c.Get("sid"))
+	require.Nil(t, ctx.Value("sid"))
+
+	// Values put only into the request context are found from echo too
+	c.SetRequest(c.Request().WithContext(Issuer.With(context.Background(), "okta")))
+	issuer, ok := Issuer.Get(c)
+	require.True(t, ok)
+	require.Equal(t, "okta", issuer)
+}
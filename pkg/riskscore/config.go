@@ -0,0 +1,62 @@
+package riskscore
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+// Engine from app config with the built-in scorers of a non zero weight, nil when risk scoring is disabled.
+// Scorers outside the config are added with extra
+func NewEngineFromConfig(cfg *config.Config, redisClient *redis.Client, extra ...Weighted) (*Engine, error) {
+	if !cfg.Risk.Enabled {
+		return nil, nil
+	}
+
+	scorers := make([]Weighted, 0, 3+len(extra))
+	if weight := cfg.Risk.Reputation.Weight; weight > 0 {
+		ranges := make([]ReputationRange, 0, len(cfg.Risk.Reputation.Ranges))
+		for _, item := range cfg.Risk.Reputation.Ranges {
+			ranges = append(ranges, ReputationRange{CIDR: item.CIDR, Score: item.Score})
+		}
+		reputation, err := NewStaticReputation(ranges)
+		if err != nil {
+			return nil, err
+		}
+		scorers = append(scorers, Weighted{Scorer: reputation, Weight: weight})
+	}
+	if weight := cfg.Risk.Velocity.Weight; weight > 0 {
+		window := time.Duration(cfg.Risk.Velocity.WindowSeconds) * time.Second
+		scorers = append(scorers, Weighted{
+			Scorer: NewVelocity(redisClient, cfg.Risk.Prefix, window, cfg.Risk.Velocity.MaxFailures),
+			Weight: weight,
+		})
+	}
+	if weight := cfg.Risk.Travel.Weight; weight > 0 {
+		locations := make([]LocatedRange, 0, len(cfg.Risk.Travel.Locations))
+		for _, item := range cfg.Risk.Travel.Locations {
+			locations = append(locations, LocatedRange{
+				CIDR:     item.CIDR,
+				Location: Location{Country: item.Country, Latitude: item.Latitude, Longitude: item.Longitude},
+			})
+		}
+		locator, err := NewStaticLocator(locations)
+		if err != nil {
+			return nil, err
+		}
+		retention := time.Duration(cfg.Risk.Travel.RetentionSeconds) * time.Second
+		scorers = append(scorers, Weighted{
+			Scorer: NewImpossibleTravel(redisClient, locator, cfg.Risk.Prefix, cfg.Risk.Travel.MaxSpeedKmh, retention),
+			Weight: weight,
+		})
+	}
+
+	thresholds := Thresholds{
+		Alert:  cfg.Risk.AlertThreshold,
+		StepUp: cfg.Risk.StepUpThreshold,
+		Block:  cfg.Risk.BlockThreshold,
+	}
+	return NewEngine(thresholds, append(scorers, extra...)...), nil
+}
@@ -0,0 +1,70 @@
+package riskscore
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Address range with a reputation score, 1 for known bad ranges such as anonymizing proxies
+type ReputationRange struct {
+	CIDR  string
+	Score float64
+}
+
+// IP reputation from a static list of ranges, the highest score of the ranges containing the address counts.
+// Feeds from a reputation service plug in as their own Scorer
+type StaticReputation struct {
+	ranges []*net.IPNet
+	scores []float64
+}
+
+// Static IP reputation constructor, single addresses are accepted as ranges
+func NewStaticReputation(ranges []ReputationRange) (*StaticReputation, error) {
+	r := &StaticReputation{}
+	for _, item := range ranges {
+		ipNet, err := parseRange(item.CIDR)
+		if err != nil {
+			return nil, errors.Wrap(err, "riskscore.NewStaticReputation")
+		}
+		r.ranges = append(r.ranges, ipNet)
+		r.scores = append(r.scores, item.Score)
+	}
+	return r, nil
+}
+
+// Scorer name
+func (r *StaticReputation) Name() string {
+	return "ip_reputation"
+}
+
+// Score of the worst range containing the attempt address
+func (r *StaticReputation) Score(_ context.Context, attempt *Attempt) (float64, string, error) {
+	ip := net.ParseIP(attempt.IPAddress)
+	if ip == nil {
+		return 0, "", nil
+	}
+	worst := 0.0
+	for i, ipNet := range r.ranges {
+		if ipNet.Contains(ip) && r.scores[i] > worst {
+			worst = r.scores[i]
+		}
+	}
+	if worst == 0 {
+		return 0, "", nil
+	}
+	return worst, "listed_address", nil
+}
+
+// CIDR or single address as a network
+func parseRange(value string) (*net.IPNet, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			return &net.IPNet{IP: v4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	return ipNet, err
+}
@@ -0,0 +1,151 @@
+// Package riskscore scores login attempts with pluggable scorers and turns the weighted score into a decision:
+// allow, alert the user, force step-up authentication or block.
+package riskscore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Decisions, ordered by severity
+const (
+	DecisionAllow  = "allow"
+	DecisionAlert  = "alert"
+	DecisionStepUp = "step_up"
+	DecisionBlock  = "block"
+)
+
+// Login attempt. UserID is only known once the credentials matched, failed attempts carry the username
+type Attempt struct {
+	UserID    int
+	Username  string
+	IPAddress string
+	UserAgent string
+	Success   bool
+	At        time.Time
+}
+
+// Scorer rates how suspicious an attempt is from 0, nothing unusual, to 1, certainly malicious.
+// Reason names what was found and is empty for a zero score
+type Scorer interface {
+	Name() string
+	Score(ctx context.Context, attempt *Attempt) (score float64, reason string, err error)
+}
+
+// Scorers keeping state implement Observer to learn the outcome of every attempt
+type Observer interface {
+	Observe(ctx context.Context, attempt *Attempt) error
+}
+
+// Scorer with the weight of its score in the total
+type Weighted struct {
+	Scorer Scorer
+	Weight float64
+}
+
+// Lowest totals reaching each decision, a zero threshold is never reached
+type Thresholds struct {
+	Alert  float64
+	StepUp float64
+	Block  float64
+}
+
+// Score of one scorer, only scorers which found something are listed
+type Signal struct {
+	Scorer string  `json:"scorer"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Reason string  `json:"reason"`
+}
+
+// Outcome of an assessment, Score is the weighted sum of the signals capped at 1
+type Assessment struct {
+	Score    float64  `json:"score"`
+	Decision string   `json:"decision"`
+	Signals  []Signal `json:"signals,omitempty"`
+}
+
+// Risk engine, safe for concurrent use
+type Engine struct {
+	scorers    []Weighted
+	thresholds Thresholds
+}
+
+// Risk engine constructor
+func NewEngine(thresholds Thresholds, scorers ...Weighted) *Engine {
+	return &Engine{scorers: scorers, thresholds: thresholds}
+}
+
+// Score attempt with every scorer and decide. Scorers failing are left out so an unavailable store does not
+// lock users out, the assessment is still returned along with an error naming them
+func (e *Engine) Assess(ctx context.Context, attempt *Attempt) (*Assessment, error) {
+	assessment := &Assessment{}
+	var failed []string
+	for _, weighted := range e.scorers {
+		score, reason, err := weighted.Scorer.Score(ctx, attempt)
+		if err != nil {
+			failed = append(failed, weighted.Scorer.Name()+": "+err.Error())
+			continue
+		}
+		if score <= 0 {
+			continue
+		}
+		if score > 1 {
+			score = 1
+		}
+		assessment.Signals = append(assessment.Signals, Signal{
+			Scorer: weighted.Scorer.Name(),
+			Score:  score,
+			Weight: weighted.Weight,
+			Reason: reason,
+		})
+		assessment.Score += score * weighted.Weight
+	}
+	if assessment.Score > 1 {
+		assessment.Score = 1
+	}
+	assessment.Decision = e.decide(assessment.Score)
+
+	if len(failed) > 0 {
+		return assessment, errors.Errorf("riskscore.Engine.Assess: %s", strings.Join(failed, "; "))
+	}
+	return assessment, nil
+}
+
+// Report outcome of an attempt to the scorers keeping state
+func (e *Engine) Observe(ctx context.Context, attempt *Attempt) error {
+	var failed []string
+	for _, weighted := range e.scorers {
+		observer, ok := weighted.Scorer.(Observer)
+		if !ok {
+			continue
+		}
+		if err := observer.Observe(ctx, attempt); err != nil {
+			failed = append(failed, weighted.Scorer.Name()+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("riskscore.Engine.Observe: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (e *Engine) decide(score float64) string {
+	switch {
+	case reached(score, e.thresholds.Block):
+		return DecisionBlock
+	case reached(score, e.thresholds.StepUp):
+		return DecisionStepUp
+	case reached(score, e.thresholds.Alert):
+		return DecisionAlert
+	default:
+		return DecisionAllow
+	}
+}
+
+func reached(score float64, threshold float64) bool {
+	return threshold > 0 && score >= threshold
+}
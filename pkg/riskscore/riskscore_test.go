@@ -0,0 +1,163 @@
+package riskscore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedScorer struct {
+	name  string
+	score float64
+	err   error
+}
+
+func (s *fixedScorer) Name() string { return s.name }
+
+func (s *fixedScorer) Score(context.Context, *Attempt) (float64, string, error) {
+	return s.score, s.name, s.err
+}
+
+func newTestRedis(t *testing.T) *redis.Client {
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Close)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	thresholds := Thresholds{Alert: 0.3, StepUp: 0.6, Block: 0.9}
+
+	cases := []struct {
+		scores   []float64
+		decision string
+	}{
+		{scores: []float64{0, 0}, decision: DecisionAllow},
+		{scores: []float64{0.5, 0}, decision: DecisionAlert},
+		{scores: []float64{0.5, 0.5}, decision: DecisionStepUp},
+		{scores: []float64{1, 1}, decision: DecisionBlock},
+	}
+	for _, tc := range cases {
+		engine := NewEngine(thresholds,
+			Weighted{Scorer: &fixedScorer{name: "a", score: tc.scores[0]}, Weight: 0.6},
+			Weighted{Scorer: &fixedScorer{name: "b", score: tc.scores[1]}, Weight: 0.6},
+		)
+		assessment, err := engine.Assess(ctx, &Attempt{})
+		require.NoError(t, err)
+		require.Equal(t, tc.decision, assessment.Decision, "scores %v", tc.scores)
+		require.LessOrEqual(t, assessment.Score, 1.0)
+	}
+
+	// A failing scorer is left out and reported, the others still decide
+	engine := NewEngine(thresholds,
+		Weighted{Scorer: &fixedScorer{name: "down", err: errors.New("unavailable")}, Weight: 1},
+		Weighted{Scorer: &fixedScorer{name: "up", score: 0.5}, Weight: 1},
+	)
+	assessment, err := engine.Assess(ctx, &Attempt{})
+	require.Error(t, err)
+	require.Equal(t, DecisionAlert, assessment.Decision)
+	require.Equal(t, []Signal{{Scorer: "up", Score: 0.5, Weight: 1, Reason: "up"}}, assessment.Signals)
+
+	// Zero thresholds are never reached
+	assessment, err = NewEngine(Thresholds{}, Weighted{Scorer: &fixedScorer{name: "a", score: 1}, Weight: 1}).Assess(ctx, &Attempt{})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllow, assessment.Decision)
+}
+
+func TestStaticReputation(t *testing.T) {
+	t.Parallel()
+
+	reputation, err := NewStaticReputation([]ReputationRange{{CIDR: "198.51.100.0/24", Score: 0.5}, {CIDR: "198.51.100.7", Score: 1}})
+	require.NoError(t, err)
+
+	for ip, want := range map[string]float64{"198.51.100.7": 1, "198.51.100.8": 0.5, "203.0.113.1": 0, "not an ip": 0} {
+		score, _, err := reputation.Score(context.Background(), &Attempt{IPAddress: ip})
+		require.NoError(t, err)
+		require.Equal(t, want, score, ip)
+	}
+
+	_, err = NewStaticReputation([]ReputationRange{{CIDR: "nope"}})
+	require.Error(t, err)
+}
+
+func TestVelocity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	velocity := NewVelocity(newTestRedis(t), "risk", time.Minute, 4)
+
+	failure := &Attempt{Username: "Jane", IPAddress: "203.0.113.1"}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, velocity.Observe(ctx, failure))
+	}
+	require.NoError(t, velocity.Observe(ctx, &Attempt{Username: "bob", IPAddress: "203.0.113.1"}))
+
+	score, reason, err := velocity.Score(ctx, &Attempt{Username: "jane", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+	require.Equal(t, 0.5, score)
+	require.Equal(t, "username_failures", reason)
+
+	score, reason, err = velocity.Score(ctx, &Attempt{Username: "alice", IPAddress: "203.0.113.1"})
+	require.NoError(t, err)
+	require.Equal(t, 0.75, score)
+	require.Equal(t, "address_failures", reason)
+
+	// Logging in clears the failures of the username, not those of the address
+	require.NoError(t, velocity.Observe(ctx, &Attempt{Username: "JANE", IPAddress: "192.0.2.1", Success: true}))
+	score, _, err = velocity.Score(ctx, &Attempt{Username: "jane", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+	require.Zero(t, score)
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locator, err := NewStaticLocator([]LocatedRange{
+		{CIDR: "192.0.2.0/24", Location: Location{Country: "GB", Latitude: 51.5074, Longitude: -0.1278}},
+		{CIDR: "198.51.100.0/24", Location: Location{Country: "AU", Latitude: -33.8688, Longitude: 151.2093}},
+		{CIDR: "203.0.113.0/24", Location: Location{Country: "FR", Latitude: 48.8566, Longitude: 2.3522}},
+	})
+	require.NoError(t, err)
+	travel := NewImpossibleTravel(newTestRedis(t), locator, "risk", 900, time.Hour)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, travel.Observe(ctx, &Attempt{UserID: 1, IPAddress: "192.0.2.10", Success: true, At: start}))
+
+	cases := []struct {
+		ip    string
+		after time.Duration
+		score float64
+	}{
+		// London to Sydney in an hour
+		{ip: "198.51.100.1", after: time.Hour, score: 1},
+		// a day is enough
+		{ip: "198.51.100.1", after: 24 * time.Hour, score: 0},
+		// London to Paris, 340 km, in an hour
+		{ip: "203.0.113.1", after: time.Hour, score: 0},
+		// same place at once
+		{ip: "192.0.2.11", after: 0, score: 0},
+		// unknown address
+		{ip: "10.0.0.1", after: 0, score: 0},
+	}
+	for _, tc := range cases {
+		score, _, err := travel.Score(ctx, &Attempt{UserID: 1, IPAddress: tc.ip, At: start.Add(tc.after)})
+		require.NoError(t, err)
+		require.Equal(t, tc.score, score, "%s after %s", tc.ip, tc.after)
+	}
+
+	// No history yet
+	score, _, err := travel.Score(ctx, &Attempt{UserID: 2, IPAddress: "198.51.100.1", At: start})
+	require.NoError(t, err)
+	require.Zero(t, score)
+}
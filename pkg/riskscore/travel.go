@@ -0,0 +1,158 @@
+package riskscore
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const earthRadiusKm = 6371.0
+
+// Where an address is, Country is an ISO 3166 code
+type Location struct {
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Locator resolves addresses to locations, nil when the address is unknown. GeoIP databases plug in here
+type Locator interface {
+	Locate(ctx context.Context, ipAddress string) (*Location, error)
+}
+
+// Address range at a known location
+type LocatedRange struct {
+	CIDR string
+	Location
+}
+
+// Locator from a static list of ranges, meant for office and VPN egress ranges and for tests
+type StaticLocator struct {
+	ranges    []*net.IPNet
+	locations []Location
+}
+
+// Static locator constructor, the first range containing an address wins
+func NewStaticLocator(ranges []LocatedRange) (*StaticLocator, error) {
+	l := &StaticLocator{}
+	for _, item := range ranges {
+		ipNet, err := parseRange(item.CIDR)
+		if err != nil {
+			return nil, errors.Wrap(err, "riskscore.NewStaticLocator")
+		}
+		l.ranges = append(l.ranges, ipNet)
+		l.locations = append(l.locations, item.Location)
+	}
+	return l, nil
+}
+
+// Location of the first range containing the address
+func (l *StaticLocator) Locate(_ context.Context, ipAddress string) (*Location, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, nil
+	}
+	for i, ipNet := range l.ranges {
+		if ipNet.Contains(ip) {
+			location := l.locations[i]
+			return &location, nil
+		}
+	}
+	return nil, nil
+}
+
+// Last successful login of a user, stored between attempts
+type lastLogin struct {
+	Location
+	At time.Time `json:"at"`
+}
+
+// Impossible travel, a login from further away than the user could have traveled at MaxSpeedKmh since
+// their last successful login scores 1. Locations of successful logins are kept for retention
+type ImpossibleTravel struct {
+	redisClient *redis.Client
+	locator     Locator
+	prefix      string
+	maxSpeedKmh float64
+	retention   time.Duration
+}
+
+// Impossible travel scorer constructor, keys are stored under prefix
+func NewImpossibleTravel(redisClient *redis.Client, locator Locator, prefix string, maxSpeedKmh float64, retention time.Duration) *ImpossibleTravel {
+	return &ImpossibleTravel{redisClient: redisClient, locator: locator, prefix: prefix, maxSpeedKmh: maxSpeedKmh, retention: retention}
+}
+
+// Scorer name
+func (t *ImpossibleTravel) Name() string {
+	return "impossible_travel"
+}
+
+// Score speed needed to get from the last login location to the attempt location
+func (t *ImpossibleTravel) Score(ctx context.Context, attempt *Attempt) (float64, string, error) {
+	if attempt.UserID == 0 || t.maxSpeedKmh <= 0 {
+		return 0, "", nil
+	}
+	location, err := t.locator.Locate(ctx, attempt.IPAddress)
+	if err != nil || location == nil {
+		return 0, "", err
+	}
+
+	raw, err := t.redisClient.Get(ctx, t.key(attempt.UserID)).Bytes()
+	if err == redis.Nil {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", errors.Wrap(err, "riskscore.ImpossibleTravel.Score.Get")
+	}
+	last := &lastLogin{}
+	if err := json.Unmarshal(raw, last); err != nil {
+		return 0, "", errors.Wrap(err, "riskscore.ImpossibleTravel.Score.json.Unmarshal")
+	}
+
+	distance := distanceKm(last.Location, *location)
+	hours := attempt.At.Sub(last.At).Hours()
+	// Nearby logins are fine however quick, locations of addresses are not precise
+	if distance < 100 || (hours > 0 && distance/hours <= t.maxSpeedKmh) {
+		return 0, "", nil
+	}
+	return 1, "impossible_travel", nil
+}
+
+// Remember location of successful logins
+func (t *ImpossibleTravel) Observe(ctx context.Context, attempt *Attempt) error {
+	if !attempt.Success || attempt.UserID == 0 {
+		return nil
+	}
+	location, err := t.locator.Locate(ctx, attempt.IPAddress)
+	if err != nil || location == nil {
+		return err
+	}
+	raw, err := json.Marshal(&lastLogin{Location: *location, At: attempt.At})
+	if err != nil {
+		return errors.Wrap(err, "riskscore.ImpossibleTravel.Observe.json.Marshal")
+	}
+	return errors.Wrap(t.redisClient.Set(ctx, t.key(attempt.UserID), raw, t.retention).Err(), "riskscore.ImpossibleTravel.Observe.Set")
+}
+
+func (t *ImpossibleTravel) key(userID int) string {
+	return t.prefix + ":last-login:" + strconv.Itoa(userID)
+}
+
+// Great circle distance
+func distanceKm(from Location, to Location) float64 {
+	lat1, lat2 := radians(from.Latitude), radians(to.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(to.Longitude - from.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
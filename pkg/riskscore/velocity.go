@@ -0,0 +1,85 @@
+package riskscore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// Count a failure and start the window on the first one, like the rate limiter
+var failureScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	if redis.call("INCR", key) == 1 then
+		redis.call("PEXPIRE", key, ARGV[1])
+	end
+end
+return 0
+`)
+
+// Failed attempts against the username and from the address within a fixed window. The score grows
+// linearly up to 1 at MaxFailures, the worse of both counts. A success clears the count of the username
+type Velocity struct {
+	redisClient *redis.Client
+	prefix      string
+	window      time.Duration
+	maxFailures int
+}
+
+// Velocity scorer constructor, keys are stored under prefix
+func NewVelocity(redisClient *redis.Client, prefix string, window time.Duration, maxFailures int) *Velocity {
+	if maxFailures <= 0 {
+		maxFailures = 1
+	}
+	return &Velocity{redisClient: redisClient, prefix: prefix, window: window, maxFailures: maxFailures}
+}
+
+// Scorer name
+func (v *Velocity) Name() string {
+	return "velocity"
+}
+
+// Score recent failures of the username and the address
+func (v *Velocity) Score(ctx context.Context, attempt *Attempt) (float64, string, error) {
+	values, err := v.redisClient.MGet(ctx, v.userKey(attempt.Username), v.ipKey(attempt.IPAddress)).Result()
+	if err != nil {
+		return 0, "", errors.Wrap(err, "riskscore.Velocity.Score.MGet")
+	}
+
+	counts := make([]int, len(values))
+	for i, value := range values {
+		raw, _ := value.(string)
+		counts[i], _ = strconv.Atoi(raw)
+	}
+	failures, reason := counts[0], "username_failures"
+	if counts[1] > failures {
+		failures, reason = counts[1], "address_failures"
+	}
+	if failures == 0 {
+		return 0, "", nil
+	}
+	return float64(failures) / float64(v.maxFailures), reason, nil
+}
+
+// Count failures, forget those of the username once it logs in
+func (v *Velocity) Observe(ctx context.Context, attempt *Attempt) error {
+	if attempt.Success {
+		if attempt.Username == "" {
+			return nil
+		}
+		return errors.Wrap(v.redisClient.Del(ctx, v.userKey(attempt.Username)).Err(), "riskscore.Velocity.Observe.Del")
+	}
+	keys := []string{v.userKey(attempt.Username), v.ipKey(attempt.IPAddress)}
+	return errors.Wrap(failureScript.Run(ctx, v.redisClient, keys, v.window.Milliseconds()).Err(), "riskscore.Velocity.Observe.failureScript")
+}
+
+func (v *Velocity) userKey(username string) string {
+	return v.prefix + ":failures:user:" + strings.ToLower(username)
+}
+
+func (v *Velocity) ipKey(ipAddress string) string {
+	return v.prefix + ":failures:ip:" + ipAddress
+}
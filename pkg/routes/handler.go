@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes of the listing
+type listing struct {
+	Routes []Route `json:"routes"`
+}
+
+// Register GET /routes on group, the table filtered by ?module= and by ?auth=, a requirement or "none" for
+// routes open without authentication
+func (t *Table) MapRoutes(group *echo.Group) {
+	group.GET("/routes", t.list)
+}
+
+func (t *Table) list(c echo.Context) error {
+	module, auth := c.QueryParam("module"), c.QueryParam("auth")
+	routes := make([]Route, 0)
+	for _, route := range t.List() {
+		if module != "" && route.Module != module {
+			continue
+		}
+		if auth != "" && !requires(route, auth) {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return c.JSON(http.StatusOK, listing{Routes: routes})
+}
+
+func requires(route Route, auth string) bool {
+	if auth == "none" {
+		return len(route.Auth) == 0
+	}
+	for _, requirement := range route.Auth {
+		if requirement == auth {
+			return true
+		}
+	}
+	return false
+}
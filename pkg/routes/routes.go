@@ -0,0 +1,210 @@
+// Package routes keeps a table of the routes registered on an echo instance with the middleware chain each one
+// runs, for operators and security reviewers auditing the exposed surface. Echo only remembers method, path and
+// handler name, the table hooks route registration for the route and group middlewares and is handed the global
+// ones through its Pre and Use.
+package routes
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Registered route, Middleware is the chain in the order it runs including the global middlewares and Auth the
+// requirements of the auth middlewares in it
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Host       string   `json:"host,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	Module     string   `json:"module,omitempty"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+	Auth       []string `json:"auth"`
+}
+
+// Routes of an echo instance, safe for concurrent use
+type Table struct {
+	e    *echo.Echo
+	auth map[string]string
+
+	mu     sync.Mutex
+	global []string
+	routes map[string]*entry
+	// Module the routes registered now belong to, set by Within
+	module string
+}
+
+type entry struct {
+	route      Route
+	middleware []string
+}
+
+var (
+	// Module path, names of its functions are listed relative to it
+	modulePath = strings.TrimSuffix(reflect.TypeOf((*Table)(nil)).Elem().PkgPath(), "pkg/routes")
+	// Suffixes of closures and method values in function names
+	closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+|-fm)+$`)
+	// Pointer receiver of a method in function names, e.g. (*MiddlewareManager)
+	receiver = regexp.MustCompile(`\(\*([^)]*)\)`)
+	version  = regexp.MustCompile(`^v\d+$`)
+)
+
+// Table of the routes registered on e from now on. auth maps middleware names, as Name gives them, to the
+// requirement the middleware enforces, e.g. "session" or "admin"
+func New(e *echo.Echo, auth map[string]string) *Table {
+	t := &Table{e: e, auth: auth, routes: make(map[string]*entry)}
+	next := e.OnAddRouteHandler
+	e.OnAddRouteHandler = func(host string, route echo.Route, handler echo.HandlerFunc, middleware []echo.MiddlewareFunc) {
+		if next != nil {
+			next(host, route, handler, middleware)
+		}
+		t.add(host, route, handler, middleware)
+	}
+	return t
+}
+
+// Name of a handler or middleware function, its package path relative to the module, or without github.com/
+// outside of it, and its function name without closure suffixes, e.g. "internal/middleware.MiddlewareManager.CSRF"
+func Name(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if !value.IsValid() {
+		return ""
+	}
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return value.Type().String()
+	}
+	name := runtime.FuncForPC(value.Pointer()).Name()
+	name = closureSuffix.ReplaceAllString(name, "")
+	name = receiver.ReplaceAllString(name, "$1")
+	if trimmed := strings.TrimPrefix(name, modulePath); trimmed != name {
+		return trimmed
+	}
+	return strings.TrimPrefix(name, "github.com/")
+}
+
+// Register middleware run before routing on e
+func (t *Table) Pre(middleware ...echo.MiddlewareFunc) {
+	t.e.Pre(middleware...)
+	t.addGlobal(middleware)
+}
+
+// Register middleware run on every route of e
+func (t *Table) Use(middleware ...echo.MiddlewareFunc) {
+	t.e.Use(middleware...)
+	t.addGlobal(middleware)
+}
+
+// Attribute the routes register adds to module
+func (t *Table) Within(module string, register func()) {
+	t.mu.Lock()
+	t.module = module
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.module = ""
+		t.mu.Unlock()
+	}()
+	register()
+}
+
+// Routes ordered by path and method. Routes registered twice are listed as they were last registered
+func (t *Table) List() []Route {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := make([]Route, 0, len(t.routes))
+	for _, e := range t.routes {
+		route := e.route
+		route.Middleware = append(append(make([]string, 0, len(t.global)+len(e.middleware)), t.global...), e.middleware...)
+		route.Auth = t.requirements(route.Middleware)
+		list = append(list, route)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		if list[i].Method != list[j].Method {
+			return list[i].Method < list[j].Method
+		}
+		return list[i].Host < list[j].Host
+	})
+	return list
+}
+
+func (t *Table) add(host string, route echo.Route, handler echo.HandlerFunc, middleware []echo.MiddlewareFunc) {
+	// Catch-alls echo adds for group middlewares are not routes of the api
+	if route.Method == echo.RouteNotFound {
+		return
+	}
+	names := make([]string, 0, len(middleware))
+	for _, m := range middleware {
+		names = append(names, Name(m))
+	}
+	handlerName := Name(handler)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	module := t.module
+	if module == "" {
+		module = handlerModule(handlerName)
+	}
+	t.routes[host+" "+route.Method+" "+route.Path] = &entry{
+		route: Route{
+			Method:  route.Method,
+			Path:    route.Path,
+			Host:    host,
+			Version: pathVersion(route.Path),
+			Module:  module,
+			Handler: handlerName,
+		},
+		middleware: names,
+	}
+}
+
+func (t *Table) addGlobal(middleware []echo.MiddlewareFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range middleware {
+		t.global = append(t.global, Name(m))
+	}
+}
+
+// Requirements of the auth middlewares in chain, each once in chain order
+func (t *Table) requirements(chain []string) []string {
+	requirements := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, name := range chain {
+		if requirement, ok := t.auth[name]; ok && !seen[requirement] {
+			seen[requirement] = true
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements
+}
+
+// Domain of a handler under internal/, e.g. "auth" for internal/auth/delivery/http.Login
+func handlerModule(handler string) string {
+	rest, ok := strings.CutPrefix(handler, "internal/")
+	if !ok {
+		return ""
+	}
+	module, _, _ := strings.Cut(rest, ".")
+	module, _, _ = strings.Cut(module, "/")
+	return module
+}
+
+// First path segment naming an api version, e.g. "v1" of /api/v1/auth/login
+func pathVersion(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if version.MatchString(segment) {
+			return segment
+		}
+	}
+	return ""
+}
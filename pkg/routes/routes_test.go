@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func requestLogger(next echo.HandlerFunc) echo.HandlerFunc { return next }
+func requireUser(next echo.HandlerFunc) echo.HandlerFunc   { return next }
+
+func requireRole(string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error { return next(c) }
+	}
+}
+
+type handlers struct{}
+
+func (handlers) Get() echo.HandlerFunc {
+	return func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+}
+
+func TestTable_List(t *testing.T) {
+	e := echo.New()
+	table := New(e, map[string]string{"pkg/routes.requireUser": "user", "pkg/routes.requireRole": "role"})
+	table.Use(requestLogger)
+
+	v1 := e.Group("/api/v1", requireUser)
+	v1.GET("/items", handlers{}.Get())
+	v1.DELETE("/items/:id", handlers{}.Get(), requireRole("admin"))
+	table.Within("widgets", func() {
+		v1.Group("/widgets").GET("", handlers{}.Get())
+	})
+	e.GET("/health", handlers{}.Get())
+	table.MapRoutes(v1)
+
+	// Chains start with the global middlewares, the catch-alls of the group middleware are left out
+	require.Equal(t, []Route{
+		{Method: http.MethodGet, Path: "/api/v1/items", Version: "v1", Handler: "pkg/routes.handlers.Get",
+			Middleware: []string{"pkg/routes.requestLogger", "pkg/routes.requireUser"}, Auth: []string{"user"}},
+		{Method: http.MethodDelete, Path: "/api/v1/items/:id", Version: "v1", Handler: "pkg/routes.handlers.Get",
+			Middleware: []string{"pkg/routes.requestLogger", "pkg/routes.requireUser", "pkg/routes.requireRole"}, Auth: []string{"user", "role"}},
+		{Method: http.MethodGet, Path: "/api/v1/routes", Version: "v1", Handler: "pkg/routes.Table.list",
+			Middleware: []string{"pkg/routes.requestLogger", "pkg/routes.requireUser"}, Auth: []string{"user"}},
+		{Method: http.MethodGet, Path: "/api/v1/widgets", Version: "v1", Module: "widgets", Handler: "pkg/routes.handlers.Get",
+			Middleware: []string{"pkg/routes.requestLogger", "pkg/routes.requireUser"}, Auth: []string{"user"}},
+		{Method: http.MethodGet, Path: "/health", Handler: "pkg/routes.handlers.Get",
+			Middleware: []string{"pkg/routes.requestLogger"}, Auth: []string{}},
+	}, table.List())
+
+	// Listing filtered by requirement
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/routes?auth=role", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed listing
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Routes, 1)
+	require.Equal(t, "/api/v1/items/:id", listed.Routes[0].Path)
+}
+
+func TestName(t *testing.T) {
+	require.Equal(t, "pkg/routes.requireRole", Name(requireRole("admin")))
+	require.Equal(t, "labstack/echo/v4/middleware.RequestIDWithConfig", Name(middleware.RequestID()))
+	require.Equal(t, "", Name(nil))
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultClamAVTimeout   = 60 * time.Second
+	defaultClamAVChunkSize = 64 * 1024
+)
+
+// ClamAV clamd TCP driver using the INSTREAM command
+type ClamAV struct {
+	address   string
+	timeout   time.Duration
+	chunkSize int
+}
+
+// ClamAV driver constructor
+func NewClamAV(address string, timeout time.Duration, chunkSize int) *ClamAV {
+	if timeout <= 0 {
+		timeout = defaultClamAVTimeout
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultClamAVChunkSize
+	}
+	return &ClamAV{address: address, timeout: timeout, chunkSize: chunkSize}
+}
+
+// Stream reader to clamd and parse the verdict
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, errors.Wrap(err, "ClamAV.Scan.Dial")
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, errors.Wrap(err, "ClamAV.Scan.SetDeadline")
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, errors.Wrap(err, "ClamAV.Scan.Write.command")
+	}
+
+	buf := make([]byte, c.chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, errors.Wrap(err, "ClamAV.Scan.Write.size")
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, errors.Wrap(err, "ClamAV.Scan.Write.chunk")
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, "ClamAV.Scan.Read")
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, errors.Wrap(err, "ClamAV.Scan.Write.terminator")
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "ClamAV.Scan.ReadReply")
+	}
+	return parseClamAVReply(reply)
+}
+
+// Replies look like "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+func parseClamAVReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	switch {
+	case strings.HasSuffix(reply, "OK"):
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(reply, "FOUND"):
+		signature := strings.TrimSuffix(reply, "FOUND")
+		signature = strings.TrimPrefix(signature, "stream:")
+		return &Result{Clean: false, Signature: strings.TrimSpace(signature)}, nil
+	default:
+		return nil, errors.Errorf("clamav: %s", reply)
+	}
+}
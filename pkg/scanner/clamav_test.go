@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Minimal clamd speaking INSTREAM, flags payloads containing "EICAR"
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				cmd := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, cmd); err != nil {
+					return
+				}
+				var body bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(conn, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					if _, err := io.CopyN(&body, conn, int64(n)); err != nil {
+						return
+					}
+				}
+				if bytes.Contains(body.Bytes(), []byte("EICAR")) {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	t.Parallel()
+
+	clam := NewClamAV(fakeClamd(t), 5*time.Second, 4)
+	ctx := context.Background()
+
+	result, err := clam.Scan(ctx, bytes.NewReader([]byte("harmless content")))
+	require.NoError(t, err)
+	require.True(t, result.Clean)
+
+	result, err = clam.Scan(ctx, bytes.NewReader([]byte("xx EICAR xx")))
+	require.NoError(t, err)
+	require.False(t, result.Clean)
+	require.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	_, err = parseClamAVReply("INSTREAM size limit exceeded. ERROR")
+	require.Error(t, err)
+}
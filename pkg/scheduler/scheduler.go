@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const defaultPrefix = "api-scheduler:"
+
+// Periodic task, an error is logged and the task runs again on the next tick
+type Task func(ctx context.Context) error
+
+type task struct {
+	name     string
+	interval time.Duration
+	fn       Task
+}
+
+// Runs periodic tasks, every tick takes a redis lock held for the task interval
+// so a task runs at most once per interval across all instances of the service
+type Scheduler struct {
+	redisClient *redis.Client
+	prefix      string
+	instance    string
+	logger      logger.Logger
+
+	mu    sync.Mutex
+	tasks []task
+}
+
+// Scheduler constructor, an empty prefix uses api-scheduler:
+func New(redisClient *redis.Client, prefix string, logger logger.Logger) *Scheduler {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &Scheduler{redisClient: redisClient, prefix: prefix, instance: uuid.New().String(), logger: logger}
+}
+
+// Register task running every interval, tasks registered after Run are ignored
+func (s *Scheduler) Every(name string, interval time.Duration, fn Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, task{name: name, interval: interval, fn: fn})
+}
+
+// Run tasks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	tasks := append([]task(nil), s.tasks...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		if t.interval <= 0 {
+			s.logger.Warnf("scheduler task %s has no interval, skipped", t.name)
+			continue
+		}
+		wg.Add(1)
+		go func(t task) {
+			defer wg.Done()
+			s.loop(ctx, t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, t task) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx, t)
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, t task) {
+	acquired, err := s.redisClient.SetNX(ctx, s.prefix+t.name, s.instance, t.interval).Result()
+	if err != nil {
+		s.logger.Errorf("scheduler task %s lock: %v", t.name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	start := time.Now()
+	if err := t.fn(ctx); err != nil {
+		s.logger.Errorf("scheduler task %s failed after %s: %v", t.name, time.Since(start), err)
+		return
+	}
+	s.logger.Debugf("scheduler task %s done in %s", t.name, time.Since(start))
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DriverEnv  = "env"
+	DriverFile = "file"
+)
+
+// ErrNotFound secret is not set in the provider
+var ErrNotFound = errors.New("secret not found")
+
+// Secrets provider
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Provider options
+type Options struct {
+	Driver string
+	// Env variable prefix, name "pii-keys" with prefix "APP_" reads APP_PII_KEYS
+	Prefix string
+	// Directory holding one file per secret, e.g. docker or kubernetes mounted secrets
+	Dir string
+}
+
+// Secrets provider constructor, env is the default driver
+func NewProvider(opts Options) (Provider, error) {
+	switch opts.Driver {
+	case DriverEnv, "":
+		return &envProvider{prefix: opts.Prefix}, nil
+	case DriverFile:
+		if opts.Dir == "" {
+			return nil, errors.New("secrets: file driver requires Dir")
+		}
+		return &fileProvider{dir: opts.Dir}, nil
+	default:
+		return nil, errors.Errorf("secrets: unknown driver %q", opts.Driver)
+	}
+}
+
+type envProvider struct {
+	prefix string
+}
+
+// Get secret from environment
+func (p *envProvider) Get(ctx context.Context, name string) (string, error) {
+	key := p.prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return "", errors.Wrapf(ErrNotFound, "env %s", key)
+	}
+	return value, nil
+}
+
+type fileProvider struct {
+	dir string
+}
+
+// Get secret from file named after it, surrounding whitespace is trimmed
+func (p *fileProvider) Get(ctx context.Context, name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return "", errors.Errorf("secrets: invalid name %q", name)
+	}
+	raw, err := os.ReadFile(filepath.Join(p.dir, name))
+	if os.IsNotExist(err) {
+		return "", errors.Wrapf(ErrNotFound, "file %s", name)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "secrets.fileProvider.ReadFile")
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
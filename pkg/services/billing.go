@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpclient"
+)
+
+// Billing service name in config
+const BillingService = "billing"
+
+// Subscription of a user kept by the billing service
+type Subscription struct {
+	UserID   int       `json:"user_id"`
+	Plan     string    `json:"plan"`
+	Status   string    `json:"status"`
+	RenewsAt time.Time `json:"renews_at"`
+}
+
+// Billing service client
+type BillingClient struct {
+	client *httpclient.Client
+}
+
+// Billing service client constructor
+func NewBillingClient(r *Registry) (*BillingClient, error) {
+	client, err := r.Client(BillingService)
+	if err != nil {
+		return nil, err
+	}
+	return &BillingClient{client: client}, nil
+}
+
+// Get subscription by user id
+func (c *BillingClient) GetSubscription(ctx context.Context, userID int) (*Subscription, error) {
+	subscription := &Subscription{}
+	if err := c.client.Get(ctx, fmt.Sprintf("/subscriptions/%d", userID), subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpclient"
+)
+
+// HR system name in config
+const HRService = "hr"
+
+// Employee record kept by the HR system
+type HRUser struct {
+	ExternalID string    `json:"external_id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	Phone      string    `json:"phone,omitempty"`
+	Timezone   string    `json:"timezone,omitempty"`
+	Active     bool      `json:"active"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Page of changed employee records, NextCursor resumes the feed after this page
+type HRChanges struct {
+	Users      []HRUser `json:"users"`
+	NextCursor string   `json:"next_cursor"`
+	HasMore    bool     `json:"has_more"`
+}
+
+// HR system client
+type HRClient struct {
+	client *httpclient.Client
+}
+
+// HR system client constructor
+func NewHRClient(r *Registry) (*HRClient, error) {
+	client, err := r.Client(HRService)
+	if err != nil {
+		return nil, err
+	}
+	return &HRClient{client: client}, nil
+}
+
+// Get records changed after cursor, an empty cursor starts from the beginning of the feed
+func (c *HRClient) Changes(ctx context.Context, cursor string, limit int) (*HRChanges, error) {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	changes := &HRChanges{}
+	if err := c.client.Get(ctx, "/users/changes?"+query.Encode(), changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpclient"
+)
+
+// Profile service name in config
+const ProfileService = "profile"
+
+// Profile of a user kept by the profile service
+type Profile struct {
+	UserID    int       `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Profile service client
+type ProfileClient struct {
+	client *httpclient.Client
+}
+
+// Profile service client constructor
+func NewProfileClient(r *Registry) (*ProfileClient, error) {
+	client, err := r.Client(ProfileService)
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileClient{client: client}, nil
+}
+
+// Get profile by user id
+func (c *ProfileClient) GetProfile(ctx context.Context, userID int) (*Profile, error) {
+	profile := &Profile{}
+	if err := c.client.Get(ctx, fmt.Sprintf("/profiles/%d", userID), profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
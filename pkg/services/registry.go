@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpclient"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthAPIKey = "apikey"
+
+	defaultAPIKeyHeader = "X-API-Key"
+)
+
+// Downstream service not declared in config
+var ErrUnknownService = errors.New("unknown downstream service")
+
+// Registry of configured downstream services
+type Registry struct {
+	clients map[string]*httpclient.Client
+}
+
+// Registry constructor, builds one client per declared service
+func NewRegistry(cfg map[string]config.Service, logger logger.Logger) (*Registry, error) {
+	clients := make(map[string]*httpclient.Client, len(cfg))
+	for name, svc := range cfg {
+		if svc.BaseURL == "" {
+			return nil, errors.Errorf("services: %s has no base url", name)
+		}
+		authorize, err := authorizer(svc.Auth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "services: %s", name)
+		}
+		clients[name] = httpclient.New(name, svc.BaseURL, httpclient.Options{
+			Timeout:   time.Duration(svc.TimeoutSeconds) * time.Second,
+			Retries:   svc.Retries,
+			Headers:   svc.Headers,
+			Authorize: authorize,
+		}, logger)
+	}
+	return &Registry{clients: clients}, nil
+}
+
+// Get client of a declared service
+func (r *Registry) Client(name string) (*httpclient.Client, error) {
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownService, name)
+	}
+	return client, nil
+}
+
+func authorizer(auth config.ServiceAuth) (func(req *http.Request), error) {
+	switch auth.Type {
+	case AuthNone, "":
+		return nil, nil
+	case AuthBearer:
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+auth.Token)
+		}, nil
+	case AuthBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Basic "+credentials)
+		}, nil
+	case AuthAPIKey:
+		header := auth.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		return func(req *http.Request) {
+			req.Header.Set(header, auth.Token)
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown auth type %q", auth.Type)
+	}
+}
@@ -0,0 +1,34 @@
+package slo
+
+import (
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+const defaultWindowHours = 24
+
+// Tracker from app config, nil when SLOs are disabled. Config targets are percentages
+func NewFromConfig(cfg *config.Config, clk clock.Clock) (*Tracker, error) {
+	if !cfg.SLO.Enabled {
+		return nil, nil
+	}
+
+	objectives := make([]Objective, 0, len(cfg.SLO.Objectives))
+	for name, objectiveCfg := range cfg.SLO.Objectives {
+		objectives = append(objectives, Objective{
+			Name:          name,
+			PathPrefix:    objectiveCfg.PathPrefix,
+			Availability:  objectiveCfg.Availability / 100,
+			Latency:       time.Duration(objectiveCfg.LatencyMs) * time.Millisecond,
+			LatencyTarget: objectiveCfg.LatencyTarget / 100,
+		})
+	}
+
+	hours := cfg.SLO.WindowHours
+	if hours <= 0 {
+		hours = defaultWindowHours
+	}
+	return New(objectives, time.Duration(hours)*time.Hour, clk)
+}
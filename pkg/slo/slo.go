@@ -0,0 +1,255 @@
+package slo
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/metric"
+)
+
+// SLI names used as metric labels
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+const (
+	// Counters are kept per minute over the budget window
+	bucketSize = time.Minute
+
+	defaultRefreshInterval = 15 * time.Second
+)
+
+// Burn rates are reported over these windows when they fit into the budget window,
+// a fast burn on the short windows pages early while the long one filters out blips
+var burnWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// Objective of the routes under PathPrefix, targets are fractions of good requests e.g. 0.999.
+// A response is unavailable on 5xx and slow above Latency, zero Latency disables the latency objective
+type Objective struct {
+	Name          string
+	PathPrefix    string
+	Availability  float64
+	Latency       time.Duration
+	LatencyTarget float64
+}
+
+// Error budget state of one SLI over the budget window.
+// BudgetRemaining is the fraction of allowed bad requests still unspent, negative once the budget is blown.
+// A burn rate of 1 spends the budget exactly over the window, BurnRates is keyed by window
+type SLIStatus struct {
+	Target          float64            `json:"target"`
+	Total           int64              `json:"total"`
+	Bad             int64              `json:"bad"`
+	BudgetRemaining float64            `json:"budget_remaining"`
+	BurnRates       map[string]float64 `json:"burn_rates"`
+}
+
+// Objective state, Latency is empty without a latency objective
+type Status struct {
+	Name         string     `json:"name"`
+	PathPrefix   string     `json:"path_prefix"`
+	Window       string     `json:"window"`
+	Availability SLIStatus  `json:"availability"`
+	Latency      *SLIStatus `json:"latency,omitempty"`
+}
+
+// Tracker of request outcomes per objective on this instance.
+// A nil Tracker records nothing, so the middleware works with SLOs disabled
+type Tracker struct {
+	objectives []*objectiveState
+	window     time.Duration
+	clock      clock.Clock
+}
+
+type counts struct {
+	minute int64
+	total  int64
+	errors int64
+	slow   int64
+}
+
+type objectiveState struct {
+	Objective
+	mu      sync.Mutex
+	buckets []counts
+}
+
+// Tracker constructor, window is the error budget period and at least one bucket long
+func New(objectives []Objective, window time.Duration, clk clock.Clock) (*Tracker, error) {
+	if window < bucketSize {
+		return nil, errors.Errorf("slo: window %s shorter than %s", window, bucketSize)
+	}
+
+	states := make([]*objectiveState, 0, len(objectives))
+	for _, objective := range objectives {
+		if objective.Availability <= 0 || objective.Availability >= 1 {
+			return nil, errors.Errorf("slo: %s availability target must be between 0 and 1", objective.Name)
+		}
+		if objective.Latency > 0 && (objective.LatencyTarget <= 0 || objective.LatencyTarget >= 1) {
+			return nil, errors.Errorf("slo: %s latency target must be between 0 and 1", objective.Name)
+		}
+		states = append(states, &objectiveState{Objective: objective, buckets: make([]counts, window/bucketSize)})
+	}
+	// Longest prefix wins when route groups nest
+	sort.Slice(states, func(i, j int) bool { return len(states[i].PathPrefix) > len(states[j].PathPrefix) })
+
+	return &Tracker{objectives: states, window: window, clock: clk}, nil
+}
+
+// Record request outcome against the objective matching path
+func (t *Tracker) Record(path string, status int, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	state := t.match(path)
+	if state == nil {
+		return
+	}
+
+	minute := t.clock.Now().Unix() / int64(bucketSize/time.Second)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	bucket := &state.buckets[minute%int64(len(state.buckets))]
+	if bucket.minute != minute {
+		*bucket = counts{minute: minute}
+	}
+	bucket.total++
+	if status >= http.StatusInternalServerError {
+		bucket.errors++
+	}
+	if state.Latency > 0 && elapsed > state.Latency {
+		bucket.slow++
+	}
+}
+
+// Error budget state of every objective, ordered by name
+func (t *Tracker) Summary() []Status {
+	if t == nil {
+		return []Status{}
+	}
+
+	minute := t.clock.Now().Unix() / int64(bucketSize/time.Second)
+	statuses := make([]Status, 0, len(t.objectives))
+	for _, state := range t.objectives {
+		statuses = append(statuses, state.status(minute, t.window))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Publish burn rates and remaining budgets as gauges until ctx is done
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, metrics metric.Metrics) {
+	if t == nil || metrics == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, status := range t.Summary() {
+			publish(metrics, status.Name, SLIAvailability, status.Availability)
+			if status.Latency != nil {
+				publish(metrics, status.Name, SLILatency, *status.Latency)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func publish(metrics metric.Metrics, name string, sli string, status SLIStatus) {
+	metrics.SetSLOBudgetRemaining(name, sli, status.BudgetRemaining)
+	for window, rate := range status.BurnRates {
+		metrics.SetSLOBurnRate(name, sli, window, rate)
+	}
+}
+
+func (t *Tracker) match(path string) *objectiveState {
+	for _, state := range t.objectives {
+		if strings.HasPrefix(path, state.PathPrefix) {
+			return state
+		}
+	}
+	return nil
+}
+
+func (s *objectiveState) status(minute int64, window time.Duration) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Name:         s.Name,
+		PathPrefix:   s.PathPrefix,
+		Window:       window.String(),
+		Availability: SLIStatus{Target: s.Availability, BurnRates: make(map[string]float64)},
+	}
+	if s.Latency > 0 {
+		status.Latency = &SLIStatus{Target: s.LatencyTarget, BurnRates: make(map[string]float64)}
+	}
+
+	for _, burn := range burnWindows {
+		if burn.duration > window {
+			continue
+		}
+		sum := s.sum(minute, int64(burn.duration/bucketSize))
+		status.Availability.BurnRates[burn.name] = burnRate(sum.errors, sum.total, s.Availability)
+		if status.Latency != nil {
+			status.Latency.BurnRates[burn.name] = burnRate(sum.slow, sum.total, s.LatencyTarget)
+		}
+	}
+
+	sum := s.sum(minute, int64(len(s.buckets)))
+	status.Availability.Total, status.Availability.Bad = sum.total, sum.errors
+	status.Availability.BudgetRemaining = 1 - burnRate(sum.errors, sum.total, s.Availability)
+	if status.Latency != nil {
+		status.Latency.Total, status.Latency.Bad = sum.total, sum.slow
+		status.Latency.BudgetRemaining = 1 - burnRate(sum.slow, sum.total, s.LatencyTarget)
+	}
+	return status
+}
+
+// Sum of the last n minutes including the current one, buckets left over from earlier laps are skipped
+func (s *objectiveState) sum(minute int64, n int64) counts {
+	var sum counts
+	for m := minute - n + 1; m <= minute; m++ {
+		bucket := s.buckets[m%int64(len(s.buckets))]
+		if bucket.minute != m {
+			continue
+		}
+		sum.total += bucket.total
+		sum.errors += bucket.errors
+		sum.slow += bucket.slow
+	}
+	return sum
+}
+
+// Observed bad ratio relative to the ratio the target allows
+func burnRate(bad int64, total int64, target float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return (float64(bad) / float64(total)) / (1 - target)
+}
@@ -0,0 +1,65 @@
+package slo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/clock"
+)
+
+func TestTracker_BurnRates(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFrozen(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	tracker, err := New([]Objective{
+		{Name: "api", PathPrefix: "/api/v1", Availability: 0.99},
+		{Name: "auth", PathPrefix: "/api/v1/auth", Availability: 0.99, Latency: 100 * time.Millisecond, LatencyTarget: 0.9},
+	}, 6*time.Hour, clk)
+	require.NoError(t, err)
+
+	// 2% errors on auth an hour ago, then a clean hour
+	for i := 0; i < 100; i++ {
+		status := http.StatusOK
+		if i < 2 {
+			status = http.StatusBadGateway
+		}
+		tracker.Record("/api/v1/auth/login", status, 50*time.Millisecond)
+	}
+	clk.Set(clk.Now().Add(time.Hour))
+	for i := 0; i < 100; i++ {
+		tracker.Record("/api/v1/auth/me", http.StatusOK, 200*time.Millisecond)
+	}
+	tracker.Record("/api/v1/files", http.StatusInternalServerError, time.Millisecond)
+	tracker.Record("/health", http.StatusInternalServerError, time.Millisecond)
+
+	summary := tracker.Summary()
+	require.Len(t, summary, 2)
+	api, auth := summary[0], summary[1]
+
+	require.Equal(t, int64(1), api.Availability.Total)
+	require.Nil(t, api.Latency)
+	require.InDelta(t, 100, api.Availability.BurnRates["5m"], 1e-9)
+
+	require.Equal(t, int64(200), auth.Availability.Total)
+	require.Equal(t, int64(2), auth.Availability.Bad)
+	require.InDelta(t, 0, auth.Availability.BurnRates["5m"], 1e-9)
+	require.InDelta(t, 1, auth.Availability.BurnRates["6h"], 1e-9)
+	require.InDelta(t, 0, auth.Availability.BudgetRemaining, 1e-9)
+	require.InDelta(t, 10, auth.Latency.BurnRates["1h"], 1e-9)
+	require.InDelta(t, -4, auth.Latency.BudgetRemaining, 1e-9)
+
+	// Buckets older than the window are dropped
+	clk.Set(clk.Now().Add(6 * time.Hour))
+	require.Zero(t, tracker.Summary()[1].Availability.Total)
+}
+
+func TestTracker_Nil(t *testing.T) {
+	t.Parallel()
+
+	var tracker *Tracker
+	tracker.Record("/api/v1", http.StatusInternalServerError, time.Second)
+	require.Empty(t, tracker.Summary())
+}
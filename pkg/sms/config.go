@@ -0,0 +1,49 @@
+package sms
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/secrets"
+)
+
+// Sender from app config, provider credentials are read from the secrets provider
+func NewFromConfig(ctx context.Context, cfg *config.Config, logger logger.Logger) (Sender, error) {
+	opts := Options{
+		Driver:  cfg.SMS.Driver,
+		Timeout: time.Duration(cfg.SMS.TimeoutSeconds) * time.Second,
+		Twilio: TwilioOptions{
+			AccountSID: cfg.SMS.Twilio.AccountSID,
+			From:       cfg.SMS.Twilio.From,
+		},
+		SNS: SNSOptions{
+			Region:    cfg.SMS.SNS.Region,
+			AccessKey: cfg.SMS.SNS.AccessKey,
+			SenderID:  cfg.SMS.SNS.SenderID,
+		},
+	}
+
+	if opts.Driver == DriverTwilio || opts.Driver == DriverSNS {
+		provider, err := secrets.NewProvider(secrets.Options{
+			Driver: cfg.Secrets.Driver,
+			Prefix: cfg.Secrets.Prefix,
+			Dir:    cfg.Secrets.Dir,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if opts.Driver == DriverTwilio {
+			if opts.Twilio.AuthToken, err = provider.Get(ctx, cfg.SMS.Twilio.AuthTokenSecret); err != nil {
+				return nil, err
+			}
+		} else {
+			if opts.SNS.SecretKey, err = provider.Get(ctx, cfg.SMS.SNS.SecretKeySecret); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return NewSender(opts, logger)
+}
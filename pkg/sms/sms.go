@@ -0,0 +1,84 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+)
+
+const (
+	DriverTwilio = "twilio"
+	DriverSNS    = "sns"
+	DriverLog    = "log"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// SMS sender
+type Sender interface {
+	Send(ctx context.Context, to string, message string) error
+}
+
+// Twilio driver options
+type TwilioOptions struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	BaseURL    string
+}
+
+// AWS SNS driver options
+type SNSOptions struct {
+	Region    string
+	AccessKey string
+	SecretKey string
+	SenderID  string
+	Endpoint  string
+}
+
+// Sender options
+type Options struct {
+	Driver  string
+	Timeout time.Duration
+	Twilio  TwilioOptions
+	SNS     SNSOptions
+}
+
+// Sender constructor, the log driver only writes messages to the app log and is meant for local development
+func NewSender(opts Options, logger logger.Logger) (Sender, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	httpClient := &http.Client{Timeout: opts.Timeout}
+
+	switch opts.Driver {
+	case DriverTwilio:
+		if opts.Twilio.AccountSID == "" || opts.Twilio.AuthToken == "" || opts.Twilio.From == "" {
+			return nil, errors.New("sms: twilio requires AccountSID, AuthToken and From")
+		}
+		return newTwilioSender(opts.Twilio, httpClient), nil
+	case DriverSNS:
+		if opts.SNS.Region == "" || opts.SNS.AccessKey == "" || opts.SNS.SecretKey == "" {
+			return nil, errors.New("sms: sns requires Region, AccessKey and SecretKey")
+		}
+		return newSNSSender(opts.SNS, httpClient), nil
+	case DriverLog, "":
+		return &logSender{logger: logger}, nil
+	default:
+		return nil, errors.Errorf("sms: unknown driver %q", opts.Driver)
+	}
+}
+
+type logSender struct {
+	logger logger.Logger
+}
+
+// Write message to the log instead of sending it
+func (s *logSender) Send(ctx context.Context, to string, message string) error {
+	s.logger.Infof("sms.logSender to: %s, message: %s", to, message)
+	return nil
+}
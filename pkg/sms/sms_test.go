@@ -0,0 +1,54 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwilioSender_Send(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "AC123", user)
+		require.Equal(t, "token", pass)
+		require.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "+15550001111", r.PostForm.Get("To"))
+		require.Equal(t, "code 123456", r.PostForm.Get("Body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(Options{Driver: DriverTwilio, Twilio: TwilioOptions{
+		AccountSID: "AC123", AuthToken: "token", From: "+15559998888", BaseURL: server.URL,
+	}}, nil)
+	require.NoError(t, err)
+	require.NoError(t, sender.Send(context.Background(), "+15550001111", "code 123456"))
+}
+
+func TestSNSSender_Send(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/sns/aws4_request")
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "Publish", r.PostForm.Get("Action"))
+		require.Equal(t, "+15550001111", r.PostForm.Get("PhoneNumber"))
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(Options{Driver: DriverSNS, SNS: SNSOptions{
+		Region: "eu-west-1", AccessKey: "AKID", SecretKey: "secret", Endpoint: server.URL + "/",
+	}}, nil)
+	require.NoError(t, err)
+	require.Error(t, sender.Send(context.Background(), "+15550001111", "code 123456"))
+}
@@ -0,0 +1,118 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	snsService    = "sns"
+	snsAPIVersion = "2010-03-31"
+	amzDateFormat = "20060102T150405Z"
+)
+
+type snsSender struct {
+	opts       SNSOptions
+	httpClient *http.Client
+}
+
+func newSNSSender(opts SNSOptions, httpClient *http.Client) *snsSender {
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://sns.%s.amazonaws.com/", opts.Region)
+	}
+	return &snsSender{opts: opts, httpClient: httpClient}
+}
+
+// Send transactional message with the SNS Publish API
+func (s *snsSender) Send(ctx context.Context, to string, message string) error {
+	form := url.Values{
+		"Action":                         {"Publish"},
+		"Version":                        {snsAPIVersion},
+		"PhoneNumber":                    {to},
+		"Message":                        {message},
+		"MessageAttributes.entry.1.Name": {"AWS.SNS.SMS.SMSType"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {"Transactional"},
+	}
+	if s.opts.SenderID != "" {
+		form.Set("MessageAttributes.entry.2.Name", "AWS.SNS.SMS.SenderID")
+		form.Set("MessageAttributes.entry.2.Value.DataType", "String")
+		form.Set("MessageAttributes.entry.2.Value.StringValue", s.opts.SenderID)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Endpoint, strings.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "snsSender.Send.NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "snsSender.Send.Do")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return errors.Errorf("snsSender.Send: status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Sign request with AWS signature version 4, only host, content type and date headers are signed
+func (s *snsSender) sign(req *http.Request, body string, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	signedHeaders := "content-type;host;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+			"host:" + req.URL.Host + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		sha256Hex([]byte(body)),
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.opts.Region, snsService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, snsService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const twilioBaseURL = "https://api.twilio.com"
+
+type twilioSender struct {
+	opts       TwilioOptions
+	httpClient *http.Client
+}
+
+func newTwilioSender(opts TwilioOptions, httpClient *http.Client) *twilioSender {
+	if opts.BaseURL == "" {
+		opts.BaseURL = twilioBaseURL
+	}
+	return &twilioSender{opts: opts, httpClient: httpClient}
+}
+
+// Send message with the Twilio Messages API
+func (s *twilioSender) Send(ctx context.Context, to string, message string) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(s.opts.BaseURL, "/"), url.PathEscape(s.opts.AccountSID))
+	form := url.Values{"To": {to}, "From": {s.opts.From}, "Body": {message}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "twilioSender.Send.NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.opts.AccountSID, s.opts.AuthToken)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "twilioSender.Send.Do")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return errors.Errorf("twilioSender.Send: status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
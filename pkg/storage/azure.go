@@ -0,0 +1,383 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+const (
+	azureVersion = "2021-08-06"
+	// Blocks staged while putting an object of unknown size
+	azureBlockSize = 8 << 20
+	// Interval of checking on a pending copy
+	azureCopyPoll = 200 * time.Millisecond
+)
+
+// Blob storage on Azure, authorized with the shared account key. Multipart uploads stage blocks named after
+// the upload id and part number on the blob, completing commits them. Uncommitted blocks are not listed
+// anywhere else, Azure discards them a week after they were staged
+type azureStore struct {
+	client   *http.Client
+	account  string
+	key      []byte
+	endpoint url.URL
+}
+
+// Azure Blob Storage store constructor
+func NewAzure(cfg config.AzureStorage, client *http.Client) (BlobStore, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.AccountKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage.NewAzure.AccountKey")
+	}
+	raw := cfg.Endpoint
+	if raw == "" {
+		raw = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.Account)
+	}
+	endpoint, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "storage.NewAzure.Endpoint")
+	}
+	return &azureStore{client: client, account: cfg.Account, key: key, endpoint: *endpoint}, nil
+}
+
+// Write object in one request, or staged in blocks when its size is unknown
+func (s *azureStore) Put(ctx context.Context, bucket string, key string, r io.Reader, size int64, contentType string) (*ObjectInfo, error) {
+	if size < 0 {
+		return s.putBlocks(ctx, bucket, key, r, contentType)
+	}
+
+	header := http.Header{}
+	header.Set("x-ms-blob-type", "BlockBlob")
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	res, err := s.do(ctx, http.MethodPut, s.blobURL(bucket, key, nil), header, r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "azureStore.Put")
+	}
+	res.Body.Close()
+	return &ObjectInfo{Bucket: bucket, Key: key, Size: size, ETag: res.Header.Get("ETag")}, nil
+}
+
+// Open object for reading
+func (s *azureStore) Get(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	res, err := s.do(ctx, http.MethodGet, s.blobURL(bucket, key, nil), nil, nil, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "azureStore.Get")
+	}
+	return res.Body, nil
+}
+
+// Copy object to another container, waiting for the copy to finish, and remove the source
+func (s *azureStore) Move(ctx context.Context, srcBucket string, dstBucket string, key string) error {
+	dst := s.blobURL(dstBucket, key, nil)
+	header := http.Header{}
+	header.Set("x-ms-copy-source", s.blobURL(srcBucket, key, nil).String())
+	res, err := s.do(ctx, http.MethodPut, dst, header, nil, 0)
+	if err != nil {
+		return errors.Wrap(err, "azureStore.Move.Copy")
+	}
+	res.Body.Close()
+
+	status := res.Header.Get("x-ms-copy-status")
+	for status == "pending" {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "azureStore.Move")
+		case <-time.After(azureCopyPoll):
+		}
+		res, err := s.do(ctx, http.MethodHead, dst, nil, nil, 0)
+		if err != nil {
+			return errors.Wrap(err, "azureStore.Move.Properties")
+		}
+		res.Body.Close()
+		status = res.Header.Get("x-ms-copy-status")
+	}
+	if status != "success" {
+		return errors.Errorf("azureStore.Move: copy %s", status)
+	}
+	return s.Remove(ctx, srcBucket, key)
+}
+
+// Remove object
+func (s *azureStore) Remove(ctx context.Context, bucket string, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, s.blobURL(bucket, key, nil), nil, nil, 0)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return errors.Wrap(err, "azureStore.Remove")
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Start multipart upload, nothing exists on Azure until the first block is staged
+func (s *azureStore) NewMultipartUpload(ctx context.Context, bucket string, key string, contentType string) (string, error) {
+	return uuid.New().String(), nil
+}
+
+// Presign PUT of a single block with a service SAS allowing writes to the blob
+func (s *azureStore) PresignPart(ctx context.Context, bucket string, key string, uploadID string, number int, expires time.Duration) (string, error) {
+	expiry := time.Now().Add(expires).UTC().Format("2006-01-02T15:04:05Z")
+	resource := "/blob/" + s.account + "/" + bucket + "/" + key
+	// Permissions, start, expiry, resource, identifier, IP, protocol, version, resource type, snapshot time,
+	// encryption scope and the five response header overrides
+	toSign := strings.Join([]string{"w", "", expiry, resource, "", "", "", azureVersion, "b", "", "", "", "", "", "", ""}, "\n")
+
+	query := url.Values{}
+	query.Set("comp", "block")
+	query.Set("blockid", blockID(uploadID, number))
+	query.Set("sv", azureVersion)
+	query.Set("sr", "b")
+	query.Set("sp", "w")
+	query.Set("se", expiry)
+	query.Set("sig", s.hmac(toSign))
+	return s.blobURL(bucket, key, query).String(), nil
+}
+
+// List the blocks staged for the upload
+func (s *azureStore) ListParts(ctx context.Context, bucket string, key string, uploadID string) ([]Part, error) {
+	query := url.Values{}
+	query.Set("comp", "blocklist")
+	query.Set("blocklisttype", "uncommitted")
+	res, err := s.do(ctx, http.MethodGet, s.blobURL(bucket, key, query), nil, nil, 0)
+	if err != nil {
+		// Blobs without any block do not exist yet
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "azureStore.ListParts")
+	}
+	defer res.Body.Close()
+
+	list := &azureBlockList{}
+	if err := xml.NewDecoder(res.Body).Decode(list); err != nil {
+		return nil, errors.Wrap(err, "azureStore.ListParts.Decode")
+	}
+	prefix := uploadID + "-"
+	parts := make([]Part, 0, len(list.Uncommitted))
+	for _, block := range list.Uncommitted {
+		name, err := base64.StdEncoding.DecodeString(block.Name)
+		if err != nil || !strings.HasPrefix(string(name), prefix) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimPrefix(string(name), prefix))
+		if err != nil {
+			continue
+		}
+		parts = append(parts, Part{Number: number, ETag: block.Name, Size: block.Size})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+// Commit the blocks of parts, the content type is not known at this point and left to the default
+func (s *azureStore) CompleteMultipartUpload(ctx context.Context, bucket string, key string, uploadID string, parts []Part) error {
+	if _, err := s.commitBlocks(ctx, bucket, key, uploadID, parts, ""); err != nil {
+		return errors.Wrap(err, "azureStore.CompleteMultipartUpload")
+	}
+	return nil
+}
+
+// Abort multipart upload, the staged blocks are left for Azure to discard
+func (s *azureStore) AbortMultipartUpload(ctx context.Context, bucket string, key string, uploadID string) error {
+	return nil
+}
+
+// Stage r in blocks and commit them
+func (s *azureStore) putBlocks(ctx context.Context, bucket string, key string, r io.Reader, contentType string) (*ObjectInfo, error) {
+	uploadID := uuid.New().String()
+	buf := make([]byte, azureBlockSize)
+	var (
+		parts []Part
+		size  int64
+	)
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			query := url.Values{}
+			query.Set("comp", "block")
+			query.Set("blockid", blockID(uploadID, number))
+			res, err := s.do(ctx, http.MethodPut, s.blobURL(bucket, key, query), nil, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				return nil, errors.Wrap(err, "azureStore.putBlocks")
+			}
+			res.Body.Close()
+			parts = append(parts, Part{Number: number})
+			size += int64(n)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, "azureStore.putBlocks.Read")
+		}
+	}
+
+	etag, err := s.commitBlocks(ctx, bucket, key, uploadID, parts, contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "azureStore.putBlocks")
+	}
+	return &ObjectInfo{Bucket: bucket, Key: key, Size: size, ETag: etag}, nil
+}
+
+// Commit the blocks of parts as the content of the blob, returns its ETag
+func (s *azureStore) commitBlocks(ctx context.Context, bucket string, key string, uploadID string, parts []Part, contentType string) (string, error) {
+	commit := azureCommit{Latest: make([]string, 0, len(parts))}
+	for _, part := range parts {
+		commit.Latest = append(commit.Latest, blockID(uploadID, part.Number))
+	}
+	body, err := xml.Marshal(commit)
+	if err != nil {
+		return "", errors.Wrap(err, "azureStore.commitBlocks.Marshal")
+	}
+	body = append([]byte(xml.Header), body...)
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("x-ms-blob-content-type", contentType)
+	}
+	query := url.Values{}
+	query.Set("comp", "blocklist")
+	res, err := s.do(ctx, http.MethodPut, s.blobURL(bucket, key, query), header, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	return res.Header.Get("ETag"), nil
+}
+
+// URL of the blob in container
+func (s *azureStore) blobURL(container string, blob string, query url.Values) *url.URL {
+	u := s.endpoint
+	u.Path = s.endpoint.Path + "/" + container + "/" + blob
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// Send a request signed with the shared key, ErrNotFound for 404 and an error for any other failure status
+func (s *azureStore) do(ctx context.Context, method string, u *url.URL, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	if body == nil || size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureVersion)
+	req.Header.Set("Authorization", "SharedKey "+s.account+":"+s.sign(req))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	return nil, errors.Errorf("azure: %s %s: %s %s", method, u.Path, res.Status, res.Header.Get("x-ms-error-code"))
+}
+
+// Shared key signature of req
+func (s *azureStore) sign(req *http.Request) string {
+	length := ""
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var canonical strings.Builder
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	canonical.WriteString("/" + s.account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := query[name]
+		sort.Strings(values)
+		fmt.Fprintf(&canonical, "\n%s:%s", strings.ToLower(name), strings.Join(values, ","))
+	}
+
+	header := req.Header
+	return s.hmac(strings.Join([]string{
+		req.Method,
+		header.Get("Content-Encoding"),
+		header.Get("Content-Language"),
+		length,
+		header.Get("Content-MD5"),
+		header.Get("Content-Type"),
+		// Date, x-ms-date is sent instead
+		"",
+		header.Get("If-Modified-Since"),
+		header.Get("If-Match"),
+		header.Get("If-None-Match"),
+		header.Get("If-Unmodified-Since"),
+		header.Get("Range"),
+		canonical.String(),
+	}, "\n"))
+}
+
+func (s *azureStore) hmac(toSign string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(toSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Block id of a part, ids of a blob must all have the same length
+func blockID(uploadID string, number int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%05d", uploadID, number)))
+}
+
+type azureBlockList struct {
+	Uncommitted []azureBlock `xml:"UncommittedBlocks>Block"`
+}
+
+type azureBlock struct {
+	Name string `xml:"Name"`
+	Size int64  `xml:"Size"`
+}
+
+type azureCommit struct {
+	XMLName xml.Name `xml:"BlockList"`
+	Latest  []string `xml:"Latest"`
+}
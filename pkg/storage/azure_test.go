@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+const testAzureAccount = "devstoreaccount1"
+
+// Blob Storage fake checking every request is signed the way the store signs it
+type fakeAzure struct {
+	t     *testing.T
+	store *azureStore
+
+	mu      sync.Mutex
+	blobs   map[string][]byte
+	blocks  map[string]map[string][]byte
+	pending map[string]bool
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+	if query.Get("sig") != "" {
+		expiry, err := time.Parse("2006-01-02T15:04:05Z", query.Get("se"))
+		if err != nil || time.Now().After(expiry) || query.Get("sp") != "w" || query.Get("comp") != "block" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	} else if r.Header.Get("Authorization") != "SharedKey "+testAzureAccount+":"+f.store.sign(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	path := r.URL.Path
+	body, err := io.ReadAll(r.Body)
+	require.NoError(f.t, err)
+	switch {
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		if f.blocks[path] == nil {
+			f.blocks[path] = map[string][]byte{}
+		}
+		f.blocks[path][query.Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		commit := &azureCommit{}
+		require.NoError(f.t, xml.Unmarshal(body, commit))
+		var content []byte
+		for _, id := range commit.Latest {
+			content = append(content, f.blocks[path][id]...)
+		}
+		f.blobs[path] = content
+		delete(f.blocks, path)
+		w.Header().Set("ETag", `"0x1"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-copy-source") != "":
+		source, err := url.Parse(r.Header.Get("x-ms-copy-source"))
+		require.NoError(f.t, err)
+		content, ok := f.blobs[source.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.blobs[path] = content
+		f.pending[path] = true
+		w.Header().Set("x-ms-copy-status", "pending")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut:
+		require.Equal(f.t, "BlockBlob", r.Header.Get("x-ms-blob-type"))
+		f.blobs[path] = body
+		w.Header().Set("ETag", `"0x2"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && query.Get("comp") == "blocklist":
+		blocks, ok := f.blocks[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "<BlockList><UncommittedBlocks>")
+		for id, content := range blocks {
+			fmt.Fprintf(w, "<Block><Name>%s</Name><Size>%d</Size></Block>", id, len(content))
+		}
+		fmt.Fprint(w, "</UncommittedBlocks></BlockList>")
+	case r.Method == http.MethodGet:
+		content, ok := f.blobs[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	case r.Method == http.MethodHead:
+		// Copies finish by the first look at them
+		delete(f.pending, path)
+		w.Header().Set("x-ms-copy-status", "success")
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.blobs, path)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAzureStore(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAzure{t: t, blobs: map[string][]byte{}, blocks: map[string]map[string][]byte{}, pending: map[string]bool{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewAzure(config.AzureStorage{
+		Account:    testAzureAccount,
+		AccountKey: base64.StdEncoding.EncodeToString([]byte("account key")),
+		// Path style like Azurite
+		Endpoint: server.URL + "/" + testAzureAccount + "/",
+	}, server.Client())
+	require.NoError(t, err)
+	fake.store = store.(*azureStore)
+
+	testBlobStore(t, store)
+	require.Empty(t, fake.pending)
+
+	// Expired part URLs are refused
+	presigned, err := store.PresignPart(context.Background(), "files", "late.bin", "upload", 1, -time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(presigned, server.URL+"/"+testAzureAccount+"/files/late.bin?"))
+	req, err := http.NewRequest(http.MethodPut, presigned, strings.NewReader("late"))
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	_, err = NewAzure(config.AzureStorage{Account: testAzureAccount, AccountKey: "not base64!"}, server.Client())
+	require.Error(t, err)
+}
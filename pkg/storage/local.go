@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+)
+
+// Blob storage on the local filesystem, parts are uploaded to the handler of the store
+type localStore struct {
+	store *blobstore.Store
+}
+
+// Local filesystem store constructor
+func NewLocal(store *blobstore.Store) BlobStore {
+	return &localStore{store: store}
+}
+
+// Handler receiving presigned part uploads
+func (s *localStore) Handler() http.Handler {
+	return s.store.Handler()
+}
+
+// Write object, the content type is not kept
+func (s *localStore) Put(ctx context.Context, bucket string, key string, r io.Reader, size int64, contentType string) (*ObjectInfo, error) {
+	written, err := s.store.Put(bucket, key, r)
+	if err != nil {
+		return nil, errors.Wrap(err, "localStore.Put")
+	}
+	return &ObjectInfo{Bucket: bucket, Key: key, Size: written}, nil
+}
+
+// Open object for reading
+func (s *localStore) Get(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	object, err := s.store.Open(bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(localErr(err), "localStore.Get")
+	}
+	return object, nil
+}
+
+// Move object to another bucket
+func (s *localStore) Move(ctx context.Context, srcBucket string, dstBucket string, key string) error {
+	if err := s.store.Move(srcBucket, dstBucket, key); err != nil {
+		return errors.Wrap(localErr(err), "localStore.Move")
+	}
+	return nil
+}
+
+// Remove object
+func (s *localStore) Remove(ctx context.Context, bucket string, key string) error {
+	if err := s.store.Remove(bucket, key); err != nil {
+		return errors.Wrap(err, "localStore.Remove")
+	}
+	return nil
+}
+
+// Start multipart upload
+func (s *localStore) NewMultipartUpload(ctx context.Context, bucket string, key string, contentType string) (string, error) {
+	uploadID, err := s.store.NewMultipartUpload(bucket, key)
+	if err != nil {
+		return "", errors.Wrap(err, "localStore.NewMultipartUpload")
+	}
+	return uploadID, nil
+}
+
+// Presign PUT of a single part, served by the handler of the store
+func (s *localStore) PresignPart(ctx context.Context, bucket string, key string, uploadID string, number int, expires time.Duration) (string, error) {
+	return s.store.PresignPart(uploadID, number, expires), nil
+}
+
+// List all uploaded parts ordered by number
+func (s *localStore) ListParts(ctx context.Context, bucket string, key string, uploadID string) ([]Part, error) {
+	stored, err := s.store.ListParts(uploadID)
+	if err != nil {
+		return nil, errors.Wrap(err, "localStore.ListParts")
+	}
+
+	parts := make([]Part, 0, len(stored))
+	for _, part := range stored {
+		parts = append(parts, Part{Number: part.Number, ETag: part.ETag, Size: part.Size})
+	}
+	return parts, nil
+}
+
+// Assemble uploaded parts into the object
+func (s *localStore) CompleteMultipartUpload(ctx context.Context, bucket string, key string, uploadID string, parts []Part) error {
+	stored := make([]blobstore.Part, 0, len(parts))
+	for _, part := range parts {
+		stored = append(stored, blobstore.Part{Number: part.Number, ETag: part.ETag})
+	}
+	if err := s.store.CompleteMultipartUpload(uploadID, stored); err != nil {
+		return errors.Wrap(err, "localStore.CompleteMultipartUpload")
+	}
+	return nil
+}
+
+// Abort multipart upload
+func (s *localStore) AbortMultipartUpload(ctx context.Context, bucket string, key string, uploadID string) error {
+	if err := s.store.AbortMultipartUpload(uploadID); err != nil && !errors.Is(err, blobstore.ErrNoSuchUpload) {
+		return errors.Wrap(err, "localStore.AbortMultipartUpload")
+	}
+	return nil
+}
+
+func localErr(err error) error {
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
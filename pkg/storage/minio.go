@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+const (
+	// Largest page S3 returns when listing parts
+	maxPartsPerPage = 1000
+
+	defaultGCSEndpoint = "storage.googleapis.com"
+)
+
+// Blob storage speaking S3 through the minio client
+type minioStore struct {
+	client *minio.Client
+	core   *minio.Core
+}
+
+// MinIO and S3 store constructor
+func NewMinio(client *minio.Client) BlobStore {
+	return &minioStore{client: client, core: &minio.Core{Client: client}}
+}
+
+// Google Cloud Storage store constructor, talks to the S3 compatible XML API with interoperability HMAC keys
+func NewGCS(cfg config.GCSStorage) (BlobStore, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCSEndpoint
+	}
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "storage.NewGCS")
+	}
+	return NewMinio(client), nil
+}
+
+// Write object
+func (s *minioStore) Put(ctx context.Context, bucket string, key string, r io.Reader, size int64, contentType string) (*ObjectInfo, error) {
+	info, err := s.client.PutObject(ctx, bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: map[string]string{"x-amz-acl": "private"},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "minioStore.Put")
+	}
+	return &ObjectInfo{Bucket: info.Bucket, Key: info.Key, Size: info.Size, ETag: info.ETag}, nil
+}
+
+// Open object for reading
+func (s *minioStore) Get(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "minioStore.Get")
+	}
+	return object, nil
+}
+
+// Copy object to another bucket and remove the source
+func (s *minioStore) Move(ctx context.Context, srcBucket string, dstBucket string, key string) error {
+	if _, err := s.client.CopyObject(
+		ctx,
+		minio.CopyDestOptions{Bucket: dstBucket, Object: key},
+		minio.CopySrcOptions{Bucket: srcBucket, Object: key},
+	); err != nil {
+		return errors.Wrap(err, "minioStore.Move.CopyObject")
+	}
+	if err := s.client.RemoveObject(ctx, srcBucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return errors.Wrap(err, "minioStore.Move.RemoveObject")
+	}
+	return nil
+}
+
+// Remove object
+func (s *minioStore) Remove(ctx context.Context, bucket string, key string) error {
+	if err := s.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return errors.Wrap(err, "minioStore.Remove")
+	}
+	return nil
+}
+
+// Start multipart upload
+func (s *minioStore) NewMultipartUpload(ctx context.Context, bucket string, key string, contentType string) (string, error) {
+	uploadID, err := s.core.NewMultipartUpload(ctx, bucket, key, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: map[string]string{"x-amz-acl": "private"},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "minioStore.NewMultipartUpload")
+	}
+	return uploadID, nil
+}
+
+// Presign PUT of a single part
+func (s *minioStore) PresignPart(ctx context.Context, bucket string, key string, uploadID string, number int, expires time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("partNumber", strconv.Itoa(number))
+	params.Set("uploadId", uploadID)
+	presigned, err := s.client.Presign(ctx, "PUT", bucket, key, expires, params)
+	if err != nil {
+		return "", errors.Wrap(err, "minioStore.PresignPart")
+	}
+	return presigned.String(), nil
+}
+
+// List all uploaded parts, S3 answers them ordered by number
+func (s *minioStore) ListParts(ctx context.Context, bucket string, key string, uploadID string) ([]Part, error) {
+	var parts []Part
+	marker := 0
+	for {
+		result, err := s.core.ListObjectParts(ctx, bucket, key, uploadID, marker, maxPartsPerPage)
+		if err != nil {
+			return nil, errors.Wrap(err, "minioStore.ListParts")
+		}
+		for _, part := range result.ObjectParts {
+			parts = append(parts, Part{Number: part.PartNumber, ETag: part.ETag, Size: part.Size})
+		}
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+// Assemble uploaded parts into the object
+func (s *minioStore) CompleteMultipartUpload(ctx context.Context, bucket string, key string, uploadID string, parts []Part) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+	if _, err := s.core.CompleteMultipartUpload(ctx, bucket, key, uploadID, completeParts, minio.PutObjectOptions{}); err != nil {
+		return errors.Wrap(err, "minioStore.CompleteMultipartUpload")
+	}
+	return nil
+}
+
+// Abort multipart upload
+func (s *minioStore) AbortMultipartUpload(ctx context.Context, bucket string, key string, uploadID string) error {
+	if err := s.core.AbortMultipartUpload(ctx, bucket, key, uploadID); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+			return nil
+		}
+		return errors.Wrap(err, "minioStore.AbortMultipartUpload")
+	}
+	return nil
+}
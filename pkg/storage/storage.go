@@ -0,0 +1,101 @@
+// Package storage abstracts blob storage behind BlobStore so subsystems keeping objects are not tied to one
+// provider. Drivers exist for MinIO and S3, Google Cloud Storage, Azure Blob Storage and the local filesystem,
+// NewFromConfig picks the configured one.
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/db/aws"
+)
+
+// Object does not exist
+var ErrNotFound = errors.New("storage: not found")
+
+// Stored object
+type ObjectInfo struct {
+	Bucket string
+	Key    string
+	Size   int64
+	ETag   string
+}
+
+// Uploaded part of a multipart upload, ETag is what completing the upload refers to it by
+type Part struct {
+	Number int
+	ETag   string
+	Size   int64
+}
+
+// Blob storage. Multipart uploads are assembled from parts uploaded by clients straight to presigned URLs,
+// every part but the last must be at least 5 MiB and an upload has at most 10000 parts
+type BlobStore interface {
+	// Write object, replacing an existing one. A negative size reads r to its end
+	Put(ctx context.Context, bucket string, key string, r io.Reader, size int64, contentType string) (*ObjectInfo, error)
+	Get(ctx context.Context, bucket string, key string) (io.ReadCloser, error)
+	// Move object to another bucket under the same key
+	Move(ctx context.Context, srcBucket string, dstBucket string, key string) error
+	// Remove object, a missing object is not an error
+	Remove(ctx context.Context, bucket string, key string) error
+	// Start multipart upload, returns the upload id
+	NewMultipartUpload(ctx context.Context, bucket string, key string, contentType string) (string, error)
+	// URL accepting a PUT of one part until expires
+	PresignPart(ctx context.Context, bucket string, key string, uploadID string, number int, expires time.Duration) (string, error)
+	// Uploaded parts ordered by number
+	ListParts(ctx context.Context, bucket string, key string, uploadID string) ([]Part, error)
+	// Assemble parts into the object
+	CompleteMultipartUpload(ctx context.Context, bucket string, key string, uploadID string, parts []Part) error
+	// Abort multipart upload and drop its parts, an already gone upload is not an error
+	AbortMultipartUpload(ctx context.Context, bucket string, key string, uploadID string) error
+}
+
+// Store receiving presigned part uploads itself, its handler has to be served where the URLs point to
+type Handler interface {
+	Handler() http.Handler
+}
+
+// Store of the configured driver, local ones when dev mode is enabled
+func NewFromConfig(cfg *config.Config) (BlobStore, error) {
+	if cfg.Dev.Enabled {
+		return newLocalFromConfig(cfg.Dev.DataDir, cfg.Dev.BlobURL)
+	}
+
+	switch cfg.Storage.Selected() {
+	case config.StorageMinio:
+		client, err := aws.NewAWSClient(cfg.AWS.Endpoint, cfg.AWS.MinioAccessKey, cfg.AWS.MinioSecretKey, cfg.AWS.UseSSL)
+		if err != nil {
+			return nil, errors.Wrap(err, "storage.NewFromConfig.NewAWSClient")
+		}
+		return NewMinio(client), nil
+	case config.StorageGCS:
+		return NewGCS(cfg.Storage.GCS)
+	case config.StorageAzure:
+		return NewAzure(cfg.Storage.Azure, http.DefaultClient)
+	case config.StorageLocal:
+		return newLocalFromConfig(cfg.Storage.Local.Root, cfg.Storage.Local.URL)
+	}
+	return nil, errors.Errorf("storage: unknown driver %q", cfg.Storage.Driver)
+}
+
+// Address the handler of a store returned by NewFromConfig is served on
+func HandlerPort(cfg *config.Config) string {
+	if cfg.Dev.Enabled {
+		return cfg.Dev.BlobPort
+	}
+	return cfg.Storage.Local.Port
+}
+
+func newLocalFromConfig(root string, baseURL string) (BlobStore, error) {
+	store, err := blobstore.New(root, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocal(store), nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/blobstore"
+)
+
+// Behavior every driver shares, parts are uploaded to the presigned URLs like clients do
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+
+	info, err := store.Put(ctx, "files", "a/b.txt", strings.NewReader("hello"), 5, "text/plain")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), info.Size)
+	require.Equal(t, "hello", read(t, store, "files", "a/b.txt"))
+
+	// Size unknown
+	info, err = store.Put(ctx, "files", "a/c.txt", io.MultiReader(strings.NewReader("hel"), strings.NewReader("lo")), -1, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), info.Size)
+	require.Equal(t, "hello", read(t, store, "files", "a/c.txt"))
+
+	require.NoError(t, store.Move(ctx, "files", "clean", "a/b.txt"))
+	require.Equal(t, "hello", read(t, store, "clean", "a/b.txt"))
+	_, err = store.Get(ctx, "files", "a/b.txt")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Remove(ctx, "clean", "a/b.txt"))
+	require.NoError(t, store.Remove(ctx, "clean", "a/b.txt"))
+	_, err = store.Get(ctx, "clean", "a/b.txt")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	uploadID, err := store.NewMultipartUpload(ctx, "files", "big.bin", "application/octet-stream")
+	require.NoError(t, err)
+	parts, err := store.ListParts(ctx, "files", "big.bin", uploadID)
+	require.NoError(t, err)
+	require.Empty(t, parts)
+	for _, number := range []int{2, 1} {
+		presigned, err := store.PresignPart(ctx, "files", "big.bin", uploadID, number, time.Minute)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPut, presigned, strings.NewReader(map[int]string{1: "hello ", 2: "world"}[number]))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Less(t, res.StatusCode, 300)
+	}
+	parts, err = store.ListParts(ctx, "files", "big.bin", uploadID)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	require.Equal(t, []int{1, 2}, []int{parts[0].Number, parts[1].Number})
+	require.Equal(t, []int64{6, 5}, []int64{parts[0].Size, parts[1].Size})
+
+	require.NoError(t, store.CompleteMultipartUpload(ctx, "files", "big.bin", uploadID, parts))
+	require.Equal(t, "hello world", read(t, store, "files", "big.bin"))
+	require.NoError(t, store.AbortMultipartUpload(ctx, "files", "big.bin", uploadID))
+}
+
+func read(t *testing.T, store BlobStore, bucket string, key string) string {
+	object, err := store.Get(context.Background(), bucket, key)
+	require.NoError(t, err)
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLocalStore(t *testing.T) {
+	t.Parallel()
+
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	local, err := blobstore.New(t.TempDir(), server.URL)
+	require.NoError(t, err)
+	store := NewLocal(local)
+	handler = store.(Handler).Handler()
+
+	testBlobStore(t, store)
+}
@@ -0,0 +1,53 @@
+package tlscert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+const (
+	CacheRedis = "redis"
+	CacheMinio = "minio"
+
+	cachePrefix = "acme-cert:"
+)
+
+// Build autocert manager for configured domains
+func NewManager(cfg *config.Config, redisClient *redis.Client, minioClient *minio.Client) (*autocert.Manager, error) {
+	if len(cfg.ACME.Domains) == 0 {
+		return nil, fmt.Errorf("acme: no domains configured")
+	}
+
+	var cache autocert.Cache
+	switch cfg.ACME.Cache {
+	case CacheRedis, "":
+		cache = NewRedisCache(redisClient, cachePrefix)
+	case CacheMinio:
+		if minioClient == nil {
+			return nil, fmt.Errorf("acme: minio cache selected but minio client is not initialized")
+		}
+		cache = NewMinioCache(minioClient, cfg.ACME.Bucket, cachePrefix)
+	default:
+		return nil, fmt.Errorf("acme: unknown cache %q", cfg.ACME.Cache)
+	}
+
+	manager := &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		HostPolicy:  autocert.HostWhitelist(cfg.ACME.Domains...),
+		Cache:       cache,
+		Email:       cfg.ACME.Email,
+		RenewBefore: time.Duration(cfg.ACME.RenewBeforeHours) * time.Hour,
+	}
+	if cfg.ACME.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.ACME.DirectoryURL}
+	}
+
+	return manager, nil
+}
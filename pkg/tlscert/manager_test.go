@@ -0,0 +1,58 @@
+package tlscert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/aditwar-man/go-microservice-boilerplate/config"
+)
+
+func TestNewManager(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.Start())
+	defer server.Close()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+
+	manager, err := NewManager(&config.Config{ACME: config.ACME{
+		Domains:          []string{"api.example.com"},
+		Email:            "ops@example.com",
+		RenewBeforeHours: 720,
+		DirectoryURL:     "https://acme-staging-v02.api.letsencrypt.org/directory",
+	}}, client, nil)
+	require.NoError(t, err)
+	require.Equal(t, 720*time.Hour, manager.RenewBefore)
+	require.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/directory", manager.Client.DirectoryURL)
+	require.NoError(t, manager.HostPolicy(ctx, "api.example.com"))
+	require.Error(t, manager.HostPolicy(ctx, "other.example.com"))
+
+	// Certificates are kept in redis, so every instance serves the same one
+	_, err = manager.Cache.Get(ctx, "api.example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
+	require.NoError(t, manager.Cache.Put(ctx, "api.example.com", []byte("cert")))
+	data, err := client.Get(ctx, cachePrefix+"api.example.com").Bytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte("cert"), data)
+	data, err = manager.Cache.Get(ctx, "api.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []byte("cert"), data)
+	require.NoError(t, manager.Cache.Delete(ctx, "api.example.com"))
+	_, err = manager.Cache.Get(ctx, "api.example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
+
+	_, err = NewManager(&config.Config{}, client, nil)
+	require.Error(t, err)
+	_, err = NewManager(&config.Config{ACME: config.ACME{Domains: []string{"api.example.com"}, Cache: CacheMinio}}, client, nil)
+	require.Error(t, err)
+	_, err = NewManager(&config.Config{ACME: config.ACME{Domains: []string{"api.example.com"}, Cache: "disk"}}, client, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package tlscert
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const noSuchKey = "NoSuchKey"
+
+// autocert.Cache backed by a minio bucket
+type minioCache struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// Minio certificate cache constructor
+func NewMinioCache(client *minio.Client, bucket string, prefix string) autocert.Cache {
+	return &minioCache{client: client, bucket: bucket, prefix: prefix}
+}
+
+// Get certificate data by name
+func (c *minioCache) Get(ctx context.Context, name string) ([]byte, error) {
+	object, err := c.client.GetObject(ctx, c.bucket, c.prefix+name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "minioCache.Get.client.GetObject")
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == noSuchKey {
+			return nil, autocert.ErrCacheMiss
+		}
+		return nil, errors.Wrap(err, "minioCache.Get.io.ReadAll")
+	}
+	return data, nil
+}
+
+// Put certificate data
+func (c *minioCache) Put(ctx context.Context, name string, data []byte) error {
+	if _, err := c.client.PutObject(ctx, c.bucket, c.prefix+name, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	}); err != nil {
+		return errors.Wrap(err, "minioCache.Put.client.PutObject")
+	}
+	return nil
+}
+
+// Delete certificate data
+func (c *minioCache) Delete(ctx context.Context, name string) error {
+	if err := c.client.RemoveObject(ctx, c.bucket, c.prefix+name, minio.RemoveObjectOptions{}); err != nil {
+		return errors.Wrap(err, "minioCache.Delete.client.RemoveObject")
+	}
+	return nil
+}
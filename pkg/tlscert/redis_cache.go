@@ -0,0 +1,48 @@
+package tlscert
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocert.Cache backed by redis, shared by every instance
+type redisCache struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// Redis certificate cache constructor
+func NewRedisCache(redisClient *redis.Client, prefix string) autocert.Cache {
+	return &redisCache{redisClient: redisClient, prefix: prefix}
+}
+
+// Get certificate data by name
+func (c *redisCache) Get(ctx context.Context, name string) ([]byte, error) {
+	data, err := c.redisClient.Get(ctx, c.prefix+name).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, autocert.ErrCacheMiss
+		}
+		return nil, errors.Wrap(err, "redisCache.Get.redisClient.Get")
+	}
+	return data, nil
+}
+
+// Put certificate data, autocert renews before expiry so no ttl is set
+func (c *redisCache) Put(ctx context.Context, name string, data []byte) error {
+	if err := c.redisClient.Set(ctx, c.prefix+name, data, 0).Err(); err != nil {
+		return errors.Wrap(err, "redisCache.Put.redisClient.Set")
+	}
+	return nil
+}
+
+// Delete certificate data
+func (c *redisCache) Delete(ctx context.Context, name string) error {
+	if err := c.redisClient.Del(ctx, c.prefix+name).Err(); err != nil {
+		return errors.Wrap(err, "redisCache.Delete.redisClient.Del")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package tracing links other signals, metrics exemplars in particular, to the traces of a request
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// Exemplar label holding the trace id, the name Grafana looks up to link a sample to its trace
+const TraceIDLabel = "trace_id"
+
+// Response headers naming the trace of a request, so a bug report can quote it
+const (
+	HeaderTraceID   = "Trace-Id"
+	HeaderTraceLink = "Trace-Link"
+)
+
+// Placeholder of the trace id in link templates
+const linkPlaceholder = "{trace_id}"
+
+// Trace id of the span carried by ctx. Only sampled jaeger spans have one, other traces are never stored
+// and linking to them would lead nowhere.
+func TraceID(ctx context.Context) (string, bool) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return "", false
+	}
+	spanCtx, ok := span.Context().(jaeger.SpanContext)
+	if !ok || !spanCtx.IsSampled() {
+		return "", false
+	}
+	return spanCtx.TraceID().String(), true
+}
+
+// Link to a trace in the tracing UI, template holds {trace_id}. Empty without a template
+func Link(template string, traceID string) string {
+	if template == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, linkPlaceholder, traceID)
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestTraceID(t *testing.T) {
+	t.Parallel()
+
+	_, ok := TraceID(context.Background())
+	require.False(t, ok)
+
+	// Spans of the default noop tracer have no trace id
+	noop, _ := opentracing.StartSpanFromContextWithTracer(context.Background(), opentracing.NoopTracer{}, "noop")
+	_, ok = TraceID(opentracing.ContextWithSpan(context.Background(), noop))
+	require.False(t, ok)
+
+	for _, sampled := range []bool{true, false} {
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(sampled), jaeger.NewNullReporter())
+		span := tracer.StartSpan("request")
+		traceID, ok := TraceID(opentracing.ContextWithSpan(context.Background(), span))
+		require.Equal(t, sampled, ok)
+		if sampled {
+			require.Equal(t, span.Context().(jaeger.SpanContext).TraceID().String(), traceID)
+		}
+		span.Finish()
+		require.NoError(t, closer.Close())
+	}
+}
+
+func TestLink(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, Link("", "4bf92f3577b34da6"))
+	require.Equal(t, "http://localhost:16686/trace/4bf92f3577b34da6", Link("http://localhost:16686/trace/{trace_id}", "4bf92f3577b34da6"))
+}
@@ -0,0 +1,11 @@
+package utils
+
+// Pointer to a copy of the value, nil stays nil. In-memory repositories copy optional fields so records
+// they hand out never share memory with the stored ones.
+func ClonePtr[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	cloned := *value
+	return &cloned
+}
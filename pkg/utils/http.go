@@ -2,7 +2,6 @@ package utils
 
 import (
 	"context"
-	"encoding/json"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
@@ -13,8 +12,10 @@ import (
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/binder"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/deprecation"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/httpErrors"
-	"github.com/aditwar-man/go-microservice-boilerplate/pkg/logger"
+	"github.com/aditwar-man/go-microservice-boilerplate/pkg/requestctx"
 	"github.com/aditwar-man/go-microservice-boilerplate/pkg/sanitize"
 )
 
@@ -76,6 +77,19 @@ func CreateSessionCookie(cfg *config.Config, session string) *http.Cookie {
 	}
 }
 
+// Configure remember-me cookie, always http only since it outlives the session
+func CreateRememberCookie(cfg *config.Config, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     cfg.Remember.CookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   cfg.Remember.ExpireDays * 24 * 60 * 60,
+		Secure:   cfg.Cookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
 // Delete session
 func DeleteSessionCookie(c echo.Context, sessionName string) {
 	c.SetCookie(&http.Cookie{
@@ -86,48 +100,37 @@ func DeleteSessionCookie(c echo.Context, sessionName string) {
 	})
 }
 
-// UserCtxKey is a key used for the User object in the context
-type UserCtxKey struct{}
-
-// Get user from context
+// Get user from context, Unauthorized when the request has none
 func GetUserFromCtx(ctx context.Context) (*models.UserWithRole, error) {
-	user, ok := ctx.Value(UserCtxKey{}).(*models.UserWithRole)
-	if !ok {
+	user, ok := requestctx.User.From(ctx)
+	if !ok || user == nil {
 		return nil, httpErrors.Unauthorized
 	}
 
 	return user, nil
 }
 
+// Get guest id from context, set for requests made with a guest session
+func GetGuestIDFromCtx(ctx context.Context) (string, bool) {
+	guestID, ok := requestctx.GuestID.From(ctx)
+	return guestID, ok && guestID != ""
+}
+
 // Get user ip address
 func GetIPAddress(c echo.Context) string {
 	return c.Request().RemoteAddr
 }
 
-// Error response with logging error for echo context
-func ErrResponseWithLog(ctx echo.Context, logger logger.Logger, err error) error {
-	logger.Errorf(
-		"ErrResponseWithLog, RequestID: %s, IPAddress: %s, Error: %s",
-		GetRequestID(ctx),
-		GetIPAddress(ctx),
-		err,
-	)
-	return ctx.JSON(httpErrors.ErrorResponse(err))
-}
-
-// Error response with logging error for echo context
-func LogResponseError(ctx echo.Context, logger logger.Logger, err error) {
-	logger.Errorf(
-		"ErrResponseWithLog, RequestID: %s, IPAddress: %s, Error: %s",
-		GetRequestID(ctx),
-		GetIPAddress(ctx),
-		err,
-	)
-}
+// Binder used when the echo instance has no strict binder installed
+var defaultBinder = binder.New(binder.DefaultMaxBodyBytes, nil)
 
-// Read request body and validate
+// Read request body strictly and validate, unknown fields and mistyped values fail with their json path
 func ReadRequest(ctx echo.Context, request interface{}) error {
-	if err := ctx.Bind(request); err != nil {
+	b, ok := ctx.Echo().Binder.(*binder.Binder)
+	if !ok {
+		b = defaultBinder
+	}
+	if err := b.Bind(request, ctx); err != nil {
 		return err
 	}
 	return validate.StructCtx(ctx.Request().Context(), request)
@@ -160,9 +163,11 @@ func SanitizeRequest(ctx echo.Context, request interface{}) error {
 		return ctx.NoContent(http.StatusBadRequest)
 	}
 
-	if err = json.Unmarshal(sanBody, request); err != nil {
+	warnings, err := binder.DecodeWithWarnings(sanBody, request)
+	if err != nil {
 		return err
 	}
+	deprecation.Add(ctx.Request().Context(), warnings...)
 
 	return validate.StructCtx(ctx.Request().Context(), request)
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"html"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 
 	"github.com/aditwar-man/go-microservice-boilerplate/config"
 	"github.com/aditwar-man/go-microservice-boilerplate/internal/models"
@@ -22,6 +24,17 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
+// Value of the typ claim of scoped tokens, full session middlewares refuse tokens carrying it
+const ScopedTokenType = "scoped"
+
+// Scoped JWT Claims struct, Scope is space separated
+type ScopedClaims struct {
+	ID    string `json:"id"`
+	Type  string `json:"typ"`
+	Scope string `json:"scope"`
+	jwt.StandardClaims
+}
+
 // Generate new JWT Token
 func GenerateJWTToken(user *models.UserWithRole, config *config.Config) (string, error) {
 	// Register the JWT claims, which includes the username and expiry time
@@ -48,6 +61,52 @@ func GenerateJWTToken(user *models.UserWithRole, config *config.Config) (string,
 	return tokenString, nil
 }
 
+// Generate short lived JWT limited to scopes, expiring at expiresAt
+func GenerateScopedJWTToken(userID int, scopes []string, expiresAt time.Time, config *config.Config) (string, error) {
+	now := time.Now()
+	claims := &ScopedClaims{
+		ID:    strconv.Itoa(userID),
+		Type:  ScopedTokenType,
+		Scope: strings.Join(scopes, " "),
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.New().String(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.Server.JwtSecretKey))
+}
+
+// Parse and verify scoped JWT, tokens of any other type are rejected
+func ParseScopedJWTToken(tokenString string, config *config.Config) (*ScopedClaims, error) {
+	claims := &ScopedClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(config.Server.JwtSecretKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.Type != ScopedTokenType {
+		return nil, errors.New("invalid scoped token")
+	}
+	return claims, nil
+}
+
+// Is scope one of the space separated granted scopes
+func (c *ScopedClaims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // Extract JWT From Request
 func ExtractJWTFromRequest(r *http.Request) (map[string]interface{}, error) {
 	// Get the JWT string
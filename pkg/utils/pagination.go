@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -12,11 +13,39 @@ const (
 	defaultSize = 10
 )
 
+// How a listing computes its total count
+type CountStrategy string
+
+const (
+	// COUNT(*) on every request
+	CountExact CountStrategy = "exact"
+	// Planner row estimate, cheap on large tables but drifts between ANALYZE runs
+	CountEstimated CountStrategy = "estimated"
+	// No total at all, only has_more
+	CountNone CountStrategy = "none"
+)
+
+// Parse configured count strategy, anything unknown counts exactly
+func ParseCountStrategy(strategy string) CountStrategy {
+	switch CountStrategy(strings.ToLower(strategy)) {
+	case CountEstimated:
+		return CountEstimated
+	case CountNone:
+		return CountNone
+	default:
+		return CountExact
+	}
+}
+
 // Pagination query params
 type PaginationQuery struct {
 	Size    int    `json:"size,omitempty"`
 	Page    int    `json:"page,omitempty"`
 	OrderBy string `json:"orderBy,omitempty"`
+	// Set per endpoint from config, never from the request
+	Count CountStrategy `json:"-"`
+	// Organization the listing is scoped to, set from the caller's membership, zero lists every user
+	OrganizationID int64 `json:"-"`
 }
 
 // Set page size
@@ -72,6 +101,14 @@ func (q *PaginationQuery) GetOrderBy() string {
 	return q.OrderBy
 }
 
+// Get count strategy, exact when none is set
+func (q *PaginationQuery) GetCount() CountStrategy {
+	if q.Count == "" {
+		return CountExact
+	}
+	return q.Count
+}
+
 // Get OrderBy
 func (q *PaginationQuery) GetPage() int {
 	return q.Page
@@ -83,7 +120,11 @@ func (q *PaginationQuery) GetSize() int {
 }
 
 func (q *PaginationQuery) GetQueryString() string {
-	return fmt.Sprintf("page=%v&size=%v&orderBy=%s", q.GetPage(), q.GetSize(), q.GetOrderBy())
+	query := fmt.Sprintf("page=%v&size=%v&orderBy=%s", q.GetPage(), q.GetSize(), q.GetOrderBy())
+	if count := q.GetCount(); count != CountExact {
+		query += "&count=" + string(count)
+	}
+	return query
 }
 
 // Get pagination query struct from